@@ -160,6 +160,7 @@ func TestAdminRequestPasswordReset(t *testing.T) {
 				"OnModelAfterUpdate":                       1,
 				"OnMailerBeforeAdminResetPasswordSend":     1,
 				"OnMailerAfterAdminResetPasswordSend":      1,
+				"OnMailerSend":                             1,
 				"OnAdminBeforeRequestPasswordResetRequest": 1,
 				"OnAdminAfterRequestPasswordResetRequest":  1,
 			},
@@ -1,10 +1,15 @@
 package apis
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/tools/i18n"
 	"github.com/pocketbase/pocketbase/tools/inflector"
 )
 
@@ -68,36 +73,90 @@ func NewUnauthorizedError(message string, data any) *ApiError {
 func NewApiError(status int, message string, data any) *ApiError {
 	return &ApiError{
 		rawData: data,
-		Data:    safeErrorsData(data),
+		Data:    safeErrorsData(data, i18n.DefaultLang),
 		Code:    status,
 		Message: strings.TrimSpace(inflector.Sentenize(message)),
 	}
 }
 
-func safeErrorsData(data any) map[string]any {
+// toApiError normalizes the provided error into *ApiError.
+func toApiError(err error) *ApiError {
+	var apiErr *ApiError
+
+	if errors.As(err, &apiErr) {
+		return apiErr // already an api error...
+	}
+
+	if v := new(echo.HTTPError); errors.As(err, &v) {
+		msg := fmt.Sprintf("%v", v.Message)
+		return NewApiError(v.Code, msg, v)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return NewNotFoundError("", err)
+	}
+
+	return NewBadRequestError("", err)
+}
+
+// langQueryParam is the query parameter that could be used to
+// explicitly specify the language of the error messages.
+const langQueryParam = "lang"
+
+// resolveRequestLang returns the registered i18n catalog language
+// matching the ?lang query parameter or the Accept-Language header.
+//
+// Returns empty string if there are no registered catalogs.
+func resolveRequestLang(c echo.Context) string {
+	catalogs := i18n.Default()
+	if len(catalogs.Langs()) == 0 {
+		return ""
+	}
+
+	candidates := i18n.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+
+	if lang := c.QueryParam(langQueryParam); lang != "" {
+		candidates = append([]string{lang}, candidates...)
+	}
+
+	return catalogs.ResolveLang(candidates...)
+}
+
+// localizeApiError replaces the public safe validation error
+// messages of the provided api error with their lang translations
+// from the registered i18n catalogs (if any).
+func localizeApiError(apiErr *ApiError, lang string) {
+	if lang == "" || !isNestedError(apiErr.rawData) {
+		return // no raw validation errors to localize
+	}
+
+	apiErr.Data = safeErrorsData(apiErr.rawData, lang)
+}
+
+func safeErrorsData(data any, lang string) map[string]any {
 	switch v := data.(type) {
 	case validation.Errors:
-		return resolveSafeErrorsData[error](v)
+		return resolveSafeErrorsData[error](v, lang)
 	case map[string]validation.Error:
-		return resolveSafeErrorsData[validation.Error](v)
+		return resolveSafeErrorsData[validation.Error](v, lang)
 	case map[string]error:
-		return resolveSafeErrorsData[error](v)
+		return resolveSafeErrorsData[error](v, lang)
 	case map[string]any:
-		return resolveSafeErrorsData[any](v)
+		return resolveSafeErrorsData[any](v, lang)
 	default:
 		return map[string]any{} // not nil to ensure that is json serialized as object
 	}
 }
 
-func resolveSafeErrorsData[T any](data map[string]T) map[string]any {
+func resolveSafeErrorsData[T any](data map[string]T, lang string) map[string]any {
 	result := map[string]any{}
 
 	for name, err := range data {
 		if isNestedError(err) {
-			result[name] = safeErrorsData(err)
+			result[name] = safeErrorsData(err, lang)
 			continue
 		}
-		result[name] = resolveSafeErrorItem(err)
+		result[name] = resolveSafeErrorItem(err, lang)
 	}
 
 	return result
@@ -113,8 +172,8 @@ func isNestedError(err any) bool {
 }
 
 // resolveSafeErrorItem extracts from each validation error its
-// public safe error code and message.
-func resolveSafeErrorItem(err any) map[string]string {
+// public safe error code and (localized) message.
+func resolveSafeErrorItem(err any, lang string) map[string]string {
 	// default public safe error values
 	code := "validation_invalid_value"
 	msg := "Invalid value."
@@ -122,7 +181,16 @@ func resolveSafeErrorItem(err any) map[string]string {
 	// only validation errors are public safe
 	if obj, ok := err.(validation.Error); ok {
 		code = obj.Code()
+
+		// replace the error message template with the catalog one (if any)
+		// so that the error params could be still applied
+		if translated, ok := i18n.Default().Message(lang, code); ok {
+			obj = obj.SetMessage(translated)
+		}
+
 		msg = inflector.Sentenize(obj.Error())
+	} else if translated, ok := i18n.Default().Message(lang, code); ok {
+		msg = inflector.Sentenize(translated)
 	}
 
 	return map[string]string{
@@ -3,10 +3,14 @@ package apis_test
 import (
 	"encoding/json"
 	"errors"
+	"net/http"
+	"strings"
 	"testing"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/i18n"
 )
 
 func TestNewApiErrorWithRawData(t *testing.T) {
@@ -160,3 +164,65 @@ func TestNewUnauthorizedError(t *testing.T) {
 		}
 	}
 }
+
+func TestApiErrorLocalization(t *testing.T) {
+	t.Parallel()
+
+	// note: use a not commonly used language code to prevent
+	// affecting the other parallel tests
+	i18n.Register("x-test", i18n.Catalog{
+		"validation_required":            "Полето е задължително",
+		"validation_length_out_of_range": "Дължината трябва да е между {{.min}} и {{.max}}",
+	})
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:           "without lang",
+			Method:         http.MethodPost,
+			Url:            "/api/collections/users/records",
+			Body:           strings.NewReader(`{"password":"123","passwordConfirm":"123"}`),
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"password":{"code":"validation_length_out_of_range","message":"The length must be between 8 and 72."}`,
+			},
+			NotExpectedContent: []string{"Дължината"},
+		},
+		{
+			Name:   "with Accept-Language header",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/records",
+			Body:   strings.NewReader(`{"password":"123","passwordConfirm":"123"}`),
+			RequestHeaders: map[string]string{
+				"Accept-Language": "en;q=0.5, x-test-region",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"password":{"code":"validation_length_out_of_range","message":"Дължината трябва да е между 8 и 72."}`,
+			},
+		},
+		{
+			Name:           "with lang query parameter",
+			Method:         http.MethodPost,
+			Url:            "/api/admins/auth-with-password?lang=x-test",
+			Body:           strings.NewReader(`{"identity":"test@example.com"}`),
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"data":{"password":{"code":"validation_required","message":"Полето е задължително."}}`,
+			},
+		},
+		{
+			Name:           "with unknown lang query parameter (fallback to the default messages)",
+			Method:         http.MethodPost,
+			Url:            "/api/admins/auth-with-password?lang=missing",
+			Body:           strings.NewReader(`{"identity":"test@example.com"}`),
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"data":{"password":{"code":"validation_required","message":"Cannot be blank."}}`,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,82 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// bindApiKeyApi registers the api keys management endpoints.
+func bindApiKeyApi(app core.App, rg *echo.Group) {
+	api := apiKeyApi{app: app}
+
+	subGroup := rg.Group("/api-keys", ActivityLogger(app), RequireAdminAuth())
+	subGroup.GET("", api.list)
+	subGroup.POST("", api.create)
+	subGroup.DELETE("/:id", api.delete)
+}
+
+type apiKeyApi struct {
+	app core.App
+}
+
+func (api *apiKeyApi) list(c echo.Context) error {
+	fieldResolver := search.NewSimpleFieldResolver(
+		"id", "created", "updated", "name", "expires",
+	)
+
+	apiKeys := []*models.ApiKey{}
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(api.app.Dao().ApiKeyQuery()).
+		ParseAndExec(c.QueryParams().Encode(), &apiKeys)
+
+	if err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// create creates a new api key and returns it together with the
+// plain key value (the only time when the plain key is available).
+func (api *apiKeyApi) create(c echo.Context) error {
+	form := forms.NewApiKeyCreate(api.app)
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	var apiKey *models.ApiKey
+
+	key, err := form.Submit(func(next forms.InterceptorNextFunc[*models.ApiKey]) forms.InterceptorNextFunc[*models.ApiKey] {
+		return func(m *models.ApiKey) error {
+			apiKey = m
+			return next(m)
+		}
+	})
+	if err != nil {
+		return NewBadRequestError("Failed to create api key.", err)
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		*models.ApiKey
+		Key string `json:"key"`
+	}{apiKey, key})
+}
+
+func (api *apiKeyApi) delete(c echo.Context) error {
+	apiKey, err := api.app.Dao().FindApiKeyById(c.PathParam("id"))
+	if err != nil {
+		return NewNotFoundError("", err)
+	}
+
+	if err := api.app.Dao().DeleteApiKey(apiKey); err != nil {
+		return NewBadRequestError("Failed to delete api key.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
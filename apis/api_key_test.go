@@ -0,0 +1,424 @@
+package apis_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	testApiKeyReadOnly = "pb_key_readonly"
+	testApiKeyWrite    = "pb_key_write"
+	testApiKeyOther    = "pb_key_other"
+	testApiKeyExpired  = "pb_key_expired"
+)
+
+func createTestApiKeys(t testing.TB, app *tests.TestApp) {
+	past, err := types.ParseDateTime(time.Now().Add(-1 * time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiKeys := []*models.ApiKey{
+		{Name: "readonly", KeyHash: security.SHA256(testApiKeyReadOnly), Collections: []string{"sz5l5z67tg7gku0"}, Scopes: []string{"read"}},
+		{Name: "write", KeyHash: security.SHA256(testApiKeyWrite), Collections: []string{"sz5l5z67tg7gku0"}, Scopes: []string{"read", "write", "reports"}},
+		{Name: "other", KeyHash: security.SHA256(testApiKeyOther), Collections: []string{"wzlqyes4orhoygb"}, Scopes: []string{"read", "write"}},
+		{Name: "expired", KeyHash: security.SHA256(testApiKeyExpired), Collections: []string{"sz5l5z67tg7gku0"}, Scopes: []string{"read", "write"}, Expires: past},
+	}
+
+	for _, apiKey := range apiKeys {
+		// skip the model hooks to not affect the scenarios events
+		if err := app.Dao().WithoutHooks().SaveApiKey(apiKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestApiKeysList(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodGet,
+			Url:             "/api/api-keys",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "auth record",
+			Method: http.MethodGet,
+			Url:    "/api/api-keys",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPUserToken,
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "api key",
+			Method: http.MethodGet,
+			Url:    "/api/api-keys",
+			RequestHeaders: map[string]string{
+				"Authorization": "Bearer " + testApiKeyWrite,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin",
+			Method: http.MethodGet,
+			Url:    "/api/api-keys?sort=name",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":4`,
+				`"name":"expired"`,
+				`"name":"other"`,
+				`"name":"readonly"`,
+				`"name":"write"`,
+				`"scopes":["read"]`,
+			},
+			NotExpectedContent: []string{
+				`"keyHash"`,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestApiKeyCreate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodPost,
+			Url:             "/api/api-keys",
+			Body:            strings.NewReader(`{"name":"test","collections":["demo2"],"scopes":["read"]}`),
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + invalid data",
+			Method: http.MethodPost,
+			Url:    "/api/api-keys",
+			Body:   strings.NewReader(`{"collections":["missing"],"scopes":["a b"],"expires":"2000-01-01 00:00:00.000Z"}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"name":{"code":"validation_required"`,
+				`"collections":{"code":"validation_invalid_collection"`,
+				`"scopes":{"0":{"code":"validation_match_invalid"`,
+				`"expires":{"code":"validation_expires_in_the_past"`,
+			},
+		},
+		{
+			Name:   "admin + valid data",
+			Method: http.MethodPost,
+			Url:    "/api/api-keys",
+			Body:   strings.NewReader(`{"name":"test","collections":["demo2","sz5l5z67tg7gku0"],"scopes":["read","read","reports"]}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"name":"test"`,
+				`"collections":["sz5l5z67tg7gku0"]`,
+				`"scopes":["read","reports"]`,
+				`"key":"pb_key_`,
+			},
+			NotExpectedContent: []string{
+				`"keyHash"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnModelBeforeCreate": 1,
+				"OnModelAfterCreate":  1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestApiKeyDelete(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodDelete,
+			Url:             "/api/api-keys/missing",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + missing api key",
+			Method: http.MethodDelete,
+			Url:    "/api/api-keys/missing",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + existing api key",
+			Method: http.MethodDelete,
+			Url:    "/api/api-keys/test_api_key_id",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				apiKey := &models.ApiKey{Name: "test", KeyHash: security.SHA256(testApiKeyWrite)}
+				apiKey.MarkAsNew()
+				apiKey.SetId("test_api_key_id")
+				if err := app.Dao().WithoutHooks().SaveApiKey(apiKey); err != nil {
+					t.Fatal(err)
+				}
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				if _, err := app.Dao().FindApiKeyByKey(testApiKeyWrite); err == nil {
+					t.Fatal("Expected the api key to be deleted")
+				}
+			},
+			ExpectedStatus: 204,
+			ExpectedEvents: map[string]int{
+				"OnModelBeforeDelete": 1,
+				"OnModelAfterDelete":  1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestApiKeyAccess(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "read-only api key + list",
+			Method: http.MethodGet,
+			Url:    "/api/collections/demo2/records",
+			RequestHeaders: map[string]string{
+				"Authorization": "Bearer " + testApiKeyReadOnly,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":3`},
+			ExpectedEvents:  map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:   "read-only api key + create",
+			Method: http.MethodPost,
+			Url:    "/api/collections/demo2/records",
+			Body:   strings.NewReader(`{"title":"new"}`),
+			RequestHeaders: map[string]string{
+				"Authorization": "Bearer " + testApiKeyReadOnly,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "read-only api key + update",
+			Method: http.MethodPatch,
+			Url:    "/api/collections/demo2/records/llvuca81nly1qls",
+			Body:   strings.NewReader(`{"title":"new"}`),
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyReadOnly,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "read-only api key + delete",
+			Method: http.MethodDelete,
+			Url:    "/api/collections/demo2/records/llvuca81nly1qls",
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyReadOnly,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "write api key + create",
+			Method: http.MethodPost,
+			Url:    "/api/collections/demo2/records",
+			Body:   strings.NewReader(`{"title":"new"}`),
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyWrite,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"title":"new"`},
+			ExpectedEvents: map[string]int{
+				"OnRecordBeforeCreateRequest": 1,
+				"OnRecordAfterCreateRequest":  1,
+				"OnModelBeforeCreate":         1,
+				"OnModelAfterCreate":          1,
+			},
+		},
+		{
+			Name:   "api key of another collection",
+			Method: http.MethodGet,
+			Url:    "/api/collections/demo2/records",
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyOther,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "api key batch request",
+			Method: http.MethodPost,
+			Url:    "/api/batch",
+			Body:   strings.NewReader(`{"requests":[{"method":"POST","url":"/api/collections/demo2/records","body":{"title":"new"}}]}`),
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyReadOnly,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"response":{"code":403`},
+		},
+		{
+			Name:   "api key graphql query of the scoped collection",
+			Method: http.MethodPost,
+			Url:    "/api/graphql",
+			Body:   strings.NewReader(`{"query":"{ demo2 { totalItems } }"}`),
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyReadOnly,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"demo2":{"totalItems":3}`},
+		},
+		{
+			Name:   "api key graphql query of another collection",
+			Method: http.MethodPost,
+			Url:    "/api/graphql",
+			Body:   strings.NewReader(`{"query":"{ demo2 { totalItems } }"}`),
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyOther,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+			},
+			ExpectedStatus:     200,
+			ExpectedContent:    []string{`"message":"The api key is not allowed to query \"demo2\"."`},
+			NotExpectedContent: []string{`"totalItems"`},
+		},
+		{
+			Name:   "api key scopes in the collection rule",
+			Method: http.MethodGet,
+			Url:    "/api/collections/demo2/records",
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyWrite,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+				setTestApiKeyListRule(t, app, `@request.apiKey.scopes ~ "reports"`)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":3`},
+			ExpectedEvents:  map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:   "api key without the rule scope",
+			Method: http.MethodGet,
+			Url:    "/api/collections/demo2/records",
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyReadOnly,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+				setTestApiKeyListRule(t, app, `@request.apiKey.scopes ~ "reports"`)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			ExpectedEvents:  map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:   "expired api key (aka. guest)",
+			Method: http.MethodGet,
+			Url:    "/api/collections/demo2/records",
+			RequestHeaders: map[string]string{
+				"X-Api-Key": testApiKeyExpired,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestApiKeys(t, app)
+				setTestApiKeyListRule(t, app, `@request.apiKey.id != ""`)
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			ExpectedEvents:  map[string]int{"OnRecordsListRequest": 1},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func setTestApiKeyListRule(t testing.TB, app *tests.TestApp, rule string) {
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.ListRule = types.Pointer(rule)
+
+	// skip the model hooks to not affect the scenarios events
+	if err := app.Dao().WithoutHooks().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := core.ReloadCachedCollections(app); err != nil {
+		t.Fatal(err)
+	}
+}
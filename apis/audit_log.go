@@ -0,0 +1,270 @@
+package apis
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/models/settings"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// auditLogSecretFields is a list with the auth record fields
+// which values are never stored in the audit logs changes.
+var auditLogSecretFields = []string{
+	schema.FieldNamePasswordHash,
+	schema.FieldNameTokenKey,
+}
+
+// bindAuditLogHooks registers the record model and auth request hooks
+// that write the _auditLogs entries (when enabled in the app settings).
+//
+// The record changes are logged on every model create/update/delete
+// (including the ones outside of the record API requests, eg. from hooks or cron jobs).
+func bindAuditLogHooks(app core.App) {
+	requests := &auditLogRequests{}
+
+	// associate the record with its request so that
+	// the model hooks could resolve the request actor
+	// -----------------------------------------------------------
+
+	app.OnRecordBeforeCreateRequest().Add(func(e *core.RecordCreateEvent) error {
+		requests.track(e.HttpContext, e.Record)
+		return nil
+	})
+
+	app.OnRecordBeforeUpdateRequest().Add(func(e *core.RecordUpdateEvent) error {
+		requests.track(e.HttpContext, e.Record)
+		return nil
+	})
+
+	app.OnRecordBeforeDeleteRequest().Add(func(e *core.RecordDeleteEvent) error {
+		requests.track(e.HttpContext, e.Record)
+		return nil
+	})
+
+	// record changes
+	// -----------------------------------------------------------
+
+	app.OnModelAfterCreate().Add(func(e *core.ModelEvent) error {
+		if record, ok := e.Model.(*models.Record); ok {
+			saveAuditLog(app, e.Dao, requests.get(record), nil, models.AuditActionCreate, models.NewRecord(record.Collection()), record)
+		}
+		return nil
+	})
+
+	app.OnModelAfterUpdate().Add(func(e *core.ModelEvent) error {
+		if record, ok := e.Model.(*models.Record); ok {
+			saveAuditLog(app, e.Dao, requests.get(record), nil, models.AuditActionUpdate, record.OriginalCopy(), record)
+		}
+		return nil
+	})
+
+	app.OnModelAfterDelete().Add(func(e *core.ModelEvent) error {
+		if record, ok := e.Model.(*models.Record); ok {
+			saveAuditLog(app, e.Dao, requests.get(record), nil, models.AuditActionDelete, record, models.NewRecord(record.Collection()))
+		}
+		return nil
+	})
+
+	// for the auth flows the actor is the auth record itself
+	// -----------------------------------------------------------
+
+	app.OnRecordAfterAuthWithPasswordRequest().Add(func(e *core.RecordAuthWithPasswordEvent) error {
+		saveAuditLog(app, app.Dao(), e.HttpContext, e.Record, models.AuditActionAuthWithPassword, e.Record.OriginalCopy(), e.Record)
+		return nil
+	})
+
+	app.OnRecordAfterAuthWithOAuth2Request().Add(func(e *core.RecordAuthWithOAuth2Event) error {
+		if e.Record == nil {
+			return nil
+		}
+
+		old := e.Record.OriginalCopy()
+		if e.IsNewRecord {
+			old = models.NewRecord(e.Record.Collection())
+		}
+
+		saveAuditLog(app, app.Dao(), e.HttpContext, e.Record, models.AuditActionAuthWithOAuth2, old, e.Record)
+		return nil
+	})
+
+	app.OnRecordAfterAuthRefreshRequest().Add(func(e *core.RecordAuthRefreshEvent) error {
+		saveAuditLog(app, app.Dao(), e.HttpContext, e.Record, models.AuditActionAuthRefresh, e.Record.OriginalCopy(), e.Record)
+		return nil
+	})
+
+	app.OnRecordAfterConfirmPasswordResetRequest().Add(func(e *core.RecordConfirmPasswordResetEvent) error {
+		saveAuditLog(app, app.Dao(), e.HttpContext, e.Record, models.AuditActionConfirmPasswordReset, e.Record.OriginalCopy(), e.Record)
+		return nil
+	})
+
+	app.OnRecordAfterConfirmVerificationRequest().Add(func(e *core.RecordConfirmVerificationEvent) error {
+		saveAuditLog(app, app.Dao(), e.HttpContext, e.Record, models.AuditActionConfirmVerification, e.Record.OriginalCopy(), e.Record)
+		return nil
+	})
+
+	app.OnRecordAfterConfirmEmailChangeRequest().Add(func(e *core.RecordConfirmEmailChangeEvent) error {
+		saveAuditLog(app, app.Dao(), e.HttpContext, e.Record, models.AuditActionConfirmEmailChange, e.Record.OriginalCopy(), e.Record)
+		return nil
+	})
+}
+
+// saveAuditLog writes a single audit log entry for the changes between
+// the old and new record states.
+//
+// If actor is nil, the actor is resolved from the request auth state.
+// If c is nil (aka. the change is not part of a record request),
+// the actor type is [models.AuditActorSystem].
+//
+// Failures are only logged to avoid failing the already persisted changes.
+func saveAuditLog(
+	app core.App,
+	dao *daos.Dao,
+	c echo.Context,
+	actor *models.Record,
+	action string,
+	oldRecord *models.Record,
+	newRecord *models.Record,
+) {
+	collection := newRecord.Collection()
+
+	// the audit logs table is not a collection but
+	// extra check to ensure that it is never self-audited
+	if collection.Name == (&models.AuditLog{}).TableName() {
+		return
+	}
+
+	if !app.Settings().AuditLogs.IsCollectionEnabled(collection.Id, collection.Name) {
+		return
+	}
+
+	recordId := newRecord.Id
+	if recordId == "" {
+		recordId = oldRecord.Id
+	}
+
+	log := &models.AuditLog{
+		ActorType:      models.RequestAuthGuest,
+		CollectionId:   collection.Id,
+		CollectionName: collection.Name,
+		RecordId:       recordId,
+		Action:         action,
+		Changes:        auditLogChanges(oldRecord, newRecord),
+	}
+
+	if c == nil {
+		log.ActorType = models.AuditActorSystem
+	} else {
+		if actor == nil {
+			actor, _ = c.Get(ContextAuthRecordKey).(*models.Record)
+		}
+
+		if actor != nil {
+			log.ActorType = models.RequestAuthRecord
+			log.ActorId = actor.Id
+			log.ActorCollectionId = actor.Collection().Id
+		} else if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+			log.ActorType = models.RequestAuthAdmin
+			log.ActorId = admin.Id
+		}
+
+		if impersonator, _ := c.Get(ContextImpersonatorKey).(*models.Admin); impersonator != nil {
+			log.ImpersonatorId = impersonator.Id
+		}
+
+		log.Ip = RealIp(c)
+	}
+
+	if err := dao.SaveAuditLog(log); err != nil {
+		app.Logger().Error(
+			"Failed to save audit log",
+			slog.String("collectionName", collection.Name),
+			slog.String("recordId", recordId),
+			slog.String("action", action),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// auditLogRequests keeps track of the in-progress record requests
+// (the entries are removed once the request response is written).
+type auditLogRequests struct {
+	items sync.Map // map[*models.Record]echo.Context
+}
+
+// track associates the provided record with the request c.
+func (r *auditLogRequests) track(c echo.Context, record *models.Record) {
+	r.items.Store(record, c)
+
+	c.Response().After(func() {
+		r.items.Delete(record)
+	})
+}
+
+// get returns the request associated with the provided record
+// or nil if the record is not part of a tracked request.
+func (r *auditLogRequests) get(record *models.Record) echo.Context {
+	c, _ := r.items.Load(record)
+
+	result, _ := c.(echo.Context)
+
+	return result
+}
+
+// auditLogChanges returns the changed stored record fields between
+// oldRecord and newRecord in the format {"field": {"old": ..., "new": ...}}.
+//
+// The values of the auth secret fields (password hash and token key)
+// and of the encrypted fields are masked.
+func auditLogChanges(oldRecord *models.Record, newRecord *models.Record) types.JsonMap {
+	collection := newRecord.Collection()
+
+	secretFields := append([]string{}, auditLogSecretFields...)
+
+	fields := make([]string, 0, len(collection.Schema.Fields())+len(schema.AuthFieldNames())+1)
+	for _, f := range collection.Schema.Fields() {
+		if f.Type == schema.FieldTypeComputed {
+			continue // not stored
+		}
+		if f.Type == schema.FieldTypeEncrypted {
+			secretFields = append(secretFields, f.Name)
+		}
+		fields = append(fields, f.Name)
+	}
+	if collection.IsAuth() {
+		fields = append(fields, schema.AuthFieldNames()...)
+	}
+	if collection.HasSoftDelete() {
+		fields = append(fields, schema.FieldNameDeleted)
+	}
+
+	changes := types.JsonMap{}
+
+	for _, name := range fields {
+		oldValue := oldRecord.Get(name)
+		newValue := newRecord.Get(name)
+
+		oldRaw, oldErr := json.Marshal(oldValue)
+		newRaw, newErr := json.Marshal(newValue)
+		if oldErr == nil && newErr == nil && bytes.Equal(oldRaw, newRaw) {
+			continue // unchanged
+		}
+
+		if list.ExistInSlice(name, secretFields) {
+			oldValue = settings.SecretMask
+			newValue = settings.SecretMask
+		}
+
+		changes[name] = map[string]any{"old": oldValue, "new": newValue}
+	}
+
+	return changes
+}
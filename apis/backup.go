@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v5"
@@ -25,7 +26,7 @@ func bindBackupApi(app core.App, rg *echo.Group) {
 	subGroup := rg.Group("/backups", ActivityLogger(app))
 	subGroup.GET("", api.list, RequireAdminAuth())
 	subGroup.POST("", api.create, RequireAdminAuth())
-	subGroup.POST("/upload", api.upload, RequireAdminAuth())
+	subGroup.POST("/upload", api.upload, RequireAdminAuth(), BodyLimit(0))
 	subGroup.GET("/:key", api.download)
 	subGroup.DELETE("/:key", api.delete, RequireAdminAuth())
 	subGroup.POST("/:key/restore", api.restore, RequireAdminAuth())
@@ -52,16 +53,21 @@ func (api *backupApi) list(c echo.Context) error {
 		return NewBadRequestError("Failed to retrieve backup items. Raw error: \n"+err.Error(), nil)
 	}
 
-	result := make([]models.BackupFileInfo, len(backups))
+	result := make([]models.BackupFileInfo, 0, len(backups))
+
+	for _, obj := range backups {
+		// skip the backup checksum files
+		if strings.HasSuffix(obj.Key, core.BackupChecksumSuffix) {
+			continue
+		}
 
-	for i, obj := range backups {
 		modified, _ := types.ParseDateTime(obj.ModTime)
 
-		result[i] = models.BackupFileInfo{
+		result = append(result, models.BackupFileInfo{
 			Key:      obj.Key,
 			Size:     obj.Size,
 			Modified: modified,
-		}
+		})
 	}
 
 	return c.JSON(http.StatusOK, result)
@@ -210,5 +216,12 @@ func (api *backupApi) delete(c echo.Context) error {
 		return NewBadRequestError("Invalid or already deleted backup file. Raw error: \n"+err.Error(), nil)
 	}
 
+	// delete the backup checksum file (if any)
+	if exists, _ := fsys.Exists(key + core.BackupChecksumSuffix); exists {
+		if err := fsys.Delete(key + core.BackupChecksumSuffix); err != nil {
+			api.app.Logger().Debug("Failed to delete backup checksum file", "key", key, "error", err.Error())
+		}
+	}
+
 	return c.NoContent(http.StatusNoContent)
 }
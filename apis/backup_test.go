@@ -612,6 +612,16 @@ func TestBackupsDelete(t *testing.T) {
 						t.Fatalf("Expected backup %q to be deleted", deletedFile)
 					}
 				}
+
+				fsys, err := app.NewBackupsFilesystem()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer fsys.Close()
+
+				if exists, _ := fsys.Exists(deletedFile + core.BackupChecksumSuffix); exists {
+					t.Fatalf("Expected the %q checksum file to be deleted", deletedFile)
+				}
 			},
 			ExpectedStatus: 204,
 		},
@@ -755,7 +765,20 @@ func getBackupFiles(app core.App) ([]*blob.ListObject, error) {
 	}
 	defer fsys.Close()
 
-	return fsys.List("")
+	files, err := fsys.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	// exclude the backup checksum files
+	backups := make([]*blob.ListObject, 0, len(files))
+	for _, f := range files {
+		if !strings.HasSuffix(f.Key, core.BackupChecksumSuffix) {
+			backups = append(backups, f)
+		}
+	}
+
+	return backups, nil
 }
 
 func ensureNoBackups(t *testing.T, app *tests.TestApp) {
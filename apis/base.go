@@ -2,7 +2,6 @@
 package apis
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -42,18 +41,25 @@ func InitApi(app core.App) (*echo.Echo, error) {
 	})
 
 	// default middlewares
+	e.Pre(Tracing(app))
+	e.Pre(CORS(app))
 	e.Pre(middleware.RemoveTrailingSlashWithConfig(middleware.RemoveTrailingSlashConfig{
 		Skipper: func(c echo.Context) bool {
 			// enable by default only for the API routes
 			return !strings.HasPrefix(c.Request().URL.Path, "/api/")
 		},
 	}))
+	e.Pre(LoadRealIp(app))
 	e.Pre(LoadAuthContext(app))
 	e.Use(middleware.Recover())
 	e.Use(middleware.Secure())
+	e.Use(BodyLimitFunc(func(c echo.Context) int64 {
+		return DefaultMaxBodySize
+	}))
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Set(ContextExecStartKey, time.Now())
+			c.Set(contextAppKey, app)
 
 			return next(c)
 		}
@@ -65,23 +71,14 @@ func InitApi(app core.App) (*echo.Echo, error) {
 			return // no error
 		}
 
-		var apiErr *ApiError
-
-		if errors.As(err, &apiErr) {
-			// already an api error...
-		} else if v := new(echo.HTTPError); errors.As(err, &v) {
-			msg := fmt.Sprintf("%v", v.Message)
-			apiErr = NewApiError(v.Code, msg, v)
-		} else {
-			if errors.Is(err, sql.ErrNoRows) {
-				apiErr = NewNotFoundError("", err)
-			} else {
-				apiErr = NewBadRequestError("", err)
-			}
-		}
+		apiErr := toApiError(err)
 
 		logRequest(app, c, apiErr)
 
+		// localize the validation error messages based on the ?lang query
+		// parameter or the Accept-Language header (if there are registered catalogs)
+		localizeApiError(apiErr, resolveRequestLang(c))
+
 		if c.Response().Committed {
 			return // already committed
 		}
@@ -120,14 +117,24 @@ func InitApi(app core.App) (*echo.Echo, error) {
 	api := e.Group("/api", eagerRequestInfoCache(app))
 	bindSettingsApi(app, api)
 	bindAdminApi(app, api)
+	bindApiKeyApi(app, api)
+	bindWebhookApi(app, api)
 	bindCollectionApi(app, api)
 	bindRecordCrudApi(app, api)
 	bindRecordAuthApi(app, api)
+	bindRecordShareApi(app, api)
 	bindFileApi(app, api)
 	bindRealtimeApi(app, api)
 	bindLogsApi(app, api)
 	bindHealthApi(app, api)
 	bindBackupApi(app, api)
+	bindBatchApi(app, api)
+	bindTasksApi(app, api)
+	bindOpenApiApi(app, api)
+	bindGraphQLApi(app, api)
+
+	// system hooks
+	bindAuditLogHooks(app)
 
 	// catch all any route
 	api.Any("/*", func(c echo.Context) error {
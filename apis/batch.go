@@ -0,0 +1,250 @@
+package apis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// MaxBatchRequests is the max allowed number of sub-requests in a single batch.
+const MaxBatchRequests = 50
+
+// errBatchItemFailed is used to rollback the batch transaction on sub-request failure.
+var errBatchItemFailed = errors.New("batch sub-request failure")
+
+// bindBatchApi registers the batch api endpoint.
+func bindBatchApi(app core.App, rg *echo.Group) {
+	api := batchApi{app: app}
+
+	rg.POST("/batch", api.execute, ActivityLogger(app))
+}
+
+type batchApi struct {
+	app core.App
+}
+
+// BatchRequest defines a single batch sub-request.
+type BatchRequest struct {
+	// Method is the sub-request http method (POST, PATCH or DELETE).
+	Method string `json:"method"`
+
+	// Url is the sub-request url (eg. "/api/collections/demo/records?expand=rel").
+	Url string `json:"url"`
+
+	// Body is the sub-request json body.
+	Body map[string]any `json:"body"`
+}
+
+// BatchRequestResult defines a single batch sub-request result.
+type BatchRequestResult struct {
+	Status int `json:"status"`
+	Body   any `json:"body"`
+}
+
+type batchForm struct {
+	Requests []*BatchRequest `json:"requests"`
+
+	// ContinueOnError instructs the batch to execute all sub-requests
+	// regardless of their errors.
+	//
+	// If false (default), the first failed sub-request rollbacks all
+	// previous changes and the batch returns an error.
+	//
+	// If true, only the changes of the failed sub-requests are rolled back.
+	ContinueOnError bool `json:"continueOnError"`
+}
+
+// execute runs all batch sub-requests in a single transaction
+// and returns their results in the same order.
+func (api *batchApi) execute(c echo.Context) error {
+	form := new(batchForm)
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	if len(form.Requests) == 0 {
+		return NewBadRequestError("The batch must contain at least one request.", nil)
+	}
+
+	if len(form.Requests) > MaxBatchRequests {
+		return NewBadRequestError(fmt.Sprintf("The batch must contain no more than %d requests.", MaxBatchRequests), nil)
+	}
+
+	results := make([]*BatchRequestResult, len(form.Requests))
+
+	var failedIndex int
+	var failedErr *ApiError
+
+	txErr := api.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		for i, br := range form.Requests {
+			var itemErr *ApiError
+
+			// each sub-request is wrapped in a savepoint so that
+			// only its own changes are rolled back on failure
+			spErr := txDao.RunInSavepoint(func(spDao *daos.Dao) error {
+				results[i], itemErr = api.executeItem(c, spDao, br)
+				if itemErr != nil {
+					return itemErr
+				}
+				return nil
+			})
+
+			if itemErr == nil && spErr != nil {
+				itemErr = NewBadRequestError("", spErr)
+				results[i] = &BatchRequestResult{Status: itemErr.Code, Body: itemErr}
+			}
+
+			if itemErr != nil && !form.ContinueOnError {
+				failedIndex = i
+				failedErr = itemErr
+				return errBatchItemFailed
+			}
+		}
+
+		return nil
+	})
+
+	if failedErr != nil {
+		apiErr := NewBadRequestError("Batch transaction failed.", nil)
+		apiErr.Data = map[string]any{
+			"requests": map[string]any{
+				strconv.Itoa(failedIndex): map[string]any{
+					"code":     "batch_request_failed",
+					"message":  failedErr.Message,
+					"response": failedErr,
+				},
+			},
+		}
+		return apiErr
+	}
+
+	if txErr != nil {
+		return NewBadRequestError("Batch transaction failed.", txErr)
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
+
+// executeItem executes a single batch sub-request using the provided dao.
+//
+// A nil *ApiError is returned only if the sub-request has completed with a 2xx status.
+func (api *batchApi) executeItem(c echo.Context, dao *daos.Dao, br *BatchRequest) (*BatchRequestResult, *ApiError) {
+	failure := func(apiErr *ApiError) (*BatchRequestResult, *ApiError) {
+		return &BatchRequestResult{Status: apiErr.Code, Body: apiErr}, apiErr
+	}
+
+	handler, pathParams, err := api.resolveHandler(dao, br)
+	if err != nil {
+		return failure(NewBadRequestError(err.Error(), nil))
+	}
+
+	rawBody, err := json.Marshal(br.Body)
+	if err != nil {
+		return failure(NewBadRequestError("Failed to serialize the batch request body.", err))
+	}
+
+	req, err := http.NewRequestWithContext(c.Request().Context(), br.Method, br.Url, bytes.NewReader(rawBody))
+	if err != nil {
+		return failure(NewBadRequestError("Invalid batch request.", err))
+	}
+	req.Header = c.Request().Header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Del("Content-Length")
+	req.RemoteAddr = c.Request().RemoteAddr
+
+	recorder := newBatchResponseRecorder()
+
+	subCtx := c.Echo().NewContext(req, recorder)
+	subCtx.SetPathParams(pathParams)
+	subCtx.Set(ContextAdminKey, c.Get(ContextAdminKey))
+	subCtx.Set(ContextAuthRecordKey, c.Get(ContextAuthRecordKey))
+	subCtx.Set(ContextApiKeyKey, c.Get(ContextApiKeyKey))
+
+	handlerErr := LoadCollectionContext(api.app, models.CollectionTypeBase, models.CollectionTypeAuth)(handler)(subCtx)
+	if handlerErr != nil {
+		return failure(toApiError(handlerErr))
+	}
+
+	result := &BatchRequestResult{Status: recorder.status}
+	if recorder.body.Len() > 0 {
+		result.Body = json.RawMessage(recorder.body.Bytes())
+	}
+
+	if recorder.status < 200 || recorder.status > 299 {
+		return result, NewApiError(recorder.status, "", nil)
+	}
+
+	return result, nil
+}
+
+// resolveHandler returns the record api handler (bound to the
+// specified dao) that matches the batch sub-request method and url.
+func (api *batchApi) resolveHandler(dao *daos.Dao, br *BatchRequest) (echo.HandlerFunc, echo.PathParams, error) {
+	u, err := url.Parse(br.Url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// expects "/api/collections/:collection/records" or "/api/collections/:collection/records/:id"
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || len(parts) > 5 || parts[0] != "api" || parts[1] != "collections" || parts[3] != "records" {
+		return nil, nil, fmt.Errorf("unsupported batch request url %q", br.Url)
+	}
+
+	params := echo.PathParams{{Name: "collection", Value: parts[2]}}
+	if len(parts) == 5 {
+		params = append(params, echo.PathParam{Name: "id", Value: parts[4]})
+	}
+
+	recordApi := &recordApi{app: api.app, txDao: dao}
+
+	switch {
+	case br.Method == http.MethodPost && len(parts) == 4:
+		return recordApi.create, params, nil
+	case br.Method == http.MethodPatch && len(parts) == 5:
+		return recordApi.update, params, nil
+	case br.Method == http.MethodDelete && len(parts) == 5:
+		return recordApi.delete, params, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported batch request %s %s", br.Method, br.Url)
+}
+
+// -------------------------------------------------------------------
+
+// batchResponseRecorder is a minimal in-memory http.ResponseWriter
+// used to capture the batch sub-requests responses.
+type batchResponseRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newBatchResponseRecorder() *batchResponseRecorder {
+	return &batchResponseRecorder{
+		header: http.Header{},
+		status: http.StatusOK,
+	}
+}
+
+func (r *batchResponseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *batchResponseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *batchResponseRecorder) WriteHeader(status int) {
+	r.status = status
+}
@@ -20,6 +20,7 @@ func bindCollectionApi(app core.App, rg *echo.Group) {
 	subGroup.GET("/:collection", api.view)
 	subGroup.PATCH("/:collection", api.update)
 	subGroup.DELETE("/:collection", api.delete)
+	subGroup.POST("/:collection/duplicate", api.duplicate)
 	subGroup.PUT("/import", api.bulkImport)
 }
 
@@ -175,6 +176,27 @@ func (api *collectionApi) delete(c echo.Context) error {
 	})
 }
 
+func (api *collectionApi) duplicate(c echo.Context) error {
+	source, err := api.app.Dao().FindCollectionByNameOrId(c.PathParam("collection"))
+	if err != nil || source == nil {
+		return NewNotFoundError("", err)
+	}
+
+	form := forms.NewCollectionDuplicate(api.app, source)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	clone, err := form.Submit()
+	if err != nil {
+		return NewBadRequestError("Failed to duplicate the collection.", err)
+	}
+
+	return c.JSON(http.StatusOK, clone)
+}
+
 func (api *collectionApi) bulkImport(c echo.Context) error {
 	form := forms.NewCollectionsImport(api.app)
 
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +28,15 @@ import (
 var imageContentTypes = []string{"image/png", "image/jpg", "image/jpeg", "image/gif"}
 var defaultThumbSizes = []string{"100x100"}
 
+// preferredThumbFormats lists the thumb formats that could be negotiated
+// with the Accept request header (ordered by preference).
+//
+// Only the formats registered in [filesystem.ThumbEncoders] are considered.
+var preferredThumbFormats = []string{"webp"}
+
+// signedFileURLExpiry is the validity duration of the signed file download urls.
+const signedFileURLExpiry = 5 * time.Minute
+
 // bindFileApi registers the file api endpoints and the corresponding handlers.
 func bindFileApi(app core.App, rg *echo.Group) {
 	api := fileApi{
@@ -133,7 +144,7 @@ func (api *fileApi) download(c echo.Context) error {
 			}
 		}
 
-		if ok, _ := api.app.Dao().CanAccessRecord(record, &requestInfo, record.Collection().ViewRule); !ok {
+		if ok, _ := api.app.Dao().CanAccessRecord(record, &requestInfo, record.Collection().TenantScopedRule(record.Collection().ViewRule)); !ok {
 			return NewForbiddenError("Insufficient permissions to access the file resource.", nil)
 		}
 	}
@@ -160,7 +171,8 @@ func (api *fileApi) download(c echo.Context) error {
 	servedName := filename
 
 	// check for valid thumb size param
-	thumbSize := c.QueryParam("thumb")
+	// (with optional output format suffix, eg. "100x100f.webp")
+	thumbSize, thumbFormat := splitThumbParam(c.QueryParam("thumb"))
 	if thumbSize != "" && (list.ExistInSlice(thumbSize, defaultThumbSizes) || list.ExistInSlice(thumbSize, options.Thumbs)) {
 		// extract the original file meta attributes and check it existence
 		oAttrs, oAttrsErr := fsys.Attributes(originalPath)
@@ -170,8 +182,18 @@ func (api *fileApi) download(c echo.Context) error {
 
 		// check if it is an image
 		if list.ExistInSlice(oAttrs.ContentType, imageContentTypes) {
+			// negotiate the thumb output format with the client
+			if thumbFormat == "" {
+				c.Response().Header().Add("Vary", "Accept")
+				thumbFormat = negotiateThumbFormat(c.Request().Header.Get("Accept"))
+			}
+
 			// add thumb size as file suffix
 			servedName = thumbSize + "_" + filename
+			if thumbFormat != "" {
+				// store the format variants next to the regular thumbs
+				servedName = strings.TrimSuffix(servedName, filepath.Ext(servedName)) + "." + thumbFormat
+			}
 			servedPath = baseFilesPath + "/thumbs_" + filename + "/" + servedName
 
 			// create a new thumb if it doesn't exist
@@ -210,6 +232,10 @@ func (api *fileApi) download(c echo.Context) error {
 			return nil
 		}
 
+		if signedURL := api.signedFileURL(e.HttpContext, fsys, e.ServedPath); signedURL != "" {
+			return e.HttpContext.Redirect(http.StatusTemporaryRedirect, signedURL)
+		}
+
 		if err := fsys.Serve(e.HttpContext.Response(), e.HttpContext.Request(), e.ServedPath, e.ServedName); err != nil {
 			return NewNotFoundError("", err)
 		}
@@ -218,6 +244,37 @@ func (api *fileApi) download(c echo.Context) error {
 	})
 }
 
+// signedFileURL returns a short-lived signed url of the file at servedPath
+// if the app storage is configured to serve its files with signed urls.
+//
+// Returns empty string if the signed urls are not enabled, when a forced
+// download is requested (the SAS urls can't set the Content-Disposition header)
+// or on signing error (aka. fallback to streaming the file through the app).
+func (api *fileApi) signedFileURL(c echo.Context, fsys *filesystem.System, servedPath string) string {
+	settings := api.app.Settings()
+
+	// note: the S3 storage has priority over the Azure Blob one
+	if settings.S3.Enabled || !settings.AzureBlob.Enabled || !settings.AzureBlob.ServeSignedURLs {
+		return ""
+	}
+
+	if forceDownload, _ := strconv.ParseBool(c.QueryParam("download")); forceDownload {
+		return ""
+	}
+
+	signedURL, err := fsys.SignedURL(servedPath, signedFileURLExpiry)
+	if err != nil {
+		api.app.Logger().Warn(
+			"Fallback to streaming - failed to sign the file url",
+			slog.Any("error", err),
+			slog.String("path", servedPath),
+		)
+		return ""
+	}
+
+	return signedURL
+}
+
 func (api *fileApi) findAdminOrAuthRecordByFileToken(fileToken string) (models.Model, error) {
 	fileToken = strings.TrimSpace(fileToken)
 	if fileToken == "" {
@@ -249,6 +306,59 @@ func (api *fileApi) findAdminOrAuthRecordByFileToken(fileToken string) (models.M
 	return nil, errors.New("missing or invalid file token")
 }
 
+// splitThumbParam splits the thumb query parameter into its size and
+// output format parts (eg. "100x100f.webp" -> "100x100f", "webp").
+//
+// The returned format is empty if there is no registered encoder for it.
+func splitThumbParam(raw string) (size string, format string) {
+	idx := strings.LastIndex(raw, ".")
+	if idx < 0 {
+		return raw, ""
+	}
+
+	format = strings.ToLower(raw[idx+1:])
+	if _, ok := filesystem.ThumbEncoders[format]; !ok {
+		format = ""
+	}
+
+	return raw[:idx], format
+}
+
+// negotiateThumbFormat returns the first preferred thumb format
+// accepted by the provided Accept header value
+// (or empty string if none of them is accepted).
+func negotiateThumbFormat(accept string) string {
+	if accept == "" {
+		return ""
+	}
+
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		// exclude explicitly rejected types (eg. "image/webp;q=0")
+		rejected := false
+		for _, param := range params[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				rejected = cast.ToFloat64(q) <= 0
+			}
+		}
+
+		accepted[mediaType] = !rejected
+	}
+
+	for _, format := range preferredThumbFormats {
+		encoder, ok := filesystem.ThumbEncoders[format]
+		if ok && accepted[encoder.ContentType] {
+			return format
+		}
+	}
+
+	return ""
+}
+
 func (api *fileApi) createThumb(
 	c echo.Context,
 	fsys *filesystem.System,
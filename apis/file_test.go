@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 
@@ -394,6 +395,76 @@ func TestFileDownload(t *testing.T) {
 	}
 }
 
+func TestFileDownloadConditional(t *testing.T) {
+	t.Parallel()
+
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileKey := "_pb_users_auth_/oap640cot4yru2s/test_kfd2wYLxkz.txt"
+
+	send := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/files/"+fileKey, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected non-empty ETag")
+	}
+	lastModified := rec.Header().Get("Last-Modified")
+	if lastModified == "" {
+		t.Fatal("Expected non-empty Last-Modified")
+	}
+
+	if rec = send(map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Fatalf("Expected status 304 for If-None-Match, got %d", rec.Code)
+	}
+
+	if rec = send(map[string]string{"If-Modified-Since": lastModified}); rec.Code != http.StatusNotModified {
+		t.Fatalf("Expected status 304 for If-Modified-Since, got %d", rec.Code)
+	}
+
+	// replace the file content
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	if err := fsys.Upload([]byte("changed"), fileKey); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = send(map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 after the file change, got %d", rec.Code)
+	}
+	if rec.Body.String() != "changed" {
+		t.Fatalf("Expected the changed file content, got %q", rec.Body.String())
+	}
+	if v := rec.Header().Get("ETag"); v == "" || v == etag {
+		t.Fatalf("Expected new ETag, got %q", v)
+	}
+}
+
 func TestConcurrentThumbsGeneration(t *testing.T) {
 	t.Parallel()
 
@@ -472,3 +543,226 @@ func TestConcurrentThumbsGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestFileDownloadSignedURL(t *testing.T) {
+	t.Parallel()
+
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// note: the SAS urls are signed locally so no requests to Azure are made
+	// (the Azure Blob files streaming is not tested to avoid network calls)
+	app.Settings().AzureBlob.Enabled = true
+	app.Settings().AzureBlob.Account = "test"
+	app.Settings().AzureBlob.Key = "dGVzdA=="
+	app.Settings().AzureBlob.Container = "test_container"
+
+	send := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	fileUrl := "/api/files/_pb_users_auth_/oap640cot4yru2s/test_kfd2wYLxkz.txt"
+
+	t.Run("disabled Azure Blob storage", func(t *testing.T) {
+		app.Settings().AzureBlob.Enabled = false
+		app.Settings().AzureBlob.ServeSignedURLs = true
+		defer func() {
+			app.Settings().AzureBlob.Enabled = true
+		}()
+
+		if rec := send(fileUrl); rec.Code != http.StatusOK {
+			t.Fatalf("Expected the file to be streamed from the local storage, got %d", rec.Code)
+		}
+	})
+
+	t.Run("enabled signed urls", func(t *testing.T) {
+		app.Settings().AzureBlob.ServeSignedURLs = true
+
+		rec := send(fileUrl)
+		if rec.Code != http.StatusTemporaryRedirect {
+			t.Fatalf("Expected status 307, got %d", rec.Code)
+		}
+
+		location := rec.Header().Get("Location")
+		expectedParts := []string{
+			"https://test.blob.core.windows.net/test_container/_pb_users_auth_%2Foap640cot4yru2s%2Ftest_kfd2wYLxkz.txt?",
+			"sp=r",
+			"sig=",
+		}
+		for _, part := range expectedParts {
+			if !strings.Contains(location, part) {
+				t.Fatalf("Expected %q in the signed url, got %q", part, location)
+			}
+		}
+	})
+
+	t.Run("enabled signed urls with S3 storage", func(t *testing.T) {
+		app.Settings().AzureBlob.ServeSignedURLs = true
+		app.Settings().S3.Enabled = true
+		defer func() {
+			app.Settings().S3.Enabled = false
+		}()
+
+		if rec := send(fileUrl); rec.Code == http.StatusTemporaryRedirect {
+			t.Fatalf("Expected no redirect, got %v", rec.Header().Get("Location"))
+		}
+	})
+}
+
+func TestFileDownloadThumbFormats(t *testing.T) {
+	t.Parallel()
+
+	fileUrl := "/api/files/_pb_users_auth_/4q1xlclmfloku33/300_1SEi6Q6U72.png"
+	thumbsDir := "_pb_users_auth_/4q1xlclmfloku33/thumbs_300_1SEi6Q6U72.png/"
+
+	checkHeaders := func(contentType string, vary string) func(t *testing.T, app *tests.TestApp, res *http.Response) {
+		return func(t *testing.T, app *tests.TestApp, res *http.Response) {
+			if v := res.Header.Get("Content-Type"); v != contentType {
+				t.Fatalf("Expected Content-Type %q, got %q", contentType, v)
+			}
+
+			if v := res.Header.Get("Vary"); !strings.Contains(v, vary) {
+				t.Fatalf("Expected Vary to contain %q, got %q", vary, v)
+			}
+		}
+	}
+
+	checkThumbExists := func(t *testing.T, app *tests.TestApp, key string, expectedContentType string) {
+		fsys, err := app.NewFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		attrs, err := fsys.Attributes(thumbsDir + key)
+		if err != nil {
+			t.Fatalf("Expected thumb %q to exist, got %v", key, err)
+		}
+
+		if attrs.ContentType != expectedContentType {
+			t.Fatalf("Expected thumb %q content type %q, got %q", key, expectedContentType, attrs.ContentType)
+		}
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "explicit webp suffix",
+			Method:          http.MethodGet,
+			Url:             fileUrl + "?thumb=70x50f.webp",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"RIFF", "WEBP"},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				checkHeaders("image/webp", "")(t, app, res)
+				checkThumbExists(t, app, "70x50f_300_1SEi6Q6U72.webp", "image/webp")
+			},
+		},
+		{
+			Name:            "explicit webp suffix with not allowed thumb size",
+			Method:          http.MethodGet,
+			Url:             fileUrl + "?thumb=999x999.webp",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"PNG"},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: checkHeaders("image/png", ""),
+		},
+		{
+			Name:            "explicit suffix without registered encoder (fallback to the default thumb)",
+			Method:          http.MethodGet,
+			Url:             fileUrl + "?thumb=70x50.avif",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"PNG"},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: checkHeaders("image/png", ""),
+		},
+		{
+			Name:   "negotiated webp with the Accept header",
+			Method: http.MethodGet,
+			Url:    fileUrl + "?thumb=70x50",
+			RequestHeaders: map[string]string{
+				"Accept": "image/avif,image/webp,image/apng,*/*;q=0.8",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"RIFF", "WEBP"},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				checkHeaders("image/webp", "Accept")(t, app, res)
+				checkThumbExists(t, app, "70x50_300_1SEi6Q6U72.webp", "image/webp")
+				// the regular thumb should remain unchanged
+				checkThumbExists(t, app, "70x50_300_1SEi6Q6U72.png", "image/png")
+			},
+		},
+		{
+			Name:   "explicitly rejected webp with the Accept header",
+			Method: http.MethodGet,
+			Url:    fileUrl + "?thumb=70x50",
+			RequestHeaders: map[string]string{
+				"Accept": "image/webp;q=0, */*",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"PNG"},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: checkHeaders("image/png", "Accept"),
+		},
+		{
+			Name:   "reuse of the cached format variant",
+			Method: http.MethodGet,
+			Url:    fileUrl + "?thumb=70x50t",
+			RequestHeaders: map[string]string{
+				"Accept": "image/webp",
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				fsys, err := app.NewFilesystem()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer fsys.Close()
+
+				if err := fsys.Upload([]byte("cached_variant"), thumbsDir+"70x50t_300_1SEi6Q6U72.webp"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"cached_variant"},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:            "non-image file with webp suffix (fallback to the original)",
+			Method:          http.MethodGet,
+			Url:             "/api/files/_pb_users_auth_/oap640cot4yru2s/test_kfd2wYLxkz.txt?thumb=100x100.webp",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test"},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+			AfterTestFunc: checkHeaders("text/plain; charset=utf-8", ""),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
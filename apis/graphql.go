@@ -0,0 +1,639 @@
+package apis
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/graphql"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+const graphqlTypenameField = "__typename"
+
+var graphqlNameRegex = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// graphqlListArgs lists the supported collection query arguments
+// and their corresponding GraphQL input types.
+var graphqlListArgs = map[string]string{
+	search.FilterQueryParam:    "String",
+	search.SortQueryParam:      "String",
+	search.PageQueryParam:      "Int",
+	search.PerPageQueryParam:   "Int",
+	search.SkipTotalQueryParam: "Boolean",
+}
+
+// bindGraphQLApi registers the read-only GraphQL api endpoints.
+func bindGraphQLApi(app core.App, rg *echo.Group) {
+	api := graphqlApi{app: app}
+
+	subGroup := rg.Group("/graphql")
+	subGroup.POST("", api.query)
+	subGroup.GET("/schema", api.schema)
+}
+
+type graphqlApi struct {
+	app core.App
+}
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// query executes a read-only GraphQL query against the collections.
+//
+// Each collection is exposed as a root query field that accepts the same
+// filter, sort and pagination arguments as the records list api and
+// resolves its relation fields through the records expand.
+//
+// The collection list and view API rules and the field view rules
+// are enforced the same way as in the records list api.
+func (api *graphqlApi) query(c echo.Context) error {
+	req := new(graphqlRequest)
+	if err := c.Bind(req); err != nil {
+		return NewBadRequestError("Failed to load the submitted data.", err)
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return graphqlErrorResponse(c, "Missing query document.")
+	}
+
+	doc, err := graphql.Parse(req.Query)
+	if err != nil {
+		return graphqlErrorResponse(c, err.Error())
+	}
+
+	op, err := doc.Operation(req.OperationName)
+	if err != nil {
+		return graphqlErrorResponse(c, err.Error())
+	}
+
+	if op.Type != "query" {
+		return graphqlErrorResponse(c, "Only query operations are supported.")
+	}
+
+	variables, err := op.VariableValues(req.Variables)
+	if err != nil {
+		return graphqlErrorResponse(c, err.Error())
+	}
+
+	collections, err := graphqlCollections(api.app.Dao())
+	if err != nil {
+		return NewBadRequestError("Failed to load the collections.", err)
+	}
+
+	executor := &graphqlExecutor{
+		app:         api.app,
+		c:           c,
+		dao:         RequestDao(api.app, c),
+		requestInfo: RequestInfo(c),
+		variables:   variables,
+		collections: collections,
+	}
+
+	data := make(map[string]any, len(op.Fields))
+	errs := []graphqlError{}
+
+	for _, field := range op.Fields {
+		key := field.ResponseKey()
+
+		if field.Name == graphqlTypenameField {
+			data[key] = "Query"
+			continue
+		}
+
+		result, err := executor.resolveList(field)
+		if err != nil {
+			data[key] = nil
+			errs = append(errs, graphqlError{Message: err.Error(), Path: []any{key}})
+			continue
+		}
+
+		data[key] = result
+	}
+
+	response := map[string]any{"data": data}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// schema returns the GraphQL schema (in SDL format) of the current collections.
+//
+// Non-admin requests receive only the collections that are
+// not restricted to admins.
+func (api *graphqlApi) schema(c echo.Context) error {
+	collections, err := graphqlCollections(api.app.Dao())
+	if err != nil {
+		return NewBadRequestError("Failed to load the collections.", err)
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+
+	return c.String(http.StatusOK, NewGraphQLSchema(collections, admin != nil))
+}
+
+// graphqlErrorResponse writes a GraphQL request error response.
+//
+// Similar to the field errors, the request errors are
+// sent with 200 status code as part of the response body.
+func graphqlErrorResponse(c echo.Context, message string) error {
+	return c.JSON(http.StatusOK, map[string]any{
+		"errors": []graphqlError{{Message: message}},
+	})
+}
+
+// graphqlCollections returns all collections with GraphQL compatible names.
+func graphqlCollections(dao *daos.Dao) ([]*models.Collection, error) {
+	collections := []*models.Collection{}
+	if err := dao.CollectionQuery().OrderBy("created ASC").All(&collections); err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Collection, 0, len(collections))
+	for _, collection := range collections {
+		if graphqlNameRegex.MatchString(collection.Name) && !strings.HasPrefix(collection.Name, "__") {
+			result = append(result, collection)
+		}
+	}
+
+	return result, nil
+}
+
+// NewGraphQLSchema generates the GraphQL schema (in SDL format)
+// of the provided collections.
+//
+// If includeAdminOnly is false, the collections with admin only
+// (aka. nil) list API rule are omitted.
+func NewGraphQLSchema(collections []*models.Collection, includeAdminOnly bool) string {
+	visible := map[string]*models.Collection{}
+	for _, collection := range collections {
+		if includeAdminOnly || collection.ListRule != nil {
+			visible[collection.Id] = collection
+		}
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("scalar JSON\n\ntype Query {\n")
+	for _, collection := range collections {
+		if visible[collection.Id] == nil {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			"  %s(filter: String, sort: String, page: Int, perPage: Int, skipTotal: Boolean): %sList!\n",
+			collection.Name,
+			collection.Name,
+		))
+	}
+	sb.WriteString("}\n")
+
+	for _, collection := range collections {
+		if visible[collection.Id] == nil {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			"\ntype %[1]sList {\n  page: Int!\n  perPage: Int!\n  totalItems: Int!\n  totalPages: Int!\n  items: [%[1]sRecord!]!\n}\n",
+			collection.Name,
+		))
+
+		sb.WriteString(fmt.Sprintf("\ntype %sRecord {\n", collection.Name))
+		for _, name := range graphqlSystemFields(collection) {
+			typ := "String"
+			switch name {
+			case schema.FieldNameId, schema.FieldNameCollectionId, schema.FieldNameCollectionName:
+				typ = "String!"
+			case schema.FieldNameEmailVisibility, schema.FieldNameVerified:
+				typ = "Boolean"
+			}
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", name, typ))
+		}
+		for _, field := range collection.Schema.Fields() {
+			if !graphqlNameRegex.MatchString(field.Name) {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", field.Name, graphqlFieldType(field, visible)))
+		}
+		sb.WriteString("}\n")
+	}
+
+	return sb.String()
+}
+
+// graphqlFieldType returns the GraphQL type of a single collection field.
+//
+// The relation fields to collections that are not in the visible
+// map are described with their related record ids.
+func graphqlFieldType(field *schema.SchemaField, visible map[string]*models.Collection) string {
+	field.InitOptions()
+
+	typ := "String"
+	multiple := false
+
+	switch field.Type {
+	case schema.FieldTypeNumber:
+		typ = "Float"
+		if options, ok := field.Options.(*schema.NumberOptions); ok && options.NoDecimal {
+			typ = "Int"
+		}
+	case schema.FieldTypeBool:
+		typ = "Boolean"
+	case schema.FieldTypeJson, schema.FieldTypeComputed:
+		typ = "JSON"
+	case schema.FieldTypeSelect, schema.FieldTypeFile, schema.FieldTypeRelation:
+		if options, ok := field.Options.(schema.MultiValuer); ok {
+			multiple = options.IsMultiple()
+		}
+		if options, ok := field.Options.(*schema.RelationOptions); ok {
+			if target := visible[options.CollectionId]; target != nil {
+				typ = target.Name + "Record"
+			}
+		}
+	}
+
+	if multiple {
+		return "[" + typ + "!]"
+	}
+
+	return typ
+}
+
+// graphqlSystemFields returns the names of the collection system
+// fields that are available for querying.
+func graphqlSystemFields(collection *models.Collection) []string {
+	names := []string{
+		schema.FieldNameId,
+		schema.FieldNameCollectionId,
+		schema.FieldNameCollectionName,
+		schema.FieldNameCreated,
+		schema.FieldNameUpdated,
+	}
+
+	if collection.IsAuth() {
+		names = append(names,
+			schema.FieldNameUsername,
+			schema.FieldNameEmail,
+			schema.FieldNameEmailVisibility,
+			schema.FieldNameVerified,
+		)
+	}
+
+	return names
+}
+
+// -------------------------------------------------------------------
+
+type graphqlExecutor struct {
+	app         core.App
+	c           echo.Context
+	dao         *daos.Dao
+	requestInfo *models.RequestInfo
+	variables   map[string]any
+	collections []*models.Collection
+}
+
+func (e *graphqlExecutor) findCollection(fn func(c *models.Collection) bool) *models.Collection {
+	for _, collection := range e.collections {
+		if fn(collection) {
+			return collection
+		}
+	}
+
+	return nil
+}
+
+// resolveList resolves a single collection root query field.
+func (e *graphqlExecutor) resolveList(field *graphql.Field) (map[string]any, error) {
+	collection := e.findCollection(func(c *models.Collection) bool {
+		return c.Name == field.Name
+	})
+	if collection == nil {
+		return nil, fmt.Errorf("Cannot query field %q on type \"Query\".", field.Name)
+	}
+
+	if len(field.Fields) == 0 {
+		return nil, fmt.Errorf("Field %q must have a selection of subfields.", field.Name)
+	}
+
+	params, err := e.listParams(field)
+	if err != nil {
+		return nil, err
+	}
+
+	expands, err := e.listExpands(collection, field.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkApiKeyScope(e.requestInfo.ApiKey, collection, models.ApiKeyScopeRead); err != nil {
+		return nil, fmt.Errorf("The api key is not allowed to query %q.", collection.Name)
+	}
+
+	isAdmin := e.requestInfo.Admin != nil
+	listRule := collection.TenantScopedRule(collection.ListRule)
+
+	// forbid users and guests to query special filter/sort fields
+	if !isAdmin {
+		for _, param := range ruleQueryParams {
+			for _, ruleField := range adminOnlyRuleFields {
+				if strings.Contains(params.Get(param), ruleField) {
+					return nil, fmt.Errorf("Only admins can filter by %s", ruleField)
+				}
+			}
+		}
+	}
+
+	if !isAdmin && listRule == nil {
+		// only admins can access if the rule is nil
+		return nil, fmt.Errorf("Only admins can query %q.", collection.Name)
+	}
+
+	maxDepth := e.app.Settings().Meta.MaxExpandDepth
+	if maxDepth <= 0 {
+		maxDepth = daos.MaxExpandDepth
+	}
+	for _, path := range expands {
+		if strings.Count(path, ".")+1 > maxDepth {
+			return nil, fmt.Errorf("The relation %q exceeds the max allowed nesting depth of %d.", path, maxDepth)
+		}
+	}
+	e.c.Set(contextExpandMaxDepthKey, maxDepth)
+
+	fieldsResolver := resolvers.NewRecordFieldResolver(
+		e.dao,
+		collection,
+		e.requestInfo,
+		// hidden fields are searchable only by admins
+		isAdmin,
+	)
+
+	query := e.dao.RecordQuery(collection)
+
+	if err := triggerRecordsQueryHook(e.app, e.c, e.requestInfo, collection, query, core.RecordsQueryActionList); err != nil {
+		return nil, err
+	}
+
+	searchProvider := search.NewProvider(fieldsResolver).Query(query)
+
+	if !isAdmin && listRule != nil {
+		searchProvider.AddFilter(search.FilterData(*listRule))
+	}
+
+	records := []*models.Record{}
+
+	result, err := searchProvider.ParseAndExec(params.Encode(), &records)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query %q: %w", collection.Name, err)
+	}
+
+	if err := EnrichRecords(e.c, e.dao, records, expands...); err != nil {
+		e.app.Logger().Debug("Failed to enrich graphql records", slog.String("error", err.Error()))
+	}
+
+	if err := triggerRecordEnrichHook(e.app, e.c, records); err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any, len(field.Fields))
+
+	for _, f := range field.Fields {
+		switch f.Name {
+		case graphqlTypenameField:
+			data[f.ResponseKey()] = collection.Name + "List"
+		case "page":
+			data[f.ResponseKey()] = result.Page
+		case "perPage":
+			data[f.ResponseKey()] = result.PerPage
+		case "totalItems":
+			data[f.ResponseKey()] = result.TotalItems
+		case "totalPages":
+			data[f.ResponseKey()] = result.TotalPages
+		case "items":
+			items := make([]any, len(records))
+			for i, record := range records {
+				items[i] = e.projectRecord(record, f.Fields)
+			}
+			data[f.ResponseKey()] = items
+		}
+	}
+
+	return data, nil
+}
+
+// listParams converts the collection query field arguments
+// into records list query params.
+func (e *graphqlExecutor) listParams(field *graphql.Field) (url.Values, error) {
+	args, err := field.ArgumentValues(e.variables)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+
+	for name, value := range args {
+		typ, ok := graphqlListArgs[name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown argument %q on field %q.", name, field.Name)
+		}
+
+		if value == nil {
+			continue
+		}
+
+		var raw string
+
+		switch v := value.(type) {
+		case string:
+			if typ == "String" {
+				raw = v
+			}
+		case bool:
+			if typ == "Boolean" {
+				raw = strconv.FormatBool(v)
+			}
+		case int64:
+			if typ == "Int" {
+				raw = strconv.FormatInt(v, 10)
+			}
+		case float64:
+			// json decoded variables
+			if typ == "Int" && v == float64(int64(v)) {
+				raw = strconv.FormatInt(int64(v), 10)
+			}
+		}
+
+		if raw == "" {
+			return nil, fmt.Errorf("Argument %q on field %q must be of type %s.", name, field.Name, typ)
+		}
+
+		params.Set(name, raw)
+	}
+
+	return params, nil
+}
+
+// listExpands validates the items selection set of a collection query field
+// and returns the relation expand paths required for its resolution.
+func (e *graphqlExecutor) listExpands(collection *models.Collection, fields []*graphql.Field) ([]string, error) {
+	expands := []string{}
+
+	for _, f := range fields {
+		switch f.Name {
+		case graphqlTypenameField, "page", "perPage", "totalItems", "totalPages":
+			if len(f.Fields) > 0 {
+				return nil, fmt.Errorf("Field %q must not have a selection since it is a scalar.", f.Name)
+			}
+		case "items":
+			if len(f.Fields) == 0 {
+				return nil, fmt.Errorf("Field %q must have a selection of subfields.", f.Name)
+			}
+
+			paths, err := e.recordExpands(collection, f.Fields, "")
+			if err != nil {
+				return nil, err
+			}
+			expands = list.ToUniqueStringSlice(append(expands, paths...))
+		default:
+			return nil, fmt.Errorf("Cannot query field %q on type \"%sList\".", f.Name, collection.Name)
+		}
+	}
+
+	return expands, nil
+}
+
+// recordExpands validates the record selection set of the provided
+// collection and returns the relation expand paths required for its resolution.
+func (e *graphqlExecutor) recordExpands(collection *models.Collection, fields []*graphql.Field, prefix string) ([]string, error) {
+	systemFields := graphqlSystemFields(collection)
+
+	expands := []string{}
+
+	for _, f := range fields {
+		if f.Name == graphqlTypenameField || list.ExistInSlice(f.Name, systemFields) {
+			if len(f.Fields) > 0 {
+				return nil, fmt.Errorf("Field %q must not have a selection since it is a scalar.", f.Name)
+			}
+			continue
+		}
+
+		schemaField := collection.Schema.GetFieldByName(f.Name)
+		if schemaField == nil {
+			return nil, fmt.Errorf("Cannot query field %q on type \"%sRecord\".", f.Name, collection.Name)
+		}
+
+		if len(f.Fields) == 0 {
+			continue // plain field value
+		}
+
+		schemaField.InitOptions()
+		options, _ := schemaField.Options.(*schema.RelationOptions)
+		if schemaField.Type != schema.FieldTypeRelation || options == nil {
+			return nil, fmt.Errorf("Field %q must not have a selection since it is not a relation.", f.Name)
+		}
+
+		relCollection := e.findCollection(func(c *models.Collection) bool {
+			return c.Id == options.CollectionId
+		})
+		if relCollection == nil {
+			return nil, fmt.Errorf("Cannot resolve the related collection of field %q.", f.Name)
+		}
+
+		path := prefix + f.Name
+
+		nested, err := e.recordExpands(relCollection, f.Fields, path+".")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(nested) == 0 {
+			expands = append(expands, path)
+		} else {
+			expands = append(expands, nested...)
+		}
+	}
+
+	return expands, nil
+}
+
+// projectRecord returns the selected fields of the provided record.
+//
+// The fields hidden by the record enrichment (eg. field view rules
+// or email visibility) and the relations that were not expanded
+// (eg. due to the related collection view rule) are resolved as null.
+func (e *graphqlExecutor) projectRecord(record *models.Record, fields []*graphql.Field) map[string]any {
+	exported := record.PublicExport()
+	expanded := record.Expand()
+
+	result := make(map[string]any, len(fields))
+
+	for _, f := range fields {
+		key := f.ResponseKey()
+
+		if f.Name == graphqlTypenameField {
+			result[key] = record.Collection().Name + "Record"
+			continue
+		}
+
+		if len(f.Fields) == 0 {
+			result[key] = exported[f.Name]
+			continue
+		}
+
+		if _, ok := exported[f.Name]; !ok {
+			result[key] = nil // hidden relation field
+			continue
+		}
+
+		var isMultiple bool
+		if schemaField := record.Collection().Schema.GetFieldByName(f.Name); schemaField != nil {
+			if options, ok := schemaField.Options.(schema.MultiValuer); ok {
+				isMultiple = options.IsMultiple()
+			}
+		}
+
+		switch v := expanded[f.Name].(type) {
+		case *models.Record:
+			if isMultiple {
+				result[key] = []any{e.projectRecord(v, f.Fields)}
+			} else {
+				result[key] = e.projectRecord(v, f.Fields)
+			}
+		case []*models.Record:
+			items := make([]any, len(v))
+			for i, rel := range v {
+				items[i] = e.projectRecord(rel, f.Fields)
+			}
+			result[key] = items
+		default:
+			if isMultiple {
+				result[key] = []any{}
+			} else {
+				result[key] = nil
+			}
+		}
+	}
+
+	return result
+}
@@ -1,10 +1,18 @@
 package apis
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/migrations/logs"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/migrate"
 )
 
 // bindHealthApi registers the health api endpoint.
@@ -14,6 +22,7 @@ func bindHealthApi(app core.App, rg *echo.Group) {
 	subGroup := rg.Group("/health")
 	subGroup.HEAD("", api.healthCheck)
 	subGroup.GET("", api.healthCheck)
+	subGroup.GET("/ready", api.readyCheck)
 }
 
 type healthApi struct {
@@ -41,3 +50,121 @@ func (api *healthApi) healthCheck(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, resp)
 }
+
+type readyCheckResponse struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    struct {
+		Ok                     bool            `json:"ok"`
+		Checks                 map[string]bool `json:"checks"`
+		Failing                []string        `json:"failing"`
+		TotalPendingMigrations int             `json:"totalPendingMigrations"`
+
+		// admins only
+		Version           string   `json:"version,omitempty"`
+		PendingMigrations []string `json:"pendingMigrations,omitempty"`
+	} `json:"data"`
+}
+
+// readyCheck verifies that the app dependencies (db, storage, migrations)
+// are available and returns 200 OK or 503 with the list of the failing checks.
+//
+// The check errors are only logged since the endpoint is public
+// and the app version and the pending migration names are returned only to the admins.
+func (api *healthApi) readyCheck(c echo.Context) error {
+	resp := new(readyCheckResponse)
+	resp.Data.Checks = map[string]bool{}
+	resp.Data.Failing = []string{}
+
+	pendingMigrations := []string{}
+
+	check := func(name string, err error) {
+		resp.Data.Checks[name] = err == nil
+
+		if err != nil {
+			resp.Data.Failing = append(resp.Data.Failing, name)
+			api.app.Logger().Warn(
+				"Readiness check failure",
+				slog.String("check", name),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	check("database", api.pingDB(api.app.DB()))
+	check("logsDatabase", api.pingDB(api.app.LogsDB()))
+	check("storage", api.checkStorage())
+
+	check("migrations", api.checkMigrations(&pendingMigrations))
+
+	resp.Data.Ok = len(resp.Data.Failing) == 0
+	resp.Data.TotalPendingMigrations = len(pendingMigrations)
+
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		resp.Data.Version = api.app.Version()
+		resp.Data.PendingMigrations = pendingMigrations
+	}
+
+	if resp.Data.Ok {
+		resp.Code = http.StatusOK
+		resp.Message = "API is ready."
+	} else {
+		resp.Code = http.StatusServiceUnavailable
+		resp.Message = "API is not ready."
+	}
+
+	return c.JSON(resp.Code, resp)
+}
+
+func (api *healthApi) pingDB(db *dbx.DB) error {
+	var one int
+
+	return db.NewQuery("SELECT 1").Row(&one)
+}
+
+func (api *healthApi) checkStorage() error {
+	fsys, err := api.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	ok, err := fsys.IsAccessible()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("the storage bucket doesn't exist")
+	}
+
+	return nil
+}
+
+func (api *healthApi) checkMigrations(pending *[]string) error {
+	connections := []migrationsConnection{
+		{
+			DB:             api.app.DB(),
+			MigrationsList: migrations.AppMigrations,
+		},
+		{
+			DB:             api.app.LogsDB(),
+			MigrationsList: logs.LogsMigrations,
+		},
+	}
+
+	for _, c := range connections {
+		runner, err := migrate.NewRunner(c.DB, c.MigrationsList)
+		if err != nil {
+			return err
+		}
+
+		*pending = append(*pending, runner.Pending()...)
+	}
+
+	if len(*pending) > 0 {
+		return fmt.Errorf("%d pending migration(s)", len(*pending))
+	}
+
+	return nil
+}
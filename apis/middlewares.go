@@ -1,19 +1,25 @@
 package apis
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/settings"
 	"github.com/pocketbase/pocketbase/tokens"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/ratelimit"
 	"github.com/pocketbase/pocketbase/tools/routine"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/spf13/cast"
@@ -25,8 +31,28 @@ const (
 	ContextAuthRecordKey string = "authRecord"
 	ContextCollectionKey string = "collection"
 	ContextExecStartKey  string = "execStart"
+
+	// ContextImpersonatorKey stores the admin that issued the
+	// impersonation token of the current request auth record (if any).
+	ContextImpersonatorKey string = "impersonator"
+
+	// ContextApiKeyKey stores the [models.ApiKey] of the current
+	// request (if authenticated with an api key instead of a token).
+	ContextApiKeyKey string = "apiKey"
+
+	// ContextRemoteIpKey stores the resolved client IP of the current
+	// request based on the app trusted proxy settings (see [RealIp]).
+	ContextRemoteIpKey string = "remoteIp"
+
+	// ContextMaxBodySizeKey stores the max allowed request body size
+	// (in bytes) applied by the [BodyLimit] middleware.
+	ContextMaxBodySizeKey string = "maxBodySize"
 )
 
+// DefaultMaxBodySize is the default max allowed request body size in bytes
+// applied to all routes that don't specify their own [BodyLimit].
+var DefaultMaxBodySize int64 = 32 << 20 // 32mb
+
 // RequireGuestOnly middleware requires a request to NOT have a valid
 // Authorization header.
 //
@@ -217,45 +243,125 @@ func RequireAdminOrOwnerAuth(ownerIdParam string) echo.MiddlewareFunc {
 func LoadAuthContext(app core.App) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if key := c.Request().Header.Get("X-Api-Key"); key != "" {
+				loadApiKey(app, c, key)
+				return next(c)
+			}
+
 			token := c.Request().Header.Get("Authorization")
 			if token == "" {
 				return next(c)
 			}
 
-			// the schema is not required and it is only for
-			// compatibility with the defaults of some HTTP clients
-			token = strings.TrimPrefix(token, "Bearer ")
+			loadAuthToken(app, c, token)
+
+			return next(c)
+		}
+	}
+}
+
+// loadAuthToken loads the token related record or admin instance into the request's context.
+//
+// Invalid or expired tokens are ignored.
+func loadAuthToken(app core.App, c echo.Context, token string) {
+	// the schema is not required and it is only for
+	// compatibility with the defaults of some HTTP clients
+	token = strings.TrimPrefix(token, "Bearer ")
+
+	if strings.HasPrefix(token, models.ApiKeyPrefix) {
+		loadApiKey(app, c, token)
+		return
+	}
 
-			claims, _ := security.ParseUnverifiedJWT(token)
-			tokenType := cast.ToString(claims["type"])
+	claims, _ := security.ParseUnverifiedJWT(token)
+	tokenType := cast.ToString(claims["type"])
 
-			switch tokenType {
-			case tokens.TypeAdmin:
-				admin, err := app.Dao().FindAdminByToken(
-					token,
-					app.Settings().AdminAuthToken.Secret,
-				)
-				if err == nil && admin != nil {
-					c.Set(ContextAdminKey, admin)
-				}
-			case tokens.TypeAuthRecord:
-				record, err := app.Dao().FindAuthRecordByToken(
-					token,
-					app.Settings().RecordAuthToken.Secret,
-				)
-				if err == nil && record != nil {
-					c.Set(ContextAuthRecordKey, record)
-				}
+	switch tokenType {
+	case tokens.TypeAdmin:
+		admin, err := app.Dao().FindAdminByToken(
+			token,
+			app.Settings().AdminAuthToken.Secret,
+		)
+		if err == nil && admin != nil {
+			c.Set(ContextAdminKey, admin)
+		}
+	case tokens.TypeAuthRecord:
+		// impersonation tokens are signed with a different key so a
+		// regular auth token can't be forged into one (and vice versa)
+		if cast.ToString(claims["impersonatedBy"]) != "" {
+			record, admin, err := app.Dao().FindAuthRecordByImpersonateToken(
+				token,
+				app.Settings().RecordAuthToken.Secret,
+			)
+			if err == nil && record != nil && admin != nil {
+				c.Set(ContextAuthRecordKey, record)
+				c.Set(ContextImpersonatorKey, admin)
 			}
+			return
+		}
 
-			return next(c)
+		record, err := app.Dao().FindAuthRecordByToken(
+			token,
+			app.Settings().RecordAuthToken.Secret,
+		)
+		if err == nil && record != nil {
+			c.Set(ContextAuthRecordKey, record)
 		}
 	}
 }
 
+// loadApiKey loads the api key model into the request's context.
+//
+// Invalid or expired api keys are ignored.
+func loadApiKey(app core.App, c echo.Context, key string) {
+	apiKey, err := app.Dao().FindApiKeyByKey(key)
+	if err == nil && apiKey != nil {
+		c.Set(ContextApiKeyKey, apiKey)
+	}
+}
+
+// checkApiKeyAccess checks whether the request api key (if any) is allowed
+// to access the provided collection with the request method operation
+// (GET and HEAD requests require the "read" scope, all others - "write").
+func checkApiKeyAccess(c echo.Context, collection *models.Collection) error {
+	apiKey, _ := c.Get(ContextApiKeyKey).(*models.ApiKey)
+
+	scope := models.ApiKeyScopeWrite
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead:
+		scope = models.ApiKeyScopeRead
+	}
+
+	return checkApiKeyScope(apiKey, collection, scope)
+}
+
+// checkApiKeyScope checks whether the api key (if any) is scoped
+// to the provided collection and has the specified scope.
+//
+// It is used by all transports that access the collection records
+// (the records api, batch, GraphQL and realtime).
+func checkApiKeyScope(apiKey *models.ApiKey, collection *models.Collection, scope string) error {
+	if apiKey == nil {
+		return nil
+	}
+
+	if !apiKey.CanAccessCollection(collection) {
+		return NewForbiddenError("The api key is not allowed to access the collection.", nil)
+	}
+
+	if !apiKey.HasScope(scope) {
+		return NewForbiddenError(fmt.Sprintf("The api key is missing the %q scope.", scope), nil)
+	}
+
+	return nil
+}
+
 // LoadCollectionContext middleware finds the collection with related
 // path identifier and loads it into the request context.
 //
+// If the request is authenticated with an api key, the key must
+// be also scoped to the found collection and request operation.
+//
 // Set optCollectionTypes to further filter the found collection by its type.
 func LoadCollectionContext(app core.App, optCollectionTypes ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -270,6 +376,10 @@ func LoadCollectionContext(app core.App, optCollectionTypes ...string) echo.Midd
 					return NewBadRequestError("Unsupported collection type.", nil)
 				}
 
+				if err := checkApiKeyAccess(c, collection); err != nil {
+					return err
+				}
+
 				c.Set(ContextCollectionKey, collection)
 			}
 
@@ -278,6 +388,232 @@ func LoadCollectionContext(app core.App, optCollectionTypes ...string) echo.Midd
 	}
 }
 
+// RateLimitOptions defines the [RateLimit] middleware options.
+type RateLimitOptions struct {
+	// MaxRequests is the max number of allowed requests per Interval (aka. the max burst size).
+	MaxRequests int
+
+	// Interval is the duration in which MaxRequests tokens are gradually refilled.
+	Interval time.Duration
+
+	// ByAuth keys the authenticated requests by the admin or auth record id
+	// instead of the client IP (the guest requests are still keyed by the client IP).
+	ByAuth bool
+
+	// TrustedProxyHeaders is an optional list of headers to read the client IP from
+	// (eg. "CF-Connecting-IP", "X-Forwarded-For"), in the order of their precedence.
+	//
+	// For comma separated lists (eg. X-Forwarded-For) the last (aka. rightmost) ip is used
+	// since it is the one appended by the trusted proxy.
+	//
+	// If empty or none of the headers is set, the client IP resolved from the
+	// app trusted proxy settings is used (see [RealIp]).
+	// Set it only if the app is behind a reverse proxy that overwrites the listed headers!
+	TrustedProxyHeaders []string
+
+	// Skipper is an optional function to skip the rate limit check for specific requests.
+	Skipper func(c echo.Context) bool
+}
+
+// RateLimit middleware limits the number of the allowed requests per
+// client IP (or per authenticated identity if opts.ByAuth is set)
+// responding with 429 and a Retry-After header when the limit is exceeded.
+//
+// Each RateLimit call has its own in-memory limiter so you can attach
+// it with different options to different route groups, eg.:
+//
+//	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+//		// max 5 auth requests per minute
+//		e.Router.Use(apis.RateLimit(apis.RateLimitOptions{
+//			MaxRequests: 5,
+//			Interval:    time.Minute,
+//			Skipper: func(c echo.Context) bool {
+//				return !strings.Contains(c.Request().URL.Path, "/auth-with-")
+//			},
+//		}))
+//
+//		// max 100 requests per minute per user for the custom routes
+//		e.Router.Group("/api/custom", apis.RateLimit(apis.RateLimitOptions{
+//			MaxRequests: 100,
+//			Interval:    time.Minute,
+//			ByAuth:      true,
+//		}))
+//
+//		return nil
+//	})
+func RateLimit(opts RateLimitOptions) echo.MiddlewareFunc {
+	limiter := ratelimit.New(opts.MaxRequests, opts.Interval)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			ok, retryAfter := limiter.Allow(rateLimitKey(c, opts))
+			if !ok {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+
+				c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+
+				return NewApiError(http.StatusTooManyRequests, "Too many requests.", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// rateLimitKey returns the rate limit bucket key of the current request.
+func rateLimitKey(c echo.Context, opts RateLimitOptions) string {
+	if opts.ByAuth {
+		if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+			return "admin:" + admin.Id
+		}
+
+		if record, _ := c.Get(ContextAuthRecordKey).(*models.Record); record != nil {
+			return "record:" + record.Collection().Id + ":" + record.Id
+		}
+	}
+
+	return "ip:" + trustedUserIp(c, opts.TrustedProxyHeaders)
+}
+
+// trustedUserIp returns the client IP from the first non-empty trusted header
+// (or the resolved request client IP if none is found, see [RealIp]).
+func trustedUserIp(c echo.Context, trustedHeaders []string) string {
+	for _, header := range trustedHeaders {
+		ips := strings.Split(c.Request().Header.Get(header), ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			if ip := strings.TrimSpace(ips[i]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	return RealIp(c)
+}
+
+// LoadRealIp middleware resolves the client IP of the current request
+// based on the app trusted proxy settings and stores it in the
+// request context (see [RealIp]).
+func LoadRealIp(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(ContextRemoteIpKey, app.Settings().TrustedProxy.ClientIp(c.Request()))
+
+			return next(c)
+		}
+	}
+}
+
+// RealIp returns the client IP of the current request resolved by
+// the [LoadRealIp] middleware (aka. based on the app trusted proxy settings).
+//
+// It fallbacks to the request remote address if the IP is not resolved yet.
+func RealIp(c echo.Context) string {
+	if ip, _ := c.Get(ContextRemoteIpKey).(string); ip != "" {
+		return ip
+	}
+
+	ip, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		return c.Request().RemoteAddr
+	}
+
+	return ip
+}
+
+// BodyLimit middleware limits the max allowed request body size (in bytes)
+// responding with 413 when the limit is exceeded.
+//
+// A route level BodyLimit overwrites the global [DefaultMaxBodySize] limit
+// (eg. to allow larger uploads). Set limitBytes <= 0 to disable the limit.
+//
+// The applied limit is stored in the request context under [ContextMaxBodySizeKey].
+func BodyLimit(limitBytes int64) echo.MiddlewareFunc {
+	return BodyLimitFunc(func(c echo.Context) int64 {
+		return limitBytes
+	})
+}
+
+// BodyLimitFunc is similar to [BodyLimit] but resolves the limit
+// dynamically for each request (eg. based on the loaded collection).
+func BodyLimitFunc(limitFunc func(c echo.Context) int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			if r.Body == nil {
+				return next(c)
+			}
+
+			// unwrap the previously applied limit (if not read yet) so that it can be overwritten
+			body := r.Body
+			if lb, ok := body.(*limitedBody); ok && !lb.touched {
+				body = lb.original
+			}
+
+			limit := limitFunc(c)
+
+			c.Set(ContextMaxBodySizeKey, limit)
+
+			if limit <= 0 {
+				r.Body = body
+				return next(c)
+			}
+
+			lb := &limitedBody{
+				original: body,
+				limited:  http.MaxBytesReader(c.Response(), body, limit),
+			}
+			r.Body = lb
+
+			err := next(c)
+
+			if err != nil && lb.exceeded {
+				return NewApiError(
+					http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("The request body is too large (max %d bytes).", limit),
+					nil,
+				)
+			}
+
+			return err
+		}
+	}
+}
+
+// limitedBody is a [http.MaxBytesReader] wrapper that keeps track
+// of the original request body and whether the limit was exceeded.
+type limitedBody struct {
+	original io.ReadCloser
+	limited  io.ReadCloser
+	touched  bool
+	exceeded bool
+}
+
+// Read implements [io.Reader] interface.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	b.touched = true
+
+	n, err := b.limited.Read(p)
+
+	var maxBytesErr *http.MaxBytesError
+	if err != nil && errors.As(err, &maxBytesErr) {
+		b.exceeded = true
+	}
+
+	return n, err
+}
+
+// Close implements [io.Closer] interface.
+func (b *limitedBody) Close() error {
+	return b.limited.Close()
+}
+
 // ActivityLogger middleware takes care to save the request information
 // into the logs database.
 //
@@ -298,11 +634,8 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 }
 
 func logRequest(app core.App, c echo.Context, err *ApiError) {
-	// no logs retention
-	if app.Settings().Logs.MaxDays == 0 {
-		return
-	}
-
+	// note: the logs retention (aka. Logs.MaxDays) is checked by the db logs
+	// handler to allow writing the request logs also to custom log handlers
 	attrs := make([]any, 0, 15)
 
 	attrs = append(attrs, slog.String("type", "request"))
@@ -349,7 +682,7 @@ func logRequest(app core.App, c echo.Context, err *ApiError) {
 		ip, _, _ := net.SplitHostPort(httpRequest.RemoteAddr)
 		attrs = append(
 			attrs,
-			slog.String("userIp", realUserIp(httpRequest, ip)),
+			slog.String("userIp", RealIp(c)),
 			slog.String("remoteIp", ip),
 		)
 	}
@@ -372,36 +705,6 @@ func logRequest(app core.App, c echo.Context, err *ApiError) {
 	})
 }
 
-// Returns the "real" user IP from common proxy headers (or fallbackIp if none is found).
-//
-// The returned IP value shouldn't be trusted if not behind a trusted reverse proxy!
-func realUserIp(r *http.Request, fallbackIp string) string {
-	if ip := r.Header.Get("CF-Connecting-IP"); ip != "" {
-		return ip
-	}
-
-	if ip := r.Header.Get("Fly-Client-IP"); ip != "" {
-		return ip
-	}
-
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return ip
-	}
-
-	if ipsList := r.Header.Get("X-Forwarded-For"); ipsList != "" {
-		// extract the first non-empty leftmost-ish ip
-		ips := strings.Split(ipsList, ",")
-		for _, ip := range ips {
-			ip = strings.TrimSpace(ip)
-			if ip != "" {
-				return ip
-			}
-		}
-	}
-
-	return fallbackIp
-}
-
 // @todo consider removing as this may no longer be needed due to the custom rest.MultiBinder.
 //
 // eagerRequestInfoCache ensures that the request data is cached in the request
@@ -412,10 +715,85 @@ func eagerRequestInfoCache(app core.App) echo.MiddlewareFunc {
 			switch c.Request().Method {
 			// currently we are eagerly caching only the requests with body
 			case "POST", "PUT", "PATCH", "DELETE":
-				RequestInfo(c)
+				// the multipart/form-data is already cached by ParseMultipartForm
+				// and it is loaded lazily to allow route level body limits
+				if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+					RequestInfo(c)
+				}
 			}
 
 			return next(c)
 		}
 	}
 }
+
+// CORS middleware handles the cross-origin requests according to
+// the app Settings.Cors config (it does nothing if the config is disabled).
+//
+// The matched request origin is echoed in the Access-Control-Allow-Origin
+// header (unless the "*" origin is allowed without credentials) and the
+// preflight requests are answered directly without reaching the route handlers.
+func CORS(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Settings().Cors
+			if !config.Enabled {
+				return next(c)
+			}
+
+			req := c.Request()
+			header := c.Response().Header()
+			origin := req.Header.Get(echo.HeaderOrigin)
+			isPreflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+			if isPreflight {
+				header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+				header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			}
+
+			// not a CORS request or a not allowed origin
+			if !config.MatchOrigin(origin) {
+				if isPreflight {
+					return c.NoContent(http.StatusNoContent)
+				}
+				return next(c)
+			}
+
+			if !config.AllowCredentials && list.ExistInSlice("*", config.AllowedOrigins) {
+				header.Set(echo.HeaderAccessControlAllowOrigin, "*")
+			} else {
+				header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+			}
+
+			if config.AllowCredentials {
+				header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+			}
+
+			if !isPreflight {
+				if len(config.ExposedHeaders) > 0 {
+					header.Set(echo.HeaderAccessControlExposeHeaders, strings.Join(config.ExposedHeaders, ","))
+				}
+				return next(c)
+			}
+
+			methods := config.AllowedMethods
+			if len(methods) == 0 {
+				methods = settings.DefaultCorsMethods
+			}
+			header.Set(echo.HeaderAccessControlAllowMethods, strings.Join(methods, ","))
+
+			if len(config.AllowedHeaders) > 0 {
+				header.Set(echo.HeaderAccessControlAllowHeaders, strings.Join(config.AllowedHeaders, ","))
+			} else if requestHeaders := req.Header.Get(echo.HeaderAccessControlRequestHeaders); requestHeaders != "" {
+				header.Set(echo.HeaderAccessControlAllowHeaders, requestHeaders)
+			}
+
+			if config.MaxAge > 0 {
+				header.Set(echo.HeaderAccessControlMaxAge, strconv.Itoa(config.MaxAge))
+			}
+
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
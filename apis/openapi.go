@@ -0,0 +1,562 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// OpenApiVersion is the version of the generated OpenAPI documents.
+const OpenApiVersion = "3.0.3"
+
+// bindOpenApiApi registers the OpenAPI document api endpoint.
+func bindOpenApiApi(app core.App, rg *echo.Group) {
+	api := openApiApi{app: app}
+
+	rg.GET("/openapi.json", api.spec)
+}
+
+type openApiApi struct {
+	app core.App
+}
+
+// spec returns an OpenAPI 3 document describing the records crud
+// endpoints of the current collections.
+//
+// The document is generated on every request so that it always reflects
+// the latest collections state. Non-admin requests receive only
+// the operations that are not restricted to admins.
+func (api *openApiApi) spec(c echo.Context) error {
+	collections := []*models.Collection{}
+	if err := api.app.Dao().CollectionQuery().OrderBy("created ASC").All(&collections); err != nil {
+		return NewBadRequestError("Failed to load the collections.", err)
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+
+	spec := NewOpenApiSpec(api.app, collections, admin != nil)
+
+	return c.JSON(http.StatusOK, spec)
+}
+
+// NewOpenApiSpec generates an OpenAPI 3 document with the records crud
+// endpoints, schemas and auth security schemes of the provided collections.
+//
+// If includeAdminOnly is false, the operations with admin only
+// (aka. nil) API rule are omitted.
+func NewOpenApiSpec(app core.App, collections []*models.Collection, includeAdminOnly bool) map[string]any {
+	title := app.Settings().Meta.AppName
+	if title == "" {
+		title = "PocketBase"
+	}
+
+	version := app.Version()
+	if version == "" {
+		version = "0.0.0"
+	}
+
+	paths := map[string]any{}
+	schemas := map[string]any{
+		"ApiError": map[string]any{
+			"type":     "object",
+			"required": []string{"code", "message", "data"},
+			"properties": map[string]any{
+				"code":    map[string]any{"type": "integer"},
+				"message": map[string]any{"type": "string"},
+				"data":    map[string]any{"type": "object", "additionalProperties": true},
+			},
+		},
+	}
+
+	for _, collection := range collections {
+		g := openApiGenerator{collection: collection, includeAdminOnly: includeAdminOnly}
+		g.addPaths(paths)
+
+		if g.hasOperations() {
+			g.addSchemas(schemas)
+		}
+	}
+
+	spec := map[string]any{
+		"openapi": OpenApiVersion,
+		"info": map[string]any{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":    schemas,
+			"parameters": openApiParameters(),
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error response.",
+					"content":     openApiJsonContent(openApiRef("schemas", "ApiError")),
+				},
+			},
+			"securitySchemes": map[string]any{
+				"adminAuth": map[string]any{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "Admin auth token.",
+				},
+				"recordAuth": map[string]any{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "Auth record token.",
+				},
+				"apiKeyAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Collection scoped API key.",
+				},
+			},
+		},
+	}
+
+	if appUrl := app.Settings().Meta.AppUrl; appUrl != "" {
+		spec["servers"] = []any{map[string]any{"url": appUrl}}
+	}
+
+	return spec
+}
+
+// -------------------------------------------------------------------
+
+type openApiGenerator struct {
+	collection       *models.Collection
+	includeAdminOnly bool
+}
+
+// allowed reports whether an operation with the specified rule should be included.
+func (g *openApiGenerator) allowed(rule *string) bool {
+	return g.includeAdminOnly || rule != nil
+}
+
+func (g *openApiGenerator) hasOperations() bool {
+	c := g.collection
+
+	if g.allowed(c.ListRule) || g.allowed(c.ViewRule) {
+		return true
+	}
+
+	return !c.IsView() && (g.allowed(c.CreateRule) || g.allowed(c.UpdateRule) || g.allowed(c.DeleteRule))
+}
+
+func (g *openApiGenerator) addPaths(paths map[string]any) {
+	c := g.collection
+	name := c.Name
+
+	recordsPath := map[string]any{}
+	recordPath := map[string]any{}
+
+	if g.allowed(c.ListRule) {
+		params := []any{
+			openApiRef("parameters", search.PageQueryParam),
+			openApiRef("parameters", search.PerPageQueryParam),
+			openApiRef("parameters", search.SortQueryParam),
+			openApiRef("parameters", search.FilterQueryParam),
+			openApiRef("parameters", search.SkipTotalQueryParam),
+			openApiRef("parameters", search.CursorQueryParam),
+			openApiRef("parameters", expandQueryParam),
+			openApiRef("parameters", fieldsQueryParam),
+		}
+		if c.HasSoftDelete() {
+			params = append(params, openApiRef("parameters", "includeTrashed"))
+		}
+
+		recordsPath["get"] = g.operation("list", c.ListRule, params, nil, name+"ListResult")
+	}
+
+	if g.allowed(c.ViewRule) {
+		recordPath["get"] = g.operation("view", c.ViewRule, g.recordParams(), nil, name+"Record")
+	}
+
+	if !c.IsView() {
+		upsertParams := []any{
+			openApiRef("parameters", expandQueryParam),
+			openApiRef("parameters", fieldsQueryParam),
+			openApiRef("parameters", validateOnlyQueryParam),
+		}
+
+		if g.allowed(c.CreateRule) {
+			recordsPath["post"] = g.operation("create", c.CreateRule, upsertParams, g.requestBody(name+"Create"), name+"Record")
+		}
+
+		if g.allowed(c.UpdateRule) {
+			params := append([]any{openApiRef("parameters", "recordId")}, upsertParams...)
+			recordPath["patch"] = g.operation("update", c.UpdateRule, params, g.requestBody(name+"Update"), name+"Record")
+		}
+
+		if g.allowed(c.DeleteRule) {
+			recordPath["delete"] = g.operation("delete", c.DeleteRule, []any{openApiRef("parameters", "recordId")}, nil, "")
+		}
+	}
+
+	if len(recordsPath) > 0 {
+		paths["/api/collections/"+name+"/records"] = recordsPath
+	}
+
+	if len(recordPath) > 0 {
+		paths["/api/collections/"+name+"/records/{id}"] = recordPath
+	}
+}
+
+func (g *openApiGenerator) recordParams() []any {
+	return []any{
+		openApiRef("parameters", "recordId"),
+		openApiRef("parameters", expandQueryParam),
+		openApiRef("parameters", fieldsQueryParam),
+	}
+}
+
+func (g *openApiGenerator) requestBody(schemaName string) map[string]any {
+	ref := openApiRef("schemas", schemaName)
+
+	return map[string]any{
+		"required": true,
+		"content": map[string]any{
+			"application/json":    map[string]any{"schema": ref},
+			"multipart/form-data": map[string]any{"schema": ref},
+		},
+	}
+}
+
+func (g *openApiGenerator) operation(action string, rule *string, params []any, requestBody map[string]any, responseSchema string) map[string]any {
+	op := map[string]any{
+		"operationId": action + "_" + g.collection.Name,
+		"tags":        []string{g.collection.Name},
+		"parameters":  params,
+		"responses": map[string]any{
+			"400":     openApiRef("responses", "Error"),
+			"403":     openApiRef("responses", "Error"),
+			"404":     openApiRef("responses", "Error"),
+			"default": openApiRef("responses", "Error"),
+		},
+	}
+
+	responses := op["responses"].(map[string]any)
+	if responseSchema == "" {
+		responses["204"] = map[string]any{"description": "No content."}
+	} else {
+		responses["200"] = map[string]any{
+			"description": "Success.",
+			"content":     openApiJsonContent(openApiRef("schemas", responseSchema)),
+		}
+	}
+
+	if requestBody != nil {
+		op["requestBody"] = requestBody
+	}
+
+	if rule == nil {
+		op["description"] = "Admins only."
+		op["security"] = []any{
+			map[string]any{"adminAuth": []string{}},
+		}
+	} else {
+		// the rules are visible only for admins
+		if *rule != "" && g.includeAdminOnly {
+			op["description"] = "API rule: " + *rule
+		}
+
+		// the empty requirement marks the auth as optional
+		op["security"] = []any{
+			map[string]any{},
+			map[string]any{"recordAuth": []string{}},
+			map[string]any{"adminAuth": []string{}},
+			map[string]any{"apiKeyAuth": []string{}},
+		}
+	}
+
+	return op
+}
+
+func (g *openApiGenerator) addSchemas(schemas map[string]any) {
+	c := g.collection
+	name := c.Name
+
+	// record response schema
+	// ---
+	props := map[string]any{
+		schema.FieldNameId:             map[string]any{"type": "string"},
+		schema.FieldNameCollectionId:   map[string]any{"type": "string", "example": c.Id},
+		schema.FieldNameCollectionName: map[string]any{"type": "string", "example": c.Name},
+		schema.FieldNameExpand:         map[string]any{"type": "object", "additionalProperties": true},
+	}
+	required := []string{schema.FieldNameId, schema.FieldNameCollectionId, schema.FieldNameCollectionName}
+
+	if !c.IsView() {
+		props[schema.FieldNameCreated] = openApiDateSchema()
+		props[schema.FieldNameUpdated] = openApiDateSchema()
+		required = append(required, schema.FieldNameCreated, schema.FieldNameUpdated)
+	}
+
+	if c.HasSoftDelete() {
+		props[schema.FieldNameDeleted] = openApiDateSchema()
+	}
+
+	if c.IsAuth() {
+		props[schema.FieldNameUsername] = map[string]any{"type": "string"}
+		props[schema.FieldNameEmail] = map[string]any{"type": "string", "format": "email"}
+		props[schema.FieldNameEmailVisibility] = map[string]any{"type": "boolean"}
+		props[schema.FieldNameVerified] = map[string]any{"type": "boolean"}
+		required = append(required, schema.FieldNameUsername, schema.FieldNameVerified)
+	}
+
+	for _, field := range c.Schema.Fields() {
+		props[field.Name] = openApiFieldSchema(field, false)
+	}
+
+	schemas[name+"Record"] = map[string]any{
+		"type":       "object",
+		"required":   required,
+		"properties": props,
+	}
+
+	if g.allowed(c.ListRule) {
+		schemas[name+"ListResult"] = map[string]any{
+			"type":     "object",
+			"required": []string{"page", "perPage", "totalItems", "totalPages", "items"},
+			"properties": map[string]any{
+				"page":       map[string]any{"type": "integer"},
+				"perPage":    map[string]any{"type": "integer"},
+				"totalItems": map[string]any{"type": "integer"},
+				"totalPages": map[string]any{"type": "integer"},
+				"nextCursor": map[string]any{"type": "string"},
+				"items": map[string]any{
+					"type":  "array",
+					"items": openApiRef("schemas", name+"Record"),
+				},
+			},
+		}
+	}
+
+	if c.IsView() {
+		return
+	}
+
+	// create/update request schemas
+	// ---
+	if g.allowed(c.CreateRule) {
+		schemas[name+"Create"] = g.upsertSchema(true)
+	}
+
+	if g.allowed(c.UpdateRule) {
+		schemas[name+"Update"] = g.upsertSchema(false)
+	}
+}
+
+func (g *openApiGenerator) upsertSchema(isCreate bool) map[string]any {
+	c := g.collection
+
+	props := map[string]any{}
+	required := []string{}
+
+	if isCreate {
+		props[schema.FieldNameId] = map[string]any{"type": "string", "minLength": 15, "maxLength": 15}
+	}
+
+	if c.IsAuth() {
+		props[schema.FieldNameUsername] = map[string]any{"type": "string"}
+		props[schema.FieldNameEmail] = map[string]any{"type": "string", "format": "email"}
+		props[schema.FieldNameEmailVisibility] = map[string]any{"type": "boolean"}
+		props[schema.FieldNameVerified] = map[string]any{"type": "boolean"}
+		props["password"] = map[string]any{"type": "string", "format": "password"}
+		props["passwordConfirm"] = map[string]any{"type": "string", "format": "password"}
+
+		if isCreate {
+			required = append(required, "password", "passwordConfirm")
+		} else {
+			props["oldPassword"] = map[string]any{"type": "string", "format": "password"}
+		}
+	}
+
+	for _, field := range c.Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // readonly
+		}
+
+		props[field.Name] = openApiFieldSchema(field, true)
+
+		if isCreate && field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	result := map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+
+	if len(required) > 0 {
+		result["required"] = required
+	}
+
+	return result
+}
+
+// openApiFieldSchema returns the OpenAPI schema of a single collection field.
+//
+// If forRequest is set, the file fields are described as binary uploads
+// (instead of the stored file names).
+func openApiFieldSchema(field *schema.SchemaField, forRequest bool) map[string]any {
+	field.InitOptions()
+
+	var result map[string]any
+
+	switch field.Type {
+	case schema.FieldTypeNumber:
+		result = map[string]any{"type": "number"}
+		if options, ok := field.Options.(*schema.NumberOptions); ok {
+			if options.NoDecimal {
+				result["type"] = "integer"
+			}
+			if options.Min != nil {
+				result["minimum"] = *options.Min
+			}
+			if options.Max != nil {
+				result["maximum"] = *options.Max
+			}
+		}
+	case schema.FieldTypeBool:
+		result = map[string]any{"type": "boolean"}
+	case schema.FieldTypeEmail:
+		result = map[string]any{"type": "string", "format": "email"}
+	case schema.FieldTypeUrl:
+		result = map[string]any{"type": "string", "format": "uri"}
+	case schema.FieldTypeDate:
+		result = openApiDateSchema()
+	case schema.FieldTypeColor:
+		result = map[string]any{"type": "string", "pattern": "^#[0-9a-f]{6}([0-9a-f]{2})?$"}
+	case schema.FieldTypeDuration:
+		result = map[string]any{"type": "string", "example": "1h30m0s"}
+	case schema.FieldTypeJson:
+		result = map[string]any{"nullable": true}
+	case schema.FieldTypeComputed:
+		result = map[string]any{"readOnly": true}
+	case schema.FieldTypeSelect:
+		result = map[string]any{"type": "string"}
+		if options, ok := field.Options.(*schema.SelectOptions); ok {
+			if len(options.Values) > 0 {
+				result["enum"] = options.Values
+			}
+			if options.IsMultiple() {
+				result = openApiArraySchema(result, options.MaxSelect)
+			}
+		}
+	case schema.FieldTypeFile:
+		result = map[string]any{"type": "string"}
+		if forRequest {
+			result["format"] = "binary"
+		}
+		if options, ok := field.Options.(*schema.FileOptions); ok && options.IsMultiple() {
+			result = openApiArraySchema(result, options.MaxSelect)
+		}
+	case schema.FieldTypeRelation:
+		result = map[string]any{"type": "string"}
+		if options, ok := field.Options.(*schema.RelationOptions); ok {
+			result["description"] = "Related " + options.CollectionId + " record id."
+			if options.IsMultiple() {
+				maxSelect := 0
+				if options.MaxSelect != nil {
+					maxSelect = *options.MaxSelect
+				}
+				result = openApiArraySchema(result, maxSelect)
+			}
+		}
+	default:
+		// text, editor, encrypted, etc.
+		result = map[string]any{"type": "string"}
+		switch options := field.Options.(type) {
+		case *schema.TextOptions:
+			if options.Min != nil {
+				result["minLength"] = *options.Min
+			}
+			if options.Max != nil {
+				result["maxLength"] = *options.Max
+			}
+			if options.Pattern != "" {
+				result["pattern"] = options.Pattern
+			}
+		case *schema.EncryptedOptions:
+			if options.Min != nil {
+				result["minLength"] = *options.Min
+			}
+			if options.Max != nil {
+				result["maxLength"] = *options.Max
+			}
+		}
+	}
+
+	return result
+}
+
+func openApiArraySchema(items map[string]any, maxItems int) map[string]any {
+	result := map[string]any{
+		"type":  "array",
+		"items": items,
+	}
+
+	if maxItems > 0 {
+		result["maxItems"] = maxItems
+	}
+
+	return result
+}
+
+func openApiDateSchema() map[string]any {
+	return map[string]any{
+		"type":    "string",
+		"example": "2022-01-01 10:00:00.123Z",
+	}
+}
+
+func openApiRef(component string, name string) map[string]any {
+	return map[string]any{"$ref": "#/components/" + component + "/" + name}
+}
+
+func openApiJsonContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": schema},
+	}
+}
+
+func openApiParameters() map[string]any {
+	query := func(name string, description string, schema map[string]any) map[string]any {
+		return map[string]any{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"schema":      schema,
+		}
+	}
+
+	str := map[string]any{"type": "string"}
+	integer := map[string]any{"type": "integer", "minimum": 1}
+	boolean := map[string]any{"type": "boolean"}
+
+	return map[string]any{
+		"recordId": map[string]any{
+			"name":     "id",
+			"in":       "path",
+			"required": true,
+			"schema":   str,
+		},
+		search.PageQueryParam:      query(search.PageQueryParam, "The page (aka. offset) of the paginated list.", integer),
+		search.PerPageQueryParam:   query(search.PerPageQueryParam, "The max returned records per page.", integer),
+		search.SortQueryParam:      query(search.SortQueryParam, "Comma separated list of the records sort fields (eg. `-created,id`).", str),
+		search.FilterQueryParam:    query(search.FilterQueryParam, "Filter expression (eg. `(title~'abc' && created>'2022-01-01')`).", str),
+		search.SkipTotalQueryParam: query(search.SkipTotalQueryParam, "Skips the total counts query.", boolean),
+		search.CursorQueryParam:    query(search.CursorQueryParam, "Keyset pagination cursor (use an empty value for the first page).", str),
+		expandQueryParam:           query(expandQueryParam, "Comma separated list of relations to auto expand (eg. `rel1,rel2.subRel`).", str),
+		fieldsQueryParam:           query(fieldsQueryParam, "Comma separated list of the fields to return (eg. `id,title,expand.rel1`).", str),
+		validateOnlyQueryParam:     query(validateOnlyQueryParam, "Only validates the submitted data without persisting it.", boolean),
+		"includeTrashed":           query("includeTrashed", "Includes the trashed records (admins only).", boolean),
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/resolvers"
@@ -28,6 +30,7 @@ func bindRealtimeApi(app core.App, rg *echo.Group) {
 
 	subGroup := rg.Group("/realtime")
 	subGroup.GET("", api.connect)
+	subGroup.GET("/ws", api.connectWS)
 	subGroup.POST("", api.setSubscriptions, ActivityLogger(app))
 
 	api.bindEvents()
@@ -45,65 +48,26 @@ func (api *realtimeApi) connect(c echo.Context) error {
 	// register new subscription client
 	client := subscriptions.NewDefaultClient()
 	api.app.SubscriptionsBroker().Register(client)
-	defer func() {
-		disconnectEvent := &core.RealtimeDisconnectEvent{
-			HttpContext: c,
-			Client:      client,
-		}
-
-		if err := api.app.OnRealtimeDisconnectRequest().Trigger(disconnectEvent); err != nil {
-			api.app.Logger().Debug(
-				"OnRealtimeDisconnectRequest error",
-				slog.String("clientId", client.Id()),
-				slog.String("error", err.Error()),
-			)
-		}
+	defer api.disconnectClient(c, client)
 
-		api.app.SubscriptionsBroker().Unregister(client.Id())
-	}()
-
-	c.Response().Header().Set("Content-Type", "text/event-stream")
-	c.Response().Header().Set("Cache-Control", "no-store")
-	// https://github.com/pocketbase/pocketbase/discussions/480#discussioncomment-3657640
-	// https://nginx.org/en/docs/http/ngx_http_proxy_module.html#proxy_buffering
-	c.Response().Header().Set("X-Accel-Buffering", "no")
+	setSSEHeaders(c)
 
-	connectEvent := &core.RealtimeConnectEvent{
-		HttpContext: c,
-		Client:      client,
-		IdleTimeout: 5 * time.Minute,
-	}
-
-	if err := api.app.OnRealtimeConnectRequest().Trigger(connectEvent); err != nil {
+	connectEvent, err := api.connectClient(c, client)
+	if err != nil {
 		return err
 	}
 
-	api.app.Logger().Debug("Realtime connection established.", slog.String("clientId", client.Id()))
+	writeSSE := func(e *core.RealtimeMessageEvent) error {
+		writeSSEMessage(e.HttpContext.Response(), e.Client.Id(), e.Message.Name, e.Message.Data)
+		return nil
+	}
 
 	// signalize established connection (aka. fire "connect" message)
-	connectMsgEvent := &core.RealtimeMessageEvent{
-		HttpContext: c,
-		Client:      client,
-		Message: &subscriptions.Message{
-			Name: "PB_CONNECT",
-			Data: []byte(`{"clientId":"` + client.Id() + `"}`),
-		},
-	}
-	connectMsgErr := api.app.OnRealtimeBeforeMessageSend().Trigger(connectMsgEvent, func(e *core.RealtimeMessageEvent) error {
-		w := e.HttpContext.Response()
-		w.Write([]byte("id:" + client.Id() + "\n"))
-		w.Write([]byte("event:" + e.Message.Name + "\n"))
-		w.Write([]byte("data:"))
-		w.Write(e.Message.Data)
-		w.Write([]byte("\n\n"))
-		w.Flush()
-		return api.app.OnRealtimeAfterMessageSend().Trigger(e)
-	})
-	if connectMsgErr != nil {
+	if err := api.sendMessage(c, client, connectMessage(client), writeSSE); err != nil {
 		api.app.Logger().Debug(
 			"Realtime connection closed (failed to deliver PB_CONNECT)",
 			slog.String("clientId", client.Id()),
-			slog.String("error", connectMsgErr.Error()),
+			slog.String("error", err.Error()),
 		)
 		return nil
 	}
@@ -127,26 +91,19 @@ func (api *realtimeApi) connect(c echo.Context) error {
 				return nil
 			}
 
-			msgEvent := &core.RealtimeMessageEvent{
-				HttpContext: c,
-				Client:      client,
-				Message:     &msg,
-			}
-			msgErr := api.app.OnRealtimeBeforeMessageSend().Trigger(msgEvent, func(e *core.RealtimeMessageEvent) error {
-				w := e.HttpContext.Response()
-				w.Write([]byte("id:" + e.Client.Id() + "\n"))
-				w.Write([]byte("event:" + e.Message.Name + "\n"))
-				w.Write([]byte("data:"))
-				w.Write(e.Message.Data)
-				w.Write([]byte("\n\n"))
-				w.Flush()
-				return api.app.OnRealtimeAfterMessageSend().Trigger(msgEvent)
-			})
-			if msgErr != nil {
+			if err := api.sendMessage(c, client, &msg, writeSSE); err != nil {
 				api.app.Logger().Debug(
 					"Realtime connection closed (failed to deliver message)",
 					slog.String("clientId", client.Id()),
-					slog.String("error", msgErr.Error()),
+					slog.String("error", err.Error()),
+				)
+				return nil
+			}
+
+			if msg.Name == disconnectMessageName {
+				api.app.Logger().Debug(
+					"Realtime connection closed (server shutdown)",
+					slog.String("clientId", client.Id()),
 				)
 				return nil
 			}
@@ -164,6 +121,25 @@ func (api *realtimeApi) connect(c echo.Context) error {
 	}
 }
 
+// setSSEHeaders sets the server-sent events stream response headers.
+func setSSEHeaders(c echo.Context) {
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-store")
+	// https://github.com/pocketbase/pocketbase/discussions/480#discussioncomment-3657640
+	// https://nginx.org/en/docs/http/ngx_http_proxy_module.html#proxy_buffering
+	c.Response().Header().Set("X-Accel-Buffering", "no")
+}
+
+// writeSSEMessage writes and flushes a single server-sent event message.
+func writeSSEMessage(w *echo.Response, id string, event string, data []byte) {
+	w.Write([]byte("id:" + id + "\n"))
+	w.Write([]byte("event:" + event + "\n"))
+	w.Write([]byte("data:"))
+	w.Write(data)
+	w.Write([]byte("\n\n"))
+	w.Flush()
+}
+
 // note: in case of reconnect, clients will have to resubmit all subscriptions again
 func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 	form := forms.NewRealtimeSubscribe()
@@ -184,6 +160,128 @@ func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 		return NewNotFoundError("Missing or invalid client id.", err)
 	}
 
+	return api.updateSubscriptions(c, client, form.Subscriptions, func(e *core.RealtimeSubscribeEvent) error {
+		if e.HttpContext.Response().Committed {
+			return nil
+		}
+
+		return e.HttpContext.NoContent(http.StatusNoContent)
+	})
+}
+
+// connectClient triggers the realtime connect hook for the already registered client.
+//
+// The transport specific connection handling is expected to continue
+// only if the returned error is nil.
+func (api *realtimeApi) connectClient(c echo.Context, client subscriptions.Client) (*core.RealtimeConnectEvent, error) {
+	connectEvent := &core.RealtimeConnectEvent{
+		HttpContext: c,
+		Client:      client,
+		IdleTimeout: 5 * time.Minute,
+	}
+
+	if err := api.app.OnRealtimeConnectRequest().Trigger(connectEvent); err != nil {
+		return nil, err
+	}
+
+	api.app.Logger().Debug("Realtime connection established.", slog.String("clientId", client.Id()))
+
+	return connectEvent, nil
+}
+
+// disconnectClient triggers the realtime disconnect hook and unregisters the client from the broker.
+func (api *realtimeApi) disconnectClient(c echo.Context, client subscriptions.Client) {
+	disconnectEvent := &core.RealtimeDisconnectEvent{
+		HttpContext: c,
+		Client:      client,
+	}
+
+	if err := api.app.OnRealtimeDisconnectRequest().Trigger(disconnectEvent); err != nil {
+		api.app.Logger().Debug(
+			"OnRealtimeDisconnectRequest error",
+			slog.String("clientId", client.Id()),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	api.app.SubscriptionsBroker().Unregister(client.Id())
+}
+
+// disconnectMessageName is the name of the message sent to the
+// connected realtime clients right before the server shutdown.
+const disconnectMessageName = "PB_DISCONNECT"
+
+// disconnectRealtimeClients notifies all connected realtime clients
+// with a "PB_DISCONNECT" message and closes their connections.
+//
+// The clients that couldn't be notified before ctx is done are
+// only unregistered from the broker.
+func disconnectRealtimeClients(ctx context.Context, app core.App) {
+	var wg sync.WaitGroup
+
+	for _, client := range app.SubscriptionsBroker().Clients() {
+		wg.Add(1)
+
+		go func(client subscriptions.Client) {
+			defer wg.Done()
+			defer app.SubscriptionsBroker().Unregister(client.Id())
+
+			if client.IsDiscarded() {
+				return
+			}
+
+			select {
+			case client.Channel() <- subscriptions.Message{Name: disconnectMessageName, Data: []byte("{}")}:
+			case <-ctx.Done():
+			}
+		}(client)
+	}
+
+	wg.Wait()
+}
+
+// connectMessage returns the initial client "PB_CONNECT" message.
+func connectMessage(client subscriptions.Client) *subscriptions.Message {
+	return &subscriptions.Message{
+		Name: "PB_CONNECT",
+		Data: []byte(`{"clientId":"` + client.Id() + `"}`),
+	}
+}
+
+// sendMessage delivers a single message to the client using the transport specific write function
+// and triggers the related before and after message send hooks.
+func (api *realtimeApi) sendMessage(
+	c echo.Context,
+	client subscriptions.Client,
+	msg *subscriptions.Message,
+	write func(e *core.RealtimeMessageEvent) error,
+) error {
+	msgEvent := &core.RealtimeMessageEvent{
+		HttpContext: c,
+		Client:      client,
+		Message:     msg,
+	}
+
+	return api.app.OnRealtimeBeforeMessageSend().Trigger(msgEvent, func(e *core.RealtimeMessageEvent) error {
+		if err := write(e); err != nil {
+			return err
+		}
+
+		return api.app.OnRealtimeAfterMessageSend().Trigger(e)
+	})
+}
+
+// updateSubscriptions replaces the client subscriptions with the provided ones
+// and updates the client auth state with the one from the current request context.
+//
+// finalize is called after the after subscribe hook and it is
+// usually used to write the transport specific response.
+func (api *realtimeApi) updateSubscriptions(
+	c echo.Context,
+	client subscriptions.Client,
+	subs []string,
+	finalize func(e *core.RealtimeSubscribeEvent) error,
+) error {
 	// check if the previous request was authorized
 	oldAuthId := extractAuthIdFromGetter(client)
 	newAuthId := extractAuthIdFromGetter(c)
@@ -194,13 +292,15 @@ func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 	event := &core.RealtimeSubscribeEvent{
 		HttpContext:   c,
 		Client:        client,
-		Subscriptions: form.Subscriptions,
+		Subscriptions: subs,
 	}
 
 	return api.app.OnRealtimeBeforeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeEvent) error {
 		// update auth state
 		e.Client.Set(ContextAdminKey, e.HttpContext.Get(ContextAdminKey))
 		e.Client.Set(ContextAuthRecordKey, e.HttpContext.Get(ContextAuthRecordKey))
+		e.Client.Set(ContextApiKeyKey, e.HttpContext.Get(ContextApiKeyKey))
+		e.Client.Set(ContextRemoteIpKey, RealIp(e.HttpContext))
 
 		// unsubscribe from any previous existing subscriptions
 		e.Client.Unsubscribe()
@@ -214,13 +314,7 @@ func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 			slog.Any("subscriptions", e.Subscriptions),
 		)
 
-		return api.app.OnRealtimeAfterSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeEvent) error {
-			if e.HttpContext.Response().Committed {
-				return nil
-			}
-
-			return e.HttpContext.NoContent(http.StatusNoContent)
-		})
+		return api.app.OnRealtimeAfterSubscribeRequest().Trigger(event, finalize)
 	})
 }
 
@@ -379,14 +473,17 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record, dr
 		return nil // no subscribers
 	}
 
+	viewRule := collection.TenantScopedRule(collection.ViewRule)
+	listRule := collection.TenantScopedRule(collection.ListRule)
+
 	subscriptionRuleMap := map[string]*string{
-		(collection.Name + "/" + record.Id + "?"): collection.ViewRule,
-		(collection.Id + "/" + record.Id + "?"):   collection.ViewRule,
-		(collection.Name + "/*?"):                 collection.ListRule,
-		(collection.Id + "/*?"):                   collection.ListRule,
+		(collection.Name + "/" + record.Id + "?"): viewRule,
+		(collection.Id + "/" + record.Id + "?"):   viewRule,
+		(collection.Name + "/*?"):                 listRule,
+		(collection.Id + "/*?"):                   listRule,
 		// @deprecated: the same      as the wildcard topic but kept for backward compatibility
-		(collection.Name + "?"): collection.ListRule,
-		(collection.Id + "?"):   collection.ListRule,
+		(collection.Name + "?"): listRule,
+		(collection.Id + "?"):   listRule,
 	}
 
 	dryCacheKey := action + "/" + record.Id
@@ -416,6 +513,12 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record, dr
 				}
 				requestInfo.Admin, _ = client.Get(ContextAdminKey).(*models.Admin)
 				requestInfo.AuthRecord, _ = client.Get(ContextAuthRecordKey).(*models.Record)
+				requestInfo.ApiKey, _ = client.Get(ContextApiKeyKey).(*models.ApiKey)
+				requestInfo.RemoteIp, _ = client.Get(ContextRemoteIpKey).(string)
+
+				if checkApiKeyScope(requestInfo.ApiKey, collection, models.ApiKeyScopeRead) != nil {
+					continue
+				}
 
 				if !api.canAccessRecord(cleanRecord, requestInfo, rule) {
 					continue
@@ -423,7 +526,15 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record, dr
 
 				rawExpand := cast.ToString(options.Query[expandQueryParam])
 				if rawExpand != "" {
-					expandErrs := api.app.Dao().ExpandRecord(cleanRecord, strings.Split(rawExpand, ","), expandFetch(api.app.Dao(), requestInfo))
+					expandErrs := api.app.Dao().ExpandRecordsWithOptions(
+						[]*models.Record{cleanRecord},
+						strings.Split(rawExpand, ","),
+						daos.ExpandOptions{
+							FetchFunc:        expandFetch(api.app, nil, api.app.Dao(), requestInfo),
+							BackRelFetchFunc: expandBackRelFetch(api.app, nil, api.app.Dao(), requestInfo),
+							BackRelQueryFunc: expandBackRelQuery(api.app.Dao(), requestInfo),
+						},
+					)
 					if len(expandErrs) > 0 {
 						api.app.Logger().Debug(
 							"[broadcastRecord] expand errors",
@@ -447,6 +558,17 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record, dr
 					}
 				}
 
+				// hide the fields with unsatisfied field view rule
+				if err := autoHideRecordsRestrictedFields(api.app.Dao(), []*models.Record{cleanRecord}, requestInfo); err != nil {
+					api.app.Logger().Debug(
+						"[broadcastRecord] fields view access error",
+						slog.String("id", cleanRecord.Id),
+						slog.String("collectionName", cleanRecord.Collection().Name),
+						slog.String("sub", sub),
+						slog.String("error", err.Error()),
+					)
+				}
+
 				data := &recordData{
 					Action: action,
 					Record: cleanRecord,
@@ -562,18 +684,29 @@ func (api *realtimeApi) canAccessRecord(
 		return false
 	}
 
-	// check the subscription client-side filter (if any)
+	// check the OnRecordsQuery hook constraints and
+	// the subscription client-side filter (if any)
 	// ---
 	filter := cast.ToString(requestInfo.Query[search.FilterQueryParam])
-	if filter == "" {
+	if filter == "" && api.app.OnRecordsQuery().Length() == 0 {
 		return true // no further checks needed
 	}
 
-	if err := checkForAdminOnlyRuleFields(requestInfo); err != nil {
-		return false
+	if filter != "" {
+		if err := checkForAdminOnlyRuleFields(requestInfo); err != nil {
+			return false
+		}
 	}
 
 	ruleFunc := func(q *dbx.SelectQuery) error {
+		if err := triggerRecordsQueryHook(api.app, nil, requestInfo, record.Collection(), q, core.RecordsQueryActionRealtime); err != nil {
+			return err
+		}
+
+		if filter == "" {
+			return nil
+		}
+
 		resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), record.Collection(), requestInfo, false)
 
 		expr, err := search.FilterData(filter).BuildExpr(resolver)
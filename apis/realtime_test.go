@@ -451,3 +451,243 @@ func TestRealtimeCustomAuthModelUpdateEvent(t *testing.T) {
 		t.Fatalf("Expected authRecord with email %q, got %q", customUser.Email, clientAuthRecord.Email())
 	}
 }
+
+func TestRealtimeFilteredSubscriptions(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	apis.InitApi(testApp)
+
+	usersRecord, err := testApp.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientsRecord, err := testApp.Dao().FindRecordById("clients", "gk390qegs4y47wn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newClient := func(authRecord *models.Record, subs ...string) subscriptions.Client {
+		client := subscriptions.NewDefaultClient()
+		if authRecord != nil {
+			client.Set(apis.ContextAuthRecordKey, authRecord)
+		}
+		client.Subscribe(subs...)
+		testApp.SubscriptionsBroker().Register(client)
+		return client
+	}
+
+	// receive returns the received client message names during the wait period
+	receive := func(client subscriptions.Client) []string {
+		names := []string{}
+		timeout := time.After(100 * time.Millisecond)
+		for {
+			select {
+			case msg := <-client.Channel():
+				names = append(names, msg.Name)
+			case <-timeout:
+				return names
+			}
+		}
+	}
+
+	activeTopic := "demo2/*?filter=active%3Dtrue"
+	activeClient := newClient(nil, activeTopic)
+	inactiveOptionsTopic := `demo2/*?options={"query":{"filter":"active=false"}}`
+	inactiveClient := newClient(nil, inactiveOptionsTopic)
+	unfilteredClient := newClient(nil, "demo2/*")
+
+	// guest and "users" auth clients shouldn't have access to demo3 even if the filter matches
+	demo3Topic := "demo3/*?filter=title!%3D''"
+	demo3GuestClient := newClient(nil, demo3Topic)
+	demo3UsersClient := newClient(usersRecord, demo3Topic)
+	demo3ClientsClient := newClient(clientsRecord, demo3Topic)
+
+	demo2Record, err := testApp.Dao().FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo3Record, err := testApp.Dao().FindRecordById("demo3", "mk5fmymtx4wsprk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name     string
+		record   *models.Record
+		data     map[string]any
+		expected map[subscriptions.Client][]string
+	}{
+		{
+			"update in the active filter",
+			demo2Record,
+			map[string]any{"active": true},
+			map[subscriptions.Client][]string{
+				activeClient:     {activeTopic},
+				inactiveClient:   {},
+				unfilteredClient: {"demo2/*"},
+			},
+		},
+		{
+			"update still in the active filter",
+			demo2Record,
+			map[string]any{"title": "new_title"},
+			map[subscriptions.Client][]string{
+				activeClient:     {activeTopic},
+				inactiveClient:   {},
+				unfilteredClient: {"demo2/*"},
+			},
+		},
+		{
+			"update out of the active filter",
+			demo2Record,
+			map[string]any{"active": false},
+			map[subscriptions.Client][]string{
+				activeClient:     {},
+				inactiveClient:   {inactiveOptionsTopic},
+				unfilteredClient: {"demo2/*"},
+			},
+		},
+		{
+			"update of a record with access rule",
+			demo3Record,
+			map[string]any{"title": "new_title"},
+			map[subscriptions.Client][]string{
+				demo3GuestClient:   {},
+				demo3UsersClient:   {},
+				demo3ClientsClient: {demo3Topic},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			for k, v := range s.data {
+				s.record.Set(k, v)
+			}
+
+			if err := testApp.Dao().SaveRecord(s.record); err != nil {
+				t.Fatal(err)
+			}
+
+			for client, expected := range s.expected {
+				received := receive(client)
+
+				if len(received) != len(expected) {
+					t.Fatalf("Expected %v messages, got %v", expected, received)
+				}
+
+				for i, name := range expected {
+					if received[i] != name {
+						t.Fatalf("Expected %v messages, got %v", expected, received)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRealtimeRecordsQueryHook(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	apis.InitApi(testApp)
+
+	testApp.OnRecordsQuery("demo2").Add(func(e *core.RecordsQueryEvent) error {
+		if e.Action != core.RecordsQueryActionRealtime {
+			t.Errorf("Expected action %q, got %q", core.RecordsQueryActionRealtime, e.Action)
+		}
+		e.Query.AndWhere(dbx.Not(dbx.HashExp{"demo2.id": "llvuca81nly1qls"}))
+		return nil
+	})
+
+	client := subscriptions.NewDefaultClient()
+	client.Subscribe("demo2/*")
+	testApp.SubscriptionsBroker().Register(client)
+
+	for _, id := range []string{"llvuca81nly1qls", "achvryl401bhse3"} {
+		record, err := testApp.Dao().FindRecordById("demo2", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Set("title", "new_title_"+id)
+		if err := testApp.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	received := []string{}
+	timeout := time.After(100 * time.Millisecond)
+loop:
+	for {
+		select {
+		case msg := <-client.Channel():
+			received = append(received, string(msg.Data))
+		case <-timeout:
+			break loop
+		}
+	}
+
+	if len(received) != 1 || !strings.Contains(received[0], `"id":"achvryl401bhse3"`) {
+		t.Fatalf("Expected only the achvryl401bhse3 record message, got %v", received)
+	}
+}
+
+func TestRealtimeApiKeyAccess(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	apis.InitApi(testApp)
+
+	scenarios := []struct {
+		name     string
+		apiKey   *models.ApiKey
+		expected int
+	}{
+		{"without api key", nil, 1},
+		{"scoped api key", &models.ApiKey{Collections: []string{"sz5l5z67tg7gku0"}, Scopes: []string{models.ApiKeyScopeRead}}, 1},
+		{"api key of another collection", &models.ApiKey{Collections: []string{"wzlqyes4orhoygb"}, Scopes: []string{models.ApiKeyScopeRead}}, 0},
+		{"api key without the read scope", &models.ApiKey{Collections: []string{"sz5l5z67tg7gku0"}, Scopes: []string{models.ApiKeyScopeWrite}}, 0},
+	}
+
+	clients := make([]subscriptions.Client, len(scenarios))
+	for i, s := range scenarios {
+		clients[i] = subscriptions.NewDefaultClient()
+		clients[i].Subscribe("demo2/*")
+		if s.apiKey != nil {
+			clients[i].Set(apis.ContextApiKeyKey, s.apiKey)
+		}
+		testApp.SubscriptionsBroker().Register(clients[i])
+	}
+
+	record, err := testApp.Dao().FindRecordById("demo2", "achvryl401bhse3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("title", "new_title")
+	if err := testApp.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			total := 0
+			timeout := time.After(100 * time.Millisecond)
+		loop:
+			for {
+				select {
+				case <-clients[i].Channel():
+					total++
+				case <-timeout:
+					break loop
+				}
+			}
+
+			if total != s.expected {
+				t.Fatalf("Expected %d messages, got %d", s.expected, total)
+			}
+		})
+	}
+}
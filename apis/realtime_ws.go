@@ -0,0 +1,333 @@
+package apis
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+const (
+	// wsWriteWait is the max time allowed to write a single message to the client.
+	//
+	// Slow clients that can't keep up with the sent messages are disconnected after it.
+	wsWriteWait = 10 * time.Second
+
+	// wsPongWait is the max time allowed to read the next pong (or any other) client message.
+	wsPongWait = 60 * time.Second
+
+	// wsPingPeriod is the interval of the keepalive ping messages (must be less than wsPongWait).
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// wsMaxCommandSize is the max allowed size of a single client command message.
+	wsMaxCommandSize = 64 << 10
+)
+
+// realtime websocket client command actions
+const (
+	wsActionSubscribe   = "subscribe"
+	wsActionUnsubscribe = "unsubscribe"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// the realtime auth is token based (no cookies) so similar to the
+	// default CORS settings the connections are allowed from any origin
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsCommand defines a single realtime websocket client command.
+type wsCommand struct {
+	Action        string   `json:"action"`
+	Subscriptions []string `json:"subscriptions"`
+
+	// Authorization is an optional auth token
+	// (browsers don't allow custom websocket handshake headers).
+	Authorization string `json:"authorization"`
+}
+
+// wsMessage defines a single realtime websocket message sent to the client.
+//
+// Data is the same as the SSE event data.
+type wsMessage struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// connectWS handles the realtime websocket transport.
+//
+// It shares the same broker, hooks and access checks as the SSE transport
+// with the difference that the subscriptions are managed with client commands
+// sent over the socket instead of a separate POST request, eg.:
+//
+//	{"action":"subscribe", "subscriptions":["posts/*"], "authorization":"TOKEN"}
+//	{"action":"unsubscribe", "subscriptions":["posts/*"]}
+//
+// The command results are sent back as "PB_SUBSCRIPTIONS" (on success)
+// or "PB_ERROR" (on failure) messages.
+func (api *realtimeApi) connectWS(c echo.Context) error {
+	if !websocket.IsWebSocketUpgrade(c.Request()) {
+		return NewBadRequestError("Missing or invalid WebSocket upgrade request.", nil)
+	}
+
+	// register new subscription client
+	client := subscriptions.NewDefaultClient()
+	api.app.SubscriptionsBroker().Register(client)
+	defer api.disconnectClient(c, client)
+
+	connectEvent, err := api.connectClient(c, client)
+	if err != nil {
+		return err
+	}
+
+	conn, err := wsUpgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// the upgrader has already responded with an error
+		api.app.Logger().Debug(
+			"Realtime WebSocket upgrade failed",
+			slog.String("clientId", client.Id()),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	defer conn.Close()
+
+	commands := make(chan wsCommand)
+	readDone := make(chan error, 1)
+	closed := make(chan struct{})
+	defer close(closed)
+	go api.readWSCommands(conn, commands, readDone, closed)
+
+	write := func(msg *wsMessage) error {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(msg)
+	}
+
+	writeEvent := func(e *core.RealtimeMessageEvent) error {
+		return write(&wsMessage{Event: e.Message.Name, Data: e.Message.Data})
+	}
+
+	// signalize established connection (aka. fire "connect" message)
+	if err := api.sendMessage(c, client, connectMessage(client), writeEvent); err != nil {
+		api.app.Logger().Debug(
+			"Realtime WebSocket connection closed (failed to deliver PB_CONNECT)",
+			slog.String("clientId", client.Id()),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	pingTicker := time.NewTicker(wsPingPeriod)
+	defer pingTicker.Stop()
+
+	// start an idle timer to keep track of inactive/forgotten connections
+	idleTimeout := connectEvent.IdleTimeout
+	idleTimer := time.NewTimer(idleTimeout)
+	defer idleTimer.Stop()
+
+	for {
+		select {
+		case <-idleTimer.C:
+			api.app.Logger().Debug(
+				"Realtime WebSocket connection closed (idle timeout)",
+				slog.String("clientId", client.Id()),
+			)
+			closeWS(conn, websocket.CloseGoingAway, "idle timeout")
+			return nil
+		case <-pingTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				api.app.Logger().Debug(
+					"Realtime WebSocket connection closed (failed ping)",
+					slog.String("clientId", client.Id()),
+					slog.String("error", err.Error()),
+				)
+				return nil
+			}
+		case err := <-readDone:
+			api.app.Logger().Debug(
+				"Realtime WebSocket connection closed (read error)",
+				slog.String("clientId", client.Id()),
+				slog.String("error", err.Error()),
+			)
+			return nil
+		case cmd := <-commands:
+			if err := api.handleWSCommand(c, client, cmd, write); err != nil {
+				api.app.Logger().Debug(
+					"Realtime WebSocket connection closed (failed to deliver command result)",
+					slog.String("clientId", client.Id()),
+					slog.String("error", err.Error()),
+				)
+				return nil
+			}
+
+			idleTimer.Stop()
+			idleTimer.Reset(idleTimeout)
+		case msg, ok := <-client.Channel():
+			if !ok {
+				// channel is closed
+				api.app.Logger().Debug(
+					"Realtime WebSocket connection closed (closed channel)",
+					slog.String("clientId", client.Id()),
+				)
+				closeWS(conn, websocket.CloseNormalClosure, "")
+				return nil
+			}
+
+			if err := api.sendMessage(c, client, &msg, writeEvent); err != nil {
+				api.app.Logger().Debug(
+					"Realtime WebSocket connection closed (failed to deliver message)",
+					slog.String("clientId", client.Id()),
+					slog.String("error", err.Error()),
+				)
+				return nil
+			}
+
+			if msg.Name == disconnectMessageName {
+				api.app.Logger().Debug(
+					"Realtime WebSocket connection closed (server shutdown)",
+					slog.String("clientId", client.Id()),
+				)
+				closeWS(conn, websocket.CloseGoingAway, "server shutdown")
+				return nil
+			}
+
+			idleTimer.Stop()
+			idleTimer.Reset(idleTimeout)
+		}
+	}
+}
+
+// readWSCommands reads the client commands until the connection is closed.
+//
+// The commands are processed one by one by the connection handler
+// so a client that sends commands faster than they are processed is throttled.
+func (api *realtimeApi) readWSCommands(
+	conn *websocket.Conn,
+	commands chan<- wsCommand,
+	done chan<- error,
+	closed <-chan struct{},
+) {
+	conn.SetReadLimit(wsMaxCommandSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var cmd wsCommand
+
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			done <- err
+			return
+		}
+
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		if err := json.Unmarshal(raw, &cmd); err != nil {
+			// forward as invalid command so that the error could be reported back
+			cmd = wsCommand{}
+		}
+
+		select {
+		case commands <- cmd:
+		case <-closed:
+			return
+		}
+	}
+}
+
+// handleWSCommand applies a single client command and writes back its result.
+//
+// The returned error is only for the result delivery failure.
+func (api *realtimeApi) handleWSCommand(
+	c echo.Context,
+	client subscriptions.Client,
+	cmd wsCommand,
+	write func(msg *wsMessage) error,
+) error {
+	sendErr := func(err error) error {
+		var apiErr *ApiError
+		if !errors.As(err, &apiErr) {
+			apiErr = NewBadRequestError("", err)
+		}
+
+		data, _ := json.Marshal(apiErr)
+
+		return write(&wsMessage{Event: "PB_ERROR", Data: data})
+	}
+
+	var subs []string
+
+	switch cmd.Action {
+	case wsActionSubscribe:
+		subs = list.ToUniqueStringSlice(append(currentSubscriptions(client), cmd.Subscriptions...))
+	case wsActionUnsubscribe:
+		if len(cmd.Subscriptions) > 0 {
+			subs = list.SubtractSlice(currentSubscriptions(client), cmd.Subscriptions)
+		}
+	default:
+		return sendErr(NewBadRequestError("Invalid or unsupported command action.", nil))
+	}
+
+	// load the command auth state
+	if cmd.Authorization != "" {
+		c.Set(ContextAdminKey, nil)
+		c.Set(ContextAuthRecordKey, nil)
+		c.Set(ContextApiKeyKey, nil)
+		loadAuthToken(api.app, c, cmd.Authorization)
+	}
+
+	var writeErr error
+
+	err := api.updateSubscriptions(c, client, subs, func(e *core.RealtimeSubscribeEvent) error {
+		data, err := json.Marshal(map[string]any{"subscriptions": currentSubscriptions(e.Client)})
+		if err != nil {
+			return err
+		}
+
+		writeErr = write(&wsMessage{Event: "PB_SUBSCRIPTIONS", Data: data})
+
+		return writeErr
+	})
+
+	if writeErr != nil {
+		return writeErr
+	}
+
+	if err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// currentSubscriptions returns the sorted client subscriptions.
+func currentSubscriptions(client subscriptions.Client) []string {
+	subs := make([]string, 0, len(client.Subscriptions()))
+	for sub := range client.Subscriptions() {
+		subs = append(subs, sub)
+	}
+
+	sort.Strings(subs)
+
+	return subs
+}
+
+// closeWS sends a close message to the client with the specified code and reason.
+func closeWS(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(wsWriteWait),
+	)
+}
@@ -1,6 +1,7 @@
 package apis
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,13 +25,15 @@ import (
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
 )
 
 // bindRecordAuthApi registers the auth record api endpoints and
 // the corresponding handlers.
 func bindRecordAuthApi(app core.App, rg *echo.Group) {
-	api := recordAuthApi{app: app}
+	api := recordAuthApi{app: app, passkeySessions: newPasskeySessions()}
 
 	// global oauth2 subscription redirect handler
 	rg.GET("/oauth2-redirect", api.oauth2SubscriptionRedirect)
@@ -43,9 +46,11 @@ func bindRecordAuthApi(app core.App, rg *echo.Group) {
 		LoadCollectionContext(app, models.CollectionTypeAuth),
 	)
 	subGroup.GET("/auth-methods", api.authMethods)
-	subGroup.POST("/auth-refresh", api.authRefresh, RequireSameContextRecordAuth())
+	subGroup.POST("/auth-refresh", api.authRefresh)
 	subGroup.POST("/auth-with-oauth2", api.authWithOAuth2)
 	subGroup.POST("/auth-with-password", api.authWithPassword)
+	subGroup.POST("/request-passkey-auth", api.requestPasskeyAuth)
+	subGroup.POST("/auth-with-passkey", api.authWithPasskey)
 	subGroup.POST("/request-password-reset", api.requestPasswordReset)
 	subGroup.POST("/confirm-password-reset", api.confirmPasswordReset)
 	subGroup.POST("/request-verification", api.requestVerification)
@@ -54,18 +59,67 @@ func bindRecordAuthApi(app core.App, rg *echo.Group) {
 	subGroup.POST("/confirm-email-change", api.confirmEmailChange)
 	subGroup.GET("/records/:id/external-auths", api.listExternalAuths, RequireAdminOrOwnerAuth("id"))
 	subGroup.DELETE("/records/:id/external-auths/:provider", api.unlinkExternalAuth, RequireAdminOrOwnerAuth("id"))
+	subGroup.POST("/records/:id/totp", api.enrollTOTP, RequireAdminOrOwnerAuth("id"))
+	subGroup.POST("/records/:id/totp/verify", api.verifyTOTP, RequireAdminOrOwnerAuth("id"))
+	subGroup.DELETE("/records/:id/totp", api.deleteTOTP, RequireAdminOrOwnerAuth("id"))
+	subGroup.GET("/records/:id/passkeys", api.listPasskeys, RequireAdminOrOwnerAuth("id"))
+	subGroup.POST("/records/:id/request-passkey-registration", api.requestPasskeyRegistration, RequireAdminOrOwnerAuth("id"))
+	subGroup.POST("/records/:id/confirm-passkey-registration", api.confirmPasskeyRegistration, RequireAdminOrOwnerAuth("id"))
+	subGroup.DELETE("/records/:id/passkeys/:passkeyId", api.deletePasskey, RequireAdminOrOwnerAuth("id"))
+	subGroup.POST("/records/:id/impersonate", api.impersonate, RequireAdminAuth())
+	subGroup.DELETE("/records/:id/refresh-tokens", api.revokeRefreshTokens, RequireAdminOrOwnerAuth("id"))
 }
 
 type recordAuthApi struct {
 	app core.App
+
+	// passkeySessions holds the pending passkey registration and login ceremonies
+	passkeySessions *passkeySessions
+
+	// externalAuthsRefresh deduplicates the concurrent OAuth2 tokens
+	// renewals of the same auth record (aka. to prevent reusing an
+	// already rotated refresh token)
+	externalAuthsRefresh singleflight.Group
 }
 
 func (api *recordAuthApi) authRefresh(c echo.Context) error {
+	// exchange the submitted refresh token (if any)
+	if refreshToken := cast.ToString(RequestInfo(c).Data["refreshToken"]); refreshToken != "" {
+		return api.authRefreshWithRefreshToken(c, refreshToken)
+	}
+
+	return RequireSameContextRecordAuth()(api.authRefreshWithAuthToken)(c)
+}
+
+func (api *recordAuthApi) authRefreshWithAuthToken(c echo.Context) error {
 	record, _ := c.Get(ContextAuthRecordKey).(*models.Record)
 	if record == nil {
 		return NewNotFoundError("Missing auth record context.", nil)
 	}
 
+	// the impersonation tokens are short-lived and shouldn't be
+	// exchanged for a regular auth token
+	if c.Get(ContextImpersonatorKey) != nil {
+		return NewForbiddenError("Impersonation tokens cannot be refreshed.", nil)
+	}
+
+	return api.refreshAuth(c, record, "")
+}
+
+// refreshAuth triggers the auth refresh hooks and writes a new auth
+// response for the provided record.
+//
+// refreshTokenFamilyId is the family of the rotated refresh token
+// (empty string for a new refresh token family).
+func (api *recordAuthApi) refreshAuth(c echo.Context, record *models.Record, refreshTokenFamilyId string) error {
+	if record.Collection().AuthOptions().RefreshOAuth2Tokens {
+		// the OAuth2 tokens are not required for the PocketBase auth
+		// so they are renewed in the background to avoid delaying the response
+		routine.FireAndForget(func() {
+			api.refreshExternalAuthTokens(record)
+		})
+	}
+
 	event := new(core.RecordAuthRefreshEvent)
 	event.HttpContext = c
 	event.Collection = record.Collection()
@@ -73,7 +127,7 @@ func (api *recordAuthApi) authRefresh(c echo.Context) error {
 
 	return api.app.OnRecordBeforeAuthRefreshRequest().Trigger(event, func(e *core.RecordAuthRefreshEvent) error {
 		return api.app.OnRecordAfterAuthRefreshRequest().Trigger(event, func(e *core.RecordAuthRefreshEvent) error {
-			return RecordAuthResponse(api.app, e.HttpContext, e.Record, nil)
+			return recordAuthResponse(api.app, e.HttpContext, e.Record, refreshTokenFamilyId, nil)
 		})
 	})
 }
@@ -103,10 +157,12 @@ func (api *recordAuthApi) authMethods(c echo.Context) error {
 		UsernamePassword bool           `json:"usernamePassword"`
 		EmailPassword    bool           `json:"emailPassword"`
 		OnlyVerified     bool           `json:"onlyVerified"`
+		Passkey          bool           `json:"passkey"`
 	}{
 		UsernamePassword: authOptions.AllowUsernameAuth,
 		EmailPassword:    authOptions.AllowEmailAuth,
 		OnlyVerified:     authOptions.OnlyVerified,
+		Passkey:          authOptions.AllowPasskeyAuth,
 		AuthProviders:    []providerInfo{},
 	}
 
@@ -222,13 +278,15 @@ func (api *recordAuthApi) authWithOAuth2(c echo.Context) error {
 					return nil // either admin or the rule is empty
 				}
 
-				if collection.CreateRule == nil {
+				createRule := collection.TenantScopedRule(collection.CreateRule)
+
+				if createRule == nil {
 					return errors.New("Only admins can create new accounts with OAuth2")
 				}
 
-				if *collection.CreateRule != "" {
+				if *createRule != "" {
 					resolver := resolvers.NewRecordFieldResolver(txDao, collection, &requestInfo, true)
-					expr, err := search.FilterData(*collection.CreateRule).BuildExpr(resolver)
+					expr, err := search.FilterData(*createRule).BuildExpr(resolver)
 					if err != nil {
 						return err
 					}
@@ -763,3 +821,94 @@ func (api *recordAuthApi) oauth2SubscriptionRedirect(c echo.Context) error {
 
 	return c.Redirect(redirectStatusCode, oauth2RedirectSuccessPath)
 }
+
+// refreshExternalAuthTokens renews the expired OAuth2 access tokens of
+// the specified auth record and logs the failed renewals (if any).
+func (api *recordAuthApi) refreshExternalAuthTokens(authRecord *models.Record) {
+	api.externalAuthsRefresh.Do(authRecord.Collection().Id+authRecord.Id, func() (any, error) {
+		if err := refreshExternalAuthTokens(api.app, authRecord); err != nil {
+			api.app.Logger().Warn(
+				"Failed to refresh the OAuth2 tokens",
+				slog.String("recordId", authRecord.Id),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		return nil, nil
+	})
+}
+
+// refreshExternalAuthTokens renews the expired OAuth2 access tokens
+// of the specified auth record using their stored refresh tokens.
+func refreshExternalAuthTokens(app core.App, authRecord *models.Record) error {
+	externalAuths, err := app.Dao().FindAllExternalAuthsByRecord(authRecord)
+	if err != nil {
+		return err
+	}
+
+	configs := app.Settings().NamedAuthProviderConfigs()
+
+	var errs []error
+
+	for _, ea := range externalAuths {
+		if ea.RefreshToken == "" || !ea.IsAccessTokenExpired() {
+			continue
+		}
+
+		_, refreshToken, err := app.Dao().DecryptExternalAuthTokens(ea)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ea.Provider, err))
+			continue
+		}
+
+		provider, err := auth.NewProviderByName(ea.Provider)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := configs[ea.Provider].SetupProvider(provider); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ea.Provider, err))
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		provider.SetContext(ctx)
+		token, err := provider.RefreshToken(refreshToken)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ea.Provider, err))
+			continue
+		}
+
+		// note: some providers rotate the refresh token on each use
+		// (an empty token.RefreshToken keeps the current one)
+		expiry, _ := types.ParseDateTime(token.Expiry)
+		if err := app.Dao().UpdateExternalAuthTokens(ea, token.AccessToken, token.RefreshToken, expiry); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ea.Provider, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// findPathAuthRecord returns the collection context record
+// matching the "id" path parameter.
+func (api *recordAuthApi) findPathAuthRecord(c echo.Context) (*models.Record, error) {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return nil, NewNotFoundError("Missing collection context.", nil)
+	}
+
+	id := c.PathParam("id")
+	if id == "" {
+		return nil, NewNotFoundError("", nil)
+	}
+
+	record, err := api.app.Dao().FindRecordById(collection.Id, id)
+	if err != nil || record == nil {
+		return nil, NewNotFoundError("", err)
+	}
+
+	return record, nil
+}
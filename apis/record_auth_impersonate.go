@@ -0,0 +1,63 @@
+package apis
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tokens"
+)
+
+// defaultImpersonateTokenDuration is the default impersonation
+// token duration in seconds (capped to the auth token duration).
+const defaultImpersonateTokenDuration int64 = 3600
+
+// impersonate issues a short-lived auth token for the path auth record
+// on behalf of the current request admin.
+//
+// Unlike the regular auth flows, the token is issued even for
+// unverified auth records in "only verified" collections.
+func (api *recordAuthApi) impersonate(c echo.Context) error {
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil {
+		return NewUnauthorizedError("The request requires valid admin authorization token to be set.", nil)
+	}
+
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	maxDuration := api.app.Settings().RecordAuthToken.Duration
+
+	data := struct {
+		Duration int64 `form:"duration" json:"duration"`
+	}{}
+	if err := c.Bind(&data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if err := validation.ValidateStruct(&data,
+		validation.Field(&data.Duration, validation.Min(int64(0)), validation.Max(maxDuration)),
+	); err != nil {
+		return NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	if data.Duration == 0 {
+		data.Duration = min(defaultImpersonateTokenDuration, maxDuration)
+	}
+
+	token, err := tokens.NewRecordImpersonateToken(api.app, record, admin, data.Duration)
+	if err != nil {
+		return NewBadRequestError("Failed to create impersonation token.", err)
+	}
+
+	meta := map[string]any{
+		"impersonatedBy": admin.Id,
+		"duration":       data.Duration,
+	}
+
+	return recordAuthTokenResponse(api.app, c, record, token, "", meta, func(token string) error {
+		saveAuditLog(api.app, api.app.Dao(), c, nil, models.AuditActionImpersonate, record, record)
+		return nil
+	})
+}
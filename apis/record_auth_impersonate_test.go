@@ -0,0 +1,427 @@
+package apis_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+func TestRecordAuthImpersonate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodPost,
+			Url:             "/api/collections/users/records/4q1xlclmfloku33/impersonate",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "auth record (owner)",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/records/4q1xlclmfloku33/impersonate",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPUserToken,
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + non-auth collection",
+			Method: http.MethodPost,
+			Url:    "/api/collections/demo1/records/al1h9ijdeojtsjy/impersonate",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + missing record",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/records/missing/impersonate",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + invalid duration",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/records/4q1xlclmfloku33/impersonate",
+			Body:   strings.NewReader(`{"duration":999999999}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"duration":{"code":"validation_max_less_equal_than_required"`},
+		},
+		{
+			Name:   "admin + default duration",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/records/4q1xlclmfloku33/impersonate",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"id":"4q1xlclmfloku33"`,
+				`"meta":{"duration":3600,"impersonatedBy":"sywbhecnh46rhm0"}`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRecordAuthRequest": 1,
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				checkImpersonateResponseToken(t, res, "4q1xlclmfloku33", "sywbhecnh46rhm0", 3600)
+			},
+		},
+		{
+			Name:   "admin + custom duration + unverified record in only verified collection",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/records/oap640cot4yru2s/impersonate",
+			Body:   strings.NewReader(`{"duration":60}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				record, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+				if err != nil {
+					t.Fatal(err)
+				}
+				record.SetVerified(false)
+				if err := app.Dao().WithoutHooks().SaveRecord(record); err != nil {
+					t.Fatal(err)
+				}
+
+				collection := record.Collection()
+				options := collection.AuthOptions()
+				options.OnlyVerified = true
+				collection.SetOptions(options)
+				if err := app.Dao().WithoutHooks().SaveCollection(collection); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"oap640cot4yru2s"`,
+				`"meta":{"duration":60,"impersonatedBy":"sywbhecnh46rhm0"}`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRecordAuthRequest": 1,
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				checkImpersonateResponseToken(t, res, "oap640cot4yru2s", "sywbhecnh46rhm0", 60)
+			},
+		},
+		{
+			Name:   "admin + audit logs enabled",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/records/4q1xlclmfloku33/impersonate",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				app.Settings().AuditLogs.Enabled = true
+				app.Settings().AuditLogs.Collections = []string{"users"}
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"impersonatedBy":"sywbhecnh46rhm0"`},
+			ExpectedEvents: map[string]int{
+				"OnRecordAuthRequest": 1,
+				"OnModelBeforeCreate": 1,
+				"OnModelAfterCreate":  1,
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				logs, err := app.Dao().FindAuditLogsByRecord("_pb_users_auth_", "4q1xlclmfloku33")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(logs) != 1 {
+					t.Fatalf("Expected 1 audit log, got %d", len(logs))
+				}
+
+				if logs[0].Action != models.AuditActionImpersonate {
+					t.Fatalf("Expected action %q, got %q", models.AuditActionImpersonate, logs[0].Action)
+				}
+
+				if logs[0].ActorType != models.RequestAuthAdmin || logs[0].ActorId != "sywbhecnh46rhm0" {
+					t.Fatalf("Expected admin actor, got %s:%s", logs[0].ActorType, logs[0].ActorId)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthImpersonateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := app.Dao().FindAdminById("sywbhecnh46rhm0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validToken, err := tokens.NewRecordImpersonateToken(app, user, admin, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredToken, err := security.NewJWT(
+		jwt.MapClaims{
+			"id":             user.Id,
+			"type":           tokens.TypeAuthRecord,
+			"collectionId":   user.Collection().Id,
+			"impersonatedBy": admin.Id,
+		},
+		user.TokenKey()+admin.TokenKey+app.Settings().RecordAuthToken.Secret,
+		-60,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// regular auth token signing key with an injected impersonation claim
+	forgedClaimToken, err := security.NewJWT(
+		jwt.MapClaims{
+			"id":             user.Id,
+			"type":           tokens.TypeAuthRecord,
+			"collectionId":   user.Collection().Id,
+			"impersonatedBy": admin.Id,
+		},
+		user.TokenKey()+app.Settings().RecordAuthToken.Secret,
+		60,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// valid impersonation token signature but with a different issuer admin claim
+	forgedAdminToken, err := security.NewJWT(
+		jwt.MapClaims{
+			"id":             user.Id,
+			"type":           tokens.TypeAuthRecord,
+			"collectionId":   user.Collection().Id,
+			"impersonatedBy": "sbmbsdb40jyxf7h",
+		},
+		user.TokenKey()+admin.TokenKey+app.Settings().RecordAuthToken.Secret,
+		60,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addAuthStateRoute := func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+		e.GET("/auth-state", func(c echo.Context) error {
+			result := map[string]string{}
+
+			if record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record); record != nil {
+				result["record"] = record.Id
+			}
+			if admin, _ := c.Get(apis.ContextAdminKey).(*models.Admin); admin != nil {
+				result["admin"] = admin.Id
+			}
+			if impersonator, _ := c.Get(apis.ContextImpersonatorKey).(*models.Admin); impersonator != nil {
+				result["impersonator"] = impersonator.Id
+			}
+
+			return c.JSON(http.StatusOK, result)
+		})
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "valid impersonation token",
+			Method: http.MethodGet,
+			Url:    "/auth-state",
+			RequestHeaders: map[string]string{
+				"Authorization": validToken,
+			},
+			BeforeTestFunc:  addAuthStateRoute,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`{"impersonator":"sywbhecnh46rhm0","record":"4q1xlclmfloku33"}`},
+		},
+		{
+			Name:   "expired impersonation token",
+			Method: http.MethodGet,
+			Url:    "/auth-state",
+			RequestHeaders: map[string]string{
+				"Authorization": expiredToken,
+			},
+			BeforeTestFunc:  addAuthStateRoute,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`{}`},
+		},
+		{
+			Name:   "forged impersonation claim",
+			Method: http.MethodGet,
+			Url:    "/auth-state",
+			RequestHeaders: map[string]string{
+				"Authorization": forgedClaimToken,
+			},
+			BeforeTestFunc:  addAuthStateRoute,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`{}`},
+		},
+		{
+			Name:   "forged impersonation admin",
+			Method: http.MethodGet,
+			Url:    "/auth-state",
+			RequestHeaders: map[string]string{
+				"Authorization": forgedAdminToken,
+			},
+			BeforeTestFunc:  addAuthStateRoute,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`{}`},
+		},
+		{
+			Name:   "impersonation token invalidated on admin token key change",
+			Method: http.MethodGet,
+			Url:    "/auth-state",
+			RequestHeaders: map[string]string{
+				"Authorization": validToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				addAuthStateRoute(t, app, e)
+
+				admin, err := app.Dao().FindAdminById("sywbhecnh46rhm0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				admin.RefreshTokenKey()
+				if err := app.Dao().WithoutHooks().SaveAdmin(admin); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`{}`},
+		},
+		{
+			Name:   "impersonation token refresh",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			RequestHeaders: map[string]string{
+				"Authorization": validToken,
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "impersonation token as admin",
+			Method: http.MethodGet,
+			Url:    "/api/admins",
+			RequestHeaders: map[string]string{
+				"Authorization": validToken,
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "audit log impersonator",
+			Method: http.MethodPatch,
+			Url:    "/api/collections/users/records/4q1xlclmfloku33",
+			Body:   strings.NewReader(`{"name":"impersonated"}`),
+			RequestHeaders: map[string]string{
+				"Authorization": validToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				app.Settings().AuditLogs.Enabled = true
+				app.Settings().AuditLogs.Collections = []string{"users"}
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"name":"impersonated"`},
+			ExpectedEvents: map[string]int{
+				"OnModelBeforeUpdate":         1,
+				"OnModelAfterUpdate":          1,
+				"OnRecordBeforeUpdateRequest": 1,
+				"OnRecordAfterUpdateRequest":  1,
+				"OnModelBeforeCreate":         1,
+				"OnModelAfterCreate":          1,
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				logs, err := app.Dao().FindAuditLogsByRecord("_pb_users_auth_", "4q1xlclmfloku33")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(logs) != 1 {
+					t.Fatalf("Expected 1 audit log, got %d", len(logs))
+				}
+
+				if logs[0].ActorType != models.RequestAuthRecord || logs[0].ActorId != "4q1xlclmfloku33" {
+					t.Fatalf("Expected auth record actor, got %s:%s", logs[0].ActorType, logs[0].ActorId)
+				}
+
+				if logs[0].ImpersonatorId != "sywbhecnh46rhm0" {
+					t.Fatalf("Expected impersonator sywbhecnh46rhm0, got %q", logs[0].ImpersonatorId)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func checkImpersonateResponseToken(t *testing.T, res *http.Response, recordId, adminId string, duration int64) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := struct {
+		Token string `json:"token"`
+	}{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := security.ParseUnverifiedJWT(data.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims["id"] != recordId {
+		t.Fatalf("Expected id claim %q, got %v", recordId, claims["id"])
+	}
+
+	if claims["impersonatedBy"] != adminId {
+		t.Fatalf("Expected impersonatedBy claim %q, got %v", adminId, claims["impersonatedBy"])
+	}
+
+	expectedExp := time.Now().Unix() + duration
+	if exp := cast.ToInt64(claims["exp"]); exp < expectedExp-10 || exp > expectedExp {
+		t.Fatalf("Expected exp claim ~%d, got %d", expectedExp, exp)
+	}
+}
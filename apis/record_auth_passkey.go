@@ -0,0 +1,583 @@
+package apis
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/ratelimit"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// passkeySessionDuration is the max allowed time between the
+	// begin and finish requests of a single passkey ceremony.
+	passkeySessionDuration = 5 * time.Minute
+
+	// passkeySessionsLimit is the max number of the pending passkey ceremonies
+	// (the oldest ones are evicted first when the limit is reached).
+	passkeySessionsLimit = 10000
+
+	// passkeySessionsIpLimit is the max number of the passkey ceremonies
+	// that a single client IP could start per passkeySessionsIpInterval.
+	passkeySessionsIpLimit    = 20
+	passkeySessionsIpInterval = 1 * time.Minute
+
+	passkeyCeremonyRegistration = "registration"
+	passkeyCeremonyLogin        = "login"
+)
+
+var errInvalidPasskeySession = errors.New("missing, expired or already used passkey session")
+
+var errTooManyPasskeySessions = errors.New("too many passkey sessions")
+
+// passkeySession holds the server-side state of a single pending
+// passkey registration or login ceremony.
+type passkeySession struct {
+	ceremony     string
+	collectionId string
+	recordId     string // empty for the discoverable (aka. resident key) logins
+	data         webauthn.SessionData
+}
+
+// passkeySessions is a concurrent safe store for the pending
+// passkey ceremonies that are consumed on their finish request.
+//
+// The sessions expire after passkeySessionDuration, the oldest ones
+// are evicted when passkeySessionsLimit is reached and the number of
+// the started ceremonies is rate limited per client IP.
+type passkeySessions struct {
+	mux     sync.Mutex
+	items   *store.LRU[*passkeySession]
+	limiter *ratelimit.Limiter
+}
+
+func newPasskeySessions() *passkeySessions {
+	return &passkeySessions{
+		items:   store.NewLRU[*passkeySession](passkeySessionsLimit),
+		limiter: ratelimit.New(passkeySessionsIpLimit, passkeySessionsIpInterval),
+	}
+}
+
+// add stores the provided session and returns its new random id.
+//
+// Returns errTooManyPasskeySessions and the duration after which
+// to retry if the ip has exceeded its sessions rate limit.
+func (s *passkeySessions) add(ip string, session *passkeySession) (string, time.Duration, error) {
+	if ok, retryAfter := s.limiter.Allow(ip); !ok {
+		return "", retryAfter, errTooManyPasskeySessions
+	}
+
+	id := security.RandomString(40)
+
+	s.items.Set(id, session, passkeySessionDuration)
+
+	return id, 0, nil
+}
+
+// take removes and returns the non-expired session with the specified id.
+func (s *passkeySessions) take(id string) (*passkeySession, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	session, ok := s.items.Get(id)
+	if !ok {
+		return nil, errInvalidPasskeySession
+	}
+
+	s.items.Remove(id)
+
+	if session.data.Expires.Before(time.Now()) {
+		return nil, errInvalidPasskeySession
+	}
+
+	return session, nil
+}
+
+// addPasskeySession stores the provided passkey session for the current
+// request client and returns its id (or a 429 error if rate limited).
+func (api *recordAuthApi) addPasskeySession(c echo.Context, session *passkeySession) (string, error) {
+	id, retryAfter, err := api.passkeySessions.add(RealIp(c), session)
+	if errors.Is(err, errTooManyPasskeySessions) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+
+		return "", NewApiError(http.StatusTooManyRequests, "Too many passkey requests.", nil)
+	}
+
+	return id, err
+}
+
+// -------------------------------------------------------------------
+
+// passkeyUser adapts an auth record and its passkeys to [webauthn.User].
+type passkeyUser struct {
+	record      *models.Record
+	passkeys    []*models.Passkey
+	credentials []webauthn.Credential
+}
+
+func newPasskeyUser(dao *daos.Dao, record *models.Record) (*passkeyUser, error) {
+	passkeys, err := dao.FindAllPasskeysByRecord(record)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &passkeyUser{
+		record:      record,
+		passkeys:    passkeys,
+		credentials: make([]webauthn.Credential, len(passkeys)),
+	}
+
+	for i, passkey := range passkeys {
+		if err := json.Unmarshal(passkey.Credential, &user.credentials[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return []byte(u.record.Id)
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	if email := u.record.Email(); email != "" {
+		return email
+	}
+
+	return u.record.Username()
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.WebAuthnName()
+}
+
+func (u *passkeyUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// findPasskey returns the user passkey with the specified raw credential id.
+func (u *passkeyUser) findPasskey(credentialId []byte) *models.Passkey {
+	for i, credential := range u.credentials {
+		if bytes.Equal(credential.ID, credentialId) {
+			return u.passkeys[i]
+		}
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// newPasskeyRelyingParty creates a new WebAuthn relying party from the app settings.
+//
+// The relying party id and origins fallback to the Meta.AppUrl hostname and origin.
+func newPasskeyRelyingParty(app core.App) (*webauthn.WebAuthn, error) {
+	settings := app.Settings()
+
+	rpId := settings.Passkeys.RPID
+	origins := settings.Passkeys.Origins
+
+	if rpId == "" || len(origins) == 0 {
+		appUrl, err := url.Parse(settings.Meta.AppUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		if rpId == "" {
+			rpId = appUrl.Hostname()
+		}
+
+		if len(origins) == 0 {
+			origins = []string{appUrl.Scheme + "://" + appUrl.Host}
+		}
+	}
+
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    passkeySessionDuration,
+		TimeoutUVD: passkeySessionDuration,
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:                  rpId,
+		RPDisplayName:         settings.Meta.AppName,
+		RPOrigins:             origins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationPreferred,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+}
+
+type passkeyFinishData struct {
+	SessionId  string          `json:"sessionId"`
+	Name       string          `json:"name"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+func (data *passkeyFinishData) validate() error {
+	return validation.ValidateStruct(data,
+		validation.Field(&data.SessionId, validation.Required),
+		validation.Field(&data.Name, validation.Length(0, 255)),
+		validation.Field(&data.Credential, validation.Required),
+	)
+}
+
+// listPasskeys returns the registered passkeys of the auth record.
+func (api *recordAuthApi) listPasskeys(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	passkeys, err := api.app.Dao().FindAllPasskeysByRecord(record)
+	if err != nil {
+		return NewBadRequestError("Failed to fetch the passkeys for the specified auth record.", err)
+	}
+
+	return c.JSON(http.StatusOK, passkeys)
+}
+
+// deletePasskey removes a single registered passkey of the auth record.
+func (api *recordAuthApi) deletePasskey(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	passkey, err := api.app.Dao().FindPasskeyByRecordAndId(record, c.PathParam("passkeyId"))
+	if err != nil {
+		return NewNotFoundError("Missing passkey.", err)
+	}
+
+	if err := api.app.Dao().DeletePasskey(passkey); err != nil {
+		return NewBadRequestError("Failed to delete the passkey.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requestPasskeyRegistration starts a new passkey registration ceremony
+// and returns the WebAuthn credential creation options for the client.
+func (api *recordAuthApi) requestPasskeyRegistration(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	if !record.Collection().AuthOptions().AllowPasskeyAuth {
+		return NewBadRequestError("The collection is not configured to allow passkey authentication.", nil)
+	}
+
+	rp, err := newPasskeyRelyingParty(api.app)
+	if err != nil {
+		return NewBadRequestError("Invalid passkeys configuration.", err)
+	}
+
+	user, err := newPasskeyUser(api.app.Dao(), record)
+	if err != nil {
+		return NewBadRequestError("Failed to fetch the passkeys for the specified auth record.", err)
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, len(user.credentials))
+	for i, credential := range user.credentials {
+		exclusions[i] = credential.Descriptor()
+	}
+
+	options, data, err := rp.BeginRegistration(
+		user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return NewBadRequestError("Failed to start the passkey registration.", err)
+	}
+
+	sessionId, err := api.addPasskeySession(c, &passkeySession{
+		ceremony:     passkeyCeremonyRegistration,
+		collectionId: record.Collection().Id,
+		recordId:     record.Id,
+		data:         *data,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"sessionId": sessionId,
+		"options":   options,
+	})
+}
+
+// confirmPasskeyRegistration verifies the client attestation response
+// of a pending passkey registration and stores the new credential.
+func (api *recordAuthApi) confirmPasskeyRegistration(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	if !record.Collection().AuthOptions().AllowPasskeyAuth {
+		return NewBadRequestError("The collection is not configured to allow passkey authentication.", nil)
+	}
+
+	data := &passkeyFinishData{}
+	if err := c.Bind(data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if err := data.validate(); err != nil {
+		return NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	session, err := api.passkeySessions.take(data.SessionId)
+	if err != nil ||
+		session.ceremony != passkeyCeremonyRegistration ||
+		session.collectionId != record.Collection().Id ||
+		session.recordId != record.Id {
+		return NewBadRequestError("Invalid or expired passkey session.", err)
+	}
+
+	rp, err := newPasskeyRelyingParty(api.app)
+	if err != nil {
+		return NewBadRequestError("Invalid passkeys configuration.", err)
+	}
+
+	user, err := newPasskeyUser(api.app.Dao(), record)
+	if err != nil {
+		return NewBadRequestError("Failed to fetch the passkeys for the specified auth record.", err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(data.Credential))
+	if err != nil {
+		return NewBadRequestError("Invalid passkey credential.", err)
+	}
+
+	credential, err := rp.CreateCredential(user, session.data, parsed)
+	if err != nil {
+		return NewBadRequestError("Failed to verify the passkey credential.", err)
+	}
+
+	credentialId := base64.RawURLEncoding.EncodeToString(credential.ID)
+
+	if existing, _ := api.app.Dao().FindPasskeyByCredentialId(record.Collection(), credentialId); existing != nil {
+		return NewBadRequestError("The passkey is already registered.", nil)
+	}
+
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		return NewBadRequestError("Failed to serialize the passkey credential.", err)
+	}
+
+	passkey := &models.Passkey{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		CredentialId: credentialId,
+		Name:         data.Name,
+		Credential:   types.JsonRaw(raw),
+	}
+
+	if err := api.app.Dao().SavePasskey(passkey); err != nil {
+		return NewBadRequestError("Failed to save the passkey.", err)
+	}
+
+	return c.JSON(http.StatusOK, passkey)
+}
+
+// requestPasskeyAuth starts a new passkey login ceremony and returns
+// the WebAuthn credential request options for the client.
+//
+// If identity (email or username) is not submitted or it doesn't match
+// any auth record with registered passkeys, the returned options are
+// for a discoverable credential login (aka. resident key).
+func (api *recordAuthApi) requestPasskeyAuth(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("Missing collection context.", nil)
+	}
+
+	if !collection.AuthOptions().AllowPasskeyAuth {
+		return NewBadRequestError("The collection is not configured to allow passkey authentication.", nil)
+	}
+
+	data := struct {
+		Identity string `form:"identity" json:"identity"`
+	}{}
+	if err := c.Bind(&data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	rp, err := newPasskeyRelyingParty(api.app)
+	if err != nil {
+		return NewBadRequestError("Invalid passkeys configuration.", err)
+	}
+
+	var user *passkeyUser
+
+	if data.Identity != "" {
+		record, err := api.app.Dao().FindAuthRecordByEmail(collection.Id, data.Identity)
+		if err != nil {
+			record, err = api.app.Dao().FindAuthRecordByUsername(collection.Id, data.Identity)
+		}
+
+		if err == nil {
+			user, _ = newPasskeyUser(api.app.Dao(), record)
+		}
+	}
+
+	session := &passkeySession{
+		ceremony:     passkeyCeremonyLogin,
+		collectionId: collection.Id,
+	}
+
+	var options *protocol.CredentialAssertion
+	var sessionData *webauthn.SessionData
+
+	// note: fallback to discoverable login to prevent identities enumeration
+	if user != nil && len(user.credentials) > 0 {
+		session.recordId = user.record.Id
+		options, sessionData, err = rp.BeginLogin(user)
+	} else {
+		options, sessionData, err = rp.BeginDiscoverableLogin()
+	}
+	if err != nil {
+		return NewBadRequestError("Failed to start the passkey authentication.", err)
+	}
+
+	session.data = *sessionData
+
+	sessionId, err := api.addPasskeySession(c, session)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"sessionId": sessionId,
+		"options":   options,
+	})
+}
+
+// authWithPasskey verifies the client assertion response of a pending
+// passkey login and returns the auth response of the credential owner.
+func (api *recordAuthApi) authWithPasskey(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("Missing collection context.", nil)
+	}
+
+	if !collection.AuthOptions().AllowPasskeyAuth {
+		return NewBadRequestError("The collection is not configured to allow passkey authentication.", nil)
+	}
+
+	data := &passkeyFinishData{}
+	if err := c.Bind(data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if err := data.validate(); err != nil {
+		return NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	session, err := api.passkeySessions.take(data.SessionId)
+	if err != nil || session.ceremony != passkeyCeremonyLogin || session.collectionId != collection.Id {
+		return NewBadRequestError("Invalid or expired passkey session.", err)
+	}
+
+	rp, err := newPasskeyRelyingParty(api.app)
+	if err != nil {
+		return NewBadRequestError("Invalid passkeys configuration.", err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(data.Credential))
+	if err != nil {
+		return NewBadRequestError("Failed to authenticate.", err)
+	}
+
+	var user *passkeyUser
+	var credential *webauthn.Credential
+
+	if session.recordId != "" {
+		record, err := api.app.Dao().FindRecordById(collection.Id, session.recordId)
+		if err != nil {
+			return NewBadRequestError("Failed to authenticate.", err)
+		}
+
+		user, err = newPasskeyUser(api.app.Dao(), record)
+		if err != nil {
+			return NewBadRequestError("Failed to authenticate.", err)
+		}
+
+		credential, err = rp.ValidateLogin(user, session.data, parsed)
+		if err != nil {
+			return NewBadRequestError("Failed to authenticate.", err)
+		}
+	} else {
+		credential, err = rp.ValidateDiscoverableLogin(func(rawId, userHandle []byte) (webauthn.User, error) {
+			record, err := api.app.Dao().FindRecordById(collection.Id, string(userHandle))
+			if err != nil {
+				return nil, err
+			}
+
+			user, err = newPasskeyUser(api.app.Dao(), record)
+
+			return user, err
+		}, session.data, parsed)
+		if err != nil {
+			return NewBadRequestError("Failed to authenticate.", err)
+		}
+	}
+
+	passkey := user.findPasskey(credential.ID)
+	if passkey == nil {
+		return NewBadRequestError("Failed to authenticate.", nil)
+	}
+
+	if credential.Authenticator.CloneWarning {
+		api.app.Logger().Warn(
+			"Rejected passkey authentication because of a possibly cloned authenticator",
+			slog.String("recordId", user.record.Id),
+			slog.String("passkeyId", passkey.Id),
+		)
+		return NewBadRequestError("Failed to authenticate.", nil)
+	}
+
+	// persist the updated sign counter and flags
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		return NewBadRequestError("Failed to authenticate.", err)
+	}
+	passkey.Credential = types.JsonRaw(raw)
+
+	if err := api.app.Dao().SavePasskey(passkey); err != nil {
+		return NewBadRequestError("Failed to authenticate.", err)
+	}
+
+	return RecordAuthResponse(api.app, c, user.record, nil)
+}
@@ -0,0 +1,135 @@
+package apis
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// authRefreshWithRefreshToken exchanges the provided refresh token
+// for a new auth token and a new (rotated) refresh token.
+//
+// Submitting an already used refresh token is treated as a possible
+// token replay and revokes the entire refresh token family.
+func (api *recordAuthApi) authRefreshWithRefreshToken(c echo.Context, refreshToken string) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("Missing collection context.", nil)
+	}
+
+	if !collection.AuthOptions().AllowRefreshTokens {
+		return NewBadRequestError("The collection is not configured to allow refresh tokens.", nil)
+	}
+
+	model, err := api.app.Dao().FindAuthRefreshTokenByHash(collection, hashRecordRefreshToken(api.app, refreshToken))
+	if err != nil {
+		return NewUnauthorizedError("Invalid or expired refresh token.", err)
+	}
+
+	if model.Used {
+		api.revokeRefreshTokenFamily(model)
+		return NewUnauthorizedError("Invalid or expired refresh token.", nil)
+	}
+
+	if !model.Expires.Time().After(time.Now()) {
+		return NewUnauthorizedError("Invalid or expired refresh token.", nil)
+	}
+
+	record, err := api.app.Dao().FindRecordById(collection.Id, model.RecordId)
+	if err != nil {
+		return NewUnauthorizedError("Invalid or expired refresh token.", err)
+	}
+
+	// the record token key was changed (eg. on password change)
+	if !security.Equal(model.TokenKeyHash, security.SHA256(record.TokenKey())) {
+		api.revokeRefreshTokenFamily(model)
+		return NewUnauthorizedError("Invalid or expired refresh token.", nil)
+	}
+
+	if err := api.app.Dao().UseAuthRefreshToken(model); err != nil {
+		if errors.Is(err, daos.ErrAuthRefreshTokenUsed) {
+			api.revokeRefreshTokenFamily(model)
+			return NewUnauthorizedError("Invalid or expired refresh token.", nil)
+		}
+		return NewBadRequestError("Failed to use the refresh token.", err)
+	}
+
+	return api.refreshAuth(c, record, model.FamilyId)
+}
+
+// revokeRefreshTokenFamily deletes all refresh tokens
+// from the family of the provided refresh token.
+func (api *recordAuthApi) revokeRefreshTokenFamily(model *models.AuthRefreshToken) {
+	if err := api.app.Dao().DeleteAuthRefreshTokenFamily(model.FamilyId); err != nil {
+		api.app.Logger().Warn(
+			"Failed to revoke the refresh token family",
+			slog.String("familyId", model.FamilyId),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// revokeRefreshTokens deletes all refresh tokens of the auth record
+// (aka. signs out the auth record from all refresh token sessions).
+func (api *recordAuthApi) revokeRefreshTokens(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	if err := api.app.Dao().DeleteAllAuthRefreshTokensByRecord(record); err != nil {
+		return NewBadRequestError("Failed to revoke the refresh tokens.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// newRecordRefreshToken generates a new plain refresh token for the auth
+// record and stores its hash in the familyId refresh token family
+// (a new family is created if familyId is empty).
+func newRecordRefreshToken(app core.App, record *models.Record, familyId string) (string, error) {
+	if familyId == "" {
+		familyId = security.RandomString(15)
+	}
+
+	token := security.RandomString(50)
+
+	duration := time.Duration(app.Settings().RecordRefreshToken.Duration) * time.Second
+	expires, err := types.ParseDateTime(time.Now().Add(duration))
+	if err != nil {
+		return "", err
+	}
+
+	// cleanup the previously expired tokens of the record
+	if err := app.Dao().DeleteExpiredAuthRefreshTokensByRecord(record); err != nil {
+		return "", err
+	}
+
+	model := &models.AuthRefreshToken{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		FamilyId:     familyId,
+		TokenHash:    hashRecordRefreshToken(app, token),
+		TokenKeyHash: security.SHA256(record.TokenKey()),
+		Expires:      expires,
+	}
+
+	if err := app.Dao().SaveAuthRefreshToken(model); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// hashRecordRefreshToken returns the keyed hash of the plain refresh token.
+func hashRecordRefreshToken(app core.App, token string) string {
+	return security.HS256(token, app.Settings().RecordRefreshToken.Secret)
+}
@@ -0,0 +1,463 @@
+package apis_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func enableTestRefreshTokens(t testing.TB, app *tests.TestApp) {
+	collection, err := app.Dao().FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection.Options["allowRefreshTokens"] = true
+
+	// skip the model hooks to not affect the scenarios events
+	if err := app.Dao().WithoutHooks().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := core.ReloadCachedCollections(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createTestRefreshToken(t testing.TB, app *tests.TestApp, recordId string, familyId string, token string, used bool, expires time.Time) *models.AuthRefreshToken {
+	record, err := app.Dao().FindRecordById("users", recordId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiresDate, err := types.ParseDateTime(expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := &models.AuthRefreshToken{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		FamilyId:     familyId,
+		TokenHash:    security.HS256(token, app.Settings().RecordRefreshToken.Secret),
+		TokenKeyHash: security.SHA256(record.TokenKey()),
+		Used:         used,
+		Expires:      expiresDate,
+	}
+
+	// skip the model hooks to not affect the scenarios events
+	if err := app.Dao().WithoutHooks().SaveAuthRefreshToken(model); err != nil {
+		t.Fatal(err)
+	}
+
+	return model
+}
+
+func countTestRefreshTokens(t testing.TB, app *tests.TestApp, familyId string) int {
+	var total int
+
+	err := app.Dao().AuthRefreshTokenQuery().
+		Select("count(*)").
+		AndWhere(dbx.HashExp{"familyId": familyId}).
+		Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return total
+}
+
+func TestRecordAuthRefreshWithRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	future := time.Now().Add(1 * time.Hour)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "collection without refresh tokens",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"test"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test", false, future)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "missing refresh token",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"missing"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test", false, future)
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "refresh token from another collection",
+			Method: http.MethodPost,
+			Url:    "/api/collections/clients/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"test"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test", false, future)
+
+				clients, err := app.Dao().FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+				clients.Options["allowRefreshTokens"] = true
+				if err := app.Dao().WithoutHooks().SaveCollection(clients); err != nil {
+					t.Fatal(err)
+				}
+				if err := core.ReloadCachedCollections(app); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "expired refresh token",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"test"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test", false, time.Now().Add(-1*time.Minute))
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "changed record token key",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"test"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test", false, future)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test_old", true, future)
+
+				record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+				if err != nil {
+					t.Fatal(err)
+				}
+				record.RefreshTokenKey()
+				if err := app.Dao().WithoutHooks().SaveRecord(record); err != nil {
+					t.Fatal(err)
+				}
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				if total := countTestRefreshTokens(t, app, "family1"); total != 0 {
+					t.Fatalf("Expected the refresh token family to be revoked, found %d tokens", total)
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "reused refresh token",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"test_old"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test_old", true, future)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test", false, future)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family2", "test_other", false, future)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				if total := countTestRefreshTokens(t, app, "family1"); total != 0 {
+					t.Fatalf("Expected the refresh token family to be revoked, found %d tokens", total)
+				}
+
+				if total := countTestRefreshTokens(t, app, "family2"); total != 1 {
+					t.Fatalf("Expected the other refresh token family to remain, found %d tokens", total)
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "valid refresh token",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"test"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test", false, future)
+				createTestRefreshToken(t, app, "4q1xlclmfloku33", "family2", "test_expired", false, time.Now().Add(-1*time.Minute))
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				body, err := io.ReadAll(res.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				result := struct {
+					RefreshToken string `json:"refreshToken"`
+				}{}
+				if err := json.Unmarshal(body, &result); err != nil {
+					t.Fatal(err)
+				}
+
+				if result.RefreshToken == "" || result.RefreshToken == "test" {
+					t.Fatalf("Expected a new refresh token, got %q", result.RefreshToken)
+				}
+
+				users, err := app.Dao().FindCollectionByNameOrId("users")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				old, err := app.Dao().FindAuthRefreshTokenByHash(users, security.HS256("test", app.Settings().RecordRefreshToken.Secret))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !old.Used {
+					t.Fatal("Expected the exchanged refresh token to be marked as used")
+				}
+
+				rotated, err := app.Dao().FindAuthRefreshTokenByHash(users, security.HS256(result.RefreshToken, app.Settings().RecordRefreshToken.Secret))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if rotated.Used || rotated.FamilyId != "family1" || rotated.RecordId != "4q1xlclmfloku33" {
+					t.Fatalf("Expected unused family1 refresh token, got %v", rotated)
+				}
+
+				if total := countTestRefreshTokens(t, app, "family2"); total != 0 {
+					t.Fatalf("Expected the expired refresh tokens to be deleted, found %d tokens", total)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"refreshToken":"`,
+				`"id":"4q1xlclmfloku33"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRecordBeforeAuthRefreshRequest": 1,
+				"OnRecordAfterAuthRefreshRequest":  1,
+				"OnRecordAuthRequest":              1,
+				"OnModelBeforeCreate":              1,
+				"OnModelAfterCreate":               1,
+			},
+		},
+		{
+			Name:   "valid auth token without refresh token",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPUserToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"refreshToken":"`,
+				`"id":"4q1xlclmfloku33"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRecordBeforeAuthRefreshRequest": 1,
+				"OnRecordAfterAuthRefreshRequest":  1,
+				"OnRecordAuthRequest":              1,
+				"OnModelBeforeCreate":              1,
+				"OnModelAfterCreate":               1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthWithPasswordRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:           "collection without refresh tokens",
+			Method:         http.MethodPost,
+			Url:            "/api/collections/users/auth-with-password",
+			Body:           strings.NewReader(`{"identity":"test@example.com","password":"1234567890"}`),
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"id":"4q1xlclmfloku33"`,
+			},
+			NotExpectedContent: []string{
+				`"refreshToken"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRecordBeforeAuthWithPasswordRequest": 1,
+				"OnRecordAfterAuthWithPasswordRequest":  1,
+				"OnRecordAuthRequest":                   1,
+				"OnModelBeforeUpdate":                   1,
+				"OnModelAfterUpdate":                    1,
+				"OnMailerSend":                          1,
+			},
+		},
+		{
+			Name:   "collection with refresh tokens",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-with-password",
+			Body:   strings.NewReader(`{"identity":"test@example.com","password":"1234567890"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableTestRefreshTokens(t, app)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				refreshTokens, err := app.Dao().FindAllAuthRefreshTokensByRecord(record)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(refreshTokens) != 1 || refreshTokens[0].Used || refreshTokens[0].FamilyId == "" {
+					t.Fatalf("Expected a single unused refresh token, got %v", refreshTokens)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"refreshToken":"`,
+				`"id":"4q1xlclmfloku33"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRecordBeforeAuthWithPasswordRequest": 1,
+				"OnRecordAfterAuthWithPasswordRequest":  1,
+				"OnRecordAuthRequest":                   1,
+				"OnModelBeforeUpdate":                   1,
+				"OnModelAfterUpdate":                    1,
+				"OnMailerSend":                          1,
+				"OnModelBeforeCreate":                   1,
+				"OnModelAfterCreate":                    1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthRevokeRefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	future := time.Now().Add(1 * time.Hour)
+
+	beforeTestFunc := func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+		enableTestRefreshTokens(t, app)
+		createTestRefreshToken(t, app, "4q1xlclmfloku33", "family1", "test1", false, future)
+		createTestRefreshToken(t, app, "4q1xlclmfloku33", "family2", "test2", false, future)
+		createTestRefreshToken(t, app, "oap640cot4yru2s", "family3", "test3", false, future)
+	}
+
+	afterTestFunc := func(revoked bool) func(t *testing.T, app *tests.TestApp, res *http.Response) {
+		return func(t *testing.T, app *tests.TestApp, res *http.Response) {
+			expected := 1
+			if revoked {
+				expected = 0
+			}
+
+			for _, familyId := range []string{"family1", "family2"} {
+				if total := countTestRefreshTokens(t, app, familyId); total != expected {
+					t.Fatalf("Expected %d %s refresh tokens, got %d", expected, familyId, total)
+				}
+			}
+
+			if total := countTestRefreshTokens(t, app, "family3"); total != 1 {
+				t.Fatalf("Expected the refresh tokens of the other record to remain, got %d", total)
+			}
+		}
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodDelete,
+			Url:             "/api/collections/users/records/4q1xlclmfloku33/refresh-tokens",
+			BeforeTestFunc:  beforeTestFunc,
+			AfterTestFunc:   afterTestFunc(false),
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "authorized as different user",
+			Method: http.MethodDelete,
+			Url:    "/api/collections/users/records/oap640cot4yru2s/refresh-tokens",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPUserToken,
+			},
+			BeforeTestFunc:  beforeTestFunc,
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "authorized as owner",
+			Method: http.MethodDelete,
+			Url:    "/api/collections/users/records/4q1xlclmfloku33/refresh-tokens",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPUserToken,
+			},
+			BeforeTestFunc: beforeTestFunc,
+			AfterTestFunc:  afterTestFunc(true),
+			ExpectedStatus: 204,
+		},
+		{
+			Name:   "authorized as admin",
+			Method: http.MethodDelete,
+			Url:    "/api/collections/users/records/4q1xlclmfloku33/refresh-tokens",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: beforeTestFunc,
+			AfterTestFunc:  afterTestFunc(true),
+			ExpectedStatus: 204,
+		},
+		{
+			Name:   "revoked refresh token",
+			Method: http.MethodPost,
+			Url:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"test1"}`),
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				beforeTestFunc(t, app, e)
+
+				record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := app.Dao().DeleteAllAuthRefreshTokensByRecord(record); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,165 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// totpRecoveryCodesTotal is the number of the generated
+// recovery codes on successful TOTP enrollment.
+const totpRecoveryCodesTotal = 10
+
+// enrollTOTP generates a new TOTP secret for the auth record
+// that needs to be confirmed with [recordAuthApi.verifyTOTP].
+func (api *recordAuthApi) enrollTOTP(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	totp, err := api.app.Dao().FindTOTPByRecord(record)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return NewBadRequestError("Failed to fetch the TOTP enrollment.", err)
+		}
+
+		totp = &models.TOTP{
+			CollectionId: record.Collection().Id,
+			RecordId:     record.Id,
+		}
+	} else if totp.Verified {
+		return NewBadRequestError("TOTP is already enabled for the auth record.", nil)
+	}
+
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		return NewBadRequestError("Failed to generate TOTP secret.", err)
+	}
+
+	totp.Secret, err = api.app.Dao().EncryptTOTPSecret(secret)
+	if err != nil {
+		return NewBadRequestError("Failed to encrypt the TOTP secret.", err)
+	}
+	totp.LastUsedStep = 0
+	totp.RecoveryCodes = nil
+
+	if err := api.app.Dao().SaveTOTP(totp); err != nil {
+		return NewBadRequestError("Failed to save the TOTP enrollment.", err)
+	}
+
+	account := record.Email()
+	if account == "" {
+		account = record.Username()
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"secret": secret,
+		"url":    security.TOTPAuthURL(api.app.Settings().Meta.AppName, account, secret),
+	})
+}
+
+// verifyTOTP confirms the pending TOTP enrollment of the auth record
+// and returns the newly generated recovery codes.
+func (api *recordAuthApi) verifyTOTP(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Code string `form:"code" json:"code"`
+	}{}
+	if err := c.Bind(&data); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if err := validation.ValidateStruct(&data,
+		validation.Field(&data.Code, validation.Required, validation.Length(security.TOTPDigits, security.TOTPDigits)),
+	); err != nil {
+		return NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	totp, err := api.app.Dao().FindTOTPByRecord(record)
+	if err != nil {
+		return NewNotFoundError("Missing TOTP enrollment.", err)
+	}
+
+	if totp.Verified {
+		return NewBadRequestError("TOTP is already enabled for the auth record.", nil)
+	}
+
+	if err := api.app.Dao().UseTOTPCode(totp, data.Code); err != nil {
+		if errors.Is(err, daos.ErrInvalidTOTPCode) {
+			return NewBadRequestError("Invalid or already used TOTP code.", validation.Errors{
+				"code": validation.NewError("validation_invalid_totp_code", "Invalid or already used TOTP code."),
+			})
+		}
+		return NewBadRequestError("Failed to verify the TOTP code.", err)
+	}
+
+	totp.Verified = true
+	recoveryCodes := totp.RefreshRecoveryCodes(totpRecoveryCodesTotal)
+
+	if err := api.app.Dao().SaveTOTP(totp); err != nil {
+		return NewBadRequestError("Failed to save the TOTP enrollment.", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+// deleteTOTP removes the TOTP enrollment of the auth record
+// (aka. disables the TOTP requirement on password auth).
+//
+// Disabling a verified enrollment requires a valid TOTP or recovery
+// code, unless the request is made by an admin (eg. account recovery).
+func (api *recordAuthApi) deleteTOTP(c echo.Context) error {
+	record, err := api.findPathAuthRecord(c)
+	if err != nil {
+		return err
+	}
+
+	totp, err := api.app.Dao().FindTOTPByRecord(record)
+	if err != nil {
+		return NewNotFoundError("Missing TOTP enrollment.", err)
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if totp.Verified && admin == nil {
+		data := struct {
+			Code string `form:"code" json:"code"`
+		}{}
+		if err := c.Bind(&data); err != nil {
+			return NewBadRequestError("An error occurred while loading the submitted data.", err)
+		}
+
+		if err := validation.ValidateStruct(&data,
+			validation.Field(&data.Code, validation.Required, validation.Length(1, 255)),
+		); err != nil {
+			return NewBadRequestError("An error occurred while validating the submitted data.", err)
+		}
+
+		if err := api.app.Dao().UseTOTPCode(totp, data.Code); err != nil {
+			if errors.Is(err, daos.ErrInvalidTOTPCode) {
+				return NewBadRequestError("Invalid or already used TOTP code.", validation.Errors{
+					"code": validation.NewError("validation_invalid_totp_code", "Invalid or already used TOTP code."),
+				})
+			}
+			return NewBadRequestError("Failed to verify the TOTP code.", err)
+		}
+	}
+
+	if err := api.app.Dao().DeleteTOTP(totp); err != nil {
+		return NewBadRequestError("Failed to delete the TOTP enrollment.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,9 +1,15 @@
 package apis
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
@@ -11,8 +17,13 @@ import (
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 )
 
 // bindRecordCrudApi registers the record crud api endpoints and
@@ -27,15 +38,94 @@ func bindRecordCrudApi(app core.App, rg *echo.Group) {
 
 	subGroup.GET("/records", api.list, LoadCollectionContext(app))
 	subGroup.GET("/records/:id", api.view, LoadCollectionContext(app))
-	subGroup.POST("/records", api.create, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
-	subGroup.PATCH("/records/:id", api.update, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+	subGroup.GET("/aggregate", api.aggregate, LoadCollectionContext(app))
+	subGroup.POST("/records", api.create, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth), BodyLimitFunc(recordBodyLimit))
+	subGroup.PATCH("/records/:id", api.update, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth), BodyLimitFunc(recordBodyLimit))
+	subGroup.DELETE("/records", api.deleteByFilter, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
 	subGroup.DELETE("/records/:id", api.delete, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+	subGroup.POST("/records/:id/restore", api.restore, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+	subGroup.POST("/records/:id/files/:field/presign", api.presignUpload, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+	subGroup.POST("/records/:id/files/:field/confirm", api.confirmUpload, LoadCollectionContext(app, models.CollectionTypeBase, models.CollectionTypeAuth))
+}
+
+// recordBodyLimit returns the max allowed body size of the record
+// create/update requests, aka. the default limit + the max size
+// of all files that could be uploaded to the context collection.
+func recordBodyLimit(c echo.Context) int64 {
+	limit := DefaultMaxBodySize
+
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return limit
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		if field.Type != schema.FieldTypeFile {
+			continue
+		}
+
+		if options, ok := field.Options.(*schema.FileOptions); ok {
+			limit += int64(options.MaxSize) * int64(max(options.MaxSelect, 1))
+		}
+	}
+
+	return limit
 }
 
 type recordApi struct {
 	app core.App
+
+	// txDao is an optional transaction dao that, if set, is used
+	// instead of the app dao (eg. when executing batch requests).
+	txDao *daos.Dao
+}
+
+// dao returns the dao that the record api handlers operate with.
+func (api *recordApi) dao(c echo.Context) *daos.Dao {
+	if api.txDao != nil {
+		return api.txDao
+	}
+
+	return RequestDao(api.app, c)
+}
+
+// includeTrashed reports whether the trashed records of a soft delete
+// collection should be also included (aka. "?includeTrashed=true").
+//
+// Returns an error if a non-admin tries to access the trashed records.
+func (api *recordApi) includeTrashed(c echo.Context, collection *models.Collection, requestInfo *models.RequestInfo) (bool, error) {
+	if !collection.HasSoftDelete() {
+		return false, nil
+	}
+
+	include, _ := strconv.ParseBool(c.QueryParam("includeTrashed"))
+	if include && requestInfo.Admin == nil {
+		return false, NewForbiddenError("Only admins can access the trashed records.", nil)
+	}
+
+	return include, nil
 }
 
+// findRecordById fetches a single collection record with the provided
+// filters, optionally including the trashed records.
+func (api *recordApi) findRecordById(
+	c echo.Context,
+	collection *models.Collection,
+	recordId string,
+	withTrashed bool,
+	optFilters ...func(q *dbx.SelectQuery) error,
+) (*models.Record, error) {
+	if withTrashed {
+		return api.dao(c).FindRecordByIdWithTrashed(collection.Id, recordId, optFilters...)
+	}
+
+	return api.dao(c).FindRecordById(collection.Id, recordId, optFilters...)
+}
+
+// fullTextSearchQueryParam is the records list query parameter
+// with the full-text search term.
+const fullTextSearchQueryParam = "search"
+
 func (api *recordApi) list(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -43,37 +133,86 @@ func (api *recordApi) list(c echo.Context) error {
 	}
 
 	requestInfo := RequestInfo(c)
+	listRule := collection.TenantScopedRule(collection.ListRule)
 
 	// forbid users and guests to query special filter/sort fields
 	if err := checkForAdminOnlyRuleFields(requestInfo); err != nil {
 		return err
 	}
 
-	if requestInfo.Admin == nil && collection.ListRule == nil {
+	if requestInfo.Admin == nil && listRule == nil {
 		// only admins can access if the rule is nil
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	if err := checkExpandDepth(api.app, c); err != nil {
+		return err
+	}
+
+	withTrashed, err := api.includeTrashed(c, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	listCache := core.RecordsListCache(api.app)
+	listCacheGeneration := listCache.Generation()
+	listCacheKey := recordsListCacheKey(api.app, c, collection, requestInfo, listRule, withTrashed)
+
+	if listCacheKey != "" && !isListCacheBypassRequest(c, requestInfo) {
+		if cached, ok := listCache.Get(collection.Id, listCacheKey); ok {
+			c.Response().Header().Set(listCacheStatusHeader, "HIT")
+			return c.JSONBlob(http.StatusOK, cached)
+		}
+	}
+
 	fieldsResolver := resolvers.NewRecordFieldResolver(
-		api.app.Dao(),
+		api.dao(c),
 		collection,
 		requestInfo,
 		// hidden fields are searchable only by admins
 		requestInfo.Admin != nil,
 	)
 
-	searchProvider := search.NewProvider(fieldsResolver).
-		Query(api.app.Dao().RecordQuery(collection))
+	query := api.dao(c).RecordQuery(collection)
+	if withTrashed {
+		query = api.dao(c).RecordQueryWithTrashed(collection)
+	}
+
+	// full-text search (ranked by relevance unless explicit sort is specified)
+	if term := c.QueryParam(fullTextSearchQueryParam); term != "" {
+		if len(collection.FullTextSearchFields()) == 0 {
+			return NewBadRequestError("The collection doesn't have full-text search fields.", nil)
+		}
+
+		orderByRank := c.QueryParam(search.SortQueryParam) == ""
+		if err := api.dao(c).WithFullTextSearch(query, collection, term, orderByRank); err != nil {
+			return NewBadRequestError("", err)
+		}
+	}
+
+	if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, query, core.RecordsQueryActionList); err != nil {
+		return err
+	}
+
+	searchProvider := search.NewProvider(fieldsResolver).Query(query)
+
+	if requestInfo.Admin == nil && listRule != nil {
+		searchProvider.AddFilter(search.FilterData(*listRule))
+	}
+
+	if isNdjsonRequest(c) {
+		return api.streamList(c, collection, searchProvider, ndjsonListEncoder{})
+	}
 
-	if requestInfo.Admin == nil && collection.ListRule != nil {
-		searchProvider.AddFilter(search.FilterData(*collection.ListRule))
+	if isCsvRequest(c) {
+		return api.streamList(c, collection, searchProvider, newCsvListEncoder(c, collection))
 	}
 
 	records := []*models.Record{}
 
 	result, err := searchProvider.ParseAndExec(c.QueryParams().Encode(), &records)
 	if err != nil {
-		return NewBadRequestError("", err)
+		return newSearchBadRequestError(err)
 	}
 
 	event := new(core.RecordsListEvent)
@@ -87,14 +226,302 @@ func (api *recordApi) list(c echo.Context) error {
 			return nil
 		}
 
-		if err := EnrichRecords(e.HttpContext, api.app.Dao(), e.Records); err != nil {
+		if err := EnrichRecords(e.HttpContext, api.dao(c), e.Records); err != nil {
 			api.app.Logger().Debug("Failed to enrich list records", slog.String("error", err.Error()))
 		}
 
-		return e.HttpContext.JSON(http.StatusOK, e.Result)
+		if err := triggerRecordEnrichHook(api.app, e.HttpContext, e.Records); err != nil {
+			return err
+		}
+
+		if listCacheKey == "" {
+			return e.HttpContext.JSON(http.StatusOK, e.Result)
+		}
+
+		var result any = e.Result
+		if fields := e.HttpContext.QueryParam(fieldsQueryParam); fields != "" {
+			result, err = rest.PickFields(result, fields)
+			if err != nil {
+				return err
+			}
+		}
+
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		listCache.Set(
+			collection.Id,
+			listCacheKey,
+			raw,
+			collection.ListCacheTTL(),
+			listCacheGeneration,
+			recordsListCacheDependencies(api.dao(c), collection, requestInfo, fieldsResolver)...,
+		)
+
+		e.HttpContext.Response().Header().Set(listCacheStatusHeader, "MISS")
+
+		return e.HttpContext.JSONBlob(http.StatusOK, raw)
 	})
 }
 
+// listCacheStatusHeader is the response header that indicates whether
+// the records list result was served from the list cache ("HIT")
+// or it was freshly queried and cached ("MISS").
+const listCacheStatusHeader = "X-Cache"
+
+// recordsListCacheKey returns the list cache key of the current records
+// list request derived from its query params and auth state.
+//
+// Returns empty string if the collection list caching is disabled or the
+// request result is not cacheable, eg. when it contains expanded relations
+// (they could change independently from the collection records), when
+// the rules depend on the request headers or when there are registered
+// OnRecordsQuery, OnRecordsListRequest or OnRecordEnrich handlers (their
+// conditions are not part of the key and they must run on every request).
+func recordsListCacheKey(
+	app core.App,
+	c echo.Context,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+	listRule *string,
+	withTrashed bool,
+) string {
+	if collection.ListCacheTTL() <= 0 ||
+		withTrashed ||
+		app.OnRecordsQuery().Length() > 0 ||
+		app.OnRecordsListRequest().Length() > 0 ||
+		app.OnRecordEnrich().Length() > 0 ||
+		c.QueryParam(expandQueryParam) != "" ||
+		isNdjsonRequest(c) ||
+		isCsvRequest(c) {
+		return ""
+	}
+
+	rules := []string{c.QueryParam(search.FilterQueryParam), c.QueryParam(search.SortQueryParam)}
+	if listRule != nil {
+		rules = append(rules, *listRule)
+	}
+	for _, field := range collection.Schema.Fields() {
+		rules = append(rules, field.ViewRule)
+	}
+	for _, rule := range rules {
+		if strings.Contains(rule, "@request.headers.") {
+			return ""
+		}
+	}
+
+	var auth string
+	switch {
+	case requestInfo.Admin != nil:
+		auth = "admin"
+	case requestInfo.AuthRecord != nil:
+		auth = requestInfo.AuthRecord.Collection().Id + "/" + requestInfo.AuthRecord.Id
+	}
+	if requestInfo.ApiKey != nil {
+		auth += "@" + requestInfo.ApiKey.Id
+	}
+
+	return auth + "?" + c.QueryParams().Encode()
+}
+
+// recordsListCacheDependencies returns the ids of the collections
+// that the cached records list result depends on, aka. the collections
+// referenced in the list rule, filter, sort and the fields view rules
+// and the collection of the current auth record.
+func recordsListCacheDependencies(
+	dao *daos.Dao,
+	collection *models.Collection,
+	requestInfo *models.RequestInfo,
+	fieldsResolver *resolvers.RecordFieldResolver,
+) []string {
+	fieldResolvers := []*resolvers.RecordFieldResolver{fieldsResolver}
+
+	// the fields view rules are resolved separately while enriching the records
+	for _, field := range collection.Schema.Fields() {
+		if field.ViewRule == "" {
+			continue
+		}
+
+		resolver := resolvers.NewRecordFieldResolver(dao, collection, requestInfo, true)
+		if _, err := search.FilterData(field.ViewRule).BuildExpr(resolver); err != nil {
+			continue
+		}
+
+		fieldResolvers = append(fieldResolvers, resolver)
+	}
+
+	ids := []string{}
+	for _, r := range fieldResolvers {
+		for _, c := range r.LoadedCollections() {
+			ids = append(ids, c.Id)
+		}
+	}
+
+	if requestInfo.AuthRecord != nil {
+		ids = append(ids, requestInfo.AuthRecord.Collection().Id)
+	}
+
+	return list.ToUniqueStringSlice(ids)
+}
+
+// isListCacheBypassRequest reports whether the records list cache
+// should be skipped for the current request ("Cache-Control: no-cache").
+//
+// Only admins are allowed to bypass the cache.
+func isListCacheBypassRequest(c echo.Context, requestInfo *models.RequestInfo) bool {
+	if requestInfo.Admin == nil {
+		return false
+	}
+
+	cacheControl := c.Request().Header.Get(echo.HeaderCacheControl)
+
+	return strings.Contains(cacheControl, "no-cache") || strings.Contains(cacheControl, "no-store")
+}
+
+// ndjsonContentType is the content type of the streamed newline-delimited json list results.
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonBatchSize is the number of records that are fetched at once
+// when streaming the list results (as newline-delimited json or csv).
+const ndjsonBatchSize = 200
+
+// isNdjsonRequest reports whether the list results should be streamed
+// as newline-delimited json (?format=ndjson or "Accept: application/x-ndjson").
+func isNdjsonRequest(c echo.Context) bool {
+	return c.QueryParam("format") == "ndjson" ||
+		strings.Contains(c.Request().Header.Get(echo.HeaderAccept), ndjsonContentType)
+}
+
+// listStreamEncoder defines the format of the streamed list results.
+type listStreamEncoder interface {
+	// Headers returns the extra response headers (including the content type).
+	Headers() map[string]string
+
+	// Expands returns the relations that should be always expanded
+	// in addition to the ?expand query parameter.
+	Expands() []string
+
+	// Encode writes a single batch of records to the response.
+	//
+	// Note that Encode is called at least once even when there are no records.
+	Encode(c echo.Context, records []*models.Record) error
+}
+
+// ndjsonListEncoder streams the list results as newline-delimited json.
+type ndjsonListEncoder struct{}
+
+func (ndjsonListEncoder) Headers() map[string]string {
+	return map[string]string{echo.HeaderContentType: ndjsonContentType}
+}
+
+func (ndjsonListEncoder) Expands() []string {
+	return nil
+}
+
+func (ndjsonListEncoder) Encode(c echo.Context, records []*models.Record) error {
+	// note: the serializer appends a new line after each record
+	for _, record := range records {
+		if err := c.Echo().JSONSerializer.Serialize(c, record, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// streamList writes all records matching the search provider
+// in the format of the specified encoder (eg. one json record per line).
+//
+// The records are iterated in batches using the cursor pagination
+// so the page related query parameters are ignored.
+//
+// Note that the OnRecordsListRequest hook is triggered for each batch.
+func (api *recordApi) streamList(c echo.Context, collection *models.Collection, searchProvider *search.Provider, encoder listStreamEncoder) error {
+	params := url.Values{}
+	for k, v := range c.QueryParams() {
+		switch k {
+		case search.PageQueryParam, search.PerPageQueryParam, search.CursorQueryParam, search.SkipTotalQueryParam:
+			// ignore
+		default:
+			params[k] = v
+		}
+	}
+
+	if err := searchProvider.Parse(params.Encode()); err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	searchProvider.SkipTotal(true).PerPage(ndjsonBatchSize)
+
+	var cursor string
+
+	for {
+		records := []*models.Record{}
+
+		result, err := searchProvider.Cursor(cursor).Exec(&records)
+		if err != nil {
+			return api.streamListError(c, newSearchBadRequestError(err))
+		}
+
+		event := new(core.RecordsListEvent)
+		event.HttpContext = c
+		event.Collection = collection
+		event.Records = records
+		event.Result = result
+
+		err = api.app.OnRecordsListRequest().Trigger(event, func(e *core.RecordsListEvent) error {
+			w := e.HttpContext.Response()
+
+			if !w.Committed {
+				for k, v := range encoder.Headers() {
+					w.Header().Set(k, v)
+				}
+				w.Header().Set("X-Accel-Buffering", "no")
+				w.WriteHeader(http.StatusOK)
+			}
+
+			if err := EnrichRecords(e.HttpContext, api.dao(c), e.Records, encoder.Expands()...); err != nil {
+				api.app.Logger().Debug("Failed to enrich list records", slog.String("error", err.Error()))
+			}
+
+			if err := triggerRecordEnrichHook(api.app, e.HttpContext, e.Records); err != nil {
+				return err
+			}
+
+			if err := encoder.Encode(e.HttpContext, e.Records); err != nil {
+				return err
+			}
+
+			w.Flush()
+
+			return nil
+		})
+		if err != nil {
+			return api.streamListError(c, err)
+		}
+
+		if result.NextCursor == "" {
+			return nil
+		}
+
+		cursor = result.NextCursor
+	}
+}
+
+// streamListError returns the provided error if the stream response
+// wasn't started yet, otherwise only logs it since the headers are already sent.
+func (api *recordApi) streamListError(c echo.Context, err error) error {
+	if !c.Response().Committed {
+		return err
+	}
+
+	api.app.Logger().Debug("Failed to stream list records", slog.String("error", err.Error()))
+
+	return nil
+}
+
 func (api *recordApi) view(c echo.Context) error {
 	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
 	if collection == nil {
@@ -107,16 +534,30 @@ func (api *recordApi) view(c echo.Context) error {
 	}
 
 	requestInfo := RequestInfo(c)
+	viewRule := collection.TenantScopedRule(collection.ViewRule)
 
-	if requestInfo.Admin == nil && collection.ViewRule == nil {
+	if requestInfo.Admin == nil && viewRule == nil {
 		// only admins can access if the rule is nil
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	if err := checkExpandDepth(api.app, c); err != nil {
+		return err
+	}
+
+	withTrashed, err := api.includeTrashed(c, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
 	ruleFunc := func(q *dbx.SelectQuery) error {
-		if requestInfo.Admin == nil && collection.ViewRule != nil && *collection.ViewRule != "" {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
-			expr, err := search.FilterData(*collection.ViewRule).BuildExpr(resolver)
+		if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, q, core.RecordsQueryActionView); err != nil {
+			return err
+		}
+
+		if requestInfo.Admin == nil && viewRule != nil && *viewRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.dao(c), collection, requestInfo, true)
+			expr, err := search.FilterData(*viewRule).BuildExpr(resolver)
 			if err != nil {
 				return err
 			}
@@ -126,7 +567,7 @@ func (api *recordApi) view(c echo.Context) error {
 		return nil
 	}
 
-	record, fetchErr := api.app.Dao().FindRecordById(collection.Id, recordId, ruleFunc)
+	record, fetchErr := api.findRecordById(c, collection, recordId, withTrashed, ruleFunc)
 	if fetchErr != nil || record == nil {
 		return NewNotFoundError("", fetchErr)
 	}
@@ -141,7 +582,7 @@ func (api *recordApi) view(c echo.Context) error {
 			return nil
 		}
 
-		if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil {
+		if err := EnrichRecord(e.HttpContext, api.dao(c), e.Record); err != nil {
 			api.app.Logger().Debug(
 				"Failed to enrich view record",
 				slog.String("id", e.Record.Id),
@@ -150,6 +591,21 @@ func (api *recordApi) view(c echo.Context) error {
 			)
 		}
 
+		if err := triggerRecordEnrichHook(api.app, e.HttpContext, []*models.Record{e.Record}); err != nil {
+			return err
+		}
+
+		etag, err := responseETag(e.HttpContext, e.Record)
+		if err != nil {
+			return err
+		}
+
+		e.HttpContext.Response().Header().Set("ETag", etag)
+
+		if matchETag(e.HttpContext.Request().Header.Get("If-None-Match"), etag) {
+			return e.HttpContext.NoContent(http.StatusNotModified)
+		}
+
 		return e.HttpContext.JSON(http.StatusOK, e.Record)
 	})
 }
@@ -161,16 +617,21 @@ func (api *recordApi) create(c echo.Context) error {
 	}
 
 	requestInfo := RequestInfo(c)
+	createRule := collection.TenantScopedRule(collection.CreateRule)
 
-	if requestInfo.Admin == nil && collection.CreateRule == nil {
+	if requestInfo.Admin == nil && createRule == nil {
 		// only admins can access if the rule is nil
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	if err := checkExpandDepth(api.app, c); err != nil {
+		return err
+	}
+
 	hasFullManageAccess := requestInfo.Admin != nil
 
 	// temporary save the record and check it against the create rule
-	if requestInfo.Admin == nil && collection.CreateRule != nil {
+	if requestInfo.Admin == nil && createRule != nil {
 		testRecord := models.NewRecord(collection)
 
 		// replace modifiers fields so that the resolved value is always
@@ -180,23 +641,30 @@ func (api *recordApi) create(c echo.Context) error {
 		}
 
 		testForm := forms.NewRecordUpsert(api.app, testRecord)
+		testForm.SetDao(api.dao(c))
 		testForm.SetFullManageAccess(true)
+		// the batch sub-requests must see the changes of the previous ones
+		testForm.SetDrySubmitSavepoint(api.txDao != nil)
 		if err := testForm.LoadRequest(c.Request(), ""); err != nil {
 			return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
 		}
 
+		if err := loadTenantDefault(testForm, collection, requestInfo); err != nil {
+			return NewBadRequestError("Failed to load the tenant field default value.", err)
+		}
+
 		// force unset the verified state to prevent ManageRule misuse
 		if !hasFullManageAccess {
 			testForm.Verified = false
 		}
 
 		createRuleFunc := func(q *dbx.SelectQuery) error {
-			if *collection.CreateRule == "" {
+			if *createRule == "" {
 				return nil // no create rule to resolve
 			}
 
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
-			expr, err := search.FilterData(*collection.CreateRule).BuildExpr(resolver)
+			resolver := resolvers.NewRecordFieldResolver(api.dao(c), collection, requestInfo, true)
+			expr, err := search.FilterData(*createRule).BuildExpr(resolver)
 			if err != nil {
 				return err
 			}
@@ -205,15 +673,24 @@ func (api *recordApi) create(c echo.Context) error {
 			return nil
 		}
 
+		var fieldsErr error
+
 		testErr := testForm.DrySubmit(func(txDao *daos.Dao) error {
 			foundRecord, err := txDao.FindRecordById(collection.Id, testRecord.Id, createRuleFunc)
 			if err != nil {
 				return fmt.Errorf("DrySubmit create rule failure: %w", err)
 			}
 			hasFullManageAccess = hasAuthManageAccess(txDao, foundRecord, requestInfo)
-			return nil
+
+			fieldsErr = checkRecordFieldsEditAccess(txDao, foundRecord, models.NewRecord(collection), testForm.Data(), requestInfo)
+
+			return fieldsErr
 		})
 
+		if fieldsErr != nil {
+			return NewForbiddenError("You are not allowed to set some of the submitted fields.", fieldsErr)
+		}
+
 		if testErr != nil {
 			return NewBadRequestError("Failed to create record.", testErr)
 		}
@@ -221,19 +698,33 @@ func (api *recordApi) create(c echo.Context) error {
 
 	record := models.NewRecord(collection)
 	form := forms.NewRecordUpsert(api.app, record)
+	form.SetDao(api.dao(c))
 	form.SetFullManageAccess(hasFullManageAccess)
+	form.SetDrySubmitSavepoint(api.txDao != nil)
 
 	// load request
 	if err := form.LoadRequest(c.Request(), ""); err != nil {
 		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
 	}
 
+	if err := loadTenantDefault(form, collection, requestInfo); err != nil {
+		return NewBadRequestError("Failed to load the tenant field default value.", err)
+	}
+
 	event := new(core.RecordCreateEvent)
 	event.HttpContext = c
 	event.Collection = collection
 	event.Record = record
 	event.UploadedFiles = form.FilesToUpload()
 
+	if isValidateOnlyRequest(c) {
+		event.ValidateOnly = true
+
+		return api.validateOnly(c, form, record, "Failed to create record.", func() error {
+			return api.app.OnRecordBeforeCreateRequest().Trigger(event)
+		})
+	}
+
 	// create the record
 	return form.Submit(func(next forms.InterceptorNextFunc[*models.Record]) forms.InterceptorNextFunc[*models.Record] {
 		return func(m *models.Record) error {
@@ -244,7 +735,7 @@ func (api *recordApi) create(c echo.Context) error {
 					return NewBadRequestError("Failed to create record.", err)
 				}
 
-				if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil {
+				if err := EnrichRecord(e.HttpContext, api.dao(c), e.Record); err != nil {
 					api.app.Logger().Debug(
 						"Failed to enrich create record",
 						slog.String("id", e.Record.Id),
@@ -258,6 +749,10 @@ func (api *recordApi) create(c echo.Context) error {
 						return nil
 					}
 
+					if err := triggerRecordEnrichHook(api.app, e.HttpContext, []*models.Record{e.Record}); err != nil {
+						return err
+					}
+
 					return e.HttpContext.JSON(http.StatusOK, e.Record)
 				})
 			})
@@ -277,16 +772,21 @@ func (api *recordApi) update(c echo.Context) error {
 	}
 
 	requestInfo := RequestInfo(c)
+	updateRule := collection.TenantScopedRule(collection.UpdateRule)
 
-	if requestInfo.Admin == nil && collection.UpdateRule == nil {
+	if requestInfo.Admin == nil && updateRule == nil {
 		// only admins can access if the rule is nil
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	if err := checkExpandDepth(api.app, c); err != nil {
+		return err
+	}
+
 	// eager fetch the record so that the modifier field values are replaced
 	// and available when accessing requestInfo.Data using just the field name
 	if requestInfo.HasModifierDataKeys() {
-		record, err := api.app.Dao().FindRecordById(collection.Id, recordId)
+		record, err := api.dao(c).FindRecordById(collection.Id, recordId)
 		if err != nil || record == nil {
 			return NewNotFoundError("", err)
 		}
@@ -294,9 +794,13 @@ func (api *recordApi) update(c echo.Context) error {
 	}
 
 	ruleFunc := func(q *dbx.SelectQuery) error {
-		if requestInfo.Admin == nil && collection.UpdateRule != nil && *collection.UpdateRule != "" {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
-			expr, err := search.FilterData(*collection.UpdateRule).BuildExpr(resolver)
+		if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, q, core.RecordsQueryActionUpdate); err != nil {
+			return err
+		}
+
+		if requestInfo.Admin == nil && updateRule != nil && *updateRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.dao(c), collection, requestInfo, true)
+			expr, err := search.FilterData(*updateRule).BuildExpr(resolver)
 			if err != nil {
 				return err
 			}
@@ -307,25 +811,54 @@ func (api *recordApi) update(c echo.Context) error {
 	}
 
 	// fetch record
-	record, fetchErr := api.app.Dao().FindRecordById(collection.Id, recordId, ruleFunc)
+	record, fetchErr := api.dao(c).FindRecordById(collection.Id, recordId, ruleFunc)
 	if fetchErr != nil || record == nil {
 		return NewNotFoundError("", fetchErr)
 	}
 
 	form := forms.NewRecordUpsert(api.app, record)
-	form.SetFullManageAccess(requestInfo.Admin != nil || hasAuthManageAccess(api.app.Dao(), record, requestInfo))
+	form.SetDao(api.dao(c))
+	form.SetFullManageAccess(requestInfo.Admin != nil || hasAuthManageAccess(api.dao(c), record, requestInfo))
+	form.SetDrySubmitSavepoint(api.txDao != nil)
+
+	// opt-in optimistic concurrency control
+	versionCheck, err := recordVersionCheck(c, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+	if versionCheck != nil {
+		form.SetVersionCheck(versionCheck)
+	}
 
 	// load request
 	if err := form.LoadRequest(c.Request(), ""); err != nil {
 		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
 	}
 
+	// only admins can move a record to another tenant
+	if field := collection.TenantField(); field != "" && requestInfo.Admin == nil &&
+		cast.ToString(form.Data()[field]) != record.GetString(field) {
+		return NewForbiddenError("The record tenant cannot be changed.", nil)
+	}
+
+	if err := checkRecordFieldsEditAccess(api.dao(c), record, record, form.Data(), requestInfo); err != nil {
+		return NewForbiddenError("You are not allowed to change some of the submitted fields.", err)
+	}
+
 	event := new(core.RecordUpdateEvent)
 	event.HttpContext = c
 	event.Collection = collection
 	event.Record = record
 	event.UploadedFiles = form.FilesToUpload()
 
+	if isValidateOnlyRequest(c) {
+		event.ValidateOnly = true
+
+		return api.validateOnly(c, form, record, "Failed to update record.", func() error {
+			return api.app.OnRecordBeforeUpdateRequest().Trigger(event)
+		})
+	}
+
 	// update the record
 	return form.Submit(func(next forms.InterceptorNextFunc[*models.Record]) forms.InterceptorNextFunc[*models.Record] {
 		return func(m *models.Record) error {
@@ -333,10 +866,15 @@ func (api *recordApi) update(c echo.Context) error {
 
 			return api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
 				if err := next(e.Record); err != nil {
+					var modifiedErr *daos.RecordModifiedError
+					if errors.As(err, &modifiedErr) {
+						return newRecordModifiedError(modifiedErr)
+					}
+
 					return NewBadRequestError("Failed to update record.", err)
 				}
 
-				if err := EnrichRecord(e.HttpContext, api.app.Dao(), e.Record); err != nil {
+				if err := EnrichRecord(e.HttpContext, api.dao(c), e.Record); err != nil {
 					api.app.Logger().Debug(
 						"Failed to enrich update record",
 						slog.String("id", e.Record.Id),
@@ -350,6 +888,10 @@ func (api *recordApi) update(c echo.Context) error {
 						return nil
 					}
 
+					if err := triggerRecordEnrichHook(api.app, e.HttpContext, []*models.Record{e.Record}); err != nil {
+						return err
+					}
+
 					return e.HttpContext.JSON(http.StatusOK, e.Record)
 				})
 			})
@@ -369,16 +911,27 @@ func (api *recordApi) delete(c echo.Context) error {
 	}
 
 	requestInfo := RequestInfo(c)
+	deleteRule := collection.TenantScopedRule(collection.DeleteRule)
 
-	if requestInfo.Admin == nil && collection.DeleteRule == nil {
+	if requestInfo.Admin == nil && deleteRule == nil {
 		// only admins can access if the rule is nil
 		return NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
+	// allow admins to permanently delete already trashed records
+	withTrashed, err := api.includeTrashed(c, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
 	ruleFunc := func(q *dbx.SelectQuery) error {
-		if requestInfo.Admin == nil && collection.DeleteRule != nil && *collection.DeleteRule != "" {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestInfo, true)
-			expr, err := search.FilterData(*collection.DeleteRule).BuildExpr(resolver)
+		if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, q, core.RecordsQueryActionDelete); err != nil {
+			return err
+		}
+
+		if requestInfo.Admin == nil && deleteRule != nil && *deleteRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.dao(c), collection, requestInfo, true)
+			expr, err := search.FilterData(*deleteRule).BuildExpr(resolver)
 			if err != nil {
 				return err
 			}
@@ -388,7 +941,7 @@ func (api *recordApi) delete(c echo.Context) error {
 		return nil
 	}
 
-	record, fetchErr := api.app.Dao().FindRecordById(collection.Id, recordId, ruleFunc)
+	record, fetchErr := api.findRecordById(c, collection, recordId, withTrashed, ruleFunc)
 	if fetchErr != nil || record == nil {
 		return NewNotFoundError("", fetchErr)
 	}
@@ -400,7 +953,7 @@ func (api *recordApi) delete(c echo.Context) error {
 
 	return api.app.OnRecordBeforeDeleteRequest().Trigger(event, func(e *core.RecordDeleteEvent) error {
 		// delete the record
-		if err := api.app.Dao().DeleteRecord(e.Record); err != nil {
+		if err := api.dao(c).DeleteRecord(e.Record); err != nil {
 			return NewBadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err)
 		}
 
@@ -413,3 +966,381 @@ func (api *recordApi) delete(c echo.Context) error {
 		})
 	})
 }
+
+// DefaultMaxBulkDelete is the default max number of records that could be
+// deleted with a single delete-by-filter request without confirmation.
+const DefaultMaxBulkDelete = 100
+
+// confirmQueryParam is the delete-by-filter request query parameter
+// with the expected number of the records to delete.
+const confirmQueryParam = "confirm"
+
+// deleteByFilter deletes all records matching the ?filter query
+// parameter in a single transaction.
+//
+// The collection delete rule is applied to each record and the
+// record delete request hooks are triggered for every deleted record.
+//
+// If the number of the matching records exceeds the app MaxBulkDelete
+// setting, the request fails unless ?confirm is equal to that number.
+func (api *recordApi) deleteByFilter(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	filter := c.QueryParam(search.FilterQueryParam)
+	if strings.TrimSpace(filter) == "" {
+		return NewBadRequestError("The filter query parameter is required.", nil)
+	}
+
+	requestInfo := RequestInfo(c)
+	deleteRule := collection.TenantScopedRule(collection.DeleteRule)
+
+	// forbid users and guests to query special filter fields
+	if err := checkForAdminOnlyRuleFields(requestInfo); err != nil {
+		return err
+	}
+
+	if requestInfo.Admin == nil && deleteRule == nil {
+		// only admins can access if the rule is nil
+		return NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	maxRecords := api.app.Settings().Meta.MaxBulkDelete
+	if maxRecords <= 0 {
+		maxRecords = DefaultMaxBulkDelete
+	}
+
+	var confirm int
+	if raw := c.QueryParam(confirmQueryParam); raw != "" {
+		var err error
+		confirm, err = strconv.Atoi(raw)
+		if err != nil || confirm < 0 {
+			return NewBadRequestError("Invalid confirm value - it must be a non-negative integer.", nil)
+		}
+	}
+
+	events := []*core.RecordDeleteEvent{}
+
+	txErr := api.dao(c).RunInTransaction(func(txDao *daos.Dao) error {
+		resolver := resolvers.NewRecordFieldResolver(
+			txDao,
+			collection,
+			requestInfo,
+			// hidden fields are searchable only by admins
+			requestInfo.Admin != nil,
+		)
+
+		filters := []search.FilterData{search.FilterData(filter)}
+		if requestInfo.Admin == nil && deleteRule != nil && *deleteRule != "" {
+			filters = append(filters, search.FilterData(*deleteRule))
+		}
+
+		query := txDao.RecordQuery(collection)
+
+		if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, query, core.RecordsQueryActionDelete); err != nil {
+			return err
+		}
+
+		for _, f := range filters {
+			expr, err := f.BuildExpr(resolver)
+			if err != nil {
+				return NewBadRequestError("Invalid filter.", err)
+			}
+			query.AndWhere(expr)
+		}
+
+		if err := resolver.UpdateQuery(query); err != nil {
+			return NewBadRequestError("Invalid filter.", err)
+		}
+
+		// count the matching records first to avoid loading
+		// an unbounded number of records in memory
+		var total int
+		countQuery := *query // shallow clone
+		err := countQuery.Distinct(false).
+			Select("COUNT(DISTINCT [[" + collection.Name + ".id]])").
+			OrderBy( /* reset */ ).
+			Row(&total)
+		if err != nil {
+			return NewBadRequestError("Failed to count the records to delete.", err)
+		}
+
+		if c.QueryParam(confirmQueryParam) != "" && confirm != total {
+			return NewBadRequestError(fmt.Sprintf(
+				"The filter matches %d records but the confirm value is %d.",
+				total,
+				confirm,
+			), nil)
+		}
+
+		if total > maxRecords && confirm != total {
+			return NewBadRequestError(fmt.Sprintf(
+				"The filter matches %d records which exceeds the max allowed %d. Set ?%s=%d to proceed.",
+				total,
+				maxRecords,
+				confirmQueryParam,
+				total,
+			), nil)
+		}
+
+		records := []*models.Record{}
+		if err := query.Distinct(true).All(&records); err != nil {
+			return NewBadRequestError("Failed to fetch the records to delete.", err)
+		}
+
+		for _, record := range records {
+			event := new(core.RecordDeleteEvent)
+			event.HttpContext = c
+			event.Collection = collection
+			event.Record = record
+
+			err := api.app.OnRecordBeforeDeleteRequest().Trigger(event, func(e *core.RecordDeleteEvent) error {
+				if err := txDao.DeleteRecord(e.Record); err != nil {
+					return NewBadRequestError(
+						fmt.Sprintf("Failed to delete record %q. Make sure that the record is not part of a required relation reference.", e.Record.Id),
+						err,
+					)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			events = append(events, event)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		var apiErr *ApiError
+		if errors.As(txErr, &apiErr) {
+			return apiErr
+		}
+		return NewBadRequestError("Failed to delete the records.", txErr)
+	}
+
+	// trigger the after hooks only after the transaction commit
+	for _, event := range events {
+		err := api.app.OnRecordAfterDeleteRequest().Trigger(event)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.Response().Committed {
+		return nil
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{"deleted": len(events)})
+}
+
+func (api *recordApi) restore(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	if !collection.HasSoftDelete() {
+		return NewBadRequestError("The collection doesn't have soft delete enabled.", nil)
+	}
+
+	recordId := c.PathParam("id")
+	if recordId == "" {
+		return NewNotFoundError("", nil)
+	}
+
+	requestInfo := RequestInfo(c)
+	deleteRule := collection.TenantScopedRule(collection.DeleteRule)
+
+	if requestInfo.Admin == nil && deleteRule == nil {
+		// only admins can access if the rule is nil
+		return NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	if err := checkExpandDepth(api.app, c); err != nil {
+		return err
+	}
+
+	ruleFunc := func(q *dbx.SelectQuery) error {
+		if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, q, core.RecordsQueryActionDelete); err != nil {
+			return err
+		}
+
+		if requestInfo.Admin == nil && deleteRule != nil && *deleteRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.dao(c), collection, requestInfo, true)
+			expr, err := search.FilterData(*deleteRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+		}
+		return nil
+	}
+
+	record, fetchErr := api.dao(c).FindRecordByIdWithTrashed(collection.Id, recordId, ruleFunc)
+	if fetchErr != nil || record == nil || !record.IsTrashed() {
+		return NewNotFoundError("", fetchErr)
+	}
+
+	if err := api.dao(c).RestoreRecord(record); err != nil {
+		return NewBadRequestError("Failed to restore record.", err)
+	}
+
+	if err := EnrichRecord(c, api.dao(c), record); err != nil {
+		api.app.Logger().Debug(
+			"Failed to enrich restore record",
+			slog.String("id", record.Id),
+			slog.String("collectionName", record.Collection().Name),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	if err := triggerRecordEnrichHook(api.app, c, []*models.Record{record}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// validateOnlyQueryParam and validateOnlyHeader are the create/update
+// request flags to only validate the submitted data without persisting it.
+const (
+	validateOnlyQueryParam = "validateOnly"
+	validateOnlyHeader     = "X-Validate-Only"
+)
+
+// isValidateOnlyRequest reports whether the create/update request
+// is a dry run (?validateOnly=true or "X-Validate-Only: 1").
+func isValidateOnlyRequest(c echo.Context) bool {
+	if raw := c.QueryParam(validateOnlyQueryParam); raw != "" {
+		return cast.ToBool(raw)
+	}
+
+	return cast.ToBool(c.Request().Header.Get(validateOnlyHeader))
+}
+
+// validateOnly runs the full form validation and persistence within
+// a transaction that is always rolled back and writes the would-be
+// record as response.
+//
+// beforeRequest triggers the OnRecordBefore*Request hooks (with
+// the event ValidateOnly flag set) after the record is persisted so
+// that their errors are returned as part of the validation.
+//
+// No files are uploaded or deleted and no other app hooks are triggered
+// except the read-only OnRecordEnrich response hook.
+func (api *recordApi) validateOnly(c echo.Context, form *forms.RecordUpsert, record *models.Record, errMsg string, beforeRequest func() error) error {
+	var hookErr error
+
+	err := form.DrySubmit(func(txDao *daos.Dao) error {
+		if hookErr = beforeRequest(); hookErr != nil {
+			return hookErr
+		}
+
+		// enrich within the transaction so that the record is visible
+		// to the expand and field rule queries
+		if err := EnrichRecord(c, txDao, record); err != nil {
+			api.app.Logger().Debug(
+				"Failed to enrich validate only record",
+				slog.String("id", record.Id),
+				slog.String("collectionName", record.Collection().Name),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		return nil
+	})
+	if hookErr != nil {
+		return hookErr
+	}
+	if err != nil {
+		var modifiedErr *daos.RecordModifiedError
+		if errors.As(err, &modifiedErr) {
+			return newRecordModifiedError(modifiedErr)
+		}
+
+		return NewBadRequestError(errMsg, err)
+	}
+
+	if err := triggerRecordEnrichHook(api.app, c, []*models.Record{record}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// expectedUpdatedKey is the optional request body field with the
+// expected "updated" value of the record that is going to be updated.
+const expectedUpdatedKey = "expectedUpdated"
+
+// recordVersionCheck returns the optimistic concurrency check of the
+// record update request (if any).
+//
+// The expected record version could be specified with either:
+//   - the "expectedUpdated" body field - the persisted record "updated"
+//     value must match exactly the specified datetime
+//   - the "If-Unmodified-Since" header - the persisted record must not
+//     be updated after the specified http date (seconds precision)
+//
+// Returns nil if none of them is specified (aka. last-write-wins).
+func recordVersionCheck(c echo.Context, collection *models.Collection, requestInfo *models.RequestInfo) (func(types.DateTime) bool, error) {
+	if raw, ok := requestInfo.Data[expectedUpdatedKey]; ok && collection.Schema.GetFieldByName(expectedUpdatedKey) == nil {
+		expected, err := types.ParseDateTime(raw)
+		if err != nil || expected.IsZero() {
+			return nil, NewBadRequestError("Invalid expectedUpdated datetime value.", err)
+		}
+
+		return func(persistedUpdated types.DateTime) bool {
+			return persistedUpdated.Time().Equal(expected.Time())
+		}, nil
+	}
+
+	if header := c.Request().Header.Get("If-Unmodified-Since"); header != "" {
+		since, err := http.ParseTime(header)
+		if err != nil {
+			return nil, nil // invalid http date -> ignore the header (RFC 9110)
+		}
+
+		return func(persistedUpdated types.DateTime) bool {
+			return !persistedUpdated.Time().Truncate(time.Second).After(since)
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// newRecordModifiedError creates a 409 ApiError with the current
+// persisted record "updated" value so that clients can reconcile.
+func newRecordModifiedError(err *daos.RecordModifiedError) *ApiError {
+	apiErr := NewApiError(http.StatusConflict, "The record was modified in the meantime.", err)
+
+	apiErr.Data = map[string]any{
+		schema.FieldNameUpdated: map[string]any{
+			"code":    "record_modified",
+			"message": "The record was modified in the meantime.",
+			"current": err.Updated,
+		},
+	}
+
+	return apiErr
+}
+
+// newSearchBadRequestError creates a new 400 ApiError from the
+// provided search provider error.
+//
+// The non-searchable field errors are exposed in the error message
+// (they don't contain sensitive information) to help the clients
+// identify the offending filter or sort field.
+func newSearchBadRequestError(err error) *ApiError {
+	var fieldErr *search.NonSearchableFieldError
+	if errors.As(err, &fieldErr) {
+		return NewBadRequestError(fieldErr.Error(), err)
+	}
+
+	return NewBadRequestError("", err)
+}
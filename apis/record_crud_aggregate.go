@@ -0,0 +1,188 @@
+package apis
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+// aggregate returns the aggregated values (count, sum, avg, min, max)
+// of the collection records matching the list rule and the ?filter
+// query parameter, optionally grouped by one or more record fields.
+//
+// Example:
+//
+//	GET /api/collections/orders/aggregate?select=count(),sum(amount)&groupBy=status
+func (api *recordApi) aggregate(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return NewNotFoundError("", "Missing collection context.")
+	}
+
+	requestInfo := RequestInfo(c)
+	listRule := collection.TenantScopedRule(collection.ListRule)
+
+	// forbid users and guests to query special filter fields
+	if err := checkForAdminOnlyRuleFields(requestInfo); err != nil {
+		return err
+	}
+
+	if requestInfo.Admin == nil && listRule == nil {
+		// only admins can access if the rule is nil
+		return NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	fields, err := search.ParseAggregateFields(c.QueryParam(search.AggregateSelectQueryParam))
+	if err != nil {
+		return NewBadRequestError("Invalid select aggregate expression.", err)
+	}
+	if len(fields) == 0 {
+		return NewBadRequestError("At least one select aggregate expression is required (eg. ?select=count()).", nil)
+	}
+
+	groupBy, err := search.ParseGroupByFromString(c.QueryParam(search.AggregateGroupByQueryParam))
+	if err != nil {
+		return NewBadRequestError("Invalid groupBy field.", err)
+	}
+
+	if err := validateAggregateFields(collection, fields, groupBy); err != nil {
+		return NewBadRequestError(err.Error(), nil)
+	}
+
+	fieldsResolver := resolvers.NewRecordFieldResolver(
+		api.dao(c),
+		collection,
+		requestInfo,
+		// hidden fields are searchable only by admins
+		requestInfo.Admin != nil,
+	)
+
+	query := api.dao(c).RecordQuery(collection)
+
+	if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, query, core.RecordsQueryActionAggregate); err != nil {
+		return err
+	}
+
+	aggregator := search.NewAggregator(fieldsResolver).
+		Query(query).
+		Fields(fields).
+		GroupBy(groupBy)
+
+	if requestInfo.Admin == nil && listRule != nil {
+		aggregator.AddFilter(search.FilterData(*listRule))
+	}
+
+	aggregator.AddFilter(search.FilterData(c.QueryParam(search.FilterQueryParam)))
+
+	rows, err := aggregator.Exec()
+	if err != nil {
+		return NewBadRequestError("Failed to aggregate the records. Make sure that the filter and the aggregate fields are valid.", err)
+	}
+
+	normalizeAggregateGroupValues(collection, groupBy, rows)
+
+	return c.JSON(http.StatusOK, map[string]any{"items": rows})
+}
+
+// validateAggregateFields checks whether the provided aggregate and
+// group by fields are direct collection fields that could be aggregated.
+//
+// The sum and avg functions are allowed only for number fields, while
+// min and max could be also used with the date and created/updated fields.
+func validateAggregateFields(collection *models.Collection, fields []search.AggregateField, groupBy []string) error {
+	for _, f := range fields {
+		if f.Func == search.AggregateCount {
+			continue
+		}
+
+		fieldType := aggregateFieldType(collection, f.Field)
+
+		switch f.Func {
+		case search.AggregateSum, search.AggregateAvg:
+			if fieldType != schema.FieldTypeNumber && fieldType != schema.FieldTypeDuration {
+				return fmt.Errorf("The %s() function could be used only with number and duration fields.", f.Func)
+			}
+		case search.AggregateMin, search.AggregateMax:
+			if fieldType != schema.FieldTypeNumber && fieldType != schema.FieldTypeDate && fieldType != schema.FieldTypeDuration {
+				return fmt.Errorf("The %s() function could be used only with number, date and duration fields.", f.Func)
+			}
+		}
+	}
+
+	for _, name := range groupBy {
+		if !isGroupableField(collection, name) {
+			return fmt.Errorf("Field %q cannot be used to group by.", name)
+		}
+	}
+
+	return nil
+}
+
+// aggregateFieldType returns the schema type of the provided direct
+// collection field name (the created and updated system fields are
+// treated as date fields).
+//
+// Returns empty string if the field doesn't exist.
+func aggregateFieldType(collection *models.Collection, name string) string {
+	if name == schema.FieldNameCreated || name == schema.FieldNameUpdated {
+		return schema.FieldTypeDate
+	}
+
+	if field := collection.Schema.GetFieldByName(name); field != nil {
+		return field.Type
+	}
+
+	return ""
+}
+
+// isGroupableField reports whether the provided direct collection field
+// has a single scalar value that could be used in a group by clause.
+func isGroupableField(collection *models.Collection, name string) bool {
+	if name == schema.FieldNameId || name == schema.FieldNameCreated || name == schema.FieldNameUpdated {
+		return true
+	}
+
+	field := collection.Schema.GetFieldByName(name)
+	if field == nil {
+		return false
+	}
+
+	switch field.Type {
+	case schema.FieldTypeText,
+		schema.FieldTypeNumber,
+		schema.FieldTypeBool,
+		schema.FieldTypeEmail,
+		schema.FieldTypeUrl,
+		schema.FieldTypeDate,
+		schema.FieldTypeDuration,
+		schema.FieldTypeColor:
+		return true
+	case schema.FieldTypeSelect, schema.FieldTypeRelation:
+		opt, ok := field.Options.(schema.MultiValuer)
+		return ok && !opt.IsMultiple()
+	}
+
+	return false
+}
+
+// normalizeAggregateGroupValues casts the raw db group values
+// of the bool fields to their bool representation.
+func normalizeAggregateGroupValues(collection *models.Collection, groupBy []string, rows []map[string]any) {
+	for _, name := range groupBy {
+		field := collection.Schema.GetFieldByName(name)
+		if field == nil || field.Type != schema.FieldTypeBool {
+			continue
+		}
+
+		for _, row := range rows {
+			row[name] = cast.ToBool(row[name])
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package apis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// csvContentType is the content type of the streamed csv list results.
+const csvContentType = "text/csv"
+
+// isCsvRequest reports whether the list results should be streamed
+// as csv (?format=csv or "Accept: text/csv").
+func isCsvRequest(c echo.Context) bool {
+	return c.QueryParam("format") == "csv" ||
+		strings.Contains(c.Request().Header.Get(echo.HeaderAccept), csvContentType)
+}
+
+// csvListEncoder streams the list results as csv.
+//
+// The csv columns are resolved from the ?fields query parameter
+// (see [csvColumns]) and the first written row is always the header.
+type csvListEncoder struct {
+	collection    *models.Collection
+	columns       []string
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+// newCsvListEncoder creates a new csv list encoder for the provided
+// request context and collection.
+func newCsvListEncoder(c echo.Context, collection *models.Collection) *csvListEncoder {
+	return &csvListEncoder{
+		collection: collection,
+		columns:    csvColumns(collection, c.QueryParam(fieldsQueryParam)),
+	}
+}
+
+func (enc *csvListEncoder) Headers() map[string]string {
+	return map[string]string{
+		echo.HeaderContentType:        csvContentType + "; charset=utf-8",
+		echo.HeaderContentDisposition: fmt.Sprintf("attachment; filename=%q", enc.collection.Name+".csv"),
+	}
+}
+
+// Expands returns the relation fields of the "rel.field" columns
+// since their display value is read from the expanded record.
+func (enc *csvListEncoder) Expands() []string {
+	var result []string
+
+	for _, column := range enc.columns {
+		rel, _, ok := strings.Cut(column, ".")
+		if !ok || list.ExistInSlice(rel, result) {
+			continue
+		}
+
+		if field := enc.collection.Schema.GetFieldByName(rel); field != nil && field.Type == schema.FieldTypeRelation {
+			result = append(result, rel)
+		}
+	}
+
+	return result
+}
+
+func (enc *csvListEncoder) Encode(c echo.Context, records []*models.Record) error {
+	if enc.writer == nil {
+		enc.writer = csv.NewWriter(c.Response())
+	}
+
+	if !enc.headerWritten {
+		if err := enc.writer.Write(enc.columns); err != nil {
+			return err
+		}
+		enc.headerWritten = true
+	}
+
+	row := make([]string, len(enc.columns))
+
+	for _, record := range records {
+		data := record.PublicExport()
+
+		for i, column := range enc.columns {
+			rel, relField, ok := strings.Cut(column, ".")
+			if !ok {
+				row[i] = csvValue(data[column])
+				continue
+			}
+
+			// relation display value
+			values := []string{}
+			for _, expanded := range record.ExpandedAll(rel) {
+				values = append(values, csvValue(expanded.PublicExport()[relField]))
+			}
+			row[i] = strings.Join(values, ",")
+		}
+
+		if err := enc.writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	enc.writer.Flush()
+
+	return enc.writer.Error()
+}
+
+// csvColumns resolves the csv columns from the provided comma separated
+// fields list (eg. "id,title,author.name").
+//
+// The field modifiers (eg. ":excerpt(10)") and the "expand." prefixes are ignored.
+// Relation fields could be suffixed with the name of a field from the related
+// collection (eg. "author.name") to export that field instead of the related ids.
+//
+// If fields is empty or contains "*", the default collection columns
+// are used (all fields except the json ones).
+func csvColumns(collection *models.Collection, fields string) []string {
+	var result []string
+
+	for _, f := range strings.Split(fields, ",") {
+		f, _, _ = strings.Cut(strings.TrimSpace(f), ":")
+		f = strings.TrimPrefix(f, schema.FieldNameExpand+".")
+
+		if f == "" {
+			continue
+		}
+
+		if f == "*" {
+			result = append(result, csvDefaultColumns(collection)...)
+			continue
+		}
+
+		result = append(result, f)
+	}
+
+	if len(result) == 0 {
+		return csvDefaultColumns(collection)
+	}
+
+	return list.NonzeroUniques(result)
+}
+
+// csvDefaultColumns returns the default csv columns of the provided collection.
+func csvDefaultColumns(collection *models.Collection) []string {
+	result := []string{schema.FieldNameId}
+
+	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeJson {
+			continue
+		}
+		result = append(result, field.Name)
+	}
+
+	if collection.IsAuth() {
+		result = append(
+			result,
+			schema.FieldNameUsername,
+			schema.FieldNameEmail,
+			schema.FieldNameEmailVisibility,
+			schema.FieldNameVerified,
+		)
+	}
+
+	if !collection.IsView() {
+		result = append(result, schema.FieldNameCreated, schema.FieldNameUpdated)
+	}
+
+	return result
+}
+
+// csvValue flattens the provided record field value to a single csv cell.
+//
+// Multiple values (eg. relation ids) are joined with comma and
+// the json values are exported as their raw serialized json.
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case types.DateTime:
+		return v.String()
+	case types.JsonRaw:
+		return v.String()
+	case bool, int, int64, float64:
+		return cast.ToString(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return cast.ToString(v)
+		}
+		return string(raw)
+	}
+}
@@ -1,29 +1,47 @@
 package apis
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/resolvers"
 	"github.com/pocketbase/pocketbase/tokens"
 	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
 )
 
 const ContextRequestInfoKey = "requestInfo"
 
 const expandQueryParam = "expand"
+const expandDepthQueryParam = "expandDepth"
 const fieldsQueryParam = "fields"
 
+// contextExpandMaxDepthKey is the context key that holds
+// the effective max expand depth of the current request.
+const contextExpandMaxDepthKey = "expandMaxDepth"
+
+// contextAppKey is the context key that holds the app instance
+// (used to trigger the app hooks from the app independent helpers).
+const contextAppKey = "app"
+
 // Deprecated: Use RequestInfo instead.
 func RequestData(c echo.Context) *models.RequestInfo {
 	log.Println("RequestData(c) is deprecated and will be removed in the future! You can replace it with RequestInfo(c).")
@@ -44,11 +62,12 @@ func RequestInfo(c echo.Context) *models.RequestInfo {
 	}
 
 	result := &models.RequestInfo{
-		Context: models.RequestInfoContextDefault,
-		Method:  c.Request().Method,
-		Query:   map[string]any{},
-		Data:    map[string]any{},
-		Headers: map[string]any{},
+		Context:  models.RequestInfoContextDefault,
+		Method:   c.Request().Method,
+		RemoteIp: RealIp(c),
+		Query:    map[string]any{},
+		Data:     map[string]any{},
+		Headers:  map[string]any{},
 	}
 
 	// extract the first value of all headers and normalizes the keys
@@ -61,6 +80,7 @@ func RequestInfo(c echo.Context) *models.RequestInfo {
 
 	result.AuthRecord, _ = c.Get(ContextAuthRecordKey).(*models.Record)
 	result.Admin, _ = c.Get(ContextAdminKey).(*models.Admin)
+	result.ApiKey, _ = c.Get(ContextApiKeyKey).(*models.ApiKey)
 	echo.BindQueryParams(c, &result.Query)
 	rest.BindBody(c, &result.Data)
 
@@ -77,6 +97,22 @@ func RecordAuthResponse(
 	authRecord *models.Record,
 	meta any,
 	finalizers ...func(token string) error,
+) error {
+	return recordAuthResponse(app, c, authRecord, "", meta, finalizers...)
+}
+
+// recordAuthResponse writes standardised json record auth response
+// into the specified request context.
+//
+// If the auth record collection allows refresh tokens, a new refresh token
+// is also issued in the refreshTokenFamilyId family (or in a new one if empty).
+func recordAuthResponse(
+	app core.App,
+	c echo.Context,
+	authRecord *models.Record,
+	refreshTokenFamilyId string,
+	meta any,
+	finalizers ...func(token string) error,
 ) error {
 	if !authRecord.Verified() && authRecord.Collection().AuthOptions().OnlyVerified {
 		return NewForbiddenError("Please verify your account first.", nil)
@@ -87,11 +123,36 @@ func RecordAuthResponse(
 		return NewBadRequestError("Failed to create auth token.", tokenErr)
 	}
 
+	var refreshToken string
+	if authRecord.Collection().AuthOptions().AllowRefreshTokens {
+		var refreshTokenErr error
+		refreshToken, refreshTokenErr = newRecordRefreshToken(app, authRecord, refreshTokenFamilyId)
+		if refreshTokenErr != nil {
+			return NewBadRequestError("Failed to create refresh token.", refreshTokenErr)
+		}
+	}
+
+	return recordAuthTokenResponse(app, c, authRecord, token, refreshToken, meta, finalizers...)
+}
+
+// recordAuthTokenResponse writes standardised json record auth response
+// with the already generated token (and optional refresh token)
+// into the specified request context.
+func recordAuthTokenResponse(
+	app core.App,
+	c echo.Context,
+	authRecord *models.Record,
+	token string,
+	refreshToken string,
+	meta any,
+	finalizers ...func(token string) error,
+) error {
 	event := new(core.RecordAuthEvent)
 	event.HttpContext = c
 	event.Collection = authRecord.Collection()
 	event.Record = authRecord
 	event.Token = token
+	event.RefreshToken = refreshToken
 	event.Meta = meta
 
 	return app.OnRecordAuthRequest().Trigger(event, func(e *core.RecordAuthEvent) error {
@@ -102,28 +163,45 @@ func RecordAuthResponse(
 		// allow always returning the email address of the authenticated account
 		e.Record.IgnoreEmailVisibility(true)
 
+		// create a copy of the cached request data and adjust it to the current auth record
+		requestInfo := *RequestInfo(e.HttpContext)
+		requestInfo.Admin = nil
+		requestInfo.AuthRecord = e.Record
+
+		if err := autoHideRecordsRestrictedFields(app.Dao(), []*models.Record{e.Record}, &requestInfo); err != nil {
+			app.Logger().Debug("[RecordAuthResponse] Failed to resolve the fields view access", slog.String("error", err.Error()))
+		}
+
 		// expand record relations
 		expands := strings.Split(c.QueryParam(expandQueryParam), ",")
 		if len(expands) > 0 {
-			// create a copy of the cached request data and adjust it to the current auth record
-			requestInfo := *RequestInfo(e.HttpContext)
-			requestInfo.Admin = nil
-			requestInfo.AuthRecord = e.Record
-			failed := app.Dao().ExpandRecord(
-				e.Record,
+			failed := app.Dao().ExpandRecordsWithOptions(
+				[]*models.Record{e.Record},
 				expands,
-				expandFetch(app.Dao(), &requestInfo),
+				daos.ExpandOptions{
+					FetchFunc:        expandFetch(app, e.HttpContext, app.Dao(), &requestInfo),
+					BackRelFetchFunc: expandBackRelFetch(app, e.HttpContext, app.Dao(), &requestInfo),
+					BackRelQueryFunc: expandBackRelQuery(app.Dao(), &requestInfo),
+				},
 			)
 			if len(failed) > 0 {
 				app.Logger().Debug("[RecordAuthResponse] Failed to expand relations", slog.Any("errors", failed))
 			}
 		}
 
+		if err := triggerRecordEnrichHook(app, e.HttpContext, []*models.Record{e.Record}); err != nil {
+			return err
+		}
+
 		result := map[string]any{
 			"token":  e.Token,
 			"record": e.Record,
 		}
 
+		if e.RefreshToken != "" {
+			result["refreshToken"] = e.RefreshToken
+		}
+
 		if e.Meta != nil {
 			result["meta"] = e.Meta
 		}
@@ -142,6 +220,7 @@ func RecordAuthResponse(
 //   - expands relations (if defaultExpands and/or ?expand query param is set)
 //   - ensures that the emails of the auth record and its expanded auth relations
 //     are visible only for the current logged admin, record owner or record with manage access
+//   - hides the record fields whose field view rule is not satisfied by the current request
 func EnrichRecord(c echo.Context, dao *daos.Dao, record *models.Record, defaultExpands ...string) error {
 	return EnrichRecords(c, dao, []*models.Record{record}, defaultExpands...)
 }
@@ -150,6 +229,7 @@ func EnrichRecord(c echo.Context, dao *daos.Dao, record *models.Record, defaultE
 //   - expands relations (if defaultExpands and/or ?expand query param is set)
 //   - ensures that the emails of the auth records and their expanded auth relations
 //     are visible only for the current logged admin, record owner or record with manage access
+//   - hides the record fields whose field view rule is not satisfied by the current request
 func EnrichRecords(c echo.Context, dao *daos.Dao, records []*models.Record, defaultExpands ...string) error {
 	requestInfo := RequestInfo(c)
 
@@ -157,6 +237,10 @@ func EnrichRecords(c echo.Context, dao *daos.Dao, records []*models.Record, defa
 		return fmt.Errorf("failed to resolve email visibility: %w", err)
 	}
 
+	if err := autoHideRecordsRestrictedFields(dao, records, requestInfo); err != nil {
+		return fmt.Errorf("failed to resolve the fields view access: %w", err)
+	}
+
 	expands := defaultExpands
 	if param := c.QueryParam(expandQueryParam); param != "" {
 		expands = append(expands, strings.Split(param, ",")...)
@@ -165,7 +249,17 @@ func EnrichRecords(c echo.Context, dao *daos.Dao, records []*models.Record, defa
 		return nil // nothing to expand
 	}
 
-	errs := dao.ExpandRecords(records, expands, expandFetch(dao, requestInfo))
+	maxDepth, _ := c.Get(contextExpandMaxDepthKey).(int)
+
+	app, _ := c.Get(contextAppKey).(core.App)
+
+	errs := dao.ExpandRecordsWithOptions(records, expands, daos.ExpandOptions{
+		FetchFunc:        expandFetch(app, c, dao, requestInfo),
+		BackRelFetchFunc: expandBackRelFetch(app, c, dao, requestInfo),
+		BackRelQueryFunc: expandBackRelQuery(dao, requestInfo),
+		MaxDepth:         maxDepth,
+		SkipCycles:       true,
+	})
 	if len(errs) > 0 {
 		return fmt.Errorf("failed to expand: %v", errs)
 	}
@@ -173,42 +267,229 @@ func EnrichRecords(c echo.Context, dao *daos.Dao, records []*models.Record, defa
 	return nil
 }
 
+// loadTenantDefault loads in the create form the tenant of the
+// authenticated record if the collection is tenant-scoped
+// and its tenant field value is not submitted.
+func loadTenantDefault(form *forms.RecordUpsert, collection *models.Collection, requestInfo *models.RequestInfo) error {
+	field := collection.TenantField()
+	if field == "" || requestInfo.AuthRecord == nil || cast.ToString(form.Data()[field]) != "" {
+		return nil
+	}
+
+	return form.LoadData(map[string]any{field: requestInfo.AuthRecord.GetString(field)})
+}
+
+// triggerRecordsQueryHook triggers the app OnRecordsQuery hook
+// for the provided base records query of the current request
+// (c is nil for the realtime broadcast queries).
+//
+// It should be called before applying the collection access rule filter.
+func triggerRecordsQueryHook(
+	app core.App,
+	c echo.Context,
+	requestInfo *models.RequestInfo,
+	collection *models.Collection,
+	query *dbx.SelectQuery,
+	action string,
+) error {
+	event := new(core.RecordsQueryEvent)
+	event.HttpContext = c
+	event.Collection = collection
+	event.RequestInfo = requestInfo
+	event.Query = query
+	event.Action = action
+
+	return app.OnRecordsQuery().Trigger(event)
+}
+
+// triggerRecordEnrichHook triggers the app OnRecordEnrich hook for each
+// of the provided response records and their expanded relations.
+//
+// It should be called right before the records serialization
+// (aka. after the [EnrichRecords] call).
+func triggerRecordEnrichHook(app core.App, c echo.Context, records []*models.Record) error {
+	requestInfo := RequestInfo(c)
+
+	// the same record could be expanded multiple times
+	visited := map[*models.Record]struct{}{}
+
+	var trigger func(records []*models.Record) error
+	trigger = func(records []*models.Record) error {
+		for _, record := range records {
+			if record == nil {
+				continue
+			}
+
+			if _, ok := visited[record]; ok {
+				continue
+			}
+			visited[record] = struct{}{}
+
+			event := new(core.RecordEnrichEvent)
+			event.HttpContext = c
+			event.Collection = record.Collection()
+			event.Record = record
+			event.RequestInfo = requestInfo
+
+			if err := app.OnRecordEnrich().Trigger(event); err != nil {
+				return err
+			}
+
+			for _, expanded := range record.Expand() {
+				switch v := expanded.(type) {
+				case *models.Record:
+					if err := trigger([]*models.Record{v}); err != nil {
+						return err
+					}
+				case []*models.Record:
+					if err := trigger(v); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return trigger(records)
+}
+
+// checkExpandDepth resolves the effective max expand depth of the
+// current request and validates the ?expand query parameter against it.
+//
+// The effective max expand depth is the app MaxExpandDepth setting
+// that could be only lowered with the ?expandDepth query parameter.
+func checkExpandDepth(app core.App, c echo.Context) error {
+	maxDepth := app.Settings().Meta.MaxExpandDepth
+	if maxDepth <= 0 {
+		maxDepth = daos.MaxExpandDepth
+	}
+
+	if raw := c.QueryParam(expandDepthQueryParam); raw != "" {
+		requested, err := strconv.Atoi(raw)
+		if err != nil || requested < 1 {
+			return NewBadRequestError("Invalid expandDepth value - it must be a positive integer.", nil)
+		}
+		maxDepth = min(maxDepth, requested)
+	}
+
+	c.Set(contextExpandMaxDepthKey, maxDepth)
+
+	for _, path := range strings.Split(c.QueryParam(expandQueryParam), ",") {
+		path = strings.Trim(strings.ReplaceAll(path, " ", ""), ".")
+		if path != "" && strings.Count(path, ".")+1 > maxDepth {
+			return NewBadRequestError(
+				fmt.Sprintf("The expand path %q exceeds the max allowed depth of %d.", path, maxDepth),
+				nil,
+			)
+		}
+	}
+
+	return nil
+}
+
 // expandFetch is the records fetch function that is used to expand related records.
+//
+// If app is set, its OnRecordsQuery hook is triggered for each fetch query.
 func expandFetch(
+	app core.App,
+	c echo.Context,
 	dao *daos.Dao,
 	requestInfo *models.RequestInfo,
 ) daos.ExpandFetchFunc {
-	return func(relCollection *models.Collection, relIds []string) ([]*models.Record, error) {
-		records, err := dao.FindRecordsByIds(relCollection.Id, relIds, func(q *dbx.SelectQuery) error {
-			if requestInfo.Admin != nil {
-				return nil // admins can access everything
-			}
+	return expandFetchWithRule(app, c, dao, requestInfo, "view", func(c *models.Collection) *string {
+		return c.ViewRule
+	})
+}
 
-			if relCollection.ViewRule == nil {
-				return fmt.Errorf("only admins can view collection %q records", relCollection.Name)
-			}
+// expandBackRelFetch is the records fetch function that is used to
+// expand back-related records (they are checked against the list rule
+// since they are not directly referenced by the expanded record).
+func expandBackRelFetch(
+	app core.App,
+	c echo.Context,
+	dao *daos.Dao,
+	requestInfo *models.RequestInfo,
+) daos.ExpandFetchFunc {
+	return expandFetchWithRule(app, c, dao, requestInfo, "list", func(c *models.Collection) *string {
+		return c.ListRule
+	})
+}
+
+// expandBackRelQuery is the back-relation ids query function that
+// applies the list rule before the ":limit(n)" expand modifier
+// (so that the limit caps only the records that could be listed).
+func expandBackRelQuery(
+	dao *daos.Dao,
+	requestInfo *models.RequestInfo,
+) func(relCollection *models.Collection, q *dbx.SelectQuery) error {
+	return func(relCollection *models.Collection, q *dbx.SelectQuery) error {
+		return applyExpandRule(dao, requestInfo, relCollection, q, "list", relCollection.ListRule)
+	}
+}
 
-			if *relCollection.ViewRule != "" {
-				resolver := resolvers.NewRecordFieldResolver(dao, relCollection, requestInfo, true)
-				expr, err := search.FilterData(*(relCollection.ViewRule)).BuildExpr(resolver)
-				if err != nil {
+func expandFetchWithRule(
+	app core.App,
+	c echo.Context,
+	dao *daos.Dao,
+	requestInfo *models.RequestInfo,
+	ruleName string,
+	ruleFunc func(c *models.Collection) *string,
+) daos.ExpandFetchFunc {
+	return func(relCollection *models.Collection, relIds []string) ([]*models.Record, error) {
+		records, err := dao.FindRecordsByIds(relCollection.Id, relIds, func(q *dbx.SelectQuery) error {
+			if app != nil {
+				if err := triggerRecordsQueryHook(app, c, requestInfo, relCollection, q, core.RecordsQueryActionExpand); err != nil {
 					return err
 				}
-				resolver.UpdateQuery(q)
-				q.AndWhere(expr)
 			}
 
-			return nil
+			return applyExpandRule(dao, requestInfo, relCollection, q, ruleName, ruleFunc(relCollection))
 		})
 
 		if err == nil && len(records) > 0 {
 			autoIgnoreAuthRecordsEmailVisibility(dao, records, requestInfo)
+			autoHideRecordsRestrictedFields(dao, records, requestInfo)
 		}
 
 		return records, err
 	}
 }
 
+// applyExpandRule filters the provided expand query with the
+// tenant scoped collection access rule (admins can access everything).
+func applyExpandRule(
+	dao *daos.Dao,
+	requestInfo *models.RequestInfo,
+	relCollection *models.Collection,
+	q *dbx.SelectQuery,
+	ruleName string,
+	rule *string,
+) error {
+	if requestInfo.Admin != nil {
+		return nil // admins can access everything
+	}
+
+	rule = relCollection.TenantScopedRule(rule)
+
+	if rule == nil {
+		return fmt.Errorf("only admins can %s collection %q records", ruleName, relCollection.Name)
+	}
+
+	if *rule != "" {
+		resolver := resolvers.NewRecordFieldResolver(dao, relCollection, requestInfo, true)
+		expr, err := search.FilterData(*rule).BuildExpr(resolver)
+		if err != nil {
+			return err
+		}
+		resolver.UpdateQuery(q)
+		q.AndWhere(expr)
+	}
+
+	return nil
+}
+
 // autoIgnoreAuthRecordsEmailVisibility ignores the email visibility check for
 // the provided record if the current auth model is admin, owner or a "manager".
 //
@@ -278,6 +559,119 @@ func autoIgnoreAuthRecordsEmailVisibility(
 	return nil
 }
 
+// autoHideRecordsRestrictedFields hides the fields of the provided records
+// whose field view rule is not satisfied by the current request.
+//
+// Each field view rule is resolved with a single query for all records
+// (admins can see all fields). On rule resolve error the related field
+// is hidden from all records.
+//
+// Note: Expects all records to be from the same collection!
+func autoHideRecordsRestrictedFields(
+	dao *daos.Dao,
+	records []*models.Record,
+	requestInfo *models.RequestInfo,
+) error {
+	if len(records) == 0 || requestInfo == nil || requestInfo.Admin != nil {
+		return nil // nothing to check
+	}
+
+	collection := records[0].Collection()
+
+	recordIds := make([]any, len(records))
+	for i, rec := range records {
+		recordIds[i] = rec.Id
+	}
+
+	var firstErr error
+
+	for _, field := range collection.Schema.Fields() {
+		if field.ViewRule == "" {
+			continue
+		}
+
+		// fetch the ids of the records that satisfy the field rule
+		// ---
+		allowedIds := []string{}
+
+		query := dao.RecordQuery(collection).
+			Select(dao.DB().QuoteSimpleColumnName(collection.Name) + ".id").
+			AndWhere(dbx.In(dao.DB().QuoteSimpleColumnName(collection.Name)+".id", recordIds...))
+
+		resolver := resolvers.NewRecordFieldResolver(dao, collection, requestInfo, true)
+		expr, err := search.FilterData(field.ViewRule).BuildExpr(resolver)
+		if err == nil {
+			resolver.UpdateQuery(query)
+			query.AndWhere(expr)
+			err = query.Column(&allowedIds)
+		}
+
+		// hide the field from all records on failure
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		// ---
+
+		for _, rec := range records {
+			if !list.ExistInSlice(rec.Id, allowedIds) {
+				rec.Hide(field.Name)
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// checkRecordFieldsEditAccess checks whether the current request is allowed
+// to change the record fields that have a field edit rule.
+//
+// Only the fields whose data value differs from the original record
+// field value are checked (admins can change all fields).
+//
+// Returns [validation.Errors] with the forbidden fields (if any).
+func checkRecordFieldsEditAccess(
+	dao *daos.Dao,
+	record *models.Record,
+	original *models.Record,
+	data map[string]any,
+	requestInfo *models.RequestInfo,
+) error {
+	if requestInfo == nil || requestInfo.Admin != nil {
+		return nil // admins can change everything
+	}
+
+	errs := validation.Errors{}
+
+	for _, field := range record.Collection().Schema.Fields() {
+		if field.EditRule == "" {
+			continue
+		}
+
+		value, ok := data[field.Name]
+		if !ok || reflect.DeepEqual(field.PrepareValue(value), original.Get(field.Name)) {
+			continue // unchanged
+		}
+
+		canEdit, err := dao.CanAccessRecord(record, requestInfo, &field.EditRule)
+		if err != nil {
+			return err
+		}
+
+		if !canEdit {
+			errs[field.Name] = validation.NewError(
+				"validation_field_edit_forbidden",
+				"You are not allowed to change this field.",
+			)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
 // hasAuthManageAccess checks whether the client is allowed to have full
 // [forms.RecordUpsert] auth management permissions
 // (aka. allowing to change system auth fields without oldPassword).
@@ -343,3 +737,47 @@ func checkForAdminOnlyRuleFields(requestInfo *models.RequestInfo) error {
 
 	return nil
 }
+
+// responseETag returns a strong ETag value for the provided final
+// (aka. already enriched) response data.
+//
+// The ETag is derived from the serialized data and the requested
+// response fields, so that any change in the response (including the
+// expanded relations and raw db updates that don't change the
+// record updated date) results in a different ETag.
+func responseETag(c echo.Context, data any) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write(raw)
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.QueryParam(fieldsQueryParam)))
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`, nil
+}
+
+// matchETag reports whether the If-None-Match header value
+// matches the provided etag (using weak comparison).
+func matchETag(ifNoneMatch string, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	if ifNoneMatch == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,75 @@
+package apis
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// bindRecordShareApi registers the public record share api endpoint.
+func bindRecordShareApi(app core.App, rg *echo.Group) {
+	api := recordShareApi{app: app}
+
+	subGroup := rg.Group("/share", ActivityLogger(app))
+	subGroup.GET("/:token", api.view)
+}
+
+type recordShareApi struct {
+	app core.App
+}
+
+// view returns the record associated with the provided share token
+// (the token is minted with [tokens.NewRecordShareToken]).
+//
+// The collection API rules are not checked but the record
+// is still enriched as for a guest request (eg. hidden email).
+func (api *recordShareApi) view(c echo.Context) error {
+	record, fields, err := api.app.Dao().FindRecordByShareToken(
+		c.PathParam("token"),
+		api.app.Settings().RecordShareToken.Secret,
+	)
+	if err != nil {
+		if errors.Is(err, daos.ErrInvalidShareToken) {
+			return NewBadRequestError("Invalid or expired share token.", nil)
+		}
+		return NewNotFoundError("", err)
+	}
+
+	if err := EnrichRecord(c, api.app.Dao(), record); err != nil {
+		api.app.Logger().Debug(
+			"Failed to enrich shared record",
+			slog.String("id", record.Id),
+			slog.String("collectionName", record.Collection().Name),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	if err := triggerRecordEnrichHook(api.app, c, []*models.Record{record}); err != nil {
+		return err
+	}
+
+	data := record.PublicExport()
+
+	if len(fields) > 0 {
+		allowed := append([]string{
+			schema.FieldNameId,
+			schema.FieldNameCollectionId,
+			schema.FieldNameCollectionName,
+		}, fields...)
+
+		for k := range data {
+			if !list.ExistInSlice(k, allowed) {
+				delete(data, k)
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
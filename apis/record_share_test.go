@@ -0,0 +1,151 @@
+package apis_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestRecordShareView(t *testing.T) {
+	t.Parallel()
+
+	const shareSecret = "test_share_secret_test_share_secret"
+
+	newToken := func(recordId string, expires time.Time, fields string) string {
+		payload := fmt.Sprintf(`{"c":"_pb_users_auth_","r":%q,"e":%d%s}`, recordId, expires.Unix(), fields)
+		return security.NewSignedToken([]byte(payload), shareSecret)
+	}
+
+	enableSharing := func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+		collection, err := app.Dao().FindCollectionByNameOrId("users")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		collection.Options.Set("allowShare", true)
+
+		if err := app.Dao().SaveCollection(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		app.Settings().RecordShareToken.Secret = shareSecret
+
+		app.ResetEventCalls()
+	}
+
+	validToken := newToken("4q1xlclmfloku33", time.Now().Add(1*time.Hour), "")
+
+	payload, signature, _ := strings.Cut(validToken, ".")
+	tamperedPayloadToken := newToken("oap640cot4yru2s", time.Now().Add(1*time.Hour), "")[:len(payload)] + "." + signature
+	tamperedSignatureToken := payload + "." + signature + "x"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "malformed token",
+			Method:          http.MethodGet,
+			Url:             "/api/share/invalid",
+			BeforeTestFunc:  enableSharing,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "valid token with disabled collection sharing",
+			Method:          http.MethodGet,
+			Url:             "/api/share/" + validToken,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "expired token",
+			Method:          http.MethodGet,
+			Url:             "/api/share/" + newToken("4q1xlclmfloku33", time.Now().Add(-1*time.Second), ""),
+			BeforeTestFunc:  enableSharing,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "tampered signature",
+			Method:          http.MethodGet,
+			Url:             "/api/share/" + tamperedSignatureToken,
+			BeforeTestFunc:  enableSharing,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "tampered payload",
+			Method:          http.MethodGet,
+			Url:             "/api/share/" + tamperedPayloadToken,
+			BeforeTestFunc:  enableSharing,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "revoked token (changed settings secret)",
+			Method: http.MethodGet,
+			Url:    "/api/share/" + validToken,
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				enableSharing(t, app, e)
+
+				app.Settings().RecordShareToken.Secret = shareSecret + "_changed"
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "valid token for missing record",
+			Method:          http.MethodGet,
+			Url:             "/api/share/" + newToken("missing", time.Now().Add(1*time.Hour), ""),
+			BeforeTestFunc:  enableSharing,
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:           "valid token",
+			Method:         http.MethodGet,
+			Url:            "/api/share/" + validToken,
+			BeforeTestFunc: enableSharing,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"4q1xlclmfloku33"`,
+				`"collectionName":"users"`,
+				`"emailVisibility":false`,
+				`"verified":`,
+			},
+			NotExpectedContent: []string{
+				// guest request
+				`"email":"test@example.com"`,
+				`"tokenKey"`,
+				`"passwordHash"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 0, "OnRecordViewRequest": 0},
+		},
+		{
+			Name:           "valid token with restricted fields",
+			Method:         http.MethodGet,
+			Url:            "/api/share/" + newToken("4q1xlclmfloku33", time.Now().Add(1*time.Hour), `,"f":["verified"]`),
+			BeforeTestFunc: enableSharing,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"4q1xlclmfloku33"`,
+				`"collectionId":"_pb_users_auth_"`,
+				`"collectionName":"users"`,
+				`"verified":`,
+			},
+			NotExpectedContent: []string{
+				`"created"`,
+				`"username"`,
+				`"emailVisibility"`,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,146 @@
+package apis
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// presignUpload handles the first step of a direct file upload
+// by returning a short-lived presigned storage upload url for a record file field.
+func (api *recordApi) presignUpload(c echo.Context) error {
+	record, err := api.findUpdatableRecord(c)
+	if err != nil {
+		return err
+	}
+
+	form, err := forms.NewRecordFilePresign(api.app, record, c.PathParam("field"))
+	if err != nil {
+		return NewNotFoundError("", err)
+	}
+
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	if err := form.Validate(); err != nil {
+		return NewBadRequestError("Failed to presign the file upload.", err)
+	}
+
+	result, err := form.Submit()
+	if err != nil {
+		return NewBadRequestError("Failed to presign the file upload. Make sure that the storage supports presigned urls (eg. S3).", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// confirmUpload handles the second step of a direct file upload
+// by validating the uploaded file and assigning it to the record file field.
+func (api *recordApi) confirmUpload(c echo.Context) error {
+	record, err := api.findUpdatableRecord(c)
+	if err != nil {
+		return err
+	}
+
+	form, err := forms.NewRecordFileConfirm(api.app, record, c.PathParam("field"))
+	if err != nil {
+		return NewNotFoundError("", err)
+	}
+	form.SetDao(api.dao(c))
+
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("An error occurred while loading the submitted data.", err)
+	}
+
+	event := new(core.RecordUpdateEvent)
+	event.HttpContext = c
+	event.Collection = record.Collection()
+	event.Record = record
+
+	return form.Submit(func(next forms.InterceptorNextFunc[*models.Record]) forms.InterceptorNextFunc[*models.Record] {
+		return func(m *models.Record) error {
+			event.Record = m
+
+			return api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
+				if err := next(e.Record); err != nil {
+					return NewBadRequestError("Failed to confirm the file upload.", err)
+				}
+
+				if err := EnrichRecord(e.HttpContext, api.dao(c), e.Record); err != nil {
+					api.app.Logger().Debug(
+						"Failed to enrich confirm upload record",
+						slog.String("id", e.Record.Id),
+						slog.String("collectionName", e.Record.Collection().Name),
+						slog.String("error", err.Error()),
+					)
+				}
+
+				return api.app.OnRecordAfterUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
+					if e.HttpContext.Response().Committed {
+						return nil
+					}
+
+					if err := triggerRecordEnrichHook(api.app, e.HttpContext, []*models.Record{e.Record}); err != nil {
+						return err
+					}
+
+					return e.HttpContext.JSON(http.StatusOK, e.Record)
+				})
+			})
+		}
+	})
+}
+
+// findUpdatableRecord loads the path params record
+// if the current request satisfies the collection update API rule.
+func (api *recordApi) findUpdatableRecord(c echo.Context) (*models.Record, error) {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return nil, NewNotFoundError("", "Missing collection context.")
+	}
+
+	recordId := c.PathParam("id")
+	if recordId == "" {
+		return nil, NewNotFoundError("", nil)
+	}
+
+	requestInfo := RequestInfo(c)
+	updateRule := collection.TenantScopedRule(collection.UpdateRule)
+
+	if requestInfo.Admin == nil && updateRule == nil {
+		// only admins can access if the rule is nil
+		return nil, NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	ruleFunc := func(q *dbx.SelectQuery) error {
+		if err := triggerRecordsQueryHook(api.app, c, requestInfo, collection, q, core.RecordsQueryActionUpdate); err != nil {
+			return err
+		}
+
+		if requestInfo.Admin == nil && updateRule != nil && *updateRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.dao(c), collection, requestInfo, true)
+			expr, err := search.FilterData(*updateRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+		}
+		return nil
+	}
+
+	record, err := api.dao(c).FindRecordById(collection.Id, recordId, ruleFunc)
+	if err != nil || record == nil {
+		return nil, NewNotFoundError("", err)
+	}
+
+	return record, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"path/filepath"
@@ -24,6 +25,10 @@ import (
 	"golang.org/x/crypto/acme/autocert"
 )
 
+// DefaultShutdownTimeout is the default max duration to wait for the
+// active requests and background jobs to complete on app termination.
+const DefaultShutdownTimeout = 10 * time.Second
+
 // ServeConfig defines a configuration struct for apis.Serve().
 type ServeConfig struct {
 	// ShowStartBanner indicates whether to show or hide the server start console message.
@@ -45,6 +50,11 @@ type ServeConfig struct {
 
 	// AllowedOrigins is an optional list of CORS origins (default to "*").
 	AllowedOrigins []string
+
+	// ShutdownTimeout is the max duration to wait for the active requests
+	// and running cron jobs to complete on app termination
+	// (default to DefaultShutdownTimeout).
+	ShutdownTimeout time.Duration
 }
 
 // Serve starts a new app web server.
@@ -63,6 +73,10 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 		config.AllowedOrigins = []string{"*"}
 	}
 
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
 	// ensure that the latest migrations are applied before starting the server
 	if err := runMigrations(app); err != nil {
 		return nil, err
@@ -82,9 +96,12 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 		return nil, err
 	}
 
-	// configure cors
+	// configure the default cors
+	// (the app Settings.Cors config, if enabled, is handled by the apis.CORS middleware)
 	router.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		Skipper:      middleware.DefaultSkipper,
+		Skipper: func(c echo.Context) bool {
+			return app.Settings().Cors.Enabled
+		},
 		AllowOrigins: config.AllowedOrigins,
 		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
 	}))
@@ -203,14 +220,32 @@ func Serve(app core.App, config ServeConfig) (*http.Server, error) {
 	var wg sync.WaitGroup
 
 	// try to gracefully shutdown the server on app termination
-	app.OnTerminate().Add(func(e *core.TerminateEvent) error {
-		cancelBaseCtx()
+	// (registered as first handler so that the other cleanup handlers,
+	// like the logs flush, run after the active requests were drained)
+	app.OnTerminate().PreAdd(func(e *core.TerminateEvent) error {
+		wg.Add(1)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		deadline := time.Now().Add(config.ShutdownTimeout)
+		e.ShutdownDeadline = deadline
+
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
 		defer cancel()
 
-		wg.Add(1)
-		server.Shutdown(ctx)
+		// notify and close the realtime connections in parallel
+		// because the SSE requests would otherwise block the server shutdown
+		go disconnectRealtimeClients(ctx, app)
+
+		// stop accepting new connections and wait for the active requests to complete
+		if err := server.Shutdown(ctx); err != nil {
+			app.Logger().Warn(
+				"Terminated before the active requests completed",
+				slog.String("error", err.Error()),
+			)
+			server.Close()
+		}
+
+		cancelBaseCtx()
+
 		if e.IsRestart {
 			// wait for execve and other handlers up to 5 seconds before exit
 			time.AfterFunc(5*time.Second, func() {
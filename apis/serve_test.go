@@ -0,0 +1,228 @@
+package apis_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// serveTestClient is a http client without connections reuse to prevent
+// spare idle pool connections delaying the server shutdown
+// (the not yet used connections are treated as active for up to 5s).
+var serveTestClient = &http.Client{
+	Transport: &http.Transport{DisableKeepAlives: true},
+}
+
+type serveTestResult struct {
+	status int
+	body   string
+	err    error
+}
+
+// startServeTestApp starts a new test app server with an additional
+// "/slow" route that responds after the provided delay.
+//
+// The app is expected to be terminated by the caller with app.Cleanup().
+func startServeTestApp(t *testing.T, config apis.ServeConfig, slowDelay time.Duration) (*tests.TestApp, string, <-chan struct{}, <-chan error) {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	slowStarted := make(chan struct{}, 1)
+
+	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+		e.Router.GET("/slow", func(c echo.Context) error {
+			slowStarted <- struct{}{}
+			time.Sleep(slowDelay)
+			return c.String(http.StatusOK, "done")
+		})
+		return nil
+	})
+
+	config.HttpAddr = addr
+
+	serveErr := make(chan error, 1)
+	go func() {
+		_, err := apis.Serve(app, config)
+		serveErr <- err
+	}()
+
+	// wait for the server to start
+	for i := 0; ; i++ {
+		res, err := serveTestClient.Get("http://" + addr + "/api/health")
+		if err == nil {
+			res.Body.Close()
+			break
+		}
+		if i > 100 {
+			t.Fatalf("Failed to start the test server: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return app, "http://" + addr, slowStarted, serveErr
+}
+
+func sendServeTestRequest(url string) <-chan serveTestResult {
+	result := make(chan serveTestResult, 1)
+
+	go func() {
+		res, err := serveTestClient.Get(url)
+		if err != nil {
+			result <- serveTestResult{err: err}
+			return
+		}
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+
+		result <- serveTestResult{status: res.StatusCode, body: string(body), err: err}
+	}()
+
+	return result
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+	t.Parallel()
+
+	app, baseUrl, slowStarted, serveErr := startServeTestApp(t, apis.ServeConfig{
+		ShutdownTimeout: 5 * time.Second,
+	}, 500*time.Millisecond)
+
+	result := sendServeTestRequest(baseUrl + "/slow")
+
+	<-slowStarted
+
+	start := time.Now()
+	app.Cleanup() // triggers the app OnTerminate hook
+	elapsed := time.Since(start)
+
+	if elapsed >= 5*time.Second {
+		t.Fatalf("Expected the shutdown to complete before the timeout, took %v", elapsed)
+	}
+
+	select {
+	case r := <-result:
+		if r.err != nil {
+			t.Fatalf("Expected the slow request to complete, got error %v", r.err)
+		}
+		if r.status != http.StatusOK || r.body != "done" {
+			t.Fatalf("Expected 200 done response, got %d %q", r.status, r.body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slow request to be completed during the shutdown")
+	}
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			t.Fatalf("Expected ErrServerClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Serve to return after the shutdown")
+	}
+
+	// new connections should be rejected
+	if r := <-sendServeTestRequest(baseUrl + "/api/health"); r.err == nil {
+		t.Fatalf("Expected the new request to fail, got %d %q", r.status, r.body)
+	}
+}
+
+func TestServeGracefulShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	app, baseUrl, slowStarted, _ := startServeTestApp(t, apis.ServeConfig{
+		ShutdownTimeout: 200 * time.Millisecond,
+	}, 3*time.Second)
+
+	result := sendServeTestRequest(baseUrl + "/slow")
+
+	<-slowStarted
+
+	start := time.Now()
+	app.Cleanup()
+	elapsed := time.Since(start)
+
+	if elapsed >= 2*time.Second {
+		t.Fatalf("Expected the shutdown to stop waiting after the timeout, took %v", elapsed)
+	}
+
+	select {
+	case r := <-result:
+		if r.err == nil {
+			t.Fatalf("Expected the slow request to be interrupted, got %d %q", r.status, r.body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slow request to be interrupted")
+	}
+}
+
+func TestServeGracefulShutdownRealtime(t *testing.T) {
+	t.Parallel()
+
+	app, baseUrl, _, _ := startServeTestApp(t, apis.ServeConfig{
+		ShutdownTimeout: 5 * time.Second,
+	}, 0)
+
+	res, err := serveTestClient.Get(baseUrl + "/api/realtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	events := make(chan string, 10)
+	go func() {
+		defer close(events)
+
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			if name, ok := strings.CutPrefix(scanner.Text(), "event:"); ok {
+				events <- name
+			}
+		}
+	}()
+
+	if name := <-events; name != "PB_CONNECT" {
+		t.Fatalf("Expected PB_CONNECT event, got %q", name)
+	}
+
+	start := time.Now()
+	app.Cleanup()
+	elapsed := time.Since(start)
+
+	// the open SSE connection shouldn't block the shutdown
+	if elapsed >= 2*time.Second {
+		t.Fatalf("Expected the realtime connection to be closed before the timeout, took %v", elapsed)
+	}
+
+	received := []string{}
+	for name := range events {
+		received = append(received, name)
+	}
+
+	if len(received) != 1 || received[0] != "PB_DISCONNECT" {
+		t.Fatalf("Expected only PB_DISCONNECT event before the connection close, got %v", received)
+	}
+
+	if total := len(app.SubscriptionsBroker().Clients()); total != 0 {
+		t.Fatalf("Expected no registered realtime clients, got %d", total)
+	}
+}
@@ -49,6 +49,7 @@ func TestSettingsList(t *testing.T) {
 			ExpectedContent: []string{
 				`"meta":{`,
 				`"logs":{`,
+				`"auditLogs":{`,
 				`"smtp":{`,
 				`"s3":{`,
 				`"backups":{`,
@@ -56,10 +57,12 @@ func TestSettingsList(t *testing.T) {
 				`"adminPasswordResetToken":{`,
 				`"adminFileToken":{`,
 				`"recordAuthToken":{`,
+				`"recordRefreshToken":{`,
 				`"recordPasswordResetToken":{`,
 				`"recordEmailChangeToken":{`,
 				`"recordVerificationToken":{`,
 				`"recordFileToken":{`,
+				`"recordShareToken":{`,
 				`"emailAuth":{`,
 				`"googleAuth":{`,
 				`"facebookAuth":{`,
@@ -86,6 +89,8 @@ func TestSettingsList(t *testing.T) {
 				`"mailcowAuth":{`,
 				`"bitbucketAuth":{`,
 				`"planningcenterAuth":{`,
+				`"zohoAuth":{`,
+				`"slackAuth":{`,
 				`"secret":"******"`,
 				`"clientSecret":"******"`,
 			},
@@ -144,10 +149,12 @@ func TestSettingsSet(t *testing.T) {
 				`"adminPasswordResetToken":{`,
 				`"adminFileToken":{`,
 				`"recordAuthToken":{`,
+				`"recordRefreshToken":{`,
 				`"recordPasswordResetToken":{`,
 				`"recordEmailChangeToken":{`,
 				`"recordVerificationToken":{`,
 				`"recordFileToken":{`,
+				`"recordShareToken":{`,
 				`"emailAuth":{`,
 				`"googleAuth":{`,
 				`"facebookAuth":{`,
@@ -173,6 +180,8 @@ func TestSettingsSet(t *testing.T) {
 				`"mailcowAuth":{`,
 				`"bitbucketAuth":{`,
 				`"planningcenterAuth":{`,
+				`"zohoAuth":{`,
+				`"slackAuth":{`,
 				`"secret":"******"`,
 				`"clientSecret":"******"`,
 				`"appName":"acme_test"`,
@@ -217,10 +226,12 @@ func TestSettingsSet(t *testing.T) {
 				`"adminPasswordResetToken":{`,
 				`"adminFileToken":{`,
 				`"recordAuthToken":{`,
+				`"recordRefreshToken":{`,
 				`"recordPasswordResetToken":{`,
 				`"recordEmailChangeToken":{`,
 				`"recordVerificationToken":{`,
 				`"recordFileToken":{`,
+				`"recordShareToken":{`,
 				`"emailAuth":{`,
 				`"googleAuth":{`,
 				`"facebookAuth":{`,
@@ -247,6 +258,8 @@ func TestSettingsSet(t *testing.T) {
 				`"mailcowAuth":{`,
 				`"bitbucketAuth":{`,
 				`"planningcenterAuth":{`,
+				`"zohoAuth":{`,
+				`"slackAuth":{`,
 				`"secret":"******"`,
 				`"clientSecret":"******"`,
 				`"appName":"update_test"`,
@@ -442,6 +455,7 @@ func TestSettingsTestEmail(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"OnMailerBeforeRecordVerificationSend": 1,
 				"OnMailerAfterRecordVerificationSend":  1,
+				"OnMailerSend":                         1,
 			},
 		},
 		{
@@ -477,6 +491,7 @@ func TestSettingsTestEmail(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"OnMailerBeforeRecordResetPasswordSend": 1,
 				"OnMailerAfterRecordResetPasswordSend":  1,
+				"OnMailerSend":                          1,
 			},
 		},
 		{
@@ -512,6 +527,7 @@ func TestSettingsTestEmail(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"OnMailerBeforeRecordChangeEmailSend": 1,
 				"OnMailerAfterRecordChangeEmailSend":  1,
+				"OnMailerSend":                        1,
 			},
 		},
 	}
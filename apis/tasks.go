@@ -0,0 +1,103 @@
+package apis
+
+import (
+	"encoding/json"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/tasks"
+)
+
+// bindTasksApi registers the background tasks api endpoints.
+func bindTasksApi(app core.App, rg *echo.Group) {
+	api := tasksApi{app: app}
+
+	subGroup := rg.Group("/tasks", RequireAdminOrRecordAuth())
+	subGroup.GET("/:id/events", api.events)
+}
+
+type tasksApi struct {
+	app core.App
+}
+
+// StartTask starts a new background task owned by the current request
+// auth admin or record, which progress could be streamed by the same
+// auth with `GET /api/tasks/{id}/events`.
+//
+// Example:
+//
+//	e.Router.POST("/api/myapp/import", func(c echo.Context) error {
+//		task, err := apis.StartTask(app, c, func(task *tasks.Task) error {
+//			for i, item := range items {
+//				// ...
+//				task.Report(float64(i+1)/float64(len(items))*100, "Imported "+item.Name)
+//			}
+//			return nil
+//		})
+//		if err != nil {
+//			return err
+//		}
+//
+//		return c.JSON(http.StatusAccepted, map[string]string{"taskId": task.Id()})
+//	}, apis.RequireAdminAuth())
+func StartTask(app core.App, c echo.Context, fn tasks.Func) (*tasks.Task, error) {
+	owner := taskOwner(c)
+	if owner == "" {
+		return nil, NewUnauthorizedError("The request requires valid admin or record authorization token to be set.", nil)
+	}
+
+	return app.Tasks().Start(owner, fn), nil
+}
+
+// taskOwner returns the tasks owner key of the current request auth.
+func taskOwner(c echo.Context) string {
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		return "admin:" + admin.Id
+	}
+
+	if record, _ := c.Get(ContextAuthRecordKey).(*models.Record); record != nil {
+		return "record:" + record.Collection().Id + ":" + record.Id
+	}
+
+	return ""
+}
+
+// events streams the task progress as server-sent events
+// ("progress", "done" or "error") until the task completes.
+func (api *tasksApi) events(c echo.Context) error {
+	task := api.app.Tasks().Get(c.PathParam("id"))
+	if task == nil || task.Owner() != taskOwner(c) {
+		return NewNotFoundError("", nil)
+	}
+
+	ch, unsubscribe := task.Subscribe()
+	defer unsubscribe()
+
+	setSSEHeaders(c)
+
+	for {
+		select {
+		case progress, ok := <-ch:
+			if !ok {
+				return nil // completed
+			}
+
+			data, err := json.Marshal(progress)
+			if err != nil {
+				return err
+			}
+
+			event := "progress"
+			if progress.Error != "" {
+				event = "error"
+			} else if progress.Done {
+				event = "done"
+			}
+
+			writeSSEMessage(c.Response(), task.Id(), event, data)
+		case <-c.Request().Context().Done():
+			return nil // connection is closed
+		}
+	}
+}
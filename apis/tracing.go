@@ -0,0 +1,110 @@
+package apis
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing middleware starts a server span for every request
+// (continuing the trace from the "traceparent" request header, if any)
+// and stores it in the request context.
+//
+// The span name is the request method and the matched route
+// (eg. "POST /api/collections/:collection/records").
+//
+// This middleware is a no-op if the app doesn't have a configured tracer provider
+// and it is expected to be already registered by default for all routes.
+func Tracing(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+
+			ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			method := strings.ToUpper(r.Method)
+
+			ctx, span := app.Tracer().Start(
+				ctx,
+				method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", method),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			if !span.IsRecording() {
+				return next(c)
+			}
+
+			c.SetRequest(r.WithContext(ctx))
+
+			// handle the error here so that the error response and hooks
+			// are also part of the request span
+			if err := next(c); err != nil {
+				c.Echo().HTTPErrorHandler(c, err)
+			}
+
+			status := c.Response().Status
+
+			// the route is available only after the router match
+			if route := c.Path(); route != "" {
+				span.SetName(method + " " + route)
+				span.SetAttributes(attribute.String("http.route", route))
+			}
+
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+
+			if status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+
+			return nil
+		}
+	}
+}
+
+// ContextRequestDaoKey is the echo context store key of the request scoped dao.
+const ContextRequestDaoKey = "requestDao"
+
+// RequestDao returns the app dao scoped to the current request.
+//
+// If the request is traced, the returned dao is bound to the request
+// context (so that the executed db statements are reported as child spans
+// of the request span) but detached from the request cancellation
+// to preserve the default db queries behavior.
+//
+// If the app has a configured read replica, the returned dao routes
+// its read queries to it until the first write performed within the
+// same request (see [daos.Dao.WithReadReplica]).
+//
+// The same dao instance is returned for all calls within a single request.
+func RequestDao(app core.App, c echo.Context) *daos.Dao {
+	if dao, ok := c.Get(ContextRequestDaoKey).(*daos.Dao); ok {
+		return dao
+	}
+
+	dao := app.Dao()
+
+	if ctx := c.Request().Context(); trace.SpanFromContext(ctx).IsRecording() {
+		dao = dao.WithContext(context.WithoutCancel(ctx))
+	}
+
+	if replica := app.ReadReplica(); replica != nil {
+		dao = dao.WithReadReplica(replica)
+	}
+
+	c.Set(ContextRequestDaoKey, dao)
+
+	return dao
+}
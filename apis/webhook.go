@@ -0,0 +1,122 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// bindWebhookApi registers the webhooks management endpoints.
+func bindWebhookApi(app core.App, rg *echo.Group) {
+	api := webhookApi{app: app}
+
+	subGroup := rg.Group("/webhooks", ActivityLogger(app), RequireAdminAuth())
+	subGroup.GET("", api.list)
+	subGroup.POST("", api.create)
+	subGroup.GET("/failures", api.listFailures)
+	subGroup.GET("/:id", api.view)
+	subGroup.PATCH("/:id", api.update)
+	subGroup.DELETE("/:id", api.delete)
+}
+
+type webhookApi struct {
+	app core.App
+}
+
+func (api *webhookApi) list(c echo.Context) error {
+	fieldResolver := search.NewSimpleFieldResolver(
+		"id", "created", "updated", "name", "collectionId", "url", "active",
+	)
+
+	webhooks := []*models.Webhook{}
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(api.app.Dao().WebhookQuery()).
+		ParseAndExec(c.QueryParams().Encode(), &webhooks)
+
+	if err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (api *webhookApi) view(c echo.Context) error {
+	webhook, err := api.app.Dao().FindWebhookById(c.PathParam("id"))
+	if err != nil {
+		return NewNotFoundError("", err)
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (api *webhookApi) create(c echo.Context) error {
+	webhook := &models.Webhook{}
+
+	form := forms.NewWebhookUpsert(api.app, webhook)
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	if err := form.Submit(); err != nil {
+		return NewBadRequestError("Failed to create webhook.", err)
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (api *webhookApi) update(c echo.Context) error {
+	webhook, err := api.app.Dao().FindWebhookById(c.PathParam("id"))
+	if err != nil {
+		return NewNotFoundError("", err)
+	}
+
+	form := forms.NewWebhookUpsert(api.app, webhook)
+	if err := c.Bind(form); err != nil {
+		return NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	if err := form.Submit(); err != nil {
+		return NewBadRequestError("Failed to update webhook.", err)
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (api *webhookApi) delete(c echo.Context) error {
+	webhook, err := api.app.Dao().FindWebhookById(c.PathParam("id"))
+	if err != nil {
+		return NewNotFoundError("", err)
+	}
+
+	if err := api.app.Dao().DeleteWebhook(webhook); err != nil {
+		return NewBadRequestError("Failed to delete webhook.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// listFailures returns a paginated list with the dead-letter
+// entries of the permanently failed webhook deliveries.
+func (api *webhookApi) listFailures(c echo.Context) error {
+	fieldResolver := search.NewSimpleFieldResolver(
+		"id", "created", "updated", "webhookId", "collectionId", "recordId",
+		"event", "url", "attempts", "statusCode",
+	)
+
+	failures := []*models.WebhookFailure{}
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(api.app.Dao().WebhookFailureQuery()).
+		ParseAndExec(c.QueryParams().Encode(), &failures)
+
+	if err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,298 @@
+package apis_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func createTestWebhook(t testing.TB, app *tests.TestApp) *models.Webhook {
+	webhook := &models.Webhook{
+		Name:         "test",
+		CollectionId: "sz5l5z67tg7gku0",
+		Events:       []string{models.WebhookEventCreate},
+		Url:          "https://example.com/webhook",
+		Secret:       "test_secret_123",
+		Active:       true,
+	}
+	webhook.MarkAsNew()
+	webhook.SetId("test_webhook_id")
+
+	// skip the model hooks to not affect the scenarios events
+	if err := app.Dao().WithoutHooks().SaveWebhook(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	return webhook
+}
+
+func TestWebhooksList(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodGet,
+			Url:             "/api/webhooks",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "auth record",
+			Method: http.MethodGet,
+			Url:    "/api/webhooks",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPUserToken,
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin",
+			Method: http.MethodGet,
+			Url:    "/api/webhooks",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestWebhook(t, app)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":1`,
+				`"id":"test_webhook_id"`,
+				`"events":["create"]`,
+				`"active":true`,
+			},
+			NotExpectedContent: []string{
+				`"secret"`,
+				`test_secret_123`,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestWebhookCreate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodPost,
+			Url:             "/api/webhooks",
+			Body:            strings.NewReader(`{"name":"test","collection":"demo2","events":["create"],"url":"https://example.com","secret":"1234567890"}`),
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + invalid data",
+			Method: http.MethodPost,
+			Url:    "/api/webhooks",
+			Body:   strings.NewReader(`{"collection":"view1","events":["create","invalid"],"url":"invalid"}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"name":{"code":"validation_required"`,
+				`"collection":{"code":"validation_invalid_collection"`,
+				`"events":{"1":{"code":"validation_in_invalid"`,
+				`"url":{"code":"validation_is_url"`,
+				`"secret":{"code":"validation_required"`,
+			},
+		},
+		{
+			Name:   "admin + valid data",
+			Method: http.MethodPost,
+			Url:    "/api/webhooks",
+			Body:   strings.NewReader(`{"name":"test","collection":"demo2","events":["create","delete","create"],"url":"https://example.com","secret":"1234567890"}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"name":"test"`,
+				`"collectionId":"sz5l5z67tg7gku0"`,
+				`"events":["create","delete"]`,
+				`"url":"https://example.com"`,
+				`"active":true`,
+			},
+			NotExpectedContent: []string{
+				`"secret"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnModelBeforeCreate": 1,
+				"OnModelAfterCreate":  1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestWebhookUpdate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodPatch,
+			Url:             "/api/webhooks/test_webhook_id",
+			Body:            strings.NewReader(`{"active":false}`),
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + missing webhook",
+			Method: http.MethodPatch,
+			Url:    "/api/webhooks/missing",
+			Body:   strings.NewReader(`{"active":false}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + existing webhook",
+			Method: http.MethodPatch,
+			Url:    "/api/webhooks/test_webhook_id",
+			Body:   strings.NewReader(`{"active":false,"events":["update"]}`),
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestWebhook(t, app)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				webhook, err := app.Dao().FindWebhookById("test_webhook_id")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// the secret should remain unchanged
+				if webhook.Secret != "test_secret_123" {
+					t.Fatalf("Expected the webhook secret to be unchanged, got %q", webhook.Secret)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"test_webhook_id"`,
+				`"events":["update"]`,
+				`"active":false`,
+				`"name":"test"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnModelBeforeUpdate": 1,
+				"OnModelAfterUpdate":  1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestWebhookDelete(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodDelete,
+			Url:             "/api/webhooks/test_webhook_id",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + missing webhook",
+			Method: http.MethodDelete,
+			Url:    "/api/webhooks/missing",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin + existing webhook",
+			Method: http.MethodDelete,
+			Url:    "/api/webhooks/test_webhook_id",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				createTestWebhook(t, app)
+			},
+			AfterTestFunc: func(t *testing.T, app *tests.TestApp, res *http.Response) {
+				if _, err := app.Dao().FindWebhookById("test_webhook_id"); err == nil {
+					t.Fatal("Expected the webhook to be deleted")
+				}
+			},
+			ExpectedStatus: 204,
+			ExpectedEvents: map[string]int{
+				"OnModelBeforeDelete": 1,
+				"OnModelAfterDelete":  1,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestWebhookFailuresList(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodGet,
+			Url:             "/api/webhooks/failures",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:   "admin",
+			Method: http.MethodGet,
+			Url:    "/api/webhooks/failures?filter=event='create'",
+			RequestHeaders: map[string]string{
+				"Authorization": testTOTPAdminToken,
+			},
+			BeforeTestFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				failures := []*models.WebhookFailure{
+					{WebhookId: "test_webhook_id", Event: "create", RecordId: "r1", StatusCode: 500, Attempts: 6, Payload: []byte(`{}`)},
+					{WebhookId: "test_webhook_id", Event: "delete", RecordId: "r2", StatusCode: 400, Attempts: 1, Payload: []byte(`{}`)},
+				}
+				for _, f := range failures {
+					if err := app.Dao().WithoutHooks().SaveWebhookFailure(f); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":1`,
+				`"recordId":"r1"`,
+				`"statusCode":500`,
+				`"attempts":6`,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
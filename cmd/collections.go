@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/spf13/cobra"
+)
+
+// NewCollectionsCommand creates and returns new command for exporting
+// and importing the app collections schema.
+func NewCollectionsCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "collections",
+		Short: "Exports and imports the app collections schema",
+	}
+
+	command.AddCommand(collectionsExportCommand(app))
+	command.AddCommand(collectionsImportCommand(app))
+
+	return command
+}
+
+func collectionsExportCommand(app core.App) *cobra.Command {
+	var output string
+
+	command := &cobra.Command{
+		Use:          "export",
+		Example:      "collections export posts users --output=pb_schema.json",
+		Short:        "Exports the specified collections (or all if none) as versioned JSON",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			data, err := core.ExportCollectionsSchema(app, args...)
+			if err != nil {
+				return fmt.Errorf("Failed to export the collections schema: %v", err)
+			}
+
+			if output == "" {
+				fmt.Fprintln(command.OutOrStdout(), string(data))
+				return nil
+			}
+
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				return fmt.Errorf("Failed to write the collections schema file: %v", err)
+			}
+
+			color.Green("Successfully exported the collections schema to %q!", output)
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().StringVar(
+		&output,
+		"output",
+		"",
+		"The file to write the exported schema to (default to stdout)",
+	)
+
+	return command
+}
+
+func collectionsImportCommand(app core.App) *cobra.Command {
+	var deleteMissing bool
+	var dryRun bool
+
+	command := &cobra.Command{
+		Use:          "import",
+		Example:      "collections import pb_schema.json --dry-run",
+		Short:        "Imports a collections schema file and prints the applied changes",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("Failed to read the collections schema file: %v", err)
+			}
+
+			collections, err := core.ParseCollectionsSchema(data)
+			if err != nil {
+				return fmt.Errorf("Failed to parse the collections schema file: %v", err)
+			}
+
+			form := forms.NewCollectionsSchemaImport(app)
+			form.Collections = collections
+			form.DeleteMissing = deleteMissing
+			form.DryRun = dryRun
+
+			changes, err := form.Submit()
+			if err != nil {
+				return fmt.Errorf("Failed to import the collections schema: %v", err)
+			}
+
+			if len(changes) > 0 {
+				raw, err := json.MarshalIndent(changes, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(command.OutOrStdout(), string(raw))
+			}
+
+			if dryRun {
+				color.Yellow("Found %d collection change(s). Run without --dry-run to apply them.", len(changes))
+			} else {
+				color.Green("Successfully applied %d collection change(s)!", len(changes))
+			}
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().BoolVar(
+		&deleteMissing,
+		"delete-missing",
+		false,
+		"Delete the existing collections that are not present in the imported file",
+	)
+
+	command.PersistentFlags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Print the changes without applying them",
+	)
+
+	return command
+}
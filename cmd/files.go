@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewFilesCommand creates and returns new command for managing
+// the app storage files.
+func NewFilesCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "files",
+		Short: "Manages the app storage files",
+	}
+
+	command.AddCommand(filesReconcileCommand(app))
+
+	return command
+}
+
+func filesReconcileCommand(app core.App) *cobra.Command {
+	var dryRun bool
+	var minAge time.Duration
+
+	command := &cobra.Command{
+		Use:          "reconcile",
+		Example:      "files reconcile --dry-run=false",
+		Short:        "Finds and deletes the storage files that are not referenced by any record",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if minAge <= 0 {
+				return errors.New("The min-age value must be a positive duration.")
+			}
+
+			result, err := core.ReconcileFiles(app, core.ReconcileFilesOptions{
+				DryRun: dryRun,
+				MinAge: minAge,
+			})
+
+			if result != nil {
+				for _, key := range result.Orphans {
+					fmt.Fprintln(command.OutOrStdout(), key)
+				}
+			}
+
+			if err != nil {
+				return fmt.Errorf("Failed to reconcile the storage files: %v", err)
+			}
+
+			if dryRun {
+				color.Yellow("Found %d orphan file(s). Run with --dry-run=false to delete them.", len(result.Orphans))
+			} else {
+				color.Green("Successfully deleted %d orphan file(s)!", result.Deleted)
+			}
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().BoolVar(
+		&dryRun,
+		"dry-run",
+		true,
+		"List the orphan files without deleting them",
+	)
+
+	command.PersistentFlags().DurationVar(
+		&minAge,
+		"min-age",
+		core.DefaultReconcileFilesMinAge,
+		"Skip the files modified more recently than the specified duration\n(eg. to keep the files of the in-progress uploads)",
+	)
+
+	return command
+}
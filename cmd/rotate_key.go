@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewRotateKeyCommand creates and returns new command for rotating the
+// app encryption key, aka. re-encrypting all stored encrypted data
+// (settings, records encrypted fields and TOTP secrets) with a new key.
+//
+// If only the old key is specified, the data is re-encrypted to the
+// current app encryption key (eg. after the env variable was already changed).
+func NewRotateKeyCommand(app core.App) *cobra.Command {
+	var oldKey string
+	var newKey string
+
+	command := &cobra.Command{
+		Use: "rotate-key",
+		Example: "rotate-key --old=oldEncryptionKey --new=newEncryptionKey\n" +
+			"rotate-key --old=oldEncryptionKey (re-encrypts to the current encryption key)",
+		Short:        "Re-encrypts all stored encrypted data from the old to the new encryption key",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			currentKey := app.Dao().EncryptionKey
+
+			if newKey == "" {
+				newKey = currentKey
+			} else if oldKey == "" {
+				oldKey = currentKey
+			}
+
+			if oldKey == "" {
+				return fmt.Errorf("Missing old encryption key. Please set the --old flag or the %s env variable and try again.", app.EncryptionEnv())
+			}
+
+			if newKey == "" {
+				return fmt.Errorf("Missing new encryption key. Please set the --new flag or the %s env variable and try again.", app.EncryptionEnv())
+			}
+
+			if len(oldKey) != 32 || len(newKey) != 32 {
+				return errors.New("The old and new encryption keys must be 32 chars long.")
+			}
+
+			if oldKey == newKey {
+				return errors.New("The new encryption key must be different from the old one.")
+			}
+
+			total, err := app.Dao().RotateEncryptionKey(oldKey, newKey)
+			if err != nil {
+				return fmt.Errorf("Failed to rotate the encryption key: %v", err)
+			}
+
+			color.Green("Successfully re-encrypted %d value(s)!", total)
+
+			if newKey != currentKey {
+				app.Dao().EncryptionKey = newKey
+				color.Yellow("Don't forget to update the %s env variable with the new encryption key.", app.EncryptionEnv())
+			}
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().StringVar(
+		&oldKey,
+		"old",
+		"",
+		"The old encryption key (default to the encryption env variable value if --new is set)",
+	)
+
+	command.PersistentFlags().StringVar(
+		&newKey,
+		"new",
+		"",
+		"The new 32 chars encryption key (default to the encryption env variable value)",
+	)
+
+	return command
+}
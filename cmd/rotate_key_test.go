@@ -0,0 +1,97 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRotateKeyCommand(t *testing.T) {
+	t.Parallel()
+
+	oldKey := "abcdabcdabcdabcdabcdabcdabcdabcd"
+	newKey := "1234123412341234123412341234abcd"
+	otherKey := "zxcvzxcvzxcvzxcvzxcvzxcvzxcvzxcv"
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = oldKey
+
+	if err := app.Dao().SaveSettings(app.Settings(), oldKey); err != nil {
+		t.Fatal(err)
+	}
+
+	collection := &models.Collection{
+		Name:   "encrypted_test",
+		Type:   models.CollectionTypeBase,
+		Schema: schema.NewSchema(&schema.SchemaField{Name: "secret", Type: schema.FieldTypeEncrypted}),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("secret", "test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		currentKey  string
+		args        []string
+		expectError bool
+		expectedKey string
+	}{
+		{"missing new key", "", []string{"--old=" + oldKey}, true, ""},
+		{"missing old key", oldKey, []string{}, true, ""},
+		{"invalid new key length", oldKey, []string{"--new=123"}, true, ""},
+		{"missing old key with new key", "", []string{"--new=" + newKey}, true, ""},
+		{"same old and new keys", oldKey, []string{"--new=" + oldKey}, true, ""},
+		{"wrong old key", "", []string{"--old=" + newKey[:31] + "x", "--new=" + newKey}, true, ""},
+		{"valid keys", "", []string{"--old=" + oldKey, "--new=" + newKey}, false, newKey},
+		{"wrong old key for the current key", otherKey, []string{"--old=" + oldKey}, true, ""},
+		{"valid old key for the current key", otherKey, []string{"--old=" + newKey}, false, otherKey},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app.Dao().EncryptionKey = s.currentKey
+
+			command := cmd.NewRotateKeyCommand(app)
+			command.SetArgs(s.args)
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if app.Dao().EncryptionKey != s.expectedKey {
+				t.Fatalf("Expected the dao encryption key to be %q, got %q", s.expectedKey, app.Dao().EncryptionKey)
+			}
+
+			found, err := app.Dao().FindRecordById(collection.Id, record.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := found.GetString("secret"); v != "test" {
+				t.Fatalf("Expected secret %q, got %q", "test", v)
+			}
+
+			if _, err := app.Dao().FindSettings(s.expectedKey); err != nil {
+				t.Fatalf("Expected the settings to be encrypted with the new key, got %v", err)
+			}
+		})
+	}
+}
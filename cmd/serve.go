@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
@@ -15,6 +16,7 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 	var allowedOrigins []string
 	var httpAddr string
 	var httpsAddr string
+	var shutdownTimeout time.Duration
 
 	command := &cobra.Command{
 		Use:          "serve [domain(s)]",
@@ -42,6 +44,7 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 				ShowStartBanner:    showStartBanner,
 				AllowedOrigins:     allowedOrigins,
 				CertificateDomains: args,
+				ShutdownTimeout:    shutdownTimeout,
 			})
 
 			if errors.Is(err, http.ErrServerClosed) {
@@ -73,5 +76,12 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 		"TCP address to listen for the HTTPS server\n(if domain args are specified - default to 0.0.0.0:443, otherwise - default to empty string, aka. no TLS)\nThe incoming HTTP traffic also will be auto redirected to the HTTPS version",
 	)
 
+	command.PersistentFlags().DurationVar(
+		&shutdownTimeout,
+		"shutdown-timeout",
+		apis.DefaultShutdownTimeout,
+		"Max duration to wait for the active requests and running cron jobs to complete on termination",
+	)
+
 	return command
 }
@@ -15,6 +15,8 @@ import (
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/tasks"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // App defines the main PocketBase app interface.
@@ -49,6 +51,12 @@ type App interface {
 	// the users table from LogsDao will result in error.
 	LogsDao() *daos.Dao
 
+	// ReadReplica returns the optional read-only replica of the app
+	// data db (see [BaseAppConfig.DataReplicaPath]).
+	//
+	// Returns nil if a data replica is not configured.
+	ReadReplica() *daos.ReadReplica
+
 	// Logger returns the active app logger.
 	Logger() *slog.Logger
 
@@ -62,6 +70,14 @@ type App interface {
 	// IsDev returns whether the app is in dev mode.
 	IsDev() bool
 
+	// Version returns the configured app version (if any).
+	Version() string
+
+	// Tracer returns the app OpenTelemetry tracer.
+	//
+	// If a tracer provider is not configured, returns a no-op tracer.
+	Tracer() trace.Tracer
+
 	// Settings returns the loaded app settings.
 	Settings() *settings.Settings
 
@@ -74,6 +90,10 @@ type App interface {
 	// SubscriptionsBroker returns the app realtime subscriptions broker instance.
 	SubscriptionsBroker() *subscriptions.Broker
 
+	// Tasks returns the app in-memory background tasks registry
+	// (eg. to report the progress of long-running imports).
+	Tasks() *tasks.Registry
+
 	// NewMailClient creates and returns a configured app mail client.
 	NewMailClient() mailer.Mailer
 
@@ -128,6 +148,21 @@ type App interface {
 	// NB! This feature is experimental and currently is expected to work only on UNIX based systems.
 	RestoreBackup(ctx context.Context, name string) error
 
+	// CreateIncrementalBackup creates a new incremental backup with
+	// only the pb_data changes since the specified parent backup
+	// (a full backup or another incremental backup of the same chain).
+	//
+	// Please refer to the godoc of the specific core.App implementation
+	// for details on the incremental backup procedures and format.
+	CreateIncrementalBackup(ctx context.Context, parent string, name string) error
+
+	// RestoreIncrementalBackup restores the full backup of the incremental
+	// backup chain ending with the specified backup, applies the ordered
+	// chain changes on top of it and restarts the current running application process.
+	//
+	// NB! This feature is experimental and currently is expected to work only on UNIX based systems.
+	RestoreIncrementalBackup(ctx context.Context, name string) error
+
 	// Restart restarts the current running application process.
 	//
 	// Currently it is relying on execve so it is supported only on UNIX based systems.
@@ -163,6 +198,17 @@ type App interface {
 	// of being terminated (eg. on SIGTERM signal).
 	OnTerminate() *hook.Hook[*TerminateEvent]
 
+	// OnSettingsReload hook is triggered after the app settings were
+	// reloaded with changed values (eg. after the settings were saved).
+	//
+	// The mail clients and filesystems created after the hook
+	// (see [App.NewMailClient] and [App.NewFilesystem]) use the new
+	// settings, while the already created ones (eg. of in-flight requests)
+	// continue to work with the old settings until they are released.
+	//
+	// Could be used to rebuild any custom long-lived settings dependent clients.
+	OnSettingsReload() *hook.Hook[*SettingsReloadEvent]
+
 	// ---------------------------------------------------------------
 	// Dao event hooks
 	// ---------------------------------------------------------------
@@ -215,6 +261,26 @@ type App interface {
 	// will be triggered and called only if their event data origin matches the tags.
 	OnModelAfterDelete(tags ...string) *hook.TaggedHook[*ModelEvent]
 
+	// OnRecordAfterTrash hook is triggered after successfully soft
+	// deleting (aka. trashing) a record of a soft delete collection.
+	//
+	// The event Model is always a [*models.Record].
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAfterTrash(tags ...string) *hook.TaggedHook[*ModelEvent]
+
+	// OnRecordAfterRestore hook is triggered after successfully
+	// restoring a trashed record of a soft delete collection.
+	//
+	// The event Model is always a [*models.Record].
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAfterRestore(tags ...string) *hook.TaggedHook[*ModelEvent]
+
 	// ---------------------------------------------------------------
 	// Mailer event hooks
 	// ---------------------------------------------------------------
@@ -279,6 +345,24 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnMailerAfterRecordChangeEmailSend(tags ...string) *hook.TaggedHook[*MailerRecordEvent]
 
+	// OnMailerSend hook is triggered right before sending any email
+	// message with the app mail client (including the builtin
+	// verification, password reset and email change emails).
+	//
+	// The event message is the final rendered message that will be sent,
+	// allowing you to inspect and modify it (eg. to add an audit Bcc or
+	// rewrite the recipients). Return an error to cancel the send.
+	OnMailerSend() *hook.Hook[*MailerSendEvent]
+
+	// OnMailReceive hook is triggered for each unseen message of the
+	// inbound IMAP mailbox (see [settings.ImapConfig]).
+	//
+	// The message is marked as seen only if the handlers chain
+	// completes without error, otherwise it will be retried on the next
+	// mailbox check. Could be used for example to create a record from
+	// the message subject, sender, body and attachments.
+	OnMailReceive() *hook.Hook[*MailReceiveEvent]
+
 	// ---------------------------------------------------------------
 	// Realtime API event hooks
 	// ---------------------------------------------------------------
@@ -756,6 +840,42 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAfterDeleteRequest(tags ...string) *hook.TaggedHook[*RecordDeleteEvent]
 
+	// OnRecordEnrich hook is triggered for each API response record
+	// (including the expanded relations) right before its serialization
+	// (aka. after the access rules checks and the expand resolution).
+	//
+	// Could be used to add extra computed fields (eg. with [models.Record.WithUnknownData])
+	// or to hide sensitive fields (eg. with [models.Record.Hide]) based
+	// on the current request auth without persisting the changes.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordEnrich(tags ...string) *hook.TaggedHook[*RecordEnrichEvent]
+
+	// OnRecordsQuery hook is triggered with the base records query of each
+	// API Records list, view, aggregate, update, delete (including delete by
+	// filter and restore), relations expand and realtime access check
+	// right before its execution.
+	//
+	// Could be used to globally constrain the accessible records
+	// (eg. to hide the archived records or to enforce an organization
+	// scope) by adding extra conditions with [dbx.SelectQuery.AndWhere].
+	//
+	// The hook is triggered for admins too and before the collection access
+	// rule filter is applied. The hook conditions and the access rule are
+	// always combined with AND, aka. the hook could only further restrict
+	// the records matched by the rule but not bypass it. For the list request
+	// the same constraints apply also to the "totalItems" count query.
+	//
+	// Note that the records list cache is bypassed while there are
+	// registered OnRecordsQuery handlers.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordsQuery(tags ...string) *hook.TaggedHook[*RecordsQueryEvent]
+
 	// ---------------------------------------------------------------
 	// Collection API event hooks
 	// ---------------------------------------------------------------
@@ -1,8 +1,10 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"log"
 	"log/slog"
@@ -10,6 +12,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -18,6 +21,7 @@ import (
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/settings"
+	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/logger"
@@ -26,13 +30,23 @@ import (
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/tasks"
 	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	DefaultDataMaxOpenConns int = 120
 	DefaultDataMaxIdleConns int = 20
+
+	// DefaultDataMaxWriteConns is the default max open connections of
+	// the data db writer (nonconcurrent) pool.
+	//
+	// SQLite allows only a single writer at a time so a single writer
+	// connection is used to avoid the "database is locked" lock contention.
+	DefaultDataMaxWriteConns int = 1
+
 	DefaultLogsMaxOpenConns int = 10
 	DefaultLogsMaxIdleConns int = 2
 
@@ -48,21 +62,37 @@ type BaseApp struct {
 	// @todo consider introducing a mutex to allow safe concurrent config changes during runtime
 
 	// configurable parameters
-	isDev            bool
-	dataDir          string
-	encryptionEnv    string
-	dataMaxOpenConns int
-	dataMaxIdleConns int
-	logsMaxOpenConns int
-	logsMaxIdleConns int
+	isDev             bool
+	dataDir           string
+	encryptionEnv     string
+	version           string
+	logHandlers       []slog.Handler
+	requestLogsRate   float64
+	tracer            trace.Tracer
+	traceDBStatements bool
+	dataMaxOpenConns  int
+	dataMaxIdleConns  int
+	dataMaxWriteConns int
+	logsMaxOpenConns  int
+	logsMaxIdleConns  int
+	dataReplicaPath   string
+	dataReplicaCheck  func(replica dbx.Builder) error
+	dbBusyTimeout     time.Duration
+	dbJournalMode     string
+	dbSynchronous     string
+	dbCacheSize       int
 
 	// internals
 	store               *store.Store[any]
 	settings            *settings.Settings
+	settingsReloadMux   sync.Mutex
 	dao                 *daos.Dao
 	logsDao             *daos.Dao
+	readReplica         *daos.ReadReplica
 	subscriptionsBroker *subscriptions.Broker
+	tasks               *tasks.Registry
 	logger              *slog.Logger
+	logsBatchHandler    *logger.BatchHandler
 
 	// app event hooks
 	onBeforeBootstrap *hook.Hook[*BootstrapEvent]
@@ -71,6 +101,7 @@ type BaseApp struct {
 	onBeforeApiError  *hook.Hook[*ApiErrorEvent]
 	onAfterApiError   *hook.Hook[*ApiErrorEvent]
 	onTerminate       *hook.Hook[*TerminateEvent]
+	onSettingsReload  *hook.Hook[*SettingsReloadEvent]
 
 	// dao event hooks
 	onModelBeforeCreate *hook.Hook[*ModelEvent]
@@ -80,6 +111,10 @@ type BaseApp struct {
 	onModelBeforeDelete *hook.Hook[*ModelEvent]
 	onModelAfterDelete  *hook.Hook[*ModelEvent]
 
+	// soft delete event hooks
+	onRecordAfterTrash   *hook.Hook[*ModelEvent]
+	onRecordAfterRestore *hook.Hook[*ModelEvent]
+
 	// mailer event hooks
 	onMailerBeforeAdminResetPasswordSend  *hook.Hook[*MailerAdminEvent]
 	onMailerAfterAdminResetPasswordSend   *hook.Hook[*MailerAdminEvent]
@@ -89,6 +124,8 @@ type BaseApp struct {
 	onMailerAfterRecordVerificationSend   *hook.Hook[*MailerRecordEvent]
 	onMailerBeforeRecordChangeEmailSend   *hook.Hook[*MailerRecordEvent]
 	onMailerAfterRecordChangeEmailSend    *hook.Hook[*MailerRecordEvent]
+	onMailerSend                          *hook.Hook[*MailerSendEvent]
+	onMailReceive                         *hook.Hook[*MailReceiveEvent]
 
 	// realtime api event hooks
 	onRealtimeConnectRequest         *hook.Hook[*RealtimeConnectEvent]
@@ -160,6 +197,8 @@ type BaseApp struct {
 	onRecordAfterUpdateRequest  *hook.Hook[*RecordUpdateEvent]
 	onRecordBeforeDeleteRequest *hook.Hook[*RecordDeleteEvent]
 	onRecordAfterDeleteRequest  *hook.Hook[*RecordDeleteEvent]
+	onRecordEnrich              *hook.Hook[*RecordEnrichEvent]
+	onRecordsQuery              *hook.Hook[*RecordsQueryEvent]
 
 	// collection API event hooks
 	onCollectionsListRequest         *hook.Hook[*CollectionsListEvent]
@@ -179,10 +218,69 @@ type BaseAppConfig struct {
 	IsDev            bool
 	DataDir          string
 	EncryptionEnv    string
+	Version          string
 	DataMaxOpenConns int // default to 500
 	DataMaxIdleConns int // default 20
 	LogsMaxOpenConns int // default to 100
 	LogsMaxIdleConns int // default to 5
+
+	// DataMaxWriteConns is the max open connections of the data db
+	// writer pool (default to [DefaultDataMaxWriteConns]).
+	//
+	// The DataMaxOpenConns and DataMaxIdleConns options are applied
+	// only to the read (concurrent) pool.
+	//
+	// Note that values greater than 1 are not recommended since the
+	// concurrent SQLite writers block each other and could fail with
+	// "database is locked" once the DBBusyTimeout is reached.
+	DataMaxWriteConns int
+
+	// Optional SQLite PRAGMAs applied to each data and logs db connection.
+	DBBusyTimeout time.Duration // default to DefaultDBBusyTimeout (10s)
+	DBJournalMode string        // default to DefaultDBJournalMode (WAL)
+	DBSynchronous string        // default to DefaultDBSynchronous (NORMAL)
+	DBCacheSize   int           // default to DefaultDBCacheSize (-16000, aka. ~16MB)
+
+	// LogHandlers is an optional list of custom slog handlers that will
+	// receive the app logs in addition to the default db logs handler
+	// (eg. slog.NewJSONHandler(os.Stdout, nil)).
+	//
+	// Set app.Settings().Logs.MaxDays to 0 if you want to disable the db logs.
+	LogHandlers []slog.Handler
+
+	// RequestLogsSampleRate is an optional fraction (0-1] of the
+	// successful request logs to keep (eg. 0.1 to keep every 10th).
+	//
+	// The failed requests and the other app logs are never sampled out.
+	// If not set, 0 or >= 1, all request logs are kept.
+	RequestLogsSampleRate float64
+
+	// TracerProvider is an optional OpenTelemetry tracer provider
+	// used to trace the http requests, db statements and fired hooks.
+	//
+	// If not set, the tracing is a no-op.
+	TracerProvider trace.TracerProvider
+
+	// TraceDBStatements enables the "db.statement" attribute of the
+	// db statements spans.
+	//
+	// Note that the exported statements contain the raw bound params
+	// (eg. emails, tokens and other sensitive data) so it should be
+	// enabled only for debugging and with trusted tracing backends.
+	TraceDBStatements bool
+
+	// DataReplicaPath is an optional path to a read-only replica
+	// of the data.db file (eg. a LiteFS replica).
+	//
+	// If set, the records list/view API read queries (including the
+	// rules evaluation) are routed to the replica (see [App.ReadReplica]).
+	DataReplicaPath string
+
+	// DataReplicaCheck is an optional function that reports whether the
+	// data replica could be used (eg. by checking its replication lag).
+	//
+	// If not set, only the replica availability is checked.
+	DataReplicaCheck func(replica dbx.Builder) error
 }
 
 // NewBaseApp creates and returns a new BaseApp instance
@@ -194,13 +292,24 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		isDev:               config.IsDev,
 		dataDir:             config.DataDir,
 		encryptionEnv:       config.EncryptionEnv,
+		version:             config.Version,
+		logHandlers:         config.LogHandlers,
+		requestLogsRate:     config.RequestLogsSampleRate,
 		dataMaxOpenConns:    config.DataMaxOpenConns,
 		dataMaxIdleConns:    config.DataMaxIdleConns,
+		dataMaxWriteConns:   config.DataMaxWriteConns,
 		logsMaxOpenConns:    config.LogsMaxOpenConns,
 		logsMaxIdleConns:    config.LogsMaxIdleConns,
+		dataReplicaPath:     config.DataReplicaPath,
+		dataReplicaCheck:    config.DataReplicaCheck,
+		dbBusyTimeout:       config.DBBusyTimeout,
+		dbJournalMode:       config.DBJournalMode,
+		dbSynchronous:       config.DBSynchronous,
+		dbCacheSize:         config.DBCacheSize,
 		store:               store.New[any](nil),
 		settings:            settings.New(),
 		subscriptionsBroker: subscriptions.NewBroker(),
+		tasks:               tasks.NewRegistry(tasks.DefaultCompletedTTL),
 
 		// app event hooks
 		onBeforeBootstrap: &hook.Hook[*BootstrapEvent]{},
@@ -209,6 +318,7 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		onBeforeApiError:  &hook.Hook[*ApiErrorEvent]{},
 		onAfterApiError:   &hook.Hook[*ApiErrorEvent]{},
 		onTerminate:       &hook.Hook[*TerminateEvent]{},
+		onSettingsReload:  &hook.Hook[*SettingsReloadEvent]{},
 
 		// dao event hooks
 		onModelBeforeCreate: &hook.Hook[*ModelEvent]{},
@@ -218,6 +328,10 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		onModelBeforeDelete: &hook.Hook[*ModelEvent]{},
 		onModelAfterDelete:  &hook.Hook[*ModelEvent]{},
 
+		// soft delete event hooks
+		onRecordAfterTrash:   &hook.Hook[*ModelEvent]{},
+		onRecordAfterRestore: &hook.Hook[*ModelEvent]{},
+
 		// mailer event hooks
 		onMailerBeforeAdminResetPasswordSend:  &hook.Hook[*MailerAdminEvent]{},
 		onMailerAfterAdminResetPasswordSend:   &hook.Hook[*MailerAdminEvent]{},
@@ -227,6 +341,8 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		onMailerAfterRecordVerificationSend:   &hook.Hook[*MailerRecordEvent]{},
 		onMailerBeforeRecordChangeEmailSend:   &hook.Hook[*MailerRecordEvent]{},
 		onMailerAfterRecordChangeEmailSend:    &hook.Hook[*MailerRecordEvent]{},
+		onMailerSend:                          &hook.Hook[*MailerSendEvent]{},
+		onMailReceive:                         &hook.Hook[*MailReceiveEvent]{},
 
 		// realtime API event hooks
 		onRealtimeConnectRequest:         &hook.Hook[*RealtimeConnectEvent]{},
@@ -298,6 +414,8 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		onRecordAfterUpdateRequest:  &hook.Hook[*RecordUpdateEvent]{},
 		onRecordBeforeDeleteRequest: &hook.Hook[*RecordDeleteEvent]{},
 		onRecordAfterDeleteRequest:  &hook.Hook[*RecordDeleteEvent]{},
+		onRecordEnrich:              &hook.Hook[*RecordEnrichEvent]{},
+		onRecordsQuery:              &hook.Hook[*RecordsQueryEvent]{},
 
 		// collection API event hooks
 		onCollectionsListRequest:         &hook.Hook[*CollectionsListEvent]{},
@@ -312,6 +430,12 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		onCollectionsAfterImportRequest:  &hook.Hook[*CollectionsImportEvent]{},
 	}
 
+	if config.TracerProvider != nil {
+		app.tracer = config.TracerProvider.Tracer(tracerName)
+		app.traceDBStatements = config.TraceDBStatements
+		app.initHooksTracing()
+	}
+
 	app.registerDefaultHooks()
 
 	return app
@@ -379,6 +503,11 @@ func (app *BaseApp) Bootstrap() error {
 // ResetBootstrapState takes care for releasing initialized app resources
 // (eg. closing db connections).
 func (app *BaseApp) ResetBootstrapState() error {
+	// flush the remaining buffered logs before closing the db connections
+	if app.logsBatchHandler != nil && app.logsDao != nil {
+		app.logsBatchHandler.WriteAll(context.Background())
+	}
+
 	if app.Dao() != nil {
 		if err := app.Dao().ConcurrentDB().(*dbx.DB).Close(); err != nil {
 			return err
@@ -397,8 +526,15 @@ func (app *BaseApp) ResetBootstrapState() error {
 		}
 	}
 
+	if app.readReplica != nil {
+		if err := app.readReplica.DB.(*dbx.DB).Close(); err != nil {
+			return err
+		}
+	}
+
 	app.dao = nil
 	app.logsDao = nil
+	app.readReplica = nil
 
 	return nil
 }
@@ -427,6 +563,11 @@ func (app *BaseApp) Dao() *daos.Dao {
 	return app.dao
 }
 
+// ReadReplica returns the app data read replica (if configured).
+func (app *BaseApp) ReadReplica() *daos.ReadReplica {
+	return app.readReplica
+}
+
 // Deprecated:
 // This method may get removed in the near future.
 // It is recommended to access the logs db instance from app.LogsDao().DB() or
@@ -462,6 +603,11 @@ func (app *BaseApp) EncryptionEnv() string {
 	return app.encryptionEnv
 }
 
+// Version returns the configured app version (if any).
+func (app *BaseApp) Version() string {
+	return app.version
+}
+
 // IsDev returns whether the app is in dev mode.
 //
 // When enabled logs, executed sql statements, etc. are printed to the stderr.
@@ -490,31 +636,119 @@ func (app *BaseApp) SubscriptionsBroker() *subscriptions.Broker {
 	return app.subscriptionsBroker
 }
 
+// Tasks returns the app in-memory background tasks registry.
+func (app *BaseApp) Tasks() *tasks.Registry {
+	return app.tasks
+}
+
 // NewMailClient creates and returns a new SMTP or Sendmail client
 // based on the current app settings.
+//
+// If there are enabled named SMTP transports, the returned client is
+// a [mailer.Transports] instance (see also [mailer.WithTransport]).
 func (app *BaseApp) NewMailClient() mailer.Mailer {
-	if app.Settings().Smtp.Enabled {
+	defaultClient := newSmtpClient(app.Settings().Smtp)
+
+	named := map[string]mailer.Mailer{}
+	for name, config := range app.Settings().SmtpTransports {
+		if config.Enabled {
+			named[name] = newSmtpClient(config)
+		}
+	}
+
+	if len(named) == 0 {
+		return BindMailerSendHook(app, defaultClient)
+	}
+
+	return BindMailerSendHook(app, &mailer.Transports{
+		Default: defaultClient,
+		Named:   named,
+	})
+}
+
+// BindMailerSendHook binds the app OnMailerSend hook to the
+// provided mail client (if it is a [mailer.SendInterceptor]).
+//
+// It is intended to be used with the custom App.NewMailClient() implementations.
+func BindMailerSendHook(app App, client mailer.Mailer) mailer.Mailer {
+	interceptor, ok := client.(mailer.SendInterceptor)
+	if !ok {
+		return client
+	}
+
+	interceptor.OnSend().Add(func(e *mailer.SendEvent) error {
+		event := &MailerSendEvent{
+			App:        app,
+			MailClient: client,
+			Message:    e.Message,
+		}
+
+		var completed bool
+
+		err := app.OnMailerSend().Trigger(event, func(event *MailerSendEvent) error {
+			completed = true
+			e.Message = event.Message
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// the send was skipped with hook.StopPropagation
+		if !completed {
+			return hook.StopPropagation
+		}
+
+		return nil
+	})
+
+	return client
+}
+
+// newSmtpClient creates a new SMTP client from the provided config
+// (fallbacks to Sendmail if the config is not enabled).
+func newSmtpClient(config settings.SmtpConfig) mailer.Mailer {
+	if config.Enabled {
 		return &mailer.SmtpClient{
-			Host:       app.Settings().Smtp.Host,
-			Port:       app.Settings().Smtp.Port,
-			Username:   app.Settings().Smtp.Username,
-			Password:   app.Settings().Smtp.Password,
-			Tls:        app.Settings().Smtp.Tls,
-			AuthMethod: app.Settings().Smtp.AuthMethod,
-			LocalName:  app.Settings().Smtp.LocalName,
+			Host:       config.Host,
+			Port:       config.Port,
+			Username:   config.Username,
+			Password:   config.Password,
+			Tls:        config.Tls,
+			AuthMethod: config.AuthMethod,
+			LocalName:  config.LocalName,
+			Dkim:       newDkimOptions(config.Dkim),
 		}
 	}
 
 	return &mailer.Sendmail{}
 }
 
-// NewFilesystem creates a new local or S3 filesystem instance
-// for managing regular app files (eg. collection uploads)
+// newDkimOptions returns the mailer DKIM options from the provided config
+// (returns nil if no private key is configured).
+func newDkimOptions(config settings.DkimConfig) *mailer.DkimOptions {
+	if config.PrivateKey == "" {
+		return nil
+	}
+
+	return &mailer.DkimOptions{
+		Domain:     config.Domain,
+		Selector:   config.Selector,
+		PrivateKey: config.PrivateKey,
+	}
+}
+
+// NewFilesystem creates a new local, S3, Azure Blob or registered backend
+// filesystem instance for managing regular app files (eg. collection uploads)
 // based on the current app settings.
 //
 // NB! Make sure to call Close() on the returned result
 // after you are done working with it.
 func (app *BaseApp) NewFilesystem() (*filesystem.System, error) {
+	if app.settings != nil && app.settings.Storage.Backend != "" {
+		return filesystem.NewByBackendName(app.settings.Storage.Backend, app.settings.Storage.Options)
+	}
+
 	if app.settings != nil && app.settings.S3.Enabled {
 		return filesystem.NewS3(
 			app.settings.S3.Bucket,
@@ -526,16 +760,29 @@ func (app *BaseApp) NewFilesystem() (*filesystem.System, error) {
 		)
 	}
 
+	if app.settings != nil && app.settings.AzureBlob.Enabled {
+		return filesystem.NewAzureBlob(
+			app.settings.AzureBlob.Account,
+			app.settings.AzureBlob.Key,
+			app.settings.AzureBlob.Container,
+			app.settings.AzureBlob.Prefix,
+		)
+	}
+
 	// fallback to local filesystem
 	return filesystem.NewLocal(filepath.Join(app.DataDir(), LocalStorageDirName))
 }
 
-// NewFilesystem creates a new local or S3 filesystem instance
-// for managing app backups based on the current app settings.
+// NewBackupsFilesystem creates a new local, S3, Azure Blob or registered backend
+// filesystem instance for managing app backups based on the current app settings.
 //
 // NB! Make sure to call Close() on the returned result
 // after you are done working with it.
 func (app *BaseApp) NewBackupsFilesystem() (*filesystem.System, error) {
+	if app.settings != nil && app.settings.Backups.Storage.Backend != "" {
+		return filesystem.NewByBackendName(app.settings.Backups.Storage.Backend, app.settings.Backups.Storage.Options)
+	}
+
 	if app.settings != nil && app.settings.Backups.S3.Enabled {
 		return filesystem.NewS3(
 			app.settings.Backups.S3.Bucket,
@@ -547,6 +794,15 @@ func (app *BaseApp) NewBackupsFilesystem() (*filesystem.System, error) {
 		)
 	}
 
+	if app.settings != nil && app.settings.Backups.AzureBlob.Enabled {
+		return filesystem.NewAzureBlob(
+			app.settings.Backups.AzureBlob.Account,
+			app.settings.Backups.AzureBlob.Key,
+			app.settings.Backups.AzureBlob.Container,
+			app.settings.Backups.AzureBlob.Prefix,
+		)
+	}
+
 	// fallback to local filesystem
 	return filesystem.NewLocal(filepath.Join(app.DataDir(), LocalBackupsDirName))
 }
@@ -578,7 +834,35 @@ func (app *BaseApp) Restart() error {
 }
 
 // RefreshSettings reinitializes and reloads the stored application settings.
+//
+// If the stored settings differ from the current app ones,
+// the OnSettingsReload hook is triggered.
 func (app *BaseApp) RefreshSettings() error {
+	event, err := app.reloadSettings()
+	if err != nil {
+		return err
+	}
+
+	// reload handler level (if initialized)
+	//
+	// note: always reload because the level depends also on the app dev mode
+	if app.logsBatchHandler != nil {
+		app.logsBatchHandler.SetLevel(app.getLoggerMinLevel())
+	}
+
+	if event == nil {
+		return nil // no changes
+	}
+
+	return app.OnSettingsReload().Trigger(event)
+}
+
+// reloadSettings loads the stored settings into the app ones and
+// returns the reload event data (nil if there were no changes).
+func (app *BaseApp) reloadSettings() (*SettingsReloadEvent, error) {
+	app.settingsReloadMux.Lock()
+	defer app.settingsReloadMux.Unlock()
+
 	if app.settings == nil {
 		app.settings = settings.New()
 	}
@@ -587,27 +871,48 @@ func (app *BaseApp) RefreshSettings() error {
 
 	storedSettings, err := app.Dao().FindSettings(encryptionKey)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return err
+		return nil, err
 	}
 
 	// no settings were previously stored
 	if storedSettings == nil {
-		return app.Dao().SaveSettings(app.settings, encryptionKey)
+		return nil, app.Dao().SaveSettings(app.settings, encryptionKey)
+	}
+
+	oldSettings, err := app.settings.Clone()
+	if err != nil {
+		return nil, err
 	}
 
 	// load the settings from the stored param into the app ones
 	if err := app.settings.Merge(storedSettings); err != nil {
-		return err
+		return nil, err
 	}
 
-	// reload handler level (if initialized)
-	if app.Logger() != nil {
-		if h, ok := app.Logger().Handler().(*logger.BatchHandler); ok {
-			h.SetLevel(app.getLoggerMinLevel())
-		}
+	newSettings, err := app.settings.Clone()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	oldRaw, err := json.Marshal(oldSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	newRaw, err := json.Marshal(newSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(oldRaw, newRaw) {
+		return nil, nil // no changes
+	}
+
+	return &SettingsReloadEvent{
+		App:         app,
+		OldSettings: oldSettings,
+		NewSettings: newSettings,
+	}, nil
 }
 
 // -------------------------------------------------------------------
@@ -638,6 +943,10 @@ func (app *BaseApp) OnTerminate() *hook.Hook[*TerminateEvent] {
 	return app.onTerminate
 }
 
+func (app *BaseApp) OnSettingsReload() *hook.Hook[*SettingsReloadEvent] {
+	return app.onSettingsReload
+}
+
 // -------------------------------------------------------------------
 // Dao event hooks
 // -------------------------------------------------------------------
@@ -666,6 +975,18 @@ func (app *BaseApp) OnModelAfterDelete(tags ...string) *hook.TaggedHook[*ModelEv
 	return hook.NewTaggedHook(app.onModelAfterDelete, tags...)
 }
 
+// -------------------------------------------------------------------
+// Soft delete event hooks
+// -------------------------------------------------------------------
+
+func (app *BaseApp) OnRecordAfterTrash(tags ...string) *hook.TaggedHook[*ModelEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterTrash, tags...)
+}
+
+func (app *BaseApp) OnRecordAfterRestore(tags ...string) *hook.TaggedHook[*ModelEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterRestore, tags...)
+}
+
 // -------------------------------------------------------------------
 // Mailer event hooks
 // -------------------------------------------------------------------
@@ -702,6 +1023,14 @@ func (app *BaseApp) OnMailerAfterRecordChangeEmailSend(tags ...string) *hook.Tag
 	return hook.NewTaggedHook(app.onMailerAfterRecordChangeEmailSend, tags...)
 }
 
+func (app *BaseApp) OnMailerSend() *hook.Hook[*MailerSendEvent] {
+	return app.onMailerSend
+}
+
+func (app *BaseApp) OnMailReceive() *hook.Hook[*MailReceiveEvent] {
+	return app.onMailReceive
+}
+
 // -------------------------------------------------------------------
 // Realtime API event hooks
 // -------------------------------------------------------------------
@@ -962,6 +1291,14 @@ func (app *BaseApp) OnRecordAfterDeleteRequest(tags ...string) *hook.TaggedHook[
 	return hook.NewTaggedHook(app.onRecordAfterDeleteRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordEnrich(tags ...string) *hook.TaggedHook[*RecordEnrichEvent] {
+	return hook.NewTaggedHook(app.onRecordEnrich, tags...)
+}
+
+func (app *BaseApp) OnRecordsQuery(tags ...string) *hook.TaggedHook[*RecordsQueryEvent] {
+	return hook.NewTaggedHook(app.onRecordsQuery, tags...)
+}
+
 // -------------------------------------------------------------------
 // Collection API event hooks
 // -------------------------------------------------------------------
@@ -1010,6 +1347,10 @@ func (app *BaseApp) OnCollectionsAfterImportRequest() *hook.Hook[*CollectionsImp
 // Helpers
 // -------------------------------------------------------------------
 
+func (app *BaseApp) dbPragmas() (dbPragmas, error) {
+	return newDBPragmas(app.dbBusyTimeout, app.dbJournalMode, app.dbSynchronous, app.dbCacheSize)
+}
+
 func (app *BaseApp) initLogsDB() error {
 	maxOpenConns := DefaultLogsMaxOpenConns
 	maxIdleConns := DefaultLogsMaxIdleConns
@@ -1020,7 +1361,12 @@ func (app *BaseApp) initLogsDB() error {
 		maxIdleConns = app.logsMaxIdleConns
 	}
 
-	concurrentDB, err := connectDB(filepath.Join(app.DataDir(), "logs.db"))
+	pragmas, err := app.dbPragmas()
+	if err != nil {
+		return err
+	}
+
+	concurrentDB, err := connectDB(filepath.Join(app.DataDir(), "logs.db"), pragmas)
 	if err != nil {
 		return err
 	}
@@ -1028,7 +1374,7 @@ func (app *BaseApp) initLogsDB() error {
 	concurrentDB.DB().SetMaxIdleConns(maxIdleConns)
 	concurrentDB.DB().SetConnMaxIdleTime(3 * time.Minute)
 
-	nonconcurrentDB, err := connectDB(filepath.Join(app.DataDir(), "logs.db"))
+	nonconcurrentDB, err := connectDB(filepath.Join(app.DataDir(), "logs.db"), pragmas)
 	if err != nil {
 		return err
 	}
@@ -1050,8 +1396,17 @@ func (app *BaseApp) initDataDB() error {
 	if app.dataMaxIdleConns > 0 {
 		maxIdleConns = app.dataMaxIdleConns
 	}
+	maxWriteConns := DefaultDataMaxWriteConns
+	if app.dataMaxWriteConns > 0 {
+		maxWriteConns = app.dataMaxWriteConns
+	}
+
+	pragmas, err := app.dbPragmas()
+	if err != nil {
+		return err
+	}
 
-	concurrentDB, err := connectDB(filepath.Join(app.DataDir(), "data.db"))
+	concurrentDB, err := connectDB(filepath.Join(app.DataDir(), "data.db"), pragmas)
 	if err != nil {
 		return err
 	}
@@ -1059,12 +1414,12 @@ func (app *BaseApp) initDataDB() error {
 	concurrentDB.DB().SetMaxIdleConns(maxIdleConns)
 	concurrentDB.DB().SetConnMaxIdleTime(3 * time.Minute)
 
-	nonconcurrentDB, err := connectDB(filepath.Join(app.DataDir(), "data.db"))
+	nonconcurrentDB, err := connectDB(filepath.Join(app.DataDir(), "data.db"), pragmas)
 	if err != nil {
 		return err
 	}
-	nonconcurrentDB.DB().SetMaxOpenConns(1)
-	nonconcurrentDB.DB().SetMaxIdleConns(1)
+	nonconcurrentDB.DB().SetMaxOpenConns(maxWriteConns)
+	nonconcurrentDB.DB().SetMaxIdleConns(maxWriteConns)
 	nonconcurrentDB.DB().SetConnMaxIdleTime(3 * time.Minute)
 
 	if app.IsDev() {
@@ -1078,13 +1433,48 @@ func (app *BaseApp) initDataDB() error {
 		concurrentDB.ExecLogFunc = nonconcurrentDB.ExecLogFunc
 	}
 
+	if app.tracer != nil {
+		app.initDBTracing(concurrentDB, nonconcurrentDB)
+	}
+
 	app.dao = app.createDaoWithHooks(concurrentDB, nonconcurrentDB)
 
+	return app.initDataReplicaDB(pragmas, maxOpenConns, maxIdleConns)
+}
+
+func (app *BaseApp) initDataReplicaDB(pragmas dbPragmas, maxOpenConns, maxIdleConns int) error {
+	if app.dataReplicaPath == "" {
+		return nil
+	}
+
+	replicaDB, err := connectDB(app.dataReplicaPath, pragmas)
+	if err != nil {
+		return err
+	}
+	replicaDB.DB().SetMaxOpenConns(maxOpenConns)
+	replicaDB.DB().SetMaxIdleConns(maxIdleConns)
+	replicaDB.DB().SetConnMaxIdleTime(3 * time.Minute)
+
+	if app.tracer != nil {
+		app.initDBTracing(replicaDB)
+	}
+
+	app.readReplica = &daos.ReadReplica{
+		DB:    replicaDB,
+		Check: app.dataReplicaCheck,
+	}
+
 	return nil
 }
 
 func (app *BaseApp) createDaoWithHooks(concurrentDB, nonconcurrentDB dbx.Builder) *daos.Dao {
 	dao := daos.NewMultiDB(concurrentDB, nonconcurrentDB)
+	dao.EncryptionKey = os.Getenv(app.EncryptionEnv())
+
+	// optional comma separated decryption only keys (eg. during key rotation)
+	if oldKeys := os.Getenv(app.EncryptionEnv() + "_OLD"); oldKeys != "" {
+		dao.OldEncryptionKeys = strings.Split(oldKeys, ",")
+	}
 
 	dao.BeforeCreateFunc = func(eventDao *daos.Dao, m models.Model, action func() error) error {
 		e := new(ModelEvent)
@@ -1140,6 +1530,22 @@ func (app *BaseApp) createDaoWithHooks(concurrentDB, nonconcurrentDB dbx.Builder
 		return app.OnModelAfterDelete().Trigger(e)
 	}
 
+	dao.AfterTrashFunc = func(eventDao *daos.Dao, m models.Model) error {
+		e := new(ModelEvent)
+		e.Dao = eventDao
+		e.Model = m
+
+		return app.OnRecordAfterTrash().Trigger(e)
+	}
+
+	dao.AfterRestoreFunc = func(eventDao *daos.Dao, m models.Model) error {
+		e := new(ModelEvent)
+		e.Dao = eventDao
+		e.Model = m
+
+		return app.OnRecordAfterRestore().Trigger(e)
+	}
+
 	return dao
 }
 
@@ -1168,15 +1574,44 @@ func (app *BaseApp) registerDefaultHooks() {
 
 			// run in the background for "optimistic" delete to avoid
 			// blocking the delete transaction
-			routine.FireAndForget(func() {
-				if err := deletePrefix(prefix); err != nil {
-					app.Logger().Error(
-						"Failed to delete storage prefix (non critical error; usually could happen because of S3 api limits)",
-						slog.String("prefix", prefix),
-						slog.String("error", err.Error()),
-					)
-				}
-			})
+			deleteFunc := func() {
+				routine.FireAndForget(func() {
+					if err := deletePrefix(prefix); err != nil {
+						app.Logger().Error(
+							"Failed to delete storage prefix (non critical error; usually could happen because of S3 api limits)",
+							slog.String("prefix", prefix),
+							slog.String("error", err.Error()),
+						)
+					}
+				})
+			}
+
+			// the after hooks are usually triggered after the transaction commit
+			// but ensure that the files are not deleted from a still active
+			// transaction (eg. a manually created tx dao) that could be rolled back
+			if e.Dao != nil {
+				e.Dao.AfterCommit(deleteFunc)
+			} else {
+				deleteFunc()
+			}
+		}
+
+		return nil
+	})
+
+	// make sure that app.Settings() is always up to date
+	// (eg. when the settings param is saved directly with the Dao)
+	app.OnModelAfterUpdate((&models.Param{}).TableName()).Add(func(e *ModelEvent) error {
+		p, ok := e.Model.(*models.Param)
+		if !ok || p.Key != models.ParamAppSettings {
+			return nil
+		}
+
+		if err := app.RefreshSettings(); err != nil {
+			app.Logger().Debug(
+				"Failed to reload the latest app settings",
+				slog.String("error", err.Error()),
+			)
 		}
 
 		return nil
@@ -1186,7 +1621,14 @@ func (app *BaseApp) registerDefaultHooks() {
 		app.Logger().Error("Failed to init auto backup hooks", slog.String("error", err.Error()))
 	}
 
+	app.initMaildropHooks()
+
+	app.initWebhooksHooks()
+
+	app.initExpiredRecordsHooks()
+
 	registerCachedCollectionsAppHooks(app)
+	registerRecordsListCacheAppHooks(app)
 }
 
 // getLoggerMinLevel returns the logger min level based on the
@@ -1210,6 +1652,45 @@ func (app *BaseApp) getLoggerMinLevel() slog.Level {
 	return minLevel
 }
 
+// isSuccessfulRequestLog reports whether the log record is a non-error request log.
+func isSuccessfulRequestLog(ctx context.Context, r slog.Record) bool {
+	if r.Level >= slog.LevelWarn {
+		return false
+	}
+
+	var isRequest bool
+
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "type" {
+			isRequest = a.Value.String() == "request"
+			return false
+		}
+		return true
+	})
+
+	return isRequest
+}
+
+// stopCron stops the provided cron ticker and waits for its running
+// jobs to complete (up to the terminate event shutdown deadline, if set).
+func stopCron(e *TerminateEvent, c *cron.Cron) {
+	c.Stop()
+
+	ctx := context.Background()
+	if !e.ShutdownDeadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, e.ShutdownDeadline)
+		defer cancel()
+	}
+
+	if err := c.Wait(ctx); err != nil {
+		e.App.Logger().Warn(
+			"Terminated before the running cron jobs completed",
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
 func (app *BaseApp) initLogger() error {
 	duration := 3 * time.Second
 	ticker := time.NewTicker(duration)
@@ -1219,6 +1700,12 @@ func (app *BaseApp) initLogger() error {
 		Level:     app.getLoggerMinLevel(),
 		BatchSize: 200,
 		BeforeAddFunc: func(ctx context.Context, log *logger.Log) bool {
+			// the request logs are also created for the custom log handlers
+			// but we ignore them if the db logs are disabled
+			if app.Settings().Logs.MaxDays == 0 && log.Data["type"] == "request" {
+				return false
+			}
+
 			if app.IsDev() {
 				printLog(log)
 
@@ -1291,7 +1778,22 @@ func (app *BaseApp) initLogger() error {
 		}
 	}()
 
-	app.logger = slog.New(handler)
+	app.logsBatchHandler = handler
+
+	var rootHandler slog.Handler = handler
+
+	if len(app.logHandlers) > 0 {
+		rootHandler = logger.NewMultiHandler(append([]slog.Handler{handler}, app.logHandlers...)...)
+	}
+
+	if app.requestLogsRate > 0 && app.requestLogsRate < 1 {
+		rootHandler = logger.NewSamplingHandler(rootHandler, logger.SamplingOptions{
+			Rate:       app.requestLogsRate,
+			Sampleable: isSuccessfulRequestLog,
+		})
+	}
+
+	app.logger = slog.New(rootHandler)
 
 	app.OnTerminate().PreAdd(func(e *TerminateEvent) error {
 		// write all remaining logs before ticker.Stop to avoid races with ResetBootstrap user calls
@@ -2,24 +2,28 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/pocketbase/pocketbase/daos"
-	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tools/archive"
 	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/osutils"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 // Deprecated: Replaced with StoreKeyActiveBackup.
@@ -27,16 +31,23 @@ const CacheKeyActiveBackup string = "@activeBackup"
 
 const StoreKeyActiveBackup string = "@activeBackup"
 
+// backupCreatedMetaKey is the backup file metadata key holding
+// the backup creation time (stored at the start of the backup transaction).
+const backupCreatedMetaKey = "pb-backup-created"
+
+// BackupChecksumSuffix is the file key suffix of the backup checksum files
+// stored alongside the generated backups (eg. "pb_backup.zip.sha256").
+const BackupChecksumSuffix string = ".sha256"
+
 // CreateBackup creates a new backup of the current app pb_data directory.
 //
 // If name is empty, it will be autogenerated.
 // If backup with the same name exists, the new backup file will replace it.
 //
-// The backup is executed within a transaction, meaning that new writes
-// will be temporary "blocked" until the backup file is generated.
-//
-// To safely perform the backup, it is recommended to have free disk space
-// for at least 2x the size of the pb_data directory.
+// The backup archive is streamed directly to the backups filesystem while it is
+// being generated (without a full local temp copy) and it is executed within
+// a transaction, meaning that new writes will be temporary "blocked" until
+// the backup file is uploaded.
 //
 // By default backups are stored in pb_data/backups
 // (the backups directory itself is excluded from the generated backup).
@@ -45,6 +56,10 @@ const StoreKeyActiveBackup string = "@activeBackup"
 // take care manually to backup those since they are not part of the pb_data.
 //
 // Backups can be stored on S3 if it is configured in app.Settings().Backups.
+//
+// If app.Settings().Backups.EncryptionKey is set, the backup is encrypted with it.
+// A SHA-256 checksum of the stored backup file is saved alongside it
+// (with the [BackupChecksumSuffix] key suffix).
 func (app *BaseApp) CreateBackup(ctx context.Context, name string) error {
 	if app.Store().Has(StoreKeyActiveBackup) {
 		return errors.New("try again later - another backup/restore operation has already been started")
@@ -60,51 +75,142 @@ func (app *BaseApp) CreateBackup(ctx context.Context, name string) error {
 	// root dir entries to exclude from the backup generation
 	exclude := []string{LocalBackupsDirName, LocalTempDirName}
 
-	// make sure that the special temp directory exists
-	// note: it needs to be inside the current pb_data to avoid "cross-device link" errors
-	localTempDir := filepath.Join(app.DataDir(), LocalTempDirName)
-	if err := os.MkdirAll(localTempDir, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create a temp dir: %w", err)
+	metadata := map[string]string{
+		backupCreatedMetaKey: types.NowDateTime().String(),
 	}
 
-	// Archive pb_data in a temp directory, exluding the "backups" and the temp dirs.
+	// Archive pb_data in a local temp file, exluding the "backups" and the temp dirs.
 	//
 	// Run in transaction to temporary block other writes (transactions uses the NonconcurrentDB connection).
 	// ---
-	tempPath := filepath.Join(localTempDir, "pb_backup_"+security.PseudorandomString(4))
+	var backup *backupTempFile
 	createErr := app.Dao().RunInTransaction(func(dataTXDao *daos.Dao) error {
 		return app.LogsDao().RunInTransaction(func(logsTXDao *daos.Dao) error {
 			// @todo consider experimenting with temp switching the readonly pragma after the db interface change
-			return archive.Create(app.DataDir(), tempPath, exclude...)
+			var err error
+			backup, err = app.writeBackupTempFile(func(w io.Writer) error {
+				return archive.CreateStream(w, app.DataDir(), exclude...)
+			})
+			return err
 		})
 	})
 	if createErr != nil {
 		return createErr
 	}
-	defer os.Remove(tempPath)
+	defer os.Remove(backup.path)
 
-	// Persist the backup in the backups filesystem.
-	// ---
+	// Persist the backup in the backups filesystem
+	// (after the transaction to not block the writes during the upload).
+	return app.uploadBackup(ctx, backup, name, metadata)
+}
+
+// backupTempFile defines a local backup file that is ready to be uploaded.
+type backupTempFile struct {
+	path        string
+	checksum    string
+	contentType string
+}
+
+// writeBackupTempFile writes the (optionally encrypted) write func content
+// to a new local temp file and returns its path and hex encoded SHA-256 checksum.
+//
+// The temp file is removed on failure.
+func (app *BaseApp) writeBackupTempFile(write func(w io.Writer) error) (result *backupTempFile, err error) {
+	// make sure that the special temp directory exists
+	localTempDir := filepath.Join(app.DataDir(), LocalTempDirName)
+	if err := os.MkdirAll(localTempDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create a temp dir: %w", err)
+	}
+
+	f, err := os.CreateTemp(localTempDir, "pb_backup_")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			os.Remove(f.Name())
+			result = nil
+		}
+	}()
+
+	encryptionKey := app.Settings().Backups.EncryptionKey
+
+	result = &backupTempFile{
+		path:        f.Name(),
+		contentType: "application/zip",
+	}
+
+	hash := sha256.New()
+
+	var w io.Writer = io.MultiWriter(f, hash)
+
+	if encryptionKey != "" {
+		result.contentType = "application/octet-stream"
+
+		ew, err := security.NewEncryptWriter(w, encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := write(ew); err != nil {
+			return nil, err
+		}
+
+		if err := ew.Close(); err != nil {
+			return nil, err
+		}
+	} else if err := write(w); err != nil {
+		return nil, err
+	}
+
+	result.checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return result, nil
+}
+
+// uploadBackup streams the local backup file to the name file of
+// the backups filesystem and stores its checksum alongside it.
+//
+// On failure the upload is aborted.
+func (app *BaseApp) uploadBackup(ctx context.Context, backup *backupTempFile, name string, metadata map[string]string) (err error) {
 	fsys, err := app.NewBackupsFilesystem()
 	if err != nil {
 		return err
 	}
 	defer fsys.Close()
 
-	fsys.SetContext(ctx)
+	// the upload is aborted on failure by canceling its context
+	uploadCtx, cancelUpload := context.WithCancel(ctx)
+	defer cancelUpload()
+
+	fsys.SetContext(uploadCtx)
 
-	file, err := filesystem.NewFileFromPath(tempPath)
+	f, err := os.Open(backup.path)
 	if err != nil {
 		return err
 	}
-	file.OriginalName = name
-	file.Name = file.OriginalName
+	defer f.Close()
 
-	if err := fsys.UploadFile(file, file.Name); err != nil {
+	bw, err := fsys.NewWriter(name, backup.contentType, metadata)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	if _, err := io.Copy(bw, f); err != nil {
+		cancelUpload()
+		bw.Close()
+		return err
+	}
+
+	if err := bw.Close(); err != nil {
+		return err
+	}
+
+	return fsys.Upload([]byte(backup.checksum), name+BackupChecksumSuffix)
 }
 
 // RestoreBackup restores the backup with the specified name and restarts
@@ -119,19 +225,22 @@ func (app *BaseApp) CreateBackup(ctx context.Context, name string) error {
 //
 //  1. Download the backup with the specified name in a temp location
 //     (this is in case of S3; otherwise it creates a temp copy of the zip)
+//     and verify its checksum (if the backup has a checksum file).
 //
-//  2. Extract the backup in a temp directory inside the app "pb_data"
+//  2. Decrypt the backup with app.Settings().Backups.EncryptionKey (if it is encrypted).
+//
+//  3. Extract the backup in a temp directory inside the app "pb_data"
 //     (eg. "pb_data/.pb_temp_to_delete/pb_restore").
 //
-//  3. Move the current app "pb_data" content (excluding the local backups and the special temp dir)
+//  4. Move the current app "pb_data" content (excluding the local backups and the special temp dir)
 //     under another temp sub dir that will be deleted on the next app start up
 //     (eg. "pb_data/.pb_temp_to_delete/old_pb_data").
 //     This is because on some environments it may not be allowed
 //     to delete the currently open "pb_data" files.
 //
-//  4. Move the extracted dir content to the app "pb_data".
+//  5. Move the extracted dir content to the app "pb_data".
 //
-//  5. Restart the app (on successful app bootstap it will also remove the old pb_data).
+//  6. Restart the app (on successful app bootstap it will also remove the old pb_data).
 //
 // If a failure occure during the restore process the dir changes are reverted.
 // If for whatever reason the revert is not possible, it panics.
@@ -155,13 +264,6 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 
 	fsys.SetContext(ctx)
 
-	// fetch the backup file in a temp location
-	br, err := fsys.GetFile(name)
-	if err != nil {
-		return err
-	}
-	defer br.Close()
-
 	// make sure that the special temp directory exists
 	// note: it needs to be inside the current pb_data to avoid "cross-device link" errors
 	localTempDir := filepath.Join(app.DataDir(), LocalTempDirName)
@@ -169,20 +271,16 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 		return fmt.Errorf("failed to create a temp dir: %w", err)
 	}
 
-	// create a temp zip file from the blob.Reader and try to extract it
-	tempZip, err := os.CreateTemp(localTempDir, "pb_restore_zip")
+	// fetch, verify and decrypt the backup file in a temp location
+	backup, err := app.fetchBackup(fsys, name, localTempDir)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(tempZip.Name())
-
-	if _, err := io.Copy(tempZip, br); err != nil {
-		return err
-	}
+	defer backup.remove()
 
 	extractedDataDir := filepath.Join(localTempDir, "pb_restore_"+security.PseudorandomString(4))
 	defer os.RemoveAll(extractedDataDir)
-	if err := archive.Extract(tempZip.Name(), extractedDataDir); err != nil {
+	if err := archive.Extract(backup.path, extractedDataDir); err != nil {
 		return err
 	}
 
@@ -192,16 +290,16 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 		return fmt.Errorf("data.db file is missing or invalid: %w", err)
 	}
 
-	// remove the extracted zip file since we no longer need it
+	// remove the temp backup files since we no longer need them
 	// (this is in case the app restarts and the defer calls are not called)
-	if err := os.Remove(tempZip.Name()); err != nil {
-		app.Logger().Debug(
-			"[RestoreBackup] Failed to remove the temp zip backup file",
-			slog.String("file", tempZip.Name()),
-			slog.String("error", err.Error()),
-		)
-	}
+	backup.remove()
 
+	return app.replaceDataDir(extractedDataDir, localTempDir)
+}
+
+// replaceDataDir replaces the current app pb_data content with the
+// extractedDataDir content and restarts the app (see [BaseApp.RestoreBackup]).
+func (app *BaseApp) replaceDataDir(extractedDataDir string, localTempDir string) error {
 	// root dir entries to exclude from the backup restore
 	exclude := []string{LocalBackupsDirName, LocalTempDirName}
 
@@ -242,6 +340,80 @@ func (app *BaseApp) RestoreBackup(ctx context.Context, name string) error {
 	return nil
 }
 
+// fetchedBackup describes a downloaded and decrypted backup file.
+type fetchedBackup struct {
+	app *BaseApp
+
+	// path is the local path of the decrypted backup zip.
+	path string
+
+	// checksum is the hex encoded SHA-256 checksum of the stored backup file.
+	checksum string
+
+	tempFiles []string
+}
+
+// remove deletes the fetched backup temp files.
+func (b *fetchedBackup) remove() {
+	for _, f := range b.tempFiles {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			b.app.Logger().Debug(
+				"[RestoreBackup] Failed to remove the temp backup file",
+				slog.String("file", f),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// fetchBackup downloads the name backup file in a temp location inside dir,
+// verifies its checksum and decrypts it (if it is encrypted).
+//
+// NB! Make sure to call `remove()` after you are done working with it.
+func (app *BaseApp) fetchBackup(fsys *filesystem.System, name string, dir string) (*fetchedBackup, error) {
+	br, err := fsys.GetFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer br.Close()
+
+	// create a temp file from the blob.Reader and verify its checksum
+	tempFile, err := os.CreateTemp(dir, "pb_restore_file")
+	if err != nil {
+		return nil, err
+	}
+	defer tempFile.Close()
+
+	result := &fetchedBackup{app: app, tempFiles: []string{tempFile.Name()}}
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(io.MultiWriter(tempFile, hash), br); err != nil {
+		result.remove()
+		return nil, err
+	}
+
+	result.checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := verifyBackupChecksum(fsys, name, result.checksum); err != nil {
+		result.remove()
+		return nil, err
+	}
+
+	// decrypt the backup (if encrypted) in a new temp zip file
+	result.path, err = app.decryptBackup(tempFile, dir)
+	if err != nil {
+		result.remove()
+		return nil, err
+	}
+
+	if result.path != tempFile.Name() {
+		result.tempFiles = append(result.tempFiles, result.path)
+	}
+
+	return result, nil
+}
+
 // initAutobackupHooks registers the autobackup app serve hooks.
 func (app *BaseApp) initAutobackupHooks() error {
 	c := cron.New()
@@ -250,16 +422,6 @@ func (app *BaseApp) initAutobackupHooks() error {
 	loadJob := func() {
 		c.Stop()
 
-		// make sure that app.Settings() is always up to date
-		//
-		// @todo remove with the refactoring as core.App and daos.Dao will be one.
-		if err := app.RefreshSettings(); err != nil {
-			app.Logger().Debug(
-				"[Backup cron] Failed to get the latest app settings",
-				slog.String("error", err.Error()),
-			)
-		}
-
 		rawSchedule := app.Settings().Backups.Cron
 		if rawSchedule == "" || !isServe || !app.IsBootstrapped() {
 			return
@@ -303,6 +465,15 @@ func (app *BaseApp) initAutobackupHooks() error {
 				return
 			}
 
+			// exclude the checksum files (they are removed together with their backup)
+			backups := files[:0]
+			for _, f := range files {
+				if !strings.HasSuffix(f.Key, BackupChecksumSuffix) {
+					backups = append(backups, f)
+				}
+			}
+			files = backups
+
 			if maxKeep >= len(files) {
 				return // nothing to remove
 			}
@@ -322,6 +493,12 @@ func (app *BaseApp) initAutobackupHooks() error {
 						slog.String("key", f.Key),
 						slog.String("error", err.Error()),
 					)
+					continue
+				}
+
+				// the backup may not have a checksum file
+				if exists, _ := fsys.Exists(f.Key + BackupChecksumSuffix); exists {
+					fsys.Delete(f.Key + BackupChecksumSuffix)
 				}
 			}
 		})
@@ -337,27 +514,96 @@ func (app *BaseApp) initAutobackupHooks() error {
 		return nil
 	})
 
-	// stop the ticker on app termination
+	// stop the ticker and wait for the running backup on app termination
 	app.OnTerminate().Add(func(e *TerminateEvent) error {
-		c.Stop()
+		stopCron(e, c)
 		return nil
 	})
 
 	// reload on app settings change
-	app.OnModelAfterUpdate((&models.Param{}).TableName()).Add(func(e *ModelEvent) error {
-		p := e.Model.(*models.Param)
-		if p == nil || p.Key != models.ParamAppSettings {
-			return nil
-		}
-
+	app.OnSettingsReload().Add(func(e *SettingsReloadEvent) error {
 		loadJob()
-
 		return nil
 	})
 
 	return nil
 }
 
+// verifyBackupChecksum compares the provided checksum with the one stored
+// alongside the name backup file (if any).
+func verifyBackupChecksum(fsys *filesystem.System, name string, checksum string) error {
+	checksumKey := name + BackupChecksumSuffix
+
+	exists, err := fsys.Exists(checksumKey)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return nil // nothing to verify (eg. an uploaded backup)
+	}
+
+	r, err := fsys.GetFile(checksumKey)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	expected, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(string(expected)), checksum) {
+		return errors.New("the backup file checksum doesn't match - the file may be corrupted or modified")
+	}
+
+	return nil
+}
+
+// decryptBackup decrypts the provided backup file (if it is encrypted) in
+// a new temp file inside dir and returns its path.
+//
+// If the backup is not encrypted, it returns the path of the provided file.
+func (app *BaseApp) decryptBackup(f *os.File, dir string) (string, error) {
+	header := make([]byte, 32)
+	n, err := f.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	if !security.IsEncryptedStream(header[:n]) {
+		return f.Name(), nil
+	}
+
+	encryptionKey := app.Settings().Backups.EncryptionKey
+	if encryptionKey == "" {
+		return "", errors.New("the backup is encrypted but there is no backups encryption key configured")
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	dr, err := security.NewDecryptReader(f, encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	decrypted, err := os.CreateTemp(dir, "pb_restore_zip")
+	if err != nil {
+		return "", err
+	}
+	defer decrypted.Close()
+
+	if _, err := io.Copy(decrypted, dr); err != nil {
+		os.Remove(decrypted.Name())
+		return "", fmt.Errorf("failed to decrypt the backup: %w", err)
+	}
+
+	return decrypted.Name(), nil
+}
+
 func (app *BaseApp) generateBackupName(prefix string) string {
 	appName := inflector.Snakecase(app.Settings().Meta.AppName)
 	if len(appName) > 50 {
@@ -0,0 +1,870 @@
+package core
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/archive"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// IncrementalBackupVersion is the current incremental backup artifact format version.
+const IncrementalBackupVersion = 1
+
+// IncrementalBackupOverlap is the time overlap with the parent backup
+// used when collecting the incremental backup changes.
+//
+// It is to ensure that the records saved with an "updated" value
+// set right before the parent backup transaction are not missed
+// (the overlapping changes are applied again on restore which is harmless).
+var IncrementalBackupOverlap = 5 * time.Minute
+
+const (
+	backupBaseMetaKey     = "pb-backup-base"
+	backupSequenceMetaKey = "pb-backup-sequence"
+
+	incrementalManifestFile = "manifest.json"
+	incrementalChangesFile  = "changes.jsonl"
+	incrementalIdsFile      = "ids.jsonl"
+	incrementalFilesFile    = "files.json"
+	incrementalFilesDir     = "files/"
+)
+
+// incrementalManifest is the manifest of an incremental backup artifact.
+type incrementalManifest struct {
+	Version int `json:"version"`
+
+	// Base is the name of the full backup of the chain.
+	Base string `json:"base"`
+
+	// Parent is the name of the previous chain backup
+	// (the full backup for the first incremental backup).
+	Parent string `json:"parent"`
+
+	// ParentChecksum is the SHA-256 checksum of the stored parent backup file.
+	ParentChecksum string `json:"parentChecksum"`
+
+	// Sequence is the incremental backup position in the chain (starting from 1).
+	Sequence int `json:"sequence"`
+
+	// Since is the inclusive "updated" watermark of the collected changes.
+	Since string `json:"since"`
+
+	// Created is the backup creation time (the next chain backup watermark).
+	Created string `json:"created"`
+
+	// SchemaHash is the hash of the data.db schema at the time of the backup.
+	SchemaHash string `json:"schemaHash"`
+
+	// FullTables lists the tables without "id" and "updated" columns
+	// that are stored with all of their rows (eg. "_migrations").
+	FullTables []string `json:"fullTables"`
+}
+
+type incrementalRow struct {
+	Table string         `json:"table"`
+	Row   map[string]any `json:"row"`
+}
+
+type incrementalIds struct {
+	Table string   `json:"table"`
+	Ids   []string `json:"ids"`
+}
+
+// CreateIncrementalBackup creates a new incremental backup with only the
+// pb_data changes since the specified parent backup.
+//
+// The parent could be a full backup (see [BaseApp.CreateBackup]) or another
+// incremental backup, forming a chain "base <- diff1 <- diff2 ...".
+// If name is empty, it will be autogenerated.
+//
+// The changes are detected with the "updated" column of the data.db tables and
+// the modification time of the other pb_data files (eg. the local storage uploads).
+// The incremental backup is a zip archive (encrypted as the full backups, if configured) with:
+//
+//	manifest.json - the chain metadata (base, parent and its checksum, sequence, watermarks, schema hash)
+//	changes.jsonl - JSON lines with the changed rows ({"table":"...","row":{...}})
+//	ids.jsonl     - JSON lines with all current row ids per table ({"table":"...","ids":[...]}) used to apply the deletes
+//	files.json    - list with all current pb_data file paths used to apply the file deletes
+//	files/...     - the changed pb_data files
+//
+// Note that the schema changes are not captured and the logs.db is not part of the incremental backups,
+// meaning that a new full backup must be created after changing the collections schema.
+func (app *BaseApp) CreateIncrementalBackup(ctx context.Context, parent string, name string) error {
+	if app.Store().Has(StoreKeyActiveBackup) {
+		return errors.New("try again later - another backup/restore operation has already been started")
+	}
+
+	if name == "" {
+		name = app.generateBackupName("pb_incremental_backup_")
+	}
+
+	if name == parent {
+		return errors.New("the incremental backup name must be different from its parent")
+	}
+
+	app.Store().Set(StoreKeyActiveBackup, name)
+	defer app.Store().Remove(StoreKeyActiveBackup)
+
+	fsys, err := app.NewBackupsFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	fsys.SetContext(ctx)
+
+	manifest, err := newIncrementalManifest(fsys, parent)
+	if err != nil {
+		return err
+	}
+
+	var metadata map[string]string
+	var backup *backupTempFile
+	createErr := app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		manifest.Created = types.NowDateTime().String()
+
+		manifest.SchemaHash, err = schemaHash(txDao)
+		if err != nil {
+			return err
+		}
+
+		metadata = map[string]string{
+			backupCreatedMetaKey:  manifest.Created,
+			backupBaseMetaKey:     manifest.Base,
+			backupSequenceMetaKey: strconv.Itoa(manifest.Sequence),
+		}
+
+		backup, err = app.writeBackupTempFile(func(w io.Writer) error {
+			return app.writeIncrementalArchive(w, txDao, manifest)
+		})
+
+		return err
+	})
+	if createErr != nil {
+		return createErr
+	}
+	defer os.Remove(backup.path)
+
+	// upload after the transaction to not block the writes during the upload
+	return app.uploadBackup(ctx, backup, name, metadata)
+}
+
+// newIncrementalManifest initializes a new incremental backup manifest
+// from the metadata and checksum of the specified parent backup.
+func newIncrementalManifest(fsys *filesystem.System, parent string) (*incrementalManifest, error) {
+	attrs, err := fsys.Attributes(parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the parent backup %q: %w", parent, err)
+	}
+
+	parentCreated, err := types.ParseDateTime(attrs.Metadata[backupCreatedMetaKey])
+	if err != nil || parentCreated.IsZero() {
+		return nil, fmt.Errorf("the parent backup %q is missing its creation time metadata (eg. an uploaded backup) - please create a new full backup", parent)
+	}
+
+	manifest := &incrementalManifest{
+		Version:  IncrementalBackupVersion,
+		Base:     parent,
+		Parent:   parent,
+		Sequence: 1,
+	}
+
+	if base := attrs.Metadata[backupBaseMetaKey]; base != "" {
+		sequence, _ := strconv.Atoi(attrs.Metadata[backupSequenceMetaKey])
+		manifest.Base = base
+		manifest.Sequence = sequence + 1
+	}
+
+	since, _ := types.ParseDateTime(parentCreated.Time().Add(-IncrementalBackupOverlap))
+	manifest.Since = since.String()
+
+	checksumReader, err := fsys.GetFile(parent + BackupChecksumSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the parent backup %q checksum: %w", parent, err)
+	}
+	defer checksumReader.Close()
+
+	rawChecksum, err := io.ReadAll(checksumReader)
+	if err != nil {
+		return nil, err
+	}
+	manifest.ParentChecksum = strings.TrimSpace(string(rawChecksum))
+
+	return manifest, nil
+}
+
+func (app *BaseApp) writeIncrementalArchive(w io.Writer, txDao *daos.Dao, manifest *incrementalManifest) error {
+	zw := zip.NewWriter(w)
+
+	tables, err := dataTables(txDao)
+	if err != nil {
+		return err
+	}
+
+	// changed rows
+	// ---
+	changesWriter, err := zw.Create(incrementalChangesFile)
+	if err != nil {
+		return err
+	}
+	changesEncoder := json.NewEncoder(changesWriter)
+
+	incrementalTables := make([]string, 0, len(tables))
+	manifest.FullTables = []string{}
+
+	for _, table := range tables {
+		columns, err := txDao.TableColumns(table)
+		if err != nil {
+			return err
+		}
+
+		query := txDao.DB().Select("*").From(table)
+
+		if list.ExistInSlice("id", columns) && list.ExistInSlice("updated", columns) {
+			incrementalTables = append(incrementalTables, table)
+			query.AndWhere(dbx.NewExp("[[updated]] >= {:since}", dbx.Params{"since": manifest.Since}))
+		} else {
+			manifest.FullTables = append(manifest.FullTables, table)
+		}
+
+		if err := encodeTableRows(changesEncoder, table, query); err != nil {
+			return err
+		}
+	}
+
+	// all current row ids
+	// ---
+	idsWriter, err := zw.Create(incrementalIdsFile)
+	if err != nil {
+		return err
+	}
+	idsEncoder := json.NewEncoder(idsWriter)
+
+	for _, table := range incrementalTables {
+		ids := []string{}
+
+		if err := txDao.DB().Select("id").From(table).Column(&ids); err != nil {
+			return err
+		}
+
+		if err := idsEncoder.Encode(incrementalIds{Table: table, Ids: ids}); err != nil {
+			return err
+		}
+	}
+
+	// changed files
+	// ---
+	since, err := types.ParseDateTime(manifest.Since)
+	if err != nil {
+		return err
+	}
+
+	files := []string{}
+
+	walkErr := filepath.WalkDir(app.DataDir(), func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(app.DataDir(), filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if d.IsDir() {
+			if relPath == LocalBackupsDirName || relPath == LocalTempDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isIncrementalSkippedFile(relPath) || !d.Type().IsRegular() {
+			return nil
+		}
+
+		files = append(files, relPath)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().Before(since.Time()) {
+			return nil // unchanged
+		}
+
+		return zipAddFile(zw, incrementalFilesDir+relPath, filePath)
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+
+	if err := zipAddJSON(zw, incrementalFilesFile, files); err != nil {
+		return err
+	}
+
+	// the manifest is written last since it contains the collected full tables
+	if err := zipAddJSON(zw, incrementalManifestFile, manifest); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// RestoreIncrementalBackup restores the incremental backup chain ending with
+// the specified backup and restarts the current running application process.
+//
+// NB! This feature is experimental and currently is expected to work only on UNIX based systems.
+//
+// The performed steps are:
+//
+//  1. Download, verify and decrypt all chain backups (following the manifest parents up to the full backup).
+//
+//  2. Validate the chain integrity - each backup must reference the checksum of
+//     its actual parent backup file, the chain sequence must be continuous and
+//     all backups must be from the same full backup.
+//
+//  3. Extract the full backup in a temp directory and apply the incremental
+//     backups changes in order (each backup schema hash must match the restored data.db schema).
+//
+//  4. Replace the app "pb_data" content and restart the app (see [BaseApp.RestoreBackup]).
+//
+// If a failure occure during the restore process the dir changes are reverted.
+// If for whatever reason the revert is not possible, it panics.
+func (app *BaseApp) RestoreIncrementalBackup(ctx context.Context, name string) error {
+	if runtime.GOOS == "windows" {
+		return errors.New("restore is not supported on windows")
+	}
+
+	if app.Store().Has(StoreKeyActiveBackup) {
+		return errors.New("try again later - another backup/restore operation has already been started")
+	}
+
+	app.Store().Set(StoreKeyActiveBackup, name)
+	defer app.Store().Remove(StoreKeyActiveBackup)
+
+	fsys, err := app.NewBackupsFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	fsys.SetContext(ctx)
+
+	// make sure that the special temp directory exists
+	// note: it needs to be inside the current pb_data to avoid "cross-device link" errors
+	localTempDir := filepath.Join(app.DataDir(), LocalTempDirName)
+	if err := os.MkdirAll(localTempDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create a temp dir: %w", err)
+	}
+
+	// fetch the chain backups (from the newest to the base)
+	// ---
+	var chain []*fetchedBackup
+	var manifests []*incrementalManifest
+	defer func() {
+		for _, b := range chain {
+			b.remove()
+		}
+	}()
+
+	current := name
+	for {
+		backup, err := app.fetchBackup(fsys, current, localTempDir)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the chain backup %q: %w", current, err)
+		}
+		chain = append(chain, backup)
+
+		manifest, err := readIncrementalManifest(backup.path)
+		if err != nil {
+			return fmt.Errorf("failed to read the chain backup %q: %w", current, err)
+		}
+
+		if manifest == nil {
+			break // full backup
+		}
+
+		if len(manifests) > 0 {
+			if err := validateIncrementalParent(manifests[len(manifests)-1], current, manifest.Sequence, backup.checksum); err != nil {
+				return err
+			}
+		}
+		manifests = append(manifests, manifest)
+
+		current = manifest.Parent
+	}
+
+	if len(manifests) == 0 {
+		return fmt.Errorf("%q is not an incremental backup", name)
+	}
+
+	if err := validateIncrementalParent(manifests[len(manifests)-1], current, 0, chain[len(chain)-1].checksum); err != nil {
+		return err
+	}
+
+	for _, manifest := range manifests {
+		if manifest.Base != current {
+			return fmt.Errorf("the chain backup base %q doesn't match with the chain full backup %q", manifest.Base, current)
+		}
+	}
+
+	// extract the full backup and apply the incremental changes
+	// ---
+	extractedDataDir := filepath.Join(localTempDir, "pb_restore_"+security.PseudorandomString(4))
+	defer os.RemoveAll(extractedDataDir)
+	if err := archive.Extract(chain[len(chain)-1].path, extractedDataDir); err != nil {
+		return err
+	}
+
+	// ensure that a database file exists
+	extractedDB := filepath.Join(extractedDataDir, "data.db")
+	if _, err := os.Stat(extractedDB); err != nil {
+		return fmt.Errorf("data.db file is missing or invalid: %w", err)
+	}
+
+	// apply from the oldest to the newest
+	for i := len(manifests) - 1; i >= 0; i-- {
+		if err := applyIncrementalBackup(chain[i].path, manifests[i], extractedDataDir); err != nil {
+			return fmt.Errorf("failed to apply the incremental backup %d: %w", manifests[i].Sequence, err)
+		}
+	}
+
+	// remove the temp backup files since we no longer need them
+	// (this is in case the app restarts and the defer calls are not called)
+	for _, b := range chain {
+		b.remove()
+	}
+
+	return app.replaceDataDir(extractedDataDir, localTempDir)
+}
+
+// validateIncrementalParent checks whether the incremental backup
+// manifest matches with its fetched parent backup.
+func validateIncrementalParent(manifest *incrementalManifest, parent string, parentSequence int, parentChecksum string) error {
+	if manifest.Parent != parent {
+		return fmt.Errorf("invalid incremental backups chain - expected parent %q, got %q", manifest.Parent, parent)
+	}
+
+	if manifest.Sequence != parentSequence+1 {
+		return fmt.Errorf("invalid incremental backups chain - expected sequence %d after %d", manifest.Sequence, parentSequence)
+	}
+
+	if !strings.EqualFold(manifest.ParentChecksum, parentChecksum) {
+		return fmt.Errorf("invalid incremental backups chain - the parent %q checksum doesn't match (the parent backup may have been replaced or modified)", parent)
+	}
+
+	return nil
+}
+
+// readIncrementalManifest reads the manifest of the provided backup zip.
+//
+// Returns nil manifest if the backup is not an incremental backup.
+func readIncrementalManifest(zipPath string) (*incrementalManifest, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	f, err := zr.Open(incrementalManifestFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	manifest := &incrementalManifest{}
+	if err := json.NewDecoder(f).Decode(manifest); err != nil {
+		return nil, err
+	}
+
+	if manifest.Version != IncrementalBackupVersion {
+		return nil, fmt.Errorf("unsupported incremental backup version %d", manifest.Version)
+	}
+
+	return manifest, nil
+}
+
+// applyIncrementalBackup applies the incremental backup changes
+// on top of the restored pb_data dir.
+func applyIncrementalBackup(zipPath string, manifest *incrementalManifest, dataDir string) error {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	if err := applyIncrementalData(&zr.Reader, manifest, filepath.Join(dataDir, "data.db")); err != nil {
+		return err
+	}
+
+	return applyIncrementalFiles(&zr.Reader, dataDir)
+}
+
+func applyIncrementalData(zr *zip.Reader, manifest *incrementalManifest, dbPath string) error {
+	db, err := connectDB(dbPath, defaultDBPragmas())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// use a single connection so that the pragma applies to the transaction below
+	db.DB().SetMaxOpenConns(1)
+
+	// disable the foreign keys to prevent the "ON DELETE CASCADE" of the replaced rows
+	if _, err := db.NewQuery("PRAGMA foreign_keys = OFF").Execute(); err != nil {
+		return err
+	}
+
+	dao := daos.New(db)
+
+	hash, err := schemaHash(dao)
+	if err != nil {
+		return err
+	}
+
+	if hash != manifest.SchemaHash {
+		return errors.New("the restored database schema doesn't match with the incremental backup schema (the schema was probably changed after the full backup)")
+	}
+
+	return dao.RunInTransaction(func(txDao *daos.Dao) error {
+		// delete the rows that no longer exist
+		err := readZipJSONLines(zr, incrementalIdsFile, func(dec *json.Decoder) error {
+			item := incrementalIds{}
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+
+			return deleteMissingRows(txDao, item.Table, item.Ids)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, table := range manifest.FullTables {
+			if _, err := txDao.DB().Delete(table, nil).Execute(); err != nil {
+				return err
+			}
+		}
+
+		// insert or replace the changed rows
+		return readZipJSONLines(zr, incrementalChangesFile, func(dec *json.Decoder) error {
+			item := incrementalRow{}
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+
+			for k, v := range item.Row {
+				if n, ok := v.(json.Number); ok {
+					item.Row[k] = jsonNumberValue(n)
+				}
+			}
+
+			return upsertRow(txDao, item.Table, item.Row)
+		})
+	})
+}
+
+func applyIncrementalFiles(zr *zip.Reader, dataDir string) error {
+	// extract the changed files
+	for _, f := range zr.File {
+		relPath, ok := strings.CutPrefix(f.Name, incrementalFilesDir)
+		if !ok || relPath == "" {
+			continue
+		}
+
+		if err := extractZipFile(f, dataDir, relPath); err != nil {
+			return err
+		}
+	}
+
+	// delete the files that no longer exist
+	var files []string
+	if err := readZipJSON(zr, incrementalFilesFile, &files); err != nil {
+		return err
+	}
+
+	existing := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		existing[f] = struct{}{}
+	}
+
+	return filepath.WalkDir(dataDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dataDir, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if _, ok := existing[relPath]; ok || isIncrementalSkippedFile(relPath) {
+			return nil
+		}
+
+		return os.Remove(filePath)
+	})
+}
+
+// -------------------------------------------------------------------
+
+// isIncrementalSkippedFile reports whether the relative pb_data file path
+// is excluded from the incremental backups files (the root database files).
+func isIncrementalSkippedFile(relPath string) bool {
+	if strings.Contains(relPath, "/") {
+		return false
+	}
+
+	return strings.HasPrefix(relPath, "data.db") || strings.HasPrefix(relPath, "logs.db")
+}
+
+// dataTables returns the names of all non-system tables of the db (excluding the views).
+func dataTables(dao *daos.Dao) ([]string, error) {
+	tables := []string{}
+
+	err := dao.DB().Select("name").
+		From("sqlite_schema").
+		AndWhere(dbx.HashExp{"type": "table"}).
+		AndWhere(dbx.Not(dbx.Like("name", "sqlite_").Match(false, true))).
+		OrderBy("name ASC").
+		Column(&tables)
+
+	return tables, err
+}
+
+// schemaHash returns a hex encoded SHA-256 hash of the db schema.
+func schemaHash(dao *daos.Dao) (string, error) {
+	rows := []struct {
+		Type string `db:"type"`
+		Name string `db:"name"`
+		Sql  string `db:"sql"`
+	}{}
+
+	err := dao.DB().Select("type", "name", "COALESCE([[sql]], '') as sql").
+		From("sqlite_schema").
+		AndWhere(dbx.Not(dbx.Like("name", "sqlite_").Match(false, true))).
+		OrderBy("type ASC", "name ASC").
+		All(&rows)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	for _, row := range rows {
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", row.Type, row.Name, row.Sql)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func encodeTableRows(enc *json.Encoder, table string, query *dbx.SelectQuery) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			// store the raw bytes as text to avoid their base64 json encoding
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+
+		if err := enc.Encode(incrementalRow{Table: table, Row: row}); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func deleteMissingRows(txDao *daos.Dao, table string, ids []string) error {
+	const tempTable = "_pbIncrementalIds"
+
+	_, err := txDao.DB().NewQuery("CREATE TEMP TABLE IF NOT EXISTS {{" + tempTable + "}} ([[id]] TEXT PRIMARY KEY NOT NULL)").Execute()
+	if err != nil {
+		return err
+	}
+
+	if _, err := txDao.DB().Delete(tempTable, nil).Execute(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := txDao.DB().Insert(tempTable, dbx.Params{"id": id}).Execute(); err != nil {
+			return err
+		}
+	}
+
+	_, err = txDao.DB().Delete(table, dbx.NewExp("[[id]] NOT IN (SELECT [[id]] FROM {{"+tempTable+"}})")).Execute()
+
+	return err
+}
+
+func upsertRow(txDao *daos.Dao, table string, row map[string]any) error {
+	columns := make([]string, 0, len(row))
+	placeholders := make([]string, 0, len(row))
+	params := make(dbx.Params, len(row))
+
+	i := 0
+	for column, value := range row {
+		param := "p" + strconv.Itoa(i)
+		columns = append(columns, "[["+column+"]]")
+		placeholders = append(placeholders, "{:"+param+"}")
+		params[param] = value
+		i++
+	}
+
+	_, err := txDao.DB().NewQuery(fmt.Sprintf(
+		"INSERT OR REPLACE INTO {{%s}} (%s) VALUES (%s)",
+		table,
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "),
+	)).Bind(params).Execute()
+
+	return err
+}
+
+func jsonNumberValue(n json.Number) any {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+
+	return n.String()
+}
+
+// -------------------------------------------------------------------
+
+func zipAddJSON(zw *zip.Writer, name string, data any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(data)
+}
+
+func zipAddFile(zw *zip.Writer, name string, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+func readZipJSON(zr *zip.Reader, name string, dest any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewDecoder(f).Decode(dest)
+}
+
+func readZipJSONLines(zr *zip.Reader, name string, fn func(dec *json.Decoder) error) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+
+	for dec.More() {
+		if err := fn(dec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractZipFile(f *zip.File, dir string, relPath string) error {
+	// prevent extracting files outside of dir
+	cleanPath := path.Clean("/" + relPath)
+	dest := filepath.Join(dir, filepath.FromSlash(cleanPath))
+	if !strings.HasPrefix(dest, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return fmt.Errorf("invalid file path %q", relPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, r)
+
+	return err
+}
@@ -0,0 +1,287 @@
+package core_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+func TestIncrementalBackupRoundTrip(t *testing.T) {
+	for _, key := range []string{"", "abcdabcdabcdabcdabcdabcdabcdabcd"} {
+		t.Run("encryption key "+key, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			app.Settings().Backups.EncryptionKey = key
+
+			storageDir := filepath.Join(app.DataDir(), "storage")
+			writeTestFile(t, filepath.Join(storageDir, "base.txt"), "base")
+			writeTestFile(t, filepath.Join(storageDir, "to_delete.txt"), "delete")
+
+			if err := app.CreateBackup(context.Background(), "base.zip"); err != nil {
+				t.Fatal(err)
+			}
+
+			// first changes
+			// ---
+			collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			created := models.NewRecord(collection)
+			created.Set("title", "created")
+			saveTestRecord(t, app, created)
+
+			// swap 2 unique titles
+			test1 := findTestRecord(t, app, "llvuca81nly1qls")
+			test2 := findTestRecord(t, app, "achvryl401bhse3")
+			test1.Set("title", "tmp")
+			saveTestRecord(t, app, test1)
+			test2.Set("title", "test1")
+			saveTestRecord(t, app, test2)
+			test1.Set("title", "test2")
+			saveTestRecord(t, app, test1)
+
+			writeTestFile(t, filepath.Join(storageDir, "new.txt"), "new")
+			if err := os.Remove(filepath.Join(storageDir, "to_delete.txt")); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := app.CreateIncrementalBackup(context.Background(), "base.zip", "diff1.zip"); err != nil {
+				t.Fatal(err)
+			}
+
+			// second changes
+			// ---
+			created.Set("title", "created_updated")
+			saveTestRecord(t, app, created)
+
+			if err := app.Dao().DeleteRecord(findTestRecord(t, app, "0yxhwia2amd8gec")); err != nil {
+				t.Fatal(err)
+			}
+
+			writeTestFile(t, filepath.Join(storageDir, "base.txt"), "base_updated")
+
+			if err := app.CreateIncrementalBackup(context.Background(), "diff1.zip", "diff2.zip"); err != nil {
+				t.Fatal(err)
+			}
+
+			// changes after the last backup that must be reverted
+			// ---
+			created.Set("title", "after_backup")
+			saveTestRecord(t, app, created)
+			writeTestFile(t, filepath.Join(storageDir, "after_backup.txt"), "after")
+
+			if err := restoreWithoutRestart(t, app, "diff2.zip"); err != nil {
+				t.Fatal(err)
+			}
+
+			// verify the restored pb_data
+			// ---
+			restored := core.NewBaseApp(core.BaseAppConfig{DataDir: app.DataDir()})
+			if err := restored.Bootstrap(); err != nil {
+				t.Fatal(err)
+			}
+			defer restored.ResetBootstrapState()
+
+			expectedTitles := map[string]string{
+				created.Id:        "created_updated",
+				"llvuca81nly1qls": "test2",
+				"achvryl401bhse3": "test1",
+			}
+
+			records, err := restored.Dao().FindRecordsByExpr("demo2")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(records) != len(expectedTitles) {
+				t.Fatalf("Expected %d demo2 records, got %d", len(expectedTitles), len(records))
+			}
+
+			for _, r := range records {
+				if expected, ok := expectedTitles[r.Id]; !ok || r.GetString("title") != expected {
+					t.Fatalf("Expected record %q title %q, got %q", r.Id, expected, r.GetString("title"))
+				}
+			}
+
+			// unchanged records from the base backup
+			if _, err := restored.Dao().FindAuthRecordByEmail("users", "test@example.com"); err != nil {
+				t.Fatalf("Expected the base backup records to be restored: %v", err)
+			}
+
+			expectedFiles := map[string]string{
+				"base.txt":         "base_updated",
+				"new.txt":          "new",
+				"to_delete.txt":    "",
+				"after_backup.txt": "",
+			}
+			for name, expected := range expectedFiles {
+				content, err := os.ReadFile(filepath.Join(storageDir, name))
+				if expected == "" {
+					if err == nil {
+						t.Fatalf("Expected %q to be deleted", name)
+					}
+					continue
+				}
+
+				if string(content) != expected {
+					t.Fatalf("Expected %q content %q, got %q (%v)", name, expected, content, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIncrementalBackupChainValidation(t *testing.T) {
+	t.Run("parent without metadata", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		fsys, err := app.NewBackupsFilesystem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fsys.Close()
+
+		if err := fsys.Upload([]byte("test"), "uploaded.zip"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := app.CreateIncrementalBackup(context.Background(), "uploaded.zip", "diff.zip"); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+
+		if err := app.CreateIncrementalBackup(context.Background(), "missing.zip", "diff.zip"); err == nil {
+			t.Fatal("Expected missing parent error, got nil")
+		}
+	})
+
+	t.Run("not incremental backup", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		if err := app.CreateBackup(context.Background(), "base.zip"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := restoreWithoutRestart(t, app, "base.zip")
+		if err == nil || !strings.Contains(err.Error(), "not an incremental backup") {
+			t.Fatalf("Expected not incremental backup error, got %v", err)
+		}
+	})
+
+	t.Run("replaced parent", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		if err := app.CreateBackup(context.Background(), "base.zip"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := app.CreateIncrementalBackup(context.Background(), "base.zip", "diff1.zip"); err != nil {
+			t.Fatal(err)
+		}
+
+		// replace the chain base with a different content
+		writeTestFile(t, filepath.Join(app.DataDir(), "storage", "new.txt"), "new")
+		if err := app.CreateBackup(context.Background(), "base.zip"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := restoreWithoutRestart(t, app, "diff1.zip")
+		if err == nil || !strings.Contains(err.Error(), "checksum doesn't match") {
+			t.Fatalf("Expected parent checksum error, got %v", err)
+		}
+	})
+
+	t.Run("schema change", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		if err := app.CreateBackup(context.Background(), "base.zip"); err != nil {
+			t.Fatal(err)
+		}
+
+		collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		collection.Schema.AddField(&schema.SchemaField{Name: "new_field", Type: schema.FieldTypeText})
+		if err := app.Dao().SaveCollection(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := app.CreateIncrementalBackup(context.Background(), "base.zip", "diff1.zip"); err != nil {
+			t.Fatal(err)
+		}
+
+		err = restoreWithoutRestart(t, app, "diff1.zip")
+		if err == nil || !strings.Contains(err.Error(), "schema") {
+			t.Fatalf("Expected schema error, got %v", err)
+		}
+
+		// the pb_data must remain unchanged
+		if _, err := os.Stat(filepath.Join(app.DataDir(), "data.db")); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// -------------------------------------------------------------------
+
+// restoreWithoutRestart restores the incremental backup by preventing the app process restart.
+func restoreWithoutRestart(t *testing.T, app *tests.TestApp, name string) error {
+	var restarted bool
+
+	// registered first to skip the other app terminate handlers
+	app.OnTerminate().PreAdd(func(e *core.TerminateEvent) error {
+		if e.IsRestart {
+			restarted = true
+			return hook.StopPropagation
+		}
+		return nil
+	})
+
+	err := app.RestoreIncrementalBackup(context.Background(), name)
+
+	if err == nil && !restarted {
+		t.Fatal("Expected the app to be restarted")
+	}
+
+	return err
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findTestRecord(t *testing.T, app *tests.TestApp, id string) *models.Record {
+	record, err := app.Dao().FindRecordById("demo2", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return record
+}
+
+func saveTestRecord(t *testing.T, app *tests.TestApp, record *models.Record) {
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,7 +2,10 @@ package core_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -12,7 +15,9 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/archive"
+	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
 func TestCreateBackup(t *testing.T) {
@@ -56,8 +61,12 @@ func TestCreateBackup(t *testing.T) {
 	expectedFiles := []string{
 		`^pb_backup_` + expectedAppNamePrefix + `_\w+\.zip$`,
 		`^pb_backup_` + expectedAppNamePrefix + `_\w+\.zip.attrs$`,
+		`^pb_backup_` + expectedAppNamePrefix + `_\w+\.zip.sha256$`,
+		`^pb_backup_` + expectedAppNamePrefix + `_\w+\.zip.sha256.attrs$`,
 		"custom",
 		"custom.attrs",
+		"custom.sha256",
+		"custom.sha256.attrs",
 	}
 
 	if len(entries) != len(expectedFiles) {
@@ -70,16 +79,28 @@ func TestCreateBackup(t *testing.T) {
 			t.Fatalf("[%d] Missing backup file %q", i, entry.Name())
 		}
 
-		if strings.HasSuffix(entry.Name(), ".attrs") {
+		if strings.HasSuffix(entry.Name(), ".attrs") || strings.HasSuffix(entry.Name(), core.BackupChecksumSuffix) {
 			continue
 		}
 
 		path := filepath.Join(backupsDir, entry.Name())
 
+		if err := verifyBackupChecksum(path); err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+
 		if err := verifyBackupContent(app, path); err != nil {
 			t.Fatalf("[%d] Failed to verify backup content: %v", i, err)
 		}
 	}
+
+	// the local temp snapshots should be removed after the upload
+	tempEntries, _ := os.ReadDir(filepath.Join(app.DataDir(), core.LocalTempDirName))
+	for _, entry := range tempEntries {
+		if strings.HasPrefix(entry.Name(), "pb_backup_") {
+			t.Fatalf("Expected the temp backup file %q to be removed", entry.Name())
+		}
+	}
 }
 
 func TestRestoreBackup(t *testing.T) {
@@ -110,8 +131,166 @@ func TestRestoreBackup(t *testing.T) {
 	}
 }
 
+func TestBackupRoundTrip(t *testing.T) {
+	const testKey = "abcdabcdabcdabcdabcdabcdabcdabcd"
+
+	scenarios := []struct {
+		name          string
+		createKey     string
+		restoreKey    string
+		tamper        bool
+		expectError   bool
+		expectEncrypt bool
+	}{
+		{"unencrypted", "", "", false, false, false},
+		{"unencrypted with encryption key on restore", "", testKey, false, false, false},
+		{"encrypted", testKey, testKey, false, false, true},
+		{"encrypted with missing key on restore", testKey, "", false, true, true},
+		{"encrypted with different key on restore", testKey, "1234abcdabcdabcdabcdabcdabcdabcd", false, true, true},
+		{"tampered unencrypted", "", "", true, true, false},
+		{"tampered encrypted", testKey, testKey, true, true, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			markerPath := filepath.Join(app.DataDir(), "storage", "backup_marker.txt")
+			if err := os.WriteFile(markerPath, []byte("original"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			app.Settings().Backups.EncryptionKey = s.createKey
+
+			if err := app.CreateBackup(context.Background(), "test.zip"); err != nil {
+				t.Fatalf("Failed to create backup: %v", err)
+			}
+
+			backupPath := filepath.Join(app.DataDir(), core.LocalBackupsDirName, "test.zip")
+
+			raw, err := os.ReadFile(backupPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v := security.IsEncryptedStream(raw); v != s.expectEncrypt {
+				t.Fatalf("Expected encrypted %v, got %v", s.expectEncrypt, v)
+			}
+
+			if err := verifyBackupChecksum(backupPath); err != nil {
+				t.Fatal(err)
+			}
+
+			if s.expectEncrypt {
+				// the encrypted backup must be decryptable with the configured key
+				if err := verifyEncryptedBackupContent(app, backupPath, s.createKey); err != nil {
+					t.Fatalf("Failed to verify the encrypted backup content: %v", err)
+				}
+			} else if err := verifyBackupContent(app, backupPath); err != nil {
+				t.Fatalf("Failed to verify the backup content: %v", err)
+			}
+
+			if s.tamper {
+				raw[len(raw)/2] ^= 1
+				if err := os.WriteFile(backupPath, raw, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// change the data after the backup
+			if err := os.WriteFile(markerPath, []byte("changed"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// prevent the actual process restart
+			// (registered first to skip the other app terminate handlers)
+			var restarted bool
+			app.OnTerminate().PreAdd(func(e *core.TerminateEvent) error {
+				if e.IsRestart {
+					restarted = true
+					return hook.StopPropagation
+				}
+				return nil
+			})
+
+			app.Settings().Backups.EncryptionKey = s.restoreKey
+
+			err = app.RestoreBackup(context.Background(), "test.zip")
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if restarted == s.expectError {
+				t.Fatalf("Expected restarted %v, got %v", !s.expectError, restarted)
+			}
+
+			marker, err := os.ReadFile(markerPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedMarker := "original"
+			if s.expectError {
+				expectedMarker = "changed" // the pb_data must remain unchanged
+			}
+
+			if string(marker) != expectedMarker {
+				t.Fatalf("Expected marker %q, got %q", expectedMarker, marker)
+			}
+		})
+	}
+}
+
 // -------------------------------------------------------------------
 
+func verifyBackupChecksum(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := os.ReadFile(path + core.BackupChecksumSuffix)
+	if err != nil {
+		return fmt.Errorf("Failed to read the backup checksum file: %w", err)
+	}
+
+	hash := sha256.Sum256(raw)
+	if expected := hex.EncodeToString(hash[:]); string(checksum) != expected {
+		return fmt.Errorf("Expected checksum %q, got %q", expected, checksum)
+	}
+
+	return nil
+}
+
+func verifyEncryptedBackupContent(app core.App, path string, key string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := security.NewDecryptReader(f, key)
+	if err != nil {
+		return err
+	}
+
+	decrypted, err := os.CreateTemp("", "backup_test_zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(decrypted.Name())
+	defer decrypted.Close()
+
+	if _, err := io.Copy(decrypted, r); err != nil {
+		return err
+	}
+
+	return verifyBackupContent(app, decrypted.Name())
+}
+
 func verifyBackupContent(app core.App, path string) error {
 	dir, err := os.MkdirTemp("", "backup_test")
 	if err != nil {
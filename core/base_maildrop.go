@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/maildrop"
+)
+
+// initMaildropHooks registers the inbound IMAP mailbox poller app hooks.
+func (app *BaseApp) initMaildropHooks() {
+	var mu sync.Mutex
+	var cancel context.CancelFunc
+	var done chan struct{}
+	var current string
+	isServe := false
+
+	stop := func() {
+		if cancel == nil {
+			return
+		}
+
+		cancel()
+		<-done
+
+		cancel = nil
+		done = nil
+		current = ""
+	}
+
+	load := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		config := app.Settings().Imap
+
+		if !config.Enabled || !isServe || !app.IsBootstrapped() {
+			stop()
+			return
+		}
+
+		// restart the poller only if the config has changed
+		hash, _ := json.Marshal(config)
+		if cancel != nil && current == string(hash) {
+			return
+		}
+
+		stop()
+
+		poller := maildrop.NewPoller(maildrop.Config{
+			Host:              config.Host,
+			Port:              config.Port,
+			Username:          config.Username,
+			Password:          config.Password,
+			Tls:               config.Tls,
+			AllowInsecureAuth: config.AllowInsecureAuth,
+			Mailbox:           config.Mailbox,
+			PollInterval:      time.Duration(config.PollInterval) * time.Second,
+			Idle:              config.Idle,
+			MaxAttempts:       config.MaxAttempts,
+			FailedMailbox:     config.FailedMailbox,
+		}, func(msg *maildrop.Message) error {
+			return app.OnMailReceive().Trigger(&MailReceiveEvent{
+				App:     app,
+				Message: msg,
+			})
+		})
+
+		poller.OnError = func(err error) {
+			app.Logger().Debug(
+				"[Maildrop] Failed to process the IMAP mailbox",
+				slog.String("host", config.Host),
+				slog.String("mailbox", config.Mailbox),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan struct{})
+		current = string(hash)
+
+		go func(done chan struct{}) {
+			defer close(done)
+			poller.Run(ctx)
+		}(done)
+	}
+
+	// start on app serve
+	app.OnBeforeServe().Add(func(e *ServeEvent) error {
+		isServe = true
+		load()
+		return nil
+	})
+
+	// stop the poller on app termination
+	app.OnTerminate().Add(func(e *TerminateEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		stop()
+
+		return nil
+	})
+
+	// reload on app settings change
+	app.OnSettingsReload().Add(func(e *SettingsReloadEvent) error {
+		load()
+		return nil
+	})
+}
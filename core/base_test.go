@@ -1,11 +1,15 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/mail"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -15,11 +19,15 @@ import (
 	"github.com/pocketbase/pocketbase/migrations"
 	"github.com/pocketbase/pocketbase/migrations/logs"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/settings"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/logger"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/migrate"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 )
 
 func TestNewBaseApp(t *testing.T) {
@@ -30,6 +38,7 @@ func TestNewBaseApp(t *testing.T) {
 		DataDir:       testDataDir,
 		EncryptionEnv: "test_env",
 		IsDev:         true,
+		Version:       "v1.2.3",
 	})
 
 	if app.dataDir != testDataDir {
@@ -44,6 +53,10 @@ func TestNewBaseApp(t *testing.T) {
 		t.Fatalf("expected isDev true, got %v", app.isDev)
 	}
 
+	if app.Version() != "v1.2.3" {
+		t.Fatalf("expected version v1.2.3, got %q", app.Version())
+	}
+
 	if app.store == nil {
 		t.Fatal("expected store to be set, got nil")
 	}
@@ -223,6 +236,127 @@ func TestBaseAppNewMailClient(t *testing.T) {
 	if val, ok := client2.(*mailer.SmtpClient); !ok {
 		t.Fatalf("Expected mailer.SmtpClient instance, got %v", val)
 	}
+
+	app.Settings().SmtpTransports = map[string]settings.SmtpConfig{
+		"marketing": {Enabled: true, Host: "marketing.example.com", Port: 587},
+		"disabled":  {Enabled: false, Host: "disabled.example.com", Port: 587},
+	}
+
+	client3, ok := app.NewMailClient().(*mailer.Transports)
+	if !ok {
+		t.Fatalf("Expected mailer.Transports instance, got %v", client3)
+	}
+
+	if val, ok := client3.Default.(*mailer.SmtpClient); !ok || val.Host != app.Settings().Smtp.Host {
+		t.Fatalf("Expected the default SMTP client, got %v", client3.Default)
+	}
+
+	if val, ok := client3.Transport("marketing").(*mailer.SmtpClient); !ok || val.Host != "marketing.example.com" {
+		t.Fatalf("Expected the marketing SMTP client, got %v", client3.Transport("marketing"))
+	}
+
+	if _, ok := client3.Named["disabled"]; ok {
+		t.Fatal("Expected the disabled transport to be skipped")
+	}
+
+	if val := client3.Transport("disabled"); val != client3.Default {
+		t.Fatalf("Expected the disabled transport to fallback to the default one, got %v", val)
+	}
+
+	if val := client3.Default.(*mailer.SmtpClient); val.Dkim != nil {
+		t.Fatalf("Expected nil DKIM options without private key, got %v", val.Dkim)
+	}
+
+	app.Settings().Smtp.Dkim = settings.DkimConfig{Domain: "example.com", Selector: "pb", PrivateKey: "test"}
+
+	client4 := app.NewMailClient().(*mailer.Transports).Default.(*mailer.SmtpClient)
+	if client4.Dkim == nil || client4.Dkim.Domain != "example.com" || client4.Dkim.Selector != "pb" || client4.Dkim.PrivateKey != "test" {
+		t.Fatalf("Expected the DKIM options to be set, got %v", client4.Dkim)
+	}
+}
+
+func TestBaseAppOnSettingsReload(t *testing.T) {
+	app, cleanup, err := initTestBaseApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var reloadEvents []*SettingsReloadEvent
+	app.OnSettingsReload().Add(func(e *SettingsReloadEvent) error {
+		reloadEvents = append(reloadEvents, e)
+		return nil
+	})
+
+	// capture the client of each send without actually sending the message
+	var sendClients []mailer.Mailer
+	app.OnMailerSend().Add(func(e *MailerSendEvent) error {
+		sendClients = append(sendClients, e.MailClient)
+		return hook.StopPropagation
+	})
+
+	message := &mailer.Message{
+		From:    mail.Address{Address: "from@example.com"},
+		To:      []mail.Address{{Address: "to@example.com"}},
+		Subject: "test",
+		HTML:    "test",
+	}
+
+	// "in-flight" client created with the old settings
+	oldClient := app.NewMailClient()
+
+	newSettings, err := app.Settings().Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newSettings.Smtp.Enabled = true
+	newSettings.Smtp.Host = "smtp.example.com"
+	newSettings.Smtp.Port = 587
+
+	if err := app.Dao().SaveSettings(newSettings, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reloadEvents) != 1 {
+		t.Fatalf("Expected 1 OnSettingsReload call, got %d", len(reloadEvents))
+	}
+	if reloadEvents[0].OldSettings.Smtp.Enabled || reloadEvents[0].NewSettings.Smtp.Host != "smtp.example.com" {
+		t.Fatalf("Unexpected old/new reload event settings: %v, %v", reloadEvents[0].OldSettings.Smtp, reloadEvents[0].NewSettings.Smtp)
+	}
+	if app.Settings().Smtp.Host != "smtp.example.com" {
+		t.Fatalf("Expected the app settings to be reloaded, got smtp host %q", app.Settings().Smtp.Host)
+	}
+
+	// next send
+	if err := app.NewMailClient().Send(message); err != nil && err != hook.StopPropagation {
+		t.Fatal(err)
+	}
+
+	// in-flight send
+	if err := oldClient.Send(message); err != nil && err != hook.StopPropagation {
+		t.Fatal(err)
+	}
+
+	if len(sendClients) != 2 {
+		t.Fatalf("Expected 2 sends, got %d", len(sendClients))
+	}
+	if c, ok := sendClients[0].(*mailer.SmtpClient); !ok || c.Host != "smtp.example.com" || c.Port != 587 {
+		t.Fatalf("Expected the next send to use the new SMTP transport, got %#v", sendClients[0])
+	}
+	if _, ok := sendClients[1].(*mailer.Sendmail); !ok {
+		t.Fatalf("Expected the in-flight client to continue using the old transport, got %#v", sendClients[1])
+	}
+
+	// saving the same settings shouldn't trigger a reload
+	if err := app.Dao().SaveSettings(newSettings, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RefreshSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloadEvents) != 1 {
+		t.Fatalf("Expected no new OnSettingsReload calls, got %d", len(reloadEvents))
+	}
 }
 
 func TestBaseAppNewFilesystem(t *testing.T) {
@@ -241,7 +375,17 @@ func TestBaseAppNewFilesystem(t *testing.T) {
 		t.Fatal("Expected local filesystem instance, got nil")
 	}
 
-	// misconfigured s3
+	// misconfigured azure blob
+	app.Settings().AzureBlob.Enabled = true
+	azure, azureErr := app.NewFilesystem()
+	if azureErr == nil {
+		t.Fatal("Expected Azure Blob error, got nil")
+	}
+	if azure != nil {
+		t.Fatalf("Expected nil azure blob filesystem, got %v", azure)
+	}
+
+	// misconfigured s3 (with higher priority than azure blob)
 	app.Settings().S3.Enabled = true
 	s3, s3Err := app.NewFilesystem()
 	if s3Err == nil {
@@ -250,6 +394,32 @@ func TestBaseAppNewFilesystem(t *testing.T) {
 	if s3 != nil {
 		t.Fatalf("Expected nil s3 filesystem, got %v", s3)
 	}
+
+	// unknown registered backend (with higher priority than s3)
+	app.Settings().Storage.Backend = "missing"
+	missing, missingErr := app.NewFilesystem()
+	if missingErr == nil {
+		t.Fatal("Expected unknown backend error, got nil")
+	}
+	if missing != nil {
+		t.Fatalf("Expected nil unknown backend filesystem, got %v", missing)
+	}
+
+	// registered backend
+	customDir := filepath.Join(app.DataDir(), "custom_storage")
+	app.Settings().Storage.Backend = filesystem.BackendLocal
+	app.Settings().Storage.Options = map[string]any{"dir": customDir}
+	custom, customErr := app.NewFilesystem()
+	if customErr != nil {
+		t.Fatal(customErr)
+	}
+	defer custom.Close()
+	if err := custom.Upload([]byte("test"), "test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(customDir, "test.txt")); err != nil {
+		t.Fatalf("Expected the file to be stored in the registered backend dir, got %v", err)
+	}
 }
 
 func TestBaseAppNewBackupsFilesystem(t *testing.T) {
@@ -268,7 +438,17 @@ func TestBaseAppNewBackupsFilesystem(t *testing.T) {
 		t.Fatal("Expected local backups filesystem instance, got nil")
 	}
 
-	// misconfigured s3
+	// misconfigured azure blob
+	app.Settings().Backups.AzureBlob.Enabled = true
+	azure, azureErr := app.NewBackupsFilesystem()
+	if azureErr == nil {
+		t.Fatal("Expected Azure Blob error, got nil")
+	}
+	if azure != nil {
+		t.Fatalf("Expected nil azure blob backups filesystem, got %v", azure)
+	}
+
+	// misconfigured s3 (with higher priority than azure blob)
 	app.Settings().Backups.S3.Enabled = true
 	s3, s3Err := app.NewBackupsFilesystem()
 	if s3Err == nil {
@@ -277,6 +457,32 @@ func TestBaseAppNewBackupsFilesystem(t *testing.T) {
 	if s3 != nil {
 		t.Fatalf("Expected nil s3 backups filesystem, got %v", s3)
 	}
+
+	// unknown registered backend (with higher priority than s3)
+	app.Settings().Backups.Storage.Backend = "missing"
+	missing, missingErr := app.NewBackupsFilesystem()
+	if missingErr == nil {
+		t.Fatal("Expected unknown backend error, got nil")
+	}
+	if missing != nil {
+		t.Fatalf("Expected nil unknown backend backups filesystem, got %v", missing)
+	}
+
+	// registered backend
+	customDir := filepath.Join(app.DataDir(), "custom_storage")
+	app.Settings().Backups.Storage.Backend = filesystem.BackendLocal
+	app.Settings().Backups.Storage.Options = map[string]any{"dir": customDir}
+	custom, customErr := app.NewBackupsFilesystem()
+	if customErr != nil {
+		t.Fatal(customErr)
+	}
+	defer custom.Close()
+	if err := custom.Upload([]byte("test"), "test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(customDir, "test.txt")); err != nil {
+		t.Fatalf("Expected the file to be stored in the registered backend dir, got %v", err)
+	}
 }
 
 func TestBaseAppLoggerWrites(t *testing.T) {
@@ -426,6 +632,40 @@ func TestBaseAppLoggerWrites(t *testing.T) {
 	})
 }
 
+func TestBaseAppResetBootstrapStateFlushLogs(t *testing.T) {
+	app, cleanup, err := initTestBaseApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	app.Settings().Logs.MaxDays = 1
+
+	app.Logger().Error("flush_test")
+
+	if err := app.ResetBootstrapState(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.ResetBootstrapState()
+
+	var total int
+	err = app.LogsDao().LogQuery().
+		Select("count(*)").
+		AndWhere(dbx.HashExp{"message": "flush_test"}).
+		Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 {
+		t.Fatalf("Expected the buffered log to be written, got %d", total)
+	}
+}
+
 func TestBaseAppRefreshSettingsLoggerMinLevelEnabled(t *testing.T) {
 	app, cleanup, err := initTestBaseApp()
 	if err != nil {
@@ -592,6 +832,60 @@ func TestBaseAppLoggerLevelDevPrint(t *testing.T) {
 	}
 }
 
+func TestBaseAppCustomLogHandlersAndSampling(t *testing.T) {
+	testDataDir, err := os.MkdirTemp("", "test_base_app_log_handlers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDataDir)
+
+	var buf bytes.Buffer
+
+	app := NewBaseApp(BaseAppConfig{
+		DataDir:               testDataDir,
+		LogHandlers:           []slog.Handler{slog.NewJSONHandler(&buf, nil)},
+		RequestLogsSampleRate: 0.25,
+	})
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.ResetBootstrapState()
+
+	if app.logsBatchHandler == nil {
+		t.Fatal("Expected the db logs handler to be initialized")
+	}
+
+	for i := 0; i < 100; i++ {
+		app.Logger().Info("success request", "type", "request", "status", 200)
+
+		if i%10 == 0 {
+			app.Logger().Error("failed request", "type", "request", "status", 400)
+			app.Logger().Info("other")
+		}
+	}
+
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		data := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			t.Fatalf("Failed to unmarshal log line %q: %v", line, err)
+		}
+		counts[cast.ToString(data["msg"])]++
+	}
+
+	expected := map[string]int{
+		"success request": 25,
+		"failed request":  10,
+		"other":           10,
+	}
+
+	for msg, total := range expected {
+		if counts[msg] != total {
+			t.Errorf("Expected %d %q logs, got %d", total, msg, counts[msg])
+		}
+	}
+}
+
 // -------------------------------------------------------------------
 
 // note: make sure to call `defer cleanup()` when the app is no longer needed.
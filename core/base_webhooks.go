@@ -0,0 +1,345 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/pocketbase/tools/webhooks"
+)
+
+// webhookPayload defines the json body of a single webhook delivery.
+type webhookPayload struct {
+	Event      string            `json:"event"`
+	Collection webhookCollection `json:"collection"`
+	Record     *models.Record    `json:"record"`
+	Timestamp  types.DateTime    `json:"timestamp"`
+}
+
+type webhookCollection struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+const (
+	webhooksWorkers   = 4
+	webhooksQueueSize = 1000
+)
+
+// initWebhooksHooks registers the app hooks that dispatch the
+// record create/update/delete events to the active webhooks.
+//
+// The deliveries are send asynchronously after the model after hooks
+// (aka. after the db transaction commit) through a bounded queue and
+// the ones that fail permanently (or that couldn't be queued) are
+// stored as [models.WebhookFailure] dead-letter entries.
+func (app *BaseApp) initWebhooksHooks() {
+	var mu sync.Mutex
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cache := &webhooksCache{}
+	queue := newWebhooksQueue(webhooksWorkers, webhooksQueueSize)
+
+	sender := webhooks.NewSender()
+	sender.OnRetry = func(msg *webhooks.Message, attempt int, delay time.Duration, err error) {
+		app.Logger().Debug(
+			"[Webhooks] Delivery attempt failed, retrying",
+			slog.String("url", msg.Url),
+			slog.String("event", msg.Event),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	dispatch := func(e *ModelEvent, event string) error {
+		if _, ok := e.Model.(*models.Webhook); ok {
+			cache.invalidate()
+			return nil
+		}
+
+		record, ok := e.Model.(*models.Record)
+		if !ok || record.Collection() == nil {
+			return nil
+		}
+
+		hooks, err := cache.find(app.Dao(), record.Collection().Id, event)
+		if err != nil {
+			app.Logger().Debug(
+				"[Webhooks] Failed to load the collection webhooks",
+				slog.String("collectionId", record.Collection().Id),
+				slog.String("error", err.Error()),
+			)
+			return nil
+		}
+
+		if len(hooks) == 0 {
+			return nil
+		}
+
+		payload, err := json.Marshal(webhookPayload{
+			Event: event,
+			Collection: webhookCollection{
+				Id:   record.Collection().Id,
+				Name: record.Collection().Name,
+			},
+			Record:    record,
+			Timestamp: types.NowDateTime(),
+		})
+		if err != nil {
+			app.Logger().Debug(
+				"[Webhooks] Failed to serialize the webhook payload",
+				slog.String("recordId", record.Id),
+				slog.String("error", err.Error()),
+			)
+			return nil
+		}
+
+		mu.Lock()
+		deliveryCtx := ctx
+		mu.Unlock()
+
+		for _, hook := range hooks {
+			queued := queue.enqueue(func() {
+				app.deliverWebhook(deliveryCtx, sender, hook, record.Id, event, payload)
+			})
+
+			if !queued {
+				app.Logger().Error(
+					"[Webhooks] The deliveries queue is full",
+					slog.String("webhookId", hook.Id),
+					slog.String("event", event),
+					slog.String("recordId", record.Id),
+				)
+
+				app.saveWebhookFailure(&models.WebhookFailure{
+					WebhookId:    hook.Id,
+					CollectionId: hook.CollectionId,
+					RecordId:     record.Id,
+					Event:        event,
+					Url:          hook.Url,
+					Payload:      payload,
+					Error:        "the webhook deliveries queue is full",
+				})
+			}
+		}
+
+		return nil
+	}
+
+	app.OnModelAfterCreate().Add(func(e *ModelEvent) error {
+		return dispatch(e, models.WebhookEventCreate)
+	})
+
+	app.OnModelAfterUpdate().Add(func(e *ModelEvent) error {
+		return dispatch(e, models.WebhookEventUpdate)
+	})
+
+	app.OnModelAfterDelete().Add(func(e *ModelEvent) error {
+		return dispatch(e, models.WebhookEventDelete)
+	})
+
+	// abort the pending retries and wait for the queued deliveries
+	// (they fail fast with the canceled context and are stored as failures)
+	app.OnTerminate().Add(func(e *TerminateEvent) error {
+		mu.Lock()
+		cancel()
+		// reinit the context in case of a restart
+		ctx, cancel = context.WithCancel(context.Background())
+		mu.Unlock()
+
+		queue.wait()
+
+		// reload the webhooks on restart
+		cache.invalidate()
+
+		return nil
+	})
+}
+
+// deliverWebhook sends the provided payload to the webhook url and
+// stores a dead-letter entry in case the delivery fails permanently.
+func (app *BaseApp) deliverWebhook(
+	ctx context.Context,
+	sender *webhooks.Sender,
+	hook *models.Webhook,
+	recordId string,
+	event string,
+	payload []byte,
+) {
+	attempts, err := sender.Send(ctx, &webhooks.Message{
+		Url:     hook.Url,
+		Event:   event,
+		Secret:  hook.Secret,
+		Payload: payload,
+		Headers: map[string]string{"X-Webhook-Id": hook.Id},
+	})
+
+	if err == nil {
+		app.Logger().Info(
+			"[Webhooks] Delivered",
+			slog.String("webhookId", hook.Id),
+			slog.String("url", hook.Url),
+			slog.String("event", event),
+			slog.String("recordId", recordId),
+			slog.Int("attempts", attempts),
+		)
+		return
+	}
+
+	app.Logger().Error(
+		"[Webhooks] Delivery failed",
+		slog.String("webhookId", hook.Id),
+		slog.String("url", hook.Url),
+		slog.String("event", event),
+		slog.String("recordId", recordId),
+		slog.Int("attempts", attempts),
+		slog.String("error", err.Error()),
+	)
+
+	failure := &models.WebhookFailure{
+		WebhookId:    hook.Id,
+		CollectionId: hook.CollectionId,
+		RecordId:     recordId,
+		Event:        event,
+		Url:          hook.Url,
+		Payload:      payload,
+		Attempts:     attempts,
+		Error:        err.Error(),
+	}
+
+	var deliveryErr *webhooks.DeliveryError
+	if errors.As(err, &deliveryErr) {
+		failure.StatusCode = deliveryErr.StatusCode
+	}
+
+	app.saveWebhookFailure(failure)
+}
+
+// saveWebhookFailure stores the provided webhook dead-letter entry.
+func (app *BaseApp) saveWebhookFailure(failure *models.WebhookFailure) {
+	if err := app.Dao().SaveWebhookFailure(failure); err != nil {
+		app.Logger().Debug(
+			"[Webhooks] Failed to store the webhook failure",
+			slog.String("webhookId", failure.WebhookId),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// -------------------------------------------------------------------
+
+// webhooksCache holds in memory the active webhooks to avoid
+// querying the db on every record change.
+//
+// It is invalidated on every webhook create, update and delete.
+type webhooksCache struct {
+	mux        sync.RWMutex
+	hooks      []*models.Webhook
+	loaded     bool
+	generation uint64
+}
+
+// find returns the cached active webhooks of the specified
+// collection that are subscribed for the provided event.
+//
+// The active webhooks are (re)loaded on first call or after invalidation.
+func (c *webhooksCache) find(dao *daos.Dao, collectionId string, event string) ([]*models.Webhook, error) {
+	c.mux.RLock()
+	hooks, loaded, generation := c.hooks, c.loaded, c.generation
+	c.mux.RUnlock()
+
+	if !loaded {
+		hooks = []*models.Webhook{}
+
+		err := dao.WebhookQuery().
+			AndWhere(dbx.HashExp{"active": true}).
+			OrderBy("created ASC", "rowid ASC").
+			All(&hooks)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mux.Lock()
+		// store only if there wasn't a change while loading
+		if c.generation == generation {
+			c.hooks = hooks
+			c.loaded = true
+		}
+		c.mux.Unlock()
+	}
+
+	result := []*models.Webhook{}
+	for _, hook := range hooks {
+		if hook.CollectionId == collectionId && hook.HasEvent(event) {
+			result = append(result, hook)
+		}
+	}
+
+	return result, nil
+}
+
+// invalidate clears the cached webhooks.
+func (c *webhooksCache) invalidate() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.hooks = nil
+	c.loaded = false
+	c.generation++
+}
+
+// -------------------------------------------------------------------
+
+// webhooksQueue is a bounded webhook deliveries queue
+// processed by a fixed number of workers.
+type webhooksQueue struct {
+	jobs    chan func()
+	pending sync.WaitGroup
+}
+
+// newWebhooksQueue creates a new webhooks queue and starts its workers.
+func newWebhooksQueue(workers int, size int) *webhooksQueue {
+	q := &webhooksQueue{
+		jobs: make(chan func(), size),
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range q.jobs {
+				job()
+				q.pending.Done()
+			}
+		}()
+	}
+
+	return q
+}
+
+// enqueue schedules the provided job for execution.
+//
+// Returns false if the queue is full.
+func (q *webhooksQueue) enqueue(job func()) bool {
+	q.pending.Add(1)
+
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		q.pending.Done()
+		return false
+	}
+}
+
+// wait blocks until all queued jobs are processed.
+func (q *webhooksQueue) wait() {
+	q.pending.Wait()
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestWebhooksQueue(t *testing.T) {
+	t.Parallel()
+
+	// no workers so that the jobs remain queued
+	q := newWebhooksQueue(0, 2)
+
+	var calls atomic.Int32
+	job := func() { calls.Add(1) }
+
+	if !q.enqueue(job) || !q.enqueue(job) {
+		t.Fatal("Expected the first 2 jobs to be queued")
+	}
+
+	if q.enqueue(job) {
+		t.Fatal("Expected the job to be rejected when the queue is full")
+	}
+
+	// process the queued jobs
+	go func() {
+		for job := range q.jobs {
+			job()
+			q.pending.Done()
+		}
+	}()
+
+	q.wait()
+
+	if v := calls.Load(); v != 2 {
+		t.Fatalf("Expected 2 processed jobs, got %d", v)
+	}
+}
@@ -0,0 +1,271 @@
+package core_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/webhooks"
+)
+
+func TestWebhooksDelivery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+
+	deliveries := make(chan delivery, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{r.Header.Clone(), body}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := &models.Webhook{
+		Name:         "test",
+		CollectionId: "sz5l5z67tg7gku0", // demo2
+		Events:       []string{models.WebhookEventCreate, models.WebhookEventDelete},
+		Url:          server.URL,
+		Secret:       "test_secret",
+		Active:       true,
+	}
+	if err := app.Dao().SaveWebhook(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	// inactive webhook
+	inactiveWebhook := &models.Webhook{
+		Name:         "test_inactive",
+		CollectionId: "sz5l5z67tg7gku0", // demo2
+		Events:       []string{models.WebhookEventCreate},
+		Url:          server.URL,
+		Secret:       "test_secret",
+		Active:       false,
+	}
+	if err := app.Dao().SaveWebhook(inactiveWebhook); err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("title", "webhook_test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// update (not subscribed)
+	record.Set("title", "webhook_test_update")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expectedEvent := range []string{models.WebhookEventCreate, models.WebhookEventDelete} {
+		var d delivery
+
+		select {
+		case d = <-deliveries:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %q delivery", expectedEvent)
+		}
+
+		timestamp := d.header.Get(webhooks.TimestampHeader)
+		if !webhooks.Verify("test_secret", timestamp, d.body, d.header.Get(webhooks.SignatureHeader), webhooks.DefaultTolerance) {
+			t.Fatalf("[%s] Invalid signature %q", expectedEvent, d.header.Get(webhooks.SignatureHeader))
+		}
+
+		if d.header.Get("X-Webhook-Id") != webhook.Id {
+			t.Fatalf("[%s] Expected X-Webhook-Id %q, got %q", expectedEvent, webhook.Id, d.header.Get("X-Webhook-Id"))
+		}
+
+		payload := struct {
+			Event      string         `json:"event"`
+			Collection map[string]any `json:"collection"`
+			Record     map[string]any `json:"record"`
+		}{}
+		if err := json.Unmarshal(d.body, &payload); err != nil {
+			t.Fatal(err)
+		}
+
+		// the deliveries are async so the order is not guaranteed
+		if payload.Event != models.WebhookEventCreate && payload.Event != models.WebhookEventDelete {
+			t.Fatalf("Unexpected payload event %q", payload.Event)
+		}
+
+		if payload.Event != d.header.Get(webhooks.EventHeader) {
+			t.Fatalf("Expected the %s header to match the payload event %q, got %q", webhooks.EventHeader, payload.Event, d.header.Get(webhooks.EventHeader))
+		}
+
+		if payload.Collection["name"] != "demo2" || payload.Record["id"] != record.Id {
+			t.Fatalf("Unexpected payload %s", d.body)
+		}
+	}
+
+	select {
+	case d := <-deliveries:
+		t.Fatalf("Expected no more deliveries, got %s", d.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhooksDeliveryFailure(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// non-retryable error
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid"))
+	}))
+	defer server.Close()
+
+	webhook := &models.Webhook{
+		Name:         "test",
+		CollectionId: "sz5l5z67tg7gku0", // demo2
+		Events:       []string{models.WebhookEventCreate},
+		Url:          server.URL,
+		Secret:       "test_secret",
+		Active:       true,
+	}
+	if err := app.Dao().SaveWebhook(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("title", "webhook_test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	var failures []*models.WebhookFailure
+	for i := 0; i < 50; i++ {
+		failures, err = app.Dao().FindWebhookFailures(webhook.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(failures) > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if len(failures) != 1 {
+		t.Fatalf("Expected 1 dead-letter failure, got %d", len(failures))
+	}
+
+	failure := failures[0]
+
+	if failure.RecordId != record.Id ||
+		failure.CollectionId != collection.Id ||
+		failure.Event != models.WebhookEventCreate ||
+		failure.Url != server.URL ||
+		failure.StatusCode != http.StatusBadRequest ||
+		failure.Attempts != 1 {
+		t.Fatalf("Unexpected failure %v", failure)
+	}
+
+	payload := map[string]any{}
+	if err := json.Unmarshal(failure.Payload, &payload); err != nil || payload["event"] != models.WebhookEventCreate {
+		t.Fatalf("Expected the failed delivery payload to be stored, got %s", failure.Payload)
+	}
+}
+
+func TestWebhooksCacheInvalidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	deliveries := make(chan string, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries <- r.Header.Get(webhooks.EventHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("title", "webhook_cache_test")
+
+	// load the (empty) webhooks cache
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	expectDelivery := func(expected bool) {
+		t.Helper()
+
+		select {
+		case event := <-deliveries:
+			if !expected {
+				t.Fatalf("Expected no delivery, got %q", event)
+			}
+		case <-time.After(200 * time.Millisecond):
+			if expected {
+				t.Fatal("Expected a new delivery")
+			}
+		}
+	}
+
+	expectDelivery(false)
+
+	// new webhook
+	webhook := &models.Webhook{
+		Name:         "test",
+		CollectionId: collection.Id,
+		Events:       []string{models.WebhookEventUpdate},
+		Url:          server.URL,
+		Secret:       "test_secret",
+		Active:       true,
+	}
+	if err := app.Dao().SaveWebhook(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	expectDelivery(true)
+
+	// deactivated webhook
+	webhook.Active = false
+	if err := app.Dao().SaveWebhook(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	expectDelivery(false)
+}
@@ -0,0 +1,429 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// CollectionsSchemaVersion is the current version of the
+// exported collections schema format (see [ExportCollectionsSchema]).
+const CollectionsSchemaVersion = 1
+
+// The [CollectionSchemaDiff] actions.
+const (
+	CollectionSchemaDiffCreate = "create"
+	CollectionSchemaDiffUpdate = "update"
+	CollectionSchemaDiffDelete = "delete"
+)
+
+// CollectionSchemaDiff describes the changes of a single collection
+// between the current and an imported collections schema.
+type CollectionSchemaDiff struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+
+	AddedFields    []string `json:"addedFields,omitempty"`
+	RemovedFields  []string `json:"removedFields,omitempty"`
+	ModifiedFields []string `json:"modifiedFields,omitempty"`
+	AddedIndexes   []string `json:"addedIndexes,omitempty"`
+	RemovedIndexes []string `json:"removedIndexes,omitempty"`
+
+	// ModifiedProps is a list with the other changed
+	// collection properties (eg. "name", "listRule", "options").
+	ModifiedProps []string `json:"modifiedProps,omitempty"`
+}
+
+// exportedCollectionsSchema defines the exported collections schema file structure.
+type exportedCollectionsSchema struct {
+	Version     int               `json:"version"`
+	Collections []json.RawMessage `json:"collections"`
+}
+
+// ExportCollectionsSchema exports the specified collections (or all
+// if none is provided) as versioned and deterministic JSON, aka.:
+//   - the collections are sorted by their name
+//   - the collection indexes are sorted alphabetically
+//   - the fields keep their schema order
+//   - the object keys are sorted alphabetically
+//   - the environment specific created/updated dates are omitted
+//   - the secret collection options are omitted (see [IsSecretCollectionOption])
+//
+// The result could be imported with [ParseCollectionsSchema] and [forms.CollectionsSchemaImport].
+func ExportCollectionsSchema(app App, namesOrIds ...string) ([]byte, error) {
+	collections := []*models.Collection{}
+
+	if len(namesOrIds) == 0 {
+		if err := app.Dao().CollectionQuery().All(&collections); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, nameOrId := range namesOrIds {
+			collection, err := app.Dao().FindCollectionByNameOrId(nameOrId)
+			if err != nil {
+				return nil, fmt.Errorf("missing collection %q", nameOrId)
+			}
+			collections = append(collections, collection)
+		}
+	}
+
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Name < collections[j].Name
+	})
+
+	result := exportedCollectionsSchema{
+		Version:     CollectionsSchemaVersion,
+		Collections: make([]json.RawMessage, 0, len(collections)),
+	}
+
+	for _, collection := range collections {
+		raw, err := exportCollection(collection)
+		if err != nil {
+			return nil, err
+		}
+		result.Collections = append(result.Collections, raw)
+	}
+
+	return json.MarshalIndent(result, "", "  ")
+}
+
+// exportCollection serializes the provided collection
+// as json object with sorted keys and indexes.
+func exportCollection(collection *models.Collection) (json.RawMessage, error) {
+	raw, err := json.Marshal(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	// unmarshal as map to sort the object keys on marshal
+	data := map[string]any{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	delete(data, schema.FieldNameCreated)
+	delete(data, schema.FieldNameUpdated)
+
+	if options, ok := data["options"].(map[string]any); ok {
+		for key := range options {
+			if IsSecretCollectionOption(key) {
+				delete(options, key)
+			}
+		}
+	}
+
+	indexes := append([]string{}, collection.Indexes...)
+	sort.Strings(indexes)
+	data["indexes"] = indexes
+
+	return json.Marshal(data)
+}
+
+// IsSecretCollectionOption reports whether the provided collection
+// options key holds a secret value (eg. "webhookSecret") that should
+// not be part of the exported collections schema.
+func IsSecretCollectionOption(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), "secret")
+}
+
+// ParseCollectionsSchema parses the provided collections schema data
+// exported with [ExportCollectionsSchema].
+//
+// For convenience a plain json array of collections
+// (eg. from the Dashboard export) is also accepted.
+func ParseCollectionsSchema(data []byte) ([]*models.Collection, error) {
+	collections := []*models.Collection{}
+
+	trimmed := strings.TrimSpace(string(data))
+
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &collections); err != nil {
+			return nil, err
+		}
+		return collections, nil
+	}
+
+	exported := struct {
+		Version     int                  `json:"version"`
+		Collections []*models.Collection `json:"collections"`
+	}{}
+
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return nil, err
+	}
+
+	if exported.Version <= 0 || exported.Version > CollectionsSchemaVersion {
+		return nil, fmt.Errorf("unsupported collections schema version %d", exported.Version)
+	}
+
+	if len(exported.Collections) == 0 {
+		return nil, errors.New("no collections to import")
+	}
+
+	return exported.Collections, nil
+}
+
+// DiffCollectionsSchema compares the imported collections with the
+// existing ones and returns the changes sorted by the collections name.
+//
+// The imported collections are matched by their id or, if the id is
+// not set, by their name. The imported collections without id are
+// updated in place with the id of the matched existing collection (and
+// their unknown fields with the id of the existing field with the same
+// name). The omitted secret options
+// (see [IsSecretCollectionOption]) of the matched collections are also
+// restored from the existing ones.
+//
+// If deleteMissing is set, the existing collections that are not
+// present in the imported list are reported for deletion.
+//
+// Unchanged collections are not included in the result.
+func DiffCollectionsSchema(dao *daos.Dao, imported []*models.Collection, deleteMissing bool) ([]*CollectionSchemaDiff, error) {
+	existingCollections := []*models.Collection{}
+	if err := dao.CollectionQuery().All(&existingCollections); err != nil {
+		return nil, err
+	}
+
+	mappedExisting := make(map[string]*models.Collection, len(existingCollections))
+	mappedExistingByName := make(map[string]*models.Collection, len(existingCollections))
+	for _, existing := range existingCollections {
+		mappedExisting[existing.Id] = existing
+		mappedExistingByName[strings.ToLower(existing.Name)] = existing
+	}
+
+	for _, collection := range imported {
+		if collection.Id == "" {
+			if existing := mappedExistingByName[strings.ToLower(collection.Name)]; existing != nil {
+				resolveImportedIds(existing, collection)
+			}
+		}
+
+		if existing := mappedExisting[collection.Id]; existing != nil {
+			restoreSecretOptions(existing, collection)
+		}
+	}
+
+	result := []*CollectionSchemaDiff{}
+
+	mappedImported := make(map[string]struct{}, len(imported))
+
+	for _, collection := range imported {
+		if collection.Id != "" {
+			mappedImported[collection.Id] = struct{}{}
+		}
+
+		existing := mappedExisting[collection.Id]
+		if collection.Id == "" || existing == nil {
+			diff := &CollectionSchemaDiff{
+				Id:     collection.Id,
+				Name:   collection.Name,
+				Action: CollectionSchemaDiffCreate,
+			}
+			for _, f := range collection.Schema.Fields() {
+				diff.AddedFields = append(diff.AddedFields, f.Name)
+			}
+			diff.AddedIndexes = sortedStrings(collection.Indexes)
+			result = append(result, diff)
+			continue
+		}
+
+		diff, err := diffCollection(existing, collection)
+		if err != nil {
+			return nil, err
+		}
+
+		if diff != nil {
+			result = append(result, diff)
+		}
+	}
+
+	if deleteMissing {
+		for _, existing := range existingCollections {
+			if _, ok := mappedImported[existing.Id]; ok {
+				continue
+			}
+
+			result = append(result, &CollectionSchemaDiff{
+				Id:     existing.Id,
+				Name:   existing.Name,
+				Action: CollectionSchemaDiffDelete,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// diffCollection returns the changes between the old and new
+// collection states (or nil if there are no changes).
+func diffCollection(oldCollection, newCollection *models.Collection) (*CollectionSchemaDiff, error) {
+	diff := &CollectionSchemaDiff{
+		Id:     newCollection.Id,
+		Name:   newCollection.Name,
+		Action: CollectionSchemaDiffUpdate,
+	}
+
+	// fields
+	for _, newField := range newCollection.Schema.Fields() {
+		oldField := oldCollection.Schema.GetFieldById(newField.Id)
+		if oldField == nil {
+			diff.AddedFields = append(diff.AddedFields, newField.Name)
+			continue
+		}
+
+		equal, err := jsonEqual(oldField, newField)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			diff.ModifiedFields = append(diff.ModifiedFields, newField.Name)
+		}
+	}
+	for _, oldField := range oldCollection.Schema.Fields() {
+		if newCollection.Schema.GetFieldById(oldField.Id) == nil {
+			diff.RemovedFields = append(diff.RemovedFields, oldField.Name)
+		}
+	}
+
+	// indexes
+	oldIndexes := make(map[string]struct{}, len(oldCollection.Indexes))
+	for _, idx := range oldCollection.Indexes {
+		oldIndexes[idx] = struct{}{}
+	}
+	newIndexes := make(map[string]struct{}, len(newCollection.Indexes))
+	for _, idx := range newCollection.Indexes {
+		newIndexes[idx] = struct{}{}
+		if _, ok := oldIndexes[idx]; !ok {
+			diff.AddedIndexes = append(diff.AddedIndexes, idx)
+		}
+	}
+	for _, idx := range oldCollection.Indexes {
+		if _, ok := newIndexes[idx]; !ok {
+			diff.RemovedIndexes = append(diff.RemovedIndexes, idx)
+		}
+	}
+	sort.Strings(diff.AddedIndexes)
+	sort.Strings(diff.RemovedIndexes)
+
+	// other props
+	props := []struct {
+		name     string
+		old, new any
+	}{
+		{"name", oldCollection.Name, newCollection.Name},
+		{"type", oldCollection.Type, newCollection.Type},
+		{"system", oldCollection.System, newCollection.System},
+		{"listRule", oldCollection.ListRule, newCollection.ListRule},
+		{"viewRule", oldCollection.ViewRule, newCollection.ViewRule},
+		{"createRule", oldCollection.CreateRule, newCollection.CreateRule},
+		{"updateRule", oldCollection.UpdateRule, newCollection.UpdateRule},
+		{"deleteRule", oldCollection.DeleteRule, newCollection.DeleteRule},
+		{"options", oldCollection.Options, newCollection.Options},
+	}
+	for _, p := range props {
+		equal, err := jsonEqual(p.old, p.new)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			diff.ModifiedProps = append(diff.ModifiedProps, p.name)
+		}
+	}
+
+	if len(diff.AddedFields) == 0 &&
+		len(diff.RemovedFields) == 0 &&
+		len(diff.ModifiedFields) == 0 &&
+		len(diff.AddedIndexes) == 0 &&
+		len(diff.RemovedIndexes) == 0 &&
+		len(diff.ModifiedProps) == 0 {
+		return nil, nil // no changes
+	}
+
+	return diff, nil
+}
+
+// resolveImportedIds sets the id of the imported collection and
+// the ids of its unknown fields matching the existing ones by name.
+//
+// Note that the fields without id are assigned with a random one on
+// unmarshal, aka. the unknown fields ids are not compared with "".
+func resolveImportedIds(existing, imported *models.Collection) {
+	imported.Id = existing.Id
+
+	for _, field := range imported.Schema.Fields() {
+		if existing.Schema.GetFieldById(field.Id) != nil {
+			continue
+		}
+
+		if existingField := existing.Schema.GetFieldByName(field.Name); existingField != nil {
+			field.Id = existingField.Id
+		}
+	}
+}
+
+// restoreSecretOptions copies the existing collection
+// secret options that are missing in the imported one.
+func restoreSecretOptions(existing, imported *models.Collection) {
+	for key, value := range existing.Options {
+		if !IsSecretCollectionOption(key) {
+			continue
+		}
+
+		if imported.Options == nil {
+			imported.Options = types.JsonMap{}
+		}
+
+		if _, ok := imported.Options[key]; !ok {
+			imported.Options[key] = value
+		}
+	}
+}
+
+func jsonEqual(a, b any) (bool, error) {
+	rawA, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+
+	rawB, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+
+	// normalize the object keys order
+	var normA, normB any
+	if err := json.Unmarshal(rawA, &normA); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(rawB, &normB); err != nil {
+		return false, err
+	}
+
+	rawA, _ = json.Marshal(normA)
+	rawB, _ = json.Marshal(normB)
+
+	return string(rawA) == string(rawB), nil
+}
+
+func sortedStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := append([]string{}, values...)
+	sort.Strings(result)
+
+	return result
+}
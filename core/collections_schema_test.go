@@ -0,0 +1,266 @@
+package core_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestExportCollectionsSchema(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// missing collection
+	if _, err := core.ExportCollectionsSchema(app, "missing"); err == nil {
+		t.Fatal("Expected error for missing collection")
+	}
+
+	first, err := core.ExportCollectionsSchema(app, "demo2", "demo1", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := core.ExportCollectionsSchema(app, "users", "demo1", "demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(first) != string(second) {
+		t.Fatalf("Expected deterministic export, got\n%s\nvs\n%s", first, second)
+	}
+
+	exported := struct {
+		Version     int              `json:"version"`
+		Collections []map[string]any `json:"collections"`
+	}{}
+	if err := json.Unmarshal(first, &exported); err != nil {
+		t.Fatal(err)
+	}
+
+	if exported.Version != core.CollectionsSchemaVersion {
+		t.Fatalf("Expected version %d, got %d", core.CollectionsSchemaVersion, exported.Version)
+	}
+
+	expectedNames := []string{"demo1", "demo2", "users"}
+	if len(exported.Collections) != len(expectedNames) {
+		t.Fatalf("Expected %d collections, got %d", len(expectedNames), len(exported.Collections))
+	}
+	for i, name := range expectedNames {
+		c := exported.Collections[i]
+		if c["name"] != name {
+			t.Errorf("Expected collection %d to be %q, got %v", i, name, c["name"])
+		}
+		if _, ok := c["created"]; ok {
+			t.Errorf("Expected %q created date to be omitted", name)
+		}
+		if _, ok := c["updated"]; ok {
+			t.Errorf("Expected %q updated date to be omitted", name)
+		}
+	}
+
+	// all collections
+	all, err := core.ExportCollectionsSchema(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := core.ParseCollectionsSchema(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := app.Dao().CollectionQuery().Select("count(*)").Row(&count); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != count {
+		t.Fatalf("Expected %d parsed collections, got %d", count, len(parsed))
+	}
+}
+
+func TestParseCollectionsSchema(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name        string
+		data        string
+		expectError bool
+		expectNames []string
+	}{
+		{"invalid json", `{`, true, nil},
+		{"missing version", `{"collections":[{"name":"a"}]}`, true, nil},
+		{"unsupported version", `{"version":999,"collections":[{"name":"a"}]}`, true, nil},
+		{"no collections", `{"version":1,"collections":[]}`, true, nil},
+		{"versioned", `{"version":1,"collections":[{"name":"a"},{"name":"b"}]}`, false, []string{"a", "b"}},
+		{"plain array", ` [{"name":"a"}]`, false, []string{"a"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collections, err := core.ParseCollectionsSchema([]byte(s.data))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if len(collections) != len(s.expectNames) {
+				t.Fatalf("Expected %d collections, got %d", len(s.expectNames), len(collections))
+			}
+			for i, name := range s.expectNames {
+				if collections[i].Name != name {
+					t.Errorf("Expected collection %d name %q, got %q", i, name, collections[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestIsSecretCollectionOption(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		key      string
+		expected bool
+	}{
+		{"", false},
+		{"allowShare", false},
+		{"secretKey", false},
+		{"secret", true},
+		{"webhookSecret", true},
+		{"WEBHOOK_SECRET", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.key, func(t *testing.T) {
+			if v := core.IsSecretCollectionOption(s.key); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestDiffCollectionsSchema(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "schema_diff",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "count", Type: schema.FieldTypeNumber},
+		),
+		Indexes: types.JsonArray[string]{"CREATE INDEX idx_schema_diff_title ON schema_diff (title)"},
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := core.ExportCollectionsSchema(app, collection.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unchanged
+	imported, err := core.ParseCollectionsSchema(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := core.DiffCollectionsSchema(app.Dao(), imported, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Expected no changes, got %v", changes)
+	}
+
+	// unchanged without ids (eg. from another environment)
+	imported, _ = core.ParseCollectionsSchema(data)
+	imported[0].Id = ""
+	for _, f := range imported[0].Schema.Fields() {
+		f.Id = "other_" + f.Name
+	}
+	changes, err = core.DiffCollectionsSchema(app.Dao(), imported, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Expected no changes when matched by name, got %v", changes)
+	}
+	if imported[0].Id != collection.Id {
+		t.Fatalf("Expected the imported collection id to be resolved to %q, got %q", collection.Id, imported[0].Id)
+	}
+	if f := imported[0].Schema.GetFieldByName("title"); f.Id != collection.Schema.GetFieldByName("title").Id {
+		t.Fatalf("Expected the title field id to be resolved by name, got %q", f.Id)
+	}
+
+	// modified
+	imported, _ = core.ParseCollectionsSchema(data)
+	modified := imported[0]
+	modified.Schema.RemoveField(modified.Schema.GetFieldByName("count").Id)
+	modified.Schema.GetFieldByName("title").Required = true
+	modified.Schema.AddField(&schema.SchemaField{Name: "body", Type: schema.FieldTypeEditor})
+	modified.Indexes = types.JsonArray[string]{"CREATE INDEX idx_schema_diff_body ON schema_diff (body)"}
+	modified.ListRule = types.Pointer("")
+
+	changes, err = core.DiffCollectionsSchema(app.Dao(), imported, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := json.Marshal(changes)
+	expected := `[{"id":"` + collection.Id + `","name":"schema_diff","action":"update",` +
+		`"addedFields":["body"],"removedFields":["count"],"modifiedFields":["title"],` +
+		`"addedIndexes":["CREATE INDEX idx_schema_diff_body ON schema_diff (body)"],` +
+		`"removedIndexes":["CREATE INDEX idx_schema_diff_title ON schema_diff (title)"],` +
+		`"modifiedProps":["listRule"]}]`
+	if string(raw) != expected {
+		t.Fatalf("Expected changes\n%s\ngot\n%s", expected, raw)
+	}
+
+	// new collection and delete missing
+	imported, _ = core.ParseCollectionsSchema(data)
+	imported = append(imported, &models.Collection{
+		Name:   "schema_new",
+		Type:   models.CollectionTypeBase,
+		Schema: schema.NewSchema(&schema.SchemaField{Name: "a", Type: schema.FieldTypeText}),
+	})
+
+	changes, err = core.DiffCollectionsSchema(app.Dao(), imported, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var creates, deletes int
+	for _, change := range changes {
+		switch change.Action {
+		case core.CollectionSchemaDiffCreate:
+			creates++
+			if change.Name != "schema_new" || strings.Join(change.AddedFields, ",") != "a" {
+				t.Errorf("Unexpected create change %v", change)
+			}
+		case core.CollectionSchemaDiffDelete:
+			deletes++
+			if change.Name == collection.Name {
+				t.Errorf("Expected %q not to be deleted", collection.Name)
+			}
+		default:
+			t.Errorf("Unexpected change %v", change)
+		}
+	}
+	if creates != 1 {
+		t.Errorf("Expected 1 create change, got %d", creates)
+	}
+	if deletes == 0 {
+		t.Errorf("Expected the missing collections to be reported for deletion")
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Safe SQLite connection defaults.
+//
+// The WAL journal mode allows the readers to continue while a single
+// writer is active and the NORMAL synchronous mode is durable enough in
+// WAL mode (the last transactions could be rolled back only on power loss).
+const (
+	DefaultDBBusyTimeout time.Duration = 10 * time.Second
+	DefaultDBJournalMode string        = "WAL"
+	DefaultDBSynchronous string        = "NORMAL"
+	DefaultDBCacheSize   int           = -16000 // ~16MB (negative values are in KiB)
+)
+
+var (
+	dbJournalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	dbSyncModes    = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+// dbPragmas defines the configurable per connection SQLite PRAGMAs.
+type dbPragmas struct {
+	busyTimeout time.Duration
+	journalMode string
+	synchronous string
+	cacheSize   int
+}
+
+// defaultDBPragmas returns the default safe SQLite PRAGMAs.
+func defaultDBPragmas() dbPragmas {
+	return dbPragmas{
+		busyTimeout: DefaultDBBusyTimeout,
+		journalMode: DefaultDBJournalMode,
+		synchronous: DefaultDBSynchronous,
+		cacheSize:   DefaultDBCacheSize,
+	}
+}
+
+// newDBPragmas normalizes the provided PRAGMAs by replacing the
+// zero values with their defaults and validates the enum options.
+func newDBPragmas(busyTimeout time.Duration, journalMode string, synchronous string, cacheSize int) (dbPragmas, error) {
+	p := defaultDBPragmas()
+
+	if busyTimeout > 0 {
+		p.busyTimeout = busyTimeout
+	}
+
+	if journalMode != "" {
+		p.journalMode = strings.ToUpper(journalMode)
+		if !slices.Contains(dbJournalModes, p.journalMode) {
+			return p, fmt.Errorf("invalid db journal mode %q (expected one of %v)", journalMode, dbJournalModes)
+		}
+	}
+
+	if synchronous != "" {
+		p.synchronous = strings.ToUpper(synchronous)
+		if !slices.Contains(dbSyncModes, p.synchronous) {
+			return p, fmt.Errorf("invalid db synchronous mode %q (expected one of %v)", synchronous, dbSyncModes)
+		}
+	}
+
+	if cacheSize != 0 {
+		p.cacheSize = cacheSize
+	}
+
+	return p, nil
+}
@@ -4,47 +4,122 @@ package core
 
 import (
 	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/mattn/go-sqlite3"
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
 )
 
+// stmtCache caches the prepared SELECT statements of each db connection
+// so that the hot list/view queries don't have to be recompiled on every request.
+//
+// The cached statements are invalidated on schema change (see [dbutils.StmtCacheDriver]).
+var stmtCache *dbutils.StmtCacheDriver
+
 func init() {
 	// Registers the sqlite3 driver with a ConnectHook so that we can
 	// initialize the default PRAGMAs.
 	//
-	// Note 1: we don't define the PRAGMA as part of the dsn string
-	// because not all pragmas are available.
-	//
-	// Note 2: the busy_timeout pragma must be first because
-	// the connection needs to be set to block on busy before WAL mode
-	// is set in case it hasn't been already set by another connection.
-	sql.Register("pb_sqlite3",
+	// Note: the configurable pragmas (busy_timeout, journal_mode, synchronous
+	// and cache_size) are defined as part of the dsn string (see connectDB)
+	// and are applied by the driver before the ConnectHook.
+	stmtCache = dbutils.NewStmtCacheDriver(
 		&sqlite3.SQLiteDriver{
 			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
 				_, err := conn.Exec(`
-					PRAGMA busy_timeout       = 10000;
-					PRAGMA journal_mode       = WAL;
 					PRAGMA journal_size_limit = 200000000;
-					PRAGMA synchronous        = NORMAL;
 					PRAGMA foreign_keys       = ON;
 					PRAGMA temp_store         = MEMORY;
-					PRAGMA cache_size         = -16000;
 				`, nil)
+				if err != nil {
+					return err
+				}
 
-				return err
+				return registerMathFunctions(conn)
 			},
 		},
+		dbutils.DefaultStmtCacheMaxSize,
 	)
 
+	sql.Register("pb_sqlite3", stmtCache)
+
 	dbx.BuilderFuncMap["pb_sqlite3"] = dbx.BuilderFuncMap["sqlite3"]
 }
 
-func connectDB(dbPath string) (*dbx.DB, error) {
-	db, err := dbx.Open("pb_sqlite3", dbPath)
+func connectDB(dbPath string, pragmas dbPragmas) (*dbx.DB, error) {
+	// Note: the driver always applies the busy_timeout pragma first because
+	// the connection needs to be set to block on busy before WAL mode
+	// is set in case it hasn't been already set by another connection.
+	dsn := fmt.Sprintf(
+		"%s?_busy_timeout=%d&_journal_mode=%s&_synchronous=%s&_cache_size=%d",
+		dbPath,
+		pragmas.busyTimeout.Milliseconds(),
+		pragmas.journalMode,
+		pragmas.synchronous,
+		pragmas.cacheSize,
+	)
+
+	db, err := dbx.Open("pb_sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
 
 	return db, nil
 }
+
+// registerMathFunctions registers the SQLite math functions used by
+// the search filters (eg. geoDistance) since the default mattn/go-sqlite3
+// build is without SQLITE_ENABLE_MATH_FUNCTIONS.
+//
+// Similar to the builtin math functions, NULL or non-numeric arguments result in NULL.
+func registerMathFunctions(conn *sqlite3.SQLiteConn) error {
+	unary := map[string]func(float64) float64{
+		"sin":     math.Sin,
+		"cos":     math.Cos,
+		"asin":    math.Asin,
+		"sqrt":    math.Sqrt,
+		"radians": func(x float64) float64 { return x * math.Pi / 180 },
+	}
+
+	for name, fn := range unary {
+		err := conn.RegisterFunc(name, func(x any) any {
+			v, ok := sqliteNumber(x)
+			if !ok {
+				return nil
+			}
+			return fn(v)
+		}, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return conn.RegisterFunc("power", func(x any, y any) any {
+		a, ok1 := sqliteNumber(x)
+		b, ok2 := sqliteNumber(y)
+		if !ok1 || !ok2 {
+			return nil
+		}
+		return math.Pow(a, b)
+	}, true)
+}
+
+func sqliteNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	case []byte:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
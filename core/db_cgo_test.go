@@ -0,0 +1,147 @@
+//go:build cgo
+
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/migrate"
+)
+
+func TestStmtCacheSchemaChange(t *testing.T) {
+	app := NewBaseApp(BaseAppConfig{DataDir: t.TempDir()})
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	runner, err := migrate.NewRunner(app.DB(), migrations.AppMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	collection := &models.Collection{
+		Name: "stmt_cache_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(&schema.SchemaField{
+			Name: "title",
+			Type: schema.FieldTypeText,
+		}),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// use a single connection to have predictable cache state
+	app.Dao().ConcurrentDB().(*dbx.DB).DB().SetMaxOpenConns(1)
+
+	find := func() {
+		records := []*models.Record{}
+		err := app.Dao().RecordQuery(collection).AndWhere(dbx.HashExp{"title": "test"}).All(&records)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	find()
+	before := stmtCache.Stats()
+	find()
+	after := stmtCache.Stats()
+
+	if after.Hits != before.Hits+1 || after.Misses != before.Misses {
+		t.Fatalf("Expected a single cache hit, got %v (before %v)", after, before)
+	}
+
+	// schema change
+	collection.Schema.AddField(&schema.SchemaField{
+		Name: "description",
+		Type: schema.FieldTypeText,
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := stmtCache.Stats(); stats.Invalidations <= after.Invalidations {
+		t.Fatalf("Expected the cache to be invalidated, got %v (before %v)", stats, after)
+	}
+
+	before = stmtCache.Stats()
+	find()
+	after = stmtCache.Stats()
+
+	if after.Misses != before.Misses+1 {
+		t.Fatalf("Expected a cache miss after the schema change, got %v (before %v)", after, before)
+	}
+
+	records, err := app.Dao().FindRecordsByExpr(collection.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("Expected no records, got %d", len(records))
+	}
+}
+
+func BenchmarkStmtCache(b *testing.B) {
+	dbPath := filepath.Join(b.TempDir(), "data.db")
+
+	setup, err := dbx.Open("sqlite3", dbPath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer setup.Close()
+
+	_, err = setup.NewQuery(`
+		CREATE TABLE demo (id TEXT PRIMARY KEY, title TEXT, created TEXT);
+		CREATE INDEX idx_demo_created ON demo (created);
+	`).Execute()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name   string
+		driver string
+	}{
+		{"without cache", "sqlite3"},
+		{"with cache", "pb_sqlite3"},
+	}
+
+	for _, s := range scenarios {
+		b.Run(s.name, func(b *testing.B) {
+			db, err := dbx.Open(s.driver, dbPath)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer db.Close()
+
+			b.RunParallel(func(pb *testing.PB) {
+				rows := []dbx.NullStringMap{}
+
+				for pb.Next() {
+					err := db.Select("demo.*").
+						From("demo").
+						Where(dbx.NewExp("title LIKE {:title} OR created > {:created}", dbx.Params{
+							"title":   "%test%",
+							"created": "2024-01-01",
+						})).
+						OrderBy("created DESC").
+						Limit(30).
+						All(&rows)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
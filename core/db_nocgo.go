@@ -3,17 +3,26 @@
 package core
 
 import (
+	"fmt"
+
 	"github.com/pocketbase/dbx"
 	_ "modernc.org/sqlite"
 )
 
-func connectDB(dbPath string) (*dbx.DB, error) {
+func connectDB(dbPath string, pragmas dbPragmas) (*dbx.DB, error) {
 	// Note: the busy_timeout pragma must be first because
 	// the connection needs to be set to block on busy before WAL mode
 	// is set in case it hasn't been already set by another connection.
-	pragmas := "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=journal_size_limit(200000000)&_pragma=synchronous(NORMAL)&_pragma=foreign_keys(ON)&_pragma=temp_store(MEMORY)&_pragma=cache_size(-16000)"
+	dsn := fmt.Sprintf(
+		"%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(%s)&_pragma=journal_size_limit(200000000)&_pragma=synchronous(%s)&_pragma=foreign_keys(ON)&_pragma=temp_store(MEMORY)&_pragma=cache_size(%d)",
+		dbPath,
+		pragmas.busyTimeout.Milliseconds(),
+		pragmas.journalMode,
+		pragmas.synchronous,
+		pragmas.cacheSize,
+	)
 
-	db, err := dbx.Open("sqlite", dbPath+pragmas)
+	db, err := dbx.Open("sqlite", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+)
+
+func TestNewDBPragmas(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		busyTimeout time.Duration
+		journalMode string
+		synchronous string
+		cacheSize   int
+		expected    dbPragmas
+		expectError bool
+	}{
+		{
+			"zero values",
+			0, "", "", 0,
+			defaultDBPragmas(),
+			false,
+		},
+		{
+			"custom values",
+			time.Second, "delete", "full", 100,
+			dbPragmas{busyTimeout: time.Second, journalMode: "DELETE", synchronous: "FULL", cacheSize: 100},
+			false,
+		},
+		{
+			"invalid journal mode",
+			0, "invalid", "", 0,
+			dbPragmas{},
+			true,
+		},
+		{
+			"invalid synchronous",
+			0, "", "invalid", 0,
+			dbPragmas{},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := newDBPragmas(s.busyTimeout, s.journalMode, s.synchronous, s.cacheSize)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestBaseAppDBPragmas(t *testing.T) {
+	app := NewBaseApp(BaseAppConfig{
+		DataDir:           t.TempDir(),
+		DataMaxOpenConns:  5,
+		DataMaxWriteConns: 2,
+		DBBusyTimeout:     3 * time.Second,
+		DBJournalMode:     "wal",
+		DBSynchronous:     "full",
+		DBCacheSize:       -2000,
+	})
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbs := map[string]dbx.Builder{
+		"data concurrent":    app.Dao().ConcurrentDB(),
+		"data nonconcurrent": app.Dao().NonconcurrentDB(),
+		"logs concurrent":    app.LogsDao().ConcurrentDB(),
+		"logs nonconcurrent": app.LogsDao().NonconcurrentDB(),
+	}
+
+	pragmas := []struct {
+		name     string
+		expected string
+	}{
+		{"busy_timeout", "3000"},
+		{"journal_mode", "wal"},
+		{"synchronous", "2"}, // FULL
+		{"cache_size", "-2000"},
+		{"foreign_keys", "1"},
+	}
+
+	for name, db := range dbs {
+		for _, p := range pragmas {
+			var value string
+			if err := db.NewQuery("PRAGMA " + p.name).Row(&value); err != nil {
+				t.Fatalf("[%s] Failed to query %s: %v", name, p.name, err)
+			}
+			if value != p.expected {
+				t.Fatalf("[%s] Expected %s %q, got %q", name, p.name, p.expected, value)
+			}
+		}
+	}
+
+	if v := app.Dao().ConcurrentDB().(*dbx.DB).DB().Stats().MaxOpenConnections; v != 5 {
+		t.Fatalf("Expected the read pool max open connections to be 5, got %d", v)
+	}
+
+	if v := app.Dao().NonconcurrentDB().(*dbx.DB).DB().Stats().MaxOpenConnections; v != 2 {
+		t.Fatalf("Expected the write pool max open connections to be 2, got %d", v)
+	}
+}
+
+func TestBaseAppDBDefaultWriteConns(t *testing.T) {
+	app := NewBaseApp(BaseAppConfig{DataDir: t.TempDir()})
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := app.Dao().NonconcurrentDB().(*dbx.DB).DB().Stats().MaxOpenConnections; v != DefaultDataMaxWriteConns {
+		t.Fatalf("Expected the write pool max open connections to be %d, got %d", DefaultDataMaxWriteConns, v)
+	}
+}
+
+func TestBaseAppDBInvalidPragmas(t *testing.T) {
+	app := NewBaseApp(BaseAppConfig{
+		DataDir:       t.TempDir(),
+		DBJournalMode: "invalid",
+	})
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err == nil {
+		t.Fatal("Expected the bootstrap to fail with invalid journal mode")
+	}
+}
+
+func TestBaseAppDBConcurrentWrites(t *testing.T) {
+	app := NewBaseApp(BaseAppConfig{
+		DataDir:       t.TempDir(),
+		DBBusyTimeout: 5 * time.Second,
+	})
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := app.Dao().DB().NewQuery("CREATE TABLE concurrent_test (id INTEGER PRIMARY KEY, value TEXT)").Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 20
+	const writesPerWorker = 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*writesPerWorker*2)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			for j := 0; j < writesPerWorker; j++ {
+				value := fmt.Sprintf("%d_%d", worker, j)
+
+				// mix of plain and transactional writes
+				if j%2 == 0 {
+					_, err := app.Dao().NonconcurrentDB().Insert("concurrent_test", dbx.Params{"value": value}).Execute()
+					errs <- err
+				} else {
+					errs <- app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+						_, err := txDao.DB().Insert("concurrent_test", dbx.Params{"value": value}).Execute()
+						return err
+					})
+				}
+
+				// concurrent reads
+				var total int
+				errs <- app.Dao().ConcurrentDB().Select("count(*)").From("concurrent_test").Row(&total)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Expected no concurrent write errors, got %v", err)
+		}
+	}
+
+	var total int
+	if err := app.Dao().DB().Select("count(*)").From("concurrent_test").Row(&total); err != nil {
+		t.Fatal(err)
+	}
+	if total != workers*writesPerWorker {
+		t.Fatalf("Expected %d rows, got %d", workers*writesPerWorker, total)
+	}
+}
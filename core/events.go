@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
@@ -12,6 +13,7 @@ import (
 	"github.com/pocketbase/pocketbase/tools/auth"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/maildrop"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
@@ -72,6 +74,18 @@ type BootstrapEvent struct {
 type TerminateEvent struct {
 	App       App
 	IsRestart bool
+
+	// ShutdownDeadline is an optional deadline for the graceful shutdown
+	// of the app background tasks (eg. waiting for the running cron jobs).
+	//
+	// It is set by apis.Serve() based on the configured shutdown timeout.
+	ShutdownDeadline time.Time
+}
+
+type SettingsReloadEvent struct {
+	App         App
+	OldSettings *settings.Settings
+	NewSettings *settings.Settings
 }
 
 type ServeEvent struct {
@@ -109,6 +123,17 @@ type MailerRecordEvent struct {
 	Meta       map[string]any
 }
 
+type MailerSendEvent struct {
+	App        App
+	MailClient mailer.Mailer
+	Message    *mailer.Message
+}
+
+type MailReceiveEvent struct {
+	App     App
+	Message *maildrop.Message
+}
+
 type MailerAdminEvent struct {
 	MailClient mailer.Mailer
 	Message    *mailer.Message
@@ -183,6 +208,11 @@ type RecordCreateEvent struct {
 	HttpContext   echo.Context
 	Record        *models.Record
 	UploadedFiles map[string][]*filesystem.File
+
+	// ValidateOnly indicates a validate-only (aka. dry-run) request
+	// whose changes are always rolled back. Handlers with side effects
+	// (eg. sending emails) should check it and skip them.
+	ValidateOnly bool
 }
 
 type RecordUpdateEvent struct {
@@ -191,6 +221,11 @@ type RecordUpdateEvent struct {
 	HttpContext   echo.Context
 	Record        *models.Record
 	UploadedFiles map[string][]*filesystem.File
+
+	// ValidateOnly indicates a validate-only (aka. dry-run) request
+	// whose changes are always rolled back. Handlers with side effects
+	// (eg. sending emails) should check it and skip them.
+	ValidateOnly bool
 }
 
 type RecordDeleteEvent struct {
@@ -200,6 +235,38 @@ type RecordDeleteEvent struct {
 	Record      *models.Record
 }
 
+type RecordEnrichEvent struct {
+	BaseCollectionEvent
+
+	HttpContext echo.Context
+	Record      *models.Record
+	RequestInfo *models.RequestInfo
+}
+
+// The [RecordsQueryEvent] actions.
+const (
+	RecordsQueryActionList      = "list"
+	RecordsQueryActionView      = "view"
+	RecordsQueryActionAggregate = "aggregate"
+	RecordsQueryActionUpdate    = "update"
+	RecordsQueryActionDelete    = "delete"
+	RecordsQueryActionExpand    = "expand"
+	RecordsQueryActionRealtime  = "realtime"
+)
+
+type RecordsQueryEvent struct {
+	BaseCollectionEvent
+
+	// HttpContext is nil for the realtime broadcast queries.
+	HttpContext echo.Context
+	RequestInfo *models.RequestInfo
+	Query       *dbx.SelectQuery
+
+	// Action is the API action of the query
+	// (one of the RecordsQueryAction* constants).
+	Action string
+}
+
 // -------------------------------------------------------------------
 // Auth Record API events data
 // -------------------------------------------------------------------
@@ -207,10 +274,11 @@ type RecordDeleteEvent struct {
 type RecordAuthEvent struct {
 	BaseCollectionEvent
 
-	HttpContext echo.Context
-	Record      *models.Record
-	Token       string
-	Meta        any
+	HttpContext  echo.Context
+	Record       *models.Record
+	Token        string
+	RefreshToken string // empty if the collection doesn't allow refresh tokens
+	Meta         any
 }
 
 type RecordAuthWithPasswordEvent struct {
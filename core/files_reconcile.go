@@ -0,0 +1,213 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// DefaultReconcileFilesMinAge is the default min age of the storage files
+// that could be considered orphans by [ReconcileFiles].
+//
+// It is used to skip the files of the in-progress uploads
+// (eg. not yet persisted records or pending presigned uploads).
+const DefaultReconcileFilesMinAge = 1 * time.Hour
+
+// reconcileFilesBatchSize is the max number of records that
+// are loaded at once to check their referenced files.
+const reconcileFilesBatchSize = 500
+
+// ReconcileFilesOptions defines the options of [ReconcileFiles].
+type ReconcileFilesOptions struct {
+	// DryRun only reports the orphan files without deleting them.
+	DryRun bool
+
+	// MinAge skips the files that were modified more recently than
+	// the specified duration (default to DefaultReconcileFilesMinAge).
+	MinAge time.Duration
+}
+
+// ReconcileFilesResult defines the [ReconcileFiles] result.
+type ReconcileFilesResult struct {
+	// Orphans is a list with the storage keys of the found orphan files.
+	Orphans []string
+
+	// Deleted is the number of the deleted orphan files
+	// (always 0 on dry run).
+	Deleted int
+}
+
+// ReconcileFiles lists the stored files of each collection and finds
+// (and if not a dry run - deletes) the ones that are not referenced
+// by any record, aka. orphan files.
+//
+// Orphan files could be left for example when the app crashes before
+// the optimistic delete of the old record files completes.
+//
+// The thumbs of a file are treated as orphans only if the original
+// file is an orphan too. The files of the trashed records are kept.
+func ReconcileFiles(app App, options ReconcileFilesOptions) (*ReconcileFilesResult, error) {
+	minAge := options.MinAge
+	if minAge <= 0 {
+		minAge = DefaultReconcileFilesMinAge
+	}
+	maxModTime := time.Now().Add(-minAge)
+
+	collections := []*models.Collection{}
+	if err := app.Dao().CollectionQuery().
+		AndWhere(dbx.NewExp("[[type]] != {:view}", dbx.Params{"view": models.CollectionTypeView})).
+		OrderBy("created ASC").
+		All(&collections); err != nil {
+		return nil, err
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+
+	result := &ReconcileFilesResult{Orphans: []string{}}
+
+	var deleteErrs []error
+
+	for _, collection := range collections {
+		prefix := collection.BaseFilesPath() + "/"
+
+		files, err := fsys.List(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the %q collection files: %w", collection.Name, err)
+		}
+
+		// group the file names by their record id
+		// (aka. "collectionId/recordId/name")
+		recordFiles := map[string][]string{}
+		recentRecords := map[string]struct{}{}
+		for _, file := range files {
+			if file.IsDir {
+				continue
+			}
+
+			recordId, name, ok := strings.Cut(strings.TrimPrefix(file.Key, prefix), "/")
+			if !ok || recordId == "" || name == "" {
+				continue
+			}
+
+			if file.ModTime.After(maxModTime) {
+				recentRecords[recordId] = struct{}{}
+				continue
+			}
+
+			recordFiles[recordId] = append(recordFiles[recordId], name)
+		}
+
+		if len(recordFiles) == 0 {
+			continue
+		}
+
+		referenced, err := findReferencedFiles(app, collection, recordFiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the %q collection records: %w", collection.Name, err)
+		}
+
+		for recordId, names := range recordFiles {
+			orphans := make([]string, 0, len(names))
+
+			for _, name := range names {
+				original := name
+
+				// thumbs are stored as "thumbs_filename/size_filename"
+				if thumbsDir, _, ok := strings.Cut(name, "/"); ok {
+					original = strings.TrimPrefix(thumbsDir, "thumbs_")
+				}
+
+				if _, ok := referenced[recordId+"/"+original]; !ok {
+					orphans = append(orphans, prefix+recordId+"/"+name)
+				}
+			}
+
+			result.Orphans = append(result.Orphans, orphans...)
+
+			if options.DryRun || len(orphans) == 0 {
+				continue
+			}
+
+			// delete the entire record dir if all of its files are orphans
+			// (eg. interrupted record delete)
+			if _, ok := recentRecords[recordId]; !ok && len(orphans) == len(names) {
+				if errs := fsys.DeletePrefix(prefix + recordId + "/"); len(errs) > 0 {
+					deleteErrs = append(deleteErrs, errs...)
+				} else {
+					result.Deleted += len(orphans)
+				}
+				continue
+			}
+
+			for _, key := range orphans {
+				if err := fsys.Delete(key); err != nil {
+					deleteErrs = append(deleteErrs, fmt.Errorf("%s: %w", key, err))
+				} else {
+					result.Deleted++
+				}
+			}
+		}
+	}
+
+	sort.Strings(result.Orphans)
+
+	if len(deleteErrs) > 0 {
+		return result, fmt.Errorf("failed to delete all orphan files: %w", errors.Join(deleteErrs...))
+	}
+
+	return result, nil
+}
+
+// findReferencedFiles returns a set with the "recordId/filename" pairs
+// of the files referenced by the provided collection records (including the trashed ones).
+func findReferencedFiles(app App, collection *models.Collection, recordFiles map[string][]string) (map[string]struct{}, error) {
+	result := map[string]struct{}{}
+
+	fileFields := []*schema.SchemaField{}
+	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeFile {
+			fileFields = append(fileFields, field)
+		}
+	}
+
+	if len(fileFields) == 0 {
+		return result, nil
+	}
+
+	ids := make([]any, 0, len(recordFiles))
+	for id := range recordFiles {
+		ids = append(ids, id)
+	}
+
+	for _, batch := range list.ToChunks(ids, reconcileFilesBatchSize) {
+		records := []*models.Record{}
+
+		err := app.Dao().RecordQueryWithTrashed(collection).
+			AndWhere(dbx.In(collection.Name+".id", batch...)).
+			All(&records)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			for _, field := range fileFields {
+				for _, name := range record.GetStringSlice(field.Name) {
+					result[record.Id+"/"+name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package core_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestReconcileFiles(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	referenced := []string{
+		"wsmn24bux7wo113/84nmscqy84lsi1t/test_d61b33QdDU.txt",
+		"wsmn24bux7wo113/84nmscqy84lsi1t/300_WlbFWSGmW9.png",
+		"wsmn24bux7wo113/84nmscqy84lsi1t/thumbs_300_WlbFWSGmW9.png/100x100_300_WlbFWSGmW9.png",
+	}
+
+	orphans := []string{
+		// unreferenced file of an existing record
+		"wsmn24bux7wo113/84nmscqy84lsi1t/orphan_abc.txt",
+		"wsmn24bux7wo113/84nmscqy84lsi1t/thumbs_orphan_abc.png/100x100_orphan_abc.png",
+		// file of a missing record
+		"wsmn24bux7wo113/missing_record/test.txt",
+	}
+
+	for _, key := range append(referenced, orphans...) {
+		if err := fsys.Upload([]byte("test"), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// files newer than the default min age are skipped
+	result, err := core.ReconcileFiles(app, core.ReconcileFilesOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range orphans {
+		if containsString(result.Orphans, key) {
+			t.Fatalf("Expected the recent file %q to be skipped", key)
+		}
+	}
+
+	// dry run
+	result, err = core.ReconcileFiles(app, core.ReconcileFilesOptions{DryRun: true, MinAge: time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 0 {
+		t.Fatalf("Expected no deleted files on dry run, got %d", result.Deleted)
+	}
+	for _, key := range orphans {
+		if !containsString(result.Orphans, key) {
+			t.Fatalf("Expected orphan %q in %v", key, result.Orphans)
+		}
+		if exists, _ := fsys.Exists(key); !exists {
+			t.Fatalf("Expected orphan %q to not be deleted on dry run", key)
+		}
+	}
+	for _, key := range referenced {
+		if containsString(result.Orphans, key) {
+			t.Fatalf("Expected referenced file %q to not be an orphan", key)
+		}
+	}
+
+	// actual delete
+	result, err = core.ReconcileFiles(app, core.ReconcileFilesOptions{MinAge: time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != len(result.Orphans) {
+		t.Fatalf("Expected %d deleted files, got %d", len(result.Orphans), result.Deleted)
+	}
+	for _, key := range orphans {
+		if exists, _ := fsys.Exists(key); exists {
+			t.Fatalf("Expected orphan %q to be deleted", key)
+		}
+	}
+	for _, key := range referenced {
+		if exists, _ := fsys.Exists(key); !exists {
+			t.Fatalf("Expected referenced file %q to not be deleted", key)
+		}
+	}
+
+	// nothing to reconcile
+	result, err = core.ReconcileFiles(app, core.ReconcileFilesOptions{MinAge: time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Orphans) != 0 {
+		t.Fatalf("Expected no orphans, got %v", result.Orphans)
+	}
+}
+
+func TestDeleteRecordFilesAfterCommit(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	fileKey := "wsmn24bux7wo113/84nmscqy84lsi1t/test_d61b33QdDU.txt"
+
+	// rolled back delete
+	app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		record, err := txDao.FindRecordById("demo1", "84nmscqy84lsi1t")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// simulate a manually created transaction dao with the app hooks
+		// (aka. the after hooks are triggered before the commit)
+		appAfterDeleteFunc := app.Dao().AfterDeleteFunc
+		txDao.AfterDeleteFunc = func(eventDao *daos.Dao, m models.Model) error {
+			return appAfterDeleteFunc(eventDao, m)
+		}
+
+		if err := txDao.DeleteRecord(record); err != nil {
+			t.Fatal(err)
+		}
+
+		return errors.New("test rollback")
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	if exists, _ := fsys.Exists(fileKey); !exists {
+		t.Fatalf("Expected %q to not be deleted after the transaction rollback", fileKey)
+	}
+
+	// committed delete
+	record, err := app.Dao().FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if exists, _ := fsys.Exists(fileKey); exists {
+		t.Fatalf("Expected %q to be deleted after the transaction commit", fileKey)
+	}
+}
+
+func containsString(list []string, str string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, str) {
+			return true
+		}
+	}
+	return false
+}
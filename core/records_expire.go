@@ -0,0 +1,182 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// DefaultExpiredRecordsBatchSize is the default max number of expired
+// records that are loaded and deleted at once by [DeleteExpiredRecords].
+const DefaultExpiredRecordsBatchSize = 100
+
+// DefaultExpiredRecordsMaxBatches is the default max number of batches
+// that are processed per collection in a single [DeleteExpiredRecords] call.
+const DefaultExpiredRecordsMaxBatches = 10
+
+// expiredRecordsCronExpr is the schedule of the expired records background sweep.
+const expiredRecordsCronExpr = "* * * * *"
+
+// DeleteExpiredRecordsOptions defines the options of [DeleteExpiredRecords].
+type DeleteExpiredRecordsOptions struct {
+	// BatchSize is the max number of records that are loaded and
+	// deleted at once (default to DefaultExpiredRecordsBatchSize).
+	BatchSize int
+
+	// MaxBatches is the max number of batches that are processed per
+	// collection (default to DefaultExpiredRecordsMaxBatches).
+	//
+	// The remaining expired records are left for the next call.
+	MaxBatches int
+}
+
+// DeleteExpiredRecords deletes the records of all collections with
+// configured expire field (see [models.Collection.ExpireField]) whose
+// expiration date + grace period is in the past.
+//
+// The records are deleted one by one with [daos.Dao.DeleteRecord],
+// aka. the regular model delete hooks are triggered, the relations
+// are cascaded and the soft delete collection records are trashed.
+//
+// The records that fail to be deleted (eg. because of a restrict
+// relation reference) are skipped and their errors are joined
+// in the returned error.
+//
+// It returns the total number of the deleted records.
+func DeleteExpiredRecords(app App, options DeleteExpiredRecordsOptions) (int, error) {
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultExpiredRecordsBatchSize
+	}
+
+	maxBatches := options.MaxBatches
+	if maxBatches <= 0 {
+		maxBatches = DefaultExpiredRecordsMaxBatches
+	}
+
+	collections := []*models.Collection{}
+	if err := app.Dao().CollectionQuery().
+		AndWhere(dbx.NewExp("[[type]] != {:view}", dbx.Params{"view": models.CollectionTypeView})).
+		OrderBy("created ASC").
+		All(&collections); err != nil {
+		return 0, err
+	}
+
+	var total int
+	var errs []error
+
+	for _, collection := range collections {
+		expireField := collection.ExpireField()
+		if expireField == "" || collection.Schema.GetFieldByName(expireField) == nil {
+			continue
+		}
+
+		threshold := time.Now().Add(-collection.ExpireGracePeriod())
+
+		for i := 0; i < maxBatches; i++ {
+			records, err := findExpiredRecords(app, collection, expireField, threshold, batchSize)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to load the expired %s records: %w", collection.Name, err))
+				break
+			}
+
+			var deleted int
+			for _, record := range records {
+				if err := app.Dao().DeleteRecord(record); err != nil {
+					errs = append(errs, fmt.Errorf("failed to delete expired record %s (%s collection): %w", record.Id, collection.Name, err))
+					continue
+				}
+				deleted++
+			}
+
+			total += deleted
+
+			// no more expired records or some of them couldn't be deleted
+			// (stop to avoid reloading the same failing records)
+			if len(records) < batchSize || deleted < len(records) {
+				break
+			}
+		}
+	}
+
+	return total, errors.Join(errs...)
+}
+
+func findExpiredRecords(
+	app App,
+	collection *models.Collection,
+	expireField string,
+	threshold time.Time,
+	limit int,
+) ([]*models.Record, error) {
+	column := app.Dao().DB().QuoteSimpleColumnName(collection.Name) + "." +
+		app.Dao().DB().QuoteSimpleColumnName(expireField)
+
+	records := []*models.Record{}
+
+	err := app.Dao().RecordQuery(collection).
+		AndWhere(dbx.NewExp(
+			column+" != '' AND "+column+" <= {:threshold}",
+			dbx.Params{"threshold": threshold.UTC().Format(types.DefaultDateLayout)},
+		)).
+		OrderBy(expireField + " ASC").
+		Limit(int64(limit)).
+		All(&records)
+
+	return records, err
+}
+
+// initExpiredRecordsHooks registers the app serve hooks that
+// periodically delete the expired collection records.
+func (app *BaseApp) initExpiredRecordsHooks() {
+	c := cron.New()
+
+	// prevent overlapping sweeps in case a run takes longer than the interval
+	var mu sync.Mutex
+
+	c.MustAdd("@expiredRecords", expiredRecordsCronExpr, func() {
+		if !mu.TryLock() {
+			return
+		}
+		defer mu.Unlock()
+
+		start := time.Now()
+
+		deleted, err := DeleteExpiredRecords(app, DeleteExpiredRecordsOptions{})
+		if err != nil {
+			app.Logger().Debug(
+				"[Expired records cron] Failed to delete some of the expired records",
+				slog.Int("deleted", deleted),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+
+		if deleted > 0 {
+			app.Logger().Debug(
+				"[Expired records cron] Deleted expired records",
+				slog.Int("deleted", deleted),
+				slog.Duration("duration", time.Since(start)),
+			)
+		}
+	})
+
+	// start the ticker on app serve
+	app.OnBeforeServe().Add(func(e *ServeEvent) error {
+		c.Start()
+		return nil
+	})
+
+	// stop the ticker and wait for the running sweep on app termination
+	app.OnTerminate().Add(func(e *TerminateEvent) error {
+		stopCron(e, c)
+		return nil
+	})
+}
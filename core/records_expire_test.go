@@ -0,0 +1,133 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestDeleteExpiredRecords(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	now := time.Now()
+
+	createCollection := func(name string, options types.JsonMap) *models.Collection {
+		collection := &models.Collection{
+			Name:    name,
+			Type:    models.CollectionTypeBase,
+			Options: options,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{Name: "expires", Type: schema.FieldTypeDate},
+			),
+		}
+		if err := app.Dao().SaveCollection(collection); err != nil {
+			t.Fatal(err)
+		}
+		return collection
+	}
+
+	createRecord := func(collection *models.Collection, id string, expires time.Time) {
+		record := models.NewRecord(collection)
+		record.Id = id
+		if !expires.IsZero() {
+			record.Set("expires", expires)
+		}
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	shares := createCollection("shares", types.JsonMap{"expireField": "expires"})
+	createRecord(shares, "shares_expired1", now.Add(-1*time.Hour))
+	createRecord(shares, "shares_expired2", now.Add(-1*time.Second))
+	createRecord(shares, "shares_expired3", now.Add(-24*time.Hour))
+	createRecord(shares, "shares_future00", now.Add(1*time.Hour))
+	createRecord(shares, "shares_noexpire", time.Time{})
+
+	links := createCollection("links", types.JsonMap{"expireField": "expires", "expireGracePeriod": 600})
+	createRecord(links, "links_expired01", now.Add(-1*time.Hour))
+	createRecord(links, "links_ingrace01", now.Add(-1*time.Minute))
+
+	// no expire field
+	noExpire := createCollection("no_expire", nil)
+	createRecord(noExpire, "noexpire_past01", now.Add(-1*time.Hour))
+
+	deletedIds := []string{}
+	app.OnModelAfterDelete().Add(func(e *core.ModelEvent) error {
+		deletedIds = append(deletedIds, e.Model.GetId())
+		return nil
+	})
+
+	// limited batches
+	deleted, err := core.DeleteExpiredRecords(app, core.DeleteExpiredRecordsOptions{BatchSize: 1, MaxBatches: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 3 { // 2 shares + 1 links
+		t.Fatalf("Expected 3 deleted records in the first run, got %d", deleted)
+	}
+
+	// delete the remaining expired records
+	deleted, err = core.DeleteExpiredRecords(app, core.DeleteExpiredRecordsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("Expected 1 deleted record in the second run, got %d", deleted)
+	}
+
+	// nothing else to delete
+	deleted, err = core.DeleteExpiredRecords(app, core.DeleteExpiredRecordsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 0 {
+		t.Fatalf("Expected no deleted records in the third run, got %d", deleted)
+	}
+
+	expectedDeleted := map[string]string{
+		"shares_expired1": "shares",
+		"shares_expired2": "shares",
+		"shares_expired3": "shares",
+		"links_expired01": "links",
+	}
+	if len(deletedIds) != len(expectedDeleted) {
+		t.Fatalf("Expected %d delete hook calls, got %v", len(expectedDeleted), deletedIds)
+	}
+	for id, collection := range expectedDeleted {
+		var found bool
+		for _, deletedId := range deletedIds {
+			if deletedId == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected delete hook to be called for %q", id)
+		}
+
+		if _, err := app.Dao().FindRecordById(collection, id); err == nil {
+			t.Errorf("Expected record %q to be deleted", id)
+		}
+	}
+
+	remaining := map[string]string{
+		"shares_future00": "shares",
+		"shares_noexpire": "shares",
+		"links_ingrace01": "links",
+		"noexpire_past01": "no_expire",
+	}
+	for id, collection := range remaining {
+		if _, err := app.Dao().FindRecordById(collection, id); err != nil {
+			t.Errorf("Expected record %q to remain, got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// RecordsListCacheMaxSize is the max number of cached records list api results.
+const RecordsListCacheMaxSize = 1000
+
+const storeRecordsListCacheKey = "@recordsListCache"
+
+// registerRecordsListCacheAppHooks registers the app hooks that
+// invalidate the cached records list results of a collection
+// on every change of its records or of the collection itself.
+func registerRecordsListCacheAppHooks(app App) {
+	app.Store().Set(storeRecordsListCacheKey, NewListCache(RecordsListCacheMaxSize))
+
+	invalidateFunc := func(e *ModelEvent) error {
+		switch m := e.Model.(type) {
+		case *models.Record:
+			RecordsListCache(app).Invalidate(m.Collection().Id)
+		case *models.Collection:
+			RecordsListCache(app).Invalidate(m.Id)
+		}
+
+		return nil
+	}
+	app.OnModelAfterCreate().Add(invalidateFunc)
+	app.OnModelAfterUpdate().Add(invalidateFunc)
+	app.OnModelAfterDelete().Add(invalidateFunc)
+}
+
+// RecordsListCache returns the app in-memory cache with the serialized
+// records list api results of the collections with enabled list caching
+// (see [models.Collection.ListCacheTTL]).
+func RecordsListCache(app App) *ListCache {
+	cache, _ := app.Store().Get(storeRecordsListCacheKey).(*ListCache)
+	if cache == nil {
+		cache = NewListCache(RecordsListCacheMaxSize)
+		app.Store().Set(storeRecordsListCacheKey, cache)
+	}
+
+	return cache
+}
+
+// ListCache defines an in-memory LRU cache for serialized
+// list results grouped by their collection.
+type ListCache struct {
+	lru        *store.LRU[[]byte]
+	generation atomic.Uint64
+
+	// dependents holds the ids of the collections with cached results
+	// that depend on the key collection (eg. referenced in a rule).
+	dependents map[string]map[string]struct{}
+	mux        sync.Mutex
+}
+
+// NewListCache creates a new ListCache that holds up to maxSize results.
+func NewListCache(maxSize int) *ListCache {
+	return &ListCache{
+		lru:        store.NewLRU[[]byte](maxSize),
+		dependents: map[string]map[string]struct{}{},
+	}
+}
+
+// Generation returns the current cache generation that is
+// incremented on every [ListCache.Invalidate] call.
+//
+// It should be loaded before querying the results that
+// are going to be cached with [ListCache.Set].
+func (c *ListCache) Generation() uint64 {
+	return c.generation.Load()
+}
+
+// Get returns the cached non-expired result of the specified
+// collection and result key.
+func (c *ListCache) Get(collectionId string, key string) ([]byte, bool) {
+	return c.lru.Get(collectionId + ":" + key)
+}
+
+// Set caches the provided collection result for ttl duration.
+//
+// The optional dependencies are the ids of the other collections that
+// the result depends on (eg. referenced in the list rule or filter)
+// and whose invalidation should also invalidate the collection results.
+//
+// The result is not cached if the cache was invalidated after the
+// provided generation (aka. the result could be already stale).
+func (c *ListCache) Set(collectionId string, key string, result []byte, ttl time.Duration, generation uint64, dependencies ...string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.generation.Load() != generation {
+		return
+	}
+
+	for _, dep := range dependencies {
+		if dep == collectionId {
+			continue
+		}
+
+		if c.dependents[dep] == nil {
+			c.dependents[dep] = map[string]struct{}{}
+		}
+		c.dependents[dep][collectionId] = struct{}{}
+	}
+
+	c.lru.Set(collectionId+":"+key, result, ttl)
+}
+
+// Invalidate removes all cached results of the specified collection
+// and of the collections that depend on it.
+//
+// It is called automatically after each record create, update and delete
+// performed through the app Dao, but it could be also called manually
+// after a direct db change (eg. raw SQL statement).
+func (c *ListCache) Invalidate(collectionId string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.generation.Add(1)
+	c.lru.RemoveByPrefix(collectionId + ":")
+
+	for dependent := range c.dependents[collectionId] {
+		c.lru.RemoveByPrefix(dependent + ":")
+	}
+	delete(c.dependents, collectionId)
+}
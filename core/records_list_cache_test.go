@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListCacheStaleGeneration(t *testing.T) {
+	t.Parallel()
+
+	cache := NewListCache(10)
+
+	generation := cache.Generation()
+
+	cache.Set("a", "key1", []byte("1"), time.Minute, generation)
+
+	if v, ok := cache.Get("a", "key1"); !ok || string(v) != "1" {
+		t.Fatalf("Expected cached result 1, got %q (%v)", v, ok)
+	}
+
+	// simulate a concurrent change while the result is being queried
+	staleGeneration := cache.Generation()
+	cache.Invalidate("b")
+	cache.Set("a", "key2", []byte("2"), time.Minute, staleGeneration)
+
+	if _, ok := cache.Get("a", "key2"); ok {
+		t.Fatal("Expected the result with stale generation to not be cached")
+	}
+
+	// other collections results should remain
+	if _, ok := cache.Get("a", "key1"); !ok {
+		t.Fatal("Expected key1 to be still cached")
+	}
+
+	cache.Invalidate("a")
+
+	if _, ok := cache.Get("a", "key1"); ok {
+		t.Fatal("Expected key1 to be invalidated")
+	}
+}
+
+func TestListCacheDependencies(t *testing.T) {
+	t.Parallel()
+
+	cache := NewListCache(10)
+
+	cache.Set("a", "key1", []byte("1"), time.Minute, cache.Generation(), "a", "b")
+	cache.Set("c", "key1", []byte("1"), time.Minute, cache.Generation())
+
+	cache.Invalidate("b")
+
+	if _, ok := cache.Get("a", "key1"); ok {
+		t.Fatal("Expected the dependent collection result to be invalidated")
+	}
+
+	if _, ok := cache.Get("c", "key1"); !ok {
+		t.Fatal("Expected the unrelated collection result to be still cached")
+	}
+}
@@ -0,0 +1,290 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// FindRecordAs returns the first collection record matching the
+// provided filter and scans it into a new T struct (see [ScanRecord]).
+//
+// NB! Use the last params argument to bind untrusted user variables!
+//
+// Example:
+//
+//	type Post struct {
+//		models.BaseModel
+//
+//		Title  string   `db:"title"`
+//		Tags   []string `db:"tags"`
+//		Author string   `db:"author"` // relation id
+//		Meta   struct {
+//			Views int `json:"views"`
+//		} `db:"meta"` // json field
+//	}
+//
+//	post, err := core.FindRecordAs[Post](app, "posts", "slug={:slug}", dbx.Params{"slug": "test"})
+func FindRecordAs[T any](app App, collectionNameOrId string, filter string, params ...dbx.Params) (*T, error) {
+	record, err := app.Dao().FindFirstRecordByFilter(collectionNameOrId, filter, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(T)
+
+	if err := ScanRecord(record, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindRecordsAs returns the collection records matching the provided
+// filter scanned into a list of T structs (see [ScanRecord]).
+//
+// The sort, limit and offset arguments are optional and behave
+// the same as in [daos.Dao.FindRecordsByFilter].
+//
+// NB! Use the last params argument to bind untrusted user variables!
+func FindRecordsAs[T any](
+	app App,
+	collectionNameOrId string,
+	filter string,
+	sort string,
+	limit int,
+	offset int,
+	params ...dbx.Params,
+) ([]*T, error) {
+	records, err := app.Dao().FindRecordsByFilter(collectionNameOrId, filter, sort, limit, offset, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*T, len(records))
+
+	for i, record := range records {
+		result[i] = new(T)
+
+		if err := ScanRecord(record, result[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// ScanRecord maps the provided record fields into the dst struct pointer.
+//
+// The struct fields are matched by their "db" tag, fallbacking to
+// the "json" tag and the (case-insensitive) field name
+// (use "-" as tag value to skip a field).
+// The fields of the embedded structs (eg. [models.BaseModel]) are also mapped.
+//
+// The relation fields are scanned as ids (string or []string), the json fields
+// are decoded with [json.Unmarshal] and the other values are converted
+// to the struct field type when possible. Missing record fields are ignored.
+//
+// If dst has a MarkAsNotNew method (eg. from an embedded [models.BaseModel]),
+// it is also marked as "not new".
+func ScanRecord(record *models.Record, dst any) error {
+	if record == nil {
+		return errors.New("missing record to scan")
+	}
+
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("the scan destination must be a non-nil struct pointer")
+	}
+
+	if err := scanRecordStruct(record, rv.Elem()); err != nil {
+		return err
+	}
+
+	if m, ok := dst.(interface{ MarkAsNotNew() }); ok {
+		m.MarkAsNotNew()
+	}
+
+	return nil
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	stringSliceType = reflect.TypeOf([]string{})
+)
+
+func scanRecordStruct(record *models.Record, structValue reflect.Value) error {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		key := scanFieldKey(field)
+		if key == "-" {
+			continue
+		}
+
+		// embedded struct (eg. models.BaseModel)
+		if field.Anonymous && key == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer && embedded.Type().Elem().Kind() == reflect.Struct {
+				if !embedded.CanSet() {
+					continue
+				}
+				if embedded.IsNil() {
+					embedded.Set(reflect.New(embedded.Type().Elem()))
+				}
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				if err := scanRecordStruct(record, embedded); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !field.IsExported() || !fieldValue.CanSet() {
+			continue
+		}
+
+		if key == "" {
+			key = scanRecordKey(record, field.Name)
+		}
+
+		if err := scanRecordValue(record, key, fieldValue); err != nil {
+			return fmt.Errorf("failed to scan record field %q into %s.%s: %w", key, structType.Name(), field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// scanFieldKey returns the record key from the struct field db or json tags.
+func scanFieldKey(field reflect.StructField) string {
+	for _, tagName := range []string{"db", "json"} {
+		if tag, _, _ := strings.Cut(field.Tag.Get(tagName), ","); tag != "" {
+			return tag
+		}
+	}
+
+	return ""
+}
+
+// scanRecordKey returns the record field name matching case-insensitively
+// the provided struct field name (or the name itself if there is no match).
+func scanRecordKey(record *models.Record, name string) string {
+	for _, systemField := range []string{schema.FieldNameId, schema.FieldNameCreated, schema.FieldNameUpdated} {
+		if strings.EqualFold(systemField, name) {
+			return systemField
+		}
+	}
+
+	for _, f := range record.Collection().Schema.Fields() {
+		if strings.EqualFold(f.Name, name) {
+			return f.Name
+		}
+	}
+
+	return name
+}
+
+func scanRecordValue(record *models.Record, key string, dst reflect.Value) error {
+	value := record.Get(key)
+	if value == nil {
+		return nil // missing field
+	}
+
+	// json field
+	if field := record.Collection().Schema.GetFieldByName(key); field != nil && field.Type == schema.FieldTypeJson {
+		raw, _ := value.(types.JsonRaw)
+		if len(raw) == 0 || string(raw) == "null" {
+			return nil
+		}
+
+		switch dst.Interface().(type) {
+		case types.JsonRaw, json.RawMessage, []byte:
+			dst.SetBytes(raw)
+			return nil
+		}
+
+		return json.Unmarshal(raw, dst.Addr().Interface())
+	}
+
+	// allocate pointer fields
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	rv := reflect.ValueOf(value)
+
+	if rv.Type().AssignableTo(dst.Type()) {
+		dst.Set(rv)
+		return nil
+	}
+
+	if dt, ok := value.(types.DateTime); ok && dst.Type() == timeType {
+		dst.Set(reflect.ValueOf(dt.Time()))
+		return nil
+	}
+
+	// eg. single relation id
+	if dst.Type() == stringSliceType {
+		dst.Set(reflect.ValueOf(list.ToUniqueStringSlice(value)))
+		return nil
+	}
+
+	var err error
+
+	switch dst.Kind() {
+	case reflect.String:
+		var v string
+		if v, err = cast.ToStringE(value); err == nil {
+			dst.SetString(v)
+		}
+	case reflect.Bool:
+		var v bool
+		if v, err = cast.ToBoolE(value); err == nil {
+			dst.SetBool(v)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		if v, err = cast.ToInt64E(value); err == nil {
+			dst.SetInt(v)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var v uint64
+		if v, err = cast.ToUint64E(value); err == nil {
+			dst.SetUint(v)
+		}
+	case reflect.Float32, reflect.Float64:
+		var v float64
+		if v, err = cast.ToFloat64E(value); err == nil {
+			dst.SetFloat(v)
+		}
+	default:
+		// fallback to json serialization for the other types
+		// (eg. custom json unmarshalers)
+		var raw []byte
+		if raw, err = json.Marshal(value); err == nil {
+			err = json.Unmarshal(raw, dst.Addr().Interface())
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,183 @@
+package core_test
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type demo1Item struct {
+	models.BaseModel
+
+	Text     string        `db:"text"`
+	Bool     bool          `json:"bool"`
+	Number   int           `db:"number"`
+	Datetime time.Time     `db:"datetime"`
+	Json     []int         `db:"json"`
+	JsonRaw  types.JsonRaw `db:"json"`
+	RelOne   string        `db:"rel_one"`
+	RelMany  []string      `db:"rel_many"`
+	Email    string        // untagged
+	Missing  string        `db:"missing"`
+	Skipped  string        `db:"-" json:"text"`
+}
+
+func TestFindRecordAs(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if _, err := core.FindRecordAs[demo1Item](app, "demo1", "id = 'missing'"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected sql.ErrNoRows, got %v", err)
+	}
+
+	item, err := core.FindRecordAs[demo1Item](app, "demo1", "text = {:text}", dbx.Params{"text": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Id != "84nmscqy84lsi1t" || item.IsNew() {
+		t.Fatalf("Expected the embedded BaseModel to be scanned, got id %q (new %v)", item.Id, item.IsNew())
+	}
+	if item.Created.IsZero() || item.Updated.IsZero() {
+		t.Fatalf("Expected non-zero created and updated dates, got %v, %v", item.Created, item.Updated)
+	}
+	if item.Text != "test" || !item.Bool || item.Number != 123456 {
+		t.Fatalf("Unexpected text/bool/number values: %q, %v, %d", item.Text, item.Bool, item.Number)
+	}
+	if item.Datetime.Format(types.DefaultDateLayout) != "2022-10-01 12:00:00.000Z" {
+		t.Fatalf("Unexpected datetime %v", item.Datetime)
+	}
+	if len(item.Json) != 3 || item.Json[0] != 1 || item.Json[2] != 3 {
+		t.Fatalf("Expected json [1 2 3], got %v", item.Json)
+	}
+	if !strings.Contains(item.JsonRaw.String(), "1") {
+		t.Fatalf("Expected the raw json value, got %q", item.JsonRaw)
+	}
+	if item.RelOne != "" {
+		t.Fatalf("Expected empty rel_one, got %q", item.RelOne)
+	}
+	if len(item.RelMany) != 1 || item.RelMany[0] != "oap640cot4yru2s" {
+		t.Fatalf("Expected rel_many [oap640cot4yru2s], got %v", item.RelMany)
+	}
+	if item.Email != "test@example.com" {
+		t.Fatalf("Expected email matched by the field name, got %q", item.Email)
+	}
+	if item.Missing != "" || item.Skipped != "" {
+		t.Fatalf("Expected missing and skipped fields to be empty, got %q, %q", item.Missing, item.Skipped)
+	}
+}
+
+func TestFindRecordsAs(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if _, err := core.FindRecordsAs[demo1Item](app, "missing", "", "", 0, 0); err == nil {
+		t.Fatal("Expected error for missing collection")
+	}
+
+	items, err := core.FindRecordsAs[demo1Item](app, "demo1", "number > {:min}", "-number", 10, 0, dbx.Params{"min": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	if items[0].Text != "test" || items[1].Text != "test2" {
+		t.Fatalf("Expected [test test2] items, got [%s %s]", items[0].Text, items[1].Text)
+	}
+
+	if items[1].RelOne != "84nmscqy84lsi1t" || len(items[1].RelMany) != 3 {
+		t.Fatalf("Unexpected relation ids: %q, %v", items[1].RelOne, items[1].RelMany)
+	}
+
+	if items[1].Json != nil || len(items[1].JsonRaw) != 0 {
+		t.Fatalf("Expected null json to be skipped, got %v, %q", items[1].Json, items[1].JsonRaw)
+	}
+}
+
+func TestScanRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("demo1", "al1h9ijdeojtsjy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("json", map[string]any{"title": "abc", "views": 10, "tags": []string{"a", "b"}})
+
+	type meta struct {
+		Title string   `json:"title"`
+		Views int      `json:"views"`
+		Tags  []string `json:"tags"`
+	}
+
+	type item struct {
+		*models.BaseModel
+
+		Text    *string  `db:"text"`
+		Number  float64  `db:"number"`
+		Bool    string   `db:"bool"`
+		RelOne  []string `db:"rel_one"`
+		RelMany []string `db:"rel_many"`
+		Meta    meta     `db:"json"`
+	}
+
+	t.Run("invalid destination", func(t *testing.T) {
+		if err := core.ScanRecord(record, item{}); err == nil {
+			t.Fatal("Expected error for non-pointer destination")
+		}
+
+		if err := core.ScanRecord(nil, &item{}); err == nil {
+			t.Fatal("Expected error for nil record")
+		}
+
+		if err := core.ScanRecord(record, &struct {
+			Text int `db:"text"`
+		}{}); err == nil {
+			t.Fatal("Expected error for incompatible field type")
+		}
+	})
+
+	t.Run("valid destination", func(t *testing.T) {
+		result := &item{}
+
+		if err := core.ScanRecord(record, result); err != nil {
+			t.Fatal(err)
+		}
+
+		if result.BaseModel == nil || result.Id != record.Id {
+			t.Fatalf("Expected the embedded BaseModel pointer to be initialized, got %v", result.BaseModel)
+		}
+		if result.Text == nil || *result.Text != "test2" {
+			t.Fatalf("Expected text pointer to test2, got %v", result.Text)
+		}
+		if result.Number != 456 || result.Bool != "false" {
+			t.Fatalf("Unexpected number/bool values: %v, %q", result.Number, result.Bool)
+		}
+		if len(result.RelOne) != 1 || result.RelOne[0] != "84nmscqy84lsi1t" {
+			t.Fatalf("Expected single relation id as slice, got %v", result.RelOne)
+		}
+		if len(result.RelMany) != 3 {
+			t.Fatalf("Expected 3 rel_many ids, got %v", result.RelMany)
+		}
+		if result.Meta.Title != "abc" || result.Meta.Views != 10 || len(result.Meta.Tags) != 2 {
+			t.Fatalf("Unexpected json field value: %#v", result.Meta)
+		}
+	})
+}
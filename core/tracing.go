@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/pocketbase/pocketbase"
+
+// Tracer returns the app OpenTelemetry tracer.
+//
+// If BaseAppConfig.TracerProvider is not set, returns a no-op tracer.
+func (app *BaseApp) Tracer() trace.Tracer {
+	if app.tracer == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+
+	return app.tracer
+}
+
+// initHooksTracing registers a tracer to all app hooks that creates
+// a child span (named after the hook method, eg. "OnModelBeforeCreate")
+// on every Trigger call with a recording span in the event context.
+func (app *BaseApp) initHooksTracing() {
+	appValue := reflect.ValueOf(app)
+	appType := appValue.Type()
+
+	for i := 0; i < appType.NumMethod(); i++ {
+		name := appType.Method(i).Name
+		if !strings.HasPrefix(name, "On") {
+			continue
+		}
+
+		method := appValue.Method(i)
+
+		// accept only methods without arguments or with optional tags
+		methodType := method.Type()
+		if methodType.NumOut() != 1 || (methodType.NumIn() != 0 && !(methodType.NumIn() == 1 && methodType.IsVariadic())) {
+			continue
+		}
+
+		traceable, ok := method.Call(nil)[0].Interface().(interface{ SetTracer(hook.TriggerTracer) })
+		if !ok {
+			continue
+		}
+
+		traceable.SetTracer(app.hookTracer(name))
+	}
+}
+
+func (app *BaseApp) hookTracer(name string) hook.TriggerTracer {
+	return func(data any) func(err error) {
+		ctx := eventContext(data)
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return func(err error) {}
+		}
+
+		_, span := app.tracer.Start(ctx, name)
+
+		return func(err error) {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	}
+}
+
+// eventContext extracts the context of the hook event data
+// from its HttpContext request or Dao (if any).
+func eventContext(data any) context.Context {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return context.Background()
+	}
+
+	if f := v.FieldByName("HttpContext"); f.IsValid() && f.CanInterface() {
+		if c, _ := f.Interface().(echo.Context); c != nil && c.Request() != nil {
+			return c.Request().Context()
+		}
+	}
+
+	if f := v.FieldByName("Dao"); f.IsValid() && f.CanInterface() {
+		if dao, _ := f.Interface().(*daos.Dao); dao != nil && dao.Context() != nil {
+			return dao.Context()
+		}
+	}
+
+	return context.Background()
+}
+
+// initDBTracing wraps the db query and exec log functions to create
+// a child span for every executed statement with a recording span in
+// the query context.
+//
+// By default only the statement "db.operation" (eg. "SELECT") is exported.
+// The "db.statement" span attribute with the raw statement and its bound
+// params (similar to the dev mode logs) is exported only if
+// BaseAppConfig.TraceDBStatements is enabled.
+func (app *BaseApp) initDBTracing(dbs ...*dbx.DB) {
+	for _, db := range dbs {
+		queryLogFunc := db.QueryLogFunc
+		db.QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+			app.traceDBStatement(ctx, "db.query", t, sql, err)
+
+			if queryLogFunc != nil {
+				queryLogFunc(ctx, t, sql, rows, err)
+			}
+		}
+
+		execLogFunc := db.ExecLogFunc
+		db.ExecLogFunc = func(ctx context.Context, t time.Duration, sql string, result sql.Result, err error) {
+			app.traceDBStatement(ctx, "db.exec", t, sql, err)
+
+			if execLogFunc != nil {
+				execLogFunc(ctx, t, sql, result, err)
+			}
+		}
+	}
+}
+
+func (app *BaseApp) traceDBStatement(ctx context.Context, name string, t time.Duration, statement string, err error) {
+	if ctx == nil || !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+
+	// the log functions are called after the statement execution
+	end := time.Now()
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "sqlite"),
+		attribute.String("db.operation", dbOperation(statement)),
+	}
+
+	if app.traceDBStatements {
+		attrs = append(attrs, attribute.String("db.statement", statement))
+	}
+
+	_, span := app.tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-t)),
+		trace.WithAttributes(attrs...),
+	)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
+
+// dbOperation returns the uppercased first keyword of the provided
+// sql statement (eg. "SELECT", "INSERT", "PRAGMA").
+func dbOperation(statement string) string {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(fields[0])
+}
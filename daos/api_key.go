@@ -0,0 +1,65 @@
+package daos
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// ApiKeyQuery returns a new ApiKey select query.
+func (dao *Dao) ApiKeyQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.ApiKey{})
+}
+
+// FindApiKeyById finds the ApiKey model by its id.
+func (dao *Dao) FindApiKeyById(id string) (*models.ApiKey, error) {
+	model := &models.ApiKey{}
+
+	err := dao.ApiKeyQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindApiKeyByKey finds the non-expired ApiKey model
+// matching the provided plain api key.
+func (dao *Dao) FindApiKeyByKey(key string) (*models.ApiKey, error) {
+	model := &models.ApiKey{}
+
+	err := dao.ApiKeyQuery().
+		AndWhere(dbx.HashExp{"keyHash": security.SHA256(key)}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if model.IsExpired() {
+		return nil, errors.New("the api key has expired")
+	}
+
+	return model, nil
+}
+
+// SaveApiKey upserts the provided ApiKey model.
+func (dao *Dao) SaveApiKey(model *models.ApiKey) error {
+	if model.Name == "" || model.KeyHash == "" {
+		return errors.New("Missing required ApiKey fields.")
+	}
+
+	return dao.Save(model)
+}
+
+// DeleteApiKey deletes the provided ApiKey model.
+func (dao *Dao) DeleteApiKey(model *models.ApiKey) error {
+	return dao.Delete(model)
+}
@@ -0,0 +1,94 @@
+package daos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestApiKeyQuery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_apiKeys}}.* FROM `_apiKeys`"
+
+	sql := app.Dao().ApiKeyQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestSaveApiKeyValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []*models.ApiKey{
+		{KeyHash: "a"},
+		{Name: "a"},
+	}
+
+	for i, apiKey := range scenarios {
+		if err := app.Dao().SaveApiKey(apiKey); err == nil {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestFindApiKeys(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	past, err := types.ParseDateTime(time.Now().Add(-1 * time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	future, err := types.ParseDateTime(time.Now().Add(1 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active := &models.ApiKey{Name: "active", KeyHash: security.SHA256("pb_key_active"), Expires: future}
+	expired := &models.ApiKey{Name: "expired", KeyHash: security.SHA256("pb_key_expired"), Expires: past}
+	for _, apiKey := range []*models.ApiKey{active, expired} {
+		if err := app.Dao().SaveApiKey(apiKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := app.Dao().FindApiKeyById(expired.Id)
+	if err != nil || found.Name != "expired" {
+		t.Fatalf("Expected api key %q, got %v (%v)", expired.Id, found, err)
+	}
+
+	found, err = app.Dao().FindApiKeyByKey("pb_key_active")
+	if err != nil || found.Id != active.Id {
+		t.Fatalf("Expected api key %q, got %v (%v)", active.Id, found, err)
+	}
+
+	if _, err := app.Dao().FindApiKeyByKey("pb_key_expired"); err == nil {
+		t.Fatal("Expected the expired api key to not be found")
+	}
+
+	if _, err := app.Dao().FindApiKeyByKey("pb_key_missing"); err == nil {
+		t.Fatal("Expected the missing api key to not be found")
+	}
+
+	if err := app.Dao().DeleteApiKey(active); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.Dao().FindApiKeyByKey("pb_key_active"); err == nil {
+		t.Fatal("Expected the deleted api key to not be found")
+	}
+}
@@ -0,0 +1,58 @@
+package daos
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// AuditLogQuery returns a new AuditLog select query.
+func (dao *Dao) AuditLogQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.AuditLog{})
+}
+
+// FindAuditLogById finds a single AuditLog entry by its id.
+func (dao *Dao) FindAuditLogById(id string) (*models.AuditLog, error) {
+	model := &models.AuditLog{}
+
+	err := dao.AuditLogQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindAuditLogsByRecord returns all AuditLog entries of a single
+// record ordered by their creation date (oldest first).
+func (dao *Dao) FindAuditLogsByRecord(collectionId string, recordId string) ([]*models.AuditLog, error) {
+	logs := []*models.AuditLog{}
+
+	err := dao.AuditLogQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": collectionId,
+			"recordId":     recordId,
+		}).
+		OrderBy("created ASC", "rowid ASC").
+		All(&logs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// SaveAuditLog upserts the provided AuditLog model.
+func (dao *Dao) SaveAuditLog(model *models.AuditLog) error {
+	if model.CollectionId == "" || model.RecordId == "" || model.Action == "" {
+		return errors.New("Missing required AuditLog fields.")
+	}
+
+	return dao.Save(model)
+}
@@ -0,0 +1,82 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestAuditLogQuery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_auditLogs}}.* FROM `_auditLogs`"
+
+	sql := app.Dao().AuditLogQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestSaveAndFindAuditLog(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// missing required fields
+	if err := app.Dao().SaveAuditLog(&models.AuditLog{RecordId: "test"}); err == nil {
+		t.Fatal("Expected error for missing required fields")
+	}
+
+	logs := []*models.AuditLog{
+		{CollectionId: "c1", RecordId: "r1", Action: models.AuditActionCreate},
+		{CollectionId: "c2", RecordId: "r1", Action: models.AuditActionCreate},
+		{
+			CollectionId: "c1",
+			RecordId:     "r1",
+			Action:       models.AuditActionUpdate,
+			ActorType:    models.RequestAuthAdmin,
+			ActorId:      "a1",
+			Ip:           "127.0.0.1",
+			Changes:      types.JsonMap{"title": map[string]any{"old": "a", "new": "b"}},
+		},
+	}
+
+	for i, l := range logs {
+		if err := app.Dao().SaveAuditLog(l); err != nil {
+			t.Fatalf("(%d) Failed to save audit log: %v", i, err)
+		}
+	}
+
+	found, err := app.Dao().FindAuditLogById(logs[2].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Action != models.AuditActionUpdate || found.ActorId != "a1" || found.Ip != "127.0.0.1" {
+		t.Fatalf("Unexpected audit log %v", found)
+	}
+
+	changesRaw, _ := found.Changes.MarshalJSON()
+	if expected := `{"title":{"new":"b","old":"a"}}`; string(changesRaw) != expected {
+		t.Fatalf("Expected changes %s, got %s", expected, changesRaw)
+	}
+
+	recordLogs, err := app.Dao().FindAuditLogsByRecord("c1", "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recordLogs) != 2 {
+		t.Fatalf("Expected 2 record audit logs, got %d", len(recordLogs))
+	}
+
+	if recordLogs[0].Id != logs[0].Id || recordLogs[1].Id != logs[2].Id {
+		t.Fatalf("Unexpected record audit logs order %v", recordLogs)
+	}
+}
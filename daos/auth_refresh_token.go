@@ -0,0 +1,138 @@
+package daos
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ErrAuthRefreshTokenUsed is returned when the refresh token
+// was already exchanged (aka. a possible token replay).
+var ErrAuthRefreshTokenUsed = errors.New("the refresh token was already used")
+
+// AuthRefreshTokenQuery returns a new AuthRefreshToken select query.
+func (dao *Dao) AuthRefreshTokenQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.AuthRefreshToken{})
+}
+
+// FindAuthRefreshTokenByHash returns the AuthRefreshToken model of the
+// specified auth collection and refresh token hash.
+func (dao *Dao) FindAuthRefreshTokenByHash(collection *models.Collection, tokenHash string) (*models.AuthRefreshToken, error) {
+	model := &models.AuthRefreshToken{}
+
+	err := dao.AuthRefreshTokenQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": collection.Id,
+			"tokenHash":    tokenHash,
+		}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindAllAuthRefreshTokensByRecord returns all AuthRefreshToken models
+// linked to the provided auth record.
+func (dao *Dao) FindAllAuthRefreshTokensByRecord(authRecord *models.Record) ([]*models.AuthRefreshToken, error) {
+	refreshTokens := []*models.AuthRefreshToken{}
+
+	err := dao.AuthRefreshTokenQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": authRecord.Collection().Id,
+			"recordId":     authRecord.Id,
+		}).
+		OrderBy("created ASC", "rowid ASC").
+		All(&refreshTokens)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return refreshTokens, nil
+}
+
+// SaveAuthRefreshToken upserts the provided AuthRefreshToken model.
+func (dao *Dao) SaveAuthRefreshToken(model *models.AuthRefreshToken) error {
+	if model.CollectionId == "" || model.RecordId == "" || model.FamilyId == "" || model.TokenHash == "" {
+		return errors.New("Missing required AuthRefreshToken fields.")
+	}
+
+	return dao.Save(model)
+}
+
+// UseAuthRefreshToken marks the provided AuthRefreshToken model as used.
+//
+// Returns [ErrAuthRefreshTokenUsed] if the refresh token was already used.
+func (dao *Dao) UseAuthRefreshToken(model *models.AuthRefreshToken) error {
+	if model.Used {
+		return ErrAuthRefreshTokenUsed
+	}
+
+	// conditional update to prevent concurrent reuse of the same token
+	result, err := dao.NonconcurrentDB().Update(
+		model.TableName(),
+		dbx.Params{
+			"used":    true,
+			"updated": types.NowDateTime().String(),
+		},
+		dbx.HashExp{"id": model.Id, "used": false},
+	).Execute()
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected != 1 {
+		return ErrAuthRefreshTokenUsed
+	}
+
+	model.Used = true
+
+	return nil
+}
+
+// DeleteAuthRefreshTokenFamily deletes all refresh tokens from the specified family.
+func (dao *Dao) DeleteAuthRefreshTokenFamily(familyId string) error {
+	_, err := dao.NonconcurrentDB().Delete(
+		(&models.AuthRefreshToken{}).TableName(),
+		dbx.HashExp{"familyId": familyId},
+	).Execute()
+
+	return err
+}
+
+// DeleteAllAuthRefreshTokensByRecord deletes all refresh tokens
+// of the provided auth record (aka. revokes all refresh token families).
+func (dao *Dao) DeleteAllAuthRefreshTokensByRecord(authRecord *models.Record) error {
+	_, err := dao.NonconcurrentDB().Delete(
+		(&models.AuthRefreshToken{}).TableName(),
+		dbx.HashExp{
+			"collectionId": authRecord.Collection().Id,
+			"recordId":     authRecord.Id,
+		},
+	).Execute()
+
+	return err
+}
+
+// DeleteExpiredAuthRefreshTokensByRecord deletes the expired
+// refresh tokens of the provided auth record.
+func (dao *Dao) DeleteExpiredAuthRefreshTokensByRecord(authRecord *models.Record) error {
+	_, err := dao.NonconcurrentDB().Delete(
+		(&models.AuthRefreshToken{}).TableName(),
+		dbx.And(
+			dbx.HashExp{
+				"collectionId": authRecord.Collection().Id,
+				"recordId":     authRecord.Id,
+			},
+			dbx.NewExp("[[expires]] <= {:now}", dbx.Params{"now": types.NowDateTime().String()}),
+		),
+	).Execute()
+
+	return err
+}
@@ -0,0 +1,243 @@
+package daos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func createTestAuthRefreshToken(t *testing.T, dao *daos.Dao, record *models.Record, familyId string, tokenHash string, expires time.Time) *models.AuthRefreshToken {
+	expiresDate, err := types.ParseDateTime(expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := &models.AuthRefreshToken{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		FamilyId:     familyId,
+		TokenHash:    tokenHash,
+		TokenKeyHash: "test",
+		Expires:      expiresDate,
+	}
+
+	if err := dao.SaveAuthRefreshToken(model); err != nil {
+		t.Fatal(err)
+	}
+
+	return model
+}
+
+func TestAuthRefreshTokenQuery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_authRefreshTokens}}.* FROM `_authRefreshTokens`"
+
+	sql := app.Dao().AuthRefreshTokenQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestSaveAuthRefreshTokenValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []*models.AuthRefreshToken{
+		{RecordId: "a", FamilyId: "b", TokenHash: "c"},
+		{CollectionId: "a", FamilyId: "b", TokenHash: "c"},
+		{CollectionId: "a", RecordId: "b", TokenHash: "c"},
+		{CollectionId: "a", RecordId: "b", FamilyId: "c"},
+	}
+
+	for i, model := range scenarios {
+		if err := app.Dao().SaveAuthRefreshToken(model); err == nil {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestFindAuthRefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().Add(1 * time.Hour)
+
+	createTestAuthRefreshToken(t, app.Dao(), user1, "family1", "hash1", future)
+	createTestAuthRefreshToken(t, app.Dao(), user1, "family1", "hash2", future)
+	createTestAuthRefreshToken(t, app.Dao(), user2, "family2", "hash3", future)
+
+	tokens, err := app.Dao().FindAllAuthRefreshTokensByRecord(user1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens[0].TokenHash != "hash1" || tokens[1].TokenHash != "hash2" {
+		t.Fatalf("Expected hash1 and hash2 refresh tokens, got %v", tokens)
+	}
+
+	found, err := app.Dao().FindAuthRefreshTokenByHash(user2.Collection(), "hash3")
+	if err != nil || found.RecordId != user2.Id {
+		t.Fatalf("Expected refresh token of %q, got %v (%v)", user2.Id, found, err)
+	}
+
+	clients, err := app.Dao().FindCollectionByNameOrId("clients")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.Dao().FindAuthRefreshTokenByHash(clients, "hash3"); err == nil {
+		t.Fatal("Expected the refresh token of another collection to not be found")
+	}
+}
+
+func TestUseAuthRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := createTestAuthRefreshToken(t, app.Dao(), record, "family1", "hash1", time.Now().Add(1*time.Hour))
+
+	// stale copy of the same refresh token
+	stale, err := app.Dao().FindAuthRefreshTokenByHash(record.Collection(), "hash1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Dao().UseAuthRefreshToken(model); err != nil {
+		t.Fatalf("Expected the first use to succeed, got %v", err)
+	}
+
+	if !model.Used {
+		t.Fatal("Expected the model to be marked as used")
+	}
+
+	if err := app.Dao().UseAuthRefreshToken(model); !errors.Is(err, daos.ErrAuthRefreshTokenUsed) {
+		t.Fatalf("Expected ErrAuthRefreshTokenUsed, got %v", err)
+	}
+
+	if err := app.Dao().UseAuthRefreshToken(stale); !errors.Is(err, daos.ErrAuthRefreshTokenUsed) {
+		t.Fatalf("Expected ErrAuthRefreshTokenUsed for the stale copy, got %v", err)
+	}
+
+	found, err := app.Dao().FindAuthRefreshTokenByHash(record.Collection(), "hash1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found.Used {
+		t.Fatal("Expected the persisted refresh token to be marked as used")
+	}
+}
+
+func TestDeleteAuthRefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().Add(1 * time.Hour)
+	past := time.Now().Add(-1 * time.Minute)
+
+	createTestAuthRefreshToken(t, app.Dao(), user1, "family1", "hash1", future)
+	createTestAuthRefreshToken(t, app.Dao(), user1, "family1", "hash2", past)
+	createTestAuthRefreshToken(t, app.Dao(), user1, "family2", "hash3", future)
+	createTestAuthRefreshToken(t, app.Dao(), user2, "family3", "hash4", past)
+
+	assertHashes := func(record *models.Record, expected ...string) {
+		tokens, err := app.Dao().FindAllAuthRefreshTokensByRecord(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tokens) != len(expected) {
+			t.Fatalf("Expected %d refresh tokens, got %d", len(expected), len(tokens))
+		}
+
+		for i, token := range tokens {
+			if token.TokenHash != expected[i] {
+				t.Fatalf("Expected refresh token %q, got %q", expected[i], token.TokenHash)
+			}
+		}
+	}
+
+	if err := app.Dao().DeleteExpiredAuthRefreshTokensByRecord(user1); err != nil {
+		t.Fatal(err)
+	}
+	assertHashes(user1, "hash1", "hash3")
+	assertHashes(user2, "hash4")
+
+	if err := app.Dao().DeleteAuthRefreshTokenFamily("family1"); err != nil {
+		t.Fatal(err)
+	}
+	assertHashes(user1, "hash3")
+	assertHashes(user2, "hash4")
+
+	if err := app.Dao().DeleteAllAuthRefreshTokensByRecord(user1); err != nil {
+		t.Fatal(err)
+	}
+	assertHashes(user1)
+	assertHashes(user2, "hash4")
+}
+
+func TestDeleteRecordWithAuthRefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createTestAuthRefreshToken(t, app.Dao(), record, "family1", "hash1", time.Now().Add(1*time.Hour))
+
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := app.Dao().FindAllAuthRefreshTokensByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 0 {
+		t.Fatalf("Expected the refresh tokens to be deleted with the record, got %d", len(tokens))
+	}
+}
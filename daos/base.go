@@ -4,12 +4,15 @@
 package daos
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
 // New creates a new Dao instance with the provided db builder
@@ -42,9 +45,21 @@ type Dao struct {
 
 	// ModelQueryTimeout is the default max duration of a running ModelQuery().
 	//
-	// This field has no effect if an explicit query context is already specified.
+	// This field has no effect if the query context already has a deadline.
 	ModelQueryTimeout time.Duration
 
+	// EncryptionKey is the 32 chars AES key used to encrypt and
+	// decrypt the records encrypted fields values.
+	//
+	// Reading or writing a non-empty encrypted field value without
+	// an encryption key fails with [ErrMissingEncryptionKey].
+	EncryptionKey string
+
+	// OldEncryptionKeys is an optional list of previous encryption keys
+	// that are used only for decrypting the values that are not yet
+	// re-encrypted with [Dao.EncryptionKey] (eg. during key rotation).
+	OldEncryptionKeys []string
+
 	// write hooks
 	BeforeCreateFunc func(eventDao *Dao, m models.Model, action func() error) error
 	AfterCreateFunc  func(eventDao *Dao, m models.Model) error
@@ -52,6 +67,19 @@ type Dao struct {
 	AfterUpdateFunc  func(eventDao *Dao, m models.Model) error
 	BeforeDeleteFunc func(eventDao *Dao, m models.Model, action func() error) error
 	AfterDeleteFunc  func(eventDao *Dao, m models.Model) error
+
+	// soft delete hooks (m is always *models.Record)
+	AfterTrashFunc   func(eventDao *Dao, m models.Model) error
+	AfterRestoreFunc func(eventDao *Dao, m models.Model) error
+
+	// afterCommitFunc registers a function to be called after the
+	// transaction commit (nil if the dao is not in a transaction).
+	afterCommitFunc func(fn func())
+
+	// optional read replica (see WithReadReplica)
+	replica      *ReadReplica
+	replicaDB    dbx.Builder
+	primaryReads *atomic.Bool
 }
 
 // DB returns the default dao db builder (*dbx.DB or *dbx.TX).
@@ -84,6 +112,35 @@ func (dao *Dao) Clone() *Dao {
 	return &clone
 }
 
+// WithContext returns a new Dao with the same configuration options
+// as the current one, but with db queries (including the transactions
+// started from it) bound to the provided context.
+//
+// Note that ctx is applied only to *dbx.DB builders and transaction
+// daos are returned as cloned copies without changes.
+func (dao *Dao) WithContext(ctx context.Context) *Dao {
+	clone := dao.Clone()
+
+	if db, ok := dao.concurrentDB.(*dbx.DB); ok {
+		clone.concurrentDB = db.WithContext(ctx)
+	}
+
+	if db, ok := dao.nonconcurrentDB.(*dbx.DB); ok {
+		clone.nonconcurrentDB = db.WithContext(ctx)
+	}
+
+	if db, ok := dao.replicaDB.(*dbx.DB); ok {
+		clone.replicaDB = db.WithContext(ctx)
+	}
+
+	return clone
+}
+
+// Context returns the context associated with the dao db queries (if any).
+func (dao *Dao) Context() context.Context {
+	return dao.DB().NewQuery("").Context()
+}
+
 // WithoutHooks returns a new Dao with the same configuration options
 // as the current one, but without create/update/delete hooks.
 func (dao *Dao) WithoutHooks() *Dao {
@@ -95,6 +152,8 @@ func (dao *Dao) WithoutHooks() *Dao {
 	clone.AfterUpdateFunc = nil
 	clone.BeforeDeleteFunc = nil
 	clone.AfterDeleteFunc = nil
+	clone.AfterTrashFunc = nil
+	clone.AfterRestoreFunc = nil
 
 	return clone
 }
@@ -104,7 +163,7 @@ func (dao *Dao) WithoutHooks() *Dao {
 func (dao *Dao) ModelQuery(m models.Model) *dbx.SelectQuery {
 	tableName := m.TableName()
 
-	return dao.DB().
+	return dao.ReadDB().
 		Select("{{" + tableName + "}}.*").
 		From(tableName).
 		WithBuildHook(func(query *dbx.Query) {
@@ -124,10 +183,30 @@ type afterCallGroup struct {
 	Action   string
 }
 
+// AfterCommit registers fn to be called after the successful commit of
+// the current dao transaction (eg. to remove storage files only after the
+// db changes referencing them are persisted).
+//
+// If the transaction or the savepoint in which fn was registered is
+// rolled back, fn is never called.
+//
+// If the dao is not in a transaction started with [Dao.RunInTransaction],
+// fn is called immediately.
+func (dao *Dao) AfterCommit(fn func()) {
+	if dao.afterCommitFunc == nil {
+		fn()
+		return
+	}
+
+	dao.afterCommitFunc(fn)
+}
+
 // RunInTransaction wraps fn into a transaction.
 //
 // It is safe to nest RunInTransaction calls as long as you use the txDao.
 func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
+	dao.MarkWrite()
+
 	switch txOrDB := dao.NonconcurrentDB().(type) {
 	case *dbx.Tx:
 		// nested transactions are not supported by default
@@ -137,19 +216,27 @@ func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 		txDao := New(txOrDB)
 		txDao.MaxLockRetries = dao.MaxLockRetries
 		txDao.ModelQueryTimeout = dao.ModelQueryTimeout
+		txDao.EncryptionKey = dao.EncryptionKey
+		txDao.OldEncryptionKeys = dao.OldEncryptionKeys
 		txDao.BeforeCreateFunc = dao.BeforeCreateFunc
 		txDao.BeforeUpdateFunc = dao.BeforeUpdateFunc
 		txDao.BeforeDeleteFunc = dao.BeforeDeleteFunc
 		txDao.AfterCreateFunc = dao.AfterCreateFunc
 		txDao.AfterUpdateFunc = dao.AfterUpdateFunc
 		txDao.AfterDeleteFunc = dao.AfterDeleteFunc
+		txDao.AfterTrashFunc = dao.AfterTrashFunc
+		txDao.AfterRestoreFunc = dao.AfterRestoreFunc
+		txDao.afterCommitFunc = dao.afterCommitFunc
 
 		return fn(txDao)
 	case *dbx.DB:
 		afterCalls := []afterCallGroup{}
+		afterCommitCalls := []func(){}
 
 		txError := txOrDB.Transactional(func(tx *dbx.Tx) error {
 			txDao := New(tx)
+			txDao.EncryptionKey = dao.EncryptionKey
+			txDao.OldEncryptionKeys = dao.OldEncryptionKeys
 
 			if dao.BeforeCreateFunc != nil {
 				txDao.BeforeCreateFunc = func(eventDao *Dao, m models.Model, action func() error) error {
@@ -185,6 +272,21 @@ func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 					return nil
 				}
 			}
+			if dao.AfterTrashFunc != nil {
+				txDao.AfterTrashFunc = func(eventDao *Dao, m models.Model) error {
+					afterCalls = append(afterCalls, afterCallGroup{m, eventDao, "trash"})
+					return nil
+				}
+			}
+			if dao.AfterRestoreFunc != nil {
+				txDao.AfterRestoreFunc = func(eventDao *Dao, m models.Model) error {
+					afterCalls = append(afterCalls, afterCallGroup{m, eventDao, "restore"})
+					return nil
+				}
+			}
+			txDao.afterCommitFunc = func(fn func()) {
+				afterCommitCalls = append(afterCommitCalls, fn)
+			}
 
 			return fn(txDao)
 		})
@@ -204,12 +306,22 @@ func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 				err = dao.AfterUpdateFunc(dao, call.Model)
 			case "delete":
 				err = dao.AfterDeleteFunc(dao, call.Model)
+			case "trash":
+				err = dao.AfterTrashFunc(dao, call.Model)
+			case "restore":
+				err = dao.AfterRestoreFunc(dao, call.Model)
 			}
 
 			if err != nil {
 				errs = append(errs, err)
 			}
 		}
+
+		// execute the registered after commit calls
+		for _, fn := range afterCommitCalls {
+			fn()
+		}
+
 		if len(errs) > 0 {
 			return fmt.Errorf("after transaction errors: %w", errors.Join(errs...))
 		}
@@ -220,12 +332,125 @@ func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 	return errors.New("failed to start transaction (unknown dao.NonconcurrentDB() instance)")
 }
 
+// RunInSavepoint wraps fn into a nested SQLite savepoint of the
+// current dao transaction.
+//
+// If fn returns an error only the changes made within the savepoint
+// are rolled back, leaving the outer transaction intact.
+//
+// The dao after hooks of the savepoint are forwarded to the current
+// dao only on success (and therefore, for a transaction dao,
+// they are still executed after the outer transaction commit).
+//
+// If the dao is not in a transaction, it fallbacks to [Dao.RunInTransaction].
+func (dao *Dao) RunInSavepoint(fn func(txDao *Dao) error) error {
+	tx, ok := dao.NonconcurrentDB().(*dbx.Tx)
+	if !ok {
+		return dao.RunInTransaction(fn)
+	}
+
+	savepoint := "sp_" + security.PseudorandomString(10)
+
+	if _, err := tx.NewQuery("SAVEPOINT " + savepoint).Execute(); err != nil {
+		return err
+	}
+
+	afterCalls := []afterCallGroup{}
+	afterCommitCalls := []func(){}
+
+	spDao := New(tx)
+	spDao.MaxLockRetries = dao.MaxLockRetries
+	spDao.ModelQueryTimeout = dao.ModelQueryTimeout
+	spDao.EncryptionKey = dao.EncryptionKey
+	spDao.OldEncryptionKeys = dao.OldEncryptionKeys
+	spDao.BeforeCreateFunc = dao.BeforeCreateFunc
+	spDao.BeforeUpdateFunc = dao.BeforeUpdateFunc
+	spDao.BeforeDeleteFunc = dao.BeforeDeleteFunc
+	if dao.AfterCreateFunc != nil {
+		spDao.AfterCreateFunc = func(eventDao *Dao, m models.Model) error {
+			afterCalls = append(afterCalls, afterCallGroup{m, eventDao, "create"})
+			return nil
+		}
+	}
+	if dao.AfterUpdateFunc != nil {
+		spDao.AfterUpdateFunc = func(eventDao *Dao, m models.Model) error {
+			afterCalls = append(afterCalls, afterCallGroup{m, eventDao, "update"})
+			return nil
+		}
+	}
+	if dao.AfterDeleteFunc != nil {
+		spDao.AfterDeleteFunc = func(eventDao *Dao, m models.Model) error {
+			afterCalls = append(afterCalls, afterCallGroup{m, eventDao, "delete"})
+			return nil
+		}
+	}
+	if dao.AfterTrashFunc != nil {
+		spDao.AfterTrashFunc = func(eventDao *Dao, m models.Model) error {
+			afterCalls = append(afterCalls, afterCallGroup{m, eventDao, "trash"})
+			return nil
+		}
+	}
+	if dao.AfterRestoreFunc != nil {
+		spDao.AfterRestoreFunc = func(eventDao *Dao, m models.Model) error {
+			afterCalls = append(afterCalls, afterCallGroup{m, eventDao, "restore"})
+			return nil
+		}
+	}
+	spDao.afterCommitFunc = func(fn func()) {
+		afterCommitCalls = append(afterCommitCalls, fn)
+	}
+
+	if fnErr := fn(spDao); fnErr != nil {
+		_, rollbackErr := tx.NewQuery("ROLLBACK TO SAVEPOINT " + savepoint).Execute()
+		_, releaseErr := tx.NewQuery("RELEASE SAVEPOINT " + savepoint).Execute()
+		if err := errors.Join(rollbackErr, releaseErr); err != nil {
+			return fmt.Errorf("savepoint rollback failure: %w (original error: %w)", err, fnErr)
+		}
+		return fnErr
+	}
+
+	if _, err := tx.NewQuery("RELEASE SAVEPOINT " + savepoint).Execute(); err != nil {
+		return err
+	}
+
+	// forward the savepoint after event calls
+	var errs []error
+	for _, call := range afterCalls {
+		var err error
+		switch call.Action {
+		case "create":
+			err = dao.AfterCreateFunc(call.EventDao, call.Model)
+		case "update":
+			err = dao.AfterUpdateFunc(call.EventDao, call.Model)
+		case "delete":
+			err = dao.AfterDeleteFunc(call.EventDao, call.Model)
+		case "trash":
+			err = dao.AfterTrashFunc(call.EventDao, call.Model)
+		case "restore":
+			err = dao.AfterRestoreFunc(call.EventDao, call.Model)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// forward the savepoint after commit calls
+	for _, fn := range afterCommitCalls {
+		dao.AfterCommit(fn)
+	}
+
+	return errors.Join(errs...)
+}
+
 // Delete deletes the provided model.
 func (dao *Dao) Delete(m models.Model) error {
 	if !m.HasId() {
 		return errors.New("ID is not set")
 	}
 
+	dao.MarkWrite()
+
 	return dao.lockRetry(func(retryDao *Dao) error {
 		action := func() error {
 			if err := retryDao.NonconcurrentDB().Model(m).Delete(); err != nil {
@@ -252,6 +477,8 @@ func (dao *Dao) Delete(m models.Model) error {
 // If m.IsNew() is true, the method will perform a create, otherwise an update.
 // To explicitly mark a model for update you can use m.MarkAsNotNew().
 func (dao *Dao) Save(m models.Model) error {
+	dao.MarkWrite()
+
 	if m.IsNew() {
 		return dao.lockRetry(func(retryDao *Dao) error {
 			return retryDao.create(m)
@@ -278,6 +505,12 @@ func (dao *Dao) update(m models.Model) error {
 		if v, ok := any(m).(models.ColumnValueMapper); ok {
 			dataMap := v.ColumnValueMap()
 
+			if record, ok := m.(*models.Record); ok {
+				if err := dao.encryptRecordColumns(record.Collection(), dataMap); err != nil {
+					return err
+				}
+			}
+
 			_, err := dao.NonconcurrentDB().Update(
 				m.TableName(),
 				dataMap,
@@ -329,6 +562,12 @@ func (dao *Dao) create(m models.Model) error {
 				dataMap["id"] = m.GetId()
 			}
 
+			if record, ok := m.(*models.Record); ok {
+				if err := dao.encryptRecordColumns(record.Collection(), dataMap); err != nil {
+					return err
+				}
+			}
+
 			_, err := dao.NonconcurrentDB().Insert(m.TableName(), dataMap).Execute()
 			if err != nil {
 				return err
@@ -365,6 +604,7 @@ func (dao *Dao) lockRetry(op func(retryDao *Dao) error) error {
 			retryDao.AfterCreateFunc = dao.AfterCreateFunc
 			retryDao.AfterUpdateFunc = dao.AfterUpdateFunc
 			retryDao.AfterDeleteFunc = dao.AfterDeleteFunc
+			retryDao.afterCommitFunc = dao.afterCommitFunc
 		}
 
 		return op(retryDao)
@@ -16,12 +16,17 @@ var defaultRetryIntervals = []int{100, 250, 350, 500, 700, 1000}
 
 func execLockRetry(timeout time.Duration, maxRetries int) dbx.ExecHookFunc {
 	return func(q *dbx.Query, op func() error) error {
-		if q.Context() == nil {
-			cancelCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		if ctx := q.Context(); ctx == nil || !hasDeadline(ctx) {
+			parentCtx := ctx
+			if parentCtx == nil {
+				parentCtx = context.Background()
+			}
+
+			cancelCtx, cancel := context.WithTimeout(parentCtx, timeout)
 			defer func() {
 				cancel()
 				//nolint:staticcheck
-				q.WithContext(nil) // reset
+				q.WithContext(ctx) // reset
 			}()
 			q.WithContext(cancelCtx)
 		}
@@ -37,6 +42,11 @@ func execLockRetry(timeout time.Duration, maxRetries int) dbx.ExecHookFunc {
 	}
 }
 
+func hasDeadline(ctx context.Context) bool {
+	_, ok := ctx.Deadline()
+	return ok
+}
+
 func baseLockRetry(op func(attempt int) error, maxRetries int) error {
 	attempt := 1
 
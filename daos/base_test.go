@@ -1,7 +1,9 @@
 package daos_test
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -195,6 +197,46 @@ func TestDaoWithoutHooks(t *testing.T) {
 	}
 }
 
+func TestDaoWithContext(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "test")
+
+	dao := testApp.Dao()
+	if dao.Context() != nil {
+		t.Fatalf("Expected nil app dao context, got %v", dao.Context())
+	}
+
+	ctxDao := dao.WithContext(ctx)
+	if ctxDao == dao {
+		t.Fatal("Expected a new dao instance")
+	}
+
+	if dao.Context() != nil {
+		t.Fatal("Expected the original dao context to remain unchanged")
+	}
+
+	if ctxDao.Context() != ctx {
+		t.Fatalf("Expected dao context %v, got %v", ctx, ctxDao.Context())
+	}
+
+	if ctxDao.BeforeCreateFunc == nil || ctxDao.ModelQueryTimeout != dao.ModelQueryTimeout {
+		t.Fatal("Expected the dao configuration options to be copied")
+	}
+
+	err := ctxDao.RunInTransaction(func(txDao *daos.Dao) error {
+		if txDao.Context() != ctx {
+			t.Fatalf("Expected tx dao context %v, got %v", ctx, txDao.Context())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestDaoModelQuery(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -243,6 +285,11 @@ func TestDaoModelQueryCancellation(t *testing.T) {
 	if err := dao.ModelQuery(m).One(m); err == nil {
 		t.Fatal("Expected to be cancelled, got nil")
 	}
+
+	// the timeout must be applied also for contexts without deadline
+	if err := dao.WithContext(context.Background()).ModelQuery(m).One(m); err == nil {
+		t.Fatal("Expected the context query to be cancelled, got nil")
+	}
 }
 
 func TestDaoFindById(t *testing.T) {
@@ -325,6 +372,133 @@ func TestDaoRunInTransaction(t *testing.T) {
 	}
 }
 
+func TestDaoRunInSavepoint(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	afterDeleteCalls := 0
+	testApp.Dao().AfterDeleteFunc = func(eventDao *daos.Dao, m models.Model) error {
+		afterDeleteCalls++
+		return nil
+	}
+
+	txErr := testApp.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		// failed savepoint
+		spErr := txDao.RunInSavepoint(func(spDao *daos.Dao) error {
+			admin, _ := spDao.FindAdminByEmail("test@example.com")
+			if err := spDao.DeleteAdmin(admin); err != nil {
+				t.Fatal(err)
+			}
+			return errors.New("test error")
+		})
+		if spErr == nil || spErr.Error() != "test error" {
+			t.Fatalf("Expected the savepoint fn error, got %v", spErr)
+		}
+
+		// the savepoint changes should be rolled back
+		if admin, _ := txDao.FindAdminByEmail("test@example.com"); admin == nil {
+			t.Fatal("Expected admin test@example.com to not be deleted")
+		}
+
+		// successful savepoint
+		return txDao.RunInSavepoint(func(spDao *daos.Dao) error {
+			admin, _ := spDao.FindAdminByEmail("test2@example.com")
+			return spDao.DeleteAdmin(admin)
+		})
+	})
+	if txErr != nil {
+		t.Fatal(txErr)
+	}
+
+	if admin, _ := testApp.Dao().FindAdminByEmail("test@example.com"); admin == nil {
+		t.Fatal("Expected admin test@example.com to not be deleted")
+	}
+
+	if admin, _ := testApp.Dao().FindAdminByEmail("test2@example.com"); admin != nil {
+		t.Fatal("Expected admin test2@example.com to be deleted")
+	}
+
+	// only the successful savepoint after hooks should be called
+	if afterDeleteCalls != 1 {
+		t.Fatalf("Expected 1 AfterDeleteFunc call, got %d", afterDeleteCalls)
+	}
+
+	// fallback to a regular transaction
+	testApp.Dao().RunInSavepoint(func(txDao *daos.Dao) error {
+		admin, _ := txDao.FindAdminByEmail("test@example.com")
+		if err := txDao.DeleteAdmin(admin); err != nil {
+			t.Fatal(err)
+		}
+		return errors.New("test error")
+	})
+
+	if admin, _ := testApp.Dao().FindAdminByEmail("test@example.com"); admin == nil {
+		t.Fatal("Expected admin test@example.com to not be deleted")
+	}
+}
+
+func TestDaoAfterCommit(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	calls := []string{}
+
+	// non-transaction dao
+	testApp.Dao().AfterCommit(func() {
+		calls = append(calls, "immediate")
+	})
+
+	// rolled back transaction
+	testApp.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		txDao.AfterCommit(func() {
+			calls = append(calls, "rollback")
+		})
+		return errors.New("test error")
+	})
+
+	// committed transaction with nested transaction and savepoints
+	txErr := testApp.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		txDao.AfterCommit(func() {
+			calls = append(calls, "tx")
+		})
+
+		txDao.RunInTransaction(func(tx2Dao *daos.Dao) error {
+			tx2Dao.AfterCommit(func() {
+				calls = append(calls, "nested")
+			})
+			return nil
+		})
+
+		txDao.RunInSavepoint(func(spDao *daos.Dao) error {
+			spDao.AfterCommit(func() {
+				calls = append(calls, "savepoint_rollback")
+			})
+			return errors.New("test error")
+		})
+
+		txDao.RunInSavepoint(func(spDao *daos.Dao) error {
+			spDao.AfterCommit(func() {
+				calls = append(calls, "savepoint")
+			})
+			return nil
+		})
+
+		if len(calls) != 1 {
+			t.Fatalf("Expected the tx after commit calls to be deferred, got %v", calls)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		t.Fatal(txErr)
+	}
+
+	expected := "immediate,tx,nested,savepoint"
+	if str := strings.Join(calls, ","); str != expected {
+		t.Fatalf("Expected calls %q, got %q", expected, str)
+	}
+}
+
 func TestDaoSaveCreate(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
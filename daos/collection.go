@@ -10,7 +10,10 @@ import (
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 // CollectionQuery returns a new Collection select query.
@@ -148,6 +151,10 @@ func (dao *Dao) DeleteCollection(collection *models.Collection) error {
 			if err := txDao.DeleteTable(collection.Name); err != nil {
 				return err
 			}
+
+			if err := txDao.DeleteTable(collection.FullTextSearchTableName()); err != nil {
+				return err
+			}
 		}
 
 		// trigger views resave to check for dependencies
@@ -297,6 +304,10 @@ func (dao *Dao) ImportCollections(
 					if err := txDao.DeleteTable(existing.Name); err != nil {
 						return err
 					}
+
+					if err := txDao.DeleteTable(existing.FullTextSearchTableName()); err != nil {
+						return err
+					}
 				}
 
 				// delete the collection
@@ -349,6 +360,108 @@ func (dao *Dao) ImportCollections(
 	})
 }
 
+// DuplicateCollectionOptions defines the [Dao.DuplicateCollection] options.
+type DuplicateCollectionOptions struct {
+	// Name is the name of the new collection.
+	Name string
+
+	// WithData indicates whether to copy also the source collection
+	// records (not applicable for view collections).
+	WithData bool
+
+	// SelfRelationsToClone indicates whether the relation fields that
+	// point to the source collection should point to the new collection
+	// (by default they continue pointing to the source collection).
+	SelfRelationsToClone bool
+}
+
+// DuplicateCollection creates a new collection with the same definition as
+// the source collection (with regenerated id, schema field ids and index names)
+// and optionally copies its records within a single transaction.
+//
+// NB! This method doesn't perform validations on the new collection name
+// and doesn't copy the records files.
+// If you need validations and files copy, use [forms.CollectionDuplicate].
+func (dao *Dao) DuplicateCollection(source *models.Collection, options DuplicateCollectionOptions) (*models.Collection, error) {
+	if options.Name == "" {
+		return nil, errors.New("missing new collection name")
+	}
+
+	// deep copy the source collection
+	raw, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &models.Collection{}
+	if err := json.Unmarshal(raw, clone); err != nil {
+		return nil, err
+	}
+
+	clone.MarkAsNew()
+	clone.RefreshId()
+	clone.Name = options.Name
+	clone.System = false
+	clone.Created = types.DateTime{}
+	clone.Updated = types.DateTime{}
+
+	for _, field := range clone.Schema.Fields() {
+		field.Id = strings.ToLower(security.PseudorandomString(8))
+
+		relOptions, _ := field.Options.(*schema.RelationOptions)
+		if relOptions != nil && relOptions.CollectionId == source.Id && options.SelfRelationsToClone {
+			relOptions.CollectionId = clone.Id
+		}
+	}
+
+	for i, rawIndex := range clone.Indexes {
+		parsed := dbutils.ParseIndex(rawIndex)
+		if !parsed.IsValid() {
+			return nil, fmt.Errorf("failed to parse index %q", rawIndex)
+		}
+
+		parsed.IndexName = "idx_" + security.PseudorandomString(10)
+		parsed.TableName = clone.Name
+		clone.Indexes[i] = parsed.Build()
+	}
+
+	txErr := dao.RunInTransaction(func(txDao *Dao) error {
+		if err := txDao.SaveCollection(clone); err != nil {
+			return err
+		}
+
+		if !options.WithData || clone.IsView() {
+			return nil
+		}
+
+		columns, err := txDao.TableColumns(source.Name)
+		if err != nil {
+			return err
+		}
+
+		quotedColumns := make([]string, len(columns))
+		for i, column := range columns {
+			quotedColumns[i] = txDao.DB().QuoteSimpleColumnName(column)
+		}
+		columnsList := strings.Join(quotedColumns, ",")
+
+		_, err = txDao.DB().NewQuery(fmt.Sprintf(
+			"INSERT INTO %s (%s) SELECT %s FROM %s",
+			txDao.DB().QuoteSimpleTableName(clone.Name),
+			columnsList,
+			columnsList,
+			txDao.DB().QuoteSimpleTableName(source.Name),
+		)).Execute()
+
+		return err
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return clone, nil
+}
+
 // saveViewCollection persists the provided View collection changes:
 //   - deletes the old related SQL view (if any)
 //   - creates a new SQL view with the latest newCollection.Options.Query
@@ -415,6 +528,7 @@ func (dao *Dao) normalizeViewQueryId(query string) (string, error) {
 	if idField != nil && idField.field != nil &&
 		idField.field.Type != schema.FieldTypeJson &&
 		idField.field.Type != schema.FieldTypeNumber &&
+		idField.field.Type != schema.FieldTypeDuration &&
 		idField.field.Type != schema.FieldTypeBool {
 		needWrapping = false
 	}
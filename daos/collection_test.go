@@ -811,3 +811,172 @@ func TestImportCollections(t *testing.T) {
 		})
 	}
 }
+
+func TestDuplicateCollection(t *testing.T) {
+	t.Parallel()
+
+	countRecords := func(t *testing.T, dao *daos.Dao, collection *models.Collection) int {
+		var total int
+		if err := dao.RecordQuery(collection).Select("count(*)").Row(&total); err != nil {
+			t.Fatal(err)
+		}
+		return total
+	}
+
+	t.Run("missing name", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		source, err := app.Dao().FindCollectionByNameOrId("demo2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := app.Dao().DuplicateCollection(source, daos.DuplicateCollectionOptions{}); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+
+	t.Run("schema only", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		source, err := app.Dao().FindCollectionByNameOrId("demo2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clone, err := app.Dao().DuplicateCollection(source, daos.DuplicateCollectionOptions{Name: "demo2_clone"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if clone.Id == source.Id || clone.Name != "demo2_clone" || clone.System {
+			t.Fatalf("Unexpected clone collection %v", clone)
+		}
+
+		sourceFields := source.Schema.Fields()
+		cloneFields := clone.Schema.Fields()
+		if len(sourceFields) != len(cloneFields) {
+			t.Fatalf("Expected %d fields, got %d", len(sourceFields), len(cloneFields))
+		}
+		for i, f := range cloneFields {
+			if f.Name != sourceFields[i].Name || f.Type != sourceFields[i].Type {
+				t.Fatalf("Expected field %q to match the source one, got %q", sourceFields[i].Name, f.Name)
+			}
+			if f.Id == sourceFields[i].Id {
+				t.Fatalf("Expected field %q to have a regenerated id", f.Name)
+			}
+		}
+
+		// the source collection should remain unchanged
+		reloadedSource, _ := app.Dao().FindCollectionByNameOrId("demo2")
+		if reloadedSource.Schema.Fields()[0].Id != sourceFields[0].Id {
+			t.Fatal("Expected the source collection schema to remain unchanged")
+		}
+
+		// indexes
+		if len(clone.Indexes) != len(source.Indexes) {
+			t.Fatalf("Expected %d indexes, got %v", len(source.Indexes), clone.Indexes)
+		}
+		for _, idx := range clone.Indexes {
+			if !strings.Contains(idx, "`demo2_clone`") || strings.Contains(idx, "idx_unique_demo2_title") {
+				t.Fatalf("Expected index to be regenerated for the clone table, got %s", idx)
+			}
+		}
+		tableIndexes, err := app.Dao().TableIndexes("demo2_clone")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tableIndexes) != len(source.Indexes) {
+			t.Fatalf("Expected %d table indexes, got %v", len(source.Indexes), tableIndexes)
+		}
+
+		if total := countRecords(t, app.Dao(), clone); total != 0 {
+			t.Fatalf("Expected no records, got %d", total)
+		}
+	})
+
+	t.Run("schema and data", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		source, err := app.Dao().FindCollectionByNameOrId("demo2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clone, err := app.Dao().DuplicateCollection(source, daos.DuplicateCollectionOptions{
+			Name:     "demo2_clone",
+			WithData: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedTotal := countRecords(t, app.Dao(), source)
+		if total := countRecords(t, app.Dao(), clone); total != expectedTotal || total == 0 {
+			t.Fatalf("Expected %d records, got %d", expectedTotal, total)
+		}
+
+		record, err := app.Dao().FindRecordById(clone.Id, "llvuca81nly1qls")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.GetString("title") != "test1" {
+			t.Fatalf("Expected the copied record title %q, got %q", "test1", record.GetString("title"))
+		}
+
+		// the unique index should be recreated
+		duplicate := models.NewRecord(clone)
+		duplicate.Set("title", "test1")
+		if err := app.Dao().SaveRecord(duplicate); err == nil {
+			t.Fatal("Expected unique constraint error")
+		}
+	})
+
+	t.Run("self relations", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		source, err := app.Dao().FindCollectionByNameOrId("demo4")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		scenarios := []struct {
+			name          string
+			toClone       bool
+			expectToClone bool
+		}{
+			{"demo4_clone1", false, false},
+			{"demo4_clone2", true, true},
+		}
+
+		for _, s := range scenarios {
+			clone, err := app.Dao().DuplicateCollection(source, daos.DuplicateCollectionOptions{
+				Name:                 s.name,
+				WithData:             true,
+				SelfRelationsToClone: s.toClone,
+			})
+			if err != nil {
+				t.Fatalf("[%s] %v", s.name, err)
+			}
+
+			selfRel := clone.Schema.GetFieldByName("self_rel_one").Options.(*schema.RelationOptions)
+			expectedId := source.Id
+			if s.expectToClone {
+				expectedId = clone.Id
+			}
+			if selfRel.CollectionId != expectedId {
+				t.Fatalf("[%s] Expected self relation to %q, got %q", s.name, expectedId, selfRel.CollectionId)
+			}
+
+			// non-self relations should remain unchanged
+			otherRel := clone.Schema.GetFieldByName("rel_one_cascade").Options.(*schema.RelationOptions)
+			if otherRel.CollectionId != "wzlqyes4orhoygb" {
+				t.Fatalf("[%s] Expected the demo3 relation to remain unchanged, got %q", s.name, otherRel.CollectionId)
+			}
+		}
+	})
+}
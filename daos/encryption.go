@@ -0,0 +1,206 @@
+package daos
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// keyring returns the runtime keyring that encrypts with [Dao.EncryptionKey]
+// and decrypts with any of [Dao.EncryptionKey] and [Dao.OldEncryptionKeys].
+func (dao *Dao) keyring() *security.Keyring {
+	return security.NewKeyring(dao.EncryptionKey, dao.OldEncryptionKeys...)
+}
+
+// RotateEncryptionKey re-encrypts all stored encrypted data
+// (the app settings, the records encrypted fields, the TOTP secrets
+// and the OAuth2 tokens of the external auths) from oldKey to newKey.
+//
+// Values that are already encrypted with newKey are skipped and the
+// legacy (non-versioned) cipher texts are upgraded to the versioned format.
+//
+// All changes are persisted in a single transaction and the
+// total number of re-encrypted values is returned on success.
+//
+// Note that [Dao.EncryptionKey] is not changed and it is up to the
+// caller to start using newKey once the rotation completes.
+func (dao *Dao) RotateEncryptionKey(oldKey string, newKey string) (int, error) {
+	if oldKey == "" || newKey == "" {
+		return 0, ErrMissingEncryptionKey
+	}
+
+	keyring := security.NewKeyring(newKey, oldKey)
+
+	var total int
+
+	err := dao.RunInTransaction(func(txDao *Dao) error {
+		settingsTotal, err := txDao.reencryptSettings(keyring)
+		if err != nil {
+			return err
+		}
+
+		recordsTotal, err := txDao.reencryptRecords(keyring)
+		if err != nil {
+			return err
+		}
+
+		totpsTotal, err := txDao.reencryptTOTPSecrets(keyring)
+		if err != nil {
+			return err
+		}
+
+		externalAuthsTotal, err := txDao.reencryptExternalAuthTokens(keyring)
+		if err != nil {
+			return err
+		}
+
+		total = settingsTotal + recordsTotal + totpsTotal + externalAuthsTotal
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// reencryptSettings re-encrypts the stored app settings param
+// (if it is encrypted) with the keyring current key.
+func (dao *Dao) reencryptSettings(keyring *security.Keyring) (int, error) {
+	param, err := dao.FindParamByKey(models.ParamAppSettings)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	raw := string(param.Value)
+
+	// plain json or already encrypted with the current key
+	if raw == "" || json.Valid(param.Value) || keyring.IsCurrent(raw) {
+		return 0, nil
+	}
+
+	encrypted, err := reencryptValue(keyring, raw)
+	if err != nil {
+		return 0, fmt.Errorf("failed to re-encrypt the app settings: %w", err)
+	}
+
+	param.Value = types.JsonRaw(encrypted)
+
+	if err := dao.Save(param); err != nil {
+		return 0, err
+	}
+
+	return 1, nil
+}
+
+// reencryptTOTPSecrets re-encrypts the stored TOTP secrets
+// with the keyring current key.
+func (dao *Dao) reencryptTOTPSecrets(keyring *security.Keyring) (int, error) {
+	totps := []*models.TOTP{}
+	if err := dao.TOTPQuery().All(&totps); err != nil {
+		return 0, err
+	}
+
+	var total int
+
+	for _, model := range totps {
+		if model.Secret == "" || keyring.IsCurrent(model.Secret) {
+			continue
+		}
+
+		encrypted, err := reencryptValue(keyring, model.Secret)
+		if err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt TOTP %q: %w", model.Id, err)
+		}
+
+		_, err = dao.DB().Update(
+			model.TableName(),
+			dbx.Params{
+				"secret":  encrypted,
+				"updated": types.NowDateTime().String(),
+			},
+			dbx.HashExp{"id": model.Id},
+		).Execute()
+		if err != nil {
+			return 0, err
+		}
+
+		total++
+	}
+
+	return total, nil
+}
+
+// reencryptExternalAuthTokens re-encrypts the stored external auths
+// OAuth2 tokens with the keyring current key.
+func (dao *Dao) reencryptExternalAuthTokens(keyring *security.Keyring) (int, error) {
+	externalAuths := []*models.ExternalAuth{}
+	err := dao.ExternalAuthQuery().
+		AndWhere(dbx.Or(
+			dbx.Not(dbx.HashExp{"accessToken": ""}),
+			dbx.Not(dbx.HashExp{"refreshToken": ""}),
+		)).
+		All(&externalAuths)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+
+	for _, model := range externalAuths {
+		params := dbx.Params{}
+
+		for name, value := range map[string]string{
+			"accessToken":  model.AccessToken,
+			"refreshToken": model.RefreshToken,
+		} {
+			if value == "" || keyring.IsCurrent(value) {
+				continue
+			}
+
+			encrypted, err := reencryptValue(keyring, value)
+			if err != nil {
+				return 0, fmt.Errorf("failed to re-encrypt the external auth %q %s: %w", model.Id, name, err)
+			}
+
+			params[name] = encrypted
+		}
+
+		if len(params) == 0 {
+			continue
+		}
+
+		params["updated"] = types.NowDateTime().String()
+
+		_, err := dao.DB().Update(model.TableName(), params, dbx.HashExp{"id": model.Id}).Execute()
+		if err != nil {
+			return 0, err
+		}
+
+		total++
+	}
+
+	return total, nil
+}
+
+// reencryptValue decrypts the provided cipher text with any of the
+// keyring keys and encrypts it again with the keyring current key.
+func reencryptValue(keyring *security.Keyring, cipherText string) (string, error) {
+	plain, err := keyring.Decrypt(cipherText)
+	if err != nil {
+		return "", err
+	}
+
+	return keyring.Encrypt(plain)
+}
@@ -0,0 +1,136 @@
+package daos_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestRotateEncryptionKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	oldKey := testEncryptionKey1
+	newKey := testEncryptionKey2
+
+	app.Dao().EncryptionKey = oldKey
+
+	// settings
+	if err := app.Dao().SaveSettings(app.Settings(), oldKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// records
+	collection := createEncryptedTestCollection(t, app)
+
+	ids := []string{}
+	for _, secret := range []string{"secret1", "secret2", ""} {
+		record := models.NewRecord(collection)
+		record.Set("secret", secret)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, record.Id)
+	}
+
+	// downgrade the second record value to the legacy non-versioned format
+	_, legacy, _ := strings.Cut(rawEncryptedTestValue(t, app.Dao(), ids[1]), ":")
+	if _, err := app.Dao().DB().Update("encrypted_test", dbx.Params{"secret": legacy}, dbx.HashExp{"id": ids[1]}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// totp
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	totp := createTestTOTP(t, app.Dao(), user, "TOTPSECRET")
+
+	// external auth
+	externalAuth, err := app.Dao().FindFirstExternalAuthByExpr(dbx.HashExp{"id": "dlmflokuq1xl342"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().UpdateExternalAuthTokens(externalAuth, "access", "refresh", externalAuth.Expiry); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid old key
+	if _, err := app.Dao().RotateEncryptionKey("abcdabcdabcdabcdabcdabcdabcdabce", newKey); err == nil {
+		t.Fatal("Expected error with invalid old key")
+	}
+
+	// nothing should have been changed
+	if _, err := app.Dao().FindSettings(oldKey); err != nil {
+		t.Fatalf("Expected the settings to be still encrypted with the old key, got %v", err)
+	}
+
+	total, err := app.Dao().RotateEncryptionKey(oldKey, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1 settings + 2 records + 1 totp + 1 external auth
+	if total != 5 {
+		t.Fatalf("Expected 5 re-encrypted values, got %d", total)
+	}
+
+	app.Dao().EncryptionKey = newKey
+
+	// settings check
+	if _, err := app.Dao().FindSettings(oldKey); err == nil {
+		t.Fatal("Expected the settings to be no longer decryptable with the old key")
+	}
+	if _, err := app.Dao().FindSettings(newKey); err != nil {
+		t.Fatalf("Expected the settings to be decryptable with the new key, got %v", err)
+	}
+
+	// records check
+	for i, secret := range []string{"secret1", "secret2", ""} {
+		record, err := app.Dao().FindRecordById(collection.Id, ids[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := record.GetString("secret"); v != secret {
+			t.Fatalf("Expected secret %q, got %q", secret, v)
+		}
+
+		if raw := rawEncryptedTestValue(t, app.Dao(), ids[i]); raw != "" && !strings.HasPrefix(raw, security.KeyVersion(newKey)+":") {
+			t.Fatalf("Expected the raw value to be encrypted with the new key version, got %q", raw)
+		}
+	}
+
+	// totp check
+	totp, err = app.Dao().FindTOTPByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := app.Dao().DecryptTOTPSecret(totp); err != nil || secret != "TOTPSECRET" {
+		t.Fatalf("Expected TOTP secret %q, got %q (%v)", "TOTPSECRET", secret, err)
+	}
+
+	// external auth check
+	externalAuth, err = app.Dao().FindFirstExternalAuthByExpr(dbx.HashExp{"id": "dlmflokuq1xl342"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if access, refresh, err := app.Dao().DecryptExternalAuthTokens(externalAuth); err != nil || access != "access" || refresh != "refresh" {
+		t.Fatalf("Expected external auth tokens %q and %q, got %q and %q (%v)", "access", "refresh", access, refresh, err)
+	}
+
+	// already rotated values should be skipped
+	total, err = app.Dao().RotateEncryptionKey(oldKey, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected 0 re-encrypted values, got %d", total)
+	}
+}
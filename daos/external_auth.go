@@ -5,6 +5,7 @@ import (
 
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 // ExternalAuthQuery returns a new ExternalAuth select query.
@@ -82,6 +83,99 @@ func (dao *Dao) SaveExternalAuth(model *models.ExternalAuth) error {
 	return dao.Save(model)
 }
 
+// SetExternalAuthTokens encrypts the provided OAuth2 tokens with
+// [Dao.EncryptionKey] and assigns them to the ExternalAuth model
+// (it is up to the caller to save the model).
+//
+// An empty refreshToken keeps the existing model refresh token
+// since not all providers return a new one on each token exchange.
+//
+// Returns [ErrMissingEncryptionKey] if there is no encryption key
+// because the tokens must not be stored as plain text.
+func (dao *Dao) SetExternalAuthTokens(model *models.ExternalAuth, accessToken string, refreshToken string, expiry types.DateTime) error {
+	if dao.EncryptionKey == "" {
+		return ErrMissingEncryptionKey
+	}
+
+	keyring := dao.keyring()
+
+	encryptedAccess, err := keyring.Encrypt([]byte(accessToken))
+	if err != nil {
+		return err
+	}
+
+	if refreshToken != "" {
+		encryptedRefresh, err := keyring.Encrypt([]byte(refreshToken))
+		if err != nil {
+			return err
+		}
+		model.RefreshToken = encryptedRefresh
+	}
+
+	model.AccessToken = encryptedAccess
+	model.Expiry = expiry
+
+	return nil
+}
+
+// DecryptExternalAuthTokens returns the decrypted plain OAuth2
+// access and refresh tokens of the provided ExternalAuth model.
+func (dao *Dao) DecryptExternalAuthTokens(model *models.ExternalAuth) (accessToken string, refreshToken string, err error) {
+	if model.AccessToken == "" && model.RefreshToken == "" {
+		return "", "", nil
+	}
+
+	if dao.EncryptionKey == "" {
+		return "", "", ErrMissingEncryptionKey
+	}
+
+	keyring := dao.keyring()
+
+	if model.AccessToken != "" {
+		plain, err := keyring.Decrypt(model.AccessToken)
+		if err != nil {
+			return "", "", err
+		}
+		accessToken = string(plain)
+	}
+
+	if model.RefreshToken != "" {
+		plain, err := keyring.Decrypt(model.RefreshToken)
+		if err != nil {
+			return "", "", err
+		}
+		refreshToken = string(plain)
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// UpdateExternalAuthTokens encrypts and persists the provided OAuth2
+// tokens of an existing ExternalAuth model.
+//
+// The model is updated directly in the db (similar to the TOTP
+// last used step) without triggering the model hooks.
+func (dao *Dao) UpdateExternalAuthTokens(model *models.ExternalAuth, accessToken string, refreshToken string, expiry types.DateTime) error {
+	if err := dao.SetExternalAuthTokens(model, accessToken, refreshToken, expiry); err != nil {
+		return err
+	}
+
+	model.RefreshUpdated()
+
+	_, err := dao.NonconcurrentDB().Update(
+		model.TableName(),
+		dbx.Params{
+			"accessToken":  model.AccessToken,
+			"refreshToken": model.RefreshToken,
+			"expiry":       model.Expiry.String(),
+			"updated":      model.Updated.String(),
+		},
+		dbx.HashExp{"id": model.Id},
+	).Execute()
+
+	return err
+}
+
 // DeleteExternalAuth deletes the provided ExternalAuth model.
 func (dao *Dao) DeleteExternalAuth(model *models.ExternalAuth) error {
 	return dao.Delete(model)
@@ -1,11 +1,14 @@
 package daos_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestExternalAuthQuery(t *testing.T) {
@@ -170,6 +173,69 @@ func TestSaveExternalAuth(t *testing.T) {
 	}
 }
 
+func TestExternalAuthTokens(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	model, err := app.Dao().FindFirstExternalAuthByExpr(dbx.HashExp{"id": "dlmflokuq1xl342"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiry, _ := types.ParseDateTime("2030-01-01 00:00:00.000Z")
+
+	// missing encryption key
+	app.Dao().EncryptionKey = ""
+	if err := app.Dao().SetExternalAuthTokens(model, "access", "refresh", expiry); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey, got %v", err)
+	}
+	if model.AccessToken != "" || model.RefreshToken != "" {
+		t.Fatalf("Expected the model tokens to remain unset, got %q and %q", model.AccessToken, model.RefreshToken)
+	}
+
+	app.Dao().EncryptionKey = testEncryptionKey1
+
+	if err := app.Dao().UpdateExternalAuthTokens(model, "access1", "refresh1", expiry); err != nil {
+		t.Fatal(err)
+	}
+
+	// empty refresh token should keep the existing one
+	if err := app.Dao().UpdateExternalAuthTokens(model, "access2", "", expiry); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := app.Dao().FindFirstExternalAuthByExpr(dbx.HashExp{"id": model.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, raw := range []string{stored.AccessToken, stored.RefreshToken} {
+		if raw == "" || raw == "access2" || raw == "refresh1" {
+			t.Fatalf("Expected the stored tokens to be encrypted, got %q", raw)
+		}
+	}
+
+	if stored.Expiry.String() != expiry.String() {
+		t.Fatalf("Expected expiry %q, got %q", expiry.String(), stored.Expiry.String())
+	}
+
+	access, refresh, err := app.Dao().DecryptExternalAuthTokens(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if access != "access2" || refresh != "refresh1" {
+		t.Fatalf("Expected tokens %q and %q, got %q and %q", "access2", "refresh1", access, refresh)
+	}
+
+	// missing encryption key on decrypt
+	app.Dao().EncryptionKey = ""
+	if _, _, err := app.Dao().DecryptExternalAuthTokens(stored); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey, got %v", err)
+	}
+}
+
 func TestDeleteExternalAuth(t *testing.T) {
 	t.Parallel()
 
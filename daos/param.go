@@ -49,7 +49,7 @@ func (dao *Dao) SaveParam(key string, value any, optEncryptionKey ...string) err
 			return encodingErr
 		}
 
-		encryptVal, encryptErr := security.Encrypt(encoded, optEncryptionKey[0])
+		encryptVal, encryptErr := security.EncryptVersioned(encoded, optEncryptionKey[0])
 		if encryptErr != nil {
 			return encryptErr
 		}
@@ -0,0 +1,88 @@
+package daos
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// PasskeyQuery returns a new Passkey select query.
+func (dao *Dao) PasskeyQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.Passkey{})
+}
+
+// FindAllPasskeysByRecord returns all Passkey models
+// linked to the provided auth record.
+func (dao *Dao) FindAllPasskeysByRecord(authRecord *models.Record) ([]*models.Passkey, error) {
+	passkeys := []*models.Passkey{}
+
+	err := dao.PasskeyQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": authRecord.Collection().Id,
+			"recordId":     authRecord.Id,
+		}).
+		OrderBy("created ASC").
+		All(&passkeys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return passkeys, nil
+}
+
+// FindPasskeyByRecordAndId returns the Passkey model with the
+// specified id that is linked to the provided auth record.
+func (dao *Dao) FindPasskeyByRecordAndId(authRecord *models.Record, id string) (*models.Passkey, error) {
+	model := &models.Passkey{}
+
+	err := dao.PasskeyQuery().
+		AndWhere(dbx.HashExp{
+			"id":           id,
+			"collectionId": authRecord.Collection().Id,
+			"recordId":     authRecord.Id,
+		}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindPasskeyByCredentialId returns the Passkey model of the
+// specified auth collection and WebAuthn credential id.
+func (dao *Dao) FindPasskeyByCredentialId(collection *models.Collection, credentialId string) (*models.Passkey, error) {
+	model := &models.Passkey{}
+
+	err := dao.PasskeyQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": collection.Id,
+			"credentialId": credentialId,
+		}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// SavePasskey upserts the provided Passkey model.
+func (dao *Dao) SavePasskey(model *models.Passkey) error {
+	if model.CollectionId == "" || model.RecordId == "" || model.CredentialId == "" || len(model.Credential) == 0 {
+		return errors.New("Missing required Passkey fields.")
+	}
+
+	return dao.Save(model)
+}
+
+// DeletePasskey deletes the provided Passkey model.
+func (dao *Dao) DeletePasskey(model *models.Passkey) error {
+	return dao.Delete(model)
+}
@@ -0,0 +1,148 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func createTestPasskey(t *testing.T, dao *daos.Dao, record *models.Record, credentialId string) *models.Passkey {
+	passkey := &models.Passkey{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		CredentialId: credentialId,
+		Credential:   types.JsonRaw(`{"id":"dGVzdA=="}`),
+	}
+
+	if err := dao.SavePasskey(passkey); err != nil {
+		t.Fatal(err)
+	}
+
+	return passkey
+}
+
+func TestPasskeyQuery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_passkeys}}.* FROM `_passkeys`"
+
+	sql := app.Dao().PasskeyQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestSavePasskeyValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []*models.Passkey{
+		{RecordId: "a", CredentialId: "b", Credential: types.JsonRaw(`{}`)},
+		{CollectionId: "a", CredentialId: "b", Credential: types.JsonRaw(`{}`)},
+		{CollectionId: "a", RecordId: "b", Credential: types.JsonRaw(`{}`)},
+		{CollectionId: "a", RecordId: "b", CredentialId: "c"},
+	}
+
+	for i, passkey := range scenarios {
+		if err := app.Dao().SavePasskey(passkey); err == nil {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestFindPasskeys(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p1 := createTestPasskey(t, app.Dao(), user1, "cred1")
+	createTestPasskey(t, app.Dao(), user1, "cred2")
+	p3 := createTestPasskey(t, app.Dao(), user2, "cred3")
+
+	// duplicated credential id in the same collection
+	duplicated := &models.Passkey{
+		CollectionId: user2.Collection().Id,
+		RecordId:     user2.Id,
+		CredentialId: "cred1",
+		Credential:   types.JsonRaw(`{}`),
+	}
+	if err := app.Dao().SavePasskey(duplicated); err == nil {
+		t.Fatal("Expected unique credential id error, got nil")
+	}
+
+	passkeys, err := app.Dao().FindAllPasskeysByRecord(user1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passkeys) != 2 || passkeys[0].CredentialId != "cred1" || passkeys[1].CredentialId != "cred2" {
+		t.Fatalf("Expected cred1 and cred2 passkeys, got %v", passkeys)
+	}
+
+	if _, err := app.Dao().FindPasskeyByRecordAndId(user1, p3.Id); err == nil {
+		t.Fatal("Expected the passkey of another record to not be found")
+	}
+
+	found, err := app.Dao().FindPasskeyByRecordAndId(user1, p1.Id)
+	if err != nil || found.CredentialId != "cred1" {
+		t.Fatalf("Expected passkey cred1, got %v (%v)", found, err)
+	}
+
+	found, err = app.Dao().FindPasskeyByCredentialId(user2.Collection(), "cred3")
+	if err != nil || found.Id != p3.Id {
+		t.Fatalf("Expected passkey %q, got %v (%v)", p3.Id, found, err)
+	}
+
+	if err := app.Dao().DeletePasskey(p3); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.Dao().FindPasskeyByCredentialId(user2.Collection(), "cred3"); err == nil {
+		t.Fatal("Expected the deleted passkey to not be found")
+	}
+}
+
+func TestDeleteRecordWithPasskeys(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createTestPasskey(t, app.Dao(), record, "cred1")
+	createTestPasskey(t, app.Dao(), record, "cred2")
+
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	passkeys, err := app.Dao().FindAllPasskeysByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passkeys) != 0 {
+		t.Fatalf("Expected the passkeys to be deleted with the record, got %d", len(passkeys))
+	}
+}
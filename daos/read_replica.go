@@ -0,0 +1,129 @@
+package daos
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/dbx"
+)
+
+// DefaultReplicaCheckInterval is the default duration for which
+// the last read replica check result is reused.
+const DefaultReplicaCheckInterval = 5 * time.Second
+
+// ReadReplica defines a read-only replica of the primary dao db
+// (eg. a LiteFS replica or a periodically synced SQLite file copy).
+type ReadReplica struct {
+	// DB is the replica db builder.
+	DB dbx.Builder
+
+	// Check is an optional function that reports whether the replica
+	// could be used (aka. it is reachable and not stale).
+	//
+	// If not set, only the replica availability is checked with a "SELECT 1" query.
+	Check func(db dbx.Builder) error
+
+	// CheckInterval is the duration for which the last check result
+	// is reused (default to [DefaultReplicaCheckInterval]).
+	CheckInterval time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	checkErr  error
+}
+
+// Healthy reports whether the replica is currently usable.
+//
+// A failed check makes the dao reads fallback to the primary db
+// until the next successful check.
+func (r *ReadReplica) Healthy() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interval := r.CheckInterval
+	if interval <= 0 {
+		interval = DefaultReplicaCheckInterval
+	}
+
+	if r.checkedAt.IsZero() || time.Since(r.checkedAt) >= interval {
+		r.checkErr = r.check()
+		r.checkedAt = time.Now()
+	}
+
+	return r.checkErr == nil
+}
+
+func (r *ReadReplica) check() error {
+	if r.Check != nil {
+		return r.Check(r.DB)
+	}
+
+	_, err := r.DB.NewQuery("SELECT 1").Execute()
+
+	return err
+}
+
+// WithReadReplica returns a new Dao with the same configuration options
+// as the current one, but with the read queries created with [Dao.ModelQuery]
+// and [Dao.RecordQuery] routed to the provided replica.
+//
+// The reads fallback to the primary db if the replica is unhealthy
+// (see [ReadReplica.Healthy]) and, to guarantee read-your-writes,
+// once a write operation is performed with the returned dao (or its clones),
+// all of its subsequent reads are also routed to the primary db.
+//
+// Transaction daos always operate only with the primary db.
+//
+// Note that raw write statements executed directly with the dao db builders
+// are not tracked (use [Dao.MarkWrite] for those).
+func (dao *Dao) WithReadReplica(replica *ReadReplica) *Dao {
+	clone := dao.Clone()
+
+	clone.replica = replica
+	clone.primaryReads = &atomic.Bool{}
+
+	if replica != nil {
+		clone.replicaDB = replica.DB
+
+		// bind the replica queries to the dao context (if any)
+		if db, ok := replica.DB.(*dbx.DB); ok {
+			if ctx := dao.Context(); ctx != nil {
+				clone.replicaDB = db.WithContext(ctx)
+			}
+		}
+	}
+
+	return clone
+}
+
+// ReadDB returns the db builder that the dao read queries operate with.
+//
+// This is the read replica db if one is configured (see [Dao.WithReadReplica]),
+// healthy and no writes were performed with the dao, otherwise - dao.ConcurrentDB().
+func (dao *Dao) ReadDB() dbx.Builder {
+	if dao.replica == nil || dao.replicaDB == nil {
+		return dao.ConcurrentDB()
+	}
+
+	if dao.primaryReads != nil && dao.primaryReads.Load() {
+		return dao.ConcurrentDB()
+	}
+
+	if !dao.replica.Healthy() {
+		return dao.ConcurrentDB()
+	}
+
+	return dao.replicaDB
+}
+
+// MarkWrite routes all subsequent reads of the current dao
+// (and its clones) to the primary db.
+//
+// It is called automatically on [Dao.Save], [Dao.Delete] and when starting
+// a transaction and it is a no-op if the dao doesn't have a read replica.
+func (dao *Dao) MarkWrite() {
+	if dao.primaryReads != nil {
+		dao.primaryReads.Store(true)
+	}
+}
@@ -0,0 +1,173 @@
+package daos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestReadReplicaHealthy(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	calls := 0
+	var checkErr error
+
+	replica := &daos.ReadReplica{
+		DB: app.Dao().ConcurrentDB(),
+		Check: func(db dbx.Builder) error {
+			calls++
+			return checkErr
+		},
+		CheckInterval: 50 * time.Millisecond,
+	}
+
+	if !replica.Healthy() {
+		t.Fatal("Expected the replica to be healthy")
+	}
+
+	// the last check result should be reused within the check interval
+	checkErr = errors.New("stale")
+	if !replica.Healthy() || calls != 1 {
+		t.Fatalf("Expected the cached healthy result (calls %d)", calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if replica.Healthy() || calls != 2 {
+		t.Fatalf("Expected the replica to be unhealthy (calls %d)", calls)
+	}
+}
+
+func TestDaoReadReplicaRouting(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	replicaApp, _ := tests.NewTestApp()
+	defer replicaApp.Cleanup()
+
+	// make the replica "stale" by removing one of the records
+	staleRecord, err := replicaApp.Dao().FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaApp.Dao().WithoutHooks().DeleteRecord(staleRecord); err != nil {
+		t.Fatal(err)
+	}
+
+	var checkErr error
+
+	replica := &daos.ReadReplica{
+		DB:            replicaApp.Dao().ConcurrentDB(),
+		CheckInterval: time.Nanosecond,
+		Check: func(db dbx.Builder) error {
+			return checkErr
+		},
+	}
+
+	existsOnRead := func(dao *daos.Dao) bool {
+		_, err := dao.FindRecordById("demo2", "llvuca81nly1qls")
+		return err == nil
+	}
+
+	t.Run("without replica", func(t *testing.T) {
+		if app.Dao().ReadDB() != app.Dao().ConcurrentDB() {
+			t.Fatal("Expected the primary db to be used")
+		}
+
+		if !existsOnRead(app.Dao()) {
+			t.Fatal("Expected the record to be found in the primary db")
+		}
+	})
+
+	t.Run("healthy replica reads", func(t *testing.T) {
+		dao := app.Dao().WithReadReplica(replica)
+
+		if existsOnRead(dao) {
+			t.Fatal("Expected the read to be routed to the replica")
+		}
+
+		// the replica should be also used for the rule/filter queries
+		total := 0
+		err := dao.RecordQuery("demo2").
+			Select("count(*)").
+			AndWhere(dbx.HashExp{"title": "test1"}).
+			Row(&total)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 0 {
+			t.Fatalf("Expected 0 replica records, got %d", total)
+		}
+	})
+
+	t.Run("unhealthy replica fallback", func(t *testing.T) {
+		checkErr = errors.New("stale")
+		defer func() { checkErr = nil }()
+
+		dao := app.Dao().WithReadReplica(replica)
+
+		if !existsOnRead(dao) {
+			t.Fatal("Expected the read to fallback to the primary db")
+		}
+	})
+
+	t.Run("post-write reads", func(t *testing.T) {
+		dao := app.Dao().WithReadReplica(replica)
+
+		if existsOnRead(dao) {
+			t.Fatal("Expected the read before the write to be routed to the replica")
+		}
+
+		collection, err := dao.FindCollectionByNameOrId("demo3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		record := models.NewRecord(collection)
+		record.Set("title", "new")
+		if err := dao.SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if !existsOnRead(dao) || !existsOnRead(dao.WithoutHooks()) {
+			t.Fatal("Expected the reads after the write to be routed to the primary db")
+		}
+
+		if _, err := dao.FindRecordById("demo3", record.Id); err != nil {
+			t.Fatalf("Expected the written record to be readable, got %v", err)
+		}
+
+		// a new replica dao should start reading from the replica again
+		if existsOnRead(app.Dao().WithReadReplica(replica)) {
+			t.Fatal("Expected the new dao read to be routed to the replica")
+		}
+	})
+
+	t.Run("transaction reads", func(t *testing.T) {
+		dao := app.Dao().WithReadReplica(replica)
+
+		err := dao.RunInTransaction(func(txDao *daos.Dao) error {
+			if !existsOnRead(txDao) {
+				t.Fatal("Expected the transaction read to be routed to the primary db")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !existsOnRead(dao) {
+			t.Fatal("Expected the reads after the transaction to be routed to the primary db")
+		}
+	})
+}
@@ -25,7 +25,20 @@ import (
 // In case a collection id or name is provided and that collection doesn't
 // actually exists, the generated query will be created with a cancelled context
 // and will fail once an executor (Row(), One(), All(), etc.) is called.
+//
+// If the collection has soft delete enabled, the trashed records are
+// excluded from the query (see [Dao.RecordQueryWithTrashed]).
 func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
+	return dao.recordQuery(collectionModelOrIdentifier, false)
+}
+
+// RecordQueryWithTrashed is similar to [Dao.RecordQuery] but for soft delete
+// collections the query also includes the trashed records.
+func (dao *Dao) RecordQueryWithTrashed(collectionModelOrIdentifier any) *dbx.SelectQuery {
+	return dao.recordQuery(collectionModelOrIdentifier, true)
+}
+
+func (dao *Dao) recordQuery(collectionModelOrIdentifier any, withTrashed bool) *dbx.SelectQuery {
 	var tableName string
 	var collection *models.Collection
 	var collectionErr error
@@ -52,7 +65,7 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 
 	selectCols := fmt.Sprintf("%s.*", dao.DB().QuoteSimpleColumnName(tableName))
 
-	query := dao.DB().Select(selectCols).From(tableName)
+	query := dao.ReadDB().Select(selectCols).From(tableName)
 
 	// in case of an error attach a new context and cancel it immediately with the error
 	if collectionErr != nil {
@@ -63,6 +76,10 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 		cancelFunc()
 	}
 
+	if !withTrashed && collection != nil && collection.HasSoftDelete() {
+		query.AndWhere(dbx.HashExp{tableName + "." + schema.FieldNameDeleted: ""})
+	}
+
 	return query.WithBuildHook(func(q *dbx.Query) {
 		q.WithExecHook(execLockRetry(dao.ModelQueryTimeout, dao.MaxLockRetries)).
 			WithOneHook(func(q *dbx.Query, a any, op func(b any) error) error {
@@ -77,6 +94,10 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 						return err
 					}
 
+					if err := dao.decryptRecordRows(collection, row); err != nil {
+						return err
+					}
+
 					record := models.NewRecordFromNullStringMap(collection, row)
 
 					*v = *record
@@ -98,6 +119,10 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 						return err
 					}
 
+					if err := dao.decryptRecordRows(collection, rows...); err != nil {
+						return err
+					}
+
 					records := models.NewRecordsFromNullStringMaps(collection, rows)
 
 					*v = records
@@ -113,6 +138,10 @@ func (dao *Dao) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
 						return err
 					}
 
+					if err := dao.decryptRecordRows(collection, rows...); err != nil {
+						return err
+					}
+
 					records := models.NewRecordsFromNullStringMaps(collection, rows)
 
 					nonPointers := make([]models.Record, len(records))
@@ -135,13 +164,32 @@ func (dao *Dao) FindRecordById(
 	collectionNameOrId string,
 	recordId string,
 	optFilters ...func(q *dbx.SelectQuery) error,
+) (*models.Record, error) {
+	return dao.findRecordById(collectionNameOrId, recordId, false, optFilters...)
+}
+
+// FindRecordByIdWithTrashed is similar to [Dao.FindRecordById]
+// but for soft delete collections it also looks in the trashed records.
+func (dao *Dao) FindRecordByIdWithTrashed(
+	collectionNameOrId string,
+	recordId string,
+	optFilters ...func(q *dbx.SelectQuery) error,
+) (*models.Record, error) {
+	return dao.findRecordById(collectionNameOrId, recordId, true, optFilters...)
+}
+
+func (dao *Dao) findRecordById(
+	collectionNameOrId string,
+	recordId string,
+	withTrashed bool,
+	optFilters ...func(q *dbx.SelectQuery) error,
 ) (*models.Record, error) {
 	collection, err := dao.FindCollectionByNameOrId(collectionNameOrId)
 	if err != nil {
 		return nil, err
 	}
 
-	query := dao.RecordQuery(collection).
+	query := dao.recordQuery(collection, withTrashed).
 		AndWhere(dbx.HashExp{collection.Name + ".id": recordId})
 
 	for _, filter := range optFilters {
@@ -162,8 +210,17 @@ func (dao *Dao) FindRecordById(
 	return record, nil
 }
 
+// maxRecordIdsPerQuery is the max number of record ids used in a single
+// FindRecordsByIds "IN" query to stay safely under the SQLite variables limit.
+const maxRecordIdsPerQuery = 500
+
 // FindRecordsByIds finds all Record models by the provided ids.
 // If no records are found, returns an empty slice.
+//
+// The duplicated ids are ignored and the returned records preserve the order
+// of the provided ids (any optFilters ordering or limit is applied per query).
+//
+// Large ids lists are fetched with multiple queries of max 500 ids each.
 func (dao *Dao) FindRecordsByIds(
 	collectionNameOrId string,
 	recordIds []string,
@@ -174,25 +231,43 @@ func (dao *Dao) FindRecordsByIds(
 		return nil, err
 	}
 
-	query := dao.RecordQuery(collection).
-		AndWhere(dbx.In(
-			collection.Name+".id",
-			list.ToInterfaceSlice(recordIds)...,
-		))
+	recordIds = list.NonzeroUniques(recordIds)
 
-	for _, filter := range optFilters {
-		if filter == nil {
-			continue
+	found := make(map[string]*models.Record, len(recordIds))
+
+	for _, chunk := range list.ToChunks(recordIds, maxRecordIdsPerQuery) {
+		query := dao.RecordQuery(collection).
+			AndWhere(dbx.In(
+				collection.Name+".id",
+				list.ToInterfaceSlice(chunk)...,
+			))
+
+		for _, filter := range optFilters {
+			if filter == nil {
+				continue
+			}
+			if err := filter(query); err != nil {
+				return nil, err
+			}
 		}
-		if err := filter(query); err != nil {
+
+		chunkRecords := make([]*models.Record, 0, len(chunk))
+
+		if err := query.All(&chunkRecords); err != nil {
 			return nil, err
 		}
+
+		for _, r := range chunkRecords {
+			found[r.Id] = r
+		}
 	}
 
-	records := make([]*models.Record, 0, len(recordIds))
+	records := make([]*models.Record, 0, len(found))
 
-	if err := query.All(&records); err != nil {
-		return nil, err
+	for _, id := range recordIds {
+		if r, ok := found[id]; ok {
+			records = append(records, r)
+		}
 	}
 
 	return records, nil
@@ -394,7 +469,8 @@ func (dao *Dao) IsRecordValueUnique(
 		expr = dbx.HashExp{inflector.Columnify(key): normalizedVal}
 	}
 
-	query := dao.RecordQuery(collection).
+	// note: the trashed records are also checked since they are still part of the unique indexes
+	query := dao.RecordQueryWithTrashed(collection).
 		Select("count(*)").
 		AndWhere(expr).
 		Limit(1)
@@ -443,6 +519,52 @@ func (dao *Dao) FindAuthRecordByToken(token string, baseTokenKey string) (*model
 	return record, nil
 }
 
+// FindAuthRecordByImpersonateToken finds the auth record and the
+// issuer admin associated with the provided impersonation JWT.
+//
+// The token is expected to be signed with the token keys of both
+// the auth record and the admin from its "impersonatedBy" claim.
+//
+// Returns an error if the JWT is invalid, expired or any of the
+// auth record or admin are missing.
+func (dao *Dao) FindAuthRecordByImpersonateToken(token string, baseTokenKey string) (*models.Record, *models.Admin, error) {
+	unverifiedClaims, err := security.ParseUnverifiedJWT(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// check required claims
+	id, _ := unverifiedClaims["id"].(string)
+	collectionId, _ := unverifiedClaims["collectionId"].(string)
+	adminId, _ := unverifiedClaims["impersonatedBy"].(string)
+	if id == "" || collectionId == "" || adminId == "" {
+		return nil, nil, errors.New("missing or invalid token claims")
+	}
+
+	record, err := dao.FindRecordById(collectionId, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !record.Collection().IsAuth() {
+		return nil, nil, errors.New("the token is not associated to an auth collection record")
+	}
+
+	admin, err := dao.FindAdminById(adminId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verificationKey := record.TokenKey() + admin.TokenKey + baseTokenKey
+
+	// verify token signature
+	if _, err := security.ParseJWT(token, verificationKey); err != nil {
+		return nil, nil, err
+	}
+
+	return record, admin, nil
+}
+
 // FindAuthRecordByEmail finds the auth record associated with the provided email.
 //
 // Returns an error if it is not an auth collection or the record is not found.
@@ -583,7 +705,18 @@ func (dao *Dao) CanAccessRecord(record *models.Record, requestInfo *models.Reque
 //
 // If record.IsNew() is true, the method will perform a create, otherwise an update.
 // To explicitly mark a record for update you can use record.MarkAsNotNew().
+//
+// On create the empty autogenerated text fields are populated with unique random values
+// (they are regenerated and the create is retried on unique constraint failure).
 func (dao *Dao) SaveRecord(record *models.Record) error {
+	var autogenerated []string
+	if record.IsNew() {
+		var err error
+		if autogenerated, err = dao.autogenerateRecordFields(record); err != nil {
+			return err
+		}
+	}
+
 	if record.Collection().IsAuth() {
 		if record.Username() == "" {
 			return errors.New("unable to save auth record without username")
@@ -606,9 +739,62 @@ func (dao *Dao) SaveRecord(record *models.Record) error {
 		}
 	}
 
+	if len(autogenerated) > 0 {
+		return dao.saveAutogeneratedRecord(record, autogenerated)
+	}
+
 	return dao.Save(record)
 }
 
+// RecordModifiedError is returned by [Dao.SaveRecordIfUnmodified]
+// when the persisted record doesn't satisfy the version check.
+type RecordModifiedError struct {
+	// Updated is the "updated" value of the persisted record.
+	Updated types.DateTime
+}
+
+// Error implements the [error] interface.
+func (e *RecordModifiedError) Error() string {
+	return "the record was modified in the meantime (updated " + e.Updated.String() + ")"
+}
+
+// SaveRecordIfUnmodified persists the provided existing Record model
+// only if the "updated" value of its persisted state satisfies the
+// provided unmodified check (aka. optimistic concurrency control).
+//
+// The check and the record update are executed in a single transaction.
+//
+// Returns a [*RecordModifiedError] if the check fails.
+func (dao *Dao) SaveRecordIfUnmodified(record *models.Record, unmodified func(persistedUpdated types.DateTime) bool) error {
+	if record.IsNew() {
+		return errors.New("cannot check the version of a new record")
+	}
+
+	return dao.RunInTransaction(func(txDao *Dao) error {
+		var rawUpdated string
+
+		err := txDao.DB().Select(schema.FieldNameUpdated).
+			From(record.Collection().Name).
+			Where(dbx.HashExp{schema.FieldNameId: record.Id}).
+			Limit(1).
+			Row(&rawUpdated)
+		if err != nil {
+			return err
+		}
+
+		persistedUpdated, err := types.ParseDateTime(rawUpdated)
+		if err != nil {
+			return err
+		}
+
+		if !unmodified(persistedUpdated) {
+			return &RecordModifiedError{Updated: persistedUpdated}
+		}
+
+		return txDao.SaveRecord(record)
+	})
+}
+
 // DeleteRecord deletes the provided Record model.
 //
 // This method will also cascade the delete operation to all linked
@@ -616,7 +802,24 @@ func (dao *Dao) SaveRecord(record *models.Record) error {
 //
 // The delete operation may fail if the record is part of a required
 // reference in another record (aka. cannot be deleted or unset).
+//
+// If the record collection has soft delete enabled, the record is
+// only trashed (see [Dao.TrashRecord]), unless it is already trashed
+// in which case it is permanently deleted.
 func (dao *Dao) DeleteRecord(record *models.Record) error {
+	if record.Collection().HasSoftDelete() && !record.IsTrashed() {
+		return dao.TrashRecord(record)
+	}
+
+	return dao.HardDeleteRecord(record)
+}
+
+// HardDeleteRecord permanently deletes the provided Record model
+// (regardless of its collection soft delete option).
+//
+// Similar to [Dao.DeleteRecord], the delete operation is cascaded
+// to all linked relational records.
+func (dao *Dao) HardDeleteRecord(record *models.Record) error {
 	// fetch rel references (if any)
 	//
 	// note: the select is outside of the transaction to minimize
@@ -641,6 +844,30 @@ func (dao *Dao) DeleteRecord(record *models.Record) error {
 					return err
 				}
 			}
+
+			totp, err := dao.FindTOTPByRecord(record)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			if totp != nil {
+				if err := txDao.DeleteTOTP(totp); err != nil {
+					return err
+				}
+			}
+
+			passkeys, err := dao.FindAllPasskeysByRecord(record)
+			if err != nil {
+				return err
+			}
+			for _, passkey := range passkeys {
+				if err := txDao.DeletePasskey(passkey); err != nil {
+					return err
+				}
+			}
+
+			if err := txDao.DeleteAllAuthRefreshTokensByRecord(record); err != nil {
+				return err
+			}
 		}
 
 		// delete the record before the relation references to ensure that there
@@ -653,6 +880,161 @@ func (dao *Dao) DeleteRecord(record *models.Record) error {
 	})
 }
 
+// TrashRecord soft deletes the provided Record model by setting
+// its "deleted" system field to the current datetime.
+//
+// Depending on the collection TrashCascade option, the trash operation
+// is cascaded to the linked relational records as follows:
+//   - [models.TrashCascadeTrash] (default) - trashes the cascade delete
+//     references that are part of a soft delete collection
+//     (all other references are left untouched)
+//   - [models.TrashCascadeDelete] - applies the regular delete cascade
+//
+// Returns an error if the record collection doesn't have soft delete enabled.
+func (dao *Dao) TrashRecord(record *models.Record) error {
+	if err := record.SetDeletedAt(types.NowDateTime()); err != nil {
+		return err
+	}
+
+	// fetch rel references (if any)
+	//
+	// note: the select is outside of the transaction to minimize
+	// SQLITE_BUSY errors when mixing read&write in a single transaction
+	refs, err := dao.FindCollectionReferences(record.Collection())
+	if err != nil {
+		return err
+	}
+
+	err = dao.RunInTransaction(func(txDao *Dao) error {
+		if err := txDao.SaveRecord(record); err != nil {
+			return err
+		}
+
+		if record.Collection().SoftDeleteOptions().TrashCascade == models.TrashCascadeDelete {
+			if err := txDao.cascadeRecordDelete(record, refs); err != nil {
+				return err
+			}
+		} else if err := txDao.cascadeRecordTrash(record, refs); err != nil {
+			return err
+		}
+
+		if txDao.AfterTrashFunc != nil {
+			return txDao.AfterTrashFunc(txDao, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		record.Set(schema.FieldNameDeleted, "") // revert
+		return err
+	}
+
+	return nil
+}
+
+// RestoreRecord restores the provided trashed Record model
+// by clearing its "deleted" system field.
+//
+// Note that the trashed cascade references are not restored automatically.
+//
+// Returns an error if the record is not trashed or its collection
+// doesn't have soft delete enabled.
+func (dao *Dao) RestoreRecord(record *models.Record) error {
+	if !record.IsTrashed() {
+		return errors.New("the record is not trashed")
+	}
+
+	deletedAt := record.DeletedAt()
+
+	record.Set(schema.FieldNameDeleted, "")
+
+	err := dao.RunInTransaction(func(txDao *Dao) error {
+		if err := txDao.SaveRecord(record); err != nil {
+			return err
+		}
+
+		if txDao.AfterRestoreFunc != nil {
+			return txDao.AfterRestoreFunc(txDao, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		record.Set(schema.FieldNameDeleted, deletedAt) // revert
+		return err
+	}
+
+	return nil
+}
+
+// cascadeRecordTrash trashes the cascade delete references of the mainRecord
+// that are part of a soft delete collection.
+//
+// NB! This method is expected to be called inside a transaction.
+func (dao *Dao) cascadeRecordTrash(mainRecord *models.Record, refs map[*models.Collection][]*schema.SchemaField) error {
+	// sort the refs keys to ensure that the cascade events firing order is always the same
+	sortedRefKeys := make([]*models.Collection, 0, len(refs))
+	for k := range refs {
+		sortedRefKeys = append(sortedRefKeys, k)
+	}
+	sort.Slice(sortedRefKeys, func(i, j int) bool {
+		return sortedRefKeys[i].Name < sortedRefKeys[j].Name
+	})
+
+	for _, refCollection := range sortedRefKeys {
+		if refCollection.IsView() {
+			continue
+		}
+
+		for _, field := range refs[refCollection] {
+			options, _ := field.Options.(*schema.RelationOptions)
+			if options == nil {
+				continue
+			}
+
+			// similar to the hard delete, the restricted references
+			// are not allowed even though the main record row still exists
+			if options.OnDeleteAction() == schema.RelationOnDeleteRestrict {
+				refRecord := &models.Record{}
+				err := dao.recordRefsQuery(mainRecord, refCollection, field, true).Limit(1).One(refRecord)
+				if err == nil {
+					return fmt.Errorf("the record cannot be deleted because it is referenced by record %s (%s collection)", refRecord.Id, refCollection.Name)
+				}
+				if !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+				continue
+			}
+
+			if options.OnDeleteAction() != schema.RelationOnDeleteCascade || !refCollection.HasSoftDelete() {
+				continue // the references remain valid since the main record row still exists
+			}
+
+			// note: the trashed records are excluded by the query
+			query := dao.recordRefsQuery(mainRecord, refCollection, field, false)
+
+			refRecords := []*models.Record{}
+			if err := query.All(&refRecords); err != nil {
+				return err
+			}
+
+			for _, refRecord := range refRecords {
+				// similar to the delete cascade, trash only if there are
+				// no other active references in case of multiple select
+				if len(refRecord.GetStringSlice(field.Name)) > 1 {
+					continue
+				}
+
+				if err := dao.TrashRecord(refRecord); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 // cascadeRecordDelete triggers cascade deletion for the provided references.
 //
 // NB! This method is expected to be called inside a transaction.
@@ -677,25 +1059,7 @@ func (dao *Dao) cascadeRecordDelete(mainRecord *models.Record, refs map[*models.
 		}
 
 		for _, field := range fields {
-			recordTableName := inflector.Columnify(refCollection.Name)
-			prefixedFieldName := recordTableName + "." + inflector.Columnify(field.Name)
-
-			query := dao.RecordQuery(refCollection)
-
-			if opt, ok := field.Options.(schema.MultiValuer); !ok || !opt.IsMultiple() {
-				query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
-			} else {
-				query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
-					`SELECT 1 FROM json_each(CASE WHEN json_valid([[%s]]) THEN [[%s]] ELSE json_array([[%s]]) END) {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
-					prefixedFieldName, prefixedFieldName, prefixedFieldName,
-				), dbx.Params{
-					"jevalue": mainRecord.Id,
-				})))
-			}
-
-			if refCollection.Id == mainRecord.Collection().Id {
-				query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
-			}
+			query := dao.recordRefsQuery(mainRecord, refCollection, field, true)
 
 			// trigger cascade for each batchSize rel items until there is none
 			batchSize := 4000
@@ -710,6 +1074,10 @@ func (dao *Dao) cascadeRecordDelete(mainRecord *models.Record, refs map[*models.
 					break
 				}
 
+				if err := dao.decryptRecordRows(refCollection, rows...); err != nil {
+					return err
+				}
+
 				refRecords := models.NewRecordsFromNullStringMaps(refCollection, rows)
 
 				err := dao.deleteRefRecords(mainRecord, refRecords, field)
@@ -729,9 +1097,48 @@ func (dao *Dao) cascadeRecordDelete(mainRecord *models.Record, refs map[*models.
 	return nil
 }
 
-// deleteRefRecords checks if related records has to be deleted (if `CascadeDelete` is set)
-// OR
-// just unset the record id from any relation field values (if they are not required).
+// recordRefsQuery returns a query for the refCollection records
+// that reference mainRecord via the provided relation field.
+//
+// The mainRecord itself is excluded in case of a self-reference.
+func (dao *Dao) recordRefsQuery(
+	mainRecord *models.Record,
+	refCollection *models.Collection,
+	field *schema.SchemaField,
+	withTrashed bool,
+) *dbx.SelectQuery {
+	recordTableName := inflector.Columnify(refCollection.Name)
+	prefixedFieldName := recordTableName + "." + inflector.Columnify(field.Name)
+
+	var query *dbx.SelectQuery
+	if withTrashed {
+		query = dao.RecordQueryWithTrashed(refCollection)
+	} else {
+		query = dao.RecordQuery(refCollection)
+	}
+
+	if opt, ok := field.Options.(schema.MultiValuer); !ok || !opt.IsMultiple() {
+		query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
+	} else {
+		query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
+			`SELECT 1 FROM json_each(CASE WHEN json_valid([[%s]]) THEN [[%s]] ELSE json_array([[%s]]) END) {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
+			prefixedFieldName, prefixedFieldName, prefixedFieldName,
+		), dbx.Params{
+			"jevalue": mainRecord.Id,
+		})))
+	}
+
+	if refCollection.Id == mainRecord.Collection().Id {
+		query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
+	}
+
+	return query
+}
+
+// deleteRefRecords applies the relation field OnDelete action to the related records:
+//   - "cascade" - deletes the related records (if there are no other active references)
+//   - "setNull" - unsets the record id from the relation field values (if they are not required)
+//   - "restrict" - returns an error if there are any related records
 //
 // NB! This method is expected to be called inside a transaction.
 func (dao *Dao) deleteRefRecords(mainRecord *models.Record, refRecords []*models.Record, field *schema.SchemaField) error {
@@ -740,7 +1147,13 @@ func (dao *Dao) deleteRefRecords(mainRecord *models.Record, refRecords []*models
 		return errors.New("relation field options are not initialized")
 	}
 
+	onDelete := options.OnDeleteAction()
+
 	for _, refRecord := range refRecords {
+		if onDelete == schema.RelationOnDeleteRestrict {
+			return fmt.Errorf("the record cannot be deleted because it is referenced by record %s (%s collection)", refRecord.Id, refRecord.Collection().Name)
+		}
+
 		ids := refRecord.GetStringSlice(field.Name)
 
 		// unset the record id
@@ -753,7 +1166,13 @@ func (dao *Dao) deleteRefRecords(mainRecord *models.Record, refRecords []*models
 
 		// cascade delete the reference
 		// (only if there are no other active references in case of multiple select)
-		if options.CascadeDelete && len(ids) == 0 {
+		if onDelete == schema.RelationOnDeleteCascade && len(ids) == 0 {
+			// unset also the reference of the soft deleted records
+			// to prevent dangling relations in case of a restore
+			if refRecord.Collection().HasSoftDelete() && !refRecord.IsTrashed() {
+				refRecord.Set(field.Name, field.PrepareValue(ids))
+			}
+
 			if err := dao.DeleteRecord(refRecord); err != nil {
 				return err
 			}
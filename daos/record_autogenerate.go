@@ -0,0 +1,184 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// maxAutogenerateAttempts is the max number of attempts to generate
+// a unique random text field value before giving up.
+const maxAutogenerateAttempts = 20
+
+// ErrAutogenerateCollision is returned when a unique random text field
+// value couldn't be generated within [maxAutogenerateAttempts]
+// (usually because of a too short length or alphabet).
+var ErrAutogenerateCollision = errors.New("failed to generate a unique random value")
+
+// autogenerateRecordFields populates the empty autogenerated text
+// fields of the provided record with unique random values.
+//
+// Returns the names of the populated fields.
+func (dao *Dao) autogenerateRecordFields(record *models.Record) ([]string, error) {
+	var generated []string
+
+	for _, field := range record.Collection().Schema.Fields() {
+		options, ok := autogenerateOptions(field)
+		if !ok || record.GetString(field.Name) != "" {
+			continue
+		}
+
+		value, err := dao.generateUniqueRecordFieldValue(record, field.Name, options)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Set(field.Name, value)
+
+		generated = append(generated, field.Name)
+	}
+
+	return generated, nil
+}
+
+// saveAutogeneratedRecord persists the provided new record and
+// regenerates its autogenerated field values in case the insert fails
+// with a unique constraint error (eg. because of a concurrent insert
+// with the same value that was created after the uniqueness check).
+func (dao *Dao) saveAutogeneratedRecord(record *models.Record, generated []string) error {
+	saveDao := dao
+
+	for attempt := 1; ; attempt++ {
+		err := saveDao.Save(record)
+		if err == nil || attempt >= maxAutogenerateAttempts {
+			return err
+		}
+
+		uniqueErr := dbutils.ParseUniqueConstraintError(err)
+		if uniqueErr == nil || !record.IsNew() {
+			return err
+		}
+
+		var regenerated bool
+		for _, name := range generated {
+			if !list.ExistInSlice(name, uniqueErr.Columns) {
+				continue
+			}
+
+			options, _ := autogenerateOptions(record.Collection().Schema.GetFieldByName(name))
+
+			value, genErr := dao.generateUniqueRecordFieldValue(record, name, options)
+			if genErr != nil {
+				return genErr
+			}
+
+			record.Set(name, value)
+
+			regenerated = true
+		}
+
+		if !regenerated {
+			return err // not an autogenerated field constraint failure
+		}
+
+		if attempt == 1 {
+			// the before hooks were already triggered with the first attempt
+			saveDao = dao.Clone()
+			saveDao.BeforeCreateFunc = nil
+		}
+	}
+}
+
+// RegenerateRecordField generates a new unique random value for the
+// specified autogenerated text field of the provided record and persists it.
+//
+// The record is only updated in memory if it is not persisted yet.
+func (dao *Dao) RegenerateRecordField(record *models.Record, fieldName string) error {
+	field := record.Collection().Schema.GetFieldByName(fieldName)
+	if field == nil {
+		return fmt.Errorf("missing field %q", fieldName)
+	}
+
+	options, ok := autogenerateOptions(field)
+	if !ok {
+		return fmt.Errorf("field %q is not an autogenerated text field", fieldName)
+	}
+
+	for attempt := 1; ; attempt++ {
+		value, err := dao.generateUniqueRecordFieldValue(record, field.Name, options)
+		if err != nil {
+			return err
+		}
+
+		record.Set(field.Name, value)
+
+		if record.IsNew() {
+			return nil
+		}
+
+		err = dao.SaveRecord(record)
+
+		// retry if the value was concurrently taken after the uniqueness check
+		uniqueErr := dbutils.ParseUniqueConstraintError(err)
+		if uniqueErr == nil || !list.ExistInSlice(field.Name, uniqueErr.Columns) || attempt >= maxAutogenerateAttempts {
+			return err
+		}
+	}
+}
+
+// generateUniqueRecordFieldValue generates a random value for the
+// specified record field that is unique within the record collection.
+func (dao *Dao) generateUniqueRecordFieldValue(record *models.Record, fieldName string, options *schema.TextOptions) (string, error) {
+	value, err := generateUniqueValue(
+		func() string {
+			if options.AutogenerateAlphabet == "" {
+				return security.RandomString(options.AutogenerateLength)
+			}
+			return security.RandomStringWithAlphabet(options.AutogenerateLength, options.AutogenerateAlphabet)
+		},
+		func(value string) bool {
+			return dao.IsRecordValueUnique(record.Collection().Id, fieldName, value, record.Id)
+		},
+		maxAutogenerateAttempts,
+	)
+	if err != nil {
+		return "", fmt.Errorf("field %q: %w", fieldName, err)
+	}
+
+	return value, nil
+}
+
+// autogenerateOptions returns the text options of the provided
+// field if it has enabled autogenerate value option.
+func autogenerateOptions(field *schema.SchemaField) (*schema.TextOptions, bool) {
+	if field.Type != schema.FieldTypeText {
+		return nil, false
+	}
+
+	field.InitOptions()
+
+	options, ok := field.Options.(*schema.TextOptions)
+	if !ok || !options.IsAutogenerated() {
+		return nil, false
+	}
+
+	return options, true
+}
+
+// generateUniqueValue calls generate until a value that satisfies
+// the isUnique check is returned or maxAttempts is reached.
+func generateUniqueValue(generate func() string, isUnique func(value string) bool, maxAttempts int) (string, error) {
+	for i := 0; i < maxAttempts; i++ {
+		value := generate()
+		if isUnique(value) {
+			return value, nil
+		}
+	}
+
+	return "", ErrAutogenerateCollision
+}
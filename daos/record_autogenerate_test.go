@@ -0,0 +1,304 @@
+package daos_test
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func createAutogenerateTestCollection(t *testing.T, app *tests.TestApp, options *schema.TextOptions) *models.Collection {
+	collection := &models.Collection{
+		Name: "autogenerate_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "code", Type: schema.FieldTypeText, Options: options},
+		),
+	}
+
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func TestRecordAutogenerateDefaultAlphabet(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createAutogenerateTestCollection(t, app, &schema.TextOptions{AutogenerateLength: 24})
+
+	record := models.NewRecord(collection)
+	record.Set("title", "test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	code := record.GetString("code")
+	if !regexp.MustCompile(`^[A-Za-z0-9]{24}$`).MatchString(code) {
+		t.Fatalf("Expected 24 [A-Za-z0-9] characters code, got %q", code)
+	}
+
+	found, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := found.GetString("code"); v != code {
+		t.Fatalf("Expected the persisted code to be %q, got %q", code, v)
+	}
+
+	// explicitly set values should remain unchanged
+	custom := models.NewRecord(collection)
+	custom.Set("code", "custom")
+	if err := app.Dao().SaveRecord(custom); err != nil {
+		t.Fatal(err)
+	}
+	if v := custom.GetString("code"); v != "custom" {
+		t.Fatalf("Expected the code to remain %q, got %q", "custom", v)
+	}
+
+	// the update of an existing record with empty value shouldn't autogenerate
+	custom.Set("code", "")
+	if err := app.Dao().SaveRecord(custom); err != nil {
+		t.Fatal(err)
+	}
+	if v := custom.GetString("code"); v != "" {
+		t.Fatalf("Expected the code to remain empty on update, got %q", v)
+	}
+}
+
+func TestRecordAutogenerateCustomAlphabet(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createAutogenerateTestCollection(t, app, &schema.TextOptions{
+		AutogenerateLength:   32,
+		AutogenerateAlphabet: "xyz-",
+	})
+
+	for i := 0; i < 5; i++ {
+		record := models.NewRecord(collection)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if code := record.GetString("code"); !regexp.MustCompile(`^[xyz\-]{32}$`).MatchString(code) {
+			t.Fatalf("Expected 32 [xyz-] characters code, got %q", code)
+		}
+	}
+}
+
+func TestRecordAutogenerateCollisions(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// only 2 possible values
+	collection := createAutogenerateTestCollection(t, app, &schema.TextOptions{
+		AutogenerateLength:   1,
+		AutogenerateAlphabet: "ab",
+	})
+
+	codes := map[string]struct{}{}
+	for i := 0; i < 2; i++ {
+		record := models.NewRecord(collection)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatalf("[%d] Failed to save record: %v", i, err)
+		}
+		codes[record.GetString("code")] = struct{}{}
+	}
+
+	if len(codes) != 2 {
+		t.Fatalf("Expected 2 unique codes, got %v", codes)
+	}
+
+	// all possible values are taken
+	record := models.NewRecord(collection)
+	err := app.Dao().SaveRecord(record)
+	if !errors.Is(err, daos.ErrAutogenerateCollision) {
+		t.Fatalf("Expected ErrAutogenerateCollision, got %v", err)
+	}
+
+	total, err := app.Dao().FindRecordsByFilter(collection.Id, "id != ''", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(total) != 2 {
+		t.Fatalf("Expected 2 persisted records, got %d", len(total))
+	}
+}
+
+func TestRegenerateRecordField(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createAutogenerateTestCollection(t, app, &schema.TextOptions{AutogenerateLength: 10})
+
+	record := models.NewRecord(collection)
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	oldCode := record.GetString("code")
+
+	// non-autogenerated field
+	if err := app.Dao().RegenerateRecordField(record, "title"); err == nil {
+		t.Fatal("Expected error for non-autogenerated field")
+	}
+
+	// missing field
+	if err := app.Dao().RegenerateRecordField(record, "missing"); err == nil {
+		t.Fatal("Expected error for missing field")
+	}
+
+	// reset the updated date to check that it is bumped
+	oldUpdated := "2020-01-01 00:00:00.000Z"
+	_, err := app.Dao().DB().Update(collection.Name, dbx.Params{"updated": oldUpdated}, dbx.HashExp{"id": record.Id}).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Dao().RegenerateRecordField(record, "code"); err != nil {
+		t.Fatal(err)
+	}
+
+	newCode := record.GetString("code")
+	if newCode == oldCode || len(newCode) != 10 {
+		t.Fatalf("Expected new 10 characters code, got %q (old %q)", newCode, oldCode)
+	}
+
+	found, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := found.GetString("code"); v != newCode {
+		t.Fatalf("Expected the regenerated code %q to be persisted, got %q", newCode, v)
+	}
+	if v := found.GetDateTime("updated").String(); v == oldUpdated {
+		t.Fatalf("Expected the updated date to be bumped, got %q", v)
+	}
+}
+
+func TestRecordAutogenerateUniqueIndex(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createAutogenerateTestCollection(t, app, &schema.TextOptions{AutogenerateLength: 10})
+
+	indexName := "_" + collection.Id + "_" + collection.Schema.GetFieldByName("code").Id + "_autogenerate_idx"
+
+	hasIndex := func() bool {
+		var total int
+		err := app.Dao().DB().Select("count(*)").
+			From("sqlite_master").
+			AndWhere(dbx.HashExp{"type": "index", "name": indexName}).
+			Row(&total)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return total > 0
+	}
+
+	if !hasIndex() {
+		t.Fatalf("Expected index %q to be created", indexName)
+	}
+
+	// empty values are allowed to repeat
+	for i := 0; i < 2; i++ {
+		_, err := app.Dao().DB().Insert(collection.Name, dbx.Params{"id": "empty" + strconv.Itoa(i)}).Execute()
+		if err != nil {
+			t.Fatalf("Expected empty code values to be allowed, got %v", err)
+		}
+	}
+
+	// duplicated non-empty values are rejected
+	if _, err := app.Dao().DB().Insert(collection.Name, dbx.Params{"id": "dup1", "code": "dup"}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Dao().DB().Insert(collection.Name, dbx.Params{"id": "dup2", "code": "dup"}).Execute(); err == nil {
+		t.Fatal("Expected unique constraint error, got nil")
+	}
+
+	// renaming the field should keep the index
+	collection.Schema.GetFieldByName("code").Name = "code_renamed"
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !hasIndex() {
+		t.Fatalf("Expected index %q to remain after the field rename", indexName)
+	}
+
+	// disabling the autogenerate should drop the index
+	collection.Schema.GetFieldByName("code_renamed").Options = &schema.TextOptions{}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if hasIndex() {
+		t.Fatalf("Expected index %q to be dropped", indexName)
+	}
+}
+
+func TestRecordAutogenerateConstraintRetry(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createAutogenerateTestCollection(t, app, &schema.TextOptions{AutogenerateLength: 10})
+
+	var beforeCalls, afterCalls int
+	var takenCode string
+
+	app.Dao().BeforeCreateFunc = func(eventDao *daos.Dao, m models.Model, action func() error) error {
+		beforeCalls++
+
+		// simulate a concurrent insert with the same value after the uniqueness check
+		takenCode = m.(*models.Record).GetString("code")
+		_, err := eventDao.DB().Insert(collection.Name, dbx.Params{"id": "concurrent", "code": takenCode}).Execute()
+		if err != nil {
+			return err
+		}
+
+		return action()
+	}
+	app.Dao().AfterCreateFunc = func(eventDao *daos.Dao, m models.Model) error {
+		afterCalls++
+		return nil
+	}
+
+	record := models.NewRecord(collection)
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatalf("Expected the create to be retried, got %v", err)
+	}
+
+	if code := record.GetString("code"); code == "" || code == takenCode {
+		t.Fatalf("Expected a regenerated code different from %q, got %q", takenCode, code)
+	}
+
+	if beforeCalls != 1 {
+		t.Fatalf("Expected the before create func to be called once, got %d", beforeCalls)
+	}
+	if afterCalls != 1 {
+		t.Fatalf("Expected the after create func to be called once, got %d", afterCalls)
+	}
+
+	if _, err := app.Dao().FindRecordById(collection.Id, record.Id); err != nil {
+		t.Fatalf("Expected the record to be persisted, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package daos
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// ErrMissingEncryptionKey is returned when trying to read or write
+// a non-empty record encrypted field value without [Dao.EncryptionKey].
+var ErrMissingEncryptionKey = errors.New("missing encryption key for the record encrypted field(s)")
+
+// encryptedFieldNames returns the names of the collection encrypted fields.
+func encryptedFieldNames(collection *models.Collection) []string {
+	if collection == nil {
+		return nil
+	}
+
+	var result []string
+
+	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeEncrypted {
+			result = append(result, field.Name)
+		}
+	}
+
+	return result
+}
+
+// encryptRecordColumns encrypts in place the collection encrypted
+// fields values of the provided record columns data map.
+func (dao *Dao) encryptRecordColumns(collection *models.Collection, data map[string]any) error {
+	for _, name := range encryptedFieldNames(collection) {
+		plain := cast.ToString(data[name])
+		if plain == "" {
+			continue // store the empty values as they are
+		}
+
+		if dao.EncryptionKey == "" {
+			return fmt.Errorf("failed to encrypt field %q: %w", name, ErrMissingEncryptionKey)
+		}
+
+		encrypted, err := dao.keyring().Encrypt([]byte(plain))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt field %q: %w", name, err)
+		}
+
+		data[name] = encrypted
+	}
+
+	return nil
+}
+
+// decryptRecordRows decrypts in place the collection encrypted
+// fields values of the provided raw record rows.
+func (dao *Dao) decryptRecordRows(collection *models.Collection, rows ...dbx.NullStringMap) error {
+	names := encryptedFieldNames(collection)
+	if len(names) == 0 {
+		return nil
+	}
+
+	keyring := dao.keyring()
+
+	for _, row := range rows {
+		for _, name := range names {
+			raw, ok := row[name]
+			if !ok || raw.String == "" {
+				continue
+			}
+
+			if dao.EncryptionKey == "" {
+				return fmt.Errorf("failed to decrypt field %q: %w", name, ErrMissingEncryptionKey)
+			}
+
+			plain, err := keyring.Decrypt(raw.String)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt field %q: %w", name, err)
+			}
+
+			row[name] = sql.NullString{String: string(plain), Valid: true}
+		}
+	}
+
+	return nil
+}
+
+// ReencryptRecords re-encrypts all stored records encrypted fields values
+// from oldKey to the current [Dao.EncryptionKey] (eg. after key rotation).
+//
+// The values that are already encrypted with the current key are skipped.
+//
+// All changes are persisted in a single transaction and the
+// total number of updated records is returned on success.
+func (dao *Dao) ReencryptRecords(oldKey string) (int, error) {
+	if dao.EncryptionKey == "" {
+		return 0, ErrMissingEncryptionKey
+	}
+
+	keyring := security.NewKeyring(dao.EncryptionKey, oldKey)
+
+	var total int
+
+	err := dao.RunInTransaction(func(txDao *Dao) error {
+		var err error
+		total, err = txDao.reencryptRecords(keyring)
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// reencryptRecords re-encrypts with the keyring current key all
+// stored records encrypted fields values that are not already
+// encrypted with it and returns the number of the updated records.
+func (dao *Dao) reencryptRecords(keyring *security.Keyring) (int, error) {
+	collections := []*models.Collection{}
+	if err := dao.CollectionQuery().AndWhere(dbx.HashExp{"type": []any{
+		models.CollectionTypeBase,
+		models.CollectionTypeAuth,
+	}}).All(&collections); err != nil {
+		return 0, err
+	}
+
+	var total int
+
+	for _, collection := range collections {
+		names := encryptedFieldNames(collection)
+		if len(names) == 0 {
+			continue
+		}
+
+		cols := make([]string, 0, len(names)+1)
+		cols = append(cols, "id")
+		cols = append(cols, names...)
+
+		rows := []dbx.NullStringMap{}
+		if err := dao.DB().Select(cols...).From(collection.Name).All(&rows); err != nil {
+			return 0, err
+		}
+
+		for _, row := range rows {
+			updateData := dbx.Params{}
+
+			for _, name := range names {
+				raw := row[name].String
+				if raw == "" || keyring.IsCurrent(raw) {
+					continue
+				}
+
+				encrypted, err := reencryptValue(keyring, raw)
+				if err != nil {
+					return 0, fmt.Errorf("failed to re-encrypt %s.%s (record %q): %w", collection.Name, name, row["id"].String, err)
+				}
+
+				updateData[name] = encrypted
+			}
+
+			if len(updateData) == 0 {
+				continue
+			}
+
+			// bump the updated date so that the change is part of the next incremental backup
+			updateData[schema.FieldNameUpdated] = types.NowDateTime().String()
+
+			_, err := dao.DB().Update(collection.Name, updateData, dbx.HashExp{"id": row["id"].String}).Execute()
+			if err != nil {
+				return 0, err
+			}
+
+			total++
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,277 @@
+package daos_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+const (
+	testEncryptionKey1 = "abcdabcdabcdabcdabcdabcdabcdabcd"
+	testEncryptionKey2 = "1234123412341234123412341234abcd"
+)
+
+func createEncryptedTestCollection(t *testing.T, app *tests.TestApp) *models.Collection {
+	collection := &models.Collection{
+		Name: "encrypted_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "secret", Type: schema.FieldTypeEncrypted},
+		),
+	}
+
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func rawEncryptedTestValue(t *testing.T, dao *daos.Dao, id string) string {
+	var raw string
+
+	err := dao.DB().Select("secret").From("encrypted_test").AndWhere(dbx.HashExp{"id": id}).Row(&raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func TestRecordEncryptedFieldRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = testEncryptionKey1
+
+	collection := createEncryptedTestCollection(t, app)
+
+	record := models.NewRecord(collection)
+	record.Set("title", "test")
+	record.Set("secret", "my_api_key")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// the in-memory value should remain the plain one
+	if v := record.GetString("secret"); v != "my_api_key" {
+		t.Fatalf("Expected the record secret to remain %q, got %q", "my_api_key", v)
+	}
+
+	raw := rawEncryptedTestValue(t, app.Dao(), record.Id)
+	if raw == "" || raw == "my_api_key" {
+		t.Fatalf("Expected the stored secret to be encrypted, got %q", raw)
+	}
+
+	found, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := found.GetString("secret"); v != "my_api_key" {
+		t.Fatalf("Expected the loaded secret to be decrypted to %q, got %q", "my_api_key", v)
+	}
+
+	// update within a transaction
+	err = app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		found.Set("secret", "my_new_api_key")
+		return txDao.SaveRecord(found)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := app.Dao().FindRecordsByExpr(collection.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 1 || all[0].GetString("secret") != "my_new_api_key" {
+		t.Fatalf("Expected the updated secret to be loaded, got %v", all)
+	}
+
+	// empty values are stored as they are
+	found.Set("secret", "")
+	if err := app.Dao().SaveRecord(found); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw := rawEncryptedTestValue(t, app.Dao(), record.Id); raw != "" {
+		t.Fatalf("Expected empty stored secret, got %q", raw)
+	}
+}
+
+func TestRecordEncryptedFieldMissingKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = testEncryptionKey1
+
+	collection := createEncryptedTestCollection(t, app)
+
+	record := models.NewRecord(collection)
+	record.Set("secret", "my_api_key")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Dao().EncryptionKey = ""
+
+	if _, err := app.Dao().FindRecordById(collection.Id, record.Id); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey on read, got %v", err)
+	}
+
+	if _, err := app.Dao().FindRecordsByExpr(collection.Id); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey on multiple records read, got %v", err)
+	}
+
+	newRecord := models.NewRecord(collection)
+	newRecord.Set("secret", "test")
+	if err := app.Dao().SaveRecord(newRecord); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey on write, got %v", err)
+	}
+
+	// records with empty encrypted values don't need the key
+	emptyRecord := models.NewRecord(collection)
+	if err := app.Dao().SaveRecord(emptyRecord); err != nil {
+		t.Fatalf("Expected the record with empty secret to be saved, got %v", err)
+	}
+
+	// wrong key
+	app.Dao().EncryptionKey = testEncryptionKey2
+	if _, err := app.Dao().FindRecordById(collection.Id, record.Id); err == nil {
+		t.Fatal("Expected read error with the wrong encryption key")
+	}
+}
+
+func TestReencryptRecords(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = testEncryptionKey1
+
+	collection := createEncryptedTestCollection(t, app)
+
+	ids := []string{}
+	for _, secret := range []string{"secret1", "secret2", ""} {
+		record := models.NewRecord(collection)
+		record.Set("secret", secret)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, record.Id)
+	}
+
+	app.Dao().EncryptionKey = testEncryptionKey2
+
+	// reset the updated dates to check that they are bumped
+	oldUpdated := "2020-01-01 00:00:00.000Z"
+	_, err := app.Dao().DB().Update(collection.Name, dbx.Params{"updated": oldUpdated}, nil).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid old key
+	if _, err := app.Dao().ReencryptRecords("abcdabcdabcdabcdabcdabcdabcdabce"); err == nil {
+		t.Fatal("Expected error with invalid old key")
+	}
+
+	total, err := app.Dao().ReencryptRecords(testEncryptionKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 2 {
+		t.Fatalf("Expected 2 re-encrypted records, got %d", total)
+	}
+
+	for i, secret := range []string{"secret1", "secret2", ""} {
+		record, err := app.Dao().FindRecordById(collection.Id, ids[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := record.GetString("secret"); v != secret {
+			t.Fatalf("Expected secret %q, got %q", secret, v)
+		}
+
+		// only the re-encrypted records should have their updated date bumped
+		isBumped := record.GetDateTime("updated").String() != oldUpdated
+		if isBumped != (secret != "") {
+			t.Fatalf("Expected bumped updated date %v for %q, got %v", secret != "", secret, isBumped)
+		}
+	}
+
+	// already re-encrypted values should be skipped
+	total, err = app.Dao().ReencryptRecords(testEncryptionKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 0 {
+		t.Fatalf("Expected 0 re-encrypted records, got %d", total)
+	}
+}
+
+func TestRecordEncryptedFieldOldEncryptionKeys(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = testEncryptionKey1
+
+	collection := createEncryptedTestCollection(t, app)
+
+	record := models.NewRecord(collection)
+	record.Set("secret", "test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Dao().EncryptionKey = testEncryptionKey2
+
+	// not yet re-encrypted value with unknown old key
+	if _, err := app.Dao().FindRecordById(collection.Id, record.Id); err == nil {
+		t.Fatal("Expected the record to not be decryptable without the old key")
+	}
+
+	app.Dao().OldEncryptionKeys = []string{testEncryptionKey1}
+
+	found, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := found.GetString("secret"); v != "test" {
+		t.Fatalf("Expected secret %q, got %q", "test", v)
+	}
+
+	// saving again should encrypt with the current key
+	if err := app.Dao().SaveRecord(found); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Dao().OldEncryptionKeys = nil
+
+	found, err = app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := found.GetString("secret"); v != "test" {
+		t.Fatalf("Expected secret %q, got %q", "test", v)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/pocketbase/dbx"
@@ -24,6 +25,54 @@ const MaxExpandDepth = 6
 // ExpandFetchFunc defines the function that is used to fetch the expanded relation records.
 type ExpandFetchFunc func(relCollection *models.Collection, relIds []string) ([]*models.Record, error)
 
+// ExpandOptions defines the optional [Dao.ExpandRecordsWithOptions] settings.
+type ExpandOptions struct {
+	// FetchFunc is the function used to fetch the relation records
+	// (if not set, a default function that returns all relation records is used).
+	FetchFunc ExpandFetchFunc
+
+	// MaxDepth is the max allowed depth of a single expand path.
+	//
+	// The expand paths exceeding it are not expanded and are reported with [*ExpandDepthError].
+	//
+	// If not set, MaxExpandDepth is used and the remaining expand path is silently ignored.
+	MaxDepth int
+
+	// SkipCycles instructs the expand to not expand further
+	// the records that were already expanded earlier in the same expand path
+	// (eg. "author.posts.author" will not expand the posts of the repeated author).
+	SkipCycles bool
+
+	// BackRelFetchFunc is the function used to fetch the back-relation
+	// records (eg. "comments_via_post").
+	//
+	// If not set, FetchFunc is used.
+	BackRelFetchFunc ExpandFetchFunc
+
+	// BackRelQueryFunc is an optional function used to modify the
+	// back-relation ids query (eg. to apply the collection list rule
+	// before the ":limit(n)" cap).
+	BackRelQueryFunc func(relCollection *models.Collection, q *dbx.SelectQuery) error
+}
+
+// MaxBackRelExpandLimit specifies the max number of back-relation
+// records that could be expanded for a single record.
+//
+// A lower per expand limit could be specified with the ":limit(n)" modifier
+// (eg. "comments_via_post:limit(5)").
+const MaxBackRelExpandLimit = 1000
+
+// ExpandDepthError is returned when an expand path exceeds the max allowed expand depth.
+type ExpandDepthError struct {
+	Path     string
+	MaxDepth int
+}
+
+// Error implements the [error] interface.
+func (e *ExpandDepthError) Error() string {
+	return fmt.Sprintf("expand path %q exceeds the max allowed depth of %d", e.Path, e.MaxDepth)
+}
+
 // ExpandRecord expands the relations of a single Record model.
 //
 // If optFetchFunc is not set, then a default function will be used
@@ -41,12 +90,39 @@ func (dao *Dao) ExpandRecord(record *models.Record, expands []string, optFetchFu
 //
 // Returns a map with the failed expand parameters and their errors.
 func (dao *Dao) ExpandRecords(records []*models.Record, expands []string, optFetchFunc ExpandFetchFunc) map[string]error {
+	return dao.ExpandRecordsWithOptions(records, expands, ExpandOptions{FetchFunc: optFetchFunc})
+}
+
+// ExpandRecordsWithOptions expands the relations of the provided Record models list
+// using the specified options (see [ExpandOptions]).
+//
+// Returns a map with the failed expand parameters and their errors.
+func (dao *Dao) ExpandRecordsWithOptions(records []*models.Record, expands []string, options ExpandOptions) map[string]error {
 	normalized := normalizeExpands(expands)
 
 	failed := map[string]error{}
 
+	maxDepth := MaxExpandDepth
+	if options.MaxDepth > 0 {
+		maxDepth = options.MaxDepth
+	}
+
 	for _, expand := range normalized {
-		if err := dao.expandRecords(records, expand, optFetchFunc, 1); err != nil {
+		if options.MaxDepth > 0 && strings.Count(expand, ".")+1 > options.MaxDepth {
+			failed[expand] = &ExpandDepthError{Path: expand, MaxDepth: options.MaxDepth}
+			continue
+		}
+
+		var ancestors expandAncestors
+		if options.SkipCycles {
+			ancestors = make(expandAncestors, len(records))
+			for _, record := range records {
+				key := expandVisitedKey(record)
+				ancestors[key] = map[string]struct{}{key: {}}
+			}
+		}
+
+		if err := dao.expandRecords(records, expand, options.FetchFunc, options.BackRelFetchFunc, options.BackRelQueryFunc, 1, maxDepth, ancestors); err != nil {
 			failed[expand] = err
 		}
 	}
@@ -59,11 +135,25 @@ var indirectExpandRegexOld = regexp.MustCompile(`^(\w+)\((\w+)\)$`)
 
 var indirectExpandRegex = regexp.MustCompile(`^(\w+)_via_(\w+)$`)
 
+var expandLimitModifierRegex = regexp.MustCompile(`^(.+):limit\((\d+)\)$`)
+
 // notes:
 // - if fetchFunc is nil, dao.FindRecordsByIds will be used
+// - if backRelFetchFunc is nil, fetchFunc will be used
+// - if backRelQueryFunc is set, it is applied to the back-relation ids query
 // - all records are expected to be from the same collection
-// - if MaxExpandDepth is reached, the function returns nil ignoring the remaining expand path
-func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetchFunc ExpandFetchFunc, recursionLevel int) error {
+// - if maxDepth is reached, the function returns nil ignoring the remaining expand path
+// - if ancestors is not nil, the records that are already part of their expand path are not expanded further
+func (dao *Dao) expandRecords(
+	records []*models.Record,
+	expandPath string,
+	fetchFunc ExpandFetchFunc,
+	backRelFetchFunc ExpandFetchFunc,
+	backRelQueryFunc func(relCollection *models.Collection, q *dbx.SelectQuery) error,
+	recursionLevel int,
+	maxDepth int,
+	ancestors expandAncestors,
+) error {
 	if fetchFunc == nil {
 		// load a default fetchFunc
 		fetchFunc = func(relCollection *models.Collection, relIds []string) ([]*models.Record, error) {
@@ -71,7 +161,7 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 		}
 	}
 
-	if expandPath == "" || recursionLevel > MaxExpandDepth || len(records) == 0 {
+	if expandPath == "" || recursionLevel > maxDepth || len(records) == 0 {
 		return nil
 	}
 
@@ -81,55 +171,50 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 	var relFieldOptions *schema.RelationOptions
 	var relCollection *models.Collection
 
-	parts := strings.SplitN(expandPath, ".", 2)
-	var matches []string
-
-	// @todo remove the old syntax support
-	if strings.Contains(parts[0], "(") {
-		matches = indirectExpandRegexOld.FindStringSubmatch(parts[0])
-		if len(matches) == 3 {
-			log.Printf(
-				"%s expand format is deprecated and will be removed in the future. Consider replacing it with %s_via_%s.\n",
-				matches[0],
-				matches[1],
-				matches[2],
-			)
-		}
-	} else {
-		matches = indirectExpandRegex.FindStringSubmatch(parts[0])
+	segment, err := dao.resolveExpandSegment(mainCollection, expandPath)
+	if err != nil {
+		return err
 	}
 
-	if len(matches) == 3 {
-		indirectRel, _ := dao.FindCollectionByNameOrId(matches[1])
-		if indirectRel == nil {
-			return fmt.Errorf("couldn't find back-related collection %q", matches[1])
-		}
-
-		indirectRelField := indirectRel.Schema.GetFieldByName(matches[2])
-		if indirectRelField == nil || indirectRelField.Type != schema.FieldTypeRelation {
-			return fmt.Errorf("couldn't find back-relation field %q in collection %q", matches[2], indirectRel.Name)
-		}
+	name := segment.name
+	indirectRel := segment.backRelCollection
+	indirectRelField := segment.backRelField
 
-		indirectRelField.InitOptions()
+	if indirectRel != nil {
 		indirectRelFieldOptions, _ := indirectRelField.Options.(*schema.RelationOptions)
-		if indirectRelFieldOptions == nil || indirectRelFieldOptions.CollectionId != mainCollection.Id {
-			return fmt.Errorf("invalid back-relation field path %q", parts[0])
+
+		maxRels := MaxBackRelExpandLimit
+		if segment.limit > 0 && segment.limit < maxRels {
+			maxRels = segment.limit
 		}
 
 		// add the related id(s) as a dynamic relation field value to
 		// allow further expand checks at later stage in a more unified manner
 		prepErr := func() error {
-			q := dao.DB().Select("id").
-				From(indirectRel.Name).
-				Limit(1000) // the limit is arbitrary chosen and may change in the future
+			tableName := indirectRel.Name
+			idColumn := dao.DB().QuoteSimpleColumnName(tableName) + ".id"
+
+			// the ids are ordered to have a deterministic ":limit(n)" result
+			// (distinct is used because the query func could add joins)
+			q := dao.DB().Select(idColumn).
+				Distinct(true).
+				From(tableName).
+				OrderBy(idColumn + " ASC").
+				Limit(int64(maxRels))
 
 			if indirectRelFieldOptions.IsMultiple() {
 				q.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
 					"SELECT 1 FROM %s je WHERE je.value = {:id}",
-					dbutils.JsonEach(indirectRelField.Name),
+					dbutils.JsonEach(tableName+"."+indirectRelField.Name),
 				))))
 			} else {
-				q.AndWhere(dbx.NewExp("[[" + indirectRelField.Name + "]] = {:id}"))
+				q.AndWhere(dbx.NewExp("[[" + tableName + "." + indirectRelField.Name + "]] = {:id}"))
+			}
+
+			if backRelQueryFunc != nil {
+				if err := backRelQueryFunc(indirectRel, q); err != nil {
+					return err
+				}
 			}
 
 			pq := q.Build().Prepare()
@@ -143,7 +228,7 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 				}
 
 				if len(relIds) > 0 {
-					record.Set(parts[0], relIds)
+					record.Set(name, relIds)
 				}
 			}
 
@@ -162,22 +247,26 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 		}
 		// indirect/back relation
 		relField = &schema.SchemaField{
-			Id:      "_" + parts[0] + security.PseudorandomString(3),
+			Id:      "_" + name + security.PseudorandomString(3),
 			Type:    schema.FieldTypeRelation,
-			Name:    parts[0],
+			Name:    name,
 			Options: relFieldOptions,
 		}
 		relCollection = indirectRel
 	} else {
+		if segment.limit > 0 {
+			return fmt.Errorf("the limit modifier is supported only for back-relation expands (%q)", name)
+		}
+
 		// direct relation
-		relField = mainCollection.Schema.GetFieldByName(parts[0])
-		if relField == nil || relField.Type != schema.FieldTypeRelation {
-			return fmt.Errorf("Couldn't find relation field %q in collection %q.", parts[0], mainCollection.Name)
+		relField = mainCollection.Schema.GetFieldByName(name)
+		if !isRelationField(relField) {
+			return fmt.Errorf("Couldn't find relation field %q in collection %q.", name, mainCollection.Name)
 		}
 		relField.InitOptions()
 		relFieldOptions, _ = relField.Options.(*schema.RelationOptions)
 		if relFieldOptions == nil {
-			return fmt.Errorf("Couldn't initialize the options of relation field %q.", parts[0])
+			return fmt.Errorf("Couldn't initialize the options of relation field %q.", name)
 		}
 
 		relCollection, _ = dao.FindCollectionByNameOrId(relFieldOptions.CollectionId)
@@ -195,14 +284,33 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 	}
 
 	// fetch rels
-	rels, relsErr := fetchFunc(relCollection, relIds)
+	fetch := fetchFunc
+	if indirectRel != nil && backRelFetchFunc != nil {
+		fetch = backRelFetchFunc
+	}
+	rels, relsErr := fetch(relCollection, relIds)
 	if relsErr != nil {
 		return relsErr
 	}
 
 	// expand nested fields
-	if len(parts) > 1 {
-		err := dao.expandRecords(rels, parts[1], fetchFunc, recursionLevel+1)
+	if segment.rest != "" {
+		nestedRels := rels
+
+		// exclude the records that are already part of their expand path to prevent cycles
+		var nestedAncestors expandAncestors
+		if ancestors != nil {
+			nestedAncestors = ancestors.next(records, relField.Name, relCollection)
+
+			nestedRels = make([]*models.Record, 0, len(rels))
+			for _, rel := range rels {
+				if _, ok := nestedAncestors[expandVisitedKey(rel)]; ok {
+					nestedRels = append(nestedRels, rel)
+				}
+			}
+		}
+
+		err := dao.expandRecords(nestedRels, segment.rest, fetchFunc, backRelFetchFunc, backRelQueryFunc, recursionLevel+1, maxDepth, nestedAncestors)
 		if err != nil {
 			return err
 		}
@@ -266,6 +374,263 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 	return nil
 }
 
+// expandSegment defines a single resolved expand path segment.
+type expandSegment struct {
+	// name is the expand key (for the back-relations it is always
+	// in the "collection_via_field" form)
+	name string
+
+	// rest is the remaining nested expand path (if any)
+	rest string
+
+	// limit is the optional back-relation ":limit(n)" modifier value
+	limit int
+
+	// backRelCollection and backRelField are set only for back-relation segments
+	backRelCollection *models.Collection
+	backRelField      *schema.SchemaField
+}
+
+// resolveExpandSegment resolves the first segment of the provided expand path.
+//
+// The back-relations could be specified as:
+//   - "collection_via_field" (eg. "comments_via_post")
+//   - "collection.field" (eg. "comments.post") - used only if the main
+//     collection doesn't have a relation field with the same name as the collection
+//   - "collection(field)" - deprecated
+func (dao *Dao) resolveExpandSegment(mainCollection *models.Collection, expandPath string) (*expandSegment, error) {
+	parts := strings.SplitN(expandPath, ".", 2)
+
+	segment := &expandSegment{}
+	if len(parts) == 2 {
+		segment.rest = parts[1]
+	}
+
+	var err error
+
+	segment.name, segment.limit, err = parseExpandLimit(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// @todo remove the old syntax support
+	if strings.Contains(segment.name, "(") {
+		matches := indirectExpandRegexOld.FindStringSubmatch(segment.name)
+		if len(matches) != 3 {
+			return nil, fmt.Errorf("invalid back-relation expand %q", segment.name)
+		}
+
+		log.Printf(
+			"%s expand format is deprecated and will be removed in the future. Consider replacing it with %s_via_%s.\n",
+			matches[0],
+			matches[1],
+			matches[2],
+		)
+
+		segment.backRelCollection, segment.backRelField, err = dao.findBackRelation(mainCollection, matches[1], matches[2])
+		if err != nil {
+			return nil, err
+		}
+
+		return segment, nil
+	}
+
+	if indirectExpandRegex.MatchString(segment.name) {
+		segment.backRelCollection, segment.backRelField, err = dao.resolveViaBackRelation(mainCollection, segment.name)
+		if err == nil {
+			return segment, nil
+		}
+
+		// fallback to the explicit form in case the collection name contains "_via_"
+		if explicit, explicitErr := dao.resolveExplicitBackRelation(mainCollection, segment); explicitErr == nil {
+			return explicit, nil
+		}
+
+		return nil, err
+	}
+
+	if isRelationField(mainCollection.Schema.GetFieldByName(segment.name)) || segment.rest == "" {
+		return segment, nil // direct relation
+	}
+
+	if explicit, err := dao.resolveExplicitBackRelation(mainCollection, segment); err == nil {
+		return explicit, nil
+	} else if !errors.Is(err, errNotBackRelCollection) {
+		return nil, err
+	}
+
+	return segment, nil // direct relation (the missing field error is handled by the caller)
+}
+
+var errNotBackRelCollection = errors.New("not a back-relation collection")
+
+// resolveExplicitBackRelation resolves the explicit "collection.field[:limit(n)]" back-relation
+// form from the provided segment name (the collection) and its nested path (the field).
+//
+// Returns errNotBackRelCollection if the segment name is not an existing collection name.
+func (dao *Dao) resolveExplicitBackRelation(mainCollection *models.Collection, segment *expandSegment) (*expandSegment, error) {
+	if segment.rest == "" || segment.limit > 0 {
+		return nil, errNotBackRelCollection
+	}
+
+	collection, _ := dao.FindCollectionByNameOrId(segment.name)
+	if collection == nil || collection.Name != segment.name {
+		return nil, errNotBackRelCollection
+	}
+
+	fieldParts := strings.SplitN(segment.rest, ".", 2)
+
+	fieldName, limit, err := parseExpandLimit(fieldParts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	_, field, err := dao.findBackRelation(mainCollection, collection.Name, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &expandSegment{
+		name:              collection.Name + "_via_" + field.Name,
+		limit:             limit,
+		backRelCollection: collection,
+		backRelField:      field,
+	}
+	if len(fieldParts) == 2 {
+		result.rest = fieldParts[1]
+	}
+
+	return result, nil
+}
+
+// resolveViaBackRelation resolves the back-relation collection and field
+// of a "collection_via_field" expand by checking all possible "_via_" splits.
+//
+// Returns an error if none or more than one of the splits are valid back-relations.
+func (dao *Dao) resolveViaBackRelation(mainCollection *models.Collection, name string) (*models.Collection, *schema.SchemaField, error) {
+	const sep = "_via_"
+
+	type candidate struct {
+		collection *models.Collection
+		field      *schema.SchemaField
+	}
+
+	var candidates []candidate
+	var lastErr error
+
+	for offset := 0; ; {
+		i := strings.Index(name[offset:], sep)
+		if i < 0 {
+			break
+		}
+		i += offset
+		offset = i + 1
+
+		collectionName, fieldName := name[:i], name[i+len(sep):]
+		if collectionName == "" || fieldName == "" {
+			continue
+		}
+
+		collection, field, err := dao.findBackRelation(mainCollection, collectionName, fieldName)
+		if err != nil {
+			// prefer the more specific field errors over the missing collection ones
+			if lastErr == nil || !errors.Is(err, errNotBackRelCollection) {
+				lastErr = err
+			}
+			continue
+		}
+
+		candidates = append(candidates, candidate{collection, field})
+	}
+
+	switch len(candidates) {
+	case 0:
+		if lastErr == nil || errors.Is(lastErr, errNotBackRelCollection) {
+			return nil, nil, fmt.Errorf("couldn't find back-related collection for %q", name)
+		}
+		return nil, nil, lastErr
+	case 1:
+		return candidates[0].collection, candidates[0].field, nil
+	default:
+		options := make([]string, len(candidates))
+		for i, c := range candidates {
+			options[i] = c.collection.Name + "." + c.field.Name
+		}
+		return nil, nil, fmt.Errorf(
+			"ambiguous back-relation expand %q (matches %s) - use the explicit \"collection.field\" form instead",
+			name,
+			strings.Join(options, ", "),
+		)
+	}
+}
+
+// findBackRelation returns the collectionName relation field
+// with the provided name that references the mainCollection.
+func (dao *Dao) findBackRelation(mainCollection *models.Collection, collectionName string, fieldName string) (*models.Collection, *schema.SchemaField, error) {
+	collection, _ := dao.FindCollectionByNameOrId(collectionName)
+	if collection == nil {
+		return nil, nil, fmt.Errorf("%w: couldn't find back-related collection %q", errNotBackRelCollection, collectionName)
+	}
+
+	var available []string
+	for _, f := range collection.Schema.Fields() {
+		if !isRelationField(f) {
+			continue
+		}
+
+		f.InitOptions()
+		options, _ := f.Options.(*schema.RelationOptions)
+		if options == nil || options.CollectionId != mainCollection.Id {
+			continue
+		}
+
+		if f.Name == fieldName {
+			return collection, f, nil
+		}
+
+		available = append(available, f.Name)
+	}
+
+	if len(available) == 0 {
+		return nil, nil, fmt.Errorf(
+			"collection %q doesn't have any relation field referencing collection %q",
+			collection.Name,
+			mainCollection.Name,
+		)
+	}
+
+	return nil, nil, fmt.Errorf(
+		"couldn't find back-relation field %q in collection %q referencing collection %q (available: %s)",
+		fieldName,
+		collection.Name,
+		mainCollection.Name,
+		strings.Join(available, ", "),
+	)
+}
+
+// parseExpandLimit extracts the optional ":limit(n)" modifier from the provided expand segment.
+func parseExpandLimit(segment string) (string, int, error) {
+	if !strings.Contains(segment, ":") {
+		return segment, 0, nil
+	}
+
+	matches := expandLimitModifierRegex.FindStringSubmatch(segment)
+	if len(matches) != 3 {
+		return "", 0, fmt.Errorf("invalid expand modifier in %q", segment)
+	}
+
+	limit, err := strconv.Atoi(matches[2])
+	if err != nil || limit <= 0 {
+		return "", 0, fmt.Errorf("invalid expand limit in %q", segment)
+	}
+
+	return matches[1], limit, nil
+}
+
+func isRelationField(field *schema.SchemaField) bool {
+	return field != nil && field.Type == schema.FieldTypeRelation
+}
+
 // normalizeExpands normalizes expand strings and merges self containing paths
 // (eg. ["a.b.c", "a.b", "   test  ", "  ", "test"] -> ["a.b.c", "test"]).
 func normalizeExpands(paths []string) []string {
@@ -301,6 +666,63 @@ func normalizeExpands(paths []string) []string {
 	return list.ToUniqueStringSlice(result)
 }
 
+// expandAncestors holds the keys of the ancestor records
+// (including the record itself) for each record of an expand level.
+type expandAncestors map[string]map[string]struct{}
+
+// next returns the ancestors of the relation records of the next expand level.
+//
+// A relation record that is already an ancestor of its parent record is a cycle
+// and it is not included in the result. Because the fetched relation records are
+// shared between their parents, a relation record reachable through multiple
+// paths keeps only the ancestors common for all of its non-cyclic paths.
+func (a expandAncestors) next(records []*models.Record, relFieldName string, relCollection *models.Collection) expandAncestors {
+	result := make(expandAncestors, len(records))
+
+	for _, record := range records {
+		parentAncestors := a[expandVisitedKey(record)]
+		if parentAncestors == nil {
+			continue
+		}
+
+		for _, relId := range record.GetStringSlice(relFieldName) {
+			relKey := relCollection.Id + "/" + relId
+
+			if _, ok := parentAncestors[relKey]; ok {
+				continue // cycle
+			}
+
+			existing, ok := result[relKey]
+			if !ok {
+				path := make(map[string]struct{}, len(parentAncestors)+1)
+				for k := range parentAncestors {
+					path[k] = struct{}{}
+				}
+				path[relKey] = struct{}{}
+				result[relKey] = path
+				continue
+			}
+
+			// keep only the common ancestors
+			for k := range existing {
+				if k == relKey {
+					continue
+				}
+				if _, ok := parentAncestors[k]; !ok {
+					delete(existing, k)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// expandVisitedKey returns the record key used to detect the expand cycles.
+func expandVisitedKey(record *models.Record) string {
+	return record.Collection().Id + "/" + record.Id
+}
+
 func isRelFieldUnique(collection *models.Collection, fieldName string) bool {
 	for _, idx := range collection.Indexes {
 		parsed := dbutils.ParseIndex(idx)
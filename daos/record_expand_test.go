@@ -6,11 +6,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestExpandRecords(t *testing.T) {
@@ -247,6 +249,188 @@ func TestExpandRecords(t *testing.T) {
 	}
 }
 
+func TestExpandRecordsWithOptions(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		testName          string
+		expands           []string
+		options           daos.ExpandOptions
+		expectExpandProps int
+		expectDepthErrors []string
+	}{
+		{
+			"max depth exceeded",
+			[]string{"self_rel_many.self_rel_many.self_rel_many", "self_rel_one.self_rel_one"},
+			daos.ExpandOptions{MaxDepth: 2},
+			2,
+			[]string{"self_rel_many.self_rel_many.self_rel_many"},
+		},
+		{
+			"max depth larger than the default",
+			[]string{"self_rel_one.self_rel_one.self_rel_one.self_rel_one.self_rel_one.self_rel_one.self_rel_one.self_rel_one"},
+			daos.ExpandOptions{MaxDepth: 8},
+			8,
+			nil,
+		},
+		{
+			"self-referential relation without cycles check",
+			[]string{"self_rel_one.self_rel_one.self_rel_one"},
+			daos.ExpandOptions{},
+			3,
+			nil,
+		},
+		{
+			"self-referential relation with cycles check",
+			[]string{"self_rel_one.self_rel_one.self_rel_one"},
+			daos.ExpandOptions{SkipCycles: true},
+			2,
+			nil,
+		},
+		{
+			"self-referential multiple relation with cycles check",
+			[]string{"self_rel_many.self_rel_many.self_rel_many"},
+			daos.ExpandOptions{SkipCycles: true},
+			1,
+			nil,
+		},
+		{
+			"mutually-referential relations without cycles check",
+			[]string{"rel_one_no_cascade.demo4_via_rel_one_no_cascade.rel_one_no_cascade"},
+			daos.ExpandOptions{},
+			3,
+			nil,
+		},
+		{
+			"mutually-referential relations with cycles check",
+			[]string{"rel_one_no_cascade.demo4_via_rel_one_no_cascade.rel_one_no_cascade"},
+			daos.ExpandOptions{SkipCycles: true},
+			2,
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.testName, func(t *testing.T) {
+			record, err := app.Dao().FindRecordById("demo4", "qzaqccwrmva4o1n")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			failed := app.Dao().ExpandRecordsWithOptions([]*models.Record{record}, s.expands, s.options)
+
+			if len(failed) != len(s.expectDepthErrors) {
+				t.Fatalf("Expected %d failures, got %d: \n%v", len(s.expectDepthErrors), len(failed), failed)
+			}
+
+			for _, path := range s.expectDepthErrors {
+				var depthErr *daos.ExpandDepthError
+				if !errors.As(failed[path], &depthErr) {
+					t.Fatalf("Expected ExpandDepthError for %q, got %v", path, failed[path])
+				}
+
+				if depthErr.MaxDepth != s.options.MaxDepth {
+					t.Fatalf("Expected MaxDepth %d, got %d", s.options.MaxDepth, depthErr.MaxDepth)
+				}
+			}
+
+			encoded, _ := json.Marshal(record)
+			encodedStr := string(encoded)
+			totalExpandProps := strings.Count(encodedStr, schema.FieldNameExpand)
+
+			if s.expectExpandProps != totalExpandProps {
+				t.Fatalf("Expected %d expand props, got %d: \n%v", s.expectExpandProps, totalExpandProps, encodedStr)
+			}
+		})
+	}
+}
+
+func TestExpandRecordsSkipCyclesPerPath(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "expand_chain",
+		Type: models.CollectionTypeBase,
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	collection.Schema.AddField(&schema.SchemaField{
+		Name:    "parent",
+		Type:    schema.FieldTypeRelation,
+		Options: &schema.RelationOptions{CollectionId: collection.Id, MaxSelect: types.Pointer(1)},
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// A -> B -> C -> A (cycle)
+	ids := []string{"aaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbb", "ccccccccccccccc"}
+	records := make([]*models.Record, len(ids))
+	for i, id := range ids {
+		records[i] = models.NewRecord(collection)
+		records[i].Id = id
+		records[i].MarkAsNew()
+		if err := app.Dao().SaveRecord(records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, record := range records {
+		record.Set("parent", ids[(i+1)%len(ids)])
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A and B as top-level records
+	a, _ := app.Dao().FindRecordById(collection.Id, ids[0])
+	b, _ := app.Dao().FindRecordById(collection.Id, ids[1])
+
+	failed := app.Dao().ExpandRecordsWithOptions(
+		[]*models.Record{a, b},
+		[]string{"parent.parent.parent.parent"},
+		daos.ExpandOptions{SkipCycles: true},
+	)
+	if len(failed) > 0 {
+		t.Fatal(failed)
+	}
+
+	// A -> B -> C (non-cyclic, even though B is also a top-level record)
+	aParent, _ := a.Expand()["parent"].(*models.Record)
+	if aParent == nil || aParent.Id != ids[1] {
+		t.Fatalf("Expected A to be expanded with B, got %v", aParent)
+	}
+	aGrandParent, _ := aParent.Expand()["parent"].(*models.Record)
+	if aGrandParent == nil || aGrandParent.Id != ids[2] {
+		t.Fatalf("Expected A.B to be expanded with C, got %v", aGrandParent)
+	}
+
+	// A -> B -> C -> A is a cycle and the repeated A shouldn't be expanded further
+	aCycle, _ := aGrandParent.Expand()["parent"].(*models.Record)
+	if aCycle == nil || aCycle.Id != ids[0] {
+		t.Fatalf("Expected A.B.C to be expanded with A, got %v", aCycle)
+	}
+	if v, ok := aCycle.Expand()["parent"]; ok {
+		t.Fatalf("Expected the repeated A to not be expanded, got %v", v)
+	}
+
+	// B -> C -> A
+	bParent, _ := b.Expand()["parent"].(*models.Record)
+	if bParent == nil || bParent.Id != ids[2] {
+		t.Fatalf("Expected B to be expanded with C, got %v", bParent)
+	}
+	bGrandParent, _ := bParent.Expand()["parent"].(*models.Record)
+	if bGrandParent == nil || bGrandParent.Id != ids[0] {
+		t.Fatalf("Expected B.C to be expanded with A, got %v", bGrandParent)
+	}
+}
+
 func TestExpandRecord(t *testing.T) {
 	t.Parallel()
 
@@ -478,3 +662,168 @@ func TestIndirectExpandSingeVsArrayResult(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandBackRelations(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo3, err := app.Dao().FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relField := func(name string, maxSelect int) *schema.SchemaField {
+		return &schema.SchemaField{
+			Name: name,
+			Type: schema.FieldTypeRelation,
+			Options: &schema.RelationOptions{
+				CollectionId: demo3.Id,
+				MaxSelect:    types.Pointer(maxSelect),
+			},
+		}
+	}
+
+	// multiple relation fields pointing to the same collection
+	comments := &models.Collection{}
+	comments.Name = "comments"
+	comments.Schema = schema.NewSchema(relField("post", 1), relField("parent_post", 2))
+
+	// "a_via_b_via_c" could be resolved both as a.b_via_c and a_via_b.c
+	ambiguous1 := &models.Collection{}
+	ambiguous1.Name = "a"
+	ambiguous1.Schema = schema.NewSchema(relField("b_via_c", 1))
+
+	ambiguous2 := &models.Collection{}
+	ambiguous2.Name = "a_via_b"
+	ambiguous2.Schema = schema.NewSchema(relField("c", 1))
+
+	for _, c := range []*models.Collection{comments, ambiguous1, ambiguous2} {
+		if err := app.Dao().SaveCollection(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mainId := "7nwo8tuiatetxdm"
+
+	for i := 0; i < 3; i++ {
+		comment := models.NewRecord(comments)
+		comment.Set("post", mainId)
+		comment.Set("parent_post", []string{mainId})
+		if err := app.Dao().SaveRecord(comment); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, c := range []*models.Collection{ambiguous1, ambiguous2} {
+		r := models.NewRecord(c)
+		r.Set(c.Schema.Fields()[0].Name, mainId)
+		if err := app.Dao().SaveRecord(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		expand        string
+		expectedKey   string
+		expectedTotal int
+		expectedError string
+	}{
+		{"comments_via_post", "comments_via_post", 3, ""},
+		{"comments_via_parent_post", "comments_via_parent_post", 3, ""},
+		{"comments_via_post:limit(2)", "comments_via_post", 2, ""},
+		{"comments_via_post:limit(5000)", "comments_via_post", 3, ""},
+		{"comments.post", "comments_via_post", 3, ""},
+		{"comments.parent_post:limit(1)", "comments_via_parent_post", 1, ""},
+		{"comments_via_post:limit(0)", "", 0, "invalid expand limit"},
+		{"comments_via_post:limit", "", 0, "invalid expand modifier"},
+		{"comments_via_missing", "", 0, `couldn't find back-relation field "missing" in collection "comments" referencing collection "demo3" (available: post, parent_post)`},
+		{"comments.missing", "", 0, `couldn't find back-relation field "missing" in collection "comments" referencing collection "demo3" (available: post, parent_post)`},
+		{"missing_via_post", "", 0, `couldn't find back-related collection for "missing_via_post"`},
+		{"demo2_via_title", "", 0, `collection "demo2" doesn't have any relation field referencing collection "demo3"`},
+		{"a_via_b_via_c", "", 0, `ambiguous back-relation expand "a_via_b_via_c" (matches a.b_via_c, a_via_b.c)`},
+		{"a.b_via_c", "a_via_b_via_c", 1, ""},
+		{"a_via_b.c", "a_via_b_via_c", 1, ""},
+		{"title:limit(1)", "", 0, "the limit modifier is supported only for back-relation expands"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expand, func(t *testing.T) {
+			record, err := app.Dao().FindRecordById(demo3.Id, mainId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var backRelFetches int
+
+			errs := app.Dao().ExpandRecordsWithOptions([]*models.Record{record}, []string{s.expand}, daos.ExpandOptions{
+				FetchFunc: func(c *models.Collection, ids []string) ([]*models.Record, error) {
+					return nil, errors.New("expected the back-relation fetch func to be used")
+				},
+				BackRelFetchFunc: func(c *models.Collection, ids []string) ([]*models.Record, error) {
+					backRelFetches++
+					return app.Dao().FindRecordsByIds(c.Id, ids, nil)
+				},
+			})
+
+			if s.expectedError != "" {
+				if err := errs[s.expand]; err == nil || !strings.Contains(err.Error(), s.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", s.expectedError, errs)
+				}
+				return
+			}
+
+			if len(errs) > 0 {
+				t.Fatalf("Expected no errors, got %v", errs)
+			}
+
+			if backRelFetches != 1 {
+				t.Fatalf("Expected the back-relation fetch func to be called once, got %d", backRelFetches)
+			}
+
+			var total int
+			switch v := record.Expand()[s.expectedKey].(type) {
+			case *models.Record:
+				total = 1
+			case []*models.Record:
+				total = len(v)
+			}
+
+			if total != s.expectedTotal {
+				t.Fatalf("Expected %d %q expanded records, got %d (%v)", s.expectedTotal, s.expectedKey, total, record.Expand())
+			}
+		})
+	}
+
+	t.Run("query func with limit", func(t *testing.T) {
+		var commentIds []string
+		err := app.Dao().DB().Select("id").From(comments.Name).OrderBy("id ASC").Column(&commentIds)
+		if err != nil || len(commentIds) != 3 {
+			t.Fatalf("Expected 3 comments, got %v (%v)", commentIds, err)
+		}
+
+		record, err := app.Dao().FindRecordById(demo3.Id, mainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expand := "comments_via_post:limit(1)"
+
+		errs := app.Dao().ExpandRecordsWithOptions([]*models.Record{record}, []string{expand}, daos.ExpandOptions{
+			// the filter should be applied before the limit
+			BackRelQueryFunc: func(c *models.Collection, q *dbx.SelectQuery) error {
+				q.AndWhere(dbx.NewExp("[[comments.id]] != {:excluded}", dbx.Params{"excluded": commentIds[0]}))
+				return nil
+			},
+		})
+		if len(errs) > 0 {
+			t.Fatalf("Expected no errors, got %v", errs)
+		}
+
+		expanded, _ := record.Expand()["comments_via_post"].([]*models.Record)
+		if len(expanded) != 1 || expanded[0].Id != commentIds[1] {
+			t.Fatalf("Expected only %q to be expanded, got %v", commentIds[1], expanded)
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package daos
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// FullTextSearchQuery converts the provided user search term into
+// a safe FTS5 MATCH query.
+//
+// Each whitespace separated word of the term is quoted (so that the FTS5
+// operators and special characters are matched literally) and used as
+// prefix token, aka. `hello wor"ld` is converted to `"hello"* "wor""ld"*`.
+//
+// Returns empty string if the term doesn't have any words.
+func FullTextSearchQuery(term string) string {
+	words := strings.Fields(term)
+
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		tokens = append(tokens, `"`+strings.ReplaceAll(w, `"`, `""`)+`"*`)
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+// WithFullTextSearch joins the provided collection records query with the
+// collection FTS5 virtual table and filters the records matching the search term.
+//
+// If orderByRank is true, the query is also ordered by the BM25 rank
+// of the matching records (aka. best match first).
+//
+// Returns an error if the collection doesn't have full-text search fields.
+func (dao *Dao) WithFullTextSearch(query *dbx.SelectQuery, collection *models.Collection, term string, orderByRank bool) error {
+	if len(collection.FullTextSearchFields()) == 0 {
+		return fmt.Errorf("collection %q doesn't have full-text search fields", collection.Name)
+	}
+
+	match := FullTextSearchQuery(term)
+	if match == "" {
+		return nil // nothing to search
+	}
+
+	ftsTable := collection.FullTextSearchTableName()
+
+	query.
+		InnerJoin(
+			dao.DB().QuoteSimpleTableName(ftsTable),
+			dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s.id]]", ftsTable, collection.Name)),
+		).
+		AndWhere(dbx.NewExp(fmt.Sprintf("[[%s]] MATCH {:ftsMatch}", ftsTable), dbx.Params{"ftsMatch": match}))
+
+	if orderByRank {
+		query.AndOrderBy(fmt.Sprintf("[[%s.rank]] ASC", ftsTable))
+	}
+
+	return nil
+}
+
+// ftsTriggerNames returns the names of the collection FTS5 sync triggers.
+func ftsTriggerNames(collection *models.Collection) []string {
+	return []string{
+		"_" + collection.Id + "_fts_insert",
+		"_" + collection.Id + "_fts_update",
+		"_" + collection.Id + "_fts_delete",
+	}
+}
+
+// dropFullTextSearchTriggers drops the collection FTS5 sync triggers (if any).
+//
+// The triggers are dropped before the record table columns changes
+// and recreated afterwards with [Dao.syncFullTextSearch].
+func (dao *Dao) dropFullTextSearchTriggers(collection *models.Collection) error {
+	for _, name := range ftsTriggerNames(collection) {
+		if _, err := dao.DB().NewQuery(fmt.Sprintf("DROP TRIGGER IF EXISTS [[%s]]", name)).Execute(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncFullTextSearch creates, recreates (and backfills) or drops the collection
+// FTS5 virtual table depending on its full-text search fields change
+// and registers the triggers that keep it in sync with the records table.
+//
+// If oldCollection is nil, the FTS5 table is created only based on newCollection.
+func (dao *Dao) syncFullTextSearch(newCollection *models.Collection, oldCollection *models.Collection) error {
+	newFields := newCollection.FullTextSearchFields()
+
+	var oldFields []string
+	if oldCollection != nil {
+		oldFields = oldCollection.FullTextSearchFields()
+	}
+
+	ftsTable := newCollection.FullTextSearchTableName()
+
+	if !slices.Equal(newFields, oldFields) {
+		if _, err := dao.DB().NewQuery(fmt.Sprintf("DROP TABLE IF EXISTS {{%s}}", ftsTable)).Execute(); err != nil {
+			return err
+		}
+
+		if len(newFields) == 0 {
+			return nil
+		}
+
+		columns := make([]string, 0, len(newFields)+1)
+		columns = append(columns, "[[id]] UNINDEXED")
+		for _, name := range newFields {
+			columns = append(columns, "[["+name+"]]")
+		}
+
+		_, err := dao.DB().NewQuery(fmt.Sprintf(
+			"CREATE VIRTUAL TABLE {{%s}} USING fts5(%s, tokenize='unicode61 remove_diacritics 2')",
+			ftsTable,
+			strings.Join(columns, ", "),
+		)).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to create the full-text search table: %w", err)
+		}
+
+		// backfill the existing records
+		_, err = dao.DB().NewQuery(fmt.Sprintf(
+			"INSERT INTO {{%s}} (%s) SELECT %s FROM {{%s}}",
+			ftsTable,
+			ftsColumnsList(newFields, ""),
+			ftsColumnsList(newFields, ""),
+			newCollection.Name,
+		)).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to populate the full-text search table: %w", err)
+		}
+	}
+
+	if len(newFields) == 0 {
+		return nil
+	}
+
+	names := ftsTriggerNames(newCollection)
+
+	_, err := dao.DB().NewQuery(fmt.Sprintf(
+		`
+		CREATE TRIGGER [[%[1]s]] AFTER INSERT ON {{%[4]s}} BEGIN
+			INSERT INTO {{%[5]s}} (%[6]s) VALUES (%[7]s);
+		END;
+		CREATE TRIGGER [[%[2]s]] AFTER UPDATE OF %[6]s ON {{%[4]s}} BEGIN
+			DELETE FROM {{%[5]s}} WHERE [[id]] = old.[[id]];
+			INSERT INTO {{%[5]s}} (%[6]s) VALUES (%[7]s);
+		END;
+		CREATE TRIGGER [[%[3]s]] AFTER DELETE ON {{%[4]s}} BEGIN
+			DELETE FROM {{%[5]s}} WHERE [[id]] = old.[[id]];
+		END;
+		`,
+		names[0],
+		names[1],
+		names[2],
+		newCollection.Name,
+		ftsTable,
+		ftsColumnsList(newFields, ""),
+		ftsColumnsList(newFields, "new."),
+	)).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to create the full-text search triggers: %w", err)
+	}
+
+	return nil
+}
+
+// ftsColumnsList returns comma separated list with the quoted
+// id and fields column names (optionally prefixed).
+func ftsColumnsList(fields []string, prefix string) string {
+	columns := make([]string, 0, len(fields)+1)
+
+	columns = append(columns, prefix+"[["+schema.FieldNameId+"]]")
+	for _, name := range fields {
+		columns = append(columns, prefix+"[["+name+"]]")
+	}
+
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,245 @@
+package daos_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// skipIfNoFTS5 skips the test if the SQLite driver is compiled without FTS5
+// (eg. the CGO driver requires the "sqlite_fts5" build tag).
+func skipIfNoFTS5(t *testing.T, dao *daos.Dao) {
+	_, err := dao.DB().NewQuery("CREATE VIRTUAL TABLE temp._fts5_check USING fts5(a)").Execute()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			t.Skip("FTS5 is not available in the current SQLite build")
+		}
+		t.Fatal(err)
+	}
+
+	dao.DB().NewQuery("DROP TABLE temp._fts5_check").Execute()
+}
+
+func TestFullTextSearchQuery(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		term     string
+		expected string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"hello", `"hello"*`},
+		{" hello   world ", `"hello"* "world"*`},
+		{`wor"ld`, `"wor""ld"*`},
+		{`a OR b NOT (c) NEAR(d e) f* ^g col:h`, `"a"* "OR"* "b"* "NOT"* "(c)"* "NEAR(d"* "e)"* "f*"* "^g"* "col:h"*`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.term, func(t *testing.T) {
+			if v := daos.FullTextSearchQuery(s.term); v != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestFullTextSearchSync(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	skipIfNoFTS5(t, app.Dao())
+
+	collection := &models.Collection{
+		Name: "fts_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "body", Type: schema.FieldTypeEditor},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// existing records before enabling the full-text search
+	existing := map[string][2]string{
+		"r1": {"Go programming", "Go is an open source programming language."},
+		"r2": {"SQLite", "SQLite full-text search with the FTS5 extension and Go bindings."},
+		"r3": {"Cooking", "Recipes for café au lait."},
+	}
+	for id, data := range existing {
+		record := models.NewRecord(collection)
+		record.Id = id + strings.Repeat("0", 15-len(id))
+		record.Set("title", data[0])
+		record.Set("body", data[1])
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := func(term string) []string {
+		query := app.Dao().RecordQuery(collection)
+		if err := app.Dao().WithFullTextSearch(query, collection, term, true); err != nil {
+			t.Fatal(err)
+		}
+
+		records := []*models.Record{}
+		if err := query.All(&records); err != nil {
+			t.Fatalf("Failed to search for %q: %v", term, err)
+		}
+
+		ids := make([]string, len(records))
+		for i, r := range records {
+			ids[i] = strings.TrimRight(r.Id, "0")
+		}
+
+		return ids
+	}
+
+	// not enabled
+	if err := app.Dao().WithFullTextSearch(app.Dao().RecordQuery(collection), collection, "go", true); err == nil {
+		t.Fatal("Expected error for collection without search fields")
+	}
+
+	// enable and backfill
+	collection.Options = types.JsonMap{"searchFields": []string{"title", "body"}}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.Dao().HasTable(collection.FullTextSearchTableName()) {
+		t.Fatal("Expected the full-text search table to be created")
+	}
+
+	// ranking (r1 has more "go" occurrences and shorter text)
+	if ids := search("go"); strings.Join(ids, ",") != "r1,r2" {
+		t.Fatalf("Expected ranked [r1 r2], got %v", ids)
+	}
+
+	// diacritics and prefix matching
+	if ids := search("cafe"); strings.Join(ids, ",") != "r3" {
+		t.Fatalf("Expected [r3], got %v", ids)
+	}
+	if ids := search("progr"); strings.Join(ids, ",") != "r1" {
+		t.Fatalf("Expected [r1], got %v", ids)
+	}
+
+	// special characters
+	for _, term := range []string{`"`, `*`, `go AND`, `(sqlite`, `NEAR(`, `title:go`, `-`} {
+		search(term)
+	}
+
+	// incremental insert
+	record := models.NewRecord(collection)
+	record.Id = "r40000000000000"
+	record.Set("title", "Go Go Go")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := search("go"); strings.Join(ids, ",") != "r4,r1,r2" {
+		t.Fatalf("Expected ranked [r4 r1 r2] after insert, got %v", ids)
+	}
+
+	// incremental update
+	record.Set("title", "Rust")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := search("go"); strings.Join(ids, ",") != "r1,r2" {
+		t.Fatalf("Expected [r1 r2] after update, got %v", ids)
+	}
+	if ids := search("rust"); strings.Join(ids, ",") != "r4" {
+		t.Fatalf("Expected [r4] after update, got %v", ids)
+	}
+
+	// incremental delete
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if ids := search("rust"); len(ids) != 0 {
+		t.Fatalf("Expected no results after delete, got %v", ids)
+	}
+
+	// change the search fields and schema
+	collection.Schema.AddField(&schema.SchemaField{Name: "extra", Type: schema.FieldTypeText})
+	collection.Options = types.JsonMap{"searchFields": []string{"title"}}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if ids := search("go"); strings.Join(ids, ",") != "r1" {
+		t.Fatalf("Expected [r1] after the search fields change, got %v", ids)
+	}
+
+	// disable
+	collection.Options = types.JsonMap{}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Dao().HasTable(collection.FullTextSearchTableName()) {
+		t.Fatal("Expected the full-text search table to be dropped")
+	}
+
+	// the records table writes should work without the triggers
+	record = models.NewRecord(collection)
+	record.Set("title", "test")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// triggers should also be removed
+	var totalTriggers int
+	err := app.Dao().DB().Select("count(*)").
+		From("sqlite_master").
+		AndWhere(dbx.HashExp{"type": "trigger"}).
+		AndWhere(dbx.Like("name", collection.Id)).
+		Row(&totalTriggers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totalTriggers != 0 {
+		t.Fatalf("Expected the full-text search triggers to be dropped, found %d", totalTriggers)
+	}
+}
+
+func TestFullTextSearchDeleteCollection(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	skipIfNoFTS5(t, app.Dao())
+
+	collection := &models.Collection{
+		Name:    "fts_test",
+		Type:    models.CollectionTypeBase,
+		Options: types.JsonMap{"searchFields": []string{"title"}},
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.Dao().HasTable(collection.FullTextSearchTableName()) {
+		t.Fatal("Expected the full-text search table to be created")
+	}
+
+	if err := app.Dao().DeleteCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Dao().HasTable(collection.FullTextSearchTableName()) {
+		t.Fatal("Expected the full-text search table to be deleted")
+	}
+}
@@ -0,0 +1,116 @@
+package daos
+
+import (
+	"sync"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// RecordsLoader is a dataloader-like helper that batches and caches
+// the records lookups by id (eg. to avoid N+1 queries when loading
+// related records in a loop within a single request or hook).
+//
+// The loader doesn't invalidate its cache, so it should be short lived
+// and usually created per request. The records are cached per the provided
+// collection identifier, so use consistently either the collection name or id.
+//
+// Example:
+//
+//	loader := app.Dao().NewRecordsLoader()
+//
+//	// queue all ids that will be needed later
+//	for _, article := range articles {
+//		loader.Queue("authors", article.GetString("author"))
+//	}
+//
+//	// all queued ids are fetched with a single query on the first Get
+//	for _, article := range articles {
+//		author, err := loader.Get("authors", article.GetString("author"))
+//		...
+//	}
+type RecordsLoader struct {
+	dao *Dao
+
+	mu      sync.Mutex
+	queued  map[string][]string                  // collection name or id -> ids
+	records map[string]map[string]*models.Record // collection name or id -> id -> record (nil if missing)
+}
+
+// NewRecordsLoader creates a new [RecordsLoader] bound to the current dao.
+func (dao *Dao) NewRecordsLoader() *RecordsLoader {
+	return &RecordsLoader{
+		dao:     dao,
+		queued:  map[string][]string{},
+		records: map[string]map[string]*models.Record{},
+	}
+}
+
+// Queue registers the provided record ids to be fetched together
+// with the next Get or Load call for the same collection.
+func (l *RecordsLoader) Queue(collectionNameOrId string, recordIds ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.queued[collectionNameOrId] = append(l.queued[collectionNameOrId], recordIds...)
+}
+
+// Get returns a single record by its id, fetching it together with
+// all queued ids of the same collection if it is not already loaded.
+//
+// Returns nil (without an error) if the record doesn't exist.
+func (l *RecordsLoader) Get(collectionNameOrId string, recordId string) (*models.Record, error) {
+	records, err := l.Load(collectionNameOrId, recordId)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	return records[0], nil
+}
+
+// Load returns the records with the provided ids (in the same order and without
+// the missing ones), fetching all not loaded yet and queued ids with as few queries as possible.
+func (l *RecordsLoader) Load(collectionNameOrId string, recordIds ...string) ([]*models.Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cache := l.records[collectionNameOrId]
+	if cache == nil {
+		cache = map[string]*models.Record{}
+		l.records[collectionNameOrId] = cache
+	}
+
+	toFetch := make([]string, 0, len(recordIds)+len(l.queued[collectionNameOrId]))
+	for _, id := range append(l.queued[collectionNameOrId], recordIds...) {
+		if _, ok := cache[id]; !ok {
+			toFetch = append(toFetch, id)
+		}
+	}
+	toFetch = list.NonzeroUniques(toFetch)
+
+	if len(toFetch) > 0 {
+		fetched, err := l.dao.FindRecordsByIds(collectionNameOrId, toFetch)
+		if err != nil {
+			return nil, err
+		}
+
+		// cache also the missing ids to avoid querying them again
+		for _, id := range toFetch {
+			cache[id] = nil
+		}
+		for _, r := range fetched {
+			cache[r.Id] = r
+		}
+	}
+
+	delete(l.queued, collectionNameOrId)
+
+	result := make([]*models.Record, 0, len(recordIds))
+	for _, id := range list.NonzeroUniques(recordIds) {
+		if r := cache[id]; r != nil {
+			result = append(result, r)
+		}
+	}
+
+	return result, nil
+}
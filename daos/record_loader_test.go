@@ -0,0 +1,69 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordsLoader(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	dao, queries := newRecordsQueryCountingDao(app)
+
+	loader := dao.NewRecordsLoader()
+
+	loader.Queue("demo2", "llvuca81nly1qls", "missing")
+	loader.Queue("demo2", "0yxhwia2amd8gec", "llvuca81nly1qls")
+
+	// fetch all queued ids
+	record, err := loader.Get("demo2", "0yxhwia2amd8gec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.Id != "0yxhwia2amd8gec" {
+		t.Fatalf("Expected record 0yxhwia2amd8gec, got %v", record)
+	}
+
+	// already loaded or known to be missing
+	missing, err := loader.Get("demo2", "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing != nil {
+		t.Fatalf("Expected nil missing record, got %v", missing)
+	}
+
+	records, err := loader.Load("demo2", "llvuca81nly1qls", "0yxhwia2amd8gec", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Id != "llvuca81nly1qls" || records[1].Id != "0yxhwia2amd8gec" {
+		t.Fatalf("Expected the cached records in the provided ids order, got %v", records)
+	}
+
+	if *queries != 1 {
+		t.Fatalf("Expected 1 records query, got %d", *queries)
+	}
+
+	// not loaded yet id
+	records, err = loader.Load("demo2", "achvryl401bhse3", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Id != "achvryl401bhse3" || records[1].Id != "llvuca81nly1qls" {
+		t.Fatalf("Expected 2 records, got %v", records)
+	}
+
+	if *queries != 2 {
+		t.Fatalf("Expected 2 records queries, got %d", *queries)
+	}
+
+	// missing collection
+	if _, err := loader.Get("missing", "llvuca81nly1qls"); err == nil {
+		t.Fatal("Expected missing collection error, got nil")
+	}
+}
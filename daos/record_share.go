@@ -0,0 +1,126 @@
+package daos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// ErrInvalidShareToken is returned when the record share token
+// is malformed, tampered, expired or revoked.
+var ErrInvalidShareToken = errors.New("invalid or expired record share token")
+
+// recordShareClaims defines the record share token payload.
+type recordShareClaims struct {
+	CollectionId string   `json:"c"`
+	RecordId     string   `json:"r"`
+	Expires      int64    `json:"e"`
+	Fields       []string `json:"f,omitempty"`
+}
+
+// NewRecordShareToken generates a new signed URL-safe token that grants
+// public read access to the provided record for the specified duration.
+//
+// If fields are set, the token grants access only to the listed record
+// fields (the system "id", "collectionId" and "collectionName" are always included).
+//
+// The token is signed with the provided secret (usually the app
+// Settings.RecordShareToken secret) and it could be revoked by changing
+// the secret or by disabling the collection "allowShare" option.
+func (dao *Dao) NewRecordShareToken(record *models.Record, secret string, duration time.Duration, fields ...string) (string, error) {
+	collection := record.Collection()
+
+	if !collection.AllowShare() {
+		return "", fmt.Errorf("records sharing is not enabled for collection %q", collection.Name)
+	}
+
+	if secret == "" {
+		return "", errors.New("missing record share token secret")
+	}
+
+	if record.IsNew() {
+		return "", errors.New("the record must be persisted before sharing")
+	}
+
+	if duration <= 0 {
+		return "", errors.New("the share token duration must be positive")
+	}
+
+	fields = list.ToUniqueStringSlice(fields)
+	for _, name := range fields {
+		if !isShareableFieldName(collection, name) {
+			return "", fmt.Errorf("missing or non-shareable collection field %q", name)
+		}
+	}
+
+	payload, err := json.Marshal(recordShareClaims{
+		CollectionId: collection.Id,
+		RecordId:     record.Id,
+		Expires:      time.Now().Add(duration).Unix(),
+		Fields:       fields,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return security.NewSignedToken(payload, secret), nil
+}
+
+// FindRecordByShareToken verifies the provided record share token
+// with the specified secret and returns the shared record together
+// with the list of its allowed fields (empty list means all fields).
+//
+// Returns [ErrInvalidShareToken] if the token is malformed,
+// tampered, expired or revoked.
+func (dao *Dao) FindRecordByShareToken(token string, secret string) (*models.Record, []string, error) {
+	if secret == "" {
+		return nil, nil, ErrInvalidShareToken
+	}
+
+	payload, err := security.ParseSignedToken(token, secret)
+	if err != nil {
+		return nil, nil, ErrInvalidShareToken
+	}
+
+	claims := recordShareClaims{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.CollectionId == "" {
+		return nil, nil, ErrInvalidShareToken
+	}
+
+	if claims.Expires <= time.Now().Unix() {
+		return nil, nil, ErrInvalidShareToken
+	}
+
+	collection, err := dao.FindCollectionByNameOrId(claims.CollectionId)
+	if err != nil || collection.Id != claims.CollectionId || !collection.AllowShare() {
+		return nil, nil, ErrInvalidShareToken
+	}
+
+	record, err := dao.FindRecordById(collection.Id, claims.RecordId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, claims.Fields, nil
+}
+
+// isShareableFieldName checks whether the provided field name
+// could be listed in the record share token allowed fields.
+func isShareableFieldName(collection *models.Collection, name string) bool {
+	if collection.Schema.GetFieldByName(name) != nil || list.ExistInSlice(name, schema.BaseModelFieldNames()) {
+		return true
+	}
+
+	return collection.IsAuth() && list.ExistInSlice(name, []string{
+		schema.FieldNameUsername,
+		schema.FieldNameEmail,
+		schema.FieldNameEmailVisibility,
+		schema.FieldNameVerified,
+	})
+}
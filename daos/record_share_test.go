@@ -0,0 +1,169 @@
+package daos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewRecordShareToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("demo1", "imy661ixudk5izi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const secret = "test_share_secret_test_share_secret"
+
+	// sharing is not enabled
+	if _, err := app.Dao().NewRecordShareToken(record, secret, time.Hour); err == nil {
+		t.Fatal("Expected error for collection without allowShare")
+	}
+
+	record.Collection().Options["allowShare"] = true
+	if err := app.Dao().SaveCollection(record.Collection()); err != nil {
+		t.Fatal(err)
+	}
+
+	// missing secret
+	if _, err := app.Dao().NewRecordShareToken(record, "", time.Hour); err == nil {
+		t.Fatal("Expected error for empty secret")
+	}
+
+	scenarios := []struct {
+		name        string
+		record      *models.Record
+		duration    time.Duration
+		fields      []string
+		expectError bool
+	}{
+		{"new record", models.NewRecord(record.Collection()), time.Hour, nil, true},
+		{"zero duration", record, 0, nil, true},
+		{"missing field", record, time.Hour, []string{"text", "missing"}, true},
+		{"auth field in non-auth collection", record, time.Hour, []string{"username"}, true},
+		{"all fields", record, time.Hour, nil, false},
+		{"restricted fields", record, time.Hour, []string{"text", "created", "text"}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			token, err := app.Dao().NewRecordShareToken(s.record, secret, s.duration, s.fields...)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			found, fields, err := app.Dao().FindRecordByShareToken(token, secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if found.Id != record.Id {
+				t.Fatalf("Expected record %q, got %q", record.Id, found.Id)
+			}
+
+			if len(s.fields) > 0 && (len(fields) != 2 || fields[0] != "text" || fields[1] != "created") {
+				t.Fatalf("Expected fields [text created], got %v", fields)
+			}
+
+			if len(s.fields) == 0 && len(fields) != 0 {
+				t.Fatalf("Expected no restricted fields, got %v", fields)
+			}
+		})
+	}
+}
+
+func TestFindRecordByShareToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("demo1", "imy661ixudk5izi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const secret = "test_share_secret_test_share_secret"
+
+	collection := record.Collection()
+	collection.Options["allowShare"] = true
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := app.Dao().NewRecordShareToken(record, secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiringToken, err := app.Dao().NewRecordShareToken(record, secret, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		token       string
+		secret      string
+		expectError bool
+	}{
+		{"empty", "", secret, true},
+		{"malformed", "invalid", secret, true},
+		{"tampered signature", token + "a", secret, true},
+		{"tampered payload", "a" + token[1:], secret, true},
+		{"different secret", token, secret + "a", true},
+		{"empty secret", token, "", true},
+		{"valid", token, secret, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			found, _, err := app.Dao().FindRecordByShareToken(s.token, s.secret)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				if !errors.Is(err, daos.ErrInvalidShareToken) {
+					t.Fatalf("Expected ErrInvalidShareToken, got %v", err)
+				}
+				return
+			}
+
+			if found.Id != record.Id {
+				t.Fatalf("Expected record %q, got %q", record.Id, found.Id)
+			}
+		})
+	}
+
+	// expired
+	time.Sleep(1100 * time.Millisecond)
+	if _, _, err := app.Dao().FindRecordByShareToken(expiringToken, secret); !errors.Is(err, daos.ErrInvalidShareToken) {
+		t.Fatalf("Expected ErrInvalidShareToken for expired token, got %v", err)
+	}
+
+	// revoke by disabling the collection sharing
+	collection.Options["allowShare"] = false
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := app.Dao().FindRecordByShareToken(token, secret); !errors.Is(err, daos.ErrInvalidShareToken) {
+		t.Fatalf("Expected ErrInvalidShareToken for revoked token, got %v", err)
+	}
+}
@@ -40,6 +40,10 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 				cols[schema.FieldNameLastLoginAlertSentAt] = "TEXT DEFAULT '' NOT NULL"
 			}
 
+			if newCollection.HasSoftDelete() {
+				cols[schema.FieldNameDeleted] = softDeleteColDefinition
+			}
+
 			// ensure that the new collection has an id
 			if !newCollection.HasId() {
 				newCollection.RefreshId()
@@ -50,6 +54,9 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 
 			// add schema field definitions
 			for _, field := range newCollection.Schema.Fields() {
+				if field.Type == schema.FieldTypeComputed {
+					continue // not stored
+				}
 				cols[field.Name] = field.ColDefinition()
 			}
 
@@ -75,6 +82,20 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 				}
 			}
 
+			if newCollection.HasSoftDelete() {
+				if err := txDao.createSoftDeleteIndex(newCollection); err != nil {
+					return err
+				}
+			}
+
+			if err := txDao.createAutogenerateIndexes(newCollection); err != nil {
+				return err
+			}
+
+			if err := txDao.syncFullTextSearch(newCollection, nil); err != nil {
+				return err
+			}
+
 			return txDao.createCollectionIndexes(newCollection)
 		}
 
@@ -91,6 +112,14 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 		if err := txDao.dropCollectionIndex(oldCollection); err != nil {
 			return err
 		}
+		if err := txDao.dropAutogenerateIndexes(oldCollection); err != nil {
+			return err
+		}
+
+		// drop the full-text search triggers to allow the columns changes
+		if err := txDao.dropFullTextSearchTriggers(oldCollection); err != nil {
+			return err
+		}
 
 		// check for renamed table
 		if !strings.EqualFold(oldTableName, newTableName) {
@@ -106,6 +135,10 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 				continue // exist
 			}
 
+			if oldField.Type == schema.FieldTypeComputed {
+				continue // not stored
+			}
+
 			_, err := txDao.DB().DropColumn(newTableName, oldField.Name).Execute()
 			if err != nil {
 				return fmt.Errorf("failed to drop column %s - %w", oldField.Name, err)
@@ -117,6 +150,10 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 		// check for new or renamed columns
 		toRename := map[string]string{}
 		for _, field := range newSchema.Fields() {
+			if field.Type == schema.FieldTypeComputed {
+				continue // not stored
+			}
+
 			oldField := oldSchema.GetFieldById(field.Id)
 			// Note:
 			// We are using a temporary column name when adding or renaming columns
@@ -159,10 +196,113 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 			return err
 		}
 
+		if err := txDao.syncSoftDeleteColumn(newCollection, oldCollection); err != nil {
+			return err
+		}
+
+		if err := txDao.createAutogenerateIndexes(newCollection); err != nil {
+			return err
+		}
+
+		if err := txDao.syncFullTextSearch(newCollection, oldCollection); err != nil {
+			return err
+		}
+
 		return txDao.createCollectionIndexes(newCollection)
 	})
 }
 
+// softDeleteColDefinition is the "deleted" system column definition
+// of the soft delete collections.
+const softDeleteColDefinition = "TEXT DEFAULT '' NOT NULL"
+
+func (dao *Dao) createSoftDeleteIndex(collection *models.Collection) error {
+	_, err := dao.DB().NewQuery(fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS _%s_deleted_idx ON {{%s}} ([[%s]])",
+		collection.Id, collection.Name, schema.FieldNameDeleted,
+	)).Execute()
+
+	return err
+}
+
+// autogenerateIndexName returns the name of the unique index
+// of the provided collection autogenerated text field.
+func autogenerateIndexName(collection *models.Collection, field *schema.SchemaField) string {
+	return fmt.Sprintf("_%s_%s_autogenerate_idx", collection.Id, field.Id)
+}
+
+// createAutogenerateIndexes creates a unique index for each autogenerated
+// text field of the provided collection to guard against concurrent
+// inserts with the same generated value
+// (the empty values of the existing records are excluded).
+func (dao *Dao) createAutogenerateIndexes(collection *models.Collection) error {
+	for _, field := range collection.Schema.Fields() {
+		if _, ok := autogenerateOptions(field); !ok {
+			continue
+		}
+
+		_, err := dao.DB().NewQuery(fmt.Sprintf(
+			"CREATE UNIQUE INDEX IF NOT EXISTS %s ON {{%s}} ([[%s]]) WHERE [[%s]] != ''",
+			autogenerateIndexName(collection, field), collection.Name, field.Name, field.Name,
+		)).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to create the unique index of the autogenerated field %s - %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// dropAutogenerateIndexes drops the unique indexes
+// of the provided collection autogenerated text fields.
+func (dao *Dao) dropAutogenerateIndexes(collection *models.Collection) error {
+	for _, field := range collection.Schema.Fields() {
+		if _, ok := autogenerateOptions(field); !ok {
+			continue
+		}
+
+		_, err := dao.DB().NewQuery("DROP INDEX IF EXISTS " + autogenerateIndexName(collection, field)).Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncSoftDeleteColumn adds or drops the "deleted" system column
+// depending on the collection soft delete option change.
+//
+// Note that disabling the soft delete drops the column and therefore
+// the previously trashed records become regular records again.
+func (dao *Dao) syncSoftDeleteColumn(newCollection, oldCollection *models.Collection) error {
+	hasNew := newCollection.HasSoftDelete()
+	hasOld := oldCollection.HasSoftDelete()
+
+	if hasNew == hasOld {
+		return nil // no change
+	}
+
+	if hasNew {
+		_, err := dao.DB().AddColumn(newCollection.Name, schema.FieldNameDeleted, softDeleteColDefinition).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to add column %s - %w", schema.FieldNameDeleted, err)
+		}
+
+		return dao.createSoftDeleteIndex(newCollection)
+	}
+
+	if _, err := dao.DB().NewQuery(fmt.Sprintf("DROP INDEX IF EXISTS _%s_deleted_idx", newCollection.Id)).Execute(); err != nil {
+		return err
+	}
+
+	if _, err := dao.DB().DropColumn(newCollection.Name, schema.FieldNameDeleted).Execute(); err != nil {
+		return fmt.Errorf("failed to drop column %s - %w", schema.FieldNameDeleted, err)
+	}
+
+	return nil
+}
+
 func (dao *Dao) normalizeSingleVsMultipleFieldChanges(newCollection, oldCollection *models.Collection) error {
 	if newCollection.IsView() || oldCollection == nil {
 		return nil // view or not an update
@@ -3,6 +3,7 @@ package daos_test
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/dbx"
@@ -321,3 +322,217 @@ func TestSingleVsMultipleValuesNormalization(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncRecordTableSchemaSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasDeletedColumn := func() bool {
+		columns, err := app.Dao().TableColumns(collection.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return list.ExistInSlice(schema.FieldNameDeleted, columns)
+	}
+
+	if hasDeletedColumn() {
+		t.Fatal("Didn't expect the deleted column")
+	}
+
+	// enable
+	collection.Options = types.JsonMap{"softDelete": true}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !hasDeletedColumn() {
+		t.Fatal("Expected the deleted column to be created")
+	}
+
+	record, _ := app.Dao().FindRecordById(collection.Id, "llvuca81nly1qls")
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// disable
+	collection.Options = types.JsonMap{}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if hasDeletedColumn() {
+		t.Fatal("Expected the deleted column to be dropped")
+	}
+
+	// the trashed records become regular ones after disabling the soft delete
+	if _, err := app.Dao().FindRecordById(collection.Id, "llvuca81nly1qls"); err != nil {
+		t.Fatalf("Expected the previously trashed record to be found, got %v", err)
+	}
+}
+
+func TestSyncRecordTableSchemaComputedField(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "computed_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "firstName", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "lastName", Type: schema.FieldTypeText},
+			&schema.SchemaField{
+				Name:    "fullName",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: `firstName + " " + lastName`},
+			},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	hasColumn := func(name string) bool {
+		columns, err := app.Dao().TableColumns(collection.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return list.ExistInSlice(name, columns)
+	}
+
+	if hasColumn("fullName") {
+		t.Fatal("Didn't expect a column for the computed field")
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("firstName", "John")
+	record.Set("lastName", "Doe")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := found.GetString("fullName"); v != "John Doe" {
+		t.Fatalf("Expected fullName %q, got %q", "John Doe", v)
+	}
+
+	// computed fields cannot be used in filter expressions
+	if _, err := app.Dao().FindRecordsByFilter(collection.Id, `fullName = "John Doe"`, "", 0, 0); err == nil {
+		t.Fatal("Expected filter error for the computed field")
+	}
+
+	// rename the computed field and add a new regular one
+	collection.Schema.GetFieldByName("fullName").Name = "displayName"
+	collection.Schema.AddField(&schema.SchemaField{Name: "nickname", Type: schema.FieldTypeText})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if hasColumn("fullName") || hasColumn("displayName") {
+		t.Fatal("Didn't expect a column for the renamed computed field")
+	}
+	if !hasColumn("nickname") {
+		t.Fatal("Expected the nickname column to be created")
+	}
+
+	// remove the computed field
+	collection.Schema.RemoveField(collection.Schema.GetFieldByName("displayName").Id)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncRecordTableSchemaPartialUniqueIndex(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "partial_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "slug", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "trashed", Type: schema.FieldTypeBool},
+		),
+		Indexes: types.JsonArray[string]{
+			"CREATE UNIQUE INDEX idx_partial_slug ON partial_test (slug) WHERE (slug != '' AND trashed = 0)",
+		},
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	indexSql := func() string {
+		var sql string
+		err := app.Dao().DB().Select("sql").
+			From("sqlite_master").
+			AndWhere(dbx.HashExp{"type": "index", "name": "idx_partial_slug"}).
+			Row(&sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sql
+	}
+
+	saveRecord := func(slug string, trashed bool) (*models.Record, error) {
+		record := models.NewRecord(collection)
+		record.Set("slug", slug)
+		record.Set("trashed", trashed)
+		return record, app.Dao().SaveRecord(record)
+	}
+
+	if sql := indexSql(); !strings.Contains(sql, "WHERE (slug != '' AND trashed = 0)") {
+		t.Fatalf("Expected the partial index predicate, got %q", sql)
+	}
+
+	// the records not matching the predicate are ignored
+	for _, slug := range []string{"", ""} {
+		if _, err := saveRecord(slug, false); err != nil {
+			t.Fatalf("Expected the empty slug to be allowed, got %v", err)
+		}
+	}
+	trashed, err := saveRecord("a", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := saveRecord("a", false); err != nil {
+		t.Fatalf("Expected the duplicated trashed slug to be allowed, got %v", err)
+	}
+
+	// conflict
+	if _, err := saveRecord("a", false); err == nil || !strings.Contains(strings.ToLower(err.Error()), "unique constraint failed") {
+		t.Fatalf("Expected unique constraint error, got %v", err)
+	}
+
+	// the index cannot be recreated with the new predicate because of the existing duplicates
+	collection.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_partial_slug ON partial_test (slug) WHERE slug != ''",
+	}
+	if err := app.Dao().SaveCollection(collection); err == nil {
+		t.Fatal("Expected the index recreation to fail")
+	}
+
+	if err := app.Dao().DeleteRecord(trashed); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if sql := indexSql(); !strings.Contains(sql, "WHERE slug != ''") || strings.Contains(sql, "trashed") {
+		t.Fatalf("Expected the partial index to be recreated with the new predicate, got %q", sql)
+	}
+
+	if _, err := saveRecord("a", true); err == nil {
+		t.Fatal("Expected unique constraint error for the trashed slug duplicate")
+	}
+}
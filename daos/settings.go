@@ -13,7 +13,8 @@ import (
 //
 // The method will first try to decode the param value without decryption.
 // If it fails and optEncryptionKey is set, it will try again by first
-// decrypting the value and then decode it again.
+// decrypting the value (with optEncryptionKey or any of [Dao.OldEncryptionKeys])
+// and then decode it again.
 //
 // Returns an error if it fails to decode the stored serialized param value.
 func (dao *Dao) FindSettings(optEncryptionKey ...string) (*settings.Settings, error) {
@@ -40,7 +41,7 @@ func (dao *Dao) FindSettings(optEncryptionKey ...string) (*settings.Settings, er
 		}
 
 		// decrypt
-		decrypted, decryptErr := security.Decrypt(string(param.Value), encryptionKey)
+		decrypted, decryptErr := security.NewKeyring(encryptionKey, dao.OldEncryptionKeys...).Decrypt(string(param.Value))
 		if decryptErr != nil {
 			return nil, decryptErr
 		}
@@ -0,0 +1,170 @@
+package daos
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// TOTPSkew is the number of the TOTP time steps before and after the
+// current one that are also accepted (to tolerate clock drifts).
+const TOTPSkew = 1
+
+// ErrInvalidTOTPCode is returned when the provided TOTP or recovery
+// code doesn't match or it was already used.
+var ErrInvalidTOTPCode = errors.New("invalid or already used TOTP code")
+
+// TOTPQuery returns a new TOTP select query.
+func (dao *Dao) TOTPQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.TOTP{})
+}
+
+// FindTOTPByRecord returns the TOTP model linked to the provided auth record.
+func (dao *Dao) FindTOTPByRecord(authRecord *models.Record) (*models.TOTP, error) {
+	model := &models.TOTP{}
+
+	err := dao.TOTPQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": authRecord.Collection().Id,
+			"recordId":     authRecord.Id,
+		}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// SaveTOTP upserts the provided TOTP model.
+func (dao *Dao) SaveTOTP(model *models.TOTP) error {
+	if model.CollectionId == "" || model.RecordId == "" || model.Secret == "" {
+		return errors.New("Missing required TOTP fields.")
+	}
+
+	return dao.Save(model)
+}
+
+// DeleteTOTP deletes the provided TOTP model.
+func (dao *Dao) DeleteTOTP(model *models.TOTP) error {
+	return dao.Delete(model)
+}
+
+// EncryptTOTPSecret encrypts the plain TOTP secret with [Dao.EncryptionKey].
+func (dao *Dao) EncryptTOTPSecret(secret string) (string, error) {
+	if dao.EncryptionKey == "" {
+		return "", ErrMissingEncryptionKey
+	}
+
+	return dao.keyring().Encrypt([]byte(secret))
+}
+
+// DecryptTOTPSecret returns the decrypted plain secret of the provided TOTP model.
+func (dao *Dao) DecryptTOTPSecret(model *models.TOTP) (string, error) {
+	if dao.EncryptionKey == "" {
+		return "", ErrMissingEncryptionKey
+	}
+
+	plain, err := dao.keyring().Decrypt(model.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+// UseTOTPCode validates the provided TOTP or recovery code against
+// the TOTP model and marks it as used so that it can't be reused.
+//
+// The TOTP codes are accepted only if their time step is after
+// the last used one and the recovery codes are removed on use.
+//
+// Returns [ErrInvalidTOTPCode] if the code doesn't match or it was already used.
+func (dao *Dao) UseTOTPCode(model *models.TOTP, code string) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) != security.TOTPDigits {
+		return dao.useTOTPRecoveryCode(model, code)
+	}
+
+	secret, err := dao.DecryptTOTPSecret(model)
+	if err != nil {
+		return err
+	}
+
+	step, ok := security.ValidateTOTP(secret, code, time.Now(), TOTPSkew)
+	if !ok || step <= model.LastUsedStep {
+		return ErrInvalidTOTPCode
+	}
+
+	// conditional update to prevent concurrent reuse of the same code
+	result, err := dao.NonconcurrentDB().Update(
+		model.TableName(),
+		dbx.Params{
+			"lastUsedStep": step,
+			"updated":      types.NowDateTime().String(),
+		},
+		dbx.And(
+			dbx.HashExp{"id": model.Id},
+			dbx.NewExp("[[lastUsedStep]] < {:step}", dbx.Params{"step": step}),
+		),
+	).Execute()
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected != 1 {
+		return ErrInvalidTOTPCode
+	}
+
+	model.LastUsedStep = step
+
+	return nil
+}
+
+func (dao *Dao) useTOTPRecoveryCode(model *models.TOTP, code string) error {
+	if code == "" {
+		return ErrInvalidTOTPCode
+	}
+
+	hash := security.SHA256(strings.ToLower(code))
+
+	for i, h := range model.RecoveryCodes {
+		if !security.Equal(h, hash) {
+			continue
+		}
+
+		// conditional update that removes the code only if it is still
+		// stored to prevent concurrent reuse of the same recovery code
+		result, err := dao.NonconcurrentDB().NewQuery(
+			"UPDATE {{" + model.TableName() + "}} SET " +
+				"[[recoveryCodes]] = (SELECT json_group_array([[value]]) FROM json_each([[recoveryCodes]]) WHERE [[value]] != {:hash}), " +
+				"[[updated]] = {:updated} " +
+				"WHERE [[id]] = {:id} AND EXISTS (SELECT 1 FROM json_each([[recoveryCodes]]) WHERE [[value]] = {:hash})",
+		).Bind(dbx.Params{
+			"id":      model.Id,
+			"hash":    hash,
+			"updated": types.NowDateTime().String(),
+		}).Execute()
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := result.RowsAffected(); affected != 1 {
+			return ErrInvalidTOTPCode
+		}
+
+		model.RecoveryCodes = append(model.RecoveryCodes[:i:i], model.RecoveryCodes[i+1:]...)
+
+		return nil
+	}
+
+	return ErrInvalidTOTPCode
+}
@@ -0,0 +1,243 @@
+package daos_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const testTOTPEncryptionKey = "abcdefghijklmnopqrstuvwxyz123456"
+
+func createTestTOTP(t *testing.T, dao *daos.Dao, record *models.Record, secret string) *models.TOTP {
+	encrypted, err := dao.EncryptTOTPSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totp := &models.TOTP{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		Secret:       encrypted,
+		Verified:     true,
+	}
+
+	if err := dao.SaveTOTP(totp); err != nil {
+		t.Fatal(err)
+	}
+
+	return totp
+}
+
+func TestTOTPQuery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_totps}}.* FROM `_totps`"
+
+	sql := app.Dao().TOTPQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = ""
+
+	if _, err := app.Dao().EncryptTOTPSecret("test"); !errors.Is(err, daos.ErrMissingEncryptionKey) {
+		t.Fatalf("Expected ErrMissingEncryptionKey, got %v", err)
+	}
+
+	app.Dao().EncryptionKey = testTOTPEncryptionKey
+
+	encrypted, err := app.Dao().EncryptTOTPSecret("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted == "" || encrypted == "test" {
+		t.Fatalf("Expected encrypted secret, got %q", encrypted)
+	}
+
+	plain, err := app.Dao().DecryptTOTPSecret(&models.TOTP{Secret: encrypted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "test" {
+		t.Fatalf("Expected decrypted secret %q, got %q", "test", plain)
+	}
+}
+
+func TestFindSaveDeleteTOTP(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = testTOTPEncryptionKey
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.Dao().FindTOTPByRecord(record); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected sql.ErrNoRows, got %v", err)
+	}
+
+	if err := app.Dao().SaveTOTP(&models.TOTP{RecordId: record.Id}); err == nil {
+		t.Fatal("Expected error for missing required fields")
+	}
+
+	totp := createTestTOTP(t, app.Dao(), record, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+
+	found, err := app.Dao().FindTOTPByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Id != totp.Id || !found.Verified {
+		t.Fatalf("Expected the saved TOTP, got %v", found)
+	}
+
+	if err := app.Dao().DeleteTOTP(found); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.Dao().FindTOTPByRecord(record); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected sql.ErrNoRows after delete, got %v", err)
+	}
+}
+
+func TestDeleteRecordWithTOTP(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = testTOTPEncryptionKey
+
+	record, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createTestTOTP(t, app.Dao(), record, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.Dao().FindTOTPByRecord(record); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected the TOTP to be deleted with the record, got %v", err)
+	}
+}
+
+func TestUseTOTPCode(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = testTOTPEncryptionKey
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totp := createTestTOTP(t, app.Dao(), record, secret)
+	recoveryCodes := totp.RefreshRecoveryCodes(2)
+	if err := app.Dao().SaveTOTP(totp); err != nil {
+		t.Fatal(err)
+	}
+
+	codeAt := func(offset int64) string {
+		code, err := security.TOTPCode(secret, security.TOTPStep(time.Now())+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	// previous step (within the drift window)
+	if err := app.Dao().UseTOTPCode(totp, codeAt(-1)); err != nil {
+		t.Fatalf("Expected the previous step code to be accepted, got %v", err)
+	}
+
+	// replay of the same code
+	if err := app.Dao().UseTOTPCode(totp, codeAt(-1)); !errors.Is(err, daos.ErrInvalidTOTPCode) {
+		t.Fatalf("Expected ErrInvalidTOTPCode for replayed code, got %v", err)
+	}
+
+	// outside of the drift window
+	if err := app.Dao().UseTOTPCode(totp, codeAt(-3)); !errors.Is(err, daos.ErrInvalidTOTPCode) {
+		t.Fatalf("Expected ErrInvalidTOTPCode for code outside of the drift window, got %v", err)
+	}
+
+	// next step (within the drift window)
+	if err := app.Dao().UseTOTPCode(totp, codeAt(1)); err != nil {
+		t.Fatalf("Expected the next step code to be accepted, got %v", err)
+	}
+
+	// older codes are rejected after a newer one was used
+	if err := app.Dao().UseTOTPCode(totp, codeAt(0)); !errors.Is(err, daos.ErrInvalidTOTPCode) {
+		t.Fatalf("Expected ErrInvalidTOTPCode for older code, got %v", err)
+	}
+
+	// replay with a stale model copy (eg. concurrent request)
+	stale, err := app.Dao().FindTOTPByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.LastUsedStep = 0
+	if err := app.Dao().UseTOTPCode(stale, codeAt(1)); !errors.Is(err, daos.ErrInvalidTOTPCode) {
+		t.Fatalf("Expected ErrInvalidTOTPCode for replay with a stale model, got %v", err)
+	}
+
+	// invalid recovery code
+	if err := app.Dao().UseTOTPCode(totp, "invalid-code"); !errors.Is(err, daos.ErrInvalidTOTPCode) {
+		t.Fatalf("Expected ErrInvalidTOTPCode for invalid recovery code, got %v", err)
+	}
+
+	// valid recovery code (case insensitive)
+	if err := app.Dao().UseTOTPCode(totp, " "+recoveryCodes[0]+" "); err != nil {
+		t.Fatalf("Expected the recovery code to be accepted, got %v", err)
+	}
+
+	// recovery code reuse
+	if err := app.Dao().UseTOTPCode(totp, recoveryCodes[0]); !errors.Is(err, daos.ErrInvalidTOTPCode) {
+		t.Fatalf("Expected ErrInvalidTOTPCode for reused recovery code, got %v", err)
+	}
+
+	// recovery code reuse with a stale model copy (eg. concurrent request)
+	if err := app.Dao().UseTOTPCode(stale, recoveryCodes[0]); !errors.Is(err, daos.ErrInvalidTOTPCode) {
+		t.Fatalf("Expected ErrInvalidTOTPCode for reused recovery code with a stale model, got %v", err)
+	}
+
+	stored, err := app.Dao().FindTOTPByRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.RecoveryCodes) != 1 {
+		t.Fatalf("Expected 1 remaining recovery code, got %d", len(stored.RecoveryCodes))
+	}
+	if stored.LastUsedStep != security.TOTPStep(time.Now())+1 && stored.LastUsedStep != security.TOTPStep(time.Now()) {
+		t.Fatalf("Expected the last used step to be persisted, got %d", stored.LastUsedStep)
+	}
+}
@@ -0,0 +1,98 @@
+package daos
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// WebhookQuery returns a new Webhook select query.
+func (dao *Dao) WebhookQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.Webhook{})
+}
+
+// FindWebhookById finds the Webhook model by its id.
+func (dao *Dao) FindWebhookById(id string) (*models.Webhook, error) {
+	model := &models.Webhook{}
+
+	err := dao.WebhookQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindActiveWebhooks returns all active webhooks of the specified
+// collection that are subscribed for the provided record event.
+func (dao *Dao) FindActiveWebhooks(collectionId string, event string) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+
+	err := dao.WebhookQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": collectionId,
+			"active":       true,
+		}).
+		AndWhere(dbx.NewExp(
+			"EXISTS (SELECT 1 FROM json_each([[events]]) WHERE json_each.value = {:event})",
+			dbx.Params{"event": event},
+		)).
+		OrderBy("created ASC", "rowid ASC").
+		All(&webhooks)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// SaveWebhook upserts the provided Webhook model.
+func (dao *Dao) SaveWebhook(model *models.Webhook) error {
+	if model.Name == "" || model.CollectionId == "" || model.Url == "" {
+		return errors.New("Missing required Webhook fields.")
+	}
+
+	return dao.Save(model)
+}
+
+// DeleteWebhook deletes the provided Webhook model.
+func (dao *Dao) DeleteWebhook(model *models.Webhook) error {
+	return dao.Delete(model)
+}
+
+// WebhookFailureQuery returns a new WebhookFailure select query.
+func (dao *Dao) WebhookFailureQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.WebhookFailure{})
+}
+
+// FindWebhookFailures returns all dead-letter entries of
+// the specified webhook ordered by their creation date (oldest first).
+func (dao *Dao) FindWebhookFailures(webhookId string) ([]*models.WebhookFailure, error) {
+	failures := []*models.WebhookFailure{}
+
+	err := dao.WebhookFailureQuery().
+		AndWhere(dbx.HashExp{"webhookId": webhookId}).
+		OrderBy("created ASC", "rowid ASC").
+		All(&failures)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+// SaveWebhookFailure upserts the provided WebhookFailure model.
+func (dao *Dao) SaveWebhookFailure(model *models.WebhookFailure) error {
+	if model.WebhookId == "" || model.Event == "" {
+		return errors.New("Missing required WebhookFailure fields.")
+	}
+
+	return dao.Save(model)
+}
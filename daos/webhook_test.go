@@ -0,0 +1,114 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestWebhookQuery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_webhooks}}.* FROM `_webhooks`"
+
+	sql := app.Dao().WebhookQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestSaveWebhookValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []*models.Webhook{
+		{CollectionId: "a", Url: "a"},
+		{Name: "a", Url: "a"},
+		{Name: "a", CollectionId: "a"},
+	}
+
+	for i, webhook := range scenarios {
+		if err := app.Dao().SaveWebhook(webhook); err == nil {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestFindActiveWebhooks(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhooks := []*models.Webhook{
+		{Name: "w1", CollectionId: "c1", Url: "a", Active: true, Events: []string{"create", "update"}},
+		{Name: "w2", CollectionId: "c1", Url: "a", Active: false, Events: []string{"create"}},
+		{Name: "w3", CollectionId: "c2", Url: "a", Active: true, Events: []string{"create"}},
+		{Name: "w4", CollectionId: "c1", Url: "a", Active: true, Events: []string{"delete", "create"}},
+	}
+	for _, w := range webhooks {
+		if err := app.Dao().SaveWebhook(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		collectionId string
+		event        string
+		expected     []string
+	}{
+		{"missing", "create", nil},
+		{"c1", "create", []string{"w1", "w4"}},
+		{"c1", "update", []string{"w1"}},
+		{"c1", "delete", []string{"w4"}},
+		{"c2", "update", nil},
+	}
+
+	for _, s := range scenarios {
+		result, err := app.Dao().FindActiveWebhooks(s.collectionId, s.event)
+		if err != nil {
+			t.Fatalf("[%s-%s] %v", s.collectionId, s.event, err)
+		}
+
+		if len(result) != len(s.expected) {
+			t.Fatalf("[%s-%s] Expected %d webhooks, got %d", s.collectionId, s.event, len(s.expected), len(result))
+		}
+
+		for i, name := range s.expected {
+			if result[i].Name != name {
+				t.Fatalf("[%s-%s] Expected webhook %q at position %d, got %q", s.collectionId, s.event, name, i, result[i].Name)
+			}
+		}
+	}
+}
+
+func TestSaveWebhookFailure(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := app.Dao().SaveWebhookFailure(&models.WebhookFailure{Event: "create"}); err == nil {
+		t.Fatal("Expected validation error, got nil")
+	}
+
+	failure := &models.WebhookFailure{WebhookId: "w1", Event: "create", Payload: []byte(`{"a":1}`)}
+	if err := app.Dao().SaveWebhookFailure(failure); err != nil {
+		t.Fatal(err)
+	}
+
+	failures, err := app.Dao().FindWebhookFailures("w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(failures) != 1 || failures[0].Id != failure.Id || string(failures[0].Payload) != `{"a":1}` {
+		t.Fatalf("Unexpected failures %v", failures)
+	}
+}
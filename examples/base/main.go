@@ -46,6 +46,14 @@ func main() {
 		"the total prewarm goja.Runtime instances for the JS app hooks execution",
 	)
 
+	var hooksTypeScript bool
+	app.RootCmd.PersistentFlags().BoolVar(
+		&hooksTypeScript,
+		"hooksTypeScript",
+		false,
+		"transpile the .ts JS app hooks and migrations files before their execution",
+	)
+
 	var migrationsDir string
 	app.RootCmd.PersistentFlags().StringVar(
 		&migrationsDir,
@@ -98,6 +106,7 @@ func main() {
 		HooksDir:      hooksDir,
 		HooksWatch:    hooksWatch,
 		HooksPoolSize: hooksPool,
+		TypeScript:    hooksTypeScript,
 	})
 
 	// migrate command (with js templates)
@@ -0,0 +1,126 @@
+package forms
+
+import (
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var apiKeyScopeRegex = regexp.MustCompile(`^[\w\.\:\-]+$`)
+
+// ApiKeyCreate is a [models.ApiKey] create form.
+type ApiKeyCreate struct {
+	app core.App
+	dao *daos.Dao
+
+	Name        string         `form:"name" json:"name"`
+	Collections []string       `form:"collections" json:"collections"`
+	Scopes      []string       `form:"scopes" json:"scopes"`
+	Expires     types.DateTime `form:"expires" json:"expires"`
+}
+
+// NewApiKeyCreate creates a new [ApiKeyCreate] form initialized with
+// the provided [core.App] instance.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewApiKeyCreate(app core.App) *ApiKeyCreate {
+	return &ApiKeyCreate{
+		app: app,
+		dao: app.Dao(),
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *ApiKeyCreate) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *ApiKeyCreate) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(
+			&form.Collections,
+			validation.Required,
+			validation.By(form.checkCollections),
+		),
+		validation.Field(
+			&form.Scopes,
+			validation.Required,
+			validation.Each(validation.Required, validation.Length(1, 100), validation.Match(apiKeyScopeRegex)),
+		),
+		validation.Field(&form.Expires, validation.By(form.checkExpires)),
+	)
+}
+
+func (form *ApiKeyCreate) checkCollections(value any) error {
+	v, _ := value.([]string)
+
+	for _, nameOrId := range v {
+		if _, err := form.dao.FindCollectionByNameOrId(nameOrId); err != nil {
+			return validation.NewError("validation_invalid_collection", "Missing or invalid collection "+nameOrId+".")
+		}
+	}
+
+	return nil
+}
+
+func (form *ApiKeyCreate) checkExpires(value any) error {
+	v, _ := value.(types.DateTime)
+
+	if !v.IsZero() && !v.Time().After(time.Now()) {
+		return validation.NewError("validation_expires_in_the_past", "The expiration date must be in the future.")
+	}
+
+	return nil
+}
+
+// Submit validates the form and creates a new api key.
+//
+// On success returns the plain api key (only its hash is stored
+// and there is no way to retrieve it later).
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *ApiKeyCreate) Submit(interceptors ...InterceptorFunc[*models.ApiKey]) (string, error) {
+	if err := form.Validate(); err != nil {
+		return "", err
+	}
+
+	key := models.ApiKeyPrefix + security.RandomString(40)
+
+	// normalize the collection names to ids
+	collectionIds := make([]string, 0, len(form.Collections))
+	for _, nameOrId := range form.Collections {
+		collection, err := form.dao.FindCollectionByNameOrId(nameOrId)
+		if err != nil {
+			return "", err
+		}
+		collectionIds = append(collectionIds, collection.Id)
+	}
+
+	apiKey := &models.ApiKey{
+		Name:        form.Name,
+		KeyHash:     security.SHA256(key),
+		Collections: list.ToUniqueStringSlice(collectionIds),
+		Scopes:      list.ToUniqueStringSlice(form.Scopes),
+		Expires:     form.Expires,
+	}
+
+	err := runInterceptors(apiKey, func(m *models.ApiKey) error {
+		return form.dao.SaveApiKey(m)
+	}, interceptors...)
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
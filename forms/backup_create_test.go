@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/tests"
 )
@@ -86,11 +87,16 @@ func TestBackupCreateValidateAndSubmit(t *testing.T) {
 				return
 			}
 
-			if total := len(files); total != 1 {
-				t.Fatalf("Expected 1 backup file, got %d", total)
+			// the backup and its checksum file
+			if total := len(files); total != 2 {
+				t.Fatalf("Expected 2 backup files, got %d", total)
 				return
 			}
 
+			if expected := files[0].Key + core.BackupChecksumSuffix; files[1].Key != expected {
+				t.Fatalf("Expected backup checksum file %q, got %q", expected, files[1].Key)
+			}
+
 			if s.backupName == "" {
 				prefix := "pb_backup_"
 				if !strings.HasPrefix(files[0].Key, prefix) {
@@ -0,0 +1,151 @@
+package forms
+
+import (
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// CollectionDuplicate is a form model to duplicate an existing
+// collection definition (and optionally its records) under a new name.
+type CollectionDuplicate struct {
+	app    core.App
+	dao    *daos.Dao
+	source *models.Collection
+
+	Name                 string `form:"name" json:"name"`
+	WithData             bool   `form:"withData" json:"withData"`
+	SelfRelationsToClone bool   `form:"selfRelationsToClone" json:"selfRelationsToClone"`
+}
+
+// NewCollectionDuplicate creates a new [CollectionDuplicate] form
+// initialized with from the provided [core.App] and source [models.Collection] instances.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewCollectionDuplicate(app core.App, source *models.Collection) *CollectionDuplicate {
+	return &CollectionDuplicate{
+		app:    app,
+		dao:    app.Dao(),
+		source: source,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *CollectionDuplicate) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *CollectionDuplicate) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Name,
+			validation.Required,
+			validation.Length(1, 255),
+			validation.Match(collectionNameRegex),
+			validation.By(form.checkUniqueName),
+		),
+		validation.Field(
+			&form.WithData,
+			validation.When(
+				form.source.IsView(),
+				validation.Empty.Error("View collections data cannot be copied."),
+			),
+		),
+	)
+}
+
+func (form *CollectionDuplicate) checkUniqueName(value any) error {
+	v, _ := value.(string)
+
+	if !form.dao.IsCollectionNameUnique(v) {
+		return validation.NewError("validation_collection_name_exists", "Collection name must be unique (case insensitive).")
+	}
+
+	if form.dao.FindById(&models.Collection{}, v) == nil {
+		return validation.NewError("validation_collection_name_id_duplicate", "The name must not match an existing collection id.")
+	}
+
+	return nil
+}
+
+// Submit validates the form and duplicates the source collection, aka.:
+// - creates the new collection and its records table (with regenerated field ids and indexes)
+// - if [form.WithData] is set, copies the source collection records and their files
+//
+// All db operations are wrapped in a single transaction that is
+// rollbacked on the first encountered error.
+func (form *CollectionDuplicate) Submit() (*models.Collection, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	var clone *models.Collection
+
+	txErr := form.dao.RunInTransaction(func(txDao *daos.Dao) error {
+		var err error
+
+		clone, err = txDao.DuplicateCollection(form.source, daos.DuplicateCollectionOptions{
+			Name:                 form.Name,
+			WithData:             form.WithData,
+			SelfRelationsToClone: form.SelfRelationsToClone,
+		})
+		if err != nil {
+			return err
+		}
+
+		if form.WithData && form.hasFileFields() {
+			return form.copyFiles(clone)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return clone, nil
+}
+
+func (form *CollectionDuplicate) hasFileFields() bool {
+	for _, field := range form.source.Schema.Fields() {
+		if field.Type == schema.FieldTypeFile {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyFiles copies the source collection records files to the clone
+// storage path (on failure the already copied files are deleted).
+func (form *CollectionDuplicate) copyFiles(clone *models.Collection) error {
+	fs, err := form.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	prefix := form.source.BaseFilesPath() + "/"
+
+	objects, err := fs.List(prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		dstKey := clone.BaseFilesPath() + "/" + strings.TrimPrefix(obj.Key, prefix)
+
+		if err := fs.Copy(obj.Key, dstKey); err != nil {
+			fs.DeletePrefix(clone.BaseFilesPath())
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package forms_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestCollectionDuplicateValidateAndSubmit(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		testName       string
+		source         string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{"empty data", "demo2", `{}`, []string{"name"}},
+		{"invalid name", "demo2", `{"name":"test!"}`, []string{"name"}},
+		{"existing name", "demo2", `{"name":"DEMO1"}`, []string{"name"}},
+		{"existing collection id", "demo2", `{"name":"wsmn24bux7wo113"}`, []string{"name"}},
+		{"view collection with data", "view1", `{"name":"view1_clone","withData":true}`, []string{"withData"}},
+		{"view collection without data", "view1", `{"name":"view1_clone"}`, []string{}},
+		{"base collection with data", "demo2", `{"name":"demo2_clone","withData":true}`, []string{}},
+	}
+
+	for _, s := range scenarios {
+		source, err := app.Dao().FindCollectionByNameOrId(s.source)
+		if err != nil {
+			t.Fatalf("[%s] Failed to fetch source collection: %v", s.testName, err)
+		}
+
+		form := forms.NewCollectionDuplicate(app, source)
+
+		// load data
+		loadErr := json.Unmarshal([]byte(s.jsonData), form)
+		if loadErr != nil {
+			t.Errorf("[%s] Failed to load form data: %v", s.testName, loadErr)
+			continue
+		}
+
+		clone, err := form.Submit()
+
+		// parse errors
+		errs, ok := err.(validation.Errors)
+		if !ok && err != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.testName, err)
+			continue
+		}
+
+		// check errors
+		if len(errs) > len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got %v", s.testName, s.expectedErrors, errs)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("[%s] Missing expected error key %q in %v", s.testName, k, errs)
+			}
+		}
+
+		if len(s.expectedErrors) > 0 {
+			continue
+		}
+
+		if clone.Name != form.Name || clone.Id == source.Id {
+			t.Errorf("[%s] Expected a new %q collection, got %v", s.testName, form.Name, clone)
+		}
+	}
+}
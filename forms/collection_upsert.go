@@ -141,7 +141,10 @@ func (form *CollectionUpsert) Validate() error {
 			validation.By(form.ensureNoSystemFieldsChange),
 			validation.By(form.ensureNoFieldsTypeChange),
 			validation.By(form.checkRelationFields),
+			validation.When(!isView, validation.By(form.checkFieldValidators)),
+			validation.When(!isView, validation.By(form.checkFieldRules)),
 			validation.When(isAuth, validation.By(form.ensureNoAuthFieldName)),
+			validation.When(!isView, validation.By(form.ensureNoSoftDeleteFieldName)),
 		),
 		validation.Field(&form.ListRule, validation.By(form.checkRule)),
 		validation.Field(&form.ViewRule, validation.By(form.checkRule)),
@@ -336,6 +339,75 @@ func (form *CollectionUpsert) ensureNoAuthFieldName(value any) error {
 	return nil
 }
 
+func (form *CollectionUpsert) ensureNoSoftDeleteFieldName(value any) error {
+	v, _ := value.(schema.Schema)
+
+	if softDelete, _ := form.Options.Get("softDelete").(bool); !softDelete {
+		return nil // soft delete is not enabled
+	}
+
+	errs := validation.Errors{}
+	for i, field := range v.Fields() {
+		if field.Name == schema.FieldNameDeleted {
+			errs[fmt.Sprint(i)] = validation.Errors{
+				"name": validation.NewError(
+					"validation_reserved_soft_delete_field_name",
+					"The field name is reserved for the soft delete collections and cannot be used.",
+				),
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkFieldValidators(value any) error {
+	v, _ := value.(schema.Schema)
+
+	for i, field := range v.Fields() {
+		for j, name := range field.Validators {
+			if !validators.HasFieldValidator(name, field.Type) {
+				return validation.Errors{fmt.Sprint(i): validation.Errors{
+					"validators": validation.Errors{
+						fmt.Sprint(j): validation.NewError(
+							"validation_field_unknown_validator",
+							fmt.Sprintf("Missing or unsupported %q field validator.", name),
+						),
+					}},
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkFieldRules(value any) error {
+	v, _ := value.(schema.Schema)
+
+	for i, field := range v.Fields() {
+		errs := validation.Errors{}
+
+		if err := form.checkRule(&field.ViewRule); err != nil {
+			errs["viewRule"] = err
+		}
+
+		if err := form.checkRule(&field.EditRule); err != nil {
+			errs["editRule"] = err
+		}
+
+		if len(errs) > 0 {
+			return validation.Errors{fmt.Sprint(i): errs}
+		}
+	}
+
+	return nil
+}
+
 func (form *CollectionUpsert) checkMinSchemaFields(value any) error {
 	v, _ := value.(schema.Schema)
 
@@ -433,6 +505,29 @@ func (form *CollectionUpsert) checkOptions(value any) error {
 	v, _ := value.(types.JsonMap)
 
 	switch form.Type {
+	case models.CollectionTypeBase:
+		options := models.CollectionBaseOptions{}
+		if err := decodeOptions(v, &options); err != nil {
+			return err
+		}
+
+		// check the generic validations
+		if err := options.Validate(); err != nil {
+			return err
+		}
+
+		// additional form specific validations
+		if err := form.checkSearchFields(options.SearchFields); err != nil {
+			return validation.Errors{"searchFields": err}
+		}
+
+		if err := form.checkTenantField(options.TenantField); err != nil {
+			return validation.Errors{"tenantField": err}
+		}
+
+		if err := form.checkExpireField(options.ExpireField); err != nil {
+			return validation.Errors{"expireField": err}
+		}
 	case models.CollectionTypeAuth:
 		options := models.CollectionAuthOptions{}
 		if err := decodeOptions(v, &options); err != nil {
@@ -448,6 +543,18 @@ func (form *CollectionUpsert) checkOptions(value any) error {
 		if err := form.checkRule(options.ManageRule); err != nil {
 			return validation.Errors{"manageRule": err}
 		}
+
+		if err := form.checkSearchFields(options.SearchFields); err != nil {
+			return validation.Errors{"searchFields": err}
+		}
+
+		if err := form.checkTenantField(options.TenantField); err != nil {
+			return validation.Errors{"tenantField": err}
+		}
+
+		if err := form.checkExpireField(options.ExpireField); err != nil {
+			return validation.Errors{"expireField": err}
+		}
 	case models.CollectionTypeView:
 		options := models.CollectionViewOptions{}
 		if err := decodeOptions(v, &options); err != nil {
@@ -473,6 +580,103 @@ func (form *CollectionUpsert) checkOptions(value any) error {
 	return nil
 }
 
+// checkSearchFields checks whether the provided full-text search
+// field names are existing text-like fields.
+func (form *CollectionUpsert) checkSearchFields(names []string) error {
+	for _, name := range names {
+		// the FTS5 reserved column names
+		if name == "rank" || name == "rowid" {
+			return validation.NewError(
+				"validation_reserved_search_field",
+				fmt.Sprintf("The field %q cannot be full-text indexed.", name),
+			)
+		}
+
+		if form.Type == models.CollectionTypeAuth &&
+			(name == schema.FieldNameUsername || name == schema.FieldNameEmail) {
+			continue
+		}
+
+		field := form.Schema.GetFieldByName(name)
+		if field == nil {
+			return validation.NewError(
+				"validation_missing_search_field",
+				fmt.Sprintf("Missing or invalid field %q.", name),
+			)
+		}
+
+		if !list.ExistInSlice(field.Type, []string{
+			schema.FieldTypeText,
+			schema.FieldTypeEditor,
+			schema.FieldTypeEmail,
+			schema.FieldTypeUrl,
+		}) {
+			return validation.NewError(
+				"validation_invalid_search_field",
+				fmt.Sprintf("The field %q must be text, editor, email or url field.", name),
+			)
+		}
+	}
+
+	return nil
+}
+
+// checkTenantField checks whether the provided tenant field name
+// is an existing text or single relation field.
+func (form *CollectionUpsert) checkTenantField(name string) error {
+	if name == "" {
+		return nil // not tenant-scoped
+	}
+
+	field := form.Schema.GetFieldByName(name)
+	if field == nil {
+		return validation.NewError(
+			"validation_missing_tenant_field",
+			fmt.Sprintf("Missing or invalid field %q.", name),
+		)
+	}
+
+	if field.Type == schema.FieldTypeText {
+		return nil
+	}
+
+	field.InitOptions()
+
+	if options, _ := field.Options.(*schema.RelationOptions); options == nil || options.IsMultiple() {
+		return validation.NewError(
+			"validation_invalid_tenant_field",
+			fmt.Sprintf("The field %q must be text or single relation field.", name),
+		)
+	}
+
+	return nil
+}
+
+// checkExpireField checks whether the provided records
+// expiration field name is an existing date field.
+func (form *CollectionUpsert) checkExpireField(name string) error {
+	if name == "" {
+		return nil // the records don't expire
+	}
+
+	field := form.Schema.GetFieldByName(name)
+	if field == nil {
+		return validation.NewError(
+			"validation_missing_expire_field",
+			fmt.Sprintf("Missing or invalid field %q.", name),
+		)
+	}
+
+	if field.Type != schema.FieldTypeDate {
+		return validation.NewError(
+			"validation_invalid_expire_field",
+			fmt.Sprintf("The field %q must be a date field.", name),
+		)
+	}
+
+	return nil
+}
+
 func decodeOptions(options types.JsonMap, result any) error {
 	raw, err := options.MarshalJSON()
 	if err != nil {
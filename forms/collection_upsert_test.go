@@ -7,6 +7,7 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/forms/validators"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tests"
@@ -825,3 +826,388 @@ func TestCollectionUpsertWithCustomId(t *testing.T) {
 		}
 	}
 }
+
+func TestCollectionUpsertSearchFieldsValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"missing field",
+			`{"name":"test","schema":[{"name":"title","type":"text"}],"options":{"searchFields":["missing"]}}`,
+			[]string{"options"},
+		},
+		{
+			"non text field",
+			`{"name":"test","schema":[{"name":"title","type":"text"},{"name":"num","type":"number"}],"options":{"searchFields":["num"]}}`,
+			[]string{"options"},
+		},
+		{
+			"reserved FTS5 column name",
+			`{"name":"test","schema":[{"name":"rank","type":"text"}],"options":{"searchFields":["rank"]}}`,
+			[]string{"options"},
+		},
+		{
+			"duplicated fields",
+			`{"name":"test","schema":[{"name":"title","type":"text"}],"options":{"searchFields":["title","title"]}}`,
+			[]string{"options"},
+		},
+		{
+			"base collection username",
+			`{"name":"test","schema":[{"name":"title","type":"text"}],"options":{"searchFields":["username"]}}`,
+			[]string{"options"},
+		},
+		{
+			"valid base fields",
+			`{"name":"test","schema":[{"name":"title","type":"text"},{"name":"body","type":"editor"}],"options":{"searchFields":["title","body"]}}`,
+			[]string{},
+		},
+		{
+			"valid auth fields",
+			`{"name":"test","type":"auth","schema":[{"name":"title","type":"text"}],"options":{"searchFields":["title","username","email"]}}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Validate()
+
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionUpsertFieldValidatorsValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	validators.RegisterFieldValidator("test_upsert_text_only", func(record *models.Record, field *schema.SchemaField, value any) error {
+		return nil
+	}, schema.FieldTypeText)
+	defer validators.UnregisterFieldValidator("test_upsert_text_only")
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"missing validator",
+			`{"name":"test","schema":[{"name":"title","type":"text","validators":["missing"]}]}`,
+			[]string{"schema"},
+		},
+		{
+			"unsupported field type",
+			`{"name":"test","schema":[{"name":"num","type":"number","validators":["test_upsert_text_only"]}]}`,
+			[]string{"schema"},
+		},
+		{
+			"empty validator name",
+			`{"name":"test","schema":[{"name":"title","type":"text","validators":[""]}]}`,
+			[]string{"schema"},
+		},
+		{
+			"valid validator",
+			`{"name":"test","schema":[{"name":"title","type":"text","validators":["test_upsert_text_only"]}]}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Validate()
+
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionUpsertFieldRulesValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"invalid view rule",
+			`{"name":"test","schema":[{"name":"title","type":"text","viewRule":"missing = 1"}]}`,
+			[]string{"schema"},
+		},
+		{
+			"invalid edit rule",
+			`{"name":"test","schema":[{"name":"title","type":"text","editRule":"title ="}]}`,
+			[]string{"schema"},
+		},
+		{
+			"valid rules",
+			`{"name":"test","schema":[{"name":"title","type":"text","viewRule":"@request.auth.id != ''","editRule":"title != 'locked'"}]}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Validate()
+
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionUpsertTenantFieldValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"missing field",
+			`{"name":"test","schema":[{"name":"title","type":"text"}],"options":{"tenantField":"missing"}}`,
+			[]string{"options"},
+		},
+		{
+			"non text field",
+			`{"name":"test","schema":[{"name":"num","type":"number"}],"options":{"tenantField":"num"}}`,
+			[]string{"options"},
+		},
+		{
+			"multiple relation field",
+			`{"name":"test","schema":[{"name":"rel","type":"relation","options":{"collectionId":"wsmn24bux7wo113","maxSelect":2}}],"options":{"tenantField":"rel"}}`,
+			[]string{"options"},
+		},
+		{
+			"valid text field",
+			`{"name":"test","schema":[{"name":"tenant","type":"text"}],"options":{"tenantField":"tenant"}}`,
+			[]string{},
+		},
+		{
+			"valid single relation field",
+			`{"name":"test","type":"auth","schema":[{"name":"rel","type":"relation","options":{"collectionId":"wsmn24bux7wo113","maxSelect":1}}],"options":{"tenantField":"rel","minPasswordLength":8}}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Validate()
+
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionUpsertEncryptedFieldRulesValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"encrypted field in listRule",
+			`{"name":"test","schema":[{"name":"secret","type":"encrypted"}],"listRule":"secret = 'test'"}`,
+			[]string{"listRule"},
+		},
+		{
+			"encrypted field in viewRule right operand",
+			`{"name":"test","schema":[{"name":"title","type":"text"},{"name":"secret","type":"encrypted"}],"viewRule":"title = secret"}`,
+			[]string{"viewRule"},
+		},
+		{
+			"encrypted field in updateRule via @request.data",
+			`{"name":"test","schema":[{"name":"secret","type":"encrypted"}],"updateRule":"@request.data.secret = 'test'"}`,
+			[]string{"updateRule"},
+		},
+		{
+			"rules without encrypted fields",
+			`{"name":"test","schema":[{"name":"title","type":"text"},{"name":"secret","type":"encrypted"}],"listRule":"title = 'test'"}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Validate()
+
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionUpsertExpireFieldValidation(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"missing field",
+			`{"name":"test","schema":[{"name":"title","type":"text"}],"options":{"expireField":"missing"}}`,
+			[]string{"options"},
+		},
+		{
+			"non date field",
+			`{"name":"test","schema":[{"name":"title","type":"text"}],"options":{"expireField":"title"}}`,
+			[]string{"options"},
+		},
+		{
+			"negative grace period",
+			`{"name":"test","schema":[{"name":"expires","type":"date"}],"options":{"expireField":"expires","expireGracePeriod":-1}}`,
+			[]string{"options"},
+		},
+		{
+			"valid date field",
+			`{"name":"test","schema":[{"name":"expires","type":"date"}],"options":{"expireField":"expires","expireGracePeriod":60}}`,
+			[]string{},
+		},
+		{
+			"valid auth date field",
+			`{"name":"test","type":"auth","schema":[{"name":"expires","type":"date"}],"options":{"expireField":"expires","minPasswordLength":8}}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form := forms.NewCollectionUpsert(app, &models.Collection{})
+
+			if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Validate()
+
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
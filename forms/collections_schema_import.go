@@ -0,0 +1,109 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// CollectionsSchemaImport is a form model to import a (partial) collections
+// schema (eg. exported with [core.ExportCollectionsSchema]) by applying
+// only the changes of the imported collections.
+//
+// Unlike [CollectionsImport] with DeleteMissing disabled, the schema
+// fields and indexes that are missing in an imported collection are removed.
+// The existing collections that are not part of the import are left
+// untouched (unless DeleteMissing is set).
+type CollectionsSchemaImport struct {
+	app core.App
+	dao *daos.Dao
+
+	Collections []*models.Collection `form:"collections" json:"collections"`
+
+	// DeleteMissing deletes the existing collections that
+	// are not present in the imported list.
+	DeleteMissing bool `form:"deleteMissing" json:"deleteMissing"`
+
+	// DryRun only computes the changes without applying them.
+	DryRun bool `form:"dryRun" json:"dryRun"`
+}
+
+// NewCollectionsSchemaImport creates a new [CollectionsSchemaImport] form
+// initialized with from the provided [core.App] instance.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewCollectionsSchemaImport(app core.App) *CollectionsSchemaImport {
+	return &CollectionsSchemaImport{
+		app: app,
+		dao: app.Dao(),
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *CollectionsSchemaImport) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *CollectionsSchemaImport) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Collections, validation.Required),
+	)
+}
+
+// Submit computes the changes between the imported and the existing
+// collections (see [core.DiffCollectionsSchema]) and, if not a dry run,
+// applies them within a single transaction with the same validations
+// as [CollectionsImport].
+//
+// It returns the computed (and applied) changes.
+func (form *CollectionsSchemaImport) Submit() ([]*core.CollectionSchemaDiff, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	changes, err := core.DiffCollectionsSchema(form.dao, form.Collections, form.DeleteMissing)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.DryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	collections := form.Collections
+
+	// keep the existing collections that are not part of the import
+	if !form.DeleteMissing {
+		existingCollections := []*models.Collection{}
+		if err := form.dao.CollectionQuery().OrderBy("created ASC").All(&existingCollections); err != nil {
+			return nil, err
+		}
+
+		imported := make(map[string]struct{}, len(form.Collections))
+		for _, c := range form.Collections {
+			imported[c.Id] = struct{}{}
+		}
+
+		collections = make([]*models.Collection, 0, len(existingCollections)+len(form.Collections))
+		for _, existing := range existingCollections {
+			if _, ok := imported[existing.Id]; !ok {
+				collections = append(collections, existing)
+			}
+		}
+		collections = append(collections, form.Collections...)
+	}
+
+	importForm := NewCollectionsImport(form.app)
+	importForm.SetDao(form.dao)
+	importForm.Collections = collections
+	importForm.DeleteMissing = true // the missing fields of the imported collections are removed
+
+	if err := importForm.Submit(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,152 @@
+package forms_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestCollectionsSchemaImportSubmit(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "schema_import",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "count", Type: schema.FieldTypeNumber},
+		),
+		Indexes: types.JsonArray[string]{"CREATE INDEX idx_schema_import_title ON schema_import (title)"},
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	var totalCollections int
+	if err := app.Dao().CollectionQuery().Select("count(*)").Row(&totalCollections); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := core.ExportCollectionsSchema(app, collection.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := core.ParseCollectionsSchema(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modified := imported[0]
+	modified.Id = "" // should be matched by name
+	modified.Schema.RemoveField(modified.Schema.GetFieldByName("count").Id)
+	modified.Schema.GetFieldByName("title").Required = true
+	modified.Schema.AddField(&schema.SchemaField{Name: "body", Type: schema.FieldTypeText})
+	modified.Indexes = types.JsonArray[string]{"CREATE INDEX idx_schema_import_body ON schema_import (body)"}
+
+	expectedChanges := `[{"id":"` + collection.Id + `","name":"schema_import","action":"update",` +
+		`"addedFields":["body"],"removedFields":["count"],"modifiedFields":["title"],` +
+		`"addedIndexes":["CREATE INDEX idx_schema_import_body ON schema_import (body)"],` +
+		`"removedIndexes":["CREATE INDEX idx_schema_import_title ON schema_import (title)"]}]`
+
+	// dry run
+	// ---
+	form := forms.NewCollectionsSchemaImport(app)
+	form.Collections = imported
+	form.DryRun = true
+
+	changes, err := form.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := json.Marshal(changes)
+	if string(raw) != expectedChanges {
+		t.Fatalf("Expected dry run changes\n%s\ngot\n%s", expectedChanges, raw)
+	}
+
+	unchanged, err := app.Dao().FindCollectionByNameOrId(collection.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.Schema.GetFieldByName("count") == nil {
+		t.Fatal("Expected the dry run to not modify the collection")
+	}
+
+	// apply
+	// ---
+	form.DryRun = false
+
+	changes, err = form.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ = json.Marshal(changes)
+	if string(raw) != expectedChanges {
+		t.Fatalf("Expected applied changes\n%s\ngot\n%s", expectedChanges, raw)
+	}
+
+	updated, err := app.Dao().FindCollectionByNameOrId(collection.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Schema.GetFieldByName("count") != nil {
+		t.Error("Expected the count field to be removed")
+	}
+	if f := updated.Schema.GetFieldByName("body"); f == nil {
+		t.Error("Expected the body field to be added")
+	}
+	if f := updated.Schema.GetFieldByName("title"); f == nil || !f.Required {
+		t.Errorf("Expected the title field to be required, got %v", f)
+	}
+	if len(updated.Indexes) != 1 || updated.Indexes[0] != modified.Indexes[0] {
+		t.Errorf("Expected indexes %v, got %v", modified.Indexes, updated.Indexes)
+	}
+
+	columns, err := app.Dao().TableColumns(collection.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedColumns := map[string]bool{"title": true, "body": true, "count": false}
+	for column, exists := range expectedColumns {
+		var found bool
+		for _, c := range columns {
+			if c == column {
+				found = true
+				break
+			}
+		}
+		if found != exists {
+			t.Errorf("Expected column %q exists to be %v", column, exists)
+		}
+	}
+
+	// the other collections are left untouched
+	var newTotalCollections int
+	if err := app.Dao().CollectionQuery().Select("count(*)").Row(&newTotalCollections); err != nil {
+		t.Fatal(err)
+	}
+	if newTotalCollections != totalCollections {
+		t.Fatalf("Expected %d collections, got %d", totalCollections, newTotalCollections)
+	}
+
+	// resubmit (no changes)
+	// ---
+	changes, err = form.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Expected no changes on resubmit, got %v", changes)
+	}
+}
@@ -0,0 +1,214 @@
+package forms
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// storedFilenameRegex defines the allowed characters of a normalized file name.
+var storedFilenameRegex = regexp.MustCompile(`^[\w\.\*\-\+\=\#]+$`)
+
+// mimeSniffLength is the number of the file leading bytes used for the mime type detection.
+const mimeSniffLength = 3072
+
+// RecordFileConfirm is a record file field direct upload confirmation form
+// (aka. the second step after [RecordFilePresign]).
+type RecordFileConfirm struct {
+	app    core.App
+	dao    *daos.Dao
+	record *models.Record
+	field  *schema.SchemaField
+
+	// filename is the uploaded file name resolved from the token claims.
+	filename string
+
+	Token string `form:"token" json:"token"`
+}
+
+// NewRecordFileConfirm creates a new [RecordFileConfirm] form
+// initialized with from the provided [core.App] and [models.Record] instances.
+//
+// Returns an error if fieldName is not a file field of the record collection.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewRecordFileConfirm(app core.App, record *models.Record, fieldName string) (*RecordFileConfirm, error) {
+	field := record.Collection().Schema.GetFieldByName(fieldName)
+	if field == nil || field.Type != schema.FieldTypeFile {
+		return nil, errors.New("invalid file field")
+	}
+
+	return &RecordFileConfirm{
+		app:    app,
+		dao:    app.Dao(),
+		record: record,
+		field:  field,
+	}, nil
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *RecordFileConfirm) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+//
+// This method doesn't check the uploaded file itself (this is done on Submit).
+func (form *RecordFileConfirm) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Token,
+			validation.Required,
+			validation.By(form.checkToken),
+		),
+	)
+}
+
+// checkToken verifies that the token was issued by [RecordFilePresign]
+// for the form record and field and resolves the uploaded file name from it.
+func (form *RecordFileConfirm) checkToken(value any) error {
+	v, _ := value.(string)
+
+	form.filename = ""
+
+	claims, err := security.ParseJWT(v, form.app.Settings().RecordFileToken.Secret)
+	if err != nil {
+		return validation.NewError("validation_invalid_token", "Invalid or expired token.")
+	}
+
+	tokenType, _ := claims["type"].(string)
+	recordId, _ := claims["id"].(string)
+	collectionId, _ := claims["collectionId"].(string)
+	field, _ := claims["field"].(string)
+	filename, _ := claims["filename"].(string)
+
+	if tokenType != tokens.TypeRecordUpload ||
+		recordId != form.record.Id ||
+		collectionId != form.record.Collection().Id ||
+		field != form.field.Name ||
+		len(filename) > 255 ||
+		!storedFilenameRegex.MatchString(filename) {
+		return validation.NewError("validation_invalid_token_payload", "The token is not issued for the current record file field.")
+	}
+
+	// the file could be already assigned to any of the record file fields
+	for _, f := range form.record.Collection().Schema.Fields() {
+		if f.Type == schema.FieldTypeFile && list.ExistInSlice(filename, form.record.GetStringSlice(f.Name)) {
+			return validation.NewError("validation_file_already_assigned", "The file is already assigned to the record.")
+		}
+	}
+
+	form.filename = filename
+
+	return nil
+}
+
+// Submit validates the form and the uploaded file constraints
+// (max size and mime types) and assigns the file to the record field.
+//
+// The uploaded file is deleted if it doesn't satisfy the file field constraints
+// (only the file issued by the presigned token is ever deleted).
+//
+// You can optionally provide a list of InterceptorFunc to further
+// modify the form behavior before persisting it.
+func (form *RecordFileConfirm) Submit(interceptors ...InterceptorFunc[*models.Record]) error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	fsys, err := form.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	key := form.record.BaseFilesPath() + "/" + form.filename
+
+	if exists, _ := fsys.Exists(key); !exists {
+		return validation.Errors{"token": validation.NewError(
+			"validation_missing_uploaded_file",
+			"Missing uploaded file.",
+		)}
+	}
+
+	if err := form.checkUploadedFile(fsys, key); err != nil {
+		if _, ok := err.(validation.Error); !ok {
+			return err
+		}
+
+		// cleanup the invalid upload
+		if deleteErr := fsys.Delete(key); deleteErr != nil {
+			form.app.Logger().Debug(
+				"Failed to delete invalid uploaded file",
+				slog.String("key", key),
+				slog.String("error", deleteErr.Error()),
+			)
+		}
+
+		return validation.Errors{"token": err}
+	}
+
+	upsert := NewRecordUpsert(form.app, form.record)
+	upsert.SetDao(form.dao)
+
+	if err := upsert.AddStoredFiles(form.field.Name, form.filename); err != nil {
+		return err
+	}
+
+	return upsert.Submit(interceptors...)
+}
+
+func (form *RecordFileConfirm) checkUploadedFile(fsys *filesystem.System, key string) error {
+	attrs, err := fsys.Attributes(key)
+	if err != nil {
+		return err
+	}
+
+	br, err := fsys.GetFile(key)
+	if err != nil {
+		return err
+	}
+	defer br.Close()
+
+	head, err := io.ReadAll(io.LimitReader(br, mimeSniffLength))
+	if err != nil {
+		return err
+	}
+
+	file := &filesystem.File{
+		Name:         form.filename,
+		OriginalName: form.filename,
+		Size:         attrs.Size,
+		Reader:       &filesystem.BytesReader{Bytes: head},
+	}
+
+	options, _ := form.field.Options.(*schema.FileOptions)
+	if options == nil {
+		return errors.New("invalid file field options")
+	}
+
+	if err := validators.UploadedFileSize(options.MaxSize)(file); err != nil {
+		return err
+	}
+
+	if len(options.MimeTypes) > 0 {
+		if err := validators.UploadedFileMimeType(options.MimeTypes)(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package forms_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tokens"
+)
+
+func TestNewRecordFileConfirm(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := forms.NewRecordFileConfirm(app, record, "missing"); err == nil {
+		t.Fatal("Expected missing field error")
+	}
+
+	if _, err := forms.NewRecordFileConfirm(app, record, "name"); err == nil {
+		t.Fatal("Expected non-file field error")
+	}
+
+	if _, err := forms.NewRecordFileConfirm(app, record, "avatar"); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+}
+
+func TestRecordFileConfirmSubmit(t *testing.T) {
+	t.Parallel()
+
+	pngContent := &bytes.Buffer{}
+	if err := png.Encode(pngContent, image.Rect(0, 0, 1, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// token options
+	const (
+		tokenNone = iota
+		tokenValid
+		tokenInvalid
+		tokenOtherRecord
+		tokenOtherField
+	)
+
+	scenarios := []struct {
+		name          string
+		field         string
+		filename      string
+		token         int
+		upload        []byte
+		expectedError string
+		expectDeleted bool
+	}{
+		{"empty token", "avatar", "test_abcdefghij.png", tokenNone, []byte("test"), "token", false},
+		{"invalid token", "avatar", "test_abcdefghij.png", tokenInvalid, []byte("test"), "token", false},
+		{"token for another record", "avatar", "test_abcdefghij.png", tokenOtherRecord, []byte("test"), "token", false},
+		{"token for another field", "avatar", "test_abcdefghij.png", tokenOtherField, []byte("test"), "token", false},
+		{"invalid token filename", "avatar", "../test.png", tokenValid, nil, "token", false},
+		{"already assigned file", "avatar", "300_1SEi6Q6U72.png", tokenValid, nil, "token", false},
+		{"missing uploaded file", "avatar", "missing_abcdefghij.png", tokenValid, nil, "token", false},
+		{"not allowed mime type", "avatar", "test_abcdefghij.png", tokenValid, []byte("test"), "token", true},
+		{"max size limit", "file", "test_abcdefghij.txt", tokenValid, bytes.Repeat([]byte("a"), 5242881), "token", true},
+		{"valid uploaded file (single)", "avatar", "test_abcdefghij.png", tokenValid, pngContent.Bytes(), "", false},
+		{"valid uploaded file (multiple)", "file", "test_abcdefghij.txt", tokenValid, []byte("test"), "", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oldAvatar := record.GetString("avatar")
+
+			if s.upload != nil {
+				fs, err := app.NewFilesystem()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer fs.Close()
+
+				if err := fs.Upload(s.upload, record.BaseFilesPath()+"/"+s.filename); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			form, err := forms.NewRecordFileConfirm(app, record, s.field)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch s.token {
+			case tokenValid:
+				form.Token, err = tokens.NewRecordUploadToken(app, record, s.field, s.filename, 60)
+			case tokenInvalid:
+				form.Token, err = tokens.NewRecordUploadToken(app, record, s.field, s.filename, -60)
+			case tokenOtherRecord:
+				other, findErr := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+				if findErr != nil {
+					t.Fatal(findErr)
+				}
+				form.Token, err = tokens.NewRecordUploadToken(app, other, s.field, s.filename, 60)
+			case tokenOtherField:
+				form.Token, err = tokens.NewRecordUploadToken(app, record, "file", s.filename, 60)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			interceptorCalls := 0
+			interceptor := func(next forms.InterceptorNextFunc[*models.Record]) forms.InterceptorNextFunc[*models.Record] {
+				return func(r *models.Record) error {
+					interceptorCalls++
+					return next(r)
+				}
+			}
+
+			submitErr := form.Submit(interceptor)
+
+			if s.expectedError == "" {
+				if submitErr != nil {
+					t.Fatalf("Expected nil error, got %v", submitErr)
+				}
+
+				if interceptorCalls != 1 {
+					t.Fatalf("Expected the interceptor to be called once, got %d", interceptorCalls)
+				}
+
+				recordAfter, err := app.Dao().FindRecordById("users", record.Id)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if s.field == "avatar" {
+					if v := recordAfter.GetString("avatar"); v != s.filename {
+						t.Fatalf("Expected avatar %q, got %q", s.filename, v)
+					}
+
+					if hasRecordFile(app, recordAfter, oldAvatar) {
+						t.Fatalf("Expected the old avatar %q to be deleted", oldAvatar)
+					}
+				} else if v := recordAfter.GetStringSlice(s.field); len(v) == 0 || v[len(v)-1] != s.filename {
+					t.Fatalf("Expected %q to be appended to %v", s.filename, v)
+				}
+
+				return
+			}
+
+			errs, ok := submitErr.(validation.Errors)
+			if !ok {
+				t.Fatalf("Expected validation errors, got %v", submitErr)
+			}
+
+			if _, ok := errs[s.expectedError]; !ok || len(errs) != 1 {
+				t.Fatalf("Expected only %q error, got %v", s.expectedError, errs)
+			}
+
+			if interceptorCalls != 0 {
+				t.Fatalf("Expected the interceptor to not be called, got %d", interceptorCalls)
+			}
+
+			if s.upload != nil && hasRecordFile(app, record, s.filename) == s.expectDeleted {
+				t.Fatalf("Expected the uploaded file deleted state to be %v", s.expectDeleted)
+			}
+
+			if !hasRecordFile(app, record, oldAvatar) {
+				t.Fatalf("Expected the old avatar %q to remain", oldAvatar)
+			}
+		})
+	}
+}
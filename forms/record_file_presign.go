@@ -0,0 +1,156 @@
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// RecordFilePresignResult defines the result of a successfully submitted [RecordFilePresign] form.
+type RecordFilePresignResult struct {
+	// Url is the presigned PUT url that the client should upload the file to.
+	Url string `json:"url"`
+
+	// Key is the final storage key of the file.
+	Key string `json:"key"`
+
+	// Filename is the normalized file name of the uploaded file.
+	Filename string `json:"filename"`
+
+	// Token is the upload confirmation token that should be
+	// submitted with the [RecordFileConfirm] form after the upload.
+	Token string `json:"token"`
+
+	// Expires is the presigned url expiration date.
+	Expires types.DateTime `json:"expires"`
+}
+
+// uploadConfirmWindow is the extra time after the presigned
+// url expiration to confirm the uploaded file.
+const uploadConfirmWindow = time.Hour
+
+// RecordFilePresign is a record file field direct upload url request form.
+type RecordFilePresign struct {
+	app    core.App
+	record *models.Record
+	field  *schema.SchemaField
+	expiry time.Duration
+
+	Filename    string `form:"filename" json:"filename"`
+	Size        int64  `form:"size" json:"size"`
+	ContentType string `form:"contentType" json:"contentType"`
+}
+
+// NewRecordFilePresign creates a new [RecordFilePresign] form
+// initialized with from the provided [core.App] and [models.Record] instances.
+//
+// Returns an error if fieldName is not a file field of the record collection.
+func NewRecordFilePresign(app core.App, record *models.Record, fieldName string) (*RecordFilePresign, error) {
+	field := record.Collection().Schema.GetFieldByName(fieldName)
+	if field == nil || field.Type != schema.FieldTypeFile {
+		return nil, errors.New("invalid file field")
+	}
+
+	return &RecordFilePresign{
+		app:    app,
+		record: record,
+		field:  field,
+		expiry: filesystem.DefaultPresignUploadExpiry,
+	}, nil
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+//
+// The Size and ContentType are client declared values and are used
+// only for early rejection (the uploaded file is validated again on confirm).
+func (form *RecordFilePresign) Validate() error {
+	options, _ := form.field.Options.(*schema.FileOptions)
+	if options == nil {
+		return errors.New("invalid file field options")
+	}
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Filename, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Size, validation.Min(0), validation.Max(int64(options.MaxSize))),
+		validation.Field(
+			&form.ContentType,
+			validation.When(len(options.MimeTypes) > 0, validation.Required, validation.By(form.checkContentType(options.MimeTypes))),
+		),
+	)
+}
+
+func (form *RecordFilePresign) checkContentType(validTypes []string) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		// strip the optional parameters (eg. "text/plain; charset=utf-8")
+		v = strings.ToLower(strings.TrimSpace(strings.Split(v, ";")[0]))
+
+		for _, t := range validTypes {
+			if strings.EqualFold(t, v) {
+				return nil // valid
+			}
+		}
+
+		return validation.NewError(
+			"validation_invalid_mime_type",
+			fmt.Sprintf("The mime type must be one of: %s.", strings.Join(validTypes, ", ")),
+		)
+	}
+}
+
+// Submit validates the form and generates a new short-lived
+// presigned upload url for the record file field.
+func (form *RecordFilePresign) Submit() (*RecordFilePresignResult, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	fsys, err := form.app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+
+	filename := filesystem.NormalizeName(form.Filename)
+	key := form.record.BaseFilesPath() + "/" + filename
+	expires := time.Now().Add(form.expiry)
+
+	url, err := fsys.PresignUpload(key, &filesystem.PresignUploadOptions{Expiry: form.expiry})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := tokens.NewRecordUploadToken(
+		form.app,
+		form.record,
+		form.field.Name,
+		filename,
+		int64((form.expiry + uploadConfirmWindow).Seconds()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RecordFilePresignResult{
+		Url:      url,
+		Key:      key,
+		Filename: filename,
+		Token:    token,
+	}
+	result.Expires, _ = types.ParseDateTime(expires)
+
+	return result, nil
+}
@@ -0,0 +1,175 @@
+package forms_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewRecordFilePresign(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := forms.NewRecordFilePresign(app, record, "missing"); err == nil {
+		t.Fatal("Expected missing field error")
+	}
+
+	if _, err := forms.NewRecordFilePresign(app, record, "name"); err == nil {
+		t.Fatal("Expected non-file field error")
+	}
+
+	if _, err := forms.NewRecordFilePresign(app, record, "avatar"); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+}
+
+func TestRecordFilePresignValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		field          string
+		data           string
+		expectedErrors []string
+	}{
+		{
+			"empty data (mime restricted field)",
+			"avatar",
+			`{}`,
+			[]string{"filename", "contentType"},
+		},
+		{
+			"empty data (unrestricted field)",
+			"file",
+			`{}`,
+			[]string{"filename"},
+		},
+		{
+			"invalid data",
+			"avatar",
+			`{"filename":"` + strings.Repeat("a", 256) + `","size":5242881,"contentType":"text/plain"}`,
+			[]string{"filename", "size", "contentType"},
+		},
+		{
+			"valid data",
+			"avatar",
+			`{"filename":"test.png","size":100,"contentType":"image/png"}`,
+			[]string{},
+		},
+		{
+			"valid data (content type with params)",
+			"avatar",
+			`{"filename":"test.png","contentType":"IMAGE/PNG; foo=bar"}`,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			form, err := forms.NewRecordFilePresign(app, record, s.field)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(s.data), form); err != nil {
+				t.Fatal(err)
+			}
+
+			result := form.Validate()
+
+			// parse errors
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got %v", s.expectedErrors, errs)
+			}
+
+			for _, k := range s.expectedErrors {
+				if _, ok := errs[k]; !ok {
+					t.Fatalf("Missing expected error key %q in %v", k, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordFilePresignSubmit(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := forms.NewRecordFilePresign(app, record, "file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	form.Filename = "My File.txt"
+
+	// local filesystem (signing is not supported)
+	if _, err := form.Submit(); err == nil {
+		t.Fatal("Expected local filesystem presign error")
+	}
+
+	// S3 filesystem
+	// (the presigned url is generated offline so the endpoint doesn't need to exist)
+	app.Settings().S3.Enabled = true
+	app.Settings().S3.Endpoint = "https://s3.example.com"
+	app.Settings().S3.Bucket = "test"
+	app.Settings().S3.Region = "test"
+	app.Settings().S3.AccessKey = "test"
+	app.Settings().S3.Secret = "test"
+	app.Settings().S3.ForcePathStyle = true
+
+	result, err := form.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(result.Filename, "my_file_") || !strings.HasSuffix(result.Filename, ".txt") {
+		t.Fatalf("Expected normalized filename, got %q", result.Filename)
+	}
+
+	expectedKey := record.BaseFilesPath() + "/" + result.Filename
+	if result.Key != expectedKey {
+		t.Fatalf("Expected key %q, got %q", expectedKey, result.Key)
+	}
+
+	if !strings.HasPrefix(result.Url, "https://s3.example.com/test/"+expectedKey+"?") || !strings.Contains(result.Url, "X-Amz-Signature=") {
+		t.Fatalf("Expected presigned S3 url, got %q", result.Url)
+	}
+
+	if result.Expires.IsZero() {
+		t.Fatal("Expected non-zero expires date")
+	}
+
+	if result.Token == "" {
+		t.Fatal("Expected non-empty confirm token")
+	}
+}
@@ -284,6 +284,21 @@ func (form *RecordOAuth2Login) submit(data *RecordOAuth2LoginData) error {
 				Provider:     form.Provider,
 				ProviderId:   data.OAuth2User.Id,
 			}
+		}
+
+		// store the latest encrypted OAuth2 tokens
+		// (they are skipped if there is no encryption key)
+		tokensErr := txDao.SetExternalAuthTokens(
+			data.ExternalAuth,
+			data.OAuth2User.AccessToken,
+			data.OAuth2User.RefreshToken,
+			data.OAuth2User.Expiry,
+		)
+		if tokensErr != nil && !errors.Is(tokensErr, daos.ErrMissingEncryptionKey) {
+			return tokensErr
+		}
+
+		if tokensErr == nil || data.ExternalAuth.IsNew() {
 			if err := txDao.SaveExternalAuth(data.ExternalAuth); err != nil {
 				return err
 			}
@@ -3,6 +3,7 @@ package forms
 import (
 	"database/sql"
 	"errors"
+	"log/slog"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
@@ -19,6 +20,10 @@ type RecordPasswordLogin struct {
 
 	Identity string `form:"identity" json:"identity"`
 	Password string `form:"password" json:"password"`
+
+	// TOTPCode is the authenticator app or recovery code that is
+	// required if the auth record has a verified TOTP enrollment.
+	TOTPCode string `form:"totpCode" json:"totpCode"`
 }
 
 // NewRecordPasswordLogin creates a new [RecordPasswordLogin] form initialized
@@ -44,6 +49,7 @@ func (form *RecordPasswordLogin) Validate() error {
 	return validation.ValidateStruct(form,
 		validation.Field(&form.Identity, validation.Required, validation.Length(1, 255)),
 		validation.Field(&form.Password, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.TOTPCode, validation.Length(1, 255)),
 	)
 }
 
@@ -84,12 +90,68 @@ func (form *RecordPasswordLogin) Submit(interceptors ...InterceptorFunc[*models.
 			return errors.New("Invalid login credentials.")
 		}
 
-		return nil
+		return form.checkTOTP(authRecord)
 	}, interceptors...)
 
 	if interceptorsErr != nil {
 		return nil, interceptorsErr
 	}
 
+	// transparently upgrade the password hash to the configured algorithm
+	// (the error is only logged to not prevent the otherwise successful login)
+	if authRecord.PasswordNeedsRehash() {
+		if err := form.rehashPassword(authRecord); err != nil {
+			form.app.Logger().Warn(
+				"Failed to rehash the auth record password",
+				slog.String("recordId", authRecord.Id),
+				slog.String("collectionName", authRecord.Collection().Name),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
 	return authRecord, nil
 }
+
+// checkTOTP checks the submitted TOTP code if the auth record
+// has a verified TOTP enrollment.
+func (form *RecordPasswordLogin) checkTOTP(authRecord *models.Record) error {
+	totp, err := form.dao.FindTOTPByRecord(authRecord)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil // no TOTP enrollment
+		}
+		return err
+	}
+
+	if !totp.Verified {
+		return nil // not confirmed yet
+	}
+
+	if form.TOTPCode == "" {
+		return validation.Errors{"totpCode": validation.NewError(
+			"validation_totp_required",
+			"The TOTP code is required.",
+		)}
+	}
+
+	if err := form.dao.UseTOTPCode(totp, form.TOTPCode); err != nil {
+		if errors.Is(err, daos.ErrInvalidTOTPCode) {
+			return validation.Errors{"totpCode": validation.NewError(
+				"validation_invalid_totp_code",
+				"Invalid or already used TOTP code.",
+			)}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (form *RecordPasswordLogin) rehashPassword(authRecord *models.Record) error {
+	if err := authRecord.RehashPassword(form.Password); err != nil {
+		return err
+	}
+
+	return form.dao.WithoutHooks().SaveRecord(authRecord)
+}
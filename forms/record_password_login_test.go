@@ -2,6 +2,7 @@ package forms_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/forms"
@@ -184,3 +185,86 @@ func TestRecordPasswordLoginInterceptors(t *testing.T) {
 		t.Fatalf("Expected auth Record model with email %s, got %v", form.Identity, interceptorRecord)
 	}
 }
+
+func TestRecordPasswordLoginRehash(t *testing.T) {
+	t.Parallel()
+
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	collection, err := testApp.Dao().FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(password string) (*models.Record, error) {
+		form := forms.NewRecordPasswordLogin(testApp, collection)
+		form.Identity = "test@example.com"
+		form.Password = password
+		return form.Submit()
+	}
+
+	findRecord := func() *models.Record {
+		record, err := testApp.Dao().FindAuthRecordByEmail(collection.Id, "test@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	findHash := func() string {
+		return findRecord().PasswordHash()
+	}
+
+	original := findRecord()
+	originalHash := original.PasswordHash()
+
+	// without explicit password hash options the legacy hash is left as it is
+	if _, err := login("1234567890"); err != nil {
+		t.Fatal(err)
+	}
+	if hash := findHash(); hash != originalHash {
+		t.Fatalf("Expected the password hash to be unchanged, got %q", hash)
+	}
+
+	// configure argon2id
+	collection.Options["passwordHash"] = map[string]any{
+		"algorithm":           "argon2id",
+		"argon2idMemory":      8 * 1024,
+		"argon2idIterations":  1,
+		"argon2idParallelism": 1,
+	}
+	if err := testApp.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// failed login shouldn't rehash
+	if _, err := login("invalid"); err == nil {
+		t.Fatal("Expected login error")
+	}
+	if hash := findHash(); hash != originalHash {
+		t.Fatalf("Expected the password hash to be unchanged after failed login, got %q", hash)
+	}
+
+	// successful login with the legacy bcrypt hash
+	if _, err := login("1234567890"); err != nil {
+		t.Fatal(err)
+	}
+
+	rehashed := findHash()
+	if !strings.HasPrefix(rehashed, "$argon2id$v=19$m=8192,t=1,p=1$") {
+		t.Fatalf("Expected the password to be rehashed with argon2id, got %q", rehashed)
+	}
+
+	if findRecord().TokenKey() != original.TokenKey() {
+		t.Fatal("Expected the tokenKey to be unchanged after the rehash")
+	}
+
+	// subsequent login with the new hash
+	if _, err := login("1234567890"); err != nil {
+		t.Fatal(err)
+	}
+	if hash := findHash(); hash != rehashed {
+		t.Fatalf("Expected the argon2id hash to not be rehashed again, got %q", hash)
+	}
+}
@@ -17,22 +17,30 @@ import (
 	"github.com/pocketbase/pocketbase/forms/validators"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
 )
 
 // username value regex pattern
 var usernameRegex = regexp.MustCompile(`^[\w][\w\.\-]*$`)
 
+// errDrySubmitRollback is used to force rollback the DrySubmit savepoint.
+var errDrySubmitRollback = errors.New("dry submit rollback")
+
 // RecordUpsert is a [models.Record] upsert (create/update) form.
 type RecordUpsert struct {
 	app          core.App
 	dao          *daos.Dao
 	manageAccess bool
 	record       *models.Record
+	versionCheck func(persistedUpdated types.DateTime) bool
+
+	drySubmitSavepoint bool
 
 	filesToUpload map[string][]*filesystem.File
 	filesToDelete []string // names list
@@ -91,6 +99,24 @@ func (form *RecordUpsert) SetDao(dao *daos.Dao) {
 	form.dao = dao
 }
 
+// SetVersionCheck registers an optimistic concurrency check that
+// is executed against the persisted record "updated" value in the
+// same transaction as the record update (see [daos.Dao.SaveRecordIfUnmodified]).
+//
+// The check is ignored when creating a new record.
+func (form *RecordUpsert) SetVersionCheck(check func(persistedUpdated types.DateTime) bool) {
+	form.versionCheck = check
+}
+
+// SetDrySubmitSavepoint enables running [RecordUpsert.DrySubmit] within
+// a savepoint of the form Dao transaction (if any) so that the not yet
+// committed changes of the outer transaction are visible to the dry submit.
+//
+// By default DrySubmit always runs in a separate transaction.
+func (form *RecordUpsert) SetDrySubmitSavepoint(enable bool) {
+	form.drySubmitSavepoint = enable
+}
+
 func (form *RecordUpsert) loadFormDefaults() {
 	form.Id = form.record.Id
 
@@ -151,9 +177,40 @@ func (form *RecordUpsert) extractJsonData(
 		}
 	}
 
+	if encodeErr := form.encodeJsonFieldStrings(data); encodeErr != nil {
+		return nil, nil, encodeErr
+	}
+
 	return data, nil, err
 }
 
+// encodeJsonFieldStrings encodes the string values of the json fields
+// with enabled [schema.JsonOptions.PreserveStrings] as json strings.
+//
+// The other json field string values are normalized as usual
+// (eg. "null" is loaded as json null).
+func (form *RecordUpsert) encodeJsonFieldStrings(data map[string]any) error {
+	for _, field := range form.record.Collection().Schema.Fields() {
+		if field.Type != schema.FieldTypeJson {
+			continue
+		}
+
+		if options, _ := field.Options.(*schema.JsonOptions); options == nil || !options.PreserveStrings {
+			continue
+		}
+
+		if str, ok := data[field.Name].(string); ok {
+			encoded, err := json.Marshal(str)
+			if err != nil {
+				return err
+			}
+			data[field.Name] = types.JsonRaw(encoded)
+		}
+	}
+
+	return nil
+}
+
 func (form *RecordUpsert) extractMultipartFormData(
 	r *http.Request,
 	keyPrefix string,
@@ -167,24 +224,43 @@ func (form *RecordUpsert) extractMultipartFormData(
 	filesToUpload := map[string][]*filesystem.File{}
 	arraybleFieldTypes := schema.ArraybleFieldTypes()
 
+	// load first the special multipart json payload (if any) so that
+	// the regular multipart fields take precedence over its keys
+	// (same as in rest.BindBody)
+	payloadKeys := []string{rest.MultipartJsonKey}
+	if keyPrefix != "" {
+		// the prefixed payload has precedence over the unprefixed one
+		payloadKeys = append(payloadKeys, keyPrefix+"."+rest.MultipartJsonKey)
+	}
+	for _, fullKey := range payloadKeys {
+		for _, v := range r.PostForm[fullKey] {
+			payload := map[string]any{}
+			if err := json.Unmarshal([]byte(v), &payload); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s value: %w", rest.MultipartJsonKey, err)
+			}
+
+			if err := form.encodeJsonFieldStrings(payload); err != nil {
+				return nil, nil, err
+			}
+
+			for k, pv := range payload {
+				data[k] = pv
+			}
+		}
+	}
+
 	for fullKey, values := range r.PostForm {
 		key := fullKey
 		if keyPrefix != "" {
 			key = strings.TrimPrefix(key, keyPrefix+".")
 		}
 
-		if len(values) == 0 {
-			data[key] = nil
-			continue
+		if key == rest.MultipartJsonKey {
+			continue // already loaded
 		}
 
-		// special case for multipart json encoded fields
-		if key == rest.MultipartJsonKey {
-			for _, v := range values {
-				if err := json.Unmarshal([]byte(v), &data); err != nil {
-					form.app.Logger().Debug("Failed to decode @json value into the data map", "error", err, "value", v)
-				}
-			}
+		if len(values) == 0 {
+			data[key] = nil
 			continue
 		}
 
@@ -315,6 +391,49 @@ func (form *RecordUpsert) AddFiles(key string, files ...*filesystem.File) error
 	return nil
 }
 
+// AddStoredFiles adds a single or multiple already stored file names
+// (eg. direct presigned uploads) to the specified file field.
+//
+// The files must be located in the record files directory and,
+// unlike [AddFiles], they are not uploaded and their size and
+// type are not validated by the form.
+//
+// The same replace/append rules as in [AddFiles] apply.
+//
+// Example
+//
+//	form.AddStoredFiles("documents", "file1_aw4bdrvws6.txt")
+func (form *RecordUpsert) AddStoredFiles(key string, filenames ...string) error {
+	field := form.record.Collection().Schema.GetFieldByName(key)
+	if field == nil || field.Type != schema.FieldTypeFile {
+		return errors.New("invalid field key")
+	}
+
+	options, ok := field.Options.(*schema.FileOptions)
+	if !ok {
+		return errors.New("failed to initilize field options")
+	}
+
+	if len(filenames) == 0 {
+		return nil // nothing to add
+	}
+
+	oldNames := list.ToUniqueStringSlice(form.data[key])
+
+	if options.MaxSelect == 1 {
+		// mark previous file(s) for deletion before replacing
+		if len(oldNames) > 0 {
+			form.filesToDelete = list.ToUniqueStringSlice(append(form.filesToDelete, oldNames...))
+		}
+
+		form.data[key] = field.PrepareValue(filenames[0])
+	} else {
+		form.data[key] = field.PrepareValue(append(oldNames, filenames...))
+	}
+
+	return nil
+}
+
 // RemoveFiles removes a single or multiple file from the specified file field.
 //
 // NB! If filesToDelete is not set it will remove all existing files
@@ -363,6 +482,10 @@ func (form *RecordUpsert) RemoveFiles(key string, toDelete ...string) error {
 }
 
 // LoadData loads and normalizes the provided regular record data fields into the form.
+//
+// The data is applied as a merge-patch, aka. the fields that are not present
+// in requestData keep their current value and the fields with explicit nil value
+// are cleared (eg. "" for text, 0 for number, null for json, [] for multiple relation).
 func (form *RecordUpsert) LoadData(requestData map[string]any) error {
 	// load base system fields
 	if v, ok := requestData[schema.FieldNameId]; ok {
@@ -397,24 +520,31 @@ func (form *RecordUpsert) LoadData(requestData map[string]any) error {
 	// replace modifiers (if any)
 	requestData = form.record.ReplaceModifers(requestData)
 
-	// create a shallow copy of form.data
-	var extendedData = make(map[string]any, len(form.data))
-	for k, v := range form.data {
-		extendedData[k] = v
-	}
-
-	// extend form.data with the request data
+	// normalize the request data values
 	rawData, err := json.Marshal(requestData)
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(rawData, &extendedData); err != nil {
+	normalizedData := make(map[string]any, len(requestData))
+	if err := json.Unmarshal(rawData, &normalizedData); err != nil {
 		return err
 	}
 
 	for _, field := range form.record.Collection().Schema.Fields() {
 		key := field.Name
-		value := field.PrepareValue(extendedData[key])
+
+		// keep the current value of the omitted fields
+		rawValue, ok := requestData[key]
+		if !ok {
+			continue
+		}
+
+		value := field.PrepareValue(normalizedData[key])
+
+		// already encoded json value (eg. a json body string)
+		if raw, ok := rawValue.(types.JsonRaw); ok && field.Type == schema.FieldTypeJson {
+			value = field.PrepareValue(raw)
+		}
 
 		if field.Type != schema.FieldTypeFile {
 			form.data[key] = value
@@ -703,8 +833,50 @@ func (form *RecordUpsert) DrySubmit(callback func(txDao *daos.Dao) error) error
 		return err
 	}
 
+	dryFunc := func(txDao *daos.Dao) error {
+		var saveErr error
+		if form.versionCheck != nil && !isNew {
+			saveErr = txDao.SaveRecordIfUnmodified(form.record, form.versionCheck)
+		} else {
+			saveErr = txDao.SaveRecord(form.record)
+		}
+		if saveErr != nil {
+			return form.prepareError(saveErr)
+		}
+
+		// restore record isNew state
+		if isNew {
+			form.record.MarkAsNew()
+		}
+
+		if callback != nil {
+			return callback(txDao)
+		}
+
+		return nil
+	}
+
+	isInTransaction := form.dao.ConcurrentDB() == form.dao.NonconcurrentDB()
+
+	if isInTransaction && form.drySubmitSavepoint {
+		// use a savepoint so that the changes from the outer transaction are visible
+		// (and to prevent locking the db with a second write transaction)
+		var dryErr error
+
+		spErr := daos.New(form.dao.NonconcurrentDB()).RunInSavepoint(func(txDao *daos.Dao) error {
+			dryErr = dryFunc(txDao)
+
+			return errDrySubmitRollback // always rollback
+		})
+		if spErr != nil && !errors.Is(spErr, errDrySubmitRollback) {
+			return spErr
+		}
+
+		return dryErr
+	}
+
 	var dryDao *daos.Dao
-	if form.dao.ConcurrentDB() == form.dao.NonconcurrentDB() {
+	if isInTransaction {
 		// it is already in a transaction and therefore use the app concurrent db pool
 		// to prevent "transaction has already been committed or rolled back" error
 		dryDao = daos.New(form.app.Dao().ConcurrentDB())
@@ -720,20 +892,7 @@ func (form *RecordUpsert) DrySubmit(callback func(txDao *daos.Dao) error) error
 		}
 		defer tx.Rollback()
 
-		if err := txDao.SaveRecord(form.record); err != nil {
-			return form.prepareError(err)
-		}
-
-		// restore record isNew state
-		if isNew {
-			form.record.MarkAsNew()
-		}
-
-		if callback != nil {
-			return callback(txDao)
-		}
-
-		return nil
+		return dryFunc(txDao)
 	})
 }
 
@@ -800,20 +959,31 @@ func (form *RecordUpsert) Submit(interceptors ...InterceptorFunc[*models.Record]
 		// ---
 
 		// persist the record model
-		if err := dao.SaveRecord(form.record); err != nil {
-			return form.prepareError(err)
+		var saveErr error
+		if form.versionCheck != nil && !form.record.IsNew() {
+			saveErr = dao.SaveRecordIfUnmodified(form.record, form.versionCheck)
+		} else {
+			saveErr = dao.SaveRecord(form.record)
+		}
+		if saveErr != nil {
+			return form.prepareError(saveErr)
 		}
 
 		// delete old files (if any)
 		//
+		// note: deferred until the transaction commit (if any) to avoid
+		// losing the still referenced files on rollback
+		//
 		// for now fail silently to avoid reupload when `form.Submit()`
 		// is called manually (aka. not from an api request)...
-		if err := form.processFilesToDelete(); err != nil {
-			form.app.Logger().Debug(
-				"Failed to delete old files",
-				slog.String("error", err.Error()),
-			)
-		}
+		dao.AfterCommit(func() {
+			if err := form.processFilesToDelete(); err != nil {
+				form.app.Logger().Debug(
+					"Failed to delete old files",
+					slog.String("error", err.Error()),
+				)
+			}
+		})
 
 		return nil
 	}, interceptors...)
@@ -910,20 +1080,12 @@ func (form *RecordUpsert) deleteFilesByNamesList(filenames []string) ([]string,
 // prepareError parses the provided error and tries to return
 // user-friendly validation error(s).
 func (form *RecordUpsert) prepareError(err error) error {
-	msg := strings.ToLower(err.Error())
-
 	validationErrs := validation.Errors{}
 
 	// check for unique constraint failure
-	if strings.Contains(msg, "unique constraint failed") {
-		msg = strings.ReplaceAll(strings.TrimSpace(msg), ",", " ")
-
-		c := form.record.Collection()
-		for _, f := range c.Schema.Fields() {
-			// blank space to unify multi-columns lookup
-			if strings.Contains(msg+" ", strings.ToLower(c.Name+"."+f.Name)) {
-				validationErrs[f.Name] = validation.NewError("validation_not_unique", "Value must be unique")
-			}
+	if uniqueErr := dbutils.ParseUniqueConstraintError(err); uniqueErr != nil {
+		for _, name := range form.uniqueErrorFields(uniqueErr) {
+			validationErrs[name] = validation.NewError("validation_not_unique", "Value must be unique")
 		}
 	}
 
@@ -933,3 +1095,89 @@ func (form *RecordUpsert) prepareError(err error) error {
 
 	return err
 }
+
+// uniqueErrorFields returns the names of the record fields
+// participating in the failed unique constraint.
+//
+// For indexes with expressions SQLite reports only the index name,
+// so in this case the index is looked up in the collection indexes
+// and its columns expressions are matched against the record fields.
+func (form *RecordUpsert) uniqueErrorFields(uniqueErr *dbutils.UniqueConstraintError) []string {
+	c := form.record.Collection()
+
+	fieldNames := make([]string, 0, len(c.Schema.Fields())+2)
+	for _, f := range c.Schema.Fields() {
+		fieldNames = append(fieldNames, f.Name)
+	}
+	if c.IsAuth() {
+		fieldNames = append(fieldNames, schema.FieldNameUsername, schema.FieldNameEmail)
+	}
+
+	result := []string{}
+
+	if uniqueErr.IndexName != "" {
+		for _, raw := range c.Indexes {
+			idx := dbutils.ParseIndex(raw)
+			if !strings.EqualFold(idx.IndexName, uniqueErr.IndexName) {
+				continue
+			}
+
+			for _, col := range idx.Columns {
+				for _, name := range fieldNames {
+					if !list.ExistInSlice(name, result) && containsIdentifier(col.Name, name) {
+						result = append(result, name)
+					}
+				}
+			}
+
+			break
+		}
+
+		return result
+	}
+
+	if uniqueErr.TableName != "" && !strings.EqualFold(uniqueErr.TableName, c.Name) {
+		return result
+	}
+
+	for _, col := range uniqueErr.Columns {
+		for _, name := range fieldNames {
+			if strings.EqualFold(col, name) {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// containsIdentifier loosely checks whether the provided sql expression
+// contains the specified (case-insensitive) identifier
+// (eg. "lower(`email`)" contains "email" but not "mail").
+func containsIdentifier(expr string, identifier string) bool {
+	isIdentifierChar := func(b byte) bool {
+		return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+	}
+
+	expr = strings.ToLower(expr)
+	identifier = strings.ToLower(identifier)
+
+	for offset := 0; offset < len(expr); {
+		pos := strings.Index(expr[offset:], identifier)
+		if pos == -1 {
+			return false
+		}
+
+		start := offset + pos
+		end := start + len(identifier)
+
+		if (start == 0 || !isIdentifierChar(expr[start-1])) && (end == len(expr) || !isIdentifierChar(expr[end])) {
+			return true
+		}
+
+		offset = start + 1
+	}
+
+	return false
+}
@@ -218,6 +218,253 @@ func TestRecordUpsertLoadRequestMultipart(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertLoadRequestMultipartJsonPayload(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("files and nested json", func(t *testing.T) {
+		formData, mp, err := tests.MockMultipartData(map[string]string{
+			rest.MultipartJsonKey: `{"text":"from_payload","email":"test3@example.com","json":{"a":{"b":[1,2]}}}`,
+			"text":                "from_field", // form field has precedence over the payload key
+		}, "file_one", "file_many", "file_many")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		form := forms.NewRecordUpsert(app, models.NewRecord(collection))
+		req := httptest.NewRequest(http.MethodPost, "/", formData)
+		req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+		if err := form.LoadRequest(req, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := form.Data()["text"]; v != "from_field" {
+			t.Fatalf("Expected text field %q, got %v", "from_field", v)
+		}
+
+		if v := form.Data()["email"]; v != "test3@example.com" {
+			t.Fatalf("Expected email field %q, got %v", "test3@example.com", v)
+		}
+
+		rawJson, _ := form.Data()["json"].(types.JsonRaw)
+		if rawJson.String() != `{"a":{"b":[1,2]}}` {
+			t.Fatalf("Expected nested json field value, got %v", rawJson)
+		}
+
+		files := form.FilesToUpload()
+		if len(files["file_one"]) != 1 {
+			t.Fatalf("Expected 1 file_one file to upload, got %d", len(files["file_one"]))
+		}
+		if len(files["file_many"]) != 2 {
+			t.Fatalf("Expected 2 file_many files to upload, got %d", len(files["file_many"]))
+		}
+	})
+
+	t.Run("json field string value", func(t *testing.T) {
+		options := collection.Schema.GetFieldByName("json").Options.(*schema.JsonOptions)
+		defer func() {
+			options.PreserveStrings = false
+		}()
+
+		for _, preserveStrings := range []bool{false, true} {
+			options.PreserveStrings = preserveStrings
+
+			formData, mp, err := tests.MockMultipartData(map[string]string{
+				rest.MultipartJsonKey: `{"json":"123"}`,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			form := forms.NewRecordUpsert(app, models.NewRecord(collection))
+			req := httptest.NewRequest(http.MethodPost, "/", formData)
+			req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+			if err := form.LoadRequest(req, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			// should be a json string only with enabled preserveStrings
+			expected := `123`
+			if preserveStrings {
+				expected = `"123"`
+			}
+
+			raw, _ := json.Marshal(form.Data()["json"])
+			if string(raw) != expected {
+				t.Fatalf("[preserveStrings %v] Expected json value %s, got %s", preserveStrings, expected, raw)
+			}
+		}
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		formData, mp, err := tests.MockMultipartData(map[string]string{
+			rest.MultipartJsonKey: `[1,2]`,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		form := forms.NewRecordUpsert(app, models.NewRecord(collection))
+		req := httptest.NewRequest(http.MethodPost, "/", formData)
+		req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+		if err := form.LoadRequest(req, ""); err == nil {
+			t.Fatal("Expected error for non-object json payload")
+		}
+	})
+}
+
+func TestRecordUpsertLoadRequestMergePatch(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// the record current values
+	original := map[string]string{
+		"text":     `"test"`,
+		"number":   `123456`,
+		"json":     `[1,2,3]`,
+		"rel_many": `["oap640cot4yru2s"]`,
+	}
+
+	scenarios := []struct {
+		name     string
+		body     string
+		expected map[string]string
+	}{
+		{
+			"omitted fields",
+			`{}`,
+			original,
+		},
+		{
+			"explicit nulls",
+			`{"text":null,"number":null,"json":null,"rel_many":null}`,
+			map[string]string{
+				"text":     `""`,
+				"number":   `0`,
+				"json":     `null`,
+				"rel_many": `[]`,
+			},
+		},
+		{
+			"empty strings",
+			`{"text":"","number":"","json":"","rel_many":""}`,
+			map[string]string{
+				"text":     `""`,
+				"number":   `0`,
+				"json":     `""`,
+				"rel_many": `[]`,
+			},
+		},
+		{
+			"json body strings are normalized by default",
+			`{"json":"null"}`,
+			map[string]string{
+				"text":     original["text"],
+				"number":   original["number"],
+				"json":     `null`,
+				"rel_many": original["rel_many"],
+			},
+		},
+		{
+			"json body encoded values",
+			`{"json":{"a":[1,null]},"rel_many":["bgs820n361vj1qd"]}`,
+			map[string]string{
+				"text":     original["text"],
+				"number":   original["number"],
+				"json":     `{"a":[1,null]}`,
+				"rel_many": `["bgs820n361vj1qd"]`,
+			},
+		},
+		{
+			"mixed",
+			`{"text":null,"json":""}`,
+			map[string]string{
+				"text":     `""`,
+				"number":   original["number"],
+				"json":     `""`,
+				"rel_many": original["rel_many"],
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record, err := app.Dao().FindRecordById("demo1", "84nmscqy84lsi1t")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			form := forms.NewRecordUpsert(app, record)
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(s.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if err := form.LoadRequest(req, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, expected := range s.expected {
+				raw, err := json.Marshal(form.Data()[key])
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if compactJson(t, string(raw)) != expected {
+					t.Errorf("Expected %s %s, got %s", key, expected, raw)
+				}
+			}
+		})
+	}
+
+	// json body strings with enabled preserveStrings
+	record, err := app.Dao().FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Collection().Schema.GetFieldByName("json").Options.(*schema.JsonOptions).PreserveStrings = true
+	preserveForm := forms.NewRecordUpsert(app, record)
+	preserveReq := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"json":"null"}`))
+	preserveReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if err := preserveForm.LoadRequest(preserveReq, ""); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := json.Marshal(preserveForm.Data()["json"]); string(raw) != `"null"` {
+		t.Fatalf("Expected json string \"null\", got %s", raw)
+	}
+
+	// the plain multipart/form-data strings are always normalized
+	formData, mp, err := tests.MockMultipartData(map[string]string{"json": "null", "text": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := forms.NewRecordUpsert(app, record)
+	req := httptest.NewRequest(http.MethodPatch, "/", formData)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+	if err := form.LoadRequest(req, ""); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := json.Marshal(form.Data()["json"]); string(raw) != "null" {
+		t.Fatalf("Expected multipart json null, got %s", raw)
+	}
+	if v := form.Data()["text"]; v != "" {
+		t.Fatalf("Expected multipart empty text, got %v", v)
+	}
+	if v := form.Data()["number"]; v != 123456.0 {
+		t.Fatalf("Expected the omitted multipart number to remain unchanged, got %v", v)
+	}
+}
+
+func compactJson(t *testing.T, raw string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(raw)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
 func TestRecordUpsertLoadData(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -416,6 +663,81 @@ func TestRecordUpsertDrySubmitWithNestedTx(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertDrySubmitWithSavepoint(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo1")
+	recordBefore, err := app.Dao().FindRecordById(collection.Id, "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formData, mp, err := tests.MockMultipartData(map[string]string{
+		"text": "dry_test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txErr := app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		// uncommitted outer transaction change
+		other, err := txDao.FindRecordById(collection.Id, "al1h9ijdeojtsjy")
+		if err != nil {
+			t.Fatal(err)
+		}
+		other.Set("text", "outer_change")
+		if err := txDao.SaveRecord(other); err != nil {
+			t.Fatal(err)
+		}
+
+		form := forms.NewRecordUpsert(app, recordBefore)
+		form.SetDao(txDao)
+		form.SetDrySubmitSavepoint(true)
+		req := httptest.NewRequest(http.MethodGet, "/", formData)
+		req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+		form.LoadRequest(req, "")
+
+		result := form.DrySubmit(func(innerTxDao *daos.Dao) error {
+			found, err := innerTxDao.FindRecordById(collection.Id, other.Id)
+			if err != nil {
+				return err
+			}
+
+			if v := found.GetString("text"); v != "outer_change" {
+				t.Fatalf("Expected the outer transaction change to be visible, got %q", v)
+			}
+
+			return nil
+		})
+		if result != nil {
+			t.Fatalf("Expected nil, got error %v", result)
+		}
+
+		// ensure that only the dry submit changes were reverted
+		recordAfter, err := txDao.FindRecordById(collection.Id, recordBefore.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recordAfter.GetString("text") == "dry_test" {
+			t.Fatalf("Expected record.text to be %v, got %v", recordBefore.GetString("text"), "dry_test")
+		}
+
+		otherAfter, err := txDao.FindRecordById(collection.Id, other.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := otherAfter.GetString("text"); v != "outer_change" {
+			t.Fatalf("Expected the outer transaction change to be preserved, got %q", v)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		t.Fatalf("Nested transactions failure: %v", txErr)
+	}
+}
+
 func TestRecordUpsertSubmitFailure(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -983,11 +1305,21 @@ func TestRecordUpsertUniqueValidator(t *testing.T) {
 				Type: "text",
 				Name: "fieldC",
 			},
+			&schema.SchemaField{
+				Type: "text",
+				Name: "fieldD",
+			},
+			&schema.SchemaField{
+				Type: "text",
+				Name: "fieldD2",
+			},
 		),
 		Indexes: types.JsonArray[string]{
 			// the field case shouldn't matter
 			"create unique index unique_single_idx on test (fielda)",
 			"create unique index unique_combined_idx on test (fieldb, FIELDC)",
+			// expression indexes are reported only by their name
+			"create unique index unique_expr_idx on test (lower(`fieldD`)) where fieldD != ''",
 		},
 	}
 	if err := app.Dao().SaveCollection(collection); err != nil {
@@ -998,6 +1330,7 @@ func TestRecordUpsertUniqueValidator(t *testing.T) {
 	dummyRecord.Set("fieldA", "a")
 	dummyRecord.Set("fieldB", "b")
 	dummyRecord.Set("fieldC", "c")
+	dummyRecord.Set("fieldD", "d")
 	if err := app.Dao().SaveRecord(dummyRecord); err != nil {
 		t.Fatal(err)
 	}
@@ -1022,6 +1355,14 @@ func TestRecordUpsertUniqueValidator(t *testing.T) {
 			},
 			[]string{"fieldB", "fieldC"},
 		},
+		{
+			"duplicated expression unique value",
+			map[string]any{
+				"fieldD":  "D",
+				"fieldD2": "d",
+			},
+			[]string{"fieldD"},
+		},
 		{
 			"non-duplicated unique value",
 			map[string]any{
@@ -1067,8 +1408,104 @@ func TestRecordUpsertUniqueValidator(t *testing.T) {
 				t.Errorf("[%s] Missing expected error key %q in %v", s.name, k, errs)
 				continue
 			}
+
+			if code := errs[k].(validation.Error).Code(); code != "validation_not_unique" {
+				t.Errorf("[%s] Expected %q error code validation_not_unique, got %q", s.name, k, code)
+			}
 		}
 	}
+
+	// update with a duplicated combined unique value
+	record := models.NewRecord(collection)
+	record.Set("fieldA", "a3")
+	record.Set("fieldB", "b2")
+	record.Set("fieldC", "c")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, record)
+	if err := form.LoadData(map[string]any{"fieldB": "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, ok := form.Submit().(validation.Errors)
+	if !ok {
+		t.Fatalf("Expected validation errors, got %v", errs)
+	}
+	if len(errs) != 2 || errs["fieldB"] == nil || errs["fieldC"] == nil {
+		t.Fatalf("Expected fieldB and fieldC errors, got %v", errs)
+	}
+}
+
+func TestRecordUpsertAddStoredFiles(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record, err := app.Dao().FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	stored1 := "stored1_abcdefghij.txt"
+	stored2 := "stored2_abcdefghij.txt"
+	for _, name := range []string{stored1, stored2} {
+		if err := fs.Upload([]byte("test"), record.BaseFilesPath()+"/"+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldFileOne := record.GetString("file_one")
+	oldFileMany := record.GetStringSlice("file_many")
+
+	form := forms.NewRecordUpsert(app, record)
+
+	if err := form.AddStoredFiles("missing", stored1); err == nil {
+		t.Fatal("Expected invalid field key error")
+	}
+
+	form.AddStoredFiles("file_one", stored1)  // should replace the existing file
+	form.AddStoredFiles("file_many", stored2) // should append
+
+	if v := form.FilesToUpload(); len(v) != 0 {
+		t.Fatalf("Expected no files to upload, got %v", v)
+	}
+
+	if v := form.FilesToDelete(); len(v) != 1 || v[0] != oldFileOne {
+		t.Fatalf("Expected only %q to be marked for deletion, got %v", oldFileOne, v)
+	}
+
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Failed to submit the RecordUpsert form, got %v", err)
+	}
+
+	recordAfter, err := app.Dao().FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := recordAfter.GetString("file_one"); v != stored1 {
+		t.Fatalf("Expected file_one %q, got %q", stored1, v)
+	}
+
+	expectedMany := append(oldFileMany, stored2)
+	if v := recordAfter.GetStringSlice("file_many"); len(v) != len(expectedMany) || v[len(v)-1] != stored2 {
+		t.Fatalf("Expected file_many %v, got %v", expectedMany, v)
+	}
+
+	if hasRecordFile(app, recordAfter, oldFileOne) {
+		t.Fatal("Expected the old file_one file to be deleted")
+	}
+
+	if !hasRecordFile(app, recordAfter, stored1) || !hasRecordFile(app, recordAfter, stored2) {
+		t.Fatal("Expected the stored files to remain")
+	}
 }
 
 func TestRecordUpsertAddAndRemoveFiles(t *testing.T) {
@@ -1165,6 +1602,59 @@ func TestRecordUpsertAddAndRemoveFiles(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertRemoveFilesInTransaction(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	removed := "300_WlbFWSGmW9.png"
+
+	submit := func(txDao *daos.Dao) *models.Record {
+		record, err := txDao.FindRecordById("demo1", "84nmscqy84lsi1t")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		form := forms.NewRecordUpsert(app, record)
+		form.SetDao(txDao)
+		form.RemoveFiles("file_many", removed)
+
+		if err := form.Submit(); err != nil {
+			t.Fatalf("Failed to submit the RecordUpsert form, got %v", err)
+		}
+
+		// the file removal should be deferred until the transaction commit
+		if !hasRecordFile(app, record, removed) {
+			t.Fatalf("Expected %s to not be deleted before the transaction commit", removed)
+		}
+
+		return record
+	}
+
+	// rolled back transaction
+	var record *models.Record
+	app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		record = submit(txDao)
+		return errors.New("test rollback")
+	})
+
+	if !hasRecordFile(app, record, removed) {
+		t.Fatalf("Expected %s to not be deleted after the transaction rollback", removed)
+	}
+
+	// committed transaction
+	txErr := app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		record = submit(txDao)
+		return nil
+	})
+	if txErr != nil {
+		t.Fatal(txErr)
+	}
+
+	if hasRecordFile(app, record, removed) {
+		t.Fatalf("Expected %s to be deleted after the transaction commit", removed)
+	}
+}
+
 func TestRecordUpsertUploadFailure(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
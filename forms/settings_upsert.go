@@ -49,11 +49,17 @@ func (form *SettingsUpsert) Validate() error {
 
 // Submit validates the form and upserts the loaded settings.
 //
+// The storage options submitted with the [settings.SecretMask] value
+// (see [settings.Settings.RedactClone]) keep their existing app settings value.
+//
 // On success the app settings will be refreshed with the form ones.
 //
 // You can optionally provide a list of InterceptorFunc to further
 // modify the form behavior before persisting it.
 func (form *SettingsUpsert) Submit(interceptors ...InterceptorFunc[*settings.Settings]) error {
+	// keep the existing values of the submitted redacted storage options
+	form.Settings.RestoreRedactedOptions(form.app.Settings())
+
 	if err := form.Validate(); err != nil {
 		return err
 	}
@@ -130,6 +130,43 @@ func TestSettingsUpsertValidateAndSubmit(t *testing.T) {
 	}
 }
 
+func TestSettingsUpsertSubmitRedactedOptions(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Storage.Options = map[string]any{"accessKey": "test_key", "bucket": "test_bucket"}
+
+	redacted, err := app.Settings().RedactClone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	redacted.Storage.Options["bucket"] = "new_bucket"
+
+	data, err := json.Marshal(map[string]any{"storage": redacted.Storage})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewSettingsUpsert(app)
+	if err := json.Unmarshal(data, form); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := app.Settings().Storage.Options["accessKey"]; v != "test_key" {
+		t.Fatalf("Expected the redacted accessKey to keep its existing value, got %v", v)
+	}
+
+	if v := app.Settings().Storage.Options["bucket"]; v != "new_bucket" {
+		t.Fatalf("Expected the bucket option to be updated, got %v", v)
+	}
+}
+
 func TestSettingsUpsertSubmitInterceptors(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,59 @@
+package validators
+
+import (
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// FieldValidatorFunc defines a custom record field validator function.
+//
+// value is the normalized submitted field value (it could be also a zero value)
+// and record is a copy of the validated record with all submitted data
+// loaded (it could be used for cross fields checks).
+//
+// The returned validation.Error is returned as it is to the client.
+// All other errors are reported with a generic "Invalid value" message.
+type FieldValidatorFunc func(record *models.Record, field *schema.SchemaField, value any) error
+
+type fieldValidator struct {
+	fn         FieldValidatorFunc
+	fieldTypes []string
+}
+
+var fieldValidators = store.New[*fieldValidator](nil)
+
+// RegisterFieldValidator registers a named custom field validator that
+// could be attached to the collection schema fields via their
+// [schema.SchemaField.Validators] list.
+//
+// If fieldTypes are specified, the validator could be attached only to
+// fields with the listed types, otherwise - to all field types.
+//
+// Registering a validator with an already existing name replaces the old one.
+//
+// Example:
+//
+//	validators.RegisterFieldValidator("multipleOf5", func(record *models.Record, field *schema.SchemaField, value any) error {
+//		if cast.ToInt(value)%5 != 0 {
+//			return validation.NewError("validation_not_multiple_of_5", "Must be a multiple of 5.")
+//		}
+//		return nil
+//	}, schema.FieldTypeNumber)
+func RegisterFieldValidator(name string, fn FieldValidatorFunc, fieldTypes ...string) {
+	fieldValidators.Set(name, &fieldValidator{fn: fn, fieldTypes: fieldTypes})
+}
+
+// UnregisterFieldValidator removes the named custom field validator (if exists).
+func UnregisterFieldValidator(name string) {
+	fieldValidators.Remove(name)
+}
+
+// HasFieldValidator checks whether a custom field validator with the
+// specified name is registered and supports the provided field type.
+func HasFieldValidator(name string, fieldType string) bool {
+	v := fieldValidators.Get(name)
+
+	return v != nil && (len(v.fieldTypes) == 0 || list.ExistInSlice(fieldType, v.fieldTypes))
+}
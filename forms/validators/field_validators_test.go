@@ -0,0 +1,47 @@
+package validators_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestRegisterFieldValidator(t *testing.T) {
+	noop := func(record *models.Record, field *schema.SchemaField, value any) error {
+		return nil
+	}
+
+	validators.RegisterFieldValidator("test_register_all", noop)
+	defer validators.UnregisterFieldValidator("test_register_all")
+
+	validators.RegisterFieldValidator("test_register_typed", noop, schema.FieldTypeText, schema.FieldTypeEmail)
+	defer validators.UnregisterFieldValidator("test_register_typed")
+
+	scenarios := []struct {
+		name      string
+		fieldType string
+		expected  bool
+	}{
+		{"missing", schema.FieldTypeText, false},
+		{"test_register_all", schema.FieldTypeText, true},
+		{"test_register_all", schema.FieldTypeNumber, true},
+		{"test_register_typed", schema.FieldTypeText, true},
+		{"test_register_typed", schema.FieldTypeEmail, true},
+		{"test_register_typed", schema.FieldTypeNumber, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name+"_"+s.fieldType, func(t *testing.T) {
+			if has := validators.HasFieldValidator(s.name, s.fieldType); has != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, has)
+			}
+		})
+	}
+
+	validators.UnregisterFieldValidator("test_register_all")
+	if validators.HasFieldValidator("test_register_all", schema.FieldTypeText) {
+		t.Fatal("Expected the validator to be unregistered")
+	}
+}
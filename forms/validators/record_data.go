@@ -1,6 +1,7 @@
 package validators
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -12,9 +13,11 @@ import (
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/expr"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 )
 
 var requiredErr = validation.NewError("validation_required", "Missing required value")
@@ -44,6 +47,7 @@ type RecordDataValidator struct {
 	dao           *daos.Dao
 	record        *models.Record
 	uploadedFiles map[string][]*filesystem.File
+	tenant        string // the validated data tenant (for tenant-scoped collections)
 }
 
 // Validate validates the provided `data` by checking it against
@@ -70,13 +74,38 @@ func (validator *RecordDataValidator) Validate(data map[string]any) error {
 		return errs
 	}
 
+	validator.tenant = ""
+	if tenantField := validator.record.Collection().TenantField(); tenantField != "" {
+		if field, ok := keyedSchema[tenantField]; ok {
+			validator.tenant = cast.ToString(field.PrepareValue(data[tenantField]))
+		}
+	}
+
+	// lazily initialized record copy with the submitted data for the custom validators
+	var dataRecord *models.Record
+
 	for key, field := range keyedSchema {
+		// the computed fields are never submitted but their
+		// expression must evaluate to a valid value with the new data
+		if field.Type == schema.FieldTypeComputed {
+			if dataRecord == nil {
+				dataRecord = validator.record.CleanCopy()
+				dataRecord.Load(data)
+			}
+
+			if err := validator.checkComputedValue(dataRecord, field); err != nil {
+				errs[key] = err
+			}
+			continue
+		}
+
 		// normalize value to emulate the same behavior
 		// when fetching or persisting the record model
 		value := field.PrepareValue(data[key])
 
 		// check required constraint
-		if field.Required && validation.Required.Validate(value) != nil {
+		// (the empty autogenerated text fields are populated on create)
+		if field.Required && validation.Required.Validate(value) != nil && !validator.isAutogeneratedOnCreate(field) {
 			errs[key] = requiredErr
 			continue
 		}
@@ -86,6 +115,19 @@ func (validator *RecordDataValidator) Validate(data map[string]any) error {
 			errs[key] = err
 			continue
 		}
+
+		// run the attached custom field validators (if any)
+		if len(field.Validators) > 0 {
+			if dataRecord == nil {
+				dataRecord = validator.record.CleanCopy()
+				dataRecord.Load(data)
+			}
+
+			if err := validator.checkCustomValidators(dataRecord, field, value); err != nil {
+				errs[key] = err
+				continue
+			}
+		}
 	}
 
 	if len(errs) == 0 {
@@ -95,6 +137,18 @@ func (validator *RecordDataValidator) Validate(data map[string]any) error {
 	return errs
 }
 
+// isAutogeneratedOnCreate reports whether the provided field value
+// will be autogenerated when the validated record is created.
+func (validator *RecordDataValidator) isAutogeneratedOnCreate(field *schema.SchemaField) bool {
+	if !validator.record.IsNew() || field.Type != schema.FieldTypeText {
+		return false
+	}
+
+	options, ok := field.Options.(*schema.TextOptions)
+
+	return ok && options.IsAutogenerated()
+}
+
 func (validator *RecordDataValidator) checkFieldValue(field *schema.SchemaField, value any) error {
 	switch field.Type {
 	case schema.FieldTypeText:
@@ -119,6 +173,29 @@ func (validator *RecordDataValidator) checkFieldValue(field *schema.SchemaField,
 		return validator.checkFileValue(field, value)
 	case schema.FieldTypeRelation:
 		return validator.checkRelationValue(field, value)
+	case schema.FieldTypeEncrypted:
+		return validator.checkEncryptedValue(field, value)
+	case schema.FieldTypeDuration:
+		return validator.checkDurationValue(field, value)
+	case schema.FieldTypeColor:
+		return validator.checkColorValue(field, value)
+	}
+
+	return nil
+}
+
+// checkCustomValidators runs the field custom validators in the order
+// they are listed and returns the first validator error.
+func (validator *RecordDataValidator) checkCustomValidators(record *models.Record, field *schema.SchemaField, value any) error {
+	for _, name := range field.Validators {
+		v := fieldValidators.Get(name)
+		if v == nil {
+			return fmt.Errorf("missing field validator %q", name)
+		}
+
+		if err := v.fn(record, field, value); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -153,6 +230,36 @@ func (validator *RecordDataValidator) checkTextValue(field *schema.SchemaField,
 	return nil
 }
 
+func (validator *RecordDataValidator) checkComputedValue(record *models.Record, field *schema.SchemaField) error {
+	if _, err := record.EvalComputedField(field); errors.Is(err, expr.ErrNonFiniteNumber) {
+		return validation.NewError("validation_non_finite_number", "The computed value must be a finite number.")
+	}
+
+	return nil
+}
+
+func (validator *RecordDataValidator) checkEncryptedValue(field *schema.SchemaField, value any) error {
+	val, _ := value.(string)
+	if val == "" {
+		return nil // nothing to check (skip zero-defaults)
+	}
+
+	options, _ := field.Options.(*schema.EncryptedOptions)
+
+	// note: casted to []rune to count multi-byte chars as one
+	length := len([]rune(val))
+
+	if options.Min != nil && length < *options.Min {
+		return validation.NewError("validation_min_text_constraint", fmt.Sprintf("Must be at least %d character(s)", *options.Min))
+	}
+
+	if options.Max != nil && length > *options.Max {
+		return validation.NewError("validation_max_text_constraint", fmt.Sprintf("Must be less than %d character(s)", *options.Max))
+	}
+
+	return nil
+}
+
 func (validator *RecordDataValidator) checkNumberValue(field *schema.SchemaField, value any) error {
 	val, _ := value.(float64)
 	if val == 0 {
@@ -265,6 +372,49 @@ func (validator *RecordDataValidator) checkDateValue(field *schema.SchemaField,
 	return nil
 }
 
+func (validator *RecordDataValidator) checkDurationValue(field *schema.SchemaField, value any) error {
+	val, _ := value.(types.Duration)
+	if val.IsZero() {
+		return nil // nothing to check (skip zero-defaults)
+	}
+
+	options, _ := field.Options.(*schema.DurationOptions)
+
+	if options.Min != nil && val < *options.Min {
+		return validation.NewError("validation_min_duration_constraint", fmt.Sprintf("Must be at least %s", options.Min.String()))
+	}
+
+	if options.Max != nil && val > *options.Max {
+		return validation.NewError("validation_max_duration_constraint", fmt.Sprintf("Must be at most %s", options.Max.String()))
+	}
+
+	return nil
+}
+
+func (validator *RecordDataValidator) checkColorValue(field *schema.SchemaField, value any) error {
+	val, _ := value.(string)
+	if val == "" {
+		return nil // nothing to check
+	}
+
+	// the valid colors are already normalized to hex with PrepareValue
+	if !schema.IsCanonicalColor(val) {
+		options, _ := field.Options.(*schema.ColorOptions)
+
+		formats := []string{"#RGB", "#RRGGBB", "#RRGGBBAA"}
+		if options.AllowRgb {
+			formats = append(formats, "rgb()")
+		}
+		if options.AllowHsl {
+			formats = append(formats, "hsl()")
+		}
+
+		return validation.NewError("validation_invalid_color", fmt.Sprintf("Must be a valid color (%s)", strings.Join(formats, ", ")))
+	}
+
+	return nil
+}
+
 func (validator *RecordDataValidator) checkSelectValue(field *schema.SchemaField, value any) error {
 	normalizedVal := list.ToUniqueStringSlice(value)
 	if len(normalizedVal) == 0 {
@@ -276,16 +426,28 @@ func (validator *RecordDataValidator) checkSelectValue(field *schema.SchemaField
 
 	options, _ := field.Options.(*schema.SelectOptions)
 
-	// check max selected items
-	if len(normalizedVal) > options.MaxSelect {
-		return validation.NewError("validation_too_many_values", fmt.Sprintf("Select no more than %d", options.MaxSelect))
-	}
-
 	// check against the allowed values
+	invalidErrs := validation.Errors{}
 	for _, val := range normalizedVal {
 		if !list.ExistInSlice(val, options.Values) {
-			return validation.NewError("validation_invalid_value", "Invalid value "+val)
+			invalidErrs[val] = validation.NewError("validation_invalid_value", "Invalid value "+val)
+		}
+	}
+	if len(invalidErrs) > 0 {
+		if !options.IsMultiple() {
+			return invalidErrs[normalizedVal[0]]
 		}
+		return invalidErrs // per value errors
+	}
+
+	// check min selected items
+	if len(normalizedVal) < options.MinSelect {
+		return validation.NewError("validation_not_enough_values", fmt.Sprintf("Select at least %d", options.MinSelect))
+	}
+
+	// check max selected items
+	if len(normalizedVal) > options.MaxSelect {
+		return validation.NewError("validation_too_many_values", fmt.Sprintf("Select no more than %d", options.MaxSelect))
 	}
 
 	return nil
@@ -389,5 +551,22 @@ func (validator *RecordDataValidator) checkRelationValue(field *schema.SchemaFie
 	}
 	// ---
 
+	// forbid cross-tenant references between tenant-scoped collections
+	// ---
+	tenantField := validator.record.Collection().TenantField()
+	relTenantField := relCollection.TenantField()
+	if tenantField != "" && relTenantField != "" && field.Name != tenantField {
+		var totalSameTenant int
+		validator.dao.RecordQuery(relCollection).
+			Select("count(*)").
+			AndWhere(dbx.In("id", list.ToInterfaceSlice(ids)...)).
+			AndWhere(dbx.HashExp{relTenantField: validator.tenant}).
+			Row(&totalSameTenant)
+		if totalSameTenant != len(ids) {
+			return validation.NewError("validation_cross_tenant_rel_records", "The relation records must belong to the same tenant")
+		}
+	}
+	// ---
+
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/daos"
@@ -176,6 +177,51 @@ func TestRecordDataValidatorValidateText(t *testing.T) {
 	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
 }
 
+func TestRecordDataValidatorValidateAutogeneratedText(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name:     "field1",
+			Type:     schema.FieldTypeText,
+			Required: true,
+			Options: &schema.TextOptions{
+				AutogenerateLength: 10,
+			},
+		},
+		&schema.SchemaField{
+			Name:     "field2",
+			Type:     schema.FieldTypeText,
+			Required: true,
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := models.NewRecord(collection)
+	existing.Set("field2", "test")
+	if err := app.Dao().SaveRecord(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]any{"field1": "", "field2": ""}
+
+	// new record
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), []testDataFieldScenario{
+		{"(autogenerate) new record", data, nil, []string{"field2"}},
+	})
+
+	// existing record
+	checkValidatorErrors(t, app.Dao(), existing, []testDataFieldScenario{
+		{"(autogenerate) existing record", data, nil, []string{"field1", "field2"}},
+	})
+}
+
 func TestRecordDataValidatorValidateNumber(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -731,6 +777,280 @@ func TestRecordDataValidatorValidateDate(t *testing.T) {
 	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
 }
 
+func TestRecordDataValidatorValidateDuration(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name: "field1",
+			Type: schema.FieldTypeDuration,
+		},
+		&schema.SchemaField{
+			Name:     "field2",
+			Required: true,
+			Type:     schema.FieldTypeDuration,
+			Options: &schema.DurationOptions{
+				Min: types.Pointer(types.Duration(time.Minute)),
+			},
+		},
+		&schema.SchemaField{
+			Name:   "field3",
+			Unique: true,
+			Type:   schema.FieldTypeDuration,
+			Options: &schema.DurationOptions{
+				Max: types.Pointer(types.Duration(2 * time.Hour)),
+			},
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// create dummy record (used for the unique check)
+	dummy := models.NewRecord(collection)
+	dummy.Set("field1", "1h")
+	dummy.Set("field2", "1h")
+	dummy.Set("field3", "1h")
+	if err := app.Dao().SaveRecord(dummy); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testDataFieldScenario{
+		{
+			"(duration) check required constraint",
+			map[string]any{
+				"field1": nil,
+				"field2": nil,
+				"field3": nil,
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"(duration) check required constraint + cast",
+			map[string]any{
+				"field1": "invalid",
+				"field2": "invalid",
+				"field3": "invalid",
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"(duration) check min constraint",
+			map[string]any{
+				"field1": "59s",
+				"field2": "59s",
+				"field3": "59s",
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"(duration) check max constraint",
+			map[string]any{
+				"field1": "P1D",
+				"field2": "P1D",
+				"field3": "P1D",
+			},
+			nil,
+			[]string{"field3"},
+		},
+		{
+			"(duration) valid data (only required)",
+			map[string]any{
+				"field2": "1m",
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"(duration) valid data (all)",
+			map[string]any{
+				"field1": -60000,
+				"field2": "PT1M30S",
+				"field3": "2h",
+			},
+			nil,
+			[]string{},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+}
+
+func TestRecordDataValidatorValidateColor(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name: "field1",
+			Type: schema.FieldTypeColor,
+		},
+		&schema.SchemaField{
+			Name:     "field2",
+			Required: true,
+			Type:     schema.FieldTypeColor,
+			Options: &schema.ColorOptions{
+				AllowRgb: true,
+			},
+		},
+		&schema.SchemaField{
+			Name: "field3",
+			Type: schema.FieldTypeColor,
+			Options: &schema.ColorOptions{
+				AllowHsl: true,
+			},
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testDataFieldScenario{
+		{
+			"(color) check required constraint",
+			map[string]any{
+				"field1": "",
+				"field2": "",
+				"field3": "",
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"(color) check invalid colors",
+			map[string]any{
+				"field1": "#ff00",
+				"field2": "rgb(300, 0, 0)",
+				"field3": "red",
+			},
+			nil,
+			[]string{"field1", "field2", "field3"},
+		},
+		{
+			"(color) check disallowed formats",
+			map[string]any{
+				"field1": "rgb(255, 0, 0)",
+				"field2": "hsl(0, 100%, 50%)",
+				"field3": "rgb(255, 0, 0)",
+			},
+			nil,
+			[]string{"field1", "field2", "field3"},
+		},
+		{
+			"(color) valid data (only required)",
+			map[string]any{
+				"field2": "#F00",
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"(color) valid data (all)",
+			map[string]any{
+				"field1": "#FF0000AA",
+				"field2": "rgba(255, 0, 0, 0.5)",
+				"field3": "hsl(0 100% 50%)",
+			},
+			nil,
+			[]string{},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+
+	// check normalization on save
+	record := models.NewRecord(collection)
+	record.Set("field1", "#ABC")
+	record.Set("field2", "rgb(255 128 0 / 100%)")
+	record.Set("field3", "HSL(120, 100%, 25%)")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := app.Dao().FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"field1": "#aabbcc", "field2": "#ff8000", "field3": "#008000"}
+	for name, color := range expected {
+		if v := saved.GetString(name); v != color {
+			t.Fatalf("Expected %s to be normalized to %q, got %q", name, color, v)
+		}
+	}
+}
+
+func TestRecordDataValidatorValidateComputed(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name: "field1",
+			Type: schema.FieldTypeNumber,
+		},
+		&schema.SchemaField{
+			Name: "field2",
+			Type: schema.FieldTypeComputed,
+			Options: &schema.ComputedOptions{
+				Expression: "field1 * field1",
+			},
+		},
+		&schema.SchemaField{
+			Name: "field3",
+			Type: schema.FieldTypeComputed,
+			Options: &schema.ComputedOptions{
+				Expression: "10 / field1",
+			},
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testDataFieldScenario{
+		{
+			"(computed) finite results",
+			map[string]any{
+				"field1": 2,
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"(computed) division by zero",
+			map[string]any{
+				"field1": 0,
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"(computed) non-finite result",
+			map[string]any{
+				"field1": 1e200,
+			},
+			nil,
+			[]string{"field2"},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+}
+
 func TestRecordDataValidatorValidateSelect(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -765,6 +1085,15 @@ func TestRecordDataValidatorValidateSelect(t *testing.T) {
 				MaxSelect: 99,
 			},
 		},
+		&schema.SchemaField{
+			Name: "field4",
+			Type: schema.FieldTypeSelect,
+			Options: &schema.SelectOptions{
+				Values:    []string{"a", "b", "c", "d"},
+				MinSelect: 2,
+				MaxSelect: 3,
+			},
+		},
 	)
 	if err := app.Dao().SaveCollection(collection); err != nil {
 		t.Fatal(err)
@@ -830,6 +1159,42 @@ func TestRecordDataValidatorValidateSelect(t *testing.T) {
 			nil,
 			[]string{"field2"},
 		},
+		{
+			"(select) check MinSelect constraint",
+			map[string]any{
+				"field2": "a",
+				"field4": []string{"a"},
+			},
+			nil,
+			[]string{"field4"},
+		},
+		{
+			"(select) check MinSelect and MaxSelect boundaries",
+			map[string]any{
+				"field2": "a",
+				"field4": []string{"d", "a"},
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"(select) check MaxSelect upper boundary",
+			map[string]any{
+				"field2": "a",
+				"field4": []string{"d", "a", "c"},
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"(select) check MaxSelect upper boundary exceeded",
+			map[string]any{
+				"field2": "a",
+				"field4": []string{"d", "a", "c", "b"},
+			},
+			nil,
+			[]string{"field4"},
+		},
 		{
 			"(select) valid data - only required fields",
 			map[string]any{
@@ -851,6 +1216,35 @@ func TestRecordDataValidatorValidateSelect(t *testing.T) {
 	}
 
 	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+
+	t.Run("per value errors", func(t *testing.T) {
+		validator := validators.NewRecordDataValidator(app.Dao(), models.NewRecord(collection), nil)
+
+		err := validator.Validate(map[string]any{
+			"field1": "x",
+			"field2": []string{"b", "x", "a", "y"},
+		})
+
+		errs, ok := err.(validation.Errors)
+		if !ok {
+			t.Fatalf("Expected validation.Errors, got %v", err)
+		}
+
+		if e, ok := errs["field1"].(validation.Error); !ok || e.Code() != "validation_invalid_value" {
+			t.Fatalf("Expected field1 validation_invalid_value error, got %v", errs["field1"])
+		}
+
+		field2Errs, ok := errs["field2"].(validation.Errors)
+		if !ok || len(field2Errs) != 2 {
+			t.Fatalf("Expected 2 field2 value errors, got %v", errs["field2"])
+		}
+
+		for _, v := range []string{"x", "y"} {
+			if e, ok := field2Errs[v].(validation.Error); !ok || e.Code() != "validation_invalid_value" {
+				t.Fatalf("Expected %q validation_invalid_value error, got %v", v, field2Errs[v])
+			}
+		}
+	})
 }
 
 func TestRecordDataValidatorValidateJson(t *testing.T) {
@@ -1320,3 +1714,82 @@ func checkValidatorErrors(t *testing.T, dao *daos.Dao, record *models.Record, sc
 		})
 	}
 }
+
+func TestRecordDataValidatorValidateCustomValidators(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	validators.RegisterFieldValidator("test_multiple_of_5", func(record *models.Record, field *schema.SchemaField, value any) error {
+		if v, _ := value.(float64); int(v)%5 != 0 {
+			return validation.NewError("validation_not_multiple_of_5", "Must be a multiple of 5.")
+		}
+		return nil
+	}, schema.FieldTypeNumber)
+	defer validators.UnregisterFieldValidator("test_multiple_of_5")
+
+	// cross field check
+	validators.RegisterFieldValidator("test_less_than_max", func(record *models.Record, field *schema.SchemaField, value any) error {
+		if v, _ := value.(float64); v >= record.GetFloat("max") {
+			return validation.NewError("validation_not_less_than_max", "Must be less than max.")
+		}
+		return nil
+	})
+	defer validators.UnregisterFieldValidator("test_less_than_max")
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name:       "field1",
+			Type:       schema.FieldTypeNumber,
+			Validators: []string{"test_multiple_of_5", "test_less_than_max"},
+		},
+		&schema.SchemaField{
+			Name:       "field2",
+			Type:       schema.FieldTypeNumber,
+			Validators: []string{"missing"},
+		},
+		&schema.SchemaField{
+			Name: "max",
+			Type: schema.FieldTypeNumber,
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("max", 100)
+
+	scenarios := []testDataFieldScenario{
+		{
+			"valid values",
+			map[string]any{"field1": 10},
+			nil,
+			[]string{"field2"}, // missing validator
+		},
+		{
+			"not multiple of 5",
+			map[string]any{"field1": 11},
+			nil,
+			[]string{"field1", "field2"},
+		},
+		{
+			"with the record max value",
+			map[string]any{"field1": 100},
+			nil,
+			[]string{"field1", "field2"},
+		},
+		{
+			"with the submitted max value",
+			map[string]any{"field1": 100, "max": 105},
+			nil,
+			[]string{"field2"},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), record, scenarios)
+}
@@ -0,0 +1,124 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// WebhookUpsert is a [models.Webhook] upsert (create/update) form.
+type WebhookUpsert struct {
+	app     core.App
+	dao     *daos.Dao
+	webhook *models.Webhook
+
+	Name       string   `form:"name" json:"name"`
+	Collection string   `form:"collection" json:"collection"`
+	Events     []string `form:"events" json:"events"`
+	Url        string   `form:"url" json:"url"`
+	Active     bool     `form:"active" json:"active"`
+
+	// Secret is the webhook signing key.
+	//
+	// On update it could be left empty to keep the existing one.
+	Secret string `form:"secret" json:"secret"`
+}
+
+// NewWebhookUpsert creates a new [WebhookUpsert] form with initializer
+// config created from the provided [core.App] and [models.Webhook] instances
+// (for create you could pass a pointer to an empty Webhook - `&models.Webhook{}`).
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewWebhookUpsert(app core.App, webhook *models.Webhook) *WebhookUpsert {
+	form := &WebhookUpsert{
+		app:     app,
+		dao:     app.Dao(),
+		webhook: webhook,
+	}
+
+	// load defaults
+	form.Name = webhook.Name
+	form.Collection = webhook.CollectionId
+	form.Events = webhook.Events
+	form.Url = webhook.Url
+	form.Active = webhook.Active || webhook.IsNew()
+
+	return form
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *WebhookUpsert) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *WebhookUpsert) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(
+			&form.Collection,
+			validation.Required,
+			validation.By(form.checkCollection),
+		),
+		validation.Field(
+			&form.Events,
+			validation.Required,
+			validation.Each(validation.In(list.ToInterfaceSlice(models.WebhookEvents())...)),
+		),
+		validation.Field(
+			&form.Url,
+			validation.Required,
+			validation.Length(1, 2000),
+			is.URL,
+		),
+		validation.Field(
+			&form.Secret,
+			validation.When(form.webhook.IsNew(), validation.Required),
+			validation.Length(10, 255),
+		),
+	)
+}
+
+func (form *WebhookUpsert) checkCollection(value any) error {
+	v, _ := value.(string)
+
+	collection, err := form.dao.FindCollectionByNameOrId(v)
+	if err != nil || collection.IsView() {
+		return validation.NewError("validation_invalid_collection", "Missing or invalid collection.")
+	}
+
+	return nil
+}
+
+// Submit validates the form and upserts the form's Webhook model.
+//
+// You can optionally provide a list of InterceptorFunc to
+// further modify the form behavior before persisting it.
+func (form *WebhookUpsert) Submit(interceptors ...InterceptorFunc[*models.Webhook]) error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	collection, err := form.dao.FindCollectionByNameOrId(form.Collection)
+	if err != nil {
+		return err
+	}
+
+	form.webhook.Name = form.Name
+	form.webhook.CollectionId = collection.Id
+	form.webhook.Events = list.ToUniqueStringSlice(form.Events)
+	form.webhook.Url = form.Url
+	form.webhook.Active = form.Active
+
+	if form.Secret != "" {
+		form.webhook.Secret = form.Secret
+	}
+
+	return runInterceptors(form.webhook, func(m *models.Webhook) error {
+		return form.dao.SaveWebhook(m)
+	}, interceptors...)
+}
@@ -5,7 +5,6 @@ import (
 	"net/mail"
 
 	"github.com/pocketbase/pocketbase/core"
-	"github.com/pocketbase/pocketbase/mails/templates"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tokens"
 	"github.com/pocketbase/pocketbase/tools/mailer"
@@ -42,22 +41,22 @@ func SendAdminPasswordReset(app core.App, admin *models.Admin) error {
 		ActionUrl: actionUrl,
 	}
 
-	mailClient := app.NewMailClient()
-
-	// resolve body template
-	body, renderErr := resolveTemplateContent(params, templates.Layout, templates.AdminPasswordResetBody)
+	rendered, renderErr := Templates.Render(TemplateAdminPasswordReset, "", params)
 	if renderErr != nil {
 		return renderErr
 	}
 
+	mailClient := mailer.WithTransport(app.NewMailClient(), rendered.Transport)
+
 	message := &mailer.Message{
 		From: mail.Address{
 			Name:    app.Settings().Meta.SenderName,
 			Address: app.Settings().Meta.SenderAddress,
 		},
 		To:      []mail.Address{{Address: admin.Email}},
-		Subject: "Reset admin password",
-		HTML:    body,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
 	}
 
 	event := new(core.MailerAdminEvent)
@@ -28,22 +28,22 @@ func SendRecordPasswordLoginAlert(app core.App, authRecord *models.Record, provi
 		ProviderNames: providerNames,
 	}
 
-	mailClient := app.NewMailClient()
-
-	// resolve body template
-	body, renderErr := resolveTemplateContent(params, templates.Layout, templates.PasswordLoginAlertBody)
+	rendered, renderErr := Templates.Render(TemplateRecordPasswordLoginAlert, recordLang(authRecord), params)
 	if renderErr != nil {
 		return renderErr
 	}
 
+	mailClient := mailer.WithTransport(app.NewMailClient(), rendered.Transport)
+
 	message := &mailer.Message{
 		From: mail.Address{
 			Name:    app.Settings().Meta.SenderName,
 			Address: app.Settings().Meta.SenderAddress,
 		},
 		To:      []mail.Address{{Address: authRecord.Email()}},
-		Subject: "Password login alert",
-		HTML:    body,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
 	}
 
 	return mailClient.Send(message)
@@ -56,21 +56,22 @@ func SendRecordPasswordReset(app core.App, authRecord *models.Record) error {
 		return tokenErr
 	}
 
-	mailClient := app.NewMailClient()
-
-	subject, body, err := resolveEmailTemplate(app, token, app.Settings().Meta.ResetPasswordTemplate)
+	rendered, err := resolveRecordEmail(app, TemplateRecordPasswordReset, app.Settings().Meta.ResetPasswordTemplate, authRecord, token, "")
 	if err != nil {
 		return err
 	}
 
+	mailClient := mailer.WithTransport(app.NewMailClient(), rendered.Transport)
+
 	message := &mailer.Message{
 		From: mail.Address{
 			Name:    app.Settings().Meta.SenderName,
 			Address: app.Settings().Meta.SenderAddress,
 		},
 		To:      []mail.Address{{Address: authRecord.Email()}},
-		Subject: subject,
-		HTML:    body,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
 	}
 
 	event := new(core.MailerRecordEvent)
@@ -96,21 +97,22 @@ func SendRecordVerification(app core.App, authRecord *models.Record) error {
 		return tokenErr
 	}
 
-	mailClient := app.NewMailClient()
-
-	subject, body, err := resolveEmailTemplate(app, token, app.Settings().Meta.VerificationTemplate)
+	rendered, err := resolveRecordEmail(app, TemplateRecordVerification, app.Settings().Meta.VerificationTemplate, authRecord, token, "")
 	if err != nil {
 		return err
 	}
 
+	mailClient := mailer.WithTransport(app.NewMailClient(), rendered.Transport)
+
 	message := &mailer.Message{
 		From: mail.Address{
 			Name:    app.Settings().Meta.SenderName,
 			Address: app.Settings().Meta.SenderAddress,
 		},
 		To:      []mail.Address{{Address: authRecord.Email()}},
-		Subject: subject,
-		HTML:    body,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
 	}
 
 	event := new(core.MailerRecordEvent)
@@ -136,21 +138,22 @@ func SendRecordChangeEmail(app core.App, record *models.Record, newEmail string)
 		return tokenErr
 	}
 
-	mailClient := app.NewMailClient()
-
-	subject, body, err := resolveEmailTemplate(app, token, app.Settings().Meta.ConfirmEmailChangeTemplate)
+	rendered, err := resolveRecordEmail(app, TemplateRecordChangeEmail, app.Settings().Meta.ConfirmEmailChangeTemplate, record, token, newEmail)
 	if err != nil {
 		return err
 	}
 
+	mailClient := mailer.WithTransport(app.NewMailClient(), rendered.Transport)
+
 	message := &mailer.Message{
 		From: mail.Address{
 			Name:    app.Settings().Meta.SenderName,
 			Address: app.Settings().Meta.SenderAddress,
 		},
 		To:      []mail.Address{{Address: newEmail}},
-		Subject: subject,
-		HTML:    body,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
 	}
 
 	event := new(core.MailerRecordEvent)
@@ -172,27 +175,71 @@ func SendRecordChangeEmail(app core.App, record *models.Record, newEmail string)
 	})
 }
 
-func resolveEmailTemplate(
+// RecordEmailData defines the render data of the builtin record emails
+// (password reset, verification and email change) templates.
+type RecordEmailData struct {
+	AppName   string
+	AppUrl    string
+	Record    *models.Record
+	Token     string
+	ActionUrl string
+
+	// NewEmail is the new record email address (available only for the change email template).
+	NewEmail string
+}
+
+// resolveRecordEmail renders the named record email template
+// registered in [Templates] if there is one or fallbacks to the
+// provided app settings email template.
+func resolveRecordEmail(
 	app core.App,
-	token string,
+	name string,
 	emailTemplate settings.EmailTemplate,
-) (subject string, body string, err error) {
-	subject, rawBody, _ := emailTemplate.Resolve(
+	record *models.Record,
+	token string,
+	newEmail string,
+) (*RenderedEmail, error) {
+	subject, rawBody, actionUrl := emailTemplate.Resolve(
 		app.Settings().Meta.AppName,
 		app.Settings().Meta.AppUrl,
 		token,
 	)
 
+	if tpl := Templates.Get(name, recordLang(record)); tpl != nil {
+		return Templates.RenderTemplate(tpl, RecordEmailData{
+			AppName:   app.Settings().Meta.AppName,
+			AppUrl:    app.Settings().Meta.AppUrl,
+			Record:    record,
+			Token:     token,
+			ActionUrl: actionUrl,
+			NewEmail:  newEmail,
+		})
+	}
+
 	params := struct {
 		HtmlContent template.HTML
 	}{
 		HtmlContent: template.HTML(rawBody),
 	}
 
-	body, err = resolveTemplateContent(params, templates.Layout, templates.HtmlBody)
+	rendered, err := Templates.RenderTemplate(&EmailTemplate{HTML: templates.HtmlBody}, params)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+
+	// the settings subject placeholders are already resolved
+	rendered.Subject = subject
+	rendered.Transport = emailTemplate.Transport
+
+	return rendered, nil
+}
+
+// recordLang returns the language of the provided auth record
+// based on the [TemplateRegistry.LangField] of the default templates registry.
+func recordLang(record *models.Record) string {
+	if Templates.LangField == "" || record == nil {
+		return ""
 	}
 
-	return subject, body, nil
+	return record.GetString(Templates.LangField)
 }
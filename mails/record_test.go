@@ -1,11 +1,15 @@
 package mails_test
 
 import (
+	"errors"
+	"net/mail"
 	"strings"
 	"testing"
 
+	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/mails"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/hook"
 )
 
 func TestSendRecordPasswordLoginAlert(t *testing.T) {
@@ -120,3 +124,88 @@ func TestSendRecordChangeEmail(t *testing.T) {
 		}
 	}
 }
+
+func TestOnMailerSend(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name          string
+		handler       func(e *core.MailerSendEvent) error
+		expectError   bool
+		expectSend    bool
+		expectTo      string
+		expectBccSize int
+	}{
+		{
+			"modify message",
+			func(e *core.MailerSendEvent) error {
+				e.Message.To = []mail.Address{{Address: "changed@example.com"}}
+				e.Message.Bcc = append(e.Message.Bcc, mail.Address{Address: "audit@example.com"})
+				return nil
+			},
+			false,
+			true,
+			"changed@example.com",
+			1,
+		},
+		{
+			"stop propagation",
+			func(e *core.MailerSendEvent) error {
+				return hook.StopPropagation
+			},
+			false,
+			false,
+			"",
+			0,
+		},
+		{
+			"error",
+			func(e *core.MailerSendEvent) error {
+				return errors.New("test")
+			},
+			true,
+			false,
+			"",
+			0,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			testApp, _ := tests.NewTestApp()
+			defer testApp.Cleanup()
+
+			testApp.OnMailerSend().Add(s.handler)
+
+			user, _ := testApp.Dao().FindFirstRecordByData("users", "email", "test@example.com")
+
+			err := mails.SendRecordVerification(testApp, user)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !s.expectSend {
+				if testApp.TestMailer.TotalSend != 0 {
+					t.Fatalf("Expected no emails to be sent, got %d", testApp.TestMailer.TotalSend)
+				}
+				return
+			}
+
+			if testApp.TestMailer.TotalSend != 1 {
+				t.Fatalf("Expected one email to be sent, got %d", testApp.TestMailer.TotalSend)
+			}
+
+			msg := testApp.TestMailer.LastMessage
+
+			if len(msg.To) != 1 || msg.To[0].Address != s.expectTo {
+				t.Fatalf("Expected To %q, got %v", s.expectTo, msg.To)
+			}
+
+			if len(msg.Bcc) != s.expectBccSize {
+				t.Fatalf("Expected %d Bcc addresses, got %v", s.expectBccSize, msg.Bcc)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package mails
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/mail"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/mails/templates"
+	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/template"
+)
+
+// Names of the builtin email templates.
+//
+// Register a template with one of the names below to override the builtin email
+// (the record emails fallback to the app settings templates if not overridden).
+const (
+	TemplateAdminPasswordReset       = "admin_password_reset"
+	TemplateRecordPasswordReset      = "record_password_reset"
+	TemplateRecordVerification       = "record_verification"
+	TemplateRecordChangeEmail        = "record_change_email"
+	TemplateRecordPasswordLoginAlert = "record_password_login_alert"
+)
+
+// ErrTemplateNotFound is returned when rendering a non-registered email template.
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// Templates is the default email templates registry used by the builtin emails.
+var Templates = NewTemplateRegistry()
+
+// EmailTemplate defines a single transactional email template.
+//
+// All fields are Go templates that are executed with the same render data.
+type EmailTemplate struct {
+	// Subject is the email subject template.
+	Subject string
+
+	// HTML is the email body template that is rendered as the
+	// "content" block of the registry layout.
+	HTML string
+
+	// Text is an optional plain text body template
+	// (if not set, the mailer will generate one from the HTML body).
+	Text string
+
+	// Transport is an optional name of the mail transport to send the email with
+	// (fallbacks to the default transport if empty or missing, see [mailer.Transports]).
+	Transport string
+}
+
+// RenderedEmail defines the result of a rendered [EmailTemplate].
+type RenderedEmail struct {
+	Subject   string
+	HTML      string
+	Text      string
+	Transport string
+}
+
+// TemplateRegistry defines a named email templates registry
+// that is safe to be used by multiple goroutines.
+type TemplateRegistry struct {
+	mu        sync.RWMutex
+	layout    string
+	templates map[string]*EmailTemplate
+	renderer  *template.Registry
+
+	// LangField is an optional auth record field name which value is used
+	// to select the language variant of the builtin record emails (eg. "lang").
+	LangField string
+}
+
+// NewTemplateRegistry creates a new email templates registry
+// with the default layout and the builtin email templates.
+func NewTemplateRegistry() *TemplateRegistry {
+	r := &TemplateRegistry{
+		layout:    templates.Layout,
+		templates: map[string]*EmailTemplate{},
+		renderer:  template.NewRegistry(),
+	}
+
+	r.Register(TemplateAdminPasswordReset, "", &EmailTemplate{
+		Subject: "Reset admin password",
+		HTML:    templates.AdminPasswordResetBody,
+	})
+
+	r.Register(TemplateRecordPasswordLoginAlert, "", &EmailTemplate{
+		Subject: "Password login alert",
+		HTML:    templates.PasswordLoginAlertBody,
+	})
+
+	return r
+}
+
+// SetLayout replaces the shared layout of the registry templates.
+//
+// The layout must render the "content" template, eg. `<body>{{template "content" .}}</body>`.
+func (r *TemplateRegistry) SetLayout(layout string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.layout = layout
+}
+
+// AddFuncs registers new global template functions (see [template.Registry.AddFuncs]).
+func (r *TemplateRegistry) AddFuncs(funcs map[string]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.renderer.AddFuncs(funcs)
+}
+
+// Register registers (or replaces) the named email template for the specified language.
+//
+// Leave lang empty to register the default template variant.
+func (r *TemplateRegistry) Register(name string, lang string, tpl *EmailTemplate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates[templateKey(name, lang)] = tpl
+}
+
+// Unregister removes the named email template for the specified language.
+func (r *TemplateRegistry) Unregister(name string, lang string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.templates, templateKey(name, lang))
+}
+
+// Get returns the named email template for the specified language.
+//
+// If there is no exact language match, Get fallbacks to the base language
+// (eg. "pt-BR" -> "pt") and then to the default template variant.
+//
+// Returns nil if the template is not registered.
+func (r *TemplateRegistry) Get(name string, lang string) *EmailTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, l := range langCandidates(lang) {
+		if tpl, ok := r.templates[templateKey(name, l)]; ok {
+			return tpl
+		}
+	}
+
+	return nil
+}
+
+// Render renders the named email template for the specified language with the provided data.
+//
+// Returns [ErrTemplateNotFound] if the template is not registered (see [TemplateRegistry.Get]).
+func (r *TemplateRegistry) Render(name string, lang string, data any) (*RenderedEmail, error) {
+	tpl := r.Get(name, lang)
+	if tpl == nil {
+		return nil, fmt.Errorf("%w: %q", ErrTemplateNotFound, name)
+	}
+
+	return r.RenderTemplate(tpl, data)
+}
+
+// RenderTemplate renders the provided (not necessary registered) email template
+// with the registry layout and data.
+//
+// The subject and text templates are rendered without HTML escaping.
+// The HTML body CSS is inlined in the style attributes of the matching elements
+// for better email clients support (see [mailer.InlineCSS]).
+//
+// Note that the missing map data keys are rendered as empty string
+// while the missing struct fields result in an error.
+func (r *TemplateRegistry) RenderTemplate(tpl *EmailTemplate, data any) (*RenderedEmail, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := &RenderedEmail{Transport: tpl.Transport}
+
+	subject, err := r.renderText(tpl.Subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the email subject: %w", err)
+	}
+	// the subject is always single line
+	result.Subject = strings.Join(strings.Fields(subject), " ")
+
+	if tpl.HTML != "" {
+		content := tpl.HTML
+		if !strings.Contains(content, `{{define "content"}}`) {
+			content = `{{define "content"}}` + content + `{{end}}`
+		}
+
+		body, err := r.renderer.LoadString(r.layout + content).Render(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the email html body: %w", err)
+		}
+
+		result.HTML, err = mailer.InlineCSS(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inline the email html body css: %w", err)
+		}
+	}
+
+	result.Text, err = r.renderText(tpl.Text, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the email text body: %w", err)
+	}
+
+	return result, nil
+}
+
+// renderText renders the provided template text without HTML escaping.
+func (r *TemplateRegistry) renderText(text string, data any) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	// note: html/template is used for consistency with the html body
+	// rendering (eg. of the missing map keys) and after that unescaped
+	rendered, err := r.renderer.LoadString(text).Render(data)
+	if err != nil {
+		return "", err
+	}
+
+	return html.UnescapeString(rendered), nil
+}
+
+// Send renders the named email template for the specified language
+// and sends it to the provided recipients with the app mail client.
+//
+// The email is sent with the template transport (if any).
+func Send(app core.App, name string, lang string, to []mail.Address, data any) error {
+	return SendWithTransport(app, "", name, lang, to, data)
+}
+
+// SendWithTransport is similar to [Send] but sends the email through
+// the named mail transport instead of the template one (if not empty).
+func SendWithTransport(app core.App, transport string, name string, lang string, to []mail.Address, data any) error {
+	rendered, err := Templates.Render(name, lang, data)
+	if err != nil {
+		return err
+	}
+
+	if transport == "" {
+		transport = rendered.Transport
+	}
+
+	message := &mailer.Message{
+		From: mail.Address{
+			Name:    app.Settings().Meta.SenderName,
+			Address: app.Settings().Meta.SenderAddress,
+		},
+		To:      to,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
+	}
+
+	return mailer.WithTransport(app.NewMailClient(), transport).Send(message)
+}
+
+// templateKey returns the registry key of the named template and language.
+func templateKey(name string, lang string) string {
+	return name + "@" + normalizeLang(lang)
+}
+
+// normalizeLang returns the lower case version of the provided
+// language tag and with "-" as subtags separator (eg. "pt_BR" -> "pt-br").
+func normalizeLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// langCandidates returns the language lookup candidates in priority order
+// (eg. "pt-BR" -> ["pt-br", "pt", ""]).
+func langCandidates(lang string) []string {
+	lang = normalizeLang(lang)
+
+	result := []string{}
+
+	for lang != "" {
+		result = append(result, lang)
+
+		i := strings.LastIndexByte(lang, '-')
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+
+	return append(result, "")
+}
@@ -0,0 +1,279 @@
+package mails_test
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/mails"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestTemplateRegistryGet(t *testing.T) {
+	t.Parallel()
+
+	r := mails.NewTemplateRegistry()
+
+	defaultTpl := &mails.EmailTemplate{Subject: "default"}
+	ptTpl := &mails.EmailTemplate{Subject: "pt"}
+	ptBRTpl := &mails.EmailTemplate{Subject: "pt-BR"}
+
+	r.Register("test", "", defaultTpl)
+	r.Register("test", "pt", ptTpl)
+	r.Register("test", "pt_BR", ptBRTpl)
+	r.Register("other", "bg", &mails.EmailTemplate{Subject: "other"})
+
+	scenarios := []struct {
+		name     string
+		tplName  string
+		lang     string
+		expected *mails.EmailTemplate
+	}{
+		{"missing template", "missing", "", nil},
+		{"missing template language without default", "other", "en", nil},
+		{"default", "test", "", defaultTpl},
+		{"unknown language fallback to default", "test", "en", defaultTpl},
+		{"exact language match", "test", "pt", ptTpl},
+		{"exact language region match (case-insensitive)", "test", "PT-br", ptBRTpl},
+		{"unknown region fallback to base language", "test", "pt-PT", ptTpl},
+		{"builtin template", mails.TemplateAdminPasswordReset, "", r.Get(mails.TemplateAdminPasswordReset, "")},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := r.Get(s.tplName, s.lang)
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+
+	if r.Get(mails.TemplateAdminPasswordReset, "") == nil {
+		t.Fatal("Expected the builtin templates to be registered")
+	}
+
+	r.Unregister("test", "pt")
+	if result := r.Get("test", "pt"); result != defaultTpl {
+		t.Fatalf("Expected the default template after unregister, got %v", result)
+	}
+}
+
+func TestTemplateRegistryRender(t *testing.T) {
+	t.Parallel()
+
+	r := mails.NewTemplateRegistry()
+	r.SetLayout(`<html><head><style>.btn { color: red }</style></head><body>{{template "content" .}}</body></html>`)
+	r.AddFuncs(map[string]any{
+		"upper": strings.ToUpper,
+	})
+
+	r.Register("test", "", &mails.EmailTemplate{
+		Subject: "Hello {{.Name}}\n & {{.Missing}} <welcome>",
+		HTML:    `<p>{{.Name | upper}}</p><a class="btn" href="{{.Url}}">{{.Missing}}</a>`,
+		Text:    "Hi {{.Name}} & {{.Missing}}!",
+	})
+	r.Register("struct", "", &mails.EmailTemplate{
+		Subject: "Hello {{.Missing}}",
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		_, err := r.Render("missing", "", nil)
+		if !errors.Is(err, mails.ErrTemplateNotFound) {
+			t.Fatalf("Expected ErrTemplateNotFound, got %v", err)
+		}
+	})
+
+	t.Run("missing and extra map data", func(t *testing.T) {
+		result, err := r.Render("test", "", map[string]any{
+			"Name":  "<John>",
+			"Url":   "https://example.com?a=1&b=2",
+			"Extra": "extra",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := "Hello <John> & <welcome>"; result.Subject != expected {
+			t.Fatalf("Expected subject %q, got %q", expected, result.Subject)
+		}
+
+		if expected := "Hi <John> & !"; result.Text != expected {
+			t.Fatalf("Expected text %q, got %q", expected, result.Text)
+		}
+
+		expectedHTML := `<html><head><style>.btn { color: red }</style></head><body><p>&lt;JOHN&gt;</p><a class="btn" href="https://example.com?a=1&amp;b=2" style="color: red;"></a></body></html>`
+		if result.HTML != expectedHTML {
+			t.Fatalf("Expected html\n%s\ngot\n%s", expectedHTML, result.HTML)
+		}
+	})
+
+	t.Run("missing struct field", func(t *testing.T) {
+		_, err := r.Render("struct", "", struct{ Name string }{"John"})
+		if err == nil || !strings.Contains(err.Error(), "Missing") {
+			t.Fatalf("Expected missing field error, got %v", err)
+		}
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		_, err := r.RenderTemplate(&mails.EmailTemplate{HTML: "{{.Name"}, nil)
+		if err == nil {
+			t.Fatal("Expected parse error, got nil")
+		}
+	})
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	to := []mail.Address{{Address: "test@example.com"}}
+
+	if err := mails.Send(testApp, "missing_send_template", "", to, nil); !errors.Is(err, mails.ErrTemplateNotFound) {
+		t.Fatalf("Expected ErrTemplateNotFound, got %v", err)
+	}
+
+	if err := mails.Send(testApp, mails.TemplateRecordPasswordLoginAlert, "", to, map[string]any{
+		"AppName":       "Acme",
+		"ProviderNames": []string{"google"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if testApp.TestMailer.TotalSend != 1 {
+		t.Fatalf("Expected one email to be sent, got %d", testApp.TestMailer.TotalSend)
+	}
+
+	msg := testApp.TestMailer.LastMessage
+
+	if msg.Subject != "Password login alert" || msg.To[0].Address != "test@example.com" {
+		t.Fatalf("Unexpected message %v", msg)
+	}
+
+	if msg.From.Address != testApp.Settings().Meta.SenderAddress {
+		t.Fatalf("Expected sender %q, got %q", testApp.Settings().Meta.SenderAddress, msg.From.Address)
+	}
+
+	if !strings.Contains(msg.HTML, "google") || !strings.Contains(msg.HTML, `<body style="`) {
+		t.Fatalf("Expected rendered html with inlined css, got\n%s", msg.HTML)
+	}
+
+	if testApp.TestMailer.LastTransport != "" {
+		t.Fatalf("Expected the default transport, got %q", testApp.TestMailer.LastTransport)
+	}
+}
+
+func TestSendWithTransport(t *testing.T) {
+	t.Parallel()
+
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	to := []mail.Address{{Address: "test@example.com"}}
+
+	mails.Templates.Register("test_send_transport", "", &mails.EmailTemplate{
+		Subject:   "test",
+		HTML:      "test",
+		Transport: "marketing",
+	})
+	defer mails.Templates.Unregister("test_send_transport", "")
+
+	scenarios := []struct {
+		name      string
+		transport string
+		expected  string
+	}{
+		{"template transport", "", "marketing"},
+		{"explicit transport", "other", "other"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := mails.SendWithTransport(testApp, s.transport, "test_send_transport", "", to, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if testApp.TestMailer.LastTransport != s.expected {
+				t.Fatalf("Expected transport %q, got %q", s.expected, testApp.TestMailer.LastTransport)
+			}
+		})
+	}
+}
+
+// note: not parallel because it changes the default registry
+func TestBuiltinRecordEmailOverride(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	mails.Templates.LangField = "name"
+	mails.Templates.Register(mails.TemplateRecordVerification, "", &mails.EmailTemplate{
+		Subject: "Verify {{.Record.Email}}",
+		HTML:    `<a href="{{.ActionUrl}}">Verify</a>`,
+	})
+	mails.Templates.Register(mails.TemplateRecordVerification, "test1", &mails.EmailTemplate{
+		Subject: "[test1] Verify {{.Record.Email}} for {{.AppName}}",
+		HTML:    `<a href="{{.ActionUrl}}">{{.Token}}</a>`,
+		Text:    "{{.ActionUrl}}",
+	})
+	defer func() {
+		mails.Templates.LangField = ""
+		mails.Templates.Unregister(mails.TemplateRecordVerification, "")
+		mails.Templates.Unregister(mails.TemplateRecordVerification, "test1")
+	}()
+
+	user, err := testApp.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Set("name", "test1")
+
+	if err := mails.SendRecordVerification(testApp, user); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := testApp.TestMailer.LastMessage
+
+	if expected := "[test1] Verify test@example.com for " + testApp.Settings().Meta.AppName; msg.Subject != expected {
+		t.Fatalf("Expected subject %q, got %q", expected, msg.Subject)
+	}
+
+	if !strings.Contains(msg.Text, "/_/#/auth/confirm-verification/") {
+		t.Fatalf("Expected the action url in the text body, got %q", msg.Text)
+	}
+
+	// fallback to the default variant
+	user.Set("name", "missing")
+	if err := mails.SendRecordVerification(testApp, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := testApp.TestMailer.LastMessage; msg.Subject != "Verify test@example.com" {
+		t.Fatalf("Expected the default variant subject, got %q", msg.Subject)
+	}
+
+	// the not overridden templates fallback to the settings ones
+	if err := mails.SendRecordPasswordReset(testApp, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := testApp.TestMailer.LastMessage; msg.Subject != "Reset your "+testApp.Settings().Meta.AppName+" password" {
+		t.Fatalf("Expected the settings template subject, got %q", msg.Subject)
+	}
+
+	if testApp.TestMailer.LastTransport != "" {
+		t.Fatalf("Expected the default transport, got %q", testApp.TestMailer.LastTransport)
+	}
+
+	// settings template transport
+	testApp.Settings().Meta.ResetPasswordTemplate.Transport = "auth"
+	if err := mails.SendRecordPasswordReset(testApp, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if testApp.TestMailer.LastTransport != "auth" {
+		t.Fatalf("Expected the settings template transport, got %q", testApp.TestMailer.LastTransport)
+	}
+}
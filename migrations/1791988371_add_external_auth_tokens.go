@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+)
+
+// adds the OAuth2 tokens columns to the _externalAuths table (if not already)
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		columns, err := daos.New(db).TableColumns("_externalAuths")
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{"accessToken", "refreshToken", "expiry"} {
+			if slices.Contains(columns, name) {
+				continue // already inserted
+			}
+
+			if _, err := db.AddColumn("_externalAuths", name, "TEXT DEFAULT '' NOT NULL").Execute(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(db dbx.Builder) error {
+		for _, name := range []string{"accessToken", "refreshToken", "expiry"} {
+			if _, err := db.DropColumn("_externalAuths", name).Execute(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// creates the _auditLogs system table
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_auditLogs}} (
+				[[id]]                TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+				[[actorType]]         TEXT DEFAULT "" NOT NULL,
+				[[actorId]]           TEXT DEFAULT "" NOT NULL,
+				[[actorCollectionId]] TEXT DEFAULT "" NOT NULL,
+				[[collectionId]]      TEXT DEFAULT "" NOT NULL,
+				[[collectionName]]    TEXT DEFAULT "" NOT NULL,
+				[[recordId]]          TEXT DEFAULT "" NOT NULL,
+				[[action]]            TEXT DEFAULT "" NOT NULL,
+				[[changes]]           JSON DEFAULT "{}" NOT NULL,
+				[[ip]]                TEXT DEFAULT "" NOT NULL,
+				[[created]]           TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]           TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS _auditLogs_record_idx on {{_auditLogs}} ([[collectionId]], [[recordId]]);
+			CREATE INDEX IF NOT EXISTS _auditLogs_actor_idx on {{_auditLogs}} ([[actorCollectionId]], [[actorId]]);
+			CREATE INDEX IF NOT EXISTS _auditLogs_created_idx on {{_auditLogs}} ([[created]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_auditLogs").Execute()
+
+		return err
+	})
+}
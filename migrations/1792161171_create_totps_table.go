@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// creates the _totps system table
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_totps}} (
+				[[id]]            TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+				[[collectionId]]  TEXT NOT NULL,
+				[[recordId]]      TEXT NOT NULL,
+				[[secret]]        TEXT DEFAULT "" NOT NULL,
+				[[verified]]      BOOLEAN DEFAULT FALSE NOT NULL,
+				[[lastUsedStep]]  INTEGER DEFAULT 0 NOT NULL,
+				[[recoveryCodes]] JSON DEFAULT "[]" NOT NULL,
+				[[created]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				---
+				FOREIGN KEY ([[collectionId]]) REFERENCES {{_collections}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS _totps_record_idx on {{_totps}} ([[collectionId]], [[recordId]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_totps").Execute()
+
+		return err
+	})
+}
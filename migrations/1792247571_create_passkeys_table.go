@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// creates the _passkeys system table
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_passkeys}} (
+				[[id]]           TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+				[[collectionId]] TEXT NOT NULL,
+				[[recordId]]     TEXT NOT NULL,
+				[[credentialId]] TEXT NOT NULL,
+				[[name]]         TEXT DEFAULT "" NOT NULL,
+				[[credential]]   JSON DEFAULT "{}" NOT NULL,
+				[[created]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				---
+				FOREIGN KEY ([[collectionId]]) REFERENCES {{_collections}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS _passkeys_credential_idx on {{_passkeys}} ([[collectionId]], [[credentialId]]);
+			CREATE INDEX IF NOT EXISTS _passkeys_record_idx on {{_passkeys}} ([[collectionId]], [[recordId]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_passkeys").Execute()
+
+		return err
+	})
+}
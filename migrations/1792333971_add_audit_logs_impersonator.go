@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+)
+
+// adds the impersonatorId column to the _auditLogs table (if not already)
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		columns, err := daos.New(db).TableColumns("_auditLogs")
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(columns, "impersonatorId") {
+			return nil // already inserted
+		}
+
+		_, err = db.AddColumn("_auditLogs", "impersonatorId", "TEXT DEFAULT '' NOT NULL").Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropColumn("_auditLogs", "impersonatorId").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// creates the _authRefreshTokens system table
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_authRefreshTokens}} (
+				[[id]]           TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+				[[collectionId]] TEXT NOT NULL,
+				[[recordId]]     TEXT NOT NULL,
+				[[familyId]]     TEXT NOT NULL,
+				[[tokenHash]]    TEXT NOT NULL,
+				[[tokenKeyHash]] TEXT NOT NULL,
+				[[used]]         BOOLEAN DEFAULT FALSE NOT NULL,
+				[[expires]]      TEXT DEFAULT "" NOT NULL,
+				[[created]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				---
+				FOREIGN KEY ([[collectionId]]) REFERENCES {{_collections}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS _authRefreshTokens_hash_idx on {{_authRefreshTokens}} ([[tokenHash]]);
+			CREATE INDEX IF NOT EXISTS _authRefreshTokens_record_idx on {{_authRefreshTokens}} ([[collectionId]], [[recordId]]);
+			CREATE INDEX IF NOT EXISTS _authRefreshTokens_family_idx on {{_authRefreshTokens}} ([[familyId]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_authRefreshTokens").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// creates the _apiKeys system table
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_apiKeys}} (
+				[[id]]          TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+				[[name]]        TEXT NOT NULL,
+				[[keyHash]]     TEXT NOT NULL,
+				[[collections]] JSON DEFAULT "[]" NOT NULL,
+				[[scopes]]      JSON DEFAULT "[]" NOT NULL,
+				[[expires]]     TEXT DEFAULT "" NOT NULL,
+				[[created]]     TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]     TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS _apiKeys_keyHash_idx on {{_apiKeys}} ([[keyHash]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_apiKeys").Execute()
+
+		return err
+	})
+}
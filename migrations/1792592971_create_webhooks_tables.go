@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// creates the _webhooks and _webhookFailures system tables
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE IF NOT EXISTS {{_webhooks}} (
+				[[id]]           TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+				[[name]]         TEXT NOT NULL,
+				[[collectionId]] TEXT NOT NULL,
+				[[events]]       JSON DEFAULT "[]" NOT NULL,
+				[[url]]          TEXT NOT NULL,
+				[[secret]]       TEXT DEFAULT "" NOT NULL,
+				[[active]]       BOOLEAN DEFAULT TRUE NOT NULL,
+				[[created]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS _webhooks_collectionId_idx on {{_webhooks}} ([[collectionId]]);
+
+			CREATE TABLE IF NOT EXISTS {{_webhookFailures}} (
+				[[id]]           TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+				[[webhookId]]    TEXT NOT NULL,
+				[[collectionId]] TEXT DEFAULT "" NOT NULL,
+				[[recordId]]     TEXT DEFAULT "" NOT NULL,
+				[[event]]        TEXT DEFAULT "" NOT NULL,
+				[[url]]          TEXT DEFAULT "" NOT NULL,
+				[[payload]]      JSON DEFAULT "{}" NOT NULL,
+				[[attempts]]     INTEGER DEFAULT 0 NOT NULL,
+				[[statusCode]]   INTEGER DEFAULT 0 NOT NULL,
+				[[error]]        TEXT DEFAULT "" NOT NULL,
+				[[created]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+				[[updated]]      TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS _webhookFailures_webhookId_idx on {{_webhookFailures}} ([[webhookId]]);
+			CREATE INDEX IF NOT EXISTS _webhookFailures_created_idx on {{_webhookFailures}} ([[created]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		tables := []string{"_webhookFailures", "_webhooks"}
+
+		for _, name := range tables {
+			if _, err := db.DropTable(name).Execute(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
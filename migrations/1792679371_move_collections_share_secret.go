@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// replaces the collections "shareSecret" option with "allowShare"
+// and persists the app settings to store the new default recordShareToken secret
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		collections := []*models.Collection{}
+		if err := dao.CollectionQuery().All(&collections); err != nil {
+			return err
+		}
+
+		for _, c := range collections {
+			secret, ok := c.Options["shareSecret"]
+			if !ok {
+				continue
+			}
+
+			delete(c.Options, "shareSecret")
+
+			if v, _ := secret.(string); v != "" {
+				c.Options["allowShare"] = true
+			}
+
+			if err := dao.WithoutHooks().Save(c); err != nil {
+				return err
+			}
+		}
+
+		settings, _ := dao.FindSettings()
+		if settings != nil {
+			if err := dao.SaveSettings(settings); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil)
+}
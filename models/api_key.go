@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ Model = (*ApiKey)(nil)
+
+// ApiKeyPrefix is the prefix of all plain api keys
+// (used to distinguish them from the JWT auth tokens).
+const ApiKeyPrefix = "pb_key_"
+
+// Built-in api key scopes.
+const (
+	ApiKeyScopeRead  = "read"
+	ApiKeyScopeWrite = "write"
+)
+
+// ApiKey defines a named server-to-server api key.
+//
+// The api key grants access only to the listed collections and
+// operations (the key scopes) and only its hash is stored.
+type ApiKey struct {
+	BaseModel
+
+	Name string `db:"name" json:"name"`
+
+	// KeyHash is the hash of the plain api key.
+	KeyHash string `db:"keyHash" json:"-"`
+
+	// Collections is the list with the ids of the collections
+	// that could be accessed with the api key.
+	Collections types.JsonArray[string] `db:"collections" json:"collections"`
+
+	// Scopes is the list with the allowed api key operations
+	// (eg. [ApiKeyScopeRead], [ApiKeyScopeWrite] or any other custom
+	// scope that could be checked in the collection API rules).
+	Scopes types.JsonArray[string] `db:"scopes" json:"scopes"`
+
+	// Expires is the optional api key expiration date.
+	Expires types.DateTime `db:"expires" json:"expires"`
+}
+
+func (m *ApiKey) TableName() string {
+	return "_apiKeys"
+}
+
+// HasScope checks whether the api key has the provided scope.
+func (m *ApiKey) HasScope(scope string) bool {
+	return list.ExistInSlice(scope, m.Scopes)
+}
+
+// CanAccessCollection checks whether the provided
+// collection is one of the api key collections.
+func (m *ApiKey) CanAccessCollection(collection *Collection) bool {
+	for _, c := range m.Collections {
+		if c == collection.Id || strings.EqualFold(c, collection.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsExpired checks whether the api key has an expiration date in the past.
+func (m *ApiKey) IsExpired() bool {
+	return !m.Expires.IsZero() && !m.Expires.Time().After(time.Now())
+}
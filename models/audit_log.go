@@ -0,0 +1,56 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ Model = (*AuditLog)(nil)
+
+// list with the supported audit log actions
+const (
+	AuditActionCreate               = "create"
+	AuditActionUpdate               = "update"
+	AuditActionDelete               = "delete"
+	AuditActionAuthWithPassword     = "authWithPassword"
+	AuditActionAuthWithOAuth2       = "authWithOAuth2"
+	AuditActionAuthRefresh          = "authRefresh"
+	AuditActionConfirmPasswordReset = "confirmPasswordReset"
+	AuditActionConfirmVerification  = "confirmVerification"
+	AuditActionConfirmEmailChange   = "confirmEmailChange"
+	AuditActionImpersonate          = "impersonate"
+)
+
+// AuditActorSystem is the audit log actor type of the record changes
+// made outside of a record API request (eg. from a hook or a cron job).
+const AuditActorSystem = "system"
+
+// AuditLog defines a single record or auth change entry.
+type AuditLog struct {
+	BaseModel
+
+	// ActorType is the request auth type
+	// (see [RequestAuthGuest], [RequestAuthAdmin], [RequestAuthRecord] and [AuditActorSystem]).
+	ActorType         string `db:"actorType" json:"actorType"`
+	ActorId           string `db:"actorId" json:"actorId"`
+	ActorCollectionId string `db:"actorCollectionId" json:"actorCollectionId"`
+
+	// ImpersonatorId is the id of the admin that issued the actor
+	// auth token (if the request was made with an impersonation token).
+	ImpersonatorId string `db:"impersonatorId" json:"impersonatorId"`
+
+	CollectionId   string `db:"collectionId" json:"collectionId"`
+	CollectionName string `db:"collectionName" json:"collectionName"`
+	RecordId       string `db:"recordId" json:"recordId"`
+	Action         string `db:"action" json:"action"`
+
+	// Changes holds the changed record fields in the format:
+	//
+	//	{"fieldName": {"old": oldValue, "new": newValue}}
+	Changes types.JsonMap `db:"changes" json:"changes"`
+
+	Ip string `db:"ip" json:"ip"`
+}
+
+func (m *AuditLog) TableName() string {
+	return "_auditLogs"
+}
@@ -0,0 +1,16 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestAuditLogTableName(t *testing.T) {
+	t.Parallel()
+
+	m := models.AuditLog{}
+	if m.TableName() != "_auditLogs" {
+		t.Fatalf("Unexpected table name, got %q", m.TableName())
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ Model = (*AuthRefreshToken)(nil)
+
+// AuthRefreshToken defines a single server-side stored auth record refresh token.
+//
+// The refresh tokens are rotated on each use and all tokens
+// originating from the same auth are grouped in a single family.
+type AuthRefreshToken struct {
+	BaseModel
+
+	CollectionId string `db:"collectionId" json:"collectionId"`
+	RecordId     string `db:"recordId" json:"recordId"`
+
+	// FamilyId groups the refresh token with its rotated predecessors and successors.
+	FamilyId string `db:"familyId" json:"familyId"`
+
+	// TokenHash is the keyed hash of the plain refresh token.
+	TokenHash string `db:"tokenHash" json:"-"`
+
+	// TokenKeyHash is the hash of the auth record token key at the time
+	// of issuing (used to invalidate the refresh tokens on token key change).
+	TokenKeyHash string `db:"tokenKeyHash" json:"-"`
+
+	// Used indicates whether the refresh token was already exchanged.
+	Used bool `db:"used" json:"used"`
+
+	Expires types.DateTime `db:"expires" json:"expires"`
+}
+
+func (m *AuthRefreshToken) TableName() string {
+	return "_authRefreshTokens"
+}
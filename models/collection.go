@@ -2,11 +2,17 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -20,6 +26,16 @@ const (
 	CollectionTypeView = "view"
 )
 
+const (
+	// TrashCascadeTrash trashes the cascade delete references
+	// of a trashed record (if their collection has soft delete enabled).
+	TrashCascadeTrash = "trash"
+
+	// TrashCascadeDelete applies the regular delete cascade
+	// to the references of a trashed record.
+	TrashCascadeDelete = "delete"
+)
+
 type Collection struct {
 	BaseModel
 
@@ -97,6 +113,133 @@ func (m *Collection) ViewOptions() CollectionViewOptions {
 	return result
 }
 
+// SoftDeleteOptions decodes the current collection options and returns
+// the soft delete related fields as new [CollectionSoftDeleteOptions] instance.
+func (m *Collection) SoftDeleteOptions() CollectionSoftDeleteOptions {
+	result := CollectionSoftDeleteOptions{}
+	if !m.IsView() {
+		m.DecodeOptions(&result)
+	}
+	return result
+}
+
+// HasSoftDelete checks if the current collection has the soft delete
+// (aka. trash mode) enabled.
+func (m *Collection) HasSoftDelete() bool {
+	if m.IsView() || m.Options == nil {
+		return false
+	}
+
+	// read directly the options map to avoid the decode overhead
+	// since the method is called for every loaded record
+	v, _ := m.Options.Get("softDelete").(bool)
+
+	return v
+}
+
+// FullTextSearchFields returns the names of the collection fields
+// that are full-text indexed (if any).
+func (m *Collection) FullTextSearchFields() []string {
+	if m.IsView() || m.Options == nil {
+		return nil
+	}
+
+	return list.ToUniqueStringSlice(m.Options.Get("searchFields"))
+}
+
+// FullTextSearchTableName returns the name of the collection FTS5 virtual table.
+//
+// The name is based on the collection id so that it remains
+// the same when the collection is renamed.
+func (m *Collection) FullTextSearchTableName() string {
+	return "_" + m.Id + "_fts"
+}
+
+// TenantField returns the name of the collection field used for the
+// tenant row-scoping (empty string if the collection is not tenant-scoped).
+func (m *Collection) TenantField() string {
+	if m.IsView() || m.Options == nil {
+		return ""
+	}
+
+	// read directly the options map to avoid the decode overhead
+	v, _ := m.Options.Get("tenantField").(string)
+
+	return v
+}
+
+// TenantScopedRule returns the provided collection API rule combined
+// with the implicit tenant scope predicate of the collection, aka.:
+//
+//	@request.auth.TENANT_FIELD != '' && TENANT_FIELD = @request.auth.TENANT_FIELD
+//
+// The rule is returned as it is if the collection is not tenant-scoped
+// or the rule is nil (aka. only admins are allowed).
+func (m *Collection) TenantScopedRule(rule *string) *string {
+	field := m.TenantField()
+	if rule == nil || field == "" {
+		return rule
+	}
+
+	scope := fmt.Sprintf("@request.auth.%[1]s != '' && %[1]s = @request.auth.%[1]s", field)
+
+	if *rule != "" {
+		scope = "(" + scope + ") && (" + *rule + ")"
+	}
+
+	return &scope
+}
+
+// ListCacheTTL returns the duration for which the records list api
+// results of the collection could be cached (0 if the caching is disabled).
+func (m *Collection) ListCacheTTL() time.Duration {
+	if m.IsView() || m.Options == nil {
+		return 0
+	}
+
+	// read directly the options map to avoid the decode overhead
+	seconds := cast.ToInt(m.Options.Get("listCacheTTL"))
+
+	return time.Duration(seconds) * time.Second
+}
+
+// ExpireField returns the name of the collection date field that holds
+// the records expiration date (empty string if the records don't expire).
+func (m *Collection) ExpireField() string {
+	if m.IsView() || m.Options == nil {
+		return ""
+	}
+
+	// read directly the options map to avoid the decode overhead
+	v, _ := m.Options.Get("expireField").(string)
+
+	return v
+}
+
+// ExpireGracePeriod returns the duration after the records expiration
+// date before they are deleted (0 if there is no grace period).
+func (m *Collection) ExpireGracePeriod() time.Duration {
+	if m.IsView() || m.Options == nil {
+		return 0
+	}
+
+	// read directly the options map to avoid the decode overhead
+	seconds := cast.ToInt(m.Options.Get("expireGracePeriod"))
+
+	return time.Duration(seconds) * time.Second
+}
+
+// AllowShare checks whether the collection records could be
+// publicly shared with record share tokens.
+func (m *Collection) AllowShare() bool {
+	if m.IsView() || m.Options == nil {
+		return false
+	}
+
+	// read directly the options map to avoid the decode overhead
+	return cast.ToBool(m.Options.Get("allowShare"))
+}
+
 // NormalizeOptions updates the current collection options with a
 // new normalized state based on the collection type.
 func (m *Collection) NormalizeOptions() error {
@@ -160,13 +303,154 @@ func (m *Collection) SetOptions(typedOptions any) error {
 
 // -------------------------------------------------------------------
 
+// CollectionSoftDeleteOptions defines the soft delete related
+// "base" and "auth" Collection.Options fields.
+type CollectionSoftDeleteOptions struct {
+	// SoftDelete enables the soft delete (aka. trash mode) of the collection records.
+	//
+	// When enabled, deleting a record sets its "deleted" system field
+	// instead of removing the row and the trashed records are excluded
+	// from the default record queries.
+	SoftDelete bool `form:"softDelete" json:"softDelete,omitempty"`
+
+	// TrashCascade specifies how the relation references of a trashed
+	// record are handled - [TrashCascadeTrash] (default) or [TrashCascadeDelete].
+	TrashCascade string `form:"trashCascade" json:"trashCascade,omitempty"`
+}
+
+// Validate implements [validation.Validatable] interface.
+func (o CollectionSoftDeleteOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.TrashCascade, validation.In(TrashCascadeTrash, TrashCascadeDelete)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// CollectionFullTextSearchOptions defines the full-text search related
+// "base" and "auth" Collection.Options fields.
+type CollectionFullTextSearchOptions struct {
+	// SearchFields is the list with the names of the text fields
+	// to index in the collection FTS5 virtual table
+	// (see [Collection.FullTextSearchTableName]).
+	SearchFields []string `form:"searchFields" json:"searchFields,omitempty"`
+}
+
+// Validate implements [validation.Validatable] interface.
+func (o CollectionFullTextSearchOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.SearchFields, validation.By(checkUniqueFieldNames)),
+	)
+}
+
+func checkUniqueFieldNames(value any) error {
+	v, _ := value.([]string)
+
+	if len(list.ToUniqueStringSlice(v)) != len(v) {
+		return validation.NewError("validation_duplicated_field_names", "The field names must be unique.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// CollectionTenantOptions defines the tenant row-scoping related
+// "base" and "auth" Collection.Options fields.
+type CollectionTenantOptions struct {
+	// TenantField is the name of the collection field that holds the
+	// record tenant (see [Collection.TenantScopedRule]).
+	//
+	// When set, the collection API rules are implicitly constrained to the
+	// records with the same tenant as the authenticated record.
+	TenantField string `form:"tenantField" json:"tenantField,omitempty"`
+}
+
+// -------------------------------------------------------------------
+
+// CollectionListCacheOptions defines the records list api caching
+// related "base" and "auth" Collection.Options fields.
+type CollectionListCacheOptions struct {
+	// ListCacheTTL is the number of seconds for which the records list api
+	// results are cached (0 to disable).
+	//
+	// The cached results are keyed by the full request query and auth state
+	// and they are invalidated on every collection record change.
+	ListCacheTTL int `form:"listCacheTTL" json:"listCacheTTL,omitempty"`
+}
+
+// Validate implements [validation.Validatable] interface.
+func (o CollectionListCacheOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.ListCacheTTL, validation.Min(0), validation.Max(86400)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// CollectionExpireOptions defines the records expiration (aka. TTL)
+// related "base" and "auth" Collection.Options fields.
+type CollectionExpireOptions struct {
+	// ExpireField is the name of the collection date field that holds
+	// the record expiration date (see [Collection.ExpireField]).
+	//
+	// When set, the records with expiration date in the past are
+	// periodically deleted in the background.
+	ExpireField string `form:"expireField" json:"expireField,omitempty"`
+
+	// ExpireGracePeriod is the number of seconds to wait after the
+	// record expiration date before deleting the record.
+	ExpireGracePeriod int `form:"expireGracePeriod" json:"expireGracePeriod,omitempty"`
+}
+
+// Validate implements [validation.Validatable] interface.
+func (o CollectionExpireOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.ExpireGracePeriod, validation.Min(0), validation.Max(31536000)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// CollectionShareOptions defines the public record share tokens
+// related "base" and "auth" Collection.Options fields.
+type CollectionShareOptions struct {
+	// AllowShare enables the public record share tokens
+	// (see Dao.NewRecordShareToken).
+	//
+	// The tokens are signed with the app Settings.RecordShareToken secret
+	// and disabling the option revokes all previously issued share tokens
+	// of the collection records.
+	AllowShare bool `form:"allowShare" json:"allowShare,omitempty"`
+}
+
+// -------------------------------------------------------------------
+
 // CollectionBaseOptions defines the "base" Collection.Options fields.
 type CollectionBaseOptions struct {
+	CollectionSoftDeleteOptions
+	CollectionFullTextSearchOptions
+	CollectionTenantOptions
+	CollectionListCacheOptions
+	CollectionExpireOptions
+	CollectionShareOptions
 }
 
 // Validate implements [validation.Validatable] interface.
 func (o CollectionBaseOptions) Validate() error {
-	return nil
+	if err := o.CollectionSoftDeleteOptions.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.CollectionListCacheOptions.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.CollectionExpireOptions.Validate(); err != nil {
+		return err
+	}
+
+	return o.CollectionFullTextSearchOptions.Validate()
 }
 
 // -------------------------------------------------------------------
@@ -182,10 +466,55 @@ type CollectionAuthOptions struct {
 	OnlyVerified       bool     `form:"onlyVerified" json:"onlyVerified"`
 	OnlyEmailDomains   []string `form:"onlyEmailDomains" json:"onlyEmailDomains"`
 	MinPasswordLength  int      `form:"minPasswordLength" json:"minPasswordLength"`
+
+	// RefreshOAuth2Tokens enables the automatic background renewal of
+	// the expired stored OAuth2 access tokens on auth record refresh
+	// (for the providers that have returned a refresh token).
+	//
+	// Note that the OAuth2 tokens are stored only if the app has an encryption key.
+	RefreshOAuth2Tokens bool `form:"refreshOAuth2Tokens" json:"refreshOAuth2Tokens"`
+
+	// AllowPasskeyAuth enables the WebAuthn passkey registration
+	// and authentication of the auth collection records.
+	AllowPasskeyAuth bool `form:"allowPasskeyAuth" json:"allowPasskeyAuth"`
+
+	// AllowRefreshTokens enables the issuing of long-lived rotating
+	// refresh tokens together with the auth record access tokens.
+	AllowRefreshTokens bool `form:"allowRefreshTokens" json:"allowRefreshTokens"`
+
+	// PasswordHash specifies the algorithm and parameters for hashing
+	// the record passwords (nil means bcrypt with the default cost).
+	//
+	// When set, the existing password hashes created with a different
+	// algorithm or parameters are rehashed on successful password login.
+	PasswordHash *CollectionPasswordHashOptions `form:"passwordHash" json:"passwordHash,omitempty"`
+
+	CollectionSoftDeleteOptions
+	CollectionFullTextSearchOptions
+	CollectionTenantOptions
+	CollectionListCacheOptions
+	CollectionExpireOptions
+	CollectionShareOptions
 }
 
 // Validate implements [validation.Validatable] interface.
 func (o CollectionAuthOptions) Validate() error {
+	if err := o.CollectionSoftDeleteOptions.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.CollectionListCacheOptions.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.CollectionExpireOptions.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.CollectionFullTextSearchOptions.Validate(); err != nil {
+		return err
+	}
+
 	return validation.ValidateStruct(&o,
 		validation.Field(&o.ManageRule, validation.NilOrNotEmpty),
 		validation.Field(
@@ -202,9 +531,63 @@ func (o CollectionAuthOptions) Validate() error {
 			validation.Min(5),
 			validation.Max(72),
 		),
+		validation.Field(&o.PasswordHash),
+	)
+}
+
+// PasswordHasher returns the configured password hasher of the auth collection records.
+func (o CollectionAuthOptions) PasswordHasher() security.PasswordHasher {
+	if o.PasswordHash == nil {
+		return &security.BcryptHasher{}
+	}
+
+	return o.PasswordHash.Hasher()
+}
+
+// -------------------------------------------------------------------
+
+// CollectionPasswordHashOptions defines the auth collection password hashing options.
+type CollectionPasswordHashOptions struct {
+	// Algorithm is the password hashing algorithm -
+	// [security.PasswordAlgorithmBcrypt] (default) or [security.PasswordAlgorithmArgon2id].
+	Algorithm string `form:"algorithm" json:"algorithm"`
+
+	// BcryptCost is the bcrypt hashing cost (default to [security.DefaultBcryptCost]).
+	BcryptCost int `form:"bcryptCost" json:"bcryptCost,omitempty"`
+
+	// Argon2idMemory is the argon2id memory cost in KiB (default to 65536).
+	Argon2idMemory uint32 `form:"argon2idMemory" json:"argon2idMemory,omitempty"`
+
+	// Argon2idIterations is the argon2id number of passes (default to 3).
+	Argon2idIterations uint32 `form:"argon2idIterations" json:"argon2idIterations,omitempty"`
+
+	// Argon2idParallelism is the argon2id number of threads (default to 2).
+	Argon2idParallelism uint8 `form:"argon2idParallelism" json:"argon2idParallelism,omitempty"`
+}
+
+// Validate implements [validation.Validatable] interface.
+func (o CollectionPasswordHashOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Algorithm, validation.In(security.PasswordAlgorithmBcrypt, security.PasswordAlgorithmArgon2id)),
+		validation.Field(&o.BcryptCost, validation.Min(bcrypt.MinCost), validation.Max(bcrypt.MaxCost)),
+		validation.Field(&o.Argon2idMemory, validation.Min(uint32(8*1024)), validation.Max(uint32(4*1024*1024))),
+		validation.Field(&o.Argon2idIterations, validation.Max(uint32(100))),
 	)
 }
 
+// Hasher returns a new [security.PasswordHasher] initialized from the options.
+func (o CollectionPasswordHashOptions) Hasher() security.PasswordHasher {
+	if o.Algorithm == security.PasswordAlgorithmArgon2id {
+		return &security.Argon2idHasher{
+			Memory:      o.Argon2idMemory,
+			Iterations:  o.Argon2idIterations,
+			Parallelism: o.Argon2idParallelism,
+		}
+	}
+
+	return &security.BcryptHasher{Cost: o.BcryptCost}
+}
+
 // -------------------------------------------------------------------
 
 // CollectionViewOptions defines the "view" Collection.Options fields.
@@ -3,6 +3,7 @@ package models_test
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/models"
@@ -100,7 +101,7 @@ func TestCollectionMarshalJSON(t *testing.T) {
 		{
 			"auth type + non empty options",
 			models.Collection{BaseModel: models.BaseModel{Id: "test"}, Type: models.CollectionTypeAuth, Options: types.JsonMap{"test": 123, "allowOAuth2Auth": true, "minPasswordLength": 4, "onlyVerified": true}},
-			`{"id":"test","created":"","updated":"","name":"","type":"auth","system":false,"schema":[],"indexes":[],"listRule":null,"viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{"allowEmailAuth":false,"allowOAuth2Auth":true,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":true,"requireEmail":false}}`,
+			`{"id":"test","created":"","updated":"","name":"","type":"auth","system":false,"schema":[],"indexes":[],"listRule":null,"viewRule":null,"createRule":null,"updateRule":null,"deleteRule":null,"options":{"allowEmailAuth":false,"allowOAuth2Auth":true,"allowPasskeyAuth":false,"allowRefreshTokens":false,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":true,"refreshOAuth2Tokens":false,"requireEmail":false}}`,
 		},
 	}
 
@@ -168,7 +169,7 @@ func TestCollectionAuthOptions(t *testing.T) {
 	t.Parallel()
 
 	options := types.JsonMap{"test": 123, "minPasswordLength": 4}
-	expectedSerialization := `{"manageRule":null,"allowOAuth2Auth":false,"allowUsernameAuth":false,"allowEmailAuth":false,"requireEmail":false,"exceptEmailDomains":null,"onlyVerified":false,"onlyEmailDomains":null,"minPasswordLength":4}`
+	expectedSerialization := `{"manageRule":null,"allowOAuth2Auth":false,"allowUsernameAuth":false,"allowEmailAuth":false,"requireEmail":false,"exceptEmailDomains":null,"onlyVerified":false,"onlyEmailDomains":null,"minPasswordLength":4,"refreshOAuth2Tokens":false,"allowPasskeyAuth":false,"allowRefreshTokens":false}`
 
 	scenarios := []struct {
 		name       string
@@ -283,7 +284,7 @@ func TestNormalizeOptions(t *testing.T) {
 		{
 			"auth type",
 			models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"test": 123, "minPasswordLength": 4}},
-			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":false,"requireEmail":false}`,
+			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowPasskeyAuth":false,"allowRefreshTokens":false,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":false,"refreshOAuth2Tokens":false,"requireEmail":false}`,
 		},
 	}
 
@@ -356,7 +357,7 @@ func TestSetOptions(t *testing.T) {
 			"auth type",
 			models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"test": 123}},
 			map[string]any{"test": 456, "minPasswordLength": 4},
-			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":false,"requireEmail":false}`,
+			`{"allowEmailAuth":false,"allowOAuth2Auth":false,"allowPasskeyAuth":false,"allowRefreshTokens":false,"allowUsernameAuth":false,"exceptEmailDomains":null,"manageRule":null,"minPasswordLength":4,"onlyEmailDomains":null,"onlyVerified":false,"refreshOAuth2Tokens":false,"requireEmail":false}`,
 		},
 	}
 
@@ -520,3 +521,287 @@ func TestCollectionViewOptionsValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectionSoftDeleteOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		options        models.CollectionSoftDeleteOptions
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			models.CollectionSoftDeleteOptions{},
+			[]string{},
+		},
+		{
+			"invalid trashCascade",
+			models.CollectionSoftDeleteOptions{SoftDelete: true, TrashCascade: "invalid"},
+			[]string{"trashCascade"},
+		},
+		{
+			"valid data",
+			models.CollectionSoftDeleteOptions{SoftDelete: true, TrashCascade: models.TrashCascadeDelete},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.options.Validate()
+
+			// parse errors
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got errors \n%v", s.expectedErrors, result)
+			}
+
+			for key := range errs {
+				if !list.ExistInSlice(key, s.expectedErrors) {
+					t.Fatalf("Unexpected error key %q in \n%v", key, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionPasswordHashOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		options        models.CollectionPasswordHashOptions
+		expectedErrors []string
+	}{
+		{
+			"empty",
+			models.CollectionPasswordHashOptions{},
+			[]string{},
+		},
+		{
+			"invalid data",
+			models.CollectionPasswordHashOptions{
+				Algorithm:          "invalid",
+				BcryptCost:         100,
+				Argon2idMemory:     1,
+				Argon2idIterations: 101,
+			},
+			[]string{"algorithm", "bcryptCost", "argon2idMemory", "argon2idIterations"},
+		},
+		{
+			"valid data",
+			models.CollectionPasswordHashOptions{
+				Algorithm:           "argon2id",
+				BcryptCost:          10,
+				Argon2idMemory:      19 * 1024,
+				Argon2idIterations:  2,
+				Argon2idParallelism: 1,
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.options.Validate()
+
+			// parse errors
+			errs, ok := result.(validation.Errors)
+			if !ok && result != nil {
+				t.Fatalf("Failed to parse errors %v", result)
+			}
+
+			if len(errs) != len(s.expectedErrors) {
+				t.Fatalf("Expected error keys %v, got errors \n%v", s.expectedErrors, result)
+			}
+
+			for key := range errs {
+				if !list.ExistInSlice(key, s.expectedErrors) {
+					t.Fatalf("Unexpected error key %q in \n%v", key, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionHasSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		collection models.Collection
+		expected   bool
+	}{
+		{models.Collection{}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"softDelete": false}}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"softDelete": true}}, true},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"softDelete": true}}, true},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"softDelete": true}}, false},
+	}
+
+	for i, s := range scenarios {
+		result := s.collection.HasSoftDelete()
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+
+		if v := s.collection.SoftDeleteOptions().SoftDelete; v != s.expected {
+			t.Errorf("(%d) Expected SoftDeleteOptions().SoftDelete %v, got %v", i, s.expected, v)
+		}
+	}
+}
+
+func TestCollectionTenantScopedRule(t *testing.T) {
+	t.Parallel()
+
+	scoped := models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"tenantField": "org"}}
+
+	scenarios := []struct {
+		name          string
+		collection    models.Collection
+		rule          *string
+		expectedField string
+		expected      *string
+	}{
+		{"not scoped", models.Collection{Type: models.CollectionTypeBase}, types.Pointer("a = 1"), "", types.Pointer("a = 1")},
+		{"view", models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"tenantField": "org"}}, types.Pointer(""), "", types.Pointer("")},
+		{"nil rule", scoped, nil, "org", nil},
+		{"empty rule", scoped, types.Pointer(""), "org", types.Pointer("@request.auth.org != '' && org = @request.auth.org")},
+		{"non-empty rule", scoped, types.Pointer("a = 1 || b = 2"), "org", types.Pointer("(@request.auth.org != '' && org = @request.auth.org) && (a = 1 || b = 2)")},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := s.collection.TenantField(); v != s.expectedField {
+				t.Fatalf("Expected tenant field %q, got %q", s.expectedField, v)
+			}
+
+			result := s.collection.TenantScopedRule(s.rule)
+
+			if s.expected == nil || result == nil {
+				if s.expected != result {
+					t.Fatalf("Expected %v, got %v", s.expected, result)
+				}
+				return
+			}
+
+			if *result != *s.expected {
+				t.Fatalf("Expected %q, got %q", *s.expected, *result)
+			}
+		})
+	}
+}
+
+func TestCollectionListCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		collection models.Collection
+		expected   time.Duration
+	}{
+		{models.Collection{Type: models.CollectionTypeBase}, 0},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"listCacheTTL": 30}}, 30 * time.Second},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"listCacheTTL": 1.0}}, time.Second},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"listCacheTTL": 30}}, 0},
+	}
+
+	for i, s := range scenarios {
+		if v := s.collection.ListCacheTTL(); v != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, v)
+		}
+	}
+}
+
+func TestCollectionListCacheOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		options     models.CollectionListCacheOptions
+		expectError bool
+	}{
+		{models.CollectionListCacheOptions{}, false},
+		{models.CollectionListCacheOptions{ListCacheTTL: 60}, false},
+		{models.CollectionListCacheOptions{ListCacheTTL: -1}, true},
+		{models.CollectionListCacheOptions{ListCacheTTL: 86401}, true},
+	}
+
+	for i, s := range scenarios {
+		err := s.options.Validate()
+
+		if hasErr := err != nil; hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestCollectionExpireOptions(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		collection    models.Collection
+		expectedField string
+		expectedGrace time.Duration
+	}{
+		{models.Collection{Type: models.CollectionTypeBase}, "", 0},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"expireField": "expires"}}, "expires", 0},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"expireField": "expires", "expireGracePeriod": 60.0}}, "expires", time.Minute},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"expireField": "expires", "expireGracePeriod": 60}}, "", 0},
+	}
+
+	for i, s := range scenarios {
+		if v := s.collection.ExpireField(); v != s.expectedField {
+			t.Errorf("(%d) Expected expire field %q, got %q", i, s.expectedField, v)
+		}
+
+		if v := s.collection.ExpireGracePeriod(); v != s.expectedGrace {
+			t.Errorf("(%d) Expected grace period %v, got %v", i, s.expectedGrace, v)
+		}
+	}
+}
+
+func TestCollectionExpireOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		options     models.CollectionExpireOptions
+		expectError bool
+	}{
+		{models.CollectionExpireOptions{}, false},
+		{models.CollectionExpireOptions{ExpireField: "expires", ExpireGracePeriod: 3600}, false},
+		{models.CollectionExpireOptions{ExpireGracePeriod: -1}, true},
+		{models.CollectionExpireOptions{ExpireGracePeriod: 31536001}, true},
+	}
+
+	for i, s := range scenarios {
+		err := s.options.Validate()
+
+		if hasErr := err != nil; hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestCollectionAllowShare(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		collection models.Collection
+		expected   bool
+	}{
+		{models.Collection{Type: models.CollectionTypeBase}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"allowShare": false}}, false},
+		{models.Collection{Type: models.CollectionTypeBase, Options: types.JsonMap{"allowShare": true}}, true},
+		{models.Collection{Type: models.CollectionTypeAuth, Options: types.JsonMap{"allowShare": true}}, true},
+		{models.Collection{Type: models.CollectionTypeView, Options: types.JsonMap{"allowShare": true}}, false},
+	}
+
+	for i, s := range scenarios {
+		if v := s.collection.AllowShare(); v != s.expected {
+			t.Errorf("(%d) Expected allowShare %v, got %v", i, s.expected, v)
+		}
+	}
+}
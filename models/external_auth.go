@@ -1,7 +1,18 @@
 package models
 
+import (
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
 var _ Model = (*ExternalAuth)(nil)
 
+// externalAuthTokenExpiryDelta is the leeway used when checking the
+// stored OAuth2 access token expiration to prevent using tokens
+// that are about to expire (similar to the oauth2 package).
+const externalAuthTokenExpiryDelta = 10 * time.Second
+
 type ExternalAuth struct {
 	BaseModel
 
@@ -9,8 +20,26 @@ type ExternalAuth struct {
 	RecordId     string `db:"recordId" json:"recordId"`
 	Provider     string `db:"provider" json:"provider"`
 	ProviderId   string `db:"providerId" json:"providerId"`
+
+	// the OAuth2 tokens are stored encrypted only for server-side usage
+	// and are never exposed in the API responses
+	// (see daos.Dao.DecryptExternalAuthTokens)
+	AccessToken  string         `db:"accessToken" json:"-"`
+	RefreshToken string         `db:"refreshToken" json:"-"`
+	Expiry       types.DateTime `db:"expiry" json:"-"`
 }
 
 func (m *ExternalAuth) TableName() string {
 	return "_externalAuths"
 }
+
+// IsAccessTokenExpired reports whether the stored OAuth2 access token has expired.
+//
+// A zero Expiry value is treated as non-expiring token.
+func (m *ExternalAuth) IsAccessTokenExpired() bool {
+	if m.Expiry.IsZero() {
+		return false
+	}
+
+	return m.Expiry.Time().Add(-externalAuthTokenExpiryDelta).Before(time.Now())
+}
@@ -2,8 +2,10 @@ package models_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestExternalAuthTableName(t *testing.T) {
@@ -14,3 +16,33 @@ func TestExternalAuthTableName(t *testing.T) {
 		t.Fatalf("Unexpected table name, got %q", m.TableName())
 	}
 }
+
+func TestExternalAuthIsAccessTokenExpired(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		expiry   time.Time
+		expected bool
+	}{
+		{"zero expiry", time.Time{}, false},
+		{"past expiry", time.Now().Add(-1 * time.Hour), true},
+		{"future expiry", time.Now().Add(1 * time.Hour), false},
+		{"about to expire", time.Now().Add(5 * time.Second), true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			m := models.ExternalAuth{}
+
+			if !s.expiry.IsZero() {
+				m.Expiry, _ = types.ParseDateTime(s.expiry)
+			}
+
+			result := m.IsAccessTokenExpired()
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ Model = (*Passkey)(nil)
+
+// Passkey defines a single WebAuthn credential (aka. passkey)
+// registered for an auth record.
+type Passkey struct {
+	BaseModel
+
+	CollectionId string `db:"collectionId" json:"collectionId"`
+	RecordId     string `db:"recordId" json:"recordId"`
+
+	// CredentialId is the base64 raw url encoded WebAuthn credential id.
+	CredentialId string `db:"credentialId" json:"credentialId"`
+
+	// Name is an optional user friendly label of the passkey.
+	Name string `db:"name" json:"name"`
+
+	// Credential is the serialized WebAuthn credential data
+	// (public key, flags, sign counter, etc.).
+	Credential types.JsonRaw `db:"credential" json:"-"`
+}
+
+func (m *Passkey) TableName() string {
+	return "_passkeys"
+}
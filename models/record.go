@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +16,6 @@ import (
 	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -29,8 +29,9 @@ type Record struct {
 
 	collection *Collection
 
-	exportUnknown         bool // whether to export unknown fields
-	ignoreEmailVisibility bool // whether to ignore the emailVisibility flag for auth collections
+	exportUnknown         bool                // whether to export unknown fields
+	ignoreEmailVisibility bool                // whether to ignore the emailVisibility flag for auth collections
+	hidden                map[string]struct{} // fields to exclude from the public export
 	loaded                bool
 	originalData          map[string]any    // the original (aka. first loaded) model data
 	expand                *store.Store[any] // expanded relations
@@ -67,6 +68,9 @@ func NewRecordFromNullStringMap(collection *Collection, data dbx.NullStringMap)
 
 	// load schema fields
 	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // not stored
+		}
 		resultMap[field.Name] = nullStringMapValue(data, field.Name)
 	}
 
@@ -82,6 +86,11 @@ func NewRecordFromNullStringMap(collection *Collection, data dbx.NullStringMap)
 		}
 	}
 
+	// load the soft delete field
+	if collection.HasSoftDelete() {
+		resultMap[schema.FieldNameDeleted] = nullStringMapValue(data, schema.FieldNameDeleted)
+	}
+
 	record := NewRecord(collection)
 
 	record.Load(resultMap)
@@ -131,6 +140,64 @@ func (m *Record) OriginalCopy() *Record {
 	return newRecord
 }
 
+// FieldChange defines a single changed record field value.
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Changes returns a map with the record fields whose current value
+// differs from the ORIGINAL (aka. the initially loaded) one (see [Record.OriginalCopy]).
+//
+// The schema fields (except the computed ones), the auth fields (for auth collections)
+// and the "deleted" field (for soft delete collections) are checked.
+// The values are compared in their normalized form, meaning that
+// the file fields old/new values are the file names and
+// the relation fields old/new values are the related record ids.
+//
+// It could be used for example in the update hooks to check which fields were modified:
+//
+//	app.OnRecordBeforeUpdateRequest("posts").Add(func(e *core.RecordUpdateEvent) error {
+//		if change, ok := e.Record.Changes()["status"]; ok {
+//			log.Println("status changed from", change.Old, "to", change.New)
+//		}
+//		return nil
+//	})
+func (m *Record) Changes() map[string]FieldChange {
+	original := m.OriginalCopy()
+
+	fields := make([]string, 0, len(m.collection.Schema.Fields())+len(schema.AuthFieldNames())+1)
+	for _, f := range m.collection.Schema.Fields() {
+		if f.Type == schema.FieldTypeComputed {
+			continue // not stored
+		}
+		fields = append(fields, f.Name)
+	}
+	if m.collection.IsAuth() {
+		fields = append(fields, schema.AuthFieldNames()...)
+	}
+	if m.collection.HasSoftDelete() {
+		fields = append(fields, schema.FieldNameDeleted)
+	}
+
+	changes := map[string]FieldChange{}
+
+	for _, name := range fields {
+		oldValue := original.Get(name)
+		newValue := m.Get(name)
+
+		oldRaw, oldErr := json.Marshal(oldValue)
+		newRaw, newErr := json.Marshal(newValue)
+		if oldErr == nil && newErr == nil && bytes.Equal(oldRaw, newRaw) {
+			continue // unchanged
+		}
+
+		changes[name] = FieldChange{Old: oldValue, New: newValue}
+	}
+
+	return changes
+}
+
 // CleanCopy returns a copy of the current record model populated only
 // with its LATEST data state and everything else reset to the defaults.
 func (m *Record) CleanCopy() *Record {
@@ -285,6 +352,27 @@ func (m *Record) WithUnknownData(state bool) {
 	m.exportUnknown = state
 }
 
+// Hide excludes the specified fields from the record public export/serialization.
+//
+// Note that the fields values are not changed, aka. they are still
+// accessible with [Record.Get] and will be persisted on save.
+func (m *Record) Hide(fieldNames ...string) {
+	if m.hidden == nil {
+		m.hidden = make(map[string]struct{}, len(fieldNames))
+	}
+
+	for _, name := range fieldNames {
+		m.hidden[name] = struct{}{}
+	}
+}
+
+// Unhide reverts the [Record.Hide] exclusion of the specified fields.
+func (m *Record) Unhide(fieldNames ...string) {
+	for _, name := range fieldNames {
+		delete(m.hidden, name)
+	}
+}
+
 // Set sets the provided key-value data pair for the current Record model.
 //
 // If the record collection has field with name matching the provided "key",
@@ -303,7 +391,12 @@ func (m *Record) Set(key string, value any) {
 		var v = value
 
 		if field := m.Collection().Schema.GetFieldByName(key); field != nil {
+			if field.Type == schema.FieldTypeComputed {
+				return // always calculated on read
+			}
 			v = field.PrepareValue(value)
+		} else if key == schema.FieldNameDeleted && m.collection.HasSoftDelete() {
+			v, _ = types.ParseDateTime(value)
 		} else if m.collection.IsAuth() {
 			// normalize auth fields
 			switch key {
@@ -342,7 +435,12 @@ func (m *Record) Get(key string) any {
 		// normalize the field value in case it is missing or an incorrect type was set
 		// to ensure that the DB will always have normalized columns value.
 		if field := m.Collection().Schema.GetFieldByName(key); field != nil {
+			if field.Type == schema.FieldTypeComputed {
+				return m.computeFieldValue(field)
+			}
 			v = field.PrepareValue(v)
+		} else if key == schema.FieldNameDeleted && m.collection.HasSoftDelete() {
+			v, _ = types.ParseDateTime(v)
 		} else if m.collection.IsAuth() {
 			switch key {
 			case schema.FieldNameEmailVisibility, schema.FieldNameVerified:
@@ -389,6 +487,12 @@ func (m *Record) GetDateTime(key string) types.DateTime {
 	return d
 }
 
+// GetDuration returns the data value for "key" as a Duration instance.
+func (m *Record) GetDuration(key string) types.Duration {
+	d, _ := types.ParseDuration(m.Get(key))
+	return d
+}
+
 // GetStringSlice returns the data value for "key" as a slice of unique strings.
 func (m *Record) GetStringSlice(key string) []string {
 	return list.ToUniqueStringSlice(m.Get(key))
@@ -493,6 +597,9 @@ func (m *Record) ColumnValueMap() map[string]any {
 
 	// export schema field values
 	for _, field := range m.collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeComputed {
+			continue // not stored
+		}
 		result[field.Name] = m.getNormalizeDataValueForDB(field.Name)
 	}
 
@@ -503,6 +610,11 @@ func (m *Record) ColumnValueMap() map[string]any {
 		}
 	}
 
+	// export the soft delete field
+	if m.collection.HasSoftDelete() {
+		result[schema.FieldNameDeleted] = m.DeletedAt()
+	}
+
 	// export base model fields
 	result[schema.FieldNameId] = m.getNormalizeDataValueForDB(schema.FieldNameId)
 	result[schema.FieldNameCreated] = m.getNormalizeDataValueForDB(schema.FieldNameCreated)
@@ -527,7 +639,17 @@ func (m *Record) PublicExport() map[string]any {
 
 	// export schema field values
 	for _, field := range m.collection.Schema.Fields() {
-		result[field.Name] = m.Get(field.Name)
+		value := m.Get(field.Name)
+
+		// omit the non-empty masked encrypted fields
+		if field.Type == schema.FieldTypeEncrypted && value != "" {
+			field.InitOptions()
+			if options, _ := field.Options.(*schema.EncryptedOptions); options == nil || !options.Unmasked {
+				continue
+			}
+		}
+
+		result[field.Name] = value
 	}
 
 	// export some of the safe auth collection fields
@@ -548,6 +670,9 @@ func (m *Record) PublicExport() map[string]any {
 	if updated := m.GetUpdated(); !m.Collection().IsView() || !updated.IsZero() {
 		result[schema.FieldNameUpdated] = updated
 	}
+	if m.collection.HasSoftDelete() {
+		result[schema.FieldNameDeleted] = m.DeletedAt()
+	}
 
 	// add helper collection reference fields
 	result[schema.FieldNameCollectionId] = m.collection.Id
@@ -558,6 +683,10 @@ func (m *Record) PublicExport() map[string]any {
 		result[schema.FieldNameExpand] = m.expand.GetAll()
 	}
 
+	for name := range m.hidden {
+		delete(result, name)
+	}
+
 	return result
 }
 
@@ -744,6 +873,10 @@ func (m *Record) extractUnknownData(data map[string]any) map[string]any {
 		}
 	}
 
+	if m.collection.HasSoftDelete() {
+		knownFields[schema.FieldNameDeleted] = struct{}{}
+	}
+
 	result := map[string]any{}
 
 	for k, v := range data {
@@ -755,6 +888,77 @@ func (m *Record) extractUnknownData(data map[string]any) map[string]any {
 	return result
 }
 
+// computeFieldValue evaluates the computed field expression
+// against the current record data.
+//
+// Returns nil if the expression evaluation fails (eg. division by zero).
+func (m *Record) computeFieldValue(field *schema.SchemaField) any {
+	result, _ := m.EvalComputedField(field)
+
+	return result
+}
+
+// EvalComputedField evaluates the provided computed field expression
+// against the current record data.
+//
+// Returns an error if the expression evaluation fails
+// (eg. division by zero or a NaN/±Inf numeric result).
+func (m *Record) EvalComputedField(field *schema.SchemaField) (any, error) {
+	field.InitOptions()
+
+	options, _ := field.Options.(*schema.ComputedOptions)
+	if options == nil {
+		return nil, errors.New("missing computed field options")
+	}
+
+	program, err := options.Program()
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]any, len(program.Identifiers()))
+	for _, name := range program.Identifiers() {
+		// allow only the base and the non-computed schema fields
+		// (computed references are not allowed to prevent cycles)
+		ref := m.collection.Schema.GetFieldByName(name)
+		if (ref == nil && !list.ExistInSlice(name, schema.BaseModelFieldNames())) ||
+			(ref != nil && ref.Type == schema.FieldTypeComputed) {
+			continue
+		}
+		vars[name] = m.Get(name)
+	}
+
+	return program.Eval(vars)
+}
+
+// -------------------------------------------------------------------
+// Soft delete helpers
+// -------------------------------------------------------------------
+
+// DeletedAt returns the "deleted" soft delete record data value
+// (zero DateTime if the record is not trashed).
+func (m *Record) DeletedAt() types.DateTime {
+	return m.GetDateTime(schema.FieldNameDeleted)
+}
+
+// SetDeletedAt sets the "deleted" soft delete record data value.
+//
+// Returns an error if the record collection doesn't have soft delete enabled.
+func (m *Record) SetDeletedAt(dateTime types.DateTime) error {
+	if !m.collection.HasSoftDelete() {
+		return errors.New("The record collection doesn't have soft delete enabled.")
+	}
+
+	m.Set(schema.FieldNameDeleted, dateTime)
+
+	return nil
+}
+
+// IsTrashed checks if the record is soft deleted.
+func (m *Record) IsTrashed() bool {
+	return m.collection.HasSoftDelete() && !m.DeletedAt().IsZero()
+}
+
 // -------------------------------------------------------------------
 // Auth helpers
 // -------------------------------------------------------------------
@@ -929,9 +1133,51 @@ func (m *Record) ValidatePassword(password string) bool {
 		return false
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(m.PasswordHash()), []byte(password))
+	return security.VerifyPassword(m.PasswordHash(), password)
+}
+
+// PasswordNeedsRehash reports whether the auth record password hash
+// was created with a different algorithm or parameters than the ones
+// configured in the collection PasswordHash options.
+//
+// Always returns false if the collection doesn't have explicit
+// PasswordHash options or the record is not from an auth collection.
+func (m *Record) PasswordNeedsRehash() bool {
+	if !m.collection.IsAuth() {
+		return false
+	}
+
+	options := m.collection.AuthOptions().PasswordHash
+	if options == nil {
+		return false
+	}
+
+	return options.Hasher().NeedsRehash(m.PasswordHash())
+}
+
+// RehashPassword replaces the auth record password hash with a new one
+// created with the configured collection password hasher.
+//
+// Unlike [Record.SetPassword] it doesn't reset the "tokenKey" and
+// "lastResetSentAt" fields and it is intended to be used only after
+// a successful password verification (eg. to upgrade legacy hashes).
+func (m *Record) RehashPassword(password string) error {
+	if !m.collection.IsAuth() {
+		return notAuthRecordErr
+	}
+
+	if password == "" {
+		return errors.New("The provided plain password is empty")
+	}
 
-	return err == nil
+	hash, err := m.collection.AuthOptions().PasswordHasher().Hash(password)
+	if err != nil {
+		return err
+	}
+
+	m.Set(schema.FieldNamePasswordHash, hash)
+
+	return nil
 }
 
 // SetPassword sets cryptographically secure string to the auth record "password" field.
@@ -949,12 +1195,12 @@ func (m *Record) SetPassword(password string) error {
 	}
 
 	// hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	hashedPassword, err := m.collection.AuthOptions().PasswordHasher().Hash(password)
 	if err != nil {
 		return err
 	}
 
-	m.Set(schema.FieldNamePasswordHash, string(hashedPassword))
+	m.Set(schema.FieldNamePasswordHash, hashedPassword)
 	m.Set(schema.FieldNameLastResetSentAt, types.DateTime{})
 
 	// invalidate previously issued tokens
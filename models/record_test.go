@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -371,6 +372,91 @@ func TestRecordOriginalCopy(t *testing.T) {
 	}
 }
 
+func TestRecordChanges(t *testing.T) {
+	t.Parallel()
+
+	collection := &models.Collection{
+		Type:    models.CollectionTypeAuth,
+		Options: types.JsonMap{"softDelete": true},
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "text", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "number", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{Name: "bool", Type: schema.FieldTypeBool},
+			&schema.SchemaField{Name: "date", Type: schema.FieldTypeDate},
+			&schema.SchemaField{Name: "json", Type: schema.FieldTypeJson},
+			&schema.SchemaField{Name: "select", Type: schema.FieldTypeSelect, Options: &schema.SelectOptions{MaxSelect: 2, Values: []string{"a", "b"}}},
+			&schema.SchemaField{Name: "file_one", Type: schema.FieldTypeFile, Options: &schema.FileOptions{MaxSelect: 1}},
+			&schema.SchemaField{Name: "file_many", Type: schema.FieldTypeFile, Options: &schema.FileOptions{MaxSelect: 3}},
+			&schema.SchemaField{Name: "rel_one", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{MaxSelect: types.Pointer(1)}},
+			&schema.SchemaField{Name: "rel_many", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{}},
+			&schema.SchemaField{Name: "computed", Type: schema.FieldTypeComputed, Options: &schema.ComputedOptions{Expression: "text"}},
+		),
+	}
+
+	m := models.NewRecord(collection)
+	m.Load(map[string]any{
+		"id":        "test",
+		"created":   "2023-01-01 00:00:00.000Z",
+		"updated":   "2023-01-01 00:00:00.000Z",
+		"email":     "test@example.com",
+		"text":      "abc",
+		"number":    "1",
+		"bool":      "true",
+		"date":      "2023-01-01 00:00:00.000Z",
+		"json":      `{"a":1}`,
+		"select":    `["a"]`,
+		"file_one":  "f1.txt",
+		"file_many": `["f2.txt","f3.txt"]`,
+		"rel_one":   "r1",
+		"rel_many":  `["r2","r3"]`,
+	})
+	m.MarkAsNotNew()
+
+	if changes := m.Changes(); len(changes) != 0 {
+		t.Fatalf("Expected no changes, got %v", changes)
+	}
+
+	// partial update
+	m.Set("updated", "2023-01-02 00:00:00.000Z") // base model fields are not checked
+	m.Set("text", "abc")                         // same value
+	m.Set("number", 1)                           // same normalized value
+	m.Set("bool", false)
+	m.Set("json", map[string]any{"a": 2})
+	m.Set("select", []string{"b", "a"})
+	m.Set("file_one", nil)
+	m.Set("file_many", []string{"f3.txt", "f4.txt"})
+	m.Set("rel_one", "r4")
+	m.Set("rel_many", []string{"r2"})
+	m.Set("email", "new@example.com")
+	m.Set("deleted", "2023-01-03 00:00:00.000Z")
+
+	raw, err := json.Marshal(m.Changes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{` +
+		`"bool":{"old":true,"new":false},` +
+		`"deleted":{"old":"","new":"2023-01-03 00:00:00.000Z"},` +
+		`"email":{"old":"test@example.com","new":"new@example.com"},` +
+		`"file_many":{"old":["f2.txt","f3.txt"],"new":["f3.txt","f4.txt"]},` +
+		`"file_one":{"old":"f1.txt","new":""},` +
+		`"json":{"old":{"a":1},"new":{"a":2}},` +
+		`"rel_many":{"old":["r2","r3"],"new":["r2"]},` +
+		`"rel_one":{"old":"r1","new":"r4"},` +
+		`"select":{"old":["a"],"new":["a","b"]}` +
+		`}`
+
+	if string(raw) != expected {
+		t.Fatalf("Expected changes\n%s\ngot\n%s", expected, raw)
+	}
+
+	// the original state shouldn't be affected
+	if changes := m.OriginalCopy().Changes(); len(changes) != 0 {
+		t.Fatalf("Expected no changes for the original copy, got %v", changes)
+	}
+}
+
 func TestRecordCleanCopy(t *testing.T) {
 	t.Parallel()
 
@@ -1015,7 +1101,7 @@ func TestRecordGetDateTime(t *testing.T) {
 		{map[string]int{"test": 1}, time.Time{}},
 		{1641024040, testTime},
 		{"2022-01-01 08:00:40.000", testTime},
-		{nowTime, nowTime},
+		{nowTime, nowTime.Truncate(types.DateTimePrecision)},
 	}
 
 	collection := &models.Collection{}
@@ -2197,3 +2283,326 @@ func TestRecordSetPassword(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordSetPasswordWithPasswordHashOptions(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		options        types.JsonMap
+		expectedPrefix string
+	}{
+		{
+			"no password hash options",
+			nil,
+			"$2a$12$",
+		},
+		{
+			"bcrypt with custom cost",
+			types.JsonMap{"passwordHash": map[string]any{"algorithm": "bcrypt", "bcryptCost": 4}},
+			"$2a$04$",
+		},
+		{
+			"argon2id",
+			types.JsonMap{"passwordHash": map[string]any{
+				"algorithm":           "argon2id",
+				"argon2idMemory":      8 * 1024,
+				"argon2idIterations":  1,
+				"argon2idParallelism": 1,
+			}},
+			"$argon2id$v=19$m=8192,t=1,p=1$",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection := &models.Collection{Type: models.CollectionTypeAuth, Options: s.options}
+			m := models.NewRecord(collection)
+
+			if err := m.SetPassword("123456"); err != nil {
+				t.Fatal(err)
+			}
+
+			if hash := m.PasswordHash(); !strings.HasPrefix(hash, s.expectedPrefix) {
+				t.Fatalf("Expected hash with prefix %q, got %q", s.expectedPrefix, hash)
+			}
+
+			if !m.ValidatePassword("123456") {
+				t.Fatal("Expected the password to be valid")
+			}
+
+			if m.PasswordNeedsRehash() {
+				t.Fatal("Expected PasswordNeedsRehash false")
+			}
+		})
+	}
+}
+
+func TestRecordPasswordRehash(t *testing.T) {
+	t.Parallel()
+
+	// 123456
+	bcryptHash := "$2a$10$YKU8mPP8sTE3xZrpuM.xQuq27KJ7aIJB2oUeKPsDDqZshbl5g5cDK"
+
+	argon2idOptions := types.JsonMap{"passwordHash": map[string]any{
+		"algorithm":           "argon2id",
+		"argon2idMemory":      8 * 1024,
+		"argon2idIterations":  1,
+		"argon2idParallelism": 1,
+	}}
+
+	// non-auth collection
+	base := models.NewRecord(&models.Collection{Type: models.CollectionTypeBase, Options: argon2idOptions})
+	if base.PasswordNeedsRehash() {
+		t.Fatal("Expected PasswordNeedsRehash false for non-auth record")
+	}
+	if err := base.RehashPassword("123456"); err == nil {
+		t.Fatal("Expected RehashPassword error for non-auth record")
+	}
+
+	// legacy hash without password hash options
+	legacy := models.NewRecord(&models.Collection{Type: models.CollectionTypeAuth})
+	legacy.Set(schema.FieldNamePasswordHash, bcryptHash)
+	if legacy.PasswordNeedsRehash() {
+		t.Fatal("Expected PasswordNeedsRehash false without explicit password hash options")
+	}
+
+	m := models.NewRecord(&models.Collection{Type: models.CollectionTypeAuth, Options: argon2idOptions})
+	m.Set(schema.FieldNamePasswordHash, bcryptHash)
+	m.Set(schema.FieldNameTokenKey, "test_token_key")
+	m.Set(schema.FieldNameLastResetSentAt, "2022-01-01 00:00:00.000Z")
+
+	if !m.ValidatePassword("123456") {
+		t.Fatal("Expected the legacy bcrypt password to be valid")
+	}
+
+	if !m.PasswordNeedsRehash() {
+		t.Fatal("Expected PasswordNeedsRehash true for the legacy bcrypt hash")
+	}
+
+	if err := m.RehashPassword(""); err == nil {
+		t.Fatal("Expected RehashPassword error for empty password")
+	}
+
+	if err := m.RehashPassword("123456"); err != nil {
+		t.Fatal(err)
+	}
+
+	if hash := m.PasswordHash(); !strings.HasPrefix(hash, "$argon2id$") {
+		t.Fatalf("Expected argon2id hash, got %q", hash)
+	}
+
+	if !m.ValidatePassword("123456") {
+		t.Fatal("Expected the rehashed password to be valid")
+	}
+
+	if m.PasswordNeedsRehash() {
+		t.Fatal("Expected PasswordNeedsRehash false after the rehash")
+	}
+
+	if v := m.TokenKey(); v != "test_token_key" {
+		t.Fatalf("Expected the tokenKey to be unchanged, got %q", v)
+	}
+
+	if v := m.LastResetSentAt().String(); v != "2022-01-01 00:00:00.000Z" {
+		t.Fatalf("Expected the lastResetSentAt to be unchanged, got %q", v)
+	}
+}
+
+func TestRecordDeletedAt(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		options     types.JsonMap
+		expectError bool
+	}{
+		{nil, true},
+		{types.JsonMap{"softDelete": false}, true},
+		{types.JsonMap{"softDelete": true}, false},
+	}
+
+	testValue, err := types.ParseDateTime("2022-01-01 00:00:00.123Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, s := range scenarios {
+		collection := &models.Collection{Type: models.CollectionTypeBase, Options: s.options}
+		m := models.NewRecord(collection)
+
+		if m.IsTrashed() {
+			t.Fatalf("(%d) Expected the new record to not be trashed", i)
+		}
+
+		if s.expectError {
+			if err := m.SetDeletedAt(testValue); err == nil {
+				t.Errorf("(%d) Expected error, got nil", i)
+			}
+			if v := m.DeletedAt(); !v.IsZero() {
+				t.Fatalf("(%d) Expected empty value, got %v", i, v)
+			}
+			if m.IsTrashed() {
+				t.Fatalf("(%d) Expected the record to not be trashed", i)
+			}
+			// verify that the field is not exported
+			if _, ok := m.PublicExport()[schema.FieldNameDeleted]; ok {
+				t.Fatalf("(%d) Didn't expect exported field %q", i, schema.FieldNameDeleted)
+			}
+		} else {
+			if err := m.SetDeletedAt(testValue); err != nil {
+				t.Fatalf("(%d) Expected nil, got error %v", i, err)
+			}
+			if v := m.DeletedAt(); v != testValue {
+				t.Fatalf("(%d) Expected %v, got %v", i, testValue, v)
+			}
+			if !m.IsTrashed() {
+				t.Fatalf("(%d) Expected the record to be trashed", i)
+			}
+			// verify that the field is exported
+			if v := m.PublicExport()[schema.FieldNameDeleted]; v != testValue {
+				t.Fatalf("(%d) Expected exported field value %v, got %v", i, testValue, v)
+			}
+		}
+	}
+}
+
+func TestRecordComputedField(t *testing.T) {
+	t.Parallel()
+
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "firstName", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "lastName", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "amount", Type: schema.FieldTypeNumber},
+			&schema.SchemaField{
+				Name:    "fullName",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: `firstName + " " + lastName`},
+			},
+			&schema.SchemaField{
+				Name:    "ratio",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: `100 / amount`},
+			},
+		),
+	}
+
+	m := models.NewRecord(collection)
+	m.Id = "test_id"
+	m.Set("firstName", "John")
+	m.Set("lastName", "Doe")
+	m.Set("amount", 4)
+	m.Set("fullName", "ignored") // computed fields cannot be set
+
+	if v := m.Get("fullName"); v != "John Doe" {
+		t.Fatalf("Expected fullName %q, got %v", "John Doe", v)
+	}
+
+	if v := m.Get("ratio"); v != 25.0 {
+		t.Fatalf("Expected ratio %v, got %v", 25.0, v)
+	}
+
+	// evaluation error
+	m.Set("amount", 0)
+	if v := m.Get("ratio"); v != nil {
+		t.Fatalf("Expected nil ratio, got %v", v)
+	}
+
+	// the computed values are exported but never persisted
+	export := m.PublicExport()
+	if v := export["fullName"]; v != "John Doe" {
+		t.Fatalf("Expected exported fullName %q, got %v", "John Doe", v)
+	}
+
+	columns := m.ColumnValueMap()
+	for _, name := range []string{"fullName", "ratio"} {
+		if _, ok := columns[name]; ok {
+			t.Fatalf("Didn't expect column %q in %v", name, columns)
+		}
+	}
+}
+
+func TestRecordEncryptedFieldExport(t *testing.T) {
+	t.Parallel()
+
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "masked", Type: schema.FieldTypeEncrypted},
+			&schema.SchemaField{
+				Name:    "unmasked",
+				Type:    schema.FieldTypeEncrypted,
+				Options: &schema.EncryptedOptions{Unmasked: true},
+			},
+			&schema.SchemaField{Name: "empty", Type: schema.FieldTypeEncrypted},
+		),
+	}
+
+	m := models.NewRecord(collection)
+	m.Set("masked", "secret1")
+	m.Set("unmasked", "secret2")
+
+	// the application code always works with the plain values
+	if v := m.GetString("masked"); v != "secret1" {
+		t.Fatalf("Expected masked field value %q, got %q", "secret1", v)
+	}
+
+	export := m.PublicExport()
+
+	expected := map[string]any{
+		"unmasked": "secret2",
+		"empty":    "",
+	}
+
+	for k, v := range expected {
+		if export[k] != v {
+			t.Fatalf("Expected exported %q to be %v, got %v", k, v, export[k])
+		}
+	}
+
+	if v, ok := export["masked"]; ok {
+		t.Fatalf("Expected the masked field to be omitted, got %v", v)
+	}
+}
+
+func TestRecordHideAndUnhide(t *testing.T) {
+	t.Parallel()
+
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "secret", Type: schema.FieldTypeText},
+		),
+	}
+
+	m := models.NewRecord(collection)
+	m.Id = "test_id"
+	m.Set("title", "test_title")
+	m.Set("secret", "test_secret")
+
+	m.Hide("secret", "id", "missing")
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"collectionId":"","collectionName":"test","created":"","title":"test_title","updated":""}`
+	if string(raw) != expected {
+		t.Fatalf("Expected \n%s\ngot\n%s", expected, raw)
+	}
+
+	// the hidden field value shouldn't be changed
+	if v := m.GetString("secret"); v != "test_secret" {
+		t.Fatalf("Expected secret %q, got %q", "test_secret", v)
+	}
+
+	m.Unhide("id", "secret")
+
+	export := m.PublicExport()
+	if export["id"] != "test_id" || export["secret"] != "test_secret" {
+		t.Fatalf("Expected id and secret to be exported, got %v", export)
+	}
+}
@@ -22,7 +22,9 @@ type RequestInfo struct {
 	Headers    map[string]any `json:"headers"`
 	AuthRecord *Record        `json:"authRecord"`
 	Admin      *Admin         `json:"admin"`
+	ApiKey     *ApiKey        `json:"apiKey"`
 	Method     string         `json:"method"`
+	RemoteIp   string         `json:"remoteIp"`
 }
 
 // HasModifierDataKeys loosely checks if the current struct has any modifier Data keys.
@@ -0,0 +1,211 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	hexColorRegex       = regexp.MustCompile(`^#(?:[0-9a-f]{3}|[0-9a-f]{6}|[0-9a-f]{8})$`)
+	canonicalColorRegex = regexp.MustCompile(`^#[0-9a-f]{6}(?:[0-9a-f]{2})?$`)
+	colorFuncRegex      = regexp.MustCompile(`^(rgba?|hsla?)\((.*)\)$`)
+)
+
+// IsCanonicalColor checks whether the provided string is a canonical
+// lowercase `#rrggbb` or `#rrggbbaa` hex color.
+func IsCanonicalColor(color string) bool {
+	return canonicalColorRegex.MatchString(color)
+}
+
+// NormalizeColor converts the provided color string to its canonical
+// lowercase hex form (`#rrggbb` or `#rrggbbaa` for translucent colors).
+//
+// Hex colors in `#RGB`, `#RRGGBB` and `#RRGGBBAA` formats are always
+// accepted, while the CSS `rgb()`/`rgba()` and `hsl()`/`hsla()` functional
+// notations are accepted only if allowRgb or allowHsl is set.
+func NormalizeColor(color string, allowRgb bool, allowHsl bool) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+
+	if hexColorRegex.MatchString(color) {
+		return normalizeHexColor(color), nil
+	}
+
+	match := colorFuncRegex.FindStringSubmatch(color)
+	if match == nil {
+		return "", fmt.Errorf("invalid color %q", color)
+	}
+
+	args, alpha, err := splitColorFuncArgs(match[2])
+	if err != nil {
+		return "", err
+	}
+
+	var r, g, b float64
+
+	if strings.HasPrefix(match[1], "rgb") {
+		if !allowRgb {
+			return "", errors.New("rgb() colors are not allowed")
+		}
+
+		channels := [3]*float64{&r, &g, &b}
+		for i, arg := range args {
+			v, err := parseColorChannel(arg, 255)
+			if err != nil {
+				return "", err
+			}
+			*channels[i] = v
+		}
+	} else {
+		if !allowHsl {
+			return "", errors.New("hsl() colors are not allowed")
+		}
+
+		h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid hsl() hue %q", args[0])
+		}
+
+		s, err := parseColorPercent(args[1])
+		if err != nil {
+			return "", err
+		}
+
+		l, err := parseColorPercent(args[2])
+		if err != nil {
+			return "", err
+		}
+
+		r, g, b = hslToRgb(h, s, l)
+	}
+
+	a := 1.0
+	if alpha != "" {
+		if strings.HasSuffix(alpha, "%") {
+			a, err = parseColorPercent(alpha)
+		} else {
+			a, err = strconv.ParseFloat(alpha, 64)
+		}
+		if err != nil || a < 0 || a > 1 {
+			return "", fmt.Errorf("invalid color alpha %q", alpha)
+		}
+	}
+
+	return normalizeHexColor(fmt.Sprintf(
+		"#%02x%02x%02x%02x",
+		int(math.Round(r)),
+		int(math.Round(g)),
+		int(math.Round(b)),
+		int(math.Round(a*255)),
+	)), nil
+}
+
+// normalizeHexColor expands the short `#rgb` colors and strips
+// the alpha channel of the fully opaque `#rrggbbff` colors.
+func normalizeHexColor(color string) string {
+	switch len(color) {
+	case 4:
+		return string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	case 9:
+		if color[7:] == "ff" {
+			return color[:7]
+		}
+	}
+
+	return color
+}
+
+// splitColorFuncArgs splits the comma or space separated color function
+// arguments (eg. "255, 0, 0, 0.5" or "255 0 0 / 50%").
+//
+// Returns the 3 color components and the optional alpha value.
+func splitColorFuncArgs(raw string) ([]string, string, error) {
+	var args []string
+
+	if strings.Contains(raw, ",") {
+		args = strings.Split(raw, ",")
+		for i := range args {
+			args[i] = strings.TrimSpace(args[i])
+		}
+	} else {
+		parts := strings.SplitN(raw, "/", 2)
+		args = strings.Fields(parts[0])
+		if len(parts) == 2 {
+			args = append(args, strings.TrimSpace(parts[1]))
+		}
+	}
+
+	if len(args) != 3 && len(args) != 4 {
+		return nil, "", fmt.Errorf("invalid color arguments %q", raw)
+	}
+
+	if len(args) == 4 {
+		return args[:3], args[3], nil
+	}
+
+	return args, "", nil
+}
+
+// parseColorChannel parses a single rgb() channel value
+// (either a number in the [0, max] range or percentage).
+func parseColorChannel(raw string, max float64) (float64, error) {
+	if strings.HasSuffix(raw, "%") {
+		p, err := parseColorPercent(raw)
+		return p * max, err
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || v < 0 || v > max {
+		return 0, fmt.Errorf("invalid color channel %q", raw)
+	}
+
+	return v, nil
+}
+
+// parseColorPercent parses a percentage string (eg. "50%") into a [0, 1] fraction.
+func parseColorPercent(raw string) (float64, error) {
+	if !strings.HasSuffix(raw, "%") {
+		return 0, fmt.Errorf("invalid color percentage %q", raw)
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+	if err != nil || v < 0 || v > 100 {
+		return 0, fmt.Errorf("invalid color percentage %q", raw)
+	}
+
+	return v / 100, nil
+}
+
+// hslToRgb converts the provided hsl color (hue in degrees and
+// saturation and lightness as [0, 1] fractions) to rgb channels.
+func hslToRgb(h, s, l float64) (float64, float64, float64) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return (r + m) * 255, (g + m) * 255, (b + m) * 255
+}
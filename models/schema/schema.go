@@ -168,10 +168,56 @@ func (s Schema) Validate() error {
 			names = append(names, nameLower)
 		}
 
-		return nil
+		return s.checkComputedFieldsReferences()
 	}))
 }
 
+// checkComputedFieldsReferences checks whether the computed fields
+// expressions reference only existing non-computed sibling fields.
+//
+// References to other computed fields are not allowed to prevent cycles.
+func (s Schema) checkComputedFieldsReferences() error {
+	for i, field := range s.fields {
+		if field.Type != FieldTypeComputed {
+			continue
+		}
+
+		field.InitOptions()
+
+		options, _ := field.Options.(*ComputedOptions)
+		if options == nil {
+			continue // invalid options are reported by the field validator
+		}
+
+		program, err := options.Program()
+		if err != nil {
+			continue // invalid expressions are reported by the field validator
+		}
+
+		for _, name := range program.Identifiers() {
+			if list.ExistInSlice(name, BaseModelFieldNames()) {
+				continue
+			}
+
+			ref := s.GetFieldByName(name)
+			if ref == nil || ref.Type == FieldTypeComputed {
+				return validation.Errors{
+					strconv.Itoa(i): validation.Errors{
+						"options": validation.Errors{
+							"expression": validation.NewError(
+								"validation_invalid_computed_reference",
+								fmt.Sprintf("Unknown or unsupported field reference %q.", name),
+							),
+						},
+					},
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 // MarshalJSON implements the [json.Marshaler] interface.
 func (s Schema) MarshalJSON() ([]byte, error) {
 	if s.fields == nil {
@@ -9,8 +9,10 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/tools/expr"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
 )
@@ -36,6 +38,7 @@ const (
 	FieldNameId                     string = "id"
 	FieldNameCreated                string = "created"
 	FieldNameUpdated                string = "updated"
+	FieldNameDeleted                string = "deleted"
 	FieldNameCollectionId           string = "collectionId"
 	FieldNameCollectionName         string = "collectionName"
 	FieldNameExpand                 string = "expand"
@@ -85,17 +88,21 @@ func AuthFieldNames() []string {
 
 // All valid field types
 const (
-	FieldTypeText     string = "text"
-	FieldTypeNumber   string = "number"
-	FieldTypeBool     string = "bool"
-	FieldTypeEmail    string = "email"
-	FieldTypeUrl      string = "url"
-	FieldTypeEditor   string = "editor"
-	FieldTypeDate     string = "date"
-	FieldTypeSelect   string = "select"
-	FieldTypeJson     string = "json"
-	FieldTypeFile     string = "file"
-	FieldTypeRelation string = "relation"
+	FieldTypeText      string = "text"
+	FieldTypeNumber    string = "number"
+	FieldTypeBool      string = "bool"
+	FieldTypeEmail     string = "email"
+	FieldTypeUrl       string = "url"
+	FieldTypeEditor    string = "editor"
+	FieldTypeDate      string = "date"
+	FieldTypeSelect    string = "select"
+	FieldTypeJson      string = "json"
+	FieldTypeFile      string = "file"
+	FieldTypeRelation  string = "relation"
+	FieldTypeComputed  string = "computed"
+	FieldTypeEncrypted string = "encrypted"
+	FieldTypeDuration  string = "duration"
+	FieldTypeColor     string = "color"
 
 	// Deprecated: Will be removed in v0.9+
 	FieldTypeUser string = "user"
@@ -115,6 +122,10 @@ func FieldTypes() []string {
 		FieldTypeJson,
 		FieldTypeFile,
 		FieldTypeRelation,
+		FieldTypeComputed,
+		FieldTypeEncrypted,
+		FieldTypeDuration,
+		FieldTypeColor,
 	}
 }
 
@@ -127,6 +138,15 @@ func ArraybleFieldTypes() []string {
 	}
 }
 
+// NonSearchableFieldTypes returns slice with all field types
+// that cannot be used in filter and sort expressions.
+func NonSearchableFieldTypes() []string {
+	return []string{
+		FieldTypeComputed,
+		FieldTypeEncrypted,
+	}
+}
+
 // SchemaField defines a single schema field structure.
 type SchemaField struct {
 	System   bool   `form:"system" json:"system"`
@@ -139,6 +159,27 @@ type SchemaField struct {
 	// visualization purposes (eg. in the Admin UI relation views).
 	Presentable bool `form:"presentable" json:"presentable"`
 
+	// Validators is an optional list with the names of the app registered
+	// custom field validators to run during the record data validation
+	// (see "forms/validators.RegisterFieldValidator").
+	Validators []string `form:"validators" json:"validators,omitempty"`
+
+	// ViewRule is an optional field level API rule (with the same syntax
+	// as the collection API rules) that controls whether the field value
+	// is included in the record API responses.
+	//
+	// Leave it empty for no field level view restriction
+	// (admins can always see all fields).
+	ViewRule string `form:"viewRule" json:"viewRule,omitempty"`
+
+	// EditRule is an optional field level API rule (with the same syntax
+	// as the collection API rules) that controls whether the field value
+	// could be changed with the record create/update APIs.
+	//
+	// Leave it empty for no field level edit restriction
+	// (admins can always change all fields).
+	EditRule string `form:"editRule" json:"editRule,omitempty"`
+
 	// Deprecated: This field is no-op and will be removed in future versions.
 	// Please use the collection.Indexes field to define a unique constraint.
 	Unique bool `form:"unique" json:"unique"`
@@ -153,6 +194,8 @@ func (f *SchemaField) ColDefinition() string {
 		return "NUMERIC DEFAULT 0 NOT NULL"
 	case FieldTypeBool:
 		return "BOOLEAN DEFAULT FALSE NOT NULL"
+	case FieldTypeDuration:
+		return "INTEGER DEFAULT 0 NOT NULL"
 	case FieldTypeJson:
 		return "JSON DEFAULT NULL"
 	default:
@@ -217,9 +260,16 @@ func (f SchemaField) Validate() error {
 			validation.By(f.checkForVia),
 		),
 		validation.Field(&f.Type, validation.Required, validation.In(list.ToInterfaceSlice(FieldTypes())...)),
+		validation.Field(&f.Validators, validation.Each(validation.Required, validation.Length(1, 255))),
 		// currently file fields cannot be unique because a proper
 		// hash/content check could cause performance issues
 		validation.Field(&f.Unique, validation.When(f.Type == FieldTypeFile, validation.Empty)),
+		// computed fields are never stored and their value cannot be submitted
+		validation.Field(&f.Required, validation.When(f.Type == FieldTypeComputed, validation.Empty)),
+		validation.Field(&f.Unique, validation.When(f.Type == FieldTypeComputed, validation.Empty)),
+		validation.Field(&f.Validators, validation.When(f.Type == FieldTypeComputed, validation.Empty)),
+		// the encrypted values are stored with a random nonce and cannot be compared
+		validation.Field(&f.Unique, validation.When(f.Type == FieldTypeEncrypted, validation.Empty)),
 	)
 }
 
@@ -283,6 +333,14 @@ func (f *SchemaField) InitOptions() error {
 		options = &FileOptions{}
 	case FieldTypeRelation:
 		options = &RelationOptions{}
+	case FieldTypeComputed:
+		options = &ComputedOptions{}
+	case FieldTypeEncrypted:
+		options = &EncryptedOptions{}
+	case FieldTypeDuration:
+		options = &DurationOptions{}
+	case FieldTypeColor:
+		options = &ColorOptions{}
 
 	// Deprecated: Will be removed in v0.9+
 	case FieldTypeUser:
@@ -307,7 +365,7 @@ func (f *SchemaField) PrepareValue(value any) any {
 	f.InitOptions()
 
 	switch f.Type {
-	case FieldTypeText, FieldTypeEmail, FieldTypeUrl, FieldTypeEditor:
+	case FieldTypeText, FieldTypeEmail, FieldTypeUrl, FieldTypeEditor, FieldTypeEncrypted:
 		return cast.ToString(value)
 	case FieldTypeJson:
 		val := value
@@ -348,6 +406,18 @@ func (f *SchemaField) PrepareValue(value any) any {
 	case FieldTypeDate:
 		val, _ := types.ParseDateTime(value)
 		return val
+	case FieldTypeDuration:
+		val, _ := types.ParseDuration(value)
+		return val
+	case FieldTypeColor:
+		val := cast.ToString(value)
+
+		options, _ := f.Options.(*ColorOptions)
+		if normalized, err := NormalizeColor(val, options.AllowRgb, options.AllowHsl); err == nil {
+			return normalized
+		}
+
+		return val // leave the invalid colors as they are for the validators
 	case FieldTypeSelect:
 		val := list.ToUniqueStringSlice(value)
 
@@ -359,7 +429,7 @@ func (f *SchemaField) PrepareValue(value any) any {
 			return ""
 		}
 
-		return val
+		return options.sortValues(val)
 	case FieldTypeFile:
 		val := list.ToUniqueStringSlice(value)
 
@@ -402,6 +472,15 @@ func (f *SchemaField) PrepareValueWithModifier(baseValue any, modifier string, m
 		case FieldValueModifierSubtract:
 			resolvedValue = cast.ToFloat64(baseValue) - cast.ToFloat64(modifierValue)
 		}
+	case FieldTypeDuration:
+		base, _ := types.ParseDuration(baseValue)
+		mod, _ := types.ParseDuration(modifierValue)
+		switch modifier {
+		case FieldValueModifierAdd:
+			resolvedValue = base + mod
+		case FieldValueModifierSubtract:
+			resolvedValue = base - mod
+		}
 	case FieldTypeSelect, FieldTypeRelation:
 		switch modifier {
 		case FieldValueModifierAdd:
@@ -444,6 +523,16 @@ type TextOptions struct {
 	Min     *int   `form:"min" json:"min"`
 	Max     *int   `form:"max" json:"max"`
 	Pattern string `form:"pattern" json:"pattern"`
+
+	// AutogenerateLength specifies the length of the cryptographically random
+	// value that is generated for the empty field on record create (0 means disabled).
+	//
+	// The autogenerated value is guaranteed to be unique within the collection.
+	AutogenerateLength int `form:"autogenerateLength" json:"autogenerateLength"`
+
+	// AutogenerateAlphabet specifies the characters of the autogenerated value
+	// (if empty fallbacks to [A-Za-z0-9]).
+	AutogenerateAlphabet string `form:"autogenerateAlphabet" json:"autogenerateAlphabet"`
 }
 
 func (o TextOptions) Validate() error {
@@ -452,13 +541,52 @@ func (o TextOptions) Validate() error {
 		minVal = *o.Min
 	}
 
+	autogenerateRules := []validation.Rule{validation.Min(0)}
+	if o.AutogenerateLength > 0 {
+		autogenerateRules = append(autogenerateRules, validation.Min(minVal))
+		if o.Max != nil && *o.Max > 0 {
+			autogenerateRules = append(autogenerateRules, validation.Max(*o.Max))
+		}
+	}
+
 	return validation.ValidateStruct(&o,
 		validation.Field(&o.Min, validation.Min(0)),
 		validation.Field(&o.Max, validation.Min(minVal)),
 		validation.Field(&o.Pattern, validation.By(o.checkRegex)),
+		validation.Field(&o.AutogenerateLength, autogenerateRules...),
+		validation.Field(&o.AutogenerateAlphabet, validation.By(o.checkAlphabet)),
 	)
 }
 
+// IsAutogenerated reports whether the text field has enabled autogenerate value option.
+func (o *TextOptions) IsAutogenerated() bool {
+	return o.AutogenerateLength > 0
+}
+
+func (o *TextOptions) checkAlphabet(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if len(v) < 2 {
+		return validation.NewError("validation_invalid_alphabet", "The alphabet must have at least 2 characters.")
+	}
+
+	seen := make(map[rune]struct{}, len(v))
+	for _, ch := range v {
+		if ch <= ' ' || ch > '~' {
+			return validation.NewError("validation_invalid_alphabet", "The alphabet must contain only printable ASCII characters.")
+		}
+		if _, ok := seen[ch]; ok {
+			return validation.NewError("validation_invalid_alphabet", "The alphabet must not contain duplicated characters.")
+		}
+		seen[ch] = struct{}{}
+	}
+
+	return nil
+}
+
 func (o *TextOptions) checkRegex(value any) error {
 	v, _ := value.(string)
 	if v == "" {
@@ -600,8 +728,17 @@ func (o *DateOptions) checkRange(min types.DateTime, max types.DateTime) validat
 // -------------------------------------------------------------------
 
 type SelectOptions struct {
-	MaxSelect int      `form:"maxSelect" json:"maxSelect"`
-	Values    []string `form:"values" json:"values"`
+	// MinSelect indicates the min number of values that must be selected
+	// when the field is not empty (0 means no limit).
+	MinSelect int `form:"minSelect" json:"minSelect"`
+
+	// MaxSelect indicates the max number of values that could be selected.
+	MaxSelect int `form:"maxSelect" json:"maxSelect"`
+
+	// Values is the list of the allowed select values.
+	//
+	// The multiple select values are stored in the same order as declared here.
+	Values []string `form:"values" json:"values"`
 }
 
 func (o SelectOptions) Validate() error {
@@ -612,6 +749,11 @@ func (o SelectOptions) Validate() error {
 
 	return validation.ValidateStruct(&o,
 		validation.Field(&o.Values, validation.Required),
+		validation.Field(
+			&o.MinSelect,
+			validation.Min(0),
+			validation.Max(o.MaxSelect),
+		),
 		validation.Field(
 			&o.MaxSelect,
 			validation.Required,
@@ -627,10 +769,44 @@ func (o SelectOptions) IsMultiple() bool {
 	return o.MaxSelect > 1
 }
 
+// sortValues sorts the provided selected values in the options declaration order.
+//
+// Values that are not in the allowed options list are kept
+// at the end in their original order (so that they could be validated).
+func (o SelectOptions) sortValues(values []string) []string {
+	if len(values) < 2 {
+		return values
+	}
+
+	result := make([]string, 0, len(values))
+
+	for _, v := range o.Values {
+		if list.ExistInSlice(v, values) {
+			result = append(result, v)
+		}
+	}
+
+	for _, v := range values {
+		if !list.ExistInSlice(v, o.Values) {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
 // -------------------------------------------------------------------
 
 type JsonOptions struct {
 	MaxSize int `form:"maxSize" json:"maxSize"`
+
+	// PreserveStrings instructs the record upsert form to store the string
+	// values of a JSON request body (or multipart @jsonPayload) as json
+	// strings instead of normalizing them (eg. "null" is stored as the
+	// "null" string and not as json null).
+	//
+	// The plain multipart/form-data values are always normalized.
+	PreserveStrings bool `form:"preserveStrings" json:"preserveStrings"`
 }
 
 func (o JsonOptions) Validate() error {
@@ -670,6 +846,30 @@ func (o FileOptions) IsMultiple() bool {
 
 // -------------------------------------------------------------------
 
+// relation field OnDelete actions
+const (
+	// RelationOnDeleteCascade deletes the referencing record
+	// (if there are no other linked relations in case of multiple relation).
+	RelationOnDeleteCascade string = "cascade"
+
+	// RelationOnDeleteSetNull unsets the deleted relation id
+	// from the referencing record field value.
+	RelationOnDeleteSetNull string = "setNull"
+
+	// RelationOnDeleteRestrict prevents the deletion of the
+	// related record while it is still referenced.
+	RelationOnDeleteRestrict string = "restrict"
+)
+
+// RelationOnDeleteActions returns a list with all available relation OnDelete actions.
+func RelationOnDeleteActions() []string {
+	return []string{
+		RelationOnDeleteCascade,
+		RelationOnDeleteSetNull,
+		RelationOnDeleteRestrict,
+	}
+}
+
 var _ MultiValuer = (*RelationOptions)(nil)
 
 type RelationOptions struct {
@@ -678,8 +878,17 @@ type RelationOptions struct {
 
 	// CascadeDelete indicates whether the root model should be deleted
 	// in case of delete of all linked relations.
+	//
+	// It is used only when OnDelete is not set.
 	CascadeDelete bool `form:"cascadeDelete" json:"cascadeDelete"`
 
+	// OnDelete specifies the action to perform with the referencing
+	// record when the related record is deleted
+	// (see [RelationOnDeleteActions()] for the available values).
+	//
+	// If empty, fallbacks to "cascade" or "setNull" based on the CascadeDelete option.
+	OnDelete string `form:"onDelete" json:"onDelete"`
+
 	// MinSelect indicates the min number of allowed relation records
 	// that could be linked to the main model.
 	//
@@ -705,6 +914,7 @@ func (o RelationOptions) Validate() error {
 
 	return validation.ValidateStruct(&o,
 		validation.Field(&o.CollectionId, validation.Required),
+		validation.Field(&o.OnDelete, validation.In(list.ToInterfaceSlice(RelationOnDeleteActions())...)),
 		validation.Field(&o.MinSelect, validation.Min(0)),
 		validation.Field(&o.MaxSelect, validation.NilOrNotEmpty, validation.Min(minVal)),
 	)
@@ -716,6 +926,137 @@ func (o RelationOptions) IsMultiple() bool {
 	return o.MaxSelect == nil || *o.MaxSelect > 1
 }
 
+// OnDeleteAction returns the resolved OnDelete action of the relation field
+// (fallbacks to the legacy CascadeDelete option if OnDelete is not set).
+func (o RelationOptions) OnDeleteAction() string {
+	if o.OnDelete != "" {
+		return o.OnDelete
+	}
+
+	if o.CascadeDelete {
+		return RelationOnDeleteCascade
+	}
+
+	return RelationOnDeleteSetNull
+}
+
+// -------------------------------------------------------------------
+
+// computedProgramsCache stores the parsed computed fields expressions.
+var computedProgramsCache = store.New[*expr.Program](nil)
+
+type ComputedOptions struct {
+	// Expression is the computed field value expression that could
+	// reference the other non-computed sibling fields
+	// (eg. `firstName + " " + lastName`).
+	//
+	// See [expr] for the supported grammar and functions.
+	Expression string `form:"expression" json:"expression"`
+}
+
+func (o ComputedOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Expression, validation.Required, validation.By(o.checkExpression)),
+	)
+}
+
+func (o *ComputedOptions) checkExpression(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := o.Program(); err != nil {
+		return validation.NewError("validation_invalid_expression", "Invalid expression - "+err.Error())
+	}
+
+	return nil
+}
+
+// Program returns the parsed (and cached) computed field expression.
+func (o ComputedOptions) Program() (*expr.Program, error) {
+	if program := computedProgramsCache.Get(o.Expression); program != nil {
+		return program, nil
+	}
+
+	program, err := expr.Parse(o.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	// the expressions are part of the collection schema so their number
+	// is usually small but nevertheless the cache is limited as a precaution
+	if !computedProgramsCache.SetIfLessThanLimit(o.Expression, program, 1000) {
+		computedProgramsCache.RemoveAll()
+	}
+
+	return program, nil
+}
+
+// -------------------------------------------------------------------
+
+type EncryptedOptions struct {
+	Min *int `form:"min" json:"min"`
+	Max *int `form:"max" json:"max"`
+
+	// Unmasked indicates whether to return the decrypted field value
+	// in the public record export (by default the non-empty values are omitted).
+	Unmasked bool `form:"unmasked" json:"unmasked"`
+}
+
+func (o EncryptedOptions) Validate() error {
+	minVal := 0
+	if o.Min != nil {
+		minVal = *o.Min
+	}
+
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Min, validation.Min(0)),
+		validation.Field(&o.Max, validation.Min(minVal)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type DurationOptions struct {
+	Min *types.Duration `form:"min" json:"min"`
+	Max *types.Duration `form:"max" json:"max"`
+}
+
+func (o DurationOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Max, validation.By(o.checkRange)),
+	)
+}
+
+func (o *DurationOptions) checkRange(value any) error {
+	v, _ := value.(*types.Duration)
+
+	if v == nil || o.Min == nil {
+		return nil // nothing to check
+	}
+
+	if *v < *o.Min {
+		return validation.NewError("validation_invalid_duration_range", "The max duration must be greater or equal to the min duration.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type ColorOptions struct {
+	// AllowRgb indicates whether to accept also the CSS rgb() and rgba() color values.
+	AllowRgb bool `form:"allowRgb" json:"allowRgb"`
+
+	// AllowHsl indicates whether to accept also the CSS hsl() and hsla() color values.
+	AllowHsl bool `form:"allowHsl" json:"allowHsl"`
+}
+
+func (o ColorOptions) Validate() error {
+	return nil
+}
+
 // -------------------------------------------------------------------
 
 // Deprecated: Will be removed in v0.9+
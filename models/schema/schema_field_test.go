@@ -40,7 +40,7 @@ func TestAuthFieldNames(t *testing.T) {
 
 func TestFieldTypes(t *testing.T) {
 	result := schema.FieldTypes()
-	expected := 11
+	expected := 15
 
 	if len(result) != expected {
 		t.Fatalf("Expected %d types, got %d (%v)", expected, len(result), result)
@@ -56,6 +56,15 @@ func TestArraybleFieldTypes(t *testing.T) {
 	}
 }
 
+func TestNonSearchableFieldTypes(t *testing.T) {
+	result := schema.NonSearchableFieldTypes()
+	expected := 2
+
+	if len(result) != expected {
+		t.Fatalf("Expected %d non-searchable types, got %d (%v)", expected, len(result), result)
+	}
+}
+
 func TestSchemaFieldColDefinition(t *testing.T) {
 	scenarios := []struct {
 		field    schema.SchemaField
@@ -89,6 +98,14 @@ func TestSchemaFieldColDefinition(t *testing.T) {
 			schema.SchemaField{Type: schema.FieldTypeDate, Name: "test"},
 			"TEXT DEFAULT '' NOT NULL",
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeDuration, Name: "test"},
+			"INTEGER DEFAULT 0 NOT NULL",
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeColor, Name: "test"},
+			"TEXT DEFAULT '' NOT NULL",
+		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeJson, Name: "test"},
 			"JSON DEFAULT NULL",
@@ -141,7 +158,7 @@ func TestSchemaFieldString(t *testing.T) {
 	}
 
 	result := f.String()
-	expected := `{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`
+	expected := `{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}`
 
 	if result != expected {
 		t.Errorf("Expected \n%v, got \n%v", expected, result)
@@ -168,7 +185,7 @@ func TestSchemaFieldMarshalJSON(t *testing.T) {
 				Presentable: true,
 				System:      true,
 			},
-			`{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":""}}`,
+			`{"system":true,"id":"abc","name":"test","type":"text","required":true,"presentable":true,"unique":false,"options":{"min":null,"max":null,"pattern":"","autogenerateLength":0,"autogenerateAlphabet":""}}`,
 		},
 		// with defined options
 		{
@@ -182,7 +199,7 @@ func TestSchemaFieldMarshalJSON(t *testing.T) {
 					Pattern: "test",
 				},
 			},
-			`{"system":true,"id":"","name":"test","type":"text","required":true,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`,
+			`{"system":true,"id":"","name":"test","type":"text","required":true,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}`,
 		},
 	}
 
@@ -227,12 +244,12 @@ func TestSchemaFieldUnmarshalJSON(t *testing.T) {
 		{
 			[]byte(`{"type":"text","system":true}`),
 			false,
-			`{"system":true,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}}`,
+			`{"system":true,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","autogenerateLength":0,"autogenerateAlphabet":""}}`,
 		},
 		{
-			[]byte(`{"type":"text","options":{"pattern":"test"}}`),
+			[]byte(`{"type":"text","options":{"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}`),
 			false,
-			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`,
+			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}`,
 		},
 	}
 
@@ -489,7 +506,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 		{
 			schema.SchemaField{Type: schema.FieldTypeText},
 			false,
-			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}}`,
+			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","autogenerateLength":0,"autogenerateAlphabet":""}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeNumber},
@@ -521,15 +538,25 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 			false,
 			`{"system":false,"id":"","name":"","type":"date","required":false,"presentable":false,"unique":false,"options":{"min":"","max":""}}`,
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeDuration},
+			false,
+			`{"system":false,"id":"","name":"","type":"duration","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null}}`,
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeColor},
+			false,
+			`{"system":false,"id":"","name":"","type":"color","required":false,"presentable":false,"unique":false,"options":{"allowRgb":false,"allowHsl":false}}`,
+		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeSelect},
 			false,
-			`{"system":false,"id":"","name":"","type":"select","required":false,"presentable":false,"unique":false,"options":{"maxSelect":0,"values":null}}`,
+			`{"system":false,"id":"","name":"","type":"select","required":false,"presentable":false,"unique":false,"options":{"minSelect":0,"maxSelect":0,"values":null}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeJson},
 			false,
-			`{"system":false,"id":"","name":"","type":"json","required":false,"presentable":false,"unique":false,"options":{"maxSize":0}}`,
+			`{"system":false,"id":"","name":"","type":"json","required":false,"presentable":false,"unique":false,"options":{"maxSize":0,"preserveStrings":false}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeFile},
@@ -539,7 +566,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 		{
 			schema.SchemaField{Type: schema.FieldTypeRelation},
 			false,
-			`{"system":false,"id":"","name":"","type":"relation","required":false,"presentable":false,"unique":false,"options":{"collectionId":"","cascadeDelete":false,"minSelect":null,"maxSelect":null,"displayFields":null}}`,
+			`{"system":false,"id":"","name":"","type":"relation","required":false,"presentable":false,"unique":false,"options":{"collectionId":"","cascadeDelete":false,"onDelete":"","minSelect":null,"maxSelect":null,"displayFields":null}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeUser},
@@ -552,7 +579,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 				Options: &schema.TextOptions{Pattern: "test"},
 			},
 			false,
-			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}`,
+			`{"system":false,"id":"","name":"","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}`,
 		},
 	}
 
@@ -667,9 +694,46 @@ func TestSchemaFieldPrepareValue(t *testing.T) {
 		{schema.SchemaField{Type: schema.FieldTypeDate}, 1641024040, `"2022-01-01 08:00:40.000Z"`},
 		{schema.SchemaField{Type: schema.FieldTypeDate}, "2022-01-01 11:27:10.123", `"2022-01-01 11:27:10.123Z"`},
 		{schema.SchemaField{Type: schema.FieldTypeDate}, "2022-01-01 11:27:10.123Z", `"2022-01-01 11:27:10.123Z"`},
+		{schema.SchemaField{Type: schema.FieldTypeDate}, "2022-01-01T11:27:10.123+02:00", `"2022-01-01 09:27:10.123Z"`},
 		{schema.SchemaField{Type: schema.FieldTypeDate}, types.DateTime{}, `""`},
 		{schema.SchemaField{Type: schema.FieldTypeDate}, time.Time{}, `""`},
 
+		// duration
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, nil, `"0s"`},
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, "", `"0s"`},
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, "test", `"0s"`},
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, 5400000, `"1h30m0s"`},
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, "5400000", `"1h30m0s"`},
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, "1h30m", `"1h30m0s"`},
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, "PT1H30M", `"1h30m0s"`},
+		{schema.SchemaField{Type: schema.FieldTypeDuration}, time.Second + time.Microsecond, `"1s"`},
+
+		// color
+		{schema.SchemaField{Type: schema.FieldTypeColor}, nil, `""`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, "", `""`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, "test", `"test"`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, "#ABC", `"#aabbcc"`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, " #FF00aa ", `"#ff00aa"`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, "#FF00AA80", `"#ff00aa80"`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, "#ff00aaFF", `"#ff00aa"`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, "rgb(255, 0, 0)", `"rgb(255, 0, 0)"`},
+		{schema.SchemaField{Type: schema.FieldTypeColor}, "hsl(0, 100%, 50%)", `"hsl(0, 100%, 50%)"`},
+		{
+			schema.SchemaField{Type: schema.FieldTypeColor, Options: &schema.ColorOptions{AllowRgb: true}},
+			"RGB(255, 128, 0)",
+			`"#ff8000"`,
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeColor, Options: &schema.ColorOptions{AllowRgb: true}},
+			"hsl(0, 100%, 50%)",
+			`"hsl(0, 100%, 50%)"`,
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeColor, Options: &schema.ColorOptions{AllowHsl: true}},
+			"hsl(120deg 100% 25%)",
+			`"#008000"`,
+		},
+
 		// select (single)
 		{schema.SchemaField{Type: schema.FieldTypeSelect}, nil, `""`},
 		{schema.SchemaField{Type: schema.FieldTypeSelect}, "", `""`},
@@ -746,6 +810,18 @@ func TestSchemaFieldPrepareValue(t *testing.T) {
 			[]string{"test1", "test2", "test1"},
 			`["test1","test2"]`,
 		},
+		{
+			// normalized to the declared options order (unknown values at the end)
+			schema.SchemaField{
+				Type: schema.FieldTypeSelect,
+				Options: &schema.SelectOptions{
+					MaxSelect: 3,
+					Values:    []string{"c", "a", "b"},
+				},
+			},
+			[]string{"x", "b", "a", "y", "c"},
+			`["c","a","b","x","y"]`,
+		},
 
 		// file (single)
 		{schema.SchemaField{Type: schema.FieldTypeFile}, nil, `""`},
@@ -1182,6 +1258,32 @@ func TestSchemaFieldPrepareValueWithModifier(t *testing.T) {
 			`"2022-12-31 22:00:00.000Z"`,
 		},
 
+		// duration
+		{
+			"duration with '+' modifier",
+			schema.SchemaField{Type: schema.FieldTypeDuration},
+			"1h",
+			"+",
+			"PT30M",
+			`"1h30m0s"`,
+		},
+		{
+			"duration with '-' modifier",
+			schema.SchemaField{Type: schema.FieldTypeDuration},
+			3600000,
+			"-",
+			"90m",
+			`"-30m0s"`,
+		},
+		{
+			"duration with unknown modifier",
+			schema.SchemaField{Type: schema.FieldTypeDuration},
+			"1h",
+			"?",
+			"1h",
+			`"1h0m0s"`,
+		},
+
 		// json
 		{
 			"json with '+' modifier",
@@ -1753,6 +1855,51 @@ func TestTextOptionsValidate(t *testing.T) {
 			schema.TextOptions{Pattern: `^\#?\w+$`},
 			[]string{},
 		},
+		{
+			"autogenerateLength - negative",
+			schema.TextOptions{AutogenerateLength: minus},
+			[]string{"autogenerateLength"},
+		},
+		{
+			"autogenerateLength - less than min",
+			schema.TextOptions{Min: &number2, AutogenerateLength: number1},
+			[]string{"autogenerateLength"},
+		},
+		{
+			"autogenerateLength - greater than max",
+			schema.TextOptions{Max: &number1, AutogenerateLength: number2},
+			[]string{"autogenerateLength"},
+		},
+		{
+			"autogenerateLength - success",
+			schema.TextOptions{Min: &number1, Max: &number2, AutogenerateLength: number1},
+			[]string{},
+		},
+		{
+			"autogenerateAlphabet - single character",
+			schema.TextOptions{AutogenerateLength: number1, AutogenerateAlphabet: "a"},
+			[]string{"autogenerateAlphabet"},
+		},
+		{
+			"autogenerateAlphabet - non-printable ascii characters",
+			schema.TextOptions{AutogenerateLength: number1, AutogenerateAlphabet: "ab c"},
+			[]string{"autogenerateAlphabet"},
+		},
+		{
+			"autogenerateAlphabet - non-ascii characters",
+			schema.TextOptions{AutogenerateLength: number1, AutogenerateAlphabet: "abц"},
+			[]string{"autogenerateAlphabet"},
+		},
+		{
+			"autogenerateAlphabet - duplicated characters",
+			schema.TextOptions{AutogenerateLength: number1, AutogenerateAlphabet: "abca"},
+			[]string{"autogenerateAlphabet"},
+		},
+		{
+			"autogenerateAlphabet - success",
+			schema.TextOptions{AutogenerateLength: number1, AutogenerateAlphabet: "ABCDEF0123456789"},
+			[]string{},
+		},
 	}
 
 	checkFieldOptionsScenarios(t, scenarios)
@@ -1991,7 +2138,7 @@ func TestDateOptionsValidate(t *testing.T) {
 				Min: date1,
 				Max: date1,
 			},
-			[]string{"max"},
+			[]string{},
 		},
 		{
 			"min < max",
@@ -2037,6 +2184,33 @@ func TestSelectOptionsValidate(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"MinSelect < 0",
+			schema.SelectOptions{
+				Values:    []string{"test1", "test2"},
+				MinSelect: -1,
+				MaxSelect: 2,
+			},
+			[]string{"minSelect"},
+		},
+		{
+			"MinSelect > MaxSelect",
+			schema.SelectOptions{
+				Values:    []string{"test1", "test2", "test3"},
+				MinSelect: 3,
+				MaxSelect: 2,
+			},
+			[]string{"minSelect"},
+		},
+		{
+			"MinSelect == MaxSelect",
+			schema.SelectOptions{
+				Values:    []string{"test1", "test2", "test3"},
+				MinSelect: 2,
+				MaxSelect: 2,
+			},
+			[]string{},
+		},
 	}
 
 	checkFieldOptionsScenarios(t, scenarios)
@@ -2086,6 +2260,165 @@ func TestJsonOptionsValidate(t *testing.T) {
 	checkFieldOptionsScenarios(t, scenarios)
 }
 
+func TestComputedOptionsValidate(t *testing.T) {
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.ComputedOptions{},
+			[]string{"expression"},
+		},
+		{
+			"invalid expression",
+			schema.ComputedOptions{Expression: "a + (b"},
+			[]string{"expression"},
+		},
+		{
+			"unknown function",
+			schema.ComputedOptions{Expression: "exec(a)"},
+			[]string{"expression"},
+		},
+		{
+			"relation path reference",
+			schema.ComputedOptions{Expression: "rel.password"},
+			[]string{"expression"},
+		},
+		{
+			"valid expression",
+			schema.ComputedOptions{Expression: `upper(firstName) + " " + lastName`},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
+func TestEncryptedOptionsValidate(t *testing.T) {
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.EncryptedOptions{},
+			[]string{},
+		},
+		{
+			"min - negative",
+			schema.EncryptedOptions{Min: types.Pointer(-1)},
+			[]string{"min"},
+		},
+		{
+			"max < min",
+			schema.EncryptedOptions{Min: types.Pointer(10), Max: types.Pointer(9)},
+			[]string{"max"},
+		},
+		{
+			"max >= min",
+			schema.EncryptedOptions{Min: types.Pointer(10), Max: types.Pointer(10), Unmasked: true},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
+func TestDurationOptionsValidate(t *testing.T) {
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.DurationOptions{},
+			[]string{},
+		},
+		{
+			"only min",
+			schema.DurationOptions{Min: types.Pointer(types.Duration(time.Hour))},
+			[]string{},
+		},
+		{
+			"only max",
+			schema.DurationOptions{Max: types.Pointer(types.Duration(time.Hour))},
+			[]string{},
+		},
+		{
+			"max < min",
+			schema.DurationOptions{
+				Min: types.Pointer(types.Duration(time.Hour)),
+				Max: types.Pointer(types.Duration(time.Minute)),
+			},
+			[]string{"max"},
+		},
+		{
+			"max >= min",
+			schema.DurationOptions{
+				Min: types.Pointer(types.Duration(time.Hour)),
+				Max: types.Pointer(types.Duration(time.Hour)),
+			},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
+func TestNormalizeColor(t *testing.T) {
+	scenarios := []struct {
+		color       string
+		allowRgb    bool
+		allowHsl    bool
+		expected    string
+		expectError bool
+	}{
+		// hex
+		{"", true, true, "", true},
+		{"invalid", true, true, "", true},
+		{"#ab", true, true, "", true},
+		{"#abcd", true, true, "", true},
+		{"#abcdeg", true, true, "", true},
+		{"ff0000", true, true, "", true},
+		{"#F0a", false, false, "#ff00aa", false},
+		{"#FF00AA", false, false, "#ff00aa", false},
+		{"#ff00aa00", false, false, "#ff00aa00", false},
+		{"#FF00AAff", false, false, "#ff00aa", false},
+
+		// rgb
+		{"rgb(255, 0, 0)", false, true, "", true},
+		{"rgb(256, 0, 0)", true, false, "", true},
+		{"rgb(255, 0)", true, false, "", true},
+		{"rgb(255, 0, 0, 2)", true, false, "", true},
+		{"rgb(255, 0, 0)", true, false, "#ff0000", false},
+		{"rgb(255 128 0)", true, false, "#ff8000", false},
+		{"rgb(100%, 50%, 0%)", true, false, "#ff8000", false},
+		{"rgba(0, 0, 255, 0.5)", true, false, "#0000ff80", false},
+		{"rgb(0 0 255 / 50%)", true, false, "#0000ff80", false},
+		{"rgba(0, 0, 255, 1)", true, false, "#0000ff", false},
+
+		// hsl
+		{"hsl(0, 100%, 50%)", true, false, "", true},
+		{"hsl(0, 100, 50)", false, true, "", true},
+		{"hsl(0, 100%, 50%)", false, true, "#ff0000", false},
+		{"hsl(120deg 100% 25%)", false, true, "#008000", false},
+		{"hsl(-120, 100%, 50%)", false, true, "#0000ff", false},
+		{"hsla(240, 100%, 50%, 0.5)", false, true, "#0000ff80", false},
+		{"hsl(0 0% 100% / 25%)", false, true, "#ffffff40", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.color, func(t *testing.T) {
+			result, err := schema.NormalizeColor(s.color, s.allowRgb, s.allowHsl)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+
+			if !hasErr && !schema.IsCanonicalColor(result) {
+				t.Fatalf("Expected %q to be a canonical color", result)
+			}
+		})
+	}
+}
+
 func TestFileOptionsValidate(t *testing.T) {
 	scenarios := []fieldOptionsScenario{
 		{
@@ -2205,6 +2538,22 @@ func TestRelationOptionsValidate(t *testing.T) {
 			},
 			[]string{"maxSelect"},
 		},
+		{
+			"invalid OnDelete",
+			schema.RelationOptions{
+				CollectionId: "abc",
+				OnDelete:     "invalid",
+			},
+			[]string{"onDelete"},
+		},
+		{
+			"valid OnDelete",
+			schema.RelationOptions{
+				CollectionId: "abc",
+				OnDelete:     schema.RelationOnDeleteRestrict,
+			},
+			[]string{},
+		},
 		{
 			"MaxSelect > 0 && nonempty CollectionId",
 			schema.RelationOptions{
@@ -2254,6 +2603,31 @@ func TestRelationOptionsValidate(t *testing.T) {
 	checkFieldOptionsScenarios(t, scenarios)
 }
 
+func TestRelationOptionsOnDeleteAction(t *testing.T) {
+	scenarios := []struct {
+		onDelete      string
+		cascadeDelete bool
+		expected      string
+	}{
+		{"", false, schema.RelationOnDeleteSetNull},
+		{"", true, schema.RelationOnDeleteCascade},
+		{schema.RelationOnDeleteRestrict, true, schema.RelationOnDeleteRestrict},
+		{schema.RelationOnDeleteSetNull, true, schema.RelationOnDeleteSetNull},
+		{schema.RelationOnDeleteCascade, false, schema.RelationOnDeleteCascade},
+	}
+
+	for i, s := range scenarios {
+		opt := schema.RelationOptions{
+			OnDelete:      s.onDelete,
+			CascadeDelete: s.cascadeDelete,
+		}
+
+		if v := opt.OnDeleteAction(); v != s.expected {
+			t.Errorf("[%d] Expected %q, got %q", i, s.expected, v)
+		}
+	}
+}
+
 func TestRelationOptionsIsMultiple(t *testing.T) {
 	scenarios := []struct {
 		maxSelect *int
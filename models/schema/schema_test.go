@@ -258,6 +258,46 @@ func TestSchemaValidate(t *testing.T) {
 			),
 			false,
 		},
+		// failure - computed field with unknown field reference
+		{
+			schema.NewSchema(
+				&schema.SchemaField{Name: "a", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "test", Type: schema.FieldTypeComputed, Options: &schema.ComputedOptions{Expression: "a + missing"}},
+			),
+			true,
+		},
+		// failure - computed field with auth system field reference
+		{
+			schema.NewSchema(
+				&schema.SchemaField{Name: "test", Type: schema.FieldTypeComputed, Options: &schema.ComputedOptions{Expression: "passwordHash"}},
+			),
+			true,
+		},
+		// failure - computed field with another computed field reference
+		{
+			schema.NewSchema(
+				&schema.SchemaField{Name: "a", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "test1", Type: schema.FieldTypeComputed, Options: &schema.ComputedOptions{Expression: "a"}},
+				&schema.SchemaField{Name: "test2", Type: schema.FieldTypeComputed, Options: &schema.ComputedOptions{Expression: "test1"}},
+			),
+			true,
+		},
+		// failure - required computed field
+		{
+			schema.NewSchema(
+				&schema.SchemaField{Name: "a", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "test", Type: schema.FieldTypeComputed, Required: true, Options: &schema.ComputedOptions{Expression: "a"}},
+			),
+			true,
+		},
+		// success - computed field with sibling and base fields references
+		{
+			schema.NewSchema(
+				&schema.SchemaField{Name: "a", Type: schema.FieldTypeText},
+				&schema.SchemaField{Name: "test", Type: schema.FieldTypeComputed, Options: &schema.ComputedOptions{Expression: "a + id + created"}},
+			),
+			false,
+		},
 	}
 
 	for i, s := range scenarios {
@@ -286,7 +326,7 @@ func TestSchemaMarshalJSON(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expected := `[{"system":false,"id":"f1id","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}},{"system":false,"id":"f2id","name":"test2","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}]`
+	expected := `[{"system":false,"id":"f1id","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","autogenerateLength":0,"autogenerateAlphabet":""}},{"system":false,"id":"f2id","name":"test2","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}]`
 
 	if string(result) != expected {
 		t.Fatalf("Expected %s, got %s", expected, string(result))
@@ -294,7 +334,7 @@ func TestSchemaMarshalJSON(t *testing.T) {
 }
 
 func TestSchemaUnmarshalJSON(t *testing.T) {
-	encoded := `[{"system":false,"id":"fid1", "name":"test1","type":"text","required":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}},{"system":false,"name":"test2","type":"text","required":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}]`
+	encoded := `[{"system":false,"id":"fid1", "name":"test1","type":"text","required":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","autogenerateLength":0,"autogenerateAlphabet":""}},{"system":false,"name":"test2","type":"text","required":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}]`
 	testSchema := schema.Schema{}
 	testSchema.AddField(&schema.SchemaField{Name: "tempField", Type: schema.FieldTypeUrl})
 	err := testSchema.UnmarshalJSON([]byte(encoded))
@@ -354,7 +394,7 @@ func TestSchemaValue(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	expected := `[{"system":false,"id":"f1id","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}}]`
+	expected := `[{"system":false,"id":"f1id","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","autogenerateLength":0,"autogenerateAlphabet":""}}]`
 
 	if v2 != expected {
 		t.Fatalf("Expected %v, got %v", expected, v2)
@@ -385,13 +425,13 @@ func TestSchemaScan(t *testing.T) {
 		{
 			`[{"system":false,"id":"123","name":"test1","type":"text","required":false,"presentable":false,"unique":false}]`,
 			false,
-			`[{"system":false,"id":"123","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":""}}]`,
+			`[{"system":false,"id":"123","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"","autogenerateLength":0,"autogenerateAlphabet":""}}]`,
 		},
 		// with options
 		{
-			`[{"system":false,"id":"123","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}]`,
+			`[{"system":false,"id":"123","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}]`,
 			false,
-			`[{"system":false,"id":"123","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test"}}]`,
+			`[{"system":false,"id":"123","name":"test1","type":"text","required":false,"presentable":false,"unique":false,"options":{"min":null,"max":null,"pattern":"test","autogenerateLength":0,"autogenerateAlphabet":""}}]`,
 		},
 	}
 
@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
@@ -11,6 +16,7 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/tools/auth"
 	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/security"
@@ -20,6 +26,23 @@ import (
 // (see Settings.RedactClone()).
 const SecretMask string = "******"
 
+var smtpTransportNameRegex = regexp.MustCompile(`^[\w\-]+$`)
+
+var dkimSelectorRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-\.]*$`)
+
+var storageSecretOptionRegex = regexp.MustCompile(`(?i)(secret|password|token|key|credential)`)
+
+// corsOriginRegex matches "*" or a scheme://host[:port] origin
+// with optional "*." wildcard subdomain prefix of the host.
+var corsOriginRegex = regexp.MustCompile(`^(\*|[a-zA-Z][a-zA-Z0-9\+\-\.]*://(\*\.)?[^\s/\*]+)$`)
+
+var corsHeaderRegex = regexp.MustCompile(`^(\*|[\w\-]+)$`)
+
+var headerNameRegex = regexp.MustCompile(`^[\w\-]+$`)
+
+// MaxExpandDepthLimit is the max value of the configurable MetaConfig.MaxExpandDepth.
+const MaxExpandDepthLimit = 20
+
 // Settings defines common app configuration options.
 type Settings struct {
 	mux sync.RWMutex
@@ -27,17 +50,48 @@ type Settings struct {
 	Meta    MetaConfig    `form:"meta" json:"meta"`
 	Logs    LogsConfig    `form:"logs" json:"logs"`
 	Smtp    SmtpConfig    `form:"smtp" json:"smtp"`
+	Imap    ImapConfig    `form:"imap" json:"imap"`
 	S3      S3Config      `form:"s3" json:"s3"`
 	Backups BackupsConfig `form:"backups" json:"backups"`
 
+	AuditLogs AuditLogsConfig `form:"auditLogs" json:"auditLogs"`
+
+	// Passkeys holds the optional WebAuthn relying party overrides
+	// used for the passkey auth of the auth collection records.
+	Passkeys PasskeysConfig `form:"passkeys" json:"passkeys"`
+
+	// Cors holds the optional granular CORS configuration
+	// (when disabled the serve command allowed origins are used).
+	Cors CorsConfig `form:"cors" json:"cors"`
+
+	// TrustedProxy holds the optional reverse proxy configuration
+	// used to resolve the real client IP (see [TrustedProxyConfig.ClientIp]).
+	TrustedProxy TrustedProxyConfig `form:"trustedProxy" json:"trustedProxy"`
+
+	// SmtpTransports holds optional additional named SMTP configurations
+	// that could be selected per message (see [mailer.Transports]).
+	//
+	// The Smtp config above remains the default transport.
+	SmtpTransports map[string]SmtpConfig `form:"smtpTransports" json:"smtpTransports"`
+
+	// AzureBlob is an optional Azure Blob Storage config
+	// (it is ignored if the S3 storage is also enabled).
+	AzureBlob AzureBlobConfig `form:"azureBlob" json:"azureBlob"`
+
+	// Storage is an optional registered filesystem backend config
+	// (if set, it takes precedence over the S3 and AzureBlob configs).
+	Storage StorageConfig `form:"storage" json:"storage"`
+
 	AdminAuthToken           TokenConfig `form:"adminAuthToken" json:"adminAuthToken"`
 	AdminPasswordResetToken  TokenConfig `form:"adminPasswordResetToken" json:"adminPasswordResetToken"`
 	AdminFileToken           TokenConfig `form:"adminFileToken" json:"adminFileToken"`
 	RecordAuthToken          TokenConfig `form:"recordAuthToken" json:"recordAuthToken"`
+	RecordRefreshToken       TokenConfig `form:"recordRefreshToken" json:"recordRefreshToken"`
 	RecordPasswordResetToken TokenConfig `form:"recordPasswordResetToken" json:"recordPasswordResetToken"`
 	RecordEmailChangeToken   TokenConfig `form:"recordEmailChangeToken" json:"recordEmailChangeToken"`
 	RecordVerificationToken  TokenConfig `form:"recordVerificationToken" json:"recordVerificationToken"`
 	RecordFileToken          TokenConfig `form:"recordFileToken" json:"recordFileToken"`
+	RecordShareToken         TokenConfig `form:"recordShareToken" json:"recordShareToken"`
 
 	// Deprecated: Will be removed in v0.9+
 	EmailAuth EmailAuthConfig `form:"emailAuth" json:"emailAuth"`
@@ -67,6 +121,12 @@ type Settings struct {
 	MailcowAuth        AuthProviderConfig `form:"mailcowAuth" json:"mailcowAuth"`
 	BitbucketAuth      AuthProviderConfig `form:"bitbucketAuth" json:"bitbucketAuth"`
 	PlanningcenterAuth AuthProviderConfig `form:"planningcenterAuth" json:"planningcenterAuth"`
+	ZohoAuth           AuthProviderConfig `form:"zohoAuth" json:"zohoAuth"`
+	SlackAuth          AuthProviderConfig `form:"slackAuth" json:"slackAuth"`
+
+	// CustomAuth holds the configurations of the OAuth2 providers
+	// registered at runtime in [auth.Providers] (indexed by their name).
+	CustomAuth map[string]AuthProviderConfig `form:"customAuth" json:"customAuth"`
 }
 
 // New creates and returns a new default Settings instance.
@@ -94,6 +154,14 @@ func New() *Settings {
 			Password: "",
 			Tls:      false,
 		},
+		Imap: ImapConfig{
+			Enabled:      false,
+			Host:         "imap.example.com",
+			Port:         993,
+			Tls:          true,
+			Mailbox:      "INBOX",
+			PollInterval: 60,
+		},
 		Backups: BackupsConfig{
 			CronMaxKeep: 3,
 		},
@@ -101,6 +169,10 @@ func New() *Settings {
 			Secret:   security.RandomString(50),
 			Duration: 1209600, // 14 days
 		},
+		RecordRefreshToken: TokenConfig{
+			Secret:   security.RandomString(50),
+			Duration: 2592000, // 30 days
+		},
 		AdminPasswordResetToken: TokenConfig{
 			Secret:   security.RandomString(50),
 			Duration: 1800, // 30 minutes
@@ -129,6 +201,10 @@ func New() *Settings {
 			Secret:   security.RandomString(50),
 			Duration: 1800, // 30 minutes
 		},
+		RecordShareToken: TokenConfig{
+			Secret:   security.RandomString(50),
+			Duration: 604800, // 7 days
+		},
 		GoogleAuth: AuthProviderConfig{
 			Enabled: false,
 		},
@@ -204,6 +280,18 @@ func New() *Settings {
 		PlanningcenterAuth: AuthProviderConfig{
 			Enabled: false,
 		},
+		ZohoAuth: AuthProviderConfig{
+			Enabled: false,
+		},
+		SlackAuth: AuthProviderConfig{
+			Enabled: false,
+		},
+		TrustedProxy: TrustedProxyConfig{
+			Cidrs:   []string{},
+			Headers: []string{"CF-Connecting-IP", "X-Real-IP"},
+		},
+		SmtpTransports: map[string]SmtpConfig{},
+		CustomAuth:     map[string]AuthProviderConfig{},
 	}
 }
 
@@ -215,16 +303,26 @@ func (s *Settings) Validate() error {
 	return validation.ValidateStruct(s,
 		validation.Field(&s.Meta),
 		validation.Field(&s.Logs),
+		validation.Field(&s.AuditLogs),
+		validation.Field(&s.Passkeys),
+		validation.Field(&s.Cors),
+		validation.Field(&s.TrustedProxy),
 		validation.Field(&s.AdminAuthToken),
 		validation.Field(&s.AdminPasswordResetToken),
 		validation.Field(&s.AdminFileToken),
 		validation.Field(&s.RecordAuthToken),
+		validation.Field(&s.RecordRefreshToken),
 		validation.Field(&s.RecordPasswordResetToken),
 		validation.Field(&s.RecordEmailChangeToken),
 		validation.Field(&s.RecordVerificationToken),
 		validation.Field(&s.RecordFileToken),
+		validation.Field(&s.RecordShareToken),
 		validation.Field(&s.Smtp),
+		validation.Field(&s.SmtpTransports, validation.By(checkSmtpTransportNames)),
+		validation.Field(&s.Imap),
 		validation.Field(&s.S3),
+		validation.Field(&s.AzureBlob),
+		validation.Field(&s.Storage),
 		validation.Field(&s.Backups),
 		validation.Field(&s.GoogleAuth),
 		validation.Field(&s.FacebookAuth),
@@ -251,6 +349,9 @@ func (s *Settings) Validate() error {
 		validation.Field(&s.MailcowAuth),
 		validation.Field(&s.BitbucketAuth),
 		validation.Field(&s.PlanningcenterAuth),
+		validation.Field(&s.ZohoAuth),
+		validation.Field(&s.SlackAuth),
+		validation.Field(&s.CustomAuth, validation.By(s.checkCustomAuthNames)),
 	)
 }
 
@@ -286,16 +387,23 @@ func (s *Settings) RedactClone() (*Settings, error) {
 
 	sensitiveFields := []*string{
 		&clone.Smtp.Password,
+		&clone.Smtp.Dkim.PrivateKey,
+		&clone.Imap.Password,
 		&clone.S3.Secret,
 		&clone.Backups.S3.Secret,
+		&clone.AzureBlob.Key,
+		&clone.Backups.AzureBlob.Key,
+		&clone.Backups.EncryptionKey,
 		&clone.AdminAuthToken.Secret,
 		&clone.AdminPasswordResetToken.Secret,
 		&clone.AdminFileToken.Secret,
 		&clone.RecordAuthToken.Secret,
+		&clone.RecordRefreshToken.Secret,
 		&clone.RecordPasswordResetToken.Secret,
 		&clone.RecordEmailChangeToken.Secret,
 		&clone.RecordVerificationToken.Secret,
 		&clone.RecordFileToken.Secret,
+		&clone.RecordShareToken.Secret,
 		&clone.GoogleAuth.ClientSecret,
 		&clone.FacebookAuth.ClientSecret,
 		&clone.GithubAuth.ClientSecret,
@@ -321,6 +429,8 @@ func (s *Settings) RedactClone() (*Settings, error) {
 		&clone.MailcowAuth.ClientSecret,
 		&clone.BitbucketAuth.ClientSecret,
 		&clone.PlanningcenterAuth.ClientSecret,
+		&clone.ZohoAuth.ClientSecret,
+		&clone.SlackAuth.ClientSecret,
 	}
 
 	// mask all sensitive fields
@@ -330,15 +440,70 @@ func (s *Settings) RedactClone() (*Settings, error) {
 		}
 	}
 
+	for name, config := range clone.SmtpTransports {
+		if config.Password != "" {
+			config.Password = SecretMask
+		}
+		if config.Dkim.PrivateKey != "" {
+			config.Dkim.PrivateKey = SecretMask
+		}
+		clone.SmtpTransports[name] = config
+	}
+
+	for name, config := range clone.CustomAuth {
+		if config.ClientSecret != "" {
+			config.ClientSecret = SecretMask
+			clone.CustomAuth[name] = config
+		}
+	}
+
+	clone.Storage.redactOptions()
+	clone.Backups.Storage.redactOptions()
+
 	return clone, nil
 }
 
+// RestoreRedactedOptions replaces the [SecretMask] storage options of the
+// current settings with their values from the original settings
+// (eg. when submitting back the options of a [Settings.RedactClone] result).
+func (s *Settings) RestoreRedactedOptions(original *Settings) {
+	if s == original {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	original.mux.RLock()
+	defer original.mux.RUnlock()
+
+	s.Storage.restoreRedactedOptions(original.Storage)
+	s.Backups.Storage.restoreRedactedOptions(original.Backups.Storage)
+}
+
 // NamedAuthProviderConfigs returns a map with all registered OAuth2
 // provider configurations (indexed by their name identifier).
 func (s *Settings) NamedAuthProviderConfigs() map[string]AuthProviderConfig {
 	s.mux.RLock()
 	defer s.mux.RUnlock()
 
+	result := s.builtinAuthProviderConfigs()
+
+	// the custom providers cannot override the builtin ones
+	for name, config := range s.CustomAuth {
+		if _, ok := result[name]; !ok {
+			result[name] = config
+		}
+	}
+
+	return result
+}
+
+// builtinAuthProviderConfigs returns a map with the builtin OAuth2
+// provider configurations (indexed by their name identifier).
+//
+// Note that the method doesn't lock the settings mutex.
+func (s *Settings) builtinAuthProviderConfigs() map[string]AuthProviderConfig {
 	return map[string]AuthProviderConfig{
 		auth.NameGoogle:         s.GoogleAuth,
 		auth.NameFacebook:       s.FacebookAuth,
@@ -365,7 +530,48 @@ func (s *Settings) NamedAuthProviderConfigs() map[string]AuthProviderConfig {
 		auth.NameMailcow:        s.MailcowAuth,
 		auth.NameBitbucket:      s.BitbucketAuth,
 		auth.NamePlanningcenter: s.PlanningcenterAuth,
+		auth.NameZoho:           s.ZohoAuth,
+		auth.NameSlack:          s.SlackAuth,
+	}
+}
+
+func (s *Settings) checkCustomAuthNames(value any) error {
+	v, _ := value.(map[string]AuthProviderConfig)
+
+	builtin := s.builtinAuthProviderConfigs()
+
+	for name := range v {
+		if _, ok := builtin[name]; ok {
+			return validation.NewError(
+				"validation_builtin_provider",
+				fmt.Sprintf("%q is a builtin OAuth2 provider and cannot be configured as custom one.", name),
+			)
+		}
+
+		if _, ok := auth.Providers[name]; !ok {
+			return validation.NewError(
+				"validation_invalid_provider",
+				fmt.Sprintf("Missing or unregistered OAuth2 provider %q.", name),
+			)
+		}
 	}
+
+	return nil
+}
+
+func checkSmtpTransportNames(value any) error {
+	v, _ := value.(map[string]SmtpConfig)
+
+	for name := range v {
+		if !smtpTransportNameRegex.MatchString(name) {
+			return validation.NewError(
+				"validation_invalid_transport_name",
+				fmt.Sprintf("Invalid SMTP transport name %q (only alphanumeric, underscore and dash characters are allowed).", name),
+			)
+		}
+	}
+
+	return nil
 }
 
 // -------------------------------------------------------------------
@@ -406,6 +612,9 @@ type SmtpConfig struct {
 	//
 	// This is required only by some SMTP servers, such as Gmail SMTP-relay.
 	LocalName string `form:"localName" json:"localName"`
+
+	// Dkim is an optional DKIM signing configuration of the outgoing messages.
+	Dkim DkimConfig `form:"dkim" json:"dkim"`
 }
 
 // Validate makes SmtpConfig validatable by implementing [validation.Validatable] interface.
@@ -429,6 +638,116 @@ func (c SmtpConfig) Validate() error {
 			validation.In(mailer.SmtpAuthLogin, mailer.SmtpAuthPlain),
 		),
 		validation.Field(&c.LocalName, is.Host),
+		validation.Field(&c.Dkim),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// DkimConfig defines the DKIM signing settings of the outgoing SMTP messages.
+//
+// The messages are signed only if PrivateKey is set.
+type DkimConfig struct {
+	// Domain is the signing domain (eg. "example.com").
+	Domain string `form:"domain" json:"domain"`
+
+	// Selector is the DNS selector of the DKIM public key
+	// (eg. "pb" for a "pb._domainkey.example.com" TXT record).
+	Selector string `form:"selector" json:"selector"`
+
+	// PrivateKey is the PEM encoded RSA or Ed25519 private key.
+	PrivateKey string `form:"privateKey" json:"privateKey"`
+}
+
+// Validate makes DkimConfig validatable by implementing [validation.Validatable] interface.
+func (c DkimConfig) Validate() error {
+	enabled := c.PrivateKey != ""
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Domain, validation.When(enabled, validation.Required), is.Domain),
+		validation.Field(
+			&c.Selector,
+			validation.When(enabled, validation.Required),
+			validation.Match(dkimSelectorRegex),
+		),
+		validation.Field(&c.PrivateKey, validation.By(checkDkimPrivateKey)),
+	)
+}
+
+func checkDkimPrivateKey(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil
+	}
+
+	if err := mailer.ValidateDkimPrivateKey(v); err != nil {
+		return validation.NewError("validation_invalid_dkim_private_key", "Invalid or unsupported DKIM private key.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// ImapConfig defines the inbound IMAP mailbox settings.
+//
+// When enabled, the unseen mailbox messages are dispatched
+// to the app OnMailReceive hook handlers.
+type ImapConfig struct {
+	Enabled  bool   `form:"enabled" json:"enabled"`
+	Host     string `form:"host" json:"host"`
+	Port     int    `form:"port" json:"port"`
+	Username string `form:"username" json:"username"`
+	Password string `form:"password" json:"password"`
+
+	// Whether to use implicit TLS for the mail server connection (usually port 993).
+	//
+	// When set to false StartTLS command is send if the server supports it.
+	Tls bool `form:"tls" json:"tls"`
+
+	// AllowInsecureAuth allows sending the credentials over a plaintext
+	// connection when the server doesn't support StartTLS.
+	AllowInsecureAuth bool `form:"allowInsecureAuth" json:"allowInsecureAuth"`
+
+	// Mailbox is the name of the mailbox to check (default to "INBOX").
+	Mailbox string `form:"mailbox" json:"mailbox"`
+
+	// PollInterval is the interval in seconds between the mailbox checks
+	// (or the max IDLE wait time if Idle is enabled).
+	PollInterval int `form:"pollInterval" json:"pollInterval"`
+
+	// Idle enables the IMAP IDLE extension (if supported by the server)
+	// to receive the new messages without waiting for the next poll interval.
+	Idle bool `form:"idle" json:"idle"`
+
+	// MaxAttempts is the max number of failed processing attempts
+	// of a single message before giving up on it (default to 3).
+	MaxAttempts int `form:"maxAttempts" json:"maxAttempts"`
+
+	// FailedMailbox is the optional mailbox where to move the messages
+	// that have reached MaxAttempts (if empty they are only marked as seen).
+	FailedMailbox string `form:"failedMailbox" json:"failedMailbox"`
+}
+
+// Validate makes ImapConfig validatable by implementing [validation.Validatable] interface.
+func (c ImapConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(
+			&c.Host,
+			validation.When(c.Enabled, validation.Required),
+			is.Host,
+		),
+		validation.Field(
+			&c.Port,
+			validation.When(c.Enabled, validation.Required),
+			validation.Min(0),
+			validation.Max(65535),
+		),
+		validation.Field(&c.Username, validation.When(c.Enabled, validation.Required)),
+		validation.Field(&c.Mailbox, validation.Length(0, 255)),
+		validation.Field(&c.PollInterval, validation.Min(0), validation.Max(86400)),
+		validation.Field(&c.MaxAttempts, validation.Min(0), validation.Max(100)),
+		validation.Field(&c.FailedMailbox, validation.Length(0, 255)),
 	)
 }
 
@@ -457,6 +776,90 @@ func (c S3Config) Validate() error {
 
 // -------------------------------------------------------------------
 
+type AzureBlobConfig struct {
+	Enabled   bool   `form:"enabled" json:"enabled"`
+	Account   string `form:"account" json:"account"`
+	Key       string `form:"key" json:"key"`
+	Container string `form:"container" json:"container"`
+
+	// Prefix is an optional path prefix of all stored files (eg. "pb_data/").
+	Prefix string `form:"prefix" json:"prefix"`
+
+	// ServeSignedURLs redirects the file download requests to short-lived
+	// SAS urls instead of streaming the files through the app.
+	//
+	// It is not applicable for the backups storage.
+	ServeSignedURLs bool `form:"serveSignedURLs" json:"serveSignedURLs"`
+}
+
+// Validate makes AzureBlobConfig validatable by implementing [validation.Validatable] interface.
+func (c AzureBlobConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Account, validation.When(c.Enabled, validation.Required)),
+		validation.Field(&c.Key, validation.When(c.Enabled, validation.Required)),
+		validation.Field(&c.Container, validation.When(c.Enabled, validation.Required)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type StorageConfig struct {
+	// Backend is the name of a registered filesystem backend
+	// (see [filesystem.RegisterBackend]).
+	//
+	// Leave it empty to use the S3, Azure Blob or local storage.
+	Backend string `form:"backend" json:"backend"`
+
+	// Options is the generic backend options map passed to the backend factory.
+	Options map[string]any `form:"options" json:"options"`
+}
+
+// Validate makes StorageConfig validatable by implementing [validation.Validatable] interface.
+func (c StorageConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Backend, validation.By(checkStorageBackend)),
+	)
+}
+
+// redactOptions replaces the secret looking string options
+// (eg. "secret", "accessKey", "credentialsJSON") with [SecretMask].
+func (c *StorageConfig) redactOptions() {
+	for k, v := range c.Options {
+		if str, ok := v.(string); ok && str != "" && storageSecretOptionRegex.MatchString(k) {
+			c.Options[k] = SecretMask
+		}
+	}
+}
+
+// restoreRedactedOptions replaces the [SecretMask] options
+// with their existing values from the original config.
+func (c *StorageConfig) restoreRedactedOptions(original StorageConfig) {
+	for k, v := range c.Options {
+		if str, ok := v.(string); !ok || str != SecretMask {
+			continue
+		}
+
+		if originalValue, ok := original.Options[k]; ok {
+			c.Options[k] = originalValue
+		}
+	}
+}
+
+func checkStorageBackend(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if !filesystem.HasBackend(v) {
+		return validation.NewError("validation_unknown_storage_backend", "Unknown filesystem backend.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
 type BackupsConfig struct {
 	// Cron is a cron expression to schedule auto backups, eg. "* * * * *".
 	//
@@ -471,13 +874,31 @@ type BackupsConfig struct {
 
 	// S3 is an optional S3 storage config specifying where to store the app backups.
 	S3 S3Config `form:"s3" json:"s3"`
+
+	// AzureBlob is an optional Azure Blob Storage config specifying where to store the app backups
+	// (it is ignored if the S3 storage is also enabled).
+	AzureBlob AzureBlobConfig `form:"azureBlob" json:"azureBlob"`
+
+	// Storage is an optional registered filesystem backend config specifying
+	// where to store the app backups (if set, it takes precedence over the S3 and AzureBlob configs).
+	Storage StorageConfig `form:"storage" json:"storage"`
+
+	// EncryptionKey is an optional 32 characters AES key used to encrypt
+	// the generated backups.
+	//
+	// Leave it empty to store the backups unencrypted.
+	// Note that the encrypted backups couldn't be restored without the same key.
+	EncryptionKey string `form:"encryptionKey" json:"encryptionKey"`
 }
 
 // Validate makes BackupsConfig validatable by implementing [validation.Validatable] interface.
 func (c BackupsConfig) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.S3),
+		validation.Field(&c.AzureBlob),
+		validation.Field(&c.Storage),
 		validation.Field(&c.Cron, validation.By(checkCronExpression)),
+		validation.Field(&c.EncryptionKey, validation.Length(32, 32)),
 		validation.Field(
 			&c.CronMaxKeep,
 			validation.When(c.Cron != "", validation.Required),
@@ -511,6 +932,18 @@ type MetaConfig struct {
 	VerificationTemplate       EmailTemplate `form:"verificationTemplate" json:"verificationTemplate"`
 	ResetPasswordTemplate      EmailTemplate `form:"resetPasswordTemplate" json:"resetPasswordTemplate"`
 	ConfirmEmailChangeTemplate EmailTemplate `form:"confirmEmailChangeTemplate" json:"confirmEmailChangeTemplate"`
+
+	// MaxExpandDepth is the max allowed depth of a single record expand path
+	// (eg. "a.b.c" has depth 3).
+	//
+	// If not set, fallbacks to the default daos.MaxExpandDepth.
+	MaxExpandDepth int `form:"maxExpandDepth" json:"maxExpandDepth"`
+
+	// MaxBulkDelete is the max number of records that could be deleted
+	// with a single delete-by-filter request without explicit ?confirm.
+	//
+	// If not set, fallbacks to the default apis.DefaultMaxBulkDelete.
+	MaxBulkDelete int `form:"maxBulkDelete" json:"maxBulkDelete"`
 }
 
 // Validate makes MetaConfig validatable by implementing [validation.Validatable] interface.
@@ -523,6 +956,8 @@ func (c MetaConfig) Validate() error {
 		validation.Field(&c.VerificationTemplate, validation.Required),
 		validation.Field(&c.ResetPasswordTemplate, validation.Required),
 		validation.Field(&c.ConfirmEmailChangeTemplate, validation.Required),
+		validation.Field(&c.MaxExpandDepth, validation.Min(0), validation.Max(MaxExpandDepthLimit)),
+		validation.Field(&c.MaxBulkDelete, validation.Min(0)),
 	)
 }
 
@@ -531,6 +966,10 @@ type EmailTemplate struct {
 	Subject   string `form:"subject" json:"subject"`
 	ActionUrl string `form:"actionUrl" json:"actionUrl"`
 	Hidden    bool   `form:"hidden" json:"hidden"`
+
+	// Transport is an optional SmtpTransports name to send the email with
+	// (fallbacks to the default Smtp config if empty or not enabled).
+	Transport string `form:"transport" json:"transport"`
 }
 
 // Validate makes EmailTemplate validatable by implementing [validation.Validatable] interface.
@@ -628,6 +1067,342 @@ func (c LogsConfig) Validate() error {
 
 // -------------------------------------------------------------------
 
+type AuditLogsConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Collections is an optional list with collection ids or names
+	// to restrict the audit logs only to their records.
+	//
+	// Leave it empty to audit the records of all collections.
+	Collections []string `form:"collections" json:"collections"`
+}
+
+// Validate makes AuditLogsConfig validatable by implementing [validation.Validatable] interface.
+func (c AuditLogsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Collections, validation.Each(validation.Required)),
+	)
+}
+
+// IsCollectionEnabled reports whether the audit logs are enabled
+// for the collection with the specified id and name.
+func (c AuditLogsConfig) IsCollectionEnabled(collectionId string, collectionName string) bool {
+	if !c.Enabled {
+		return false
+	}
+
+	if len(c.Collections) == 0 {
+		return true
+	}
+
+	for _, idOrName := range c.Collections {
+		if idOrName == collectionId || strings.EqualFold(idOrName, collectionName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// -------------------------------------------------------------------
+
+type PasskeysConfig struct {
+	// RPID is the WebAuthn relying party id (the domain, or its
+	// registrable suffix, of the origins that will use the passkeys).
+	//
+	// Leave it empty to use the Meta.AppUrl hostname.
+	RPID string `form:"rpId" json:"rpId"`
+
+	// Origins is the list of the allowed WebAuthn client origins
+	// (eg. "https://example.com").
+	//
+	// Leave it empty to allow only the Meta.AppUrl origin.
+	Origins []string `form:"origins" json:"origins"`
+}
+
+// Validate makes PasskeysConfig validatable by implementing [validation.Validatable] interface.
+func (c PasskeysConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.RPID, validation.Length(0, 255)),
+		validation.Field(&c.Origins, validation.Each(validation.Required, is.URL)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// DefaultCorsMethods is the list of the allowed CORS methods
+// used when CorsConfig.AllowedMethods is not set.
+var DefaultCorsMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodDelete,
+}
+
+type CorsConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// AllowedOrigins is the list of the allowed request origins.
+	//
+	// Each origin could be either "*" (any origin), an exact origin
+	// (eg. "https://example.com") or a wildcard subdomain pattern
+	// (eg. "https://*.example.com").
+	AllowedOrigins []string `form:"allowedOrigins" json:"allowedOrigins"`
+
+	// AllowedMethods is the list of the allowed request methods
+	// (leave it empty to use [DefaultCorsMethods]).
+	AllowedMethods []string `form:"allowedMethods" json:"allowedMethods"`
+
+	// AllowedHeaders is the list of the allowed request headers
+	// (leave it empty to allow the preflight requested headers).
+	AllowedHeaders []string `form:"allowedHeaders" json:"allowedHeaders"`
+
+	// ExposedHeaders is the list of the response headers
+	// that are accessible from the browser scripts.
+	ExposedHeaders []string `form:"exposedHeaders" json:"exposedHeaders"`
+
+	// AllowCredentials indicates whether the requests could
+	// include credentials like cookies and TLS client certificates.
+	//
+	// It cannot be combined with the "*" origin.
+	AllowCredentials bool `form:"allowCredentials" json:"allowCredentials"`
+
+	// MaxAge specifies in seconds how long the preflight
+	// responses could be cached (0 means no Access-Control-Max-Age header).
+	MaxAge int `form:"maxAge" json:"maxAge"`
+}
+
+// Validate makes CorsConfig validatable by implementing [validation.Validatable] interface.
+func (c CorsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(
+			&c.AllowedOrigins,
+			validation.When(c.Enabled, validation.Required),
+			validation.Each(validation.Required, validation.Match(corsOriginRegex)),
+			validation.When(
+				c.AllowCredentials,
+				validation.Each(validation.NotIn("*").Error("The wildcard origin cannot be used with allowed credentials.")),
+			),
+		),
+		validation.Field(
+			&c.AllowedMethods,
+			validation.Each(validation.In(
+				http.MethodGet,
+				http.MethodHead,
+				http.MethodPut,
+				http.MethodPatch,
+				http.MethodPost,
+				http.MethodDelete,
+				http.MethodOptions,
+			)),
+		),
+		validation.Field(&c.AllowedHeaders, validation.Each(validation.Required, validation.Match(corsHeaderRegex))),
+		validation.Field(&c.ExposedHeaders, validation.Each(validation.Required, validation.Match(corsHeaderRegex))),
+		validation.Field(&c.MaxAge, validation.Min(0), validation.Max(86400)),
+	)
+}
+
+// MatchOrigin checks whether the provided request origin
+// matches any of the AllowedOrigins patterns.
+func (c CorsConfig) MatchOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	for _, pattern := range c.AllowedOrigins {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+
+		// wildcard subdomain, eg. "https://*.example.com"
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+
+		prefix := scheme + "://"
+		suffix := "." + host
+		if len(origin) > len(prefix)+len(suffix) &&
+			strings.EqualFold(origin[:len(prefix)], prefix) &&
+			strings.EqualFold(origin[len(origin)-len(suffix):], suffix) &&
+			!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:@") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// -------------------------------------------------------------------
+
+type TrustedProxyConfig struct {
+	// Cidrs is the list of the trusted reverse proxies IP ranges
+	// in CIDR notation or as single IPs (eg. "10.0.0.0/8", "127.0.0.1").
+	//
+	// The proxy headers are considered only if the request
+	// remote address is one of the trusted proxies.
+	Cidrs []string `form:"cidrs" json:"cidrs"`
+
+	// Headers is the list of the client IP headers
+	// (eg. "CF-Connecting-IP", "X-Real-IP") checked in the order of
+	// their precedence when the X-Forwarded-For header doesn't
+	// have any non-trusted address.
+	Headers []string `form:"headers" json:"headers"`
+}
+
+// Validate makes TrustedProxyConfig validatable by implementing [validation.Validatable] interface.
+func (c TrustedProxyConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Cidrs, validation.Each(validation.Required, validation.By(checkIpOrCidr))),
+		validation.Field(&c.Headers, validation.Each(validation.Required, validation.Match(headerNameRegex))),
+	)
+}
+
+// ClientIp resolves the real client IP of the provided request.
+//
+// If the request remote address is not a trusted proxy, the remote
+// address is returned and the proxy headers are ignored (aka. they
+// cannot be spoofed by the clients).
+//
+// Otherwise the client IP is the right-most X-Forwarded-For address
+// that is not a trusted proxy, fallbacking to the first valid
+// Headers value and then to the remote address.
+//
+// The X-Forwarded-For walk stops at the first invalid entry and the last
+// trusted hop (or the remote address) is returned because the entries
+// on its left could be spoofed.
+func (c TrustedProxyConfig) ClientIp(r *http.Request) string {
+	remoteIp, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIp = r.RemoteAddr
+	}
+
+	prefixes := c.prefixes()
+
+	if len(prefixes) == 0 || !isTrustedIp(prefixes, remoteIp) {
+		return remoteIp
+	}
+
+	// walk the proxy chain from right to left
+	// (each trusted proxy appends the address of its peer)
+	if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		lastTrusted := remoteIp
+
+		ips := strings.Split(strings.Join(values, ","), ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(ips[i]))
+			if err != nil {
+				return lastTrusted
+			}
+
+			ip := addr.Unmap().String()
+
+			if !isTrustedIp(prefixes, ip) {
+				return ip
+			}
+
+			lastTrusted = ip
+		}
+	}
+
+	for _, header := range c.Headers {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get(header))); err == nil {
+			return addr.Unmap().String()
+		}
+	}
+
+	return remoteIp
+}
+
+// trustedPrefixesCache holds the last parsed TrustedProxyConfig.Cidrs
+// to avoid parsing them on every ClientIp call.
+var trustedPrefixesCache struct {
+	mux      sync.RWMutex
+	cidrs    []string
+	prefixes []netip.Prefix
+}
+
+// prefixes returns the parsed Cidrs (the invalid ones are skipped).
+//
+// The Cidrs are parsed only once per unique list.
+func (c TrustedProxyConfig) prefixes() []netip.Prefix {
+	trustedPrefixesCache.mux.RLock()
+	cached, ok := trustedPrefixesCache.prefixes, slices.Equal(trustedPrefixesCache.cidrs, c.Cidrs)
+	trustedPrefixesCache.mux.RUnlock()
+
+	if ok {
+		return cached
+	}
+
+	result := make([]netip.Prefix, 0, len(c.Cidrs))
+
+	for _, cidr := range c.Cidrs {
+		if prefix, err := parseIpOrCidr(cidr); err == nil {
+			result = append(result, prefix)
+		}
+	}
+
+	trustedPrefixesCache.mux.Lock()
+	trustedPrefixesCache.cidrs = slices.Clone(c.Cidrs)
+	trustedPrefixesCache.prefixes = result
+	trustedPrefixesCache.mux.Unlock()
+
+	return result
+}
+
+func isTrustedIp(prefixes []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseIpOrCidr parses the provided CIDR or single IP as netip.Prefix.
+func parseIpOrCidr(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func checkIpOrCidr(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := parseIpOrCidr(v); err != nil {
+		return validation.NewError("validation_invalid_cidr", "Must be a valid IP address or CIDR range.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
 type AuthProviderConfig struct {
 	Enabled      bool   `form:"enabled" json:"enabled"`
 	ClientId     string `form:"clientId" json:"clientId"`
@@ -635,6 +1410,7 @@ type AuthProviderConfig struct {
 	AuthUrl      string `form:"authUrl" json:"authUrl"`
 	TokenUrl     string `form:"tokenUrl" json:"tokenUrl"`
 	UserApiUrl   string `form:"userApiUrl" json:"userApiUrl"`
+	JWKSUrl      string `form:"jwksUrl" json:"jwksUrl"`
 	DisplayName  string `form:"displayName" json:"displayName"`
 	PKCE         *bool  `form:"pkce" json:"pkce"`
 }
@@ -647,6 +1423,7 @@ func (c AuthProviderConfig) Validate() error {
 		validation.Field(&c.AuthUrl, is.URL),
 		validation.Field(&c.TokenUrl, is.URL),
 		validation.Field(&c.UserApiUrl, is.URL),
+		validation.Field(&c.JWKSUrl, is.URL),
 	)
 }
 
@@ -676,6 +1453,10 @@ func (c AuthProviderConfig) SetupProvider(provider auth.Provider) error {
 		provider.SetTokenUrl(c.TokenUrl)
 	}
 
+	if c.JWKSUrl != "" {
+		provider.SetJWKSUrl(c.JWKSUrl)
+	}
+
 	if c.DisplayName != "" {
 		provider.SetDisplayName(c.DisplayName)
 	}
@@ -2,8 +2,14 @@ package settings_test
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -20,18 +26,26 @@ func TestSettingsValidate(t *testing.T) {
 	// set invalid settings data
 	s.Meta.AppName = ""
 	s.Logs.MaxDays = -10
+	s.AuditLogs.Collections = []string{""}
+	s.Passkeys.Origins = []string{"invalid"}
+	s.Cors.Enabled = true
 	s.Smtp.Enabled = true
 	s.Smtp.Host = ""
+	s.Imap.Enabled = true
+	s.Imap.Host = ""
 	s.S3.Enabled = true
 	s.S3.Endpoint = "invalid"
+	s.AzureBlob.Enabled = true
 	s.AdminAuthToken.Duration = -10
 	s.AdminPasswordResetToken.Duration = -10
 	s.AdminFileToken.Duration = -10
 	s.RecordAuthToken.Duration = -10
+	s.RecordRefreshToken.Duration = -10
 	s.RecordPasswordResetToken.Duration = -10
 	s.RecordEmailChangeToken.Duration = -10
 	s.RecordVerificationToken.Duration = -10
 	s.RecordFileToken.Duration = -10
+	s.RecordShareToken.Duration = -10
 	s.GoogleAuth.Enabled = true
 	s.GoogleAuth.ClientId = ""
 	s.FacebookAuth.Enabled = true
@@ -82,6 +96,10 @@ func TestSettingsValidate(t *testing.T) {
 	s.BitbucketAuth.ClientId = ""
 	s.PlanningcenterAuth.Enabled = true
 	s.PlanningcenterAuth.ClientId = ""
+	s.ZohoAuth.Enabled = true
+	s.ZohoAuth.ClientId = ""
+	s.SlackAuth.Enabled = true
+	s.SlackAuth.ClientId = ""
 
 	// check if Validate() is triggering the members validate methods.
 	err := s.Validate()
@@ -92,16 +110,23 @@ func TestSettingsValidate(t *testing.T) {
 	expectations := []string{
 		`"meta":{`,
 		`"logs":{`,
+		`"auditLogs":{`,
+		`"passkeys":{`,
+		`"cors":{`,
 		`"smtp":{`,
+		`"imap":{`,
 		`"s3":{`,
+		`"azureBlob":{`,
 		`"adminAuthToken":{`,
 		`"adminPasswordResetToken":{`,
 		`"adminFileToken":{`,
 		`"recordAuthToken":{`,
+		`"recordRefreshToken":{`,
 		`"recordPasswordResetToken":{`,
 		`"recordEmailChangeToken":{`,
 		`"recordVerificationToken":{`,
 		`"recordFileToken":{`,
+		`"recordShareToken":{`,
 		`"googleAuth":{`,
 		`"facebookAuth":{`,
 		`"githubAuth":{`,
@@ -127,6 +152,8 @@ func TestSettingsValidate(t *testing.T) {
 		`"mailcowAuth":{`,
 		`"bitbucketAuth":{`,
 		`"planningcenterAuth":{`,
+		`"zohoAuth":{`,
+		`"slackAuth":{`,
 	}
 
 	errBytes, _ := json.Marshal(err)
@@ -208,6 +235,10 @@ func TestSettingsMerge(t *testing.T) {
 	s2.BitbucketAuth.ClientId = "bitbucket_test"
 	s2.PlanningcenterAuth.Enabled = true
 	s2.PlanningcenterAuth.ClientId = "planningcenter_test"
+	s2.ZohoAuth.Enabled = true
+	s2.ZohoAuth.ClientId = "zoho_test"
+	s2.SlackAuth.Enabled = true
+	s2.SlackAuth.ClientId = "slack_test"
 
 	if err := s1.Merge(s2); err != nil {
 		t.Fatal(err)
@@ -267,16 +298,23 @@ func TestSettingsRedactClone(t *testing.T) {
 
 	// secrets
 	s1.Smtp.Password = testSecret
+	s1.Smtp.Dkim.PrivateKey = testSecret
+	s1.Imap.Password = testSecret
 	s1.S3.Secret = testSecret
 	s1.Backups.S3.Secret = testSecret
+	s1.AzureBlob.Key = testSecret
+	s1.Backups.AzureBlob.Key = testSecret
+	s1.Backups.EncryptionKey = testSecret
 	s1.AdminAuthToken.Secret = testSecret
 	s1.AdminPasswordResetToken.Secret = testSecret
 	s1.AdminFileToken.Secret = testSecret
 	s1.RecordAuthToken.Secret = testSecret
+	s1.RecordRefreshToken.Secret = testSecret
 	s1.RecordPasswordResetToken.Secret = testSecret
 	s1.RecordEmailChangeToken.Secret = testSecret
 	s1.RecordVerificationToken.Secret = testSecret
 	s1.RecordFileToken.Secret = testSecret
+	s1.RecordShareToken.Secret = testSecret
 	s1.GoogleAuth.ClientSecret = testSecret
 	s1.FacebookAuth.ClientSecret = testSecret
 	s1.GithubAuth.ClientSecret = testSecret
@@ -302,6 +340,10 @@ func TestSettingsRedactClone(t *testing.T) {
 	s1.MailcowAuth.ClientSecret = testSecret
 	s1.BitbucketAuth.ClientSecret = testSecret
 	s1.PlanningcenterAuth.ClientSecret = testSecret
+	s1.ZohoAuth.ClientSecret = testSecret
+	s1.SlackAuth.ClientSecret = testSecret
+	s1.Storage.Options = map[string]any{"accessKey": testSecret, "bucket": "test_bucket"}
+	s1.Backups.Storage.Options = map[string]any{"credentialsJSON": testSecret}
 
 	s1Bytes, err := json.Marshal(s1)
 	if err != nil {
@@ -333,6 +375,47 @@ func TestSettingsRedactClone(t *testing.T) {
 	if !strings.Contains(string(s2Bytes), `"appName":"test123"`) {
 		t.Fatalf("Missing control field in \n%s", s2Bytes)
 	}
+
+	if !strings.Contains(string(s2Bytes), `"bucket":"test_bucket"`) {
+		t.Fatalf("Expected the non-secret storage options to be preserved in \n%s", s2Bytes)
+	}
+
+	if s1.Storage.Options["accessKey"] != testSecret {
+		t.Fatal("Expected the original storage options to remain unchanged")
+	}
+}
+
+func TestSettingsRestoreRedactedOptions(t *testing.T) {
+	original := settings.New()
+	original.Storage.Options = map[string]any{"accessKey": "test_key", "secret": "test_secret"}
+	original.Backups.Storage.Options = map[string]any{"credentialsJSON": "test_credentials"}
+
+	s := settings.New()
+	s.Storage.Options = map[string]any{
+		"accessKey": settings.SecretMask, // restored
+		"secret":    "new_secret",        // changed
+		"missing":   settings.SecretMask, // not in the original
+		"bucket":    "test_bucket",
+	}
+	s.Backups.Storage.Options = map[string]any{"credentialsJSON": settings.SecretMask}
+
+	s.RestoreRedactedOptions(original)
+
+	expectedStorage := map[string]any{
+		"accessKey": "test_key",
+		"secret":    "new_secret",
+		"missing":   settings.SecretMask,
+		"bucket":    "test_bucket",
+	}
+	for k, v := range expectedStorage {
+		if s.Storage.Options[k] != v {
+			t.Errorf("Expected storage option %q to be %v, got %v", k, v, s.Storage.Options[k])
+		}
+	}
+
+	if v := s.Backups.Storage.Options["credentialsJSON"]; v != "test_credentials" {
+		t.Errorf("Expected the backups storage credentialsJSON to be restored, got %v", v)
+	}
 }
 
 func TestNamedAuthProviderConfigs(t *testing.T) {
@@ -364,6 +447,8 @@ func TestNamedAuthProviderConfigs(t *testing.T) {
 	s.MailcowAuth.ClientId = "mailcow_test"
 	s.BitbucketAuth.ClientId = "bitbucket_test"
 	s.PlanningcenterAuth.ClientId = "planningcenter_test"
+	s.ZohoAuth.ClientId = "zoho_test"
+	s.SlackAuth.ClientId = "slack_test"
 
 	result := s.NamedAuthProviderConfigs()
 
@@ -399,6 +484,8 @@ func TestNamedAuthProviderConfigs(t *testing.T) {
 		`"mailcow":{"enabled":false,"clientId":"mailcow_test"`,
 		`"bitbucket":{"enabled":false,"clientId":"bitbucket_test"`,
 		`"planningcenter":{"enabled":false,"clientId":"planningcenter_test"`,
+		`"zoho":{"enabled":false,"clientId":"zoho_test"`,
+		`"slack":{"enabled":false,"clientId":"slack_test"`,
 	}
 	for _, p := range expectedParts {
 		if !strings.Contains(encodedStr, p) {
@@ -407,6 +494,141 @@ func TestNamedAuthProviderConfigs(t *testing.T) {
 	}
 }
 
+func TestSettingsCustomAuth(t *testing.T) {
+	auth.RegisterOIDCProvider("test_custom", auth.OIDCConfig{})
+	defer delete(auth.Providers, "test_custom")
+
+	t.Run("validate", func(t *testing.T) {
+		scenarios := []struct {
+			name        string
+			customAuth  map[string]settings.AuthProviderConfig
+			expectError bool
+		}{
+			{"nil", nil, false},
+			{"registered provider", map[string]settings.AuthProviderConfig{"test_custom": {Enabled: false}}, false},
+			{"unregistered provider", map[string]settings.AuthProviderConfig{"missing": {Enabled: false}}, true},
+			{"builtin provider", map[string]settings.AuthProviderConfig{auth.NameGoogle: {Enabled: false}}, true},
+			{"invalid config", map[string]settings.AuthProviderConfig{"test_custom": {Enabled: true}}, true},
+		}
+
+		for _, scenario := range scenarios {
+			t.Run(scenario.name, func(t *testing.T) {
+				s := settings.New()
+				s.CustomAuth = scenario.customAuth
+
+				err := s.Validate()
+
+				hasErr := err != nil
+				if hasErr != scenario.expectError {
+					t.Fatalf("Expected hasErr %v, got %v (%v)", scenario.expectError, hasErr, err)
+				}
+
+				if hasErr && !strings.Contains(fmt.Sprint(err), "customAuth") {
+					t.Fatalf("Expected customAuth error, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("named configs", func(t *testing.T) {
+		s := settings.New()
+		s.GoogleAuth.ClientId = "google_test"
+		s.CustomAuth = map[string]settings.AuthProviderConfig{
+			"test_custom":   {ClientId: "custom_test"},
+			auth.NameGoogle: {ClientId: "override_test"},
+		}
+
+		configs := s.NamedAuthProviderConfigs()
+
+		if configs["test_custom"].ClientId != "custom_test" {
+			t.Fatalf("Expected the custom provider config, got %v", configs["test_custom"])
+		}
+
+		if configs[auth.NameGoogle].ClientId != "google_test" {
+			t.Fatalf("Expected the builtin provider config to not be overwritten, got %v", configs[auth.NameGoogle])
+		}
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		s := settings.New()
+		s.CustomAuth = map[string]settings.AuthProviderConfig{
+			"test_custom": {ClientId: "custom_test", ClientSecret: "custom_secret"},
+		}
+
+		clone, err := s.RedactClone()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := clone.CustomAuth["test_custom"].ClientSecret; v != settings.SecretMask {
+			t.Fatalf("Expected the custom secret to be masked, got %q", v)
+		}
+
+		if v := s.CustomAuth["test_custom"].ClientSecret; v != "custom_secret" {
+			t.Fatalf("Expected the original secret to remain unchanged, got %q", v)
+		}
+	})
+}
+
+func TestSettingsSmtpTransports(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		scenarios := []struct {
+			name        string
+			transports  map[string]settings.SmtpConfig
+			expectError bool
+		}{
+			{"nil", nil, false},
+			{"valid disabled transport", map[string]settings.SmtpConfig{"marketing": {Enabled: false}}, false},
+			{"valid enabled transport", map[string]settings.SmtpConfig{"marketing-1": {Enabled: true, Host: "example.com", Port: 587}}, false},
+			{"invalid name", map[string]settings.SmtpConfig{"invalid name": {Enabled: false}}, true},
+			{"empty name", map[string]settings.SmtpConfig{"": {Enabled: false}}, true},
+			{"invalid config", map[string]settings.SmtpConfig{"marketing": {Enabled: true}}, true},
+		}
+
+		for _, scenario := range scenarios {
+			t.Run(scenario.name, func(t *testing.T) {
+				s := settings.New()
+				s.SmtpTransports = scenario.transports
+
+				err := s.Validate()
+
+				hasErr := err != nil
+				if hasErr != scenario.expectError {
+					t.Fatalf("Expected hasErr %v, got %v (%v)", scenario.expectError, hasErr, err)
+				}
+
+				if hasErr && !strings.Contains(fmt.Sprint(err), "smtpTransports") {
+					t.Fatalf("Expected smtpTransports error, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		s := settings.New()
+		s.SmtpTransports = map[string]settings.SmtpConfig{
+			"marketing": {Username: "test", Password: "smtp_secret", Dkim: settings.DkimConfig{PrivateKey: "dkim_secret"}},
+		}
+
+		clone, err := s.RedactClone()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := clone.SmtpTransports["marketing"].Password; v != settings.SecretMask {
+			t.Fatalf("Expected the transport password to be masked, got %q", v)
+		}
+
+		if v := clone.SmtpTransports["marketing"].Dkim.PrivateKey; v != settings.SecretMask {
+			t.Fatalf("Expected the transport DKIM private key to be masked, got %q", v)
+		}
+
+		if v := s.SmtpTransports["marketing"].Password; v != "smtp_secret" {
+			t.Fatalf("Expected the original password to remain unchanged, got %q", v)
+		}
+	})
+}
+
 func TestTokenConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		config      settings.TokenConfig
@@ -456,6 +678,92 @@ func TestTokenConfigValidate(t *testing.T) {
 	}
 }
 
+func TestAzureBlobConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.AzureBlobConfig
+		expectError bool
+	}{
+		// zero values (disabled)
+		{
+			settings.AzureBlobConfig{},
+			false,
+		},
+		// zero values (enabled)
+		{
+			settings.AzureBlobConfig{Enabled: true},
+			true,
+		},
+		// missing container
+		{
+			settings.AzureBlobConfig{
+				Enabled: true,
+				Account: "test",
+				Key:     "test",
+			},
+			true,
+		},
+		// valid data
+		{
+			settings.AzureBlobConfig{
+				Enabled:   true,
+				Account:   "test",
+				Key:       "test",
+				Container: "test",
+			},
+			false,
+		},
+		// valid data (with prefix)
+		{
+			settings.AzureBlobConfig{
+				Enabled:   true,
+				Account:   "test",
+				Key:       "test",
+				Container: "test",
+				Prefix:    "pb_data/",
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestStorageConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.StorageConfig
+		expectError bool
+	}{
+		// zero values
+		{settings.StorageConfig{}, false},
+		// unknown backend
+		{settings.StorageConfig{Backend: "missing"}, true},
+		// registered backend
+		{settings.StorageConfig{Backend: "local", Options: map[string]any{"dir": "test"}}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
 func TestSmtpConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		config      settings.SmtpConfig
@@ -545,6 +853,133 @@ func TestSmtpConfigValidate(t *testing.T) {
 	}
 }
 
+func TestDkimConfigValidate(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}))
+
+	scenarios := []struct {
+		name        string
+		config      settings.DkimConfig
+		expectError bool
+	}{
+		{"zero values (disabled)", settings.DkimConfig{}, false},
+		{"domain and selector without key", settings.DkimConfig{Domain: "example.com", Selector: "pb"}, false},
+		{"key without domain and selector", settings.DkimConfig{PrivateKey: pemKey}, true},
+		{"invalid domain", settings.DkimConfig{Domain: "invalid!", Selector: "pb", PrivateKey: pemKey}, true},
+		{"invalid selector", settings.DkimConfig{Domain: "example.com", Selector: "invalid selector", PrivateKey: pemKey}, true},
+		{"invalid key", settings.DkimConfig{Domain: "example.com", Selector: "pb", PrivateKey: "invalid"}, true},
+		{"valid data", settings.DkimConfig{Domain: "example.com", Selector: "pb.2024", PrivateKey: pemKey}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			hasErr := result != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, result)
+			}
+		})
+	}
+}
+
+func TestImapConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.ImapConfig
+		expectError bool
+	}{
+		// zero values (disabled)
+		{
+			settings.ImapConfig{},
+			false,
+		},
+		// zero values (enabled)
+		{
+			settings.ImapConfig{Enabled: true},
+			true,
+		},
+		// invalid data
+		{
+			settings.ImapConfig{
+				Enabled:      true,
+				Host:         "test:test:test",
+				Port:         -10,
+				Username:     "test",
+				PollInterval: -1,
+			},
+			true,
+		},
+		// missing username
+		{
+			settings.ImapConfig{
+				Enabled: true,
+				Host:    "example.com",
+				Port:    993,
+			},
+			true,
+		},
+		// too large poll interval
+		{
+			settings.ImapConfig{
+				Enabled:      true,
+				Host:         "example.com",
+				Port:         993,
+				Username:     "test",
+				PollInterval: 86401,
+			},
+			true,
+		},
+		// too large max attempts
+		{
+			settings.ImapConfig{
+				Enabled:     true,
+				Host:        "example.com",
+				Port:        993,
+				Username:    "test",
+				MaxAttempts: 101,
+			},
+			true,
+		},
+		// valid data
+		{
+			settings.ImapConfig{
+				Enabled:       true,
+				Host:          "example.com",
+				Port:          993,
+				Username:      "test",
+				Tls:           true,
+				Mailbox:       "Support",
+				PollInterval:  30,
+				Idle:          true,
+				MaxAttempts:   5,
+				FailedMailbox: "Failed",
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
 func TestS3ConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		config      settings.S3Config
@@ -661,6 +1096,34 @@ func TestMetaConfigValidate(t *testing.T) {
 			},
 			true,
 		},
+		// invalid data (negative max expand depth)
+		{
+			settings.MetaConfig{
+				AppName:                    "test",
+				AppUrl:                     "https://example.com",
+				SenderName:                 "test",
+				SenderAddress:              "test@example.com",
+				VerificationTemplate:       withPlaceholdersTemplate,
+				ResetPasswordTemplate:      withPlaceholdersTemplate,
+				ConfirmEmailChangeTemplate: withPlaceholdersTemplate,
+				MaxExpandDepth:             -1,
+			},
+			true,
+		},
+		// invalid data (too large max expand depth)
+		{
+			settings.MetaConfig{
+				AppName:                    "test",
+				AppUrl:                     "https://example.com",
+				SenderName:                 "test",
+				SenderAddress:              "test@example.com",
+				VerificationTemplate:       withPlaceholdersTemplate,
+				ResetPasswordTemplate:      withPlaceholdersTemplate,
+				ConfirmEmailChangeTemplate: withPlaceholdersTemplate,
+				MaxExpandDepth:             settings.MaxExpandDepthLimit + 1,
+			},
+			true,
+		},
 		// valid data
 		{
 			settings.MetaConfig{
@@ -674,6 +1137,20 @@ func TestMetaConfigValidate(t *testing.T) {
 			},
 			false,
 		},
+		// valid data (with max expand depth)
+		{
+			settings.MetaConfig{
+				AppName:                    "test",
+				AppUrl:                     "https://example.com",
+				SenderName:                 "test",
+				SenderAddress:              "test@example.com",
+				VerificationTemplate:       withPlaceholdersTemplate,
+				ResetPasswordTemplate:      withPlaceholdersTemplate,
+				ConfirmEmailChangeTemplate: withPlaceholdersTemplate,
+				MaxExpandDepth:             settings.MaxExpandDepthLimit,
+			},
+			false,
+		},
 	}
 
 	for i, scenario := range scenarios {
@@ -717,9 +1194,26 @@ func TestBackupsConfigValidate(t *testing.T) {
 			},
 			[]string{"s3"},
 		},
+		{
+			"invalid enabled Azure Blob",
+			settings.BackupsConfig{
+				AzureBlob: settings.AzureBlobConfig{
+					Enabled: true,
+				},
+			},
+			[]string{"azureBlob"},
+		},
+		{
+			"invalid encryption key",
+			settings.BackupsConfig{
+				EncryptionKey: "test",
+			},
+			[]string{"encryptionKey"},
+		},
 		{
 			"valid data",
 			settings.BackupsConfig{
+				EncryptionKey: "abcdabcdabcdabcdabcdabcdabcdabcd",
 				S3: settings.S3Config{
 					Enabled:   true,
 					Endpoint:  "example.com",
@@ -916,6 +1410,405 @@ func TestLogsConfigValidate(t *testing.T) {
 	}
 }
 
+func TestAuditLogsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.AuditLogsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.AuditLogsConfig{},
+			false,
+		},
+		// invalid data
+		{
+			settings.AuditLogsConfig{Enabled: true, Collections: []string{"demo1", ""}},
+			true,
+		},
+		// valid data
+		{
+			settings.AuditLogsConfig{Enabled: true, Collections: []string{"demo1", "wsmn24bux7wo113"}},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestAuditLogsConfigIsCollectionEnabled(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		config   settings.AuditLogsConfig
+		id       string
+		colName  string
+		expected bool
+	}{
+		{"disabled", settings.AuditLogsConfig{}, "id1", "demo1", false},
+		{"disabled with collections", settings.AuditLogsConfig{Collections: []string{"demo1"}}, "id1", "demo1", false},
+		{"enabled for all", settings.AuditLogsConfig{Enabled: true}, "id1", "demo1", true},
+		{"enabled by name", settings.AuditLogsConfig{Enabled: true, Collections: []string{"DEMO1"}}, "id1", "demo1", true},
+		{"enabled by id", settings.AuditLogsConfig{Enabled: true, Collections: []string{"id1"}}, "id1", "demo1", true},
+		{"not listed", settings.AuditLogsConfig{Enabled: true, Collections: []string{"demo2"}}, "id1", "demo1", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.IsCollectionEnabled(s.id, s.colName)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestPasskeysConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.PasskeysConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			settings.PasskeysConfig{},
+			false,
+		},
+		// invalid origins
+		{
+			settings.PasskeysConfig{RPID: "example.com", Origins: []string{"https://example.com", ""}},
+			true,
+		},
+		{
+			settings.PasskeysConfig{RPID: "example.com", Origins: []string{"invalid"}},
+			true,
+		},
+		// too long rp id
+		{
+			settings.PasskeysConfig{RPID: strings.Repeat("a", 256)},
+			true,
+		},
+		// valid data
+		{
+			settings.PasskeysConfig{RPID: "example.com", Origins: []string{"https://example.com", "https://app.example.com:8080"}},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCorsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.CorsConfig
+		expectError bool
+	}{
+		// zero values (disabled)
+		{
+			settings.CorsConfig{},
+			false,
+		},
+		// zero values (enabled)
+		{
+			settings.CorsConfig{Enabled: true},
+			true,
+		},
+		// invalid origins
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"https://example.com", ""}},
+			true,
+		},
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"example.com"}},
+			true,
+		},
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"https://example.com/path"}},
+			true,
+		},
+		// wildcard origin with credentials
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			true,
+		},
+		// invalid methods
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "INVALID"}},
+			true,
+		},
+		// invalid headers
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"X Invalid"}},
+			true,
+		},
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"*"}, ExposedHeaders: []string{""}},
+			true,
+		},
+		// invalid max age
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"*"}, MaxAge: -1},
+			true,
+		},
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"*"}, MaxAge: 86401},
+			true,
+		},
+		// valid data
+		{
+			settings.CorsConfig{Enabled: true, AllowedOrigins: []string{"*"}},
+			false,
+		},
+		{
+			settings.CorsConfig{
+				Enabled:          true,
+				AllowedOrigins:   []string{"https://example.com", "https://*.example.com", "http://localhost:8090"},
+				AllowedMethods:   []string{"GET", "POST"},
+				AllowedHeaders:   []string{"Authorization", "Content-Type"},
+				ExposedHeaders:   []string{"X-Total-Count"},
+				AllowCredentials: true,
+				MaxAge:           600,
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCorsConfigMatchOrigin(t *testing.T) {
+	config := settings.CorsConfig{
+		AllowedOrigins: []string{"https://example.com", "https://*.demo.com"},
+	}
+
+	scenarios := []struct {
+		origin   string
+		expected bool
+	}{
+		{"", false},
+		{"https://example.com", true},
+		{"HTTPS://EXAMPLE.COM", true},
+		{"http://example.com", false},
+		{"https://example.com:8080", false},
+		{"https://sub.example.com", false},
+		{"https://demo.com", false},
+		{"https://app.demo.com", true},
+		{"https://a.b.demo.com", true},
+		{"http://app.demo.com", false},
+		{"https://app.demo.com:8080", false},
+		{"https://evil.com/.demo.com", false},
+		{"https://user@app.demo.com", false},
+		{"https://app.demo.com.evil.com", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.origin, func(t *testing.T) {
+			result := config.MatchOrigin(s.origin)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+
+	anyConfig := settings.CorsConfig{AllowedOrigins: []string{"*"}}
+	if !anyConfig.MatchOrigin("https://anything.com") {
+		t.Fatal("Expected the wildcard origin to match any origin")
+	}
+}
+
+func TestTrustedProxyConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      settings.TrustedProxyConfig
+		expectError bool
+	}{
+		{settings.TrustedProxyConfig{}, false},
+		{settings.TrustedProxyConfig{Cidrs: []string{""}}, true},
+		{settings.TrustedProxyConfig{Cidrs: []string{"invalid"}}, true},
+		{settings.TrustedProxyConfig{Cidrs: []string{"10.0.0.0/33"}}, true},
+		{settings.TrustedProxyConfig{Headers: []string{""}}, true},
+		{settings.TrustedProxyConfig{Headers: []string{"X Real IP"}}, true},
+		{
+			settings.TrustedProxyConfig{
+				Cidrs:   []string{"10.0.0.0/8", "127.0.0.1", "::1", "fd00::/8"},
+				Headers: []string{"CF-Connecting-IP", "X-Real-IP"},
+			},
+			false,
+		},
+	}
+
+	for i, s := range scenarios {
+		result := s.config.Validate()
+
+		if hasErr := result != nil; hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, result)
+		}
+	}
+}
+
+func TestTrustedProxyConfigClientIp(t *testing.T) {
+	config := settings.TrustedProxyConfig{
+		Cidrs:   []string{"10.0.0.0/8", "127.0.0.1", "::1"},
+		Headers: []string{"CF-Connecting-IP", "X-Real-IP"},
+	}
+
+	scenarios := []struct {
+		name       string
+		config     settings.TrustedProxyConfig
+		remoteAddr string
+		headers    map[string][]string
+		expected   string
+	}{
+		{
+			"no trusted proxies (headers are ignored)",
+			settings.TrustedProxyConfig{Headers: []string{"X-Real-IP"}},
+			"1.1.1.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2"}, "X-Real-IP": {"3.3.3.3"}},
+			"1.1.1.1",
+		},
+		{
+			"direct request without headers",
+			config,
+			"1.1.1.1:1000",
+			nil,
+			"1.1.1.1",
+		},
+		{
+			"spoofed headers from non-trusted remote address",
+			config,
+			"1.1.1.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2"}, "CF-Connecting-IP": {"3.3.3.3"}},
+			"1.1.1.1",
+		},
+		{
+			"single trusted proxy",
+			config,
+			"127.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2"}},
+			"2.2.2.2",
+		},
+		{
+			"multiple trusted proxies",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2, 10.0.0.3, 10.0.0.2"}},
+			"2.2.2.2",
+		},
+		{
+			"multiple X-Forwarded-For header lines",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2", "10.0.0.2"}},
+			"2.2.2.2",
+		},
+		{
+			"spoofed left-most X-Forwarded-For entries",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"9.9.9.9, 10.0.0.5, 2.2.2.2, 10.0.0.2"}},
+			"2.2.2.2",
+		},
+		{
+			"invalid X-Forwarded-For entry (the last trusted hop)",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2, invalid, 10.0.0.2"}},
+			"10.0.0.2",
+		},
+		{
+			"invalid right-most X-Forwarded-For entry (the remote address)",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2, invalid"}, "CF-Connecting-IP": {"4.4.4.4"}},
+			"10.0.0.1",
+		},
+		{
+			"empty X-Forwarded-For entry",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"2.2.2.2, , 10.0.0.2"}},
+			"10.0.0.2",
+		},
+		{
+			"all X-Forwarded-For entries are trusted (fallback headers)",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"X-Forwarded-For": {"10.0.0.2"}, "X-Real-IP": {"3.3.3.3"}, "CF-Connecting-IP": {"4.4.4.4"}},
+			"4.4.4.4",
+		},
+		{
+			"invalid fallback header",
+			config,
+			"10.0.0.1:1000",
+			map[string][]string{"CF-Connecting-IP": {"invalid"}, "X-Real-IP": {"3.3.3.3"}},
+			"3.3.3.3",
+		},
+		{
+			"no client headers from a trusted proxy",
+			config,
+			"10.0.0.1:1000",
+			nil,
+			"10.0.0.1",
+		},
+		{
+			"ipv6 trusted proxy",
+			config,
+			"[::1]:1000",
+			map[string][]string{"X-Forwarded-For": {"2001:db8::1"}},
+			"2001:db8::1",
+		},
+		{
+			"ipv4-mapped ipv6 addresses",
+			config,
+			"[::ffff:10.0.0.1]:1000",
+			map[string][]string{"X-Forwarded-For": {"::ffff:2.2.2.2, ::ffff:10.0.0.2"}},
+			"2.2.2.2",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = s.remoteAddr
+			for k, values := range s.headers {
+				for _, v := range values {
+					req.Header.Add(k, v)
+				}
+			}
+
+			if ip := s.config.ClientIp(req); ip != s.expected {
+				t.Fatalf("Expected client ip %q, got %q", s.expected, ip)
+			}
+		})
+	}
+}
+
 func TestAuthProviderConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		config      settings.AuthProviderConfig
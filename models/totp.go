@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ Model = (*TOTP)(nil)
+
+// TOTP defines the authenticator app (TOTP) two-factor
+// authentication settings of a single auth record.
+type TOTP struct {
+	BaseModel
+
+	CollectionId string `db:"collectionId" json:"collectionId"`
+	RecordId     string `db:"recordId" json:"recordId"`
+
+	// Secret is the encrypted base32 TOTP secret.
+	Secret string `db:"secret" json:"-"`
+
+	// Verified indicates whether the enrollment was confirmed with a
+	// valid code, aka. whether the TOTP is required on password auth.
+	Verified bool `db:"verified" json:"verified"`
+
+	// LastUsedStep is the time step counter of the last accepted code
+	// (used to reject already used codes).
+	LastUsedStep int64 `db:"lastUsedStep" json:"-"`
+
+	// RecoveryCodes is the list with the sha256 hashes of the unused recovery codes.
+	RecoveryCodes types.JsonArray[string] `db:"recoveryCodes" json:"-"`
+}
+
+func (m *TOTP) TableName() string {
+	return "_totps"
+}
+
+// RefreshRecoveryCodes replaces the TOTP recovery codes with
+// total new random ones and returns their plain values.
+//
+// Only the recovery codes hashes are stored in the model.
+func (m *TOTP) RefreshRecoveryCodes(total int) []string {
+	codes := make([]string, total)
+	hashes := make(types.JsonArray[string], total)
+
+	for i := range codes {
+		code := security.RandomStringWithAlphabet(10, "abcdefghijklmnopqrstuvwxyz0123456789")
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = security.SHA256(strings.ToLower(codes[i]))
+	}
+
+	m.RecoveryCodes = hashes
+
+	return codes
+}
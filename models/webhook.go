@@ -0,0 +1,74 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ Model = (*Webhook)(nil)
+	_ Model = (*WebhookFailure)(nil)
+)
+
+// list with the supported webhook record events
+const (
+	WebhookEventCreate = "create"
+	WebhookEventUpdate = "update"
+	WebhookEventDelete = "delete"
+)
+
+// WebhookEvents returns a list with all supported webhook events.
+func WebhookEvents() []string {
+	return []string{WebhookEventCreate, WebhookEventUpdate, WebhookEventDelete}
+}
+
+// Webhook defines an outbound webhook subscription
+// for the record changes of a single collection.
+type Webhook struct {
+	BaseModel
+
+	Name         string `db:"name" json:"name"`
+	CollectionId string `db:"collectionId" json:"collectionId"`
+
+	// Events is the list with the subscribed record events
+	// (see [WebhookEvents]).
+	Events types.JsonArray[string] `db:"events" json:"events"`
+
+	// Url is the target url where the signed POST requests are send.
+	Url string `db:"url" json:"url"`
+
+	// Secret is the key used to sign the delivery request body
+	// (it is never returned by the api).
+	Secret string `db:"secret" json:"-"`
+
+	Active bool `db:"active" json:"active"`
+}
+
+func (m *Webhook) TableName() string {
+	return "_webhooks"
+}
+
+// HasEvent checks whether the webhook is subscribed for the provided event.
+func (m *Webhook) HasEvent(event string) bool {
+	return list.ExistInSlice(event, m.Events)
+}
+
+// WebhookFailure defines a dead-letter entry of a webhook delivery
+// that failed permanently (after all retries).
+type WebhookFailure struct {
+	BaseModel
+
+	WebhookId    string        `db:"webhookId" json:"webhookId"`
+	CollectionId string        `db:"collectionId" json:"collectionId"`
+	RecordId     string        `db:"recordId" json:"recordId"`
+	Event        string        `db:"event" json:"event"`
+	Url          string        `db:"url" json:"url"`
+	Payload      types.JsonRaw `db:"payload" json:"payload"`
+	Attempts     int           `db:"attempts" json:"attempts"`
+	StatusCode   int           `db:"statusCode" json:"statusCode"`
+	Error        string        `db:"error" json:"error"`
+}
+
+func (m *WebhookFailure) TableName() string {
+	return "_webhookFailures"
+}
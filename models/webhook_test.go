@@ -0,0 +1,44 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestWebhookTableName(t *testing.T) {
+	t.Parallel()
+
+	m := models.Webhook{}
+	if m.TableName() != "_webhooks" {
+		t.Fatalf("Unexpected table name, got %q", m.TableName())
+	}
+}
+
+func TestWebhookHasEvent(t *testing.T) {
+	t.Parallel()
+
+	m := models.Webhook{Events: []string{models.WebhookEventCreate, models.WebhookEventDelete}}
+
+	scenarios := map[string]bool{
+		models.WebhookEventCreate: true,
+		models.WebhookEventUpdate: false,
+		models.WebhookEventDelete: true,
+		"missing":                 false,
+	}
+
+	for event, expected := range scenarios {
+		if result := m.HasEvent(event); result != expected {
+			t.Errorf("[%s] Expected %v, got %v", event, expected, result)
+		}
+	}
+}
+
+func TestWebhookFailureTableName(t *testing.T) {
+	t.Parallel()
+
+	m := models.WebhookFailure{}
+	if m.TableName() != "_webhookFailures" {
+		t.Fatalf("Unexpected table name, got %q", m.TableName())
+	}
+}
@@ -0,0 +1,184 @@
+package jsvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	defaultBackgroundWorkers   = 4
+	defaultBackgroundQueueSize = 1000
+)
+
+// deferredJobsContextKey is the echo context store key of the background
+// jobs that are scheduled during the request (see [backgroundQueue.deferMiddleware]).
+const deferredJobsContextKey = "__jsvmDeferredJobs"
+
+// deferredJobs holds the background jobs of a single request.
+type deferredJobs struct {
+	mux  sync.Mutex
+	jobs []func() error
+}
+
+// backgroundQueue is a bounded jobs queue processed by a fixed number of workers.
+type backgroundQueue struct {
+	app  core.App
+	jobs chan func() error
+
+	// executors is the vms pool used for running the JS jobs
+	// (it is expected to be set before enqueueing the first job).
+	executors *vmsPool
+
+	wg     sync.WaitGroup
+	mux    sync.RWMutex
+	closed bool
+}
+
+// newBackgroundQueue creates a new background jobs queue and starts its workers.
+func newBackgroundQueue(app core.App, workers int, size int) *backgroundQueue {
+	if workers <= 0 {
+		workers = defaultBackgroundWorkers
+	}
+
+	if size <= 0 {
+		size = defaultBackgroundQueueSize
+	}
+
+	q := &backgroundQueue{
+		app:  app,
+		jobs: make(chan func() error, size),
+	}
+
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer q.wg.Done()
+
+			for job := range q.jobs {
+				q.exec(job)
+			}
+		}()
+	}
+
+	return q
+}
+
+// enqueue schedules the provided job for execution.
+//
+// Returns an error if the queue is full or closed.
+func (q *backgroundQueue) enqueue(job func() error) error {
+	q.mux.RLock()
+	defer q.mux.RUnlock()
+
+	if q.closed {
+		return errors.New("the background jobs queue is closed")
+	}
+
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return errors.New("the background jobs queue is full")
+	}
+}
+
+// schedule enqueues the provided job.
+//
+// If c is a request context handled by [backgroundQueue.deferMiddleware],
+// the enqueue is postponed until after the request response is written.
+func (q *backgroundQueue) schedule(c echo.Context, job func() error) error {
+	if c != nil {
+		if deferred, ok := c.Get(deferredJobsContextKey).(*deferredJobs); ok {
+			deferred.mux.Lock()
+			deferred.jobs = append(deferred.jobs, job)
+			deferred.mux.Unlock()
+
+			return nil
+		}
+	}
+
+	return q.enqueue(job)
+}
+
+// deferMiddleware returns a middleware that enqueues the background jobs
+// scheduled during the request after its response is written.
+func (q *backgroundQueue) deferMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			deferred := &deferredJobs{}
+
+			c.Set(deferredJobsContextKey, deferred)
+
+			// handle the error here so that the error response
+			// is written before enqueueing the jobs
+			if err := next(c); err != nil {
+				c.Echo().HTTPErrorHandler(c, err)
+			}
+
+			deferred.mux.Lock()
+			jobs := deferred.jobs
+			deferred.jobs = nil
+			deferred.mux.Unlock()
+
+			for _, job := range jobs {
+				if err := q.enqueue(job); err != nil {
+					q.app.Logger().Error(
+						"[runInBackground] failed to enqueue background job",
+						slog.String("error", err.Error()),
+					)
+				}
+			}
+
+			return nil
+		}
+	}
+}
+
+// exec executes a single job and logs its error or panic (if any).
+func (q *backgroundQueue) exec(job func() error) {
+	defer func() {
+		if r := recover(); r != nil {
+			q.app.Logger().Error(
+				"[runInBackground] background job panicked",
+				slog.String("panic", fmt.Sprint(r)),
+			)
+		}
+	}()
+
+	if err := job(); err != nil {
+		q.app.Logger().Error(
+			"[runInBackground] failed to execute background job",
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// shutdown stops accepting new jobs and waits for the already
+// enqueued ones to complete or until ctx is done.
+func (q *backgroundQueue) shutdown(ctx context.Context) error {
+	q.mux.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mux.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,153 @@
+package jsvm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestBackgroundQueueBounded(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	q := newBackgroundQueue(app, 1, 1)
+
+	var total atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	if err := q.enqueue(func() error {
+		close(started)
+		<-release
+		total.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	<-started
+
+	// pending
+	if err := q.enqueue(func() error {
+		total.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// full
+	if err := q.enqueue(func() error { return nil }); err == nil {
+		t.Fatal("Expected queue full error")
+	}
+
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := q.shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := total.Load(); v != 2 {
+		t.Fatalf("Expected the pending jobs to be drained, got %d completed jobs", v)
+	}
+
+	// closed
+	if err := q.enqueue(func() error { return nil }); err == nil {
+		t.Fatal("Expected queue closed error")
+	}
+}
+
+func TestBackgroundQueueConcurrency(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	q := newBackgroundQueue(app, 2, 20)
+
+	var running atomic.Int32
+	var maxRunning atomic.Int32
+
+	for i := 0; i < 10; i++ {
+		err := q.enqueue(func() error {
+			current := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				old := maxRunning.Load()
+				if current <= old || maxRunning.CompareAndSwap(old, current) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := q.shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := maxRunning.Load(); v != 2 {
+		t.Fatalf("Expected max 2 concurrently running jobs, got %d", v)
+	}
+}
+
+func TestBackgroundQueuePanic(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	q := newBackgroundQueue(app, 1, 10)
+
+	var total atomic.Int32
+
+	q.enqueue(func() error {
+		panic("test")
+	})
+	q.enqueue(func() error {
+		return errors.New("test")
+	})
+	q.enqueue(func() error {
+		total.Add(1)
+		return nil
+	})
+
+	if err := q.shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := total.Load(); v != 1 {
+		t.Fatalf("Expected the job after the panic to be executed, got %d", v)
+	}
+}
+
+func TestBackgroundQueueShutdownTimeout(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	q := newBackgroundQueue(app, 1, 10)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	q.enqueue(func() error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := q.shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded error, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -161,6 +162,86 @@ func cronBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 	})
 }
 
+// backgroundBinds registers the $app.runInBackground() method.
+//
+// Note: it is expected to be called after the $app global is set.
+func backgroundBinds(vm *goja.Runtime, queue *backgroundQueue) {
+	appObj := vm.Get("$app").ToObject(vm)
+
+	// the Go host objects don't allow assigning new properties
+	// so the method is registered in an intermediate prototype
+	proto := vm.NewObject()
+	proto.SetPrototype(appObj.Prototype())
+	appObj.SetPrototype(proto)
+
+	proto.Set("runInBackground", func(handler goja.Value, args ...goja.Value) error {
+		if handler == nil {
+			return errors.New("[runInBackground] handler must be non-nil")
+		}
+
+		if _, ok := goja.AssertFunction(handler); !ok {
+			return errors.New("[runInBackground] handler must be a function")
+		}
+
+		pr, err := goja.Compile("", "{("+handler.String()+").apply(undefined, __args)}", true)
+		if err != nil {
+			return fmt.Errorf("[runInBackground] failed to compile handler: %w", err)
+		}
+
+		// export the arguments since the job is executed in a different vm
+		jobArgs := make([]any, len(args))
+		for i, arg := range args {
+			jobArgs[i] = arg.Export()
+		}
+
+		return queue.schedule(vmRequestContext(vm), func() error {
+			return queue.executors.run(func(executor *goja.Runtime) error {
+				executor.Set("__args", jobArgs)
+				defer executor.Set("__args", goja.Undefined())
+
+				_, err := executor.RunProgram(pr)
+
+				return err
+			})
+		})
+	})
+}
+
+// vmRequestContext returns the echo context of the request that is
+// currently handled by the vm (aka. the route handler or middleware
+// context or the request hook event HttpContext).
+//
+// Returns nil if the vm is not handling a request.
+func vmRequestContext(vm *goja.Runtime) echo.Context {
+	for _, name := range []string{"__args", "__args2"} {
+		raw := vm.Get(name)
+		if raw == nil {
+			continue
+		}
+
+		args, _ := raw.Export().([]any)
+
+		for _, arg := range args {
+			if c, ok := arg.(echo.Context); ok {
+				return c
+			}
+
+			rv := reflect.Indirect(reflect.ValueOf(arg))
+			if rv.Kind() != reflect.Struct {
+				continue
+			}
+
+			if field := rv.FieldByName("HttpContext"); field.IsValid() && field.CanInterface() {
+				if c, ok := field.Interface().(echo.Context); ok && c != nil {
+					return c
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func routerBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 	loader.Set("routerAdd", func(method string, path string, handler goja.Value, middlewares ...goja.Value) {
 		wrappedMiddlewares, err := wrapMiddlewares(executors, middlewares...)
@@ -469,6 +550,11 @@ func mailsBinds(vm *goja.Runtime) {
 	obj.Set("sendRecordPasswordReset", mails.SendRecordPasswordReset)
 	obj.Set("sendRecordVerification", mails.SendRecordVerification)
 	obj.Set("sendRecordChangeEmail", mails.SendRecordChangeEmail)
+
+	// templates
+	obj.Set("templates", mails.Templates)
+	obj.Set("send", mails.Send)
+	obj.Set("sendWithTransport", mails.SendWithTransport)
 }
 
 func tokensBinds(vm *goja.Runtime) {
@@ -647,7 +733,11 @@ func apisBinds(vm *goja.Runtime) {
 	registerFactoryAsConstructor(vm, "UnauthorizedError", apis.NewUnauthorizedError)
 }
 
-func httpClientBinds(vm *goja.Runtime) {
+// httpClientBinds registers the $http client binds.
+//
+// shutdownCtx is used to abort the pending $http.sendWithRetry calls
+// when the app terminates.
+func httpClientBinds(vm *goja.Runtime, shutdownCtx context.Context) {
 	obj := vm.NewObject()
 	vm.Set("$http", obj)
 
@@ -668,6 +758,12 @@ func httpClientBinds(vm *goja.Runtime) {
 		StatusCode int                     `json:"statusCode"`
 	}
 
+	type sendWithRetryResult struct {
+		sendResult
+
+		Attempts int `json:"attempts"`
+	}
+
 	type sendConfig struct {
 		// Deprecated: consider using Body instead
 		Data map[string]any
@@ -679,7 +775,7 @@ func httpClientBinds(vm *goja.Runtime) {
 		Timeout int // seconds (default to 120)
 	}
 
-	obj.Set("send", func(params map[string]any) (*sendResult, error) {
+	parseSendConfig := func(params map[string]any) sendConfig {
 		config := sendConfig{
 			Method: "GET",
 		}
@@ -712,7 +808,11 @@ func httpClientBinds(vm *goja.Runtime) {
 			config.Timeout = 120
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+		return config
+	}
+
+	send := func(parentCtx context.Context, config sendConfig) (*sendResult, error) {
+		ctx, cancel := context.WithTimeout(parentCtx, time.Duration(config.Timeout)*time.Second)
 		defer cancel()
 
 		var reqBody io.Reader
@@ -798,6 +898,76 @@ func httpClientBinds(vm *goja.Runtime) {
 		}
 
 		return result, nil
+	}
+
+	obj.Set("send", func(params map[string]any) (*sendResult, error) {
+		return send(context.Background(), parseSendConfig(params))
+	})
+
+	// sendWithRetry is similar to send but retries the request
+	// on network error or 5xx response with exponential backoff.
+	//
+	// Additional params:
+	//   - retries - max number of retries after the first attempt (default to 3)
+	//   - backoff - the delay before the first retry in ms, doubled after each retry (default to 500)
+	//   - maxTime - the max total time budget for all attempts in seconds (default to 300)
+	obj.Set("sendWithRetry", func(params map[string]any) (*sendWithRetryResult, error) {
+		config := parseSendConfig(params)
+
+		retries := 3
+		if v, ok := params["retries"]; ok {
+			retries = max(cast.ToInt(v), 0)
+		}
+
+		backoff := 500 * time.Millisecond
+		if v, ok := params["backoff"]; ok {
+			backoff = max(time.Duration(cast.ToInt(v))*time.Millisecond, 0)
+		}
+
+		maxTime := 300 * time.Second
+		if v := cast.ToInt(params["maxTime"]); v > 0 {
+			maxTime = time.Duration(v) * time.Second
+		}
+
+		ctx, cancel := context.WithTimeout(shutdownCtx, maxTime)
+		defer cancel()
+
+		deadline, _ := ctx.Deadline()
+
+		var attempts int
+		for {
+			attempts++
+
+			result, err := send(ctx, config)
+
+			// the total time budget is exceeded or the app is shutting down
+			if err != nil && ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			delay := backoff << (attempts - 1)
+
+			canRetry := attempts <= retries &&
+				delay >= 0 && // overflow
+				time.Now().Add(delay).Before(deadline)
+
+			if canRetry && (err != nil || result.StatusCode >= 500) {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			return &sendWithRetryResult{sendResult: *result, Attempts: attempts}, nil
+		}
 	})
 }
 
@@ -1,6 +1,7 @@
 package jsvm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,11 +11,13 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/dop251/goja"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
@@ -322,7 +325,7 @@ func TestBaseBindsMailerMessage(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expected := `{"from":{"Name":"test_from","Address":"test_from@example.com"},"to":[{"Name":"test_to1","Address":"test_to1@example.com"},{"Name":"test_to2","Address":"test_to2@example.com"}],"bcc":[{"Name":"test_bcc1","Address":"test_bcc1@example.com"},{"Name":"test_bcc2","Address":"test_bcc2@example.com"}],"cc":[{"Name":"test_cc1","Address":"test_cc1@example.com"},{"Name":"test_cc2","Address":"test_cc2@example.com"}],"subject":"test_subject","html":"test_html","text":"test_text","headers":{"header1":"a","header2":"b"},"attachments":null}`
+	expected := `{"from":{"Name":"test_from","Address":"test_from@example.com"},"to":[{"Name":"test_to1","Address":"test_to1@example.com"},{"Name":"test_to2","Address":"test_to2@example.com"}],"bcc":[{"Name":"test_bcc1","Address":"test_bcc1@example.com"},{"Name":"test_bcc2","Address":"test_bcc2@example.com"}],"cc":[{"Name":"test_cc1","Address":"test_cc1@example.com"},{"Name":"test_cc2","Address":"test_cc2@example.com"}],"subject":"test_subject","html":"test_html","text":"test_text","headers":{"header1":"a","header2":"b"},"attachments":null,"inlineAttachments":null}`
 
 	if string(raw) != expected {
 		t.Fatalf("Expected \n%s, \ngot \n%s", expected, raw)
@@ -595,7 +598,7 @@ func TestMailsBindsCount(t *testing.T) {
 	vm := goja.New()
 	mailsBinds(vm)
 
-	testBindsCount(vm, "$mails", 4, t)
+	testBindsCount(vm, "$mails", 7, t)
 }
 
 func TestMailsBinds(t *testing.T) {
@@ -639,6 +642,19 @@ func TestMailsBinds(t *testing.T) {
 		if (!$app.testMailer.lastMessage.html.includes("/_/#/auth/confirm-email-change/")) {
 			throw new Error("Expected record email change email")
 		}
+
+		$mails.templates.register("jsvm_test", "bg", {
+			subject: "Здравей {{.name}}",
+			html:    "<p>{{.name}}</p>",
+		});
+		$mails.send($app, "jsvm_test", "bg-BG", [{ address: "test@example.com" }], { name: "John" });
+		$mails.templates.unregister("jsvm_test", "bg");
+		if (
+			$app.testMailer.lastMessage.subject != "Здравей John" ||
+			!$app.testMailer.lastMessage.html.includes(">John</p>")
+		) {
+			throw new Error("Expected the registered template email")
+		}
 	`)
 	if vmErr != nil {
 		t.Fatal(vmErr)
@@ -1152,10 +1168,10 @@ func TestHttpClientBindsCount(t *testing.T) {
 	defer app.Cleanup()
 
 	vm := goja.New()
-	httpClientBinds(vm)
+	httpClientBinds(vm, context.Background())
 
 	testBindsCount(vm, "this", 2, t) // + FormData
-	testBindsCount(vm, "$http", 1, t)
+	testBindsCount(vm, "$http", 2, t)
 }
 
 func TestHttpClientBindsSend(t *testing.T) {
@@ -1202,7 +1218,7 @@ func TestHttpClientBindsSend(t *testing.T) {
 
 	vm := goja.New()
 	baseBinds(vm)
-	httpClientBinds(vm)
+	httpClientBinds(vm, context.Background())
 	vm.Set("testUrl", server.URL)
 
 	_, err := vm.RunString(`
@@ -1329,6 +1345,163 @@ func TestHttpClientBindsSend(t *testing.T) {
 	}
 }
 
+func TestHttpClientBindsSendWithRetry(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+
+	// start a test server that fails the first "fails" requests for each key
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		key := req.URL.Query().Get("key")
+		fails, _ := strconv.Atoi(req.URL.Query().Get("fails"))
+		status, _ := strconv.Atoi(req.URL.Query().Get("status"))
+		if status == 0 {
+			status = 503
+		}
+
+		mu.Lock()
+		attempts[key]++
+		current := attempts[key]
+		mu.Unlock()
+
+		if current <= fails {
+			res.WriteHeader(status)
+			return
+		}
+
+		bodyRaw, _ := io.ReadAll(req.Body)
+		defer req.Body.Close()
+
+		res.Write([]byte(`{"body":"` + string(bodyRaw) + `"}`))
+	}))
+	defer server.Close()
+
+	// closed server for the network errors
+	closedServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+	closedServer.Close()
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scenarios := []struct {
+		name               string
+		ctx                context.Context
+		params             string
+		expectError        bool
+		expectedStatus     int
+		expectedAttempts   int
+		expectedServerHits int
+	}{
+		{
+			"success on first attempt",
+			context.Background(),
+			`{url: testUrl + "?key=test1", method: "post", body: "123", backoff: 10}`,
+			false,
+			200,
+			1,
+			1,
+		},
+		{
+			"fails then succeeds",
+			context.Background(),
+			`{url: testUrl + "?key=test2&fails=2", method: "post", body: "123", backoff: 10}`,
+			false,
+			200,
+			3,
+			3,
+		},
+		{
+			"exhausted retries",
+			context.Background(),
+			`{url: testUrl + "?key=test3&fails=10", retries: 2, backoff: 10}`,
+			false,
+			503,
+			3,
+			3,
+		},
+		{
+			"non 5xx error response",
+			context.Background(),
+			`{url: testUrl + "?key=test4&fails=10&status=400", backoff: 10}`,
+			false,
+			400,
+			1,
+			1,
+		},
+		{
+			"exceeded total time budget",
+			context.Background(),
+			`{url: testUrl + "?key=test5&fails=10", retries: 10, backoff: 2000, maxTime: 1}`,
+			false,
+			503,
+			1,
+			1,
+		},
+		{
+			"network error",
+			context.Background(),
+			`{url: closedUrl, retries: 2, backoff: 10}`,
+			true,
+			0,
+			0,
+			0,
+		},
+		{
+			"canceled shutdown context",
+			canceledCtx,
+			`{url: testUrl + "?key=test7", backoff: 10}`,
+			true,
+			0,
+			0,
+			0,
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			vm := goja.New()
+			baseBinds(vm)
+			httpClientBinds(vm, s.ctx)
+			vm.Set("testUrl", server.URL)
+			vm.Set("closedUrl", closedServer.URL)
+
+			result, err := vm.RunString(`$http.sendWithRetry(` + s.params + `)`)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			mu.Lock()
+			hits := attempts["test"+strconv.Itoa(i+1)]
+			mu.Unlock()
+
+			if hits != s.expectedServerHits {
+				t.Fatalf("Expected %d server hits, got %d", s.expectedServerHits, hits)
+			}
+
+			if hasErr {
+				return
+			}
+
+			obj := result.ToObject(vm)
+
+			if v := obj.Get("statusCode").ToInteger(); v != int64(s.expectedStatus) {
+				t.Fatalf("Expected statusCode %d, got %d", s.expectedStatus, v)
+			}
+
+			if v := obj.Get("attempts").ToInteger(); v != int64(s.expectedAttempts) {
+				t.Fatalf("Expected attempts %d, got %d", s.expectedAttempts, v)
+			}
+
+			if s.expectedStatus == 200 {
+				if v := obj.Get("json").ToObject(vm).Get("body").String(); v != "123" {
+					t.Fatalf("Expected the request body to be resent on each attempt, got %q", v)
+				}
+			}
+		})
+	}
+}
+
 func TestCronBindsCount(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -1346,7 +1519,7 @@ func TestHooksBindsCount(t *testing.T) {
 	vm := goja.New()
 	hooksBinds(app, vm, nil)
 
-	testBindsCount(vm, "this", 88, t)
+	testBindsCount(vm, "this", 95, t)
 }
 
 func TestHooksBinds(t *testing.T) {
@@ -1431,6 +1604,163 @@ func TestHooksBinds(t *testing.T) {
 	}
 }
 
+func TestBackgroundBindsCount(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	vm := goja.New()
+	vm.Set("$app", app)
+	backgroundBinds(vm, nil)
+
+	// no new globals
+	testBindsCount(vm, "this", 1, t)
+
+	v, err := vm.RunString(`typeof $app.runInBackground`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "function" {
+		t.Fatalf("Expected $app.runInBackground function, got %q", v.String())
+	}
+}
+
+func TestBackgroundBinds(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	queue := newBackgroundQueue(app, 1, 10)
+	defer queue.shutdown(context.Background())
+
+	results := make(chan any, 10)
+
+	vmFactory := func() *goja.Runtime {
+		vm := goja.New()
+		baseBinds(vm)
+		vm.Set("$app", app)
+		backgroundBinds(vm, queue)
+		vm.Set("done", func(v any) {
+			results <- v
+		})
+		vm.Set("goPanic", func() {
+			panic("test panic")
+		})
+		return vm
+	}
+
+	queue.executors = newPool(1, vmFactory)
+
+	vm := vmFactory()
+
+	_, err := vm.RunString(`
+		$app.runInBackground((a, b) => {
+			done(a + b)
+		}, 1, 2)
+
+		// panicking and failing jobs shouldn't affect the other jobs
+		$app.runInBackground(() => {
+			goPanic()
+		})
+		$app.runInBackground(() => {
+			throw new Error("test error")
+		})
+
+		$app.runInBackground((data) => {
+			done(data.title)
+		}, { title: "after" })
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []any{int64(3), "after"}
+	for _, v := range expected {
+		select {
+		case r := <-results:
+			if r != v {
+				t.Fatalf("Expected job result %v (%T), got %v (%T)", v, v, r, r)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected job result %v to be received", v)
+		}
+	}
+
+	// invalid handler
+	if _, err := vm.RunString(`$app.runInBackground("test")`); err == nil {
+		t.Fatal("Expected non-function handler error")
+	}
+
+	// $app should be still usable as Go core.App argument
+	vm.Set("isApp", func(a core.App) bool {
+		return a == app
+	})
+	v, err := vm.RunString(`isApp($app)`)
+	if err != nil || !v.ToBoolean() {
+		t.Fatalf("Expected $app to be exported as the app instance, got %v (%v)", v, err)
+	}
+}
+
+func TestBackgroundBindsDeferredUntilResponse(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	queue := newBackgroundQueue(app, 1, 10)
+	defer queue.shutdown(context.Background())
+
+	results := make(chan string, 10)
+
+	vmFactory := func() *goja.Runtime {
+		vm := goja.New()
+		baseBinds(vm)
+		vm.Set("$app", app)
+		backgroundBinds(vm, queue)
+		vm.Set("done", func(v string) {
+			results <- v
+		})
+		vm.Set("pendingResults", func() int {
+			time.Sleep(50 * time.Millisecond) // give time to the workers
+			return len(results)
+		})
+		return vm
+	}
+
+	queue.executors = newPool(1, vmFactory)
+
+	loader := vmFactory()
+
+	handler, err := wrapHandler(queue.executors, loader.ToValue(`(c) => {
+		$app.runInBackground((v) => done(v), "job")
+
+		if (pendingResults() > 0) {
+			throw new Error("Expected the job to be deferred until after the response")
+		}
+
+		return c.string(200, "test")
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Pre(queue.deferMiddleware())
+	e.GET("/test", handler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if rec.Code != 200 || rec.Body.String() != "test" {
+		t.Fatalf("Expected 200 test response, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	select {
+	case v := <-results:
+		if v != "job" {
+			t.Fatalf("Expected job result, got %q", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the deferred job to be executed after the response")
+	}
+}
+
 func TestRouterBindsCount(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -61,6 +61,41 @@ declare function cronAdd(
  */
 declare function cronRemove(jobId: string): void;
 
+// -------------------------------------------------------------------
+// backgroundBinds
+// -------------------------------------------------------------------
+
+interface BackgroundJobs {
+  /**
+   * RunInBackground schedules the handler for execution on the background
+   * jobs worker pool without blocking the current request or hook.
+   *
+   * Similar to the other hook handlers, the handler is executed in a
+   * different vm and it cannot access the outer scope variables
+   * (use the args to pass any data to the handler).
+   *
+   * When called from a route handler, middleware or request hook, the job
+   * is enqueued after the request response is written.
+   *
+   * The handler errors and panics are only logged and the pending
+   * jobs are drained on the app graceful shutdown.
+   * Throws an error if the jobs queue is full (for the jobs scheduled
+   * during a request the error is only logged).
+   *
+   * Example:
+   *
+   * ` + "```" + `js
+   * onRecordAfterCreateRequest((e) => {
+   *     $app.runInBackground((recordId) => {
+   *         const record = $app.dao().findRecordById("posts", recordId)
+   *         // ...
+   *     }, e.record.id)
+   * }, "posts")
+   * ` + "```" + `
+   */
+  runInBackground(handler: (...args: Array<any>) => void, ...args: Array<any>): void
+}
+
 // -------------------------------------------------------------------
 // routerBinds
 // -------------------------------------------------------------------
@@ -158,7 +193,8 @@ type excludeHooks<Type> = {
 type CoreApp = excludeHooks<ORIGINAL_CORE_APP>
 
 // pocketbase.PocketBase without the on* hook methods
-type PocketBase = excludeHooks<ORIGINAL_POCKETBASE>
+// (extended with the $app.runInBackground() method)
+type PocketBase = excludeHooks<ORIGINAL_POCKETBASE> & BackgroundJobs
 
 /**
  * ` + "`$app`" + ` is the current running PocketBase instance that is globally
@@ -1051,6 +1087,47 @@ declare namespace $http {
     raw:        string,
     json:       any,
   };
+
+  /**
+   * Sends a single HTTP request similar to [$http.send] but retries it
+   * on network error or 5xx response with exponential backoff.
+   *
+   * The pending attempts are aborted when the app terminates.
+   *
+   * Example:
+   *
+   * ` + "```" + `js
+   * const res = $http.sendWithRetry({
+   *     url:     "https://example.com",
+   *     retries: 5,
+   *     backoff: 200,
+   *     maxTime: 30,
+   * })
+   *
+   * console.log(res.statusCode) // the last attempt response HTTP status code
+   * console.log(res.attempts)   // the total number of sent requests
+   * ` + "```" + `
+   */
+  function sendWithRetry(config: {
+    url:      string,
+    body?:    string|FormData,
+    method?:  string, // default to "GET"
+    headers?: { [key:string]: string },
+    timeout?: number, // per attempt in seconds, default to 120
+    retries?: number, // default to 3
+    backoff?: number, // initial retry delay in ms (doubled after each retry), default to 500
+    maxTime?: number, // total time budget in seconds, default to 300
+
+    // deprecated, please use body instead
+    data?: { [key:string]: any },
+  }): {
+    statusCode: number,
+    headers:    { [key:string]: Array<string> },
+    cookies:    { [key:string]: http.Cookie },
+    raw:        string,
+    json:       any,
+    attempts:   number,
+  };
 }
 
 // -------------------------------------------------------------------
@@ -1060,6 +1137,9 @@ declare namespace $http {
 /**
  * Migrate defines a single migration upgrade/downgrade action.
  *
+ * The down action is invoked when the migration is reverted with
+ * ` + "`" + `migrate down [n]` + "`" + ` (reverting a migration without down action fails).
+ *
  * _Note that this method is available only in pb_migrations context._
  *
  * @group PocketBase
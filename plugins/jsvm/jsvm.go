@@ -10,10 +10,12 @@ package jsvm
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -29,6 +31,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	m "github.com/pocketbase/pocketbase/migrations"
 	"github.com/pocketbase/pocketbase/plugins/jsvm/internal/types/generated"
@@ -64,6 +67,13 @@ type Config struct {
 	// HookdsDir file ending in ".pb.js" or ".pb.ts" (the last one is to enforce IDE linters).
 	HooksFilesPattern string
 
+	// TypeScript enables the transpilation of the ".ts" hooks and migrations
+	// files to plain JS (using esbuild) before their execution.
+	//
+	// Note that the transpilation only strips the TypeScript types and
+	// doesn't perform type checking (use your IDE or tsc for that).
+	TypeScript bool
+
 	// HooksPoolSize specifies how many goja.Runtime instances to prewarm
 	// and keep for the JS app hooks gorotines execution.
 	//
@@ -71,6 +81,16 @@ type Config struct {
 	// on every fired goroutine.
 	HooksPoolSize int
 
+	// BackgroundWorkers specifies the max number of concurrently executed
+	// runInBackground() jobs (default to 4).
+	BackgroundWorkers int
+
+	// BackgroundQueueSize specifies the max number of pending
+	// runInBackground() jobs (default to 1000).
+	//
+	// Scheduling a new job when the queue is full throws an error.
+	BackgroundQueueSize int
+
 	// MigrationsDir specifies the JS migrations directory.
 	//
 	// If not set it fallbacks to a relative "pb_data/../pb_migrations" directory.
@@ -111,6 +131,14 @@ func MustRegister(app core.App, config Config) {
 func Register(app core.App, config Config) error {
 	p := &plugin{app: app, config: config}
 
+	var cancelShutdownCtx context.CancelFunc
+	p.shutdownCtx, cancelShutdownCtx = context.WithCancel(context.Background())
+
+	p.app.OnTerminate().Add(func(e *core.TerminateEvent) error {
+		cancelShutdownCtx()
+		return nil
+	})
+
 	if p.config.HooksDir == "" {
 		p.config.HooksDir = filepath.Join(app.DataDir(), "../pb_hooks")
 	}
@@ -154,6 +182,9 @@ func Register(app core.App, config Config) error {
 type plugin struct {
 	app    core.App
 	config Config
+
+	// shutdownCtx is canceled when the app terminates
+	shutdownCtx context.Context
 }
 
 // registerMigrations registers the JS migrations loader.
@@ -177,7 +208,7 @@ func (p *plugin) registerMigrations() error {
 		securityBinds(vm)
 		osBinds(vm)
 		filepathBinds(vm)
-		httpClientBinds(vm)
+		httpClientBinds(vm, p.shutdownCtx)
 
 		vm.Set("migrate", func(up, down func(db dbx.Builder) error) {
 			m.AppMigrations.Register(up, down, file)
@@ -187,7 +218,12 @@ func (p *plugin) registerMigrations() error {
 			p.config.OnInit(vm)
 		}
 
-		_, err := vm.RunString(string(content))
+		code, err := p.fileCode(file, content)
+		if err != nil {
+			return fmt.Errorf("failed to transpile migration %s: %w", file, err)
+		}
+
+		_, err = vm.RunScript(file, code)
 		if err != nil {
 			return fmt.Errorf("failed to run migration %s: %w", file, err)
 		}
@@ -246,6 +282,33 @@ func (p *plugin) registerHooks() error {
 	// safe to be shared across multiple vms
 	requireRegistry := new(require.Registry)
 	templateRegistry := template.NewRegistry()
+	backgroundJobs := newBackgroundQueue(p.app, p.config.BackgroundWorkers, p.config.BackgroundQueueSize)
+
+	p.app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+		e.Router.Pre(backgroundJobs.deferMiddleware())
+		return nil
+	})
+
+	// drain the pending background jobs after the server shutdown
+	// but before canceling the shutdownCtx
+	p.app.OnTerminate().PreAdd(func(e *core.TerminateEvent) error {
+		deadline := e.ShutdownDeadline
+		if deadline.IsZero() {
+			deadline = time.Now().Add(apis.DefaultShutdownTimeout)
+		}
+
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		defer cancel()
+
+		if err := backgroundJobs.shutdown(ctx); err != nil {
+			p.app.Logger().Warn(
+				"[runInBackground] not all background jobs completed before the shutdown deadline",
+				slog.String("error", err.Error()),
+			)
+		}
+
+		return nil
+	})
 
 	sharedBinds := func(vm *goja.Runtime) {
 		requireRegistry.Enable(vm)
@@ -259,12 +322,13 @@ func (p *plugin) registerHooks() error {
 		securityBinds(vm)
 		osBinds(vm)
 		filepathBinds(vm)
-		httpClientBinds(vm)
+		httpClientBinds(vm, p.shutdownCtx)
 		formsBinds(vm)
 		apisBinds(vm)
 		mailsBinds(vm)
 
 		vm.Set("$app", p.app)
+		backgroundBinds(vm, backgroundJobs)
 		vm.Set("$template", templateRegistry)
 		vm.Set("__hooks", absHooksDir)
 
@@ -279,6 +343,7 @@ func (p *plugin) registerHooks() error {
 		sharedBinds(executor)
 		return executor
 	})
+	backgroundJobs.executors = executors
 
 	// initialize the loader vm
 	loader := goja.New()
@@ -301,7 +366,12 @@ func (p *plugin) registerHooks() error {
 				}
 			}()
 
-			_, err := loader.RunString(string(content))
+			code, err := p.fileCode(file, content)
+			if err != nil {
+				panic(err)
+			}
+
+			_, err = loader.RunScript(file, code)
 			if err != nil {
 				panic(err)
 			}
@@ -311,6 +381,16 @@ func (p *plugin) registerHooks() error {
 	return nil
 }
 
+// fileCode returns the JS code of the provided hooks or migration file
+// (transpiling it first if it is a TypeScript file and TypeScript is enabled).
+func (p *plugin) fileCode(file string, content []byte) (string, error) {
+	if p.config.TypeScript && isTypeScriptFile(file) {
+		return transpileTypeScript(file, content)
+	}
+
+	return string(content), nil
+}
+
 // normalizeExceptions wraps the provided error handler and returns a new one
 // with extracted goja exception error value for consistency when throwing or returning errors.
 func (p *plugin) normalizeServeExceptions(oldErrorHandler echo.HTTPErrorHandler) echo.HTTPErrorHandler {
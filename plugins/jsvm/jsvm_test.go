@@ -0,0 +1,233 @@
+package jsvm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestTypeScriptHooks(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		typeScript    bool
+		content       string
+		expectPanic   string
+		expectedTitle string
+	}{
+		{
+			"TypeScript disabled",
+			false,
+			`onRecordBeforeCreateRequest((e: core.RecordCreateEvent) => { e.record.set("title", "from_ts") }, "demo2")`,
+			"test.pb.ts",
+			"",
+		},
+		{
+			"TypeScript syntax error",
+			true,
+			"const a: number = 1;\nonRecordBeforeCreateRequest((e: core.RecordCreateEvent) => {, \"demo2\")",
+			"test.pb.ts:2:",
+			"",
+		},
+		{
+			"valid TypeScript hook",
+			true,
+			`
+				interface Data {
+					title: string
+				}
+
+				const data: Data = { title: "from_ts" }
+
+				onRecordBeforeCreateRequest((e: core.RecordCreateEvent): void => {
+					e.record.set("title", ("from_" + "ts") as string)
+				}, "demo2")
+			`,
+			"",
+			"from_ts",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			hooksDir := t.TempDir()
+
+			if err := os.WriteFile(filepath.Join(hooksDir, "test.pb.ts"), []byte(s.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var panicErr string
+
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panicErr = fmt.Sprint(r)
+					}
+				}()
+
+				err := Register(app, Config{
+					HooksDir:      hooksDir,
+					MigrationsDir: t.TempDir(),
+					TypesDir:      t.TempDir(),
+					TypeScript:    s.typeScript,
+					HooksPoolSize: 1,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			if s.expectPanic != "" {
+				if !strings.Contains(panicErr, s.expectPanic) {
+					t.Fatalf("Expected panic with %q, got %q", s.expectPanic, panicErr)
+				}
+				return
+			}
+
+			if panicErr != "" {
+				t.Fatalf("Unexpected panic: %v", panicErr)
+			}
+
+			collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record := models.NewRecord(collection)
+
+			err = app.OnRecordBeforeCreateRequest().Trigger(&core.RecordCreateEvent{
+				BaseCollectionEvent: core.BaseCollectionEvent{Collection: collection},
+				Record:              record,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if title := record.GetString("title"); title != s.expectedTitle {
+				t.Fatalf("Expected title %q, got %q", s.expectedTitle, title)
+			}
+		})
+	}
+}
+
+func TestRunInBackgroundDrainOnTerminate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+
+	hooksDir := t.TempDir()
+
+	content := `
+		onRecordBeforeCreateRequest((e) => {
+			$app.runInBackground((title) => {
+				sleep(100)
+				$app.store().set("background_test", title)
+			}, e.record.getString("title"))
+		}, "demo2")
+	`
+	if err := os.WriteFile(filepath.Join(hooksDir, "test.pb.js"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Register(app, Config{
+		HooksDir:      hooksDir,
+		MigrationsDir: t.TempDir(),
+		TypesDir:      t.TempDir(),
+		HooksPoolSize: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("title", "background")
+
+	err = app.OnRecordBeforeCreateRequest().Trigger(&core.RecordCreateEvent{
+		BaseCollectionEvent: core.BaseCollectionEvent{Collection: collection},
+		Record:              record,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the hook shouldn't wait for the job
+	if v := app.Store().Get("background_test"); v != nil {
+		t.Fatalf("Expected the background job to be still running, got %v", v)
+	}
+
+	app.Cleanup() // triggers the app OnTerminate hook
+
+	if v := app.Store().Get("background_test"); v != "background" {
+		t.Fatalf("Expected the background job to be completed on terminate, got %v", v)
+	}
+}
+
+func TestTranspileTypeScriptCache(t *testing.T) {
+	content := []byte(`const a: number = 1`)
+
+	code1, err := transpileTypeScript("cache_test.ts", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(code1, ": number") {
+		t.Fatalf("Expected the type annotations to be stripped, got\n%s", code1)
+	}
+
+	cacheKeys := len(transpiledCache.GetAll())
+
+	code2, err := transpileTypeScript("cache_test.ts", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code1 != code2 {
+		t.Fatalf("Expected the same transpiled code, got\n%s\nvs\n%s", code1, code2)
+	}
+
+	if total := len(transpiledCache.GetAll()); total != cacheKeys {
+		t.Fatalf("Expected %d cached files, got %d", cacheKeys, total)
+	}
+
+	// different content should be cached separately
+	if _, err := transpileTypeScript("cache_test.ts", []byte(`const b: string = "b"`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := len(transpiledCache.GetAll()); total != cacheKeys+1 {
+		t.Fatalf("Expected %d cached files, got %d", cacheKeys+1, total)
+	}
+}
+
+func TestIsTypeScriptFile(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		expected bool
+	}{
+		{"test.js", false},
+		{"test.pb.js", false},
+		{"types.d.ts", false},
+		{"test.ts", true},
+		{"test.pb.ts", true},
+		{"TEST.PB.TS", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := isTypeScriptFile(s.name); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
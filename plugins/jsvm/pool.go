@@ -62,12 +62,19 @@ func (p *vmsPool) run(call func(vm *goja.Runtime) error) error {
 		return call(p.factory())
 	}
 
-	execErr := call(freeItem.vm)
+	defer func() {
+		// "free" the vm
+		freeItem.mux.Lock()
+		defer freeItem.mux.Unlock()
 
-	// "free" the vm
-	freeItem.mux.Lock()
-	freeItem.busy = false
-	freeItem.mux.Unlock()
+		freeItem.busy = false
 
-	return execErr
+		// replace the vm since it could be left in an inconsistent state
+		if r := recover(); r != nil {
+			freeItem.vm = p.factory()
+			panic(r)
+		}
+	}()
+
+	return call(freeItem.vm)
 }
@@ -0,0 +1,84 @@
+package jsvm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// transpiledCacheLimit is the max number of transpiled files to keep in the cache.
+const transpiledCacheLimit = 500
+
+// transpiledCache holds the previously transpiled TypeScript files
+// (the key is the hash of the file name and content).
+var transpiledCache = store.New[string](nil)
+
+// isTypeScriptFile reports whether the file name has a TypeScript extension.
+func isTypeScriptFile(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".ts") &&
+		!strings.HasSuffix(strings.ToLower(name), ".d.ts")
+}
+
+// transpileTypeScript transpiles the provided TypeScript file content to
+// plain JS that could be executed by the goja runtime.
+//
+// The generated JS includes an inline source map so that the runtime
+// exceptions could be reported with the original file lines.
+//
+// Note that esbuild only strips the type annotations without type checking,
+// aka. the returned error is only for syntax errors in the source file.
+func transpileTypeScript(name string, content []byte) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(content)
+	cacheKey := hex.EncodeToString(h.Sum(nil))
+
+	if transpiledCache.Has(cacheKey) {
+		return transpiledCache.Get(cacheKey), nil
+	}
+
+	result := api.Transform(string(content), api.TransformOptions{
+		Loader:     api.LoaderTS,
+		Sourcefile: name,
+		Sourcemap:  api.SourceMapInline,
+		Target:     api.ES2017,
+		LogLevel:   api.LogLevelSilent,
+	})
+
+	if len(result.Errors) > 0 {
+		errs := make([]error, 0, len(result.Errors))
+		for _, msg := range result.Errors {
+			errs = append(errs, formatTranspileMessage(name, msg))
+		}
+		return "", errors.Join(errs...)
+	}
+
+	code := string(result.Code)
+
+	transpiledCache.SetIfLessThanLimit(cacheKey, code, transpiledCacheLimit)
+
+	return code, nil
+}
+
+// formatTranspileMessage formats a single esbuild message
+// in the "file:line:column: text" format.
+func formatTranspileMessage(name string, msg api.Message) error {
+	if msg.Location == nil {
+		return fmt.Errorf("%s: %s", name, msg.Text)
+	}
+
+	return fmt.Errorf(
+		"%s:%d:%d: %s\n    %s",
+		name,
+		msg.Location.Line,
+		msg.Location.Column+1, // esbuild columns are 0-based
+		msg.Text,
+		msg.Location.LineText,
+	)
+}
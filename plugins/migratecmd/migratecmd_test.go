@@ -44,12 +44,15 @@ migrate((db) => {
     "options": {
       "allowEmailAuth": false,
       "allowOAuth2Auth": false,
+      "allowPasskeyAuth": false,
+      "allowRefreshTokens": false,
       "allowUsernameAuth": false,
       "exceptEmailDomains": null,
       "manageRule": "created > 0",
       "minPasswordLength": 20,
       "onlyEmailDomains": null,
       "onlyVerified": false,
+      "refreshOAuth2Tokens": false,
       "requireEmail": false
     }
   });
@@ -98,12 +101,15 @@ func init() {
 			"options": {
 				"allowEmailAuth": false,
 				"allowOAuth2Auth": false,
+				"allowPasskeyAuth": false,
+				"allowRefreshTokens": false,
 				"allowUsernameAuth": false,
 				"exceptEmailDomains": null,
 				"manageRule": "created > 0",
 				"minPasswordLength": 20,
 				"onlyEmailDomains": null,
 				"onlyVerified": false,
+				"refreshOAuth2Tokens": false,
 				"requireEmail": false
 			}
 		}` + "`" + `
@@ -226,12 +232,15 @@ migrate((db) => {
     "options": {
       "allowEmailAuth": false,
       "allowOAuth2Auth": false,
+      "allowPasskeyAuth": false,
+      "allowRefreshTokens": false,
       "allowUsernameAuth": false,
       "exceptEmailDomains": null,
       "manageRule": "created > 0",
       "minPasswordLength": 20,
       "onlyEmailDomains": null,
       "onlyVerified": false,
+      "refreshOAuth2Tokens": false,
       "requireEmail": false
     }
   });
@@ -284,12 +293,15 @@ func init() {
 			"options": {
 				"allowEmailAuth": false,
 				"allowOAuth2Auth": false,
+				"allowPasskeyAuth": false,
+				"allowRefreshTokens": false,
 				"allowUsernameAuth": false,
 				"exceptEmailDomains": null,
 				"manageRule": "created > 0",
 				"minPasswordLength": 20,
 				"onlyEmailDomains": null,
 				"onlyVerified": false,
+				"refreshOAuth2Tokens": false,
 				"requireEmail": false
 			}
 		}` + "`" + `
@@ -414,7 +426,9 @@ migrate((db) => {
     "options": {
       "min": null,
       "max": null,
-      "pattern": "` + "`" + `test backtick` + "`" + `123"
+      "pattern": "` + "`" + `test backtick` + "`" + `123",
+      "autogenerateLength": 0,
+      "autogenerateAlphabet": ""
     }
   }))
 
@@ -448,12 +462,15 @@ migrate((db) => {
   collection.options = {
     "allowEmailAuth": false,
     "allowOAuth2Auth": false,
+    "allowPasskeyAuth": false,
+    "allowRefreshTokens": false,
     "allowUsernameAuth": false,
     "exceptEmailDomains": null,
     "manageRule": "created > 0",
     "minPasswordLength": 20,
     "onlyEmailDomains": null,
     "onlyVerified": false,
+    "refreshOAuth2Tokens": false,
     "requireEmail": false
   }
   collection.indexes = [
@@ -559,7 +576,9 @@ func init() {
 			"options": {
 				"min": null,
 				"max": null,
-				"pattern": ` + "\"` + \"`\" + `test backtick` + \"`\" + `123\"" + `
+				"pattern": ` + "\"` + \"`\" + `test backtick` + \"`\" + `123\"" + `,
+				"autogenerateLength": 0,
+				"autogenerateAlphabet": ""
 			}
 		}` + "`" + `), new_f4_name); err != nil {
 			return err
@@ -611,12 +630,15 @@ func init() {
 		if err := json.Unmarshal([]byte(` + "`" + `{
 			"allowEmailAuth": false,
 			"allowOAuth2Auth": false,
+			"allowPasskeyAuth": false,
+			"allowRefreshTokens": false,
 			"allowUsernameAuth": false,
 			"exceptEmailDomains": null,
 			"manageRule": "created > 0",
 			"minPasswordLength": 20,
 			"onlyEmailDomains": null,
 			"onlyVerified": false,
+			"refreshOAuth2Tokens": false,
 			"requireEmail": false
 		}` + "`" + `), &options); err != nil {
 			return err
@@ -839,3 +861,70 @@ func TestAutomigrateCollectionNoChanges(t *testing.T) {
 		}
 	}
 }
+
+func TestAutomigrateCollectionIndexPredicateChange(t *testing.T) {
+	scenarios := []struct {
+		lang string
+	}{
+		{
+			migratecmd.TemplateLangJS,
+		},
+		{
+			migratecmd.TemplateLangGo,
+		},
+	}
+
+	for i, s := range scenarios {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		migrationsDir := filepath.Join(app.DataDir(), "_test_migrations")
+
+		migratecmd.MustRegister(app, nil, migratecmd.Config{
+			TemplateLang: s.lang,
+			Automigrate:  true,
+			Dir:          migrationsDir,
+		})
+
+		oldIndex := "CREATE UNIQUE INDEX idx_slug ON test123 (slug) WHERE slug != ''"
+		newIndex := "CREATE UNIQUE INDEX idx_slug ON test123 (slug) WHERE slug != '' AND trashed = 0"
+
+		// create dummy collection
+		collection := &models.Collection{}
+		collection.Name = "test123"
+		collection.Type = models.CollectionTypeBase
+		collection.Schema.AddField(&schema.SchemaField{Name: "slug", Type: schema.FieldTypeText})
+		collection.Schema.AddField(&schema.SchemaField{Name: "trashed", Type: schema.FieldTypeBool})
+		collection.Indexes = types.JsonArray[string]{oldIndex}
+
+		// use different dao to avoid triggering automigrate while saving the dummy collection
+		if err := daos.New(app.DB()).SaveCollection(collection); err != nil {
+			t.Fatalf("[%d] Failed to save dummy collection, got %v", i, err)
+		}
+
+		// @todo remove after collections cache is replaced
+		app.Bootstrap()
+
+		// change only the index predicate and trigger automigrate
+		collection.Indexes = types.JsonArray[string]{newIndex}
+		if err := app.Dao().SaveCollection(collection); err != nil {
+			t.Fatalf("[%d] Failed to save dummy collection update, got %v", i, err)
+		}
+
+		files, _ := os.ReadDir(migrationsDir)
+		if total := len(files); total != 1 {
+			t.Fatalf("[%d] Expected 1 file to be generated, got %d", i, total)
+		}
+
+		content, err := os.ReadFile(filepath.Join(migrationsDir, files[0].Name()))
+		if err != nil {
+			t.Fatalf("[%d] Failed to read the generated migration file: %v", i, err)
+		}
+
+		for _, idx := range []string{oldIndex, newIndex} {
+			if !strings.Contains(string(content), idx) {
+				t.Fatalf("[%d] Expected the migration to contain %q, got\n%s", i, idx, content)
+			}
+		}
+	}
+}
@@ -2,17 +2,21 @@ package pocketbase
 
 import (
 	"io"
+	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/cmd"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ core.App = (*PocketBase)(nil)
@@ -56,6 +60,25 @@ type Config struct {
 	DataMaxIdleConns int // default to core.DefaultDataMaxIdleConns
 	LogsMaxOpenConns int // default to core.DefaultLogsMaxOpenConns
 	LogsMaxIdleConns int // default to core.DefaultLogsMaxIdleConns
+
+	// optional data db writer pool and SQLite PRAGMAs configurations
+	// (could be also changed with the related console flags, see core.BaseAppConfig)
+	DataMaxWriteConns int           // default to core.DefaultDataMaxWriteConns
+	DBBusyTimeout     time.Duration // default to core.DefaultDBBusyTimeout
+	DBJournalMode     string        // default to core.DefaultDBJournalMode
+	DBSynchronous     string        // default to core.DefaultDBSynchronous
+	DBCacheSize       int           // default to core.DefaultDBCacheSize
+
+	// optional logs configurations (see core.BaseAppConfig)
+	LogHandlers           []slog.Handler
+	RequestLogsSampleRate float64
+
+	// optional OpenTelemetry tracer provider (see core.BaseAppConfig)
+	TracerProvider trace.TracerProvider
+
+	// optional data read replica configurations (see core.BaseAppConfig)
+	DataReplicaPath  string
+	DataReplicaCheck func(replica dbx.Builder) error
 }
 
 // New creates a new PocketBase instance with the default configuration.
@@ -119,10 +142,25 @@ func NewWithConfig(config Config) *PocketBase {
 		IsDev:            pb.devFlag,
 		DataDir:          pb.dataDirFlag,
 		EncryptionEnv:    pb.encryptionEnvFlag,
+		Version:          Version,
 		DataMaxOpenConns: config.DataMaxOpenConns,
 		DataMaxIdleConns: config.DataMaxIdleConns,
 		LogsMaxOpenConns: config.LogsMaxOpenConns,
 		LogsMaxIdleConns: config.LogsMaxIdleConns,
+
+		DataMaxWriteConns: config.DataMaxWriteConns,
+		DBBusyTimeout:     config.DBBusyTimeout,
+		DBJournalMode:     config.DBJournalMode,
+		DBSynchronous:     config.DBSynchronous,
+		DBCacheSize:       config.DBCacheSize,
+
+		LogHandlers:           config.LogHandlers,
+		RequestLogsSampleRate: config.RequestLogsSampleRate,
+
+		TracerProvider: config.TracerProvider,
+
+		DataReplicaPath:  config.DataReplicaPath,
+		DataReplicaCheck: config.DataReplicaCheck,
 	})}
 
 	// hide the default help command (allow only `--help` flag)
@@ -136,6 +174,9 @@ func NewWithConfig(config Config) *PocketBase {
 func (pb *PocketBase) Start() error {
 	// register system commands
 	pb.RootCmd.AddCommand(cmd.NewAdminCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewRotateKeyCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewFilesCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewCollectionsCommand(pb))
 	pb.RootCmd.AddCommand(cmd.NewServeCommand(pb, !pb.hideStartBanner))
 
 	return pb.Execute()
@@ -206,9 +247,78 @@ func (pb *PocketBase) eagerParseFlags(config *Config) error {
 		"enable dev mode, aka. printing logs and sql statements to the console",
 	)
 
+	pb.eagerParseDBFlags(config)
+
 	return pb.RootCmd.ParseFlags(os.Args[1:])
 }
 
+// eagerParseDBFlags registers the db pool and SQLite PRAGMAs flags
+// bound directly to the related config fields.
+func (pb *PocketBase) eagerParseDBFlags(config *Config) {
+	if config.DataMaxOpenConns <= 0 {
+		config.DataMaxOpenConns = core.DefaultDataMaxOpenConns
+	}
+	if config.DataMaxWriteConns <= 0 {
+		config.DataMaxWriteConns = core.DefaultDataMaxWriteConns
+	}
+	if config.DBBusyTimeout <= 0 {
+		config.DBBusyTimeout = core.DefaultDBBusyTimeout
+	}
+	if config.DBJournalMode == "" {
+		config.DBJournalMode = core.DefaultDBJournalMode
+	}
+	if config.DBSynchronous == "" {
+		config.DBSynchronous = core.DefaultDBSynchronous
+	}
+	if config.DBCacheSize == 0 {
+		config.DBCacheSize = core.DefaultDBCacheSize
+	}
+
+	flags := pb.RootCmd.PersistentFlags()
+
+	flags.IntVar(
+		&config.DataMaxOpenConns,
+		"dataMaxOpenConns",
+		config.DataMaxOpenConns,
+		"the max open connections of the data db read pool",
+	)
+
+	flags.IntVar(
+		&config.DataMaxWriteConns,
+		"dataMaxWriteConns",
+		config.DataMaxWriteConns,
+		"the max open connections of the data db write pool \n(values greater than 1 are not recommended due to the SQLite lock contention)",
+	)
+
+	flags.DurationVar(
+		&config.DBBusyTimeout,
+		"dbBusyTimeout",
+		config.DBBusyTimeout,
+		"the SQLite busy_timeout, aka. how long to wait for a locked db before failing",
+	)
+
+	flags.StringVar(
+		&config.DBJournalMode,
+		"dbJournalMode",
+		config.DBJournalMode,
+		"the SQLite journal_mode (DELETE, TRUNCATE, PERSIST, MEMORY, WAL, OFF)",
+	)
+
+	flags.StringVar(
+		&config.DBSynchronous,
+		"dbSynchronous",
+		config.DBSynchronous,
+		"the SQLite synchronous mode (OFF, NORMAL, FULL, EXTRA)",
+	)
+
+	flags.IntVar(
+		&config.DBCacheSize,
+		"dbCacheSize",
+		config.DBCacheSize,
+		"the SQLite cache_size (negative values are in KiB, positive - in pages)",
+	)
+}
+
 // skipBootstrap eagerly checks if the app should skip the bootstrap process:
 // - already bootstrapped
 // - is unknown command
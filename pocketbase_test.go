@@ -130,6 +130,52 @@ func TestNewWithConfigAndFlags(t *testing.T) {
 	}
 }
 
+func TestNewWithConfigAndDBFlags(t *testing.T) {
+	// copy os.Args
+	originalArgs := make([]string, len(os.Args))
+	copy(originalArgs, os.Args)
+	defer func() {
+		// restore os.Args
+		os.Args = originalArgs
+	}()
+
+	// change os.Args
+	os.Args = os.Args[:1]
+	os.Args = append(
+		os.Args,
+		"--dir="+t.TempDir(),
+		"--dbBusyTimeout=2s",
+		"--dbJournalMode=truncate",
+		"--dbCacheSize=-1000",
+	)
+
+	app := NewWithConfig(Config{
+		DBSynchronous: "FULL",
+	})
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.ResetBootstrapState()
+
+	pragmas := map[string]string{
+		"busy_timeout": "2000",
+		"journal_mode": "truncate",
+		"synchronous":  "2", // FULL
+		"cache_size":   "-1000",
+	}
+
+	for name, expected := range pragmas {
+		var value string
+		if err := app.DB().NewQuery("PRAGMA " + name).Row(&value); err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Fatalf("Expected %s %q, got %q", name, expected, value)
+		}
+	}
+}
+
 func TestSkipBootstrap(t *testing.T) {
 	// copy os.Args
 	originalArgs := make([]string, len(os.Args))
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/pocketbase/dbx"
@@ -15,6 +14,7 @@ import (
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 // maxNestedRels defines the max allowed nested relations depth.
@@ -69,6 +69,19 @@ func (r *runner) run() (*search.ResolverResult, error) {
 	}
 
 	if r.activeProps[0] == "@request" {
+		// reject the encrypted data fields for consistency with
+		// the stored ones (checked even without request info so
+		// that the collection rules validation could fail early)
+		if strings.HasPrefix(r.fieldName, "@request.data.") && len(r.activeProps) > 2 {
+			name, _, _ := splitModifier(r.activeProps[2])
+			if f := r.resolver.baseCollection.Schema.GetFieldByName(name); f != nil && f.Type == schema.FieldTypeEncrypted {
+				return nil, &search.NonSearchableFieldError{
+					Field:  name,
+					Reason: nonSearchableFieldReason(f.Type),
+				}
+			}
+		}
+
 		if r.resolver.requestInfo == nil {
 			return &search.ResolverResult{Identifier: "NULL"}, nil
 		}
@@ -395,6 +408,17 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 				return nil, fmt.Errorf("unknown field %q", name)
 			}
 
+			if err := r.resolver.checkFieldViewRule(collection, field); err != nil {
+				return nil, err
+			}
+
+			if list.ExistInSlice(field.Type, schema.NonSearchableFieldTypes()) {
+				return nil, &search.NonSearchableFieldError{
+					Field:  name,
+					Reason: nonSearchableFieldReason(field.Type),
+				}
+			}
+
 			cleanFieldName := inflector.Columnify(field.Name)
 
 			// arrayable fields with ":length" modifier
@@ -467,36 +491,40 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			// (https://github.com/pocketbase/pocketbase/issues/4068)
 			if field.Type == schema.FieldTypeJson {
 				result.NoCoalesce = true
+				result.JsonArray = true
 				result.Identifier = dbutils.JsonExtract(r.activeTableAlias+"."+cleanFieldName, "")
 				if r.withMultiMatch {
 					r.multiMatch.valueIdentifier = dbutils.JsonExtract(r.multiMatchActiveTableAlias+"."+cleanFieldName, "")
 				}
 			}
 
+			// the duration values are stored as integers so convert
+			// the compared duration strings to the same format (eg. `timeout > "5m"`)
+			if field.Type == schema.FieldTypeDuration {
+				result.NormalizeLiteral = normalizeDurationLiteral
+			}
+
 			return result, nil
 		}
 
 		field := collection.Schema.GetFieldByName(prop)
 
+		if field != nil {
+			if err := r.resolver.checkFieldViewRule(collection, field); err != nil {
+				return nil, err
+			}
+		}
+
 		// json field -> treat the rest of the props as json path
 		if field != nil && field.Type == schema.FieldTypeJson {
-			var jsonPath strings.Builder
-			for j, p := range r.activeProps[i+1:] {
-				if _, err := strconv.Atoi(p); err == nil {
-					jsonPath.WriteString("[")
-					jsonPath.WriteString(inflector.Columnify(p))
-					jsonPath.WriteString("]")
-				} else {
-					if j > 0 {
-						jsonPath.WriteString(".")
-					}
-					jsonPath.WriteString(inflector.Columnify(p))
-				}
+			jsonPathStr, err := search.BuildJsonPath(r.activeProps[i+1:])
+			if err != nil {
+				return nil, err
 			}
-			jsonPathStr := jsonPath.String()
 
 			result := &search.ResolverResult{
 				NoCoalesce: true,
+				JsonArray:  true,
 				Identifier: dbutils.JsonExtract(r.activeTableAlias+"."+inflector.Columnify(prop), jsonPathStr),
 			}
 
@@ -541,6 +569,9 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			if backField.Type != schema.FieldTypeRelation {
 				return nil, fmt.Errorf("invalid back relation field %q", parts[2])
 			}
+			if err := r.resolver.checkFieldViewRule(backCollection, backField); err != nil {
+				return nil, err
+			}
 
 			backField.InitOptions()
 			backFieldOptions, ok := backField.Options.(*schema.RelationOptions)
@@ -728,5 +759,39 @@ func resolvableSystemFieldNames(collection *models.Collection) []string {
 		)
 	}
 
+	if collection.HasSoftDelete() {
+		result = append(result, schema.FieldNameDeleted)
+	}
+
 	return result
 }
+
+// normalizeDurationLiteral converts the provided duration string
+// literal (eg. "1h30m") into its stored integer form.
+//
+// Empty literals are left unchanged to preserve the empty value comparisons.
+func normalizeDurationLiteral(literal string) (any, error) {
+	if literal == "" {
+		return literal, nil
+	}
+
+	d, err := types.ParseDuration(literal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %q", literal)
+	}
+
+	return d.Int(), nil
+}
+
+// nonSearchableFieldReason returns the reason why
+// the provided field type cannot be searched.
+func nonSearchableFieldReason(fieldType string) string {
+	switch fieldType {
+	case schema.FieldTypeEncrypted:
+		return "encrypted fields values are stored as ciphertext"
+	case schema.FieldTypeComputed:
+		return "computed fields values are not stored"
+	default:
+		return fieldType + " fields are not searchable"
+	}
+}
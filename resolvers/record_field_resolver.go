@@ -3,14 +3,17 @@ package resolvers
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
 )
 
@@ -21,6 +24,12 @@ const (
 	lengthModifier string = "length"
 )
 
+// @request.body.* is an alias of the @request.data.* filter fields
+const (
+	requestDataPrefix string = "@request.data."
+	requestBodyPrefix string = "@request.body."
+)
+
 // list of auth filter fields that don't require join with the auth
 // collection or any other extra checks to be resolved.
 var plainRequestAuthFields = []string{
@@ -70,6 +79,10 @@ type RecordFieldResolver struct {
 	loadedCollections []*models.Collection
 	joins             []*join
 	allowHiddenFields bool
+
+	// fieldViewChecks caches the field view rule checks results
+	// (keyed by "collectionId.fieldName").
+	fieldViewChecks map[string]error
 }
 
 // NewRecordFieldResolver creates and initializes a new `RecordFieldResolver`.
@@ -86,12 +99,15 @@ func NewRecordFieldResolver(
 		requestInfo:       requestInfo,
 		allowHiddenFields: allowHiddenFields,
 		joins:             []*join{},
+		fieldViewChecks:   map[string]error{},
 		loadedCollections: []*models.Collection{baseCollection},
 		allowedFields: []string{
 			`^\w+[\w\.\:]*$`,
 			`^\@request\.context$`,
 			`^\@request\.method$`,
+			`^\@request\.remoteIp$`,
 			`^\@request\.auth\.[\w\.\:]*\w+$`,
+			`^\@request\.apiKey\.\w+$`,
 			`^\@request\.data\.[\w\.\:]*\w+$`,
 			`^\@request\.query\.[\w\.\:]*\w+$`,
 			`^\@request\.headers\.\w+$`,
@@ -103,6 +119,7 @@ func NewRecordFieldResolver(
 	if r.requestInfo != nil {
 		r.staticRequestInfo["context"] = r.requestInfo.Context
 		r.staticRequestInfo["method"] = r.requestInfo.Method
+		r.staticRequestInfo["remoteIp"] = r.requestInfo.RemoteIp
 		r.staticRequestInfo["query"] = r.requestInfo.Query
 		r.staticRequestInfo["headers"] = r.requestInfo.Headers
 		r.staticRequestInfo["data"] = r.requestInfo.Data
@@ -113,6 +130,16 @@ func NewRecordFieldResolver(
 			authData[schema.FieldNameEmail] = r.requestInfo.AuthRecord.Email()
 			r.staticRequestInfo["auth"] = authData
 		}
+		r.staticRequestInfo["apiKey"] = nil
+		// the api key is visible only to the rules of its scoped collections
+		if r.requestInfo.ApiKey != nil && r.requestInfo.ApiKey.CanAccessCollection(baseCollection) {
+			r.staticRequestInfo["apiKey"] = map[string]any{
+				"id":          r.requestInfo.ApiKey.Id,
+				"name":        r.requestInfo.ApiKey.Name,
+				"collections": r.requestInfo.ApiKey.Collections,
+				"scopes":      r.requestInfo.ApiKey.Scopes,
+			}
+		}
 	}
 
 	return r
@@ -147,15 +174,27 @@ func (r *RecordFieldResolver) UpdateQuery(query *dbx.SelectQuery) error {
 //	screen.project_via_prototype.name
 //	@request.context
 //	@request.method
+//	@request.remoteIp
 //	@request.query.filter
 //	@request.headers.x_token
 //	@request.auth.someRelation.name
+//	@request.apiKey.scopes
 //	@request.data.someRelation.name
 //	@request.data.someField
 //	@request.data.someSelect:each
 //	@request.data.someField:isset
+//	@request.body.someField (alias of @request.data.someField)
 //	@collection.product.name
+//
+// Note that the plain record fields resolve to the currently stored
+// record values, while @request.data.* (or @request.body.*) resolve to the
+// submitted ones, allowing for example in an update rule to restrict
+// a field value transition (eg. `status = "draft" && @request.body.status = "published"`).
 func (r *RecordFieldResolver) Resolve(fieldName string) (*search.ResolverResult, error) {
+	if strings.HasPrefix(fieldName, requestBodyPrefix) {
+		fieldName = requestDataPrefix + strings.TrimPrefix(fieldName, requestBodyPrefix)
+	}
+
 	return parseAndRun(fieldName, r)
 }
 
@@ -197,6 +236,12 @@ func (r *RecordFieldResolver) resolveStaticRequestField(path ...string) (*search
 				resultVal = nv
 			}
 		}
+		// similarly, normalize the duration field value to its stored integer form
+		if field != nil && field.Type == schema.FieldTypeDuration {
+			if dv, err := types.ParseDuration(v); err == nil {
+				resultVal = dv.Int()
+			}
+		}
 		// otherwise - no further processing is needed...
 	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
 		// no further processing is needed...
@@ -224,6 +269,12 @@ func (r *RecordFieldResolver) resolveStaticRequestField(path ...string) (*search
 	}, nil
 }
 
+// LoadedCollections returns the collections that were loaded so far
+// while resolving the fields (including the base collection).
+func (r *RecordFieldResolver) LoadedCollections() []*models.Collection {
+	return slices.Clone(r.loadedCollections)
+}
+
 func (r *RecordFieldResolver) loadCollection(collectionNameOrId string) (*models.Collection, error) {
 	// return already loaded
 	for _, collection := range r.loadedCollections {
@@ -242,6 +293,71 @@ func (r *RecordFieldResolver) loadCollection(collectionNameOrId string) (*models
 	return collection, nil
 }
 
+// checkFieldViewRule checks whether the field could be used in the
+// filter and sort expressions of the current request.
+//
+// Fields with a field view rule are allowed only if the rule is satisfied
+// for all records of the field collection, preventing this way guessing
+// the hidden field values through the filter (admins are not restricted).
+func (r *RecordFieldResolver) checkFieldViewRule(collection *models.Collection, field *schema.SchemaField) error {
+	if r.allowHiddenFields || field.ViewRule == "" {
+		return nil
+	}
+
+	key := collection.Id + "." + field.Name
+
+	err, ok := r.fieldViewChecks[key]
+	if !ok {
+		if !r.isFieldViewRuleSatisfied(collection, field) {
+			err = fmt.Errorf("the field %q is restricted by its view rule and cannot be used for filtering or sorting", field.Name)
+		}
+		r.fieldViewChecks[key] = err
+	}
+
+	return err
+}
+
+// isFieldViewRuleSatisfied reports whether the field view rule
+// is satisfied for all records of the collection.
+//
+// Returns false on error or if the resolver dao doesn't have a db builder.
+func (r *RecordFieldResolver) isFieldViewRuleSatisfied(collection *models.Collection, field *schema.SchemaField) bool {
+	builder, ok := r.dao.(interface{ DB() dbx.Builder })
+	if !ok {
+		return false
+	}
+	db := builder.DB()
+
+	tableName := inflector.Columnify(collection.Name)
+
+	var total int
+	if err := db.Select("count(*)").From(tableName).Row(&total); err != nil {
+		return false
+	}
+
+	resolver := NewRecordFieldResolver(r.dao, collection, r.requestInfo, true)
+
+	expr, err := search.FilterData(field.ViewRule).BuildExpr(resolver)
+	if err != nil {
+		return false
+	}
+
+	query := db.Select(fmt.Sprintf("count(DISTINCT [[%s.id]])", tableName)).
+		From(tableName).
+		AndWhere(expr)
+
+	if err := resolver.UpdateQuery(query); err != nil {
+		return false
+	}
+
+	var satisfied int
+	if err := query.Row(&satisfied); err != nil {
+		return false
+	}
+
+	return satisfied == total
+}
+
 func (r *RecordFieldResolver) registerJoin(tableName string, tableAlias string, on dbx.Expression) {
 	join := &join{
 		tableName:  tableName,
@@ -2,6 +2,7 @@ package resolvers_test
 
 import (
 	"encoding/json"
+	"errors"
 	"regexp"
 	"strings"
 	"testing"
@@ -147,7 +148,7 @@ func TestRecordFieldResolverUpdateQuery(t *testing.T) {
 			"demo4",
 			"self_rel_many.title ?= 'test' || self_rel_one.json_object.a ?> true",
 			false,
-			"SELECT DISTINCT `demo4`.* FROM `demo4` LEFT JOIN json_each(CASE WHEN json_valid([[demo4.self_rel_many]]) THEN [[demo4.self_rel_many]] ELSE json_array([[demo4.self_rel_many]]) END) `demo4_self_rel_many_je` LEFT JOIN `demo4` `demo4_self_rel_many` ON [[demo4_self_rel_many.id]] = [[demo4_self_rel_many_je.value]] LEFT JOIN `demo4` `demo4_self_rel_one` ON [[demo4_self_rel_one.id]] = [[demo4.self_rel_one]] WHERE ([[demo4_self_rel_many.title]] = {:TEST} OR (CASE WHEN json_valid([[demo4_self_rel_one.json_object]]) THEN JSON_EXTRACT([[demo4_self_rel_one.json_object]], '$.a') ELSE JSON_EXTRACT(json_object('pb', [[demo4_self_rel_one.json_object]]), '$.pb.a') END) > 1)",
+			"SELECT DISTINCT `demo4`.* FROM `demo4` LEFT JOIN json_each(CASE WHEN json_valid([[demo4.self_rel_many]]) THEN [[demo4.self_rel_many]] ELSE json_array([[demo4.self_rel_many]]) END) `demo4_self_rel_many_je` LEFT JOIN `demo4` `demo4_self_rel_many` ON [[demo4_self_rel_many.id]] = [[demo4_self_rel_many_je.value]] LEFT JOIN `demo4` `demo4_self_rel_one` ON [[demo4_self_rel_one.id]] = [[demo4.self_rel_one]] WHERE ([[demo4_self_rel_many.title]] = {:TEST} OR (CASE WHEN (CASE WHEN json_valid((CASE WHEN json_valid([[demo4_self_rel_one.json_object]]) THEN JSON_EXTRACT([[demo4_self_rel_one.json_object]], '$.a') ELSE JSON_EXTRACT(json_object('pb', [[demo4_self_rel_one.json_object]]), '$.pb.a') END)) THEN json_type((CASE WHEN json_valid([[demo4_self_rel_one.json_object]]) THEN JSON_EXTRACT([[demo4_self_rel_one.json_object]], '$.a') ELSE JSON_EXTRACT(json_object('pb', [[demo4_self_rel_one.json_object]]), '$.pb.a') END)) END) = 'array' THEN EXISTS (SELECT 1 FROM json_each((CASE WHEN json_valid([[demo4_self_rel_one.json_object]]) THEN JSON_EXTRACT([[demo4_self_rel_one.json_object]], '$.a') ELSE JSON_EXTRACT(json_object('pb', [[demo4_self_rel_one.json_object]]), '$.pb.a') END)) {{__jeTEST}} WHERE [[__jeTEST.value]] > 1) ELSE (CASE WHEN json_valid([[demo4_self_rel_one.json_object]]) THEN JSON_EXTRACT([[demo4_self_rel_one.json_object]], '$.a') ELSE JSON_EXTRACT(json_object('pb', [[demo4_self_rel_one.json_object]]), '$.pb.a') END) > 1 END))",
 		},
 		{
 			"multiple relations (multi-match operators)",
@@ -233,6 +234,27 @@ func TestRecordFieldResolverUpdateQuery(t *testing.T) {
 			false,
 			"SELECT DISTINCT `demo4`.* FROM `demo4` LEFT JOIN `users` `__collection_users` WHERE ((([[__collection_users.email]] > 1) AND (NOT EXISTS (SELECT 1 FROM (SELECT [[__mm__collection_users.email]] as [[multiMatchValue]] FROM `demo4` `__mm_demo4` LEFT JOIN `users` `__mm__collection_users` WHERE `__mm_demo4`.`id` = `demo4`.`id`) {{__smTEST}} WHERE ((NOT ([[__smTEST.multiMatchValue]] > 1)) OR ([[__smTEST.multiMatchValue]] IS NULL))))) OR {:TEST} > 1)",
 		},
+		{
+			"relation field with case modifier (multi-match)",
+			"demo1",
+			"rel_many.email:lower = 'Test@Example.com'",
+			true,
+			"SELECT DISTINCT `demo1`.* FROM `demo1` LEFT JOIN json_each(CASE WHEN json_valid([[demo1.rel_many]]) THEN [[demo1.rel_many]] ELSE json_array([[demo1.rel_many]]) END) `demo1_rel_many_je` LEFT JOIN `users` `demo1_rel_many` ON [[demo1_rel_many.id]] = [[demo1_rel_many_je.value]] WHERE (((LOWER([[demo1_rel_many.email]]) = LOWER({:TEST})) AND (NOT EXISTS (SELECT 1 FROM (SELECT [[__mm_demo1_rel_many.email]] as [[multiMatchValue]] FROM `demo1` `__mm_demo1` LEFT JOIN json_each(CASE WHEN json_valid([[__mm_demo1.rel_many]]) THEN [[__mm_demo1.rel_many]] ELSE json_array([[__mm_demo1.rel_many]]) END) `__mm_demo1_rel_many_je` LEFT JOIN `users` `__mm_demo1_rel_many` ON [[__mm_demo1_rel_many.id]] = [[__mm_demo1_rel_many_je.value]] WHERE `__mm_demo1`.`id` = `demo1`.`id`) {{__smTEST}} WHERE NOT (LOWER([[__smTEST.multiMatchValue]]) = LOWER({:TEST}))))))",
+		},
+		{
+			"relation field with case modifiers (single-match)",
+			"demo4",
+			"self_rel_one.title:lower ~ 'Abc' && title:upper != self_rel_one.title:upper",
+			false,
+			"SELECT DISTINCT `demo4`.* FROM `demo4` LEFT JOIN `demo4` `demo4_self_rel_one` ON [[demo4_self_rel_one.id]] = [[demo4.self_rel_one]] WHERE (LOWER([[demo4_self_rel_one.title]]) LIKE LOWER({:TEST}) ESCAPE '\\' AND COALESCE(UPPER([[demo4.title]]), '') IS NOT COALESCE(UPPER([[demo4_self_rel_one.title]]), ''))",
+		},
+		{
+			"case modifier combined with :each",
+			"demo1",
+			"select_many:each:lower ?= 'optiona'",
+			false,
+			"SELECT DISTINCT `demo1`.* FROM `demo1` LEFT JOIN json_each(CASE WHEN json_valid([[demo1.select_many]]) THEN [[demo1.select_many]] ELSE json_array([[demo1.select_many]]) END) `demo1_select_many_je` WHERE LOWER([[demo1_select_many_je.value]]) = LOWER({:TEST})",
+		},
 		{
 			"hidden field (add emailVisibility)",
 			"users",
@@ -259,6 +281,15 @@ func TestRecordFieldResolverUpdateQuery(t *testing.T) {
 			false,
 			"SELECT `demo1`.* FROM `demo1` WHERE (TRUE > 1 OR TRUE > 1 OR FALSE > 1 OR TRUE > 1 OR TRUE > 1 OR FALSE > 1)",
 		},
+		{
+			"@request.body.* alias",
+			"demo4",
+			"@request.body.a:isset > true ||" +
+				"@request.body.rel_one_cascade.title > true ||" +
+				"@request.body.missing = true",
+			false,
+			"SELECT DISTINCT `demo4`.* FROM `demo4` LEFT JOIN `demo3` `__data_demo3_rel_one_cascade` ON [[__data_demo3_rel_one_cascade.id]]={:p0} WHERE (TRUE > 1 OR [[__data_demo3_rel_one_cascade.title]] > 1 OR '' = 1)",
+		},
 		{
 			"@request.data.rel.* fields",
 			"demo4",
@@ -368,6 +399,13 @@ func TestRecordFieldResolverUpdateQuery(t *testing.T) {
 			false,
 			"SELECT `demo4`.* FROM `demo4` WHERE ((CASE WHEN json_valid([[demo4.json_object]]) THEN JSON_EXTRACT([[demo4.json_object]], '$.a.b') ELSE JSON_EXTRACT(json_object('pb', [[demo4.json_object]]), '$.pb.a.b') END) IS {:TEST} AND json_array_length(CASE WHEN json_valid([[demo4.self_rel_many]]) THEN [[demo4.self_rel_many]] ELSE (CASE WHEN [[demo4.self_rel_many]] = '' OR [[demo4.self_rel_many]] IS NULL THEN json_array() ELSE json_array([[demo4.self_rel_many]]) END) END) IS NOT {:TEST} AND (CASE WHEN json_valid([[demo4.json_object]]) THEN JSON_EXTRACT([[demo4.json_object]], '$.a.b') ELSE JSON_EXTRACT(json_object('pb', [[demo4.json_object]]), '$.pb.a.b') END) > {:TEST} AND json_array_length(CASE WHEN json_valid([[demo4.self_rel_many]]) THEN [[demo4.self_rel_many]] ELSE (CASE WHEN [[demo4.self_rel_many]] = '' OR [[demo4.self_rel_many]] IS NULL THEN json_array() ELSE json_array([[demo4.self_rel_many]]) END) END) <= {:TEST})",
 		},
+		{
+			"json path with quoted keys, array indexes and like operators",
+			"demo4",
+			"json_object.`a.b`.c = 'test' && json_array.0 ~ 'go' && json_object.a.1.b !~ 'go' && json_object.a = null",
+			false,
+			"SELECT `demo4`.* FROM `demo4` WHERE ((CASE WHEN json_valid([[demo4.json_object]]) THEN JSON_EXTRACT([[demo4.json_object]], '$.\"a.b\".c') ELSE JSON_EXTRACT(json_object('pb', [[demo4.json_object]]), '$.pb.\"a.b\".c') END) IS {:TEST} AND (CASE WHEN json_valid([[demo4.json_array]]) THEN JSON_EXTRACT([[demo4.json_array]], '$[0]') ELSE JSON_EXTRACT(json_object('pb', [[demo4.json_array]]), '$.pb[0]') END) LIKE {:TEST} ESCAPE '\\' AND COALESCE((CASE WHEN json_valid([[demo4.json_object]]) THEN JSON_EXTRACT([[demo4.json_object]], '$.a[1].b') ELSE JSON_EXTRACT(json_object('pb', [[demo4.json_object]]), '$.pb.a[1].b') END), '') NOT LIKE {:TEST} ESCAPE '\\' AND (CASE WHEN json_valid([[demo4.json_object]]) THEN JSON_EXTRACT([[demo4.json_object]], '$.a') ELSE JSON_EXTRACT(json_object('pb', [[demo4.json_object]]), '$.pb.a') END) IS NULL)",
+		},
 		{
 			"json field equal normalization checks",
 			"demo4",
@@ -540,8 +578,9 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 	}
 
 	requestInfo := &models.RequestInfo{
-		Context: "ctx",
-		Method:  "get",
+		Context:  "ctx",
+		Method:   "get",
+		RemoteIp: "1.2.3.4",
 		Query: map[string]any{
 			"a": 123,
 		},
@@ -555,6 +594,12 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 			"d": "789",
 		},
 		AuthRecord: authRecord,
+		ApiKey: &models.ApiKey{
+			BaseModel:   models.BaseModel{Id: "test_key"},
+			Name:        "test",
+			Collections: []string{"demo1"},
+			Scopes:      []string{"read", "reports"},
+		},
 	}
 
 	r := resolvers.NewRecordFieldResolver(app.Dao(), collection, requestInfo, true)
@@ -570,6 +615,8 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		{"@request.missing", true, ""},
 		{"@request.context", false, `"ctx"`},
 		{"@request.method", false, `"get"`},
+		{"@request.remoteIp", false, `"1.2.3.4"`},
+		{"@request.remoteIp.sub", true, ``},
 		{"@request.query", true, ``},
 		{"@request.query.a", false, `123`},
 		{"@request.query.a.missing", false, ``},
@@ -583,6 +630,10 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		{"@request.data.number_unknown", false, `"20"`}, // no numeric normalizations for unknown fields
 		{"@request.data.b.missing", false, ``},
 		{"@request.data.c", false, `"{\"sub\":1}"`},
+		{"@request.body", true, ``},
+		{"@request.body.b", false, `456`},
+		{"@request.body.number", false, `10`},
+		{"@request.body.b.missing", false, ``},
 		{"@request.auth", true, ""},
 		{"@request.auth.id", false, `"4q1xlclmfloku33"`},
 		{"@request.auth.username", false, `"users75657"`},
@@ -590,6 +641,11 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		{"@request.auth.emailVisibility", false, `false`},
 		{"@request.auth.email", false, `"test@example.com"`}, // should always be returned no matter of the emailVisibility state
 		{"@request.auth.missing", false, `NULL`},
+		{"@request.apiKey", true, ""},
+		{"@request.apiKey.id", false, `"test_key"`},
+		{"@request.apiKey.name", false, `"test"`},
+		{"@request.apiKey.scopes", false, `"[\"read\",\"reports\"]"`},
+		{"@request.apiKey.missing", false, ``},
 	}
 
 	for i, s := range scenarios {
@@ -646,3 +702,212 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		t.Fatalf("Expected the original authRecord email to not be exported, got %q", v)
 	}
 }
+
+func TestRecordFieldResolverApiKeyOfAnotherCollection(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestInfo := &models.RequestInfo{
+		ApiKey: &models.ApiKey{
+			BaseModel:   models.BaseModel{Id: "test_key"},
+			Collections: []string{"demo2"},
+			Scopes:      []string{"read"},
+		},
+	}
+
+	r := resolvers.NewRecordFieldResolver(app.Dao(), collection, requestInfo, true)
+
+	result, err := r.Resolve("@request.apiKey.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Identifier != "NULL" || len(result.Params) != 0 {
+		t.Fatalf("Expected the api key to be resolved as NULL, got %q (%v)", result.Identifier, result.Params)
+	}
+}
+
+func TestRecordFieldResolverNonSearchableFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Dao().EncryptionKey = "abcdabcdabcdabcdabcdabcdabcdabcd"
+
+	collection := &models.Collection{
+		Name: "non_searchable_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "secret", Type: schema.FieldTypeEncrypted},
+			&schema.SchemaField{
+				Name:    "computed",
+				Type:    schema.FieldTypeComputed,
+				Options: &schema.ComputedOptions{Expression: "title"},
+			},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		filter       string
+		sort         string
+		expectField  string
+		expectReason string
+	}{
+		{"filter encrypted", "secret = 'test'", "", "secret", "ciphertext"},
+		{"filter encrypted with modifier", "secret:lower ~ 'test'", "", "secret", "ciphertext"},
+		{"filter encrypted right operand", "title = secret", "", "secret", "ciphertext"},
+		{"filter encrypted via @collection", "@collection.non_searchable_test.secret = 'test'", "", "secret", "ciphertext"},
+		{"filter encrypted via @request.data", "@request.data.secret = 'test'", "", "secret", "ciphertext"},
+		{"filter encrypted via @request.data with modifier", "@request.data.secret:isset = true", "", "secret", "ciphertext"},
+		{"filter computed", "computed = 'test'", "", "computed", "not stored"},
+		{"sort encrypted", "", "-secret", "secret", "ciphertext"},
+		{"sort computed", "", "computed", "computed", "not stored"},
+		{"searchable field", "title = 'test'", "title", "", ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil, true)
+
+			var err error
+			if s.filter != "" {
+				_, err = search.FilterData(s.filter).BuildExpr(r)
+			}
+			if err == nil && s.sort != "" {
+				_, err = search.ParseSortFromString(s.sort)[0].BuildExpr(r)
+			}
+
+			if s.expectField == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var fieldErr *search.NonSearchableFieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Expected NonSearchableFieldError, got %v", err)
+			}
+
+			if fieldErr.Field != s.expectField {
+				t.Fatalf("Expected field %q, got %q", s.expectField, fieldErr.Field)
+			}
+
+			if !strings.Contains(fieldErr.Reason, s.expectReason) || !strings.Contains(err.Error(), fieldErr.Reason) {
+				t.Fatalf("Expected reason with %q, got %q (%v)", s.expectReason, fieldErr.Reason, err)
+			}
+
+			if !strings.Contains(err.Error(), `"`+s.expectField+`"`) {
+				t.Fatalf("Expected the error to name the field %q, got %v", s.expectField, err)
+			}
+		})
+	}
+}
+
+func TestRecordFieldResolverFieldViewRule(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "view_rule_test",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "name", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "salary", Type: schema.FieldTypeNumber, ViewRule: "@request.auth.id = '4q1xlclmfloku33'"},
+			&schema.SchemaField{Name: "notes", Type: schema.FieldTypeText, ViewRule: "name = @request.auth.username"},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"users75657", "test"} {
+		record := models.NewRecord(collection)
+		record.Set("name", name)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager, err := app.Dao().FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	employee, err := app.Dao().FindRecordById("users", "oap640cot4yru2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name              string
+		authRecord        *models.Record
+		allowHiddenFields bool
+		filter            string
+		sort              string
+		expectError       bool
+	}{
+		{"unrestricted field", employee, false, "name = 'test'", "-name", false},
+		{"filter restricted field with failing rule", employee, false, "salary > 0", "", true},
+		{"sort restricted field with failing rule", employee, false, "", "-salary", true},
+		{"filter restricted field via @collection", employee, false, "@collection.view_rule_test.salary > 0", "", true},
+		{"filter restricted field with satisfied rule", manager, false, "salary > 0", "-salary", false},
+		{"filter restricted field with rule satisfied only for some records", manager, false, "notes = ''", "", true},
+		{"filter restricted field with allowed hidden fields", employee, true, "salary > 0 && notes = ''", "-salary", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			requestInfo := &models.RequestInfo{AuthRecord: s.authRecord}
+
+			r := resolvers.NewRecordFieldResolver(app.Dao(), collection, requestInfo, s.allowHiddenFields)
+
+			var err error
+			if s.filter != "" {
+				_, err = search.FilterData(s.filter).BuildExpr(r)
+			}
+			if err == nil && s.sort != "" {
+				_, err = search.ParseSortFromString(s.sort)[0].BuildExpr(r)
+			}
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestRecordFieldResolverLoadedCollections(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil, true)
+
+	if _, err := search.FilterData("title != '' && @collection.demo1.id != ''").BuildExpr(r); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, c := range r.LoadedCollections() {
+		names = append(names, c.Name)
+	}
+
+	if len(names) != 2 || names[0] != "demo4" || names[1] != "demo1" {
+		t.Fatalf("Expected [demo4 demo1] loaded collections, got %v", names)
+	}
+}
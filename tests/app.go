@@ -60,6 +60,7 @@ func (t *TestApp) NewMailClient() mailer.Mailer {
 
 	if t.TestMailer == nil {
 		t.TestMailer = &TestMailer{}
+		core.BindMailerSendHook(t, t.TestMailer)
 	}
 
 	return t.TestMailer
@@ -92,12 +93,28 @@ func (t *TestApp) registerEventCall(name string) error {
 // when the app is no longer needed.
 func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 	var testDataDir string
-	if len(optTestDataDir) == 0 || optTestDataDir[0] == "" {
+	if len(optTestDataDir) > 0 {
+		testDataDir = optTestDataDir[0]
+	}
+
+	return NewTestAppWithConfig(core.BaseAppConfig{DataDir: testDataDir})
+}
+
+// NewTestAppWithConfig creates and initializes a test application
+// instance with the provided base app config (eg. to test a custom
+// TracerProvider or LogHandlers).
+//
+// config.DataDir is the test data directory that will be cloned
+// (fallbacks to the default test data directory if empty).
+//
+// It is the caller's responsibility to call `app.Cleanup()`
+// when the app is no longer needed.
+func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
+	testDataDir := config.DataDir
+	if testDataDir == "" {
 		// fallback to the default test data directory
 		_, currentFile, _, _ := runtime.Caller(0)
 		testDataDir = filepath.Join(path.Dir(currentFile), "data")
-	} else {
-		testDataDir = optTestDataDir[0]
 	}
 
 	tempDir, err := TempDirClone(testDataDir)
@@ -105,10 +122,12 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return nil, err
 	}
 
-	app := core.NewBaseApp(core.BaseAppConfig{
-		DataDir:       tempDir,
-		EncryptionEnv: "pb_test_env",
-	})
+	config.DataDir = tempDir
+	if config.EncryptionEnv == "" {
+		config.EncryptionEnv = "pb_test_env"
+	}
+
+	app := core.NewBaseApp(config)
 
 	// load data dir and db connections
 	if err := app.Bootstrap(); err != nil {
@@ -138,6 +157,8 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		TestMailer: &TestMailer{},
 	}
 
+	core.BindMailerSendHook(t, t.TestMailer)
+
 	t.OnBeforeApiError().Add(func(e *core.ApiErrorEvent) error {
 		return t.registerEventCall("OnBeforeApiError")
 	})
@@ -170,6 +191,14 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return t.registerEventCall("OnModelAfterDelete")
 	})
 
+	t.OnRecordAfterTrash().Add(func(e *core.ModelEvent) error {
+		return t.registerEventCall("OnRecordAfterTrash")
+	})
+
+	t.OnRecordAfterRestore().Add(func(e *core.ModelEvent) error {
+		return t.registerEventCall("OnRecordAfterRestore")
+	})
+
 	t.OnRecordsListRequest().Add(func(e *core.RecordsListEvent) error {
 		return t.registerEventCall("OnRecordsListRequest")
 	})
@@ -322,6 +351,14 @@ func NewTestApp(optTestDataDir ...string) (*TestApp, error) {
 		return t.registerEventCall("OnMailerAfterRecordChangeEmailSend")
 	})
 
+	t.OnMailerSend().Add(func(e *core.MailerSendEvent) error {
+		return t.registerEventCall("OnMailerSend")
+	})
+
+	t.OnMailReceive().Add(func(e *core.MailReceiveEvent) error {
+		return t.registerEventCall("OnMailReceive")
+	})
+
 	t.OnRealtimeConnectRequest().Add(func(e *core.RealtimeConnectEvent) error {
 		return t.registerEventCall("OnRealtimeConnectRequest")
 	})
@@ -3,10 +3,14 @@ package tests
 import (
 	"sync"
 
+	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 )
 
-var _ mailer.Mailer = (*TestMailer)(nil)
+var (
+	_ mailer.TransportMailer = (*TestMailer)(nil)
+	_ mailer.SendInterceptor = (*TestMailer)(nil)
+)
 
 // TestMailer is a mock `mailer.Mailer` implementation.
 type TestMailer struct {
@@ -17,6 +21,24 @@ type TestMailer struct {
 
 	// @todo consider deprecating the above 2 fields?
 	SentMessages []mailer.Message
+
+	// LastTransport is the transport name of the last sent message
+	// (empty for the messages sent with the default transport).
+	LastTransport string
+
+	onSend *hook.Hook[*mailer.SendEvent]
+}
+
+// OnSend implements `mailer.SendInterceptor` interface.
+func (tm *TestMailer) OnSend() *hook.Hook[*mailer.SendEvent] {
+	tm.mux.Lock()
+	defer tm.mux.Unlock()
+
+	if tm.onSend == nil {
+		tm.onSend = &hook.Hook[*mailer.SendEvent]{}
+	}
+
+	return tm.onSend
 }
 
 // Reset clears any previously test collected data.
@@ -27,13 +49,26 @@ func (tm *TestMailer) Reset() {
 	tm.TotalSend = 0
 	tm.LastMessage = mailer.Message{}
 	tm.SentMessages = nil
+	tm.LastTransport = ""
 }
 
 // Send implements `mailer.Mailer` interface.
 func (tm *TestMailer) Send(m *mailer.Message) error {
+	return tm.SendWithTransport("", m)
+}
+
+// SendWithTransport implements `mailer.TransportMailer` interface.
+func (tm *TestMailer) SendWithTransport(name string, m *mailer.Message) error {
+	return tm.OnSend().Trigger(&mailer.SendEvent{Message: m}, func(e *mailer.SendEvent) error {
+		return tm.send(name, e.Message)
+	})
+}
+
+func (tm *TestMailer) send(name string, m *mailer.Message) error {
 	tm.mux.Lock()
 	defer tm.mux.Unlock()
 
+	tm.LastTransport = name
 	tm.TotalSend++
 	tm.LastMessage = *m
 	tm.SentMessages = append(tm.SentMessages, tm.LastMessage)
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+var _ trace.TracerProvider = (*TestTracerProvider)(nil)
+
+// TestTracerProvider is a minimal in-memory OpenTelemetry tracer
+// provider that records all started spans (used for testing).
+type TestTracerProvider struct {
+	embedded.TracerProvider
+
+	mux   sync.Mutex
+	spans []*TestSpan
+}
+
+// NewTestTracerProvider creates a new empty TestTracerProvider.
+func NewTestTracerProvider() *TestTracerProvider {
+	return &TestTracerProvider{}
+}
+
+// Tracer implements [trace.TracerProvider.Tracer].
+func (p *TestTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &testTracer{provider: p}
+}
+
+// Spans returns all recorded spans in the order of their start.
+func (p *TestTracerProvider) Spans() []*TestSpan {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return append([]*TestSpan(nil), p.spans...)
+}
+
+// Children returns the recorded direct child spans of the provided parent span.
+func (p *TestTracerProvider) Children(parent *TestSpan) []*TestSpan {
+	result := []*TestSpan{}
+
+	for _, span := range p.Spans() {
+		if span.Parent().SpanID() == parent.SpanContext().SpanID() {
+			result = append(result, span)
+		}
+	}
+
+	return result
+}
+
+// Reset clears all recorded spans.
+func (p *TestTracerProvider) Reset() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.spans = nil
+}
+
+type testTracer struct {
+	embedded.Tracer
+
+	provider *TestTracerProvider
+}
+
+// Start implements [trace.Tracer.Start].
+func (t *testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+
+	var parent trace.SpanContext
+	if !config.NewRoot() {
+		parent = trace.SpanContextFromContext(ctx)
+	}
+
+	traceId := parent.TraceID()
+	if !traceId.IsValid() {
+		rand.Read(traceId[:])
+	}
+
+	var spanId trace.SpanID
+	rand.Read(spanId[:])
+
+	span := &TestSpan{
+		provider: t.provider,
+		name:     name,
+		parent:   parent,
+		kind:     config.SpanKind(),
+		spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceId,
+			SpanID:     spanId,
+			TraceFlags: trace.FlagsSampled,
+		}),
+		attributes: config.Attributes(),
+	}
+
+	t.provider.mux.Lock()
+	t.provider.spans = append(t.provider.spans, span)
+	t.provider.mux.Unlock()
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// TestSpan is a recorded span of a TestTracerProvider.
+type TestSpan struct {
+	embedded.Span
+
+	provider *TestTracerProvider
+
+	mux         sync.Mutex
+	name        string
+	kind        trace.SpanKind
+	parent      trace.SpanContext
+	spanContext trace.SpanContext
+	attributes  []attribute.KeyValue
+	status      codes.Code
+	ended       bool
+}
+
+// Name returns the span name.
+func (s *TestSpan) Name() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.name
+}
+
+// Kind returns the span kind.
+func (s *TestSpan) Kind() trace.SpanKind {
+	return s.kind
+}
+
+// Parent returns the parent span context (could be invalid for root spans).
+func (s *TestSpan) Parent() trace.SpanContext {
+	return s.parent
+}
+
+// Attributes returns the span attributes.
+func (s *TestSpan) Attributes() []attribute.KeyValue {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return append([]attribute.KeyValue(nil), s.attributes...)
+}
+
+// Attribute returns the value of a single span attribute (if exists).
+func (s *TestSpan) Attribute(key string) (attribute.Value, bool) {
+	for _, attr := range s.Attributes() {
+		if string(attr.Key) == key {
+			return attr.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+// Status returns the span status code.
+func (s *TestSpan) Status() codes.Code {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.status
+}
+
+// Ended reports whether the span End was called.
+func (s *TestSpan) Ended() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.ended
+}
+
+// End implements [trace.Span.End].
+func (s *TestSpan) End(options ...trace.SpanEndOption) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.ended = true
+}
+
+// AddEvent implements [trace.Span.AddEvent].
+func (s *TestSpan) AddEvent(name string, options ...trace.EventOption) {}
+
+// AddLink implements [trace.Span.AddLink].
+func (s *TestSpan) AddLink(link trace.Link) {}
+
+// IsRecording implements [trace.Span.IsRecording].
+func (s *TestSpan) IsRecording() bool {
+	return !s.Ended()
+}
+
+// RecordError implements [trace.Span.RecordError].
+func (s *TestSpan) RecordError(err error, options ...trace.EventOption) {}
+
+// SpanContext implements [trace.Span.SpanContext].
+func (s *TestSpan) SpanContext() trace.SpanContext {
+	return s.spanContext
+}
+
+// SetStatus implements [trace.Span.SetStatus].
+func (s *TestSpan) SetStatus(code codes.Code, description string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.status = code
+}
+
+// SetName implements [trace.Span.SetName].
+func (s *TestSpan) SetName(name string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.name = name
+}
+
+// SetAttributes implements [trace.Span.SetAttributes].
+func (s *TestSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.attributes = append(s.attributes, kv...)
+}
+
+// TracerProvider implements [trace.Span.TracerProvider].
+func (s *TestSpan) TracerProvider() trace.TracerProvider {
+	return s.provider
+}
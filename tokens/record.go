@@ -2,6 +2,7 @@ package tokens
 
 import (
 	"errors"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pocketbase/pocketbase/core"
@@ -26,6 +27,35 @@ func NewRecordAuthToken(app core.App, record *models.Record) (string, error) {
 	)
 }
 
+// NewRecordImpersonateToken generates and returns a new auth record
+// authentication token issued by the provided admin.
+//
+// The token has an extra "impersonatedBy" claim with the admin id and
+// it is signed with the token keys of both the auth record and the admin.
+//
+// The token expires after duration seconds (or after the default
+// auth token duration if duration is not positive).
+func NewRecordImpersonateToken(app core.App, record *models.Record, admin *models.Admin, duration int64) (string, error) {
+	if !record.Collection().IsAuth() {
+		return "", errors.New("the record is not from an auth collection")
+	}
+
+	if duration <= 0 {
+		duration = app.Settings().RecordAuthToken.Duration
+	}
+
+	return security.NewJWT(
+		jwt.MapClaims{
+			"id":             record.Id,
+			"type":           TypeAuthRecord,
+			"collectionId":   record.Collection().Id,
+			"impersonatedBy": admin.Id,
+		},
+		(record.TokenKey() + admin.TokenKey + app.Settings().RecordAuthToken.Secret),
+		duration,
+	)
+}
+
 // NewRecordVerifyToken generates and returns a new record verification token.
 func NewRecordVerifyToken(app core.App, record *models.Record) (string, error) {
 	if !record.Collection().IsAuth() {
@@ -93,3 +123,31 @@ func NewRecordFileToken(app core.App, record *models.Record) (string, error) {
 		app.Settings().RecordFileToken.Duration,
 	)
 }
+
+// NewRecordUploadToken generates and returns a new record file field
+// direct upload confirmation token bound to the provided stored file name.
+func NewRecordUploadToken(app core.App, record *models.Record, field string, filename string, secondsDuration int64) (string, error) {
+	return security.NewJWT(
+		jwt.MapClaims{
+			"id":           record.Id,
+			"type":         TypeRecordUpload,
+			"collectionId": record.Collection().Id,
+			"field":        field,
+			"filename":     filename,
+		},
+		app.Settings().RecordFileToken.Secret,
+		secondsDuration,
+	)
+}
+
+// NewRecordShareToken generates and returns a new public record share token
+// signed with the app Settings.RecordShareToken secret (see [daos.Dao.NewRecordShareToken]).
+//
+// If duration is not positive, the default share token duration is used.
+func NewRecordShareToken(app core.App, record *models.Record, duration time.Duration, fields ...string) (string, error) {
+	if duration <= 0 {
+		duration = time.Duration(app.Settings().RecordShareToken.Duration) * time.Second
+	}
+
+	return app.Dao().NewRecordShareToken(record, app.Settings().RecordShareToken.Secret, duration, fields...)
+}
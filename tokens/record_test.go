@@ -32,6 +32,48 @@ func TestNewRecordAuthToken(t *testing.T) {
 	}
 }
 
+func TestNewRecordImpersonateToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokens.NewRecordImpersonateToken(app, user, admin, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// shouldn't be valid as a regular auth token
+	regularRecord, _ := app.Dao().FindAuthRecordByToken(
+		token,
+		app.Settings().RecordAuthToken.Secret,
+	)
+	if regularRecord != nil {
+		t.Fatalf("Expected the impersonation token to not be a valid regular auth token")
+	}
+
+	tokenRecord, tokenAdmin, _ := app.Dao().FindAuthRecordByImpersonateToken(
+		token,
+		app.Settings().RecordAuthToken.Secret,
+	)
+	if tokenRecord == nil || tokenRecord.Id != user.Id {
+		t.Fatalf("Expected auth record %v, got %v", user, tokenRecord)
+	}
+	if tokenAdmin == nil || tokenAdmin.Id != admin.Id {
+		t.Fatalf("Expected admin %v, got %v", admin, tokenAdmin)
+	}
+}
+
 func TestNewRecordVerifyToken(t *testing.T) {
 	t.Parallel()
 
@@ -131,3 +173,41 @@ func TestNewRecordFileToken(t *testing.T) {
 		t.Fatalf("Expected auth record %v, got %v", user, tokenRecord)
 	}
 }
+
+func TestNewRecordShareToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sharing is not enabled
+	if _, err := tokens.NewRecordShareToken(app, user, 0); err == nil {
+		t.Fatal("Expected error for collection without allowShare")
+	}
+
+	user.Collection().Options["allowShare"] = true
+	if err := app.Dao().SaveCollection(user.Collection()); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokens.NewRecordShareToken(app, user, 0, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenRecord, fields, _ := app.Dao().FindRecordByShareToken(
+		token,
+		app.Settings().RecordShareToken.Secret,
+	)
+	if tokenRecord == nil || tokenRecord.Id != user.Id {
+		t.Fatalf("Expected record %v, got %v", user, tokenRecord)
+	}
+	if len(fields) != 1 || fields[0] != "name" {
+		t.Fatalf("Expected fields [name], got %v", fields)
+	}
+}
@@ -2,6 +2,7 @@
 package tokens
 
 const (
-	TypeAdmin      = "admin"
-	TypeAuthRecord = "authRecord"
+	TypeAdmin        = "admin"
+	TypeAuthRecord   = "authRecord"
+	TypeRecordUpload = "recordUpload"
 )
@@ -25,8 +25,24 @@ func Create(src string, dest string, skipPaths ...string) error {
 	}
 	defer zf.Close()
 
-	zw := zip.NewWriter(zf)
-	defer zw.Close()
+	if err := CreateStream(zf, src, skipPaths...); err != nil {
+		// try to cleanup at least the created zip file
+		zf.Close()
+		os.Remove(dest)
+
+		return err
+	}
+
+	return nil
+}
+
+// CreateStream creates a new zip archive from src dir content and writes it to w
+// (eg. for uploading the archive while it is being generated).
+//
+// You can specify skipPaths to skip/ignore certain directories and files (relative to src)
+// preventing adding them in the final archive.
+func CreateStream(w io.Writer, src string, skipPaths ...string) error {
+	zw := zip.NewWriter(w)
 
 	// register a custom Deflate compressor
 	zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
@@ -34,13 +50,11 @@ func Create(src string, dest string, skipPaths ...string) error {
 	})
 
 	if err := zipAddFS(zw, os.DirFS(src), skipPaths...); err != nil {
-		// try to cleanup at least the created zip file
-		os.Remove(dest)
-
+		zw.Close()
 		return err
 	}
 
-	return nil
+	return zw.Close()
 }
 
 // note remove after similar method is added in the std lib (https://github.com/golang/go/issues/54898)
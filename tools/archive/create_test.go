@@ -1,6 +1,8 @@
 package archive_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
@@ -54,6 +56,41 @@ func TestCreateSuccess(t *testing.T) {
 	}
 }
 
+func TestCreateStream(t *testing.T) {
+	testDir := createTestDir(t)
+	defer os.RemoveAll(testDir)
+
+	var buf bytes.Buffer
+
+	if err := archive.CreateStream(&buf, testDir, "a/b/c", "test"); err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read the generated archive: %v", err)
+	}
+
+	names := make([]string, len(zr.File))
+	for i, f := range zr.File {
+		names[i] = f.Name
+	}
+
+	expected := []string{"a/b/sub1", "a/test", "test2", "test_symlink"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected archive files %v, got %v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Fatalf("Expected archive files %v, got %v", expected, names)
+		}
+	}
+
+	if err := archive.CreateStream(&buf, filepath.Join(os.TempDir(), "missing")); err == nil {
+		t.Fatal("Expected to fail due to missing directory")
+	}
+}
+
 // -------------------------------------------------------------------
 
 // note: make sure to call os.RemoveAll(dir) after you are done
@@ -2,14 +2,8 @@ package auth
 
 import (
 	"context"
-	"crypto/rsa"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
-	"math/big"
-	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -28,8 +22,6 @@ const NameApple string = "apple"
 // [OIDC differences]: https://bitbucket.org/openid/connect/src/master/How-Sign-in-with-Apple-differs-from-OpenID-Connect.md
 type Apple struct {
 	*baseProvider
-
-	jwksUrl string
 }
 
 // NewAppleProvider creates a new Apple provider instance with some defaults.
@@ -42,8 +34,8 @@ func NewAppleProvider() *Apple {
 			scopes:      []string{"name", "email"},
 			authUrl:     "https://appleid.apple.com/auth/authorize",
 			tokenUrl:    "https://appleid.apple.com/auth/token",
+			jwksUrl:     "https://appleid.apple.com/auth/keys",
 		},
-		jwksUrl: "https://appleid.apple.com/auth/keys",
 	}
 }
 
@@ -61,25 +53,16 @@ func (p *Apple) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
 		return nil, err
 	}
 
-	extracted := struct {
-		Id            string `json:"sub"`
-		Name          string `json:"name"`
-		Email         string `json:"email"`
-		EmailVerified any    `json:"email_verified"` // could be string or bool
-		User          struct {
-			Name struct {
-				FirstName string `json:"firstName"`
-				LastName  string `json:"lastName"`
-			} `json:"name"`
-		} `json:"user"`
-	}{}
-	if err := json.Unmarshal(data, &extracted); err != nil {
-		return nil, err
+	id, ok := extractString(rawUser, "sub")
+	if !ok || id == "" {
+		return nil, errMissingSubClaim
 	}
 
+	name, _ := extractString(rawUser, "name")
+
 	user := &AuthUser{
-		Id:           extracted.Id,
-		Name:         extracted.Name,
+		Id:           id,
+		Name:         name,
 		RawUser:      rawUser,
 		AccessToken:  token.AccessToken,
 		RefreshToken: token.RefreshToken,
@@ -87,12 +70,17 @@ func (p *Apple) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
 
 	user.Expiry, _ = types.ParseDateTime(token.Expiry)
 
-	if cast.ToBool(extracted.EmailVerified) {
-		user.Email = extracted.Email
+	// email_verified could be string or bool
+	if cast.ToBool(rawUser["email_verified"]) {
+		user.Email, _ = extractString(rawUser, "email")
 	}
 
 	if user.Name == "" {
-		user.Name = strings.TrimSpace(extracted.User.Name.FirstName + " " + extracted.User.Name.LastName)
+		appleUser, _ := rawUser["user"].(map[string]any)
+		appleUserName, _ := appleUser["name"].(map[string]any)
+		firstName, _ := extractString(appleUserName, "firstName")
+		lastName, _ := extractString(appleUserName, "lastName")
+		user.Name = strings.TrimSpace(firstName + " " + lastName)
 	}
 
 	return user, nil
@@ -128,14 +116,7 @@ func (p *Apple) FetchRawUserData(token *oauth2.Token) ([]byte, error) {
 // -------------------------------------------------------------------
 
 func (p *Apple) parseAndVerifyIdToken(idToken string) (jwt.MapClaims, error) {
-	if idToken == "" {
-		return nil, errors.New("empty id_token")
-	}
-
-	// extract the token header params and claims
-	// ---
-	claims := jwt.MapClaims{}
-	t, _, err := jwt.NewParser().ParseUnverified(idToken, claims)
+	claims, err := p.verifyIdTokenSignature(idToken)
 	if err != nil {
 		return nil, err
 	}
@@ -150,102 +131,5 @@ func (p *Apple) parseAndVerifyIdToken(idToken string) (jwt.MapClaims, error) {
 		return nil, errors.New("aud must be the developer's client_id")
 	}
 
-	// fetch the public key set
-	// ---
-	kid, _ := t.Header["kid"].(string)
-	if kid == "" {
-		return nil, errors.New("missing kid header value")
-	}
-
-	key, err := p.fetchJWK(kid)
-	if err != nil {
-		return nil, err
-	}
-
-	// decode the key params per RFC 7518 (https://tools.ietf.org/html/rfc7518#section-6.3)
-	// and construct a valid publicKey from them
-	// ---
-	exponent, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.E, "="))
-	if err != nil {
-		return nil, err
-	}
-
-	modulus, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.N, "="))
-	if err != nil {
-		return nil, err
-	}
-
-	publicKey := &rsa.PublicKey{
-		// https://tools.ietf.org/html/rfc7517#appendix-A.1
-		E: int(big.NewInt(0).SetBytes(exponent).Uint64()),
-		N: big.NewInt(0).SetBytes(modulus),
-	}
-
-	// verify the id_token
-	// ---
-	parser := jwt.NewParser(jwt.WithValidMethods([]string{key.Alg}))
-
-	parsedToken, err := parser.Parse(idToken, func(t *jwt.Token) (any, error) {
-		return publicKey, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
-		return claims, nil
-	}
-
-	return nil, errors.New("the parsed id_token is invalid")
-}
-
-type jwk struct {
-	Kty string
-	Kid string
-	Use string
-	Alg string
-	N   string
-	E   string
-}
-
-func (p *Apple) fetchJWK(kid string) (*jwk, error) {
-	req, err := http.NewRequestWithContext(p.ctx, "GET", p.jwksUrl, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	rawBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// http.Client.Get doesn't treat non 2xx responses as error
-	if res.StatusCode >= 400 {
-		return nil, fmt.Errorf(
-			"failed to verify the provided id_token (%d):\n%s",
-			res.StatusCode,
-			string(rawBody),
-		)
-	}
-
-	jwks := struct {
-		Keys []*jwk
-	}{}
-	if err := json.Unmarshal(rawBody, &jwks); err != nil {
-		return nil, err
-	}
-
-	for _, key := range jwks.Keys {
-		if key.Kid == kid {
-			return key, nil
-		}
-	}
-
-	return nil, fmt.Errorf("jwk with kid %q was not found", kid)
+	return claims, nil
 }
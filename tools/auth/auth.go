@@ -9,6 +9,10 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// errMissingSubClaim is returned when the OAuth2 user data
+// doesn't have the required "sub" (aka. user identifier) claim.
+var errMissingSubClaim = errors.New("missing required sub claim")
+
 // AuthUser defines a standardized oauth2 user data structure.
 type AuthUser struct {
 	Id           string         `json:"id"`
@@ -86,6 +90,21 @@ type Provider interface {
 	// SetUserApiUrl sets the provider's UserApiUrl.
 	SetUserApiUrl(url string)
 
+	// JWKSUrl returns the provider's JSON Web Key Set url
+	// used for verifying the id_token signature (if any).
+	JWKSUrl() string
+
+	// SetJWKSUrl sets the provider's JWKSUrl.
+	SetJWKSUrl(url string)
+
+	// HTTPClient returns the base http client used by the provider
+	// for its outbound requests (token exchange, user info, JWKS, etc.).
+	HTTPClient() *http.Client
+
+	// SetHTTPClient sets the provider's base http client
+	// (eg. to route the requests through a proxy or to change the default timeout).
+	SetHTTPClient(client *http.Client)
+
 	// Client returns an http client using the provided token.
 	Client(token *oauth2.Token) *http.Client
 
@@ -96,6 +115,10 @@ type Provider interface {
 	// FetchToken converts an authorization code to token.
 	FetchToken(code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error)
 
+	// RefreshToken exchanges the specified refresh token for a new token
+	// using the provider's token endpoint.
+	RefreshToken(refreshToken string) (*oauth2.Token, error)
+
 	// FetchRawUserData requests and marshalizes into `result` the
 	// the OAuth user api response.
 	FetchRawUserData(token *oauth2.Token) ([]byte, error)
@@ -105,60 +128,69 @@ type Provider interface {
 	FetchAuthUser(token *oauth2.Token) (user *AuthUser, err error)
 }
 
+// ProviderFactoryFunc defines a function for initializing a new OAuth2 provider.
+type ProviderFactoryFunc func() Provider
+
+// Providers defines a map with all of the available OAuth2 providers.
+//
+// To register a new provider, add a new entry in the map
+// (or use [RegisterOIDCProvider] for generic OIDC based providers).
+//
+// Note that the map is not safe for concurrent writes and it is
+// expected to be modified only during the app initialization.
+var Providers = map[string]ProviderFactoryFunc{
+	NameGoogle:         wrapFactory(NewGoogleProvider),
+	NameFacebook:       wrapFactory(NewFacebookProvider),
+	NameGithub:         wrapFactory(NewGithubProvider),
+	NameGitlab:         wrapFactory(NewGitlabProvider),
+	NameDiscord:        wrapFactory(NewDiscordProvider),
+	NameTwitter:        wrapFactory(NewTwitterProvider),
+	NameMicrosoft:      wrapFactory(NewMicrosoftProvider),
+	NameSpotify:        wrapFactory(NewSpotifyProvider),
+	NameKakao:          wrapFactory(NewKakaoProvider),
+	NameTwitch:         wrapFactory(NewTwitchProvider),
+	NameStrava:         wrapFactory(NewStravaProvider),
+	NameGitee:          wrapFactory(NewGiteeProvider),
+	NameLivechat:       wrapFactory(NewLivechatProvider),
+	NameGitea:          wrapFactory(NewGiteaProvider),
+	NameOIDC:           wrapFactory(NewOIDCProvider),
+	NameOIDC + "2":     wrapFactory(NewOIDCProvider),
+	NameOIDC + "3":     wrapFactory(NewOIDCProvider),
+	NameApple:          wrapFactory(NewAppleProvider),
+	NameInstagram:      wrapFactory(NewInstagramProvider),
+	NameVK:             wrapFactory(NewVKProvider),
+	NameYandex:         wrapFactory(NewYandexProvider),
+	NamePatreon:        wrapFactory(NewPatreonProvider),
+	NameMailcow:        wrapFactory(NewMailcowProvider),
+	NameBitbucket:      wrapFactory(NewBitbucketProvider),
+	NamePlanningcenter: wrapFactory(NewPlanningcenterProvider),
+	NameZoho:           wrapFactory(NewZohoProvider),
+	NameSlack:          wrapFactory(NewSlackProvider),
+}
+
 // NewProviderByName returns a new preconfigured provider instance by its name identifier.
 func NewProviderByName(name string) (Provider, error) {
-	switch name {
-	case NameGoogle:
-		return NewGoogleProvider(), nil
-	case NameFacebook:
-		return NewFacebookProvider(), nil
-	case NameGithub:
-		return NewGithubProvider(), nil
-	case NameGitlab:
-		return NewGitlabProvider(), nil
-	case NameDiscord:
-		return NewDiscordProvider(), nil
-	case NameTwitter:
-		return NewTwitterProvider(), nil
-	case NameMicrosoft:
-		return NewMicrosoftProvider(), nil
-	case NameSpotify:
-		return NewSpotifyProvider(), nil
-	case NameKakao:
-		return NewKakaoProvider(), nil
-	case NameTwitch:
-		return NewTwitchProvider(), nil
-	case NameStrava:
-		return NewStravaProvider(), nil
-	case NameGitee:
-		return NewGiteeProvider(), nil
-	case NameLivechat:
-		return NewLivechatProvider(), nil
-	case NameGitea:
-		return NewGiteaProvider(), nil
-	case NameOIDC:
-		return NewOIDCProvider(), nil
-	case NameOIDC + "2":
-		return NewOIDCProvider(), nil
-	case NameOIDC + "3":
-		return NewOIDCProvider(), nil
-	case NameApple:
-		return NewAppleProvider(), nil
-	case NameInstagram:
-		return NewInstagramProvider(), nil
-	case NameVK:
-		return NewVKProvider(), nil
-	case NameYandex:
-		return NewYandexProvider(), nil
-	case NamePatreon:
-		return NewPatreonProvider(), nil
-	case NameMailcow:
-		return NewMailcowProvider(), nil
-	case NameBitbucket:
-		return NewBitbucketProvider(), nil
-	case NamePlanningcenter:
-		return NewPlanningcenterProvider(), nil
-	default:
+	factory, ok := Providers[name]
+	if !ok {
 		return nil, errors.New("Missing provider " + name)
 	}
+
+	return factory(), nil
+}
+
+// wrapFactory is a helper that wraps a concrete provider factory
+// function into a generic ProviderFactoryFunc.
+func wrapFactory[T Provider](factory func() T) ProviderFactoryFunc {
+	return func() Provider {
+		return factory()
+	}
+}
+
+// extractString returns the string value of the specified claims key.
+//
+// The returned bool is false if the key is missing or its value is not a string
+// (for optional claims the zero value could be safely used as a fallback).
+func extractString(claims map[string]any, key string) (string, bool) {
+	v, ok := claims[key].(string)
+	return v, ok
 }
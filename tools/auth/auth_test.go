@@ -1,9 +1,15 @@
 package auth_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"golang.org/x/oauth2"
 )
 
 func TestNewProviderByName(t *testing.T) {
@@ -243,4 +249,436 @@ func TestNewProviderByName(t *testing.T) {
 	if _, ok := p.(*auth.Planningcenter); !ok {
 		t.Error("Expected to be instance of *auth.Planningcenter")
 	}
+
+	// zoho
+	p, err = auth.NewProviderByName(auth.NameZoho)
+	if err != nil {
+		t.Errorf("Expected nil, got error %v", err)
+	}
+	if _, ok := p.(*auth.Zoho); !ok {
+		t.Error("Expected to be instance of *auth.Zoho")
+	}
+
+	// slack
+	p, err = auth.NewProviderByName(auth.NameSlack)
+	if err != nil {
+		t.Errorf("Expected nil, got error %v", err)
+	}
+	if _, ok := p.(*auth.Slack); !ok {
+		t.Error("Expected to be instance of *auth.Slack")
+	}
+}
+
+func TestFetchAuthUserPartialClaims(t *testing.T) {
+	newIdToken := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	scenarios := []struct {
+		name        string
+		claims      jwt.MapClaims
+		expectError bool
+		expectUser  *auth.AuthUser
+	}{
+		{
+			"missing sub",
+			jwt.MapClaims{"name": "test", "email": "test@example.com", "email_verified": true},
+			true,
+			nil,
+		},
+		{
+			"empty sub",
+			jwt.MapClaims{"sub": ""},
+			true,
+			nil,
+		},
+		{
+			"non-string sub",
+			jwt.MapClaims{"sub": 123},
+			true,
+			nil,
+		},
+		{
+			"only sub",
+			jwt.MapClaims{"sub": "test_id"},
+			false,
+			&auth.AuthUser{Id: "test_id"},
+		},
+		{
+			"non-string optional claims",
+			jwt.MapClaims{"sub": "test_id", "name": 123, "picture": false, "email": []string{"a"}, "email_verified": true},
+			false,
+			&auth.AuthUser{Id: "test_id"},
+		},
+		{
+			"unverified email",
+			jwt.MapClaims{"sub": "test_id", "name": "test", "email": "test@example.com"},
+			false,
+			&auth.AuthUser{Id: "test_id", Name: "test"},
+		},
+		{
+			"all claims",
+			jwt.MapClaims{"sub": "test_id", "name": "test", "picture": "https://example.com/a.png", "email": "test@example.com", "email_verified": "true"},
+			false,
+			&auth.AuthUser{Id: "test_id", Name: "test", Email: "test@example.com", AvatarUrl: "https://example.com/a.png"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run("zoho_"+s.name, func(t *testing.T) {
+			claims := jwt.MapClaims{"iss": "https://accounts.zoho.eu", "aud": "test_client"}
+			for k, v := range s.claims {
+				claims[k] = v
+			}
+
+			p := auth.NewZohoProvider()
+			p.SetClientId("test_client")
+			p.SetSkipIdTokenVerification(true)
+
+			token := (&oauth2.Token{}).WithExtra(map[string]any{"id_token": newIdToken(claims)})
+
+			user, err := p.FetchAuthUser(token)
+
+			checkPartialClaimsUser(t, s.expectError, s.expectUser, user, err, true)
+		})
+
+		t.Run("apple_"+s.name, func(t *testing.T) {
+			claims := jwt.MapClaims{"iss": "https://appleid.apple.com", "aud": "test_client"}
+			for k, v := range s.claims {
+				claims[k] = v
+			}
+
+			p := auth.NewAppleProvider()
+			p.SetClientId("test_client")
+			p.SetSkipIdTokenVerification(true)
+
+			token := (&oauth2.Token{}).WithExtra(map[string]any{"id_token": newIdToken(claims)})
+
+			user, err := p.FetchAuthUser(token)
+
+			checkPartialClaimsUser(t, s.expectError, s.expectUser, user, err, false)
+		})
+
+		t.Run("oidc_"+s.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(s.claims)
+			}))
+			defer server.Close()
+
+			p := auth.NewOIDCProvider()
+			p.SetUserApiUrl(server.URL)
+
+			user, err := p.FetchAuthUser(&oauth2.Token{AccessToken: "test"})
+
+			checkPartialClaimsUser(t, s.expectError, s.expectUser, user, err, true)
+		})
+	}
+}
+
+func checkPartialClaimsUser(t *testing.T, expectError bool, expected *auth.AuthUser, user *auth.AuthUser, err error, checkAvatar bool) {
+	hasErr := err != nil
+	if hasErr != expectError {
+		t.Fatalf("Expected hasErr %v, got %v (%v)", expectError, hasErr, err)
+	}
+
+	if hasErr {
+		return
+	}
+
+	if user.Id != expected.Id {
+		t.Fatalf("Expected Id %q, got %q", expected.Id, user.Id)
+	}
+
+	if user.Name != expected.Name {
+		t.Fatalf("Expected Name %q, got %q", expected.Name, user.Name)
+	}
+
+	if user.Email != expected.Email {
+		t.Fatalf("Expected Email %q, got %q", expected.Email, user.Email)
+	}
+
+	if checkAvatar && user.AvatarUrl != expected.AvatarUrl {
+		t.Fatalf("Expected AvatarUrl %q, got %q", expected.AvatarUrl, user.AvatarUrl)
+	}
+}
+
+func TestRegisterOIDCProvider(t *testing.T) {
+	name := "test_corp"
+
+	if _, err := auth.NewProviderByName(name); err == nil {
+		t.Fatalf("Expected %q provider to be missing", name)
+	}
+
+	auth.RegisterOIDCProvider(name, auth.OIDCConfig{
+		DisplayName: "Corp",
+		AuthUrl:     "https://example.com/auth",
+		TokenUrl:    "https://example.com/token",
+		UserInfoUrl: "https://example.com/userinfo",
+		JWKSUrl:     "https://example.com/jwks",
+		Issuer:      "https://example.com",
+		Scopes:      []string{"openid", "corp"},
+		ClaimsMap:   auth.OIDCClaimsMap{Id: "employee_id"},
+	})
+	defer delete(auth.Providers, name)
+
+	if _, ok := auth.Providers[name]; !ok {
+		t.Fatalf("Expected %q to be registered in auth.Providers", name)
+	}
+
+	p, err := auth.NewProviderByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oidc, ok := p.(*auth.OIDC)
+	if !ok {
+		t.Fatalf("Expected to be instance of *auth.OIDC, got %T", p)
+	}
+
+	checks := map[string][2]string{
+		"displayName": {"Corp", oidc.DisplayName()},
+		"authUrl":     {"https://example.com/auth", oidc.AuthUrl()},
+		"tokenUrl":    {"https://example.com/token", oidc.TokenUrl()},
+		"userApiUrl":  {"https://example.com/userinfo", oidc.UserApiUrl()},
+		"jwksUrl":     {"https://example.com/jwks", oidc.JWKSUrl()},
+		"issuer":      {"https://example.com", oidc.Issuer()},
+		"claimsMapId": {"employee_id", oidc.ClaimsMap().Id},
+	}
+	for k, v := range checks {
+		if v[0] != v[1] {
+			t.Errorf("[%s] Expected %q, got %q", k, v[0], v[1])
+		}
+	}
+
+	if len(oidc.Scopes()) != 2 || oidc.Scopes()[1] != "corp" {
+		t.Errorf("Expected the config scopes, got %v", oidc.Scopes())
+	}
+
+	if !oidc.PKCE() {
+		t.Error("Expected PKCE to be enabled by default")
+	}
+
+	// each call should return a new provider instance
+	p2, _ := auth.NewProviderByName(name)
+	if p2 == p {
+		t.Fatal("Expected a new provider instance")
+	}
+}
+
+func TestOIDCClaimsMapping(t *testing.T) {
+	rawClaims := map[string]any{
+		"sub":              "default_sub",
+		"employee_id":      "test_id",
+		"display_name":     "test_name",
+		"corp_email":       "test@example.com",
+		"corp_email_valid": true,
+		"login":            "test_username",
+		"photo":            "https://example.com/a.png",
+	}
+
+	claimsMap := auth.OIDCClaimsMap{
+		Id:            "employee_id",
+		Name:          "display_name",
+		Email:         "corp_email",
+		EmailVerified: "corp_email_valid",
+		Username:      "login",
+		AvatarUrl:     "photo",
+	}
+
+	expected := &auth.AuthUser{
+		Id:        "test_id",
+		Name:      "test_name",
+		Email:     "test@example.com",
+		Username:  "test_username",
+		AvatarUrl: "https://example.com/a.png",
+	}
+
+	checkUser := func(t *testing.T, user *auth.AuthUser) {
+		if user.Id != expected.Id ||
+			user.Name != expected.Name ||
+			user.Email != expected.Email ||
+			user.Username != expected.Username ||
+			user.AvatarUrl != expected.AvatarUrl {
+			t.Fatalf("Expected user\n%v\ngot\n%v", expected, user)
+		}
+	}
+
+	t.Run("user info", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(rawClaims)
+		}))
+		defer server.Close()
+
+		p := auth.NewOIDCProviderWithConfig(auth.OIDCConfig{
+			UserInfoUrl: server.URL,
+			ClaimsMap:   claimsMap,
+		})
+
+		user, err := p.FetchAuthUser(&oauth2.Token{AccessToken: "test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkUser(t, user)
+	})
+
+	t.Run("id_token fallback", func(t *testing.T) {
+		claims := jwt.MapClaims{"aud": "test_client", "iss": "https://example.com"}
+		for k, v := range rawClaims {
+			claims[k] = v
+		}
+
+		idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token := (&oauth2.Token{}).WithExtra(map[string]any{"id_token": idToken})
+
+		p := auth.NewOIDCProviderWithConfig(auth.OIDCConfig{
+			Issuer:    "https://example.com",
+			ClaimsMap: claimsMap,
+		})
+		p.SetClientId("test_client")
+
+		// signature verification without jwksUrl
+		if _, err := p.FetchAuthUser(token); err == nil {
+			t.Fatal("Expected error due to the missing jwks url")
+		}
+
+		p.SetSkipIdTokenVerification(true)
+
+		user, err := p.FetchAuthUser(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkUser(t, user)
+
+		// issuer mismatch
+		p.SetIssuer("https://example.org")
+		if _, err := p.FetchAuthUser(token); err == nil {
+			t.Fatal("Expected issuer mismatch error")
+		}
+	})
+}
+
+func TestSlackFetchAuthUser(t *testing.T) {
+	// representative Sign in with Slack id_token payload
+	// (https://api.slack.com/authentication/sign-in-with-slack#response)
+	claims := jwt.MapClaims{
+		"iss":                              "https://slack.com",
+		"sub":                              "U0R7JM",
+		"aud":                              "test_client",
+		"exp":                              time.Now().Add(1 * time.Hour).Unix(),
+		"iat":                              time.Now().Unix(),
+		"auth_time":                        time.Now().Unix(),
+		"nonce":                            "abcd",
+		"at_hash":                          "tUKW9GQZ_c6NnYhqbT4PQg",
+		"https://slack.com/team_id":        "T0R7GR",
+		"https://slack.com/user_id":        "U0R7JM",
+		"email":                            "krane@slack-corp.com",
+		"email_verified":                   true,
+		"date_email_verified":              1622128723,
+		"locale":                           "en-US",
+		"name":                             "krane",
+		"picture":                          "https://secure.gravatar.com/avatar/bc.png",
+		"given_name":                       "",
+		"family_name":                      "",
+		"https://slack.com/team_name":      "kraneflannel",
+		"https://slack.com/team_domain":    "kraneflannel",
+		"https://slack.com/user_image_512": "https://secure.gravatar.com/avatar/bc-512.png",
+	}
+
+	newToken := func(c jwt.MapClaims) *oauth2.Token {
+		idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte("test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return (&oauth2.Token{AccessToken: "test_access"}).WithExtra(map[string]any{"id_token": idToken})
+	}
+
+	p := auth.NewSlackProvider()
+	p.SetClientId("test_client")
+	p.SetSkipIdTokenVerification(true)
+
+	t.Run("valid id_token", func(t *testing.T) {
+		user, err := p.FetchAuthUser(newToken(claims))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if user.Id != "U0R7JM" {
+			t.Fatalf("Expected id %q, got %q", "U0R7JM", user.Id)
+		}
+
+		if user.Email != "krane@slack-corp.com" {
+			t.Fatalf("Expected email %q, got %q", "krane@slack-corp.com", user.Email)
+		}
+
+		if user.Name != "krane" {
+			t.Fatalf("Expected name %q, got %q", "krane", user.Name)
+		}
+
+		if user.AvatarUrl != "https://secure.gravatar.com/avatar/bc.png" {
+			t.Fatalf("Expected avatar %q, got %q", "https://secure.gravatar.com/avatar/bc.png", user.AvatarUrl)
+		}
+
+		if user.AccessToken != "test_access" {
+			t.Fatalf("Expected access token %q, got %q", "test_access", user.AccessToken)
+		}
+
+		if v := user.RawUser["team_id"]; v != "T0R7GR" {
+			t.Fatalf("Expected RawUser team_id %q, got %v", "T0R7GR", v)
+		}
+
+		if v := user.RawUser["https://slack.com/team_id"]; v != "T0R7GR" {
+			t.Fatalf("Expected RawUser https://slack.com/team_id %q, got %v", "T0R7GR", v)
+		}
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		c := jwt.MapClaims{}
+		for k, v := range claims {
+			c[k] = v
+		}
+		c["email_verified"] = false
+
+		user, err := p.FetchAuthUser(newToken(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if user.Email != "" {
+			t.Fatalf("Expected empty email, got %q", user.Email)
+		}
+	})
+
+	t.Run("invalid issuer", func(t *testing.T) {
+		c := jwt.MapClaims{}
+		for k, v := range claims {
+			c[k] = v
+		}
+		c["iss"] = "https://example.com"
+
+		if _, err := p.FetchAuthUser(newToken(c)); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+
+	t.Run("invalid audience", func(t *testing.T) {
+		c := jwt.MapClaims{}
+		for k, v := range claims {
+			c[k] = v
+		}
+		c["aud"] = "other_client"
+
+		if _, err := p.FetchAuthUser(newToken(c)); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
 }
@@ -2,16 +2,28 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/oauth2"
 )
 
+// DefaultHTTPClientTimeout is the timeout of the default http client
+// used by the providers for their outbound requests.
+const DefaultHTTPClientTimeout = 30 * time.Second
+
+// defaultHTTPClient is the http client used by the providers
+// that doesn't have an explicitly set custom one.
+var defaultHTTPClient = &http.Client{Timeout: DefaultHTTPClientTimeout}
+
 // baseProvider defines common fields and methods used by OAuth2 client providers.
 type baseProvider struct {
 	ctx          context.Context
+	httpClient   *http.Client
 	clientId     string
 	clientSecret string
 	displayName  string
@@ -19,8 +31,11 @@ type baseProvider struct {
 	authUrl      string
 	tokenUrl     string
 	userApiUrl   string
+	jwksUrl      string
 	scopes       []string
 	pkce         bool
+
+	skipIdTokenVerification bool
 }
 
 // Context implements Provider.Context() interface method.
@@ -33,6 +48,22 @@ func (p *baseProvider) SetContext(ctx context.Context) {
 	p.ctx = ctx
 }
 
+// HTTPClient implements Provider.HTTPClient() interface method.
+func (p *baseProvider) HTTPClient() *http.Client {
+	if p.httpClient == nil {
+		return defaultHTTPClient
+	}
+
+	return p.httpClient
+}
+
+// SetHTTPClient implements Provider.SetHTTPClient() interface method.
+//
+// Set it to nil to restore the default http client.
+func (p *baseProvider) SetHTTPClient(client *http.Client) {
+	p.httpClient = client
+}
+
 // PKCE implements Provider.PKCE() interface method.
 func (p *baseProvider) PKCE() bool {
 	return p.pkce
@@ -123,6 +154,29 @@ func (p *baseProvider) SetUserApiUrl(url string) {
 	p.userApiUrl = url
 }
 
+// JWKSUrl implements Provider.JWKSUrl() interface method.
+func (p *baseProvider) JWKSUrl() string {
+	return p.jwksUrl
+}
+
+// SetJWKSUrl implements Provider.SetJWKSUrl() interface method.
+func (p *baseProvider) SetJWKSUrl(url string) {
+	p.jwksUrl = url
+}
+
+// SkipIdTokenVerification reports whether the id_token signature
+// verification is disabled.
+func (p *baseProvider) SkipIdTokenVerification() bool {
+	return p.skipIdTokenVerification
+}
+
+// SetSkipIdTokenVerification toggles the id_token signature verification.
+//
+// It is intended to be used only in tests with self-issued tokens!
+func (p *baseProvider) SetSkipIdTokenVerification(skip bool) {
+	p.skipIdTokenVerification = skip
+}
+
 // BuildAuthUrl implements Provider.BuildAuthUrl() interface method.
 func (p *baseProvider) BuildAuthUrl(state string, opts ...oauth2.AuthCodeOption) string {
 	return p.oauth2Config().AuthCodeURL(state, opts...)
@@ -130,12 +184,28 @@ func (p *baseProvider) BuildAuthUrl(state string, opts ...oauth2.AuthCodeOption)
 
 // FetchToken implements Provider.FetchToken() interface method.
 func (p *baseProvider) FetchToken(code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
-	return p.oauth2Config().Exchange(p.ctx, code, opts...)
+	return p.oauth2Config().Exchange(p.clientContext(), code, opts...)
+}
+
+// RefreshToken implements Provider.RefreshToken() interface method.
+func (p *baseProvider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	if refreshToken == "" {
+		return nil, errors.New("empty refresh token")
+	}
+
+	// the token source will perform a refresh request because the
+	// provided token doesn't have an access token
+	return p.oauth2Config().TokenSource(p.clientContext(), &oauth2.Token{RefreshToken: refreshToken}).Token()
 }
 
 // Client implements Provider.Client() interface method.
 func (p *baseProvider) Client(token *oauth2.Token) *http.Client {
-	return p.oauth2Config().Client(p.ctx, token)
+	client := p.oauth2Config().Client(p.clientContext(), token)
+
+	// the oauth2 client reuses only the transport of the context client
+	client.Timeout = p.HTTPClient().Timeout
+
+	return client
 }
 
 // FetchRawUserData implements Provider.FetchRawUserData() interface method.
@@ -176,6 +246,78 @@ func (p *baseProvider) sendRawUserDataRequest(req *http.Request, token *oauth2.T
 	return result, nil
 }
 
+// verifyIdTokenSignature validates the signature of the provided
+// id_token against the provider's JWKS and returns its claims.
+//
+// Only the signature and the standard time based claims (exp, nbf, iat)
+// are verified. The issuer and audience checks are left to the caller.
+func (p *baseProvider) verifyIdTokenSignature(idToken string) (jwt.MapClaims, error) {
+	if idToken == "" {
+		return nil, errors.New("empty id_token")
+	}
+
+	if p.skipIdTokenVerification {
+		claims := jwt.MapClaims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(idToken, claims); err != nil {
+			return nil, err
+		}
+		return claims, claims.Valid()
+	}
+
+	// extract the token header params
+	t, _, err := jwt.NewParser().ParseUnverified(idToken, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing kid header value")
+	}
+
+	key, err := fetchJWK(p.ctx, p.HTTPClient(), p.jwksUrl, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := key.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	validMethods := []string{"RS256", "ES256"}
+	if key.Alg != "" {
+		validMethods = []string{key.Alg}
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods(validMethods))
+
+	parsedToken, err := parser.Parse(idToken, func(t *jwt.Token) (any, error) {
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("the parsed id_token is invalid")
+}
+
+// clientContext returns a copy of the provider context with the
+// provider http client attached to it, so that it could be used
+// by the oauth2 package for the token and api requests.
+func (p *baseProvider) clientContext() context.Context {
+	ctx := p.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, oauth2.HTTPClient, p.HTTPClient())
+}
+
 // oauth2Config constructs a oauth2.Config instance based on the provider settings.
 func (p *baseProvider) oauth2Config() *oauth2.Config {
 	return &oauth2.Config{
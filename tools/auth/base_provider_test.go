@@ -2,8 +2,21 @@ package auth
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/oauth2"
 )
 
@@ -23,6 +36,79 @@ func TestContext(t *testing.T) {
 	}
 }
 
+func TestHTTPClient(t *testing.T) {
+	b := baseProvider{}
+
+	before := b.HTTPClient()
+	if before == nil || before.Timeout != DefaultHTTPClientTimeout {
+		t.Fatalf("Expected the default http client with %v timeout, got %v", DefaultHTTPClientTimeout, before)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	b.SetHTTPClient(client)
+
+	after := b.HTTPClient()
+	if after != client {
+		t.Fatalf("Expected the custom http client, got %v", after)
+	}
+
+	b.SetHTTPClient(nil)
+
+	if restored := b.HTTPClient(); restored != defaultHTTPClient {
+		t.Fatalf("Expected the default http client to be restored, got %v", restored)
+	}
+}
+
+func TestCustomHTTPClientRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test_access","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	var totalRequests atomic.Int32
+
+	b := baseProvider{
+		ctx:        context.Background(),
+		tokenUrl:   server.URL,
+		userApiUrl: server.URL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				totalRequests.Add(1)
+				return http.DefaultTransport.RoundTrip(r)
+			}),
+		},
+	}
+
+	token, err := b.FetchToken("test_code")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.RefreshToken("test_refresh"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.FetchRawUserData(token); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := totalRequests.Load(); total != 3 {
+		t.Fatalf("Expected 3 requests to be sent via the custom http client, got %d", total)
+	}
+
+	if timeout := b.Client(token).Timeout; timeout != 5*time.Second {
+		t.Fatalf("Expected the token client to inherit the custom timeout, got %v", timeout)
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
 func TestDisplayName(t *testing.T) {
 	b := baseProvider{}
 
@@ -167,6 +253,38 @@ func TestUserApiUrl(t *testing.T) {
 	}
 }
 
+func TestJWKSUrl(t *testing.T) {
+	b := baseProvider{}
+
+	before := b.JWKSUrl()
+	if before != "" {
+		t.Fatalf("Expected jwksUrl to be empty, got %v", before)
+	}
+
+	b.SetJWKSUrl("test")
+
+	after := b.JWKSUrl()
+	if after != "test" {
+		t.Fatalf("Expected jwksUrl to be 'test', got %v", after)
+	}
+}
+
+func TestSkipIdTokenVerification(t *testing.T) {
+	b := baseProvider{}
+
+	before := b.SkipIdTokenVerification()
+	if before != false {
+		t.Fatalf("Expected skipIdTokenVerification to be %v, got %v", false, before)
+	}
+
+	b.SetSkipIdTokenVerification(true)
+
+	after := b.SkipIdTokenVerification()
+	if after != true {
+		t.Fatalf("Expected skipIdTokenVerification to be %v, got %v", true, after)
+	}
+}
+
 func TestBuildAuthUrl(t *testing.T) {
 	b := baseProvider{
 		authUrl:      "authUrl_test",
@@ -190,7 +308,54 @@ func TestClient(t *testing.T) {
 
 	result := b.Client(&oauth2.Token{})
 	if result == nil {
-		t.Error("Expected *http.Client instance, got nil")
+		t.Fatal("Expected *http.Client instance, got nil")
+	}
+
+	if result.Timeout != DefaultHTTPClientTimeout {
+		t.Fatalf("Expected %v timeout, got %v", DefaultHTTPClientTimeout, result.Timeout)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "old_refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new_access","refresh_token":"new_refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	b := baseProvider{ctx: context.Background(), tokenUrl: server.URL}
+
+	if _, err := b.RefreshToken(""); err == nil {
+		t.Fatal("Expected error for empty refresh token")
+	}
+
+	if _, err := b.RefreshToken("invalid"); err == nil {
+		t.Fatal("Expected error for invalid refresh token")
+	}
+
+	token, err := b.RefreshToken("old_refresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "new_access" {
+		t.Fatalf("Expected access token %q, got %q", "new_access", token.AccessToken)
+	}
+
+	if token.RefreshToken != "new_refresh" {
+		t.Fatalf("Expected refresh token %q, got %q", "new_refresh", token.RefreshToken)
+	}
+
+	if token.Expiry.Before(time.Now()) {
+		t.Fatalf("Expected future expiry, got %v", token.Expiry)
 	}
 }
 
@@ -230,3 +395,269 @@ func TestOauth2Config(t *testing.T) {
 		t.Errorf("Expected scopes %s, got %s", b.Scopes(), result.Scopes)
 	}
 }
+
+func TestVerifyIdTokenSignature(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherRsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []map[string]any{
+		{
+			"kty": "RSA",
+			"kid": "rsa_kid",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC",
+			"kid": "ec_kid",
+			"alg": "ES256",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+			"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer server.Close()
+
+	sign := func(method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	validClaims := jwt.MapClaims{"sub": "test", "exp": time.Now().Add(1 * time.Hour).Unix()}
+	expiredClaims := jwt.MapClaims{"sub": "test", "exp": time.Now().Add(-1 * time.Hour).Unix()}
+
+	scenarios := []struct {
+		name        string
+		token       string
+		skip        bool
+		expectError bool
+	}{
+		{"empty token", "", false, true},
+		{"missing kid", sign(jwt.SigningMethodRS256, "", rsaKey, validClaims), false, true},
+		{"unknown kid", sign(jwt.SigningMethodRS256, "missing", rsaKey, validClaims), false, true},
+		{"RS256 valid", sign(jwt.SigningMethodRS256, "rsa_kid", rsaKey, validClaims), false, false},
+		{"RS256 expired", sign(jwt.SigningMethodRS256, "rsa_kid", rsaKey, expiredClaims), false, true},
+		{"RS256 forged", sign(jwt.SigningMethodRS256, "rsa_kid", otherRsaKey, validClaims), false, true},
+		{"ES256 valid", sign(jwt.SigningMethodES256, "ec_kid", ecKey, validClaims), false, false},
+		{"ES256 alg mismatch", sign(jwt.SigningMethodRS256, "ec_kid", rsaKey, validClaims), false, true},
+		{"skipped verification with forged token", sign(jwt.SigningMethodRS256, "rsa_kid", otherRsaKey, validClaims), true, false},
+		{"skipped verification with expired token", sign(jwt.SigningMethodRS256, "rsa_kid", otherRsaKey, expiredClaims), true, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			b := baseProvider{ctx: context.Background(), jwksUrl: server.URL}
+			b.SetSkipIdTokenVerification(s.skip)
+
+			claims, err := b.verifyIdTokenSignature(s.token)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && claims["sub"] != "test" {
+				t.Fatalf("Expected sub claim %q, got %v", "test", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestVerifyIdTokenSignatureKeyRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toJWK := func(kid string, key *rsa.PrivateKey) map[string]any {
+		return map[string]any{
+			"kty": "RSA",
+			"kid": kid,
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	}
+
+	var rotated atomic.Bool
+	var totalRequests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		totalRequests.Add(1)
+		w.Header().Set("Cache-Control", "max-age=3600")
+
+		keys := []map[string]any{toJWK("old", oldKey)}
+		if rotated.Load() {
+			keys = []map[string]any{toJWK("new", newKey)}
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer server.Close()
+
+	sign := func(kid string, key *rsa.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "test"})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	b := baseProvider{ctx: context.Background(), jwksUrl: server.URL}
+
+	// initial fetch
+	if _, err := b.verifyIdTokenSignature(sign("old", oldKey)); err != nil {
+		t.Fatal(err)
+	}
+
+	// should be served from the cache
+	if _, err := b.verifyIdTokenSignature(sign("old", oldKey)); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := totalRequests.Load(); total != 1 {
+		t.Fatalf("Expected 1 jwks request, got %d", total)
+	}
+
+	// unknown kid shouldn't trigger a refetch before the min interval elapse
+	rotated.Store(true)
+	if _, err := b.verifyIdTokenSignature(sign("new", newKey)); err == nil {
+		t.Fatal("Expected error for the not yet fetched kid")
+	}
+
+	if total := totalRequests.Load(); total != 1 {
+		t.Fatalf("Expected 1 jwks request, got %d", total)
+	}
+
+	// unknown kid should trigger a refetch after the min interval
+	backdateJWKSFetch(server.URL, jwksMinRefetchInterval)
+	if _, err := b.verifyIdTokenSignature(sign("new", newKey)); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := totalRequests.Load(); total != 2 {
+		t.Fatalf("Expected 2 jwks requests, got %d", total)
+	}
+}
+
+func TestVerifyIdTokenSignatureConcurrentRefetch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var totalRequests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		totalRequests.Add(1)
+
+		// slow down the response to ensure that the requests overlap
+		time.Sleep(50 * time.Millisecond)
+
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer server.Close()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "test"})
+	token.Header["kid"] = "unknown"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			b := baseProvider{ctx: context.Background(), jwksUrl: server.URL}
+			if _, err := b.verifyIdTokenSignature(signed); err == nil {
+				t.Error("Expected unknown kid error")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the "no-store" keys are still reused within the min refetch interval
+	for i := 0; i < 5; i++ {
+		b := baseProvider{ctx: context.Background(), jwksUrl: server.URL}
+		b.verifyIdTokenSignature(signed)
+	}
+
+	if total := totalRequests.Load(); total != 1 {
+		t.Fatalf("Expected 1 jwks request, got %d", total)
+	}
+}
+
+func TestParseCacheControlTTL(t *testing.T) {
+	scenarios := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"", defaultJWKSCacheTTL},
+		{"public", defaultJWKSCacheTTL},
+		{"no-store", 0},
+		{"private, no-cache", 0},
+		{"max-age=invalid", 0},
+		{"max-age=-1", 0},
+		{"public, max-age=120", 120 * time.Second},
+		{"public, MAX-AGE=\"60\", must-revalidate", 60 * time.Second},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.header, func(t *testing.T) {
+			result := parseCacheControlTTL(s.header)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+// backdateJWKSFetch moves back the fetch time of the cached JWKS keys with d.
+func backdateJWKSFetch(url string, d time.Duration) {
+	defaultJWKSCache.mux.Lock()
+	defer defaultJWKSCache.mux.Unlock()
+
+	if entry, ok := defaultJWKSCache.entries[url]; ok {
+		entry.fetched = entry.fetched.Add(-d)
+	}
+}
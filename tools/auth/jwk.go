@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultJWKSCacheTTL is the fallback duration for caching a JWKS
+// response that doesn't specify a "Cache-Control: max-age" directive.
+const defaultJWKSCacheTTL = 10 * time.Minute
+
+// jwksMinRefetchInterval is the min duration between two JWKS requests
+// to the same url (it prevents flooding the provider with refetches
+// triggered by tokens with unknown kid or by "no-store" responses).
+const jwksMinRefetchInterval = 1 * time.Minute
+
+// jwk defines a single JSON Web Key (https://datatracker.ietf.org/doc/html/rfc7517).
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA key params
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC key params
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decodes the key params per RFC 7518 and constructs
+// a *rsa.PublicKey or *ecdsa.PublicKey from them.
+//
+// https://datatracker.ietf.org/doc/html/rfc7518#section-6
+func (k *jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		exponent, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.E, "="))
+		if err != nil {
+			return nil, err
+		}
+
+		modulus, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.N, "="))
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			// https://tools.ietf.org/html/rfc7517#appendix-A.1
+			E: int(big.NewInt(0).SetBytes(exponent).Uint64()),
+			N: big.NewInt(0).SetBytes(modulus),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported jwk curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.X, "="))
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.Y, "="))
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     big.NewInt(0).SetBytes(x),
+			Y:     big.NewInt(0).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwk type %q", k.Kty)
+	}
+}
+
+// -------------------------------------------------------------------
+
+// jwksCache is a concurrent safe in-memory store for the fetched
+// JWKS keys, indexed by their source url and kid.
+type jwksCache struct {
+	mux     sync.RWMutex
+	entries map[string]*jwksCacheEntry
+
+	// group deduplicates the concurrent JWKS requests to the same url
+	group singleflight.Group
+}
+
+type jwksCacheEntry struct {
+	keys    map[string]*jwk
+	fetched time.Time
+	expires time.Time
+}
+
+// defaultJWKSCache is the shared JWKS cache used by all providers.
+//
+// The providers are usually instantiated per request so the cache
+// is intentionally not bound to a single provider instance.
+var defaultJWKSCache = &jwksCache{entries: map[string]*jwksCacheEntry{}}
+
+// get returns the cached key with the specified url and kid.
+//
+// refetch reports whether the JWKS could be requested again, aka. when
+// the key is missing or expired and the last request to the url was
+// made before more than [jwksMinRefetchInterval].
+func (c *jwksCache) get(url string, kid string) (key *jwk, refetch bool) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	entry, ok := c.entries[url]
+	if !ok {
+		return nil, true
+	}
+
+	now := time.Now()
+
+	// reuse the last fetched keys (even if expired) until the min interval elapse
+	if now.Sub(entry.fetched) < jwksMinRefetchInterval {
+		return entry.keys[kid], false
+	}
+
+	key = entry.keys[kid]
+	if key == nil || now.After(entry.expires) {
+		return nil, true
+	}
+
+	return key, false
+}
+
+// set replaces all cached keys for the specified url.
+func (c *jwksCache) set(url string, keys []*jwk, ttl time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	now := time.Now()
+
+	entry := &jwksCacheEntry{
+		keys:    make(map[string]*jwk, len(keys)),
+		fetched: now,
+		expires: now.Add(max(ttl, 0)),
+	}
+	for _, k := range keys {
+		entry.keys[k.Kid] = k
+	}
+
+	c.entries[url] = entry
+}
+
+// fetchJWK returns the JWK with the specified kid from the JWKS located at url.
+//
+// The previously cached keys are reused until they expire. A cache miss
+// (eg. due to a key rotation) triggers a new JWKS request but no more
+// often than once per [jwksMinRefetchInterval] and the concurrent
+// requests to the same url are merged into a single one.
+func fetchJWK(ctx context.Context, client *http.Client, url string, kid string) (*jwk, error) {
+	key, refetch := defaultJWKSCache.get(url, kid)
+	if key != nil {
+		return key, nil
+	}
+
+	if refetch {
+		_, err, _ := defaultJWKSCache.group.Do(url, func() (any, error) {
+			// recheck in case the keys were just fetched by another request
+			if _, refetch := defaultJWKSCache.get(url, kid); !refetch {
+				return nil, nil
+			}
+
+			keys, ttl, err := fetchJWKS(ctx, client, url)
+			if err != nil {
+				return nil, err
+			}
+
+			defaultJWKSCache.set(url, keys, ttl)
+
+			return nil, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		key, _ = defaultJWKSCache.get(url, kid)
+	}
+
+	if key == nil {
+		return nil, fmt.Errorf("jwk with kid %q was not found", kid)
+	}
+
+	return key, nil
+}
+
+// fetchJWKS loads the JSON Web Key Set from the specified url and
+// returns its keys together with the allowed cache duration.
+func fetchJWKS(ctx context.Context, client *http.Client, url string) ([]*jwk, time.Duration, error) {
+	if url == "" {
+		return nil, 0, errors.New("missing jwks url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	rawBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// http.Client.Get doesn't treat non 2xx responses as error
+	if res.StatusCode >= 400 {
+		return nil, 0, fmt.Errorf(
+			"failed to verify the provided id_token (%d):\n%s",
+			res.StatusCode,
+			string(rawBody),
+		)
+	}
+
+	jwks := struct {
+		Keys []*jwk `json:"keys"`
+	}{}
+	if err := json.Unmarshal(rawBody, &jwks); err != nil {
+		return nil, 0, err
+	}
+
+	return jwks.Keys, parseCacheControlTTL(res.Header.Get("Cache-Control")), nil
+}
+
+// parseCacheControlTTL extracts the cache duration from a Cache-Control header value.
+//
+// "no-store" and "no-cache" result in 0 (aka. don't cache).
+// If max-age is not set, fallbacks to defaultJWKSCacheTTL.
+func parseCacheControlTTL(header string) time.Duration {
+	if header == "" {
+		return defaultJWKSCacheTTL
+	}
+
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		if directive == "no-store" || directive == "no-cache" {
+			return 0
+		}
+
+		if v, ok := strings.CutPrefix(directive, "max-age="); ok {
+			seconds, err := strconv.Atoi(strings.Trim(v, `"`))
+			if err != nil || seconds < 0 {
+				return 0
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return defaultJWKSCacheTTL
+}
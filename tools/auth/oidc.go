@@ -3,8 +3,11 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 	"golang.org/x/oauth2"
 )
 
@@ -13,26 +16,147 @@ var _ Provider = (*OIDC)(nil)
 // NameOIDC is the unique name of the OpenID Connect (OIDC) provider.
 const NameOIDC string = "oidc"
 
+// OIDCClaimsMap describes which OIDC claim should be mapped to
+// which AuthUser field.
+//
+// Empty values fallback to the standard OIDC claims names
+// (https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims).
+type OIDCClaimsMap struct {
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified string `json:"emailVerified"`
+	Username      string `json:"username"`
+	AvatarUrl     string `json:"avatarUrl"`
+}
+
+// withDefaults returns a new OIDCClaimsMap copy with the standard
+// claims names for the empty fields.
+func (m OIDCClaimsMap) withDefaults() OIDCClaimsMap {
+	if m.Id == "" {
+		m.Id = "sub"
+	}
+	if m.Name == "" {
+		m.Name = "name"
+	}
+	if m.Email == "" {
+		m.Email = "email"
+	}
+	if m.EmailVerified == "" {
+		m.EmailVerified = "email_verified"
+	}
+	if m.Username == "" {
+		m.Username = "preferred_username"
+	}
+	if m.AvatarUrl == "" {
+		m.AvatarUrl = "picture"
+	}
+	return m
+}
+
+// OIDCConfig defines the configuration options of a generic
+// OpenID Connect (OIDC) provider.
+type OIDCConfig struct {
+	DisplayName string
+	AuthUrl     string
+	TokenUrl    string
+
+	// UserInfoUrl is the optional OIDC userinfo endpoint.
+	//
+	// If not set, the user claims are extracted from the verified id_token.
+	UserInfoUrl string
+
+	// JWKSUrl is the url of the JSON Web Key Set used to verify the id_token signature.
+	JWKSUrl string
+
+	// Issuer is the optional expected "iss" claim of the id_token.
+	Issuer string
+
+	// Scopes is the list of requested scopes
+	// (default to "openid", "email", "profile").
+	Scopes []string
+
+	// ClaimsMap describes which claim maps to which AuthUser field.
+	ClaimsMap OIDCClaimsMap
+
+	// DisablePKCE disables the PKCE flow (enabled by default).
+	DisablePKCE bool
+}
+
 // OIDC allows authentication via OpenID Connect (OIDC) OAuth2 provider.
 type OIDC struct {
 	*baseProvider
+
+	issuer    string
+	claimsMap OIDCClaimsMap
 }
 
 // NewOIDCProvider creates new OpenID Connect (OIDC) provider instance with some defaults.
 func NewOIDCProvider() *OIDC {
-	return &OIDC{&baseProvider{
-		ctx:         context.Background(),
-		displayName: "OIDC",
-		pkce:        true,
-		scopes: []string{
+	return NewOIDCProviderWithConfig(OIDCConfig{DisplayName: "OIDC"})
+}
+
+// NewOIDCProviderWithConfig creates new OpenID Connect (OIDC) provider
+// instance from the specified config.
+func NewOIDCProviderWithConfig(config OIDCConfig) *OIDC {
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{
 			"openid", // minimal requirement to return the id
 			"email",
 			"profile",
+		}
+	}
+
+	return &OIDC{
+		baseProvider: &baseProvider{
+			ctx:         context.Background(),
+			displayName: config.DisplayName,
+			pkce:        !config.DisablePKCE,
+			scopes:      scopes,
+			authUrl:     config.AuthUrl,
+			tokenUrl:    config.TokenUrl,
+			userApiUrl:  config.UserInfoUrl,
+			jwksUrl:     config.JWKSUrl,
 		},
-	}}
+		issuer:    config.Issuer,
+		claimsMap: config.ClaimsMap,
+	}
+}
+
+// RegisterOIDCProvider registers a new generic OIDC provider
+// factory in the [Providers] map under the specified name.
+//
+// Note that the provider still needs to be enabled and configured
+// with its client credentials in the app settings.
+func RegisterOIDCProvider(name string, config OIDCConfig) {
+	Providers[name] = func() Provider {
+		return NewOIDCProviderWithConfig(config)
+	}
+}
+
+// Issuer returns the expected id_token issuer (if any).
+func (p *OIDC) Issuer() string {
+	return p.issuer
+}
+
+// SetIssuer sets the expected id_token issuer.
+func (p *OIDC) SetIssuer(issuer string) {
+	p.issuer = issuer
+}
+
+// ClaimsMap returns the provider claims mapping.
+func (p *OIDC) ClaimsMap() OIDCClaimsMap {
+	return p.claimsMap
+}
+
+// SetClaimsMap sets the provider claims mapping.
+func (p *OIDC) SetClaimsMap(claimsMap OIDCClaimsMap) {
+	p.claimsMap = claimsMap
 }
 
-// FetchAuthUser returns an AuthUser instance based the provider's user api.
+// FetchAuthUser returns an AuthUser instance based the provider's user api
+// (or the id_token claims if the provider doesn't have a user api url).
 //
 // API reference: https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
 func (p *OIDC) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
@@ -46,23 +170,22 @@ func (p *OIDC) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
 		return nil, err
 	}
 
-	extracted := struct {
-		Id            string `json:"sub"`
-		Name          string `json:"name"`
-		Username      string `json:"preferred_username"`
-		Picture       string `json:"picture"`
-		Email         string `json:"email"`
-		EmailVerified bool   `json:"email_verified"`
-	}{}
-	if err := json.Unmarshal(data, &extracted); err != nil {
-		return nil, err
+	claimsMap := p.claimsMap.withDefaults()
+
+	id, ok := extractString(rawUser, claimsMap.Id)
+	if !ok || id == "" {
+		return nil, errMissingSubClaim
 	}
 
+	name, _ := extractString(rawUser, claimsMap.Name)
+	username, _ := extractString(rawUser, claimsMap.Username)
+	picture, _ := extractString(rawUser, claimsMap.AvatarUrl)
+
 	user := &AuthUser{
-		Id:           extracted.Id,
-		Name:         extracted.Name,
-		Username:     extracted.Username,
-		AvatarUrl:    extracted.Picture,
+		Id:           id,
+		Name:         name,
+		Username:     username,
+		AvatarUrl:    picture,
 		RawUser:      rawUser,
 		AccessToken:  token.AccessToken,
 		RefreshToken: token.RefreshToken,
@@ -70,9 +193,75 @@ func (p *OIDC) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
 
 	user.Expiry, _ = types.ParseDateTime(token.Expiry)
 
-	if extracted.EmailVerified {
-		user.Email = extracted.Email
+	if cast.ToBool(rawUser[claimsMap.EmailVerified]) {
+		user.Email, _ = extractString(rawUser, claimsMap.Email)
 	}
 
 	return user, nil
 }
+
+// FetchRawUserData implements Provider.FetchRawUserData interface.
+//
+// If the provider doesn't have a user api url, the user data is
+// extracted from the verified "id_token" claims.
+//
+// If the provider has both user api url and JWKS url, the "id_token"
+// signature is also verified and its subject is required to match the user api response.
+func (p *OIDC) FetchRawUserData(token *oauth2.Token) ([]byte, error) {
+	idToken, _ := token.Extra("id_token").(string)
+
+	if p.userApiUrl == "" {
+		claims, err := p.parseIdToken(idToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(claims)
+	}
+
+	data, err := p.baseProvider.FetchRawUserData(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.jwksUrl == "" {
+		return data, nil
+	}
+
+	claims, err := p.parseIdToken(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := map[string]any{}
+	if err := json.Unmarshal(data, &userInfo); err != nil {
+		return nil, err
+	}
+
+	idClaim := p.claimsMap.withDefaults().Id
+	tokenSub, _ := extractString(claims, idClaim)
+	userInfoSub, _ := extractString(userInfo, idClaim)
+	if tokenSub == "" || tokenSub != userInfoSub {
+		return nil, errors.New("the id_token subject doesn't match the user info response")
+	}
+
+	return data, nil
+}
+
+// parseIdToken verifies the id_token signature and its aud and iss (if set) claims.
+func (p *OIDC) parseIdToken(idToken string) (jwt.MapClaims, error) {
+	claims, err := p.verifyIdTokenSignature(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyAudience(p.clientId, true) {
+		return nil, errors.New("aud must be the developer's client_id")
+	}
+
+	if p.issuer != "" && !claims.VerifyIssuer(p.issuer, true) {
+		return nil, errors.New("iss must be " + p.issuer)
+	}
+
+	return claims, nil
+}
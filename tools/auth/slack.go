@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+	"golang.org/x/oauth2"
+)
+
+var _ Provider = (*Slack)(nil)
+
+// NameSlack is the unique name of the Slack provider.
+const NameSlack string = "slack"
+
+// slackTeamIdClaim is the Slack specific id_token claim with the workspace id.
+const slackTeamIdClaim = "https://slack.com/team_id"
+
+// Slack allows authentication via Slack OAuth2 (Sign in with Slack / OpenID Connect).
+type Slack struct {
+	*baseProvider
+}
+
+// NewSlackProvider creates a new Slack provider instance with some defaults.
+func NewSlackProvider() *Slack {
+	return &Slack{&baseProvider{
+		ctx:         context.Background(),
+		displayName: "Slack",
+		pkce:        false, // Slack doesn't support PKCE for the OpenID Connect flow
+		scopes:      []string{"openid", "profile", "email"},
+		authUrl:     "https://slack.com/openid/connect/authorize",
+		tokenUrl:    "https://slack.com/api/openid.connect.token",
+		jwksUrl:     "https://slack.com/openid/connect/keys",
+	}}
+}
+
+// FetchAuthUser returns an AuthUser instance based on the Slack's id_token claims.
+//
+// The Slack workspace id is also available as "team_id" in the AuthUser.RawUser map.
+//
+// API reference: https://api.slack.com/authentication/sign-in-with-slack
+func (p *Slack) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
+	data, err := p.FetchRawUserData(token)
+	if err != nil {
+		return nil, err
+	}
+
+	rawUser := map[string]any{}
+	if err := json.Unmarshal(data, &rawUser); err != nil {
+		return nil, err
+	}
+
+	id, ok := extractString(rawUser, "sub")
+	if !ok || id == "" {
+		return nil, errMissingSubClaim
+	}
+
+	if teamId, ok := extractString(rawUser, slackTeamIdClaim); ok {
+		rawUser["team_id"] = teamId
+	}
+
+	name, _ := extractString(rawUser, "name")
+	picture, _ := extractString(rawUser, "picture")
+
+	user := &AuthUser{
+		Id:           id,
+		Name:         name,
+		AvatarUrl:    picture,
+		RawUser:      rawUser,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+
+	user.Expiry, _ = types.ParseDateTime(token.Expiry)
+
+	if cast.ToBool(rawUser["email_verified"]) {
+		user.Email, _ = extractString(rawUser, "email")
+	}
+
+	return user, nil
+}
+
+// FetchRawUserData implements Provider.FetchRawUserData interface.
+//
+// The user claims are extracted from the verified "id_token".
+func (p *Slack) FetchRawUserData(token *oauth2.Token) ([]byte, error) {
+	idToken, _ := token.Extra("id_token").(string)
+
+	claims, err := p.parseIdToken(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(claims)
+}
+
+// parseIdToken verifies the id_token signature and its iss and aud claims.
+func (p *Slack) parseIdToken(idToken string) (jwt.MapClaims, error) {
+	claims, err := p.verifyIdTokenSignature(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer("https://slack.com", true) {
+		return nil, errors.New("iss must be https://slack.com")
+	}
+
+	if !claims.VerifyAudience(p.clientId, true) {
+		return nil, errors.New("aud must be the developer's client_id")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+	"golang.org/x/oauth2"
+)
+
+var _ Provider = (*Zoho)(nil)
+
+// NameZoho is the unique name of the Zoho provider.
+const NameZoho string = "zoho"
+
+// zohoRegionalJWKSUrls is a map with the Zoho data centers accounts
+// issuers and their corresponding JWKS endpoints.
+//
+// https://www.zoho.com/accounts/protocol/oauth/multi-dc.html
+var zohoRegionalJWKSUrls = map[string]string{
+	"https://accounts.zoho.com":    "https://accounts.zoho.com/oauth/v2/keys",
+	"https://accounts.zoho.eu":     "https://accounts.zoho.eu/oauth/v2/keys",
+	"https://accounts.zoho.in":     "https://accounts.zoho.in/oauth/v2/keys",
+	"https://accounts.zoho.com.au": "https://accounts.zoho.com.au/oauth/v2/keys",
+	"https://accounts.zoho.jp":     "https://accounts.zoho.jp/oauth/v2/keys",
+	"https://accounts.zoho.ca":     "https://accounts.zoho.ca/oauth/v2/keys",
+	"https://accounts.zoho.sa":     "https://accounts.zoho.sa/oauth/v2/keys",
+	"https://accounts.zoho.com.cn": "https://accounts.zoho.com.cn/oauth/v2/keys",
+}
+
+// Zoho allows authentication via Zoho OAuth2 (OpenID Connect).
+type Zoho struct {
+	*baseProvider
+}
+
+// NewZohoProvider creates a new Zoho provider instance with some defaults.
+func NewZohoProvider() *Zoho {
+	return &Zoho{&baseProvider{
+		ctx:         context.Background(),
+		displayName: "Zoho",
+		pkce:        true,
+		scopes:      []string{"openid", "email", "profile"},
+		authUrl:     "https://accounts.zoho.com/oauth/v2/auth",
+		tokenUrl:    "https://accounts.zoho.com/oauth/v2/token",
+	}}
+}
+
+// FetchAuthUser returns an AuthUser instance based on the Zoho's id_token claims.
+//
+// API reference: https://www.zoho.com/accounts/protocol/oauth/use-access-token.html
+func (p *Zoho) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
+	data, err := p.FetchRawUserData(token)
+	if err != nil {
+		return nil, err
+	}
+
+	rawUser := map[string]any{}
+	if err := json.Unmarshal(data, &rawUser); err != nil {
+		return nil, err
+	}
+
+	id, ok := extractString(rawUser, "sub")
+	if !ok || id == "" {
+		return nil, errMissingSubClaim
+	}
+
+	name, _ := extractString(rawUser, "name")
+	picture, _ := extractString(rawUser, "picture")
+
+	user := &AuthUser{
+		Id:           id,
+		Name:         name,
+		AvatarUrl:    picture,
+		RawUser:      rawUser,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+
+	user.Expiry, _ = types.ParseDateTime(token.Expiry)
+
+	if cast.ToBool(rawUser["email_verified"]) {
+		user.Email, _ = extractString(rawUser, "email")
+	}
+
+	return user, nil
+}
+
+// FetchRawUserData implements Provider.FetchRawUserData interface.
+//
+// The user claims are extracted from the verified "id_token".
+func (p *Zoho) FetchRawUserData(token *oauth2.Token) ([]byte, error) {
+	idToken, _ := token.Extra("id_token").(string)
+
+	claims, err := p.parseIdToken(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(claims)
+}
+
+// parseIdToken verifies the id_token signature using the JWKS
+// of the data center that issued the token and validates its iss and aud claims.
+func (p *Zoho) parseIdToken(idToken string) (jwt.MapClaims, error) {
+	if idToken == "" {
+		return nil, errors.New("empty id_token")
+	}
+
+	// resolve the regional JWKS endpoint based on the token issuer
+	// (if not explicitly set)
+	unverified := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, unverified); err != nil {
+		return nil, err
+	}
+
+	iss, _ := extractString(unverified, "iss")
+	regionalJWKSUrl, ok := zohoRegionalJWKSUrls[iss]
+	if !ok {
+		return nil, fmt.Errorf("unknown id_token issuer %q", iss)
+	}
+
+	if p.jwksUrl == "" {
+		p.jwksUrl = regionalJWKSUrl
+	}
+
+	claims, err := p.verifyIdTokenSignature(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(iss, true) {
+		return nil, errors.New("iss must be a Zoho accounts server")
+	}
+
+	if !claims.VerifyAudience(p.clientId, true) {
+		return nil, errors.New("aud must be the developer's client_id")
+	}
+
+	return claims, nil
+}
@@ -9,26 +9,61 @@
 package cron
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
 
-type job struct {
-	schedule *Schedule
-	run      func()
+// Job defines a single registered cron job.
+type Job struct {
+	id         string
+	expression string
+	schedule   *Schedule
+	run        func()
+}
+
+// Id returns the cron job id.
+func (j *Job) Id() string {
+	return j.id
+}
+
+// Expression returns the raw cron job schedule expression.
+func (j *Job) Expression() string {
+	return j.expression
+}
+
+// Schedule returns the parsed cron job schedule.
+func (j *Job) Schedule() *Schedule {
+	return j.schedule
+}
+
+// Run runs the cron job function.
+func (j *Job) Run() {
+	if j.run != nil {
+		j.run()
+	}
 }
 
 // Cron is a crontab-like struct for tasks/jobs scheduling.
+//
+// It is safe to add and remove jobs concurrently, including while the cron is running.
 type Cron struct {
 	timezone   *time.Location
 	ticker     *time.Ticker
 	startTimer *time.Timer
-	jobs       map[string]*job
+	jobs       map[string]*Job
 	interval   time.Duration
 	tickerDone chan bool
 
+	// tickInterval is the interval of the current running ticker
+	tickInterval time.Duration
+
+	// running keeps track of the currently running jobs
+	running sync.WaitGroup
+
 	sync.RWMutex
 }
 
@@ -41,7 +76,7 @@ func New() *Cron {
 	return &Cron{
 		interval:   1 * time.Minute,
 		timezone:   time.UTC,
-		jobs:       map[string]*job{},
+		jobs:       map[string]*Job{},
 		tickerDone: make(chan bool),
 	}
 }
@@ -49,15 +84,14 @@ func New() *Cron {
 // SetInterval changes the current cron tick interval
 // (it usually should be >= 1 minute).
 func (c *Cron) SetInterval(d time.Duration) {
-	// update interval
 	c.Lock()
-	wasStarted := c.ticker != nil
+	defer c.Unlock()
+
 	c.interval = d
-	c.Unlock()
 
 	// restart the ticker
-	if wasStarted {
-		c.Start()
+	if c.isStarted() {
+		c.start()
 	}
 }
 
@@ -83,24 +117,31 @@ func (c *Cron) MustAdd(jobId string, cronExpr string, run func()) {
 //
 // cronExpr is a regular cron expression, eg. "0 */3 * * *" (aka. at minute 0 past every 3rd hour).
 // Check cron.NewSchedule() for the supported tokens.
+//
+// Registering a job with a seconds segment (eg. "30 * * * * *") switches
+// the cron ticker to tick every second.
 func (c *Cron) Add(jobId string, cronExpr string, run func()) error {
 	if run == nil {
 		return errors.New("failed to add new cron job: run must be non-nil function")
 	}
 
-	c.Lock()
-	defer c.Unlock()
-
 	schedule, err := NewSchedule(cronExpr)
 	if err != nil {
 		return fmt.Errorf("failed to add new cron job: %w", err)
 	}
 
-	c.jobs[jobId] = &job{
-		schedule: schedule,
-		run:      run,
+	c.Lock()
+	defer c.Unlock()
+
+	c.jobs[jobId] = &Job{
+		id:         jobId,
+		expression: cronExpr,
+		schedule:   schedule,
+		run:        run,
 	}
 
+	c.syncTicker()
+
 	return nil
 }
 
@@ -110,6 +151,8 @@ func (c *Cron) Remove(jobId string) {
 	defer c.Unlock()
 
 	delete(c.jobs, jobId)
+
+	c.syncTicker()
 }
 
 // RemoveAll removes all registered cron jobs.
@@ -117,7 +160,9 @@ func (c *Cron) RemoveAll() {
 	c.Lock()
 	defer c.Unlock()
 
-	c.jobs = map[string]*job{}
+	c.jobs = map[string]*Job{}
+
+	c.syncTicker()
 }
 
 // Total returns the current total number of registered cron jobs.
@@ -128,6 +173,23 @@ func (c *Cron) Total() int {
 	return len(c.jobs)
 }
 
+// Jobs returns a shallow copy of the currently registered cron jobs sorted by their id.
+func (c *Cron) Jobs() []*Job {
+	c.RLock()
+	defer c.RUnlock()
+
+	result := make([]*Job, 0, len(c.jobs))
+	for _, j := range c.jobs {
+		result = append(result, j)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].id < result[j].id
+	})
+
+	return result
+}
+
 // Stop stops the current cron ticker (if not already).
 //
 // You can resume the ticker by calling Start().
@@ -135,6 +197,59 @@ func (c *Cron) Stop() {
 	c.Lock()
 	defer c.Unlock()
 
+	c.stop()
+}
+
+// Start starts the cron ticker.
+//
+// Calling Start() on already started cron will restart the ticker.
+func (c *Cron) Start() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.start()
+}
+
+// Wait blocks until all currently running jobs complete
+// or the provided context is done (whichever comes first).
+//
+// It is usually called after Stop() to gracefully wait
+// for the jobs started by the ticker to finish.
+func (c *Cron) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		c.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HasStarted checks whether the current Cron ticker has been started.
+func (c *Cron) HasStarted() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.ticker != nil
+}
+
+// isStarted reports whether the ticker is running or scheduled to start.
+//
+// This method must be called with the cron lock held.
+func (c *Cron) isStarted() bool {
+	return c.ticker != nil || c.startTimer != nil
+}
+
+// stop stops the current ticker and its pending start timer (if any).
+//
+// This method must be called with the cron lock held.
+func (c *Cron) stop() {
 	if c.startTimer != nil {
 		c.startTimer.Stop()
 		c.startTimer = nil
@@ -144,64 +259,131 @@ func (c *Cron) Stop() {
 		return // already stopped
 	}
 
-	c.tickerDone <- true
+	// closed instead of sent to avoid blocking on a tick that waits for the lock
+	close(c.tickerDone)
 	c.ticker.Stop()
 	c.ticker = nil
 }
 
-// Start starts the cron ticker.
+// start (re)starts the ticker.
 //
-// Calling Start() on already started cron will restart the ticker.
-func (c *Cron) Start() {
-	c.Stop()
+// This method must be called with the cron lock held.
+func (c *Cron) start() {
+	c.stop()
+
+	interval := c.interval
+	if interval > time.Second && c.hasSecondsJobs() {
+		interval = time.Second
+	}
+	c.tickInterval = interval
+
+	done := make(chan bool)
+	c.tickerDone = done
 
-	// delay the ticker to start at 00 of 1 c.interval duration
+	// delay the ticker to start at 00 of 1 interval duration
 	now := time.Now()
-	next := now.Add(c.interval).Truncate(c.interval)
+	next := now.Add(interval).Truncate(interval)
 	delay := next.Sub(now)
 
-	c.Lock()
-	c.startTimer = time.AfterFunc(delay, func() {
+	var startTimer *time.Timer
+	startTimer = time.AfterFunc(delay, func() {
 		c.Lock()
-		c.ticker = time.NewTicker(c.interval)
+		if c.startTimer != startTimer {
+			c.Unlock()
+			return // stopped or restarted in the meantime
+		}
+		c.startTimer = nil
+		ticker := time.NewTicker(interval)
+		c.ticker = ticker
 		c.Unlock()
 
 		// run immediately at 00
-		c.runDue(time.Now())
+		c.runDue(time.Now(), done)
 
 		// run after each tick
 		go func() {
 			for {
 				select {
-				case <-c.tickerDone:
+				case <-done:
 					return
-				case t := <-c.ticker.C:
-					c.runDue(t)
+				case t := <-ticker.C:
+					c.runDue(t, done)
 				}
 			}
 		}()
 	})
-	c.Unlock()
+	c.startTimer = startTimer
 }
 
-// HasStarted checks whether the current Cron ticker has been started.
-func (c *Cron) HasStarted() bool {
-	c.RLock()
-	defer c.RUnlock()
+// syncTicker restarts the running ticker if its interval
+// no longer matches the registered jobs precision
+// (eg. after adding or removing a seconds precision job).
+//
+// This method must be called with the cron lock held.
+func (c *Cron) syncTicker() {
+	if !c.isStarted() {
+		return
+	}
 
-	return c.ticker != nil
+	expected := c.interval
+	if expected > time.Second && c.hasSecondsJobs() {
+		expected = time.Second
+	}
+
+	if c.tickInterval != expected {
+		c.start()
+	}
 }
 
 // runDue runs all registered jobs that are scheduled for the provided time.
-func (c *Cron) runDue(t time.Time) {
+//
+// done is the ticker channel the call belongs to. The check is performed
+// under the lock because a tick could be received right before Stop and
+// its jobs shouldn't be started after that (e.g. while waiting them with Wait).
+func (c *Cron) runDue(t time.Time, done chan bool) {
 	c.RLock()
 	defer c.RUnlock()
 
-	moment := NewMoment(t.In(c.timezone))
+	select {
+	case <-done:
+		return // stopped
+	default:
+	}
 
 	for _, j := range c.jobs {
+		loc := c.timezone
+		if j.schedule.Location != nil {
+			loc = j.schedule.Location
+		}
+
+		moment := NewMoment(t.In(loc))
+
+		// when the ticker was switched to a seconds precision because of
+		// another job, check the minute precision jobs only once per minute
+		if !j.schedule.HasSeconds() && c.tickInterval < c.interval && moment.Second != 0 {
+			continue
+		}
+
 		if j.schedule.IsDue(moment) {
-			go j.run()
+			c.running.Add(1)
+			go func(j *Job) {
+				defer c.running.Done()
+				j.Run()
+			}(j)
 		}
 	}
 }
+
+// hasSecondsJobs reports whether there is at least one registered job
+// with a seconds precision schedule.
+//
+// This method must be called with the cron lock held.
+func (c *Cron) hasSecondsJobs() bool {
+	for _, j := range c.jobs {
+		if j.schedule.HasSeconds() {
+			return true
+		}
+	}
+
+	return false
+}
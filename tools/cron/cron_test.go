@@ -1,7 +1,11 @@
 package cron
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -261,3 +265,298 @@ func TestCronStartStop(t *testing.T) {
 		t.Fatalf("Expected %d test2, got %d", expectedCalls, test2)
 	}
 }
+
+func TestCronWait(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+
+	c.MustAdd("test", "* * * * *", func() {
+		close(started)
+		<-release
+		finished.Store(true)
+	})
+
+	// no running jobs
+	if err := c.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	c.runDue(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), c.tickerDone)
+	<-started
+
+	// the job is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded error, got %v", err)
+	}
+
+	close(release)
+
+	if err := c.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	if !finished.Load() {
+		t.Fatal("Expected the job to be finished after Wait")
+	}
+}
+
+func TestCronWaitAfterStopWithPendingTick(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	var calls atomic.Int32
+
+	c.MustAdd("test", "* * * * *", func() {
+		calls.Add(1)
+	})
+
+	done := make(chan bool)
+	c.tickerDone = done
+	c.ticker = time.NewTicker(time.Hour)
+
+	// simulate a received tick that is waiting for the lock while the cron is being stopped
+	c.Lock()
+	pending := make(chan struct{})
+	go func() {
+		defer close(pending)
+		c.runDue(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), done)
+	}()
+	c.stop()
+	c.Unlock()
+
+	if err := c.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	<-pending
+
+	if err := c.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	if total := calls.Load(); total != 0 {
+		t.Fatalf("Expected no job calls after Stop, got %d", total)
+	}
+}
+
+func TestCronSecondsPrecision(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	var mu sync.Mutex
+	calls := 0
+
+	c.MustAdd("test1", "* * * * *", func() {})
+
+	c.Start()
+	defer c.Stop()
+
+	c.RLock()
+	interval := c.tickInterval
+	c.RUnlock()
+	if interval != time.Minute {
+		t.Fatalf("Expected tick interval %v, got %v", time.Minute, interval)
+	}
+
+	// should restart the ticker with seconds precision
+	c.MustAdd("test2", "* * * * * *", func() {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+
+	c.RLock()
+	interval = c.tickInterval
+	c.RUnlock()
+	if interval != time.Second {
+		t.Fatalf("Expected tick interval %v, got %v", time.Second, interval)
+	}
+
+	time.Sleep(2500 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls < 2 {
+		t.Fatalf("Expected at least 2 test2 calls, got %d", calls)
+	}
+}
+
+func TestCronRunDueScheduleTimezone(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+	c.tickInterval = time.Second
+	c.interval = time.Minute
+
+	called := make(chan string, 10)
+
+	c.MustAdd("tokyo", "CRON_TZ=Asia/Tokyo 0 9 * * *", func() { called <- "tokyo" })
+	c.MustAdd("utc", "0 0 * * *", func() { called <- "utc" })
+	c.MustAdd("seconds", "30 0 0 * * *", func() { called <- "seconds" })
+
+	scenarios := []struct {
+		time     time.Time
+		expected []string
+	}{
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), []string{"tokyo", "utc"}},
+		// minute precision jobs are skipped on the non-zero ticker seconds
+		{time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC), []string{"seconds"}},
+		{time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), nil},
+	}
+
+	for i, s := range scenarios {
+		c.runDue(s.time, c.tickerDone)
+
+		result := map[string]bool{}
+		timeout := time.After(100 * time.Millisecond)
+	loop:
+		for {
+			select {
+			case name := <-called:
+				result[name] = true
+			case <-timeout:
+				break loop
+			}
+		}
+
+		if len(result) != len(s.expected) {
+			t.Fatalf("[%d] Expected %v calls, got %v", i, s.expected, result)
+		}
+		for _, name := range s.expected {
+			if !result[name] {
+				t.Fatalf("[%d] Expected %q to be called, got %v", i, name, result)
+			}
+		}
+	}
+}
+
+func TestCronJobs(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	if total := len(c.Jobs()); total != 0 {
+		t.Fatalf("Expected no jobs, got %d", total)
+	}
+
+	c.MustAdd("b", "* * * * *", func() {})
+	c.MustAdd("a", "CRON_TZ=Asia/Tokyo 30 */2 * * * *", func() {})
+	c.MustAdd("c", "@daily", func() {})
+	c.MustAdd("b", "0 0 * * *", func() {}) // replace
+
+	jobs := c.Jobs()
+
+	expected := []struct {
+		id         string
+		expression string
+		hasSeconds bool
+	}{
+		{"a", "CRON_TZ=Asia/Tokyo 30 */2 * * * *", true},
+		{"b", "0 0 * * *", false},
+		{"c", "@daily", false},
+	}
+
+	if len(jobs) != len(expected) {
+		t.Fatalf("Expected %d jobs, got %d", len(expected), len(jobs))
+	}
+
+	for i, e := range expected {
+		if jobs[i].Id() != e.id {
+			t.Fatalf("[%d] Expected id %q, got %q", i, e.id, jobs[i].Id())
+		}
+		if jobs[i].Expression() != e.expression {
+			t.Fatalf("[%d] Expected expression %q, got %q", i, e.expression, jobs[i].Expression())
+		}
+		if jobs[i].Schedule().HasSeconds() != e.hasSeconds {
+			t.Fatalf("[%d] Expected hasSeconds %v", i, e.hasSeconds)
+		}
+	}
+
+	// modifying the returned slice shouldn't affect the cron jobs
+	jobs[0] = nil
+	if c.Jobs()[0] == nil {
+		t.Fatal("Expected the cron jobs to be unaffected")
+	}
+}
+
+func TestCronAddAndRemoveAfterStart(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+	c.SetInterval(100 * time.Millisecond)
+	c.Start()
+	defer c.Stop()
+
+	var calls atomic.Int32
+
+	c.MustAdd("test", "* * * * *", func() {
+		calls.Add(1)
+	})
+
+	time.Sleep(350 * time.Millisecond)
+
+	if v := calls.Load(); v < 2 {
+		t.Fatalf("Expected the job to be called at least 2 times after start, got %d", v)
+	}
+
+	c.Remove("test")
+
+	time.Sleep(50 * time.Millisecond) // wait for any already triggered run
+	afterRemove := calls.Load()
+
+	time.Sleep(300 * time.Millisecond)
+
+	if v := calls.Load(); v != afterRemove {
+		t.Fatalf("Expected no more calls after the job removal, got %d (before %d)", v, afterRemove)
+	}
+
+	if !c.HasStarted() {
+		t.Fatal("Expected the cron ticker to be still running")
+	}
+}
+
+func TestCronConcurrentChanges(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+	c.SetInterval(10 * time.Millisecond)
+	c.Start()
+	defer c.Stop()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			id := fmt.Sprintf("test%d", i)
+
+			for j := 0; j < 20; j++ {
+				expr := "* * * * *"
+				if j%2 == 0 {
+					expr = "* * * * * *"
+				}
+				c.MustAdd(id, expr, func() {})
+				c.Jobs()
+				c.Remove(id)
+				time.Sleep(time.Millisecond)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if total := c.Total(); total != 0 {
+		t.Fatalf("Expected 0 jobs, got %d", total)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 // Moment represents a parsed single time moment.
 type Moment struct {
+	Second    int `json:"second"`
 	Minute    int `json:"minute"`
 	Hour      int `json:"hour"`
 	Day       int `json:"day"`
@@ -20,6 +21,7 @@ type Moment struct {
 // NewMoment creates a new Moment from the specified time.
 func NewMoment(t time.Time) *Moment {
 	return &Moment{
+		Second:    t.Second(),
 		Minute:    t.Minute(),
 		Hour:      t.Hour(),
 		Day:       t.Day(),
@@ -30,15 +32,36 @@ func NewMoment(t time.Time) *Moment {
 
 // Schedule stores parsed information for each time component when a cron job should run.
 type Schedule struct {
+	// Seconds is nil for the regular minute precision schedules.
+	Seconds    map[int]struct{} `json:"seconds,omitempty"`
 	Minutes    map[int]struct{} `json:"minutes"`
 	Hours      map[int]struct{} `json:"hours"`
 	Days       map[int]struct{} `json:"days"`
 	Months     map[int]struct{} `json:"months"`
 	DaysOfWeek map[int]struct{} `json:"daysOfWeek"`
+
+	// Location is the optional CRON_TZ schedule timezone.
+	//
+	// If nil, the schedule is evaluated in the timezone of the provided time
+	// (or in the Cron timezone when the schedule is part of a Cron instance).
+	Location *time.Location `json:"-"`
+}
+
+// HasSeconds reports whether the schedule has the optional seconds segment.
+func (s *Schedule) HasSeconds() bool {
+	return s.Seconds != nil
 }
 
 // IsDue checks whether the provided Moment satisfies the current Schedule.
+//
+// The Moment seconds are checked only if the schedule has a seconds segment.
 func (s *Schedule) IsDue(m *Moment) bool {
+	if s.Seconds != nil {
+		if _, ok := s.Seconds[m.Second]; !ok {
+			return false
+		}
+	}
+
 	if _, ok := s.Minutes[m.Minute]; !ok {
 		return false
 	}
@@ -62,6 +85,69 @@ func (s *Schedule) IsDue(m *Moment) bool {
 	return true
 }
 
+// maxNextYears is the max number of years that [Schedule.Next] will search for a match.
+const maxNextYears = 5
+
+// Next returns the first time after t that satisfies the current Schedule.
+//
+// The calculation is performed in the schedule Location (or in the t location if not set).
+// Local times that don't exist due to a DST transition (eg. 02:30 on a spring-forward day)
+// are skipped and the repeated local times on a fall-back day could match twice.
+//
+// Returns zero time if there is no match in the next 5 years (eg. "0 0 30 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
+
+	loc := t.Location()
+
+	seconds := s.Seconds
+	if seconds == nil {
+		seconds = map[int]struct{}{0: {}}
+	}
+
+	// start from the next whole second
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+
+	yearLimit := t.Year() + maxNextYears
+
+	for t.Year() <= yearLimit {
+		if _, ok := s.Months[int(t.Month())]; !ok {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		_, dayOk := s.Days[t.Day()]
+		_, dayOfWeekOk := s.DaysOfWeek[int(t.Weekday())]
+		if !dayOk || !dayOfWeekOk {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		// the hours, minutes and seconds are advanced with absolute durations
+		// to avoid going back in time when the same local time is repeated
+		if _, ok := s.Hours[t.Hour()]; !ok {
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+			continue
+		}
+
+		if _, ok := s.Minutes[t.Minute()]; !ok {
+			t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+			continue
+		}
+
+		if _, ok := seconds[t.Second()]; !ok {
+			t = t.Add(time.Second)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
 var macros = map[string]string{
 	"@yearly":   "0 0 1 1 *",
 	"@annually": "0 0 1 1 *",
@@ -72,11 +158,20 @@ var macros = map[string]string{
 	"@hourly":   "0 * * * *",
 }
 
+// timezonePrefix is the optional cron expression timezone prefix (eg. "CRON_TZ=Europe/Sofia 0 0 * * *").
+const timezonePrefix = "CRON_TZ="
+
 // NewSchedule creates a new Schedule from a cron expression.
 //
 // A cron expression could be a macro OR 5 segments separated by space,
 // representing: minute, hour, day of the month, month and day of the week.
 //
+// An optional 6th leading seconds segment is also supported
+// (eg. "30 * * * * *" runs at the 30th second of every minute).
+//
+// The expression could be also prefixed with "CRON_TZ=Area/City" to evaluate
+// the schedule in a specific IANA timezone (eg. "CRON_TZ=Europe/Sofia 0 9 * * *").
+//
 // The following segment formats are supported:
 //   - wildcard: *
 //   - range:    1-30
@@ -90,13 +185,37 @@ var macros = map[string]string{
 //   - @daily (or @midnight)
 //   - @hourly
 func NewSchedule(cronExpr string) (*Schedule, error) {
+	var location *time.Location
+
+	if strings.HasPrefix(cronExpr, timezonePrefix) {
+		tz, expr, _ := strings.Cut(strings.TrimPrefix(cronExpr, timezonePrefix), " ")
+
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "" {
+			return nil, fmt.Errorf("invalid cron expression timezone %q", tz)
+		}
+
+		location = loc
+		cronExpr = expr
+	}
+
 	if v, ok := macros[cronExpr]; ok {
 		cronExpr = v
 	}
 
 	segments := strings.Split(cronExpr, " ")
-	if len(segments) != 5 {
-		return nil, errors.New("invalid cron expression - must be a valid macro or to have exactly 5 space separated segments")
+	if len(segments) != 5 && len(segments) != 6 {
+		return nil, errors.New("invalid cron expression - must be a valid macro or to have exactly 5 or 6 space separated segments")
+	}
+
+	var seconds map[int]struct{}
+	if len(segments) == 6 {
+		var err error
+		seconds, err = parseCronSegment(segments[0], 0, 59)
+		if err != nil {
+			return nil, err
+		}
+		segments = segments[1:]
 	}
 
 	minutes, err := parseCronSegment(segments[0], 0, 59)
@@ -125,11 +244,13 @@ func NewSchedule(cronExpr string) (*Schedule, error) {
 	}
 
 	return &Schedule{
+		Seconds:    seconds,
 		Minutes:    minutes,
 		Hours:      hours,
 		Days:       days,
 		Months:     months,
 		DaysOfWeek: daysOfWeek,
+		Location:   location,
 	}, nil
 }
 
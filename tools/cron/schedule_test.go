@@ -11,13 +11,17 @@ import (
 func TestNewMoment(t *testing.T) {
 	t.Parallel()
 
-	date, err := time.Parse("2006-01-02 15:04", "2023-05-09 15:20")
+	date, err := time.Parse("2006-01-02 15:04:05", "2023-05-09 15:20:30")
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	m := cron.NewMoment(date)
 
+	if m.Second != 30 {
+		t.Fatalf("Expected m.Second %d, got %d", 30, m.Second)
+	}
+
 	if m.Minute != 20 {
 		t.Fatalf("Expected m.Minute %d, got %d", 20, m.Minute)
 	}
@@ -58,7 +62,7 @@ func TestNewSchedule(t *testing.T) {
 			"",
 		},
 		{
-			"* * * * * *",
+			"* * * * * * *",
 			true,
 			"",
 		},
@@ -213,6 +217,55 @@ func TestNewSchedule(t *testing.T) {
 			`{"minutes":{"0":{},"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"24":{},"25":{},"26":{},"27":{},"28":{},"29":{},"3":{},"30":{},"31":{},"32":{},"33":{},"34":{},"35":{},"36":{},"37":{},"38":{},"39":{},"4":{},"40":{},"41":{},"42":{},"43":{},"44":{},"45":{},"46":{},"47":{},"48":{},"49":{},"5":{},"50":{},"51":{},"52":{},"53":{},"54":{},"55":{},"56":{},"57":{},"58":{},"59":{},"6":{},"7":{},"8":{},"9":{}},"hours":{"0":{},"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"days":{"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"24":{},"25":{},"26":{},"27":{},"28":{},"29":{},"3":{},"30":{},"31":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"months":{"1":{},"10":{},"11":{},"12":{},"2":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"daysOfWeek":{"1":{},"2":{},"4":{}}}`,
 		},
 
+		// seconds segment
+		{
+			"-1 * * * * *",
+			true,
+			"",
+		},
+		{
+			"60 * * * * *",
+			true,
+			"",
+		},
+		{
+			"30 0 0 1 1 *",
+			false,
+			`{"seconds":{"30":{}},"minutes":{"0":{}},"hours":{"0":{}},"days":{"1":{}},"months":{"1":{}},"daysOfWeek":{"0":{},"1":{},"2":{},"3":{},"4":{},"5":{},"6":{}}}`,
+		},
+		{
+			"*/15,1 0 0 1 1 *",
+			false,
+			`{"seconds":{"0":{},"1":{},"15":{},"30":{},"45":{}},"minutes":{"0":{}},"hours":{"0":{}},"days":{"1":{}},"months":{"1":{}},"daysOfWeek":{"0":{},"1":{},"2":{},"3":{},"4":{},"5":{},"6":{}}}`,
+		},
+
+		// timezone prefix
+		{
+			"CRON_TZ= 0 0 1 1 *",
+			true,
+			"",
+		},
+		{
+			"CRON_TZ=Invalid/Zone 0 0 1 1 *",
+			true,
+			"",
+		},
+		{
+			"CRON_TZ=Europe/Sofia",
+			true,
+			"",
+		},
+		{
+			"CRON_TZ=Europe/Sofia 0 0 1 1 *",
+			false,
+			`{"minutes":{"0":{}},"hours":{"0":{}},"days":{"1":{}},"months":{"1":{}},"daysOfWeek":{"0":{},"1":{},"2":{},"3":{},"4":{},"5":{},"6":{}}}`,
+		},
+		{
+			"CRON_TZ=Europe/Sofia @yearly",
+			false,
+			`{"minutes":{"0":{}},"hours":{"0":{}},"days":{"1":{}},"months":{"1":{}},"daysOfWeek":{"0":{},"1":{},"2":{},"3":{},"4":{},"5":{},"6":{}}}`,
+		},
+
 		// macros
 		{
 			"@yearly",
@@ -387,6 +440,42 @@ func TestScheduleIsDue(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"* * 1 * * *",
+			&cron.Moment{
+				Second:    10,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			true,
+		},
+		{
+			"30 * * * * *",
+			&cron.Moment{
+				Second:    10,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			false,
+		},
+		{
+			"30 * * * * *",
+			&cron.Moment{
+				Second:    30,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			true,
+		},
 	}
 
 	for i, s := range scenarios {
@@ -402,3 +491,141 @@ func TestScheduleIsDue(t *testing.T) {
 		}
 	}
 }
+
+func TestNewScheduleTimezone(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := cron.NewSchedule("0 0 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Location != nil {
+		t.Fatalf("Expected nil location, got %v", schedule.Location)
+	}
+
+	schedule, err = cron.NewSchedule("CRON_TZ=America/New_York 0 0 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Location == nil || schedule.Location.String() != "America/New_York" {
+		t.Fatalf("Expected America/New_York location, got %v", schedule.Location)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	t.Parallel()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name     string
+		cronExpr string
+		after    time.Time
+		expected string // in UTC
+	}{
+		{
+			"minute precision",
+			"*/5 * * * *",
+			time.Date(2024, 1, 1, 10, 2, 10, 500, time.UTC),
+			"2024-01-01 10:05:00 +0000 UTC",
+		},
+		{
+			"minute precision on an exact match",
+			"*/5 * * * *",
+			time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC),
+			"2024-01-01 10:10:00 +0000 UTC",
+		},
+		{
+			"seconds precision",
+			"30 * * * * *",
+			time.Date(2024, 1, 1, 10, 2, 10, 0, time.UTC),
+			"2024-01-01 10:02:30 +0000 UTC",
+		},
+		{
+			"seconds precision in the next minute",
+			"30 * * * * *",
+			time.Date(2024, 1, 1, 10, 2, 30, 0, time.UTC),
+			"2024-01-01 10:03:30 +0000 UTC",
+		},
+		{
+			"seconds precision in the next year",
+			"*/10 0 0 1 1 *",
+			time.Date(2024, 1, 1, 0, 0, 50, 0, time.UTC),
+			"2025-01-01 00:00:00 +0000 UTC",
+		},
+		{
+			"day of week",
+			"0 12 * * 1",
+			time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), // wednesday
+			"2024-01-08 12:00:00 +0000 UTC",
+		},
+		{
+			"leap day",
+			"0 0 29 2 *",
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			"2028-02-29 00:00:00 +0000 UTC",
+		},
+		{
+			"no match",
+			"0 0 30 2 *",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			"0001-01-01 00:00:00 +0000 UTC",
+		},
+		{
+			"timezone prefix",
+			"CRON_TZ=America/New_York 0 9 * * *",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), // 2023-12-31 19:00 EST
+			"2024-01-01 14:00:00 +0000 UTC",
+		},
+		{
+			"timezone of the after time",
+			"0 9 * * *",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, newYork),
+			"2024-01-01 14:00:00 +0000 UTC",
+		},
+		{
+			// 2024-03-10 02:00 EST -> 03:00 EDT
+			"spring-forward with an existing local time",
+			"CRON_TZ=America/New_York 30 0 3 * * *",
+			time.Date(2024, 3, 10, 6, 0, 0, 0, time.UTC), // 01:00 EST
+			"2024-03-10 07:00:30 +0000 UTC",              // 03:00:30 EDT
+		},
+		{
+			"spring-forward with a skipped local time",
+			"CRON_TZ=America/New_York 30 2 * * *",
+			time.Date(2024, 3, 10, 6, 0, 0, 0, time.UTC), // 01:00 EST
+			"2024-03-11 06:30:00 +0000 UTC",              // 02:30 EDT on the next day
+		},
+		{
+			"spring-forward hourly",
+			"CRON_TZ=America/New_York 0 0 * * * *",
+			time.Date(2024, 3, 10, 6, 30, 0, 0, time.UTC), // 01:30 EST
+			"2024-03-10 07:00:00 +0000 UTC",               // 03:00 EDT
+		},
+		{
+			// 2024-11-03 02:00 EDT -> 01:00 EST
+			"fall-back with a repeated local time",
+			"CRON_TZ=America/New_York 30 1 * * *",
+			time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+			"2024-11-03 06:30:00 +0000 UTC",               // 01:30 EST
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			schedule, err := cron.NewSchedule(s.cronExpr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			next := schedule.Next(s.after).UTC().String()
+
+			if next != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, next)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package dbutils
+
+import (
+	"strings"
+)
+
+// UniqueConstraintError represents a single parsed SQLite unique constraint failure.
+//
+// Depending on the violated index, SQLite reports either the list of
+// the indexed table columns (eg. "UNIQUE constraint failed: demo.a, demo.b")
+// or the index name for indexes with expressions (eg. "UNIQUE constraint failed: index 'idx_demo'").
+type UniqueConstraintError struct {
+	TableName string   `json:"tableName"`
+	Columns   []string `json:"columns"`
+	IndexName string   `json:"indexName"`
+}
+
+// ParseUniqueConstraintError parses the provided db error and
+// extracts the table columns or the index name of the failed unique constraint.
+//
+// Returns nil if err is not a unique constraint failure.
+func ParseUniqueConstraintError(err error) *UniqueConstraintError {
+	if err == nil {
+		return nil
+	}
+
+	const marker = "unique constraint failed:"
+
+	msg := err.Error()
+
+	pos := strings.Index(strings.ToLower(msg), marker)
+	if pos == -1 {
+		return nil
+	}
+
+	details := strings.TrimSpace(msg[pos+len(marker):])
+
+	// trim the optional driver error code suffix (eg. "demo.a (2067)")
+	if i := strings.LastIndex(details, " ("); i > 0 && strings.HasSuffix(details, ")") {
+		details = strings.TrimSpace(details[:i])
+	}
+
+	result := &UniqueConstraintError{Columns: []string{}}
+
+	// index 'name'
+	if len(details) > 6 && strings.EqualFold(details[:6], "index ") {
+		result.IndexName = strings.Trim(strings.TrimSpace(details[6:]), "'\"`[]")
+		return result
+	}
+
+	// table.column1, table.column2, ...
+	for _, part := range strings.Split(details, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		table, column, ok := strings.Cut(part, ".")
+		if !ok {
+			column = table
+			table = ""
+		}
+
+		if result.TableName == "" {
+			result.TableName = table
+		}
+
+		result.Columns = append(result.Columns, column)
+	}
+
+	return result
+}
@@ -0,0 +1,45 @@
+package dbutils_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+)
+
+func TestParseUniqueConstraintError(t *testing.T) {
+	scenarios := []struct {
+		err      error
+		expected string
+	}{
+		{nil, `null`},
+		{errors.New(""), `null`},
+		{errors.New("NOT NULL constraint failed: demo.a"), `null`},
+		{
+			errors.New("UNIQUE constraint failed: demo.a"),
+			`{"tableName":"demo","columns":["a"],"indexName":""}`,
+		},
+		{
+			errors.New("constraint failed: UNIQUE constraint failed: demo.a, demo.b (2067)"),
+			`{"tableName":"demo","columns":["a","b"],"indexName":""}`,
+		},
+		{
+			errors.New("unique constraint failed: index 'idx_demo'"),
+			`{"tableName":"","columns":[],"indexName":"idx_demo"}`,
+		},
+	}
+
+	for i, s := range scenarios {
+		result := dbutils.ParseUniqueConstraintError(s.err)
+
+		raw, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+
+		if str := string(raw); str != s.expected {
+			t.Fatalf("[%d] Expected \n%s, \ngot \n%s", i, s.expected, str)
+		}
+	}
+}
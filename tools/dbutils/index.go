@@ -8,7 +8,8 @@ import (
 )
 
 var (
-	indexRegex       = regexp.MustCompile(`(?im)create\s+(unique\s+)?\s*index\s*(if\s+not\s+exists\s+)?(\S*)\s+on\s+(\S*)\s*\(([\s\S]*)\)(?:\s*where\s+([\s\S]*))?`)
+	indexRegex       = regexp.MustCompile(`(?im)create\s+(unique\s+)?\s*index\s*(if\s+not\s+exists\s+)?(\S*)\s+on\s+([^\s(]*)\s*\(([\s\S]*)$`)
+	indexWhereRegex  = regexp.MustCompile(`(?im)^\s*where\s+([\s\S]*)$`)
 	indexColumnRegex = regexp.MustCompile(`(?im)^([\s\S]+?)(?:\s+collate\s+([\w]+))?(?:\s+(asc|desc))?$`)
 )
 
@@ -128,7 +129,12 @@ func ParseIndex(createIndexExpr string) Index {
 	result := Index{}
 
 	matches := indexRegex.FindStringSubmatch(createIndexExpr)
-	if len(matches) != 7 {
+	if len(matches) != 6 {
+		return result
+	}
+
+	rawColumns, rawWhere, ok := splitIndexColumnsAndWhere(matches[5])
+	if !ok {
 		return result
 	}
 
@@ -161,14 +167,14 @@ func ParseIndex(createIndexExpr string) Index {
 
 	// Columns
 	// ---
-	columnsTk := tokenizer.NewFromString(matches[5])
+	columnsTk := tokenizer.NewFromString(rawColumns)
 	columnsTk.Separators(',')
 
-	rawColumns, _ := columnsTk.ScanAll()
+	columns, _ := columnsTk.ScanAll()
 
-	result.Columns = make([]IndexColumn, 0, len(rawColumns))
+	result.Columns = make([]IndexColumn, 0, len(columns))
 
-	for _, col := range rawColumns {
+	for _, col := range columns {
 		colMatches := indexColumnRegex.FindStringSubmatch(col)
 		if len(colMatches) != 4 {
 			continue
@@ -188,11 +194,53 @@ func ParseIndex(createIndexExpr string) Index {
 
 	// WHERE expression
 	// ---
-	result.Where = strings.TrimSpace(matches[6])
+	if whereMatches := indexWhereRegex.FindStringSubmatch(rawWhere); len(whereMatches) == 2 {
+		result.Where = strings.TrimSpace(whereMatches[1])
+	}
 
 	return result
 }
 
+// splitIndexColumnsAndWhere splits the part after the index columns list
+// opening parenthesis into the raw columns list and the remaining
+// (partial index "WHERE ...") expression.
+//
+// The columns list end is determined by its matching closing parenthesis
+// so that parenthesis in the column or WHERE expressions are allowed,
+// eg. `col1, lower(col2)) WHERE (col1 != "" AND col2 != "")`.
+//
+// Returns false if there is no matching closing parenthesis.
+func splitIndexColumnsAndWhere(str string) (string, string, bool) {
+	depth := 0
+
+	var quote rune
+
+	for i, ch := range str {
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '[':
+			quote = ']'
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return str[:i], str[i+1:], true
+			}
+			depth--
+		}
+	}
+
+	return "", "", false
+}
+
 // HasColumnUniqueIndex loosely checks whether the specified column has
 // a single column unique index (WHERE statements are ignored).
 func HasSingleColumnUniqueIndex(column string, indexes []string) bool {
@@ -66,6 +66,25 @@ func TestParseIndex(t *testing.T) {
 				Where: "test = 1",
 			},
 		},
+		// partial index with parenthesis in the columns and WHERE expressions
+		{
+			`create unique index idx on test (lower(slug), "a)b") where (trashed = '' or trashed = ')') and slug != ''`,
+			dbutils.Index{
+				Unique:    true,
+				IndexName: "idx",
+				TableName: "test",
+				Columns: []dbutils.IndexColumn{
+					{Name: "lower(slug)"},
+					{Name: "a)b"},
+				},
+				Where: "(trashed = '' or trashed = ')') and slug != ''",
+			},
+		},
+		// missing columns list closing parenthesis
+		{
+			`create index idx on test (col1 where test = 1`,
+			dbutils.Index{},
+		},
 	}
 
 	for i, s := range scenarios {
@@ -0,0 +1,405 @@
+package dbutils
+
+import (
+	"container/list"
+	"context"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultStmtCacheMaxSize is the default max number of
+// cached prepared statements per db connection.
+const DefaultStmtCacheMaxSize = 100
+
+var schemaChangeRegex = regexp.MustCompile(`(?i)(^|;)\s*(CREATE|ALTER|DROP)\s`)
+
+// StmtCacheStats defines the statements cache usage counters.
+type StmtCacheStats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Invalidations uint64 `json:"invalidations"`
+}
+
+// StmtCacheDriver is a [driver.Driver] wrapper that caches the prepared
+// single SELECT statements of each opened connection keyed by their
+// normalized SQL string, allowing the query plans to be reused across requests.
+//
+// Each connection maintains its own LRU cache because the prepared
+// statements are bound to the connection that created them.
+//
+// All cached statements are invalidated on [StmtCacheDriver.Invalidate]
+// and automatically after executing a schema change statement
+// (CREATE, ALTER or DROP), eg. on collection save or migration.
+type StmtCacheDriver struct {
+	parent  driver.Driver
+	maxSize int
+
+	generation atomic.Uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+// NewStmtCacheDriver creates a new [StmtCacheDriver] that wraps the
+// parent driver and caches up to maxSize statements per connection
+// (fallbacks to [DefaultStmtCacheMaxSize] if maxSize <= 0).
+func NewStmtCacheDriver(parent driver.Driver, maxSize int) *StmtCacheDriver {
+	if maxSize <= 0 {
+		maxSize = DefaultStmtCacheMaxSize
+	}
+
+	return &StmtCacheDriver{
+		parent:  parent,
+		maxSize: maxSize,
+	}
+}
+
+// Open implements [driver.Driver.Open].
+func (d *StmtCacheDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.parent.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stmtCacheConn{
+		Conn:       conn,
+		driver:     d,
+		generation: d.generation.Load(),
+		items:      map[string]*list.Element{},
+		lru:        list.New(),
+	}, nil
+}
+
+// Invalidate marks all cached statements of all connections as stale.
+//
+// The stale statements are closed lazily on the next connection query.
+func (d *StmtCacheDriver) Invalidate() {
+	d.generation.Add(1)
+}
+
+// Stats returns the current statements cache usage counters.
+func (d *StmtCacheDriver) Stats() StmtCacheStats {
+	return StmtCacheStats{
+		Hits:          d.hits.Load(),
+		Misses:        d.misses.Load(),
+		Invalidations: d.generation.Load(),
+	}
+}
+
+// isCacheableQuery reports whether the query is a single read statement.
+func isCacheableQuery(query string) bool {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+
+	if strings.Contains(query, ";") || len(query) < 6 {
+		return false
+	}
+
+	prefix := strings.ToUpper(query[:6])
+
+	return prefix == "SELECT" || strings.HasPrefix(prefix, "WITH")
+}
+
+// -------------------------------------------------------------------
+
+type cachedStmt struct {
+	key  string
+	stmt driver.Stmt
+
+	// busy indicates that the statement has open rows
+	busy bool
+
+	// evicted indicates that the statement was removed from the cache
+	// while being busy and should be closed together with its rows
+	evicted bool
+}
+
+type stmtCacheConn struct {
+	driver.Conn
+
+	driver     *StmtCacheDriver
+	generation uint64
+	items      map[string]*list.Element
+	lru        *list.List
+}
+
+// Close closes all cached statements and the parent connection.
+func (c *stmtCacheConn) Close() error {
+	c.clear()
+
+	return c.Conn.Close()
+}
+
+func (c *stmtCacheConn) clear() {
+	for _, el := range c.items {
+		c.release(el.Value.(*cachedStmt))
+	}
+
+	c.items = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+func (c *stmtCacheConn) release(item *cachedStmt) {
+	if item.busy {
+		item.evicted = true
+		return
+	}
+
+	item.stmt.Close()
+}
+
+func (c *stmtCacheConn) syncGeneration() {
+	if generation := c.driver.generation.Load(); generation != c.generation {
+		c.clear()
+		c.generation = generation
+	}
+}
+
+// PrepareContext implements [driver.ConnPrepareContext.PrepareContext].
+func (c *stmtCacheConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+// BeginTx implements [driver.ConnBeginTx.BeginTx].
+func (c *stmtCacheConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin() //nolint:staticcheck
+}
+
+// Ping implements [driver.Pinger.Ping].
+func (c *stmtCacheConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+// ResetSession implements [driver.SessionResetter.ResetSession].
+func (c *stmtCacheConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+// IsValid implements [driver.Validator.IsValid].
+func (c *stmtCacheConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+// CheckNamedValue implements [driver.NamedValueChecker.CheckNamedValue].
+func (c *stmtCacheConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+
+	// fallback to the default database/sql conversion
+	return driver.ErrSkip
+}
+
+// ExecContext implements [driver.ExecerContext.ExecContext].
+func (c *stmtCacheConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	result, err := execer.ExecContext(ctx, query, args)
+
+	if schemaChangeRegex.MatchString(query) {
+		c.driver.Invalidate()
+	}
+
+	return result, err
+}
+
+// QueryContext implements [driver.QueryerContext.QueryContext].
+func (c *stmtCacheConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !isCacheableQuery(query) {
+		return c.uncachedQuery(ctx, query, args)
+	}
+
+	c.syncGeneration()
+
+	key := strings.TrimSpace(query)
+
+	var item *cachedStmt
+
+	if el, ok := c.items[key]; ok {
+		item = el.Value.(*cachedStmt)
+
+		// the same statement is already in use (eg. nested query in a transaction)
+		if item.busy {
+			return c.uncachedQuery(ctx, query, args)
+		}
+
+		c.lru.MoveToFront(el)
+		c.driver.hits.Add(1)
+	} else {
+		stmt, err := c.PrepareContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		item = &cachedStmt{key: key, stmt: stmt}
+		c.items[key] = c.lru.PushFront(item)
+		c.driver.misses.Add(1)
+
+		c.evict()
+	}
+
+	rows, err := queryStmt(ctx, item.stmt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	item.busy = true
+
+	return &stmtCacheRows{Rows: rows, item: item}, nil
+}
+
+func (c *stmtCacheConn) evict() {
+	for c.lru.Len() > c.driver.maxSize {
+		el := c.lru.Back()
+		item := el.Value.(*cachedStmt)
+
+		c.lru.Remove(el)
+		delete(c.items, item.key)
+		c.release(item)
+	}
+}
+
+func (c *stmtCacheConn) uncachedQuery(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func queryStmt(ctx context.Context, stmt driver.Stmt, args []driver.NamedValue) (driver.Rows, error) {
+	if s, ok := stmt.(driver.StmtQueryContext); ok {
+		return s.QueryContext(ctx, args)
+	}
+
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	return stmt.Query(values) //nolint:staticcheck
+}
+
+// -------------------------------------------------------------------
+
+var (
+	_ driver.RowsColumnTypeDatabaseTypeName = (*stmtCacheRows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*stmtCacheRows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*stmtCacheRows)(nil)
+	_ driver.RowsColumnTypeLength           = (*stmtCacheRows)(nil)
+	_ driver.RowsColumnTypePrecisionScale   = (*stmtCacheRows)(nil)
+	_ driver.RowsNextResultSet              = (*stmtCacheRows)(nil)
+)
+
+// stmtCacheRows wraps the rows of a cached statement
+// and releases the statement on close.
+type stmtCacheRows struct {
+	driver.Rows
+
+	item   *cachedStmt
+	closed bool
+}
+
+// Close implements [driver.Rows.Close].
+func (r *stmtCacheRows) Close() error {
+	err := r.Rows.Close()
+
+	if !r.closed {
+		r.closed = true
+		r.item.busy = false
+
+		if r.item.evicted {
+			r.item.stmt.Close()
+		}
+	}
+
+	return err
+}
+
+// ColumnTypeDatabaseTypeName implements [driver.RowsColumnTypeDatabaseTypeName].
+func (r *stmtCacheRows) ColumnTypeDatabaseTypeName(index int) string {
+	if v, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return v.ColumnTypeDatabaseTypeName(index)
+	}
+
+	return ""
+}
+
+// ColumnTypeScanType implements [driver.RowsColumnTypeScanType].
+func (r *stmtCacheRows) ColumnTypeScanType(index int) reflect.Type {
+	if v, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return v.ColumnTypeScanType(index)
+	}
+
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+// ColumnTypeNullable implements [driver.RowsColumnTypeNullable].
+func (r *stmtCacheRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if v, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return v.ColumnTypeNullable(index)
+	}
+
+	return false, false
+}
+
+// ColumnTypeLength implements [driver.RowsColumnTypeLength].
+func (r *stmtCacheRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if v, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return v.ColumnTypeLength(index)
+	}
+
+	return 0, false
+}
+
+// ColumnTypePrecisionScale implements [driver.RowsColumnTypePrecisionScale].
+func (r *stmtCacheRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if v, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return v.ColumnTypePrecisionScale(index)
+	}
+
+	return 0, 0, false
+}
+
+// HasNextResultSet implements [driver.RowsNextResultSet].
+func (r *stmtCacheRows) HasNextResultSet() bool {
+	if v, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return v.HasNextResultSet()
+	}
+
+	return false
+}
+
+// NextResultSet implements [driver.RowsNextResultSet].
+func (r *stmtCacheRows) NextResultSet() error {
+	if v, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return v.NextResultSet()
+	}
+
+	return io.EOF
+}
@@ -0,0 +1,255 @@
+package dbutils_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+)
+
+// fake driver that counts the prepared and closed statements
+// -------------------------------------------------------------------
+
+type fakeDriver struct {
+	prepared atomic.Int64
+	closed   atomic.Int64
+	queried  atomic.Int64
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.driver.prepared.Add(1)
+	return &fakeStmt{driver: c.driver}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeConn) Commit() error { return nil }
+
+func (c *fakeConn) Rollback() error { return nil }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.queried.Add(1)
+	return &fakeRows{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+type fakeStmt struct {
+	driver *fakeDriver
+}
+
+func (s *fakeStmt) Close() error {
+	s.driver.closed.Add(1)
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"a"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+func openStmtCacheTestDB(t *testing.T, maxSize int) (*sql.DB, *fakeDriver, *dbutils.StmtCacheDriver) {
+	fake := &fakeDriver{}
+	cache := dbutils.NewStmtCacheDriver(fake, maxSize)
+
+	sql.Register("stmt_cache_"+t.Name(), cache)
+
+	db, err := sql.Open("stmt_cache_"+t.Name(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// use a single connection to have predictable cache state
+	db.SetMaxOpenConns(1)
+
+	return db, fake, cache
+}
+
+func queryValue(t *testing.T, db queryRower, query string, args ...any) {
+	var v int
+	if err := db.QueryRow(query, args...).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func TestStmtCacheHits(t *testing.T) {
+	db, fake, cache := openStmtCacheTestDB(t, 0)
+
+	queryValue(t, db, "SELECT a FROM test WHERE b = ?", 1)
+	queryValue(t, db, "SELECT a FROM test WHERE b = ?", 2)
+	queryValue(t, db, "  SELECT a FROM test WHERE b = ?  ", 3) // normalized
+	queryValue(t, db, "WITH x AS (SELECT 1) SELECT a FROM x")
+	queryValue(t, db, "WITH x AS (SELECT 1) SELECT a FROM x")
+
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 2 {
+		t.Fatalf("Expected 3 hits and 2 misses, got %v", stats)
+	}
+
+	if v := fake.prepared.Load(); v != 2 {
+		t.Fatalf("Expected 2 prepared statements, got %d", v)
+	}
+}
+
+func TestStmtCacheNonCacheableQueries(t *testing.T) {
+	db, fake, cache := openStmtCacheTestDB(t, 0)
+
+	queries := []string{
+		"SELECT 1; SELECT 2",
+		"INSERT INTO test (a) VALUES (1) RETURNING a",
+		"PRAGMA table_info(test)",
+	}
+
+	for _, q := range queries {
+		queryValue(t, db, q)
+		queryValue(t, db, q)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 0 || stats.Misses != 0 {
+		t.Fatalf("Expected no cache usage, got %v", stats)
+	}
+
+	if v := fake.queried.Load(); v != int64(2*len(queries)) {
+		t.Fatalf("Expected %d direct queries, got %d", 2*len(queries), v)
+	}
+}
+
+func TestStmtCacheEviction(t *testing.T) {
+	db, fake, cache := openStmtCacheTestDB(t, 2)
+
+	queryValue(t, db, "SELECT 1")
+	queryValue(t, db, "SELECT 2")
+	queryValue(t, db, "SELECT 1") // hit and move to front
+	queryValue(t, db, "SELECT 3") // evicts "SELECT 2"
+	queryValue(t, db, "SELECT 1") // hit
+	queryValue(t, db, "SELECT 2") // miss
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 4 {
+		t.Fatalf("Expected 2 hits and 4 misses, got %v", stats)
+	}
+
+	if v := fake.closed.Load(); v != 2 {
+		t.Fatalf("Expected 2 evicted statements, got %d", v)
+	}
+}
+
+func TestStmtCacheBusyStatement(t *testing.T) {
+	db, fake, cache := openStmtCacheTestDB(t, 0)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT a FROM test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// same statement while the previous rows are still open
+	queryValue(t, tx, "SELECT a FROM test")
+
+	rows.Close()
+
+	queryValue(t, tx, "SELECT a FROM test")
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("Expected 1 hit and 1 miss, got %v", stats)
+	}
+
+	if v := fake.queried.Load(); v != 1 {
+		t.Fatalf("Expected 1 uncached query for the busy statement, got %d", v)
+	}
+}
+
+func TestStmtCacheInvalidation(t *testing.T) {
+	db, fake, cache := openStmtCacheTestDB(t, 0)
+
+	queryValue(t, db, "SELECT a FROM test")
+	queryValue(t, db, "SELECT b FROM test")
+
+	// non schema change statement
+	if _, err := db.Exec("UPDATE test SET a = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	queryValue(t, db, "SELECT a FROM test")
+
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Invalidations != 0 {
+		t.Fatalf("Expected 1 hit and no invalidations, got %v", stats)
+	}
+
+	// schema change
+	if _, err := db.Exec("ALTER TABLE test ADD COLUMN c TEXT"); err != nil {
+		t.Fatal(err)
+	}
+
+	queryValue(t, db, "SELECT a FROM test")
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Invalidations != 1 {
+		t.Fatalf("Expected 1 hit, 3 misses and 1 invalidation, got %v", stats)
+	}
+
+	if v := fake.closed.Load(); v != 2 {
+		t.Fatalf("Expected the 2 stale statements to be closed, got %d", v)
+	}
+
+	// manual invalidation
+	cache.Invalidate()
+
+	queryValue(t, db, "SELECT a FROM test")
+
+	if stats := cache.Stats(); stats.Misses != 4 || stats.Invalidations != 2 {
+		t.Fatalf("Expected 4 misses and 2 invalidations, got %v", stats)
+	}
+}
@@ -0,0 +1,461 @@
+// Package expr implements a minimal and safe expression language
+// for evaluating derived values from a set of named variables
+// (eg. the sibling fields of a record).
+//
+// The supported grammar is:
+//
+//	expression := term { ("+" | "-") term }
+//	term       := unary { ("*" | "/" | "%") unary }
+//	unary      := "-" unary | primary
+//	primary    := number | string | "true" | "false" | "null"
+//	           | identifier | identifier "(" [expression { "," expression }] ")"
+//	           | "(" expression ")"
+//
+// String literals could be single or double quoted. The "+" operator
+// concatenates its operands if at least one of them is a string,
+// otherwise it performs a numeric addition.
+//
+// Only the functions listed in [Functions] are allowed to be called.
+//
+// Example
+//
+//	program, _ := expr.Parse(`upper(firstName) + " " + lastName`)
+//	result, _ := program.Eval(map[string]any{"firstName": "john", "lastName": "doe"}) // "JOHN doe"
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxLength is the max allowed length of a single expression.
+const MaxLength = 1000
+
+// MaxDepth is the max allowed nesting depth of a single expression.
+const MaxDepth = 30
+
+// ErrDivisionByZero is returned when dividing a number by zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// ErrNonFiniteNumber is returned when an operation results in NaN or ±Inf.
+var ErrNonFiniteNumber = errors.New("non-finite number")
+
+// Program defines a single parsed expression.
+type Program struct {
+	root        node
+	identifiers []string
+}
+
+// Identifiers returns the unique variable names referenced by the program
+// (in order of their first occurrence).
+func (p *Program) Identifiers() []string {
+	return p.identifiers
+}
+
+// Eval evaluates the program against the provided variables.
+//
+// Missing variables are evaluated as null.
+//
+// The result is always one of: nil, bool, float64 or string.
+//
+// Returns [ErrNonFiniteNumber] if the result or any of the intermediate
+// numeric operations evaluates to NaN or ±Inf.
+func (p *Program) Eval(vars map[string]any) (any, error) {
+	return checkFinite(p.root.eval(vars))
+}
+
+// checkFinite returns [ErrNonFiniteNumber] if v is a NaN or ±Inf number.
+func checkFinite(v any, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return nil, ErrNonFiniteNumber
+	}
+
+	return v, nil
+}
+
+// Parse parses the provided expression string into a new [Program].
+func Parse(expression string) (*Program, error) {
+	if len(expression) > MaxLength {
+		return nil, fmt.Errorf("the expression must be no more than %d characters", MaxLength)
+	}
+
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+
+	return &Program{root: root, identifiers: p.identifiers}, nil
+}
+
+// -------------------------------------------------------------------
+// Lexer
+// -------------------------------------------------------------------
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdentifier
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func lex(expression string) ([]token, error) {
+	runes := []rune(expression)
+	tokens := []token{}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case strings.ContainsRune("+-*/%", r):
+			tokens = append(tokens, token{tokenOperator, string(r), i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string literal at position %d", start)
+			}
+			tokens = append(tokens, token{tokenString, sb.String(), start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdentifier, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", string(r), i)
+		}
+	}
+
+	tokens = append(tokens, token{tokenEOF, "", len(runes)})
+
+	return tokens, nil
+}
+
+// -------------------------------------------------------------------
+// Parser
+// -------------------------------------------------------------------
+
+type parser struct {
+	tokens      []token
+	pos         int
+	identifiers []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseExpression(depth int) (node, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("the expression must be no more than %d levels deep", MaxDepth)
+	}
+
+	left, err := p.parseTerm(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.value != "+" && t.value != "-") {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseTerm(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: t.value, left: left, right: right}
+	}
+}
+
+func (p *parser) parseTerm(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.value != "*" && t.value != "/" && t.value != "%") {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: t.value, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if t := p.peek(); t.kind == tokenOperator && t.value == "-" {
+		p.next()
+
+		if depth+1 > MaxDepth {
+			return nil, fmt.Errorf("the expression must be no more than %d levels deep", MaxDepth)
+		}
+
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return &negateNode{operand: operand}, nil
+	}
+
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		return &literalNode{value: v}, nil
+	case tokenString:
+		return &literalNode{value: t.value}, nil
+	case tokenLParen:
+		inner, err := p.parseExpression(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at position %d", closing.pos)
+		}
+		return inner, nil
+	case tokenIdentifier:
+		switch t.value {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(t, depth)
+		}
+
+		exists := false
+		for _, name := range p.identifiers {
+			if name == t.value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			p.identifiers = append(p.identifiers, t.value)
+		}
+
+		return &identifierNode{name: t.value}, nil
+	case tokenEOF:
+		return nil, errors.New("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+}
+
+func (p *parser) parseCall(name token, depth int) (node, error) {
+	fn, ok := Functions[name.value]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.value, name.pos)
+	}
+
+	p.next() // "("
+
+	args := []node{}
+
+	if p.peek().kind == tokenRParen {
+		p.next()
+	} else {
+		for {
+			arg, err := p.parseExpression(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			t := p.next()
+			if t.kind == tokenRParen {
+				break
+			}
+			if t.kind != tokenComma {
+				return nil, fmt.Errorf("expected ',' or ')' at position %d", t.pos)
+			}
+		}
+	}
+
+	if len(args) < fn.MinArgs || (fn.MaxArgs >= 0 && len(args) > fn.MaxArgs) {
+		return nil, fmt.Errorf("invalid number of arguments for function %q", name.value)
+	}
+
+	return &callNode{fn: fn, args: args}, nil
+}
+
+// -------------------------------------------------------------------
+// Nodes
+// -------------------------------------------------------------------
+
+type node interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(vars map[string]any) (any, error) {
+	return n.value, nil
+}
+
+type identifierNode struct {
+	name string
+}
+
+func (n *identifierNode) eval(vars map[string]any) (any, error) {
+	return normalize(vars[n.name]), nil
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n *negateNode) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return -toNumber(v), nil
+}
+
+type binaryNode struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *binaryNode) eval(vars map[string]any) (any, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "+" {
+		_, lIsString := l.(string)
+		_, rIsString := r.(string)
+		if lIsString || rIsString {
+			return toString(l) + toString(r), nil
+		}
+	}
+
+	return checkFinite(arithmetic(n.op, toNumber(l), toNumber(r)))
+}
+
+type callNode struct {
+	fn   *Function
+	args []node
+}
+
+func (n *callNode) eval(vars map[string]any) (any, error) {
+	args := make([]any, len(n.args))
+
+	for i, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	return checkFinite(n.fn.Call(args))
+}
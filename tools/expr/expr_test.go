@@ -0,0 +1,166 @@
+package expr_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/expr"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []string{
+		"",
+		"a +",
+		"(a",
+		"a)",
+		"a b",
+		`"abc`,
+		"a.b",
+		"a[0]",
+		"a == b",
+		"a; b",
+		"unknown(a)",
+		"lower()",
+		"lower(a, b)",
+		"lower(a b)",
+		fmt.Sprintf("%0*d", expr.MaxLength+1, 0),
+		fmt.Sprintf("%s1%s", strings.Repeat("(", expr.MaxDepth+1), strings.Repeat(")", expr.MaxDepth+1)),
+	}
+
+	for _, s := range scenarios {
+		t.Run(s, func(t *testing.T) {
+			if _, err := expr.Parse(s); err == nil {
+				t.Fatalf("Expected parse error for %q", s)
+			}
+		})
+	}
+}
+
+func TestProgramIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	program, err := expr.Parse(`a + lower(b) + a * (c - 1) + "d" + true + null`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a", "b", "c"}
+
+	result := program.Identifiers()
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected identifiers %v, got %v", expected, result)
+	}
+
+	for i, name := range expected {
+		if result[i] != name {
+			t.Fatalf("Expected identifiers %v, got %v", expected, result)
+		}
+	}
+}
+
+func TestProgramEval(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]any{
+		"firstName": "John",
+		"lastName":  "Doe",
+		"empty":     "",
+		"num1":      10,
+		"num2":      2.5,
+		"numStr":    "4",
+		"yes":       true,
+		"date":      types.DateTime{},
+		"tags":      []string{"a", "b"},
+		"birthdate": time.Now().UTC().AddDate(-20, 0, -1).Format(types.DefaultDateLayout),
+		"nextYear":  time.Now().UTC().AddDate(-20, 0, 1).Format(types.DefaultDateLayout),
+		"big":       math.MaxFloat64,
+		"nan":       math.NaN(),
+	}
+
+	scenarios := []struct {
+		expression  string
+		expected    any
+		expectError bool
+	}{
+		// literals
+		{`123`, 123.0, false},
+		{`1.5`, 1.5, false},
+		{`"abc"`, "abc", false},
+		{`'a\'bc'`, "a'bc", false},
+		{`true`, true, false},
+		{`null`, nil, false},
+		// identifiers
+		{`firstName`, "John", false},
+		{`missing`, nil, false},
+		{`num1`, 10.0, false},
+		{`yes`, true, false},
+		{`date`, "", false},
+		{`tags`, "a,b", false},
+		// arithmetic
+		{`num1 + num2 * 2`, 15.0, false},
+		{`(num1 + num2) * 2`, 25.0, false},
+		{`-num1 - -2`, -8.0, false},
+		{`num1 / 4`, 2.5, false},
+		{`num1 % 3`, 1.0, false},
+		{`num1 * numStr - missing`, 40.0, false},
+		{`num1 + numStr`, "104", false}, // string concatenation
+		{`num1 / 0`, nil, true},
+		{`num1 % 0`, nil, true},
+		// non-finite numbers
+		{`big * 10`, nil, true},
+		{`1 / (big * 10)`, nil, true},
+		{`nan`, nil, true},
+		{`nan + 1`, nil, true},
+		{`abs(-big * 2)`, nil, true},
+		// concatenation
+		{`firstName + " " + lastName`, "John Doe", false},
+		{`firstName + num1`, "John10", false},
+		{`num2 + "x"`, "2.5x", false},
+		{`firstName + missing`, "John", false},
+		// functions
+		{`lower(firstName)`, "john", false},
+		{`upper(firstName)`, "JOHN", false},
+		{`trim("  a ")`, "a", false},
+		{`length("абв")`, 3.0, false},
+		{`concat(firstName, "-", num1)`, "John-10", false},
+		{`coalesce(empty, missing, lastName)`, "Doe", false},
+		{`coalesce(empty, missing)`, nil, false},
+		{`round(num2)`, 3.0, false},
+		{`round(1.2345, 2)`, 1.23, false},
+		{`floor(num2)`, 2.0, false},
+		{`ceil(num2)`, 3.0, false},
+		{`abs(-num1)`, 10.0, false},
+		{`min(num1, num2, 5)`, 2.5, false},
+		{`max(num1, num2, 5)`, 10.0, false},
+		{`years(birthdate)`, 20.0, false},
+		{`years(nextYear)`, 19.0, false},
+		{`years(date)`, nil, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expression, func(t *testing.T) {
+			program, err := expr.Parse(s.expression)
+			if err != nil {
+				t.Fatalf("Failed to parse expression: %v", err)
+			}
+
+			result, err := program.Eval(vars)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %v (%T), got %v (%T)", s.expected, s.expected, result, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package expr
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// Function defines a single expression function.
+type Function struct {
+	// MinArgs is the min number of required arguments.
+	MinArgs int
+
+	// MaxArgs is the max number of allowed arguments (-1 for no limit).
+	MaxArgs int
+
+	// Call is the function handler (the arguments are already normalized).
+	Call func(args []any) (any, error)
+}
+
+// Functions is a map with all functions that could be called in an expression.
+var Functions = map[string]*Function{
+	"lower": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		return strings.ToLower(toString(args[0])), nil
+	}},
+	"upper": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		return strings.ToUpper(toString(args[0])), nil
+	}},
+	"trim": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		return strings.TrimSpace(toString(args[0])), nil
+	}},
+	"length": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		return float64(len([]rune(toString(args[0])))), nil
+	}},
+	"concat": {MinArgs: 1, MaxArgs: -1, Call: func(args []any) (any, error) {
+		var sb strings.Builder
+		for _, arg := range args {
+			sb.WriteString(toString(arg))
+		}
+		return sb.String(), nil
+	}},
+	"coalesce": {MinArgs: 1, MaxArgs: -1, Call: func(args []any) (any, error) {
+		for _, arg := range args {
+			if arg != nil && arg != "" {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	}},
+	"round": {MinArgs: 1, MaxArgs: 2, Call: func(args []any) (any, error) {
+		var precision float64
+		if len(args) > 1 {
+			precision = toNumber(args[1])
+		}
+		pow := math.Pow(10, math.Trunc(precision))
+		return math.Round(toNumber(args[0])*pow) / pow, nil
+	}},
+	"floor": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		return math.Floor(toNumber(args[0])), nil
+	}},
+	"ceil": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		return math.Ceil(toNumber(args[0])), nil
+	}},
+	"abs": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		return math.Abs(toNumber(args[0])), nil
+	}},
+	"min": {MinArgs: 1, MaxArgs: -1, Call: func(args []any) (any, error) {
+		result := toNumber(args[0])
+		for _, arg := range args[1:] {
+			result = math.Min(result, toNumber(arg))
+		}
+		return result, nil
+	}},
+	"max": {MinArgs: 1, MaxArgs: -1, Call: func(args []any) (any, error) {
+		result := toNumber(args[0])
+		for _, arg := range args[1:] {
+			result = math.Max(result, toNumber(arg))
+		}
+		return result, nil
+	}},
+	// years returns the number of the full years elapsed since the
+	// provided date (eg. to calculate an age from a birthdate).
+	"years": {MinArgs: 1, MaxArgs: 1, Call: func(args []any) (any, error) {
+		date, _ := types.ParseDateTime(args[0])
+		if date.IsZero() {
+			return nil, nil
+		}
+
+		from := date.Time()
+		to := time.Now().UTC()
+
+		years := to.Year() - from.Year()
+		if to.Month() < from.Month() || (to.Month() == from.Month() && to.Day() < from.Day()) {
+			years--
+		}
+
+		return float64(years), nil
+	}},
+}
+
+// -------------------------------------------------------------------
+
+// normalize converts the provided variable value to one of the
+// expression primitive types (nil, bool, float64 or string).
+func normalize(v any) any {
+	switch val := v.(type) {
+	case nil, bool, float64, string:
+		return val
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32:
+		return cast.ToFloat64(val)
+	case types.DateTime:
+		if val.IsZero() {
+			return ""
+		}
+		return val.String()
+	case []string:
+		return strings.Join(val, ",")
+	default:
+		return cast.ToString(val)
+	}
+}
+
+func toString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return cast.ToString(val)
+	}
+}
+
+func toNumber(v any) float64 {
+	return cast.ToFloat64(v)
+}
+
+func arithmetic(op string, l, r float64) (any, error) {
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return math.Mod(l, r), nil
+	}
+
+	return nil, nil
+}
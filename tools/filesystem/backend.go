@@ -0,0 +1,476 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/driver"
+	"gocloud.dev/gcerrors"
+)
+
+// ErrNotFound is the error returned (or wrapped) by the backends
+// when the requested file doesn't exist.
+var ErrNotFound = errors.New("file not found")
+
+// ErrNotSupported is the error returned (or wrapped) by the backends
+// when the requested operation is not supported (eg. presigning urls
+// for the local filesystem).
+var ErrNotSupported = errors.New("operation not supported")
+
+// Backend defines a minimal storage backend that could be used to
+// construct a [System] instance (see [NewSystem] and [RegisterBackend]).
+//
+// There is no dedicated serve method because the file serving is handled
+// generically by [System.Serve] with the help of Open and Attributes.
+type Backend interface {
+	// Attributes returns the attributes of the file stored at key.
+	//
+	// It must return an error wrapping [ErrNotFound] if the file doesn't exist.
+	Attributes(ctx context.Context, key string) (*BackendAttributes, error)
+
+	// Open returns a reader for length bytes of the file stored at key
+	// starting from offset (negative length means until the end of the file).
+	//
+	// It must return an error wrapping [ErrNotFound] if the file doesn't exist.
+	Open(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+
+	// Upload returns a writer that stores its content at key on Close.
+	//
+	// If ctx is canceled before Close, the upload must be aborted.
+	Upload(ctx context.Context, key string, opts *BackendUploadOptions) (io.WriteCloser, error)
+
+	// Delete deletes the file stored at key.
+	//
+	// It must return an error wrapping [ErrNotFound] if the file doesn't exist.
+	Delete(ctx context.Context, key string) error
+
+	// List returns the attributes of all files with the specified key prefix.
+	List(ctx context.Context, prefix string) ([]*BackendAttributes, error)
+
+	// Presign returns a temporary url for the specified http method
+	// (GET or PUT) to the file stored at key.
+	//
+	// It must return an error wrapping [ErrNotSupported] if the backend doesn't support signing.
+	Presign(ctx context.Context, key string, method string, expiry time.Duration) (string, error)
+
+	// Close releases any resources used by the backend.
+	Close() error
+}
+
+// BackendAttributes defines the attributes of a single backend file.
+type BackendAttributes struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+	ETag        string
+	Metadata    map[string]string
+}
+
+// BackendUploadOptions defines the options of a single backend upload.
+type BackendUploadOptions struct {
+	// ContentType is the file MIME type (it is always set by [System]).
+	ContentType string
+
+	// Metadata is an optional key-value metadata to store with the file.
+	Metadata map[string]string
+}
+
+// BackendFactory defines a function that creates a new [Backend]
+// instance from a generic options map (eg. loaded from the app settings).
+type BackendFactory func(options map[string]any) (Backend, error)
+
+var (
+	backendsMux sync.RWMutex
+	backends    = map[string]BackendFactory{}
+)
+
+// RegisterBackend registers a new named backend factory
+// (replacing the existing one with the same name, if any).
+//
+// The registered backends could be selected by name in the app
+// storage settings or created manually with [NewByBackendName].
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMux.Lock()
+	defer backendsMux.Unlock()
+
+	backends[name] = factory
+}
+
+// HasBackend checks whether a backend with the specified name is registered.
+func HasBackend(name string) bool {
+	backendsMux.RLock()
+	defer backendsMux.RUnlock()
+
+	_, ok := backends[name]
+
+	return ok
+}
+
+// BackendNames returns a sorted list with the names of all registered backends.
+func BackendNames() []string {
+	backendsMux.RLock()
+	defer backendsMux.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewBackendByName creates a new [Backend] instance with the
+// factory registered under the specified name.
+func NewBackendByName(name string, options map[string]any) (Backend, error) {
+	backendsMux.RLock()
+	factory, ok := backends[name]
+	backendsMux.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("missing filesystem backend %q", name)
+	}
+
+	return factory(options)
+}
+
+// NewByBackendName initializes a new filesystem instance with the
+// backend registered under the specified name.
+//
+// NB! Make sure to call `Close()` after you are done working with it.
+func NewByBackendName(name string, options map[string]any) (*System, error) {
+	backend, err := NewBackendByName(name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSystem(backend), nil
+}
+
+// NewSystem initializes a new filesystem instance from the provided backend.
+//
+// NB! Make sure to call `Close()` after you are done working with it.
+func NewSystem(backend Backend) *System {
+	if b, ok := backend.(*bucketBackend); ok {
+		return &System{ctx: context.Background(), bucket: b.bucket}
+	}
+
+	return &System{ctx: context.Background(), bucket: blob.NewBucket(&backendDriver{backend: backend})}
+}
+
+// -------------------------------------------------------------------
+
+// NewBucketBackend wraps the provided [blob.Bucket] into a [Backend]
+// (eg. to register a backend based on one of the other Go CDK blob drivers).
+func NewBucketBackend(bucket *blob.Bucket) Backend {
+	return &bucketBackend{bucket: bucket}
+}
+
+var _ Backend = (*bucketBackend)(nil)
+
+// bucketBackend is a [Backend] implementation backed by a [blob.Bucket].
+type bucketBackend struct {
+	bucket *blob.Bucket
+}
+
+// Attributes implements the [Backend.Attributes] interface method.
+func (b *bucketBackend) Attributes(ctx context.Context, key string) (*BackendAttributes, error) {
+	attrs, err := b.bucket.Attributes(ctx, key)
+	if err != nil {
+		return nil, wrapBucketError(err)
+	}
+
+	return &BackendAttributes{
+		Key:         key,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		ModTime:     attrs.ModTime,
+		ETag:        attrs.ETag,
+		Metadata:    attrs.Metadata,
+	}, nil
+}
+
+// Open implements the [Backend.Open] interface method.
+func (b *bucketBackend) Open(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	r, err := b.bucket.NewRangeReader(ctx, key, offset, length, nil)
+	if err != nil {
+		return nil, wrapBucketError(err)
+	}
+
+	return r, nil
+}
+
+// Upload implements the [Backend.Upload] interface method.
+func (b *bucketBackend) Upload(ctx context.Context, key string, opts *BackendUploadOptions) (io.WriteCloser, error) {
+	writerOpts := &blob.WriterOptions{}
+	if opts != nil {
+		writerOpts.ContentType = opts.ContentType
+		writerOpts.Metadata = opts.Metadata
+	}
+
+	return b.bucket.NewWriter(ctx, key, writerOpts)
+}
+
+// Delete implements the [Backend.Delete] interface method.
+func (b *bucketBackend) Delete(ctx context.Context, key string) error {
+	return wrapBucketError(b.bucket.Delete(ctx, key))
+}
+
+// List implements the [Backend.List] interface method.
+func (b *bucketBackend) List(ctx context.Context, prefix string) ([]*BackendAttributes, error) {
+	result := []*BackendAttributes{}
+
+	iter := b.bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err != nil {
+			if err != io.EOF {
+				return nil, wrapBucketError(err)
+			}
+			break
+		}
+
+		result = append(result, &BackendAttributes{
+			Key:     obj.Key,
+			Size:    obj.Size,
+			ModTime: obj.ModTime,
+		})
+	}
+
+	return result, nil
+}
+
+// Presign implements the [Backend.Presign] interface method.
+func (b *bucketBackend) Presign(ctx context.Context, key string, method string, expiry time.Duration) (string, error) {
+	url, err := b.bucket.SignedURL(ctx, key, &blob.SignedURLOptions{
+		Method: method,
+		Expiry: expiry,
+	})
+	if err != nil {
+		return "", wrapBucketError(err)
+	}
+
+	return url, nil
+}
+
+// Close implements the [Backend.Close] interface method.
+func (b *bucketBackend) Close() error {
+	return b.bucket.Close()
+}
+
+// wrapBucketError wraps the known blob error codes with
+// their related [ErrNotFound] and [ErrNotSupported] errors.
+func wrapBucketError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch gcerrors.Code(err) {
+	case gcerrors.NotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case gcerrors.Unimplemented:
+		return fmt.Errorf("%w: %w", ErrNotSupported, err)
+	}
+
+	return err
+}
+
+// -------------------------------------------------------------------
+
+var _ driver.Bucket = (*backendDriver)(nil)
+
+// backendDriver adapts a [Backend] to a [driver.Bucket] so that
+// it could be used with the regular [blob.Bucket] methods.
+type backendDriver struct {
+	backend Backend
+}
+
+// ErrorCode implements the [driver.Bucket.ErrorCode] interface method.
+func (d *backendDriver) ErrorCode(err error) gcerrors.ErrorCode {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return gcerrors.NotFound
+	case errors.Is(err, ErrNotSupported):
+		return gcerrors.Unimplemented
+	}
+
+	return gcerrors.Unknown
+}
+
+// As implements the [driver.Bucket.As] interface method.
+func (d *backendDriver) As(i any) bool {
+	return false
+}
+
+// ErrorAs implements the [driver.Bucket.ErrorAs] interface method.
+func (d *backendDriver) ErrorAs(err error, i any) bool {
+	return false
+}
+
+// Attributes implements the [driver.Bucket.Attributes] interface method.
+func (d *backendDriver) Attributes(ctx context.Context, key string) (*driver.Attributes, error) {
+	attrs, err := d.backend.Attributes(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &driver.Attributes{
+		ContentType: attrs.ContentType,
+		Metadata:    attrs.Metadata,
+		ModTime:     attrs.ModTime,
+		Size:        attrs.Size,
+		ETag:        attrs.ETag,
+	}, nil
+}
+
+// ListPaged implements the [driver.Bucket.ListPaged] interface method.
+//
+// All matching objects are returned as a single page.
+func (d *backendDriver) ListPaged(ctx context.Context, opts *driver.ListOptions) (*driver.ListPage, error) {
+	files, err := d.backend.List(ctx, opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Key < files[j].Key
+	})
+
+	page := &driver.ListPage{}
+	dirs := map[string]struct{}{}
+
+	for _, f := range files {
+		if !strings.HasPrefix(f.Key, opts.Prefix) {
+			continue
+		}
+
+		// group the nested files into a single "directory" object
+		if opts.Delimiter != "" {
+			rest := f.Key[len(opts.Prefix):]
+			if idx := strings.Index(rest, opts.Delimiter); idx >= 0 {
+				dir := opts.Prefix + rest[:idx+len(opts.Delimiter)]
+				if _, ok := dirs[dir]; !ok {
+					dirs[dir] = struct{}{}
+					page.Objects = append(page.Objects, &driver.ListObject{Key: dir, IsDir: true})
+				}
+				continue
+			}
+		}
+
+		page.Objects = append(page.Objects, &driver.ListObject{
+			Key:     f.Key,
+			ModTime: f.ModTime,
+			Size:    f.Size,
+		})
+	}
+
+	return page, nil
+}
+
+// NewRangeReader implements the [driver.Bucket.NewRangeReader] interface method.
+func (d *backendDriver) NewRangeReader(ctx context.Context, key string, offset, length int64, opts *driver.ReaderOptions) (driver.Reader, error) {
+	attrs, err := d.backend.Attributes(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := d.backend.Open(ctx, key, offset, length)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backendReader{
+		ReadCloser: r,
+		attrs: &driver.ReaderAttributes{
+			ContentType: attrs.ContentType,
+			ModTime:     attrs.ModTime,
+			Size:        attrs.Size,
+		},
+	}, nil
+}
+
+// NewTypedWriter implements the [driver.Bucket.NewTypedWriter] interface method.
+func (d *backendDriver) NewTypedWriter(ctx context.Context, key, contentType string, opts *driver.WriterOptions) (driver.Writer, error) {
+	uploadOpts := &BackendUploadOptions{ContentType: contentType}
+	if opts != nil {
+		uploadOpts.Metadata = opts.Metadata
+	}
+
+	return d.backend.Upload(ctx, key, uploadOpts)
+}
+
+// Copy implements the [driver.Bucket.Copy] interface method.
+func (d *backendDriver) Copy(ctx context.Context, dstKey, srcKey string, opts *driver.CopyOptions) error {
+	attrs, err := d.backend.Attributes(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+
+	r, err := d.backend.Open(ctx, srcKey, 0, -1)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := d.backend.Upload(ctx, dstKey, &BackendUploadOptions{
+		ContentType: attrs.ContentType,
+		Metadata:    attrs.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// Delete implements the [driver.Bucket.Delete] interface method.
+func (d *backendDriver) Delete(ctx context.Context, key string) error {
+	return d.backend.Delete(ctx, key)
+}
+
+// SignedURL implements the [driver.Bucket.SignedURL] interface method.
+func (d *backendDriver) SignedURL(ctx context.Context, key string, opts *driver.SignedURLOptions) (string, error) {
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return d.backend.Presign(ctx, key, method, opts.Expiry)
+}
+
+// Close implements the [driver.Bucket.Close] interface method.
+func (d *backendDriver) Close() error {
+	return d.backend.Close()
+}
+
+// backendReader is a [driver.Reader] implementation
+// that wraps a [Backend.Open] reader.
+type backendReader struct {
+	io.ReadCloser
+	attrs *driver.ReaderAttributes
+}
+
+// Attributes implements the [driver.Reader.Attributes] interface method.
+func (r *backendReader) Attributes() *driver.ReaderAttributes {
+	return r.attrs
+}
+
+// As implements the [driver.Reader.As] interface method.
+func (r *backendReader) As(i any) bool {
+	return false
+}
@@ -0,0 +1,100 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// The names of the builtin filesystem backends.
+const (
+	// BackendLocal is the local filesystem backend.
+	//
+	// Options: "dir".
+	BackendLocal = "local"
+
+	// BackendS3 is the S3 compatible storage backend.
+	//
+	// Options: "bucket", "region", "endpoint", "accessKey", "secret", "forcePathStyle".
+	BackendS3 = "s3"
+
+	// BackendAzureBlob is the Azure Blob Storage backend.
+	//
+	// Options: "account", "key", "container", "prefix".
+	BackendAzureBlob = "azureBlob"
+)
+
+func init() {
+	RegisterBackend(BackendLocal, newLocalBackend)
+	RegisterBackend(BackendS3, newS3Backend)
+	RegisterBackend(BackendAzureBlob, newAzureBlobBackend)
+}
+
+func newLocalBackend(options map[string]any) (Backend, error) {
+	opts := struct {
+		Dir string `json:"dir"`
+	}{}
+	if err := decodeBackendOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	if opts.Dir == "" {
+		return nil, errors.New("local backend: missing dir option")
+	}
+
+	fs, err := NewLocal(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBucketBackend(fs.bucket), nil
+}
+
+func newS3Backend(options map[string]any) (Backend, error) {
+	opts := struct {
+		Bucket         string `json:"bucket"`
+		Region         string `json:"region"`
+		Endpoint       string `json:"endpoint"`
+		AccessKey      string `json:"accessKey"`
+		Secret         string `json:"secret"`
+		ForcePathStyle bool   `json:"forcePathStyle"`
+	}{}
+	if err := decodeBackendOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	fs, err := NewS3(opts.Bucket, opts.Region, opts.Endpoint, opts.AccessKey, opts.Secret, opts.ForcePathStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBucketBackend(fs.bucket), nil
+}
+
+func newAzureBlobBackend(options map[string]any) (Backend, error) {
+	opts := struct {
+		Account   string `json:"account"`
+		Key       string `json:"key"`
+		Container string `json:"container"`
+		Prefix    string `json:"prefix"`
+	}{}
+	if err := decodeBackendOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	fs, err := NewAzureBlob(opts.Account, opts.Key, opts.Container, opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBucketBackend(fs.bucket), nil
+}
+
+// decodeBackendOptions decodes the generic backend options map into dst.
+func decodeBackendOptions(options map[string]any, dst any) error {
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, dst)
+}
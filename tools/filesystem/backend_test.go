@@ -0,0 +1,379 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestBackendRegistry(t *testing.T) {
+	names := filesystem.BackendNames()
+	for _, name := range []string{filesystem.BackendLocal, filesystem.BackendS3, filesystem.BackendAzureBlob} {
+		if !slices.Contains(names, name) {
+			t.Fatalf("Expected builtin backend %q to be registered, got %v", name, names)
+		}
+	}
+
+	if filesystem.HasBackend("registry_test") {
+		t.Fatal("Expected registry_test backend to be missing")
+	}
+
+	if _, err := filesystem.NewBackendByName("registry_test", nil); err == nil {
+		t.Fatal("Expected missing backend error")
+	}
+
+	var factoryOptions map[string]any
+	filesystem.RegisterBackend("registry_test", func(options map[string]any) (filesystem.Backend, error) {
+		factoryOptions = options
+		return newMemoryBackend(), nil
+	})
+
+	if !filesystem.HasBackend("registry_test") {
+		t.Fatal("Expected registry_test backend to be registered")
+	}
+
+	backend, err := filesystem.NewBackendByName("registry_test", map[string]any{"a": 123})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	if factoryOptions["a"] != 123 {
+		t.Fatalf("Expected the options to be passed to the factory, got %v", factoryOptions)
+	}
+}
+
+func TestNewByBackendNameLocal(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	if _, err := filesystem.NewByBackendName(filesystem.BackendLocal, nil); err == nil {
+		t.Fatal("Expected missing dir option error")
+	}
+
+	fs, err := filesystem.NewByBackendName(filesystem.BackendLocal, map[string]any{"dir": dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if exists, _ := fs.Exists("image.png"); !exists {
+		t.Fatal("Expected image.png to exist")
+	}
+}
+
+func TestSystemWithCustomBackend(t *testing.T) {
+	backend := newMemoryBackend()
+
+	filesystem.RegisterBackend("memory_test", func(options map[string]any) (filesystem.Backend, error) {
+		return backend, nil
+	})
+
+	fs, err := filesystem.NewByBackendName("memory_test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if ok, err := fs.IsAccessible(); !ok || err != nil {
+		t.Fatalf("Expected the filesystem to be accessible, got %v (%v)", ok, err)
+	}
+
+	// upload
+	if err := fs.Upload([]byte("hello world"), "a/test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Upload([]byte("nested"), "a/b/test2.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := fs.NewWriter("c/meta.txt", "text/custom", map[string]string{"original-filename": "meta.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("meta"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// exists
+	if exists, err := fs.Exists("a/test.txt"); !exists || err != nil {
+		t.Fatalf("Expected a/test.txt to exist, got %v (%v)", exists, err)
+	}
+	if exists, err := fs.Exists("missing.txt"); exists || err != nil {
+		t.Fatalf("Expected missing.txt to not exist, got %v (%v)", exists, err)
+	}
+
+	// attributes
+	attrs, err := fs.Attributes("c/meta.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.ContentType != "text/custom" || attrs.Size != 4 || attrs.Metadata["original-filename"] != "meta.txt" {
+		t.Fatalf("Unexpected attributes %v", attrs)
+	}
+
+	// get
+	br, err := fs.GetFile("a/test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(br)
+	br.Close()
+	if string(content) != "hello world" {
+		t.Fatalf("Expected content %q, got %q", "hello world", content)
+	}
+
+	// list
+	files, err := fs.List("a/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, f := range files {
+		keys = append(keys, f.Key)
+	}
+	if strings.Join(keys, ",") != "a/b/test2.txt,a/test.txt" {
+		t.Fatalf("Unexpected listed files %v", keys)
+	}
+
+	// copy
+	if err := fs.Copy("c/meta.txt", "c/meta_copy.txt"); err != nil {
+		t.Fatal(err)
+	}
+	copyAttrs, err := fs.Attributes("c/meta_copy.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copyAttrs.ContentType != "text/custom" || copyAttrs.Metadata["original-filename"] != "meta.txt" {
+		t.Fatalf("Expected the copy to preserve the attributes, got %v", copyAttrs)
+	}
+
+	// serve (range)
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Range", "bytes=6-10")
+	if err := fs.Serve(res, req, "a/test.txt", "test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != http.StatusPartialContent || res.Body.String() != "world" {
+		t.Fatalf("Expected 206 %q response, got %d %q", "world", res.Code, res.Body.String())
+	}
+	if etag := res.Header().Get("Etag"); etag != `"memory-11"` {
+		t.Fatalf("Expected the backend ETag, got %q", etag)
+	}
+
+	// presign
+	url, err := fs.PresignUpload("a/new.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "memory://a/new.txt?method=PUT" {
+		t.Fatalf("Unexpected presigned url %q", url)
+	}
+
+	// aborted upload
+	ctx, cancel := context.WithCancel(context.Background())
+	fs.SetContext(ctx)
+	w, err = fs.NewWriter("aborted.txt", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("aborted"))
+	cancel()
+	w.Close()
+	fs.SetContext(context.Background())
+	if exists, _ := fs.Exists("aborted.txt"); exists {
+		t.Fatal("Expected the aborted upload to be discarded")
+	}
+
+	// delete
+	if err := fs.Delete("a/test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := fs.Exists("a/test.txt"); exists {
+		t.Fatal("Expected a/test.txt to be deleted")
+	}
+	if err := fs.Delete("a/test.txt"); err == nil {
+		t.Fatal("Expected not found delete error")
+	}
+
+	// delete prefix
+	if errs := fs.DeletePrefix("c/"); len(errs) != 0 {
+		t.Fatalf("Expected no delete prefix errors, got %v", errs)
+	}
+	if total := backend.total(); total != 1 {
+		t.Fatalf("Expected only a/b/test2.txt to remain, got %d files", total)
+	}
+}
+
+func TestSystemWithCustomBackendNotSupported(t *testing.T) {
+	backend := newMemoryBackend()
+	backend.noPresign = true
+
+	fs := filesystem.NewSystem(backend)
+	defer fs.Close()
+
+	_, err := fs.SignedURL("test.txt", time.Minute)
+	if !errors.Is(err, filesystem.ErrNotSupported) {
+		t.Fatalf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+// -------------------------------------------------------------------
+
+type memoryFile struct {
+	content     []byte
+	contentType string
+	metadata    map[string]string
+	modTime     time.Time
+}
+
+// memoryBackend is a fake in-memory filesystem backend.
+type memoryBackend struct {
+	mux       sync.Mutex
+	files     map[string]*memoryFile
+	noPresign bool
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{files: map[string]*memoryFile{}}
+}
+
+func (b *memoryBackend) total() int {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return len(b.files)
+}
+
+func (b *memoryBackend) find(key string) (*memoryFile, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	f, ok := b.files[key]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", key, filesystem.ErrNotFound)
+	}
+
+	return f, nil
+}
+
+func (b *memoryBackend) attributes(key string, f *memoryFile) *filesystem.BackendAttributes {
+	return &filesystem.BackendAttributes{
+		Key:         key,
+		Size:        int64(len(f.content)),
+		ContentType: f.contentType,
+		ModTime:     f.modTime,
+		ETag:        fmt.Sprintf(`"memory-%d"`, len(f.content)),
+		Metadata:    f.metadata,
+	}
+}
+
+func (b *memoryBackend) Attributes(ctx context.Context, key string) (*filesystem.BackendAttributes, error) {
+	f, err := b.find(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.attributes(key, f), nil
+}
+
+func (b *memoryBackend) Open(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	f, err := b.find(key)
+	if err != nil {
+		return nil, err
+	}
+
+	content := f.content[min(offset, int64(len(f.content))):]
+	if length >= 0 && length < int64(len(content)) {
+		content = content[:length]
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (b *memoryBackend) Upload(ctx context.Context, key string, opts *filesystem.BackendUploadOptions) (io.WriteCloser, error) {
+	return &memoryWriter{ctx: ctx, backend: b, key: key, opts: opts}, nil
+}
+
+func (b *memoryBackend) Delete(ctx context.Context, key string) error {
+	if _, err := b.find(key); err != nil {
+		return err
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	delete(b.files, key)
+
+	return nil
+}
+
+func (b *memoryBackend) List(ctx context.Context, prefix string) ([]*filesystem.BackendAttributes, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	result := []*filesystem.BackendAttributes{}
+	for key, f := range b.files {
+		if strings.HasPrefix(key, prefix) {
+			result = append(result, b.attributes(key, f))
+		}
+	}
+
+	return result, nil
+}
+
+func (b *memoryBackend) Presign(ctx context.Context, key string, method string, expiry time.Duration) (string, error) {
+	if b.noPresign {
+		return "", filesystem.ErrNotSupported
+	}
+
+	return "memory://" + key + "?method=" + method, nil
+}
+
+func (b *memoryBackend) Close() error {
+	return nil
+}
+
+type memoryWriter struct {
+	ctx     context.Context
+	backend *memoryBackend
+	key     string
+	opts    *filesystem.BackendUploadOptions
+	buf     bytes.Buffer
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memoryWriter) Close() error {
+	if err := w.ctx.Err(); err != nil {
+		return errors.Join(errors.New("upload aborted"), err)
+	}
+
+	w.backend.mux.Lock()
+	defer w.backend.mux.Unlock()
+
+	w.backend.files[w.key] = &memoryFile{
+		content:     w.buf.Bytes(),
+		contentType: w.opts.ContentType,
+		metadata:    w.opts.Metadata,
+		modTime:     time.Now(),
+	}
+
+	return nil
+}
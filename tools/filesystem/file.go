@@ -168,12 +168,22 @@ func (r *bytesReadSeekCloser) Close() error {
 
 var extInvalidCharsRegex = regexp.MustCompile(`[^\w\.\*\-\+\=\#]+`)
 
+// NormalizeName returns a storage safe version of the provided
+// original file name with an additional random suffix
+// (eg. "My Photo.png" -> "my_photo_a1b2c3d4e5.png").
+//
+// Unlike the File constructors, it doesn't try to detect
+// a missing extension from the file content.
+func NormalizeName(name string) string {
+	return normalizeName(nil, name)
+}
+
 func normalizeName(fr FileReader, name string) string {
 	// extension
 	// ---
 	originalExt := extractExtension(name)
 	cleanExt := extInvalidCharsRegex.ReplaceAllString(originalExt, "")
-	if cleanExt == "" {
+	if cleanExt == "" && fr != nil {
 		// try to detect the extension from the file content
 		cleanExt, _ = detectExtension(fr)
 	}
@@ -205,3 +205,25 @@ func TestFileNameNormalizations(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeName(t *testing.T) {
+	scenarios := []struct {
+		name    string
+		pattern string
+	}{
+		{"", `^\w{10}_\w{10}$`}, // no content based extension detection
+		{"abcd", `^abcd_\w{10}$`},
+		{".png", `^\w{10}_\w{10}\.png$`},
+		{"My Photo.png", `^my_photo_\w{10}\.png$`},
+		{"a  b! c d  . 456", `^a_b_c_d_\w{10}\.456$`},
+	}
+
+	for i, s := range scenarios {
+		t.Run(strconv.Itoa(i)+"_"+s.name, func(t *testing.T) {
+			result := filesystem.NormalizeName(s.name)
+			if match, err := regexp.Match(s.pattern, []byte(result)); !match {
+				t.Fatalf("Expected name to match %v, got %q (%v)", s.pattern, result, err)
+			}
+		})
+	}
+}
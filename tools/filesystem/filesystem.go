@@ -2,18 +2,24 @@ package filesystem
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"image"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/HugoSmits86/nativewebp"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -22,6 +28,7 @@ import (
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"gocloud.dev/blob"
+	"gocloud.dev/blob/azureblob"
 	"gocloud.dev/blob/fileblob"
 )
 
@@ -84,6 +91,53 @@ func NewS3(
 	return &System{ctx: ctx, bucket: bucket}, nil
 }
 
+// NewAzureBlob initializes an Azure Blob Storage filesystem instance
+// authenticated with the storage account shared key.
+//
+// prefix is an optional path prefix (eg. "pb_data/") that is applied
+// to all file keys of the filesystem instance.
+//
+// NB! Make sure to call `Close()` after you are done working with it.
+func NewAzureBlob(
+	account string,
+	key string,
+	containerName string,
+	prefix string,
+) (*System, error) {
+	ctx := context.Background() // default context
+
+	cred, err := azblob.NewSharedKeyCredential(account, key)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceURL, err := azureblob.NewServiceURL(&azureblob.ServiceURLOptions{AccountName: account})
+	if err != nil {
+		return nil, err
+	}
+
+	containerURL, err := url.JoinPath(string(serviceURL), containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := container.NewClientWithSharedKeyCredential(containerURL, cred, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := azureblob.OpenBucket(ctx, client, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix != "" {
+		bucket = blob.PrefixedBucket(bucket, strings.TrimSuffix(prefix, "/")+"/")
+	}
+
+	return &System{ctx: ctx, bucket: bucket}, nil
+}
+
 // NewLocal initializes a new local filesystem instance.
 //
 // NB! Make sure to call `Close()` after you are done working with it.
@@ -115,6 +169,12 @@ func (s *System) Close() error {
 	return s.bucket.Close()
 }
 
+// IsAccessible checks if the filesystem bucket exists and is reachable
+// (eg. to verify the S3 credentials).
+func (s *System) IsAccessible() (bool, error) {
+	return s.bucket.IsAccessible(s.ctx)
+}
+
 // Exists checks if file with fileKey path exists or not.
 func (s *System) Exists(fileKey string) (bool, error) {
 	return s.bucket.Exists(s.ctx, fileKey)
@@ -137,6 +197,48 @@ func (s *System) GetFile(fileKey string) (*blob.Reader, error) {
 	return br, nil
 }
 
+// DefaultPresignUploadExpiry is the default validity duration of a presigned upload url.
+const DefaultPresignUploadExpiry = 15 * time.Minute
+
+// PresignUploadOptions defines the optional settings of a presigned upload url.
+type PresignUploadOptions struct {
+	// Expiry is the duration for which the url is valid
+	// (fallbacks to [DefaultPresignUploadExpiry] if not set).
+	Expiry time.Duration
+}
+
+// PresignUpload returns a temporary PUT url that could be used by
+// the clients to upload a file directly to the fileKey location
+// (eg. a presigned url for S3 or a SAS url for Azure Blob Storage).
+//
+// Note that the url doesn't enforce any size or content type constraints,
+// so the uploaded file must be validated separately after the upload.
+//
+// Returns an error if the underlying storage doesn't support signing (eg. the local filesystem).
+func (s *System) PresignUpload(fileKey string, opts *PresignUploadOptions) (string, error) {
+	expiry := DefaultPresignUploadExpiry
+	if opts != nil && opts.Expiry > 0 {
+		expiry = opts.Expiry
+	}
+
+	return s.bucket.SignedURL(s.ctx, fileKey, &blob.SignedURLOptions{
+		Method: http.MethodPut,
+		Expiry: expiry,
+	})
+}
+
+// SignedURL returns a temporary GET url for the file with fileKey path
+// that is valid for the specified expiry duration
+// (eg. a SAS url for Azure Blob Storage or a presigned url for S3).
+//
+// Returns an error if the underlying storage doesn't support signing (eg. the local filesystem).
+func (s *System) SignedURL(fileKey string, expiry time.Duration) (string, error) {
+	return s.bucket.SignedURL(s.ctx, fileKey, &blob.SignedURLOptions{
+		Method: http.MethodGet,
+		Expiry: expiry,
+	})
+}
+
 // Copy copies the file stored at srcKey to dstKey.
 //
 // If dstKey file already exists, it is overwritten.
@@ -185,6 +287,23 @@ func (s *System) Upload(content []byte, fileKey string) error {
 	return w.Close()
 }
 
+// NewWriter returns a writer that streams its content into the fileKey location
+// (eg. a multipart upload in case of S3).
+//
+// The optional metadata is stored with the file and could be
+// retrieved later with [System.Attributes].
+//
+// The upload is finalized on Close. To abort it and discard the written content,
+// cancel the filesystem context (see [System.SetContext]) before calling Close.
+//
+// NB! Make sure to call `Close()` after you are done working with it.
+func (s *System) NewWriter(fileKey string, contentType string, metadata map[string]string) (*blob.Writer, error) {
+	return s.bucket.NewWriter(s.ctx, fileKey, &blob.WriterOptions{
+		ContentType: contentType,
+		Metadata:    metadata,
+	})
+}
+
 // UploadFile uploads the provided multipart file to the fileKey location.
 func (s *System) UploadFile(file *File, fileKey string) error {
 	f, err := file.Reader.Open()
@@ -405,11 +524,33 @@ func (s *System) Serve(res http.ResponseWriter, req *http.Request, fileKey strin
 	// that are made in the last day while revalidating the res in the background)
 	setHeaderIfMissing(res, "Cache-Control", "max-age=2592000, stale-while-revalidate=86400")
 
+	// set a strong ETag so that http.ServeContent could handle
+	// the If-None-Match conditional requests
+	// (If-Modified-Since is handled based on the file modification time)
+	if attrs, err := s.bucket.Attributes(s.ctx, fileKey); err == nil {
+		if etag := fileETag(attrs); etag != "" {
+			setHeaderIfMissing(res, "Etag", etag)
+		}
+	}
+
 	http.ServeContent(res, req, name, br.ModTime(), br)
 
 	return nil
 }
 
+// fileETag returns the ETag value for the file with the specified attributes.
+//
+// The file content MD5 hash is used if available, otherwise fallbacks
+// to the storage driver ETag (eg. for the local filesystem it is
+// derived from the file modification time and size).
+func fileETag(attrs *blob.Attributes) string {
+	if len(attrs.MD5) > 0 {
+		return `"` + hex.EncodeToString(attrs.MD5) + `"`
+	}
+
+	return attrs.ETag
+}
+
 // note: expects key to be in a canonical form (eg. "accept-encoding" should be "Accept-Encoding").
 func setHeaderIfMissing(res http.ResponseWriter, key string, value string) {
 	if _, ok := res.Header()[key]; !ok {
@@ -419,6 +560,34 @@ func setHeaderIfMissing(res http.ResponseWriter, key string, value string) {
 
 var ThumbSizeRegex = regexp.MustCompile(`^(\d+)x(\d+)(t|b|f)?$`)
 
+// ThumbEncoder defines a thumb encoder for a specific output image format.
+type ThumbEncoder struct {
+	// ContentType is the content type of the encoded thumb (eg. "image/webp").
+	ContentType string
+
+	// Encode writes img to w in the encoder format.
+	//
+	// quality is in the 1-100 range and it is relevant only for the lossy encoders.
+	Encode func(w io.Writer, img image.Image, quality int) error
+}
+
+// ThumbQuality is the quality (1-100) of the lossy encoded thumbs.
+//
+// It is used for the jpeg thumbs and it is also passed to the [ThumbEncoders].
+var ThumbQuality = 95
+
+// ThumbEncoders is a map with the additional thumb output formats
+// (keyed by their lowercased file extension without the dot).
+var ThumbEncoders = map[string]*ThumbEncoder{
+	// note: the encoder is lossless and ignores the quality argument
+	"webp": {
+		ContentType: "image/webp",
+		Encode: func(w io.Writer, img image.Image, quality int) error {
+			return nativewebp.Encode(w, img, nil)
+		},
+	},
+}
+
 // CreateThumb creates a new thumb image for the file at originalKey location.
 // The new thumb file is stored at thumbKey location.
 //
@@ -429,6 +598,11 @@ var ThumbSizeRegex = regexp.MustCompile(`^(\d+)x(\d+)(t|b|f)?$`)
 // - WxHt (eg. 300x100t) - resize and crop to WxH viewbox (from top)
 // - WxHb (eg. 300x100b) - resize and crop to WxH viewbox (from bottom)
 // - WxHf (eg. 300x100f) - fit inside a WxH viewbox (without cropping)
+//
+// If thumbKey has an extension registered in [ThumbEncoders]
+// (eg. "thumb.webp"), the thumb is encoded using that format.
+// Otherwise the format is detected from the thumbKey extension
+// (fallbacks to png).
 func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) error {
 	sizeParts := ThumbSizeRegex.FindStringSubmatch(thumbSize)
 	if len(sizeParts) != 4 {
@@ -479,27 +653,44 @@ func (s *System) CreateThumb(originalKey string, thumbKey, thumbSize string) err
 		}
 	}
 
+	encoder := ThumbEncoders[strings.ToLower(strings.TrimPrefix(filepath.Ext(thumbKey), "."))]
+
 	opts := &blob.WriterOptions{
 		ContentType: r.ContentType(),
 	}
+	if encoder != nil {
+		opts.ContentType = encoder.ContentType
+	}
+
+	// the writer context is canceled on encode error to discard
+	// the partially written thumb (otherwise it would be served as cached)
+	writerCtx, cancelWriter := context.WithCancel(s.ctx)
+	defer cancelWriter()
 
 	// open a thumb storage writer (aka. prepare for upload)
-	w, writerErr := s.bucket.NewWriter(s.ctx, thumbKey, opts)
+	w, writerErr := s.bucket.NewWriter(writerCtx, thumbKey, opts)
 	if writerErr != nil {
 		return writerErr
 	}
 
-	// try to detect the thumb format based on the original file name
-	// (fallbacks to png on error)
-	format, err := imaging.FormatFromFilename(thumbKey)
-	if err != nil {
-		format = imaging.PNG
-	}
-
 	// thumb encode (aka. upload)
-	if err := imaging.Encode(w, thumbImg, format); err != nil {
+	var encodeErr error
+	if encoder != nil {
+		encodeErr = encoder.Encode(w, thumbImg, ThumbQuality)
+	} else {
+		// try to detect the thumb format based on the original file name
+		// (fallbacks to png on error)
+		format, err := imaging.FormatFromFilename(thumbKey)
+		if err != nil {
+			format = imaging.PNG
+		}
+
+		encodeErr = imaging.Encode(w, thumbImg, format, imaging.JPEGQuality(ThumbQuality))
+	}
+	if encodeErr != nil {
+		cancelWriter()
 		w.Close()
-		return err
+		return encodeErr
 	}
 
 	// check for close errors to ensure that the thumb was really saved
@@ -2,17 +2,25 @@ package filesystem_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"image"
+	"image/color"
+	"image/gif"
 	"image/png"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"golang.org/x/image/webp"
 )
 
 func TestFileSystemExists(t *testing.T) {
@@ -44,6 +52,21 @@ func TestFileSystemExists(t *testing.T) {
 	}
 }
 
+func TestFileSystemIsAccessible(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if ok, err := fs.IsAccessible(); !ok || err != nil {
+		t.Fatalf("Expected the filesystem to be accessible, got %v (%v)", ok, err)
+	}
+}
+
 func TestFileSystemAttributes(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -278,6 +301,80 @@ func TestFileSystemUpload(t *testing.T) {
 	}
 }
 
+func TestFileSystemNewWriter(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	// successful write
+	{
+		w, err := fs.NewWriter("newdir/stream.txt", "text/plain", map[string]string{"test": "abc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, part := range []string{"a", "b", "c"} {
+			if _, err := w.Write([]byte(part)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := fs.GetFile("newdir/stream.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		content, _ := io.ReadAll(r)
+		if string(content) != "abc" {
+			t.Fatalf("Expected content %q, got %q", "abc", content)
+		}
+
+		if r.ContentType() != "text/plain" {
+			t.Fatalf("Expected text/plain content type, got %q", r.ContentType())
+		}
+
+		attrs, err := fs.Attributes("newdir/stream.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if attrs.Metadata["test"] != "abc" {
+			t.Fatalf("Expected metadata test=abc, got %v", attrs.Metadata)
+		}
+	}
+
+	// aborted write
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		fs.SetContext(ctx)
+
+		w, err := fs.NewWriter("aborted.txt", "text/plain", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.Write([]byte("test"))
+		cancel()
+		w.Close()
+
+		fs.SetContext(context.Background())
+
+		if exists, _ := fs.Exists("aborted.txt"); exists {
+			t.Fatal("Expected the aborted file to not be created")
+		}
+	}
+}
+
 func TestFileSystemServe(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -622,6 +719,113 @@ func TestFileSystemServeMultiRange(t *testing.T) {
 	}
 }
 
+func TestFileSystemServeConditional(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if err := fs.Upload([]byte("test"), "conditional.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(headers map[string]string) *http.Response {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		if err := fs.Serve(res, req, "conditional.txt", "conditional.txt"); err != nil {
+			t.Fatal(err)
+		}
+
+		return res.Result()
+	}
+
+	// md5 of "test"
+	expectedETag := `"098f6bcd4621d373cade4e832627b4f6"`
+
+	result := serve(nil)
+	if result.StatusCode != http.StatusOK {
+		t.Fatalf("Expected StatusCode %d, got %d", http.StatusOK, result.StatusCode)
+	}
+	if etag := result.Header.Get("ETag"); etag != expectedETag {
+		t.Fatalf("Expected ETag %q, got %q", expectedETag, etag)
+	}
+
+	scenarios := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			"matching If-None-Match",
+			map[string]string{"If-None-Match": expectedETag},
+			http.StatusNotModified,
+		},
+		{
+			"matching If-None-Match in a list",
+			map[string]string{"If-None-Match": `"abc", ` + expectedETag},
+			http.StatusNotModified,
+		},
+		{
+			"wildcard If-None-Match",
+			map[string]string{"If-None-Match": "*"},
+			http.StatusNotModified,
+		},
+		{
+			"non-matching If-None-Match",
+			map[string]string{"If-None-Match": `"abc"`},
+			http.StatusOK,
+		},
+		{
+			"If-Modified-Since in the future",
+			map[string]string{"If-Modified-Since": time.Now().Add(1 * time.Hour).UTC().Format(http.TimeFormat)},
+			http.StatusNotModified,
+		},
+		{
+			"If-Modified-Since in the past",
+			map[string]string{"If-Modified-Since": time.Now().Add(-1 * time.Hour).UTC().Format(http.TimeFormat)},
+			http.StatusOK,
+		},
+		{
+			"non-matching If-None-Match has precedence over If-Modified-Since",
+			map[string]string{
+				"If-None-Match":     `"abc"`,
+				"If-Modified-Since": time.Now().Add(1 * time.Hour).UTC().Format(http.TimeFormat),
+			},
+			http.StatusOK,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := serve(s.headers)
+			if result.StatusCode != s.expectedStatus {
+				t.Fatalf("Expected StatusCode %d, got %d", s.expectedStatus, result.StatusCode)
+			}
+		})
+	}
+
+	// replace the file content and check that the old ETag is no longer matching
+	if err := fs.Upload([]byte("test_new"), "conditional.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	result = serve(map[string]string{"If-None-Match": expectedETag})
+	if result.StatusCode != http.StatusOK {
+		t.Fatalf("Expected StatusCode %d after the file change, got %d", http.StatusOK, result.StatusCode)
+	}
+	if etag := result.Header.Get("ETag"); etag == "" || etag == expectedETag {
+		t.Fatalf("Expected new ETag, got %q", etag)
+	}
+}
+
 func TestFileSystemCreateThumb(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -669,6 +873,300 @@ func TestFileSystemCreateThumb(t *testing.T) {
 	}
 }
 
+func TestFileSystemCreateThumbFormats(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	// animated gif with a red first frame and a blue second frame
+	palette := color.Palette{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	frame1 := image.NewPaletted(image.Rect(0, 0, 10, 10), palette)
+	frame2 := image.NewPaletted(image.Rect(0, 0, 10, 10), palette)
+	for i := range frame2.Pix {
+		frame2.Pix[i] = 1
+	}
+	animated := &bytes.Buffer{}
+	if err := gif.EncodeAll(animated, &gif.GIF{Image: []*image.Paletted{frame1, frame2}, Delay: []int{10, 10}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Upload(animated.Bytes(), "animated.gif"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("webp", func(t *testing.T) {
+		if err := fs.CreateThumb("image.png", "thumbs/image.webp", "10x10"); err != nil {
+			t.Fatal(err)
+		}
+
+		attrs, err := fs.Attributes("thumbs/image.webp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attrs.ContentType != "image/webp" {
+			t.Fatalf("Expected image/webp content type, got %q", attrs.ContentType)
+		}
+	})
+
+	t.Run("animated gif to webp (first frame)", func(t *testing.T) {
+		if err := fs.CreateThumb("animated.gif", "thumbs/animated.webp", "5x5"); err != nil {
+			t.Fatal(err)
+		}
+
+		br, err := fs.GetFile("thumbs/animated.webp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer br.Close()
+
+		img, err := webp.Decode(br)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if w := img.Bounds().Dx(); w != 5 {
+			t.Fatalf("Expected thumb width 5, got %d", w)
+		}
+
+		r, g, b, _ := img.At(2, 2).RGBA()
+		if r>>8 != 255 || g != 0 || b != 0 {
+			t.Fatalf("Expected the first (red) frame, got rgb(%d, %d, %d)", r>>8, g>>8, b>>8)
+		}
+	})
+
+	t.Run("custom encoder", func(t *testing.T) {
+		var quality int
+		filesystem.ThumbEncoders["test"] = &filesystem.ThumbEncoder{
+			ContentType: "image/test",
+			Encode: func(w io.Writer, img image.Image, q int) error {
+				quality = q
+				_, err := w.Write([]byte("test_encoded"))
+				return err
+			},
+		}
+		defer delete(filesystem.ThumbEncoders, "test")
+
+		if err := fs.CreateThumb("image.png", "thumbs/image.TEST", "10x10"); err != nil {
+			t.Fatal(err)
+		}
+
+		if quality != filesystem.ThumbQuality {
+			t.Fatalf("Expected quality %d, got %d", filesystem.ThumbQuality, quality)
+		}
+
+		attrs, err := fs.Attributes("thumbs/image.TEST")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attrs.ContentType != "image/test" {
+			t.Fatalf("Expected image/test content type, got %q", attrs.ContentType)
+		}
+	})
+
+	t.Run("jpeg quality", func(t *testing.T) {
+		defer func(original int) {
+			filesystem.ThumbQuality = original
+		}(filesystem.ThumbQuality)
+
+		sizes := map[int]int64{}
+		for _, quality := range []int{10, 100} {
+			filesystem.ThumbQuality = quality
+
+			key := "thumbs/quality_" + strconv.Itoa(quality) + ".jpg"
+			if err := fs.CreateThumb("image.png", key, "50x50"); err != nil {
+				t.Fatal(err)
+			}
+
+			attrs, err := fs.Attributes(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sizes[quality] = attrs.Size
+		}
+
+		if sizes[10] >= sizes[100] {
+			t.Fatalf("Expected the low quality thumb to be smaller, got %v", sizes)
+		}
+	})
+
+	t.Run("failing encoder", func(t *testing.T) {
+		filesystem.ThumbEncoders["fail"] = &filesystem.ThumbEncoder{
+			ContentType: "image/fail",
+			Encode: func(w io.Writer, img image.Image, q int) error {
+				return errors.New("test")
+			},
+		}
+		defer delete(filesystem.ThumbEncoders, "fail")
+
+		if err := fs.CreateThumb("image.png", "thumbs/image.fail", "10x10"); err == nil {
+			t.Fatal("Expected encode error")
+		}
+
+		if exists, _ := fs.Exists("thumbs/image.fail"); exists {
+			t.Fatal("Expected the failed thumb to not be saved")
+		}
+	})
+}
+
+func TestFileSystemSignedURL(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	// the local filesystem doesn't support signing
+	if _, err := fs.SignedURL("image.png", time.Minute); err == nil {
+		t.Fatal("Expected signed url error, got nil")
+	}
+}
+
+func TestFileSystemPresignUpload(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	local, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	// the local filesystem doesn't support signing
+	if _, err := local.PresignUpload("image.png", nil); err == nil {
+		t.Fatal("Expected presign error, got nil")
+	}
+
+	// the url is signed offline so the endpoint doesn't need to exist
+	s3, err := filesystem.NewS3("test", "test", "https://s3.example.com", "test", "test", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s3.Close()
+
+	scenarios := []struct {
+		name           string
+		opts           *filesystem.PresignUploadOptions
+		expectedExpiry string
+	}{
+		{"nil options", nil, "X-Amz-Expires=900"},
+		{"zero expiry", &filesystem.PresignUploadOptions{}, "X-Amz-Expires=900"},
+		{"custom expiry", &filesystem.PresignUploadOptions{Expiry: time.Minute}, "X-Amz-Expires=60"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			url, err := s3.PresignUpload("a/image.png", s.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedParts := []string{
+				"https://s3.example.com/test/a/image.png?",
+				"X-Amz-Signature=",
+				s.expectedExpiry,
+			}
+			for _, part := range expectedParts {
+				if !strings.Contains(url, part) {
+					t.Fatalf("Expected %q in %q", part, url)
+				}
+			}
+		})
+	}
+}
+
+// TestFileSystemAzureBlob is an integration test against a real Azure Blob Storage container.
+//
+// It runs only if the PB_TEST_AZURE_ACCOUNT, PB_TEST_AZURE_KEY
+// and PB_TEST_AZURE_CONTAINER env variables are set.
+func TestFileSystemAzureBlob(t *testing.T) {
+	account := os.Getenv("PB_TEST_AZURE_ACCOUNT")
+	key := os.Getenv("PB_TEST_AZURE_KEY")
+	container := os.Getenv("PB_TEST_AZURE_CONTAINER")
+	if account == "" || key == "" || container == "" {
+		t.Skip("Missing PB_TEST_AZURE_* env variables")
+	}
+
+	prefix := "pb_test_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	fs, err := filesystem.NewAzureBlob(account, key, container, prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	defer fs.DeletePrefix("a/")
+
+	// upload
+	if err := fs.Upload([]byte("test"), "a/test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Upload([]byte("test2"), "a/test2.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// get
+	br, err := fs.GetFile("a/test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(br)
+	br.Close()
+	if string(content) != "test" {
+		t.Fatalf("Expected content %q, got %q", "test", content)
+	}
+
+	// list
+	files, err := fs.List("a/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+
+	// serve
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	if err := fs.Serve(res, req, "a/test.txt", "test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if res.Body.String() != "test" {
+		t.Fatalf("Expected served content %q, got %q", "test", res.Body.String())
+	}
+
+	// signed url (SAS)
+	signedURL, err := fs.SignedURL("a/test.txt", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(signedURL, "/"+prefix+"/a/test.txt?") || !strings.Contains(signedURL, "sig=") {
+		t.Fatalf("Expected SAS signed url, got %q", signedURL)
+	}
+	signedRes, err := http.Get(signedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedContent, _ := io.ReadAll(signedRes.Body)
+	signedRes.Body.Close()
+	if string(signedContent) != "test" {
+		t.Fatalf("Expected signed url content %q, got %q", "test", signedContent)
+	}
+
+	// delete
+	if err := fs.Delete("a/test.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := fs.Exists("a/test.txt"); exists {
+		t.Fatal("Expected a/test.txt to be deleted")
+	}
+}
+
 // ---
 
 func createTestDir(t *testing.T) string {
@@ -0,0 +1,905 @@
+// Package graphql implements a minimal parser for the executable
+// subset of the GraphQL query language.
+//
+// Only operations with fields, aliases, arguments, variables and
+// nested selection sets are supported. Fragments and directives
+// are not supported and result in a parse error.
+//
+// Example
+//
+//	doc, _ := graphql.Parse(`query Posts($limit: Int) {
+//		posts(perPage: $limit) { items { id title } }
+//	}`)
+//	op, _ := doc.Operation("")
+//	args, _ := op.Fields[0].ArgumentValues(map[string]any{"limit": 10}) // {"perPage": 10}
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength is the max allowed length of a single query document.
+const MaxLength = 50000
+
+// MaxDepth is the max allowed nesting depth of the selection sets and values.
+const MaxDepth = 30
+
+// Document defines a single parsed query document.
+type Document struct {
+	Operations []*Operation
+}
+
+// Operation returns the document operation with the provided name.
+//
+// The name could be empty only if the document contains a single operation.
+func (d *Document) Operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, errors.New("operationName is required when the document contains multiple operations")
+		}
+		return d.Operations[0], nil
+	}
+
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// Operation defines a single document operation (eg. "query { ... }").
+type Operation struct {
+	// Type is the operation type - "query", "mutation" or "subscription".
+	Type      string
+	Name      string
+	Variables []*VariableDefinition
+	Fields    []*Field
+}
+
+// VariableValues returns the operation variables from the provided
+// input values, applying the variable definitions defaults.
+//
+// Returns an error if a non-null variable value is missing.
+func (op *Operation) VariableValues(input map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(op.Variables))
+
+	for _, def := range op.Variables {
+		if v, ok := input[def.Name]; ok && v != nil {
+			result[def.Name] = v
+			continue
+		}
+
+		if def.Default != nil {
+			v, err := ResolveValue(def.Default, nil)
+			if err != nil {
+				return nil, err
+			}
+			result[def.Name] = v
+			continue
+		}
+
+		if strings.HasSuffix(def.Type, "!") {
+			return nil, fmt.Errorf("missing value for the non-null variable $%s", def.Name)
+		}
+
+		result[def.Name] = nil
+	}
+
+	return result, nil
+}
+
+// VariableDefinition defines a single operation variable (eg. "$limit: Int = 10").
+type VariableDefinition struct {
+	Name string
+
+	// Type is the normalized string representation of the variable type (eg. "[String!]!").
+	Type string
+
+	// Default is the variable default value (nil if not set).
+	Default Value
+}
+
+// Field defines a single selection set field.
+type Field struct {
+	Alias     string
+	Name      string
+	Arguments []*Argument
+	Fields    []*Field
+}
+
+// ResponseKey returns the field alias (if set) or its name.
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+// ArgumentValues resolves the field arguments with the provided variables.
+func (f *Field) ArgumentValues(variables map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(f.Arguments))
+
+	for _, arg := range f.Arguments {
+		v, err := ResolveValue(arg.Value, variables)
+		if err != nil {
+			return nil, err
+		}
+		result[arg.Name] = v
+	}
+
+	return result, nil
+}
+
+// Argument defines a single field argument.
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+// Value is one of the following literal types:
+// nil, bool, int64, float64, string, [EnumValue], [Variable],
+// []Value or map[string]Value.
+type Value any
+
+// EnumValue defines an enum literal value (eg. "ASC").
+type EnumValue string
+
+// Variable defines a variable reference value (eg. "$limit").
+type Variable string
+
+// ResolveValue converts the provided literal value into its plain Go
+// representation, replacing the variable references with their
+// corresponding variables value.
+//
+// Enum values are resolved as strings.
+func ResolveValue(v Value, variables map[string]any) (any, error) {
+	switch val := v.(type) {
+	case Variable:
+		resolved, ok := variables[string(val)]
+		if !ok {
+			return nil, fmt.Errorf("undefined variable $%s", val)
+		}
+		return resolved, nil
+	case EnumValue:
+		return string(val), nil
+	case []Value:
+		result := make([]any, len(val))
+		for i, item := range val {
+			r, err := ResolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = r
+		}
+		return result, nil
+	case map[string]Value:
+		result := make(map[string]any, len(val))
+		for k, item := range val {
+			r, err := ResolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = r
+		}
+		return result, nil
+	default:
+		return val, nil
+	}
+}
+
+// Parse parses the provided GraphQL query document.
+func Parse(query string) (*Document, error) {
+	if len(query) > MaxLength {
+		return nil, fmt.Errorf("the query document must be less than %d characters", MaxLength)
+	}
+
+	p := &parser{lexer: lexer{input: query}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+
+	for p.token.kind != tokenEOF {
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.Operations = append(doc.Operations, op)
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, errors.New("the query document must contain at least one operation")
+	}
+
+	return doc, nil
+}
+
+// -------------------------------------------------------------------
+
+type parser struct {
+	lexer lexer
+	token token
+	depth int
+}
+
+func (p *parser) next() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+
+	p.token = t
+
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("syntax error at position %d: %s", p.token.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) is(kind tokenKind, value string) bool {
+	return p.token.kind == kind && p.token.value == value
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if !p.is(kind, value) {
+		return p.errorf("expected %q, got %q", value, p.token.value)
+	}
+
+	return p.next()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.errorf("expected name, got %q", p.token.value)
+	}
+
+	name := p.token.value
+
+	return name, p.next()
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > MaxDepth {
+		return p.errorf("max nesting depth of %d reached", MaxDepth)
+	}
+
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) checkUnsupported() error {
+	if p.is(tokenPunct, "@") {
+		return p.errorf("directives are not supported")
+	}
+
+	if p.is(tokenPunct, "...") {
+		return p.errorf("fragments are not supported")
+	}
+
+	return nil
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	if p.is(tokenName, "fragment") {
+		return nil, p.errorf("fragments are not supported")
+	}
+
+	op := &Operation{Type: "query"}
+
+	// shorthand query
+	if p.is(tokenPunct, "{") {
+		fields, err := p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+		op.Fields = fields
+		return op, nil
+	}
+
+	switch {
+	case p.is(tokenName, "query"), p.is(tokenName, "mutation"), p.is(tokenName, "subscription"):
+		op.Type = p.token.value
+	default:
+		return nil, p.errorf("expected operation, got %q", p.token.value)
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if p.token.kind == tokenName {
+		op.Name = p.token.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.is(tokenPunct, "(") {
+		vars, err := p.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.Variables = vars
+	}
+
+	if err := p.checkUnsupported(); err != nil {
+		return nil, err
+	}
+
+	fields, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Fields = fields
+
+	return op, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect(tokenPunct, "("); err != nil {
+		return nil, err
+	}
+
+	result := []*VariableDefinition{}
+
+	for !p.is(tokenPunct, ")") {
+		if err := p.expect(tokenPunct, "$"); err != nil {
+			return nil, err
+		}
+
+		def := &VariableDefinition{}
+
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		def.Name = name
+
+		if err := p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+
+		def.Type, err = p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.is(tokenPunct, "=") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			def.Default, err = p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.checkUnsupported(); err != nil {
+			return nil, err
+		}
+
+		result = append(result, def)
+	}
+
+	if len(result) == 0 {
+		return nil, p.errorf("expected at least one variable definition")
+	}
+
+	return result, p.next()
+}
+
+func (p *parser) parseType() (string, error) {
+	if err := p.enter(); err != nil {
+		return "", err
+	}
+	defer p.leave()
+
+	var result string
+
+	if p.is(tokenPunct, "[") {
+		if err := p.next(); err != nil {
+			return "", err
+		}
+
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+
+		if err := p.expect(tokenPunct, "]"); err != nil {
+			return "", err
+		}
+
+		result = "[" + inner + "]"
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", err
+		}
+		result = name
+	}
+
+	if p.is(tokenPunct, "!") {
+		result += "!"
+		return result, p.next()
+	}
+
+	return result, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Field, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	if err := p.expect(tokenPunct, "{"); err != nil {
+		return nil, err
+	}
+
+	fields := []*Field{}
+
+	for !p.is(tokenPunct, "}") {
+		if err := p.checkUnsupported(); err != nil {
+			return nil, err
+		}
+
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, p.errorf("expected at least one field in the selection set")
+	}
+
+	return fields, p.next()
+}
+
+func (p *parser) parseField() (*Field, error) {
+	field := &Field{}
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name
+
+	if p.is(tokenPunct, ":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+
+		field.Alias = field.Name
+		field.Name = name
+	}
+
+	if p.is(tokenPunct, "(") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		for !p.is(tokenPunct, ")") {
+			argName, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(tokenPunct, ":"); err != nil {
+				return nil, err
+			}
+
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+
+			field.Arguments = append(field.Arguments, &Argument{Name: argName, Value: value})
+		}
+
+		if len(field.Arguments) == 0 {
+			return nil, p.errorf("expected at least one argument")
+		}
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.checkUnsupported(); err != nil {
+		return nil, err
+	}
+
+	if p.is(tokenPunct, "{") {
+		field.Fields, err = p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseValue(isConst bool) (Value, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	t := p.token
+
+	switch t.kind {
+	case tokenInt:
+		v, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid int value %q", t.value)
+		}
+		return v, p.next()
+	case tokenFloat:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, p.errorf("invalid float value %q", t.value)
+		}
+		return v, p.next()
+	case tokenString:
+		return t.value, p.next()
+	case tokenName:
+		var v Value
+		switch t.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = EnumValue(t.value)
+		}
+		return v, p.next()
+	}
+
+	switch {
+	case p.is(tokenPunct, "$"):
+		if isConst {
+			return nil, p.errorf("variables are not allowed in constant values")
+		}
+
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+
+		return Variable(name), nil
+	case p.is(tokenPunct, "["):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		list := []Value{}
+		for !p.is(tokenPunct, "]") {
+			item, err := p.parseValue(isConst)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+
+		return list, p.next()
+	case p.is(tokenPunct, "{"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		obj := map[string]Value{}
+		for !p.is(tokenPunct, "}") {
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(tokenPunct, ":"); err != nil {
+				return nil, err
+			}
+
+			obj[name], err = p.parseValue(isConst)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return obj, p.next()
+	}
+
+	return nil, p.errorf("unexpected %q", t.value)
+}
+
+// -------------------------------------------------------------------
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("syntax error at position %d: %s", l.pos, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	ch := l.input[l.pos]
+
+	switch {
+	case strings.HasPrefix(l.input[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenPunct, value: "...", pos: start}, nil
+	case strings.IndexByte("!$()[]{}:=@|&", ch) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(ch), pos: start}, nil
+	case isNameStart(ch):
+		for l.pos < len(l.input) && isNameContinue(l.input[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.input[start:l.pos], pos: start}, nil
+	case ch == '-' || isDigit(ch):
+		return l.readNumber()
+	case ch == '"':
+		if strings.HasPrefix(l.input[l.pos:], `"""`) {
+			return l.readBlockString()
+		}
+		return l.readString()
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+
+	return token{}, l.errorf("unexpected character %q", r)
+}
+
+// skipIgnored skips the whitespaces, commas, comments and unicode BOM.
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.input) {
+		switch ch := l.input[l.pos]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == ',':
+			l.pos++
+		case ch == '#':
+			for l.pos < len(l.input) && l.input[l.pos] != '\n' && l.input[l.pos] != '\r' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.input[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) readNumber() (token, error) {
+	start := l.pos
+	kind := tokenInt
+
+	if l.input[l.pos] == '-' {
+		l.pos++
+	}
+
+	if !l.readDigits() {
+		return token{}, l.errorf("invalid number")
+	}
+
+	if l.pos < len(l.input) && l.input[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if !l.readDigits() {
+			return token{}, l.errorf("invalid number")
+		}
+	}
+
+	if l.pos < len(l.input) && (l.input[l.pos] == 'e' || l.input[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.input) && (l.input[l.pos] == '+' || l.input[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.readDigits() {
+			return token{}, l.errorf("invalid number")
+		}
+	}
+
+	if l.pos < len(l.input) && (isNameStart(l.input[l.pos]) || l.input[l.pos] == '.') {
+		return token{}, l.errorf("invalid number")
+	}
+
+	return token{kind: kind, value: l.input[start:l.pos], pos: start}, nil
+}
+
+func (l *lexer) readDigits() bool {
+	start := l.pos
+
+	for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+		l.pos++
+	}
+
+	return l.pos > start
+}
+
+func (l *lexer) readString() (token, error) {
+	start := l.pos
+	l.pos++ // opening quote
+
+	var sb strings.Builder
+
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+
+		switch ch {
+		case '"':
+			l.pos++
+			return token{kind: tokenString, value: sb.String(), pos: start}, nil
+		case '\n', '\r':
+			return token{}, l.errorf("unterminated string")
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.input) {
+				return token{}, l.errorf("unterminated string")
+			}
+
+			switch esc := l.input[l.pos]; esc {
+			case '"', '\\', '/':
+				sb.WriteByte(esc)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if l.pos+5 > len(l.input) {
+					return token{}, l.errorf("invalid unicode escape sequence")
+				}
+				code, err := strconv.ParseUint(l.input[l.pos+1:l.pos+5], 16, 32)
+				if err != nil {
+					return token{}, l.errorf("invalid unicode escape sequence")
+				}
+				sb.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				return token{}, l.errorf("invalid escape sequence \\%c", esc)
+			}
+
+			l.pos++
+		default:
+			sb.WriteByte(ch)
+			l.pos++
+		}
+	}
+
+	return token{}, l.errorf("unterminated string")
+}
+
+// readBlockString reads a """ block string.
+//
+// The common indentation and the leading and trailing blank lines are removed.
+func (l *lexer) readBlockString() (token, error) {
+	start := l.pos
+	l.pos += 3
+
+	var sb strings.Builder
+
+	for l.pos < len(l.input) {
+		switch {
+		case strings.HasPrefix(l.input[l.pos:], `\"""`):
+			sb.WriteString(`"""`)
+			l.pos += 4
+		case strings.HasPrefix(l.input[l.pos:], `"""`):
+			l.pos += 3
+			return token{kind: tokenString, value: dedentBlockString(sb.String()), pos: start}, nil
+		default:
+			sb.WriteByte(l.input[l.pos])
+			l.pos++
+		}
+	}
+
+	return token{}, l.errorf("unterminated block string")
+}
+
+func dedentBlockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for i, line := range lines {
+		if i == 0 {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isNameContinue(ch byte) bool {
+	return isNameStart(ch) || isDigit(ch)
+}
@@ -0,0 +1,186 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/graphql"
+)
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []string{
+		"",
+		"   # comment only",
+		"{}",
+		"{ a",
+		"{ a( ) }",
+		"{ a(b) }",
+		"{ a(b: ) }",
+		`{ a(b: "c) }`,
+		"{ a(b: 01x) }",
+		"{ a(b: 1.) }",
+		"{ a @include(if: true) }",
+		"{ ...frag }",
+		"{ ... on A { b } }",
+		"fragment A on B { c }",
+		"query ($a) { b }",
+		"query ($a: Int = $b) { b }",
+		"unknown { a }",
+		"{ a } }",
+		"{ a: }",
+		fmt.Sprintf("{ a(b: \"%s\") }", strings.Repeat("x", graphql.MaxLength)),
+		strings.Repeat("{ a ", graphql.MaxDepth+1) + strings.Repeat("}", graphql.MaxDepth+1),
+	}
+
+	for _, s := range scenarios {
+		name := s
+		if len(name) > 50 {
+			name = name[:50]
+		}
+
+		t.Run(name, func(t *testing.T) {
+			if _, err := graphql.Parse(s); err == nil {
+				t.Fatalf("Expected parse error for %q", s)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	doc, err := graphql.Parse(`
+		# list posts
+		query Posts($filter: String!, $page: Int = 2, $tags: [String!]) {
+			list: posts(filter: $filter, page: $page, sort: "-created", perPage: 10 active: true, score: -1.5e2, dir: ASC, nothing: null) {
+				totalItems
+				items {
+					id, __typename
+					author { name }
+				}
+			}
+			other: posts(obj: { a: [1, "b", $tags], b: """
+				multi
+				  line
+			""" }) { page }
+		}
+
+		query Other { users { items { id } } }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Operations) != 2 {
+		t.Fatalf("Expected 2 operations, got %d", len(doc.Operations))
+	}
+
+	if _, err := doc.Operation(""); err == nil {
+		t.Fatal("Expected error for the missing operation name")
+	}
+
+	if _, err := doc.Operation("missing"); err == nil {
+		t.Fatal("Expected error for unknown operation name")
+	}
+
+	op, err := doc.Operation("Posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if op.Type != "query" || op.Name != "Posts" {
+		t.Fatalf("Expected query Posts operation, got %s %s", op.Type, op.Name)
+	}
+
+	var varTypes []string
+	for _, v := range op.Variables {
+		varTypes = append(varTypes, v.Name+":"+v.Type)
+	}
+	if str := strings.Join(varTypes, ","); str != "filter:String!,page:Int,tags:[String!]" {
+		t.Fatalf("Unexpected variable definitions %s", str)
+	}
+
+	if _, err := op.VariableValues(nil); err == nil {
+		t.Fatal("Expected error for the missing non-null variable")
+	}
+
+	vars, err := op.VariableValues(map[string]any{"filter": "a = 1", "tags": []any{"c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(op.Fields) != 2 {
+		t.Fatalf("Expected 2 root fields, got %d", len(op.Fields))
+	}
+
+	list := op.Fields[0]
+	if list.Name != "posts" || list.ResponseKey() != "list" {
+		t.Fatalf("Expected posts field with list alias, got %s (%s)", list.Name, list.ResponseKey())
+	}
+
+	args, err := list.ArgumentValues(vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rawArgs, _ := json.Marshal(args)
+	expectedArgs := `{"active":true,"dir":"ASC","filter":"a = 1","nothing":null,"page":2,"perPage":10,"score":-150,"sort":"-created"}`
+	if string(rawArgs) != expectedArgs {
+		t.Fatalf("Expected args\n%s\ngot\n%s", expectedArgs, rawArgs)
+	}
+
+	items := list.Fields[1]
+	if items.Name != "items" || len(items.Fields) != 3 || items.Fields[1].Name != "__typename" {
+		t.Fatalf("Unexpected items selection set %v", items.Fields)
+	}
+
+	if author := items.Fields[2]; author.Name != "author" || len(author.Fields) != 1 || author.Fields[0].Name != "name" {
+		t.Fatalf("Unexpected author selection set %v", author)
+	}
+
+	otherArgs, err := op.Fields[1].ArgumentValues(vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rawOtherArgs, _ := json.Marshal(otherArgs)
+	expectedOtherArgs := `{"obj":{"a":[1,"b",["c"]],"b":"multi\n  line"}}`
+	if string(rawOtherArgs) != expectedOtherArgs {
+		t.Fatalf("Expected args\n%s\ngot\n%s", expectedOtherArgs, rawOtherArgs)
+	}
+
+	if _, err := op.Fields[1].ArgumentValues(nil); err == nil {
+		t.Fatal("Expected error for undefined variable")
+	}
+}
+
+func TestParseShorthandQuery(t *testing.T) {
+	t.Parallel()
+
+	doc, err := graphql.Parse(`{ a(b: "\"q\" é\n") { c } d }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op, err := doc.Operation("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if op.Type != "query" || op.Name != "" || len(op.Fields) != 2 {
+		t.Fatalf("Unexpected shorthand operation %v", op)
+	}
+
+	args, err := op.Fields[0].ArgumentValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if args["b"] != "\"q\" é\n" {
+		t.Fatalf("Unexpected string argument %q", args["b"])
+	}
+}
@@ -19,11 +19,26 @@ type handlerPair[T any] struct {
 	handler Handler[T]
 }
 
+// TriggerTracer defines a function that is called before executing
+// the hook handlers and that returns a callback to be invoked with the
+// Trigger result (eg. to start and end a tracing span).
+type TriggerTracer func(data any) (done func(err error))
+
 // Hook defines a concurrent safe structure for handling event hooks
 // (aka. callbacks propagation).
 type Hook[T any] struct {
 	mux      sync.RWMutex
 	handlers []*handlerPair[T]
+	tracer   TriggerTracer
+}
+
+// SetTracer registers an optional tracer function that will be
+// called on every hook Trigger (pass nil to remove it).
+func (h *Hook[T]) SetTracer(tracer TriggerTracer) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.tracer = tracer
 }
 
 // PreAdd registers a new handler to the hook by prepending it to the existing queue.
@@ -57,6 +72,14 @@ func (h *Hook[T]) Add(fn Handler[T]) string {
 	return id
 }
 
+// Length returns the total number of the registered hook handlers.
+func (h *Hook[T]) Length() int {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	return len(h.handlers)
+}
+
 // Remove removes a single hook handler by its id.
 func (h *Hook[T]) Remove(id string) {
 	h.mux.Lock()
@@ -101,10 +124,23 @@ func (h *Hook[T]) Trigger(data T, oneOffHandlers ...Handler[T]) error {
 		})
 	}
 
+	tracer := h.tracer
+
 	// unlock is not deferred to avoid deadlocks in case Trigger
 	// is called recursively by the handlers
 	h.mux.RUnlock()
 
+	if tracer != nil {
+		done := tracer(data)
+		err := runHandlers(data, handlers)
+		done(err)
+		return err
+	}
+
+	return runHandlers(data, handlers)
+}
+
+func runHandlers[T any](data T, handlers []*handlerPair[T]) error {
 	for _, item := range handlers {
 		err := item.handler(data)
 		if err == nil {
@@ -89,6 +89,27 @@ func TestHookRemoveAll(t *testing.T) {
 	}
 }
 
+func TestHookLength(t *testing.T) {
+	h := Hook[int]{}
+
+	if total := h.Length(); total != 0 {
+		t.Fatalf("Expected 0 handlers, found %d", total)
+	}
+
+	id := h.Add(func(data int) error { return nil })
+	h.PreAdd(func(data int) error { return nil })
+
+	if total := h.Length(); total != 2 {
+		t.Fatalf("Expected 2 handlers, found %d", total)
+	}
+
+	h.Remove(id)
+
+	if total := h.Length(); total != 1 {
+		t.Fatalf("Expected 1 handler after Remove, found %d", total)
+	}
+}
+
 func TestHookTrigger(t *testing.T) {
 	err1 := errors.New("demo")
 	err2 := errors.New("demo")
@@ -177,3 +198,56 @@ func TestHookTriggerStopPropagation(t *testing.T) {
 		}
 	}
 }
+
+func TestHookSetTracer(t *testing.T) {
+	demoErr := errors.New("demo")
+
+	scenarios := []struct {
+		handler       Handler[int]
+		expectedError error
+	}{
+		{func(data int) error { return nil }, nil},
+		{func(data int) error { return StopPropagation }, nil},
+		{func(data int) error { return demoErr }, demoErr},
+	}
+
+	for i, s := range scenarios {
+		var tracedData any
+		var tracedErr error
+		var doneCalls int
+
+		h := Hook[int]{}
+		h.Add(s.handler)
+		h.SetTracer(func(data any) func(err error) {
+			tracedData = data
+			return func(err error) {
+				doneCalls++
+				tracedErr = err
+			}
+		})
+
+		result := h.Trigger(123)
+		if result != s.expectedError {
+			t.Fatalf("[%d] Expected error %v, got %v", i, s.expectedError, result)
+		}
+
+		if tracedData != 123 {
+			t.Fatalf("[%d] Expected traced data 123, got %v", i, tracedData)
+		}
+
+		if doneCalls != 1 {
+			t.Fatalf("[%d] Expected the done callback to be called once, got %d", i, doneCalls)
+		}
+
+		if tracedErr != s.expectedError {
+			t.Fatalf("[%d] Expected traced error %v, got %v", i, s.expectedError, tracedErr)
+		}
+
+		// remove the tracer
+		h.SetTracer(nil)
+		h.Trigger(456)
+		if doneCalls != 1 || tracedData != 123 {
+			t.Fatalf("[%d] Expected the tracer to be removed", i)
+		}
+	}
+}
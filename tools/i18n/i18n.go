@@ -0,0 +1,211 @@
+// Package i18n implements a minimal concurrent safe registry
+// of language message catalogs keyed by error code.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLang is the fallback language used when
+// the requested language (or catalog key) is missing.
+const DefaultLang = "en"
+
+// Catalog defines a single language messages catalog keyed
+// by message (usually validation error) code, eg.:
+//
+//	i18n.Catalog{
+//		"validation_required":        "Задължително поле.",
+//		"validation_length_too_long": "Максималната дължина е {{.max}}.",
+//	}
+//
+// The messages could contain text/template placeholders
+// that are replaced with the related error params.
+type Catalog map[string]string
+
+// Catalogs is a concurrent safe registry of language catalogs.
+type Catalogs struct {
+	mux      sync.RWMutex
+	catalogs map[string]Catalog
+}
+
+// NewCatalogs creates a new empty Catalogs registry.
+func NewCatalogs() *Catalogs {
+	return &Catalogs{catalogs: map[string]Catalog{}}
+}
+
+// Register registers the provided catalog messages for the specified language.
+//
+// If the language already has a registered catalog, the new messages
+// are merged with it (overwriting the existing ones with the same code).
+func (c *Catalogs) Register(lang string, catalog Catalog) {
+	lang = normalizeLang(lang)
+	if lang == "" {
+		return
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	existing, ok := c.catalogs[lang]
+	if !ok {
+		existing = make(Catalog, len(catalog))
+		c.catalogs[lang] = existing
+	}
+
+	for code, msg := range catalog {
+		existing[code] = msg
+	}
+}
+
+// Unregister removes the registered catalog of the specified language (if any).
+func (c *Catalogs) Unregister(lang string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.catalogs, normalizeLang(lang))
+}
+
+// Has checks whether a catalog for the specified language is registered.
+func (c *Catalogs) Has(lang string) bool {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	_, ok := c.catalogs[normalizeLang(lang)]
+
+	return ok
+}
+
+// Langs returns a sorted list with the registered catalogs languages.
+func (c *Catalogs) Langs() []string {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	result := make([]string, 0, len(c.catalogs))
+	for lang := range c.catalogs {
+		result = append(result, lang)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Message returns the catalog message of the specified language and code.
+//
+// It fallbacks to the DefaultLang catalog if the language or
+// the message code is missing and returns false if there is
+// no registered message at all (aka. the original message should be used).
+func (c *Catalogs) Message(lang string, code string) (string, bool) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	for _, l := range []string{normalizeLang(lang), DefaultLang} {
+		if msg, ok := c.catalogs[l][code]; ok && msg != "" {
+			return msg, true
+		}
+	}
+
+	return "", false
+}
+
+// ResolveLang returns the first language from the provided
+// candidates that has a registered catalog.
+//
+// Region specific candidates (eg. "bg-BG") fallback to their less specific language (eg. "bg").
+//
+// Returns DefaultLang if none of the candidates is registered.
+func (c *Catalogs) ResolveLang(candidates ...string) string {
+	for _, candidate := range candidates {
+		lang := normalizeLang(candidate)
+
+		for lang != "" {
+			if c.Has(lang) {
+				return lang
+			}
+
+			// strip the last subtag (eg. "zh-hant-tw" -> "zh-hant")
+			i := strings.LastIndex(lang, "-")
+			if i == -1 {
+				break
+			}
+			lang = lang[:i]
+		}
+	}
+
+	return DefaultLang
+}
+
+// -------------------------------------------------------------------
+
+// defaultCatalogs is the global catalogs registry used by the package level helpers.
+var defaultCatalogs = NewCatalogs()
+
+// Default returns the global catalogs registry.
+func Default() *Catalogs {
+	return defaultCatalogs
+}
+
+// Register registers (or merges) the provided catalog
+// for the specified language in the global registry.
+func Register(lang string, catalog Catalog) {
+	defaultCatalogs.Register(lang, catalog)
+}
+
+// -------------------------------------------------------------------
+
+// ParseAcceptLanguage parses the provided Accept-Language header value
+// and returns its languages sorted by their quality value (highest first).
+//
+// Example:
+//
+//	ParseAcceptLanguage("en;q=0.5, bg-BG, bg;q=0.8") // [bg-bg bg en]
+func ParseAcceptLanguage(header string) []string {
+	type langQuality struct {
+		lang    string
+		quality float64
+	}
+
+	items := []langQuality{}
+
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(part, ";")
+
+		lang = normalizeLang(lang)
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				quality = q
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		items = append(items, langQuality{lang, quality})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].quality > items[j].quality
+	})
+
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = item.lang
+	}
+
+	return result
+}
+
+// normalizeLang converts the provided language tag into
+// its lowercase "-" separated form (eg. "bg_BG" -> "bg-bg").
+func normalizeLang(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
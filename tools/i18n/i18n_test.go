@@ -0,0 +1,107 @@
+package i18n_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/i18n"
+)
+
+func TestCatalogsRegister(t *testing.T) {
+	c := i18n.NewCatalogs()
+
+	c.Register("", i18n.Catalog{"a": "ignored"})
+	c.Register("BG_bg", i18n.Catalog{"a": "a1", "b": "b1"})
+	c.Register("bg-BG", i18n.Catalog{"b": "b2", "c": "c2"})
+	c.Register("de", i18n.Catalog{"a": "a3"})
+
+	if langs := strings.Join(c.Langs(), ","); langs != "bg-bg,de" {
+		t.Fatalf("Expected langs bg-bg,de, got %s", langs)
+	}
+
+	expected := map[string]string{"a": "a1", "b": "b2", "c": "c2"}
+	for code, msg := range expected {
+		if result, _ := c.Message("bg-bg", code); result != msg {
+			t.Fatalf("Expected %q message %q, got %q", code, msg, result)
+		}
+	}
+
+	c.Unregister("DE")
+
+	if c.Has("de") {
+		t.Fatal("Expected de catalog to be unregistered")
+	}
+}
+
+func TestCatalogsMessage(t *testing.T) {
+	c := i18n.NewCatalogs()
+	c.Register("bg", i18n.Catalog{"a": "bg_a", "empty": ""})
+	c.Register(i18n.DefaultLang, i18n.Catalog{"a": "en_a", "b": "en_b"})
+
+	scenarios := []struct {
+		lang        string
+		code        string
+		expectedMsg string
+		expectedOk  bool
+	}{
+		{"bg", "a", "bg_a", true},
+		{"BG", "a", "bg_a", true},
+		{"bg", "b", "en_b", true},
+		{"bg", "empty", "", false},
+		{"bg", "missing", "", false},
+		{"missing", "a", "en_a", true},
+		{"", "b", "en_b", true},
+	}
+
+	for i, s := range scenarios {
+		msg, ok := c.Message(s.lang, s.code)
+
+		if msg != s.expectedMsg || ok != s.expectedOk {
+			t.Errorf("[%d] Expected (%q, %v), got (%q, %v)", i, s.expectedMsg, s.expectedOk, msg, ok)
+		}
+	}
+}
+
+func TestCatalogsResolveLang(t *testing.T) {
+	c := i18n.NewCatalogs()
+	c.Register("bg", i18n.Catalog{})
+	c.Register("zh-hant", i18n.Catalog{})
+
+	scenarios := []struct {
+		candidates []string
+		expected   string
+	}{
+		{nil, i18n.DefaultLang},
+		{[]string{"missing", "de-DE"}, i18n.DefaultLang},
+		{[]string{"BG"}, "bg"},
+		{[]string{"bg-BG"}, "bg"},
+		{[]string{"missing", "zh-Hant-TW", "bg"}, "zh-hant"},
+		{[]string{"zh-TW", "bg"}, "bg"},
+	}
+
+	for i, s := range scenarios {
+		if result := c.ResolveLang(s.candidates...); result != s.expected {
+			t.Errorf("[%d] Expected %q, got %q", i, s.expected, result)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	scenarios := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"*", ""},
+		{"bg", "bg"},
+		{"en;q=0.5, bg-BG, bg;q=0.8", "bg-bg,bg,en"},
+		{"de;q=0, fr;q=invalid, en;q=0.9", "fr,en"},
+		{"en-US,en;q=0.9,*;q=0.1", "en-us,en"},
+	}
+
+	for i, s := range scenarios {
+		if result := strings.Join(i18n.ParseAcceptLanguage(s.header), ","); result != s.expected {
+			t.Errorf("[%d] Expected %q, got %q", i, s.expected, result)
+		}
+	}
+}
@@ -86,6 +86,25 @@ func ToInterfaceSlice[T any](list []T) []any {
 	return result
 }
 
+// ToChunks splits list into chunks with max chunkSize items each
+// (the last chunk may have less items).
+//
+// Returns an empty slice if list is empty or chunkSize is <= 0.
+func ToChunks[T any](list []T, chunkSize int) [][]T {
+	if chunkSize <= 0 || len(list) == 0 {
+		return [][]T{}
+	}
+
+	result := make([][]T, 0, (len(list)+chunkSize-1)/chunkSize)
+
+	for chunkSize < len(list) {
+		result = append(result, list[:chunkSize:chunkSize])
+		list = list[chunkSize:]
+	}
+
+	return append(result, list)
+}
+
 // NonzeroUniques returns only the nonzero unique values from a slice.
 func NonzeroUniques[T comparable](list []T) []T {
 	result := make([]T, 0, len(list))
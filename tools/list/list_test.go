@@ -214,6 +214,33 @@ func TestToInterfaceSlice(t *testing.T) {
 	}
 }
 
+func TestToChunks(t *testing.T) {
+	scenarios := []struct {
+		items     []int
+		chunkSize int
+		expected  string
+	}{
+		{nil, 2, `[]`},
+		{[]int{1, 2, 3}, 0, `[]`},
+		{[]int{1, 2, 3}, -1, `[]`},
+		{[]int{1, 2, 3}, 1, `[[1],[2],[3]]`},
+		{[]int{1, 2, 3}, 2, `[[1,2],[3]]`},
+		{[]int{1, 2, 3, 4}, 2, `[[1,2],[3,4]]`},
+		{[]int{1, 2, 3}, 5, `[[1,2,3]]`},
+	}
+
+	for i, s := range scenarios {
+		raw, err := json.Marshal(list.ToChunks(s.items, s.chunkSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := string(raw); v != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, v)
+		}
+	}
+}
+
 func TestNonzeroUniquesString(t *testing.T) {
 	scenarios := []struct {
 		items    []string
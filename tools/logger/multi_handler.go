@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+var _ slog.Handler = (*MultiHandler)(nil)
+
+// NewMultiHandler creates a slog handler that forwards the log records
+// to all of the provided handlers (eg. the app db logs handler and a
+// custom JSON stdout one).
+//
+// Example:
+//
+//	l := slog.New(logger.NewMultiHandler(
+//	    slog.NewJSONHandler(os.Stdout, nil),
+//	    myOtherHandler,
+//	))
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+// MultiHandler is a slog handler that fan-outs the records to multiple handlers.
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// Handlers returns the list of the wrapped handlers.
+func (h *MultiHandler) Handlers() []slog.Handler {
+	return h.handlers
+}
+
+// Enabled reports whether at least one of the wrapped handlers
+// handles records at the given level.
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle forwards a copy of the record to all enabled wrapped handlers.
+//
+// Returns the joined errors of the failed handlers (if any).
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new MultiHandler with the attributes
+// applied to all of the wrapped handlers.
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return &MultiHandler{handlers: handlers}
+}
+
+// WithGroup returns a new MultiHandler with the group
+// applied to all of the wrapped handlers.
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handlers := make([]slog.Handler, len(h.handlers))
+
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return &MultiHandler{handlers: handlers}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// testHandler is a minimal slog handler that collects the handled records.
+type testHandler struct {
+	mux     *sync.Mutex
+	level   slog.Level
+	err     error
+	attrs   []slog.Attr
+	records *[]slog.Record
+}
+
+func newTestHandler(level slog.Level) *testHandler {
+	return &testHandler{mux: &sync.Mutex{}, level: level, records: &[]slog.Record{}}
+}
+
+func (h *testHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *testHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	r.AddAttrs(h.attrs...)
+	*h.records = append(*h.records, r)
+
+	return h.err
+}
+
+func (h *testHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *testHandler) WithGroup(name string) slog.Handler {
+	return h
+}
+
+func (h *testHandler) total() int {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	return len(*h.records)
+}
+
+func TestMultiHandler(t *testing.T) {
+	h1 := newTestHandler(slog.LevelInfo)
+	h2 := newTestHandler(slog.LevelError)
+
+	h := NewMultiHandler(h1, h2)
+
+	if len(h.Handlers()) != 2 {
+		t.Fatalf("Expected 2 handlers, got %d", len(h.Handlers()))
+	}
+
+	if h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Expected debug level to be disabled")
+	}
+
+	if !h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Fatal("Expected info level to be enabled")
+	}
+
+	l := slog.New(h).With("a", 123)
+	l.Info("info")
+	l.Error("error")
+
+	if h1.total() != 2 {
+		t.Fatalf("Expected h1 to handle 2 records, got %d", h1.total())
+	}
+
+	if h2.total() != 1 {
+		t.Fatalf("Expected h2 to handle 1 record, got %d", h2.total())
+	}
+
+	// the attrs should be applied to all handlers
+	for _, r := range append(*h1.records, *h2.records...) {
+		var found bool
+		r.Attrs(func(a slog.Attr) bool {
+			found = a.Key == "a" && a.Value.Int64() == 123
+			return !found
+		})
+		if !found {
+			t.Fatalf("Missing attr a in record %q", r.Message)
+		}
+	}
+}
+
+func TestMultiHandlerErrors(t *testing.T) {
+	h1 := newTestHandler(slog.LevelInfo)
+	h1.err = errors.New("h1 error")
+	h2 := newTestHandler(slog.LevelInfo)
+
+	err := NewMultiHandler(h1, h2).Handle(context.Background(), slog.Record{Level: slog.LevelInfo})
+	if err == nil || err.Error() != "h1 error" {
+		t.Fatalf("Expected h1 error, got %v", err)
+	}
+
+	// the handlers after the failed one should still be called
+	if h2.total() != 1 {
+		t.Fatalf("Expected h2 to handle 1 record, got %d", h2.total())
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+var _ slog.Handler = (*SamplingHandler)(nil)
+
+// SamplingOptions are options for the SamplingHandler.
+type SamplingOptions struct {
+	// Rate is the fraction (0-1] of the sampleable records to keep
+	// (eg. 0.1 keeps every 10th record).
+	//
+	// If not set, 0 or >= 1, all records are kept.
+	Rate float64
+
+	// Sampleable is an optional function that reports whether
+	// the record is subject to sampling (the other records are always kept).
+	//
+	// If not set, fallbacks to all records with level lower than [slog.LevelWarn].
+	Sampleable func(ctx context.Context, r slog.Record) bool
+}
+
+// NewSamplingHandler creates a slog handler that forwards to handler
+// only a fraction of the sampleable records based on the specified options.
+//
+// The sampling is deterministic, aka. with Rate 0.25 exactly every 4th
+// sampleable record is kept, while the warnings and errors are never dropped
+// (unless a custom [SamplingOptions.Sampleable] is set).
+func NewSamplingHandler(handler slog.Handler, options SamplingOptions) *SamplingHandler {
+	if options.Sampleable == nil {
+		options.Sampleable = func(ctx context.Context, r slog.Record) bool {
+			return r.Level < slog.LevelWarn
+		}
+	}
+
+	return &SamplingHandler{
+		handler: handler,
+		options: &options,
+		counter: &atomic.Uint64{},
+	}
+}
+
+// SamplingHandler is a slog handler that drops a fraction of the records.
+type SamplingHandler struct {
+	handler slog.Handler
+	options *SamplingOptions
+	counter *atomic.Uint64
+}
+
+// Handler returns the wrapped handler.
+func (h *SamplingHandler) Handler() slog.Handler {
+	return h.handler
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle forwards the record to the wrapped handler if it is not sampled out.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	rate := h.options.Rate
+
+	if rate > 0 && rate < 1 && h.options.Sampleable(ctx, r) {
+		n := h.counter.Add(1)
+
+		// keep the record only when the scaled counter reaches the next integer
+		if uint64(float64(n)*rate) == uint64(float64(n-1)*rate) {
+			return nil
+		}
+	}
+
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a new SamplingHandler sharing the same sampling state
+// and wrapping handler with the specified attributes.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{
+		handler: h.handler.WithAttrs(attrs),
+		options: h.options,
+		counter: h.counter,
+	}
+}
+
+// WithGroup returns a new SamplingHandler sharing the same sampling state
+// and wrapping handler with the specified group.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SamplingHandler{
+		handler: h.handler.WithGroup(name),
+		options: h.options,
+		counter: h.counter,
+	}
+}
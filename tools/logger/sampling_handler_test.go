@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	scenarios := []struct {
+		rate          float64
+		expectedInfo  int
+		expectedError int
+	}{
+		{0, 1000, 100},
+		{-1, 1000, 100},
+		{1, 1000, 100},
+		{2, 1000, 100},
+		{0.5, 500, 100},
+		{0.25, 250, 100},
+		{0.1, 100, 100},
+		{0.001, 1, 100},
+	}
+
+	for _, s := range scenarios {
+		base := newTestHandler(slog.LevelDebug)
+
+		l := slog.New(NewSamplingHandler(base, SamplingOptions{Rate: s.rate}))
+
+		for i := 0; i < 1000; i++ {
+			// the derived loggers must share the same sampling state
+			l.With("i", i).Info("info")
+
+			if i%10 == 0 {
+				l.Error("error")
+			}
+		}
+
+		var infos, errs int
+		for _, r := range *base.records {
+			if r.Level == slog.LevelError {
+				errs++
+			} else {
+				infos++
+			}
+		}
+
+		if infos != s.expectedInfo {
+			t.Errorf("[%v] Expected %d info records, got %d", s.rate, s.expectedInfo, infos)
+		}
+
+		// errors are never sampled out
+		if errs != s.expectedError {
+			t.Errorf("[%v] Expected %d error records, got %d", s.rate, s.expectedError, errs)
+		}
+	}
+}
+
+func TestSamplingHandlerCustomSampleable(t *testing.T) {
+	base := newTestHandler(slog.LevelDebug)
+
+	h := NewSamplingHandler(base, SamplingOptions{
+		Rate: 0.5,
+		Sampleable: func(ctx context.Context, r slog.Record) bool {
+			return r.Message == "sampled"
+		},
+	})
+
+	if h.Handler() != base {
+		t.Fatal("Expected the wrapped handler to be returned")
+	}
+
+	l := slog.New(h)
+	for i := 0; i < 10; i++ {
+		l.Info("sampled")
+		l.Info("other")
+	}
+
+	if total := base.total(); total != 15 {
+		t.Fatalf("Expected 15 records, got %d", total)
+	}
+}
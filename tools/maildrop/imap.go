@@ -0,0 +1,481 @@
+package maildrop
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLiteralSize is the max allowed size of a single IMAP literal
+// (aka. the max size of a fetched raw message).
+const maxLiteralSize = 50 << 20
+
+// DefaultTimeout is the default max duration of a single IMAP command
+// when the command context doesn't have an earlier deadline.
+const DefaultTimeout = 1 * time.Minute
+
+// ImapClient is a minimal IMAP4rev1 client that supports only the
+// commands required for polling a single mailbox.
+//
+// ImapClient is not safe for concurrent use.
+type ImapClient struct {
+	// Timeout is the max duration of a single IMAP command
+	// (default to [DefaultTimeout]).
+	Timeout time.Duration
+
+	conn         net.Conn
+	reader       *bufio.Reader
+	tagCounter   int
+	capabilities []string
+	secure       bool
+}
+
+// imapResponse defines a single IMAP server response line
+// with its parsed literals (if any).
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+// DialImap connects to the IMAP server at the specified address
+// (host:port) and reads the server greeting.
+//
+// If useTls is true, the connection is established using implicit TLS
+// (usually port 993), otherwise the STARTTLS command is sent if the server supports it.
+//
+// The connection I/O is bound to ctx deadline (or [DefaultTimeout])
+// and it is interrupted on ctx cancellation.
+func DialImap(ctx context.Context, addr string, useTls bool) (*ImapClient, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+
+	if useTls {
+		conn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewImapClient(conn)
+	client.secure = useTls
+
+	stop := client.watch(ctx)
+	greeting, err := client.readResponse()
+	stop()
+	if err != nil {
+		client.conn.Close()
+		return nil, client.contextErr(ctx, err)
+	}
+	if !strings.HasPrefix(greeting.line, "* OK") && !strings.HasPrefix(greeting.line, "* PREAUTH") {
+		client.conn.Close()
+		return nil, fmt.Errorf("unexpected IMAP server greeting %q", greeting.line)
+	}
+
+	if err := client.loadCapabilities(ctx); err != nil {
+		client.conn.Close()
+		return nil, err
+	}
+
+	if !useTls && client.HasCapability("STARTTLS") {
+		if _, err := client.execute(ctx, "STARTTLS"); err != nil {
+			client.conn.Close()
+			return nil, err
+		}
+
+		host, _, _ := net.SplitHostPort(addr)
+
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		client.conn = tlsConn
+		client.reader = bufio.NewReader(tlsConn)
+		client.secure = true
+
+		// the capabilities could change after the TLS negotiation
+		if err := client.loadCapabilities(ctx); err != nil {
+			client.conn.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// NewImapClient creates a new IMAP client from an already established
+// server connection (the server greeting is expected to be read by the caller).
+func NewImapClient(conn net.Conn) *ImapClient {
+	_, secure := conn.(*tls.Conn)
+
+	return &ImapClient{
+		Timeout: DefaultTimeout,
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		secure:  secure,
+	}
+}
+
+// IsSecure reports whether the server connection is encrypted
+// (either with implicit TLS or with STARTTLS).
+func (c *ImapClient) IsSecure() bool {
+	return c.secure
+}
+
+// HasCapability reports whether the server advertised the specified capability (eg. "IDLE").
+func (c *ImapClient) HasCapability(name string) bool {
+	for _, v := range c.capabilities {
+		if strings.EqualFold(v, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Login authenticates the client with the provided credentials.
+func (c *ImapClient) Login(ctx context.Context, username string, password string) error {
+	_, err := c.execute(ctx, "LOGIN "+quote(username)+" "+quote(password))
+	if err != nil {
+		return err
+	}
+
+	// some servers advertise new capabilities after authentication
+	return c.loadCapabilities(ctx)
+}
+
+// Select selects the specified mailbox (eg. "INBOX").
+func (c *ImapClient) Select(ctx context.Context, mailbox string) error {
+	_, err := c.execute(ctx, "SELECT "+quote(mailbox))
+	return err
+}
+
+// SearchUnseen returns the UIDs of the unseen messages in the selected mailbox.
+func (c *ImapClient) SearchUnseen(ctx context.Context) ([]uint32, error) {
+	responses, err := c.execute(ctx, "UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+
+	result := []uint32{}
+
+	for _, r := range responses {
+		if !strings.HasPrefix(strings.ToUpper(r.line), "* SEARCH") {
+			continue
+		}
+
+		for _, field := range strings.Fields(r.line)[2:] {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SEARCH response %q", r.line)
+			}
+			result = append(result, uint32(uid))
+		}
+	}
+
+	return result, nil
+}
+
+// FetchRaw fetches the full raw RFC 5322 message with the specified UID
+// without marking it as seen.
+func (c *ImapClient) FetchRaw(ctx context.Context, uid uint32) ([]byte, error) {
+	responses, err := c.execute(ctx, fmt.Sprintf("UID FETCH %d (BODY.PEEK[])", uid))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range responses {
+		if strings.Contains(strings.ToUpper(r.line), " FETCH ") && len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+
+	return nil, fmt.Errorf("message with UID %d was not found", uid)
+}
+
+// MarkSeen adds the \Seen flag to the message with the specified UID.
+func (c *ImapClient) MarkSeen(ctx context.Context, uid uint32) error {
+	_, err := c.execute(ctx, fmt.Sprintf(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid))
+	return err
+}
+
+// Move moves the message with the specified UID to the specified mailbox.
+//
+// If the server doesn't support the MOVE extension, the message is
+// copied to the mailbox and the original is flagged as seen and deleted
+// (it will be removed on the next mailbox expunge).
+func (c *ImapClient) Move(ctx context.Context, uid uint32, mailbox string) error {
+	if c.HasCapability("MOVE") {
+		_, err := c.execute(ctx, fmt.Sprintf("UID MOVE %d %s", uid, quote(mailbox)))
+		return err
+	}
+
+	if _, err := c.execute(ctx, fmt.Sprintf("UID COPY %d %s", uid, quote(mailbox))); err != nil {
+		return err
+	}
+
+	_, err := c.execute(ctx, fmt.Sprintf(`UID STORE %d +FLAGS.SILENT (\Seen \Deleted)`, uid))
+	return err
+}
+
+// Idle waits (using the IDLE command) until there is a new message
+// in the selected mailbox or until the timeout or context cancellation,
+// whichever comes first.
+//
+// Returns an error if the server doesn't support the IDLE extension.
+func (c *ImapClient) Idle(ctx context.Context, timeout time.Duration) error {
+	if !c.HasCapability("IDLE") {
+		return errors.New("the IMAP server doesn't support IDLE")
+	}
+
+	tag := c.nextTag()
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout()))
+
+	if err := c.writeLine(tag + " IDLE"); err != nil {
+		return err
+	}
+
+	cont, err := c.readResponse()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(cont.line, "+") {
+		return fmt.Errorf("IDLE failed: %s", cont.line)
+	}
+
+	c.conn.SetDeadline(time.Now().Add(timeout))
+
+	// interrupt the blocking read on context cancellation
+	stop := context.AfterFunc(ctx, func() {
+		c.conn.SetReadDeadline(time.Now())
+	})
+
+	for {
+		r, err := c.readResponse()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			stop()
+			return err
+		}
+
+		if strings.HasSuffix(strings.ToUpper(r.line), " EXISTS") || strings.HasSuffix(strings.ToUpper(r.line), " RECENT") {
+			break
+		}
+	}
+
+	stop()
+
+	// the IDLE termination is not bound to ctx to allow
+	// the connection to be reused or gracefully logged out
+	c.conn.SetDeadline(time.Now().Add(c.timeout()))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := c.writeLine("DONE"); err != nil {
+		return err
+	}
+
+	_, err = c.readTagged(tag)
+
+	return err
+}
+
+// Logout sends the LOGOUT command and closes the server connection.
+func (c *ImapClient) Logout() error {
+	_, logoutErr := c.execute(context.Background(), "LOGOUT")
+
+	if err := c.conn.Close(); err != nil {
+		return err
+	}
+
+	return logoutErr
+}
+
+// -------------------------------------------------------------------
+
+func (c *ImapClient) loadCapabilities(ctx context.Context) error {
+	responses, err := c.execute(ctx, "CAPABILITY")
+	if err != nil {
+		return err
+	}
+
+	c.capabilities = nil
+
+	for _, r := range responses {
+		if strings.HasPrefix(strings.ToUpper(r.line), "* CAPABILITY ") {
+			c.capabilities = append(c.capabilities, strings.Fields(r.line)[2:]...)
+		}
+	}
+
+	return nil
+}
+
+func (c *ImapClient) nextTag() string {
+	c.tagCounter++
+	return "pb" + strconv.Itoa(c.tagCounter)
+}
+
+func (c *ImapClient) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+
+	return c.Timeout
+}
+
+// watch binds the connection I/O deadline to the earliest of ctx
+// deadline and the client timeout and interrupts the pending I/O
+// on ctx cancellation.
+//
+// The returned function must be called to release the ctx
+// watcher and to reset the connection deadline.
+func (c *ImapClient) watch(ctx context.Context) func() {
+	deadline := time.Now().Add(c.timeout())
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	conn := c.conn
+	conn.SetDeadline(deadline)
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+
+	return func() {
+		if stop() {
+			conn.SetDeadline(time.Time{})
+		}
+	}
+}
+
+// contextErr returns the ctx error if the I/O err was caused by the ctx cancellation.
+func (c *ImapClient) contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return err
+}
+
+// execute sends the specified command and returns its untagged responses.
+//
+// Returns an error if the command completion status is not OK.
+func (c *ImapClient) execute(ctx context.Context, command string) ([]*imapResponse, error) {
+	stop := c.watch(ctx)
+	defer stop()
+
+	tag := c.nextTag()
+
+	if err := c.writeLine(tag + " " + command); err != nil {
+		return nil, c.contextErr(ctx, err)
+	}
+
+	untagged, err := c.readTagged(tag)
+	if err != nil {
+		return nil, c.contextErr(ctx, err)
+	}
+
+	return untagged, nil
+}
+
+// readTagged reads the server responses until the tagged completion response.
+func (c *ImapClient) readTagged(tag string) ([]*imapResponse, error) {
+	var untagged []*imapResponse
+
+	for {
+		r, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasPrefix(r.line, tag+" ") {
+			untagged = append(untagged, r)
+			continue
+		}
+
+		status := strings.TrimPrefix(r.line, tag+" ")
+		if !strings.HasPrefix(strings.ToUpper(status), "OK") {
+			return nil, fmt.Errorf("IMAP command failed: %s", status)
+		}
+
+		return untagged, nil
+	}
+}
+
+// readResponse reads a single server response line
+// including its string literals (eg. "* 1 FETCH (BODY[] {123}\r\n...)").
+func (c *ImapClient) readResponse() (*imapResponse, error) {
+	r := &imapResponse{}
+
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		size, ok := literalSize(line)
+		if !ok {
+			r.line += line
+			return r, nil
+		}
+
+		if size > maxLiteralSize {
+			return nil, fmt.Errorf("the IMAP literal size %d exceeds the max allowed %d bytes", size, maxLiteralSize)
+		}
+
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, literal); err != nil {
+			return nil, err
+		}
+
+		r.line += line
+		r.literals = append(r.literals, literal)
+	}
+}
+
+func (c *ImapClient) writeLine(line string) error {
+	_, err := io.WriteString(c.conn, line+"\r\n")
+	return err
+}
+
+// literalSize extracts the literal size from the line "{size}" suffix.
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+
+	start := strings.LastIndexByte(line, '{')
+	if start < 0 {
+		return 0, false
+	}
+
+	size, err := strconv.Atoi(strings.TrimSuffix(line[start+1:len(line)-1], "+"))
+	if err != nil || size < 0 {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// quote returns the provided value as IMAP quoted string.
+func quote(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", "")
+	return `"` + r.Replace(value) + `"`
+}
@@ -0,0 +1,300 @@
+package maildrop
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"golang.org/x/net/html/charset"
+)
+
+// maxMultipartDepth is the max allowed nesting of the multipart message parts.
+const maxMultipartDepth = 10
+
+// Message defines a single parsed inbound email message.
+type Message struct {
+	// Uid is the IMAP unique identifier of the message
+	// (it is 0 if the message was parsed directly with [Parse]).
+	Uid uint32 `json:"uid"`
+
+	MessageId   string         `json:"messageId"`
+	From        mail.Address   `json:"from"`
+	To          []mail.Address `json:"to"`
+	Cc          []mail.Address `json:"cc"`
+	ReplyTo     []mail.Address `json:"replyTo"`
+	Subject     string         `json:"subject"`
+	Date        time.Time      `json:"date"`
+	Text        string         `json:"text"`
+	HTML        string         `json:"html"`
+	Attachments []*Attachment  `json:"attachments"`
+
+	// Header contains the raw (not decoded) top level message headers.
+	Header mail.Header `json:"header"`
+}
+
+// Attachment defines a single inbound email message attachment
+// (including the inline ones).
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	ContentId   string `json:"contentId"`
+	Inline      bool   `json:"inline"`
+	Data        []byte `json:"-"`
+}
+
+// File creates a new [filesystem.File] from the attachment data
+// (eg. to be assigned to a record file field).
+func (a *Attachment) File() (*filesystem.File, error) {
+	return filesystem.NewFileFromBytes(a.Data, a.Name)
+}
+
+// wordDecoder decodes the RFC 2047 encoded-words headers
+// (eg. "=?iso-8859-1?q?caf=E9?=") in any known charset.
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// Parse parses the provided raw RFC 5322 email message.
+//
+// The multipart/* bodies are walked recursively and the first
+// text/plain and text/html parts are used as message Text and HTML
+// (converted to UTF-8). All other parts, or the parts with
+// "Content-Disposition: attachment", are returned as attachments.
+func Parse(r io.Reader) (*Message, error) {
+	raw, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the message: %w", err)
+	}
+
+	msg := &Message{
+		MessageId: strings.Trim(strings.TrimSpace(raw.Header.Get("Message-Id")), "<>"),
+		Subject:   decodeHeader(raw.Header.Get("Subject")),
+		Header:    raw.Header,
+	}
+
+	parser := &mail.AddressParser{WordDecoder: wordDecoder}
+
+	if from := raw.Header.Get("From"); from != "" {
+		if addr, err := parser.Parse(from); err == nil {
+			msg.From = *addr
+		} else {
+			msg.From = mail.Address{Address: strings.TrimSpace(decodeHeader(from))}
+		}
+	}
+
+	msg.To = parseAddressList(parser, raw.Header.Get("To"))
+	msg.Cc = parseAddressList(parser, raw.Header.Get("Cc"))
+	msg.ReplyTo = parseAddressList(parser, raw.Header.Get("Reply-To"))
+
+	if date, err := raw.Header.Date(); err == nil {
+		msg.Date = date
+	}
+
+	if err := msg.parsePart(textproto.MIMEHeader(raw.Header), raw.Body, 0); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// parsePart parses a single (possibly multipart) message part
+// and stores its content in the message.
+func (msg *Message) parsePart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// RFC 2045 default
+		mediaType = "text/plain"
+		params = map[string]string{"charset": "us-ascii"}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMultipartDepth {
+			return fmt.Errorf("the message parts exceed the max nesting depth of %d", maxMultipartDepth)
+		}
+
+		if params["boundary"] == "" {
+			return fmt.Errorf("missing %s boundary", mediaType)
+		}
+
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read %s part: %w", mediaType, err)
+			}
+
+			if err := msg.parsePart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s part: %w", mediaType, err)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+
+	name := dispositionParams["filename"]
+	if name == "" {
+		name = params["name"]
+	}
+	name = decodeHeader(name)
+
+	isAttachment := disposition == "attachment" || name != ""
+
+	if !isAttachment && (mediaType == "text/plain" || mediaType == "text/html") {
+		text, err := toUTF8(params["charset"], data)
+		if err != nil {
+			return err
+		}
+
+		if mediaType == "text/html" {
+			if msg.HTML == "" {
+				msg.HTML = text
+				return nil
+			}
+		} else if msg.Text == "" {
+			msg.Text = text
+			return nil
+		}
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("attachment%d%s", len(msg.Attachments)+1, extensionByType(mediaType))
+	}
+
+	msg.Attachments = append(msg.Attachments, &Attachment{
+		Name:        name,
+		ContentType: mediaType,
+		ContentId:   strings.Trim(strings.TrimSpace(header.Get("Content-Id")), "<>"),
+		Inline:      disposition == "inline",
+		Data:        data,
+	})
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// decodeHeader decodes the RFC 2047 encoded-words of the provided header value.
+//
+// Returns the original value on decoding error.
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+
+	return decoded
+}
+
+// parseAddressList parses the provided address list header value
+// ignoring the invalid addresses.
+func parseAddressList(parser *mail.AddressParser, value string) []mail.Address {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	result := []mail.Address{}
+
+	list, err := parser.ParseList(value)
+	if err == nil {
+		for _, addr := range list {
+			result = append(result, *addr)
+		}
+		return result
+	}
+
+	// try to parse the addresses one by one
+	for _, part := range strings.Split(value, ",") {
+		if addr, err := parser.Parse(part); err == nil {
+			result = append(result, *addr)
+		}
+	}
+
+	return result
+}
+
+// decodeTransferEncoding wraps body with a reader decoding the
+// specified Content-Transfer-Encoding (7bit, 8bit and binary are read as they are).
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// note: the new line characters are ignored by the decoder
+		return base64.NewDecoder(base64.StdEncoding, &base64Sanitizer{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// toUTF8 converts the provided data from the specified charset to UTF-8.
+func toUTF8(charsetLabel string, data []byte) (string, error) {
+	switch strings.ToLower(charsetLabel) {
+	case "", "utf-8", "utf8", "us-ascii":
+		return string(data), nil
+	}
+
+	r, err := charset.NewReaderLabel(charsetLabel, bytes.NewReader(data))
+	if err != nil {
+		// unknown charset - return the data as it is
+		return string(data), nil
+	}
+
+	converted, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert %s text: %w", charsetLabel, err)
+	}
+
+	return string(converted), nil
+}
+
+// extensionByType returns the first known file extension
+// of the specified media type (or empty string if there is none).
+func extensionByType(mediaType string) string {
+	exts, _ := mime.ExtensionsByType(mediaType)
+	if len(exts) > 0 {
+		return exts[0]
+	}
+
+	return ""
+}
+
+// base64Sanitizer strips the whitespace characters (except the new lines
+// that are handled by the decoder) that some clients add in the base64 lines.
+type base64Sanitizer struct {
+	r io.Reader
+}
+
+func (s *base64Sanitizer) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+
+	j := 0
+	for i := 0; i < n; i++ {
+		if p[i] == ' ' || p[i] == '\t' {
+			continue
+		}
+		p[j] = p[i]
+		j++
+	}
+
+	// avoid returning (0, nil) for whitespace only chunks
+	if j == 0 && n > 0 && err == nil {
+		return s.Read(p)
+	}
+
+	return j, err
+}
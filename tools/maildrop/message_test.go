@@ -0,0 +1,180 @@
+package maildrop_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/maildrop"
+)
+
+const plainMessage = "From: =?UTF-8?B?0JjQstCw0L0=?= <ivan@example.com>\r\n" +
+	"To: support@example.com, \"Second, Recipient\" <second@example.com>\r\n" +
+	"Subject: =?iso-8859-1?q?caf=E9?= order\r\n" +
+	"Message-ID: <abc123@example.com>\r\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+	"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Hello caf=E9,=\r\n" +
+	" soft break\r\n"
+
+const multipartMessage = "From: john@example.com\r\n" +
+	"To: support@example.com\r\n" +
+	"Subject: =?UTF-8?Q?multipart_=F0=9F=93=8E?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"preamble\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"plain body\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"PHA+aHRtbCBi\r\n" +
+	"b2R5PC9wPg==\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/plain; name=\"notes.txt\"\r\n" +
+	"Content-Disposition: attachment; filename*=UTF-8''n%C3%B6tes.txt\r\n" +
+	"\r\n" +
+	"attached text\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Disposition: inline\r\n" +
+	"Content-ID: <logo@example.com>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBO Rw0K\r\n" +
+	"--outer--\r\n"
+
+func TestParsePlainMessage(t *testing.T) {
+	msg, err := maildrop.Parse(strings.NewReader(plainMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.From.Name != "Иван" || msg.From.Address != "ivan@example.com" {
+		t.Fatalf("Unexpected From %v", msg.From)
+	}
+
+	if len(msg.To) != 2 || msg.To[0].Address != "support@example.com" || msg.To[1].Name != "Second, Recipient" {
+		t.Fatalf("Unexpected To %v", msg.To)
+	}
+
+	if msg.Subject != "café order" {
+		t.Fatalf("Expected subject %q, got %q", "café order", msg.Subject)
+	}
+
+	if msg.MessageId != "abc123@example.com" {
+		t.Fatalf("Expected message id %q, got %q", "abc123@example.com", msg.MessageId)
+	}
+
+	if msg.Date.UTC().Format("2006-01-02 15:04:05") != "2006-01-02 15:04:05" {
+		t.Fatalf("Unexpected date %v", msg.Date)
+	}
+
+	if msg.Text != "Hello café, soft break\r\n" {
+		t.Fatalf("Unexpected text %q", msg.Text)
+	}
+
+	if msg.HTML != "" || len(msg.Attachments) != 0 {
+		t.Fatalf("Expected no html and attachments, got %q, %v", msg.HTML, msg.Attachments)
+	}
+}
+
+func TestParseMultipartMessage(t *testing.T) {
+	msg, err := maildrop.Parse(strings.NewReader(multipartMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Subject != "multipart 📎" {
+		t.Fatalf("Unexpected subject %q", msg.Subject)
+	}
+
+	if msg.Text != "plain body" {
+		t.Fatalf("Unexpected text %q", msg.Text)
+	}
+
+	if msg.HTML != "<p>html body</p>" {
+		t.Fatalf("Unexpected html %q", msg.HTML)
+	}
+
+	if len(msg.Attachments) != 2 {
+		t.Fatalf("Expected 2 attachments, got %d", len(msg.Attachments))
+	}
+
+	notes := msg.Attachments[0]
+	if notes.Name != "nötes.txt" || notes.ContentType != "text/plain" || notes.Inline || string(notes.Data) != "attached text" {
+		t.Fatalf("Unexpected attachment %+v", notes)
+	}
+
+	logo := msg.Attachments[1]
+	if logo.Name != "attachment2.png" || logo.ContentId != "logo@example.com" || !logo.Inline {
+		t.Fatalf("Unexpected inline attachment %+v", logo)
+	}
+	if string(logo.Data) != "\x89PNG\r\n" {
+		t.Fatalf("Unexpected inline attachment data %q", logo.Data)
+	}
+
+	file, err := logo.File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.OriginalName != "attachment2.png" || file.Size != 6 {
+		t.Fatalf("Unexpected attachment file %+v", file)
+	}
+}
+
+func TestParseInvalidMessage(t *testing.T) {
+	scenarios := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"missing boundary", "Content-Type: multipart/mixed\r\n\r\ntest"},
+		{"too deep nesting", nestedMultipart(12)},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if _, err := maildrop.Parse(strings.NewReader(s.content)); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
+
+// nestedMultipart generates a message with the specified multipart nesting depth.
+func nestedMultipart(depth int) string {
+	var sb strings.Builder
+
+	sb.WriteString("Content-Type: multipart/mixed; boundary=b0\r\n\r\n")
+	for i := 1; i <= depth; i++ {
+		sb.WriteString(fmt.Sprintf("--b%d\r\nContent-Type: multipart/mixed; boundary=b%d\r\n\r\n", i-1, i))
+	}
+	sb.WriteString(fmt.Sprintf("--b%d\r\n\r\ntext\r\n", depth))
+	for i := depth; i >= 0; i-- {
+		sb.WriteString(fmt.Sprintf("--b%d--\r\n", i))
+	}
+
+	return sb.String()
+}
+
+func TestParseMissingContentType(t *testing.T) {
+	msg, err := maildrop.Parse(strings.NewReader("Subject: test\r\n\r\nbody"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Text != "body" || msg.From.Address != "" || len(msg.To) != 0 {
+		t.Fatalf("Unexpected message %+v", msg)
+	}
+}
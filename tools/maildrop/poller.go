@@ -0,0 +1,307 @@
+package maildrop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DefaultPollInterval is the default interval between the mailbox checks.
+const DefaultPollInterval = 1 * time.Minute
+
+// DefaultMailbox is the default IMAP mailbox to poll.
+const DefaultMailbox = "INBOX"
+
+// DefaultMaxAttempts is the default max number of processing attempts of a single message.
+const DefaultMaxAttempts = 3
+
+// ErrInsecureAuth is returned when the poller refuses to send the
+// credentials over an unencrypted connection.
+var ErrInsecureAuth = errors.New("refusing to send the IMAP credentials over an unencrypted connection (enable TLS or AllowInsecureAuth)")
+
+// Config defines the IMAP mailbox poller configuration.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+
+	// Tls specifies whether to use implicit TLS (usually port 993).
+	//
+	// When false, STARTTLS is used if the server supports it.
+	Tls bool
+
+	// AllowInsecureAuth allows sending the credentials over a plaintext
+	// connection when Tls is disabled and the server doesn't support STARTTLS.
+	AllowInsecureAuth bool
+
+	// Mailbox is the name of the mailbox to poll (default to "INBOX").
+	Mailbox string
+
+	// PollInterval is the interval between the mailbox checks (default to 1 minute).
+	//
+	// When Idle is enabled, it is used as max IDLE wait time.
+	PollInterval time.Duration
+
+	// Idle enables the IMAP IDLE extension (if supported by the server)
+	// to be notified immediately for the new messages instead of
+	// waiting for the next poll interval.
+	Idle bool
+
+	// MaxAttempts is the max number of failed processing attempts
+	// of a single message before giving up on it (default to 3).
+	MaxAttempts int
+
+	// FailedMailbox is the optional mailbox where to move the messages
+	// that have reached MaxAttempts.
+	//
+	// When empty, the failed messages are only marked as seen.
+	FailedMailbox string
+}
+
+// HandlerFunc processes a single inbound message.
+//
+// The message is marked as seen only if the handler returns nil,
+// otherwise it will be retried on the next mailbox check
+// (up to Config.MaxAttempts).
+type HandlerFunc func(msg *Message) error
+
+// Poller fetches the unseen messages of a single IMAP mailbox
+// and dispatches them to a handler.
+//
+// Poller is not safe for concurrent use.
+type Poller struct {
+	config  Config
+	handler HandlerFunc
+
+	// failures holds the failed processing attempts per message UID
+	failures map[uint32]int
+
+	// OnError is an optional callback that is called on connection
+	// and message processing errors (the poller doesn't stop on error).
+	OnError func(err error)
+}
+
+// NewPoller creates a new mailbox poller with the provided config and message handler.
+func NewPoller(config Config, handler HandlerFunc) *Poller {
+	if config.Mailbox == "" {
+		config.Mailbox = DefaultMailbox
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return &Poller{
+		config:   config,
+		handler:  handler,
+		failures: map[uint32]int{},
+	}
+}
+
+// Poll connects to the mailbox, processes all unseen messages and disconnects.
+//
+// It returns the number of the successfully processed messages.
+func (p *Poller) Poll(ctx context.Context) (int, error) {
+	client, err := p.connect(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Logout()
+
+	return p.process(ctx, client)
+}
+
+// Run checks periodically the mailbox for unseen messages until ctx is canceled.
+//
+// The same server connection is reused between the checks and
+// it is reestablished on the next check in case of an error.
+func (p *Poller) Run(ctx context.Context) {
+	var client *ImapClient
+
+	defer func() {
+		if client != nil {
+			client.Logout()
+		}
+	}()
+
+	for ctx.Err() == nil {
+		if client == nil {
+			var err error
+			client, err = p.connect(ctx)
+			if err != nil {
+				p.reportError(err)
+				sleep(ctx, p.config.PollInterval)
+				continue
+			}
+		}
+
+		if _, err := p.process(ctx, client); err != nil {
+			p.reportError(err)
+			client.Logout()
+			client = nil
+			sleep(ctx, p.config.PollInterval)
+			continue
+		}
+
+		if p.config.Idle && client.HasCapability("IDLE") {
+			if err := client.Idle(ctx, p.config.PollInterval); err != nil {
+				if ctx.Err() == nil {
+					p.reportError(err)
+				}
+				client.Logout()
+				client = nil
+			}
+		} else {
+			sleep(ctx, p.config.PollInterval)
+		}
+	}
+}
+
+func (p *Poller) connect(ctx context.Context) (*ImapClient, error) {
+	addr := net.JoinHostPort(p.config.Host, strconv.Itoa(p.config.Port))
+
+	client, err := DialImap(ctx, addr, p.config.Tls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	if !client.IsSecure() && !p.config.AllowInsecureAuth {
+		client.Logout()
+		return nil, ErrInsecureAuth
+	}
+
+	if err := client.Login(ctx, p.config.Username, p.config.Password); err != nil {
+		client.Logout()
+		return nil, err
+	}
+
+	if err := client.Select(ctx, p.config.Mailbox); err != nil {
+		client.Logout()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// process dispatches all unseen messages of the selected mailbox to the poller handler.
+//
+// The handler and parse errors are only reported to avoid blocking
+// the processing of the other messages.
+func (p *Poller) process(ctx context.Context, client *ImapClient) (int, error) {
+	uids, err := client.SearchUnseen(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// forget the failures of the no longer unseen messages
+	for uid := range p.failures {
+		if !slices.Contains(uids, uid) {
+			delete(p.failures, uid)
+		}
+	}
+
+	var total int
+
+	for _, uid := range uids {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		raw, err := client.FetchRaw(ctx, uid)
+		if err != nil {
+			return total, err
+		}
+
+		msg, err := Parse(bytes.NewReader(raw))
+		if err != nil {
+			err = fmt.Errorf("failed to parse message %d: %w", uid, err)
+		} else {
+			msg.Uid = uid
+			if err = p.handle(msg); err != nil {
+				err = fmt.Errorf("failed to process message %d: %w", uid, err)
+			}
+		}
+
+		if err != nil {
+			p.reportError(err)
+
+			if err := p.fail(ctx, client, uid); err != nil {
+				return total, err
+			}
+
+			continue
+		}
+
+		delete(p.failures, uid)
+
+		if err := client.MarkSeen(ctx, uid); err != nil {
+			return total, err
+		}
+
+		total++
+	}
+
+	return total, nil
+}
+
+// fail registers a failed processing attempt of the message with the
+// specified UID and moves it to the FailedMailbox (or marks it as seen)
+// once the MaxAttempts are reached so that it is no longer retried.
+func (p *Poller) fail(ctx context.Context, client *ImapClient, uid uint32) error {
+	p.failures[uid]++
+	if p.failures[uid] < p.config.MaxAttempts {
+		return nil
+	}
+
+	delete(p.failures, uid)
+
+	p.reportError(fmt.Errorf("giving up on message %d after %d failed attempts", uid, p.config.MaxAttempts))
+
+	if p.config.FailedMailbox != "" {
+		return client.Move(ctx, uid, p.config.FailedMailbox)
+	}
+
+	return client.MarkSeen(ctx, uid)
+}
+
+// handle calls the poller handler recovering from panics.
+func (p *Poller) handle(msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	if p.handler == nil {
+		return errors.New("missing message handler")
+	}
+
+	return p.handler(msg)
+}
+
+func (p *Poller) reportError(err error) {
+	if p.OnError != nil {
+		p.OnError(err)
+	}
+}
+
+// sleep pauses the current goroutine for d duration or until ctx is canceled.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,450 @@
+package maildrop_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/maildrop"
+)
+
+// mockImapServer is a minimal in-memory IMAP server for the poller tests.
+type mockImapServer struct {
+	mu       sync.Mutex
+	listener net.Listener
+	messages map[uint32]string
+	seen     map[uint32]bool
+	idle     bool
+	commands []string
+	idleCh   chan struct{}
+}
+
+func newMockImapServer(t *testing.T, idle bool, messages map[uint32]string) *mockImapServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &mockImapServer{
+		listener: listener,
+		messages: messages,
+		seen:     map[uint32]bool{},
+		idle:     idle,
+		idleCh:   make(chan struct{}, 1),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() {
+		listener.Close()
+	})
+
+	return s
+}
+
+func (s *mockImapServer) config() maildrop.Config {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	return maildrop.Config{
+		Host:         host,
+		Port:         p,
+		Username:     "test",
+		Password:     `pass"word`,
+		PollInterval: 50 * time.Millisecond,
+		Idle:         s.idle,
+		// the mock server doesn't support TLS
+		AllowInsecureAuth: true,
+	}
+}
+
+// addMessage adds a new message and notifies the idling clients.
+func (s *mockImapServer) addMessage(uid uint32, raw string) {
+	s.mu.Lock()
+	s.messages[uid] = raw
+	s.mu.Unlock()
+
+	select {
+	case s.idleCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *mockImapServer) isSeen(uid uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.seen[uid]
+}
+
+func (s *mockImapServer) hasCommand(prefix string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.commands {
+		if strings.HasPrefix(c, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *mockImapServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	write := func(format string, args ...any) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	write("* OK mock IMAP server ready")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		tag, command, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		switch {
+		case command == "CAPABILITY":
+			if s.idle {
+				write("* CAPABILITY IMAP4rev1 MOVE IDLE")
+			} else {
+				write("* CAPABILITY IMAP4rev1 MOVE")
+			}
+			write("%s OK CAPABILITY completed", tag)
+		case strings.HasPrefix(command, "LOGIN "):
+			if command != `LOGIN "test" "pass\"word"` {
+				write("%s NO invalid credentials", tag)
+				continue
+			}
+			write("%s OK LOGIN completed", tag)
+		case strings.HasPrefix(command, "SELECT "):
+			write("* FLAGS (\\Seen)")
+			write("%s OK [READ-WRITE] SELECT completed", tag)
+		case command == "UID SEARCH UNSEEN":
+			s.mu.Lock()
+			uids := []string{}
+			for uid := range s.messages {
+				if !s.seen[uid] {
+					uids = append(uids, strconv.Itoa(int(uid)))
+				}
+			}
+			s.mu.Unlock()
+			write("* SEARCH %s", strings.Join(uids, " "))
+			write("%s OK SEARCH completed", tag)
+		case strings.HasPrefix(command, "UID FETCH "):
+			uid, _ := strconv.Atoi(strings.Fields(command)[2])
+			s.mu.Lock()
+			raw := s.messages[uint32(uid)]
+			s.mu.Unlock()
+			write("* 1 FETCH (UID %d BODY[] {%d}", uid, len(raw))
+			fmt.Fprintf(conn, "%s)\r\n", raw)
+			write("%s OK FETCH completed", tag)
+		case strings.HasPrefix(command, "UID STORE "):
+			uid, _ := strconv.Atoi(strings.Fields(command)[2])
+			s.mu.Lock()
+			s.seen[uint32(uid)] = true
+			s.mu.Unlock()
+			write("%s OK STORE completed", tag)
+		case strings.HasPrefix(command, "UID MOVE "):
+			uid, _ := strconv.Atoi(strings.Fields(command)[2])
+			s.mu.Lock()
+			delete(s.messages, uint32(uid))
+			s.mu.Unlock()
+			write("%s OK MOVE completed", tag)
+		case command == "IDLE":
+			write("+ idling")
+			doneCh := make(chan string, 1)
+			go func() {
+				done, _ := reader.ReadString('\n')
+				doneCh <- done
+			}()
+			var done string
+			select {
+			case <-s.idleCh:
+				write("* 2 EXISTS")
+				done = <-doneCh
+			case done = <-doneCh:
+			}
+			if strings.TrimSpace(done) != "DONE" {
+				return
+			}
+			write("%s OK IDLE terminated", tag)
+		case command == "LOGOUT":
+			write("* BYE")
+			write("%s OK LOGOUT completed", tag)
+			return
+		default:
+			write("%s BAD unknown command", tag)
+		}
+	}
+}
+
+func TestPollerPoll(t *testing.T) {
+	server := newMockImapServer(t, false, map[uint32]string{
+		1: plainMessage,
+		2: multipartMessage,
+		3: "Subject: fail\r\n\r\nfail",
+	})
+
+	processed := []string{}
+
+	poller := maildrop.NewPoller(server.config(), func(msg *maildrop.Message) error {
+		if msg.Subject == "fail" {
+			return errors.New("test handler error")
+		}
+		processed = append(processed, fmt.Sprintf("%d:%s", msg.Uid, msg.Subject))
+		return nil
+	})
+
+	var reportedErrors []error
+	poller.OnError = func(err error) {
+		reportedErrors = append(reportedErrors, err)
+	}
+
+	total, err := poller.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 2 {
+		t.Fatalf("Expected 2 processed messages, got %d", total)
+	}
+
+	if len(processed) != 2 {
+		t.Fatalf("Expected 2 handler calls, got %v", processed)
+	}
+
+	if len(reportedErrors) != 1 || !strings.Contains(reportedErrors[0].Error(), "test handler error") {
+		t.Fatalf("Expected the handler error to be reported, got %v", reportedErrors)
+	}
+
+	if !server.isSeen(1) || !server.isSeen(2) {
+		t.Fatal("Expected messages 1 and 2 to be marked as seen")
+	}
+
+	if server.isSeen(3) {
+		t.Fatal("Expected the failed message 3 to remain unseen")
+	}
+
+	if !server.hasCommand("UID FETCH 1 (BODY.PEEK[])") {
+		t.Fatal("Expected the messages to be fetched with BODY.PEEK")
+	}
+
+	// only the failed message should be retried
+	processed = processed[:0]
+	total, err = poller.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 || len(processed) != 0 {
+		t.Fatalf("Expected no new processed messages, got %d (%v)", total, processed)
+	}
+}
+
+func TestPollerPollInvalidCredentials(t *testing.T) {
+	server := newMockImapServer(t, false, map[uint32]string{})
+
+	config := server.config()
+	config.Password = "invalid"
+
+	poller := maildrop.NewPoller(config, func(msg *maildrop.Message) error {
+		return nil
+	})
+
+	if _, err := poller.Poll(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid credentials") {
+		t.Fatalf("Expected invalid credentials error, got %v", err)
+	}
+}
+
+func TestPollerPollInsecureAuth(t *testing.T) {
+	server := newMockImapServer(t, false, map[uint32]string{})
+
+	config := server.config()
+	config.AllowInsecureAuth = false
+
+	poller := maildrop.NewPoller(config, func(msg *maildrop.Message) error {
+		return nil
+	})
+
+	if _, err := poller.Poll(context.Background()); !errors.Is(err, maildrop.ErrInsecureAuth) {
+		t.Fatalf("Expected ErrInsecureAuth, got %v", err)
+	}
+
+	if server.hasCommand("LOGIN") {
+		t.Fatal("Expected the credentials to not be sent over the plaintext connection")
+	}
+}
+
+func TestPollerPollMaxAttempts(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		failedMailbox string
+		expectedMove  bool
+	}{
+		{"mark as seen", "", false},
+		{"move to mailbox", "Failed", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			server := newMockImapServer(t, false, map[uint32]string{
+				1: "Subject: fail\r\n\r\nfail",
+			})
+
+			config := server.config()
+			config.MaxAttempts = 2
+			config.FailedMailbox = s.failedMailbox
+
+			var calls int
+			poller := maildrop.NewPoller(config, func(msg *maildrop.Message) error {
+				calls++
+				return errors.New("test handler error")
+			})
+
+			for i := 0; i < 3; i++ {
+				if _, err := poller.Poll(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if calls != 2 {
+				t.Fatalf("Expected the handler to be called 2 times, got %d", calls)
+			}
+
+			if s.expectedMove != server.hasCommand(`UID MOVE 1 "Failed"`) {
+				t.Fatalf("Expected the message move %v", s.expectedMove)
+			}
+
+			if !s.expectedMove && !server.isSeen(1) {
+				t.Fatal("Expected the failed message to be marked as seen")
+			}
+		})
+	}
+}
+
+func TestPollerPollContextDeadline(t *testing.T) {
+	// server that accepts the connection but never sends the greeting
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	poller := maildrop.NewPoller(maildrop.Config{Host: host, Port: p}, func(msg *maildrop.Message) error {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := poller.Poll(ctx); err == nil {
+		t.Fatal("Expected the poll to fail")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Expected the poll to stop on the ctx deadline, took %v", elapsed)
+	}
+}
+
+func TestPollerRun(t *testing.T) {
+	for _, idle := range []bool{false, true} {
+		t.Run(fmt.Sprintf("idle_%v", idle), func(t *testing.T) {
+			server := newMockImapServer(t, idle, map[uint32]string{1: plainMessage})
+
+			received := make(chan uint32, 10)
+
+			config := server.config()
+			if idle {
+				// ensure that the new message is received with IDLE and not by the next poll
+				config.PollInterval = 10 * time.Second
+			}
+
+			poller := maildrop.NewPoller(config, func(msg *maildrop.Message) error {
+				received <- msg.Uid
+				return nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				poller.Run(ctx)
+				close(done)
+			}()
+
+			waitUid := func(expected uint32) {
+				select {
+				case uid := <-received:
+					if uid != expected {
+						t.Fatalf("Expected message %d, got %d", expected, uid)
+					}
+				case <-time.After(3 * time.Second):
+					t.Fatalf("Timeout waiting for message %d", expected)
+				}
+			}
+
+			waitUid(1)
+
+			if idle {
+				// wait for the client to enter IDLE
+				for i := 0; i < 100 && !server.hasCommand("IDLE"); i++ {
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+
+			server.addMessage(2, multipartMessage)
+
+			waitUid(2)
+
+			cancel()
+
+			select {
+			case <-done:
+			case <-time.After(3 * time.Second):
+				t.Fatal("Expected Run to stop after the context cancellation")
+			}
+
+			if idle != server.hasCommand("IDLE") {
+				t.Fatalf("Expected IDLE command %v", idle)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strings"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// DefaultDkimHeaders is the default list of the DKIM signed message headers.
+//
+// Only the headers that are present in the message are signed
+// (with the exception of "From" which is always required).
+var DefaultDkimHeaders = []string{
+	"From",
+	"To",
+	"Cc",
+	"Subject",
+	"Date",
+	"Reply-To",
+	"Message-ID",
+	"MIME-Version",
+	"Content-Type",
+}
+
+// DkimOptions defines the DKIM message signing options.
+type DkimOptions struct {
+	// Domain is the signing domain identifier (the DKIM "d=" tag).
+	Domain string
+
+	// Selector is the DNS selector of the public key (the DKIM "s=" tag),
+	// eg. "pb" for a "pb._domainkey.example.com" TXT record.
+	Selector string
+
+	// PrivateKey is the PEM encoded RSA (PKCS #1 or PKCS #8) or Ed25519 (PKCS #8) private key.
+	PrivateKey string
+
+	// Headers is an optional list of the message headers to sign
+	// (if not set, fallbacks to [DefaultDkimHeaders]).
+	Headers []string
+}
+
+// DkimSign signs the provided raw RFC 5322 message with the specified options
+// and returns the message with the prepended DKIM-Signature header.
+//
+// The message is signed using the "relaxed/relaxed" canonicalization
+// and "rsa-sha256" or "ed25519-sha256" algorithm based on the private key type.
+func DkimSign(raw []byte, options *DkimOptions) ([]byte, error) {
+	if options.Domain == "" || options.Selector == "" {
+		return nil, errors.New("missing DKIM domain or selector")
+	}
+
+	signer, err := parseDkimPrivateKey(options.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	raw = normalizeCRLF(raw)
+
+	header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(raw))).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse the message headers: %w", err)
+	}
+
+	headerNames := options.Headers
+	if len(headerNames) == 0 {
+		headerNames = DefaultDkimHeaders
+	}
+
+	// sign only the headers that are present in the message
+	headerKeys := make([]string, 0, len(headerNames))
+	for _, name := range headerNames {
+		if _, ok := header[textproto.CanonicalMIMEHeaderKey(name)]; ok {
+			headerKeys = append(headerKeys, strings.ToLower(name))
+		}
+	}
+
+	if header.Get("From") == "" {
+		return nil, errors.New("the DKIM signed message must have a From header")
+	}
+
+	var result bytes.Buffer
+	result.Grow(len(raw) + 1024)
+
+	err = dkim.Sign(&result, bytes.NewReader(raw), &dkim.SignOptions{
+		Domain:                 options.Domain,
+		Selector:               options.Selector,
+		Signer:                 signer,
+		Hash:                   crypto.SHA256,
+		HeaderCanonicalization: dkim.CanonicalizationRelaxed,
+		BodyCanonicalization:   dkim.CanonicalizationRelaxed,
+		HeaderKeys:             headerKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Bytes(), nil
+}
+
+// parseDkimPrivateKey parses the provided PEM encoded private key
+// and returns its signer.
+func parseDkimPrivateKey(pemKey string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(pemKey)))
+	if block == nil {
+		return nil, errors.New("invalid or missing PEM encoded DKIM private key")
+	}
+
+	var key any
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the DKIM private key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported DKIM private key type %T", key)
+	}
+}
+
+// ValidateDkimPrivateKey checks whether the provided PEM encoded
+// private key could be used for DKIM signing.
+func ValidateDkimPrivateKey(pemKey string) error {
+	_, err := parseDkimPrivateKey(pemKey)
+	return err
+}
+
+// normalizeCRLF replaces the bare LF line endings with CRLF.
+func normalizeCRLF(raw []byte) []byte {
+	raw = bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(raw, []byte("\n"), []byte("\r\n"))
+}
@@ -0,0 +1,385 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+func TestDkimSign(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPKCS1 := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+	}))
+	rsaPKCS8Bytes, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPKCS8 := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rsaPKCS8Bytes}))
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPKCS8Bytes, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPKCS8 := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edPKCS8Bytes}))
+
+	message := "From: Test <test@example.com>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+		"Subject:   Hello\r\n\t world\r\n" +
+		"To: a@example.com,b@example.com\r\n" +
+		"X-Custom: unsigned\r\n" +
+		"\r\n" +
+		"Hello  world \t\r\n" +
+		"\tindented line\n" + // bare LF
+		"\r\n" +
+		"\r\n"
+
+	scenarios := []struct {
+		name      string
+		key       string
+		publicKey crypto.PublicKey
+		algorithm string
+	}{
+		{"rsa pkcs1", rsaPKCS1, &rsaKey.PublicKey, "rsa-sha256"},
+		{"rsa pkcs8", rsaPKCS8, &rsaKey.PublicKey, "rsa-sha256"},
+		{"ed25519", edPKCS8, edPub, "ed25519-sha256"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			signed, err := DkimSign([]byte(message), &DkimOptions{
+				Domain:     "example.com",
+				Selector:   "pb",
+				PrivateKey: s.key,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tags, err := verifyDkimSignature(signed, s.publicKey)
+			if err != nil {
+				t.Fatalf("Expected valid DKIM signature, got %v\n%s", err, signed)
+			}
+
+			expectedTags := map[string]string{
+				"v": "1",
+				"a": s.algorithm,
+				"c": "relaxed/relaxed",
+				"d": "example.com",
+				"s": "pb",
+				"h": "from:to:subject:date:mime-version",
+			}
+			for k, v := range expectedTags {
+				if tags[k] != v {
+					t.Fatalf("Expected tag %s=%q, got %q", k, v, tags[k])
+				}
+			}
+
+			// whitespace changes in the body and headers are allowed by the relaxed canonicalization
+			relaxed := bytes.Replace(signed, []byte("Hello  world"), []byte("Hello world"), 1)
+			relaxed = bytes.Replace(relaxed, []byte("Subject:   Hello"), []byte("Subject: Hello"), 1)
+			if _, err := verifyDkimSignature(relaxed, s.publicKey); err != nil {
+				t.Fatalf("Expected the relaxed message to be still valid, got %v", err)
+			}
+
+			// unsigned headers changes are allowed
+			unsigned := bytes.Replace(signed, []byte("X-Custom: unsigned"), []byte("X-Custom: changed"), 1)
+			if _, err := verifyDkimSignature(unsigned, s.publicKey); err != nil {
+				t.Fatalf("Expected the unsigned header change to be still valid, got %v", err)
+			}
+
+			tampered := map[string][]byte{
+				"body":    bytes.Replace(signed, []byte("indented line"), []byte("indented lines"), 1),
+				"from":    bytes.Replace(signed, []byte("test@example.com"), []byte("evil@example.com"), 1),
+				"to":      bytes.Replace(signed, []byte("b@example.com"), []byte("c@example.com"), 1),
+				"subject": bytes.Replace(signed, []byte(" world\r\nTo:"), []byte(" worlds\r\nTo:"), 1),
+				"date":    bytes.Replace(signed, []byte("15:04:05"), []byte("15:04:06"), 1),
+			}
+			for name, raw := range tampered {
+				if _, err := verifyDkimSignature(raw, s.publicKey); err == nil {
+					t.Fatalf("Expected the message with tampered %s to be invalid", name)
+				}
+			}
+		})
+	}
+
+	t.Run("wrong public key", func(t *testing.T) {
+		otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+		signed, err := DkimSign([]byte(message), &DkimOptions{Domain: "example.com", Selector: "pb", PrivateKey: rsaPKCS1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := verifyDkimSignature(signed, &otherKey.PublicKey); err == nil {
+			t.Fatal("Expected invalid signature with the wrong public key")
+		}
+	})
+
+	t.Run("custom headers", func(t *testing.T) {
+		signed, err := DkimSign([]byte(message), &DkimOptions{
+			Domain:     "example.com",
+			Selector:   "pb",
+			PrivateKey: rsaPKCS1,
+			Headers:    []string{"from", "x-custom", "missing"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tags, err := verifyDkimSignature(signed, &rsaKey.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tags["h"] != "from:x-custom" {
+			t.Fatalf("Expected h=from:x-custom, got %q", tags["h"])
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		scenarios := []struct {
+			name    string
+			message string
+			options *DkimOptions
+		}{
+			{"missing domain", message, &DkimOptions{Selector: "pb", PrivateKey: rsaPKCS1}},
+			{"missing selector", message, &DkimOptions{Domain: "example.com", PrivateKey: rsaPKCS1}},
+			{"invalid key", message, &DkimOptions{Domain: "example.com", Selector: "pb", PrivateKey: "invalid"}},
+			{"missing from", "Subject: test\r\n\r\ntest", &DkimOptions{Domain: "example.com", Selector: "pb", PrivateKey: rsaPKCS1}},
+		}
+
+		for _, s := range scenarios {
+			t.Run(s.name, func(t *testing.T) {
+				if _, err := DkimSign([]byte(s.message), s.options); err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+			})
+		}
+	})
+}
+
+func TestSmtpClientSendWithDkim(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemKey := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	type received struct {
+		from       string
+		recipients []string
+		data       []byte
+	}
+	receivedCh := make(chan received, 1)
+
+	// minimal SMTP server that accepts a single message
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		write := func(line string) {
+			fmt.Fprintf(conn, "%s\r\n", line)
+		}
+
+		var result received
+
+		write("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			switch {
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				write("250 localhost")
+			case strings.HasPrefix(line, "MAIL FROM:"):
+				result.from = line
+				write("250 OK")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				result.recipients = append(result.recipients, line)
+				write("250 OK")
+			case line == "DATA":
+				write("354 send data")
+				var data bytes.Buffer
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				result.data = data.Bytes()
+				write("250 OK")
+				receivedCh <- result
+			case line == "QUIT":
+				write("221 bye")
+				return
+			default:
+				write("250 OK")
+			}
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	client := &SmtpClient{
+		Host: "127.0.0.1",
+		Port: p,
+		Dkim: &DkimOptions{
+			Domain:     "example.com",
+			Selector:   "pb",
+			PrivateKey: pemKey,
+		},
+	}
+
+	err = client.Send(&Message{
+		From:    mail.Address{Name: "Sender", Address: "sender@example.com"},
+		To:      []mail.Address{{Address: "to@example.com"}},
+		Cc:      []mail.Address{{Address: "cc@example.com"}},
+		Bcc:     []mail.Address{{Address: "bcc@example.com"}},
+		Subject: "Test DKIM",
+		HTML:    "<p>Hello</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := <-receivedCh
+
+	if result.from != "MAIL FROM:<sender@example.com>" {
+		t.Fatalf("Unexpected MAIL FROM %q", result.from)
+	}
+
+	if len(result.recipients) != 3 {
+		t.Fatalf("Expected 3 recipients, got %v", result.recipients)
+	}
+
+	if !bytes.HasPrefix(result.data, []byte("DKIM-Signature:")) {
+		t.Fatalf("Expected the message to start with DKIM-Signature header, got\n%s", result.data)
+	}
+
+	if bytes.Contains(result.data, []byte("bcc@example.com")) {
+		t.Fatal("Expected the Bcc address to not be part of the message data")
+	}
+
+	tags, err := verifyDkimSignature(result.data, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("Expected valid DKIM signature, got %v\n%s", err, result.data)
+	}
+
+	for _, h := range []string{"from", "to", "subject", "date"} {
+		if !strings.Contains(":"+tags["h"]+":", ":"+h+":") {
+			t.Fatalf("Expected %q to be signed, got h=%q", h, tags["h"])
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+
+var (
+	testWSPRegex     = regexp.MustCompile(`[ \t]+`)
+	testFoldingRegex = regexp.MustCompile(`\r\n[ \t]`)
+)
+
+// verifyDkimSignature verifies the message DKIM signature with the
+// provided public key and returns the parsed DKIM-Signature tags.
+func verifyDkimSignature(raw []byte, publicKey crypto.PublicKey) (map[string]string, error) {
+	var record string
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		record = "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der)
+	case ed25519.PublicKey:
+		record = "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(k)
+	default:
+		return nil, fmt.Errorf("unsupported public key %T", publicKey)
+	}
+
+	verifications, err := dkim.VerifyWithOptions(bytes.NewReader(raw), &dkim.VerifyOptions{
+		LookupTXT: func(domain string) ([]string, error) {
+			if domain != "pb._domainkey.example.com" {
+				return nil, fmt.Errorf("unexpected DKIM lookup domain %q", domain)
+			}
+			return []string{record}, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(verifications) != 1 {
+		return nil, fmt.Errorf("expected 1 DKIM signature, got %d", len(verifications))
+	}
+	if verifications[0].Err != nil {
+		return nil, verifications[0].Err
+	}
+
+	header, _, _ := strings.Cut(string(raw), "\r\n\r\n")
+
+	// unfold the header fields
+	unfolded := testFoldingRegex.ReplaceAllStringFunc(header, func(s string) string {
+		return s[2:]
+	})
+	fields := strings.Split(unfolded, "\r\n")
+
+	name, signatureValue, _ := strings.Cut(fields[0], ":")
+	if !strings.EqualFold(name, "DKIM-Signature") {
+		return nil, errors.New("the first header is not DKIM-Signature")
+	}
+
+	tags := map[string]string{}
+	for _, tag := range strings.Split(signatureValue, ";") {
+		k, v, _ := strings.Cut(tag, "=")
+		tags[strings.TrimSpace(k)] = testWSPRegex.ReplaceAllString(strings.TrimSpace(v), "")
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,276 @@
+package mailer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/list"
+	"golang.org/x/net/html"
+)
+
+var cssCommentsRegex = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// simpleSelectorRegex matches a single compound selector
+// like "a", "*", ".btn", "#main" or "a.btn.primary".
+var simpleSelectorRegex = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*|\*)?((?:[.#][a-zA-Z0-9_-]+)*)$`)
+
+var selectorPartsRegex = regexp.MustCompile(`[.#][a-zA-Z0-9_-]+`)
+
+var inlineCSSSkipTags = []string{"head", "style", "script", "meta", "title", "link"}
+
+// cssRule defines a single inlinable css rule.
+type cssRule struct {
+	tag          string
+	ids          []string
+	classes      []string
+	specificity  int
+	order        int
+	declarations string
+}
+
+func (r *cssRule) matches(node *html.Node) bool {
+	if r.tag != "" && r.tag != "*" && !strings.EqualFold(r.tag, node.Data) {
+		return false
+	}
+
+	var id string
+	var classes []string
+	for _, attr := range node.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "id":
+			id = attr.Val
+		case "class":
+			classes = strings.Fields(attr.Val)
+		}
+	}
+
+	for _, v := range r.ids {
+		if v != id {
+			return false
+		}
+	}
+
+	for _, v := range r.classes {
+		if !list.ExistInSlice(v, classes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// InlineCSS copies the rules of the <style> elements of the provided
+// HTML document into the style attribute of the matching elements
+// since many email clients ignore or strip the <style> elements.
+//
+// Only the simple type, class and id selectors (eg. "p", ".btn", "a.btn", "#main")
+// are inlined following their specificity and source order. The existing element
+// style attribute declarations are preserved and take precedence.
+//
+// The <style> elements are kept as they are (eg. for the @media queries).
+func InlineCSS(htmlDocument string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlDocument))
+	if err != nil {
+		return "", err
+	}
+
+	var css strings.Builder
+
+	var collectStyles func(*html.Node)
+	collectStyles = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "style" {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.TextNode {
+					css.WriteString(c.Data)
+					css.WriteString("\n")
+				}
+			}
+			return
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collectStyles(c)
+		}
+	}
+	collectStyles(doc)
+
+	rules := parseInlinableCSSRules(css.String())
+	if len(rules) == 0 {
+		return htmlDocument, nil // nothing to inline
+	}
+
+	var apply func(*html.Node)
+	apply = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if list.ExistInSlice(n.Data, inlineCSSSkipTags) {
+				return
+			}
+
+			applyCSSRules(n, rules)
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			apply(c)
+		}
+	}
+	apply(doc)
+
+	var result strings.Builder
+	if err := html.Render(&result, doc); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+// applyCSSRules updates the node style attribute with the declarations
+// of all matching rules (ordered by their specificity).
+func applyCSSRules(node *html.Node, rules []*cssRule) {
+	var matched []*cssRule
+	for _, r := range rules {
+		if r.matches(node) {
+			matched = append(matched, r)
+		}
+	}
+
+	if len(matched) == 0 {
+		return
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].specificity == matched[j].specificity {
+			return matched[i].order < matched[j].order
+		}
+		return matched[i].specificity < matched[j].specificity
+	})
+
+	var style strings.Builder
+	for _, r := range matched {
+		style.WriteString(r.declarations)
+	}
+
+	for i, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, "style") {
+			// the inline declarations are last to take precedence
+			style.WriteString(normalizeCSSDeclarations(attr.Val))
+			node.Attr[i].Val = strings.TrimSpace(style.String())
+			return
+		}
+	}
+
+	node.Attr = append(node.Attr, html.Attribute{Key: "style", Val: strings.TrimSpace(style.String())})
+}
+
+// parseInlinableCSSRules parses the provided css and returns only the
+// rules with simple selectors (the at-rules like @media are skipped).
+func parseInlinableCSSRules(css string) []*cssRule {
+	css = cssCommentsRegex.ReplaceAllString(css, "")
+
+	var rules []*cssRule
+
+	for len(css) > 0 {
+		css = strings.TrimSpace(css)
+		if css == "" {
+			break
+		}
+
+		if css[0] == '@' {
+			css = skipCSSAtRule(css)
+			continue
+		}
+
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			break
+		}
+
+		end := strings.IndexByte(css[open:], '}')
+		if end < 0 {
+			break
+		}
+		end += open
+
+		declarations := normalizeCSSDeclarations(css[open+1 : end])
+
+		for _, selector := range strings.Split(css[:open], ",") {
+			rule := parseSimpleSelector(strings.TrimSpace(selector))
+			if rule == nil || declarations == "" {
+				continue
+			}
+
+			rule.order = len(rules)
+			rule.declarations = declarations
+			rules = append(rules, rule)
+		}
+
+		css = css[end+1:]
+	}
+
+	return rules
+}
+
+// skipCSSAtRule skips the leading at-rule (with its nested block if any)
+// and returns the remaining css.
+func skipCSSAtRule(css string) string {
+	depth := 0
+
+	for i := 0; i < len(css); i++ {
+		switch css[i] {
+		case ';':
+			if depth == 0 {
+				return css[i+1:]
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth <= 0 {
+				return css[i+1:]
+			}
+		}
+	}
+
+	return ""
+}
+
+func parseSimpleSelector(selector string) *cssRule {
+	match := simpleSelectorRegex.FindStringSubmatch(selector)
+	if match == nil || selector == "" {
+		return nil
+	}
+
+	rule := &cssRule{tag: strings.ToLower(match[1])}
+	if rule.tag != "" && rule.tag != "*" {
+		rule.specificity = 1
+	}
+
+	for _, part := range selectorPartsRegex.FindAllString(match[2], -1) {
+		if part[0] == '#' {
+			rule.ids = append(rule.ids, part[1:])
+			rule.specificity += 100
+		} else {
+			rule.classes = append(rule.classes, part[1:])
+			rule.specificity += 10
+		}
+	}
+
+	return rule
+}
+
+// normalizeCSSDeclarations trims the provided declarations block
+// and ensures that each declaration ends with a semicolon.
+func normalizeCSSDeclarations(declarations string) string {
+	var result strings.Builder
+
+	for _, d := range strings.Split(declarations, ";") {
+		d = strings.Join(strings.Fields(d), " ")
+		if d == "" {
+			continue
+		}
+		result.WriteString(d)
+		result.WriteString("; ")
+	}
+
+	return result.String()
+}
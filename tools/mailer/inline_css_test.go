@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"testing"
+)
+
+func TestInlineCSS(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			"no style elements",
+			`<p class="a">test</p>`,
+			`<p class="a">test</p>`,
+		},
+		{
+			"simple selectors with specificity and source order",
+			`<html><head><style>
+				/* comment { color: red } */
+				p { color: red; margin: 0 }
+				.a { color: blue }
+				p.a { font-weight: bold }
+				#main { color: green }
+				p { padding: 1px; }
+			</style></head><body><p id="main" class="a b">1</p><p>2</p><div class="a">3</div></body></html>`,
+			`<html><head><style>
+				/* comment { color: red } */
+				p { color: red; margin: 0 }
+				.a { color: blue }
+				p.a { font-weight: bold }
+				#main { color: green }
+				p { padding: 1px; }
+			</style></head><body><p id="main" class="a b" style="color: red; margin: 0; padding: 1px; color: blue; font-weight: bold; color: green;">1</p><p style="color: red; margin: 0; padding: 1px;">2</p><div class="a" style="color: blue;">3</div></body></html>`,
+		},
+		{
+			"comma selectors, existing style attribute and not inlinable rules",
+			`<html><head><style>
+				body, h1 { margin: 0 }
+				a:hover { color: red }
+				div p { color: red }
+				@media (max-width: 600px) { h1 { font-size: 10px } }
+				@import url("test.css");
+				* { box-sizing: border-box }
+			</style></head><body><h1 style="color:red">title</h1></body></html>`,
+			`<html style="box-sizing: border-box;"><head><style>
+				body, h1 { margin: 0 }
+				a:hover { color: red }
+				div p { color: red }
+				@media (max-width: 600px) { h1 { font-size: 10px } }
+				@import url("test.css");
+				* { box-sizing: border-box }
+			</style></head><body style="box-sizing: border-box; margin: 0;"><h1 style="box-sizing: border-box; margin: 0; color:red;">title</h1></body></html>`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := InlineCSS(s.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package mailer
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"net/mail"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/hook"
 )
 
 // Message defines a generic email message struct.
@@ -16,6 +22,65 @@ type Message struct {
 	Text        string               `json:"text"`
 	Headers     map[string]string    `json:"headers"`
 	Attachments map[string]io.Reader `json:"attachments"`
+
+	// InlineAttachments holds the message inline attachments (eg. logos)
+	// that could be referenced in the HTML body by their name, eg. <img src="cid:logo.png">.
+	InlineAttachments map[string]io.Reader `json:"inlineAttachments"`
+}
+
+// AttachFile adds the provided file as a message attachment
+// (the file OriginalName is used as attachment filename).
+//
+// Use [filesystem.NewFileFromBytes] or [filesystem.NewFileFromPath]
+// to attach a file from bytes or a local path.
+func (m *Message) AttachFile(file *filesystem.File) error {
+	data, err := readFile(file)
+	if err != nil {
+		return err
+	}
+
+	if m.Attachments == nil {
+		m.Attachments = map[string]io.Reader{}
+	}
+	m.Attachments[file.OriginalName] = bytes.NewReader(data)
+
+	return nil
+}
+
+// AttachInlineFile adds the provided file as a message inline attachment
+// that could be referenced in the HTML body with "cid:" + cid.
+//
+// If cid is empty, fallbacks to the file OriginalName.
+func (m *Message) AttachInlineFile(file *filesystem.File, cid string) error {
+	data, err := readFile(file)
+	if err != nil {
+		return err
+	}
+
+	if cid == "" {
+		cid = file.OriginalName
+	}
+
+	if m.InlineAttachments == nil {
+		m.InlineAttachments = map[string]io.Reader{}
+	}
+	m.InlineAttachments[cid] = bytes.NewReader(data)
+
+	return nil
+}
+
+func readFile(file *filesystem.File) ([]byte, error) {
+	if file == nil || file.Reader == nil {
+		return nil, fmt.Errorf("missing or invalid attachment file")
+	}
+
+	r, err := file.Reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
 }
 
 // Mailer defines a base mail client interface.
@@ -24,6 +89,58 @@ type Mailer interface {
 	Send(message *Message) error
 }
 
+// SendEvent defines the data of a mail client send hook event.
+type SendEvent struct {
+	Message *Message
+}
+
+// SendInterceptor defines a mail client that allows
+// inspecting, modifying or canceling its outgoing messages.
+type SendInterceptor interface {
+	// OnSend returns the hook that is triggered right before sending a message.
+	//
+	// Return an error from a hook handler to cancel the send or
+	// [hook.StopPropagation] to silently skip it.
+	OnSend() *hook.Hook[*SendEvent]
+}
+
+// sendHook is a helper struct that implements [SendInterceptor]
+// and could be embedded in the mail clients.
+type sendHook struct {
+	mu     sync.Mutex
+	onSend *hook.Hook[*SendEvent]
+}
+
+// OnSend implements [SendInterceptor.OnSend] interface method.
+func (h *sendHook) OnSend() *hook.Hook[*SendEvent] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.onSend == nil {
+		h.onSend = &hook.Hook[*SendEvent]{}
+	}
+
+	return h.onSend
+}
+
+// triggerSend triggers the OnSend hook and calls the send
+// function with the final message if not canceled.
+func (h *sendHook) triggerSend(m *Message, send func(m *Message) error) error {
+	return h.OnSend().Trigger(&SendEvent{Message: m}, func(e *SendEvent) error {
+		return send(e.Message)
+	})
+}
+
+// messageRecipients returns the email addresses of all message recipients (To, Cc and Bcc).
+func messageRecipients(m *Message) []string {
+	result := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	result = append(result, addressesToStrings(m.To, false)...)
+	result = append(result, addressesToStrings(m.Cc, false)...)
+	result = append(result, addressesToStrings(m.Bcc, false)...)
+
+	return result
+}
+
 // addressesToStrings converts the provided address to a list of serialized RFC 5322 strings.
 //
 // To export only the email part of mail.Address, you can set withName to false.
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestMessageAttachFileFromPath(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "test.txt")
+	if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := filesystem.NewFileFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Message{}
+
+	if err := m.AttachFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.AttachInlineFile(file, "custom_cid"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.AttachFile(nil); err == nil {
+		t.Fatal("Expected error for nil file")
+	}
+
+	data, _ := io.ReadAll(m.Attachments["test.txt"])
+	if string(data) != "test" {
+		t.Fatalf("Expected test.txt attachment, got %v", m.Attachments)
+	}
+
+	data, _ = io.ReadAll(m.InlineAttachments["custom_cid"])
+	if string(data) != "test" {
+		t.Fatalf("Expected custom_cid inline attachment, got %v", m.InlineAttachments)
+	}
+}
@@ -9,17 +9,25 @@ import (
 	"strings"
 )
 
-var _ Mailer = (*Sendmail)(nil)
+var (
+	_ Mailer          = (*Sendmail)(nil)
+	_ SendInterceptor = (*Sendmail)(nil)
+)
 
 // Sendmail implements [mailer.Mailer] interface and defines a mail
 // client that sends emails via the "sendmail" *nix command.
 //
 // This client is usually recommended only for development and testing.
 type Sendmail struct {
+	sendHook
 }
 
 // Send implements `mailer.Mailer` interface.
 func (c *Sendmail) Send(m *Message) error {
+	return c.triggerSend(m, c.send)
+}
+
+func (c *Sendmail) send(m *Message) error {
 	toAddresses := addressesToStrings(m.To, false)
 
 	headers := make(http.Header)
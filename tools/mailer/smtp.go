@@ -1,16 +1,22 @@
 package mailer
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/smtp"
+	"strconv"
 	"strings"
 
 	"github.com/domodwyer/mailyak/v3"
 	"github.com/pocketbase/pocketbase/tools/security"
 )
 
-var _ Mailer = (*SmtpClient)(nil)
+var (
+	_ Mailer          = (*SmtpClient)(nil)
+	_ SendInterceptor = (*SmtpClient)(nil)
+)
 
 const (
 	SmtpAuthPlain = "PLAIN"
@@ -39,6 +45,8 @@ func NewSmtpClient(
 // SmtpClient defines a SMTP mail client structure that implements
 // `mailer.Mailer` interface.
 type SmtpClient struct {
+	sendHook
+
 	Host     string
 	Port     int
 	Username string
@@ -54,10 +62,19 @@ type SmtpClient struct {
 	//
 	// This is required only by some SMTP servers, such as Gmail SMTP-relay.
 	LocalName string
+
+	// Dkim is an optional DKIM signing configuration.
+	//
+	// If set, the messages are DKIM signed before sending (see [DkimSign]).
+	Dkim *DkimOptions
 }
 
 // Send implements `mailer.Mailer` interface.
 func (c *SmtpClient) Send(m *Message) error {
+	return c.triggerSend(m, c.send)
+}
+
+func (c *SmtpClient) send(m *Message) error {
 	var smtpAuth smtp.Auth
 	if c.Username != "" || c.Password != "" {
 		switch c.AuthMethod {
@@ -68,13 +85,36 @@ func (c *SmtpClient) Send(m *Message) error {
 		}
 	}
 
-	// create mail instance
+	yak, err := c.newYak(m, smtpAuth)
+	if err != nil {
+		return err
+	}
+
+	if c.Dkim == nil || c.Dkim.PrivateKey == "" {
+		return yak.Send()
+	}
+
+	raw, err := yak.MimeBuf()
+	if err != nil {
+		return err
+	}
+
+	signed, err := DkimSign(raw.Bytes(), c.Dkim)
+	if err != nil {
+		return fmt.Errorf("failed to DKIM sign the message: %w", err)
+	}
+
+	return c.sendRaw(smtpAuth, m.From.Address, messageRecipients(m), signed)
+}
+
+// newYak creates a new mail instance from the provided message.
+func (c *SmtpClient) newYak(m *Message, smtpAuth smtp.Auth) (*mailyak.MailYak, error) {
 	var yak *mailyak.MailYak
 	if c.Tls {
 		var tlsErr error
 		yak, tlsErr = mailyak.NewWithTLS(fmt.Sprintf("%s:%d", c.Host, c.Port), smtpAuth, nil)
 		if tlsErr != nil {
-			return tlsErr
+			return nil, tlsErr
 		}
 	} else {
 		yak = mailyak.New(fmt.Sprintf("%s:%d", c.Host, c.Port), smtpAuth)
@@ -117,6 +157,11 @@ func (c *SmtpClient) Send(m *Message) error {
 		yak.Attach(name, data)
 	}
 
+	// add inline attachements (if any)
+	for name, data := range m.InlineAttachments {
+		yak.AttachInline(name, data)
+	}
+
 	// add custom headers (if any)
 	var hasMessageId bool
 	for k, v := range m.Headers {
@@ -136,7 +181,72 @@ func (c *SmtpClient) Send(m *Message) error {
 		}
 	}
 
-	return yak.Send()
+	return yak, nil
+}
+
+// sendRaw sends the already built raw message to the specified recipients
+// (similar to the mailyak sender, it tries to upgrade the non-TLS connections with STARTTLS).
+func (c *SmtpClient) sendRaw(auth smtp.Auth, from string, recipients []string, raw []byte) error {
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+
+	var conn net.Conn
+	var err error
+	if c.Tls {
+		conn, err = tls.Dial("tcp", addr, &tls.Config{ServerName: c.Host})
+	} else {
+		conn, err = net.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Quit()
+
+	if c.LocalName != "" {
+		if err := client.Hello(c.LocalName); err != nil {
+			return err
+		}
+	}
+
+	if !c.Tls {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: c.Host}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+
+	return w.Close()
 }
 
 // -------------------------------------------------------------------
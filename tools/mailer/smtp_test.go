@@ -1,7 +1,11 @@
 package mailer
 
 import (
+	"bytes"
+	"io"
+	"net/mail"
 	"net/smtp"
+	"strings"
 	"testing"
 )
 
@@ -162,3 +166,42 @@ func TestLoginAuthNext(t *testing.T) {
 		}
 	}
 }
+
+func TestSmtpClientInlineAttachments(t *testing.T) {
+	client := &SmtpClient{Host: "127.0.0.1", Port: 25}
+
+	yak, err := client.newYak(&Message{
+		From:    mail.Address{Address: "from@example.com"},
+		To:      []mail.Address{{Address: "to@example.com"}},
+		Subject: "Test",
+		HTML:    `<img src="cid:logo.png">`,
+		Attachments: map[string]io.Reader{
+			"test.txt": bytes.NewReader([]byte("test")),
+		},
+		InlineAttachments: map[string]io.Reader{
+			"logo.png": bytes.NewReader([]byte("logo")),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := yak.MimeBuf()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.String()
+
+	expectations := []string{
+		"Content-Disposition: attachment;",
+		`filename="test.txt"`,
+		"Content-Disposition: inline;",
+		`filename="logo.png"`,
+		"Content-ID: <logo.png>",
+	}
+	for _, e := range expectations {
+		if !strings.Contains(raw, e) {
+			t.Fatalf("Missing %q in\n%s", e, raw)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package mailer
+
+import "errors"
+
+var (
+	_ TransportMailer = (*Transports)(nil)
+	_ SendInterceptor = (*Transports)(nil)
+)
+
+// TransportMailer defines a mail client that could send
+// messages through multiple named transports.
+type TransportMailer interface {
+	Mailer
+
+	// SendWithTransport sends the provided Message through the named transport
+	// (fallbacks to the default transport if there is no such transport).
+	SendWithTransport(name string, message *Message) error
+}
+
+// Transports is a [TransportMailer] implementation that routes
+// the messages to a set of named mail clients.
+type Transports struct {
+	sendHook
+
+	// Default is the mail client used for sending the messages
+	// without (or with unknown) transport name.
+	Default Mailer
+
+	// Named holds the named transport mail clients.
+	Named map[string]Mailer
+}
+
+// Transport returns the mail client of the named transport.
+//
+// Returns the Default mail client if name is empty or there is no such named transport.
+func (t *Transports) Transport(name string) Mailer {
+	if name != "" {
+		if m, ok := t.Named[name]; ok && m != nil {
+			return m
+		}
+	}
+
+	return t.Default
+}
+
+// Send implements [Mailer.Send] interface by sending
+// the message through the default transport.
+func (t *Transports) Send(message *Message) error {
+	return t.SendWithTransport("", message)
+}
+
+// SendWithTransport implements [TransportMailer.SendWithTransport] interface.
+func (t *Transports) SendWithTransport(name string, message *Message) error {
+	m := t.Transport(name)
+	if m == nil {
+		return errors.New("missing default mail transport")
+	}
+
+	return t.triggerSend(message, m.Send)
+}
+
+// WithTransport returns a [Mailer] that sends its messages through
+// the named transport of the provided mail client.
+//
+// If the mail client is not a [TransportMailer] or name is empty,
+// the mail client is returned as it is.
+func WithTransport(m Mailer, name string) Mailer {
+	tm, ok := m.(TransportMailer)
+	if !ok || name == "" {
+		return m
+	}
+
+	return &transportMailer{mailer: tm, name: name}
+}
+
+type transportMailer struct {
+	mailer TransportMailer
+	name   string
+}
+
+// Send implements [Mailer.Send] interface.
+func (t *transportMailer) Send(message *Message) error {
+	return t.mailer.SendWithTransport(t.name, message)
+}
@@ -0,0 +1,151 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+type recordMailer struct {
+	name string
+	sent *[]string
+}
+
+func (m *recordMailer) Send(message *Message) error {
+	*m.sent = append(*m.sent, m.name+":"+message.Subject)
+	return nil
+}
+
+func TestTransportsSendWithTransport(t *testing.T) {
+	sent := []string{}
+
+	transports := &Transports{
+		Default: &recordMailer{name: "default", sent: &sent},
+		Named: map[string]Mailer{
+			"marketing": &recordMailer{name: "marketing", sent: &sent},
+			"nil":       nil,
+		},
+	}
+
+	scenarios := []struct {
+		transport string
+		expected  string
+	}{
+		{"", "default"},
+		{"marketing", "marketing"},
+		{"missing", "default"},
+		{"nil", "default"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.transport, func(t *testing.T) {
+			sent = sent[:0]
+
+			if err := transports.SendWithTransport(s.transport, &Message{Subject: "test"}); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(sent) != 1 || sent[0] != s.expected+":test" {
+				t.Fatalf("Expected the message to be sent through %q, got %v", s.expected, sent)
+			}
+		})
+	}
+
+	t.Run("Send", func(t *testing.T) {
+		sent = sent[:0]
+
+		if err := transports.Send(&Message{Subject: "test"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(sent) != 1 || sent[0] != "default:test" {
+			t.Fatalf("Expected the message to be sent through the default transport, got %v", sent)
+		}
+	})
+
+	t.Run("missing default", func(t *testing.T) {
+		empty := &Transports{}
+
+		if err := empty.SendWithTransport("missing", &Message{}); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+}
+
+func TestWithTransport(t *testing.T) {
+	sent := []string{}
+
+	plain := &recordMailer{name: "plain", sent: &sent}
+
+	transports := &Transports{
+		Default: &recordMailer{name: "default", sent: &sent},
+		Named: map[string]Mailer{
+			"marketing": &recordMailer{name: "marketing", sent: &sent},
+		},
+	}
+
+	scenarios := []struct {
+		name      string
+		client    Mailer
+		transport string
+		expected  string
+	}{
+		{"non-transport mailer", plain, "marketing", "plain"},
+		{"empty transport", transports, "", "default"},
+		{"existing transport", transports, "marketing", "marketing"},
+		{"missing transport", transports, "missing", "default"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sent = sent[:0]
+
+			if err := WithTransport(s.client, s.transport).Send(&Message{Subject: "test"}); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(sent) != 1 || sent[0] != s.expected+":test" {
+				t.Fatalf("Expected the message to be sent through %q, got %v", s.expected, sent)
+			}
+		})
+	}
+}
+
+func TestTransportsOnSend(t *testing.T) {
+	var sent []string
+
+	transports := &Transports{
+		Default: &recordMailer{name: "default", sent: &sent},
+	}
+
+	transports.OnSend().Add(func(e *SendEvent) error {
+		switch e.Message.Subject {
+		case "cancel":
+			return hook.StopPropagation
+		case "error":
+			return errors.New("test")
+		}
+
+		e.Message = &Message{Subject: e.Message.Subject + "_changed"}
+
+		return nil
+	})
+
+	if err := transports.Send(&Message{Subject: "test"}); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	if err := transports.Send(&Message{Subject: "cancel"}); err != nil {
+		t.Fatalf("Expected nil error for the canceled message, got %v", err)
+	}
+
+	if err := transports.Send(&Message{Subject: "error"}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	expected := "default:test_changed"
+	if len(sent) != 1 || sent[0] != expected {
+		t.Fatalf("Expected only %q to be sent, got %v", expected, sent)
+	}
+}
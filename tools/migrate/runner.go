@@ -153,9 +153,26 @@ func (r *Runner) Up() ([]string, error) {
 	return applied, nil
 }
 
+// Pending returns the file names of all registered but not applied migrations
+// (in the order they will be applied).
+func (r *Runner) Pending() []string {
+	pending := []string{}
+
+	for _, m := range r.migrationsList.Items() {
+		if !r.isMigrationApplied(r.db, m.File) {
+			pending = append(pending, m.File)
+		}
+	}
+
+	return pending
+}
+
 // Down reverts the last `toRevertCount` applied migrations
 // (in the order they were applied).
 //
+// All migrations are reverted in a single transaction and if any of
+// them doesn't have a Down action an error is returned and nothing is reverted.
+//
 // On success returns list with the reverted migrations file names.
 func (r *Runner) Down(toRevertCount int) ([]string, error) {
 	reverted := make([]string, 0, toRevertCount)
@@ -177,11 +194,14 @@ func (r *Runner) Down(toRevertCount int) ([]string, error) {
 					return nil
 				}
 
-				// ignore empty Down action
-				if m.Down != nil {
-					if err := m.Down(tx); err != nil {
-						return fmt.Errorf("Failed to revert migration %s: %w", m.File, err)
-					}
+				// the migration cannot be reverted without its Down action
+				// (the entire transaction is rolled back)
+				if m.Down == nil {
+					return fmt.Errorf("Failed to revert migration %s: missing down action", m.File)
+				}
+
+				if err := m.Down(tx); err != nil {
+					return fmt.Errorf("Failed to revert migration %s: %w", m.File, err)
 				}
 
 				if err := r.saveRevertedMigration(tx, m.File); err != nil {
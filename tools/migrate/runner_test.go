@@ -138,6 +138,168 @@ func TestRunnerUpAndDown(t *testing.T) {
 	}
 }
 
+func TestRunnerPending(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	l := MigrationsList{}
+	l.Register(nil, nil, "1_test")
+	l.Register(nil, nil, "2_test")
+	l.Register(nil, nil, "3_test")
+
+	r, err := NewRunner(testDB.DB, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.saveAppliedMigration(testDB, "2_test")
+
+	pending, err := json.Marshal(r.Pending())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := string(pending); v != `["1_test","3_test"]` {
+		t.Fatalf("Expected pending migrations %s, got %s", `["1_test","3_test"]`, v)
+	}
+
+	if _, err := r.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := r.Pending(); len(v) != 0 {
+		t.Fatalf("Expected no pending migrations, got %v", v)
+	}
+}
+
+func TestRunnerDownMissingAction(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	callsOrder := []string{}
+
+	l := MigrationsList{}
+	l.Register(func(db dbx.Builder) error {
+		return nil
+	}, nil, "1_test")
+	l.Register(func(db dbx.Builder) error {
+		return nil
+	}, func(db dbx.Builder) error {
+		callsOrder = append(callsOrder, "down2")
+		return nil
+	}, "2_test")
+
+	r, err := NewRunner(testDB.DB, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := r.Down(2)
+	if err == nil {
+		t.Fatal("Expected Down() to fail due to the missing 1_test down action")
+	}
+
+	if len(reverted) != 0 {
+		t.Fatalf("Expected no reverted migrations, got %v", reverted)
+	}
+
+	// the already reverted migrations should be rolled back
+	for _, file := range []string{"1_test", "2_test"} {
+		if !r.isMigrationApplied(testDB.DB, file) {
+			t.Fatalf("Expected %s to be still applied", file)
+		}
+	}
+
+	// reverting only the migration with a down action should succeed
+	reverted, err = r.Down(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reverted) != 1 || reverted[0] != "2_test" {
+		t.Fatalf("Expected only 2_test to be reverted, got %v", reverted)
+	}
+
+	if r.isMigrationApplied(testDB.DB, "2_test") {
+		t.Fatal("Expected 2_test to be reverted")
+	}
+}
+
+func TestRunnerUpAndDownSchemaChange(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	l := MigrationsList{}
+	l.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery("CREATE TABLE {{demo}} (id TEXT PRIMARY KEY)").Execute()
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("demo").Execute()
+		return err
+	}, "1_test")
+	l.Register(func(db dbx.Builder) error {
+		_, err := db.AddColumn("demo", "title", "TEXT").Execute()
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropColumn("demo", "title").Execute()
+		return err
+	}, "2_test")
+
+	r, err := NewRunner(testDB.DB, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns := func() []string {
+		result := []string{}
+		testDB.NewQuery("SELECT name FROM pragma_table_info('demo')").Column(&result)
+		return result
+	}
+
+	if _, err := r.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cols := columns(); len(cols) != 2 {
+		t.Fatalf("Expected the demo table to have 2 columns after Up(), got %v", cols)
+	}
+
+	if _, err := r.Down(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if cols := columns(); len(cols) != 1 || cols[0] != "id" {
+		t.Fatalf("Expected the demo table to have only the id column after Down(1), got %v", cols)
+	}
+
+	if _, err := r.Down(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if cols := columns(); len(cols) != 0 {
+		t.Fatalf("Expected the demo table to be dropped, got %v", cols)
+	}
+
+	for _, file := range []string{"1_test", "2_test"} {
+		if r.isMigrationApplied(testDB.DB, file) {
+			t.Fatalf("Expected %s to be reverted", file)
+		}
+	}
+}
+
 func TestHistorySync(t *testing.T) {
 	testDB, err := createTestDB()
 	if err != nil {
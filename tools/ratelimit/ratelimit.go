@@ -0,0 +1,137 @@
+// Package ratelimit implements a concurrent safe in-memory token bucket rate limiter.
+//
+// Example:
+//
+//	// max 10 requests per minute per key
+//	limiter := ratelimit.New(10, time.Minute)
+//
+//	if ok, retryAfter := limiter.Allow("127.0.0.1"); !ok {
+//		// try again after retryAfter
+//	}
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultCleanupInterval is the minimal interval between two cleanups
+// of the fully refilled (aka. inactive) buckets.
+const DefaultCleanupInterval = 1 * time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter is an in-memory token bucket rate limiter.
+//
+// Each key has its own bucket that holds up to maxRequests tokens
+// and it is gradually refilled with maxRequests tokens per interval.
+type Limiter struct {
+	mux         sync.Mutex
+	buckets     map[string]*bucket
+	maxTokens   float64
+	refillRate  float64 // tokens per nanosecond
+	lastCleanup time.Time
+
+	// now is the current time provider (could be replaced in tests)
+	now func() time.Time
+}
+
+// New creates a new Limiter that allows bursts of up to maxRequests
+// and refills maxRequests tokens per interval for each key.
+//
+// maxRequests and interval must be positive (otherwise the limiter rejects all requests).
+func New(maxRequests int, interval time.Duration) *Limiter {
+	l := &Limiter{
+		buckets:   map[string]*bucket{},
+		maxTokens: float64(maxRequests),
+		now:       time.Now,
+	}
+
+	if maxRequests > 0 && interval > 0 {
+		l.refillRate = float64(maxRequests) / float64(interval)
+	}
+
+	return l
+}
+
+// Allow consumes a single token from the key bucket and reports whether it was allowed.
+//
+// If not allowed, it also returns the duration after which the next token will be available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+
+	l.cleanup(now)
+
+	if l.refillRate <= 0 {
+		return false, 0
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.maxTokens, updated: now}
+		l.buckets[key] = b
+	} else {
+		l.refill(b, now)
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	retryAfter := time.Duration(math.Ceil((1 - b.tokens) / l.refillRate))
+
+	return false, retryAfter
+}
+
+// Reset removes the key bucket (aka. fully refills it).
+func (l *Limiter) Reset(key string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	delete(l.buckets, key)
+}
+
+// Total returns the number of the currently tracked buckets.
+func (l *Limiter) Total() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return len(l.buckets)
+}
+
+// refill updates the bucket tokens based on the elapsed time since its last update.
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens = math.Min(l.maxTokens, b.tokens+float64(elapsed)*l.refillRate)
+	b.updated = now
+}
+
+// cleanup removes the fully refilled buckets since they are
+// no different from a new bucket (runs at most once per DefaultCleanupInterval).
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < DefaultCleanupInterval {
+		return
+	}
+
+	l.lastCleanup = now
+
+	for key, b := range l.buckets {
+		l.refill(b, now)
+
+		if b.tokens >= l.maxTokens {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestLimiter(maxRequests int, interval time.Duration) (*Limiter, *testClock) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	l := New(maxRequests, interval)
+	l.now = clock.Now
+	l.lastCleanup = clock.now
+
+	return l, clock
+}
+
+func TestLimiterBurst(t *testing.T) {
+	t.Parallel()
+
+	l, _ := newTestLimiter(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("Expected request %d to be allowed", i)
+		}
+	}
+
+	ok, retryAfter := l.Allow("a")
+	if ok {
+		t.Fatal("Expected the 4th request to be rejected")
+	}
+	if retryAfter != 20*time.Second {
+		t.Fatalf("Expected retryAfter %v, got %v", 20*time.Second, retryAfter)
+	}
+
+	// the other keys shouldn't be affected
+	if ok, _ := l.Allow("b"); !ok {
+		t.Fatal("Expected a different key request to be allowed")
+	}
+}
+
+func TestLimiterRefill(t *testing.T) {
+	t.Parallel()
+
+	l, clock := newTestLimiter(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		l.Allow("a")
+	}
+
+	// partial refill
+	clock.Add(10 * time.Second)
+	ok, retryAfter := l.Allow("a")
+	if ok {
+		t.Fatal("Expected the request to be rejected before a whole token is refilled")
+	}
+	if retryAfter != 10*time.Second {
+		t.Fatalf("Expected retryAfter %v, got %v", 10*time.Second, retryAfter)
+	}
+
+	// single token refill
+	clock.Add(10 * time.Second)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("Expected the request to be allowed after a token refill")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("Expected the next request to be rejected")
+	}
+
+	// full window refill (shouldn't exceed the max burst)
+	clock.Add(10 * time.Minute)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("Expected request %d to be allowed after the window refill", i)
+		}
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("Expected the request to be rejected after the refilled burst")
+	}
+}
+
+func TestLimiterReset(t *testing.T) {
+	t.Parallel()
+
+	l, _ := newTestLimiter(1, time.Minute)
+
+	l.Allow("a")
+
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("Expected the request to be rejected")
+	}
+
+	l.Reset("a")
+
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("Expected the request to be allowed after reset")
+	}
+}
+
+func TestLimiterCleanup(t *testing.T) {
+	t.Parallel()
+
+	l, clock := newTestLimiter(2, 10*time.Second)
+
+	l.Allow("a")
+	l.Allow("b")
+	l.Allow("b")
+
+	if total := l.Total(); total != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", total)
+	}
+
+	// "a" is fully refilled but "b" is not
+	// (no cleanup yet since the cleanup interval hasn't passed)
+	clock.Add(5 * time.Second)
+	l.Allow("c")
+	if total := l.Total(); total != 3 {
+		t.Fatalf("Expected 3 buckets, got %d", total)
+	}
+
+	clock.Add(DefaultCleanupInterval)
+	l.Allow("b")
+	if total := l.Total(); total != 1 {
+		t.Fatalf("Expected only the new b bucket to remain, got %d", total)
+	}
+}
+
+func TestLimiterInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		maxRequests int
+		interval    time.Duration
+	}{
+		{0, time.Minute},
+		{-1, time.Minute},
+		{1, 0},
+	}
+
+	for i, s := range scenarios {
+		l := New(s.maxRequests, s.interval)
+
+		if ok, _ := l.Allow("a"); ok {
+			t.Fatalf("[%d] Expected the request to be rejected", i)
+		}
+	}
+}
@@ -14,6 +14,9 @@ import (
 
 // MultipartJsonKey is the key for the special multipart/form-data
 // handling allowing reading serialized json payload without normalization.
+//
+// The json payload object keys are merged with the other multipart
+// values, with the regular multipart fields taking precedence.
 const MultipartJsonKey string = "@jsonPayload"
 
 // MultiBinder is similar to [echo.DefaultBinder] but uses slightly different
@@ -0,0 +1,392 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+)
+
+// MaxAggregateGroups specifies the max allowed number of
+// groups returned by a single aggregate query.
+const MaxAggregateGroups int = 500
+
+// url aggregate query params
+const (
+	AggregateSelectQueryParam  string = "select"
+	AggregateGroupByQueryParam string = "groupBy"
+)
+
+// supported aggregate functions
+const (
+	AggregateCount string = "count"
+	AggregateSum   string = "sum"
+	AggregateAvg   string = "avg"
+	AggregateMin   string = "min"
+	AggregateMax   string = "max"
+)
+
+var aggregateFieldRegex = regexp.MustCompile(`^(\w+)\(\s*([\w\.]*)\s*\)$`)
+
+var groupByFieldRegex = regexp.MustCompile(`^[\w\.]+$`)
+
+// AggregateField defines a single aggregate function expression
+// (eg. "count()", "sum(amount)").
+type AggregateField struct {
+	Func  string `json:"func"`
+	Field string `json:"field"`
+}
+
+// Key returns the normalized aggregate expression that is used
+// as key of the aggregate value in the result rows (eg. "sum(amount)").
+func (f AggregateField) Key() string {
+	return f.Func + "(" + f.Field + ")"
+}
+
+// BuildExpr resolves the aggregate field into a valid db select expression.
+func (f AggregateField) BuildExpr(fieldResolver FieldResolver) (string, error) {
+	if f.Func == AggregateCount {
+		if f.Field != "" {
+			return "", errors.New("the count function doesn't accept arguments")
+		}
+		return "COUNT(*)", nil
+	}
+
+	identifier, err := resolveAggregateIdentifier(f.Field, fieldResolver)
+	if err != nil {
+		return "", fmt.Errorf("invalid aggregate field %q - %w", f.Key(), err)
+	}
+
+	return strings.ToUpper(f.Func) + "(" + identifier + ")", nil
+}
+
+// ParseAggregateFields parses the provided comma separated string
+// expression into a slice of AggregateFields.
+//
+// Only the count, sum, avg, min and max functions are supported,
+// where count doesn't accept any arguments and the others require
+// a single field name.
+//
+// Example:
+//
+//	fields, err := search.ParseAggregateFields("count(),sum(amount),max(created)")
+func ParseAggregateFields(str string) ([]AggregateField, error) {
+	result := []AggregateField{}
+	existing := map[string]struct{}{}
+
+	for _, part := range splitTopLevel(str, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		matches := aggregateFieldRegex.FindStringSubmatch(part)
+		if len(matches) != 3 {
+			return nil, fmt.Errorf("invalid aggregate expression %q", part)
+		}
+
+		field := AggregateField{
+			Func:  strings.ToLower(matches[1]),
+			Field: matches[2],
+		}
+
+		switch field.Func {
+		case AggregateCount:
+			if field.Field != "" {
+				return nil, fmt.Errorf("invalid aggregate expression %q - the count function doesn't accept arguments", part)
+			}
+		case AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+			if field.Field == "" {
+				return nil, fmt.Errorf("invalid aggregate expression %q - missing field argument", part)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported aggregate function %q", matches[1])
+		}
+
+		if _, ok := existing[field.Key()]; ok {
+			continue // already added
+		}
+		existing[field.Key()] = struct{}{}
+
+		result = append(result, field)
+	}
+
+	return result, nil
+}
+
+// ParseGroupByFromString parses the provided comma separated string
+// expression into a slice of unique group by field names.
+func ParseGroupByFromString(str string) ([]string, error) {
+	result := []string{}
+	existing := map[string]struct{}{}
+
+	for _, part := range strings.Split(str, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if !groupByFieldRegex.MatchString(part) {
+			return nil, fmt.Errorf("invalid group by field %q", part)
+		}
+
+		if _, ok := existing[part]; ok {
+			continue // already added
+		}
+		existing[part] = struct{}{}
+
+		result = append(result, part)
+	}
+
+	return result, nil
+}
+
+// resolveAggregateIdentifier resolves the provided field name into
+// a plain column identifier.
+//
+// Fields that resolve to bind parameters or to multi-match relation
+// expressions are not allowed since they cannot be aggregated.
+func resolveAggregateIdentifier(field string, fieldResolver FieldResolver) (string, error) {
+	result, err := fieldResolver.Resolve(field)
+	if err != nil {
+		return "", err
+	}
+
+	if len(result.Params) > 0 ||
+		result.MultiMatchSubQuery != nil ||
+		result.Identifier == "" ||
+		strings.ToLower(result.Identifier) == "null" {
+		return "", errors.New("the field must resolve to a single column")
+	}
+
+	return result.Identifier, nil
+}
+
+// Aggregator represents a single configured aggregate query
+// (eg. count, sum, etc.) over the filtered search items.
+type Aggregator struct {
+	fieldResolver FieldResolver
+	query         *dbx.SelectQuery
+	uniqueCol     string
+	fields        []AggregateField
+	groupBy       []string
+	filter        []FilterData
+}
+
+// NewAggregator creates and returns a new search Aggregator.
+//
+// Example:
+//
+//	baseQuery := db.Select("*").From("orders")
+//	fieldResolver := search.NewSimpleFieldResolver("status", "amount")
+//
+//	rows, err := search.NewAggregator(fieldResolver).
+//		Query(baseQuery).
+//		ParseAndExec("select=count(),sum(amount)&groupBy=status&filter=amount>0")
+func NewAggregator(fieldResolver FieldResolver) *Aggregator {
+	return &Aggregator{
+		fieldResolver: fieldResolver,
+		uniqueCol:     "id",
+		fields:        []AggregateField{},
+		groupBy:       []string{},
+		filter:        []FilterData{},
+	}
+}
+
+// Query sets the base query that will be used to aggregate the search items.
+func (s *Aggregator) Query(query *dbx.SelectQuery) *Aggregator {
+	s.query = query
+	return s
+}
+
+// UniqueCol sets the name of the unique column (default to "id") that
+// is used to deduplicate the filtered items before their aggregation
+// (eg. when the filter fields resolver has added joins).
+func (s *Aggregator) UniqueCol(name string) *Aggregator {
+	s.uniqueCol = name
+	return s
+}
+
+// Fields sets the aggregator aggregate fields.
+func (s *Aggregator) Fields(fields []AggregateField) *Aggregator {
+	s.fields = fields
+	return s
+}
+
+// GroupBy sets the aggregator group by fields.
+func (s *Aggregator) GroupBy(fields []string) *Aggregator {
+	s.groupBy = fields
+	return s
+}
+
+// AddFilter appends the provided FilterData to the existing aggregator's filter field.
+func (s *Aggregator) AddFilter(filter FilterData) *Aggregator {
+	if filter != "" {
+		s.filter = append(s.filter, filter)
+	}
+	return s
+}
+
+// Parse parses the aggregate query parameters from the provided query
+// string and assigns the found fields to the current aggregator.
+func (s *Aggregator) Parse(urlQuery string) error {
+	params, err := url.ParseQuery(urlQuery)
+	if err != nil {
+		return err
+	}
+
+	if raw := params.Get(AggregateSelectQueryParam); raw != "" {
+		fields, err := ParseAggregateFields(raw)
+		if err != nil {
+			return err
+		}
+		s.Fields(fields)
+	}
+
+	if raw := params.Get(AggregateGroupByQueryParam); raw != "" {
+		groupBy, err := ParseGroupByFromString(raw)
+		if err != nil {
+			return err
+		}
+		s.GroupBy(groupBy)
+	}
+
+	if raw := params.Get(FilterQueryParam); raw != "" {
+		s.AddFilter(FilterData(raw))
+	}
+
+	return nil
+}
+
+// Exec executes the aggregate query and returns the result rows.
+//
+// Each row contains the group by field values and the aggregate values
+// keyed by their normalized expression (see [AggregateField.Key]).
+//
+// The rows are sorted by the group by fields in ascending order and
+// an error is returned if their number exceeds [MaxAggregateGroups].
+func (s *Aggregator) Exec() ([]map[string]any, error) {
+	if s.query == nil {
+		return nil, errors.New("query is not set")
+	}
+
+	if len(s.fields) == 0 {
+		return nil, errors.New("at least one aggregate field is required")
+	}
+
+	uniqueCol := s.uniqueCol
+	if info := s.query.Info(); len(info.From) > 0 {
+		uniqueCol = info.From[0] + "." + uniqueCol
+	}
+
+	// filter the unique items ids in a subquery
+	// (the filters could add joins that would duplicate the aggregated rows)
+	filterQuery := *s.query // shallow clone
+	for _, f := range s.filter {
+		expr, err := f.BuildExpr(s.fieldResolver)
+		if err != nil {
+			return nil, err
+		}
+		if expr != nil {
+			filterQuery.AndWhere(expr)
+		}
+	}
+
+	if err := s.fieldResolver.UpdateQuery(&filterQuery); err != nil {
+		return nil, err
+	}
+
+	filterSubquery := filterQuery.Distinct(true).
+		Select("[[" + uniqueCol + "]]").
+		OrderBy( /* reset */ ).
+		Build()
+
+	selects := make([]string, 0, len(s.groupBy)+len(s.fields))
+	groupIdentifiers := make([]string, 0, len(s.groupBy))
+
+	for _, name := range s.groupBy {
+		identifier, err := resolveAggregateIdentifier(name, s.fieldResolver)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group by field %q - %w", name, err)
+		}
+		groupIdentifiers = append(groupIdentifiers, identifier)
+		selects = append(selects, identifier)
+	}
+
+	for _, field := range s.fields {
+		expr, err := field.BuildExpr(s.fieldResolver)
+		if err != nil {
+			return nil, err
+		}
+		selects = append(selects, expr)
+	}
+
+	aggregateQuery := *s.query // shallow clone
+	aggregateQuery.Distinct(false).
+		Select(selects...).
+		AndWhere(dbx.NewExp(
+			"[["+uniqueCol+"]] IN ("+filterSubquery.SQL()+")",
+			filterSubquery.Params(),
+		)).
+		GroupBy(groupIdentifiers...).
+		OrderBy(groupIdentifiers...).
+		Limit(int64(MaxAggregateGroups + 1))
+
+	rows, err := aggregateQuery.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []map[string]any{}
+
+	for rows.Next() {
+		if len(result) >= MaxAggregateGroups {
+			return nil, fmt.Errorf("the aggregate query returns more than %d groups", MaxAggregateGroups)
+		}
+
+		values := make([]any, len(selects))
+		pointers := make([]any, len(selects))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(values))
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+
+			if i < len(s.groupBy) {
+				row[s.groupBy[i]] = v
+			} else {
+				row[s.fields[i-len(s.groupBy)].Key()] = v
+			}
+		}
+
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ParseAndExec is a short convenient method to trigger both
+// Parse() and Exec() in a single call.
+func (s *Aggregator) ParseAndExec(urlQuery string) ([]map[string]any, error) {
+	if err := s.Parse(urlQuery); err != nil {
+		return nil, err
+	}
+
+	return s.Exec()
+}
@@ -0,0 +1,190 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAggregateFields(t *testing.T) {
+	scenarios := []struct {
+		value       string
+		expectError bool
+		expected    string
+	}{
+		{"", false, `[]`},
+		{" , ", false, `[]`},
+		{"count()", false, `[{"func":"count","field":""}]`},
+		{"COUNT( ), sum(a), avg( b.c ),min(a),max(a),sum(a)", false, `[{"func":"count","field":""},{"func":"sum","field":"a"},{"func":"avg","field":"b.c"},{"func":"min","field":"a"},{"func":"max","field":"a"}]`},
+		{"count(a)", true, ``},
+		{"sum()", true, ``},
+		{"total(a)", true, ``},
+		{"sum(a", true, ``},
+		{"sum(a) + 1", true, ``},
+		{"sum(a,b)", true, ``},
+		{"sum(a);DROP TABLE test", true, ``},
+		{"sum((select 1))", true, ``},
+		{"a", true, ``},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.value, func(t *testing.T) {
+			result, err := ParseAggregateFields(s.value)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			encoded, _ := json.Marshal(result)
+			if string(encoded) != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, encoded)
+			}
+		})
+	}
+}
+
+func TestAggregateFieldKey(t *testing.T) {
+	scenarios := []struct {
+		field    AggregateField
+		expected string
+	}{
+		{AggregateField{Func: AggregateCount}, "count()"},
+		{AggregateField{Func: AggregateSum, Field: "a.b"}, "sum(a.b)"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expected, func(t *testing.T) {
+			if key := s.field.Key(); key != s.expected {
+				t.Fatalf("Expected key %q, got %q", s.expected, key)
+			}
+		})
+	}
+}
+
+func TestParseGroupByFromString(t *testing.T) {
+	scenarios := []struct {
+		value       string
+		expectError bool
+		expected    string
+	}{
+		{"", false, `[]`},
+		{"a, b.c ,a,", false, `["a","b.c"]`},
+		{"a b", true, ``},
+		{"a(b)", true, ``},
+		{"a;b", true, ``},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.value, func(t *testing.T) {
+			result, err := ParseGroupByFromString(s.value)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			encoded, _ := json.Marshal(result)
+			if string(encoded) != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, encoded)
+			}
+		})
+	}
+}
+
+func TestAggregatorParseAndExec(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	query := testDB.Select("*").From("test").OrderBy("id ASC")
+
+	scenarios := []struct {
+		name        string
+		queryString string
+		expectError bool
+		expected    string
+	}{
+		{
+			"missing aggregate fields",
+			"groupBy=test2",
+			true,
+			``,
+		},
+		{
+			"invalid aggregate field",
+			"select=count(),sum(unknown)",
+			true,
+			``,
+		},
+		{
+			"invalid group by field",
+			"select=count()&groupBy=unknown",
+			true,
+			``,
+		},
+		{
+			"invalid filter",
+			"select=count()&filter=unknown>1",
+			true,
+			``,
+		},
+		{
+			"without group by",
+			"select=count(),sum(test1),avg(test1),min(test2),max(test2)",
+			false,
+			`[{"avg(test1)":1.5,"count()":2,"max(test2)":"test2.2","min(test2)":"test2.1","sum(test1)":3}]`,
+		},
+		{
+			"without group by and matching items",
+			"select=count(),sum(test1)&filter=test1>10",
+			false,
+			`[{"count()":0,"sum(test1)":null}]`,
+		},
+		{
+			"with group by and filter",
+			"select=count(),sum(test1)&groupBy=test3,test2&filter=test1>1",
+			false,
+			`[{"count()":1,"sum(test1)":2,"test2":"test2.2","test3":""}]`,
+		},
+		{
+			"with group by",
+			"select=count()&groupBy=test2",
+			false,
+			`[{"count()":1,"test2":"test2.1"},{"count()":1,"test2":"test2.2"}]`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			testDB.CalledQueries = []string{} // reset
+
+			result, err := NewAggregator(&testFieldResolver{}).
+				Query(query).
+				ParseAndExec(s.queryString)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			encoded, _ := json.Marshal(result)
+			if string(encoded) != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s\nqueries: %v", s.expected, encoded, testDB.CalledQueries)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package search
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// cursor pagination helper column aliases
+const (
+	cursorValueAlias string = "__cursor_v"
+	cursorTypeAlias  string = "__cursor_t"
+)
+
+// ErrInvalidCursor is returned when the provided pagination cursor
+// is malformed or was generated for a different sort.
+var ErrInvalidCursor = errors.New("invalid or expired pagination cursor")
+
+// cursorKey defines a single keyset column used for the cursor pagination.
+type cursorKey struct {
+	expr      string
+	direction string
+}
+
+// cursorValue defines a single encoded keyset column value.
+//
+// The SQLite storage type of the value is stored together with its
+// string representation so that the value could be bound back with
+// the same type when comparing against expressions without affinity.
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+// cursorData defines the decoded pagination cursor structure.
+type cursorData struct {
+	// Sort holds the normalized sort signature for which the cursor was generated.
+	Sort string `json:"s"`
+
+	// Values holds the keyset values of the last item from
+	// the previous page (including the unique tie-breaker column).
+	Values []cursorValue `json:"v"`
+}
+
+// encodeCursor encodes the provided cursor data into an opaque url safe string.
+func encodeCursor(data *cursorData) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor decodes and validates the provided opaque cursor string
+// against the expected sort signature and number of keyset columns.
+func decodeCursor(cursor string, sortSignature string, totalKeys int) (*cursorData, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	data := &cursorData{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if data.Sort != sortSignature || len(data.Values) != totalKeys {
+		return nil, ErrInvalidCursor
+	}
+
+	return data, nil
+}
+
+// cursorSortSignature returns a normalized string representation
+// of the provided sort fields (eg. "-created,title").
+func cursorSortSignature(sort []SortField) string {
+	parts := make([]string, len(sort))
+
+	for i, s := range sort {
+		if s.Direction == SortDesc {
+			parts[i] = "-" + s.Name
+		} else {
+			parts[i] = s.Name
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// bindValue converts the encoded cursor value back to its original storage type.
+func (v cursorValue) bindValue() (any, error) {
+	switch v.Type {
+	case "null":
+		return nil, nil
+	case "integer":
+		return strconv.ParseInt(v.Value, 10, 64)
+	case "real":
+		return strconv.ParseFloat(v.Value, 64)
+	case "text", "blob":
+		return v.Value, nil
+	default:
+		return nil, ErrInvalidCursor
+	}
+}
+
+// buildKeysetExpr builds the keyset WHERE expression that matches
+// all rows positioned after the provided values for the specified keys.
+//
+// For example, for keys (a ASC, id ASC) it generates the equivalent of
+// `(a, id) > (v1, v2)` expanded as `a > v1 OR (a = v1 AND id > v2)`
+// so that mixed sort directions and NULL values are also supported
+// (SQLite considers NULLs smaller than any other value).
+func buildKeysetExpr(keys []cursorKey, values []cursorValue) (dbx.Expression, error) {
+	if len(keys) != len(values) {
+		return nil, ErrInvalidCursor
+	}
+
+	params := dbx.Params{}
+	placeholders := make([]string, len(keys))
+	bindValues := make([]any, len(keys))
+
+	for i, v := range values {
+		bindValue, err := v.bindValue()
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		bindValues[i] = bindValue
+
+		if bindValue != nil {
+			placeholders[i] = "c" + security.PseudorandomString(5)
+			params[placeholders[i]] = bindValue
+		}
+	}
+
+	disjunctions := make([]string, 0, len(keys))
+
+	for i, key := range keys {
+		var after string
+
+		switch {
+		case key.direction == SortDesc && bindValues[i] == nil:
+			// nothing comes after NULL in descending order
+			continue
+		case key.direction == SortDesc:
+			after = fmt.Sprintf("(%s < {:%s} OR %s IS NULL)", key.expr, placeholders[i], key.expr)
+		case bindValues[i] == nil:
+			after = fmt.Sprintf("%s IS NOT NULL", key.expr)
+		default:
+			after = fmt.Sprintf("%s > {:%s}", key.expr, placeholders[i])
+		}
+
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			if bindValues[j] == nil {
+				parts = append(parts, fmt.Sprintf("%s IS NULL", keys[j].expr))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s = {:%s}", keys[j].expr, placeholders[j]))
+			}
+		}
+		parts = append(parts, after)
+
+		disjunctions = append(disjunctions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(disjunctions) == 0 {
+		return dbx.NewExp("0=1"), nil
+	}
+
+	return dbx.NewExp(strings.Join(disjunctions, " OR "), params), nil
+}
+
+// cursorSelectColumns returns the select columns that extract the
+// keyset values (and their storage types) of the specified keys.
+func cursorSelectColumns(keys []cursorKey) []string {
+	cols := make([]string, 0, len(keys)*2)
+
+	for i, key := range keys {
+		cols = append(
+			cols,
+			fmt.Sprintf("%s AS [[%s%d]]", key.expr, cursorValueAlias, i),
+			fmt.Sprintf("typeof(%s) AS [[%s%d]]", key.expr, cursorTypeAlias, i),
+		)
+	}
+
+	return cols
+}
+
+// cursorValuesFromRow extracts the keyset values of a row fetched
+// with the cursorSelectColumns.
+func cursorValuesFromRow(row dbx.NullStringMap, totalKeys int) []cursorValue {
+	values := make([]cursorValue, totalKeys)
+
+	for i := 0; i < totalKeys; i++ {
+		values[i] = cursorValue{
+			Type:  row[fmt.Sprintf("%s%d", cursorTypeAlias, i)].String,
+			Value: row[fmt.Sprintf("%s%d", cursorValueAlias, i)].String,
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCursorSortSignature(t *testing.T) {
+	scenarios := []struct {
+		sort     []SortField
+		expected string
+	}{
+		{nil, ""},
+		{[]SortField{{"a", SortAsc}}, "a"},
+		{[]SortField{{"a", SortDesc}, {"b", SortAsc}, {"c", ""}}, "-a,b,c"},
+	}
+
+	for i, s := range scenarios {
+		result := cursorSortSignature(s.sort)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, s.expected, result)
+		}
+	}
+}
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	data := &cursorData{
+		Sort: "-a,b",
+		Values: []cursorValue{
+			{"integer", "1"},
+			{"null", ""},
+			{"text", "test"},
+		},
+	}
+
+	cursor, err := encodeCursor(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		cursor      string
+		sort        string
+		totalKeys   int
+		expectError bool
+	}{
+		{"empty cursor", "", "-a,b", 3, true},
+		{"malformed cursor", "invalid", "-a,b", 3, true},
+		{"sort mismatch", cursor, "a,b", 3, true},
+		{"keys mismatch", cursor, "-a,b", 2, true},
+		{"valid cursor", cursor, "-a,b", 3, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := decodeCursor(s.cursor, s.sort, s.totalKeys)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			encodedExpected, _ := json.Marshal(data)
+			encodedResult, _ := json.Marshal(result)
+			if string(encodedExpected) != string(encodedResult) {
+				t.Fatalf("Expected %s, got %s", encodedExpected, encodedResult)
+			}
+		})
+	}
+}
+
+func TestCursorValueBindValue(t *testing.T) {
+	scenarios := []struct {
+		value       cursorValue
+		expectError bool
+		expected    any
+	}{
+		{cursorValue{"null", ""}, false, nil},
+		{cursorValue{"integer", "123"}, false, int64(123)},
+		{cursorValue{"integer", "abc"}, true, nil},
+		{cursorValue{"real", "1.5"}, false, 1.5},
+		{cursorValue{"text", "abc"}, false, "abc"},
+		{cursorValue{"blob", "abc"}, false, "abc"},
+		{cursorValue{"unknown", "abc"}, true, nil},
+	}
+
+	for i, s := range scenarios {
+		result, err := s.value.bindValue()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if !hasErr && result != s.expected {
+			t.Errorf("(%d) Expected %v (%T), got %v (%T)", i, s.expected, s.expected, result, result)
+		}
+	}
+}
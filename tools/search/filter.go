@@ -19,6 +19,16 @@ import (
 // The filter string can also contain dbx placeholder parameters (eg. "title = {:name}"),
 // that will be safely replaced and properly quoted inplace with the placeholderReplacements values.
 //
+// Identifier keys with special characters (eg. dots in a json object key)
+// could be quoted with backticks (eg. "data.`a.b`.c = 1").
+//
+// Text and number list literals could be used with the =, !=, ?= and ?!= operators
+// to match any of the list items (eg. "id ?= ['a', 'b']" is the same as "id IN ('a', 'b')").
+//
+// The "any" operators could be used to match the individual items of the json array
+// resolved identifiers (eg. "tags ?~ 'go'") and the number of the json array items could be
+// filtered or sorted with the "length" function (eg. "length(tags) > 2").
+//
 // Example:
 //
 //	var filter FilterData = "id = null || (name = 'test' && status = true) || (total >= {:min} && total <= {:max})"
@@ -26,6 +36,12 @@ import (
 //	expr, err := filter.BuildExpr(resolver, dbx.Params{"min": 100, "max": 200})
 type FilterData string
 
+// case-insensitive identifier modifiers (eg. "email:lower")
+const (
+	lowerModifier string = "lower"
+	upperModifier string = "upper"
+)
+
 // parsedFilterData holds a cache with previously parsed filter data expressions
 // (initialized with some preallocated empty data map)
 var parsedFilterData = store.New(make(map[string][]fexpr.ExprGroup, 50))
@@ -64,6 +80,20 @@ func (f FilterData) BuildExpr(
 		}
 	}
 
+	// encode the backtick quoted identifier keys (eg. "data.`a.b`")
+	// since they are not supported by the fexpr identifier grammar
+	raw, err := encodeQuotedKeys(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// encode the list literals (eg. `id = ["a", "b"]`)
+	// since they are not supported by the fexpr grammar
+	raw, err = encodeListLiterals(raw)
+	if err != nil {
+		return nil, err
+	}
+
 	if parsedFilterData.Has(raw) {
 		return buildParsedFilterExpr(parsedFilterData.Get(raw), fieldResolver)
 	}
@@ -129,15 +159,54 @@ func buildParsedFilterExpr(data []fexpr.ExprGroup, fieldResolver FieldResolver)
 func resolveTokenizedExpr(expr fexpr.Expr, fieldResolver FieldResolver) (dbx.Expression, error) {
 	lResult, lErr := resolveToken(expr.Left, fieldResolver)
 	if lErr != nil || lResult.Identifier == "" {
-		return nil, fmt.Errorf("invalid left operand %q - %v", expr.Left.Literal, lErr)
+		return nil, fmt.Errorf("invalid left operand %q - %w", expr.Left.Literal, lErr)
 	}
 
 	rResult, rErr := resolveToken(expr.Right, fieldResolver)
 	if rErr != nil || rResult.Identifier == "" {
-		return nil, fmt.Errorf("invalid right operand %q - %v", expr.Right.Literal, rErr)
+		return nil, fmt.Errorf("invalid right operand %q - %w", expr.Right.Literal, rErr)
+	}
+
+	// convert the literal operands to the format of the other operand (if needed)
+	if err := normalizeLiteralOperand(lResult, expr.Right, rResult); err != nil {
+		return nil, fmt.Errorf("invalid right operand %q - %w", expr.Right.Literal, err)
+	}
+	if err := normalizeLiteralOperand(rResult, expr.Left, lResult); err != nil {
+		return nil, fmt.Errorf("invalid left operand %q - %w", expr.Left.Literal, err)
+	}
+
+	// apply the case modifier of one of the operands to the other
+	// so that `email:lower = "Foo@x.com"` could match "foo@x.com"
+	if lResult.caseFunc != "" && rResult.caseFunc == "" {
+		applyCaseFunc(rResult, lResult.caseFunc)
+	} else if rResult.caseFunc != "" && lResult.caseFunc == "" {
+		applyCaseFunc(lResult, rResult.caseFunc)
+	}
+
+	result, err := buildResolversExpr(lResult, expr.Op, rResult)
+	if err != nil {
+		return nil, err
+	}
+
+	// prefilter the geoDistance(...) upper bound comparisons with a bounding box
+	if bbox := resolveGeoBoundingBox(expr, lResult, rResult); bbox != nil {
+		result = dbx.Enclose(dbx.And(bbox, result))
+	}
+
+	return result, nil
+}
+
+// resolveGeoBoundingBox returns a bounding box prefilter expression
+// for the "geoDistance(...) < number" and "number > geoDistance(...)" expressions.
+func resolveGeoBoundingBox(expr fexpr.Expr, left *ResolverResult, right *ResolverResult) dbx.Expression {
+	switch {
+	case left.geo != nil && expr.Right.Type == fexpr.TokenNumber && (expr.Op == fexpr.SignLt || expr.Op == fexpr.SignLte):
+		return geoBoundingBoxExpr(left.geo, cast.ToFloat64(expr.Right.Literal))
+	case right.geo != nil && expr.Left.Type == fexpr.TokenNumber && (expr.Op == fexpr.SignGt || expr.Op == fexpr.SignGte):
+		return geoBoundingBoxExpr(right.geo, cast.ToFloat64(expr.Left.Literal))
 	}
 
-	return buildResolversExpr(lResult, expr.Op, rResult)
+	return nil
 }
 
 func buildResolversExpr(
@@ -145,35 +214,64 @@ func buildResolversExpr(
 	op fexpr.SignOp,
 	right *ResolverResult,
 ) (dbx.Expression, error) {
+	// match the individual json array items (eg. `tags ?~ "go"`)
+	if left.JsonArray && left.MultiMatchSubQuery == nil && isAnyMatchOp(op) {
+		return resolveJsonArrayExpr(left, op, right)
+	}
+
 	var expr dbx.Expression
 
-	switch op {
-	case fexpr.SignEq, fexpr.SignAnyEq:
-		expr = resolveEqualExpr(true, left, right)
-	case fexpr.SignNeq, fexpr.SignAnyNeq:
-		expr = resolveEqualExpr(false, left, right)
-	case fexpr.SignLike, fexpr.SignAnyLike:
-		// the right side is a column and therefor wrap it with "%" for contains like behavior
-		if len(right.Params) == 0 {
-			expr = dbx.NewExp(fmt.Sprintf("%s LIKE ('%%' || %s || '%%') ESCAPE '\\'", left.Identifier, right.Identifier), left.Params)
-		} else {
-			expr = dbx.NewExp(fmt.Sprintf("%s LIKE %s ESCAPE '\\'", left.Identifier, right.Identifier), mergeParams(left.Params, wrapLikeParams(right.Params)))
+	if left.list != nil || right.list != nil {
+		if left.list != nil && right.list != nil {
+			return nil, errors.New("list literals cannot be compared with each other")
 		}
-	case fexpr.SignNlike, fexpr.SignAnyNlike:
-		// the right side is a column and therefor wrap it with "%" for not-contains like behavior
-		if len(right.Params) == 0 {
-			expr = dbx.NewExp(fmt.Sprintf("%s NOT LIKE ('%%' || %s || '%%') ESCAPE '\\'", left.Identifier, right.Identifier), left.Params)
-		} else {
-			expr = dbx.NewExp(fmt.Sprintf("%s NOT LIKE %s ESCAPE '\\'", left.Identifier, right.Identifier), mergeParams(left.Params, wrapLikeParams(right.Params)))
+
+		// normalize the list literal to always be the right operand
+		if left.list != nil {
+			left, right = right, left
+		}
+
+		var err error
+		expr, err = resolveListExpr(left, op, right)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		switch op {
+		case fexpr.SignEq, fexpr.SignAnyEq:
+			expr = resolveEqualExpr(true, left, right)
+		case fexpr.SignNeq, fexpr.SignAnyNeq:
+			expr = resolveEqualExpr(false, left, right)
+		case fexpr.SignLike, fexpr.SignAnyLike:
+			// the right side is a column and therefor wrap it with "%" for contains like behavior
+			if len(right.Params) == 0 {
+				expr = dbx.NewExp(fmt.Sprintf("%s LIKE ('%%' || %s || '%%') ESCAPE '\\'", left.Identifier, right.Identifier), left.Params)
+			} else {
+				expr = dbx.NewExp(fmt.Sprintf("%s LIKE %s ESCAPE '\\'", left.Identifier, right.Identifier), mergeParams(left.Params, wrapLikeParams(right.Params)))
+			}
+		case fexpr.SignNlike, fexpr.SignAnyNlike:
+			// NULL values (eg. missing json path) are treated as empty strings
+			// since `NULL NOT LIKE x` evaluates to NULL instead of TRUE
+			leftIdentifier := left.Identifier
+			if left.NoCoalesce {
+				leftIdentifier = "COALESCE(" + leftIdentifier + ", '')"
+			}
+
+			// the right side is a column and therefor wrap it with "%" for not-contains like behavior
+			if len(right.Params) == 0 {
+				expr = dbx.NewExp(fmt.Sprintf("%s NOT LIKE ('%%' || %s || '%%') ESCAPE '\\'", leftIdentifier, right.Identifier), left.Params)
+			} else {
+				expr = dbx.NewExp(fmt.Sprintf("%s NOT LIKE %s ESCAPE '\\'", leftIdentifier, right.Identifier), mergeParams(left.Params, wrapLikeParams(right.Params)))
+			}
+		case fexpr.SignLt, fexpr.SignAnyLt:
+			expr = dbx.NewExp(fmt.Sprintf("%s < %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
+		case fexpr.SignLte, fexpr.SignAnyLte:
+			expr = dbx.NewExp(fmt.Sprintf("%s <= %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
+		case fexpr.SignGt, fexpr.SignAnyGt:
+			expr = dbx.NewExp(fmt.Sprintf("%s > %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
+		case fexpr.SignGte, fexpr.SignAnyGte:
+			expr = dbx.NewExp(fmt.Sprintf("%s >= %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
 		}
-	case fexpr.SignLt, fexpr.SignAnyLt:
-		expr = dbx.NewExp(fmt.Sprintf("%s < %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
-	case fexpr.SignLte, fexpr.SignAnyLte:
-		expr = dbx.NewExp(fmt.Sprintf("%s <= %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
-	case fexpr.SignGt, fexpr.SignAnyGt:
-		expr = dbx.NewExp(fmt.Sprintf("%s > %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
-	case fexpr.SignGte, fexpr.SignAnyGte:
-		expr = dbx.NewExp(fmt.Sprintf("%s >= %s", left.Identifier, right.Identifier), mergeParams(left.Params, right.Params))
 	}
 
 	if expr == nil {
@@ -193,6 +291,7 @@ func buildResolversExpr(
 		} else if left.MultiMatchSubQuery != nil {
 			mm := &manyVsOneExpr{
 				noCoalesce:   left.NoCoalesce,
+				caseFunc:     left.caseFunc,
 				subQuery:     left.MultiMatchSubQuery,
 				op:           op,
 				otherOperand: right,
@@ -202,6 +301,7 @@ func buildResolversExpr(
 		} else if right.MultiMatchSubQuery != nil {
 			mm := &manyVsOneExpr{
 				noCoalesce:   right.NoCoalesce,
+				caseFunc:     right.caseFunc,
 				subQuery:     right.MultiMatchSubQuery,
 				op:           op,
 				otherOperand: left,
@@ -244,7 +344,9 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 
 		// custom resolver
 		// ---
-		result, err := fieldResolver.Resolve(token.Literal)
+		name, caseFunc := splitCaseModifier(token.Literal)
+
+		result, err := fieldResolver.Resolve(name)
 
 		if err != nil || result.Identifier == "" {
 			m := map[string]string{
@@ -255,12 +357,16 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 				// if `false` field is missing, treat `false` identifier as FALSE token
 				"false": "0",
 			}
-			if v, ok := m[strings.ToLower(token.Literal)]; ok {
+			if v, ok := m[strings.ToLower(name)]; ok {
 				return &ResolverResult{Identifier: v}, nil
 			}
 			return nil, err
 		}
 
+		if caseFunc != "" {
+			applyCaseFunc(result, caseFunc)
+		}
+
 		return result, err
 	case fexpr.TokenText:
 		placeholder := "t" + security.PseudorandomString(5)
@@ -276,11 +382,124 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 			Identifier: "{:" + placeholder + "}",
 			Params:     dbx.Params{placeholder: cast.ToFloat64(token.Literal)},
 		}, nil
+	case fexpr.TokenFunction:
+		if token.Literal == listLiteralFunc {
+			args, _ := token.Meta.([]fexpr.Token)
+			return resolveListLiteral(args)
+		}
+
+		fn, ok := tokenFunctions[token.Literal]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %q", token.Literal)
+		}
+
+		args, _ := token.Meta.([]fexpr.Token)
+
+		return fn(func(arg fexpr.Token) (*ResolverResult, error) {
+			return resolveToken(arg, fieldResolver)
+		}, args...)
 	}
 
 	return nil, errors.New("unresolvable token type")
 }
 
+// normalizeLiteralOperand converts the text and list literal values of
+// the literal operand with the NormalizeLiteral function of the identifier operand.
+func normalizeLiteralOperand(identifier *ResolverResult, literalToken fexpr.Token, literal *ResolverResult) error {
+	if identifier.NormalizeLiteral == nil {
+		return nil
+	}
+
+	if literal.list != nil {
+		for i, item := range literal.list {
+			str, ok := item.(string)
+			if !ok {
+				continue
+			}
+
+			normalized, err := identifier.NormalizeLiteral(str)
+			if err != nil {
+				return err
+			}
+
+			literal.list[i] = normalized
+		}
+
+		raw, err := json.Marshal(literal.list)
+		if err != nil {
+			return err
+		}
+
+		for placeholder := range literal.Params {
+			literal.Params[placeholder] = string(raw)
+		}
+
+		return nil
+	}
+
+	if literalToken.Type != fexpr.TokenText {
+		return nil
+	}
+
+	for placeholder, value := range literal.Params {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		normalized, err := identifier.NormalizeLiteral(str)
+		if err != nil {
+			return err
+		}
+
+		literal.Params[placeholder] = normalized
+	}
+
+	return nil
+}
+
+// splitCaseModifier extracts the optional trailing ":lower" or ":upper"
+// modifier from the provided identifier and returns the identifier
+// without it together with its corresponding SQL function.
+//
+// The case modifier is always the last one and could be combined
+// with other resolver specific modifiers (eg. "tags:each:lower").
+func splitCaseModifier(identifier string) (string, string) {
+	idx := strings.LastIndex(identifier, ":")
+	if idx <= 0 {
+		return identifier, ""
+	}
+
+	switch identifier[idx+1:] {
+	case lowerModifier:
+		return identifier[:idx], "LOWER"
+	case upperModifier:
+		return identifier[:idx], "UPPER"
+	}
+
+	return identifier, ""
+}
+
+// applyCaseFunc wraps the resolved identifier with the specified
+// SQL case function (LOWER or UPPER).
+//
+// Note that the case function is applied on the db side also for the
+// literal operands to ensure consistent case folding with the column values.
+func applyCaseFunc(result *ResolverResult, caseFunc string) {
+	if caseFunc == "" || isEmptyIdentifier(result) {
+		return
+	}
+
+	// the list literal items are wrapped on expression build
+	if result.list != nil {
+		result.caseFunc = caseFunc
+		return
+	}
+
+	result.caseFunc = caseFunc
+	result.Identifier = caseFunc + "(" + result.Identifier + ")"
+}
+
 // Resolves = and != expressions in an attempt to minimize the COALESCE
 // usage and to gracefully handle null vs empty string normalizations.
 //
@@ -589,20 +808,22 @@ func (e *manyVsManyExpr) Build(db *dbx.DB, params dbx.Params) string {
 	lAlias := "__ml" + security.PseudorandomString(5)
 	rAlias := "__mr" + security.PseudorandomString(5)
 
-	whereExpr, buildErr := buildResolversExpr(
-		&ResolverResult{
-			NoCoalesce: e.left.NoCoalesce,
-			Identifier: "[[" + lAlias + ".multiMatchValue]]",
-		},
-		e.op,
-		&ResolverResult{
-			NoCoalesce: e.right.NoCoalesce,
-			Identifier: "[[" + rAlias + ".multiMatchValue]]",
-			// note: the AfterBuild needs to be handled only once and it
-			// doesn't matter whether it is applied on the left or right subquery operand
-			AfterBuild: multiMatchAfterBuildFunc(e.op, lAlias, rAlias),
-		},
-	)
+	lResult := &ResolverResult{
+		NoCoalesce: e.left.NoCoalesce,
+		Identifier: "[[" + lAlias + ".multiMatchValue]]",
+	}
+	applyCaseFunc(lResult, e.left.caseFunc)
+
+	rResult := &ResolverResult{
+		NoCoalesce: e.right.NoCoalesce,
+		Identifier: "[[" + rAlias + ".multiMatchValue]]",
+		// note: the AfterBuild needs to be handled only once and it
+		// doesn't matter whether it is applied on the left or right subquery operand
+		AfterBuild: multiMatchAfterBuildFunc(e.op, lAlias, rAlias),
+	}
+	applyCaseFunc(rResult, e.right.caseFunc)
+
+	whereExpr, buildErr := buildResolversExpr(lResult, e.op, rResult)
 
 	if buildErr != nil {
 		return "0=1"
@@ -633,6 +854,7 @@ type manyVsOneExpr struct {
 	op           fexpr.SignOp
 	inverse      bool
 	noCoalesce   bool
+	caseFunc     string
 }
 
 // Build converts the expression into a SQL fragment.
@@ -650,10 +872,13 @@ func (e *manyVsOneExpr) Build(db *dbx.DB, params dbx.Params) string {
 		Identifier: "[[" + alias + ".multiMatchValue]]",
 		AfterBuild: multiMatchAfterBuildFunc(e.op, alias),
 	}
+	applyCaseFunc(r1, e.caseFunc)
 
 	r2 := &ResolverResult{
 		Identifier: e.otherOperand.Identifier,
 		Params:     e.otherOperand.Params,
+		list:       e.otherOperand.list,
+		caseFunc:   e.otherOperand.caseFunc,
 	}
 
 	var whereExpr dbx.Expression
@@ -3,7 +3,9 @@ package search_test
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -99,6 +101,42 @@ func TestFilterDataBuildExpr(t *testing.T) {
 			false,
 			"(JSON_EXTRACT([[test5]], '$.a') IS JSON_EXTRACT([[test5]], '$.b') OR JSON_EXTRACT([[test5]], '$.c') IS NOT JSON_EXTRACT([[test5]], '$.d'))",
 		},
+		{
+			"unknown case modifier",
+			"test1:unknown = 'a'",
+			true,
+			"",
+		},
+		{
+			"case modifiers with text operands",
+			"test1:lower = 'Abc' || test1:lower != 'Abc' || test2:upper ~ 'Abc' || test2:upper !~ 'Abc'",
+			false,
+			"(LOWER([[test1]]) = LOWER({:TEST}) OR LOWER([[test1]]) IS NOT LOWER({:TEST}) OR UPPER([[test2]]) LIKE UPPER({:TEST}) ESCAPE '\\' OR UPPER([[test2]]) NOT LIKE UPPER({:TEST}) ESCAPE '\\')",
+		},
+		{
+			"case modifier on the right operand",
+			"'Abc' = test1:lower && test2 = test1:lower",
+			false,
+			"(LOWER({:TEST}) = LOWER([[test1]]) AND COALESCE(LOWER([[test2]]), '') = COALESCE(LOWER([[test1]]), ''))",
+		},
+		{
+			"case modifiers on both operands",
+			"test1:lower = test2:upper",
+			false,
+			"COALESCE(LOWER([[test1]]), '') = COALESCE(UPPER([[test2]]), '')",
+		},
+		{
+			"case modifier with null and empty string",
+			"test1:lower = null && test2:upper != ''",
+			false,
+			"((LOWER([[test1]]) = '' OR LOWER([[test1]]) IS NULL) AND (UPPER([[test2]]) IS NOT '' AND UPPER([[test2]]) IS NOT NULL))",
+		},
+		{
+			"case modifier with nested json",
+			"test5.a:lower = 'Abc'",
+			false,
+			"LOWER(JSON_EXTRACT([[test5]], '$.a')) IS LOWER({:TEST})",
+		},
 		{
 			"macros",
 			`
@@ -138,6 +176,108 @@ func TestFilterDataBuildExpr(t *testing.T) {
 			false,
 			"((COALESCE([[test1]], '') = COALESCE([[test2]], '') OR COALESCE([[test2]], '') IS NOT COALESCE([[test3]], '')) AND ([[test2]] LIKE {:TEST} ESCAPE '\\' OR [[test2]] NOT LIKE {:TEST} ESCAPE '\\') AND {:TEST} LIKE ('%' || [[test1]] || '%') ESCAPE '\\' AND {:TEST} NOT LIKE ('%' || [[test2]] || '%') ESCAPE '\\' AND [[test3]] > {:TEST} AND [[test3]] >= {:TEST} AND [[test3]] <= {:TEST} AND {:TEST} < {:TEST})",
 		},
+		{
+			"backtick quoted identifier keys",
+			"test5.`a.b`.c = 'x`y' && test5.`a b` = \"`\" // `comment",
+			false,
+			`(JSON_EXTRACT([[test5]], '$."a.b".c') IS {:TEST} AND JSON_EXTRACT([[test5]], '$."a b"') IS {:TEST})`,
+		},
+		{
+			"unterminated backtick quoted identifier key",
+			"test5.`a.b = 1",
+			true,
+			"",
+		},
+		{
+			"invalid backtick quoted identifier key",
+			"test5.`a'b` = 1",
+			true,
+			"",
+		},
+		{
+			"empty backtick quoted identifier key",
+			"test5.`` = 1",
+			true,
+			"",
+		},
+		{
+			"list literals",
+			"test1 = ['a', 1] && test2 != [\"b\"] || test3 ?= ['c'] || test3 ?!= ['d']",
+			false,
+			"([[test1]] IN ({:TEST}, {:TEST}) AND COALESCE([[test2]], '') NOT IN ({:TEST}) OR [[test3]] IN ({:TEST}) OR COALESCE([[test3]], '') NOT IN ({:TEST}))",
+		},
+		{
+			"list literal as left operand",
+			"['a', 'b'] = test1",
+			false,
+			"[[test1]] IN ({:TEST}, {:TEST})",
+		},
+		{
+			"list literal with empty item",
+			"test1 = ['', 'a']",
+			false,
+			"COALESCE([[test1]], '') IN ({:TEST}, {:TEST})",
+		},
+		{
+			"list literal with case modifier",
+			"test1:lower = ['A', 'B']",
+			false,
+			"LOWER([[test1]]) IN (LOWER({:TEST}), LOWER({:TEST}))",
+		},
+		{
+			"brackets in text literals and comments",
+			"test1 = '[a]' // [b",
+			false,
+			"[[test1]] = {:TEST}",
+		},
+		{
+			"empty list literal",
+			"test1 = []",
+			true,
+			"",
+		},
+		{
+			"empty list literal with whitespaces",
+			"test1 = [ ]",
+			true,
+			"",
+		},
+		{
+			"nested list literal",
+			"test1 = [[1]]",
+			true,
+			"",
+		},
+		{
+			"unterminated list literal",
+			"test1 = [1, 2",
+			true,
+			"",
+		},
+		{
+			"unexpected list literal closing bracket",
+			"test1 = 1]",
+			true,
+			"",
+		},
+		{
+			"list literal with unsupported operator",
+			"test1 > [1, 2]",
+			true,
+			"",
+		},
+		{
+			"list literal with identifier item",
+			"test1 = [test2]",
+			true,
+			"",
+		},
+		{
+			"list literal vs list literal",
+			"[1] = [1]",
+			true,
+			"",
+		},
 	}
 
 	for _, s := range scenarios {
@@ -239,6 +379,76 @@ func TestFilterDataBuildExprWithParams(t *testing.T) {
 	}
 }
 
+func TestFilterDataBuildExprLargeList(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	db := dbx.NewFromDB(sqlDB, "sqlite")
+	defer db.Close()
+
+	// more than the default sqlite max variables number (32766)
+	total := 40000
+
+	_, err = db.NewQuery(fmt.Sprintf(`
+		CREATE TABLE items (id TEXT PRIMARY KEY, num INTEGER);
+		WITH RECURSIVE seq(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM seq WHERE x < %d)
+		INSERT INTO items (id, num) SELECT 'id' || x, x FROM seq;
+	`, total)).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, total)
+	for i := range ids {
+		ids[i] = strconv.Quote(fmt.Sprintf("id%d", i+1))
+	}
+	list := "[" + strings.Join(ids, ",") + "]"
+
+	resolver := search.NewSimpleFieldResolver("id", "num")
+
+	scenarios := []struct {
+		filter      string
+		expectCount int
+		expectJoin  string
+	}{
+		{"id = " + list + " && num != [5, 6]", total - 2, " OR "},
+		{"id != " + list, 0, " AND "},
+		{"id = " + list + " && id != ['id1']", total - 1, " OR "},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rawSql := expr.Build(db, dbx.Params{})
+
+			expectedChunks := total / 100
+			if chunks := strings.Count(rawSql, "json_each("); chunks != expectedChunks {
+				t.Fatalf("Expected %d json_each chunks, got %d", expectedChunks, chunks)
+			}
+
+			if joins := strings.Count(rawSql, "))"+s.expectJoin); joins < expectedChunks-1 {
+				t.Fatalf("Expected the chunks to be joined with %q, got %d joins", s.expectJoin, joins)
+			}
+
+			var count int
+			err = db.Select("count(*)").From("items").Where(expr).Row(&count)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count != s.expectCount {
+				t.Fatalf("Expected %d items, got %d", s.expectCount, count)
+			}
+		})
+	}
+}
+
 func TestLikeParamsWrapping(t *testing.T) {
 	// create a dummy db
 	sqlDB, err := sql.Open("sqlite", "file::memory:?cache=shared")
@@ -303,3 +513,31 @@ func TestLikeParamsWrapping(t *testing.T) {
 		t.Fatalf("Expected query \n%s, \ngot \n%s", expectedQuery, calledQueries[0])
 	}
 }
+
+func TestDecodeQuotedKey(t *testing.T) {
+	scenarios := []struct {
+		part        string
+		expectKey   string
+		expectValid bool
+	}{
+		{"", "", false},
+		{"abc", "", false},
+		{"_pbq_", "", false},
+		{"_pbq_xyz", "", false},
+		{"_pbq_612e62", "a.b", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.part, func(t *testing.T) {
+			key, ok := search.DecodeQuotedKey(s.part)
+
+			if ok != s.expectValid {
+				t.Fatalf("Expected valid %v, got %v", s.expectValid, ok)
+			}
+
+			if key != s.expectKey {
+				t.Fatalf("Expected key %q, got %q", s.expectKey, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// jsonArrayLength resolves the "length" filter function to
+// the number of items of a json array operand, eg.:
+//
+//	length(tags) > 2
+//
+// Empty, NULL and non-array values have 0 length.
+func jsonArrayLength(resolveArg func(arg fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("length: expected 1 argument, got %d", len(args))
+	}
+
+	if args[0].Type != fexpr.TokenIdentifier {
+		return nil, errors.New("length: the argument must be an identifier")
+	}
+
+	r, err := resolveArg(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("length: invalid argument %q - %w", args[0].Literal, err)
+	}
+	if r.Identifier == "" || r.MultiMatchSubQuery != nil {
+		return nil, fmt.Errorf("length: invalid or multi-value argument %q", args[0].Literal)
+	}
+
+	return &ResolverResult{
+		NoCoalesce: true,
+		Identifier: fmt.Sprintf(
+			"(CASE WHEN json_valid(%[1]s) THEN json_array_length(%[1]s) ELSE 0 END)",
+			r.Identifier,
+		),
+		Params: r.Params,
+	}, nil
+}
+
+// resolveJsonArrayExpr builds an "any item" match expression
+// for the provided json array operand (eg. `tags ?~ "go"`).
+//
+// The expression is checked against each array item with EXISTS + json_each
+// and fallbacks to the regular single value comparison in case the
+// operand value is not a json array.
+func resolveJsonArrayExpr(array *ResolverResult, op fexpr.SignOp, other *ResolverResult) (dbx.Expression, error) {
+	alias := "__je" + security.PseudorandomString(5)
+
+	// the after build callbacks are applied once to the final expression
+	otherCopy := *other
+	otherCopy.AfterBuild = nil
+
+	itemExpr, err := buildResolversExpr(&ResolverResult{
+		Identifier: "[[" + alias + ".value]]",
+	}, op, &otherCopy)
+	if err != nil {
+		return nil, err
+	}
+
+	arrayCopy := *array
+	arrayCopy.JsonArray = false
+	arrayCopy.AfterBuild = nil
+
+	plainExpr, err := buildResolversExpr(&arrayCopy, op, &otherCopy)
+	if err != nil {
+		return nil, err
+	}
+
+	var expr dbx.Expression = &jsonArrayExpr{
+		identifier: array.Identifier,
+		params:     array.Params,
+		alias:      alias,
+		item:       itemExpr,
+		fallback:   plainExpr,
+	}
+
+	if array.AfterBuild != nil {
+		expr = array.AfterBuild(expr)
+	}
+
+	if other.AfterBuild != nil {
+		expr = other.AfterBuild(expr)
+	}
+
+	return expr, nil
+}
+
+// -------------------------------------------------------------------
+
+var _ dbx.Expression = (*jsonArrayExpr)(nil)
+
+// jsonArrayExpr defines an expression that matches at least
+// one item of a json array identifier.
+type jsonArrayExpr struct {
+	identifier string
+	params     dbx.Params
+	alias      string
+	item       dbx.Expression
+	fallback   dbx.Expression
+}
+
+// Build converts the expression into a SQL fragment.
+//
+// Implements [dbx.Expression] interface.
+func (e *jsonArrayExpr) Build(db *dbx.DB, params dbx.Params) string {
+	for k, v := range e.params {
+		params[k] = v
+	}
+
+	return fmt.Sprintf(
+		"(CASE WHEN (CASE WHEN json_valid(%[1]s) THEN json_type(%[1]s) END) = 'array' THEN EXISTS (SELECT 1 FROM json_each(%[1]s) {{%[2]s}} WHERE %[3]s) ELSE %[4]s END)",
+		e.identifier,
+		e.alias,
+		e.item.Build(db, params),
+		e.fallback.Build(db, params),
+	)
+}
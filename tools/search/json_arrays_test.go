@@ -0,0 +1,124 @@
+package search_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// jsonArrayTestResolver is a SimpleFieldResolver that marks
+// the "tags" and "nums" identifiers as json arrays.
+type jsonArrayTestResolver struct {
+	*search.SimpleFieldResolver
+}
+
+func (r *jsonArrayTestResolver) Resolve(field string) (*search.ResolverResult, error) {
+	result, err := r.SimpleFieldResolver.Resolve(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if field == "tags" || field == "nums" {
+		result.JsonArray = true
+	}
+
+	return result, nil
+}
+
+func TestFilterDataBuildExprJsonArrays(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	db := dbx.NewFromDB(sqlDB, "sqlite")
+	defer db.Close()
+
+	_, err = db.NewQuery(`
+		CREATE TABLE items (id TEXT PRIMARY KEY, tags JSON DEFAULT NULL, nums JSON DEFAULT NULL);
+		INSERT INTO items (id, tags, nums) VALUES
+			('a', '["go","js"]', '[1,2,3]'),
+			('b', '["Golang"]',  '[10]'),
+			('c', '[]',          '[]'),
+			('d', 'go',          '5'),
+			('e', NULL,          NULL),
+			('f', '{"go":1}',    '{"a":1}');
+	`).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := &jsonArrayTestResolver{search.NewSimpleFieldResolver("id", "tags", "nums")}
+
+	scenarios := []struct {
+		filter      string
+		expectError bool
+		expectIds   []string
+	}{
+		// contains
+		{"tags ?= 'go'", false, []string{"a", "d"}},
+		{"tags ?~ 'go'", false, []string{"a", "b", "d", "f"}},
+		{"tags:lower ?~ 'GOLANG'", false, []string{"b"}},
+		{"tags ?!= 'go'", false, []string{"a", "b", "e", "f"}},
+		{"tags ?!~ 'go'", false, []string{"a"}},
+		{"tags ?= ['js', 'golang']", false, []string{"a"}},
+		{"nums ?= 2", false, []string{"a"}},
+		{"nums ?> 5", false, []string{"b", "f"}},
+		{"nums ?>= 5", false, []string{"b", "d", "f"}},
+		{"nums ?<= 1", false, []string{"a"}},
+		{"'go' ?= tags", false, []string{"d"}},
+		{"tags = 'go'", false, []string{"d"}},
+
+		// length
+		{"length(tags) = 0", false, []string{"c", "d", "e", "f"}},
+		{"length(tags) > 1", false, []string{"a"}},
+		{"length(nums) >= 1 && nums ?> 2", false, []string{"a", "b"}},
+		{"length(tags, nums) > 1", true, nil},
+		{"length('a') > 1", true, nil},
+		{"length(unknown) > 1", true, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			ids := []string{}
+			err = db.Select("id").From("items").Where(expr).OrderBy("id").Column(&ids)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(ids, ",") != strings.Join(s.expectIds, ",") {
+				t.Fatalf("Expected ids %v, got %v", s.expectIds, ids)
+			}
+		})
+	}
+}
+
+func TestSortFieldBuildExprJsonArrayLength(t *testing.T) {
+	resolver := search.NewSimpleFieldResolver("tags")
+
+	sortField := search.SortField{Name: "length(tags)", Direction: search.SortDesc}
+
+	result, err := sortField.BuildExpr(resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "(CASE WHEN json_valid([[tags]]) THEN json_array_length([[tags]]) ELSE 0 END) DESC"
+	if result != expected {
+		t.Fatalf("Expected %q, got %q", expected, result)
+	}
+}
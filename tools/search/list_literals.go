@@ -0,0 +1,239 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// listLiteralFunc is the name of the internal function token
+// that the list literals (eg. `["a", "b"]`) are encoded to.
+const listLiteralFunc = "_pbl_"
+
+// listLiteralChunkSize is the max number of list items that are
+// bound as separate query parameters (eg. `id IN ({:a}, {:b})`).
+//
+// Larger lists are split into chunks of the same size, where each chunk
+// is bound as a single json array parameter and the chunk expressions are
+// combined with OR (or with AND for the negated match), aka. the number
+// of the bound parameters never exceeds the db variables limit.
+const listLiteralChunkSize = 100
+
+// encodeListLiterals replaces the square brackets list literals
+// (eg. `id = ["a", "b"]`) outside of the text literals and comments
+// with their fexpr compatible internal function form.
+func encodeListLiterals(raw string) (string, error) {
+	if !strings.ContainsAny(raw, "[]") {
+		return raw, nil // nothing to encode
+	}
+
+	var result strings.Builder
+	result.Grow(len(raw) + 10)
+
+	var quote rune   // the active text literal quote
+	var escaped bool // whether the previous text literal char was backslash
+	var comment bool // whether a single line comment is active
+	var list bool    // whether a list literal is active
+	var empty bool   // whether the active list literal has no items so far
+
+	rs := []rune(raw)
+
+	for i := 0; i < len(rs); i++ {
+		ch := rs[i]
+
+		switch {
+		case comment:
+			if ch == '\n' {
+				comment = false
+			}
+			result.WriteRune(ch)
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if ch == '\\' {
+				escaped = true
+			} else if ch == quote {
+				quote = 0
+			}
+			result.WriteRune(ch)
+		case ch == '\'' || ch == '"':
+			quote = ch
+			empty = false
+			result.WriteRune(ch)
+		case ch == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			comment = true
+			result.WriteRune(ch)
+		case ch == '[':
+			if list {
+				return "", errors.New("nested list literals are not supported")
+			}
+			list = true
+			empty = true
+			result.WriteString(listLiteralFunc)
+			result.WriteRune('(')
+		case ch == ']':
+			if !list {
+				return "", errors.New("unexpected list literal closing bracket")
+			}
+			if empty {
+				return "", errors.New("empty list literals are not allowed")
+			}
+			list = false
+			result.WriteRune(')')
+		default:
+			if list && ch != ' ' && ch != '\t' && ch != '\n' {
+				empty = false
+			}
+			result.WriteRune(ch)
+		}
+	}
+
+	if list {
+		return "", errors.New("unterminated list literal")
+	}
+
+	return result.String(), nil
+}
+
+// resolveListLiteral resolves the provided list literal function token arguments.
+//
+// Only text and number list items are allowed.
+func resolveListLiteral(args []fexpr.Token) (*ResolverResult, error) {
+	if len(args) == 0 {
+		return nil, errors.New("empty list literals are not allowed")
+	}
+
+	items := make([]any, len(args))
+	for i, arg := range args {
+		switch arg.Type {
+		case fexpr.TokenText:
+			items[i] = arg.Literal
+		case fexpr.TokenNumber:
+			items[i] = cast.ToFloat64(arg.Literal)
+		default:
+			return nil, fmt.Errorf("invalid list item %q (only text and number values are allowed)", arg.Literal)
+		}
+	}
+
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholder := "t" + security.PseudorandomString(5)
+
+	return &ResolverResult{
+		Identifier: "{:" + placeholder + "}",
+		Params:     dbx.Params{placeholder: string(raw)},
+		list:       items,
+	}, nil
+}
+
+// resolveListExpr builds an IN (or NOT IN) expression
+// for the provided operand and list literal operand.
+//
+// The list literals could be used only with the =, !=, ?= and ?!= operators.
+func resolveListExpr(operand *ResolverResult, op fexpr.SignOp, list *ResolverResult) (dbx.Expression, error) {
+	var negate bool
+
+	switch op {
+	case fexpr.SignEq, fexpr.SignAnyEq:
+	case fexpr.SignNeq, fexpr.SignAnyNeq:
+		negate = true
+	default:
+		return nil, fmt.Errorf("the %q operator is not supported with list literals", op)
+	}
+
+	var hasEmptyItem bool
+	for _, item := range list.list {
+		if item == "" {
+			hasEmptyItem = true
+			break
+		}
+	}
+
+	// NULL values are treated as empty strings
+	// (see also resolveEqualExpr)
+	identifier := operand.Identifier
+	inOp := "IN"
+	if negate {
+		inOp = "NOT IN"
+	}
+	if !operand.NoCoalesce && (negate || hasEmptyItem) {
+		identifier = "COALESCE(" + identifier + ", '')"
+	}
+
+	wrapValue := func(value string) string {
+		if list.caseFunc == "" {
+			return value
+		}
+		return list.caseFunc + "(" + value + ")"
+	}
+
+	params := mergeParams(operand.Params)
+
+	// derive the item placeholders from the list literal one
+	// so that the expression could be rebuilt with the same params
+	basePlaceholder := strings.TrimSuffix(strings.TrimPrefix(list.Identifier, "{:"), "}")
+
+	// small list
+	// ---
+	if len(list.list) <= listLiteralChunkSize {
+		placeholders := make([]string, len(list.list))
+		for i, item := range list.list {
+			placeholder := fmt.Sprintf("%s_%d", basePlaceholder, i)
+			placeholders[i] = wrapValue("{:" + placeholder + "}")
+			params[placeholder] = item
+		}
+
+		sql := fmt.Sprintf("%s %s (%s)", identifier, inOp, strings.Join(placeholders, ", "))
+
+		if operand.NoCoalesce && negate {
+			sql = fmt.Sprintf("(%s IS NULL OR %s)", identifier, sql)
+		}
+
+		return dbx.NewExp(sql, params), nil
+	}
+
+	// large list (chunked)
+	// ---
+	chunks := make([]string, 0, len(list.list)/listLiteralChunkSize+1)
+	for start := 0; start < len(list.list); start += listLiteralChunkSize {
+		end := min(start+listLiteralChunkSize, len(list.list))
+
+		raw, err := json.Marshal(list.list[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		placeholder := fmt.Sprintf("%s_c%d", basePlaceholder, start/listLiteralChunkSize)
+		params[placeholder] = string(raw)
+
+		chunks = append(chunks, fmt.Sprintf(
+			"%s %s (SELECT %s FROM json_each({:%s}))",
+			identifier,
+			inOp,
+			wrapValue("[[value]]"),
+			placeholder,
+		))
+	}
+
+	join := " OR "
+	if negate {
+		join = " AND "
+	}
+
+	sql := "(" + strings.Join(chunks, join) + ")"
+
+	if operand.NoCoalesce && negate {
+		sql = fmt.Sprintf("(%s IS NULL OR %s)", identifier, sql)
+	}
+
+	return dbx.NewExp(sql, params), nil
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/pocketbase/dbx"
 	"golang.org/x/sync/errgroup"
@@ -23,6 +24,7 @@ const (
 	SortQueryParam      string = "sort"
 	FilterQueryParam    string = "filter"
 	SkipTotalQueryParam string = "skipTotal"
+	CursorQueryParam    string = "cursor"
 )
 
 // Result defines the returned search result structure.
@@ -32,6 +34,10 @@ type Result struct {
 	TotalItems int `json:"totalItems"`
 	TotalPages int `json:"totalPages"`
 	Items      any `json:"items"`
+
+	// NextCursor is the opaque cursor of the next result page
+	// (available only for the cursor paginated results with more items).
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // Provider represents a single configured search provider instance.
@@ -39,6 +45,8 @@ type Provider struct {
 	fieldResolver FieldResolver
 	query         *dbx.SelectQuery
 	skipTotal     bool
+	useCursor     bool
+	cursor        string
 	countCol      string
 	page          int
 	perPage       int
@@ -83,7 +91,9 @@ func (s *Provider) SkipTotal(skipTotal bool) *Provider {
 // CountCol allows changing the default column (id) that is used
 // to generate the COUNT SQL query statement.
 //
-// This field is ignored if skipTotal is true.
+// The same column is also used as unique tie-breaker for the cursor pagination.
+//
+// This field is ignored for the COUNT query if skipTotal is true.
 func (s *Provider) CountCol(name string) *Provider {
 	s.countCol = name
 	return s
@@ -97,6 +107,19 @@ func (s *Provider) Page(page int) *Provider {
 	return s
 }
 
+// Cursor enables the keyset (aka. cursor) pagination mode and sets
+// the opaque cursor of the page to fetch.
+//
+// Use an empty string to fetch the first page. The cursor of the
+// next page is returned with the Result.NextCursor field.
+//
+// Note that the offset `page` and `cursor` are mutually exclusive.
+func (s *Provider) Cursor(cursor string) *Provider {
+	s.useCursor = true
+	s.cursor = cursor
+	return s
+}
+
 // PerPage sets the `perPage` field of the current search provider.
 //
 // Normalization on the `perPage` value is done during `Exec()`.
@@ -143,6 +166,13 @@ func (s *Provider) Parse(urlQuery string) error {
 		return err
 	}
 
+	if params.Has(CursorQueryParam) {
+		if params.Has(PageQueryParam) {
+			return errors.New("the page and cursor query parameters are mutually exclusive")
+		}
+		s.Cursor(params.Get(CursorQueryParam))
+	}
+
 	if raw := params.Get(SkipTotalQueryParam); raw != "" {
 		v, err := strconv.ParseBool(raw)
 		if err != nil {
@@ -187,9 +217,18 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return nil, errors.New("query is not set")
 	}
 
+	if s.useCursor && s.page > 1 {
+		return nil, errors.New("the page and cursor pagination are mutually exclusive")
+	}
+
 	// shallow clone the provider's query
 	modelsQuery := *s.query
 
+	// the keyset ordering must be fully defined by the provider sort fields
+	if s.useCursor {
+		modelsQuery.OrderBy( /* reset */ )
+	}
+
 	// build filters
 	for _, f := range s.filter {
 		expr, err := f.BuildExpr(s.fieldResolver)
@@ -202,13 +241,33 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	}
 
 	// apply sorting
+	// (the random sort fields are always applied last as tie-breakers)
+	sortFields := make([]SortField, 0, len(s.sort))
+	randomSortFields := make([]SortField, 0, 1)
 	for _, sortField := range s.sort {
+		if sortField.IsRandom() {
+			randomSortFields = append(randomSortFields, sortField)
+		} else {
+			sortFields = append(sortFields, sortField)
+		}
+	}
+	if s.useCursor && len(randomSortFields) > 0 {
+		return nil, errors.New("the random sort is not supported with the cursor pagination")
+	}
+	sortFields = append(sortFields, randomSortFields...)
+
+	cursorKeys := make([]cursorKey, 0, len(sortFields)+1)
+	for _, sortField := range sortFields {
 		expr, err := sortField.BuildExpr(s.fieldResolver)
 		if err != nil {
 			return nil, err
 		}
 		if expr != "" {
 			modelsQuery.AndOrderBy(expr)
+			cursorKeys = append(cursorKeys, cursorKey{
+				expr:      strings.TrimSuffix(expr, " "+sortField.Direction),
+				direction: sortField.Direction,
+			})
 		}
 	}
 
@@ -233,18 +292,18 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	totalCount := -1
 	totalPages := -1
 
+	queryInfo := modelsQuery.Info()
+	uniqueCol := s.countCol
+	if len(queryInfo.From) > 0 {
+		uniqueCol = queryInfo.From[0] + "." + uniqueCol
+	}
+
 	// prepare a count query from the base one
 	countQuery := modelsQuery // shallow clone
 	countExec := func() error {
-		queryInfo := countQuery.Info()
-		countCol := s.countCol
-		if len(queryInfo.From) > 0 {
-			countCol = queryInfo.From[0] + "." + countCol
-		}
-
 		// note: countQuery is shallow cloned and slice/map in-place modifications should be avoided
 		err := countQuery.Distinct(false).
-			Select("COUNT(DISTINCT [[" + countCol + "]])").
+			Select("COUNT(DISTINCT [[" + uniqueCol + "]])").
 			OrderBy( /* reset */ ).
 			Row(&totalCount)
 		if err != nil {
@@ -264,6 +323,18 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return modelsQuery.All(items)
 	}
 
+	var nextCursor string
+
+	if s.useCursor {
+		s.page = 1
+
+		modelsExec = func() error {
+			var err error
+			nextCursor, err = s.execCursorQuery(modelsQuery, uniqueCol, cursorKeys, items)
+			return err
+		}
+	}
+
 	if !s.skipTotal {
 		// execute the 2 queries concurrently
 		errg := new(errgroup.Group)
@@ -285,11 +356,92 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		TotalItems: totalCount,
 		TotalPages: totalPages,
 		Items:      items,
+		NextCursor: nextCursor,
 	}
 
 	return result, nil
 }
 
+// execCursorQuery fetches the current cursor page items using the provided
+// query and returns the cursor of the next page (if there are more items).
+//
+// The keyset values are loaded with a separate lightweight query and then
+// the page items are fetched by their unique column so that the returned
+// cursor always corresponds to the last of the fetched items.
+func (s *Provider) execCursorQuery(query dbx.SelectQuery, uniqueCol string, sortKeys []cursorKey, items any) (string, error) {
+	// always append the unique column as last keyset key to ensure deterministic order
+	lastDirection := SortAsc
+	if len(sortKeys) > 0 {
+		lastDirection = sortKeys[len(sortKeys)-1].direction
+	}
+	uniqueExpr := "[[" + uniqueCol + "]]"
+	keys := append(append([]cursorKey{}, sortKeys...), cursorKey{expr: uniqueExpr, direction: lastDirection})
+
+	// note: avoid in-place modifications of the shallow cloned query slices
+	orderBy := make([]string, 0, len(keys))
+	for _, k := range keys {
+		orderBy = append(orderBy, k.expr+" "+k.direction)
+	}
+
+	sortSignature := cursorSortSignature(s.sort)
+
+	keysQuery := query // shallow clone
+	keysQuery.Select(cursorSelectColumns(keys)...).OrderBy(orderBy...)
+
+	if s.cursor != "" {
+		data, err := decodeCursor(s.cursor, sortSignature, len(keys))
+		if err != nil {
+			return "", err
+		}
+
+		keysetExpr, err := buildKeysetExpr(keys, data.Values)
+		if err != nil {
+			return "", err
+		}
+
+		keysQuery.AndWhere(keysetExpr)
+	}
+
+	// fetch 1 extra row to check whether there is a next page
+	rows := []dbx.NullStringMap{}
+	if err := keysQuery.Limit(int64(s.perPage + 1)).All(&rows); err != nil {
+		return "", err
+	}
+
+	hasMore := len(rows) > s.perPage
+	if hasMore {
+		rows = rows[:s.perPage]
+	}
+
+	uniqueValues := make([]any, len(rows))
+	uniqueValueIndex := len(keys) - 1
+	for i, row := range rows {
+		value, err := cursorValuesFromRow(row, len(keys))[uniqueValueIndex].bindValue()
+		if err != nil {
+			return "", err
+		}
+		uniqueValues[i] = value
+	}
+
+	itemsQuery := query // shallow clone
+	err := itemsQuery.AndWhere(dbx.In(uniqueCol, uniqueValues...)).
+		OrderBy(orderBy...).
+		Limit(int64(s.perPage)).
+		All(items)
+	if err != nil {
+		return "", err
+	}
+
+	if !hasMore || len(rows) == 0 {
+		return "", nil
+	}
+
+	return encodeCursor(&cursorData{
+		Sort:   sortSignature,
+		Values: cursorValuesFromRow(rows[len(rows)-1], len(keys)),
+	})
+}
+
 // ParseAndExec is a short convenient method to trigger both
 // `Parse()` and `Exec()` in a single call.
 func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, error) {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -168,6 +169,15 @@ func TestProviderParse(t *testing.T) {
 			`[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"ASC"}]`,
 			`["test1","test2"]`,
 		},
+		// page and cursor
+		{
+			"page=3&cursor=",
+			true,
+			initialPage,
+			initialPerPage,
+			`[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"ASC"}]`,
+			`["test1","test2"]`,
+		},
 		// valid query parameters
 		{
 			"page=3&perPage=456&filter=test3&sort=-a,b,+c&other=123",
@@ -550,6 +560,318 @@ func TestProviderParseAndExec(t *testing.T) {
 	}
 }
 
+func TestProviderExecCursor(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	// extra rows with duplicated and NULL sort values
+	testDB.Insert("test", dbx.Params{"id": 3, "test1": 2, "test2": "test2.3"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 4, "test1": nil, "test2": "test2.4"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 5, "test1": 5, "test2": "test2.5"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 6, "test1": nil, "test2": "test2.6"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 7, "test1": 2, "test2": "test2.7"}).Execute()
+
+	scenarios := []struct {
+		sort      string
+		expectIds []int
+	}{
+		{"", []int{1, 2, 3, 4, 5, 6, 7}},
+		{"test1", []int{4, 6, 1, 2, 3, 7, 5}},
+		{"-test1", []int{5, 7, 3, 2, 1, 6, 4}},
+		{"test1,-test2", []int{6, 4, 1, 7, 3, 2, 5}},
+		{"-test1,test2", []int{5, 2, 3, 7, 1, 4, 6}},
+	}
+
+	for _, s := range scenarios {
+		for _, perPage := range []int{1, 2, 3, 7, 10} {
+			t.Run(fmt.Sprintf("%s_%d", s.sort, perPage), func(t *testing.T) {
+				ids := []int{}
+				cursor := ""
+				totalPages := 0
+
+				for {
+					totalPages++
+					if totalPages > 10 {
+						t.Fatal("Too many pages")
+					}
+
+					items := []struct {
+						Id int `db:"id"`
+					}{}
+
+					p := NewProvider(&testFieldResolver{}).
+						Query(testDB.Select("*").From("test").OrderBy("test2 DESC")).
+						PerPage(perPage).
+						Sort(ParseSortFromString(s.sort)).
+						Cursor(cursor)
+
+					if s.sort == "" {
+						p.Sort(nil)
+					}
+
+					result, err := p.Exec(&items)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if result.TotalItems != len(s.expectIds) {
+						t.Fatalf("Expected %d total items, got %d", len(s.expectIds), result.TotalItems)
+					}
+
+					for _, item := range items {
+						ids = append(ids, item.Id)
+					}
+
+					if result.NextCursor == "" {
+						break
+					}
+
+					if len(items) != perPage {
+						t.Fatalf("Expected %d page items, got %d", perPage, len(items))
+					}
+
+					cursor = result.NextCursor
+				}
+
+				if fmt.Sprint(ids) != fmt.Sprint(s.expectIds) {
+					t.Fatalf("Expected ids %v, got %v", s.expectIds, ids)
+				}
+			})
+		}
+	}
+}
+
+func TestProviderExecJsonPathFilter(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	testDB.CreateTable("json_test", map[string]string{"id": "int default 0", "data": "json default '{}'"}).Execute()
+	defer testDB.DropTable("json_test").Execute()
+
+	testDB.Insert("json_test", dbx.Params{"id": 1, "data": `{"address":{"city":"Berlin"},"tags":["go","sqlite"],"a.b":1,"active":true,"n":null}`}).Execute()
+	testDB.Insert("json_test", dbx.Params{"id": 2, "data": `{"address":{"city":"Sofia"},"tags":["js"],"a.b":"1","active":false}`}).Execute()
+	testDB.Insert("json_test", dbx.Params{"id": 3, "data": `{"tags":[],"n":1.5}`}).Execute()
+
+	scenarios := []struct {
+		filter    string
+		expectIds []int
+	}{
+		// nested objects
+		{`data.address.city = "Berlin"`, []int{1}},
+		{`data.address.city != "Berlin"`, []int{2, 3}},
+		{`data.address.city ~ "of"`, []int{2}},
+		{`data.address.city !~ "of"`, []int{1, 3}},
+		// arrays
+		{`data.tags.0 = "go"`, []int{1}},
+		{`data.tags.1 ~ "sql"`, []int{1}},
+		{`data.tags.0 = null`, []int{3}},
+		// quoted keys
+		{"data.`a.b` = 1", []int{1}},
+		{"data.`a.b` = '1'", []int{2}},
+		{"data.a.b = null", []int{1, 2, 3}},
+		// booleans and numbers
+		{`data.active = true`, []int{1}},
+		{`data.active = false`, []int{2}},
+		{`data.n > 1`, []int{3}},
+		// null and missing values
+		{`data.n = null`, []int{1, 2}},
+		{`data.n != null`, []int{3}},
+		{`data.missing.deep = null`, []int{1, 2, 3}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			items := []struct {
+				Id int `db:"id"`
+			}{}
+
+			_, err := NewProvider(NewSimpleFieldResolver("id", `^data(\.\w+)+$`)).
+				Query(testDB.Select("id").From("json_test")).
+				Sort([]SortField{{"id", SortAsc}}).
+				Filter([]FilterData{FilterData(s.filter)}).
+				Exec(&items)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := make([]int, len(items))
+			for i, item := range items {
+				ids[i] = item.Id
+			}
+
+			if fmt.Sprint(ids) != fmt.Sprint(s.expectIds) {
+				t.Fatalf("Expected ids %v, got %v", s.expectIds, ids)
+			}
+		})
+	}
+}
+
+func TestProviderExecRandomSort(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	for i := 3; i <= 20; i++ {
+		testDB.Insert("test", dbx.Params{"id": i, "test1": i % 2, "test2": fmt.Sprintf("test2.%d", i)}).Execute()
+	}
+
+	scenarios := []struct {
+		name        string
+		sort        string
+		expectOrder string
+	}{
+		{
+			"unseeded random",
+			"@random",
+			"ORDER BY RANDOM() LIMIT 5",
+		},
+		{
+			"random before other fields (random is applied last)",
+			"@random,-test1",
+			"ORDER BY [[test1]] DESC, RANDOM() LIMIT 5",
+		},
+		{
+			"seeded random after other fields",
+			"-test1,@random(abc)",
+			"ORDER BY [[test1]] DESC, ((COALESCE(unicode(substr([[id]], 1, 1)), 0) *",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			testDB.CalledQueries = []string{}
+
+			items := []testTableStruct{}
+
+			_, err := NewProvider(NewSimpleFieldResolver("id", "test1")).
+				Query(testDB.Select("*").From("test")).
+				Sort(ParseSortFromString(s.sort)).
+				SkipTotal(true).
+				PerPage(5).
+				Exec(&items)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(testDB.CalledQueries) != 1 || !strings.Contains(testDB.CalledQueries[0], s.expectOrder) {
+				t.Fatalf("Expected query with %q, got %v", s.expectOrder, testDB.CalledQueries)
+			}
+		})
+	}
+
+	// seeded random pagination stability
+	fetchIds := func(seed string, page int) []int {
+		items := []struct {
+			Id int `db:"id"`
+		}{}
+
+		_, err := NewProvider(NewSimpleFieldResolver("id")).
+			Query(testDB.Select("*").From("test")).
+			Sort([]SortField{{"@random(" + seed + ")", SortAsc}}).
+			Page(page).
+			PerPage(10).
+			Exec(&items)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]int, len(items))
+		for i, item := range items {
+			ids[i] = item.Id
+		}
+
+		return ids
+	}
+
+	page1 := fetchIds("abc", 1)
+	page2 := fetchIds("abc", 2)
+
+	if fmt.Sprint(page1) != fmt.Sprint(fetchIds("abc", 1)) {
+		t.Fatalf("Expected the same seed to return the same page items")
+	}
+
+	seen := map[int]bool{}
+	for _, id := range append(page1, page2...) {
+		if seen[id] {
+			t.Fatalf("Duplicated id %d in the seeded random pages %v and %v", id, page1, page2)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 20 {
+		t.Fatalf("Expected 20 unique ids, got %d", len(seen))
+	}
+
+	if fmt.Sprint(page1) == fmt.Sprint(fetchIds("xyz", 1)) {
+		t.Fatalf("Expected different seeds to return different order, got %v", page1)
+	}
+}
+
+func TestProviderExecCursorErrors(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	items := []testTableStruct{}
+
+	result, err := NewProvider(&testFieldResolver{}).
+		Query(testDB.Select("*").From("test")).
+		PerPage(1).
+		Sort([]SortField{{"test1", SortDesc}}).
+		Cursor("").
+		Exec(&items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NextCursor == "" {
+		t.Fatal("Expected non-empty next cursor")
+	}
+
+	scenarios := []struct {
+		name     string
+		provider *Provider
+	}{
+		{
+			"malformed cursor",
+			NewProvider(&testFieldResolver{}).Sort([]SortField{{"test1", SortDesc}}).Cursor("invalid"),
+		},
+		{
+			"cursor generated for different sort",
+			NewProvider(&testFieldResolver{}).Sort([]SortField{{"test1", SortAsc}}).Cursor(result.NextCursor),
+		},
+		{
+			"random sort",
+			NewProvider(&testFieldResolver{}).Sort([]SortField{{"@random", SortAsc}}).Cursor(""),
+		},
+		{
+			"seeded random sort",
+			NewProvider(&testFieldResolver{}).Sort([]SortField{{"test1", SortAsc}, {"@random(abc)", SortAsc}}).Cursor(""),
+		},
+		{
+			"page and cursor",
+			NewProvider(&testFieldResolver{}).Page(2).Cursor(""),
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := s.provider.Query(testDB.Select("*").From("test")).Exec(&items)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
+
 // -------------------------------------------------------------------
 // Helpers
 // -------------------------------------------------------------------
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/inflector"
+)
+
+// quotedKeyPrefix is the prefix of the encoded backtick quoted identifier keys.
+const quotedKeyPrefix = "_pbq_"
+
+// encodeQuotedKeys replaces the backtick quoted identifier keys
+// (eg. "data.`a.b`.c") outside of the text literals and comments
+// with their fexpr compatible encoded identifier form.
+//
+// Use [DecodeQuotedKey] to restore the original key value.
+func encodeQuotedKeys(raw string) (string, error) {
+	if !strings.Contains(raw, "`") {
+		return raw, nil // nothing to encode
+	}
+
+	var result strings.Builder
+	result.Grow(len(raw))
+
+	var quote rune   // the active text literal quote
+	var escaped bool // whether the previous text literal char was backslash
+	var comment bool // whether a single line comment is active
+	var key *strings.Builder
+
+	rs := []rune(raw)
+
+	for i := 0; i < len(rs); i++ {
+		ch := rs[i]
+
+		switch {
+		case key != nil:
+			if ch == '`' {
+				if key.Len() == 0 {
+					return "", errors.New("empty quoted identifier key")
+				}
+				result.WriteString(quotedKeyPrefix)
+				result.WriteString(hex.EncodeToString([]byte(key.String())))
+				key = nil
+			} else {
+				key.WriteRune(ch)
+			}
+		case comment:
+			if ch == '\n' {
+				comment = false
+			}
+			result.WriteRune(ch)
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if ch == '\\' {
+				escaped = true
+			} else if ch == quote {
+				quote = 0
+			}
+			result.WriteRune(ch)
+		case ch == '\'' || ch == '"':
+			quote = ch
+			result.WriteRune(ch)
+		case ch == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			comment = true
+			result.WriteRune(ch)
+		case ch == '`':
+			key = &strings.Builder{}
+		default:
+			result.WriteRune(ch)
+		}
+	}
+
+	if key != nil {
+		return "", errors.New("unterminated quoted identifier key")
+	}
+
+	return result.String(), nil
+}
+
+// DecodeQuotedKey reports whether the provided identifier part is
+// a backtick quoted key (eg. "`a.b`" from "data.`a.b`.c") and returns its original value.
+func DecodeQuotedKey(part string) (string, bool) {
+	if !strings.HasPrefix(part, quotedKeyPrefix) {
+		return "", false
+	}
+
+	key, err := hex.DecodeString(part[len(quotedKeyPrefix):])
+	if err != nil || len(key) == 0 {
+		return "", false
+	}
+
+	return string(key), true
+}
+
+// jsonQuotedKeyRegex defines the allowed characters of a quoted json path key.
+var jsonQuotedKeyRegex = regexp.MustCompile(`^[^"'\\\[\]{}]+$`)
+
+// BuildJsonPath builds a SQLite json path (without the "$" root) from the provided identifier parts.
+//
+// Numeric parts are treated as array indexes (eg. "tags.0" -> "tags[0]") and
+// the backtick quoted keys are escaped as json path labels (eg. "`a.b`" -> `"a.b"`).
+func BuildJsonPath(parts []string) (string, error) {
+	var jsonPath strings.Builder
+
+	for i, part := range parts {
+		if key, ok := DecodeQuotedKey(part); ok {
+			if !jsonQuotedKeyRegex.MatchString(key) {
+				return "", fmt.Errorf("invalid json path key %q", key)
+			}
+			if i > 0 {
+				jsonPath.WriteString(".")
+			}
+			jsonPath.WriteString(`"`)
+			jsonPath.WriteString(key)
+			jsonPath.WriteString(`"`)
+		} else if _, err := strconv.Atoi(part); err == nil {
+			jsonPath.WriteString("[")
+			jsonPath.WriteString(inflector.Columnify(part))
+			jsonPath.WriteString("]")
+		} else {
+			if i > 0 {
+				jsonPath.WriteString(".")
+			}
+			jsonPath.WriteString(inflector.Columnify(part))
+		}
+	}
+
+	return jsonPath.String(), nil
+}
@@ -2,7 +2,6 @@ package search
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/pocketbase/dbx"
@@ -31,6 +30,29 @@ type ResolverResult struct {
 	// AfterBuild is an optional function that will be called after building
 	// and combining the result of both resolved operands/sides in a single expression.
 	AfterBuild func(expr dbx.Expression) dbx.Expression
+
+	// JsonArray indicates that the identifier could hold a json array value
+	// (eg. a json field) whose items should be matched individually when
+	// the identifier is used as left operand with the "any" operators
+	// (eg. `tags ?= "go"`, `tags ?~ "go"`).
+	JsonArray bool
+
+	// NormalizeLiteral is an optional function to convert the text literal
+	// of the other operand to the identifier values format
+	// (eg. `duration > "5m"` where duration is stored as integer).
+	//
+	// Returning an error rejects the filter expression.
+	NormalizeLiteral func(literal string) (any, error)
+
+	// caseFunc is the SQL case function (LOWER or UPPER) applied
+	// to the identifier via the ":lower" or ":upper" filter modifiers.
+	caseFunc string
+
+	// geo holds the resolved "geoDistance" function operands (if any).
+	geo *geoPoint
+
+	// list holds the items of a resolved list literal operand (if any).
+	list []any
 }
 
 // FieldResolver defines an interface for managing search fields.
@@ -46,6 +68,22 @@ type FieldResolver interface {
 	Resolve(field string) (*ResolverResult, error)
 }
 
+// NonSearchableFieldError is returned by the field resolvers when
+// a filter or sort expression references a field whose value cannot
+// be meaningfully compared (eg. encrypted or computed field).
+type NonSearchableFieldError struct {
+	// Field is the name of the offending field.
+	Field string
+
+	// Reason is a short human readable explanation why the field is not searchable.
+	Reason string
+}
+
+// Error implements the [error] interface.
+func (e *NonSearchableFieldError) Error() string {
+	return fmt.Sprintf("field %q cannot be used in filter or sort expressions - %s", e.Field, e.Reason)
+}
+
 // NewSimpleFieldResolver creates a new `SimpleFieldResolver` with the
 // provided `allowedFields`.
 //
@@ -89,17 +127,12 @@ func (r *SimpleFieldResolver) Resolve(field string) (*ResolverResult, error) {
 	}
 
 	// treat as json path
-	var jsonPath strings.Builder
-	jsonPath.WriteString("$")
-	for _, part := range parts[1:] {
-		if _, err := strconv.Atoi(part); err == nil {
-			jsonPath.WriteString("[")
-			jsonPath.WriteString(inflector.Columnify(part))
-			jsonPath.WriteString("]")
-		} else {
-			jsonPath.WriteString(".")
-			jsonPath.WriteString(inflector.Columnify(part))
-		}
+	jsonPath, err := BuildJsonPath(parts[1:])
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(jsonPath, "[") {
+		jsonPath = "." + jsonPath
 	}
 
 	return &ResolverResult{
@@ -107,7 +140,7 @@ func (r *SimpleFieldResolver) Resolve(field string) (*ResolverResult, error) {
 		Identifier: fmt.Sprintf(
 			"JSON_EXTRACT([[%s]], '%s')",
 			inflector.Columnify(parts[0]),
-			jsonPath.String(),
+			"$"+jsonPath,
 		),
 	}, nil
 }
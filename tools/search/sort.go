@@ -1,12 +1,21 @@
 package search
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"strconv"
 	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
 )
 
 const randomSortKey string = "@random"
 
+// randomSortIdLength is the max number of unique identifier characters
+// used to generate the seeded random sort expression.
+const randomSortIdLength = 15
+
 // sort field directions
 const (
 	SortAsc  string = "ASC"
@@ -20,30 +29,105 @@ type SortField struct {
 }
 
 // BuildExpr resolves the sort field into a valid db sort expression.
+//
+// The special "@random" sort field is resolved to RANDOM() and its seeded
+// "@random(seed)" form to a deterministic pseudorandom expression based on the
+// "id" field, so that the same seed results in the same order across pages.
 func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 	// special case for random sort
 	if s.Name == randomSortKey {
 		return "RANDOM()", nil
 	}
 
-	result, err := fieldResolver.Resolve(s.Name)
+	// special case for seeded random sort
+	if seed, ok := parseRandomSortSeed(s.Name); ok {
+		result, err := fieldResolver.Resolve("id")
+		if err != nil || len(result.Params) > 0 || result.Identifier == "" {
+			return "", fmt.Errorf("invalid sort field %q - the id field must be resolvable", s.Name)
+		}
+
+		return fmt.Sprintf("%s %s", seededRandomExpr(result.Identifier, seed), s.Direction), nil
+	}
+
+	name, err := encodeQuotedKeys(s.Name)
+	if err != nil {
+		return "", fmt.Errorf("invalid sort field %q - %w", s.Name, err)
+	}
+
+	// sort by function (eg. "geoDistance(location, 42.69, 23.32)")
+	if strings.HasSuffix(name, ")") {
+		identifier, err := resolveSortFunction(name, fieldResolver)
+		if err != nil {
+			return "", fmt.Errorf("invalid sort field %q - %w", s.Name, err)
+		}
+
+		return fmt.Sprintf("%s %s", identifier, s.Direction), nil
+	}
+
+	result, err := fieldResolver.Resolve(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid sort field %q - %w", s.Name, err)
+	}
 
 	// invalidate empty fields and non-column identifiers
-	if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.ToLower(result.Identifier) == "null" {
+	if len(result.Params) > 0 || result.Identifier == "" || strings.ToLower(result.Identifier) == "null" {
 		return "", fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
 	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil
 }
 
+// resolveSortFunction resolves a single function sort field
+// into a plain db expression.
+//
+// Since the sort expressions don't support bind parameters, only
+// number literals are allowed as additional (non-column) arguments.
+func resolveSortFunction(name string, fieldResolver FieldResolver) (string, error) {
+	scanner := fexpr.NewScanner([]byte(name))
+
+	token, err := scanner.Scan()
+	if err != nil {
+		return "", err
+	}
+
+	if token.Type != fexpr.TokenFunction {
+		return "", errors.New("not a function")
+	}
+
+	// ensure that there are no other trailing tokens
+	if next, err := scanner.Scan(); err != nil || next.Type != fexpr.TokenEOF {
+		return "", errors.New("expected a single function expression")
+	}
+
+	result, err := resolveToken(token, fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	identifier := result.Identifier
+
+	for key, value := range result.Params {
+		v, ok := value.(float64)
+		if !ok {
+			return "", errors.New("only number literal arguments are allowed")
+		}
+
+		identifier = strings.ReplaceAll(identifier, "{:"+key+"}", strconv.FormatFloat(v, 'f', -1, 64))
+	}
+
+	return identifier, nil
+}
+
 // ParseSortFromString parses the provided string expression
 // into a slice of SortFields.
 //
+// Commas inside function parenthesis are not treated as separators.
+//
 // Example:
 //
-//	fields := search.ParseSortFromString("-name,+created")
+//	fields := search.ParseSortFromString("-name,+created,geoDistance(location, 42.69, 23.32)")
 func ParseSortFromString(str string) (fields []SortField) {
-	data := strings.Split(str, ",")
+	data := splitTopLevel(str, ',')
 
 	for _, field := range data {
 		// trim whitespaces
@@ -57,3 +141,81 @@ func ParseSortFromString(str string) (fields []SortField) {
 
 	return
 }
+
+// splitTopLevel splits str by sep ignoring the separators inside parenthesis.
+func splitTopLevel(str string, sep rune) []string {
+	var parts []string
+
+	var depth int
+	var start int
+
+	for i, ch := range str {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				parts = append(parts, str[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, str[start:])
+}
+
+// IsRandom reports whether the sort field is the special "@random"
+// or "@random(seed)" sort field.
+func (s *SortField) IsRandom() bool {
+	if s.Name == randomSortKey {
+		return true
+	}
+
+	_, ok := parseRandomSortSeed(s.Name)
+
+	return ok
+}
+
+// parseRandomSortSeed extracts the seed from the "@random(seed)" sort field name.
+func parseRandomSortSeed(name string) (string, bool) {
+	seed, ok := strings.CutPrefix(name, randomSortKey+"(")
+	if !ok || !strings.HasSuffix(seed, ")") {
+		return "", false
+	}
+
+	seed = strings.TrimSuffix(seed, ")")
+
+	return seed, seed != ""
+}
+
+// seededRandomExpr generates a deterministic pseudorandom sort expression
+// for the provided unique identifier and seed.
+//
+// SQLite doesn't have a seeded RANDOM() function so instead the expression
+// hashes the first randomSortIdLength identifier characters with seed
+// derived multipliers, eg.:
+//
+//	((COALESCE(unicode(substr([[id]], 1, 1)), 0) * 123 + ...) % 2147483647)
+func seededRandomExpr(identifier string, seed string) string {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	state := h.Sum64()
+
+	parts := make([]string, randomSortIdLength)
+
+	for i := 0; i < randomSortIdLength; i++ {
+		// 64-bit LCG step (Knuth's MMIX constants)
+		state = state*6364136223846793005 + 1442695040888963407
+
+		// keep the multiplier < 2^31 to prevent integer overflows
+		multiplier := (state>>33)%2147483646 + 1
+
+		parts[i] = fmt.Sprintf("COALESCE(unicode(substr(%s, %d, 1)), 0) * %d", identifier, i+1, multiplier)
+	}
+
+	return "((" + strings.Join(parts, " + ") + ") % 2147483647)"
+}
@@ -2,13 +2,15 @@ package search_test
 
 import (
 	"encoding/json"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tools/search"
 )
 
 func TestSortFieldBuildExpr(t *testing.T) {
-	resolver := search.NewSimpleFieldResolver("test1", "test2", "test3", "test4.sub")
+	resolver := search.NewSimpleFieldResolver("test1", "test2", "test3", "test4.sub", `^test5\.\w+$`)
 
 	scenarios := []struct {
 		sortField        search.SortField
@@ -29,6 +31,26 @@ func TestSortFieldBuildExpr(t *testing.T) {
 		{search.SortField{"test1", search.SortDesc}, false, "[[test1]] DESC"},
 		// special @random field (ignore direction)
 		{search.SortField{"@random", search.SortDesc}, false, "RANDOM()"},
+		// @random with empty seed
+		{search.SortField{"@random()", search.SortAsc}, true, ""},
+		// seeded @random with unresolvable id field
+		{search.SortField{"@random(abc)", search.SortAsc}, true, ""},
+		// json path with backtick quoted key
+		{search.SortField{"test5.`a.b`", search.SortDesc}, false, `JSON_EXTRACT([[test5]], '$."a.b"') DESC`},
+		// json path with unterminated backtick quoted key
+		{search.SortField{"test5.`a.b", search.SortDesc}, true, ""},
+		// unknown function
+		{search.SortField{"unknown(test1)", search.SortAsc}, true, ""},
+		// function with non-number literal argument
+		{search.SortField{"geoDistance(test1, test2, 'a', 1)", search.SortAsc}, true, ""},
+		// function with trailing tokens
+		{search.SortField{"geoDistance(test1, test2, 1, 2) test3", search.SortAsc}, true, ""},
+		// geoDistance function
+		{
+			search.SortField{"geoDistance(test1, test2, 1.5, -2)", search.SortDesc},
+			false,
+			"(12742017.6 * ASIN(SQRT(MIN(1, POWER(SIN(RADIANS(1.5 - [[test1]]) / 2), 2) + COS(RADIANS([[test1]])) * COS(RADIANS(1.5)) * POWER(SIN(RADIANS(-2 - [[test2]]) / 2), 2))))) DESC",
+		},
 	}
 
 	for i, s := range scenarios {
@@ -46,6 +68,73 @@ func TestSortFieldBuildExpr(t *testing.T) {
 	}
 }
 
+func TestSortFieldBuildExprSeededRandom(t *testing.T) {
+	resolver := search.NewSimpleFieldResolver("id")
+
+	build := func(name string, direction string) string {
+		sortField := search.SortField{name, direction}
+
+		expr, err := sortField.BuildExpr(resolver)
+		if err != nil {
+			t.Fatalf("Failed to build %q sort expression: %v", name, err)
+		}
+
+		return expr
+	}
+
+	expr1 := build("@random(abc)", search.SortAsc)
+	expr2 := build("@random(abc)", search.SortAsc)
+	expr3 := build("@random(xyz)", search.SortAsc)
+	expr4 := build("@random(abc)", search.SortDesc)
+
+	expectPattern := regexp.MustCompile(
+		`^\(\((COALESCE\(unicode\(substr\(\[\[id\]\], \d+, 1\)\), 0\) \* \d+( \+ )?){15}\) % 2147483647\) (ASC|DESC)$`,
+	)
+
+	for _, expr := range []string{expr1, expr3, expr4} {
+		if !expectPattern.MatchString(expr) {
+			t.Fatalf("Expression %q doesn't match pattern %q", expr, expectPattern)
+		}
+	}
+
+	if expr1 != expr2 {
+		t.Fatalf("Expected the same seed to generate the same expression, got\n%s\n%s", expr1, expr2)
+	}
+
+	if expr1 == expr3 {
+		t.Fatalf("Expected different seeds to generate different expressions, got\n%s", expr1)
+	}
+
+	if strings.TrimSuffix(expr1, " ASC") != strings.TrimSuffix(expr4, " DESC") {
+		t.Fatalf("Expected only the direction to differ, got\n%s\n%s", expr1, expr4)
+	}
+}
+
+func TestSortFieldIsRandom(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		expected bool
+	}{
+		{"", false},
+		{"test", false},
+		{"random", false},
+		{"@random", true},
+		{"@random()", false},
+		{"@random(", false},
+		{"@random(abc", false},
+		{"@random(abc)", true},
+		{"@random(123)", true},
+	}
+
+	for _, s := range scenarios {
+		sortField := search.SortField{s.name, search.SortAsc}
+
+		if v := sortField.IsRandom(); v != s.expected {
+			t.Errorf("[%s] Expected %v, got %v", s.name, s.expected, v)
+		}
+	}
+}
+
 func TestParseSortFromString(t *testing.T) {
 	scenarios := []struct {
 		value        string
@@ -57,6 +146,8 @@ func TestParseSortFromString(t *testing.T) {
 		{"-test", `[{"name":"test","direction":"DESC"}]`},
 		{"test1,-test2,+test3", `[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"DESC"},{"name":"test3","direction":"ASC"}]`},
 		{"@random,-test", `[{"name":"@random","direction":"ASC"},{"name":"test","direction":"DESC"}]`},
+		{"-@random(abc),test", `[{"name":"@random(abc)","direction":"DESC"},{"name":"test","direction":"ASC"}]`},
+		{"-geoDistance(a, 1, 2),test", `[{"name":"geoDistance(a, 1, 2)","direction":"DESC"},{"name":"test","direction":"ASC"}]`},
 	}
 
 	for i, s := range scenarios {
@@ -0,0 +1,195 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// earthRadius is the mean Earth radius in meters used for the geoDistance calculations.
+const earthRadius = 6371008.8
+
+// tokenFunctions is a map with the supported filter (and sort) functions.
+//
+// resolveArg resolves a single function argument token to its db operand.
+var tokenFunctions = map[string]func(
+	resolveArg func(arg fexpr.Token) (*ResolverResult, error),
+	args ...fexpr.Token,
+) (*ResolverResult, error){
+	"geoDistance": geoDistance,
+	"length":      jsonArrayLength,
+}
+
+// geoPoint holds the resolved geoDistance operands used for
+// the optional bounding box prefilter (see [geoBoundingBoxExpr]).
+type geoPoint struct {
+	latIdentifier string
+	lonIdentifier string
+	params        dbx.Params
+
+	// origin is the literal (lat, lon) point to measure against (if any)
+	origin []float64
+}
+
+// geoDistance resolves the "geoDistance" filter function to
+// a Haversine great-circle distance expression in meters.
+//
+// It could be called with 3 arguments, where the first one is an identifier
+// to a json object with "lat" and "lon" keys (eg. a json field):
+//
+//	geoDistance(location, lat, lon)
+//
+// or with 4 arguments for separately stored coordinates:
+//
+//	geoDistance(latA, lonA, latB, lonB)
+//
+// The coordinates are expected to be in degrees and the longitudes
+// to be normalized in the [-180, 180] range.
+func geoDistance(resolveArg func(arg fexpr.Token) (*ResolverResult, error), args ...fexpr.Token) (*ResolverResult, error) {
+	var pointArgs []fexpr.Token
+	var originArgs []fexpr.Token
+
+	switch len(args) {
+	case 3:
+		if args[0].Type != fexpr.TokenIdentifier {
+			return nil, errors.New("geoDistance: the first argument must be a point identifier")
+		}
+		pointArgs = []fexpr.Token{
+			{Type: fexpr.TokenIdentifier, Literal: args[0].Literal + ".lat"},
+			{Type: fexpr.TokenIdentifier, Literal: args[0].Literal + ".lon"},
+		}
+		originArgs = args[1:]
+	case 4:
+		pointArgs = args[:2]
+		originArgs = args[2:]
+	default:
+		return nil, fmt.Errorf("geoDistance: expected 3 or 4 arguments, got %d", len(args))
+	}
+
+	operands := make([]*ResolverResult, 0, 4)
+	for _, arg := range []fexpr.Token{pointArgs[0], pointArgs[1], originArgs[0], originArgs[1]} {
+		r, err := resolveArg(arg)
+		if err != nil {
+			return nil, fmt.Errorf("geoDistance: invalid argument %q - %w", arg.Literal, err)
+		}
+		if r.Identifier == "" || r.MultiMatchSubQuery != nil {
+			return nil, fmt.Errorf("geoDistance: invalid or multi-value argument %q", arg.Literal)
+		}
+		operands = append(operands, r)
+	}
+
+	lat1, lon1, lat2, lon2 := operands[0].Identifier, operands[1].Identifier, operands[2].Identifier, operands[3].Identifier
+
+	params := dbx.Params{}
+	for _, r := range operands {
+		params = mergeParams(params, r.Params)
+	}
+
+	// note: MIN(1, ...) guards against floating point errors for antipodal points
+	result := &ResolverResult{
+		NoCoalesce: true,
+		Identifier: fmt.Sprintf(
+			"(%.1f * ASIN(SQRT(MIN(1, POWER(SIN(RADIANS(%s - %s) / 2), 2) + COS(RADIANS(%s)) * COS(RADIANS(%s)) * POWER(SIN(RADIANS(%s - %s) / 2), 2)))))",
+			2*earthRadius,
+			lat2, lat1,
+			lat1, lat2,
+			lon2, lon1,
+		),
+		Params: params,
+		geo: &geoPoint{
+			latIdentifier: lat1,
+			lonIdentifier: lon1,
+			params:        mergeParams(operands[0].Params, operands[1].Params),
+		},
+	}
+
+	// store the literal origin for the bounding box prefilter
+	if originArgs[0].Type == fexpr.TokenNumber && originArgs[1].Type == fexpr.TokenNumber {
+		lat := cast.ToFloat64(originArgs[0].Literal)
+		lon := cast.ToFloat64(originArgs[1].Literal)
+		if lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180 {
+			result.geo.origin = []float64{lat, lon}
+		}
+	}
+
+	return result, nil
+}
+
+// geoBoundingBoxExpr returns a cheap lat/lon range expression that
+// could be used as a prefilter for the geoDistance(...) <= maxDistance
+// comparison (all points within the distance are inside the bounding box).
+//
+// Returns nil if a bounding box is not applicable (eg. missing literal origin)
+// or the box covers the whole globe.
+func geoBoundingBoxExpr(point *geoPoint, maxDistance float64) dbx.Expression {
+	if point == nil || len(point.origin) != 2 || maxDistance < 0 {
+		return nil
+	}
+
+	// angular distance
+	d := maxDistance / earthRadius
+	if d >= math.Pi {
+		return nil // everything is within the range
+	}
+
+	lat, lon := point.origin[0], point.origin[1]
+
+	minLat := lat - d*180/math.Pi
+	maxLat := lat + d*180/math.Pi
+
+	latExpr := func(from, to float64) dbx.Expression {
+		p1 := "t" + security.PseudorandomString(5)
+		p2 := "t" + security.PseudorandomString(5)
+		return dbx.NewExp(
+			fmt.Sprintf("%s BETWEEN {:%s} AND {:%s}", point.latIdentifier, p1, p2),
+			mergeParams(point.params, dbx.Params{p1: from, p2: to}),
+		)
+	}
+
+	// one of the poles is within the range and therefore all longitudes are
+	if minLat <= -90 || maxLat >= 90 {
+		return latExpr(math.Max(minLat, -90), math.Min(maxLat, 90))
+	}
+
+	// the longitude delta at the origin latitude
+	// (http://janmatuschek.de/LatitudeLongitudeBoundingCoordinates)
+	sinDeltaLon := math.Sin(d) / math.Cos(lat*math.Pi/180)
+	if sinDeltaLon >= 1 {
+		return latExpr(minLat, maxLat)
+	}
+	deltaLon := math.Asin(sinDeltaLon) * 180 / math.Pi
+
+	minLon := lon - deltaLon
+	maxLon := lon + deltaLon
+
+	lonExpr := func(sql string, values ...float64) dbx.Expression {
+		params := dbx.Params{}
+		args := make([]any, 0, len(values)+1)
+		args = append(args, point.lonIdentifier)
+		for _, v := range values {
+			p := "t" + security.PseudorandomString(5)
+			params[p] = v
+			args = append(args, "{:"+p+"}")
+		}
+		return dbx.NewExp(fmt.Sprintf(sql, args...), mergeParams(point.params, params))
+	}
+
+	var lonRange dbx.Expression
+	switch {
+	case minLon < -180:
+		// crosses the antimeridian from the west
+		lonRange = lonExpr("(%[1]s >= %[2]s OR %[1]s <= %[3]s)", minLon+360, maxLon)
+	case maxLon > 180:
+		// crosses the antimeridian from the east
+		lonRange = lonExpr("(%[1]s >= %[2]s OR %[1]s <= %[3]s)", minLon, maxLon-360)
+	default:
+		lonRange = lonExpr("%s BETWEEN %s AND %s", minLon, maxLon)
+	}
+
+	return dbx.And(latExpr(minLat, maxLat), lonRange)
+}
@@ -0,0 +1,157 @@
+package search_test
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func newGeoTestDB(t *testing.T) *dbx.DB {
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := dbx.NewFromDB(sqlDB, "sqlite")
+
+	_, err = db.NewQuery(`
+		CREATE TABLE points (id TEXT, lat REAL, lon REAL, location JSON);
+		INSERT INTO points VALUES
+			('london',      51.5074, -0.1278,   '{"lat":51.5074,"lon":-0.1278}'),
+			('paris',       48.8566, 2.3522,    '{"lat":48.8566,"lon":2.3522}'),
+			('new_york',    40.7128, -74.0060,  '{"lat":40.7128,"lon":-74.0060}'),
+			('los_angeles', 34.0522, -118.2437, '{"lat":34.0522,"lon":-118.2437}'),
+			('east_am',     0,       179.9,     '{"lat":0,"lon":179.9}'),
+			('west_am',     0,       -179.9,    '{"lat":0,"lon":-179.9}'),
+			('north_a',     89.95,   0,         '{"lat":89.95,"lon":0}'),
+			('north_b',     89.95,   180,       '{"lat":89.95,"lon":180}'),
+			('missing',     NULL,    NULL,      '{}');
+	`).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestGeoDistanceKnownValues(t *testing.T) {
+	db := newGeoTestDB(t)
+	defer db.Close()
+
+	resolver := search.NewSimpleFieldResolver("lat", "lon", `^location\.\w+$`)
+
+	scenarios := []struct {
+		name     string
+		id       string
+		sort     string
+		expected float64 // in meters
+	}{
+		{"london -> paris (4 args)", "london", "geoDistance(lat, lon, 48.8566, 2.3522)", 343556},
+		{"london -> paris (json point)", "london", "geoDistance(location, 48.8566, 2.3522)", 343556},
+		{"paris -> london", "paris", "geoDistance(location, 51.5074, -0.1278)", 343556},
+		{"new york -> los angeles", "new_york", "geoDistance(location, 34.0522, -118.2437)", 3935752},
+		{"same point", "new_york", "geoDistance(lat, lon, 40.7128, -74.0060)", 0},
+		{"across the antimeridian", "east_am", "geoDistance(location, 0, -179.9)", 22239},
+		{"across the north pole", "north_a", "geoDistance(location, 89.95, 180)", 11119.5},
+		{"antipodal points", "east_am", "geoDistance(location, 0, -0.1)", 20015114},
+		{"column vs column", "london", "geoDistance(lat, lon, location.lat, location.lon)", 0},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sortField := search.SortField{Name: s.sort, Direction: search.SortAsc}
+
+			expr, err := sortField.BuildExpr(resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var distance float64
+
+			err = db.Select(strings.TrimSuffix(expr, " ASC") + " as distance").
+				From("points").
+				AndWhere(dbx.HashExp{"id": s.id}).
+				Row(&distance)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// ~1 meter or 0.01% tolerance
+			if diff := math.Abs(distance - s.expected); diff > 1 && diff > s.expected*0.0001 {
+				t.Fatalf("Expected distance %v, got %v", s.expected, distance)
+			}
+		})
+	}
+}
+
+func TestGeoDistanceFilter(t *testing.T) {
+	db := newGeoTestDB(t)
+	defer db.Close()
+
+	resolver := search.NewSimpleFieldResolver("id", "lat", "lon", `^location\.\w+$`)
+
+	scenarios := []struct {
+		filter      string
+		expectError bool
+		expectBBox  bool
+		expectedIds []string
+	}{
+		// invalid arguments
+		{"geoDistance(location) < 1", true, false, nil},
+		{"geoDistance(1, 2, 3) < 1", true, false, nil},
+		{"geoDistance(missing, 2, 3) < 1", true, false, nil},
+		{"unknown(location, 2, 3) < 1", true, false, nil},
+
+		{"geoDistance(location, 51.5, -0.12) <= 400000", false, true, []string{"london", "paris"}},
+		{"400000 >= geoDistance(location, 51.5, -0.12)", false, true, []string{"london", "paris"}},
+		{"geoDistance(location, 51.5, -0.12) < 1000", false, true, []string{"london"}},
+		{"geoDistance(location, 51.5, -0.12) > 400000", false, false, []string{"east_am", "los_angeles", "new_york", "north_a", "north_b", "west_am"}},
+		{"geoDistance(lat, lon, 51.5, -0.12) <= 400000 && id != 'london'", false, true, []string{"paris"}},
+		// non-literal origin
+		{"geoDistance(location, lat, lon) <= 0", false, false, []string{"east_am", "london", "los_angeles", "new_york", "north_a", "north_b", "paris", "west_am"}},
+		// antimeridian
+		{"geoDistance(location, 0, 179.9) <= 50000", false, true, []string{"east_am", "west_am"}},
+		{"geoDistance(location, 0, -179.95) <= 50000", false, true, []string{"east_am", "west_am"}},
+		// poles
+		{"geoDistance(location, 89.95, 90) <= 20000", false, true, []string{"north_a", "north_b"}},
+		{"geoDistance(location, 90, 0) <= 10000", false, true, []string{"north_a", "north_b"}},
+		// whole globe
+		{"geoDistance(location, 0, 0) <= 30000000", false, false, []string{"east_am", "london", "los_angeles", "new_york", "north_a", "north_b", "paris", "west_am"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			query := db.Select("id").From("points").Where(expr).OrderBy("id ASC")
+
+			rawSql := query.Build().SQL()
+			if hasBBox := strings.Contains(rawSql, "BETWEEN"); hasBBox != s.expectBBox {
+				t.Fatalf("Expected bounding box %v, got %v\n%s", s.expectBBox, hasBBox, rawSql)
+			}
+
+			ids := []string{}
+			if err := query.Column(&ids); err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(ids) != fmt.Sprint(s.expectedIds) {
+				t.Fatalf("Expected ids %v, got %v", s.expectedIds, ids)
+			}
+		})
+	}
+}
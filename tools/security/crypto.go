@@ -56,6 +56,15 @@ func HS512(text string, secret string) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// EqualConstantTime compares two secret strings (tokens, hashes, signatures, etc.)
+// for equality without leaking timing information about their content.
+//
+// Note that the comparison still returns early if the strings lengths
+// differ, so it should be used only with values whose length is not secret.
+func EqualConstantTime(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
 // Equal compares two hash strings for equality without leaking timing information.
 func Equal(hash1 string, hash2 string) bool {
 	return subtle.ConstantTimeCompare([]byte(hash1), []byte(hash2)) == 1
@@ -133,6 +133,37 @@ func TestHS512(t *testing.T) {
 	}
 }
 
+func TestEqualConstantTime(t *testing.T) {
+	scenarios := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"abc", "abd", false},
+		{"abc", "ABC", false},
+		{"abc", "abcd", false},
+		{"abc", "abc", true},
+		{"абв", "абв", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%qVS%q", s.a, s.b), func(t *testing.T) {
+			result := security.EqualConstantTime(s.a, s.b)
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+
+			// should be symmetric
+			if reversed := security.EqualConstantTime(s.b, s.a); reversed != result {
+				t.Fatalf("Expected the reversed comparison to be %v, got %v", result, reversed)
+			}
+		})
+	}
+}
+
 func TestEqual(t *testing.T) {
 	scenarios := []struct {
 		hash1    string
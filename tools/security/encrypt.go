@@ -5,9 +5,41 @@ import (
 	"crypto/cipher"
 	crand "crypto/rand"
 	"encoding/base64"
+	"errors"
 	"io"
+	"strings"
 )
 
+// keyVersionSeparator separates the key version prefix from
+// the base64 encoded cipher text (eg. "1a2b3c4d:base64...").
+const keyVersionSeparator = ":"
+
+// ErrKeyVersionMismatch is returned when trying to decrypt a versioned
+// cipher text with a key that has different version.
+var ErrKeyVersionMismatch = errors.New("the cipher text is encrypted with a different key version")
+
+// KeyVersion returns the short version identifier of the provided
+// encryption key that is used as prefix of the [EncryptVersioned] cipher texts.
+//
+// The version is derived from the key sha256 hash so that the
+// cipher text could be matched with its key without exposing it.
+func KeyVersion(key string) string {
+	return SHA256(key)[:8]
+}
+
+// splitKeyVersion splits the provided cipher text into its key version
+// and base64 encoded data parts.
+//
+// The key version is empty for the legacy (non-versioned) cipher texts.
+func splitKeyVersion(cipherText string) (string, string) {
+	version, data, ok := strings.Cut(cipherText, keyVersionSeparator)
+	if !ok {
+		return "", cipherText
+	}
+
+	return version, data
+}
+
 // Encrypt encrypts "data" with the specified "key" (must be valid 32 char AES key).
 //
 // This method uses AES-256-GCM block cypher mode.
@@ -36,10 +68,29 @@ func Encrypt(data []byte, key string) (string, error) {
 	return result, nil
 }
 
+// EncryptVersioned is similar to [Encrypt] but prefixes the result with
+// the [KeyVersion] of the encryption key (eg. "1a2b3c4d:base64...")
+// so that the cipher text could be matched with its key on decrypt.
+func EncryptVersioned(data []byte, key string) (string, error) {
+	encrypted, err := Encrypt(data, key)
+	if err != nil {
+		return "", err
+	}
+
+	return KeyVersion(key) + keyVersionSeparator + encrypted, nil
+}
+
 // Decrypt decrypts encrypted text with key (must be valid 32 chars AES key).
 //
 // This method uses AES-256-GCM block cypher mode.
+//
+// Both the versioned and the legacy (non-prefixed) cipher texts are supported.
 func Decrypt(cipherText string, key string) ([]byte, error) {
+	version, data := splitKeyVersion(cipherText)
+	if version != "" && version != KeyVersion(key) {
+		return nil, ErrKeyVersionMismatch
+	}
+
 	block, err := aes.NewCipher([]byte(key))
 	if err != nil {
 		return nil, err
@@ -52,11 +103,15 @@ func Decrypt(cipherText string, key string) ([]byte, error) {
 
 	nonceSize := gcm.NonceSize()
 
-	cipherByte, err := base64.StdEncoding.DecodeString(cipherText)
+	cipherByte, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(cipherByte) < nonceSize {
+		return nil, errors.New("invalid cipher text")
+	}
+
 	nonce, cipherByteClean := cipherByte[:nonceSize], cipherByte[nonceSize:]
 	return gcm.Open(nil, nonce, cipherByteClean, nil)
 }
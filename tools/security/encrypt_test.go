@@ -1,6 +1,7 @@
 package security_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tools/security"
@@ -33,6 +34,9 @@ func TestEncrypt(t *testing.T) {
 		if !scenario.expectError && result == "" {
 			t.Errorf("(%d) Expected non empty encrypted result string", i)
 		}
+		if strings.Contains(result, ":") {
+			t.Errorf("(%d) Expected legacy non-versioned cipher text, got %q", i, result)
+		}
 
 		// try to decrypt
 		if result != "" {
@@ -54,6 +58,7 @@ func TestDecrypt(t *testing.T) {
 		{"", "", true, ""},
 		{"123", "test", true, ""}, // key must be valid 32 char aes string
 		{"8kcEqilvvYKYcfnSr0aSC54gmnQCsB02SaB8ATlnA==", "abcdabcdabcdabcdabcdabcdabcdabcd", true, ""}, // illegal base64 encoded cipherText
+		{"dGVzdA==", "abcdabcdabcdabcdabcdabcdabcdabcd", true, ""},                                    // shorter than the nonce
 		{"8kcEqilvv+YKYcfnSr0aSC54gmnQCsB02SaB8ATlnA==", "abcdabcdabcdabcdabcdabcdabcdabcd", false, "123"},
 	}
 
@@ -0,0 +1,99 @@
+package security
+
+import (
+	"errors"
+)
+
+// ErrUnknownKeyVersion is returned when a versioned cipher text
+// doesn't match with any of the keyring keys.
+var ErrUnknownKeyVersion = errors.New("no matching keyring key for the cipher text version")
+
+// Keyring is a set of AES-256 keys that allows decrypting data
+// encrypted with any of the known keys while always encrypting with
+// the current one (eg. during encryption key rotation).
+type Keyring struct {
+	current  string
+	keys     []string
+	versions map[string]string
+}
+
+// NewKeyring creates a new keyring with the provided current
+// encryption key and optional list of old decryption only keys.
+//
+// Empty keys are ignored.
+func NewKeyring(currentKey string, oldKeys ...string) *Keyring {
+	k := &Keyring{
+		current:  currentKey,
+		versions: make(map[string]string, 1+len(oldKeys)),
+	}
+
+	for _, key := range append([]string{currentKey}, oldKeys...) {
+		if key == "" {
+			continue
+		}
+
+		version := KeyVersion(key)
+		if _, ok := k.versions[version]; ok {
+			continue // duplicated
+		}
+
+		k.versions[version] = key
+		k.keys = append(k.keys, key)
+	}
+
+	return k
+}
+
+// CurrentKey returns the keyring encryption key.
+func (k *Keyring) CurrentKey() string {
+	return k.current
+}
+
+// Encrypt encrypts the provided data with the keyring current key
+// (see [EncryptVersioned]).
+func (k *Keyring) Encrypt(data []byte) (string, error) {
+	return EncryptVersioned(data, k.current)
+}
+
+// Decrypt decrypts the provided cipher text with the keyring key
+// matching its version.
+//
+// Legacy (non-versioned) cipher texts are tried with all keyring
+// keys starting from the current one.
+func (k *Keyring) Decrypt(cipherText string) ([]byte, error) {
+	version, _ := splitKeyVersion(cipherText)
+
+	if version != "" {
+		key, ok := k.versions[version]
+		if !ok {
+			return nil, ErrUnknownKeyVersion
+		}
+
+		return Decrypt(cipherText, key)
+	}
+
+	if len(k.keys) == 0 {
+		return nil, errors.New("empty keyring")
+	}
+
+	var lastErr error
+
+	for _, key := range k.keys {
+		plain, err := Decrypt(cipherText, key)
+		if err == nil {
+			return plain, nil
+		}
+
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// IsCurrent reports whether the provided cipher text is versioned
+// and encrypted with the keyring current key.
+func (k *Keyring) IsCurrent(cipherText string) bool {
+	version, _ := splitKeyVersion(cipherText)
+
+	return k.current != "" && version == KeyVersion(k.current)
+}
@@ -0,0 +1,124 @@
+package security_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestKeyVersion(t *testing.T) {
+	key1 := "abcdabcdabcdabcdabcdabcdabcdabcd"
+	key2 := "1234123412341234123412341234abcd"
+
+	v1 := security.KeyVersion(key1)
+	v2 := security.KeyVersion(key2)
+
+	if len(v1) != 8 {
+		t.Fatalf("Expected 8 chars version, got %q", v1)
+	}
+
+	if v1 != security.KeyVersion(key1) {
+		t.Fatalf("Expected the same version for the same key")
+	}
+
+	if v1 == v2 {
+		t.Fatalf("Expected different versions for different keys, got %q", v1)
+	}
+
+	encrypted, err := security.EncryptVersioned([]byte("test"), key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(encrypted, v1+":") {
+		t.Fatalf("Expected cipher text with %q version prefix, got %q", v1, encrypted)
+	}
+
+	if _, err := security.Decrypt(encrypted, key2); !errors.Is(err, security.ErrKeyVersionMismatch) {
+		t.Fatalf("Expected ErrKeyVersionMismatch, got %v", err)
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	oldKey := "abcdabcdabcdabcdabcdabcdabcdabcd"
+	newKey := "1234123412341234123412341234abcd"
+	otherKey := "zxcvzxcvzxcvzxcvzxcvzxcvzxcvzxcv"
+
+	oldEncrypted, err := security.EncryptVersioned([]byte("old"), oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// legacy non-versioned cipher text
+	legacyEncrypted, err := security.Encrypt([]byte("old"), oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherEncrypted, err := security.EncryptVersioned([]byte("other"), otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate
+	keyring := security.NewKeyring(newKey, oldKey, "")
+
+	if keyring.CurrentKey() != newKey {
+		t.Fatalf("Expected current key %q, got %q", newKey, keyring.CurrentKey())
+	}
+
+	scenarios := []struct {
+		name          string
+		cipherText    string
+		expectError   bool
+		expectedPlain string
+	}{
+		{"old version", oldEncrypted, false, "old"},
+		{"legacy", legacyEncrypted, false, "old"},
+		{"unknown version", otherEncrypted, true, ""},
+		{"invalid", "invalid", true, ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			plain, err := keyring.Decrypt(s.cipherText)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if string(plain) != s.expectedPlain {
+				t.Fatalf("Expected %q, got %q", s.expectedPlain, plain)
+			}
+
+			if keyring.IsCurrent(s.cipherText) {
+				t.Fatalf("Expected %q to not be encrypted with the current key", s.cipherText)
+			}
+		})
+	}
+
+	// re-encrypt to the new key
+	reencrypted, err := keyring.Encrypt([]byte("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !keyring.IsCurrent(reencrypted) {
+		t.Fatalf("Expected %q to be encrypted with the current key", reencrypted)
+	}
+
+	if _, err := security.Decrypt(reencrypted, oldKey); err == nil {
+		t.Fatal("Expected the re-encrypted value to not be decryptable with the old key")
+	}
+
+	plain, err := security.Decrypt(reencrypted, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != "old" {
+		t.Fatalf("Expected %q, got %q", "old", plain)
+	}
+}
@@ -0,0 +1,236 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+// DefaultBcryptCost is the default bcrypt password hashing cost.
+const DefaultBcryptCost = 12
+
+// PasswordHasher defines a password hashing strategy.
+type PasswordHasher interface {
+	// Hash creates a new hash string from the provided plain password.
+	Hash(password string) (string, error)
+
+	// Verify reports whether the plain password matches the hash.
+	Verify(hash string, password string) bool
+
+	// NeedsRehash reports whether the hash was created with a different
+	// algorithm or parameters than the ones of the current hasher.
+	NeedsRehash(hash string) bool
+}
+
+// VerifyPassword reports whether the plain password matches
+// the hash of any of the supported algorithms (bcrypt and argon2id).
+func VerifyPassword(hash string, password string) bool {
+	if strings.HasPrefix(hash, "$"+PasswordAlgorithmArgon2id+"$") {
+		return (&Argon2idHasher{}).Verify(hash, password)
+	}
+
+	return (&BcryptHasher{}).Verify(hash, password)
+}
+
+// -------------------------------------------------------------------
+
+var _ PasswordHasher = (*BcryptHasher)(nil)
+
+// BcryptHasher is a [PasswordHasher] implementation
+// using the bcrypt password hashing algorithm.
+type BcryptHasher struct {
+	// Cost is the bcrypt hashing cost (default to [DefaultBcryptCost]).
+	Cost int
+}
+
+func (h *BcryptHasher) cost() int {
+	if h.Cost <= 0 {
+		return DefaultBcryptCost
+	}
+
+	return h.Cost
+}
+
+// Hash implements [PasswordHasher.Hash].
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost())
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// Verify implements [PasswordHasher.Verify].
+func (h *BcryptHasher) Verify(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NeedsRehash implements [PasswordHasher.NeedsRehash].
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost != h.cost()
+}
+
+// -------------------------------------------------------------------
+
+var _ PasswordHasher = (*Argon2idHasher)(nil)
+
+// Argon2idHasher is a [PasswordHasher] implementation using the
+// argon2id password hashing algorithm.
+//
+// The hashes are encoded in the PHC string format, eg.:
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<base64 salt>$<base64 key>
+type Argon2idHasher struct {
+	// Memory is the memory cost in KiB (default to 65536, aka. 64MiB).
+	Memory uint32
+
+	// Iterations is the number of passes over the memory (default to 3).
+	Iterations uint32
+
+	// Parallelism is the number of threads (default to 2).
+	Parallelism uint8
+}
+
+func (h *Argon2idHasher) params() (memory uint32, iterations uint32, parallelism uint8) {
+	memory, iterations, parallelism = h.Memory, h.Iterations, h.Parallelism
+
+	if memory == 0 {
+		memory = 64 * 1024
+	}
+
+	if iterations == 0 {
+		iterations = 3
+	}
+
+	if parallelism == 0 {
+		parallelism = 2
+	}
+
+	return
+}
+
+const (
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// Hash implements [PasswordHasher.Hash].
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	memory, iterations, parallelism := h.params()
+
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, argon2idKeyLength)
+
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		PasswordAlgorithmArgon2id,
+		argon2.Version,
+		memory,
+		iterations,
+		parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify implements [PasswordHasher.Verify].
+func (h *Argon2idHasher) Verify(hash string, password string) bool {
+	decoded, err := decodeArgon2idHash(hash)
+	if err != nil {
+		return false
+	}
+
+	key := argon2.IDKey(
+		[]byte(password),
+		decoded.salt,
+		decoded.iterations,
+		decoded.memory,
+		decoded.parallelism,
+		uint32(len(decoded.key)),
+	)
+
+	return subtle.ConstantTimeCompare(key, decoded.key) == 1
+}
+
+// NeedsRehash implements [PasswordHasher.NeedsRehash].
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	decoded, err := decodeArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+
+	memory, iterations, parallelism := h.params()
+
+	return decoded.memory != memory ||
+		decoded.iterations != iterations ||
+		decoded.parallelism != parallelism
+}
+
+type argon2idHash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func decodeArgon2idHash(hash string) (*argon2idHash, error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return nil, errors.New("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, err
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	result := &argon2idHash{}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &result.memory, &result.iterations, &result.parallelism); err != nil {
+		return nil, err
+	}
+	if result.iterations == 0 || result.parallelism == 0 {
+		return nil, errors.New("invalid argon2id hash parameters")
+	}
+
+	var err error
+
+	result.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, err
+	}
+
+	result.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.key) == 0 {
+		return nil, errors.New("empty argon2id key")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package security_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := &security.BcryptHasher{Cost: 4}
+
+	hash, err := hasher.Hash("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(hash, "$2a$04$") {
+		t.Fatalf("Expected bcrypt hash with cost 4, got %q", hash)
+	}
+
+	if !hasher.Verify(hash, "123456") {
+		t.Fatal("Expected the password to match")
+	}
+
+	if hasher.Verify(hash, "1234567") {
+		t.Fatal("Expected the password to not match")
+	}
+
+	if hasher.NeedsRehash(hash) {
+		t.Fatal("Expected NeedsRehash false for the same cost")
+	}
+
+	if !(&security.BcryptHasher{Cost: 5}).NeedsRehash(hash) {
+		t.Fatal("Expected NeedsRehash true for different cost")
+	}
+
+	if !hasher.NeedsRehash("invalid") {
+		t.Fatal("Expected NeedsRehash true for invalid hash")
+	}
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := &security.Argon2idHasher{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}
+
+	hash, err := hasher.Hash("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=8192,t=1,p=1$") {
+		t.Fatalf("Expected argon2id hash with the configured params, got %q", hash)
+	}
+
+	hash2, err := hasher.Hash("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == hash2 {
+		t.Fatal("Expected different hashes due to the random salt")
+	}
+
+	if !hasher.Verify(hash, "123456") || !hasher.Verify(hash2, "123456") {
+		t.Fatal("Expected the password to match")
+	}
+
+	if hasher.Verify(hash, "1234567") {
+		t.Fatal("Expected the password to not match")
+	}
+
+	scenarios := []struct {
+		name     string
+		hasher   *security.Argon2idHasher
+		hash     string
+		expected bool
+	}{
+		{"same params", hasher, hash, false},
+		{"different memory", &security.Argon2idHasher{Memory: 16 * 1024, Iterations: 1, Parallelism: 1}, hash, true},
+		{"different iterations", &security.Argon2idHasher{Memory: 8 * 1024, Iterations: 2, Parallelism: 1}, hash, true},
+		{"different parallelism", &security.Argon2idHasher{Memory: 8 * 1024, Iterations: 1, Parallelism: 2}, hash, true},
+		{"default params", &security.Argon2idHasher{}, hash, true},
+		{"bcrypt hash", hasher, "$2a$04$gJmCzm3CLmOQnhmsVj6OZ.bqB.YtmlrM8EN0U1Ue9CTHPMO9Rid7O", true},
+		{"invalid hash", hasher, "$argon2id$v=19$m=8192,t=0,p=1$YWJj$YWJj", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.hasher.NeedsRehash(s.hash)
+			if result != s.expected {
+				t.Fatalf("Expected NeedsRehash %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	bcryptHash, err := (&security.BcryptHasher{Cost: 4}).Hash("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	argon2idHash, err := (&security.Argon2idHasher{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}).Hash("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name     string
+		hash     string
+		password string
+		expected bool
+	}{
+		{"empty hash", "", "123456", false},
+		{"invalid hash", "invalid", "123456", false},
+		{"invalid argon2id hash", "$argon2id$v=19$m=8192,t=1,p=1$YWJj", "123456", false},
+		{"unsupported argon2id version", strings.Replace(argon2idHash, "v=19", "v=16", 1), "123456", false},
+		{"bcrypt with invalid password", bcryptHash, "1234567", false},
+		{"bcrypt with valid password", bcryptHash, "123456", true},
+		{"argon2id with invalid password", argon2idHash, "1234567", false},
+		{"argon2id with valid password", argon2idHash, "123456", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := security.VerifyPassword(s.hash, s.password)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
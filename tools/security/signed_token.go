@@ -0,0 +1,67 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// signedTokenSeparator separates the payload and signature parts of a signed token.
+const signedTokenSeparator = "."
+
+// ErrInvalidSignedToken is returned when the signed token
+// is malformed or its signature doesn't match.
+var ErrInvalidSignedToken = errors.New("invalid signed token")
+
+// NewSignedToken creates a compact URL-safe token from the provided
+// payload signed with HMAC-SHA256, aka.:
+//
+//	base64url(payload) + "." + base64url(hmac_sha256(base64url(payload), secret))
+//
+// Note that the payload is only encoded and NOT encrypted.
+func NewSignedToken(payload []byte, secret string) string {
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encodedPayload + signedTokenSeparator + signedTokenSignature(encodedPayload, secret)
+}
+
+// ParseUnverifiedSignedToken returns the decoded payload of the
+// provided signed token but DOES NOT verify its signature.
+func ParseUnverifiedSignedToken(token string) ([]byte, error) {
+	encodedPayload, _, ok := strings.Cut(token, signedTokenSeparator)
+	if !ok {
+		return nil, ErrInvalidSignedToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidSignedToken
+	}
+
+	return payload, nil
+}
+
+// ParseSignedToken verifies the signature of the provided
+// signed token and returns its decoded payload.
+//
+// The signature is compared in constant time with [EqualConstantTime].
+func ParseSignedToken(token string, secret string) ([]byte, error) {
+	encodedPayload, signature, ok := strings.Cut(token, signedTokenSeparator)
+	if !ok || secret == "" {
+		return nil, ErrInvalidSignedToken
+	}
+
+	if !EqualConstantTime(signature, signedTokenSignature(encodedPayload, secret)) {
+		return nil, ErrInvalidSignedToken
+	}
+
+	return ParseUnverifiedSignedToken(token)
+}
+
+func signedTokenSignature(encodedPayload string, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(encodedPayload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
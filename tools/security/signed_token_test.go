@@ -0,0 +1,62 @@
+package security_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestSignedToken(t *testing.T) {
+	token := security.NewSignedToken([]byte(`{"test":123}`), "secret")
+
+	if strings.ContainsAny(token, "+/=") {
+		t.Fatalf("Expected URL-safe token, got %q", token)
+	}
+
+	payload, signature, _ := strings.Cut(token, ".")
+
+	scenarios := []struct {
+		name          string
+		token         string
+		secret        string
+		expectError   bool
+		expectPayload string
+	}{
+		{"empty token", "", "secret", true, ""},
+		{"missing signature", payload, "secret", true, ""},
+		{"empty secret", token, "", true, ""},
+		{"wrong secret", token, "secret2", true, ""},
+		{"tampered signature", payload + "." + signature[1:], "secret", true, ""},
+		{"tampered payload", security.NewSignedToken([]byte(`{"test":456}`), "secret")[:len(payload)] + "." + signature, "secret", true, ""},
+		{"valid", token, "secret", false, `{"test":123}`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := security.ParseSignedToken(s.token, s.secret)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if string(result) != s.expectPayload {
+				t.Fatalf("Expected payload %q, got %q", s.expectPayload, result)
+			}
+		})
+	}
+
+	// unverified
+	unverified, err := security.ParseUnverifiedSignedToken(payload + ".invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(unverified) != `{"test":123}` {
+		t.Fatalf("Expected unverified payload %q, got %q", `{"test":123}`, unverified)
+	}
+
+	if _, err := security.ParseUnverifiedSignedToken("invalid"); err == nil {
+		t.Fatal("Expected error for token without signature")
+	}
+}
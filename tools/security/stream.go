@@ -0,0 +1,232 @@
+package security
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// streamMagic is the header prefix of the [NewEncryptWriter] generated streams.
+var streamMagic = []byte("PBSTREAM1")
+
+const (
+	streamChunkSize   = 64 * 1024
+	streamNoncePrefix = 7 // + 4 bytes chunk counter + 1 byte final chunk flag
+)
+
+// IsEncryptedStream reports whether the provided data starts with
+// the header of a [NewEncryptWriter] generated stream.
+func IsEncryptedStream(data []byte) bool {
+	return bytes.HasPrefix(data, streamMagic)
+}
+
+// NewEncryptWriter returns a writer that encrypts the written data
+// with the specified "key" (must be valid 32 char AES key) and writes it to w.
+//
+// The data is encrypted in 64KiB chunks using AES-256-GCM, where each chunk nonce
+// contains the chunk position and a final chunk flag so that reordered or truncated
+// streams can be detected on decryption (see [NewDecryptReader]).
+//
+// NB! Make sure to call `Close()` to write the final chunk.
+func NewEncryptWriter(w io.Writer, key string) (io.WriteCloser, error) {
+	gcm, err := newStreamGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, streamNoncePrefix)
+	if _, err := io.ReadFull(crand.Reader, prefix); err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(append(append([]byte{}, streamMagic...), prefix...)); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:      w,
+		gcm:    gcm,
+		prefix: prefix,
+		buf:    make([]byte, 0, streamChunkSize),
+	}, nil
+}
+
+type encryptWriter struct {
+	w       io.Writer
+	gcm     cipher.AEAD
+	prefix  []byte
+	buf     []byte
+	counter uint32
+	closed  bool
+}
+
+// Write implements [io.Writer] interface.
+func (ew *encryptWriter) Write(p []byte) (int, error) {
+	if ew.closed {
+		return 0, errors.New("write to a closed encrypt writer")
+	}
+
+	written := 0
+
+	for len(p) > 0 {
+		// the chunk is flushed only when there is more data so that
+		// the last chunk could be always sealed with the final flag on Close
+		if len(ew.buf) == streamChunkSize {
+			if err := ew.flush(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(ew.buf[len(ew.buf):streamChunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close writes the final encrypted chunk.
+//
+// It doesn't close the underlying writer.
+func (ew *encryptWriter) Close() error {
+	if ew.closed {
+		return nil
+	}
+
+	ew.closed = true
+
+	return ew.flush(true)
+}
+
+func (ew *encryptWriter) flush(final bool) error {
+	nonce, err := streamNonce(ew.prefix, ew.counter, final)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ew.w.Write(ew.gcm.Seal(nil, nonce, ew.buf, nil)); err != nil {
+		return err
+	}
+
+	ew.counter++
+	ew.buf = ew.buf[:0]
+
+	return nil
+}
+
+// NewDecryptReader returns a reader that decrypts the [NewEncryptWriter]
+// generated stream from r with the specified "key" (must be valid 32 char AES key).
+//
+// The Read calls return an error if the stream was modified, truncated
+// or encrypted with a different key (note that the data of the already
+// verified chunks may have been returned before the error).
+func NewDecryptReader(r io.Reader, key string) (io.Reader, error) {
+	gcm, err := newStreamGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(streamMagic)+streamNoncePrefix)
+	if _, err := io.ReadFull(r, header); err != nil || !IsEncryptedStream(header) {
+		return nil, errors.New("invalid or missing encrypted stream header")
+	}
+
+	return &decryptReader{
+		r:      bufio.NewReaderSize(r, streamChunkSize+gcm.Overhead()+1),
+		gcm:    gcm,
+		prefix: header[len(streamMagic):],
+		chunk:  make([]byte, streamChunkSize+gcm.Overhead()),
+	}, nil
+}
+
+type decryptReader struct {
+	r       *bufio.Reader
+	gcm     cipher.AEAD
+	prefix  []byte
+	chunk   []byte
+	plain   []byte
+	counter uint32
+	done    bool
+}
+
+// Read implements [io.Reader] interface.
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for len(dr.plain) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+
+		if err := dr.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, dr.plain)
+	dr.plain = dr.plain[n:]
+
+	return n, nil
+}
+
+func (dr *decryptReader) next() error {
+	n, err := io.ReadFull(dr.r, dr.chunk)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			return errors.New("truncated encrypted stream")
+		}
+		return err
+	}
+
+	// the chunk is final if there is no more data after it
+	final := err == io.ErrUnexpectedEOF
+	if !final {
+		if _, peekErr := dr.r.Peek(1); peekErr == io.EOF {
+			final = true
+		}
+	}
+
+	nonce, err := streamNonce(dr.prefix, dr.counter, final)
+	if err != nil {
+		return err
+	}
+
+	plain, err := dr.gcm.Open(dr.chunk[:0], nonce, dr.chunk[:n], nil)
+	if err != nil {
+		return errors.New("failed to decrypt the stream chunk (invalid key or corrupted data)")
+	}
+
+	dr.counter++
+	dr.plain = plain
+	dr.done = final
+
+	return nil
+}
+
+func newStreamGCM(key string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func streamNonce(prefix []byte, counter uint32, final bool) ([]byte, error) {
+	if counter == ^uint32(0) {
+		return nil, errors.New("the encrypted stream is too large")
+	}
+
+	nonce := make([]byte, streamNoncePrefix+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefix:], counter)
+	if final {
+		nonce[len(nonce)-1] = 1
+	}
+
+	return nonce, nil
+}
@@ -0,0 +1,105 @@
+package security_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func encryptStream(t *testing.T, data []byte, key string) []byte {
+	var buf bytes.Buffer
+
+	w, err := security.NewEncryptWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// write in odd sized parts to ensure that the chunks are properly buffered
+	for len(data) > 0 {
+		n := min(len(data), 10000)
+		if _, err := w.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestEncryptStreamRoundTrip(t *testing.T) {
+	key := "abcdabcdabcdabcdabcdabcdabcdabcd"
+
+	for _, size := range []int{0, 1, 64*1024 - 1, 64 * 1024, 64*1024 + 1, 3 * 64 * 1024, 200000} {
+		data := []byte(security.PseudorandomString(size))
+
+		encrypted := encryptStream(t, data, key)
+
+		if !security.IsEncryptedStream(encrypted) {
+			t.Fatalf("[%d] Expected encrypted stream header", size)
+		}
+
+		if size > 10 && bytes.Contains(encrypted, data[:10]) {
+			t.Fatalf("[%d] Expected the data to be encrypted", size)
+		}
+
+		r, err := security.NewDecryptReader(bytes.NewReader(encrypted), key)
+		if err != nil {
+			t.Fatalf("[%d] Failed to init the decrypt reader: %v", size, err)
+		}
+
+		decrypted, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("[%d] Failed to decrypt: %v", size, err)
+		}
+
+		if !bytes.Equal(decrypted, data) {
+			t.Fatalf("[%d] Expected the decrypted data to match", size)
+		}
+	}
+}
+
+func TestDecryptStreamInvalid(t *testing.T) {
+	key := "abcdabcdabcdabcdabcdabcdabcdabcd"
+
+	data := []byte(security.PseudorandomString(2*64*1024 + 100))
+	encrypted := encryptStream(t, data, key)
+
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)/2] ^= 1
+
+	// 9 magic + 7 nonce prefix + one full chunk sealed with the GCM tag
+	chunkBoundary := 16 + 64*1024 + 16
+
+	scenarios := []struct {
+		name string
+		data []byte
+		key  string
+	}{
+		{"invalid key", encrypted, "test"},
+		{"different key", encrypted, "1234abcdabcdabcdabcdabcdabcdabcd"},
+		{"missing header", data, key},
+		{"tampered chunk", tampered, key},
+		{"truncated in chunk", encrypted[:len(encrypted)-10], key},
+		{"truncated at chunk boundary", encrypted[:chunkBoundary], key},
+		{"only header", encrypted[:16], key},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			r, err := security.NewDecryptReader(bytes.NewReader(s.data), s.key)
+			if err == nil {
+				_, err = io.ReadAll(r)
+			}
+
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
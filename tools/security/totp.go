@@ -0,0 +1,127 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPPeriod is the TOTP time step in seconds.
+	TOTPPeriod = 30
+
+	// TOTPDigits is the number of the TOTP code digits.
+	TOTPDigits = 6
+
+	// totpSecretSize is the size in bytes of the generated TOTP secrets
+	// (160 bits as recommended by RFC 4226).
+	totpSecretSize = 20
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret generates a new random base32 encoded TOTP secret.
+func NewTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPStep returns the TOTP time step counter for the specified time.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / TOTPPeriod
+}
+
+// TOTPCode generates the [RFC 6238] TOTP code (HMAC-SHA1) for the
+// base32 encoded secret and the specified time step counter.
+//
+// [RFC 6238]: https://datatracker.ietf.org/doc/html/rfc6238
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	if len(key) == 0 {
+		return "", errors.New("empty TOTP secret")
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	h := hmac.New(sha1.New, key)
+	h.Write(msg)
+	sum := h.Sum(nil)
+
+	// dynamic truncation (RFC 4226 section 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod), nil
+}
+
+// ValidateTOTP checks the provided TOTP code against the secret
+// for the time step of t and up to skew steps before and after it
+// (to tolerate small clock drifts).
+//
+// On success returns the matched time step counter that could be
+// stored and used for rejecting already used codes.
+func ValidateTOTP(secret string, code string, t time.Time, skew int) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	current := TOTPStep(t)
+
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+
+		if Equal(expected, code) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// TOTPAuthURL returns the otpauth:// key URI of the TOTP secret
+// that could be imported (usually as QR code) in the authenticator apps.
+func TOTPAuthURL(issuer string, account string, secret string) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(TOTPPeriod))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
@@ -0,0 +1,147 @@
+package security_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// base32 of the RFC 6238 SHA1 test secret "12345678901234567890"
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret1, err := security.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret2, err := security.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secret1) != 32 {
+		t.Fatalf("Expected secret with 32 characters, got %d (%q)", len(secret1), secret1)
+	}
+
+	if secret1 == secret2 {
+		t.Fatalf("Expected different secrets, got %q", secret1)
+	}
+
+	if _, err := security.TOTPCode(secret1, 1); err != nil {
+		t.Fatalf("Expected the secret to be usable, got %v", err)
+	}
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 Appendix B (truncated to 6 digits)
+	scenarios := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.expected, func(t *testing.T) {
+			code, err := security.TOTPCode(rfcTOTPSecret, security.TOTPStep(time.Unix(s.unix, 0)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if code != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, code)
+			}
+		})
+	}
+
+	if _, err := security.TOTPCode("invalid!", 1); err == nil {
+		t.Fatal("Expected error for invalid secret")
+	}
+
+	if _, err := security.TOTPCode("", 1); err == nil {
+		t.Fatal("Expected error for empty secret")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := security.TOTPStep(now)
+
+	codeAt := func(offset int64) string {
+		code, err := security.TOTPCode(rfcTOTPSecret, step+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	scenarios := []struct {
+		name         string
+		code         string
+		skew         int
+		expectedOk   bool
+		expectedStep int64
+	}{
+		{"empty code", "", 1, false, 0},
+		{"invalid length", "12345", 1, false, 0},
+		{"invalid code", "000000", 1, false, 0},
+		{"current step", codeAt(0), 0, true, step},
+		{"current step with surrounding whitespaces", " " + codeAt(0) + " ", 0, true, step},
+		{"previous step without skew", codeAt(-1), 0, false, 0},
+		{"previous step within the drift window", codeAt(-1), 1, true, step - 1},
+		{"next step within the drift window", codeAt(1), 1, true, step + 1},
+		{"outside of the drift window", codeAt(2), 1, false, 0},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			matched, ok := security.ValidateTOTP(rfcTOTPSecret, s.code, now, s.skew)
+
+			if ok != s.expectedOk {
+				t.Fatalf("Expected ok %v, got %v", s.expectedOk, ok)
+			}
+
+			if matched != s.expectedStep {
+				t.Fatalf("Expected step %d, got %d", s.expectedStep, matched)
+			}
+		})
+	}
+}
+
+func TestTOTPAuthURL(t *testing.T) {
+	result := security.TOTPAuthURL("Acme Inc", "test@example.com", rfcTOTPSecret)
+
+	u, err := url.Parse(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Fatalf("Expected otpauth://totp/ url, got %q", result)
+	}
+
+	if u.Path != "/Acme Inc:test@example.com" {
+		t.Fatalf("Expected label %q, got %q", "/Acme Inc:test@example.com", u.Path)
+	}
+
+	expectedQuery := map[string]string{
+		"secret":    rfcTOTPSecret,
+		"issuer":    "Acme Inc",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for k, v := range expectedQuery {
+		if q := u.Query().Get(k); q != v {
+			t.Fatalf("Expected query param %q to be %q, got %q", k, v, q)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU defines a concurrent safe in memory least recently used
+// key-value cache with per item expiration.
+type LRU[T any] struct {
+	maxSize int
+	items   map[string]*list.Element
+	order   *list.List
+	mux     sync.Mutex
+}
+
+type lruItem[T any] struct {
+	key     string
+	value   T
+	expires time.Time
+}
+
+// NewLRU creates a new LRU[T] instance that holds up to maxSize items
+// (the least recently used items are evicted first).
+func NewLRU[T any](maxSize int) *LRU[T] {
+	return &LRU[T]{
+		maxSize: max(maxSize, 1),
+		items:   map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Length returns the current number of items in the cache
+// (including the expired ones that are not evicted yet).
+func (c *LRU[T]) Length() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.order.Len()
+}
+
+// Get returns the non-expired cached value for the provided key
+// and marks it as most recently used.
+func (c *LRU[T]) Get(key string) (T, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	var zero T
+
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	item := el.Value.(*lruItem[T])
+	if !item.expires.After(time.Now()) {
+		c.removeElement(el)
+		return zero, false
+	}
+
+	c.order.MoveToFront(el)
+
+	return item.value, true
+}
+
+// Set stores the provided value for ttl duration, evicting the
+// least recently used item if the cache max size is reached.
+func (c *LRU[T]) Set(key string, value T, ttl time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	expires := time.Now().Add(ttl)
+
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*lruItem[T])
+		item.value = value
+		item.expires = expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem[T]{key: key, value: value, expires: expires})
+
+	for c.order.Len() > c.maxSize {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Remove removes a single item from the cache.
+func (c *LRU[T]) Remove(key string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// RemoveByPrefix removes all items whose key starts with the
+// provided prefix and returns the number of the removed items.
+func (c *LRU[T]) RemoveByPrefix(prefix string) int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	var removed int
+
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// RemoveAll removes all items from the cache.
+func (c *LRU[T]) RemoveAll() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.items = map[string]*list.Element{}
+	c.order.Init()
+}
+
+func (c *LRU[T]) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*lruItem[T]).key)
+}
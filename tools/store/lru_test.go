@@ -0,0 +1,97 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+func TestLRUGetSet(t *testing.T) {
+	c := store.NewLRU[int](2)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("Expected missing item")
+	}
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+
+	// mark "a" as most recently used
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Expected a=1, got %v (%v)", v, ok)
+	}
+
+	// should evict "b"
+	c.Set("c", 3, time.Minute)
+
+	if c.Length() != 2 {
+		t.Fatalf("Expected 2 items, got %d", c.Length())
+	}
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("Expected b to be evicted")
+	}
+
+	// replace existing
+	c.Set("a", 10, time.Minute)
+
+	if v, _ := c.Get("a"); v != 10 {
+		t.Fatalf("Expected a=10, got %v", v)
+	}
+
+	if v, _ := c.Get("c"); v != 3 {
+		t.Fatalf("Expected c=3, got %v", v)
+	}
+}
+
+func TestLRUExpiration(t *testing.T) {
+	c := store.NewLRU[int](10)
+
+	c.Set("a", 1, 10*time.Millisecond)
+	c.Set("b", 2, time.Minute)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Expected a to be expired")
+	}
+
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Fatalf("Expected b=2, got %v (%v)", v, ok)
+	}
+
+	if c.Length() != 1 {
+		t.Fatalf("Expected the expired item to be removed, got %d items", c.Length())
+	}
+}
+
+func TestLRURemove(t *testing.T) {
+	c := store.NewLRU[int](10)
+
+	c.Set("x:1", 1, time.Minute)
+	c.Set("x:2", 2, time.Minute)
+	c.Set("y:1", 3, time.Minute)
+	c.Set("y:2", 4, time.Minute)
+
+	c.Remove("y:2")
+	c.Remove("missing")
+
+	if removed := c.RemoveByPrefix("x:"); removed != 2 {
+		t.Fatalf("Expected 2 removed items, got %d", removed)
+	}
+
+	if c.Length() != 1 {
+		t.Fatalf("Expected 1 item, got %d", c.Length())
+	}
+
+	if _, ok := c.Get("y:1"); !ok {
+		t.Fatal("Expected y:1 to remain")
+	}
+
+	c.RemoveAll()
+
+	if c.Length() != 0 {
+		t.Fatalf("Expected no items, got %d", c.Length())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/security"
@@ -45,11 +46,15 @@ type Client interface {
 	//
 	// Each subscription can also have "options" (json serialized SubscriptionOptions) as query parameter.
 	//
+	// The other url encoded query parameters are used as shorthand for the options query
+	// (eg. "subscriptionC?filter=a%3D1" is the same as `subscriptionC?options={"query":{"filter":"a=1"}}`).
+	//
 	// Example:
 	//
 	// 	Subscribe(
 	// 	    "subscriptionA",
 	// 	    `subscriptionB?options={"query":{"a":1},"headers":{"x_token":"abc"}}`,
+	// 	    "subscriptionC?filter=a%3D1",
 	// 	)
 	Subscribe(subs ...string)
 
@@ -79,6 +84,9 @@ type Client interface {
 	IsDiscarded() bool
 
 	// Send sends the specified message to the client's channel (if not discarded).
+	//
+	// The messages of the subscriptions with enabled coalescing
+	// (see [SubscriptionOptions.Coalesce]) could be buffered and sent as a single batch message.
 	Send(m Message)
 }
 
@@ -90,7 +98,9 @@ type DefaultClient struct {
 	store         map[string]any
 	subscriptions map[string]SubscriptionOptions
 	channel       chan Message
+	done          chan struct{}
 	id            string
+	coalesced     map[string]*coalesceBuffer
 	mux           sync.RWMutex
 	isDiscarded   bool
 }
@@ -101,6 +111,7 @@ func NewDefaultClient() *DefaultClient {
 		id:            security.RandomString(40),
 		store:         map[string]any{},
 		channel:       make(chan Message),
+		done:          make(chan struct{}),
 		subscriptions: map[string]SubscriptionOptions{},
 	}
 }
@@ -175,6 +186,22 @@ func (c *DefaultClient) Subscribe(subs ...string) {
 			if rawOptions != "" {
 				json.Unmarshal([]byte(rawOptions), &options)
 			}
+
+			// merge the shorthand query params (eg. "posts/*?filter=status%3D'active'")
+			// with the options query (the options query params take precedence)
+			for k, v := range u.Query() {
+				if k == optionsParam || len(v) == 0 {
+					continue
+				}
+
+				if options.Query == nil {
+					options.Query = map[string]any{}
+				}
+
+				if _, ok := options.Query[k]; !ok {
+					options.Query[k] = v[0]
+				}
+			}
 		}
 
 		// normalize query
@@ -197,20 +224,34 @@ func (c *DefaultClient) Subscribe(subs ...string) {
 // Unsubscribe implements the [Client.Unsubscribe] interface method.
 //
 // If subs is not set, this method removes all registered client's subscriptions.
+//
+// The buffered coalesced messages of the removed subscriptions (if any)
+// are flushed in the background.
 func (c *DefaultClient) Unsubscribe(subs ...string) {
 	c.mux.Lock()
-	defer c.mux.Unlock()
 
-	if len(subs) > 0 {
-		for _, s := range subs {
-			delete(c.subscriptions, s)
-		}
-	} else {
+	if len(subs) == 0 {
 		// unsubscribe all
 		for s := range c.subscriptions {
-			delete(c.subscriptions, s)
+			subs = append(subs, s)
 		}
 	}
+
+	pending := map[string][]json.RawMessage{}
+
+	for _, s := range subs {
+		delete(c.subscriptions, s)
+
+		if events := c.stopCoalesced(s); len(events) > 0 {
+			pending[s] = events
+		}
+	}
+
+	c.mux.Unlock()
+
+	for s, events := range pending {
+		go c.sendCoalesced(s, events)
+	}
 }
 
 // HasSubscription implements the [Client.HasSubscription] interface method.
@@ -252,7 +293,19 @@ func (c *DefaultClient) Discard() {
 	c.mux.Lock()
 	defer c.mux.Unlock()
 
+	if c.isDiscarded {
+		return
+	}
+
 	c.isDiscarded = true
+
+	if c.done != nil {
+		close(c.done)
+	}
+
+	for s := range c.coalesced {
+		c.stopCoalesced(s)
+	}
 }
 
 // IsDiscarded implements the [Client.IsDiscarded] interface method.
@@ -264,10 +317,129 @@ func (c *DefaultClient) IsDiscarded() bool {
 }
 
 // Send sends the specified message to the client's channel (if not discarded).
+//
+// If the message subscription has the coalescing enabled, the first message
+// is sent immediately and the following messages within the coalesce window
+// are buffered and sent as a single batch message (see [BatchData]) once
+// the window expires or the buffer reaches its max size.
+// A single buffered message is sent as it is.
 func (c *DefaultClient) Send(m Message) {
 	if c.IsDiscarded() {
 		return
 	}
 
+	if c.coalesce(m) {
+		return
+	}
+
 	c.Channel() <- m
 }
+
+// coalesce buffers the specified message if its subscription
+// has the coalescing enabled and there is an active coalesce window.
+//
+// Returns false if the message should be sent directly.
+func (c *DefaultClient) coalesce(m Message) bool {
+	c.mux.Lock()
+
+	options, ok := c.subscriptions[m.Name]
+	if !ok {
+		c.mux.Unlock()
+		return false
+	}
+
+	coalesceOptions, ok := options.Coalesce()
+	if !ok || !json.Valid(m.Data) {
+		c.mux.Unlock()
+		return false
+	}
+
+	buf, ok := c.coalesced[m.Name]
+	if !ok {
+		// start a new window and send the first message directly
+		if c.coalesced == nil {
+			c.coalesced = map[string]*coalesceBuffer{}
+		}
+		buf = &coalesceBuffer{}
+		buf.timer = time.AfterFunc(coalesceOptions.Window, func() {
+			c.flushCoalesced(m.Name, buf)
+		})
+		c.coalesced[m.Name] = buf
+		c.mux.Unlock()
+		return false
+	}
+
+	buf.events = append(buf.events, m.Data)
+	isFull := len(buf.events) >= coalesceOptions.MaxSize
+
+	c.mux.Unlock()
+
+	if isFull {
+		c.flushCoalesced(m.Name, buf)
+	}
+
+	return true
+}
+
+// flushCoalesced closes the coalesce window of the specified
+// subscription and sends its buffered messages (if any).
+func (c *DefaultClient) flushCoalesced(sub string, buf *coalesceBuffer) {
+	c.mux.Lock()
+
+	if c.coalesced[sub] != buf {
+		c.mux.Unlock()
+		return // already flushed or discarded
+	}
+
+	events := c.stopCoalesced(sub)
+
+	c.mux.Unlock()
+
+	c.sendCoalesced(sub, events)
+}
+
+// sendCoalesced sends the specified subscription buffered messages
+// as a single message.
+//
+// The send is aborted if the client is discarded in the meantime
+// (eg. when it is no longer reading from its channel).
+func (c *DefaultClient) sendCoalesced(sub string, events []json.RawMessage) {
+	var msg Message
+	switch len(events) {
+	case 0:
+		return
+	case 1:
+		msg = Message{Name: sub, Data: events[0]}
+	default:
+		var err error
+		msg, err = batchMessage(sub, events)
+		if err != nil {
+			return
+		}
+	}
+
+	if c.IsDiscarded() {
+		return
+	}
+
+	select {
+	case c.Channel() <- msg:
+	case <-c.done:
+	}
+}
+
+// stopCoalesced stops the coalesce window of the specified
+// subscription and returns its buffered messages (if any).
+//
+// Note: the caller is expected to hold the client write lock.
+func (c *DefaultClient) stopCoalesced(sub string) []json.RawMessage {
+	buf, ok := c.coalesced[sub]
+	if !ok {
+		return nil
+	}
+
+	buf.timer.Stop()
+	delete(c.coalesced, sub)
+
+	return buf.events
+}
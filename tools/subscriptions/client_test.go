@@ -117,8 +117,10 @@ func TestSubscribeOptions(t *testing.T) {
 
 	sub1 := "test1"
 	sub2 := `test2?options={"query":{"name":123},"headers":{"X-Token":456}}`
+	sub3 := "test3?filter=status%3D'active'&expand=rel"
+	sub4 := `test4?filter=a%3D1&fields=id&options={"query":{"filter":"b=2"}}`
 
-	c.Subscribe(sub1, sub2)
+	c.Subscribe(sub1, sub2, sub3, sub4)
 
 	subs := c.Subscriptions()
 
@@ -128,6 +130,8 @@ func TestSubscribeOptions(t *testing.T) {
 	}{
 		{sub1, `{"query":null,"headers":null}`},
 		{sub2, `{"query":{"name":"123"},"headers":{"x_token":"456"}}`},
+		{sub3, `{"query":{"expand":"rel","filter":"status='active'"},"headers":null}`},
+		{sub4, `{"query":{"fields":"id","filter":"b=2"},"headers":null}`},
 	}
 
 	for _, s := range scenarios {
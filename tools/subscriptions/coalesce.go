@@ -0,0 +1,100 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	// CoalesceParam is the subscription query parameter that enables the
+	// coalescing of the subscription messages into a single batch message
+	// (eg. "posts/*?coalesce=1" or "posts/*?coalesce=500ms").
+	//
+	// The parameter value could be a truthy value (to use the default window)
+	// or the batch time window as duration string.
+	CoalesceParam = "coalesce"
+
+	// CoalesceMaxParam is the optional subscription query parameter
+	// that limits the number of messages in a single batch
+	// (eg. "posts/*?coalesce=1&coalesceMax=50").
+	CoalesceMaxParam = "coalesceMax"
+
+	// BatchAction is the action of the coalesced batch messages data.
+	BatchAction = "batch"
+)
+
+const (
+	DefaultCoalesceWindow  = 100 * time.Millisecond
+	MaxCoalesceWindow      = 5 * time.Second
+	DefaultCoalesceMaxSize = 100
+	MaxCoalesceMaxSize     = 1000
+)
+
+// CoalesceOptions defines the batching thresholds of a coalesced subscription.
+type CoalesceOptions struct {
+	// Window is the max time that the first buffered message waits before the batch is flushed.
+	Window time.Duration
+
+	// MaxSize is the max number of buffered messages before the batch is flushed.
+	MaxSize int
+}
+
+// BatchData defines the data of a coalesced batch message.
+//
+// Events contains the data of each of the coalesced messages in the order of their send.
+type BatchData struct {
+	Action string            `json:"action"`
+	Events []json.RawMessage `json:"events"`
+}
+
+// Coalesce returns the subscription coalescing options normalized
+// within the allowed bounds.
+//
+// Returns false if the subscription doesn't have the coalescing enabled.
+func (o SubscriptionOptions) Coalesce() (CoalesceOptions, bool) {
+	result := CoalesceOptions{
+		Window:  DefaultCoalesceWindow,
+		MaxSize: DefaultCoalesceMaxSize,
+	}
+
+	raw := cast.ToString(o.Query[CoalesceParam])
+	if raw == "" {
+		return result, false
+	}
+
+	if window, err := time.ParseDuration(raw); err == nil {
+		if window <= 0 {
+			return result, false
+		}
+		result.Window = min(window, MaxCoalesceWindow)
+	} else if enabled, err := cast.ToBoolE(raw); err != nil || !enabled {
+		return result, false
+	}
+
+	if maxSize := cast.ToInt(o.Query[CoalesceMaxParam]); maxSize > 0 {
+		result.MaxSize = min(maxSize, MaxCoalesceMaxSize)
+	}
+
+	return result, true
+}
+
+// coalesceBuffer holds the pending messages of a single coalesced subscription.
+type coalesceBuffer struct {
+	timer  *time.Timer
+	events []json.RawMessage
+}
+
+// batchMessage creates a new single batch message from the provided events.
+func batchMessage(name string, events []json.RawMessage) (Message, error) {
+	data, err := json.Marshal(BatchData{
+		Action: BatchAction,
+		Events: events,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{Name: name, Data: data}, nil
+}
@@ -0,0 +1,305 @@
+package subscriptions_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+func TestSubscriptionOptionsCoalesce(t *testing.T) {
+	scenarios := []struct {
+		query           map[string]any
+		expectedEnabled bool
+		expected        subscriptions.CoalesceOptions
+	}{
+		{
+			nil,
+			false,
+			subscriptions.CoalesceOptions{},
+		},
+		{
+			map[string]any{"coalesce": "false"},
+			false,
+			subscriptions.CoalesceOptions{},
+		},
+		{
+			map[string]any{"coalesce": "invalid"},
+			false,
+			subscriptions.CoalesceOptions{},
+		},
+		{
+			map[string]any{"coalesce": "-1s"},
+			false,
+			subscriptions.CoalesceOptions{},
+		},
+		{
+			map[string]any{"coalesce": "1"},
+			true,
+			subscriptions.CoalesceOptions{Window: subscriptions.DefaultCoalesceWindow, MaxSize: subscriptions.DefaultCoalesceMaxSize},
+		},
+		{
+			map[string]any{"coalesce": "250ms", "coalesceMax": "10"},
+			true,
+			subscriptions.CoalesceOptions{Window: 250 * time.Millisecond, MaxSize: 10},
+		},
+		{
+			// bounded
+			map[string]any{"coalesce": "1h", "coalesceMax": "1000000"},
+			true,
+			subscriptions.CoalesceOptions{Window: subscriptions.MaxCoalesceWindow, MaxSize: subscriptions.MaxCoalesceMaxSize},
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%v", i, s.query), func(t *testing.T) {
+			result, enabled := subscriptions.SubscriptionOptions{Query: s.query}.Coalesce()
+
+			if enabled != s.expectedEnabled {
+				t.Fatalf("Expected enabled %v, got %v", s.expectedEnabled, enabled)
+			}
+
+			if enabled && result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestSendCoalesceBurst(t *testing.T) {
+	c := subscriptions.NewDefaultClient()
+	c.Subscribe("posts/*?coalesce=100ms")
+
+	received := collectTestMessages(c)
+
+	for i := 0; i < 30; i++ {
+		c.Send(testRecordMessage("posts/*?coalesce=100ms", i))
+	}
+
+	// the first message is delivered as it is
+	first := waitTestMessage(t, received)
+	if string(first.Data) != string(testRecordMessage("", 0).Data) {
+		t.Fatalf("Expected the first message to be delivered directly, got %s", first.Data)
+	}
+
+	// the rest are coalesced in a single batch message
+	batch := waitTestMessage(t, received)
+	if batch.Name != "posts/*?coalesce=100ms" {
+		t.Fatalf("Expected the batch message name to be the subscription, got %q", batch.Name)
+	}
+
+	data := subscriptions.BatchData{}
+	if err := json.Unmarshal(batch.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Action != subscriptions.BatchAction {
+		t.Fatalf("Expected %q action, got %q", subscriptions.BatchAction, data.Action)
+	}
+	if len(data.Events) != 29 {
+		t.Fatalf("Expected 29 coalesced events, got %d", len(data.Events))
+	}
+	for i, event := range data.Events {
+		if expected := testRecordMessage("", i+1).Data; string(event) != string(expected) {
+			t.Fatalf("Expected event %d to be %s, got %s", i, expected, event)
+		}
+	}
+
+	select {
+	case m := <-received:
+		t.Fatalf("Expected no more messages, got %s", m.Data)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSendCoalesceMaxSize(t *testing.T) {
+	sub := "posts/*?coalesce=200ms&coalesceMax=10"
+
+	c := subscriptions.NewDefaultClient()
+	c.Subscribe(sub)
+
+	received := collectTestMessages(c)
+
+	for i := 0; i < 26; i++ {
+		c.Send(testRecordMessage(sub, i))
+	}
+
+	// 1 + batch(10) + 1 + batch(10) + 1 + batch(3)
+	events := []json.RawMessage{}
+	for i := 0; i < 6; i++ {
+		m := waitTestMessage(t, received)
+
+		data := subscriptions.BatchData{}
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Action != subscriptions.BatchAction {
+			events = append(events, m.Data)
+			continue
+		}
+
+		if len(data.Events) > 10 {
+			t.Fatalf("Expected the batch to be limited to 10 events, got %d", len(data.Events))
+		}
+
+		events = append(events, data.Events...)
+	}
+
+	if len(events) != 26 {
+		t.Fatalf("Expected 26 total events, got %d", len(events))
+	}
+	for i, event := range events {
+		if expected := testRecordMessage("", i).Data; string(event) != string(expected) {
+			t.Fatalf("Expected event %d to be %s, got %s", i, expected, event)
+		}
+	}
+}
+
+func TestSendCoalesceLowRate(t *testing.T) {
+	sub := "posts/*?coalesce=20ms"
+
+	c := subscriptions.NewDefaultClient()
+	c.Subscribe(sub)
+
+	received := collectTestMessages(c)
+
+	for i := 0; i < 3; i++ {
+		c.Send(testRecordMessage(sub, i))
+
+		m := waitTestMessage(t, received)
+		if string(m.Data) != string(testRecordMessage("", i).Data) {
+			t.Fatalf("Expected message %d to be delivered directly, got %s", i, m.Data)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestSendWithoutCoalesce(t *testing.T) {
+	sub := "posts/*"
+
+	c := subscriptions.NewDefaultClient()
+	c.Subscribe(sub)
+
+	received := collectTestMessages(c)
+
+	for i := 0; i < 20; i++ {
+		c.Send(testRecordMessage(sub, i))
+	}
+
+	for i := 0; i < 20; i++ {
+		m := waitTestMessage(t, received)
+		if string(m.Data) != string(testRecordMessage("", i).Data) {
+			t.Fatalf("Expected message %d to be delivered directly, got %s", i, m.Data)
+		}
+	}
+}
+
+func TestSendCoalesceUnsubscribe(t *testing.T) {
+	sub := "posts/*?coalesce=50ms"
+
+	c := subscriptions.NewDefaultClient()
+	c.Subscribe(sub)
+
+	received := collectTestMessages(c)
+
+	for i := 0; i < 3; i++ {
+		c.Send(testRecordMessage(sub, i))
+	}
+
+	waitTestMessage(t, received)
+
+	c.Unsubscribe(sub)
+
+	// the buffered messages should be flushed immediately as a single batch
+	batch := waitTestMessage(t, received)
+
+	data := subscriptions.BatchData{}
+	if err := json.Unmarshal(batch.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Events) != 2 {
+		t.Fatalf("Expected 2 flushed events, got %d", len(data.Events))
+	}
+	for i, event := range data.Events {
+		if expected := testRecordMessage("", i+1).Data; string(event) != string(expected) {
+			t.Fatalf("Expected event %d to be %s, got %s", i, expected, event)
+		}
+	}
+
+	select {
+	case m := <-received:
+		t.Fatalf("Expected no other messages, got %s", m.Data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSendCoalesceDiscardedClient(t *testing.T) {
+	sub := "posts/*?coalesce=10ms"
+
+	c := subscriptions.NewDefaultClient()
+	c.Subscribe(sub)
+
+	// open the coalesce window without reading from the client channel
+	go c.Send(testRecordMessage(sub, 0))
+	<-c.Channel()
+
+	c.Send(testRecordMessage(sub, 1))
+
+	// let the window flush block on the unread channel
+	time.Sleep(50 * time.Millisecond)
+
+	// the blocked flush should be released on discard
+	done := make(chan struct{})
+	go func() {
+		c.Unsubscribe() // noop since the buffer was already flushed
+		c.Discard()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the client discard to not block")
+	}
+
+	select {
+	case m := <-c.Channel():
+		t.Fatalf("Expected the pending flush to be aborted, got %s", m.Data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// -------------------------------------------------------------------
+
+func testRecordMessage(sub string, i int) subscriptions.Message {
+	return subscriptions.Message{
+		Name: sub,
+		Data: []byte(fmt.Sprintf(`{"action":"create","record":{"id":"%d"}}`, i)),
+	}
+}
+
+func collectTestMessages(c subscriptions.Client) <-chan subscriptions.Message {
+	result := make(chan subscriptions.Message, 100)
+
+	go func() {
+		for m := range c.Channel() {
+			result <- m
+		}
+	}()
+
+	return result
+}
+
+func waitTestMessage(t *testing.T, received <-chan subscriptions.Message) subscriptions.Message {
+	select {
+	case m := <-received:
+		return m
+	case <-time.After(time.Second):
+		t.Fatal("Expected a new message")
+	}
+
+	return subscriptions.Message{}
+}
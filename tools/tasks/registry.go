@@ -0,0 +1,96 @@
+// Package tasks implements a simple in-memory registry for running
+// and tracking the progress of long-running background tasks.
+package tasks
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// DefaultCompletedTTL is the default duration for which the
+// completed tasks are kept in the registry.
+const DefaultCompletedTTL = 5 * time.Minute
+
+// Func defines a task function.
+//
+// Use task.Report to update the task progress while it is running.
+// The task is marked as done (or failed) once the function returns.
+type Func func(task *Task) error
+
+// Registry defines a concurrent safe in-memory tasks registry.
+type Registry struct {
+	mux          sync.RWMutex
+	tasks        map[string]*Task
+	completedTTL time.Duration
+}
+
+// NewRegistry initializes and returns a new Registry instance.
+//
+// completedTTL specifies for how long the completed tasks are kept
+// in the registry (fallbacks to DefaultCompletedTTL if <= 0).
+func NewRegistry(completedTTL time.Duration) *Registry {
+	if completedTTL <= 0 {
+		completedTTL = DefaultCompletedTTL
+	}
+
+	return &Registry{
+		tasks:        map[string]*Task{},
+		completedTTL: completedTTL,
+	}
+}
+
+// Start registers a new task owned by the provided owner key
+// and executes fn in a new go routine.
+func (r *Registry) Start(owner string, fn Func) *Task {
+	task := &Task{
+		id:          security.RandomString(20),
+		owner:       owner,
+		subscribers: map[chan Progress]struct{}{},
+	}
+
+	r.mux.Lock()
+	r.tasks[task.id] = task
+	r.mux.Unlock()
+
+	go func() {
+		var err error
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("task panic: %v", recovered)
+			}
+
+			task.finish(err)
+
+			time.AfterFunc(r.completedTTL, func() {
+				r.mux.Lock()
+				delete(r.tasks, task.id)
+				r.mux.Unlock()
+			})
+		}()
+
+		err = fn(task)
+	}()
+
+	return task
+}
+
+// Get returns a single registered task by its id
+// (nil if the task doesn't exist or has expired).
+func (r *Registry) Get(id string) *Task {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return r.tasks[id]
+}
+
+// Total returns the total number of registered tasks.
+func (r *Registry) Total() int {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return len(r.tasks)
+}
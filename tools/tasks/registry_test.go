@@ -0,0 +1,183 @@
+package tasks_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/tasks"
+)
+
+func TestRegistryStartAndGet(t *testing.T) {
+	r := tasks.NewRegistry(0)
+
+	release := make(chan struct{})
+
+	task := r.Start("owner1", func(task *tasks.Task) error {
+		task.Report(50, "half")
+		<-release
+		return nil
+	})
+
+	if task.Id() == "" {
+		t.Fatal("Expected non-empty task id")
+	}
+
+	if task.Owner() != "owner1" {
+		t.Fatalf("Expected owner %q, got %q", "owner1", task.Owner())
+	}
+
+	if r.Get(task.Id()) != task {
+		t.Fatal("Expected the task to be registered")
+	}
+
+	if r.Get("missing") != nil {
+		t.Fatal("Expected nil for missing task")
+	}
+
+	ch, unsubscribe := task.Subscribe()
+	defer unsubscribe()
+
+	close(release)
+
+	var last tasks.Progress
+	for p := range ch {
+		last = p
+	}
+
+	if !last.Done || last.Error != "" || last.Percent != 100 {
+		t.Fatalf("Expected successfully completed task, got %#v", last)
+	}
+
+	if p := task.Progress(); p != last {
+		t.Fatalf("Expected the final progress %#v, got %#v", last, p)
+	}
+
+	// reports after completion are ignored
+	task.Report(10, "after")
+	if p := task.Progress(); p != last {
+		t.Fatalf("Expected the final progress to remain unchanged, got %#v", p)
+	}
+
+	// subscribing to a completed task returns the final progress
+	ch2, _ := task.Subscribe()
+	if p, ok := <-ch2; !ok || p != last {
+		t.Fatalf("Expected the final progress, got %#v (%v)", p, ok)
+	}
+	if _, ok := <-ch2; ok {
+		t.Fatal("Expected closed channel")
+	}
+}
+
+func TestRegistryTaskError(t *testing.T) {
+	r := tasks.NewRegistry(0)
+
+	scenarios := []struct {
+		name     string
+		fn       tasks.Func
+		expected string
+	}{
+		{
+			"error",
+			func(task *tasks.Task) error {
+				task.Report(30, "working")
+				return errors.New("test_error")
+			},
+			"test_error",
+		},
+		{
+			"panic",
+			func(task *tasks.Task) error {
+				panic("test_panic")
+			},
+			"task panic: test_panic",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			task := r.Start("", s.fn)
+
+			ch, _ := task.Subscribe()
+
+			var last tasks.Progress
+			for p := range ch {
+				last = p
+			}
+
+			if !last.Done || last.Error != s.expected {
+				t.Fatalf("Expected failed task with error %q, got %#v", s.expected, last)
+			}
+		})
+	}
+}
+
+func TestRegistryReportNormalization(t *testing.T) {
+	r := tasks.NewRegistry(0)
+
+	done := make(chan struct{})
+	release := make(chan struct{})
+
+	task := r.Start("", func(task *tasks.Task) error {
+		defer close(done)
+		<-release
+		return nil
+	})
+
+	task.Report(-10, "a")
+	if p := task.Progress(); p.Percent != 0 || p.Message != "a" {
+		t.Fatalf("Expected 0%% a, got %#v", p)
+	}
+
+	task.Report(200, "b")
+	if p := task.Progress(); p.Percent != 100 || p.Message != "b" || p.Done {
+		t.Fatalf("Expected not done 100%% b, got %#v", p)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestRegistryUnsubscribe(t *testing.T) {
+	r := tasks.NewRegistry(0)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	task := r.Start("", func(task *tasks.Task) error {
+		<-release
+		return nil
+	})
+
+	ch, unsubscribe := task.Subscribe()
+	<-ch // initial state
+
+	unsubscribe()
+	unsubscribe() // no-op
+
+	if _, ok := <-ch; ok {
+		t.Fatal("Expected closed channel after unsubscribe")
+	}
+}
+
+func TestRegistryCompletedTTL(t *testing.T) {
+	r := tasks.NewRegistry(50 * time.Millisecond)
+
+	task := r.Start("", func(task *tasks.Task) error {
+		return nil
+	})
+
+	ch, _ := task.Subscribe()
+	for range ch {
+	}
+
+	if r.Get(task.Id()) == nil {
+		t.Fatal("Expected the completed task to be still registered")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if r.Get(task.Id()) != nil || r.Total() != 0 {
+		t.Fatal("Expected the completed task to be removed")
+	}
+}
@@ -0,0 +1,127 @@
+package tasks
+
+import (
+	"sync"
+)
+
+// Progress defines a single task progress state.
+type Progress struct {
+	Percent float64 `json:"percent"`
+	Message string  `json:"message"`
+	Done    bool    `json:"done"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Task defines a single registered background task.
+type Task struct {
+	mux         sync.RWMutex
+	id          string
+	owner       string
+	progress    Progress
+	subscribers map[chan Progress]struct{}
+}
+
+// Id returns the task id.
+func (t *Task) Id() string {
+	return t.id
+}
+
+// Owner returns the key of the task starter.
+func (t *Task) Owner() string {
+	return t.owner
+}
+
+// Progress returns the current task progress state.
+func (t *Task) Progress() Progress {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	return t.progress
+}
+
+// Report updates the task progress and notifies the task subscribers.
+//
+// percent is normalized in the range 0-100.
+// Reports after the task completion are ignored.
+func (t *Task) Report(percent float64, message string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.progress.Done {
+		return
+	}
+
+	t.progress.Percent = min(max(percent, 0), 100)
+	t.progress.Message = message
+
+	t.publish()
+}
+
+// Subscribe returns a channel that receives the current task progress
+// and its following updates until the task completes (the channel is
+// closed after the final progress state).
+//
+// The updates are coalesced for slow receivers, aka. the channel
+// always holds only the latest not received progress state.
+//
+// Call the returned unsubscribe function to stop receiving updates.
+func (t *Task) Subscribe() (ch <-chan Progress, unsubscribe func()) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	sub := make(chan Progress, 1)
+	sub <- t.progress
+
+	if t.progress.Done {
+		close(sub)
+		return sub, func() {}
+	}
+
+	t.subscribers[sub] = struct{}{}
+
+	return sub, func() {
+		t.mux.Lock()
+		defer t.mux.Unlock()
+
+		if _, ok := t.subscribers[sub]; ok {
+			delete(t.subscribers, sub)
+			close(sub)
+		}
+	}
+}
+
+// finish marks the task as done (optionally with an error)
+// and closes all subscribers channels.
+func (t *Task) finish(err error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.progress.Done = true
+	if err != nil {
+		t.progress.Error = err.Error()
+	} else {
+		t.progress.Percent = 100
+	}
+
+	t.publish()
+
+	for sub := range t.subscribers {
+		delete(t.subscribers, sub)
+		close(sub)
+	}
+}
+
+// publish sends the current progress to all subscribers
+// replacing their not received progress state (if any).
+//
+// Must be called with an acquired write lock.
+func (t *Task) publish() {
+	for sub := range t.subscribers {
+		select {
+		case <-sub:
+		default:
+		}
+
+		sub <- t.progress
+	}
+}
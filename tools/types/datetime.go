@@ -11,9 +11,25 @@ import (
 // DefaultDateLayout specifies the default app date strings layout.
 const DefaultDateLayout = "2006-01-02 15:04:05.000Z"
 
-// NowDateTime returns new DateTime instance with the current local time.
+// DateTimePrecision specifies the max precision of the DateTime values
+// (aka. the precision of the [DefaultDateLayout] serialized strings).
+const DateTimePrecision = time.Millisecond
+
+// dateTimeLayouts specifies the additional date string layouts
+// that are checked in order if the value is not in [DefaultDateLayout].
+//
+// The values with a timezone offset are normalized to UTC.
+var dateTimeLayouts = []string{
+	// eg. "2006-01-02 15:04:05Z", "2006-01-02 15:04:05.123456+02:00"
+	"2006-01-02 15:04:05.999999999Z07:00",
+
+	// eg. "2006-01-02T15:04:05Z", "2006-01-02T15:04:05.123456+02:00"
+	time.RFC3339Nano,
+}
+
+// NowDateTime returns new DateTime instance with the current UTC time.
 func NowDateTime() DateTime {
-	return DateTime{t: time.Now()}
+	return DateTime{t: normalizeTime(time.Now())}
 }
 
 // ParseDateTime creates a new DateTime from the provided value
@@ -26,6 +42,9 @@ func ParseDateTime(value any) (DateTime, error) {
 
 // DateTime represents a [time.Time] instance in UTC that is wrapped
 // and serialized using the app default date layout.
+//
+// The wrapped time is truncated to [DateTimePrecision] so that
+// the DateTime values are preserved as they are on serialize->parse.
 type DateTime struct {
 	t time.Time
 }
@@ -73,6 +92,8 @@ func (d DateTime) Value() (driver.Value, error) {
 
 // Scan implements [sql.Scanner] interface to scan the provided value
 // into the current DateTime instance.
+//
+// The scanned time is normalized to UTC and truncated to [DateTimePrecision].
 func (d *DateTime) Scan(value any) error {
 	switch v := value.(type) {
 	case time.Time:
@@ -80,26 +101,47 @@ func (d *DateTime) Scan(value any) error {
 	case DateTime:
 		d.t = v.Time()
 	case string:
-		if v == "" {
-			d.t = time.Time{}
-		} else {
-			t, err := time.Parse(DefaultDateLayout, v)
-			if err != nil {
-				// check for other common date layouts
-				t = cast.ToTime(v)
-			}
-			d.t = t
-		}
+		d.t = parseTime(v)
 	case int, int64, int32, uint, uint64, uint32:
 		d.t = cast.ToTime(v)
 	default:
-		str := cast.ToString(v)
-		if str == "" {
-			d.t = time.Time{}
-		} else {
-			d.t = cast.ToTime(str)
-		}
+		d.t = parseTime(cast.ToString(v))
 	}
 
+	d.t = normalizeTime(d.t)
+
 	return nil
 }
+
+// parseTime parses the provided date string by checking the
+// [DefaultDateLayout], the [dateTimeLayouts] and the other [cast.ToTime]
+// supported layouts (in that order).
+//
+// Returns zero time if the string couldn't be parsed.
+func parseTime(str string) time.Time {
+	if str == "" {
+		return time.Time{}
+	}
+
+	if t, err := time.Parse(DefaultDateLayout, str); err == nil {
+		return t
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t
+		}
+	}
+
+	// check for other common date layouts
+	return cast.ToTime(str)
+}
+
+// normalizeTime converts the provided time to UTC and truncates it to [DateTimePrecision].
+func normalizeTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	return t.UTC().Truncate(DateTimePrecision)
+}
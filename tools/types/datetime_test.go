@@ -1,6 +1,7 @@
 package types_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -38,6 +39,13 @@ func TestParseDateTime(t *testing.T) {
 		{uint64(1641024040), "2022-01-01 08:00:40.000Z"},
 		{uint32(1641024040), "2022-01-01 08:00:40.000Z"},
 		{"2022-01-01 11:23:45.678", "2022-01-01 11:23:45.678Z"},
+		{"2022-01-01 11:23:45Z", "2022-01-01 11:23:45.000Z"},
+		{"2022-01-01 11:23:45.678+02:00", "2022-01-01 09:23:45.678Z"},
+		{"2022-01-01 01:23:45.6789-03:30", "2022-01-01 04:53:45.678Z"},
+		{"2022-01-01T11:23:45.678Z", "2022-01-01 11:23:45.678Z"},
+		{"2022-01-01T11:23:45+02:00", "2022-01-01 09:23:45.000Z"},
+		{"2022-01-01T01:23:45.123456789+05:45", "2021-12-31 19:38:45.123Z"},
+		{time.Date(2022, 1, 1, 11, 23, 45, 678999999, time.FixedZone("", 3600)), "2022-01-01 10:23:45.678Z"},
 	}
 
 	for i, s := range scenarios {
@@ -53,6 +61,78 @@ func TestParseDateTime(t *testing.T) {
 	}
 }
 
+func TestDateTimeNormalization(t *testing.T) {
+	loc := time.FixedZone("test", -5*3600)
+	original := time.Date(2022, 1, 1, 11, 23, 45, 678123456, loc)
+
+	dt, err := types.ParseDateTime(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loc := dt.Time().Location(); loc != time.UTC {
+		t.Fatalf("Expected UTC location, got %v", loc)
+	}
+
+	expected := original.Truncate(time.Millisecond)
+	if !dt.Time().Equal(expected) {
+		t.Fatalf("Expected time %v, got %v", expected, dt.Time())
+	}
+
+	now := types.NowDateTime()
+	if loc := now.Time().Location(); loc != time.UTC {
+		t.Fatalf("Expected NowDateTime UTC location, got %v", loc)
+	}
+	if now.Time().Nanosecond()%int(time.Millisecond) != 0 {
+		t.Fatalf("Expected NowDateTime to be truncated to milliseconds, got %v", now.Time())
+	}
+}
+
+func TestDateTimeMillisecondsRoundTrip(t *testing.T) {
+	original, err := types.ParseDateTime(time.Date(2022, 1, 1, 11, 23, 45, 678999999, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// string
+	fromString, err := types.ParseDateTime(original.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromString != original {
+		t.Fatalf("Expected string round-trip %v, got %v", original.Time(), fromString.Time())
+	}
+
+	// json
+	raw, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `"2022-01-01 11:23:45.678Z"` {
+		t.Fatalf("Expected json %s, got %s", `"2022-01-01 11:23:45.678Z"`, raw)
+	}
+	fromJson := types.DateTime{}
+	if err := json.Unmarshal(raw, &fromJson); err != nil {
+		t.Fatal(err)
+	}
+	if fromJson != original {
+		t.Fatalf("Expected json round-trip %v, got %v", original.Time(), fromJson.Time())
+	}
+
+	// db value
+	value, err := original.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromValue := types.DateTime{}
+	if err := fromValue.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if fromValue != original {
+		t.Fatalf("Expected db value round-trip %v, got %v", original.Time(), fromValue.Time())
+	}
+}
+
 func TestDateTimeTime(t *testing.T) {
 	str := "2022-01-01 11:23:45.678Z"
 
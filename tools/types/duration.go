@@ -0,0 +1,220 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// DurationUnit specifies the canonical unit of the Duration values,
+// aka. the unit of the stored db integer and of the plain numeric values.
+const DurationUnit = time.Millisecond
+
+// isoDurationRegex matches the ISO-8601 durations with week, day
+// and time components (eg. "PT1H30M", "P1DT2H", "-PT0.5S").
+//
+// The year and month components are not supported because they
+// don't have a fixed length.
+var isoDurationRegex = regexp.MustCompile(`^([-+])?P(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// isoDurationUnits lists the units of the isoDurationRegex components (in order).
+var isoDurationUnits = []time.Duration{
+	7 * 24 * time.Hour,
+	24 * time.Hour,
+	time.Hour,
+	time.Minute,
+	time.Second,
+}
+
+// ParseDuration creates a new Duration from the provided value.
+//
+// The value could be:
+//   - Go duration string (eg. "1h30m", "1.5s", "-45m")
+//   - ISO-8601 duration string without years and months (eg. "PT1H30M", "P1DT2H")
+//   - number or numeric string in [DurationUnit] (eg. 5400000)
+//   - [time.Duration] or Duration
+//
+// Empty string and nil are parsed as zero Duration.
+func ParseDuration(value any) (Duration, error) {
+	d := Duration(0)
+	err := d.Scan(value)
+	return d, err
+}
+
+// Duration represents a [time.Duration] that is truncated to
+// [DurationUnit] and serialized as a human readable string (eg. "1h30m0s").
+//
+// In the db it is stored as integer number in [DurationUnit]
+// so that it could be numerically compared and sorted.
+type Duration time.Duration
+
+// Duration returns the internal [time.Duration] value.
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// Int returns the Duration value as integer number in [DurationUnit].
+func (d Duration) Int() int64 {
+	return int64(d) / int64(DurationUnit)
+}
+
+// IsZero checks whether the current Duration has zero value.
+func (d Duration) IsZero() bool {
+	return d == 0
+}
+
+// String serializes the current Duration into a Go duration string (eg. "1h30m0s").
+func (d Duration) String() string {
+	return d.Duration().String()
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// Both duration strings and numbers in [DurationUnit] are accepted.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	return d.Scan(raw)
+}
+
+// Value implements the [driver.Valuer] interface.
+func (d Duration) Value() (driver.Value, error) {
+	return d.Int(), nil
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current Duration instance.
+//
+// The scanned value is truncated to [DurationUnit].
+func (d *Duration) Scan(value any) error {
+	var result time.Duration
+
+	switch v := value.(type) {
+	case nil:
+	case Duration:
+		result = v.Duration()
+	case time.Duration:
+		result = v
+	case []byte:
+		parsed, err := parseDuration(string(v))
+		if err != nil {
+			return err
+		}
+		result = parsed
+	case string:
+		parsed, err := parseDuration(v)
+		if err != nil {
+			return err
+		}
+		result = parsed
+	default:
+		num, err := cast.ToFloat64E(v)
+		if err != nil {
+			return fmt.Errorf("failed to scan duration value %v: %w", value, err)
+		}
+		parsed, err := unitsToDuration(num, DurationUnit)
+		if err != nil {
+			return err
+		}
+		result = parsed
+	}
+
+	*d = Duration(result.Truncate(DurationUnit))
+
+	return nil
+}
+
+// parseDuration parses the provided Go, ISO-8601 or numeric duration string.
+func parseDuration(str string) (time.Duration, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return 0, nil
+	}
+
+	if num, err := strconv.ParseFloat(str, 64); err == nil {
+		return unitsToDuration(num, DurationUnit)
+	}
+
+	if d, err := time.ParseDuration(str); err == nil {
+		return d, nil
+	}
+
+	if d, ok, err := parseIsoDuration(str); ok {
+		return d, err
+	}
+
+	return 0, fmt.Errorf("invalid duration %q (expected Go duration like \"1h30m\" or ISO-8601 duration like \"PT1H30M\")", str)
+}
+
+// parseIsoDuration parses an ISO-8601 duration string.
+//
+// The second returned argument reports whether the string is in a valid ISO-8601 format.
+func parseIsoDuration(str string) (time.Duration, bool, error) {
+	match := isoDurationRegex.FindStringSubmatch(strings.ToUpper(str))
+	if match == nil {
+		return 0, false, nil
+	}
+
+	var total time.Duration
+	var hasComponent bool
+
+	for i, unit := range isoDurationUnits {
+		raw := match[i+2]
+		if raw == "" {
+			continue
+		}
+		hasComponent = true
+
+		num, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+		if err != nil {
+			return 0, true, err
+		}
+
+		d, err := unitsToDuration(num, unit)
+		if err != nil {
+			return 0, true, err
+		}
+
+		if d > math.MaxInt64-total {
+			return 0, true, errors.New("duration is out of range")
+		}
+		total += d
+	}
+
+	// "P" and "PT" are not valid durations
+	if !hasComponent || strings.HasSuffix(match[0], "T") {
+		return 0, false, nil
+	}
+
+	if match[1] == "-" {
+		total = -total
+	}
+
+	return total, true, nil
+}
+
+// unitsToDuration converts the provided number of units to [time.Duration].
+func unitsToDuration(num float64, unit time.Duration) (time.Duration, error) {
+	result := num * float64(unit)
+
+	if math.IsNaN(result) || result >= math.MaxInt64 || result <= math.MinInt64 {
+		return 0, errors.New("duration is out of range")
+	}
+
+	return time.Duration(result), nil
+}
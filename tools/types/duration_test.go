@@ -0,0 +1,182 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestParseDuration(t *testing.T) {
+	scenarios := []struct {
+		value       any
+		expected    time.Duration
+		expectError bool
+	}{
+		{nil, 0, false},
+		{"", 0, false},
+		{"invalid", 0, true},
+		{"P", 0, true},
+		{"PT", 0, true},
+		{"P1DT", 0, true},
+		{"P1Y", 0, true},
+		{"P1M", 0, true},
+		{[]int{1}, 0, true},
+
+		// numbers (in milliseconds)
+		{0, 0, false},
+		{1500, 1500 * time.Millisecond, false},
+		{int64(-60000), -time.Minute, false},
+		{1.9, time.Millisecond, false},
+		{"5400000", 90 * time.Minute, false},
+
+		// go durations
+		{"1h30m", 90 * time.Minute, false},
+		{"-45m", -45 * time.Minute, false},
+		{"1.5s", 1500 * time.Millisecond, false},
+		{"1ms999us", time.Millisecond, false},
+		{"2h45m30.5s", 2*time.Hour + 45*time.Minute + 30500*time.Millisecond, false},
+
+		// iso-8601 durations
+		{"PT1H30M", 90 * time.Minute, false},
+		{"pt1h30m", 90 * time.Minute, false},
+		{"P1DT2H", 26 * time.Hour, false},
+		{"P2W", 14 * 24 * time.Hour, false},
+		{"PT0.5S", 500 * time.Millisecond, false},
+		{"PT1,5M", 90 * time.Second, false},
+		{"-PT15M", -15 * time.Minute, false},
+		{"P1D", 24 * time.Hour, false},
+
+		// durations
+		{time.Minute + time.Microsecond, time.Minute, false},
+		{types.Duration(time.Hour), time.Hour, false},
+	}
+
+	for i, s := range scenarios {
+		d, err := types.ParseDuration(s.value)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if d.Duration() != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, d.Duration())
+		}
+	}
+}
+
+func TestDurationInt(t *testing.T) {
+	scenarios := []struct {
+		value    types.Duration
+		expected int64
+	}{
+		{0, 0},
+		{types.Duration(time.Second), 1000},
+		{types.Duration(-90 * time.Minute), -5400000},
+	}
+
+	for i, s := range scenarios {
+		if v := s.value.Int(); v != s.expected {
+			t.Errorf("(%d) Expected %d, got %d", i, s.expected, v)
+		}
+	}
+}
+
+func TestDurationIsZero(t *testing.T) {
+	if !types.Duration(0).IsZero() {
+		t.Fatal("Expected zero duration")
+	}
+
+	if types.Duration(time.Millisecond).IsZero() {
+		t.Fatal("Expected non-zero duration")
+	}
+}
+
+func TestDurationMarshalJSON(t *testing.T) {
+	scenarios := []struct {
+		value    types.Duration
+		expected string
+	}{
+		{0, `"0s"`},
+		{types.Duration(90 * time.Minute), `"1h30m0s"`},
+		{types.Duration(1500 * time.Millisecond), `"1.5s"`},
+	}
+
+	for i, s := range scenarios {
+		result, err := json.Marshal(s.value)
+		if err != nil {
+			t.Errorf("(%d) %v", i, err)
+			continue
+		}
+
+		if string(result) != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, result)
+		}
+	}
+}
+
+func TestDurationUnmarshalJSON(t *testing.T) {
+	scenarios := []struct {
+		json        string
+		expected    time.Duration
+		expectError bool
+	}{
+		{`invalid`, 0, true},
+		{`"invalid"`, 0, true},
+		{`null`, 0, false},
+		{`""`, 0, false},
+		{`60000`, time.Minute, false},
+		{`"1h30m0s"`, 90 * time.Minute, false},
+		{`"PT1H"`, time.Hour, false},
+	}
+
+	for i, s := range scenarios {
+		d := types.Duration(0)
+		err := json.Unmarshal([]byte(s.json), &d)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if d.Duration() != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, d.Duration())
+		}
+	}
+}
+
+func TestDurationValueAndScan(t *testing.T) {
+	original := types.Duration(26*time.Hour + 5*time.Millisecond)
+
+	value, err := original.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value != int64(93600005) {
+		t.Fatalf("Expected db value %d, got %v", 93600005, value)
+	}
+
+	scanned := types.Duration(0)
+	if err := scanned.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+
+	if scanned != original {
+		t.Fatalf("Expected scanned duration %v, got %v", original, scanned)
+	}
+
+	// human string round-trip
+	fromString, err := types.ParseDuration(original.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fromString != original {
+		t.Fatalf("Expected string round-trip %v, got %v", original, fromString)
+	}
+}
@@ -0,0 +1,237 @@
+// Package webhooks implements HMAC signed outbound webhook
+// deliveries with exponential backoff retries.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const (
+	// SignatureHeader is the name of the header with the request body signature.
+	SignatureHeader = "X-Signature"
+
+	// EventHeader is the name of the header with the delivery event name.
+	EventHeader = "X-Webhook-Event"
+
+	// TimestampHeader is the name of the header with the signed
+	// delivery attempt unix timestamp (in seconds).
+	TimestampHeader = "X-Webhook-Timestamp"
+
+	// signaturePrefix is the prefix of the signature header value
+	// that describes the used HMAC digest algorithm.
+	signaturePrefix = "sha256="
+)
+
+// Default Sender options.
+const (
+	DefaultMaxRetries = 5
+	DefaultBaseDelay  = 1 * time.Second
+	DefaultMaxDelay   = 5 * time.Minute
+	DefaultTimeout    = 10 * time.Second
+)
+
+// DefaultTolerance is the default max allowed difference between the
+// signed timestamp and the current time used by the webhook receivers.
+const DefaultTolerance = 5 * time.Minute
+
+// Sign returns the HMAC-SHA256 signature of the "timestamp.payload"
+// string in the format used by the SignatureHeader (eg. "sha256=3b8d...").
+func Sign(secret string, timestamp string, payload []byte) string {
+	return signaturePrefix + security.HS256(timestamp+"."+string(payload), secret)
+}
+
+// Verify checks in constant time whether the provided signature
+// is a valid SignatureHeader value of the timestamp and payload.
+//
+// If tolerance is positive, the timestamp is also required to be within
+// the tolerance from the current time (to prevent replaying old deliveries).
+//
+// It could be used by the webhook receivers, eg.:
+//
+//	body, _ := io.ReadAll(r.Body)
+//	timestamp := r.Header.Get(webhooks.TimestampHeader)
+//	signature := r.Header.Get(webhooks.SignatureHeader)
+//	if !webhooks.Verify(secret, timestamp, body, signature, webhooks.DefaultTolerance) {
+//		// invalid signature...
+//	}
+func Verify(secret string, timestamp string, payload []byte, signature string, tolerance time.Duration) bool {
+	timestamp = strings.TrimSpace(timestamp)
+
+	if tolerance > 0 {
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false
+		}
+
+		diff := time.Since(time.Unix(unix, 0))
+		if diff > tolerance || diff < -tolerance {
+			return false
+		}
+	}
+
+	return security.Equal(Sign(secret, timestamp, payload), strings.TrimSpace(signature))
+}
+
+// Message defines a single webhook delivery.
+type Message struct {
+	Url     string
+	Event   string
+	Secret  string
+	Payload []byte
+	Headers map[string]string
+}
+
+// DeliveryError defines a failed webhook delivery error.
+type DeliveryError struct {
+	// StatusCode is the last response status code (0 on network error).
+	StatusCode int
+
+	// Retryable indicates whether the last error was a temporary one.
+	Retryable bool
+
+	Err error
+}
+
+// Error implements the [error] interface.
+func (e *DeliveryError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("webhook delivery failed with status %d: %v", e.StatusCode, e.Err)
+	}
+
+	return fmt.Sprintf("webhook delivery failed: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Sender delivers webhook messages with exponential backoff retries.
+type Sender struct {
+	// Client is the http client used for the deliveries.
+	Client *http.Client
+
+	// MaxRetries is the max number of retries after the first failed attempt.
+	MaxRetries int
+
+	// BaseDelay is the delay before the first retry (doubled after each retry).
+	BaseDelay time.Duration
+
+	// MaxDelay is the max delay between two attempts.
+	MaxDelay time.Duration
+
+	// OnRetry is an optional callback that is invoked
+	// before each retry (eg. for logging purposes).
+	OnRetry func(msg *Message, attempt int, delay time.Duration, err error)
+}
+
+// NewSender creates a new Sender with the default options.
+func NewSender() *Sender {
+	return &Sender{
+		Client:     &http.Client{Timeout: DefaultTimeout},
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
+
+// Send delivers the provided message as signed POST request.
+//
+// Network errors and 408, 429 and 5xx responses are retried
+// with exponential backoff until MaxRetries is reached or ctx is canceled.
+//
+// Returns the number of the made attempts and a [*DeliveryError]
+// if the message couldn't be delivered.
+func (s *Sender) Send(ctx context.Context, msg *Message) (int, error) {
+	var attempts int
+
+	for {
+		attempts++
+
+		err := s.send(ctx, msg)
+		if err == nil {
+			return attempts, nil
+		}
+
+		var deliveryErr *DeliveryError
+		if !errors.As(err, &deliveryErr) || !deliveryErr.Retryable || attempts > s.MaxRetries {
+			return attempts, err
+		}
+
+		delay := s.delay(attempts)
+
+		if s.OnRetry != nil {
+			s.OnRetry(msg, attempts, delay, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempts, &DeliveryError{StatusCode: deliveryErr.StatusCode, Err: ctx.Err()}
+		case <-time.After(delay):
+		}
+	}
+}
+
+// delay returns the backoff delay after the specified failed attempt.
+func (s *Sender) delay(attempt int) time.Duration {
+	delay := s.BaseDelay << (attempt - 1)
+
+	if s.MaxDelay > 0 && (delay > s.MaxDelay || delay < 0) {
+		delay = s.MaxDelay
+	}
+
+	return max(delay, 0)
+}
+
+func (s *Sender) send(ctx context.Context, msg *Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Url, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return &DeliveryError{Err: err}
+	}
+
+	for k, v := range msg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, msg.Event)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(msg.Secret, timestamp, msg.Payload))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return &DeliveryError{Retryable: ctx.Err() == nil, Err: err}
+	}
+	defer res.Body.Close()
+
+	// read a small part of the body for the error message
+	// (and to allow reusing the connection)
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	return &DeliveryError{
+		StatusCode: res.StatusCode,
+		Retryable: res.StatusCode == http.StatusRequestTimeout ||
+			res.StatusCode == http.StatusTooManyRequests ||
+			res.StatusCode >= 500,
+		Err: errors.New(strings.TrimSpace(string(body))),
+	}
+}
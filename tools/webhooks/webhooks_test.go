@@ -0,0 +1,229 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/webhooks"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	payload := []byte(`{"test":123}`)
+
+	signature := webhooks.Sign("secret", "1700000000", payload)
+
+	expected := "sha256=3d89430314de7ce2664954158c6405cbf89c09166fabe3e670b86eea209c772e"
+	if signature != expected {
+		t.Fatalf("Expected signature %q, got %q", expected, signature)
+	}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	nowSignature := webhooks.Sign("secret", now, payload)
+
+	old := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	oldSignature := webhooks.Sign("secret", old, payload)
+
+	scenarios := []struct {
+		secret    string
+		timestamp string
+		payload   []byte
+		signature string
+		tolerance time.Duration
+		expected  bool
+	}{
+		{"secret", "1700000000", payload, signature, 0, true},
+		{"secret", "1700000000", payload, " " + signature + " ", 0, true},
+		{"secret", "1700000000", payload, signature[7:], 0, false},
+		{"secret", "1700000001", payload, signature, 0, false},
+		{"secret", "1700000000", []byte(`{"test":1234}`), signature, 0, false},
+		{"secret2", "1700000000", payload, signature, 0, false},
+		{"secret", "1700000000", payload, "", 0, false},
+		// tolerance
+		{"secret", now, payload, nowSignature, webhooks.DefaultTolerance, true},
+		{"secret", old, payload, oldSignature, webhooks.DefaultTolerance, false},
+		{"secret", old, payload, oldSignature, 0, true},
+		{"secret", "invalid", payload, webhooks.Sign("secret", "invalid", payload), webhooks.DefaultTolerance, false},
+	}
+
+	for i, s := range scenarios {
+		if result := webhooks.Verify(s.secret, s.timestamp, s.payload, s.signature, s.tolerance); result != s.expected {
+			t.Errorf("[%d] Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestSenderSendSuccess(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := webhooks.NewSender()
+
+	attempts, err := sender.Send(context.Background(), &webhooks.Message{
+		Url:     server.URL,
+		Event:   "create",
+		Secret:  "test",
+		Payload: []byte(`{"a":1}`),
+		Headers: map[string]string{"X-Custom": "abc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", attempts)
+	}
+
+	if received.Method != http.MethodPost {
+		t.Fatalf("Expected POST request, got %s", received.Method)
+	}
+
+	if string(receivedBody) != `{"a":1}` {
+		t.Fatalf("Expected the message payload, got %s", receivedBody)
+	}
+
+	timestamp := received.Header.Get(webhooks.TimestampHeader)
+
+	headers := map[string]string{
+		"Content-Type":           "application/json",
+		"X-Custom":               "abc",
+		webhooks.EventHeader:     "create",
+		webhooks.SignatureHeader: webhooks.Sign("test", timestamp, receivedBody),
+	}
+	for k, v := range headers {
+		if h := received.Header.Get(k); h != v {
+			t.Fatalf("Expected %q header %q, got %q", k, v, h)
+		}
+	}
+
+	signature := received.Header.Get(webhooks.SignatureHeader)
+	if !webhooks.Verify("test", timestamp, receivedBody, signature, webhooks.DefaultTolerance) {
+		t.Fatal("Expected the received signature to be valid")
+	}
+}
+
+func TestSenderSendRetry(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	sender := webhooks.NewSender()
+	sender.BaseDelay = 5 * time.Millisecond
+
+	delays := []time.Duration{}
+	sender.OnRetry = func(msg *webhooks.Message, attempt int, delay time.Duration, err error) {
+		delays = append(delays, delay)
+	}
+
+	attempts, err := sender.Send(context.Background(), &webhooks.Message{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 || calls != 3 {
+		t.Fatalf("Expected 3 attempts, got %d (calls %d)", attempts, calls)
+	}
+
+	// exponential backoff
+	if len(delays) != 2 || delays[0] != 5*time.Millisecond || delays[1] != 10*time.Millisecond {
+		t.Fatalf("Expected [5ms 10ms] delays, got %v", delays)
+	}
+}
+
+func TestSenderSendFailure(t *testing.T) {
+	scenarios := []struct {
+		name             string
+		status           int
+		maxRetries       int
+		expectedAttempts int
+	}{
+		{"permanent error (no retries)", http.StatusBadRequest, 3, 1},
+		{"max retries reached", http.StatusInternalServerError, 2, 3},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(s.status)
+				w.Write([]byte("test_error"))
+			}))
+			defer server.Close()
+
+			sender := webhooks.NewSender()
+			sender.BaseDelay = time.Millisecond
+			sender.MaxRetries = s.maxRetries
+
+			attempts, err := sender.Send(context.Background(), &webhooks.Message{Url: server.URL})
+
+			if attempts != s.expectedAttempts {
+				t.Fatalf("Expected %d attempts, got %d", s.expectedAttempts, attempts)
+			}
+
+			var deliveryErr *webhooks.DeliveryError
+			if !errors.As(err, &deliveryErr) {
+				t.Fatalf("Expected DeliveryError, got %v", err)
+			}
+
+			if deliveryErr.StatusCode != s.status {
+				t.Fatalf("Expected status code %d, got %d", s.status, deliveryErr.StatusCode)
+			}
+
+			if deliveryErr.Err.Error() != "test_error" {
+				t.Fatalf("Expected the response body as error, got %v", deliveryErr.Err)
+			}
+		})
+	}
+}
+
+func TestSenderSendCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sender := webhooks.NewSender()
+	sender.BaseDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sender.OnRetry = func(msg *webhooks.Message, attempt int, delay time.Duration, err error) {
+		cancel()
+	}
+
+	attempts, err := sender.Send(ctx, &webhooks.Message{Url: server.URL})
+	if attempts != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", attempts)
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+}
@@ -133,6 +133,11 @@ export default [
         title: "Planning Center",
         logo:  "planningcenter.svg",
     },
+    {
+        key:   "slackAuth",
+        title: "Slack",
+        logo:  "slack.svg",
+    },
     {
         key:   "oidcAuth",
         title: "OpenID Connect",